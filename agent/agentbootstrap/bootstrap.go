@@ -208,6 +208,7 @@ func InitializeState(
 		CloudRegion:             args.ControllerCloudRegion,
 		CloudCredential:         cloudCredentialTag,
 		StorageProviderRegistry: args.StorageProviderRegistry,
+		StoragePools:            args.HostedModelStoragePools,
 	})
 	if err != nil {
 		return nil, nil, errors.Annotate(err, "creating hosted model")
@@ -19,6 +19,7 @@ import (
 	agenttools "github.com/juju/juju/agent/tools"
 	"github.com/juju/juju/testing"
 	coretest "github.com/juju/juju/tools"
+	"github.com/juju/juju/tools/delta"
 )
 
 type ToolsSuite struct {
@@ -40,7 +41,7 @@ func (t *ToolsSuite) TestPackageDependencies(c *gc.C) {
 	// resulting slice has that prefix removed to keep the output short.
 	c.Assert(testing.FindJujuCoreImports(c, "github.com/juju/juju/agent/tools"),
 		gc.DeepEquals,
-		[]string{"tools"})
+		[]string{"tools", "tools/delta"})
 }
 
 // gzyesses holds the result of running:
@@ -148,6 +149,62 @@ func (t *ToolsSuite) TestUnpackToolsContents(c *gc.C) {
 	t.assertToolsContents(c, testTools, files)
 }
 
+func (t *ToolsSuite) TestUnpackToolsDelta(c *gc.C) {
+	files := []*testing.TarFile{
+		testing.NewTarFile("bar", agenttools.DirPerm, "bar contents"),
+		testing.NewTarFile("foo", agenttools.DirPerm, "foo contents"),
+	}
+	data, checksum := testing.TarGz(files...)
+	baseTools := &coretest.Tools{
+		URL:     "http://foo/bar",
+		Version: version.MustParseBinary("1.2.3-quantal-amd64"),
+		Size:    int64(len(data)),
+		SHA256:  checksum,
+	}
+	err := agenttools.UnpackTools(t.dataDir, baseTools, bytes.NewReader(data))
+	c.Assert(err, jc.ErrorIsNil)
+
+	files2 := []*testing.TarFile{
+		testing.NewTarFile("bar", agenttools.DirPerm, "bar contents, updated"),
+		testing.NewTarFile("baz", agenttools.DirPerm, "baz contents"),
+	}
+	data2, checksum2 := testing.TarGz(files2...)
+	baseFiles, err := delta.ReadTarball(bytes.NewReader(data))
+	c.Assert(err, jc.ErrorIsNil)
+	targetFiles, err := delta.ReadTarball(bytes.NewReader(data2))
+	c.Assert(err, jc.ErrorIsNil)
+	toolsDelta, err := delta.DiffTools(baseFiles, targetFiles)
+	c.Assert(err, jc.ErrorIsNil)
+
+	testTools := &coretest.Tools{
+		URL:     "http://foo/baz",
+		Version: version.MustParseBinary("1.2.4-quantal-amd64"),
+		Size:    int64(len(data2)),
+		SHA256:  checksum2,
+	}
+	err = agenttools.UnpackToolsDelta(t.dataDir, baseTools.Version, testTools, bytes.NewReader(toolsDelta))
+	c.Assert(err, jc.ErrorIsNil)
+	assertDirNames(c, t.toolsDir(), []string{"1.2.3-quantal-amd64", "1.2.4-quantal-amd64"})
+	t.assertToolsContents(c, testTools, files2)
+}
+
+func (t *ToolsSuite) TestUnpackToolsDeltaBaseMissing(c *gc.C) {
+	base := []delta.File{{Name: "jujud", Mode: 0755, Data: []byte("old")}}
+	target := []delta.File{{Name: "jujud", Mode: 0755, Data: []byte("new")}}
+	toolsDelta, err := delta.DiffTools(base, target)
+	c.Assert(err, jc.ErrorIsNil)
+	testTools := &coretest.Tools{
+		Version: version.MustParseBinary("1.2.4-quantal-amd64"),
+	}
+	err = agenttools.UnpackToolsDelta(
+		t.dataDir, version.MustParseBinary("1.2.3-quantal-amd64"),
+		testTools, bytes.NewReader(toolsDelta),
+	)
+	c.Assert(err, gc.ErrorMatches, `applying tools delta: reading base file "jujud": .*`)
+	_, err = os.Stat(t.toolsDir())
+	c.Assert(err, jc.Satisfies, os.IsNotExist)
+}
+
 func (t *ToolsSuite) TestReadToolsErrors(c *gc.C) {
 	vers := version.MustParseBinary("1.2.3-precise-amd64")
 	testTools, err := agenttools.ReadTools(t.dataDir, vers)
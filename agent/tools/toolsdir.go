@@ -5,6 +5,7 @@ package tools
 
 import (
 	"archive/tar"
+	"bytes"
 	"compress/gzip"
 	"crypto/sha256"
 	"encoding/json"
@@ -20,6 +21,7 @@ import (
 	"github.com/juju/version"
 
 	coretools "github.com/juju/juju/tools"
+	"github.com/juju/juju/tools/delta"
 )
 
 const (
@@ -78,10 +80,69 @@ func UnpackTools(dataDir string, tools *coretools.Tools, r io.Reader) (err error
 		return fmt.Errorf("tarball sha256 mismatch, expected %s, got %s", tools.SHA256, gzipSHA256)
 	}
 
+	// Checksum matches, now reset the file and untar it.
+	_, err = f.Seek(0, 0)
+	if err != nil {
+		return err
+	}
+	return installTools(dataDir, tools, func(dir string) error {
+		tr := tar.NewReader(f)
+		for {
+			hdr, err := tr.Next()
+			if err == io.EOF {
+				return nil
+			}
+			if err != nil {
+				return err
+			}
+			if strings.ContainsAny(hdr.Name, "/\\") {
+				return fmt.Errorf("bad name %q in tools archive", hdr.Name)
+			}
+			if hdr.Typeflag != tar.TypeReg {
+				return fmt.Errorf("bad file type %c in file %q in tools archive", hdr.Typeflag, hdr.Name)
+			}
+			name := path.Join(dir, hdr.Name)
+			if err := writeFile(name, os.FileMode(hdr.Mode&0777), tr); err != nil {
+				return errors.Annotatef(err, "tar extract %q failed", name)
+			}
+		}
+	})
+}
+
+// UnpackToolsDelta reads a delta between sets of juju tools, as
+// produced by delta.DiffTools, and applies it to the tools of the
+// given base version within dataDir, unpacking the result into the
+// appropriate tools directory. The resulting files are verified
+// against the hashes recorded in the delta, so the delta must have
+// been read from a trusted source. If a valid tools directory
+// already exists, UnpackToolsDelta returns without error.
+func UnpackToolsDelta(dataDir string, baseVersion version.Binary, tools *coretools.Tools, r io.Reader) error {
+	baseDir := SharedToolsDir(dataDir, baseVersion)
+	files, err := delta.ApplyTools(r, func(name string) ([]byte, error) {
+		return ioutil.ReadFile(path.Join(baseDir, name))
+	})
+	if err != nil {
+		return errors.Annotate(err, "applying tools delta")
+	}
+	return installTools(dataDir, tools, func(dir string) error {
+		for _, f := range files {
+			name := path.Join(dir, f.Name)
+			if err := writeFile(name, f.Mode, bytes.NewReader(f.Data)); err != nil {
+				return errors.Annotatef(err, "writing %q failed", name)
+			}
+		}
+		return nil
+	})
+}
+
+// installTools makes the tools directory for the given tools within
+// dataDir, calling writeFiles to write the tools' files into a
+// temporary directory that is then renamed into place.
+func installTools(dataDir string, tools *coretools.Tools, writeFiles func(dir string) error) error {
 	// Make a temporary directory in the tools directory,
 	// first ensuring that the tools directory exists.
 	toolsDir := path.Join(dataDir, "tools")
-	err = os.MkdirAll(toolsDir, dirPerm)
+	err := os.MkdirAll(toolsDir, dirPerm)
 	if err != nil {
 		return err
 	}
@@ -91,31 +152,9 @@ func UnpackTools(dataDir string, tools *coretools.Tools, r io.Reader) (err error
 	}
 	defer removeAll(dir)
 
-	// Checksum matches, now reset the file and untar it.
-	_, err = f.Seek(0, 0)
-	if err != nil {
+	if err := writeFiles(dir); err != nil {
 		return err
 	}
-	tr := tar.NewReader(f)
-	for {
-		hdr, err := tr.Next()
-		if err == io.EOF {
-			break
-		}
-		if err != nil {
-			return err
-		}
-		if strings.ContainsAny(hdr.Name, "/\\") {
-			return fmt.Errorf("bad name %q in tools archive", hdr.Name)
-		}
-		if hdr.Typeflag != tar.TypeReg {
-			return fmt.Errorf("bad file type %c in file %q in tools archive", hdr.Typeflag, hdr.Name)
-		}
-		name := path.Join(dir, hdr.Name)
-		if err := writeFile(name, os.FileMode(hdr.Mode&0777), tr); err != nil {
-			return errors.Annotatef(err, "tar extract %q failed", name)
-		}
-	}
 	toolsMetadataData, err := json.Marshal(tools)
 	if err != nil {
 		return err
@@ -75,14 +75,64 @@ func (st *State) IsMaster() (bool, error) {
 	return results.Master, err
 }
 
+// ModelCredential returns the tag of the cloud credential used by the
+// model. If the model has no credential, the returned bool is false.
+func (c *State) ModelCredential() (names.CloudCredentialTag, bool, error) {
+	var result params.ModelCredential
+	err := c.facade.FacadeCall("ModelCredential", nil, &result)
+	if err != nil {
+		return names.CloudCredentialTag{}, false, errors.Trace(err)
+	}
+	if result.CloudCredential == "" {
+		return names.CloudCredentialTag{}, false, nil
+	}
+	tag, err := names.ParseCloudCredentialTag(result.CloudCredential)
+	if err != nil {
+		return names.CloudCredentialTag{}, false, errors.Trace(err)
+	}
+	return tag, true, nil
+}
+
+// ModelCredentialValid reports whether the cloud credential used by
+// the model is valid, i.e. has not been rejected by the cloud. A model
+// without a credential is considered to have a valid credential.
+func (c *State) ModelCredentialValid() (bool, error) {
+	var result params.ModelCredential
+	err := c.facade.FacadeCall("ModelCredential", nil, &result)
+	if err != nil {
+		return false, errors.Trace(err)
+	}
+	return !result.Invalid, nil
+}
+
+// InvalidateModelCredential records that the cloud has rejected the
+// model's cloud credential for the given reason.
+func (c *State) InvalidateModelCredential(reason string) error {
+	var result params.ErrorResult
+	args := params.InvalidateCredentialArg{Reason: reason}
+	err := c.facade.FacadeCall("InvalidateModelCredential", args, &result)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	if result.Error != nil {
+		return result.Error
+	}
+	return nil
+}
+
 // WatchCredential returns a watcher which reports when the specified
 // credential has changed.
 func (c *State) WatchCredential(tag names.CloudCredentialTag) (watcher.NotifyWatcher, error) {
-	var result params.NotifyWatchResult
-	err := c.facade.FacadeCall("WatchCredential", nil, &result)
+	var results params.NotifyWatchResults
+	args := params.Entities{Entities: []params.Entity{{Tag: tag.String()}}}
+	err := c.facade.FacadeCall("WatchCredentials", args, &results)
 	if err != nil {
 		return nil, errors.Trace(err)
 	}
+	if n := len(results.Results); n != 1 {
+		return nil, errors.Errorf("expected 1 result, got %d", n)
+	}
+	result := results.Results[0]
 	if result.Error != nil {
 		return nil, result.Error
 	}
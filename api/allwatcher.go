@@ -16,6 +16,12 @@ type AllWatcher struct {
 	objType string
 	caller  base.APICaller
 	id      *string
+
+	// sessionToken identifies the resumable session of the watcher,
+	// if it has one, and sequence is the sequence number of the most
+	// recent deltas returned by Next.
+	sessionToken string
+	sequence     int64
 }
 
 // NewAllWatcher returns an AllWatcher instance which interacts with a
@@ -37,6 +43,22 @@ func NewAllModelWatcher(caller base.APICaller, id *string) *AllWatcher {
 	return newAllWatcher("AllModelWatcher", caller, id)
 }
 
+// NewAllModelWatcherSession returns an AllWatcher instance which
+// interacts with a watcher created or resumed by the WatchAllModels or
+// ResumeWatchAllModels API calls, recording the session token returned
+// by the call. The sequence number is that of the most recent deltas
+// already received by the client, if the session is being resumed.
+//
+// There should be no need to call this from outside of the api
+// package. It is only used by Client.WatchAllModels and
+// Client.ResumeWatchAllModels in api/controller.
+func NewAllModelWatcherSession(caller base.APICaller, info params.AllWatcherId, sequence int64) *AllWatcher {
+	w := newAllWatcher("AllModelWatcher", caller, &info.AllWatcherId)
+	w.sessionToken = info.SessionToken
+	w.sequence = sequence
+	return w
+}
+
 func newAllWatcher(objType string, caller base.APICaller, id *string) *AllWatcher {
 	return &AllWatcher{
 		objType: objType,
@@ -57,9 +79,27 @@ func (watcher *AllWatcher) Next() ([]multiwatcher.Delta, error) {
 		"Next",
 		nil, &info,
 	)
+	if err == nil && info.Sequence != 0 {
+		watcher.sequence = info.Sequence
+	}
 	return info.Deltas, err
 }
 
+// SessionToken returns the token identifying the watcher's resumable
+// session, or the empty string if the API server does not support
+// resumable sessions.
+func (watcher *AllWatcher) SessionToken() string {
+	return watcher.sessionToken
+}
+
+// Sequence returns the sequence number of the most recent deltas
+// returned by Next. The session token and sequence number may be used
+// to resume the watcher on a new connection, should the current one
+// be lost.
+func (watcher *AllWatcher) Sequence() int64 {
+	return watcher.sequence
+}
+
 // Stop shutdowns down a watcher previously created by the WatchAll or
 // WatchAllModels API calls
 func (watcher *AllWatcher) Stop() error {
@@ -102,6 +102,10 @@ type state struct {
 	// controllerAccess holds the access level of the user to the connected controller.
 	controllerAccess string
 
+	// canDelegate holds whether the server permits the authenticated
+	// entity to make requests on behalf of other entities.
+	canDelegate bool
+
 	// broken is a channel that gets closed when the connection is
 	// broken.
 	broken chan struct{}
@@ -191,6 +195,31 @@ func open(
 	client := rpc.NewConn(jsoncodec.NewWebsocket(conn), observer.None())
 	client.Start()
 
+	st := newState(client, conn.Config().Location.Host, tlsConfig, info, opts, clock)
+	st.conn = conn
+	if !info.SkipLogin {
+		if err := st.Login(info.Tag, info.Password, info.Nonce, info.Macaroons); err != nil {
+			conn.Close()
+			return nil, errors.Trace(err)
+		}
+	}
+	st.broken = make(chan struct{})
+	st.closed = make(chan struct{})
+	go st.heartbeatMonitor()
+	return st, nil
+}
+
+// newState returns a state that makes RPC calls with the given client,
+// and HTTP requests to the API server at the given host, authenticating
+// them with the credentials in info.
+func newState(
+	client rpcConnection,
+	apiHost string,
+	tlsConfig *tls.Config,
+	info *Info,
+	opts DialOpts,
+	clock clock.Clock,
+) *state {
 	bakeryClient := opts.BakeryClient
 	if bakeryClient == nil {
 		bakeryClient = httpbakery.NewClient()
@@ -201,7 +230,6 @@ func open(
 		httpc := *bakeryClient.Client
 		bakeryClient.Client = &httpc
 	}
-	apiHost := conn.Config().Location.Host
 	// Technically when there's no CACert, we don't need this
 	// machinery, because we could just use http.DefaultTransport
 	// for everything, but it's easier just to leave it in place.
@@ -211,19 +239,18 @@ func open(
 		fallback:    http.DefaultTransport,
 	}
 
-	st := &state{
+	return &state{
 		client: client,
-		conn:   conn,
 		clock:  clock,
 		addr:   apiHost,
 		cookieURL: &url.URL{
 			Scheme: "https",
-			Host:   conn.Config().Location.Host,
+			Host:   apiHost,
 			Path:   "/",
 		},
 		pingerFacadeVersion: facadeVersions["Pinger"],
 		serverScheme:        "https",
-		serverRootAddress:   conn.Config().Location.Host,
+		serverRootAddress:   apiHost,
 		// We populate the username and password before
 		// login because, when doing HTTP requests, we'll want
 		// to use the same username and password for authenticating
@@ -236,16 +263,6 @@ func open(
 		bakeryClient: bakeryClient,
 		modelTag:     info.ModelTag,
 	}
-	if !info.SkipLogin {
-		if err := st.Login(info.Tag, info.Password, info.Nonce, info.Macaroons); err != nil {
-			conn.Close()
-			return nil, errors.Trace(err)
-		}
-	}
-	st.broken = make(chan struct{})
-	st.closed = make(chan struct{})
-	go st.heartbeatMonitor()
-	return st, nil
 }
 
 // hostSwitchingTransport provides an http.RoundTripper
@@ -278,6 +295,25 @@ func connectWebsocket(info *Info, opts DialOpts) (*websocket.Conn, *tls.Config,
 	if len(info.Addrs) == 0 {
 		return nil, nil, errors.New("no API addresses to connect to")
 	}
+	tlsConfig, err := newTLSConfig(info, opts)
+	if err != nil {
+		return nil, nil, errors.Trace(err)
+	}
+	path, err := apiPath(info.ModelTag, "/api")
+	if err != nil {
+		return nil, nil, errors.Trace(err)
+	}
+	conn, err := dialWebSocket(info.Addrs, path, tlsConfig, opts)
+	if err != nil {
+		return nil, nil, errors.Trace(err)
+	}
+	logger.Infof("connection established to %q", conn.RemoteAddr())
+	return conn, tlsConfig, nil
+}
+
+// newTLSConfig returns the TLS configuration appropriate for making
+// SSL connections to the API server described by info.
+func newTLSConfig(info *Info, opts DialOpts) (*tls.Config, error) {
 	tlsConfig := utils.SecureTLSConfig()
 	tlsConfig.InsecureSkipVerify = opts.InsecureSkipVerify
 
@@ -287,20 +323,11 @@ func connectWebsocket(info *Info, opts DialOpts) (*websocket.Conn, *tls.Config,
 		tlsConfig.ServerName = "juju-apiserver"
 		certPool, err := CreateCertPool(info.CACert)
 		if err != nil {
-			return nil, nil, errors.Annotate(err, "cert pool creation failed")
+			return nil, errors.Annotate(err, "cert pool creation failed")
 		}
 		tlsConfig.RootCAs = certPool
 	}
-	path, err := apiPath(info.ModelTag, "/api")
-	if err != nil {
-		return nil, nil, errors.Trace(err)
-	}
-	conn, err := dialWebSocket(info.Addrs, path, tlsConfig, opts)
-	if err != nil {
-		return nil, nil, errors.Trace(err)
-	}
-	logger.Infof("connection established to %q", conn.RemoteAddr())
-	return conn, tlsConfig, nil
+	return tlsConfig, nil
 }
 
 // dialWebSocket dials a websocket with one of the provided addresses, the
@@ -591,14 +618,20 @@ type hasErrorCode interface {
 // object id, and the specific RPC method. It marshalls the Arguments, and will
 // unmarshall the result into the response object that is supplied.
 func (s *state) APICall(facade string, version int, id, method string, args, response interface{}) error {
+	return s.call(rpc.Request{
+		Type:    facade,
+		Version: version,
+		Id:      id,
+		Action:  method,
+	}, args, response)
+}
+
+// call makes the given request, retrying while the
+// server reports that the request should be retried.
+func (s *state) call(req rpc.Request, args, response interface{}) error {
 	retrySpec := retry.CallArgs{
 		Func: func() error {
-			return s.client.Call(rpc.Request{
-				Type:    facade,
-				Version: version,
-				Id:      id,
-				Action:  method,
-			}, args, response)
+			return s.client.Call(req, args, response)
 		},
 		IsFatalError: func(err error) bool {
 			err = errors.Cause(err)
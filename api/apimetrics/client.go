@@ -0,0 +1,31 @@
+// Copyright 2016 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package apimetrics
+
+import (
+	"github.com/juju/errors"
+
+	"github.com/juju/juju/api/base"
+	"github.com/juju/juju/apiserver/params"
+)
+
+// Client provides access to the APIMetrics API facade.
+type Client struct {
+	facade base.FacadeCaller
+}
+
+// NewClient returns a new Client based on an existing API connection.
+func NewClient(caller base.APICaller) *Client {
+	return &Client{base.NewFacadeCaller(caller, "APIMetrics")}
+}
+
+// SlowCalls returns the calls in the API server's slow call log,
+// oldest first.
+func (c *Client) SlowCalls() ([]params.SlowCall, error) {
+	var result params.SlowCallsResult
+	if err := c.facade.FacadeCall("SlowCalls", nil, &result); err != nil {
+		return nil, errors.Trace(err)
+	}
+	return result.Calls, nil
+}
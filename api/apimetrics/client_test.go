@@ -0,0 +1,70 @@
+// Copyright 2016 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package apimetrics_test
+
+import (
+	"time"
+
+	"github.com/juju/errors"
+	jujutesting "github.com/juju/testing"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/api/apimetrics"
+	basetesting "github.com/juju/juju/api/base/testing"
+	"github.com/juju/juju/apiserver/params"
+)
+
+type clientSuite struct {
+	jujutesting.IsolationSuite
+}
+
+var _ = gc.Suite(&clientSuite{})
+
+func (s *clientSuite) TestSlowCalls(c *gc.C) {
+	calls := []params.SlowCall{{
+		Time:      time.Date(2016, 10, 1, 0, 0, 0, 0, time.UTC),
+		Duration:  3 * time.Second,
+		Facade:    "Client",
+		Version:   1,
+		Method:    "FullStatus",
+		ModelUUID: "deadbeef",
+		Entity:    "user-bob",
+	}}
+	var called bool
+	apiCaller := basetesting.APICallerFunc(
+		func(objType string,
+			version int,
+			id, request string,
+			a, result interface{},
+		) error {
+			called = true
+			c.Check(objType, gc.Equals, "APIMetrics")
+			c.Check(id, gc.Equals, "")
+			c.Check(request, gc.Equals, "SlowCalls")
+			c.Check(a, gc.IsNil)
+			c.Assert(result, gc.FitsTypeOf, &params.SlowCallsResult{})
+			*(result.(*params.SlowCallsResult)) = params.SlowCallsResult{Calls: calls}
+			return nil
+		},
+	)
+	result, err := apimetrics.NewClient(apiCaller).SlowCalls()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(called, jc.IsTrue)
+	c.Assert(result, jc.DeepEquals, calls)
+}
+
+func (s *clientSuite) TestSlowCallsError(c *gc.C) {
+	apiCaller := basetesting.APICallerFunc(
+		func(objType string,
+			version int,
+			id, request string,
+			a, result interface{},
+		) error {
+			return errors.New("boom")
+		},
+	)
+	_, err := apimetrics.NewClient(apiCaller).SlowCalls()
+	c.Assert(err, gc.ErrorMatches, "boom")
+}
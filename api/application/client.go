@@ -265,6 +265,32 @@ func (c *Client) Unexpose(application string) error {
 	return c.facade.FacadeCall("Unexpose", params, nil)
 }
 
+// Rename renames the application, along with its units and relations.
+func (c *Client) Rename(application, newName string) error {
+	params := params.ApplicationRename{
+		ApplicationName: application,
+		NewName:         newName,
+	}
+	return c.facade.FacadeCall("Rename", params, nil)
+}
+
+// SetScale sets the number of units the application is expected to
+// have. Units will be added or destroyed as necessary to match it.
+func (c *Client) SetScale(application string, scale int) error {
+	params := params.ApplicationSetScale{
+		ApplicationName: application,
+		Scale:           &scale,
+	}
+	return c.facade.FacadeCall("SetScale", params, nil)
+}
+
+// UnsetScale clears the application's desired scale, so that units
+// are added and destroyed only on request.
+func (c *Client) UnsetScale(application string) error {
+	params := params.ApplicationSetScale{ApplicationName: application}
+	return c.facade.FacadeCall("SetScale", params, nil)
+}
+
 // Get returns the configuration for the named application.
 func (c *Client) Get(application string) (*params.ApplicationGetResults, error) {
 	var results params.ApplicationGetResults
@@ -138,6 +138,55 @@ func (s *serviceSuite) TestServiceGetCharmURL(c *gc.C) {
 	c.Assert(called, jc.IsTrue)
 }
 
+func (s *serviceSuite) TestRename(c *gc.C) {
+	var called bool
+	application.PatchFacadeCall(s, s.client, func(request string, a, response interface{}) error {
+		called = true
+		c.Assert(request, gc.Equals, "Rename")
+		c.Assert(a, jc.DeepEquals, params.ApplicationRename{
+			ApplicationName: "application",
+			NewName:         "renamed",
+		})
+		return nil
+	})
+	err := s.client.Rename("application", "renamed")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(called, jc.IsTrue)
+}
+
+func (s *serviceSuite) TestSetScale(c *gc.C) {
+	var called bool
+	application.PatchFacadeCall(s, s.client, func(request string, a, response interface{}) error {
+		called = true
+		c.Assert(request, gc.Equals, "SetScale")
+		args, ok := a.(params.ApplicationSetScale)
+		c.Assert(ok, jc.IsTrue)
+		c.Assert(args.ApplicationName, gc.Equals, "application")
+		c.Assert(args.Scale, gc.NotNil)
+		c.Assert(*args.Scale, gc.Equals, 3)
+		return nil
+	})
+	err := s.client.SetScale("application", 3)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(called, jc.IsTrue)
+}
+
+func (s *serviceSuite) TestUnsetScale(c *gc.C) {
+	var called bool
+	application.PatchFacadeCall(s, s.client, func(request string, a, response interface{}) error {
+		called = true
+		c.Assert(request, gc.Equals, "SetScale")
+		args, ok := a.(params.ApplicationSetScale)
+		c.Assert(ok, jc.IsTrue)
+		c.Assert(args.ApplicationName, gc.Equals, "application")
+		c.Assert(args.Scale, gc.IsNil)
+		return nil
+	})
+	err := s.client.UnsetScale("application")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(called, jc.IsTrue)
+}
+
 func (s *serviceSuite) TestServiceSetCharm(c *gc.C) {
 	var called bool
 	toUint64Ptr := func(v uint64) *uint64 {
@@ -48,8 +48,8 @@ func (api *API) Watch() (watcher.StringsWatcher, error) {
 }
 
 // Rescale requests that all supplied service names be rescaled to
-// their minimum configured sizes. It returns the first error it
-// encounters.
+// their minimum configured sizes, and to their desired scales.
+// It returns the first error it encounters.
 func (api *API) Rescale(services []string) error {
 	args := params.Entities{
 		Entities: make([]params.Entity, len(services)),
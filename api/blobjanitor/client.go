@@ -0,0 +1,41 @@
+// Copyright 2016 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package blobjanitor
+
+import (
+	"github.com/juju/errors"
+
+	"github.com/juju/juju/api/base"
+	"github.com/juju/juju/apiserver/params"
+)
+
+// Client provides access to the BlobJanitor API facade.
+type Client struct {
+	facade base.FacadeCaller
+}
+
+// NewClient returns a new Client based on an existing API connection.
+func NewClient(caller base.APICaller) *Client {
+	return &Client{base.NewFacadeCaller(caller, "BlobJanitor")}
+}
+
+// EnforceBucketPolicies removes the model's blobs that are not retained
+// under their bucket's lifecycle policy, returning those removed.
+func (c *Client) EnforceBucketPolicies() ([]params.RemovedBlob, error) {
+	var result params.RemovedBlobsResult
+	if err := c.facade.FacadeCall("EnforceBucketPolicies", nil, &result); err != nil {
+		return nil, errors.Trace(err)
+	}
+	return result.Removed, nil
+}
+
+// BucketUsage returns the number and total length of the blobs held in
+// each of the model's buckets.
+func (c *Client) BucketUsage() ([]params.BucketUsage, error) {
+	var result params.BucketUsageResult
+	if err := c.facade.FacadeCall("BucketUsage", nil, &result); err != nil {
+		return nil, errors.Trace(err)
+	}
+	return result.Usage, nil
+}
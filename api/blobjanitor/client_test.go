@@ -0,0 +1,79 @@
+// Copyright 2016 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package blobjanitor_test
+
+import (
+	"github.com/juju/errors"
+	jujutesting "github.com/juju/testing"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	basetesting "github.com/juju/juju/api/base/testing"
+	"github.com/juju/juju/api/blobjanitor"
+	"github.com/juju/juju/apiserver/params"
+)
+
+type clientSuite struct {
+	jujutesting.IsolationSuite
+}
+
+var _ = gc.Suite(&clientSuite{})
+
+func (s *clientSuite) TestEnforceBucketPolicies(c *gc.C) {
+	apiCaller := basetesting.APICallerFunc(
+		func(objType string,
+			version int,
+			id, request string,
+			a, result interface{},
+		) error {
+			c.Check(objType, gc.Equals, "BlobJanitor")
+			c.Check(id, gc.Equals, "")
+			c.Check(request, gc.Equals, "EnforceBucketPolicies")
+			c.Check(a, gc.IsNil)
+			c.Assert(result, gc.FitsTypeOf, &params.RemovedBlobsResult{})
+			*(result.(*params.RemovedBlobsResult)) = params.RemovedBlobsResult{
+				Removed: []params.RemovedBlob{{Bucket: "charms", Name: "local:xenial/dummy-1"}},
+			}
+			return nil
+		},
+	)
+	removed, err := blobjanitor.NewClient(apiCaller).EnforceBucketPolicies()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(removed, jc.DeepEquals, []params.RemovedBlob{{Bucket: "charms", Name: "local:xenial/dummy-1"}})
+}
+
+func (s *clientSuite) TestBucketUsage(c *gc.C) {
+	apiCaller := basetesting.APICallerFunc(
+		func(objType string,
+			version int,
+			id, request string,
+			a, result interface{},
+		) error {
+			c.Check(objType, gc.Equals, "BlobJanitor")
+			c.Check(request, gc.Equals, "BucketUsage")
+			c.Assert(result, gc.FitsTypeOf, &params.BucketUsageResult{})
+			*(result.(*params.BucketUsageResult)) = params.BucketUsageResult{
+				Usage: []params.BucketUsage{{Bucket: "tools", Blobs: 2, Length: 1024}},
+			}
+			return nil
+		},
+	)
+	usage, err := blobjanitor.NewClient(apiCaller).BucketUsage()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(usage, jc.DeepEquals, []params.BucketUsage{{Bucket: "tools", Blobs: 2, Length: 1024}})
+}
+
+func (s *clientSuite) TestError(c *gc.C) {
+	apiCaller := basetesting.APICallerFunc(
+		func(objType string,
+			version int,
+			id, request string,
+			a, result interface{},
+		) error {
+			return errors.New("boom")
+		},
+	)
+	_, err := blobjanitor.NewClient(apiCaller).BucketUsage()
+	c.Assert(err, gc.ErrorMatches, "boom")
+}
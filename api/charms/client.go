@@ -44,6 +44,10 @@ type CharmInfo struct {
 	Meta     *charm.Meta
 	Actions  *charm.Actions
 	Metrics  *charm.Metrics
+
+	// ScanFindings holds the findings of the scans run over
+	// the charm's archive when it was uploaded.
+	ScanFindings []params.CharmScanFinding
 }
 
 // CharmInfo returns information about the requested charm.
@@ -58,12 +62,13 @@ func (c *Client) CharmInfo(charmURL string) (*CharmInfo, error) {
 		return nil, errors.Trace(err)
 	}
 	result := &CharmInfo{
-		Revision: info.Revision,
-		URL:      info.URL,
-		Config:   convertCharmConfig(info.Config),
-		Meta:     meta,
-		Actions:  convertCharmActions(info.Actions),
-		Metrics:  convertCharmMetrics(info.Metrics),
+		Revision:     info.Revision,
+		URL:          info.URL,
+		Config:       convertCharmConfig(info.Config),
+		Meta:         meta,
+		Actions:      convertCharmActions(info.Actions),
+		Metrics:      convertCharmMetrics(info.Metrics),
+		ScanFindings: info.ScanFindings,
 	}
 	return result, nil
 }
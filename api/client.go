@@ -16,6 +16,7 @@ import (
 
 	"github.com/juju/errors"
 	"github.com/juju/loggo"
+	"github.com/juju/utils"
 	"github.com/juju/version"
 	"golang.org/x/net/websocket"
 	"gopkg.in/juju/charm.v6-unstable"
@@ -41,9 +42,14 @@ type Client struct {
 
 // Status returns the status of the juju model.
 func (c *Client) Status(patterns []string) (*params.FullStatus, error) {
+	return c.FullStatus(params.StatusParams{Patterns: patterns})
+}
+
+// FullStatus returns the status of the juju model, filtered and
+// paginated as specified in args.
+func (c *Client) FullStatus(args params.StatusParams) (*params.FullStatus, error) {
 	var result params.FullStatus
-	p := params.StatusParams{Patterns: patterns}
-	if err := c.facade.FacadeCall("FullStatus", p, &result); err != nil {
+	if err := c.facade.FacadeCall("FullStatus", args, &result); err != nil {
 		return nil, err
 	}
 	return &result, nil
@@ -220,7 +226,32 @@ func (c *Client) WatchAll() (*AllWatcher, error) {
 	if err := c.facade.FacadeCall("WatchAll", nil, &info); err != nil {
 		return nil, err
 	}
-	return NewAllWatcher(c.st, &info.AllWatcherId), nil
+	return newAllWatcherSession(c.st, info), nil
+}
+
+// ResumeWatchAll resumes the AllWatcher session with the given token,
+// started on an earlier connection. The resumed watcher's Next method
+// will first return any deltas after the given sequence number. An
+// error satisfying params.IsCodeNotFound is returned if the session
+// cannot be resumed, in which case a new watcher should be started.
+func (c *Client) ResumeWatchAll(sessionToken string, sequence int64) (*AllWatcher, error) {
+	args := params.ResumeAllWatcher{
+		SessionToken: sessionToken,
+		Sequence:     sequence,
+	}
+	var info params.AllWatcherId
+	if err := c.facade.FacadeCall("ResumeWatchAll", args, &info); err != nil {
+		return nil, err
+	}
+	w := newAllWatcherSession(c.st, info)
+	w.sequence = sequence
+	return w, nil
+}
+
+func newAllWatcherSession(caller base.APICaller, info params.AllWatcherId) *AllWatcher {
+	w := NewAllWatcher(caller, &info.AllWatcherId)
+	w.sessionToken = info.SessionToken
+	return w
 }
 
 // Close closes the Client's underlying State connection
@@ -303,7 +334,12 @@ func (c *Client) AddLocalCharm(curl *charm.URL, ch charm.Charm) (*charm.URL, err
 
 // UploadCharm sends the content to the API server using an HTTP post.
 func (c *Client) UploadCharm(curl *charm.URL, content io.ReadSeeker) (*charm.URL, error) {
+	hash, err := readSeekerSHA256(content)
+	if err != nil {
+		return nil, errors.Annotate(err, "cannot compute charm hash")
+	}
 	args := url.Values{}
+	args.Add("sha256", hash)
 	args.Add("series", curl.Series)
 	args.Add("schema", curl.Schema)
 	args.Add("revision", strconv.Itoa(curl.Revision))
@@ -315,7 +351,7 @@ func (c *Client) UploadCharm(curl *charm.URL, content io.ReadSeeker) (*charm.URL
 		return nil, errors.Trace(err)
 	}
 
-	curl, err := charm.ParseURL(resp.CharmURL)
+	curl, err = charm.ParseURL(resp.CharmURL)
 	if err != nil {
 		return nil, errors.Annotatef(err, "bad charm URL in response")
 	}
@@ -455,8 +491,26 @@ func NewCharmDownloader(client *Client) *downloader.Downloader {
 }
 
 // UploadTools uploads tools at the specified location to the API server over HTTPS.
+// The tools are published in the released stream.
 func (c *Client) UploadTools(r io.ReadSeeker, vers version.Binary, additionalSeries ...string) (tools.List, error) {
-	endpoint := fmt.Sprintf("/tools?binaryVersion=%s&series=%s", vers, strings.Join(additionalSeries, ","))
+	return c.UploadToolsToStream(r, vers, "", additionalSeries...)
+}
+
+// UploadToolsToStream uploads tools at the specified location to the API server
+// over HTTPS, publishing them in the specified stream, e.g. "proposed". If the
+// stream is empty, the tools are published in the released stream.
+func (c *Client) UploadToolsToStream(r io.ReadSeeker, vers version.Binary, stream string, additionalSeries ...string) (tools.List, error) {
+	hash, err := readSeekerSHA256(r)
+	if err != nil {
+		return nil, errors.Annotate(err, "cannot compute tools hash")
+	}
+	endpoint := fmt.Sprintf(
+		"/tools?binaryVersion=%s&series=%s&sha256=%s",
+		vers, strings.Join(additionalSeries, ","), hash,
+	)
+	if stream != "" {
+		endpoint += "&stream=" + url.QueryEscape(stream)
+	}
 	contentType := "application/x-tar-gz"
 	var resp params.ToolsResult
 	if err := c.httpPost(r, endpoint, contentType, &resp); err != nil {
@@ -465,6 +519,20 @@ func (c *Client) UploadTools(r io.ReadSeeker, vers version.Binary, additionalSer
 	return resp.ToolsList, nil
 }
 
+// readSeekerSHA256 returns the hex-encoded SHA-256 hash of the
+// content of r, and rewinds r so that the content may be uploaded.
+// The server verifies uploaded content against the hash.
+func readSeekerSHA256(r io.ReadSeeker) (string, error) {
+	hash, _, err := utils.ReadSHA256(r)
+	if err != nil {
+		return "", errors.Trace(err)
+	}
+	if _, err := r.Seek(0, 0); err != nil {
+		return "", errors.Trace(err)
+	}
+	return hash, nil
+}
+
 func (c *Client) httpPost(content io.ReadSeeker, endpoint, contentType string, response interface{}) error {
 	req, err := http.NewRequest("POST", endpoint, nil)
 	if err != nil {
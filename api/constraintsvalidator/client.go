@@ -0,0 +1,47 @@
+// Copyright 2016 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package constraintsvalidator
+
+import (
+	"github.com/juju/errors"
+
+	"github.com/juju/juju/api/base"
+	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/constraints"
+)
+
+// Client provides access to the ConstraintsValidator API facade.
+type Client struct {
+	base.ClientFacade
+	facade base.FacadeCaller
+}
+
+// NewClient returns a new Client based on an existing API connection.
+func NewClient(st base.APICallCloser) *Client {
+	frontend, backend := base.NewClientFacade(st, "ConstraintsValidator")
+	return &Client{ClientFacade: frontend, facade: backend}
+}
+
+// Vocabulary returns the constraint values accepted by the model.
+func (c *Client) Vocabulary() (params.ConstraintsVocabulary, error) {
+	var result params.ConstraintsVocabulary
+	if err := c.facade.FacadeCall("Vocabulary", nil, &result); err != nil {
+		return params.ConstraintsVocabulary{}, errors.Trace(err)
+	}
+	return result, nil
+}
+
+// ValidateConstraints validates the given constraints values against
+// the model's constraints vocabulary, returning a result for each.
+func (c *Client) ValidateConstraints(cons ...constraints.Value) ([]params.ValidateConstraintsResult, error) {
+	args := params.ValidateConstraintsArgs{Constraints: cons}
+	var results params.ValidateConstraintsResults
+	if err := c.facade.FacadeCall("ValidateConstraints", args, &results); err != nil {
+		return nil, errors.Trace(err)
+	}
+	if len(results.Results) != len(cons) {
+		return nil, errors.Errorf("expected %d result(s), got %d", len(cons), len(results.Results))
+	}
+	return results.Results, nil
+}
@@ -0,0 +1,113 @@
+// Copyright 2016 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package constraintsvalidator_test
+
+import (
+	"github.com/juju/errors"
+	jujutesting "github.com/juju/testing"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	basetesting "github.com/juju/juju/api/base/testing"
+	"github.com/juju/juju/api/constraintsvalidator"
+	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/constraints"
+)
+
+type clientSuite struct {
+	jujutesting.IsolationSuite
+}
+
+var _ = gc.Suite(&clientSuite{})
+
+func (s *clientSuite) TestVocabulary(c *gc.C) {
+	expected := params.ConstraintsVocabulary{
+		Vocabulary: map[string][]interface{}{
+			"instance-type": {"Standard_A1"},
+		},
+		Conflicts: map[string][]string{
+			"instance-type": {"mem"},
+		},
+		Unsupported: []string{"cpu-power"},
+	}
+	apiCaller := basetesting.APICallerFunc(
+		func(objType string,
+			version int,
+			id, request string,
+			a, result interface{},
+		) error {
+			c.Check(objType, gc.Equals, "ConstraintsValidator")
+			c.Check(id, gc.Equals, "")
+			c.Check(request, gc.Equals, "Vocabulary")
+			c.Check(a, gc.IsNil)
+			c.Assert(result, gc.FitsTypeOf, &params.ConstraintsVocabulary{})
+			*(result.(*params.ConstraintsVocabulary)) = expected
+			return nil
+		},
+	)
+	client := constraintsvalidator.NewClient(apiCaller)
+	vocab, err := client.Vocabulary()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(vocab, jc.DeepEquals, expected)
+}
+
+func (s *clientSuite) TestVocabularyError(c *gc.C) {
+	apiCaller := basetesting.APICallerFunc(
+		func(objType string,
+			version int,
+			id, request string,
+			a, result interface{},
+		) error {
+			return errors.New("boom")
+		},
+	)
+	client := constraintsvalidator.NewClient(apiCaller)
+	_, err := client.Vocabulary()
+	c.Assert(err, gc.ErrorMatches, "boom")
+}
+
+func (s *clientSuite) TestValidateConstraints(c *gc.C) {
+	cons := constraints.MustParse("instance-type=Standard_A1")
+	apiCaller := basetesting.APICallerFunc(
+		func(objType string,
+			version int,
+			id, request string,
+			a, result interface{},
+		) error {
+			c.Check(objType, gc.Equals, "ConstraintsValidator")
+			c.Check(request, gc.Equals, "ValidateConstraints")
+			c.Check(a, jc.DeepEquals, params.ValidateConstraintsArgs{
+				Constraints: []constraints.Value{cons},
+			})
+			c.Assert(result, gc.FitsTypeOf, &params.ValidateConstraintsResults{})
+			*(result.(*params.ValidateConstraintsResults)) = params.ValidateConstraintsResults{
+				Results: []params.ValidateConstraintsResult{{
+					Unsupported: []string{"cpu-power"},
+				}},
+			}
+			return nil
+		},
+	)
+	client := constraintsvalidator.NewClient(apiCaller)
+	results, err := client.ValidateConstraints(cons)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(results, jc.DeepEquals, []params.ValidateConstraintsResult{{
+		Unsupported: []string{"cpu-power"},
+	}})
+}
+
+func (s *clientSuite) TestValidateConstraintsResultCount(c *gc.C) {
+	apiCaller := basetesting.APICallerFunc(
+		func(objType string,
+			version int,
+			id, request string,
+			a, result interface{},
+		) error {
+			return nil
+		},
+	)
+	client := constraintsvalidator.NewClient(apiCaller)
+	_, err := client.ValidateConstraints(constraints.Value{})
+	c.Assert(err, gc.ErrorMatches, `expected 1 result\(s\), got 0`)
+}
@@ -146,6 +146,104 @@ func (c *Client) RemoveBlocks() error {
 	return c.facade.FacadeCall("RemoveBlocks", args, nil)
 }
 
+// MigrateBlobBackend starts moving the content of the controller's
+// blobs to the backend with the given attributes. The blobs are moved
+// in the background by the controller.
+func (c *Client) MigrateBlobBackend(backend map[string]string) error {
+	args := params.MigrateBlobBackendArgs{Backend: backend}
+	return errors.Trace(c.facade.FacadeCall("MigrateBlobBackend", args, nil))
+}
+
+// SetBucketPolicies sets the lifecycle policies of the specified blob
+// buckets.
+func (c *Client) SetBucketPolicies(policies ...params.BucketPolicy) error {
+	args := params.BucketPolicies{Policies: policies}
+	var results params.ErrorResults
+	if err := c.facade.FacadeCall("SetBucketPolicies", args, &results); err != nil {
+		return errors.Trace(err)
+	}
+	return results.Combine()
+}
+
+// BucketPolicies returns the lifecycle policies of the blob buckets
+// that have them.
+func (c *Client) BucketPolicies() ([]params.BucketPolicy, error) {
+	var result params.BucketPolicies
+	if err := c.facade.FacadeCall("BucketPolicies", nil, &result); err != nil {
+		return nil, errors.Trace(err)
+	}
+	return result.Policies, nil
+}
+
+// RotateStorageCredentials replaces the credentials that the specified
+// models use to access their providers' storage.
+func (c *Client) RotateStorageCredentials(models ...names.ModelTag) error {
+	args := params.Entities{Entities: make([]params.Entity, len(models))}
+	for i, model := range models {
+		args.Entities[i].Tag = model.String()
+	}
+	var results params.ErrorResults
+	if err := c.facade.FacadeCall("RotateStorageCredentials", args, &results); err != nil {
+		return errors.Trace(err)
+	}
+	return results.Combine()
+}
+
+// CheckIntegrity checks the referential integrity of the documents in
+// each model in the controller, including the unit and relation counts
+// recorded for applications, and reports the problems found along with
+// the transactions, if any, that would repair them.
+func (c *Client) CheckIntegrity() ([]params.IntegrityReport, error) {
+	var results params.IntegrityReports
+	if err := c.facade.FacadeCall("CheckIntegrity", nil, &results); err != nil {
+		return nil, errors.Trace(err)
+	}
+	return results.Results, nil
+}
+
+// ControllerHealth returns the results of the checks that the
+// controller's API server runs against itself.
+func (c *Client) ControllerHealth() (params.ControllerHealth, error) {
+	var result params.ControllerHealth
+	if err := c.facade.FacadeCall("ControllerHealth", nil, &result); err != nil {
+		return result, errors.Trace(err)
+	}
+	return result, nil
+}
+
+// RepairIntegrity applies the suggested repairs of the integrity
+// problems in the specified model with the given IDs, as reported
+// by CheckIntegrity, and returns an error for each problem.
+func (c *Client) RepairIntegrity(model names.ModelTag, problemIDs ...string) ([]error, error) {
+	args := params.RepairIntegrityArgs{
+		Args: []params.RepairIntegrityArg{{
+			ModelTag:   model.String(),
+			ProblemIDs: problemIDs,
+		}},
+	}
+	var results params.RepairIntegrityResults
+	if err := c.facade.FacadeCall("RepairIntegrity", args, &results); err != nil {
+		return nil, errors.Trace(err)
+	}
+	if n := len(results.Results); n != 1 {
+		return nil, errors.Errorf("expected 1 result, got %d", n)
+	}
+	result := results.Results[0]
+	if result.Error != nil {
+		return nil, errors.Trace(result.Error)
+	}
+	if n := len(result.Results); n != len(problemIDs) {
+		return nil, errors.Errorf("expected %d results, got %d", len(problemIDs), n)
+	}
+	errs := make([]error, len(result.Results))
+	for i, result := range result.Results {
+		if result.Error != nil {
+			errs[i] = result.Error
+		}
+	}
+	return errs, nil
+}
+
 // WatchAllModels returns an AllWatcher, from which you can request
 // the Next collection of Deltas (for all models).
 func (c *Client) WatchAllModels() (*api.AllWatcher, error) {
@@ -153,7 +251,23 @@ func (c *Client) WatchAllModels() (*api.AllWatcher, error) {
 	if err := c.facade.FacadeCall("WatchAllModels", nil, &info); err != nil {
 		return nil, err
 	}
-	return api.NewAllModelWatcher(c.facade.RawAPICaller(), &info.AllWatcherId), nil
+	return api.NewAllModelWatcherSession(c.facade.RawAPICaller(), info, 0), nil
+}
+
+// ResumeWatchAllModels resumes the AllModelWatcher session with the
+// given token, started on an earlier connection. The resumed watcher's
+// Next method will first return any deltas after the given sequence
+// number.
+func (c *Client) ResumeWatchAllModels(sessionToken string, sequence int64) (*api.AllWatcher, error) {
+	args := params.ResumeAllWatcher{
+		SessionToken: sessionToken,
+		Sequence:     sequence,
+	}
+	var info params.AllWatcherId
+	if err := c.facade.FacadeCall("ResumeWatchAllModels", args, &info); err != nil {
+		return nil, err
+	}
+	return api.NewAllModelWatcherSession(c.facade.RawAPICaller(), info, sequence), nil
 }
 
 // ModelStatus returns a status summary for each model tag passed in.
@@ -194,6 +194,168 @@ func (s *Suite) TestHostedModelConfigs_FormatResults(c *gc.C) {
 	c.Assert(third.Error.Error(), gc.Equals, "validating CloudSpec: empty Type not valid")
 }
 
+func (s *Suite) TestCheckIntegrity(c *gc.C) {
+	reports := []params.IntegrityReport{{
+		ModelTag: "model-deadbeef-0bad-400d-8000-4b1d0d06f00d",
+		Problems: []params.IntegrityProblem{{
+			ID:          "dangling-reference:units/wordpress/0:machineid",
+			Kind:        "dangling-reference",
+			Collection:  "units",
+			DocID:       "wordpress/0",
+			Description: `unit "wordpress/0" is assigned to missing machine "42"`,
+		}},
+	}}
+	apiCaller := apitesting.APICallerFunc(func(objType string, version int, id, request string, arg, result interface{}) error {
+		c.Check(objType, gc.Equals, "Controller")
+		c.Check(request, gc.Equals, "CheckIntegrity")
+		c.Check(arg, gc.IsNil)
+		c.Assert(result, gc.FitsTypeOf, &params.IntegrityReports{})
+		*(result.(*params.IntegrityReports)) = params.IntegrityReports{Results: reports}
+		return nil
+	})
+	client := controller.NewClient(apiCaller)
+	results, err := client.CheckIntegrity()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(results, jc.DeepEquals, reports)
+}
+
+func (s *Suite) TestControllerHealth(c *gc.C) {
+	apiCaller := apitesting.APICallerFunc(func(objType string, version int, id, request string, arg, result interface{}) error {
+		c.Check(objType, gc.Equals, "Controller")
+		c.Check(request, gc.Equals, "ControllerHealth")
+		c.Check(arg, gc.IsNil)
+		c.Assert(result, gc.FitsTypeOf, &params.ControllerHealth{})
+		*(result.(*params.ControllerHealth)) = params.ControllerHealth{
+			FreeDiskSpace:       2048,
+			ControllerInstances: []string{"inst-0"},
+		}
+		return nil
+	})
+	client := controller.NewClient(apiCaller)
+	result, err := client.ControllerHealth()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(result, jc.DeepEquals, params.ControllerHealth{
+		FreeDiskSpace:       2048,
+		ControllerInstances: []string{"inst-0"},
+	})
+}
+
+func (s *Suite) TestCheckIntegrityError(c *gc.C) {
+	apiCaller := apitesting.APICallerFunc(func(string, int, string, string, interface{}, interface{}) error {
+		return errors.New("boom")
+	})
+	client := controller.NewClient(apiCaller)
+	results, err := client.CheckIntegrity()
+	c.Check(results, gc.HasLen, 0)
+	c.Check(err, gc.ErrorMatches, "boom")
+}
+
+func (s *Suite) TestRepairIntegrity(c *gc.C) {
+	apiCaller := apitesting.APICallerFunc(func(objType string, version int, id, request string, arg, result interface{}) error {
+		c.Check(objType, gc.Equals, "Controller")
+		c.Check(request, gc.Equals, "RepairIntegrity")
+		c.Check(arg, jc.DeepEquals, params.RepairIntegrityArgs{
+			Args: []params.RepairIntegrityArg{{
+				ModelTag:   "model-deadbeef-0bad-400d-8000-4b1d0d06f00d",
+				ProblemIDs: []string{"a", "b"},
+			}},
+		})
+		c.Assert(result, gc.FitsTypeOf, &params.RepairIntegrityResults{})
+		*(result.(*params.RepairIntegrityResults)) = params.RepairIntegrityResults{
+			Results: []params.RepairIntegrityResult{{
+				Results: []params.ErrorResult{{}, {Error: &params.Error{Message: "boom"}}},
+			}},
+		}
+		return nil
+	})
+	client := controller.NewClient(apiCaller)
+	errs, err := client.RepairIntegrity(names.NewModelTag("deadbeef-0bad-400d-8000-4b1d0d06f00d"), "a", "b")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(errs, gc.HasLen, 2)
+	c.Assert(errs[0], jc.ErrorIsNil)
+	c.Assert(errs[1], gc.ErrorMatches, "boom")
+}
+
+func (s *Suite) TestRepairIntegrityModelError(c *gc.C) {
+	apiCaller := apitesting.APICallerFunc(func(objType string, version int, id, request string, arg, result interface{}) error {
+		*(result.(*params.RepairIntegrityResults)) = params.RepairIntegrityResults{
+			Results: []params.RepairIntegrityResult{{
+				Error: &params.Error{Message: "model not found", Code: params.CodeNotFound},
+			}},
+		}
+		return nil
+	})
+	client := controller.NewClient(apiCaller)
+	errs, err := client.RepairIntegrity(names.NewModelTag("deadbeef-0bad-400d-8000-4b1d0d06f00d"), "a")
+	c.Assert(errs, gc.HasLen, 0)
+	c.Assert(err, gc.ErrorMatches, "model not found")
+	c.Assert(err, jc.Satisfies, params.IsCodeNotFound)
+}
+
+func (s *Suite) TestMigrateBlobBackend(c *gc.C) {
+	backend := map[string]string{"type": "filesystem", "directory": "/var/lib/juju/blobs"}
+	apiCaller := apitesting.APICallerFunc(func(objType string, version int, id, request string, arg, result interface{}) error {
+		c.Check(objType, gc.Equals, "Controller")
+		c.Check(request, gc.Equals, "MigrateBlobBackend")
+		c.Check(arg, jc.DeepEquals, params.MigrateBlobBackendArgs{Backend: backend})
+		c.Check(result, gc.IsNil)
+		return nil
+	})
+	client := controller.NewClient(apiCaller)
+	err := client.MigrateBlobBackend(backend)
+	c.Assert(err, jc.ErrorIsNil)
+}
+
+func (s *Suite) TestSetBucketPolicies(c *gc.C) {
+	policy := params.BucketPolicy{Bucket: "charms", MaxVersions: 3}
+	apiCaller := apitesting.APICallerFunc(func(objType string, version int, id, request string, arg, result interface{}) error {
+		c.Check(objType, gc.Equals, "Controller")
+		c.Check(request, gc.Equals, "SetBucketPolicies")
+		c.Check(arg, jc.DeepEquals, params.BucketPolicies{Policies: []params.BucketPolicy{policy}})
+		c.Assert(result, gc.FitsTypeOf, &params.ErrorResults{})
+		*(result.(*params.ErrorResults)) = params.ErrorResults{
+			Results: []params.ErrorResult{{Error: &params.Error{Message: "boom"}}},
+		}
+		return nil
+	})
+	client := controller.NewClient(apiCaller)
+	err := client.SetBucketPolicies(policy)
+	c.Assert(err, gc.ErrorMatches, "boom")
+}
+
+func (s *Suite) TestBucketPolicies(c *gc.C) {
+	policies := []params.BucketPolicy{{Bucket: "charms", MaxVersions: 3}}
+	apiCaller := apitesting.APICallerFunc(func(objType string, version int, id, request string, arg, result interface{}) error {
+		c.Check(objType, gc.Equals, "Controller")
+		c.Check(request, gc.Equals, "BucketPolicies")
+		c.Assert(result, gc.FitsTypeOf, &params.BucketPolicies{})
+		*(result.(*params.BucketPolicies)) = params.BucketPolicies{Policies: policies}
+		return nil
+	})
+	client := controller.NewClient(apiCaller)
+	result, err := client.BucketPolicies()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(result, jc.DeepEquals, policies)
+}
+
+func (s *Suite) TestRotateStorageCredentials(c *gc.C) {
+	apiCaller := apitesting.APICallerFunc(func(objType string, version int, id, request string, arg, result interface{}) error {
+		c.Check(objType, gc.Equals, "Controller")
+		c.Check(request, gc.Equals, "RotateStorageCredentials")
+		c.Check(arg, jc.DeepEquals, params.Entities{
+			Entities: []params.Entity{{Tag: "model-deadbeef-0bad-400d-8000-4b1d0d06f00d"}},
+		})
+		c.Assert(result, gc.FitsTypeOf, &params.ErrorResults{})
+		*(result.(*params.ErrorResults)) = params.ErrorResults{
+			Results: []params.ErrorResult{{Error: &params.Error{Message: "boom"}}},
+		}
+		return nil
+	})
+	client := controller.NewClient(apiCaller)
+	err := client.RotateStorageCredentials(names.NewModelTag("deadbeef-0bad-400d-8000-4b1d0d06f00d"))
+	c.Assert(err, gc.ErrorMatches, "boom")
+}
+
 func makeClient(results params.InitiateMigrationResults) (
 	*controller.Client, *jujutesting.Stub,
 ) {
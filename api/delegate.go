@@ -0,0 +1,53 @@
+// Copyright 2016 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package api
+
+import (
+	"github.com/juju/errors"
+	"gopkg.in/juju/names.v2"
+
+	"github.com/juju/juju/api/base"
+	"github.com/juju/juju/rpc"
+)
+
+// NewDelegatedAPICaller returns a base.APICaller that makes API calls
+// over the given connection on behalf of the entity with the given tag.
+// The API server authorizes the calls as though the entity had made
+// them, provided the connection's own entity is permitted to act for
+// it; currently machine agents may act for the units assigned to their
+// machines. An error satisfying errors.IsNotSupported is returned if
+// the server did not report, on login, that the connection's entity may
+// make requests on behalf of others; servers that predate delegation
+// would otherwise ignore the request's delegate and make it as the
+// connection's entity.
+//
+// Only RPC calls are delegated: HTTP requests and streams made through
+// the returned APICaller are still authenticated as the connection's
+// own entity.
+func NewDelegatedAPICaller(conn Connection, tag names.Tag) (base.APICaller, error) {
+	st, ok := conn.(*state)
+	if !ok {
+		return nil, errors.NotSupportedf("delegating API calls over %T", conn)
+	}
+	if !st.canDelegate {
+		return nil, errors.NotSupportedf("delegating API calls as %s", st.authTag)
+	}
+	return &delegatedAPICaller{state: st, tag: tag}, nil
+}
+
+type delegatedAPICaller struct {
+	*state
+	tag names.Tag
+}
+
+// APICall is part of the base.APICaller interface.
+func (d *delegatedAPICaller) APICall(facade string, version int, id, method string, args, response interface{}) error {
+	return d.call(rpc.Request{
+		Type:       facade,
+		Version:    version,
+		Id:         id,
+		Action:     method,
+		OnBehalfOf: d.tag.String(),
+	}, args, response)
+}
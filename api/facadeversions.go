@@ -17,22 +17,25 @@ var facadeVersions = map[string]int{
 	"AllModelWatcher":              2,
 	"AllWatcher":                   1,
 	"Annotations":                  2,
+	"APIMetrics":                   1,
 	"Application":                  2,
 	"ApplicationScaler":            1,
 	"Backups":                      1,
+	"BlobJanitor":                  1,
 	"Block":                        2,
 	"CharmRevisionUpdater":         2,
 	"Charms":                       2,
 	"Cleaner":                      2,
 	"Client":                       1,
 	"Cloud":                        1,
-	"Controller":                   3,
+	"ConstraintsValidator":         1,
+	"Controller":                   4,
 	"Deployer":                     1,
 	"DiscoverSpaces":               2,
 	"DiskManager":                  2,
 	"EntityWatcher":                2,
 	"FilesystemAttachmentsWatcher": 2,
-	"Firewaller":                   3,
+	"Firewaller":                   4,
 	"HighAvailability":             2,
 	"HostKeyReporter":              1,
 	"ImageManager":                 2,
@@ -46,6 +49,7 @@ var facadeVersions = map[string]int{
 	"Logger":                       1,
 	"MachineActions":               1,
 	"MachineManager":               2,
+	"MachinePower":                 1,
 	"MachineUndertaker":            1,
 	"Machiner":                     1,
 	"MeterStatus":                  1,
@@ -63,12 +67,15 @@ var facadeVersions = map[string]int{
 	"Payloads":                     1,
 	"PayloadsHookContext":          1,
 	"Pinger":                       1,
+	"ProviderResources":            1,
 	"Provisioner":                  3,
 	"ProxyUpdater":                 1,
 	"Reboot":                       2,
+	"RelationScopeGC":              1,
 	"RelationUnitsWatcher":         1,
 	"Resources":                    1,
 	"ResourcesHookContext":         1,
+	"ResourceSweeper":              1,
 	"Resumer":                      2,
 	"RetryStrategy":                1,
 	"Singular":                     1,
@@ -79,6 +86,9 @@ var facadeVersions = map[string]int{
 	"StorageProvisioner":           3,
 	"StringsWatcher":               1,
 	"Subnets":                      2,
+	"ToolsGC":                      1,
+	"ToolsMirror":                  1,
+	"ToolsStreams":                 1,
 	"Undertaker":                   1,
 	"UnitAssigner":                 1,
 	"Uniter":                       4,
@@ -117,3 +117,29 @@ func (st *State) WatchOpenedPorts() (watcher.StringsWatcher, error) {
 	w := apiwatcher.NewStringsWatcher(st.facade.RawAPICaller(), result)
 	return w, nil
 }
+
+// WatchExposureIntents returns a StringsWatcher that notifies of
+// changes to the exposure intents of applications in the current
+// model. The names of the affected applications are reported.
+func (st *State) WatchExposureIntents() (watcher.StringsWatcher, error) {
+	modelTag, ok := st.ModelTag()
+	if !ok {
+		return nil, errors.New("API connection is controller-only (should never happen)")
+	}
+	var results params.StringsWatchResults
+	args := params.Entities{
+		Entities: []params.Entity{{Tag: modelTag.String()}},
+	}
+	if err := st.facade.FacadeCall("WatchExposureIntents", args, &results); err != nil {
+		return nil, err
+	}
+	if len(results.Results) != 1 {
+		return nil, errors.Errorf("expected 1 result, got %d", len(results.Results))
+	}
+	result := results.Results[0]
+	if err := result.Error; err != nil {
+		return nil, result.Error
+	}
+	w := apiwatcher.NewStringsWatcher(st.facade.RawAPICaller(), result)
+	return w, nil
+}
@@ -10,6 +10,7 @@ import (
 
 	"github.com/juju/juju/api/common"
 	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/network"
 	"github.com/juju/juju/watcher"
 )
 
@@ -75,3 +76,47 @@ func (s *Application) IsExposed() (bool, error) {
 	}
 	return result.Result, nil
 }
+
+// ExposureIntent holds the exposure intent recorded for an application:
+// whether it should be exposed, and the port ranges opened by each of
+// its units that should be made accessible when it is.
+type ExposureIntent struct {
+	Exposed    bool
+	PortRanges map[names.UnitTag][]network.PortRange
+}
+
+// ExposureIntent returns the exposure intent recorded for the
+// application. If the application has not been exposed since exposure
+// intents were introduced, an error satisfying params.IsCodeNotFound
+// is returned.
+func (s *Application) ExposureIntent() (ExposureIntent, error) {
+	var results params.ExposureIntentResults
+	args := params.Entities{
+		Entities: []params.Entity{{Tag: s.tag.String()}},
+	}
+	err := s.st.facade.FacadeCall("GetExposureIntents", args, &results)
+	if err != nil {
+		return ExposureIntent{}, err
+	}
+	if len(results.Results) != 1 {
+		return ExposureIntent{}, fmt.Errorf("expected 1 result, got %d", len(results.Results))
+	}
+	result := results.Results[0]
+	if result.Error != nil {
+		return ExposureIntent{}, result.Error
+	}
+	intent := ExposureIntent{
+		Exposed:    result.Result.Exposed,
+		PortRanges: make(map[names.UnitTag][]network.PortRange),
+	}
+	for _, portRange := range result.Result.PortRanges {
+		unitTag, err := names.ParseUnitTag(portRange.UnitTag)
+		if err != nil {
+			return ExposureIntent{}, err
+		}
+		intent.PortRanges[unitTag] = append(
+			intent.PortRanges[unitTag], portRange.PortRange.NetworkPortRange(),
+		)
+	}
+	return intent, nil
+}
@@ -10,6 +10,7 @@ import (
 
 	"github.com/juju/juju/api/firewaller"
 	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/network"
 	"github.com/juju/juju/watcher/watchertest"
 )
 
@@ -91,3 +92,22 @@ func (s *serviceSuite) TestIsExposed(c *gc.C) {
 	c.Assert(err, jc.ErrorIsNil)
 	c.Assert(isExposed, jc.IsFalse)
 }
+
+func (s *serviceSuite) TestExposureIntent(c *gc.C) {
+	_, err := s.apiApplication.ExposureIntent()
+	c.Assert(err, jc.Satisfies, params.IsCodeNotFound)
+
+	err = s.units[0].OpenPort("tcp", 80)
+	c.Assert(err, jc.ErrorIsNil)
+	err = s.application.SetExposed()
+	c.Assert(err, jc.ErrorIsNil)
+
+	intent, err := s.apiApplication.ExposureIntent()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(intent, jc.DeepEquals, firewaller.ExposureIntent{
+		Exposed: true,
+		PortRanges: map[names.UnitTag][]network.PortRange{
+			s.units[0].UnitTag(): {{FromPort: 80, ToPort: 80, Protocol: "tcp"}},
+		},
+	})
+}
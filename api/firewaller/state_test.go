@@ -100,3 +100,25 @@ func (s *stateSuite) TestWatchOpenedPorts(c *gc.C) {
 	wc.AssertChange("1:")
 	wc.AssertNoChange()
 }
+
+func (s *stateSuite) TestWatchExposureIntents(c *gc.C) {
+	w, err := s.firewaller.WatchExposureIntents()
+	c.Assert(err, jc.ErrorIsNil)
+	wc := watchertest.NewStringsWatcherC(c, w, s.BackingState.StartSync)
+	defer wc.AssertStops()
+
+	// No application has been exposed yet.
+	wc.AssertChange()
+	wc.AssertNoChange()
+
+	err = s.application.SetExposed()
+	c.Assert(err, jc.ErrorIsNil)
+	wc.AssertChange(s.application.Name())
+	wc.AssertNoChange()
+
+	// Ports opened after exposure are recorded in the intent.
+	err = s.units[0].OpenPorts("tcp", 1234, 1400)
+	c.Assert(err, jc.ErrorIsNil)
+	wc.AssertChange(s.application.Name())
+	wc.AssertNoChange()
+}
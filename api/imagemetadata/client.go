@@ -63,6 +63,28 @@ func (c *Client) Save(metadata []params.CloudImageMetadata) error {
 	return nil
 }
 
+// SetModelImages saves specified image metadata as specific to the
+// model the client is connected to. Such metadata is used in
+// preference to metadata shared by all models when provisioning
+// machines in the model.
+func (c *Client) SetModelImages(metadata []params.CloudImageMetadata) error {
+	in := params.MetadataSaveParams{
+		Metadata: []params.CloudImageMetadataList{{metadata}},
+	}
+	out := params.ErrorResults{}
+	err := c.facade.FacadeCall("SetModelImages", in, &out)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	if len(out.Results) != 1 {
+		return errors.Errorf("expected 1 result, got %d", len(out.Results))
+	}
+	if out.Results[0].Error != nil {
+		return errors.Trace(out.Results[0].Error)
+	}
+	return nil
+}
+
 // UpdateFromPublishedImages retrieves currently published image metadata and
 // updates stored ones accordingly.
 // This method is primarily intended for a worker.
@@ -203,6 +203,62 @@ func (s *imagemetadataSuite) TestSaveFacadeCallErrorResult(c *gc.C) {
 	c.Assert(errors.Cause(err), gc.ErrorMatches, msg)
 }
 
+func (s *imagemetadataSuite) TestSetModelImages(c *gc.C) {
+	m := params.CloudImageMetadata{ImageId: "image-id"}
+	called := false
+
+	apiCaller := testing.APICallerFunc(
+		func(objType string,
+			version int,
+			id, request string,
+			a, result interface{},
+		) error {
+			called = true
+			c.Check(objType, gc.Equals, "ImageMetadata")
+			c.Check(id, gc.Equals, "")
+			c.Check(request, gc.Equals, "SetModelImages")
+
+			c.Assert(a, jc.DeepEquals, params.MetadataSaveParams{
+				Metadata: []params.CloudImageMetadataList{
+					{[]params.CloudImageMetadata{m}},
+				},
+			})
+
+			c.Assert(result, gc.FitsTypeOf, &params.ErrorResults{})
+			*(result.(*params.ErrorResults)) = params.ErrorResults{
+				Results: []params.ErrorResult{{}},
+			}
+
+			return nil
+		})
+
+	client := imagemetadata.NewClient(apiCaller)
+	err := client.SetModelImages([]params.CloudImageMetadata{m})
+	c.Check(err, jc.ErrorIsNil)
+	c.Assert(called, jc.IsTrue)
+}
+
+func (s *imagemetadataSuite) TestSetModelImagesFacadeCallErrorResult(c *gc.C) {
+	msg := "permission denied"
+	apiCaller := testing.APICallerFunc(
+		func(objType string,
+			version int,
+			id, request string,
+			a, result interface{},
+		) error {
+			c.Check(request, gc.Equals, "SetModelImages")
+			*(result.(*params.ErrorResults)) = params.ErrorResults{
+				Results: []params.ErrorResult{
+					{Error: &params.Error{Message: msg}},
+				},
+			}
+			return nil
+		})
+	client := imagemetadata.NewClient(apiCaller)
+	err := client.SetModelImages([]params.CloudImageMetadata{{}})
+	c.Assert(errors.Cause(err), gc.ErrorMatches, msg)
+}
+
 func (s *imagemetadataSuite) TestUpdateFromPublishedImages(c *gc.C) {
 	called := false
 
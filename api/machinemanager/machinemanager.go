@@ -5,6 +5,7 @@ package machinemanager
 
 import (
 	"github.com/juju/errors"
+	"gopkg.in/juju/names.v2"
 
 	"github.com/juju/juju/api/base"
 	"github.com/juju/juju/apiserver/params"
@@ -36,3 +37,42 @@ func (client *Client) AddMachines(machineParams []params.AddMachineParams) ([]pa
 	}
 	return results.Machines, err
 }
+
+// PreviewInstanceSpecs reports the instances that the provider would
+// start for machines with the supplied parameters, without adding them.
+func (client *Client) PreviewInstanceSpecs(specs []params.PreviewInstanceSpec) ([]params.InstanceSpecPreviewResult, error) {
+	args := params.PreviewInstanceSpecs{
+		Specs: specs,
+	}
+	var results params.InstanceSpecPreviewResults
+	if err := client.facade.FacadeCall("PreviewInstanceSpecs", args, &results); err != nil {
+		return nil, errors.Trace(err)
+	}
+	if len(results.Results) != len(specs) {
+		return nil, errors.Errorf("expected %d result, got %d", len(specs), len(results.Results))
+	}
+	return results.Results, nil
+}
+
+// ReplaceMachines requests that the instances of the specified machines
+// be replaced, for machines whose instances were removed from the cloud
+// outside of Juju.
+func (client *Client) ReplaceMachines(machines ...string) ([]params.ErrorResult, error) {
+	args := params.Entities{
+		Entities: make([]params.Entity, len(machines)),
+	}
+	for i, id := range machines {
+		if !names.IsValidMachine(id) {
+			return nil, errors.NotValidf("machine ID %q", id)
+		}
+		args.Entities[i].Tag = names.NewMachineTag(id).String()
+	}
+	var results params.ErrorResults
+	if err := client.facade.FacadeCall("ReplaceMachines", args, &results); err != nil {
+		return nil, errors.Trace(err)
+	}
+	if len(results.Results) != len(machines) {
+		return nil, errors.Errorf("expected %d result, got %d", len(machines), len(results.Results))
+	}
+	return results.Results, nil
+}
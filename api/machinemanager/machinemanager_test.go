@@ -116,3 +116,64 @@ func (s *MachinemanagerSuite) TestAddMachinesResultCountInvalid(c *gc.C) {
 		c.Check(err, gc.ErrorMatches, fmt.Sprintf("expected 1 result, got %d", n))
 	}
 }
+
+func (s *MachinemanagerSuite) TestPreviewInstanceSpecs(c *gc.C) {
+	apiResult := []params.InstanceSpecPreviewResult{{
+		Result: &params.InstanceSpecPreview{
+			InstanceType: "m1.small",
+			Image:        "ami-0123",
+			Arch:         "amd64",
+		},
+	}}
+	var callCount int
+	apiCaller := testing.APICallerFunc(func(objType string, version int, id, request string, arg, result interface{}) error {
+		c.Check(objType, gc.Equals, "MachineManager")
+		c.Check(request, gc.Equals, "PreviewInstanceSpecs")
+		c.Check(arg, jc.DeepEquals, params.PreviewInstanceSpecs{
+			Specs: []params.PreviewInstanceSpec{{Series: "trusty"}},
+		})
+		c.Assert(result, gc.FitsTypeOf, &params.InstanceSpecPreviewResults{})
+		*(result.(*params.InstanceSpecPreviewResults)) = params.InstanceSpecPreviewResults{
+			Results: apiResult,
+		}
+		callCount++
+		return nil
+	})
+	st := machinemanager.NewClient(apiCaller)
+	results, err := st.PreviewInstanceSpecs([]params.PreviewInstanceSpec{{Series: "trusty"}})
+	c.Check(err, jc.ErrorIsNil)
+	c.Assert(results, jc.DeepEquals, apiResult)
+	c.Check(callCount, gc.Equals, 1)
+}
+
+func (s *MachinemanagerSuite) TestReplaceMachines(c *gc.C) {
+	var callCount int
+	apiCaller := testing.APICallerFunc(func(objType string, version int, id, request string, arg, result interface{}) error {
+		c.Check(objType, gc.Equals, "MachineManager")
+		c.Check(request, gc.Equals, "ReplaceMachines")
+		c.Check(arg, jc.DeepEquals, params.Entities{
+			Entities: []params.Entity{{Tag: "machine-0"}, {Tag: "machine-1"}},
+		})
+		c.Assert(result, gc.FitsTypeOf, &params.ErrorResults{})
+		*(result.(*params.ErrorResults)) = params.ErrorResults{
+			Results: []params.ErrorResult{{}, {Error: &params.Error{Message: "boom"}}},
+		}
+		callCount++
+		return nil
+	})
+	st := machinemanager.NewClient(apiCaller)
+	results, err := st.ReplaceMachines("0", "1")
+	c.Check(err, jc.ErrorIsNil)
+	c.Assert(results, jc.DeepEquals, []params.ErrorResult{{}, {Error: &params.Error{Message: "boom"}}})
+	c.Check(callCount, gc.Equals, 1)
+}
+
+func (s *MachinemanagerSuite) TestReplaceMachinesInvalidId(c *gc.C) {
+	apiCaller := testing.APICallerFunc(func(objType string, version int, id, request string, arg, result interface{}) error {
+		c.Fatalf("unexpected API call")
+		return nil
+	})
+	st := machinemanager.NewClient(apiCaller)
+	_, err := st.ReplaceMachines("0/lxd")
+	c.Assert(err, gc.ErrorMatches, `machine ID "0/lxd" not valid`)
+}
@@ -0,0 +1,54 @@
+// Copyright 2016 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package machinepower
+
+import (
+	"github.com/juju/errors"
+	"gopkg.in/juju/names.v2"
+
+	"github.com/juju/juju/api/base"
+	"github.com/juju/juju/apiserver/params"
+)
+
+// Client provides access to the MachinePower API facade.
+type Client struct {
+	base.ClientFacade
+	facade base.FacadeCaller
+}
+
+// NewClient returns a new Client based on an existing API connection.
+func NewClient(st base.APICallCloser) *Client {
+	frontend, backend := base.NewClientFacade(st, "MachinePower")
+	return &Client{ClientFacade: frontend, facade: backend}
+}
+
+// SuspendMachines stops the instances of the specified machines
+// without destroying them. A result is returned for each machine.
+func (c *Client) SuspendMachines(machineIds ...string) ([]params.ErrorResult, error) {
+	return c.call("SuspendMachines", machineIds)
+}
+
+// ResumeMachines starts the instances of the specified suspended
+// machines. A result is returned for each machine.
+func (c *Client) ResumeMachines(machineIds ...string) ([]params.ErrorResult, error) {
+	return c.call("ResumeMachines", machineIds)
+}
+
+func (c *Client) call(method string, machineIds []string) ([]params.ErrorResult, error) {
+	args := params.Entities{Entities: make([]params.Entity, len(machineIds))}
+	for i, id := range machineIds {
+		if !names.IsValidMachine(id) {
+			return nil, errors.NotValidf("machine ID %q", id)
+		}
+		args.Entities[i].Tag = names.NewMachineTag(id).String()
+	}
+	var result params.ErrorResults
+	if err := c.facade.FacadeCall(method, args, &result); err != nil {
+		return nil, errors.Trace(err)
+	}
+	if len(result.Results) != len(machineIds) {
+		return nil, errors.Errorf("expected %d results, got %d", len(machineIds), len(result.Results))
+	}
+	return result.Results, nil
+}
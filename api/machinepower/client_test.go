@@ -0,0 +1,84 @@
+// Copyright 2016 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package machinepower_test
+
+import (
+	"github.com/juju/errors"
+	jujutesting "github.com/juju/testing"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	basetesting "github.com/juju/juju/api/base/testing"
+	"github.com/juju/juju/api/machinepower"
+	"github.com/juju/juju/apiserver/params"
+)
+
+type clientSuite struct {
+	jujutesting.IsolationSuite
+}
+
+var _ = gc.Suite(&clientSuite{})
+
+func (s *clientSuite) apiCaller(c *gc.C, expectRequest string, results ...params.ErrorResult) basetesting.APICallerFunc {
+	return basetesting.APICallerFunc(
+		func(objType string,
+			version int,
+			id, request string,
+			a, result interface{},
+		) error {
+			c.Check(objType, gc.Equals, "MachinePower")
+			c.Check(id, gc.Equals, "")
+			c.Check(request, gc.Equals, expectRequest)
+			c.Check(a, jc.DeepEquals, params.Entities{
+				Entities: []params.Entity{{Tag: "machine-0"}, {Tag: "machine-1"}},
+			})
+			c.Assert(result, gc.FitsTypeOf, &params.ErrorResults{})
+			*(result.(*params.ErrorResults)) = params.ErrorResults{Results: results}
+			return nil
+		},
+	)
+}
+
+func (s *clientSuite) TestSuspendMachines(c *gc.C) {
+	expect := []params.ErrorResult{{}, {Error: &params.Error{Message: "boom"}}}
+	client := machinepower.NewClient(s.apiCaller(c, "SuspendMachines", expect...))
+	results, err := client.SuspendMachines("0", "1")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(results, jc.DeepEquals, expect)
+}
+
+func (s *clientSuite) TestResumeMachines(c *gc.C) {
+	expect := []params.ErrorResult{{}, {}}
+	client := machinepower.NewClient(s.apiCaller(c, "ResumeMachines", expect...))
+	results, err := client.ResumeMachines("0", "1")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(results, jc.DeepEquals, expect)
+}
+
+func (s *clientSuite) TestSuspendMachinesResultCount(c *gc.C) {
+	client := machinepower.NewClient(s.apiCaller(c, "SuspendMachines"))
+	_, err := client.SuspendMachines("0", "1")
+	c.Assert(err, gc.ErrorMatches, "expected 2 results, got 0")
+}
+
+func (s *clientSuite) TestSuspendMachinesInvalidId(c *gc.C) {
+	client := machinepower.NewClient(s.apiCaller(c, "SuspendMachines"))
+	_, err := client.SuspendMachines("foo")
+	c.Assert(err, gc.ErrorMatches, `machine ID "foo" not valid`)
+}
+
+func (s *clientSuite) TestSuspendMachinesError(c *gc.C) {
+	apiCaller := basetesting.APICallerFunc(
+		func(objType string,
+			version int,
+			id, request string,
+			a, result interface{},
+		) error {
+			return errors.New("boom")
+		},
+	)
+	client := machinepower.NewClient(apiCaller)
+	_, err := client.SuspendMachines("0")
+	c.Assert(err, gc.ErrorMatches, "boom")
+}
@@ -9,6 +9,7 @@ import (
 
 	"github.com/juju/juju/api/common"
 	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/instance"
 	"github.com/juju/juju/network"
 	"github.com/juju/juju/status"
 	"github.com/juju/juju/watcher"
@@ -71,6 +72,22 @@ func (m *Machine) SetMachineAddresses(addresses []network.Address) error {
 	return result.OneError()
 }
 
+// SetHardwareCharacteristics updates the recorded hardware characteristics
+// of the machine's instance, after it has been resized.
+func (m *Machine) SetHardwareCharacteristics(characteristics instance.HardwareCharacteristics) error {
+	var result params.ErrorResults
+	args := params.SetMachinesHardwareCharacteristics{
+		Machines: []params.MachineHardwareCharacteristics{
+			{Tag: m.tag.String(), Characteristics: characteristics},
+		},
+	}
+	err := m.st.facade.FacadeCall("SetHardwareCharacteristics", args, &result)
+	if err != nil {
+		return err
+	}
+	return result.OneError()
+}
+
 // EnsureDead sets the machine lifecycle to Dead if it is Alive or
 // Dying. It does nothing otherwise.
 func (m *Machine) EnsureDead() error {
@@ -15,6 +15,7 @@ import (
 	"github.com/juju/juju/api/machiner"
 	apitesting "github.com/juju/juju/api/testing"
 	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/instance"
 	"github.com/juju/juju/juju/testing"
 	"github.com/juju/juju/network"
 	"github.com/juju/juju/state"
@@ -156,6 +157,26 @@ func (s *machinerSuite) TestSetMachineAddresses(c *gc.C) {
 	c.Assert(s.machine.MachineAddresses(), jc.DeepEquals, expectAddresses)
 }
 
+func (s *machinerSuite) TestSetHardwareCharacteristics(c *gc.C) {
+	machine, err := s.machiner.Machine(names.NewMachineTag("1"))
+	c.Assert(err, jc.ErrorIsNil)
+
+	mem := uint64(8192)
+	cores := uint64(4)
+	err = machine.SetHardwareCharacteristics(instance.HardwareCharacteristics{
+		Mem:      &mem,
+		CpuCores: &cores,
+	})
+	c.Assert(err, jc.ErrorIsNil)
+
+	md, err := s.machine.HardwareCharacteristics()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(md.Mem, gc.NotNil)
+	c.Assert(*md.Mem, gc.Equals, mem)
+	c.Assert(md.CpuCores, gc.NotNil)
+	c.Assert(*md.CpuCores, gc.Equals, cores)
+}
+
 func (s *machinerSuite) TestSetEmptyMachineAddresses(c *gc.C) {
 	machine, err := s.machiner.Machine(names.NewMachineTag("1"))
 	c.Assert(err, jc.ErrorIsNil)
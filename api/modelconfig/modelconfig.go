@@ -7,8 +7,10 @@ import (
 	"github.com/juju/errors"
 
 	"github.com/juju/juju/api/base"
+	apiwatcher "github.com/juju/juju/api/watcher"
 	"github.com/juju/juju/apiserver/params"
 	"github.com/juju/juju/environs/config"
+	"github.com/juju/juju/watcher"
 )
 
 // Client provides methods that the Juju client command uses to interact
@@ -73,3 +75,29 @@ func (c *Client) ModelUnset(keys ...string) error {
 	args := params.ModelUnset{Keys: keys}
 	return c.facade.FacadeCall("ModelUnset", args, nil)
 }
+
+// ModelConfigChanges returns the most recent changes made to the
+// model's config, newest first. If limit is positive, at most that
+// many changes are returned.
+func (c *Client) ModelConfigChanges(limit int) ([]params.ModelConfigChange, error) {
+	args := params.ModelConfigChangesArgs{Limit: limit}
+	var result params.ModelConfigChangesResult
+	if err := c.facade.FacadeCall("ModelConfigChanges", args, &result); err != nil {
+		return nil, errors.Trace(err)
+	}
+	return result.Changes, nil
+}
+
+// WatchModelConfigChanges returns a NotifyWatcher that triggers
+// whenever a change to the model's config is recorded, as returned
+// by ModelConfigChanges.
+func (c *Client) WatchModelConfigChanges() (watcher.NotifyWatcher, error) {
+	var result params.NotifyWatchResult
+	if err := c.facade.FacadeCall("WatchModelConfigChanges", nil, &result); err != nil {
+		return nil, errors.Trace(err)
+	}
+	if result.Error != nil {
+		return nil, errors.Trace(result.Error)
+	}
+	return apiwatcher.NewNotifyWatcher(c.facade.RawAPICaller(), result), nil
+}
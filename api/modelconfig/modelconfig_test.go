@@ -127,3 +127,62 @@ func (s *modelconfigSuite) TestModelUnset(c *gc.C) {
 	c.Assert(err, jc.ErrorIsNil)
 	c.Assert(called, jc.IsTrue)
 }
+
+func (s *modelconfigSuite) TestModelConfigChanges(c *gc.C) {
+	apiCaller := basetesting.APICallerFunc(
+		func(objType string,
+			version int,
+			id, request string,
+			a, result interface{},
+		) error {
+			c.Check(objType, gc.Equals, "ModelConfig")
+			c.Check(id, gc.Equals, "")
+			c.Check(request, gc.Equals, "ModelConfigChanges")
+			c.Check(a, jc.DeepEquals, params.ModelConfigChangesArgs{Limit: 5})
+			c.Assert(result, gc.FitsTypeOf, &params.ModelConfigChangesResult{})
+			results := result.(*params.ModelConfigChangesResult)
+			results.Changes = []params.ModelConfigChange{{
+				UserTag: "user-bruce@local",
+				Source:  "api",
+				Attributes: []params.ModelConfigAttributeChange{
+					{Key: "foo", OldValue: "bar", NewValue: "baz"},
+				},
+			}}
+			return nil
+		},
+	)
+	client := modelconfig.NewClient(apiCaller)
+	result, err := client.ModelConfigChanges(5)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(result, jc.DeepEquals, []params.ModelConfigChange{{
+		UserTag: "user-bruce@local",
+		Source:  "api",
+		Attributes: []params.ModelConfigAttributeChange{
+			{Key: "foo", OldValue: "bar", NewValue: "baz"},
+		},
+	}})
+}
+
+func (s *modelconfigSuite) TestWatchModelConfigChangesError(c *gc.C) {
+	apiCaller := basetesting.APICallerFunc(
+		func(objType string,
+			version int,
+			id, request string,
+			a, result interface{},
+		) error {
+			c.Check(objType, gc.Equals, "ModelConfig")
+			c.Check(id, gc.Equals, "")
+			c.Check(request, gc.Equals, "WatchModelConfigChanges")
+			c.Check(a, gc.IsNil)
+			c.Assert(result, gc.FitsTypeOf, &params.NotifyWatchResult{})
+			*(result.(*params.NotifyWatchResult)) = params.NotifyWatchResult{
+				Error: &params.Error{Message: "permission denied"},
+			}
+			return nil
+		},
+	)
+	client := modelconfig.NewClient(apiCaller)
+	w, err := client.WatchModelConfigChanges()
+	c.Assert(err, gc.ErrorMatches, "permission denied")
+	c.Assert(w, gc.IsNil)
+}
@@ -0,0 +1,33 @@
+// Copyright 2016 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package providerresources
+
+import (
+	"github.com/juju/errors"
+
+	"github.com/juju/juju/api/base"
+	"github.com/juju/juju/apiserver/params"
+)
+
+// Client provides access to the ProviderResources API facade.
+type Client struct {
+	base.ClientFacade
+	facade base.FacadeCaller
+}
+
+// NewClient returns a new Client based on an existing API connection.
+func NewClient(st base.APICallCloser) *Client {
+	frontend, backend := base.NewClientFacade(st, "ProviderResources")
+	return &Client{ClientFacade: frontend, facade: backend}
+}
+
+// ListProviderResources returns the cloud resources that the provider
+// created for the model.
+func (c *Client) ListProviderResources() ([]params.ProviderResource, error) {
+	var result params.ProviderResourcesResult
+	if err := c.facade.FacadeCall("ListProviderResources", nil, &result); err != nil {
+		return nil, errors.Trace(err)
+	}
+	return result.Resources, nil
+}
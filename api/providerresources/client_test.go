@@ -0,0 +1,60 @@
+// Copyright 2016 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package providerresources_test
+
+import (
+	"github.com/juju/errors"
+	jujutesting "github.com/juju/testing"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	basetesting "github.com/juju/juju/api/base/testing"
+	"github.com/juju/juju/api/providerresources"
+	"github.com/juju/juju/apiserver/params"
+)
+
+type clientSuite struct {
+	jujutesting.IsolationSuite
+}
+
+var _ = gc.Suite(&clientSuite{})
+
+func (s *clientSuite) TestListProviderResources(c *gc.C) {
+	apiCaller := basetesting.APICallerFunc(
+		func(objType string,
+			version int,
+			id, request string,
+			a, result interface{},
+		) error {
+			c.Check(objType, gc.Equals, "ProviderResources")
+			c.Check(id, gc.Equals, "")
+			c.Check(request, gc.Equals, "ListProviderResources")
+			c.Check(a, gc.IsNil)
+			c.Assert(result, gc.FitsTypeOf, &params.ProviderResourcesResult{})
+			*(result.(*params.ProviderResourcesResult)) = params.ProviderResourcesResult{
+				Resources: []params.ProviderResource{{Type: "vm", Id: "machine-0"}},
+			}
+			return nil
+		},
+	)
+	client := providerresources.NewClient(apiCaller)
+	resources, err := client.ListProviderResources()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(resources, jc.DeepEquals, []params.ProviderResource{{Type: "vm", Id: "machine-0"}})
+}
+
+func (s *clientSuite) TestListProviderResourcesError(c *gc.C) {
+	apiCaller := basetesting.APICallerFunc(
+		func(objType string,
+			version int,
+			id, request string,
+			a, result interface{},
+		) error {
+			return errors.New("boom")
+		},
+	)
+	client := providerresources.NewClient(apiCaller)
+	_, err := client.ListProviderResources()
+	c.Assert(err, gc.ErrorMatches, "boom")
+}
@@ -119,6 +119,28 @@ func (m *Machine) SetStatus(status status.Status, info string, data map[string]i
 	return result.OneError()
 }
 
+// SetProvisioningError records the reason that the provisioner was
+// unable to start an instance for the machine, after the specified
+// number of attempts.
+func (m *Machine) SetProvisioningError(kind, message string, attempts int) error {
+	var result params.ErrorResults
+	args := params.SetMachineProvisioningErrors{
+		Errors: []params.SetMachineProvisioningError{{
+			MachineTag: m.tag.String(),
+			Error: params.MachineProvisioningError{
+				Kind:     kind,
+				Message:  message,
+				Attempts: attempts,
+			},
+		}},
+	}
+	err := m.st.facade.FacadeCall("SetProvisioningErrors", args, &result)
+	if err != nil {
+		return err
+	}
+	return result.OneError()
+}
+
 // Status returns the status of the machine.
 func (m *Machine) Status() (status.Status, string, error) {
 	var results params.StatusResults
@@ -254,6 +276,31 @@ func (m *Machine) SetInstanceInfo(
 	return result.OneError()
 }
 
+// ReplaceInstance records that the machine's instance, whose replacement
+// was requested, has been replaced by the instance with the given id,
+// nonce and hardware characteristics, and with the given volume
+// attachments.
+func (m *Machine) ReplaceInstance(
+	id instance.Id, nonce string, characteristics *instance.HardwareCharacteristics,
+	volumeAttachments map[string]params.VolumeAttachmentInfo,
+) error {
+	var result params.ErrorResults
+	args := params.ReplacementInstancesInfo{
+		Machines: []params.ReplacementInstanceInfo{{
+			Tag:               m.tag.String(),
+			InstanceId:        id,
+			Nonce:             nonce,
+			Characteristics:   characteristics,
+			VolumeAttachments: volumeAttachments,
+		}},
+	}
+	err := m.st.facade.FacadeCall("ReplaceInstances", args, &result)
+	if err != nil {
+		return err
+	}
+	return result.OneError()
+}
+
 // InstanceId returns the provider specific instance id for the
 // machine or an CodeNotProvisioned error, if not set.
 func (m *Machine) InstanceId() (instance.Id, error) {
@@ -132,6 +132,28 @@ func (st *State) MachinesWithTransientErrors() ([]*Machine, []params.StatusResul
 	return machines, results.Results, nil
 }
 
+// MachinesToReplace returns the machines whose instances have been
+// requested to be replaced.
+func (st *State) MachinesToReplace() ([]*Machine, error) {
+	var result params.StringsResult
+	err := st.facade.FacadeCall("MachinesToReplace", nil, &result)
+	if err != nil {
+		return nil, err
+	}
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	machines := make([]*Machine, len(result.Result))
+	for i, id := range result.Result {
+		machines[i] = &Machine{
+			tag:  names.NewMachineTag(id),
+			life: params.Alive,
+			st:   st,
+		}
+	}
+	return machines, nil
+}
+
 // FindTools returns al ist of tools matching the specified version number and
 // series, and, arch. If arch is blank, a default will be used.
 func (st *State) FindTools(v version.Number, series string, arch string) (tools.List, error) {
@@ -131,6 +131,51 @@ func (s *provisionerSuite) TestGetSetInstanceStatus(c *gc.C) {
 	c.Assert(statusInfo.Data, gc.HasLen, 0)
 }
 
+func (s *provisionerSuite) TestSetProvisioningError(c *gc.C) {
+	machine, err := s.State.AddMachine("quantal", state.JobHostUnits)
+	c.Assert(err, jc.ErrorIsNil)
+	apiMachine, err := s.provisioner.Machine(machine.Tag().(names.MachineTag))
+	c.Assert(err, jc.ErrorIsNil)
+
+	err = apiMachine.SetProvisioningError("quota", "cores quota exhausted", 3)
+	c.Assert(err, jc.ErrorIsNil)
+
+	perr, err := machine.ProvisioningError()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(perr.Kind, gc.Equals, "quota")
+	c.Assert(perr.Message, gc.Equals, "cores quota exhausted")
+	c.Assert(perr.Attempts, gc.Equals, 3)
+}
+
+func (s *provisionerSuite) TestReplaceInstance(c *gc.C) {
+	machine, err := s.State.AddMachine("quantal", state.JobHostUnits)
+	c.Assert(err, jc.ErrorIsNil)
+	err = machine.SetProvisioned("i-old", "fake_nonce", nil)
+	c.Assert(err, jc.ErrorIsNil)
+	err = machine.RequestReplacement()
+	c.Assert(err, jc.ErrorIsNil)
+
+	machines, err := s.provisioner.MachinesToReplace()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(machines, gc.HasLen, 1)
+	c.Assert(machines[0].Id(), gc.Equals, machine.Id())
+
+	hwChars := instance.MustParseHardware("cores=123", "mem=4G")
+	err = machines[0].ReplaceInstance("i-new", "new_nonce", &hwChars, nil)
+	c.Assert(err, jc.ErrorIsNil)
+
+	err = machine.Refresh()
+	c.Assert(err, jc.ErrorIsNil)
+	instanceId, err := machine.InstanceId()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(instanceId, gc.Equals, instance.Id("i-new"))
+	c.Assert(machine.CheckProvisioned("new_nonce"), jc.IsTrue)
+
+	machines, err = s.provisioner.MachinesToReplace()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(machines, gc.HasLen, 0)
+}
+
 func (s *provisionerSuite) TestGetSetStatusWithData(c *gc.C) {
 	apiMachine, err := s.provisioner.Machine(s.machine.Tag().(names.MachineTag))
 	c.Assert(err, jc.ErrorIsNil)
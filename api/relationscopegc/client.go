@@ -0,0 +1,51 @@
+// Copyright 2016 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package relationscopegc
+
+import (
+	"github.com/juju/errors"
+
+	"github.com/juju/juju/api/base"
+	"github.com/juju/juju/apiserver/params"
+)
+
+// Client provides access to the RelationScopeGC API facade.
+type Client struct {
+	facade base.FacadeCaller
+}
+
+// NewClient returns a new Client based on an existing API connection.
+func NewClient(caller base.APICaller) *Client {
+	return &Client{base.NewFacadeCaller(caller, "RelationScopeGC")}
+}
+
+// StaleRelationScopes returns the keys of the relation scope
+// memberships whose units, or the units' machines, are gone.
+func (c *Client) StaleRelationScopes() ([]string, error) {
+	var result params.RelationScopeKeys
+	if err := c.facade.FacadeCall("StaleRelationScopes", nil, &result); err != nil {
+		return nil, errors.Trace(err)
+	}
+	return result.Keys, nil
+}
+
+// RemoveStaleRelationScopes removes the relation scope memberships with
+// the given keys, returning an error for each.
+func (c *Client) RemoveStaleRelationScopes(keys []string) ([]error, error) {
+	args := params.RelationScopeKeys{Keys: keys}
+	var results params.ErrorResults
+	if err := c.facade.FacadeCall("RemoveStaleRelationScopes", args, &results); err != nil {
+		return nil, errors.Trace(err)
+	}
+	if len(results.Results) != len(keys) {
+		return nil, errors.Errorf("expected %d results, got %d", len(keys), len(results.Results))
+	}
+	errs := make([]error, len(keys))
+	for i, result := range results.Results {
+		if result.Error != nil {
+			errs[i] = result.Error
+		}
+	}
+	return errs, nil
+}
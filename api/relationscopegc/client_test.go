@@ -0,0 +1,86 @@
+// Copyright 2016 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package relationscopegc_test
+
+import (
+	"github.com/juju/errors"
+	jujutesting "github.com/juju/testing"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	basetesting "github.com/juju/juju/api/base/testing"
+	"github.com/juju/juju/api/relationscopegc"
+	"github.com/juju/juju/apiserver/params"
+)
+
+type clientSuite struct {
+	jujutesting.IsolationSuite
+}
+
+var _ = gc.Suite(&clientSuite{})
+
+func (s *clientSuite) TestStaleRelationScopes(c *gc.C) {
+	apiCaller := basetesting.APICallerFunc(
+		func(objType string,
+			version int,
+			id, request string,
+			a, result interface{},
+		) error {
+			c.Check(objType, gc.Equals, "RelationScopeGC")
+			c.Check(id, gc.Equals, "")
+			c.Check(request, gc.Equals, "StaleRelationScopes")
+			c.Check(a, gc.IsNil)
+			c.Assert(result, gc.FitsTypeOf, &params.RelationScopeKeys{})
+			*(result.(*params.RelationScopeKeys)) = params.RelationScopeKeys{
+				Keys: []string{"r#0#peer#riak/0"},
+			}
+			return nil
+		},
+	)
+	keys, err := relationscopegc.NewClient(apiCaller).StaleRelationScopes()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(keys, jc.DeepEquals, []string{"r#0#peer#riak/0"})
+}
+
+func (s *clientSuite) TestRemoveStaleRelationScopes(c *gc.C) {
+	apiCaller := basetesting.APICallerFunc(
+		func(objType string,
+			version int,
+			id, request string,
+			a, result interface{},
+		) error {
+			c.Check(objType, gc.Equals, "RelationScopeGC")
+			c.Check(request, gc.Equals, "RemoveStaleRelationScopes")
+			c.Check(a, jc.DeepEquals, params.RelationScopeKeys{
+				Keys: []string{"r#0#peer#riak/0", "r#0#peer#riak/1"},
+			})
+			c.Assert(result, gc.FitsTypeOf, &params.ErrorResults{})
+			*(result.(*params.ErrorResults)) = params.ErrorResults{
+				Results: []params.ErrorResult{{}, {Error: &params.Error{Message: "boom"}}},
+			}
+			return nil
+		},
+	)
+	errs, err := relationscopegc.NewClient(apiCaller).RemoveStaleRelationScopes(
+		[]string{"r#0#peer#riak/0", "r#0#peer#riak/1"},
+	)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(errs, gc.HasLen, 2)
+	c.Assert(errs[0], jc.ErrorIsNil)
+	c.Assert(errs[1], gc.ErrorMatches, "boom")
+}
+
+func (s *clientSuite) TestError(c *gc.C) {
+	apiCaller := basetesting.APICallerFunc(
+		func(objType string,
+			version int,
+			id, request string,
+			a, result interface{},
+		) error {
+			return errors.New("boom")
+		},
+	)
+	_, err := relationscopegc.NewClient(apiCaller).StaleRelationScopes()
+	c.Assert(err, gc.ErrorMatches, "boom")
+}
@@ -0,0 +1,51 @@
+// Copyright 2016 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package api
+
+import (
+	"net"
+
+	"github.com/juju/errors"
+	"github.com/juju/utils/clock"
+
+	"github.com/juju/juju/apiserver/observer"
+	"github.com/juju/juju/rpc"
+	"github.com/juju/juju/rpc/jsoncodec"
+)
+
+// OpenRelayed establishes a connection to the API server through the
+// API relay listening on the unix socket at the given path, logging in
+// with the credentials in info. The relay makes the connection's RPC
+// calls over its own agent's API connection, on behalf of the entity
+// that logged in, so that the agents on a machine need not each hold
+// a connection to the API server.
+//
+// HTTP requests and streams made through the returned Connection are
+// sent directly to the first of the API server addresses in info.
+func OpenRelayed(info *Info, opts DialOpts, socketPath string) (Connection, error) {
+	if err := info.Validate(); err != nil {
+		return nil, errors.Annotate(err, "validating info for opening an API connection")
+	}
+	tlsConfig, err := newTLSConfig(info, opts)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	conn, err := net.Dial("unix", socketPath)
+	if err != nil {
+		return nil, errors.Annotate(err, "cannot connect to API relay")
+	}
+
+	client := rpc.NewConn(jsoncodec.NewNet(conn), observer.None())
+	client.Start()
+
+	st := newState(client, info.Addrs[0], tlsConfig, info, opts, clock.WallClock)
+	if err := st.Login(info.Tag, info.Password, info.Nonce, info.Macaroons); err != nil {
+		client.Close()
+		return nil, errors.Trace(err)
+	}
+	st.broken = make(chan struct{})
+	st.closed = make(chan struct{})
+	go st.heartbeatMonitor()
+	return st, nil
+}
@@ -0,0 +1,32 @@
+// Copyright 2016 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package resourcesweeper
+
+import (
+	"github.com/juju/errors"
+
+	"github.com/juju/juju/api/base"
+	"github.com/juju/juju/apiserver/params"
+)
+
+// Client provides access to the ResourceSweeper API facade.
+type Client struct {
+	facade base.FacadeCaller
+}
+
+// NewClient returns a new Client based on an existing API connection.
+func NewClient(caller base.APICaller) *Client {
+	return &Client{base.NewFacadeCaller(caller, "ResourceSweeper")}
+}
+
+// SweepMachineResources deletes the unused cloud resources that the
+// provider created for machines that are no longer in the model, and
+// returns the deleted resources.
+func (c *Client) SweepMachineResources() ([]params.ProviderResource, error) {
+	var result params.ProviderResourcesResult
+	if err := c.facade.FacadeCall("SweepMachineResources", nil, &result); err != nil {
+		return nil, errors.Trace(err)
+	}
+	return result.Resources, nil
+}
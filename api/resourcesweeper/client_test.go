@@ -0,0 +1,60 @@
+// Copyright 2016 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package resourcesweeper_test
+
+import (
+	"github.com/juju/errors"
+	jujutesting "github.com/juju/testing"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	basetesting "github.com/juju/juju/api/base/testing"
+	"github.com/juju/juju/api/resourcesweeper"
+	"github.com/juju/juju/apiserver/params"
+)
+
+type clientSuite struct {
+	jujutesting.IsolationSuite
+}
+
+var _ = gc.Suite(&clientSuite{})
+
+func (s *clientSuite) TestSweepMachineResources(c *gc.C) {
+	apiCaller := basetesting.APICallerFunc(
+		func(objType string,
+			version int,
+			id, request string,
+			a, result interface{},
+		) error {
+			c.Check(objType, gc.Equals, "ResourceSweeper")
+			c.Check(id, gc.Equals, "")
+			c.Check(request, gc.Equals, "SweepMachineResources")
+			c.Check(a, gc.IsNil)
+			c.Assert(result, gc.FitsTypeOf, &params.ProviderResourcesResult{})
+			*(result.(*params.ProviderResourcesResult)) = params.ProviderResourcesResult{
+				Resources: []params.ProviderResource{{Type: "nic", Id: "machine-1-primary"}},
+			}
+			return nil
+		},
+	)
+	client := resourcesweeper.NewClient(apiCaller)
+	resources, err := client.SweepMachineResources()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(resources, jc.DeepEquals, []params.ProviderResource{{Type: "nic", Id: "machine-1-primary"}})
+}
+
+func (s *clientSuite) TestSweepMachineResourcesError(c *gc.C) {
+	apiCaller := basetesting.APICallerFunc(
+		func(objType string,
+			version int,
+			id, request string,
+			a, result interface{},
+		) error {
+			return errors.New("boom")
+		},
+	)
+	client := resourcesweeper.NewClient(apiCaller)
+	_, err := client.SweepMachineResources()
+	c.Assert(err, gc.ErrorMatches, "boom")
+}
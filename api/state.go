@@ -122,6 +122,7 @@ func (st *state) Login(tag names.Tag, password, nonce string, macaroons []macaro
 		facades:          result.Facades,
 		modelAccess:      modelAccess,
 		controllerAccess: controllerAccess,
+		canDelegate:      result.CanDelegate,
 	}); err != nil {
 		return errors.Trace(err)
 	}
@@ -140,6 +141,7 @@ type loginResultParams struct {
 	controllerAccess string
 	servers          [][]network.HostPort
 	facades          []params.FacadeVersions
+	canDelegate      bool
 }
 
 func (st *state) setLoginResult(p loginResultParams) error {
@@ -162,6 +164,7 @@ func (st *state) setLoginResult(p loginResultParams) error {
 	st.controllerTag = ctag
 	st.controllerAccess = p.controllerAccess
 	st.modelAccess = p.modelAccess
+	st.canDelegate = p.canDelegate
 
 	hostPorts, err := addAddress(p.servers, st.addr)
 	if err != nil {
@@ -137,6 +137,26 @@ func (c *Client) ListFilesystems(machines []string) ([]params.FilesystemDetailsL
 	return results.Results, nil
 }
 
+// StoragePlans returns the plans for provisioning the volumes and
+// filesystems with the given tags, without provisioning them.
+func (c *Client) StoragePlans(tags []names.Tag) ([]params.StoragePlanResult, error) {
+	entities := make([]params.Entity, len(tags))
+	for i, tag := range tags {
+		entities[i] = params.Entity{Tag: tag.String()}
+	}
+	var results params.StoragePlanResults
+	if err := c.facade.FacadeCall("StoragePlans", params.Entities{Entities: entities}, &results); err != nil {
+		return nil, errors.Trace(err)
+	}
+	if len(results.Results) != len(tags) {
+		return nil, errors.Errorf(
+			"expected %d result(s), got %d",
+			len(tags), len(results.Results),
+		)
+	}
+	return results.Results, nil
+}
+
 // AddToUnit adds specified storage to desired units.
 func (c *Client) AddToUnit(storages []params.StorageAddParams) ([]params.ErrorResult, error) {
 	out := params.ErrorResults{}
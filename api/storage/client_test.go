@@ -581,3 +581,54 @@ func (s *storageMockSuite) TestAddToUnitFacadeCallError(c *gc.C) {
 	c.Assert(errors.Cause(err), gc.ErrorMatches, msg)
 	c.Assert(found, gc.HasLen, 0)
 }
+
+func (s *storageMockSuite) TestStoragePlans(c *gc.C) {
+	var called bool
+	apiCaller := basetesting.APICallerFunc(
+		func(objType string,
+			version int,
+			id, request string,
+			a, result interface{},
+		) error {
+			called = true
+			c.Check(objType, gc.Equals, "Storage")
+			c.Check(id, gc.Equals, "")
+			c.Check(request, gc.Equals, "StoragePlans")
+			c.Check(a, jc.DeepEquals, params.Entities{Entities: []params.Entity{
+				{Tag: "volume-0"}, {Tag: "filesystem-1"},
+			}})
+			c.Assert(result, gc.FitsTypeOf, &params.StoragePlanResults{})
+			results := result.(*params.StoragePlanResults)
+			results.Results = []params.StoragePlanResult{{
+				Result: &params.StoragePlan{Tag: "volume-0", Create: true, Provider: "loop"},
+			}, {
+				Error: &params.Error{Message: "filesystem 1 not found"},
+			}}
+			return nil
+		})
+	storageClient := storage.NewClient(apiCaller)
+	found, err := storageClient.StoragePlans([]names.Tag{
+		names.NewVolumeTag("0"), names.NewFilesystemTag("1"),
+	})
+	c.Assert(called, jc.IsTrue)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(found, jc.DeepEquals, []params.StoragePlanResult{{
+		Result: &params.StoragePlan{Tag: "volume-0", Create: true, Provider: "loop"},
+	}, {
+		Error: &params.Error{Message: "filesystem 1 not found"},
+	}})
+}
+
+func (s *storageMockSuite) TestStoragePlansResultCountMismatch(c *gc.C) {
+	apiCaller := basetesting.APICallerFunc(
+		func(objType string,
+			version int,
+			id, request string,
+			a, result interface{},
+		) error {
+			return nil
+		})
+	storageClient := storage.NewClient(apiCaller)
+	_, err := storageClient.StoragePlans([]names.Tag{names.NewVolumeTag("0")})
+	c.Assert(err, gc.ErrorMatches, `expected 1 result\(s\), got 0`)
+}
@@ -430,6 +430,39 @@ func (st *State) RemoveAttachments(ids []params.MachineStorageId) ([]params.Erro
 	return results.Results, nil
 }
 
+// ClaimStorageEntities claims the volumes and filesystems with the
+// specified tags on behalf of the scope passed to NewState.
+func (st *State) ClaimStorageEntities(tags []names.Tag) ([]params.ErrorResult, error) {
+	return st.updateStorageClaims("ClaimStorageEntities", tags)
+}
+
+// ReleaseStorageEntities releases the claims on the volumes and
+// filesystems with the specified tags held by the scope passed to
+// NewState.
+func (st *State) ReleaseStorageEntities(tags []names.Tag) ([]params.ErrorResult, error) {
+	return st.updateStorageClaims("ReleaseStorageEntities", tags)
+}
+
+func (st *State) updateStorageClaims(method string, tags []names.Tag) ([]params.ErrorResult, error) {
+	var results params.ErrorResults
+	args := params.StorageClaims{
+		Claims: make([]params.StorageClaim, len(tags)),
+	}
+	for i, tag := range tags {
+		args.Claims[i] = params.StorageClaim{
+			EntityTag: tag.String(),
+			OwnerTag:  st.scope.String(),
+		}
+	}
+	if err := st.facade.FacadeCall(method, args, &results); err != nil {
+		return nil, err
+	}
+	if len(results.Results) != len(tags) {
+		return nil, errors.Errorf("expected %d result(s), got %d", len(tags), len(results.Results))
+	}
+	return results.Results, nil
+}
+
 // InstanceIds returns the provider specific instance ID for each machine,
 // or an CodeNotProvisioned error if not set.
 func (st *State) InstanceIds(tags []names.MachineTag) ([]params.StringResult, error) {
@@ -697,6 +697,48 @@ func (s *provisionerSuite) TestEnsureDead(c *gc.C) {
 	})
 }
 
+func (s *provisionerSuite) testStorageClaims(
+	c *gc.C, opName string, apiCall func(*storageprovisioner.State, []names.Tag) ([]params.ErrorResult, error),
+) {
+	var callCount int
+	apiCaller := testing.APICallerFunc(func(objType string, version int, id, request string, arg, result interface{}) error {
+		c.Check(objType, gc.Equals, "StorageProvisioner")
+		c.Check(version, gc.Equals, 0)
+		c.Check(id, gc.Equals, "")
+		c.Check(request, gc.Equals, opName)
+		c.Check(arg, gc.DeepEquals, params.StorageClaims{Claims: []params.StorageClaim{{
+			EntityTag: "volume-100",
+			OwnerTag:  "machine-123",
+		}}})
+		c.Assert(result, gc.FitsTypeOf, &params.ErrorResults{})
+		*(result.(*params.ErrorResults)) = params.ErrorResults{
+			Results: []params.ErrorResult{{Error: nil}},
+		}
+		callCount++
+		return nil
+	})
+
+	st, err := storageprovisioner.NewState(apiCaller, names.NewMachineTag("123"))
+	c.Assert(err, jc.ErrorIsNil)
+	volumes := []names.Tag{names.NewVolumeTag("100")}
+	errorResults, err := apiCall(st, volumes)
+	c.Check(err, jc.ErrorIsNil)
+	c.Check(callCount, gc.Equals, 1)
+	c.Assert(errorResults, jc.DeepEquals, []params.ErrorResult{{}})
+}
+
+func (s *provisionerSuite) TestClaimStorageEntities(c *gc.C) {
+	s.testStorageClaims(c, "ClaimStorageEntities", func(st *storageprovisioner.State, tags []names.Tag) ([]params.ErrorResult, error) {
+		return st.ClaimStorageEntities(tags)
+	})
+}
+
+func (s *provisionerSuite) TestReleaseStorageEntities(c *gc.C) {
+	s.testStorageClaims(c, "ReleaseStorageEntities", func(st *storageprovisioner.State, tags []names.Tag) ([]params.ErrorResult, error) {
+		return st.ReleaseStorageEntities(tags)
+	})
+}
+
 func (s *provisionerSuite) TestLife(c *gc.C) {
 	var callCount int
 	apiCaller := testing.APICallerFunc(func(objType string, version int, id, request string, arg, result interface{}) error {
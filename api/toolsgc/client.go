@@ -0,0 +1,43 @@
+// Copyright 2016 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package toolsgc
+
+import (
+	"github.com/juju/errors"
+
+	"github.com/juju/juju/api/base"
+	"github.com/juju/juju/apiserver/params"
+)
+
+// Client provides access to the ToolsGC API facade.
+type Client struct {
+	facade base.FacadeCaller
+}
+
+// NewClient returns a new Client based on an existing API connection.
+func NewClient(caller base.APICaller) *Client {
+	return &Client{base.NewFacadeCaller(caller, "ToolsGC")}
+}
+
+// UnusedAgentBinaries reports the agent binaries that would be removed
+// if only the keepLatest most recent versions for each major.minor
+// version, and those in use, were kept.
+func (c *Client) UnusedAgentBinaries(keepLatest int) ([]params.AgentBinary, error) {
+	return c.call("UnusedAgentBinaries", keepLatest)
+}
+
+// RemoveUnusedAgentBinaries removes the agent binaries reported by
+// UnusedAgentBinaries, returning those removed.
+func (c *Client) RemoveUnusedAgentBinaries(keepLatest int) ([]params.AgentBinary, error) {
+	return c.call("RemoveUnusedAgentBinaries", keepLatest)
+}
+
+func (c *Client) call(method string, keepLatest int) ([]params.AgentBinary, error) {
+	args := params.ToolsGCArgs{KeepLatest: keepLatest}
+	var result params.AgentBinariesResult
+	if err := c.facade.FacadeCall(method, args, &result); err != nil {
+		return nil, errors.Trace(err)
+	}
+	return result.Binaries, nil
+}
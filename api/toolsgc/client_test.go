@@ -0,0 +1,68 @@
+// Copyright 2016 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package toolsgc_test
+
+import (
+	"github.com/juju/errors"
+	jujutesting "github.com/juju/testing"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	basetesting "github.com/juju/juju/api/base/testing"
+	"github.com/juju/juju/api/toolsgc"
+	"github.com/juju/juju/apiserver/params"
+)
+
+type clientSuite struct {
+	jujutesting.IsolationSuite
+}
+
+var _ = gc.Suite(&clientSuite{})
+
+func (s *clientSuite) newClient(c *gc.C, expectRequest string) *toolsgc.Client {
+	apiCaller := basetesting.APICallerFunc(
+		func(objType string,
+			version int,
+			id, request string,
+			a, result interface{},
+		) error {
+			c.Check(objType, gc.Equals, "ToolsGC")
+			c.Check(id, gc.Equals, "")
+			c.Check(request, gc.Equals, expectRequest)
+			c.Check(a, jc.DeepEquals, params.ToolsGCArgs{KeepLatest: 2})
+			c.Assert(result, gc.FitsTypeOf, &params.AgentBinariesResult{})
+			*(result.(*params.AgentBinariesResult)) = params.AgentBinariesResult{
+				Binaries: []params.AgentBinary{{Version: "1.25.0-trusty-amd64", Size: 123}},
+			}
+			return nil
+		},
+	)
+	return toolsgc.NewClient(apiCaller)
+}
+
+func (s *clientSuite) TestUnusedAgentBinaries(c *gc.C) {
+	binaries, err := s.newClient(c, "UnusedAgentBinaries").UnusedAgentBinaries(2)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(binaries, jc.DeepEquals, []params.AgentBinary{{Version: "1.25.0-trusty-amd64", Size: 123}})
+}
+
+func (s *clientSuite) TestRemoveUnusedAgentBinaries(c *gc.C) {
+	binaries, err := s.newClient(c, "RemoveUnusedAgentBinaries").RemoveUnusedAgentBinaries(2)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(binaries, jc.DeepEquals, []params.AgentBinary{{Version: "1.25.0-trusty-amd64", Size: 123}})
+}
+
+func (s *clientSuite) TestError(c *gc.C) {
+	apiCaller := basetesting.APICallerFunc(
+		func(objType string,
+			version int,
+			id, request string,
+			a, result interface{},
+		) error {
+			return errors.New("boom")
+		},
+	)
+	_, err := toolsgc.NewClient(apiCaller).RemoveUnusedAgentBinaries(2)
+	c.Assert(err, gc.ErrorMatches, "boom")
+}
@@ -0,0 +1,26 @@
+// Copyright 2016 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package toolsmirror
+
+import (
+	"github.com/juju/errors"
+
+	"github.com/juju/juju/api/base"
+)
+
+// Client provides access to the ToolsMirror API facade.
+type Client struct {
+	facade base.FacadeCaller
+}
+
+// NewClient returns a new Client based on an existing API connection.
+func NewClient(caller base.APICaller) *Client {
+	return &Client{base.NewFacadeCaller(caller, "ToolsMirror")}
+}
+
+// UpdateToolsMirror regenerates the simplestreams metadata of the
+// model's tools mirror from the agent binaries in its tools storage.
+func (c *Client) UpdateToolsMirror() error {
+	return errors.Trace(c.facade.FacadeCall("UpdateToolsMirror", nil, nil))
+}
@@ -0,0 +1,56 @@
+// Copyright 2016 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package toolsmirror_test
+
+import (
+	"github.com/juju/errors"
+	jujutesting "github.com/juju/testing"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	basetesting "github.com/juju/juju/api/base/testing"
+	"github.com/juju/juju/api/toolsmirror"
+)
+
+type clientSuite struct {
+	jujutesting.IsolationSuite
+}
+
+var _ = gc.Suite(&clientSuite{})
+
+func (s *clientSuite) TestUpdateToolsMirror(c *gc.C) {
+	var called bool
+	apiCaller := basetesting.APICallerFunc(
+		func(objType string,
+			version int,
+			id, request string,
+			a, result interface{},
+		) error {
+			called = true
+			c.Check(objType, gc.Equals, "ToolsMirror")
+			c.Check(id, gc.Equals, "")
+			c.Check(request, gc.Equals, "UpdateToolsMirror")
+			c.Check(a, gc.IsNil)
+			c.Check(result, gc.IsNil)
+			return nil
+		},
+	)
+	err := toolsmirror.NewClient(apiCaller).UpdateToolsMirror()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(called, jc.IsTrue)
+}
+
+func (s *clientSuite) TestUpdateToolsMirrorError(c *gc.C) {
+	apiCaller := basetesting.APICallerFunc(
+		func(objType string,
+			version int,
+			id, request string,
+			a, result interface{},
+		) error {
+			return errors.New("boom")
+		},
+	)
+	err := toolsmirror.NewClient(apiCaller).UpdateToolsMirror()
+	c.Assert(err, gc.ErrorMatches, "boom")
+}
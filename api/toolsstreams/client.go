@@ -0,0 +1,36 @@
+// Copyright 2016 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package toolsstreams
+
+import (
+	"github.com/juju/errors"
+	"github.com/juju/version"
+
+	"github.com/juju/juju/api/base"
+	"github.com/juju/juju/apiserver/params"
+)
+
+// Client provides access to the ToolsStreams API facade.
+type Client struct {
+	facade base.FacadeCaller
+}
+
+// NewClient returns a new Client based on an existing API connection.
+func NewClient(caller base.APICaller) *Client {
+	return &Client{base.NewFacadeCaller(caller, "ToolsStreams")}
+}
+
+// PromoteTools publishes the agent binaries of the given version, for
+// all series and architectures, in the given stream, without uploading
+// them again.
+func (c *Client) PromoteTools(number version.Number, stream string) error {
+	args := params.PromoteToolsArgs{
+		Args: []params.PromoteToolsArg{{Version: number, Stream: stream}},
+	}
+	var results params.ErrorResults
+	if err := c.facade.FacadeCall("PromoteTools", args, &results); err != nil {
+		return errors.Trace(err)
+	}
+	return results.OneError()
+}
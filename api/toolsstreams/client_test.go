@@ -0,0 +1,54 @@
+// Copyright 2016 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package toolsstreams_test
+
+import (
+	jujutesting "github.com/juju/testing"
+	jc "github.com/juju/testing/checkers"
+	"github.com/juju/version"
+	gc "gopkg.in/check.v1"
+
+	basetesting "github.com/juju/juju/api/base/testing"
+	"github.com/juju/juju/api/toolsstreams"
+	"github.com/juju/juju/apiserver/params"
+)
+
+type clientSuite struct {
+	jujutesting.IsolationSuite
+}
+
+var _ = gc.Suite(&clientSuite{})
+
+func (s *clientSuite) TestPromoteTools(c *gc.C) {
+	var called bool
+	apiCaller := basetesting.APICallerFunc(
+		func(objType string,
+			version int,
+			id, request string,
+			a, result interface{},
+		) error {
+			called = true
+			c.Check(objType, gc.Equals, "ToolsStreams")
+			c.Check(id, gc.Equals, "")
+			c.Check(request, gc.Equals, "PromoteTools")
+			c.Check(a, jc.DeepEquals, params.PromoteToolsArgs{
+				Args: []params.PromoteToolsArg{{
+					Version: version.MustParse("2.1.0"),
+					Stream:  "released",
+				}},
+			})
+			c.Assert(result, gc.FitsTypeOf, &params.ErrorResults{})
+			*(result.(*params.ErrorResults)) = params.ErrorResults{
+				Results: []params.ErrorResult{{
+					Error: &params.Error{Message: "bletch"},
+				}},
+			}
+			return nil
+		},
+	)
+	client := toolsstreams.NewClient(apiCaller)
+	err := client.PromoteTools(version.MustParse("2.1.0"), "released")
+	c.Assert(err, gc.ErrorMatches, "bletch")
+	c.Assert(called, jc.IsTrue)
+}
@@ -85,6 +85,27 @@ func (s *Application) Refresh() error {
 	return nil
 }
 
+// Paused returns whether the application has been paused. Units of a
+// paused application should defer running hooks until it is resumed.
+func (s *Application) Paused() (bool, error) {
+	var results params.BoolResults
+	args := params.Entities{
+		Entities: []params.Entity{{Tag: s.tag.String()}},
+	}
+	err := s.st.facade.FacadeCall("ApplicationPaused", args, &results)
+	if err != nil {
+		return false, err
+	}
+	if len(results.Results) != 1 {
+		return false, fmt.Errorf("expected 1 result, got %d", len(results.Results))
+	}
+	result := results.Results[0]
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.Result, nil
+}
+
 // CharmModifiedVersion increments every time the charm, or any part of it, is
 // changed in some way.
 func (s *Application) CharmModifiedVersion() (int, error) {
@@ -122,6 +122,18 @@ func (s *serviceSuite) TestCharmModifiedVersion(c *gc.C) {
 	c.Assert(ver, gc.Equals, s.wordpressService.CharmModifiedVersion())
 }
 
+func (s *serviceSuite) TestPaused(c *gc.C) {
+	paused, err := s.apiService.Paused()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(paused, jc.IsFalse)
+
+	err = s.wordpressService.Pause()
+	c.Assert(err, jc.ErrorIsNil)
+	paused, err = s.apiService.Paused()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(paused, jc.IsTrue)
+}
+
 func (s *serviceSuite) TestSetServiceStatus(c *gc.C) {
 	message := "a test message"
 	stat, err := s.wordpressService.Status()
@@ -5,7 +5,11 @@ package upgrader
 
 import (
 	"fmt"
+	"io"
+	"net/http"
+	"net/url"
 
+	"github.com/juju/errors"
 	"github.com/juju/version"
 
 	"github.com/juju/juju/api/base"
@@ -93,6 +97,31 @@ func (st *State) Tools(tag string) (tools.List, error) {
 	return result.ToolsList, nil
 }
 
+// OpenToolsDelta opens the agent binaries of the given version for
+// download over the API connection, asking the controller for a delta
+// from the given base binaries, which the agent already has. The
+// controller may return the full tarball instead; isDelta reports
+// whether the returned content is a delta.
+func (st *State) OpenToolsDelta(v version.Binary, base *tools.Tools) (_ io.ReadCloser, isDelta bool, err error) {
+	query := url.Values{}
+	query.Set("base-version", base.Version.String())
+	query.Set("base-sha256", base.SHA256)
+	req, err := http.NewRequest("GET", "/tools/"+v.String()+"?"+query.Encode(), nil)
+	if err != nil {
+		return nil, false, errors.Annotate(err, "cannot create tools request")
+	}
+	httpClient, err := st.facade.RawAPICaller().HTTPClient()
+	if err != nil {
+		return nil, false, errors.Annotate(err, "cannot retrieve HTTP client")
+	}
+	var resp *http.Response
+	if err := httpClient.Do(req, nil, &resp); err != nil {
+		return nil, false, errors.Annotate(err, "cannot retrieve tools")
+	}
+	isDelta = resp.Header.Get("Content-Type") == params.ContentTypeToolsDelta
+	return resp.Body, isDelta, nil
+}
+
 func (st *State) WatchAPIVersion(agentTag string) (watcher.NotifyWatcher, error) {
 	var results params.NotifyWatchResults
 	args := params.Entities{
@@ -4,10 +4,14 @@
 package upgrader_test
 
 import (
+	"bytes"
 	"fmt"
+	"io/ioutil"
+	"strings"
 
 	"github.com/juju/errors"
 	jc "github.com/juju/testing/checkers"
+	"github.com/juju/utils"
 	"github.com/juju/version"
 	gc "gopkg.in/check.v1"
 
@@ -16,9 +20,11 @@ import (
 	"github.com/juju/juju/apiserver/params"
 	"github.com/juju/juju/juju/testing"
 	"github.com/juju/juju/state"
+	"github.com/juju/juju/state/binarystorage"
 	statetesting "github.com/juju/juju/state/testing"
 	coretesting "github.com/juju/juju/testing"
 	"github.com/juju/juju/tools"
+	"github.com/juju/juju/tools/delta"
 	"github.com/juju/juju/watcher/watchertest"
 )
 
@@ -105,6 +111,59 @@ func (s *machineUpgraderSuite) TestTools(c *gc.C) {
 	c.Assert(stateTools.URL, gc.Equals, url)
 }
 
+func (s *machineUpgraderSuite) storeTools(c *gc.C, v string, jujud string) (*tools.Tools, []byte) {
+	data, sha256 := coretesting.TarGz(coretesting.NewTarFile("jujud", 0755, jujud))
+	storage, err := s.State.ToolsStorage()
+	c.Assert(err, jc.ErrorIsNil)
+	defer storage.Close()
+	err = storage.Add(bytes.NewReader(data), binarystorage.Metadata{
+		Version: v,
+		Size:    int64(len(data)),
+		SHA256:  sha256,
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	return &tools.Tools{
+		Version: version.MustParseBinary(v),
+		Size:    int64(len(data)),
+		SHA256:  sha256,
+	}, data
+}
+
+func (s *machineUpgraderSuite) TestOpenToolsDelta(c *gc.C) {
+	// The random content makes the delta smaller than the tarball.
+	jujud := strings.Repeat("0123456789abcdef", 4096) + utils.RandomString(1024, utils.LowerAlpha)
+	base, _ := s.storeTools(c, "2.0.0-trusty-amd64", jujud)
+	target, targetData := s.storeTools(c, "2.0.1-trusty-amd64", jujud+"!")
+
+	r, isDelta, err := s.st.OpenToolsDelta(target.Version, base)
+	c.Assert(err, jc.ErrorIsNil)
+	defer r.Close()
+	c.Assert(isDelta, jc.IsTrue)
+	files, err := delta.ApplyTools(r, func(name string) ([]byte, error) {
+		c.Assert(name, gc.Equals, "jujud")
+		return []byte(jujud), nil
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	targetFiles, err := delta.ReadTarball(bytes.NewReader(targetData))
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(files, jc.DeepEquals, targetFiles)
+}
+
+func (s *machineUpgraderSuite) TestOpenToolsDeltaBaseNotFound(c *gc.C) {
+	target, targetData := s.storeTools(c, "2.0.1-trusty-amd64", "jujud")
+	base := &tools.Tools{
+		Version: version.MustParseBinary("2.0.0-trusty-amd64"),
+		SHA256:  "abc",
+	}
+	r, isDelta, err := s.st.OpenToolsDelta(target.Version, base)
+	c.Assert(err, jc.ErrorIsNil)
+	defer r.Close()
+	c.Assert(isDelta, jc.IsFalse)
+	data, err := ioutil.ReadAll(r)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(data, jc.DeepEquals, targetData)
+}
+
 func (s *machineUpgraderSuite) TestWatchAPIVersion(c *gc.C) {
 	w, err := s.st.WatchAPIVersion(s.rawMachine.Tag().String())
 	c.Assert(err, jc.ErrorIsNil)
@@ -54,7 +54,8 @@ func (a *admin) login(req params.LoginRequest, loginVersion int) (params.LoginRe
 	}
 
 	// apiRoot is the API root exposed to the client after authentication.
-	var apiRoot rpc.Root = newAPIRoot(a.root.state, a.root.resources, a.root)
+	authedRoot := newAPIRoot(a.root.state, a.root.resources, a.root)
+	var apiRoot rpc.Root = authedRoot
 
 	// Use the login validation function, if one was specified.
 	if a.srv.validator != nil {
@@ -143,7 +144,23 @@ func (a *admin) login(req params.LoginRequest, loginVersion int) (params.LoginRe
 	a.loggedIn = true
 
 	if !controllerMachineLogin {
-		if err := startPingerIfAgent(a.srv.clock, a.root, entity); err != nil {
+		if err := startPingerIfAgent(a.srv.clock, a.srv.pingTimeout, a.root, entity); err != nil {
+			return fail, errors.Trace(err)
+		}
+		if kind == names.MachineTagKind && !controllerOnlyLogin {
+			// Machine agents may make requests on behalf of the
+			// units on their machines, sharing their connection.
+			delegator := &unitDelegator{
+				st:        a.root.state,
+				clock:     a.srv.clock,
+				resources: a.root.resources,
+				machine:   a.root,
+			}
+			authedRoot.delegate = delegator.delegate
+		}
+	}
+	if isUser && a.srv.pingTimeoutUsers {
+		if err := startPingTimeout(a.srv.clock, a.srv.pingTimeout, a.root); err != nil {
 			return fail, errors.Trace(err)
 		}
 	}
@@ -214,6 +231,7 @@ func (a *admin) login(req params.LoginRequest, loginVersion int) (params.LoginRe
 		ControllerTag: model.ControllerTag().String(),
 		UserInfo:      maybeUserInfo,
 		ServerVersion: jujuversion.Current.String(),
+		CanDelegate:   authedRoot.delegate != nil,
 	}
 
 	if controllerOnlyLogin {
@@ -501,27 +519,19 @@ func (shim presenceShim) Start() (presence.Pinger, error) {
 	return pinger, nil
 }
 
-func startPingerIfAgent(clock clock.Clock, root *apiHandler, entity state.Entity) error {
+func startPingerIfAgent(clock clock.Clock, pingTimeout time.Duration, root *apiHandler, entity state.Entity) error {
 	// worker runs presence.Pingers -- absence of which will cause
 	// embarrassing "agent is lost" messages to show up in status --
 	// until it's stopped. It's stored in resources purely for the
 	// side effects: we don't record its id, and nobody else
 	// retrieves it -- we just expect it to be stopped when the
 	// connection is shut down.
-	agent, ok := entity.(statepresence.Agent)
-	if !ok {
+	if _, ok := entity.(statepresence.Agent); !ok {
 		return nil
 	}
-	worker, err := presence.New(presence.Config{
-		Identity:   entity.Tag(),
-		Start:      presenceShim{agent}.Start,
-		Clock:      clock,
-		RetryDelay: 3 * time.Second,
-	})
-	if err != nil {
+	if _, err := startPresence(clock, root.getResources(), entity); err != nil {
 		return err
 	}
-	root.getResources().Register(worker)
 
 	// pingTimeout, by contrast, *is* used by the Pinger facade to
 	// stave off the call to action() that will shut down the agent
@@ -533,13 +543,41 @@ func startPingerIfAgent(clock clock.Clock, root *apiHandler, entity state.Entity
 	// but only as a relatively distant consequence).
 	//
 	// We should have picked better names...
+	return startPingTimeout(clock, pingTimeout, root)
+}
+
+// startPresence starts a worker that maintains the presence of the
+// given entity, if it is an agent, until the resources are stopped,
+// and returns the worker's resource id. If the entity is not an
+// agent, no worker is started and the returned id is empty.
+func startPresence(clock clock.Clock, resources *common.Resources, entity state.Entity) (string, error) {
+	agent, ok := entity.(statepresence.Agent)
+	if !ok {
+		return "", nil
+	}
+	worker, err := presence.New(presence.Config{
+		Identity:   entity.Tag(),
+		Start:      presenceShim{agent}.Start,
+		Clock:      clock,
+		RetryDelay: 3 * time.Second,
+	})
+	if err != nil {
+		return "", err
+	}
+	return resources.Register(worker), nil
+}
+
+// startPingTimeout starts a pingTimeout for the connection, which
+// will close the connection if the client does not send keepalive
+// Pings at least as often as the given timeout.
+func startPingTimeout(clock clock.Clock, timeout time.Duration, root *apiHandler) error {
 	action := func() {
 		logger.Debugf("closing connection due to ping timout")
 		if err := root.getRpcConn().Close(); err != nil {
 			logger.Errorf("error closing the RPC connection: %v", err)
 		}
 	}
-	pingTimeout := newPingTimeout(action, clock, maxClientPingInterval)
+	pingTimeout := newPingTimeout(action, clock, timeout)
 	return root.getResources().RegisterNamed("pingTimeout", pingTimeout)
 }
 
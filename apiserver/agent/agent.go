@@ -158,6 +158,40 @@ func stateJobsToAPIParamsJobs(jobs []state.MachineJob) []multiwatcher.MachineJob
 	return pjobs
 }
 
+// ModelCredential returns the tag of the cloud credential used by the
+// model, so that the credential may be watched for changes.
+func (api *AgentAPIV2) ModelCredential() (params.ModelCredential, error) {
+	if !api.auth.AuthModelManager() {
+		return params.ModelCredential{}, common.ErrPerm
+	}
+	model, err := api.st.Model()
+	if err != nil {
+		return params.ModelCredential{}, errors.Trace(err)
+	}
+	result := params.ModelCredential{Model: model.ModelTag().String()}
+	if tag, ok := model.CloudCredential(); ok {
+		credential, err := api.st.CloudCredential(tag)
+		if err != nil {
+			return params.ModelCredential{}, errors.Trace(err)
+		}
+		result.CloudCredential = tag.String()
+		result.Invalid = credential.Invalid
+		result.InvalidReason = credential.InvalidReason
+	}
+	return result, nil
+}
+
+// InvalidateModelCredential records that the cloud has rejected the
+// model's cloud credential, and suspends the model until the credential
+// is updated.
+func (api *AgentAPIV2) InvalidateModelCredential(args params.InvalidateCredentialArg) (params.ErrorResult, error) {
+	if !api.auth.AuthModelManager() {
+		return params.ErrorResult{}, common.ErrPerm
+	}
+	err := api.st.InvalidateModelCredential(args.Reason)
+	return params.ErrorResult{Error: common.ServerError(err)}, nil
+}
+
 // WatchCredentials watches for changes to the specified credentials.
 func (api *AgentAPIV2) WatchCredentials(args params.Entities) (params.NotifyWatchResults, error) {
 	if !api.auth.AuthModelManager() {
@@ -20,6 +20,7 @@ import (
 	"github.com/juju/juju/state"
 	"github.com/juju/juju/state/multiwatcher"
 	statetesting "github.com/juju/juju/state/testing"
+	"github.com/juju/juju/status"
 	coretesting "github.com/juju/juju/testing"
 )
 
@@ -265,6 +266,74 @@ func (s *agentSuite) TestWatchCredentials(c *gc.C) {
 	wc.AssertOneChange()
 }
 
+func (s *agentSuite) TestModelCredential(c *gc.C) {
+	authorizer := apiservertesting.FakeAuthorizer{
+		Tag:            names.NewMachineTag("0"),
+		EnvironManager: true,
+	}
+	api, err := agent.NewAgentAPIV2(s.State, s.resources, authorizer)
+	c.Assert(err, jc.ErrorIsNil)
+	result, err := api.ModelCredential()
+	c.Assert(err, jc.ErrorIsNil)
+
+	model, err := s.State.Model()
+	c.Assert(err, jc.ErrorIsNil)
+	expect := params.ModelCredential{Model: model.ModelTag().String()}
+	if tag, ok := model.CloudCredential(); ok {
+		expect.CloudCredential = tag.String()
+	}
+	c.Assert(result, jc.DeepEquals, expect)
+}
+
+func (s *agentSuite) TestModelCredentialAuthError(c *gc.C) {
+	authorizer := apiservertesting.FakeAuthorizer{
+		Tag:            names.NewMachineTag("1"),
+		EnvironManager: false,
+	}
+	api, err := agent.NewAgentAPIV2(s.State, s.resources, authorizer)
+	c.Assert(err, jc.ErrorIsNil)
+	_, err = api.ModelCredential()
+	c.Assert(err, gc.ErrorMatches, "permission denied")
+}
+
+func (s *agentSuite) TestInvalidateModelCredential(c *gc.C) {
+	authorizer := apiservertesting.FakeAuthorizer{
+		Tag:            names.NewMachineTag("0"),
+		EnvironManager: true,
+	}
+	api, err := agent.NewAgentAPIV2(s.State, s.resources, authorizer)
+	c.Assert(err, jc.ErrorIsNil)
+	result, err := api.InvalidateModelCredential(params.InvalidateCredentialArg{
+		Reason: "secret expired",
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(result.Error, gc.IsNil)
+
+	credential, err := api.ModelCredential()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(credential.Invalid, jc.IsTrue)
+	c.Assert(credential.InvalidReason, gc.Equals, "secret expired")
+
+	model, err := s.State.Model()
+	c.Assert(err, jc.ErrorIsNil)
+	info, err := model.Status()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(info.Status, gc.Equals, status.Suspended)
+}
+
+func (s *agentSuite) TestInvalidateModelCredentialAuthError(c *gc.C) {
+	authorizer := apiservertesting.FakeAuthorizer{
+		Tag:            names.NewMachineTag("1"),
+		EnvironManager: false,
+	}
+	api, err := agent.NewAgentAPIV2(s.State, s.resources, authorizer)
+	c.Assert(err, jc.ErrorIsNil)
+	_, err = api.InvalidateModelCredential(params.InvalidateCredentialArg{
+		Reason: "secret expired",
+	})
+	c.Assert(err, gc.ErrorMatches, "permission denied")
+}
+
 func (s *agentSuite) TestWatchAuthError(c *gc.C) {
 	authorizer := apiservertesting.FakeAuthorizer{
 		Tag:            names.NewMachineTag("1"),
@@ -14,16 +14,19 @@ import (
 	_ "github.com/juju/juju/apiserver/agent"
 	_ "github.com/juju/juju/apiserver/agenttools"
 	_ "github.com/juju/juju/apiserver/annotations" // ModelUser Write
+	_ "github.com/juju/juju/apiserver/apimetrics"  // Controller Superuser
 	_ "github.com/juju/juju/apiserver/application" // ModelUser Write
 	_ "github.com/juju/juju/apiserver/applicationscaler"
 	_ "github.com/juju/juju/apiserver/backups" // ModelUser Write
-	_ "github.com/juju/juju/apiserver/block"   // ModelUser Write
+	_ "github.com/juju/juju/apiserver/blobjanitor"
+	_ "github.com/juju/juju/apiserver/block" // ModelUser Write
 	_ "github.com/juju/juju/apiserver/charmrevisionupdater"
 	_ "github.com/juju/juju/apiserver/charms" // ModelUser Write
 	_ "github.com/juju/juju/apiserver/cleaner"
-	_ "github.com/juju/juju/apiserver/client"     // ModelUser Write
-	_ "github.com/juju/juju/apiserver/cloud"      // ModelUser Read
-	_ "github.com/juju/juju/apiserver/controller" // ModelUser Admin (although some methods check for read only)
+	_ "github.com/juju/juju/apiserver/client"               // ModelUser Write
+	_ "github.com/juju/juju/apiserver/cloud"                // ModelUser Read
+	_ "github.com/juju/juju/apiserver/constraintsvalidator" // ModelUser Read
+	_ "github.com/juju/juju/apiserver/controller"           // ModelUser Admin (although some methods check for read only)
 	_ "github.com/juju/juju/apiserver/deployer"
 	_ "github.com/juju/juju/apiserver/discoverspaces"
 	_ "github.com/juju/juju/apiserver/diskmanager"
@@ -42,6 +45,7 @@ import (
 	_ "github.com/juju/juju/apiserver/machine"
 	_ "github.com/juju/juju/apiserver/machineactions"
 	_ "github.com/juju/juju/apiserver/machinemanager" // ModelUser Write
+	_ "github.com/juju/juju/apiserver/machinepower"   // ModelUser Write
 	_ "github.com/juju/juju/apiserver/machineundertaker"
 	_ "github.com/juju/juju/apiserver/meterstatus"
 	_ "github.com/juju/juju/apiserver/metricsadder"
@@ -53,9 +57,12 @@ import (
 	_ "github.com/juju/juju/apiserver/migrationtarget" // ModelUser Write
 	_ "github.com/juju/juju/apiserver/modelconfig"     // ModelUser Write
 	_ "github.com/juju/juju/apiserver/modelmanager"    // ModelUser Write
+	_ "github.com/juju/juju/apiserver/providerresources"
 	_ "github.com/juju/juju/apiserver/provisioner"
 	_ "github.com/juju/juju/apiserver/proxyupdater"
 	_ "github.com/juju/juju/apiserver/reboot"
+	_ "github.com/juju/juju/apiserver/relationscopegc"
+	_ "github.com/juju/juju/apiserver/resourcesweeper"
 	_ "github.com/juju/juju/apiserver/resumer"
 	_ "github.com/juju/juju/apiserver/retrystrategy"
 	_ "github.com/juju/juju/apiserver/singular"
@@ -65,6 +72,9 @@ import (
 	_ "github.com/juju/juju/apiserver/storage" // ModelUser Write
 	_ "github.com/juju/juju/apiserver/storageprovisioner"
 	_ "github.com/juju/juju/apiserver/subnets"
+	_ "github.com/juju/juju/apiserver/toolsgc"
+	_ "github.com/juju/juju/apiserver/toolsmirror"
+	_ "github.com/juju/juju/apiserver/toolsstreams"
 	_ "github.com/juju/juju/apiserver/undertaker"
 	_ "github.com/juju/juju/apiserver/unitassigner"
 	_ "github.com/juju/juju/apiserver/uniter"
@@ -0,0 +1,80 @@
+// Copyright 2016 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+// Package apimetrics implements the API endpoint for inspecting the
+// API server's log of slow API calls.
+package apimetrics
+
+import (
+	"github.com/juju/errors"
+	"gopkg.in/juju/names.v2"
+
+	"github.com/juju/juju/apiserver/common"
+	"github.com/juju/juju/apiserver/facade"
+	"github.com/juju/juju/apiserver/observer"
+	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/permission"
+	"github.com/juju/juju/state"
+)
+
+func init() {
+	common.RegisterStandardFacade("APIMetrics", 1, newFacade)
+}
+
+// SlowCallLog provides the calls in an API server's slow call log.
+// It is implemented by *observer.APIMetrics.
+type SlowCallLog interface {
+	SlowCalls() []observer.SlowCall
+}
+
+// Facade implements the APIMetrics API. Only controller
+// administrators may inspect the slow call log.
+type Facade struct {
+	log SlowCallLog
+}
+
+func newFacade(st *state.State, resources facade.Resources, authorizer facade.Authorizer) (*Facade, error) {
+	log, ok := resources.Get(observer.APIMetricsResourceName).(*observer.APIMetrics)
+	if !ok {
+		return nil, errors.NotSupportedf("API metrics")
+	}
+	return New(log, authorizer, st.ControllerTag())
+}
+
+// New returns a new APIMetrics API facade.
+func New(log SlowCallLog, authorizer facade.Authorizer, controllerTag names.ControllerTag) (*Facade, error) {
+	if !authorizer.AuthClient() {
+		return nil, common.ErrPerm
+	}
+	isAdmin, err := authorizer.HasPermission(permission.SuperuserAccess, controllerTag)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	if !isAdmin {
+		return nil, common.ErrPerm
+	}
+	return &Facade{log: log}, nil
+}
+
+// SlowCalls returns the calls in the API server's slow call log,
+// oldest first. The log holds the most recent calls that took longer
+// than the server's slow call threshold to complete.
+func (f *Facade) SlowCalls() params.SlowCallsResult {
+	calls := f.log.SlowCalls()
+	result := params.SlowCallsResult{
+		Calls: make([]params.SlowCall, len(calls)),
+	}
+	for i, call := range calls {
+		result.Calls[i] = params.SlowCall{
+			Time:      call.Time,
+			Duration:  call.Duration,
+			Facade:    call.Facade,
+			Version:   call.Version,
+			Method:    call.Method,
+			ModelUUID: call.ModelUUID,
+			Entity:    call.Entity,
+			Error:     call.Error,
+		}
+	}
+	return result
+}
@@ -0,0 +1,104 @@
+// Copyright 2016 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package apimetrics_test
+
+import (
+	"time"
+
+	jujutesting "github.com/juju/testing"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+	"gopkg.in/juju/names.v2"
+
+	"github.com/juju/juju/apiserver/apimetrics"
+	"github.com/juju/juju/apiserver/common"
+	"github.com/juju/juju/apiserver/observer"
+	"github.com/juju/juju/apiserver/params"
+	apiservertesting "github.com/juju/juju/apiserver/testing"
+	coretesting "github.com/juju/juju/testing"
+)
+
+type apiMetricsSuite struct {
+	jujutesting.IsolationSuite
+	log        slowCallLog
+	authorizer apiservertesting.FakeAuthorizer
+}
+
+var _ = gc.Suite(&apiMetricsSuite{})
+
+func (s *apiMetricsSuite) SetUpTest(c *gc.C) {
+	s.IsolationSuite.SetUpTest(c)
+	s.log = slowCallLog{{
+		Time:      time.Date(2016, 10, 1, 0, 0, 0, 0, time.UTC),
+		Duration:  3 * time.Second,
+		Facade:    "Client",
+		Version:   1,
+		Method:    "FullStatus",
+		ModelUUID: coretesting.ModelTag.Id(),
+		Entity:    "user-bob",
+	}, {
+		Time:     time.Date(2016, 10, 1, 0, 1, 0, 0, time.UTC),
+		Duration: 2 * time.Second,
+		Facade:   "Uniter",
+		Version:  4,
+		Method:   "Watch",
+		Entity:   "unit-mysql-0",
+		Error:    "boom",
+	}}
+	s.authorizer = apiservertesting.FakeAuthorizer{
+		Tag: names.NewUserTag("admin"),
+	}
+}
+
+func (s *apiMetricsSuite) TestNewNotClient(c *gc.C) {
+	s.authorizer.Tag = names.NewMachineTag("0")
+	_, err := apimetrics.New(s.log, &s.authorizer, coretesting.ControllerTag)
+	c.Assert(err, gc.Equals, common.ErrPerm)
+}
+
+func (s *apiMetricsSuite) TestNewNotControllerAdmin(c *gc.C) {
+	s.authorizer.Tag = names.NewUserTag("bob")
+	_, err := apimetrics.New(s.log, &s.authorizer, coretesting.ControllerTag)
+	c.Assert(err, gc.Equals, common.ErrPerm)
+}
+
+func (s *apiMetricsSuite) TestSlowCalls(c *gc.C) {
+	facade, err := apimetrics.New(s.log, &s.authorizer, coretesting.ControllerTag)
+	c.Assert(err, jc.ErrorIsNil)
+	result := facade.SlowCalls()
+	c.Assert(result, jc.DeepEquals, params.SlowCallsResult{
+		Calls: []params.SlowCall{{
+			Time:      time.Date(2016, 10, 1, 0, 0, 0, 0, time.UTC),
+			Duration:  3 * time.Second,
+			Facade:    "Client",
+			Version:   1,
+			Method:    "FullStatus",
+			ModelUUID: coretesting.ModelTag.Id(),
+			Entity:    "user-bob",
+		}, {
+			Time:     time.Date(2016, 10, 1, 0, 1, 0, 0, time.UTC),
+			Duration: 2 * time.Second,
+			Facade:   "Uniter",
+			Version:  4,
+			Method:   "Watch",
+			Entity:   "unit-mysql-0",
+			Error:    "boom",
+		}},
+	})
+}
+
+func (s *apiMetricsSuite) TestSlowCallsEmpty(c *gc.C) {
+	facade, err := apimetrics.New(slowCallLog{}, &s.authorizer, coretesting.ControllerTag)
+	c.Assert(err, jc.ErrorIsNil)
+	result := facade.SlowCalls()
+	c.Assert(result, jc.DeepEquals, params.SlowCallsResult{
+		Calls: []params.SlowCall{},
+	})
+}
+
+type slowCallLog []observer.SlowCall
+
+func (l slowCallLog) SlowCalls() []observer.SlowCall {
+	return l
+}
@@ -6,17 +6,20 @@ package apiserver
 import (
 	"crypto/tls"
 	"crypto/x509"
+	"fmt"
 	"net"
 	"net/http"
 	"strings"
 	"sync"
 	"sync/atomic"
+	"time"
 
 	"github.com/bmizerany/pat"
 	"github.com/juju/errors"
 	"github.com/juju/loggo"
 	"github.com/juju/utils"
 	"github.com/juju/utils/clock"
+	"github.com/prometheus/client_golang/prometheus"
 	"golang.org/x/crypto/acme"
 	"golang.org/x/crypto/acme/autocert"
 	"golang.org/x/net/websocket"
@@ -32,6 +35,7 @@ import (
 	"github.com/juju/juju/rpc"
 	"github.com/juju/juju/rpc/jsoncodec"
 	"github.com/juju/juju/state"
+	"github.com/juju/juju/state/watcher"
 )
 
 var logger = loggo.GetLogger("juju.apiserver")
@@ -62,6 +66,26 @@ type Server struct {
 	certChanged       <-chan params.StateServingInfo
 	tlsConfig         *tls.Config
 
+	// pingTimeout is how long the server waits for a Ping
+	// before closing a connection, and pingTimeoutUsers
+	// records whether it applies to user connections.
+	pingTimeout      time.Duration
+	pingTimeoutUsers bool
+
+	// allWatcherSessions holds the resumable AllWatcher
+	// sessions of all connections.
+	allWatcherSessions *common.AllWatcherSessions
+
+	// apiMetrics records the API calls handled by the server,
+	// and metricsRegisterer, if non-nil, is the registerer with
+	// which apiMetrics is registered while the server runs.
+	apiMetrics        *observer.APIMetrics
+	metricsRegisterer MetricsRegisterer
+
+	// toolsDeltas caches the deltas between agent binaries
+	// served to upgrading agents.
+	toolsDeltas *toolsDeltaCache
+
 	// mu guards the fields below it.
 	mu sync.Mutex
 
@@ -72,6 +96,20 @@ type Server struct {
 	certDNSNames []string
 }
 
+// MetricsRegisterer is an interface for registering and unregistering
+// Prometheus metrics collectors. The API server registers its collector
+// when it starts, and unregisters it when it stops.
+type MetricsRegisterer interface {
+	// Register registers the given collector, returning an
+	// error if it, or a collector with the same descriptors,
+	// is already registered.
+	Register(prometheus.Collector) error
+
+	// Unregister unregisters the given collector, returning
+	// true if the collector was registered.
+	Unregister(prometheus.Collector) bool
+}
+
 // LoginValidator functions are used to decide whether login requests
 // are to be allowed. The validator is called before credentials are
 // checked.
@@ -105,6 +143,40 @@ type ServerConfig struct {
 
 	// StatePool only exists to support testing.
 	StatePool *state.StatePool
+
+	// PingTimeout is how long the server waits for a Ping from a
+	// client before closing its connection. If zero, a default of
+	// 3 minutes is used.
+	PingTimeout time.Duration
+
+	// PingTimeoutUsers, if true, applies the ping timeout to
+	// connections from users as well as agents. Closing dead
+	// user connections promptly allows their AllWatcher sessions
+	// to be resumed sooner.
+	PingTimeoutUsers bool
+
+	// AllWatcherSessionTimeout is how long an AllWatcher session is
+	// retained after its connection is closed, awaiting resumption
+	// by the client. If zero, a default of 5 minutes is used.
+	AllWatcherSessionTimeout time.Duration
+
+	// AllWatcherReplaySize is the maximum number of delta batches
+	// retained by each AllWatcher session for replay to a resuming
+	// client. If zero, a default of 100 is used.
+	AllWatcherReplaySize int
+
+	// SlowCallThreshold is the minimum duration of an API call for
+	// it to be recorded in the server's slow call log. If zero, a
+	// default of 1 second is used.
+	SlowCallThreshold time.Duration
+
+	// SlowCallLogSize is the maximum number of calls retained in the
+	// server's slow call log. If zero, a default of 100 is used.
+	SlowCallLogSize int
+
+	// MetricsRegisterer, if non-nil, is used to register the
+	// server's API call metrics with Prometheus.
+	MetricsRegisterer MetricsRegisterer
 }
 
 func (c *ServerConfig) Validate() error {
@@ -114,6 +186,21 @@ func (c *ServerConfig) Validate() error {
 	if c.NewObserver == nil {
 		return errors.NotValidf("missing NewObserver")
 	}
+	if c.PingTimeout < 0 {
+		return errors.NotValidf("negative PingTimeout")
+	}
+	if c.AllWatcherSessionTimeout < 0 {
+		return errors.NotValidf("negative AllWatcherSessionTimeout")
+	}
+	if c.AllWatcherReplaySize < 0 {
+		return errors.NotValidf("negative AllWatcherReplaySize")
+	}
+	if c.SlowCallThreshold < 0 {
+		return errors.NotValidf("negative SlowCallThreshold")
+	}
+	if c.SlowCallLogSize < 0 {
+		return errors.NotValidf("negative SlowCallLogSize")
+	}
 
 	return nil
 }
@@ -147,11 +234,44 @@ func newServer(s *state.State, lis net.Listener, cfg ServerConfig) (_ *Server, e
 	if stPool == nil {
 		stPool = state.NewStatePool(s)
 	}
+	pingTimeout := cfg.PingTimeout
+	if pingTimeout == 0 {
+		pingTimeout = maxClientPingInterval
+	}
+	allWatcherSessionsConfig := common.AllWatcherSessionsConfig{
+		Clock:      cfg.Clock,
+		Timeout:    cfg.AllWatcherSessionTimeout,
+		ReplaySize: cfg.AllWatcherReplaySize,
+	}
+	if allWatcherSessionsConfig.Timeout == 0 {
+		allWatcherSessionsConfig.Timeout = defaultAllWatcherSessionTimeout
+	}
+	if allWatcherSessionsConfig.ReplaySize == 0 {
+		allWatcherSessionsConfig.ReplaySize = defaultAllWatcherReplaySize
+	}
+	if err := allWatcherSessionsConfig.Validate(); err != nil {
+		return nil, errors.Trace(err)
+	}
+	apiMetricsConfig := observer.APIMetricsConfig{
+		Clock:             cfg.Clock,
+		SlowCallThreshold: cfg.SlowCallThreshold,
+		SlowCallLogSize:   cfg.SlowCallLogSize,
+	}
+	if apiMetricsConfig.SlowCallThreshold == 0 {
+		apiMetricsConfig.SlowCallThreshold = defaultSlowCallThreshold
+	}
+	if apiMetricsConfig.SlowCallLogSize == 0 {
+		apiMetricsConfig.SlowCallLogSize = defaultSlowCallLogSize
+	}
+	apiMetrics, err := observer.NewAPIMetrics(apiMetricsConfig)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
 
 	srv := &Server{
 		clock:       cfg.Clock,
 		lis:         lis,
-		newObserver: cfg.NewObserver,
+		newObserver: observer.ObserverFactoryMultiplexer(cfg.NewObserver, apiMetrics.NewObserver),
 		state:       s,
 		statePool:   stPool,
 		tag:         cfg.Tag,
@@ -162,7 +282,12 @@ func newServer(s *state.State, lis net.Listener, cfg ServerConfig) (_ *Server, e
 		adminAPIFactories: map[int]adminAPIFactory{
 			3: newAdminAPIV3,
 		},
-		certChanged: cfg.CertChanged,
+		certChanged:       cfg.CertChanged,
+		pingTimeout:       pingTimeout,
+		pingTimeoutUsers:  cfg.PingTimeoutUsers,
+		apiMetrics:        apiMetrics,
+		metricsRegisterer: cfg.MetricsRegisterer,
+		toolsDeltas:       &toolsDeltaCache{},
 	}
 
 	srv.tlsConfig = srv.newTLSConfig(cfg)
@@ -175,6 +300,20 @@ func newServer(s *state.State, lis net.Listener, cfg ServerConfig) (_ *Server, e
 	if err := srv.updateCertificate(cfg.Cert, cfg.Key); err != nil {
 		return nil, errors.Annotatef(err, "cannot set initial certificate")
 	}
+	if srv.metricsRegisterer != nil {
+		if err := srv.metricsRegisterer.Register(srv.apiMetrics); err != nil {
+			return nil, errors.Annotate(err, "registering metrics")
+		}
+	}
+	// The sessions are created last, as their reaper
+	// runs until they are closed when the server stops.
+	srv.allWatcherSessions, err = common.NewAllWatcherSessions(allWatcherSessionsConfig)
+	if err != nil {
+		if srv.metricsRegisterer != nil {
+			srv.metricsRegisterer.Unregister(srv.apiMetrics)
+		}
+		return nil, errors.Trace(err)
+	}
 	go srv.run()
 	return srv, nil
 }
@@ -254,6 +393,10 @@ func (srv *Server) run() {
 
 		srv.state.HackLeadership() // Break deadlocks caused by BlockUntil... calls.
 		srv.wg.Wait()              // wait for any outstanding requests to complete.
+		srv.allWatcherSessions.CloseAll()
+		if srv.metricsRegisterer != nil {
+			srv.metricsRegisterer.Unregister(srv.apiMetrics)
+		}
 		srv.tomb.Done()
 		srv.statePool.Close()
 		srv.state.Close()
@@ -277,6 +420,12 @@ func (srv *Server) run() {
 		srv.tomb.Kill(srv.processCertChanges())
 	}()
 
+	srv.wg.Add(1)
+	go func() {
+		defer srv.wg.Done()
+		srv.tomb.Kill(srv.processModelRemovals())
+	}()
+
 	// for pat based handlers, they are matched in-order of being
 	// registered, first match wins. So more specific ones have to be
 	// registered first.
@@ -351,6 +500,16 @@ func (srv *Server) endpoints() []apihttp.Endpoint {
 			ctxt: httpCtxt,
 		},
 	)
+	add("/model/:modeluuid/tools-mirror/streams/v1/:file",
+		&toolsMirrorHandler{
+			ctxt: httpCtxt,
+		},
+	)
+	add("/model/:modeluuid/tools-mirror/tools/:version",
+		&toolsDownloadHandler{
+			ctxt: httpCtxt,
+		},
+	)
 	add("/model/:modeluuid/backups",
 		&backupHandler{
 			ctxt: strictCtxt,
@@ -385,11 +544,28 @@ func (srv *Server) endpoints() []apihttp.Endpoint {
 			ctxt: httpCtxt,
 		},
 	)
+	add("/tools-mirror/streams/v1/:file",
+		&toolsMirrorHandler{
+			ctxt: httpCtxt,
+		},
+	)
+	add("/tools-mirror/tools/:version",
+		&toolsDownloadHandler{
+			ctxt: httpCtxt,
+		},
+	)
 	add("/register",
 		&registerUserHandler{
 			ctxt: httpCtxt,
 		},
 	)
+	controllerCtxt := httpCtxt
+	controllerCtxt.controllerModelOnly = true
+	add("/introspection/:name",
+		&introspectionHandler{
+			ctxt: controllerCtxt,
+		},
+	)
 	add("/api", mainAPIHandler)
 	// Serve the API at / (only) for backward compatiblity. Note that the
 	// pat muxer special-cases / so that it does not serve all
@@ -522,7 +698,7 @@ func (srv *Server) apiHandler(w http.ResponseWriter, req *http.Request) {
 		Handler: func(conn *websocket.Conn) {
 			modelUUID := req.URL.Query().Get(":modeluuid")
 			logger.Tracef("got a request for model %q", modelUUID)
-			if err := srv.serveConn(conn, modelUUID, apiObserver, req.Host); err != nil {
+			if err := srv.serveConn(conn, modelUUID, connectionID, apiObserver, req.Host); err != nil {
 				logger.Errorf("error serving RPCs: %v", err)
 			}
 		},
@@ -530,12 +706,12 @@ func (srv *Server) apiHandler(w http.ResponseWriter, req *http.Request) {
 	wsServer.ServeHTTP(w, req)
 }
 
-func (srv *Server) serveConn(wsConn *websocket.Conn, modelUUID string, apiObserver observer.Observer, host string) error {
+func (srv *Server) serveConn(wsConn *websocket.Conn, modelUUID string, connectionID uint64, apiObserver observer.Observer, host string) error {
 	codec := jsoncodec.NewWebsocket(wsConn)
 
 	conn := rpc.NewConn(codec, apiObserver)
 
-	h, err := srv.newAPIHandler(conn, modelUUID, host)
+	h, err := srv.newAPIHandler(conn, modelUUID, connectionID, host)
 	if err != nil {
 		conn.ServeRoot(&errRoot{err}, serverError)
 	} else {
@@ -553,7 +729,7 @@ func (srv *Server) serveConn(wsConn *websocket.Conn, modelUUID string, apiObserv
 	return conn.Close()
 }
 
-func (srv *Server) newAPIHandler(conn *rpc.Conn, modelUUID, serverHost string) (*apiHandler, error) {
+func (srv *Server) newAPIHandler(conn *rpc.Conn, modelUUID string, connectionID uint64, serverHost string) (*apiHandler, error) {
 	// Note that we don't overwrite modelUUID here because
 	// newAPIHandler treats an empty modelUUID as signifying
 	// the API version used.
@@ -568,6 +744,9 @@ func (srv *Server) newAPIHandler(conn *rpc.Conn, modelUUID, serverHost string) (
 	if err != nil {
 		return nil, errors.Trace(err)
 	}
+	// The connection's watches are registered on its behalf,
+	// so that any left behind when it is closed are stopped.
+	st = st.WithWatchOwner(fmt.Sprintf("connection-%d", connectionID))
 	return newAPIHandler(srv, st, conn, modelUUID, serverHost)
 }
 
@@ -632,6 +811,31 @@ func (srv *Server) processCertChanges() error {
 	}
 }
 
+// processModelRemovals removes the API call metrics recorded
+// for each model when the model is removed.
+func (srv *Server) processModelRemovals() error {
+	w := srv.state.WatchModels()
+	defer watcher.Stop(w, &srv.tomb)
+	for {
+		select {
+		case uuids, ok := <-w.Changes():
+			if !ok {
+				return watcher.EnsureErr(w)
+			}
+			for _, uuid := range uuids {
+				_, err := srv.state.GetModel(names.NewModelTag(uuid))
+				if errors.IsNotFound(err) {
+					srv.apiMetrics.RemoveModel(uuid)
+				} else if err != nil {
+					return errors.Trace(err)
+				}
+			}
+		case <-srv.tomb.Dying():
+			return tomb.ErrDying
+		}
+	}
+}
+
 // updateCertificate updates the current CA certificate and key
 // from the given cert and key.
 func (srv *Server) updateCertificate(cert, key string) error {
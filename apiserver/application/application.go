@@ -522,11 +522,7 @@ func (api *API) Unset(p params.ApplicationUnset) error {
 	if err != nil {
 		return err
 	}
-	settings := make(charm.Settings)
-	for _, option := range p.Options {
-		settings[option] = nil
-	}
-	return app.UpdateConfigSettings(settings)
+	return app.ResetConfigSettings(p.Options)
 }
 
 // CharmRelations implements the server side of Application.CharmRelations.
@@ -595,6 +591,38 @@ func addApplicationUnits(backend Backend, args params.AddApplicationUnits) ([]*s
 	return jjj.AddUnits(backend, application, args.ApplicationName, args.NumUnits, args.Placement)
 }
 
+// Rename renames an application, along with its units and relations.
+// Applications with deployed units may not be renamed.
+func (api *API) Rename(args params.ApplicationRename) error {
+	if err := api.checkCanWrite(); err != nil {
+		return err
+	}
+	if err := api.check.ChangeAllowed(); err != nil {
+		return errors.Trace(err)
+	}
+	return api.backend.RenameApplication(args.ApplicationName, args.NewName)
+}
+
+// SetScale sets the number of units an application is expected to
+// have; units are then added or destroyed as necessary to match it.
+// If no scale is specified, the desired scale is unset.
+func (api *API) SetScale(args params.ApplicationSetScale) error {
+	if err := api.checkCanWrite(); err != nil {
+		return err
+	}
+	if err := api.check.ChangeAllowed(); err != nil {
+		return errors.Trace(err)
+	}
+	app, err := api.backend.Application(args.ApplicationName)
+	if err != nil {
+		return err
+	}
+	if args.Scale == nil {
+		return app.UnsetScale()
+	}
+	return app.SetScale(*args.Scale)
+}
+
 // AddUnits adds a given number of units to an application.
 func (api *API) AddUnits(args params.AddApplicationUnits) (params.AddApplicationUnitsResults, error) {
 	if err := api.checkCanWrite(); err != nil {
@@ -505,6 +505,24 @@ func (s *serviceSuite) TestAddCharmWithAuthorization(c *gc.C) {
 	c.Assert(err, gc.IsNil)
 }
 
+func (s *serviceSuite) TestAddCharmWithAuthorizationScansCharm(c *gc.C) {
+	// The dummy charm declares no series, which is recorded
+	// as a warning by the default scanners.
+	curl, _ := s.UploadCharm(c, "precise/dummy-0", "dummy")
+	err := application.AddCharmWithAuthorization(s.State, params.AddCharmWithAuthorization{
+		URL: curl.String(),
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	sch, err := s.State.Charm(curl)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(sch.ScanFindings(), jc.DeepEquals, []state.CharmScanFinding{{
+		Scanner:  "metadata",
+		Severity: "warning",
+		Path:     "metadata.yaml",
+		Message:  "no supported series declared",
+	}})
+}
+
 func (s *serviceSuite) TestAddCharmConcurrently(c *gc.C) {
 	c.Skip("see lp:1596960 -- bad test for bad code")
 
@@ -1282,6 +1300,66 @@ func (s *serviceSuite) TestServiceUpdateSetMinUnits(c *gc.C) {
 	c.Assert(application.MinUnits(), gc.Equals, minUnits)
 }
 
+func (s *serviceSuite) TestServiceRename(c *gc.C) {
+	s.AddTestingService(c, "dummy", s.AddTestingCharm(c, "dummy"))
+
+	err := s.applicationAPI.Rename(params.ApplicationRename{
+		ApplicationName: "dummy",
+		NewName:         "dummy2",
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	_, err = s.State.Application("dummy2")
+	c.Assert(err, jc.ErrorIsNil)
+	_, err = s.State.Application("dummy")
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+}
+
+func (s *serviceSuite) TestServiceRenameBlocked(c *gc.C) {
+	s.AddTestingService(c, "dummy", s.AddTestingCharm(c, "dummy"))
+	s.BlockAllChanges(c, "TestServiceRenameBlocked")
+
+	err := s.applicationAPI.Rename(params.ApplicationRename{
+		ApplicationName: "dummy",
+		NewName:         "dummy2",
+	})
+	s.AssertBlocked(c, err, "TestServiceRenameBlocked")
+}
+
+func (s *serviceSuite) TestServiceSetScale(c *gc.C) {
+	application := s.AddTestingService(c, "dummy", s.AddTestingCharm(c, "dummy"))
+
+	scale := 3
+	err := s.applicationAPI.SetScale(params.ApplicationSetScale{
+		ApplicationName: "dummy",
+		Scale:           &scale,
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(application.Refresh(), gc.IsNil)
+	desired, ok := application.DesiredScale()
+	c.Assert(ok, jc.IsTrue)
+	c.Assert(desired, gc.Equals, 3)
+
+	err = s.applicationAPI.SetScale(params.ApplicationSetScale{
+		ApplicationName: "dummy",
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(application.Refresh(), gc.IsNil)
+	_, ok = application.DesiredScale()
+	c.Assert(ok, jc.IsFalse)
+}
+
+func (s *serviceSuite) TestServiceSetScaleError(c *gc.C) {
+	s.AddTestingService(c, "dummy", s.AddTestingCharm(c, "dummy"))
+
+	scale := -1
+	err := s.applicationAPI.SetScale(params.ApplicationSetScale{
+		ApplicationName: "dummy",
+		Scale:           &scale,
+	})
+	c.Assert(err, gc.ErrorMatches,
+		`cannot set scale for application "dummy": cannot set a negative scale`)
+}
+
 func (s *serviceSuite) TestServiceUpdateSetMinUnitsError(c *gc.C) {
 	application := s.AddTestingService(c, "dummy", s.AddTestingCharm(c, "dummy"))
 
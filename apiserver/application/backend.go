@@ -27,6 +27,7 @@ type Backend interface {
 	InferEndpoints(...string) ([]state.Endpoint, error)
 	Machine(string) (Machine, error)
 	ModelTag() names.ModelTag
+	RenameApplication(oldName, newName string) error
 	Unit(string) (Unit, error)
 }
 
@@ -49,6 +50,7 @@ type Application interface {
 	Channel() csparams.Channel
 	ClearExposed() error
 	ConfigSettings() (charm.Settings, error)
+	ConfigValues() (map[string]state.ConfigValue, error)
 	Constraints() (constraints.Value, error)
 	Destroy() error
 	Endpoints() ([]state.Endpoint, error)
@@ -59,6 +61,9 @@ type Application interface {
 	SetExposed() error
 	SetMetricCredentials([]byte) error
 	SetMinUnits(int) error
+	SetScale(int) error
+	ResetConfigSettings([]string) error
+	UnsetScale() error
 	UpdateConfigSettings(charm.Settings) error
 }
 
@@ -4,6 +4,7 @@
 package application
 
 import (
+	"archive/zip"
 	"fmt"
 	"io"
 	"net/url"
@@ -19,6 +20,7 @@ import (
 	"gopkg.in/macaroon-bakery.v1/httpbakery"
 	"gopkg.in/macaroon.v1"
 
+	"github.com/juju/juju/apiserver/charmscan"
 	"github.com/juju/juju/apiserver/params"
 	"github.com/juju/juju/environs/config"
 	"github.com/juju/juju/state"
@@ -93,6 +95,10 @@ func AddCharmWithAuthorization(st *state.State, args params.AddCharmWithAuthoriz
 	if !ok {
 		return errors.Errorf("expected a charm archive, got %T", downloadedCharm)
 	}
+	findings, err := ScanCharmArchive(st, downloadedBundle.Path, downloadedBundle.Meta())
+	if err != nil {
+		return errors.Trace(err)
+	}
 	archive, err := os.Open(downloadedBundle.Path)
 	if err != nil {
 		return errors.Annotate(err, "cannot read downloaded charm")
@@ -107,11 +113,12 @@ func AddCharmWithAuthorization(st *state.State, args params.AddCharmWithAuthoriz
 	}
 
 	ca := CharmArchive{
-		ID:     charmURL,
-		Charm:  downloadedCharm,
-		Data:   archive,
-		Size:   size,
-		SHA256: bundleSHA256,
+		ID:           charmURL,
+		Charm:        downloadedCharm,
+		Data:         archive,
+		Size:         size,
+		SHA256:       bundleSHA256,
+		ScanFindings: findings,
 	}
 	if args.CharmStoreMacaroon != nil {
 		ca.Macaroon = macaroon.Slice{args.CharmStoreMacaroon}
@@ -121,6 +128,47 @@ func AddCharmWithAuthorization(st *state.State, args params.AddCharmWithAuthoriz
 	return StoreCharmArchive(st, ca)
 }
 
+// ScanCharmArchive runs the charm scanners enabled in the controller
+// config over the charm archive at path, returning their findings for
+// recording with the charm. Every charm archive is scanned before it
+// is added to a model. An error satisfying charmscan.IsRejectedError
+// is returned if the findings prevent the charm from being added.
+func ScanCharmArchive(st *state.State, path string, meta *charm.Meta) ([]state.CharmScanFinding, error) {
+	controllerConfig, err := st.ControllerConfig()
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	scanners, err := charmscan.NewScanners(controllerConfig)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	if len(scanners) == 0 {
+		return nil, nil
+	}
+	zipr, err := zip.OpenReader(path)
+	if err != nil {
+		return nil, errors.Annotate(err, "cannot open charm archive")
+	}
+	defer zipr.Close()
+	findings, err := charmscan.Scan(scanners, charmscan.Archive{
+		Zip:  &zipr.Reader,
+		Meta: meta,
+	})
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	result := make([]state.CharmScanFinding, len(findings))
+	for i, finding := range findings {
+		result[i] = state.CharmScanFinding{
+			Scanner:  finding.Scanner,
+			Severity: string(finding.Severity),
+			Path:     finding.Path,
+			Message:  finding.Message,
+		}
+	}
+	return result, nil
+}
+
 func openCSRepo(args params.AddCharmWithAuthorization) (charmrepo.Interface, error) {
 	csClient, err := openCSClient(args)
 	if err != nil {
@@ -194,6 +242,10 @@ type CharmArchive struct {
 
 	// Macaroon is the authorization macaroon for accessing the charmstore.
 	Macaroon macaroon.Slice
+
+	// ScanFindings holds the findings of the scans run over
+	// the archive when it was uploaded.
+	ScanFindings []state.CharmScanFinding
 }
 
 // StoreCharmArchive stores a charm archive in environment storage.
@@ -208,11 +260,12 @@ func StoreCharmArchive(st *state.State, archive CharmArchive) error {
 	}
 
 	info := state.CharmInfo{
-		Charm:       archive.Charm,
-		ID:          archive.ID,
-		StoragePath: storagePath,
-		SHA256:      archive.SHA256,
-		Macaroon:    archive.Macaroon,
+		Charm:        archive.Charm,
+		ID:           archive.ID,
+		StoragePath:  storagePath,
+		SHA256:       archive.SHA256,
+		Macaroon:     archive.Macaroon,
+		ScanFindings: archive.ScanFindings,
 	}
 
 	// Now update the charm data in state and mark it as no longer pending.
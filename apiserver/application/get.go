@@ -8,6 +8,7 @@ import (
 
 	"github.com/juju/juju/apiserver/params"
 	"github.com/juju/juju/constraints"
+	"github.com/juju/juju/state"
 )
 
 // Get returns the configuration for a service.
@@ -19,7 +20,7 @@ func (api *API) Get(args params.ApplicationGet) (params.ApplicationGetResults, e
 	if err != nil {
 		return params.ApplicationGetResults{}, err
 	}
-	settings, err := app.ConfigSettings()
+	values, err := app.ConfigValues()
 	if err != nil {
 		return params.ApplicationGetResults{}, err
 	}
@@ -27,7 +28,7 @@ func (api *API) Get(args params.ApplicationGet) (params.ApplicationGetResults, e
 	if err != nil {
 		return params.ApplicationGetResults{}, err
 	}
-	configInfo := describe(settings, charm.Config())
+	configInfo := describe(values, charm.Config())
 	var constraints constraints.Value
 	if app.IsPrincipal() {
 		constraints, err = app.Constraints()
@@ -44,21 +45,28 @@ func (api *API) Get(args params.ApplicationGet) (params.ApplicationGetResults, e
 	}, nil
 }
 
-func describe(settings charm.Settings, config *charm.Config) map[string]interface{} {
+// describe returns the description of each of the charm's config
+// options, along with the option's effective value, and the source
+// of the value: "user" if the user has set it, "default" if it is
+// the charm's default, or "unset" if there is no value.
+func describe(values map[string]state.ConfigValue, config *charm.Config) map[string]interface{} {
 	results := make(map[string]interface{})
 	for name, option := range config.Options {
 		info := map[string]interface{}{
 			"description": option.Description,
 			"type":        option.Type,
 		}
-		if value := settings[name]; value != nil {
-			info["value"] = value
-		} else {
-			if option.Default != nil {
-				info["value"] = option.Default
-			}
+		value, ok := values[name]
+		if !ok {
+			value.Source = state.ConfigSourceUnset
+		}
+		if value.Value != nil {
+			info["value"] = value.Value
+		}
+		if value.Source != state.ConfigSourceUser {
 			info["default"] = true
 		}
+		info["source"] = string(value.Source)
 		results[name] = info
 	}
 	return results
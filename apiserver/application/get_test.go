@@ -54,6 +54,7 @@ func (s *getSuite) TestClientServiceGetSmoketest(c *gc.C) {
 		Config: map[string]interface{}{
 			"blog-title": map[string]interface{}{
 				"type":        "string",
+				"source":      "default",
 				"value":       "My Title",
 				"description": "A descriptive title used for the blog.",
 				"default":     true,
@@ -92,21 +93,25 @@ var getTests = []struct {
 			"title": map[string]interface{}{
 				"description": "A descriptive title used for the application.",
 				"type":        "string",
+				"source":      "user",
 				"value":       "Look To Windward",
 			},
 			"outlook": map[string]interface{}{
 				"description": "No default outlook.",
 				"type":        "string",
+				"source":      "unset",
 				"default":     true,
 			},
 			"username": map[string]interface{}{
 				"description": "The name of the initial account (given admin permissions).",
 				"type":        "string",
+				"source":      "user",
 				"value":       "admin001",
 			},
 			"skill-level": map[string]interface{}{
 				"description": "A number indicating skill.",
 				"type":        "int",
+				"source":      "unset",
 				"default":     true,
 			},
 		},
@@ -130,22 +135,26 @@ var getTests = []struct {
 			"title": map[string]interface{}{
 				"description": "A descriptive title used for the application.",
 				"type":        "string",
+				"source":      "default",
 				"value":       "My Title",
 				"default":     true,
 			},
 			"outlook": map[string]interface{}{
 				"description": "No default outlook.",
 				"type":        "string",
+				"source":      "user",
 				"value":       "phlegmatic",
 			},
 			"username": map[string]interface{}{
 				"description": "The name of the initial account (given admin permissions).",
 				"type":        "string",
+				"source":      "user",
 				"value":       "foobie",
 			},
 			"skill-level": map[string]interface{}{
 				"description": "A number indicating skill.",
 				"type":        "int",
+				"source":      "user",
 				// TODO(jam): 2013-08-28 bug #1217742
 				// we have to use float64() here, because the
 				// API does not preserve int types. This used
@@ -214,6 +223,7 @@ func (s *getSuite) TestGetMaxResolutionInt(c *gc.C) {
 	c.Assert(got.Config["skill-level"], jc.DeepEquals, map[string]interface{}{
 		"description": "A number indicating skill.",
 		"type":        "int",
+		"source":      "user",
 		"value":       asFloat,
 	})
 }
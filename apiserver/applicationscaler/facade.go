@@ -17,11 +17,13 @@ import (
 type Backend interface {
 
 	// WatchScaledServices returns a watcher that sends service ids
-	// that might not have enough units.
+	// that might not have enough units, or might have more units
+	// than their desired scale.
 	WatchScaledServices() state.StringsWatcher
 
 	// RescaleService ensures that the named service has at least its
-	// configured minimum unit count.
+	// configured minimum unit count, and that it has its desired
+	// scale if one is set.
 	RescaleService(name string) error
 }
 
@@ -43,7 +45,8 @@ func NewFacade(backend Backend, res facade.Resources, auth facade.Authorizer) (*
 }
 
 // Watch returns a watcher that sends the names of services whose
-// unit count may be below their configured minimum.
+// unit count may be below their configured minimum, or may differ
+// from their desired scale.
 func (facade *Facade) Watch() (params.StringsWatchResult, error) {
 	watch := facade.backend.WatchScaledServices()
 	if changes, ok := <-watch.Changes(); ok {
@@ -57,7 +60,7 @@ func (facade *Facade) Watch() (params.StringsWatchResult, error) {
 }
 
 // Rescale causes any supplied services to be scaled up to their
-// minimum size.
+// minimum size, and then to their desired scale.
 func (facade *Facade) Rescale(args params.Entities) params.ErrorResults {
 	result := params.ErrorResults{
 		Results: make([]params.ErrorResult, len(args.Entities)),
@@ -38,7 +38,7 @@ type backendShim struct {
 
 // WatchScaledServices is part of the Backend interface.
 func (shim backendShim) WatchScaledServices() state.StringsWatcher {
-	return shim.st.WatchMinUnits()
+	return shim.st.WatchScaledApplications()
 }
 
 // RescaleService is part of the Backend interface.
@@ -47,5 +47,8 @@ func (shim backendShim) RescaleService(name string) error {
 	if err != nil {
 		return errors.Trace(err)
 	}
-	return service.EnsureMinUnits()
+	if err := service.EnsureMinUnits(); err != nil {
+		return errors.Trace(err)
+	}
+	return service.EnsureScale()
 }
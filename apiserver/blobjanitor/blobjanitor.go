@@ -0,0 +1,200 @@
+// Copyright 2016 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+// Package blobjanitor implements the API endpoint for enforcing the
+// lifecycle policies of blob storage buckets, and reporting their
+// usage.
+package blobjanitor
+
+import (
+	"io"
+	"sort"
+
+	"github.com/juju/errors"
+	"github.com/juju/utils/clock"
+	"gopkg.in/juju/charm.v6-unstable"
+
+	"github.com/juju/juju/apiserver/common"
+	"github.com/juju/juju/apiserver/facade"
+	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/state"
+	"github.com/juju/juju/state/backups"
+	"github.com/juju/juju/state/binarystorage"
+	"github.com/juju/juju/state/storage"
+)
+
+func init() {
+	common.RegisterStandardFacade("BlobJanitor", 1, newFacade)
+}
+
+// Backend defines the State API used by the blobjanitor facade.
+type Backend interface {
+	IsController() bool
+	BucketPolicies() (map[storage.Bucket]storage.BucketPolicy, error)
+	ListBlobs() ([]storage.Blob, error)
+	RemoveUnusedAgentBinaries(keepLatest int) ([]binarystorage.Metadata, error)
+	RemoveExcessCharmRevisions(keepLatest int) ([]*charm.URL, error)
+}
+
+// Backups defines the backups API used by the blobjanitor facade.
+type Backups interface {
+	List() ([]*backups.Metadata, error)
+	Remove(id string) error
+}
+
+// NewBackupsFunc is the type of a function that returns the
+// controller's Backups, and a Closer to release it.
+type NewBackupsFunc func() (Backups, io.Closer)
+
+// Facade implements the BlobJanitor API.
+type Facade struct {
+	backend    Backend
+	newBackups NewBackupsFunc
+	clock      clock.Clock
+}
+
+func newFacade(st *state.State, _ facade.Resources, authorizer facade.Authorizer) (*Facade, error) {
+	newBackups := func() (Backups, io.Closer) {
+		stor := backups.NewStorage(st)
+		return backups.NewBackups(stor), stor
+	}
+	return New(st, newBackups, clock.WallClock, authorizer)
+}
+
+// New returns a new BlobJanitor API facade.
+func New(backend Backend, newBackups NewBackupsFunc, clock clock.Clock, authorizer facade.Authorizer) (*Facade, error) {
+	if !authorizer.AuthModelManager() {
+		return nil, common.ErrPerm
+	}
+	return &Facade{
+		backend:    backend,
+		newBackups: newBackups,
+		clock:      clock,
+	}, nil
+}
+
+// EnforceBucketPolicies removes the model's blobs that are no longer
+// in use and are not retained under their bucket's lifecycle policy,
+// returning those removed. Backups are removed only by the controller
+// model's janitor, as they belong to the controller.
+func (f *Facade) EnforceBucketPolicies() (params.RemovedBlobsResult, error) {
+	var result params.RemovedBlobsResult
+	policies, err := f.backend.BucketPolicies()
+	if err != nil {
+		return result, errors.Trace(err)
+	}
+	removed := func(bucket storage.Bucket, name string) {
+		result.Removed = append(result.Removed, params.RemovedBlob{
+			Bucket: string(bucket),
+			Name:   name,
+		})
+	}
+
+	if policy := policies[storage.BucketTools]; policy.MaxVersions > 0 {
+		binaries, err := f.backend.RemoveUnusedAgentBinaries(policy.MaxVersions)
+		if err != nil {
+			return result, errors.Annotate(err, "enforcing tools policy")
+		}
+		for _, binary := range binaries {
+			removed(storage.BucketTools, binary.Version)
+		}
+	}
+	if policy := policies[storage.BucketCharms]; policy.MaxVersions > 0 {
+		curls, err := f.backend.RemoveExcessCharmRevisions(policy.MaxVersions)
+		if err != nil {
+			return result, errors.Annotate(err, "enforcing charms policy")
+		}
+		for _, curl := range curls {
+			removed(storage.BucketCharms, curl.String())
+		}
+	}
+	if policy, ok := policies[storage.BucketBackups]; ok && f.backend.IsController() {
+		ids, err := f.enforceBackupsPolicy(policy)
+		if err != nil {
+			return result, errors.Annotate(err, "enforcing backups policy")
+		}
+		for _, id := range ids {
+			removed(storage.BucketBackups, id)
+		}
+	}
+	return result, nil
+}
+
+// enforceBackupsPolicy removes the backups that are older than the
+// policy's maximum age, or are not among the most recent backups that
+// it retains, returning the IDs of those removed. The most recent
+// backup is never removed, however old it is.
+func (f *Facade) enforceBackupsPolicy(policy storage.BucketPolicy) ([]string, error) {
+	b, closer := f.newBackups()
+	defer closer.Close()
+	metadata, err := b.List()
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	sort.Sort(byStartedDescending(metadata))
+	now := f.clock.Now()
+	var removed []string
+	for i, meta := range metadata {
+		if i == 0 {
+			continue
+		}
+		tooMany := policy.MaxVersions > 0 && i >= policy.MaxVersions
+		tooOld := policy.MaxAge > 0 && now.Sub(meta.Started) > policy.MaxAge
+		if !tooMany && !tooOld {
+			continue
+		}
+		if err := b.Remove(meta.ID()); errors.IsNotFound(err) {
+			continue
+		} else if err != nil {
+			return removed, errors.Annotatef(err, "removing backup %q", meta.ID())
+		}
+		removed = append(removed, meta.ID())
+	}
+	return removed, nil
+}
+
+// BucketUsage reports the number and total length of the blobs held in
+// each of the model's buckets. Backups are reported only for the
+// controller model.
+func (f *Facade) BucketUsage() (params.BucketUsageResult, error) {
+	var result params.BucketUsageResult
+	blobs, err := f.backend.ListBlobs()
+	if err != nil {
+		return result, errors.Trace(err)
+	}
+	usage := storage.Usage(blobs)
+	if f.backend.IsController() {
+		b, closer := f.newBackups()
+		defer closer.Close()
+		metadata, err := b.List()
+		if err != nil {
+			return result, errors.Annotate(err, "listing backups")
+		}
+		var backupsUsage storage.BucketUsage
+		for _, meta := range metadata {
+			backupsUsage.Blobs++
+			backupsUsage.Length += meta.Size()
+		}
+		if backupsUsage.Blobs > 0 {
+			usage[storage.BucketBackups] = backupsUsage
+		}
+	}
+	for _, bucket := range storage.Buckets {
+		u, ok := usage[bucket]
+		if !ok {
+			continue
+		}
+		result.Usage = append(result.Usage, params.BucketUsage{
+			Bucket: string(bucket),
+			Blobs:  u.Blobs,
+			Length: u.Length,
+		})
+	}
+	return result, nil
+}
+
+type byStartedDescending []*backups.Metadata
+
+func (s byStartedDescending) Len() int           { return len(s) }
+func (s byStartedDescending) Swap(i, j int)      { s[i], s[j] = s[j], s[i] }
+func (s byStartedDescending) Less(i, j int) bool { return s[i].Started.After(s[j].Started) }
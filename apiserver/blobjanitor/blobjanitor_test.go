@@ -0,0 +1,279 @@
+// Copyright 2016 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package blobjanitor_test
+
+import (
+	"io"
+	"time"
+
+	"github.com/juju/errors"
+	jujutesting "github.com/juju/testing"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+	"gopkg.in/juju/charm.v6-unstable"
+	"gopkg.in/juju/names.v2"
+
+	"github.com/juju/juju/apiserver/blobjanitor"
+	"github.com/juju/juju/apiserver/common"
+	"github.com/juju/juju/apiserver/params"
+	apiservertesting "github.com/juju/juju/apiserver/testing"
+	"github.com/juju/juju/state/backups"
+	"github.com/juju/juju/state/binarystorage"
+	"github.com/juju/juju/state/storage"
+)
+
+var now = time.Date(2016, 10, 1, 12, 0, 0, 0, time.UTC)
+
+type blobJanitorSuite struct {
+	jujutesting.IsolationSuite
+	backend    mockBackend
+	backups    mockBackups
+	authorizer apiservertesting.FakeAuthorizer
+}
+
+var _ = gc.Suite(&blobJanitorSuite{})
+
+func (s *blobJanitorSuite) SetUpTest(c *gc.C) {
+	s.IsolationSuite.SetUpTest(c)
+	s.backend = mockBackend{
+		controller: true,
+		policies:   make(map[storage.Bucket]storage.BucketPolicy),
+	}
+	s.backups = mockBackups{}
+	s.authorizer = apiservertesting.FakeAuthorizer{
+		Tag:            names.NewMachineTag("0"),
+		EnvironManager: true,
+	}
+}
+
+func (s *blobJanitorSuite) newFacade(c *gc.C) *blobjanitor.Facade {
+	newBackups := func() (blobjanitor.Backups, io.Closer) {
+		return &s.backups, &s.backups
+	}
+	facade, err := blobjanitor.New(&s.backend, newBackups, jujutesting.NewClock(now), &s.authorizer)
+	c.Assert(err, jc.ErrorIsNil)
+	return facade
+}
+
+func (s *blobJanitorSuite) TestNewNotAuthorized(c *gc.C) {
+	s.authorizer = apiservertesting.FakeAuthorizer{
+		Tag:      names.NewUserTag("admin"),
+		AdminTag: names.NewUserTag("admin"),
+	}
+	_, err := blobjanitor.New(&s.backend, nil, jujutesting.NewClock(now), &s.authorizer)
+	c.Assert(err, gc.Equals, common.ErrPerm)
+}
+
+func (s *blobJanitorSuite) TestEnforceBucketPoliciesNone(c *gc.C) {
+	result, err := s.newFacade(c).EnforceBucketPolicies()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(result.Removed, gc.HasLen, 0)
+	s.backend.CheckCallNames(c, "BucketPolicies")
+	s.backups.CheckNoCalls(c)
+}
+
+func (s *blobJanitorSuite) TestEnforceBucketPolicies(c *gc.C) {
+	s.backend.policies[storage.BucketTools] = storage.BucketPolicy{MaxVersions: 3}
+	s.backend.policies[storage.BucketCharms] = storage.BucketPolicy{MaxVersions: 2}
+	s.backend.binaries = []binarystorage.Metadata{{Version: "2.0.0-xenial-amd64"}}
+	s.backend.charms = []*charm.URL{charm.MustParseURL("local:xenial/dummy-1")}
+
+	result, err := s.newFacade(c).EnforceBucketPolicies()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(result, jc.DeepEquals, params.RemovedBlobsResult{
+		Removed: []params.RemovedBlob{
+			{Bucket: "tools", Name: "2.0.0-xenial-amd64"},
+			{Bucket: "charms", Name: "local:xenial/dummy-1"},
+		},
+	})
+	s.backend.CheckCalls(c, []jujutesting.StubCall{
+		{"BucketPolicies", nil},
+		{"RemoveUnusedAgentBinaries", []interface{}{3}},
+		{"RemoveExcessCharmRevisions", []interface{}{2}},
+	})
+}
+
+func (s *blobJanitorSuite) TestEnforceBackupsPolicy(c *gc.C) {
+	s.backend.policies[storage.BucketBackups] = storage.BucketPolicy{
+		MaxAge:      48 * time.Hour,
+		MaxVersions: 2,
+	}
+	s.backups.metadata = []*backups.Metadata{
+		newBackup("old", now.Add(-72*time.Hour), 1),
+		newBackup("newest", now.Add(-time.Hour), 1),
+		newBackup("older", now.Add(-25*time.Hour), 1),
+		newBackup("newer", now.Add(-2*time.Hour), 1),
+	}
+
+	result, err := s.newFacade(c).EnforceBucketPolicies()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(result, jc.DeepEquals, params.RemovedBlobsResult{
+		Removed: []params.RemovedBlob{
+			{Bucket: "backups", Name: "older"},
+			{Bucket: "backups", Name: "old"},
+		},
+	})
+	s.backups.CheckCalls(c, []jujutesting.StubCall{
+		{"List", nil},
+		{"Remove", []interface{}{"older"}},
+		{"Remove", []interface{}{"old"}},
+		{"Close", nil},
+	})
+}
+
+func (s *blobJanitorSuite) TestEnforceBackupsPolicyMaxAge(c *gc.C) {
+	s.backend.policies[storage.BucketBackups] = storage.BucketPolicy{MaxAge: 48 * time.Hour}
+	s.backups.metadata = []*backups.Metadata{
+		newBackup("old", now.Add(-72*time.Hour), 1),
+		newBackup("new", now.Add(-time.Hour), 1),
+	}
+
+	result, err := s.newFacade(c).EnforceBucketPolicies()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(result, jc.DeepEquals, params.RemovedBlobsResult{
+		Removed: []params.RemovedBlob{{Bucket: "backups", Name: "old"}},
+	})
+}
+
+func (s *blobJanitorSuite) TestEnforceBackupsPolicyMaxAgeKeepsNewest(c *gc.C) {
+	s.backend.policies[storage.BucketBackups] = storage.BucketPolicy{MaxAge: 48 * time.Hour}
+	s.backups.metadata = []*backups.Metadata{
+		newBackup("oldest", now.Add(-96*time.Hour), 1),
+		newBackup("newest", now.Add(-72*time.Hour), 1),
+		newBackup("old", now.Add(-84*time.Hour), 1),
+	}
+
+	result, err := s.newFacade(c).EnforceBucketPolicies()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(result, jc.DeepEquals, params.RemovedBlobsResult{
+		Removed: []params.RemovedBlob{
+			{Bucket: "backups", Name: "old"},
+			{Bucket: "backups", Name: "oldest"},
+		},
+	})
+	s.backups.CheckCalls(c, []jujutesting.StubCall{
+		{"List", nil},
+		{"Remove", []interface{}{"old"}},
+		{"Remove", []interface{}{"oldest"}},
+		{"Close", nil},
+	})
+}
+
+func (s *blobJanitorSuite) TestEnforceBackupsPolicyHostedModel(c *gc.C) {
+	s.backend.controller = false
+	s.backend.policies[storage.BucketBackups] = storage.BucketPolicy{MaxVersions: 1}
+	s.backups.metadata = []*backups.Metadata{
+		newBackup("a", now, 1),
+		newBackup("b", now, 1),
+	}
+
+	result, err := s.newFacade(c).EnforceBucketPolicies()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(result.Removed, gc.HasLen, 0)
+	s.backups.CheckNoCalls(c)
+}
+
+func (s *blobJanitorSuite) TestEnforceBucketPoliciesError(c *gc.C) {
+	s.backend.policies[storage.BucketCharms] = storage.BucketPolicy{MaxVersions: 2}
+	s.backend.SetErrors(nil, errors.New("boom"))
+	_, err := s.newFacade(c).EnforceBucketPolicies()
+	c.Assert(err, gc.ErrorMatches, "enforcing charms policy: boom")
+}
+
+func (s *blobJanitorSuite) TestBucketUsage(c *gc.C) {
+	s.backend.blobs = []storage.Blob{
+		{Path: "tools/2.0.0-xenial-amd64", Bucket: storage.BucketTools, Length: 100},
+		{Path: "charms/local:xenial/dummy-1", Bucket: storage.BucketCharms, Length: 10},
+		{Path: "charms/local:xenial/dummy-2", Bucket: storage.BucketCharms, Length: 20},
+	}
+	s.backups.metadata = []*backups.Metadata{
+		newBackup("a", now, 1000),
+		newBackup("b", now, 2000),
+	}
+
+	result, err := s.newFacade(c).BucketUsage()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(result, jc.DeepEquals, params.BucketUsageResult{
+		Usage: []params.BucketUsage{
+			{Bucket: "backups", Blobs: 2, Length: 3000},
+			{Bucket: "charms", Blobs: 2, Length: 30},
+			{Bucket: "tools", Blobs: 1, Length: 100},
+		},
+	})
+}
+
+func (s *blobJanitorSuite) TestBucketUsageHostedModel(c *gc.C) {
+	s.backend.controller = false
+	s.backend.blobs = []storage.Blob{
+		{Path: "charms/local:xenial/dummy-1", Bucket: storage.BucketCharms, Length: 10},
+	}
+
+	result, err := s.newFacade(c).BucketUsage()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(result, jc.DeepEquals, params.BucketUsageResult{
+		Usage: []params.BucketUsage{{Bucket: "charms", Blobs: 1, Length: 10}},
+	})
+	s.backups.CheckNoCalls(c)
+}
+
+func newBackup(id string, started time.Time, size int64) *backups.Metadata {
+	meta := backups.NewMetadata()
+	meta.SetID(id)
+	meta.Started = started
+	meta.MarkComplete(size, "checksum")
+	return meta
+}
+
+type mockBackend struct {
+	jujutesting.Stub
+	controller bool
+	policies   map[storage.Bucket]storage.BucketPolicy
+	blobs      []storage.Blob
+	binaries   []binarystorage.Metadata
+	charms     []*charm.URL
+}
+
+func (b *mockBackend) IsController() bool {
+	return b.controller
+}
+
+func (b *mockBackend) BucketPolicies() (map[storage.Bucket]storage.BucketPolicy, error) {
+	b.MethodCall(b, "BucketPolicies")
+	return b.policies, b.NextErr()
+}
+
+func (b *mockBackend) ListBlobs() ([]storage.Blob, error) {
+	b.MethodCall(b, "ListBlobs")
+	return b.blobs, b.NextErr()
+}
+
+func (b *mockBackend) RemoveUnusedAgentBinaries(keepLatest int) ([]binarystorage.Metadata, error) {
+	b.MethodCall(b, "RemoveUnusedAgentBinaries", keepLatest)
+	return b.binaries, b.NextErr()
+}
+
+func (b *mockBackend) RemoveExcessCharmRevisions(keepLatest int) ([]*charm.URL, error) {
+	b.MethodCall(b, "RemoveExcessCharmRevisions", keepLatest)
+	return b.charms, b.NextErr()
+}
+
+type mockBackups struct {
+	jujutesting.Stub
+	metadata []*backups.Metadata
+}
+
+func (b *mockBackups) List() ([]*backups.Metadata, error) {
+	b.MethodCall(b, "List")
+	return b.metadata, b.NextErr()
+}
+
+func (b *mockBackups) Remove(id string) error {
+	b.MethodCall(b, "Remove", id)
+	return b.NextErr()
+}
+
+func (b *mockBackups) Close() error {
+	b.MethodCall(b, "Close")
+	return b.NextErr()
+}
@@ -234,13 +234,22 @@ func (h *charmsHandler) processPost(r *http.Request, st *state.State) (*charm.UR
 	if contentType != "application/zip" {
 		return nil, fmt.Errorf("expected Content-Type: application/zip, got: %v", contentType)
 	}
+	expectedSHA256, err := expectedUploadSHA256(query, st)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
 
-	charmFileName, err := writeCharmToTempFile(r.Body)
+	charmFileName, charmSHA256, err := writeCharmToTempFile(r.Body)
 	if err != nil {
 		return nil, errors.Trace(err)
 	}
 	defer os.Remove(charmFileName)
 
+	// Verify the upload before anything is recorded in state.
+	if err := verifyUploadSHA256(expectedSHA256, charmSHA256); err != nil {
+		return nil, errors.Trace(err)
+	}
+
 	err = h.processUploadedArchive(charmFileName)
 	if err != nil {
 		return nil, err
@@ -250,6 +259,12 @@ func (h *charmsHandler) processPost(r *http.Request, st *state.State) (*charm.UR
 		return nil, fmt.Errorf("invalid charm archive: %v", err)
 	}
 
+	// Charms are scanned before anything is recorded in state.
+	findings, err := application.ScanCharmArchive(st, charmFileName, archive.Meta())
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+
 	// We got it, now let's reserve a charm URL for it in state.
 	curl := &charm.URL{
 		Schema:   schema,
@@ -291,7 +306,7 @@ func (h *charmsHandler) processPost(r *http.Request, st *state.State) (*charm.UR
 
 	// Now we need to repackage it with the reserved URL, upload it to
 	// provider storage and update the state.
-	err = h.repackageAndUploadCharm(st, archive, curl)
+	err = h.repackageAndUploadCharm(st, archive, curl, findings)
 	if err != nil {
 		return nil, err
 	}
@@ -387,8 +402,12 @@ func (d byDepth) Less(i, j int) bool { return depth(d[i]) < depth(d[j]) }
 
 // repackageAndUploadCharm expands the given charm archive to a
 // temporary directoy, repackages it with the given curl's revision,
-// then uploads it to storage, and finally updates the state.
-func (h *charmsHandler) repackageAndUploadCharm(st *state.State, archive *charm.CharmArchive, curl *charm.URL) error {
+// then uploads it to storage, and finally updates the state, recording
+// the findings of the archive's scans.
+func (h *charmsHandler) repackageAndUploadCharm(
+	st *state.State, archive *charm.CharmArchive, curl *charm.URL,
+	findings []state.CharmScanFinding,
+) error {
 	// Create a temp dir to contain the extracted charm dir.
 	tempDir, err := ioutil.TempDir("", "charm-download")
 	if err != nil {
@@ -417,11 +436,12 @@ func (h *charmsHandler) repackageAndUploadCharm(st *state.State, archive *charm.
 	bundleSHA256 := hex.EncodeToString(hash.Sum(nil))
 
 	info := application.CharmArchive{
-		ID:     curl,
-		Charm:  archive,
-		Data:   &repackagedArchive,
-		Size:   int64(repackagedArchive.Len()),
-		SHA256: bundleSHA256,
+		ID:           curl,
+		Charm:        archive,
+		Data:         &repackagedArchive,
+		Size:         int64(repackagedArchive.Len()),
+		SHA256:       bundleSHA256,
+		ScanFindings: findings,
 	}
 	// Store the charm archive in environment storage.
 	return application.StoreCharmArchive(st, info)
@@ -496,16 +516,21 @@ func cleanupFile(file *os.File) {
 	os.Remove(file.Name())
 }
 
-func writeCharmToTempFile(r io.Reader) (string, error) {
+// writeCharmToTempFile writes the uploaded charm to a temporary file,
+// returning the file's name and the hex-encoded SHA-256 hash of its
+// contents.
+func writeCharmToTempFile(r io.Reader) (string, string, error) {
 	tempFile, err := ioutil.TempFile("", "charm")
 	if err != nil {
-		return "", errors.Annotate(err, "creating temp file")
+		return "", "", errors.Annotate(err, "creating temp file")
 	}
 	defer tempFile.Close()
-	if _, err := io.Copy(tempFile, r); err != nil {
-		return "", errors.Annotate(err, "processing upload")
+	hash := sha256.New()
+	if _, err := io.Copy(tempFile, io.TeeReader(r, hash)); err != nil {
+		os.Remove(tempFile.Name())
+		return "", "", errors.Annotate(err, "processing upload")
 	}
-	return tempFile.Name(), nil
+	return tempFile.Name(), hex.EncodeToString(hash.Sum(nil)), nil
 }
 
 func modelIsImporting(st *state.State) (bool, error) {
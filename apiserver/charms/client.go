@@ -82,12 +82,13 @@ func (a *API) CharmInfo(args params.CharmURL) (params.CharmInfo, error) {
 		return params.CharmInfo{}, errors.Trace(err)
 	}
 	info := params.CharmInfo{
-		Revision: aCharm.Revision(),
-		URL:      curl.String(),
-		Config:   convertCharmConfig(aCharm.Config()),
-		Meta:     convertCharmMeta(aCharm.Meta()),
-		Actions:  convertCharmActions(aCharm.Actions()),
-		Metrics:  convertCharmMetrics(aCharm.Metrics()),
+		Revision:     aCharm.Revision(),
+		URL:          curl.String(),
+		Config:       convertCharmConfig(aCharm.Config()),
+		Meta:         convertCharmMeta(aCharm.Meta()),
+		Actions:      convertCharmActions(aCharm.Actions()),
+		Metrics:      convertCharmMetrics(aCharm.Metrics()),
+		ScanFindings: convertCharmScanFindings(aCharm.ScanFindings()),
 	}
 	return info, nil
 }
@@ -151,6 +152,22 @@ func convertCharmConfig(config *charm.Config) map[string]params.CharmOption {
 	return result
 }
 
+func convertCharmScanFindings(findings []state.CharmScanFinding) []params.CharmScanFinding {
+	if len(findings) == 0 {
+		return nil
+	}
+	result := make([]params.CharmScanFinding, len(findings))
+	for i, finding := range findings {
+		result[i] = params.CharmScanFinding{
+			Scanner:  finding.Scanner,
+			Severity: finding.Severity,
+			Path:     finding.Path,
+			Message:  finding.Message,
+		}
+	}
+	return result
+}
+
 func convertCharmOption(opt charm.Option) params.CharmOption {
 	return params.CharmOption{
 		Type:        opt.Type,
@@ -6,12 +6,15 @@ package charms_test
 import (
 	jc "github.com/juju/testing/checkers"
 	gc "gopkg.in/check.v1"
+	"gopkg.in/juju/charm.v6-unstable"
 
 	"github.com/juju/juju/apiserver/charms"
 	"github.com/juju/juju/apiserver/common"
 	"github.com/juju/juju/apiserver/params"
 	"github.com/juju/juju/apiserver/testing"
 	jujutesting "github.com/juju/juju/juju/testing"
+	"github.com/juju/juju/state"
+	"github.com/juju/juju/testcharms"
 	"github.com/juju/juju/testing/factory"
 )
 
@@ -221,6 +224,30 @@ func (s *charmsSuite) TestMeteredCharmInfo(c *gc.C) {
 	c.Assert(info.Metrics, jc.DeepEquals, expected)
 }
 
+func (s *charmsSuite) TestCharmInfoScanFindings(c *gc.C) {
+	_, err := s.State.AddCharm(state.CharmInfo{
+		Charm:       testcharms.Repo.CharmDir("dummy"),
+		ID:          charm.MustParseURL("local:quantal/dummy-1"),
+		StoragePath: "dummy-1",
+		SHA256:      "dummy-1-sha256",
+		ScanFindings: []state.CharmScanFinding{{
+			Scanner:  "metadata",
+			Severity: "warning",
+			Path:     "metadata.yaml",
+			Message:  "no supported series declared",
+		}},
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	info, err := s.api.CharmInfo(params.CharmURL{URL: "local:quantal/dummy-1"})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(info.ScanFindings, jc.DeepEquals, []params.CharmScanFinding{{
+		Scanner:  "metadata",
+		Severity: "warning",
+		Path:     "metadata.yaml",
+		Message:  "no supported series declared",
+	}})
+}
+
 func (s *charmsSuite) TestListCharmsNoFilter(c *gc.C) {
 	s.assertListCharms(c, []string{"dummy"}, []string{}, []string{"local:quantal/dummy-1"})
 }
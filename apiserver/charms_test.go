@@ -13,6 +13,7 @@ import (
 	"os"
 	"path/filepath"
 	"runtime"
+	"strings"
 
 	"github.com/juju/errors"
 	jc "github.com/juju/testing/checkers"
@@ -236,6 +237,72 @@ func (s *charmsSuite) TestUploadRespectsLocalRevision(c *gc.C) {
 	c.Assert(downloadedSHA256, gc.Equals, expectedSHA256)
 }
 
+func (s *charmsSuite) TestUploadWithChecksum(c *gc.C) {
+	ch := testcharms.Repo.CharmArchive(c.MkDir(), "dummy")
+	f, err := os.Open(ch.Path)
+	c.Assert(err, jc.ErrorIsNil)
+	defer f.Close()
+	expectedSHA256, _, err := utils.ReadSHA256(f)
+	c.Assert(err, jc.ErrorIsNil)
+
+	resp := s.uploadRequest(c, s.charmsURI(c, "?series=quantal&sha256="+expectedSHA256), "application/zip", ch.Path)
+	expectedURL := charm.MustParseURL("local:quantal/dummy-1")
+	s.assertUploadResponse(c, resp, expectedURL.String())
+	sch, err := s.State.Charm(expectedURL)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(sch.BundleSha256(), gc.Equals, expectedSHA256)
+}
+
+func (s *charmsSuite) TestUploadChecksumMismatch(c *gc.C) {
+	ch := testcharms.Repo.CharmArchive(c.MkDir(), "dummy")
+	wrongSHA256 := strings.Repeat("0", 64)
+	resp := s.uploadRequest(c, s.charmsURI(c, "?series=quantal&sha256="+wrongSHA256), "application/zip", ch.Path)
+	s.assertErrorResponse(c, resp, http.StatusBadRequest, "upload corrupted: expected sha256 0+, got [0-9a-f]+")
+
+	// The charm should not have been added.
+	_, err := s.State.Charm(charm.MustParseURL("local:quantal/dummy-1"))
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+}
+
+func (s *charmsSuite) TestUploadRecordsScanFindings(c *gc.C) {
+	// The dummy charm declares no series, which is recorded
+	// as a warning by the default scanners.
+	ch := testcharms.Repo.CharmArchive(c.MkDir(), "dummy")
+	resp := s.uploadRequest(c, s.charmsURI(c, "?series=quantal"), "application/zip", ch.Path)
+	expectedURL := charm.MustParseURL("local:quantal/dummy-1")
+	s.assertUploadResponse(c, resp, expectedURL.String())
+	sch, err := s.State.Charm(expectedURL)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(sch.ScanFindings(), jc.DeepEquals, []state.CharmScanFinding{{
+		Scanner:  "metadata",
+		Severity: "warning",
+		Path:     "metadata.yaml",
+		Message:  "no supported series declared",
+	}})
+}
+
+func (s *charmsSuite) TestUploadRejectedByScan(c *gc.C) {
+	if runtime.GOOS == "windows" {
+		c.Skip("setuid bits are not supported on windows")
+	}
+	dir := testcharms.Repo.ClonedDir(c.MkDir(), "dummy")
+	err := os.Chmod(filepath.Join(dir.Path, "hooks", "install"), os.ModeSetuid|0755)
+	c.Assert(err, jc.ErrorIsNil)
+	tempFile, err := ioutil.TempFile(c.MkDir(), "charm")
+	c.Assert(err, jc.ErrorIsNil)
+	defer tempFile.Close()
+	err = dir.ArchiveTo(tempFile)
+	c.Assert(err, jc.ErrorIsNil)
+
+	resp := s.uploadRequest(c, s.charmsURI(c, "?series=quantal"), "application/zip", tempFile.Name())
+	s.assertErrorResponse(c, resp, http.StatusBadRequest,
+		"charm rejected: setuid: hooks/install: setuid and setgid files not allowed")
+
+	// The charm should not have been added.
+	_, err = s.State.Charm(charm.MustParseURL("local:quantal/dummy-1"))
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+}
+
 func (s *charmsSuite) TestUploadWithMultiSeriesCharm(c *gc.C) {
 	ch := testcharms.Repo.CharmArchive(c.MkDir(), "dummy")
 	resp := s.uploadRequest(c, s.charmsURL(c, "").String(), "application/zip", ch.Path)
@@ -372,6 +439,19 @@ func (s *charmsSuite) TestNonLocalCharmUpload(c *gc.C) {
 	c.Assert(sch.IsUploaded(), jc.IsTrue)
 }
 
+func (s *charmsSuite) TestNonLocalCharmUploadScanned(c *gc.C) {
+	s.setModelImporting(c)
+	ch := testcharms.Repo.CharmArchive(c.MkDir(), "dummy")
+
+	resp := s.uploadRequest(c, s.charmsURI(c, "?schema=cs&series=quantal"), "application/zip", ch.Path)
+	expectedURL := charm.MustParseURL("cs:quantal/dummy-1")
+	s.assertUploadResponse(c, resp, expectedURL.String())
+	sch, err := s.State.Charm(expectedURL)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(sch.ScanFindings(), gc.HasLen, 1)
+	c.Assert(sch.ScanFindings()[0].Scanner, gc.Equals, "metadata")
+}
+
 func (s *charmsSuite) TestNonLocalCharmUploadWithRevisionOverride(c *gc.C) {
 	s.setModelImporting(c)
 	ch := testcharms.Repo.CharmArchive(c.MkDir(), "dummy")
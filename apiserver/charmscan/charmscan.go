@@ -0,0 +1,127 @@
+// Copyright 2016 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+// Package charmscan provides the hook point through which charm
+// archives are statically inspected before they are added to a model,
+// whether uploaded or fetched from the charm store, so that controllers
+// may enforce policies on what is deployed. The scanners run are chosen
+// with the charm-scanners controller config attribute.
+package charmscan
+
+import (
+	"archive/zip"
+	"fmt"
+	"strings"
+
+	"github.com/juju/errors"
+	"gopkg.in/juju/charm.v6-unstable"
+)
+
+// Severity describes how a finding affects the charm's upload.
+type Severity string
+
+const (
+	// SeverityWarning findings are recorded with the charm,
+	// but do not prevent it from being uploaded.
+	SeverityWarning Severity = "warning"
+
+	// SeverityError findings cause the charm's upload
+	// to be rejected.
+	SeverityError Severity = "error"
+)
+
+// Finding is a problem found by a Scanner in a charm archive.
+type Finding struct {
+	// Scanner is the name of the scanner that reported the finding.
+	// It is set by Scan, and need not be set by scanners.
+	Scanner string
+
+	// Severity describes how the finding affects the upload.
+	Severity Severity
+
+	// Path is the path of the archive entry that the finding
+	// concerns, or the empty string if it concerns the archive
+	// as a whole.
+	Path string
+
+	// Message describes the finding.
+	Message string
+}
+
+// String returns a description of the finding.
+func (f Finding) String() string {
+	if f.Path == "" {
+		return fmt.Sprintf("%s: %s", f.Scanner, f.Message)
+	}
+	return fmt.Sprintf("%s: %s: %s", f.Scanner, f.Path, f.Message)
+}
+
+// Archive is an uploaded charm archive to be scanned.
+type Archive struct {
+	// Zip is a reader for the archive's entries, which
+	// are rooted at the charm's directory.
+	Zip *zip.Reader
+
+	// Meta is the charm's metadata, read from
+	// its metadata.yaml.
+	Meta *charm.Meta
+}
+
+// Scanner is the interface implemented by hooks that inspect
+// uploaded charm archives.
+type Scanner interface {
+	// Name returns the name of the scanner, recorded
+	// in its findings.
+	Name() string
+
+	// Scan inspects the archive and returns its findings.
+	// An error is returned only if the scan could not be
+	// completed.
+	Scan(Archive) ([]Finding, error)
+}
+
+// Scan runs each of the scanners over the archive in turn, returning
+// all of their findings. The archive is rejected, with a *RejectedError,
+// if any of the findings is of SeverityError.
+func Scan(scanners []Scanner, archive Archive) ([]Finding, error) {
+	var all, rejected []Finding
+	for _, scanner := range scanners {
+		findings, err := scanner.Scan(archive)
+		if err != nil {
+			return nil, errors.Annotatef(err, "running %s scanner", scanner.Name())
+		}
+		for _, finding := range findings {
+			finding.Scanner = scanner.Name()
+			all = append(all, finding)
+			if finding.Severity == SeverityError {
+				rejected = append(rejected, finding)
+			}
+		}
+	}
+	if len(rejected) > 0 {
+		return all, &RejectedError{rejected}
+	}
+	return all, nil
+}
+
+// RejectedError is returned by Scan when scanners report
+// findings that prevent a charm from being uploaded.
+type RejectedError struct {
+	// Findings holds the findings of SeverityError.
+	Findings []Finding
+}
+
+// Error is part of the error interface.
+func (e *RejectedError) Error() string {
+	descriptions := make([]string, len(e.Findings))
+	for i, finding := range e.Findings {
+		descriptions[i] = finding.String()
+	}
+	return "charm rejected: " + strings.Join(descriptions, "; ")
+}
+
+// IsRejectedError reports whether the cause of err is a *RejectedError.
+func IsRejectedError(err error) bool {
+	_, ok := errors.Cause(err).(*RejectedError)
+	return ok
+}
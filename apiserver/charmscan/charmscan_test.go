@@ -0,0 +1,277 @@
+// Copyright 2016 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package charmscan_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"os"
+
+	"github.com/juju/errors"
+	"github.com/juju/testing"
+	jc "github.com/juju/testing/checkers"
+	"github.com/juju/version"
+	gc "gopkg.in/check.v1"
+	"gopkg.in/juju/charm.v6-unstable"
+
+	"github.com/juju/juju/apiserver/charmscan"
+	"github.com/juju/juju/controller"
+	coretesting "github.com/juju/juju/testing"
+)
+
+type ScanSuite struct {
+	testing.IsolationSuite
+}
+
+var _ = gc.Suite(&ScanSuite{})
+
+type archiveEntry struct {
+	name string
+	mode os.FileMode
+	size int
+}
+
+func makeArchive(c *gc.C, meta *charm.Meta, entries ...archiveEntry) charmscan.Archive {
+	var buf bytes.Buffer
+	w := zip.NewWriter(&buf)
+	for _, entry := range entries {
+		header := &zip.FileHeader{Name: entry.name}
+		header.SetMode(entry.mode)
+		f, err := w.CreateHeader(header)
+		c.Assert(err, jc.ErrorIsNil)
+		_, err = f.Write(make([]byte, entry.size))
+		c.Assert(err, jc.ErrorIsNil)
+	}
+	c.Assert(w.Close(), jc.ErrorIsNil)
+	r, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	c.Assert(err, jc.ErrorIsNil)
+	if meta == nil {
+		meta = &charm.Meta{Name: "dummy", Series: []string{"xenial"}}
+	}
+	return charmscan.Archive{Zip: r, Meta: meta}
+}
+
+func (s *ScanSuite) TestScan(c *gc.C) {
+	archive := makeArchive(c, nil)
+	scanners := []charmscan.Scanner{
+		scannerFunc{"a", []charmscan.Finding{{
+			Severity: charmscan.SeverityWarning,
+			Message:  "meh",
+		}}},
+		scannerFunc{"b", []charmscan.Finding{{
+			Severity: charmscan.SeverityWarning,
+			Path:     "hooks/install",
+			Message:  "hmm",
+		}}},
+	}
+	findings, err := charmscan.Scan(scanners, archive)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(findings, jc.DeepEquals, []charmscan.Finding{{
+		Scanner:  "a",
+		Severity: charmscan.SeverityWarning,
+		Message:  "meh",
+	}, {
+		Scanner:  "b",
+		Severity: charmscan.SeverityWarning,
+		Path:     "hooks/install",
+		Message:  "hmm",
+	}})
+}
+
+func (s *ScanSuite) TestScanRejected(c *gc.C) {
+	archive := makeArchive(c, nil)
+	scanners := []charmscan.Scanner{
+		scannerFunc{"a", []charmscan.Finding{{
+			Severity: charmscan.SeverityWarning,
+			Message:  "meh",
+		}}},
+		scannerFunc{"b", []charmscan.Finding{{
+			Severity: charmscan.SeverityError,
+			Path:     "hooks/install",
+			Message:  "nope",
+		}}},
+	}
+	findings, err := charmscan.Scan(scanners, archive)
+	c.Assert(err, gc.ErrorMatches, "charm rejected: b: hooks/install: nope")
+	c.Assert(err, jc.Satisfies, charmscan.IsRejectedError)
+	c.Assert(findings, gc.HasLen, 2)
+}
+
+func (s *ScanSuite) TestScanError(c *gc.C) {
+	archive := makeArchive(c, nil)
+	_, err := charmscan.Scan([]charmscan.Scanner{failingScanner{}}, archive)
+	c.Assert(err, gc.ErrorMatches, "running failing scanner: boom")
+	c.Assert(err, gc.Not(jc.Satisfies), charmscan.IsRejectedError)
+}
+
+func (s *ScanSuite) TestStructureScanner(c *gc.C) {
+	archive := makeArchive(c, nil,
+		archiveEntry{name: "metadata.yaml", mode: 0644},
+		archiveEntry{name: "hooks/../../etc/passwd", mode: 0644},
+		archiveEntry{name: "/etc/shadow", mode: 0644},
+		archiveEntry{name: "hooks/..install", mode: 0755},
+	)
+	findings, err := charmscan.StructureScanner{}.Scan(archive)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(findings, jc.DeepEquals, []charmscan.Finding{{
+		Severity: charmscan.SeverityError,
+		Path:     "hooks/../../etc/passwd",
+		Message:  "path outside charm directory not allowed",
+	}, {
+		Severity: charmscan.SeverityError,
+		Path:     "/etc/shadow",
+		Message:  "absolute path not allowed",
+	}})
+}
+
+func (s *ScanSuite) TestMetadataScanner(c *gc.C) {
+	scanner := charmscan.MetadataScanner{JujuVersion: version.MustParse("2.0.0")}
+	archive := makeArchive(c, &charm.Meta{
+		Name:           "dummy",
+		Series:         []string{"xenial"},
+		MinJujuVersion: version.MustParse("2.0.0"),
+	})
+	findings, err := scanner.Scan(archive)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(findings, gc.HasLen, 0)
+
+	archive = makeArchive(c, &charm.Meta{
+		Name:           "dummy",
+		MinJujuVersion: version.MustParse("2.1.0"),
+	})
+	findings, err = scanner.Scan(archive)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(findings, jc.DeepEquals, []charmscan.Finding{{
+		Severity: charmscan.SeverityError,
+		Path:     "metadata.yaml",
+		Message:  "min-juju-version 2.1.0 is higher than the controller's version 2.0.0",
+	}, {
+		Severity: charmscan.SeverityWarning,
+		Path:     "metadata.yaml",
+		Message:  "no supported series declared",
+	}})
+}
+
+func (s *ScanSuite) TestHookSizeScanner(c *gc.C) {
+	archive := makeArchive(c, nil,
+		archiveEntry{name: "hooks/", mode: os.ModeDir | 0755},
+		archiveEntry{name: "hooks/install", mode: 0755, size: 100},
+		archiveEntry{name: "hooks/start", mode: 0755, size: 101},
+		archiveEntry{name: "./hooks/stop", mode: 0644, size: 101},
+		archiveEntry{name: "actions/backup", mode: 0755, size: 101},
+		archiveEntry{name: "lib/big", mode: 0644, size: 1000},
+		archiveEntry{name: "lib/run", mode: 0755, size: 101},
+	)
+	findings, err := charmscan.HookSizeScanner{MaxSize: 100}.Scan(archive)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(findings, jc.DeepEquals, []charmscan.Finding{{
+		Severity: charmscan.SeverityError,
+		Path:     "hooks/start",
+		Message:  "hook size 101 bytes exceeds maximum of 100 bytes",
+	}, {
+		Severity: charmscan.SeverityError,
+		Path:     "./hooks/stop",
+		Message:  "hook size 101 bytes exceeds maximum of 100 bytes",
+	}, {
+		Severity: charmscan.SeverityError,
+		Path:     "actions/backup",
+		Message:  "hook size 101 bytes exceeds maximum of 100 bytes",
+	}, {
+		Severity: charmscan.SeverityError,
+		Path:     "lib/run",
+		Message:  "hook size 101 bytes exceeds maximum of 100 bytes",
+	}})
+}
+
+func (s *ScanSuite) TestNewScanners(c *gc.C) {
+	cfg := newControllerConfig(c, map[string]interface{}{})
+	scanners, err := charmscan.NewScanners(cfg)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(scannerNames(scanners), jc.DeepEquals, []string{
+		"structure", "metadata", "hook-size", "setuid",
+	})
+
+	cfg = newControllerConfig(c, map[string]interface{}{
+		controller.CharmScannersKey:    "setuid,hook-size",
+		controller.MaxCharmHookSizeKey: 1024,
+	})
+	scanners, err = charmscan.NewScanners(cfg)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(scanners, jc.DeepEquals, []charmscan.Scanner{
+		charmscan.SetuidScanner{},
+		charmscan.HookSizeScanner{MaxSize: 1024},
+	})
+
+	cfg = newControllerConfig(c, map[string]interface{}{
+		controller.CharmScannersKey: "none",
+	})
+	scanners, err = charmscan.NewScanners(cfg)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(scanners, gc.HasLen, 0)
+}
+
+func (s *ScanSuite) TestNewScannersUnknown(c *gc.C) {
+	cfg := newControllerConfig(c, map[string]interface{}{
+		controller.CharmScannersKey: "structure,antivirus",
+	})
+	_, err := charmscan.NewScanners(cfg)
+	c.Assert(err, jc.Satisfies, errors.IsNotValid)
+	c.Assert(err, gc.ErrorMatches, `charm-scanners "antivirus" not valid`)
+}
+
+func newControllerConfig(c *gc.C, attrs map[string]interface{}) controller.Config {
+	cfg, err := controller.NewConfig(coretesting.ControllerTag.Id(), coretesting.CACert, attrs)
+	c.Assert(err, jc.ErrorIsNil)
+	return cfg
+}
+
+func scannerNames(scanners []charmscan.Scanner) []string {
+	names := make([]string, len(scanners))
+	for i, scanner := range scanners {
+		names[i] = scanner.Name()
+	}
+	return names
+}
+
+func (s *ScanSuite) TestSetuidScanner(c *gc.C) {
+	archive := makeArchive(c, nil,
+		archiveEntry{name: "hooks/install", mode: 0755},
+		archiveEntry{name: "bin/su", mode: os.ModeSetuid | 0755},
+		archiveEntry{name: "bin/wall", mode: os.ModeSetgid | 0755},
+	)
+	findings, err := charmscan.SetuidScanner{}.Scan(archive)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(findings, jc.DeepEquals, []charmscan.Finding{{
+		Severity: charmscan.SeverityError,
+		Path:     "bin/su",
+		Message:  "setuid and setgid files not allowed",
+	}, {
+		Severity: charmscan.SeverityError,
+		Path:     "bin/wall",
+		Message:  "setuid and setgid files not allowed",
+	}})
+}
+
+type scannerFunc struct {
+	name     string
+	findings []charmscan.Finding
+}
+
+func (s scannerFunc) Name() string {
+	return s.name
+}
+
+func (s scannerFunc) Scan(charmscan.Archive) ([]charmscan.Finding, error) {
+	return s.findings, nil
+}
+
+type failingScanner struct{}
+
+func (failingScanner) Name() string {
+	return "failing"
+}
+
+func (failingScanner) Scan(charmscan.Archive) ([]charmscan.Finding, error) {
+	return nil, errors.New("boom")
+}
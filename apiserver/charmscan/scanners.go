@@ -0,0 +1,209 @@
+// Copyright 2016 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package charmscan
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/juju/errors"
+	"github.com/juju/version"
+
+	"github.com/juju/juju/controller"
+	jujuversion "github.com/juju/juju/version"
+)
+
+// newScannerFuncs holds a function to create each of the scanners
+// that may be enabled in the controller config, by name.
+var newScannerFuncs = map[string]func(controller.Config) Scanner{
+	"structure": func(controller.Config) Scanner {
+		return StructureScanner{}
+	},
+	"metadata": func(controller.Config) Scanner {
+		return MetadataScanner{JujuVersion: jujuversion.Current}
+	},
+	"hook-size": func(cfg controller.Config) Scanner {
+		return HookSizeScanner{MaxSize: uint64(cfg.MaxCharmHookSize())}
+	},
+	"setuid": func(controller.Config) Scanner {
+		return SetuidScanner{}
+	},
+}
+
+// defaultScannerNames holds the names of the scanners run
+// when the controller config does not specify them, in the
+// order in which they are run.
+var defaultScannerNames = []string{"structure", "metadata", "hook-size", "setuid"}
+
+// NewScanners returns the scanners enabled in the given controller
+// config, which are to be run over each charm archive added to a
+// model. An error satisfying errors.IsNotValid is returned if the
+// config names an unknown scanner.
+func NewScanners(cfg controller.Config) ([]Scanner, error) {
+	names := cfg.CharmScanners()
+	if names == nil {
+		names = defaultScannerNames
+	}
+	scanners := make([]Scanner, len(names))
+	for i, name := range names {
+		newScanner, ok := newScannerFuncs[name]
+		if !ok {
+			return nil, errors.NotValidf("%s %q", controller.CharmScannersKey, name)
+		}
+		scanners[i] = newScanner(cfg)
+	}
+	return scanners, nil
+}
+
+// entryPath returns the path of the given archive entry,
+// cleaned and with any backslashes converted to slashes.
+func entryPath(name string) string {
+	return path.Clean(strings.Replace(name, `\`, "/", -1))
+}
+
+// StructureScanner rejects archives with entries whose paths
+// are absolute, or would be extracted outside the charm's
+// directory.
+type StructureScanner struct{}
+
+// Name is part of the Scanner interface.
+func (StructureScanner) Name() string {
+	return "structure"
+}
+
+// Scan is part of the Scanner interface.
+func (StructureScanner) Scan(archive Archive) ([]Finding, error) {
+	var findings []Finding
+	for _, f := range archive.Zip.File {
+		name := entryPath(f.Name)
+		if path.IsAbs(name) {
+			findings = append(findings, Finding{
+				Severity: SeverityError,
+				Path:     f.Name,
+				Message:  "absolute path not allowed",
+			})
+			continue
+		}
+		if name == ".." || strings.HasPrefix(name, "../") {
+			findings = append(findings, Finding{
+				Severity: SeverityError,
+				Path:     f.Name,
+				Message:  "path outside charm directory not allowed",
+			})
+		}
+	}
+	return findings, nil
+}
+
+// MetadataScanner checks that the charm's metadata is supported
+// by the controller.
+type MetadataScanner struct {
+	// JujuVersion is the version of the controller; charms that
+	// require a newer version are rejected.
+	JujuVersion version.Number
+}
+
+// Name is part of the Scanner interface.
+func (MetadataScanner) Name() string {
+	return "metadata"
+}
+
+// Scan is part of the Scanner interface.
+func (s MetadataScanner) Scan(archive Archive) ([]Finding, error) {
+	var findings []Finding
+	minver := archive.Meta.MinJujuVersion
+	if minver != version.Zero && minver.Compare(s.JujuVersion) > 0 {
+		findings = append(findings, Finding{
+			Severity: SeverityError,
+			Path:     "metadata.yaml",
+			Message: fmt.Sprintf(
+				"min-juju-version %s is higher than the controller's version %s",
+				minver, s.JujuVersion,
+			),
+		})
+	}
+	if len(archive.Meta.Series) == 0 {
+		findings = append(findings, Finding{
+			Severity: SeverityWarning,
+			Path:     "metadata.yaml",
+			Message:  "no supported series declared",
+		})
+	}
+	return findings, nil
+}
+
+// HookSizeScanner rejects archives containing hook or action files
+// larger than a maximum size. Hook files are those in the charm's
+// hooks directory, and action files those in its actions directory;
+// any other executable file in the archive may be run by them, so
+// is also checked.
+type HookSizeScanner struct {
+	// MaxSize is the maximum uncompressed size, in bytes,
+	// of each hook or action file.
+	MaxSize uint64
+}
+
+// Name is part of the Scanner interface.
+func (HookSizeScanner) Name() string {
+	return "hook-size"
+}
+
+// Scan is part of the Scanner interface.
+func (s HookSizeScanner) Scan(archive Archive) ([]Finding, error) {
+	var findings []Finding
+	for _, f := range archive.Zip.File {
+		mode := f.Mode()
+		if mode.IsDir() || !isHookFile(entryPath(f.Name), mode) {
+			continue
+		}
+		if f.UncompressedSize64 > s.MaxSize {
+			findings = append(findings, Finding{
+				Severity: SeverityError,
+				Path:     f.Name,
+				Message: fmt.Sprintf(
+					"hook size %d bytes exceeds maximum of %d bytes",
+					f.UncompressedSize64, s.MaxSize,
+				),
+			})
+		}
+	}
+	return findings, nil
+}
+
+// isHookFile reports whether the archive entry with the given
+// cleaned path and mode may be run as, or by, a hook or action.
+func isHookFile(name string, mode os.FileMode) bool {
+	if strings.HasPrefix(name, "hooks/") || strings.HasPrefix(name, "actions/") {
+		return true
+	}
+	return mode&0111 != 0
+}
+
+// SetuidScanner rejects archives containing files with
+// the setuid or setgid bits set.
+type SetuidScanner struct{}
+
+// Name is part of the Scanner interface.
+func (SetuidScanner) Name() string {
+	return "setuid"
+}
+
+// Scan is part of the Scanner interface.
+func (SetuidScanner) Scan(archive Archive) ([]Finding, error) {
+	var findings []Finding
+	for _, f := range archive.Zip.File {
+		mode := f.Mode()
+		if mode&(os.ModeSetuid|os.ModeSetgid) == 0 {
+			continue
+		}
+		findings = append(findings, Finding{
+			Severity: SeverityError,
+			Path:     f.Name,
+			Message:  "setuid and setgid files not allowed",
+		})
+	}
+	return findings, nil
+}
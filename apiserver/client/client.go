@@ -92,7 +92,7 @@ func newClient(st *state.State, resources facade.Resources, authorizer facade.Au
 		return environs.GetEnviron(configGetter, environs.New)
 	}
 	blockChecker := common.NewBlockChecker(st)
-	modelConfigAPI, err := modelconfig.NewModelConfigAPI(st, authorizer)
+	modelConfigAPI, err := modelconfig.NewModelConfigAPI(st, resources, authorizer)
 	if err != nil {
 		return nil, errors.Trace(err)
 	}
@@ -142,9 +142,23 @@ func (c *Client) WatchAll() (params.AllWatcherId, error) {
 		return params.AllWatcherId{}, err
 	}
 	w := c.api.stateAccessor.Watch()
-	return params.AllWatcherId{
-		AllWatcherId: c.api.resources.Register(w),
-	}, nil
+	return common.RegisterAllWatcher(
+		c.api.resources, w, c.api.auth.GetAuthTag(),
+		c.api.stateAccessor.ModelTag().String(),
+	)
+}
+
+// ResumeWatchAll resumes an AllWatcher session started by WatchAll on
+// an earlier connection. The deltas after the given sequence number are
+// replayed by the resumed watcher before any new deltas.
+func (c *Client) ResumeWatchAll(args params.ResumeAllWatcher) (params.AllWatcherId, error) {
+	if err := c.checkCanRead(); err != nil {
+		return params.AllWatcherId{}, err
+	}
+	return common.ResumeAllWatcher(
+		c.api.resources, args, c.api.auth.GetAuthTag(),
+		c.api.stateAccessor.ModelTag().String(),
+	)
 }
 
 // Resolved implements the server side of Client.Resolved.
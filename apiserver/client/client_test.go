@@ -75,12 +75,13 @@ func (s *serverSuite) SetUpTest(c *gc.C) {
 		return s.newEnviron()
 	}
 	blockChecker := common.NewBlockChecker(s.State)
-	modelConfigAPI, err := modelconfig.NewModelConfigAPI(s.State, auth)
+	resources := common.NewResources()
+	modelConfigAPI, err := modelconfig.NewModelConfigAPI(s.State, resources, auth)
 	c.Assert(err, jc.ErrorIsNil)
 	s.client, err = client.NewClient(
 		client.NewStateBackend(s.State),
 		modelConfigAPI,
-		common.NewResources(),
+		resources,
 		auth,
 		statusSetter,
 		toolsFinder,
@@ -264,6 +265,41 @@ func (s *serverSuite) TestCheckProviderAPIFail(c *gc.C) {
 	s.assertCheckProviderAPI(c, errors.New("instances error"), "cannot make API call to provider: instances error")
 }
 
+type featuresEnviron struct {
+	environs.Environ
+}
+
+func (featuresEnviron) SupportedFeatures() ([]environs.Feature, error) {
+	return []environs.Feature{environs.FeatureVolumes, environs.FeatureSpaces}, nil
+}
+
+func (s *serverSuite) TestFullStatusSupportedFeatures(c *gc.C) {
+	s.newEnviron = func() (environs.Environ, error) {
+		return featuresEnviron{}, nil
+	}
+	status, err := s.client.FullStatus(params.StatusParams{})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(status.Model.Features, jc.DeepEquals, []string{"spaces", "volumes"})
+}
+
+func (s *serverSuite) TestFullStatusSupportedFeaturesUnknown(c *gc.C) {
+	s.newEnviron = func() (environs.Environ, error) {
+		return &mockEnviron{}, nil
+	}
+	status, err := s.client.FullStatus(params.StatusParams{})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(status.Model.Features, gc.IsNil)
+}
+
+func (s *serverSuite) TestFullStatusSupportedFeaturesError(c *gc.C) {
+	s.newEnviron = func() (environs.Environ, error) {
+		return nil, errors.New("no environ for you")
+	}
+	status, err := s.client.FullStatus(params.StatusParams{})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(status.Model.Features, gc.IsNil)
+}
+
 func (s *serverSuite) assertSetEnvironAgentVersion(c *gc.C) {
 	args := params.SetModelAgentVersion{
 		Version: version.MustParse("9.8.7"),
@@ -7,6 +7,7 @@ import (
 	"fmt"
 
 	"github.com/juju/errors"
+	"github.com/juju/utils/clock"
 	"github.com/juju/utils/set"
 
 	"github.com/juju/juju/api"
@@ -52,7 +53,13 @@ func InstanceConfig(st *state.State, machineId, nonce, dataDir string) (*instanc
 	if err != nil {
 		return nil, errors.Annotate(err, "getting state model")
 	}
-	urlGetter := common.NewToolsURLGetter(environment.UUID(), st)
+	// Tools URLs are signed, as the machine downloads its
+	// tools before it has any agent credentials.
+	urlSigner, err := common.NewStateToolsURLSigner(st, clock.WallClock)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	urlGetter := common.NewSignedToolsURLGetter(environment.UUID(), st, urlSigner)
 	configGetter := stateenvirons.EnvironConfigGetter{st}
 	toolsFinder := common.NewToolsFinder(configGetter, st, urlGetter)
 	findToolsResult, err := toolsFinder.FindTools(params.FindToolsParams{
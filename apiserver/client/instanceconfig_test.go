@@ -9,7 +9,6 @@ import (
 	"strconv"
 
 	jc "github.com/juju/testing/checkers"
-	"github.com/juju/version"
 	gc "gopkg.in/check.v1"
 
 	"github.com/juju/juju/apiserver/client"
@@ -54,9 +53,11 @@ func (s *machineConfigSuite) TestMachineConfig(c *gc.C) {
 	c.Check(instanceConfig.APIInfo.Addrs, gc.DeepEquals, apiAddrs)
 	toolsURL := fmt.Sprintf("https://%s/model/%s/tools/%s",
 		apiAddrs[0], jujutesting.ModelTag.Id(), instanceConfig.AgentVersion())
-	c.Assert(instanceConfig.ToolsList().URLs(), jc.DeepEquals, map[version.Binary][]string{
-		instanceConfig.AgentVersion(): []string{toolsURL},
-	})
+	urls := instanceConfig.ToolsList().URLs()
+	c.Assert(urls, gc.HasLen, 1)
+	c.Assert(urls[instanceConfig.AgentVersion()], gc.HasLen, 1)
+	c.Assert(urls[instanceConfig.AgentVersion()][0], jc.HasPrefix, toolsURL+"?")
+	c.Assert(urls[instanceConfig.AgentVersion()][0], gc.Matches, `.*\?expires=[0-9]+&signature=[0-9a-f]{64}`)
 }
 
 func (s *machineConfigSuite) TestMachineConfigNoArch(c *gc.C) {
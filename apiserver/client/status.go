@@ -6,6 +6,7 @@ package client
 import (
 	"fmt"
 	"sort"
+	"strconv"
 	"strings"
 
 	"github.com/juju/errors"
@@ -15,6 +16,7 @@ import (
 
 	"github.com/juju/juju/apiserver/common"
 	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/environs"
 	"github.com/juju/juju/network"
 	"github.com/juju/juju/state"
 	"github.com/juju/juju/state/multiwatcher"
@@ -167,17 +169,32 @@ func (c *Client) FullStatus(args params.StatusParams) (params.FullStatus, error)
 	}
 
 	var noStatus params.FullStatus
-	var context statusContext
+	if args.UnitsOffset < 0 {
+		return noStatus, errors.NotValidf("negative units offset")
+	}
+	if args.UnitsLimit < 0 {
+		return noStatus, errors.NotValidf("negative units limit")
+	}
+	context := statusContext{
+		unitsOffset: args.UnitsOffset,
+		unitsLimit:  args.UnitsLimit,
+	}
 	var err error
 	if context.services, context.units, context.latestCharms, err =
 		fetchAllApplicationsAndUnits(c.api.stateAccessor, len(args.Patterns) <= 0); err != nil {
 		return noStatus, errors.Annotate(err, "could not fetch services and units")
 	}
-	if context.machines, err = fetchMachines(c.api.stateAccessor, nil); err != nil {
-		return noStatus, errors.Annotate(err, "could not fetch machines")
+	// Machines are needed to match patterns, even if they are not
+	// reported; relations are reported with applications too.
+	if !args.ExcludeMachines || len(args.Patterns) > 0 {
+		if context.machines, err = fetchMachines(c.api.stateAccessor, nil); err != nil {
+			return noStatus, errors.Annotate(err, "could not fetch machines")
+		}
 	}
-	if context.relations, err = fetchRelations(c.api.stateAccessor); err != nil {
-		return noStatus, errors.Annotate(err, "could not fetch relations")
+	if !args.ExcludeRelations || !args.ExcludeApplications {
+		if context.relations, err = fetchRelations(c.api.stateAccessor); err != nil {
+			return noStatus, errors.Annotate(err, "could not fetch relations")
+		}
 	}
 	if len(context.services) > 0 {
 		if context.leaders, err = c.api.stateAccessor.ApplicationLeaders(); err != nil {
@@ -277,12 +294,17 @@ func (c *Client) FullStatus(args params.StatusParams) (params.FullStatus, error)
 	if err != nil {
 		return noStatus, errors.Annotate(err, "cannot determine model status")
 	}
-	return params.FullStatus{
-		Model:        modelStatus,
-		Machines:     processMachines(context.machines),
-		Applications: context.processApplications(),
-		Relations:    context.processRelations(),
-	}, nil
+	result := params.FullStatus{Model: modelStatus}
+	if !args.ExcludeMachines {
+		result.Machines = processMachines(context.machines)
+	}
+	if !args.ExcludeApplications {
+		result.Applications = context.processApplications()
+	}
+	if !args.ExcludeRelations {
+		result.Relations = context.processRelations()
+	}
+	return result, nil
 }
 
 // newToolsVersionAvailable will return a string representing a tools
@@ -322,9 +344,38 @@ func (c *Client) modelStatus() (params.ModelStatusInfo, error) {
 		info.Migration = migStatus
 	}
 
+	features, err := c.supportedFeatures()
+	if err != nil {
+		// As with migration status, it's not worth failing
+		// the entire status if the features can't be retrieved.
+		logger.Errorf("error retrieving supported features: %v", err)
+	} else {
+		info.Features = features
+	}
+
 	return info, nil
 }
 
+// supportedFeatures returns the features that the model's provider
+// supports, or nil if the provider does not declare them.
+func (c *Client) supportedFeatures() ([]string, error) {
+	env, err := c.newEnviron()
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	features, err := environs.SupportedFeatures(env)
+	if errors.IsNotSupported(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, errors.Trace(err)
+	}
+	result := make([]string, len(features))
+	for i, f := range features {
+		result[i] = string(f)
+	}
+	return result, nil
+}
+
 func (c *Client) getMigrationStatus() (string, error) {
 	mig, err := c.api.stateAccessor.LatestMigration()
 	if err != nil {
@@ -357,6 +408,11 @@ type statusContext struct {
 	units        map[string]map[string]*state.Unit
 	latestCharms map[charm.URL]*state.Charm
 	leaders      map[string]string
+
+	// unitsOffset and unitsLimit select the page of each principal
+	// application's units to report; see params.StatusParams.
+	unitsOffset int
+	unitsLimit  int
 }
 
 // fetchMachines returns a map from top level machine id to machines, where machines[0] is the host
@@ -365,12 +421,13 @@ type statusContext struct {
 // If machineIds is non-nil, only machines whose IDs are in the set are returned.
 func fetchMachines(st Backend, machineIds set.Strings) (map[string][]*state.Machine, error) {
 	v := make(map[string][]*state.Machine)
-	machines, err := st.AllMachines()
-	if err != nil {
-		return nil, err
-	}
-	// AllMachines gives us machines sorted by id.
-	for _, m := range machines {
+	// The machines are iterated over in batches, so that machines
+	// that are not of interest are not all held in memory at once.
+	// The iterator returns each machine after its parent.
+	iter := st.MachinesIter(0)
+	defer iter.Close()
+	for iter.Next() {
+		m := iter.Machine()
 		if machineIds != nil && !machineIds.Contains(m.Id()) {
 			continue
 		}
@@ -388,6 +445,9 @@ func fetchMachines(st Backend, machineIds set.Strings) (map[string][]*state.Mach
 			v[topParentId] = machines
 		}
 	}
+	if err := iter.Close(); err != nil {
+		return nil, err
+	}
 	return v, nil
 }
 
@@ -406,14 +466,15 @@ func fetchAllApplicationsAndUnits(
 		return nil, nil, nil, err
 	}
 	for _, s := range services {
-		units, err := s.AllUnits()
-		if err != nil {
-			return nil, nil, nil, err
-		}
 		svcUnitMap := make(map[string]*state.Unit)
-		for _, u := range units {
+		iter := s.UnitsIter(0)
+		for iter.Next() {
+			u := iter.Unit()
 			svcUnitMap[u.Name()] = u
 		}
+		if err := iter.Close(); err != nil {
+			return nil, nil, nil, err
+		}
 		if matchAny || len(svcUnitMap) > 0 {
 			unitMap[s.Name()] = svcUnitMap
 			svcMap[s.Name()] = s
@@ -537,6 +598,17 @@ func makeMachineStatus(machine *state.Machine) (status params.MachineStatus) {
 	} else {
 		status.Hardware = hc.String()
 	}
+	perr, err := machine.ProvisioningError()
+	if err == nil {
+		status.ProvisioningError = &params.MachineProvisioningError{
+			Kind:     perr.Kind,
+			Message:  perr.Message,
+			Attempts: perr.Attempts,
+			Since:    &perr.Updated,
+		}
+	} else if !errors.IsNotFound(err) {
+		logger.Debugf("error fetching provisioning error: %v", err)
+	}
 	status.Containers = make(map[string]params.MachineStatus)
 	return
 }
@@ -642,7 +714,12 @@ func (context *statusContext) processApplication(service *state.Application) par
 	}
 	units := context.units[service.Name()]
 	if service.IsPrincipal() {
-		processedStatus.Units = context.processUnits(units, serviceCharm.URL().String())
+		pageUnits := units
+		if context.unitsOffset > 0 || context.unitsLimit > 0 {
+			pageUnits = unitsPage(units, context.unitsOffset, context.unitsLimit)
+			processedStatus.UnitCount = len(units)
+		}
+		processedStatus.Units = context.processUnits(pageUnits, serviceCharm.URL().String())
 	}
 	applicationStatus, err := service.Status()
 	if err != nil {
@@ -660,27 +737,7 @@ func (context *statusContext) processApplication(service *state.Application) par
 		processedStatus.MeterStatuses = context.processUnitMeterStatuses(units)
 	}
 
-	versions := make([]status.StatusInfo, 0, len(units))
-	for _, unit := range units {
-		statuses, err := unit.WorkloadVersionHistory().StatusHistory(
-			status.StatusHistoryFilter{Size: 1},
-		)
-		if err != nil {
-			processedStatus.Err = common.ServerError(err)
-			return processedStatus
-		}
-		// Even though we fully expect there to be historical values there,
-		// even the first should be the empty string, the status history
-		// collection is not added to in a transactional manner, so it may be
-		// not there even though we'd really like it to be. Such is mongo.
-		if len(statuses) > 0 {
-			versions = append(versions, statuses[0])
-		}
-	}
-	if len(versions) > 0 {
-		sort.Sort(bySinceDescending(versions))
-		processedStatus.WorkloadVersion = versions[0].Message
-	}
+	processedStatus.WorkloadVersion = service.WorkloadVersion()
 
 	return processedStatus
 }
@@ -714,6 +771,45 @@ func (context *statusContext) processUnits(units map[string]*state.Unit, service
 	return unitsMap
 }
 
+// unitsPage returns the units on the page of the given units, ordered
+// by unit number, that starts at offset and has at most limit units,
+// or all of the remaining units if limit is zero.
+func unitsPage(units map[string]*state.Unit, offset, limit int) map[string]*state.Unit {
+	names := make([]string, 0, len(units))
+	for name := range units {
+		names = append(names, name)
+	}
+	sort.Sort(byUnitNumber(names))
+	if offset >= len(names) {
+		return nil
+	}
+	names = names[offset:]
+	if limit > 0 && limit < len(names) {
+		names = names[:limit]
+	}
+	page := make(map[string]*state.Unit, len(names))
+	for _, name := range names {
+		page[name] = units[name]
+	}
+	return page
+}
+
+// byUnitNumber sorts the names of an application's units
+// by their unit numbers.
+type byUnitNumber []string
+
+func (s byUnitNumber) Len() int      { return len(s) }
+func (s byUnitNumber) Swap(i, j int) { s[i], s[j] = s[j], s[i] }
+func (s byUnitNumber) Less(i, j int) bool {
+	return unitNumber(s[i]) < unitNumber(s[j])
+}
+
+// unitNumber returns the number of the named unit.
+func unitNumber(name string) int {
+	n, _ := strconv.Atoi(name[strings.LastIndex(name, "/")+1:])
+	return n
+}
+
 func (context *statusContext) processUnit(unit *state.Unit, serviceCharm string) params.UnitStatus {
 	var result params.UnitStatus
 	addr, err := unit.PublicAddress()
@@ -860,14 +956,3 @@ func processLife(entity lifer) string {
 	}
 	return ""
 }
-
-type bySinceDescending []status.StatusInfo
-
-// Len implements sort.Interface.
-func (s bySinceDescending) Len() int { return len(s) }
-
-// Swap implements sort.Interface.
-func (s bySinceDescending) Swap(a, b int) { s[a], s[b] = s[b], s[a] }
-
-// Less implements sort.Interface.
-func (s bySinceDescending) Less(a, b int) bool { return s[a].Since.After(*s[b].Since) }
@@ -57,6 +57,27 @@ func (s *statusSuite) TestFullStatus(c *gc.C) {
 	c.Check(resultMachine.Series, gc.Equals, machine.Series())
 }
 
+func (s *statusSuite) TestFullStatusProvisioningError(c *gc.C) {
+	machine := s.addMachine(c)
+	err := machine.SetProvisioningError(state.MachineProvisioningError{
+		Kind:     "quota",
+		Message:  "cores quota exhausted",
+		Attempts: 3,
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	client := s.APIState.Client()
+	status, err := client.Status(nil)
+	c.Assert(err, jc.ErrorIsNil)
+	resultMachine, ok := status.Machines[machine.Id()]
+	c.Assert(ok, jc.IsTrue)
+	perr := resultMachine.ProvisioningError
+	c.Assert(perr, gc.NotNil)
+	c.Check(perr.Kind, gc.Equals, "quota")
+	c.Check(perr.Message, gc.Equals, "cores quota exhausted")
+	c.Check(perr.Attempts, gc.Equals, 3)
+	c.Check(perr.Since, gc.NotNil)
+}
+
 func (s *statusSuite) TestFullStatusUnitLeadership(c *gc.C) {
 	u := s.Factory.MakeUnit(c, nil)
 	s.State.LeadershipClaimer().ClaimLeadership(u.ApplicationName(), u.Name(), time.Minute)
@@ -70,6 +91,91 @@ func (s *statusSuite) TestFullStatusUnitLeadership(c *gc.C) {
 	c.Assert(unit.Leader, jc.IsTrue)
 }
 
+func (s *statusSuite) TestFullStatusExclusions(c *gc.C) {
+	s.addMachine(c)
+	u := s.Factory.MakeUnit(c, nil)
+	client := s.APIState.Client()
+	status, err := client.FullStatus(params.StatusParams{
+		ExcludeMachines:  true,
+		ExcludeRelations: true,
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(status.Model.Name, gc.Equals, "controller")
+	c.Check(status.Machines, gc.HasLen, 0)
+	c.Check(status.Relations, gc.HasLen, 0)
+	c.Check(status.Applications, gc.HasLen, 1)
+
+	status, err = client.FullStatus(params.StatusParams{
+		ExcludeApplications: true,
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(status.Machines, gc.HasLen, 2)
+	c.Check(status.Applications, gc.HasLen, 0)
+
+	// Machines are still used to match patterns.
+	status, err = client.FullStatus(params.StatusParams{
+		Patterns:        []string{u.ApplicationName()},
+		ExcludeMachines: true,
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(status.Machines, gc.HasLen, 0)
+	c.Check(status.Applications, gc.HasLen, 1)
+}
+
+func (s *statusSuite) TestFullStatusUnitsPage(c *gc.C) {
+	application := s.Factory.MakeApplication(c, nil)
+	var units []*state.Unit
+	for i := 0; i < 12; i++ {
+		unit, err := application.AddUnit()
+		c.Assert(err, jc.ErrorIsNil)
+		units = append(units, unit)
+	}
+	client := s.APIState.Client()
+
+	// Units are ordered by number, not name, so 10 and 11
+	// come after 9.
+	status, err := client.FullStatus(params.StatusParams{
+		UnitsOffset: 8,
+		UnitsLimit:  3,
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	appStatus := status.Applications[application.Name()]
+	c.Check(appStatus.UnitCount, gc.Equals, 12)
+	c.Assert(appStatus.Units, gc.HasLen, 3)
+	for _, unit := range units[8:11] {
+		_, ok := appStatus.Units[unit.Name()]
+		c.Check(ok, jc.IsTrue)
+	}
+
+	status, err = client.FullStatus(params.StatusParams{UnitsOffset: 10})
+	c.Assert(err, jc.ErrorIsNil)
+	appStatus = status.Applications[application.Name()]
+	c.Check(appStatus.UnitCount, gc.Equals, 12)
+	c.Check(appStatus.Units, gc.HasLen, 2)
+
+	status, err = client.FullStatus(params.StatusParams{UnitsOffset: 20})
+	c.Assert(err, jc.ErrorIsNil)
+	appStatus = status.Applications[application.Name()]
+	c.Check(appStatus.UnitCount, gc.Equals, 12)
+	c.Check(appStatus.Units, gc.HasLen, 0)
+
+	// Without pagination, all units are reported and
+	// the count is omitted.
+	status, err = client.FullStatus(params.StatusParams{})
+	c.Assert(err, jc.ErrorIsNil)
+	appStatus = status.Applications[application.Name()]
+	c.Check(appStatus.UnitCount, gc.Equals, 0)
+	c.Check(appStatus.Units, gc.HasLen, 12)
+}
+
+func (s *statusSuite) TestFullStatusUnitsPageInvalid(c *gc.C) {
+	client := s.APIState.Client()
+	_, err := client.FullStatus(params.StatusParams{UnitsOffset: -1})
+	c.Assert(err, gc.ErrorMatches, "negative units offset not valid")
+	_, err = client.FullStatus(params.StatusParams{UnitsLimit: -1})
+	c.Assert(err, gc.ErrorMatches, "negative units limit not valid")
+}
+
 var _ = gc.Suite(&statusUnitTestSuite{})
 
 type statusUnitTestSuite struct {
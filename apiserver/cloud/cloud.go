@@ -22,6 +22,10 @@ func init() {
 	common.RegisterStandardFacade("Cloud", 1, newFacade)
 }
 
+// getProvider is used to obtain the provider for a cloud type, in
+// order to validate credentials.
+var getProvider = environs.Provider
+
 // CloudAPI implements the model manager interface and is
 // the concrete implementation of the api end point.
 type CloudAPI struct {
@@ -207,6 +211,15 @@ func (api *CloudAPI) UpdateCredentials(args params.UpdateCloudCredentials) (para
 			cloud.AuthType(arg.Credential.AuthType),
 			arg.Credential.Attributes,
 		)
+		// Validate the credential before storing it, so that
+		// models using the credential are not switched over to
+		// one that does not work.
+		if err := api.validateCredential(tag, in); err != nil {
+			results.Results[i].Error = common.ServerError(
+				errors.Annotate(err, "validating credential"),
+			)
+			continue
+		}
 		if err := api.backend.UpdateCloudCredential(tag, in); err != nil {
 			results.Results[i].Error = common.ServerError(err)
 			continue
@@ -215,6 +228,33 @@ func (api *CloudAPI) UpdateCredentials(args params.UpdateCloudCredentials) (para
 	return results, nil
 }
 
+// validateCredential validates the credential against the cloud, if the
+// cloud's provider is able to do so.
+func (api *CloudAPI) validateCredential(tag names.CloudCredentialTag, credential cloud.Credential) error {
+	cloudName := tag.Cloud().Id()
+	aCloud, err := api.backend.Cloud(cloudName)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	provider, err := getProvider(aCloud.Type)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	validator, ok := provider.(environs.CredentialValidator)
+	if !ok {
+		return nil
+	}
+	var regionName string
+	if len(aCloud.Regions) > 0 {
+		regionName = aCloud.Regions[0].Name
+	}
+	spec, err := environs.MakeCloudSpec(aCloud, cloudName, regionName, &credential)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	return validator.ValidateCredential(spec)
+}
+
 // RevokeCredentials revokes a set of cloud credentials.
 func (api *CloudAPI) RevokeCredentials(args params.Entities) (params.ErrorResults, error) {
 	results := params.ErrorResults{
@@ -4,6 +4,7 @@
 package cloud_test
 
 import (
+	"github.com/juju/errors"
 	gitjujutesting "github.com/juju/testing"
 	jc "github.com/juju/testing/checkers"
 	gc "gopkg.in/check.v1"
@@ -13,6 +14,7 @@ import (
 	"github.com/juju/juju/apiserver/params"
 	apiservertesting "github.com/juju/juju/apiserver/testing"
 	"github.com/juju/juju/cloud"
+	"github.com/juju/juju/environs"
 	_ "github.com/juju/juju/provider/dummy"
 )
 
@@ -148,7 +150,7 @@ func (s *cloudSuite) TestUpdateCredentials(c *gc.C) {
 		},
 	}}})
 	c.Assert(err, jc.ErrorIsNil)
-	s.backend.CheckCallNames(c, "ControllerTag", "UpdateCloudCredential")
+	s.backend.CheckCallNames(c, "ControllerTag", "Cloud", "UpdateCloudCredential")
 	c.Assert(results.Results, gc.HasLen, 3)
 	c.Assert(results.Results[0].Error, jc.DeepEquals, &params.Error{
 		Message: `"machine-0" is not a valid cloudcred tag`,
@@ -158,8 +160,9 @@ func (s *cloudSuite) TestUpdateCredentials(c *gc.C) {
 	})
 	c.Assert(results.Results[2].Error, gc.IsNil)
 
+	s.backend.CheckCall(c, 1, "Cloud", "meep")
 	s.backend.CheckCall(
-		c, 1, "UpdateCloudCredential",
+		c, 2, "UpdateCloudCredential",
 		names.NewCloudCredentialTag("meep/bruce/three"),
 		cloud.NewCredential(
 			cloud.OAuth1AuthType,
@@ -178,12 +181,60 @@ func (s *cloudSuite) TestUpdateCredentialsAdminAccess(c *gc.C) {
 		},
 	}}})
 	c.Assert(err, jc.ErrorIsNil)
-	s.backend.CheckCallNames(c, "ControllerTag", "UpdateCloudCredential")
+	s.backend.CheckCallNames(c, "ControllerTag", "Cloud", "UpdateCloudCredential")
 	c.Assert(results.Results, gc.HasLen, 1)
 	// admin can update others' credentials
 	c.Assert(results.Results[0].Error, gc.IsNil)
 }
 
+func (s *cloudSuite) TestUpdateCredentialsValidates(c *gc.C) {
+	provider := &validatingProvider{}
+	s.PatchValue(cloudfacade.GetProvider, func(cloudType string) (environs.EnvironProvider, error) {
+		c.Assert(cloudType, gc.Equals, "dummy")
+		return provider, nil
+	})
+	s.authorizer.Tag = names.NewUserTag("bruce@local")
+	results, err := s.api.UpdateCredentials(params.UpdateCloudCredentials{Credentials: []params.UpdateCloudCredential{{
+		Tag: "cloudcred-meep_bruce_three",
+		Credential: params.CloudCredential{
+			AuthType:   "userpass",
+			Attributes: map[string]string{"username": "bruce", "password": "rotated"},
+		},
+	}}})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(results.Results, gc.HasLen, 1)
+	c.Assert(results.Results[0].Error, gc.IsNil)
+	provider.CheckCallNames(c, "ValidateCredential")
+	spec := provider.Calls()[0].Args[0].(environs.CloudSpec)
+	c.Assert(spec.Name, gc.Equals, "meep")
+	c.Assert(spec.Region, gc.Equals, "nether")
+	c.Assert(spec.Endpoint, gc.Equals, "endpoint")
+	c.Assert(spec.Credential.Attributes()["password"], gc.Equals, "rotated")
+}
+
+func (s *cloudSuite) TestUpdateCredentialsValidationFails(c *gc.C) {
+	provider := &validatingProvider{}
+	provider.SetErrors(errors.New("invalid client secret"))
+	s.PatchValue(cloudfacade.GetProvider, func(string) (environs.EnvironProvider, error) {
+		return provider, nil
+	})
+	s.authorizer.Tag = names.NewUserTag("bruce@local")
+	results, err := s.api.UpdateCredentials(params.UpdateCloudCredentials{Credentials: []params.UpdateCloudCredential{{
+		Tag: "cloudcred-meep_bruce_three",
+		Credential: params.CloudCredential{
+			AuthType:   "userpass",
+			Attributes: map[string]string{"username": "bruce", "password": "wrong"},
+		},
+	}}})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(results.Results, gc.HasLen, 1)
+	c.Assert(results.Results[0].Error, jc.DeepEquals, &params.Error{
+		Message: "validating credential: invalid client secret",
+	})
+	// The invalid credential must not be stored.
+	s.backend.CheckCallNames(c, "ControllerTag", "Cloud")
+}
+
 func (s *cloudSuite) TestRevokeCredentials(c *gc.C) {
 	s.authorizer.Tag = names.NewUserTag("bruce@local")
 	results, err := s.api.RevokeCredentials(params.Entities{Entities: []params.Entity{{
@@ -262,6 +313,16 @@ func (s *cloudSuite) TestCredentialAdminAccess(c *gc.C) {
 	c.Assert(results.Results[0].Error, gc.IsNil)
 }
 
+type validatingProvider struct {
+	environs.EnvironProvider
+	gitjujutesting.Stub
+}
+
+func (p *validatingProvider) ValidateCredential(spec environs.CloudSpec) error {
+	p.MethodCall(p, "ValidateCredential", spec)
+	return p.NextErr()
+}
+
 type mockBackend struct {
 	gitjujutesting.Stub
 	cloud cloud.Cloud
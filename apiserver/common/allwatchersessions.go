@@ -0,0 +1,399 @@
+// Copyright 2016 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package common
+
+import (
+	"sync"
+	"time"
+
+	"github.com/juju/errors"
+	"github.com/juju/utils"
+	"github.com/juju/utils/clock"
+	"gopkg.in/juju/names.v2"
+
+	"github.com/juju/juju/apiserver/facade"
+	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/state/multiwatcher"
+)
+
+// AllWatcherSessionsResourceName is the name under which an API server's
+// AllWatcherSessions are registered in each connection's resources.
+const AllWatcherSessionsResourceName = "allWatcherSessions"
+
+// AllWatcher is the interface of the watchers created by the WatchAll
+// and WatchAllModels API calls. It is implemented by *state.Multiwatcher.
+type AllWatcher interface {
+	Next() ([]multiwatcher.Delta, error)
+	Stop() error
+}
+
+// AllWatcherSessionsConfig holds the configuration for an
+// AllWatcherSessions.
+type AllWatcherSessionsConfig struct {
+	// Clock is used to expire detached sessions.
+	Clock clock.Clock
+
+	// Timeout is how long a session is retained after its
+	// connection is closed, awaiting resumption by the client.
+	Timeout time.Duration
+
+	// ReplaySize is the maximum number of delta batches retained
+	// by each session for replay to a resuming client.
+	ReplaySize int
+}
+
+// Validate returns an error if the config is not valid.
+func (config AllWatcherSessionsConfig) Validate() error {
+	if config.Clock == nil {
+		return errors.NotValidf("nil Clock")
+	}
+	if config.Timeout <= 0 {
+		return errors.NotValidf("non-positive Timeout")
+	}
+	if config.ReplaySize <= 0 {
+		return errors.NotValidf("non-positive ReplaySize")
+	}
+	return nil
+}
+
+// AllWatcherSessions holds the resumable AllWatcher sessions of an API
+// server. A session outlives the connection that started it, so that a
+// client that loses its connection may resume the session on another
+// connection without having to receive the complete state again.
+//
+// Sessions whose connections have been closed are closed once they have
+// been detached for longer than the configured timeout. Expired sessions
+// are collected whenever a session is started, resumed or detached, and
+// periodically by a reaper until CloseAll is called.
+type AllWatcherSessions struct {
+	config AllWatcherSessionsConfig
+
+	// closed is closed by CloseAll to stop the reaper.
+	closed    chan struct{}
+	closeOnce sync.Once
+
+	mu       sync.Mutex
+	sessions map[string]*AllWatcherSession
+}
+
+// NewAllWatcherSessions returns a new AllWatcherSessions with the given
+// configuration.
+func NewAllWatcherSessions(config AllWatcherSessionsConfig) (*AllWatcherSessions, error) {
+	if err := config.Validate(); err != nil {
+		return nil, errors.Trace(err)
+	}
+	s := &AllWatcherSessions{
+		config:   config,
+		closed:   make(chan struct{}),
+		sessions: make(map[string]*AllWatcherSession),
+	}
+	go s.reap()
+	return s, nil
+}
+
+// reap closes expired sessions every timeout period, until CloseAll
+// is called. Without it, a session detached after the last session
+// was started, resumed or detached would never be closed.
+func (s *AllWatcherSessions) reap() {
+	for {
+		select {
+		case <-s.closed:
+			return
+		case <-s.config.Clock.After(s.config.Timeout):
+			s.mu.Lock()
+			s.expireLocked()
+			s.mu.Unlock()
+		}
+	}
+}
+
+// Stop is part of the facade.Resource interface. The sessions are shared
+// by all of an API server's connections, and are registered as a resource
+// of each; Stop therefore does nothing. Use CloseAll to close the sessions.
+func (s *AllWatcherSessions) Stop() error {
+	return nil
+}
+
+// CloseAll closes all of the sessions, stopping their watchers,
+// and stops the reaper.
+func (s *AllWatcherSessions) CloseAll() {
+	s.closeOnce.Do(func() { close(s.closed) })
+	s.mu.Lock()
+	sessions := s.sessions
+	s.sessions = make(map[string]*AllWatcherSession)
+	s.mu.Unlock()
+	for _, session := range sessions {
+		session.stopWatcher()
+	}
+}
+
+// Start starts a new session for the given watcher, attached to the
+// calling connection. The owner and scope of the session must be
+// matched when it is resumed; the scope identifies what is being
+// watched, e.g. a model.
+func (s *AllWatcherSessions) Start(w AllWatcher, owner names.Tag, scope string) (*AllWatcherSession, error) {
+	uuid, err := utils.NewUUID()
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	session := &AllWatcherSession{
+		sessions: s,
+		token:    uuid.String(),
+		owner:    owner,
+		scope:    scope,
+		watcher:  w,
+		attached: true,
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.expireLocked()
+	s.sessions[session.token] = session
+	return session, nil
+}
+
+// Resume attaches the session with the given token to the calling
+// connection. Deltas after the given sequence number, which must
+// still be held in the session's replay buffer, are replayed before
+// any new deltas are returned.
+//
+// An error satisfying errors.IsNotFound is returned if the session
+// does not exist, has expired, or can no longer replay the deltas
+// after the given sequence number; the client must then start a new
+// watcher.
+func (s *AllWatcherSessions) Resume(token string, owner names.Tag, scope string, sequence int64) (*AllWatcherSession, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.expireLocked()
+	session, ok := s.sessions[token]
+	if !ok || session.owner != owner || session.scope != scope {
+		return nil, errors.NotFoundf("AllWatcher session")
+	}
+	if err := session.resume(sequence); err != nil {
+		return nil, errors.Trace(err)
+	}
+	return session, nil
+}
+
+// remove removes the session with the given token, if it exists,
+// returning true if it was removed.
+func (s *AllWatcherSessions) remove(token string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.sessions[token]; !ok {
+		return false
+	}
+	delete(s.sessions, token)
+	return true
+}
+
+// expireLocked closes and removes the sessions that have been detached
+// for longer than the configured timeout. It must be called with s.mu
+// held.
+func (s *AllWatcherSessions) expireLocked() {
+	now := s.config.Clock.Now()
+	for token, session := range s.sessions {
+		if session.expired(now, s.config.Timeout) {
+			delete(s.sessions, token)
+			go session.stopWatcher()
+		}
+	}
+}
+
+// allWatcherBatch holds a batch of deltas returned by a session's
+// watcher, and the sequence number assigned to them.
+type allWatcherBatch struct {
+	sequence int64
+	deltas   []multiwatcher.Delta
+}
+
+// AllWatcherSession is a resumable session for an AllWatcher. The
+// session is registered as a resource of the connection it is attached
+// to; stopping the resource detaches the session rather than closing
+// it, so that the session may be resumed by the client.
+type AllWatcherSession struct {
+	sessions *AllWatcherSessions
+	token    string
+	owner    names.Tag
+	scope    string
+	watcher  AllWatcher
+
+	// mu guards the fields below it.
+	mu sync.Mutex
+
+	// attached records whether or not the session is attached to a
+	// connection, and detached records when it was last detached.
+	attached bool
+	detached time.Time
+
+	// sequence is the sequence number of the most recent batch of
+	// deltas, and delivered is the sequence number of the most
+	// recent batch returned by Next.
+	sequence  int64
+	delivered int64
+
+	// replay holds the most recent batches of deltas, oldest first.
+	replay []allWatcherBatch
+
+	// fetching is non-nil while the watcher's Next method is being
+	// called, and is closed when the call completes.
+	fetching chan struct{}
+
+	// err holds the error returned by the watcher, if any.
+	err error
+}
+
+// Token returns the token that identifies the session.
+func (s *AllWatcherSession) Token() string {
+	return s.token
+}
+
+// Next returns the next batch of deltas and its sequence number,
+// blocking until there is one or the abort channel is closed.
+//
+// Batches retrieved from the watcher are recorded for replay even if
+// the call to Next is aborted, so they are not lost if the client's
+// connection is closed while it is waiting.
+func (s *AllWatcherSession) Next(abort <-chan struct{}) ([]multiwatcher.Delta, int64, error) {
+	for {
+		s.mu.Lock()
+		for _, batch := range s.replay {
+			if batch.sequence > s.delivered {
+				s.delivered = batch.sequence
+				s.mu.Unlock()
+				return batch.deltas, batch.sequence, nil
+			}
+		}
+		if s.err != nil {
+			err := s.err
+			s.mu.Unlock()
+			return nil, 0, err
+		}
+		if s.fetching == nil {
+			s.fetching = make(chan struct{})
+			go s.fetch(s.fetching)
+		}
+		fetching := s.fetching
+		s.mu.Unlock()
+
+		select {
+		case <-fetching:
+		case <-abort:
+			return nil, 0, errors.New("AllWatcher session aborted")
+		}
+	}
+}
+
+// fetch retrieves the next batch of deltas from the watcher, and
+// records it for replay.
+func (s *AllWatcherSession) fetch(done chan struct{}) {
+	deltas, err := s.watcher.Next()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	defer close(done)
+	s.fetching = nil
+	if err != nil {
+		s.err = err
+		return
+	}
+	s.sequence++
+	s.replay = append(s.replay, allWatcherBatch{s.sequence, deltas})
+	if excess := len(s.replay) - s.sessions.config.ReplaySize; excess > 0 {
+		// A batch is only fetched when all recorded batches have
+		// been delivered, so only delivered batches are dropped.
+		s.replay = append(s.replay[:0:0], s.replay[excess:]...)
+	}
+}
+
+// resume attaches the session to a connection, arranging for the
+// batches after the given sequence number to be replayed.
+func (s *AllWatcherSession) resume(sequence int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.attached {
+		return errors.Errorf("AllWatcher session is in use")
+	}
+	if sequence < 0 || sequence > s.sequence {
+		return errors.NotValidf("sequence %d", sequence)
+	}
+	if sequence < s.sequence && (len(s.replay) == 0 || sequence < s.replay[0].sequence-1) {
+		return errors.NotFoundf("AllWatcher deltas after sequence %d", sequence)
+	}
+	s.attached = true
+	s.delivered = sequence
+	return nil
+}
+
+// Stop is part of the facade.Resource interface. It detaches the
+// session from its connection; the session is retained until it is
+// resumed or expires.
+func (s *AllWatcherSession) Stop() error {
+	s.mu.Lock()
+	s.attached = false
+	s.detached = s.sessions.config.Clock.Now()
+	s.mu.Unlock()
+
+	s.sessions.mu.Lock()
+	defer s.sessions.mu.Unlock()
+	s.sessions.expireLocked()
+	return nil
+}
+
+// Close closes the session, stopping its watcher.
+func (s *AllWatcherSession) Close() error {
+	if !s.sessions.remove(s.token) {
+		// The session has already been closed.
+		return nil
+	}
+	return s.stopWatcher()
+}
+
+func (s *AllWatcherSession) stopWatcher() error {
+	return errors.Trace(s.watcher.Stop())
+}
+
+// expired reports whether the session has been detached for at
+// least the given timeout.
+func (s *AllWatcherSession) expired(now time.Time, timeout time.Duration) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return !s.attached && now.Sub(s.detached) >= timeout
+}
+
+// RegisterAllWatcher registers the given watcher in resources, returning
+// the watcher's id. If the resources hold the API server's
+// AllWatcherSessions, the watcher is registered as a resumable session
+// with the given owner and scope.
+func RegisterAllWatcher(resources facade.Resources, w AllWatcher, owner names.Tag, scope string) (params.AllWatcherId, error) {
+	sessions, ok := resources.Get(AllWatcherSessionsResourceName).(*AllWatcherSessions)
+	if !ok {
+		return params.AllWatcherId{AllWatcherId: resources.Register(w)}, nil
+	}
+	session, err := sessions.Start(w, owner, scope)
+	if err != nil {
+		w.Stop()
+		return params.AllWatcherId{}, errors.Trace(err)
+	}
+	return params.AllWatcherId{
+		AllWatcherId: resources.Register(session),
+		SessionToken: session.Token(),
+	}, nil
+}
+
+// ResumeAllWatcher resumes the AllWatcher session identified by args,
+// registering it in resources and returning its new id. The owner and
+// scope must match those with which the session was started.
+func ResumeAllWatcher(resources facade.Resources, args params.ResumeAllWatcher, owner names.Tag, scope string) (params.AllWatcherId, error) {
+	sessions, ok := resources.Get(AllWatcherSessionsResourceName).(*AllWatcherSessions)
+	if !ok {
+		return params.AllWatcherId{}, errors.NotSupportedf("resuming AllWatcher sessions")
+	}
+	session, err := sessions.Resume(args.SessionToken, owner, scope, args.Sequence)
+	if err != nil {
+		return params.AllWatcherId{}, errors.Trace(err)
+	}
+	return params.AllWatcherId{
+		AllWatcherId: resources.Register(session),
+		SessionToken: session.Token(),
+	}, nil
+}
@@ -0,0 +1,290 @@
+// Copyright 2016 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package common_test
+
+import (
+	"time"
+
+	"github.com/juju/errors"
+	jujutesting "github.com/juju/testing"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+	"gopkg.in/juju/names.v2"
+
+	"github.com/juju/juju/apiserver/common"
+	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/state/multiwatcher"
+	coretesting "github.com/juju/juju/testing"
+)
+
+type allWatcherSessionsSuite struct {
+	clock    *jujutesting.Clock
+	sessions *common.AllWatcherSessions
+	owner    names.Tag
+}
+
+var _ = gc.Suite(&allWatcherSessionsSuite{})
+
+func (s *allWatcherSessionsSuite) SetUpTest(c *gc.C) {
+	s.clock = jujutesting.NewClock(time.Time{})
+	sessions, err := common.NewAllWatcherSessions(common.AllWatcherSessionsConfig{
+		Clock:      s.clock,
+		Timeout:    time.Minute,
+		ReplaySize: 2,
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	s.sessions = sessions
+	s.owner = names.NewUserTag("bob")
+}
+
+func (s *allWatcherSessionsSuite) TearDownTest(c *gc.C) {
+	s.sessions.CloseAll()
+}
+
+func (s *allWatcherSessionsSuite) TestConfigValidate(c *gc.C) {
+	_, err := common.NewAllWatcherSessions(common.AllWatcherSessionsConfig{
+		Timeout:    time.Minute,
+		ReplaySize: 1,
+	})
+	c.Assert(err, gc.ErrorMatches, "nil Clock not valid")
+	_, err = common.NewAllWatcherSessions(common.AllWatcherSessionsConfig{
+		Clock:      s.clock,
+		ReplaySize: 1,
+	})
+	c.Assert(err, gc.ErrorMatches, "non-positive Timeout not valid")
+	_, err = common.NewAllWatcherSessions(common.AllWatcherSessionsConfig{
+		Clock:   s.clock,
+		Timeout: time.Minute,
+	})
+	c.Assert(err, gc.ErrorMatches, "non-positive ReplaySize not valid")
+}
+
+func (s *allWatcherSessionsSuite) TestNextSequence(c *gc.C) {
+	w := newFakeAllWatcher()
+	session, err := s.sessions.Start(w, s.owner, "scope")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(session.Token(), gc.Not(gc.Equals), "")
+
+	s.assertNext(c, session, w, "a", 1)
+	s.assertNext(c, session, w, "b", 2)
+}
+
+func (s *allWatcherSessionsSuite) TestResumeReplays(c *gc.C) {
+	w := newFakeAllWatcher()
+	session, err := s.sessions.Start(w, s.owner, "scope")
+	c.Assert(err, jc.ErrorIsNil)
+	s.assertNext(c, session, w, "a", 1)
+	s.assertNext(c, session, w, "b", 2)
+
+	// The client received "a", but lost its
+	// connection before receiving "b".
+	c.Assert(session.Stop(), jc.ErrorIsNil)
+	resumed, err := s.sessions.Resume(session.Token(), s.owner, "scope", 1)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(resumed, gc.Equals, session)
+
+	deltas, sequence, err := resumed.Next(nil)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(sequence, gc.Equals, int64(2))
+	c.Assert(deltas, jc.DeepEquals, fakeDeltas("b"))
+	s.assertNext(c, resumed, w, "c", 3)
+}
+
+func (s *allWatcherSessionsSuite) TestResumeBeyondReplay(c *gc.C) {
+	w := newFakeAllWatcher()
+	session, err := s.sessions.Start(w, s.owner, "scope")
+	c.Assert(err, jc.ErrorIsNil)
+	s.assertNext(c, session, w, "a", 1)
+	s.assertNext(c, session, w, "b", 2)
+	s.assertNext(c, session, w, "c", 3)
+	c.Assert(session.Stop(), jc.ErrorIsNil)
+
+	// Only two batches are retained, so the
+	// deltas after sequence 0 are lost.
+	_, err = s.sessions.Resume(session.Token(), s.owner, "scope", 0)
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+	_, err = s.sessions.Resume(session.Token(), s.owner, "scope", 4)
+	c.Assert(err, jc.Satisfies, errors.IsNotValid)
+	_, err = s.sessions.Resume(session.Token(), s.owner, "scope", 1)
+	c.Assert(err, jc.ErrorIsNil)
+}
+
+func (s *allWatcherSessionsSuite) TestResumeInUse(c *gc.C) {
+	w := newFakeAllWatcher()
+	session, err := s.sessions.Start(w, s.owner, "scope")
+	c.Assert(err, jc.ErrorIsNil)
+	_, err = s.sessions.Resume(session.Token(), s.owner, "scope", 0)
+	c.Assert(err, gc.ErrorMatches, "AllWatcher session is in use")
+}
+
+func (s *allWatcherSessionsSuite) TestResumeMismatch(c *gc.C) {
+	w := newFakeAllWatcher()
+	session, err := s.sessions.Start(w, s.owner, "scope")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(session.Stop(), jc.ErrorIsNil)
+
+	_, err = s.sessions.Resume(session.Token(), names.NewUserTag("mallory"), "scope", 0)
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+	_, err = s.sessions.Resume(session.Token(), s.owner, "elsewhere", 0)
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+	_, err = s.sessions.Resume("nope", s.owner, "scope", 0)
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+}
+
+func (s *allWatcherSessionsSuite) TestExpiry(c *gc.C) {
+	w := newFakeAllWatcher()
+	session, err := s.sessions.Start(w, s.owner, "scope")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(session.Stop(), jc.ErrorIsNil)
+
+	s.clock.Advance(time.Minute)
+	_, err = s.sessions.Resume(session.Token(), s.owner, "scope", 0)
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+	w.assertStopped(c)
+}
+
+func (s *allWatcherSessionsSuite) TestReaper(c *gc.C) {
+	w := newFakeAllWatcher()
+	session, err := s.sessions.Start(w, s.owner, "scope")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(session.Stop(), jc.ErrorIsNil)
+
+	// The reaper closes the expired session, without any
+	// other session being started, resumed or detached.
+	select {
+	case <-s.clock.Alarms():
+	case <-time.After(coretesting.LongWait):
+		c.Fatalf("timed out waiting for reaper")
+	}
+	s.clock.Advance(time.Minute)
+	w.assertStopped(c)
+}
+
+func (s *allWatcherSessionsSuite) TestClose(c *gc.C) {
+	w := newFakeAllWatcher()
+	session, err := s.sessions.Start(w, s.owner, "scope")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(session.Close(), jc.ErrorIsNil)
+	w.assertStopped(c)
+
+	_, err = s.sessions.Resume(session.Token(), s.owner, "scope", 0)
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+}
+
+func (s *allWatcherSessionsSuite) TestNextAbort(c *gc.C) {
+	w := newFakeAllWatcher()
+	session, err := s.sessions.Start(w, s.owner, "scope")
+	c.Assert(err, jc.ErrorIsNil)
+
+	abort := make(chan struct{})
+	close(abort)
+	_, _, err = session.Next(abort)
+	c.Assert(err, gc.ErrorMatches, "AllWatcher session aborted")
+
+	// The deltas fetched while aborted are not lost.
+	w.deltas <- fakeDeltas("a")
+	deltas, sequence, err := session.Next(nil)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(sequence, gc.Equals, int64(1))
+	c.Assert(deltas, jc.DeepEquals, fakeDeltas("a"))
+}
+
+func (s *allWatcherSessionsSuite) TestRegisterWithoutSessions(c *gc.C) {
+	resources := common.NewResources()
+	w := newFakeAllWatcher()
+	id, err := common.RegisterAllWatcher(resources, w, s.owner, "scope")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(id, jc.DeepEquals, params.AllWatcherId{AllWatcherId: "1"})
+	c.Assert(resources.Get("1"), gc.Equals, w)
+
+	_, err = common.ResumeAllWatcher(resources, params.ResumeAllWatcher{}, s.owner, "scope")
+	c.Assert(err, jc.Satisfies, errors.IsNotSupported)
+}
+
+func (s *allWatcherSessionsSuite) TestRegisterAndResume(c *gc.C) {
+	resources := common.NewResources()
+	err := resources.RegisterNamed(common.AllWatcherSessionsResourceName, s.sessions)
+	c.Assert(err, jc.ErrorIsNil)
+	w := newFakeAllWatcher()
+	id, err := common.RegisterAllWatcher(resources, w, s.owner, "scope")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(id.SessionToken, gc.Not(gc.Equals), "")
+	session, ok := resources.Get(id.AllWatcherId).(*common.AllWatcherSession)
+	c.Assert(ok, jc.IsTrue)
+	c.Assert(session.Token(), gc.Equals, id.SessionToken)
+
+	// Stopping the connection's resources detaches
+	// the session, without stopping the watcher.
+	resources.StopAll()
+	w.assertNotStopped(c)
+
+	resources = common.NewResources()
+	err = resources.RegisterNamed(common.AllWatcherSessionsResourceName, s.sessions)
+	c.Assert(err, jc.ErrorIsNil)
+	resumed, err := common.ResumeAllWatcher(resources, params.ResumeAllWatcher{
+		SessionToken: id.SessionToken,
+	}, s.owner, "scope")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(resumed.SessionToken, gc.Equals, id.SessionToken)
+	c.Assert(resources.Get(resumed.AllWatcherId), gc.Equals, session)
+}
+
+func (s *allWatcherSessionsSuite) assertNext(
+	c *gc.C, session *common.AllWatcherSession, w *fakeAllWatcher,
+	name string, expectSequence int64,
+) {
+	w.deltas <- fakeDeltas(name)
+	deltas, sequence, err := session.Next(nil)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(sequence, gc.Equals, expectSequence)
+	c.Assert(deltas, jc.DeepEquals, fakeDeltas(name))
+}
+
+func fakeDeltas(name string) []multiwatcher.Delta {
+	return []multiwatcher.Delta{{
+		Entity: &multiwatcher.MachineInfo{Id: name},
+	}}
+}
+
+type fakeAllWatcher struct {
+	deltas  chan []multiwatcher.Delta
+	stopped chan struct{}
+}
+
+func newFakeAllWatcher() *fakeAllWatcher {
+	return &fakeAllWatcher{
+		deltas:  make(chan []multiwatcher.Delta),
+		stopped: make(chan struct{}),
+	}
+}
+
+func (w *fakeAllWatcher) Next() ([]multiwatcher.Delta, error) {
+	select {
+	case deltas := <-w.deltas:
+		return deltas, nil
+	case <-w.stopped:
+		return nil, errors.New("watcher stopped")
+	}
+}
+
+func (w *fakeAllWatcher) Stop() error {
+	close(w.stopped)
+	return nil
+}
+
+func (w *fakeAllWatcher) assertStopped(c *gc.C) {
+	select {
+	case <-w.stopped:
+	case <-time.After(coretesting.LongWait):
+		c.Fatalf("timed out waiting for watcher to be stopped")
+	}
+}
+
+func (w *fakeAllWatcher) assertNotStopped(c *gc.C) {
+	select {
+	case <-w.stopped:
+		c.Fatalf("watcher stopped unexpectedly")
+	case <-time.After(coretesting.ShortWait):
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"net/http"
 	"strings"
+	"time"
 
 	"github.com/juju/errors"
 	"github.com/juju/txn"
@@ -113,6 +114,30 @@ func OperationBlockedError(msg string) error {
 	}
 }
 
+// retryableError wraps an error to indicate that the failed
+// operation is expected to succeed if retried.
+type retryableError struct {
+	err   error
+	after time.Duration
+}
+
+func (e *retryableError) Error() string {
+	return e.err.Error()
+}
+
+// RetryableError returns an error that, when returned to an API client,
+// indicates that the operation failed with the given error due to a
+// transient condition, and should be retried no sooner than after the
+// specified duration. If after is zero, the client chooses its own delay.
+//
+// The error code is determined by the wrapped error.
+func RetryableError(err error, after time.Duration) error {
+	if err == nil {
+		return nil
+	}
+	return &retryableError{err: err, after: after}
+}
+
 var singletonErrorCodes = map[error]string{
 	state.ErrCannotEnterScopeYet: params.CodeCannotEnterScopeYet,
 	state.ErrCannotEnterScope:    params.CodeCannotEnterScope,
@@ -184,6 +209,10 @@ func ServerErrorAndStatus(err error) (*params.Error, int) {
 		status = http.StatusUnauthorized
 	case params.CodeRetry:
 		status = http.StatusServiceUnavailable
+	default:
+		if err1.Info != nil && err1.Info.Retryable {
+			status = http.StatusServiceUnavailable
+		}
 	}
 	return err1, status
 }
@@ -199,8 +228,15 @@ func ServerError(err error) *params.Error {
 	msg := err.Error()
 	// Skip past annotations when looking for the code.
 	err = errors.Cause(err)
-	code, ok := singletonCode(err)
 	var info *params.ErrorInfo
+	if rerr, ok := err.(*retryableError); ok {
+		info = &params.ErrorInfo{
+			Retryable:  true,
+			RetryAfter: rerr.after,
+		}
+		err = errors.Cause(rerr.err)
+	}
+	code, ok := singletonCode(err)
 	switch {
 	case ok:
 	case errors.IsUnauthorized(err):
@@ -6,6 +6,7 @@ package common_test
 import (
 	stderrors "errors"
 	"net/http"
+	"time"
 
 	"github.com/juju/errors"
 	jc "github.com/juju/testing/checkers"
@@ -260,6 +261,28 @@ func (s *errorsSuite) TestErrorTransform(c *gc.C) {
 	}
 }
 
+func (s *errorsSuite) TestRetryableError(c *gc.C) {
+	err := common.RetryableError(errors.NotProvisionedf("machine 0"), time.Minute)
+	err = errors.Annotate(err, "cannot do the thing")
+
+	err1, status := common.ServerErrorAndStatus(err)
+	c.Assert(err1, jc.DeepEquals, &params.Error{
+		Message: "cannot do the thing: machine 0 not provisioned",
+		Code:    params.CodeNotProvisioned,
+		Info: &params.ErrorInfo{
+			Retryable:  true,
+			RetryAfter: time.Minute,
+		},
+	})
+	c.Assert(status, gc.Equals, http.StatusServiceUnavailable)
+	c.Assert(params.IsRetryable(err1), jc.IsTrue)
+	c.Assert(params.RetryAfter(err1), gc.Equals, time.Minute)
+}
+
+func (s *errorsSuite) TestRetryableErrorNil(c *gc.C) {
+	c.Assert(common.RetryableError(nil, time.Minute), jc.ErrorIsNil)
+}
+
 func (s *errorsSuite) TestUnknownModel(c *gc.C) {
 	err := common.UnknownModelError("dead-beef")
 	c.Check(err, gc.ErrorMatches, `unknown model: "dead-beef"`)
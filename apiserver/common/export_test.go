@@ -6,12 +6,13 @@ package common
 import "github.com/juju/juju/apiserver/facade"
 
 var (
-	MachineJobFromParams    = machineJobFromParams
-	ValidateNewFacade       = validateNewFacade
-	WrapNewFacade           = wrapNewFacade
-	EnvtoolsFindTools       = &envtoolsFindTools
-	SendMetrics             = &sendMetrics
-	MockableDestroyMachines = destroyMachines
+	MachineJobFromParams              = machineJobFromParams
+	ValidateNewFacade                 = validateNewFacade
+	WrapNewFacade                     = wrapNewFacade
+	EnvtoolsFindTools                 = &envtoolsFindTools
+	EnvtoolsFindControllerMirrorTools = &envtoolsFindControllerMirrorTools
+	SendMetrics                       = &sendMetrics
+	MockableDestroyMachines           = destroyMachines
 )
 
 type Patcher interface {
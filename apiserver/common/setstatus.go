@@ -134,28 +134,43 @@ func NewStatusSetter(st state.EntityFinder, getCanModify GetAuthFunc) *StatusSet
 	}
 }
 
-func (s *StatusSetter) setEntityStatus(tag names.Tag, entityStatus status.Status, info string, data map[string]interface{}, updated *time.Time) error {
+// statusesSetter is implemented by state.EntityFinders that can set
+// the statuses of many entities at once, such as *state.State.
+type statusesSetter interface {
+	SetStatuses([]state.StatusUpdate) []error
+}
+
+// setStatuses sets the statuses of the given entities, in a batch if
+// the supplied EntityFinder supports it, and individually otherwise.
+func setStatuses(st state.EntityFinder, updates []state.StatusUpdate) []error {
+	if setter, ok := st.(statusesSetter); ok {
+		return setter.SetStatuses(updates)
+	}
+	errs := make([]error, len(updates))
+	for i, update := range updates {
+		errs[i] = update.Entity.SetStatus(update.Status)
+	}
+	return errs
+}
+
+func (s *StatusSetter) findStatusSetter(tag names.Tag) (status.StatusSetter, error) {
 	entity, err := s.st.FindEntity(tag)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	switch entity := entity.(type) {
 	case *state.Application:
-		return ErrPerm
+		return nil, ErrPerm
 	case status.StatusSetter:
-		sInfo := status.StatusInfo{
-			Status:  entityStatus,
-			Message: info,
-			Data:    data,
-			Since:   updated,
-		}
-		return entity.SetStatus(sInfo)
+		return entity, nil
 	default:
-		return NotSupportedError(tag, fmt.Sprintf("setting status, %T", entity))
+		return nil, NotSupportedError(tag, fmt.Sprintf("setting status, %T", entity))
 	}
 }
 
-// SetStatus sets the status of each given entity.
+// SetStatus sets the status of each given entity. The statuses are
+// set together, where the underlying state supports it, so that many
+// statuses may be set in a single transaction.
 func (s *StatusSetter) SetStatus(args params.SetStatus) (params.ErrorResults, error) {
 	result := params.ErrorResults{
 		Results: make([]params.ErrorResult, len(args.Entities)),
@@ -169,17 +184,36 @@ func (s *StatusSetter) SetStatus(args params.SetStatus) (params.ErrorResults, er
 	}
 	// TODO(perrito666) 2016-05-02 lp:1558657
 	now := time.Now()
+	var updates []state.StatusUpdate
+	var indices []int
 	for i, arg := range args.Entities {
 		tag, err := names.ParseTag(arg.Tag)
 		if err != nil {
 			result.Results[i].Error = ServerError(err)
 			continue
 		}
-		err = ErrPerm
-		if canModify(tag) {
-			err = s.setEntityStatus(tag, status.Status(arg.Status), arg.Info, arg.Data, &now)
+		if !canModify(tag) {
+			result.Results[i].Error = ServerError(ErrPerm)
+			continue
 		}
-		result.Results[i].Error = ServerError(err)
+		entity, err := s.findStatusSetter(tag)
+		if err != nil {
+			result.Results[i].Error = ServerError(err)
+			continue
+		}
+		updates = append(updates, state.StatusUpdate{
+			Entity: entity,
+			Status: status.StatusInfo{
+				Status:  status.Status(arg.Status),
+				Message: arg.Info,
+				Data:    arg.Data,
+				Since:   &now,
+			},
+		})
+		indices = append(indices, i)
+	}
+	for i, err := range setStatuses(s.st, updates) {
+		result.Results[indices[i]].Error = ServerError(err)
 	}
 	return result, nil
 }
@@ -258,6 +292,12 @@ type UnitAgentFinder struct {
 	state.EntityFinder
 }
 
+// SetStatuses sets the statuses of the given entities, in a batch
+// if the wrapped EntityFinder supports it.
+func (ua *UnitAgentFinder) SetStatuses(updates []state.StatusUpdate) []error {
+	return setStatuses(ua.EntityFinder, updates)
+}
+
 // FindEntity implements state.EntityFinder and returns unit agents.
 func (ua *UnitAgentFinder) FindEntity(tag names.Tag) (state.Entity, error) {
 	_, ok := tag.(names.UnitTag)
@@ -141,6 +141,62 @@ func (s *statusSetterSuite) TestBulk(c *gc.C) {
 	c.Assert(result.Results[1].Error, gc.ErrorMatches, `"bad-tag" is not a valid tag`)
 }
 
+func (s *statusSetterSuite) TestBulkSetsStatuses(c *gc.C) {
+	machine := s.Factory.MakeMachine(c, nil)
+	unit := s.Factory.MakeUnit(c, &factory.UnitParams{Status: &status.StatusInfo{
+		Status: status.Maintenance,
+	}})
+	s.badTag = names.NewMachineTag("42")
+	result, err := s.setter.SetStatus(params.SetStatus{[]params.EntityStatusArgs{{
+		Tag:    machine.Tag().String(),
+		Status: status.Started.String(),
+	}, {
+		Tag:    s.badTag.String(),
+		Status: status.Started.String(),
+	}, {
+		Tag:    unit.Tag().String(),
+		Status: status.Active.String(),
+	}, {
+		Tag:    names.NewMachineTag("43").String(),
+		Status: status.Started.String(),
+	}, {
+		Tag:    machine.Tag().String(),
+		Status: "vliegkat",
+	}}})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(result.Results, gc.HasLen, 5)
+	c.Assert(result.Results[0].Error, gc.IsNil)
+	c.Assert(result.Results[1].Error, jc.Satisfies, params.IsCodeUnauthorized)
+	c.Assert(result.Results[2].Error, gc.IsNil)
+	c.Assert(result.Results[3].Error, jc.Satisfies, params.IsCodeNotFound)
+	c.Assert(result.Results[4].Error, gc.ErrorMatches, `cannot set invalid status "vliegkat"`)
+
+	machineStatus, err := machine.Status()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(machineStatus.Status, gc.Equals, status.Started)
+	unitStatus, err := unit.Status()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(unitStatus.Status, gc.Equals, status.Active)
+}
+
+func (s *statusSetterSuite) TestSetUnitAgentStatus(c *gc.C) {
+	unit := s.Factory.MakeUnit(c, nil)
+	setter := common.NewStatusSetter(&common.UnitAgentFinder{s.State}, func() (common.AuthFunc, error) {
+		return s.authFunc, nil
+	})
+	result, err := setter.SetStatus(params.SetStatus{[]params.EntityStatusArgs{{
+		Tag:    unit.Tag().String(),
+		Status: status.Executing.String(),
+	}}})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(result.Results, gc.HasLen, 1)
+	c.Assert(result.Results[0].Error, gc.IsNil)
+
+	agentStatus, err := unit.Agent().Status()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(agentStatus.Status, gc.Equals, status.Executing)
+}
+
 type serviceStatusSetterSuite struct {
 	statusBaseSuite
 	setter *common.ServiceStatusSetter
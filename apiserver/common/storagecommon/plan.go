@@ -0,0 +1,173 @@
+// Copyright 2016 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package storagecommon
+
+import (
+	"fmt"
+
+	"github.com/juju/errors"
+	"gopkg.in/juju/names.v2"
+
+	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/instance"
+	"github.com/juju/juju/state"
+	"github.com/juju/juju/storage"
+	"github.com/juju/juju/storage/poolmanager"
+)
+
+// PlanBackend provides the state required to plan the provisioning
+// of volumes and filesystems.
+type PlanBackend interface {
+	Volume(names.VolumeTag) (state.Volume, error)
+	VolumeAttachments(names.VolumeTag) ([]state.VolumeAttachment, error)
+	Filesystem(names.FilesystemTag) (state.Filesystem, error)
+	FilesystemAttachments(names.FilesystemTag) ([]state.FilesystemAttachment, error)
+	MachineInstanceId(names.MachineTag) (instance.Id, error)
+}
+
+// VolumePlan returns the plan for provisioning the volume with the
+// given tag, describing the operations that the storage provisioner
+// would perform, without performing them. A volume with exactly one
+// pending attachment is attached as it is created, as the storage
+// provisioner does; any other attachments wait for the volume to be
+// created.
+func VolumePlan(
+	st PlanBackend,
+	tag names.VolumeTag,
+	poolManager poolmanager.PoolManager,
+	registry storage.ProviderRegistry,
+) (params.StoragePlan, error) {
+	v, err := st.Volume(tag)
+	if err != nil {
+		return params.StoragePlan{}, errors.Trace(err)
+	}
+	plan := params.StoragePlan{Tag: tag.String()}
+	if volumeParams, ok := v.Params(); ok {
+		plan.Create = true
+		plan.Pool = volumeParams.Pool
+		plan.Size = volumeParams.Size
+	} else {
+		info, err := v.Info()
+		if err != nil {
+			return params.StoragePlan{}, errors.Trace(err)
+		}
+		plan.Pool = info.Pool
+		plan.Size = info.Size
+	}
+	providerType, _, err := StoragePoolConfig(plan.Pool, poolManager, registry)
+	if err != nil {
+		return params.StoragePlan{}, errors.Trace(err)
+	}
+	plan.Provider = string(providerType)
+
+	attachments, err := st.VolumeAttachments(tag)
+	if err != nil {
+		return params.StoragePlan{}, errors.Trace(err)
+	}
+	withCreate := plan.Create && len(attachments) == 1
+	for _, a := range attachments {
+		attachmentParams, ok := a.Params()
+		if !ok {
+			// Already attached.
+			continue
+		}
+		attachmentPlan, err := attachmentPlan(st, a.Machine(), attachmentParams.ReadOnly)
+		if err != nil {
+			return params.StoragePlan{}, errors.Trace(err)
+		}
+		if withCreate {
+			attachmentPlan.WithCreate = true
+			plan.Waiting = attachmentPlan.Waiting
+		} else if plan.Create && attachmentPlan.Waiting == "" {
+			attachmentPlan.Waiting = "volume to be created"
+		}
+		plan.Attachments = append(plan.Attachments, attachmentPlan)
+	}
+	return plan, nil
+}
+
+// FilesystemPlan returns the plan for provisioning the filesystem with
+// the given tag, describing the operations that the storage provisioner
+// would perform, without performing them. Attachments of a filesystem
+// wait for the filesystem to be created.
+func FilesystemPlan(
+	st PlanBackend,
+	tag names.FilesystemTag,
+	poolManager poolmanager.PoolManager,
+	registry storage.ProviderRegistry,
+) (params.StoragePlan, error) {
+	f, err := st.Filesystem(tag)
+	if err != nil {
+		return params.StoragePlan{}, errors.Trace(err)
+	}
+	plan := params.StoragePlan{Tag: tag.String()}
+	if filesystemParams, ok := f.Params(); ok {
+		plan.Create = true
+		plan.Pool = filesystemParams.Pool
+		plan.Size = filesystemParams.Size
+	} else {
+		info, err := f.Info()
+		if err != nil {
+			return params.StoragePlan{}, errors.Trace(err)
+		}
+		plan.Pool = info.Pool
+		plan.Size = info.Size
+	}
+	providerType, _, err := StoragePoolConfig(plan.Pool, poolManager, registry)
+	if err != nil {
+		return params.StoragePlan{}, errors.Trace(err)
+	}
+	plan.Provider = string(providerType)
+
+	volumeTag, err := f.Volume()
+	if err == nil {
+		// The filesystem is created on the volume once the
+		// volume is attached to the machine.
+		plan.VolumeTag = volumeTag.String()
+		if plan.Create {
+			plan.Waiting = fmt.Sprintf("volume %s to be attached", volumeTag.Id())
+		}
+	} else if errors.Cause(err) != state.ErrNoBackingVolume {
+		return params.StoragePlan{}, errors.Trace(err)
+	}
+
+	attachments, err := st.FilesystemAttachments(tag)
+	if err != nil {
+		return params.StoragePlan{}, errors.Trace(err)
+	}
+	for _, a := range attachments {
+		attachmentParams, ok := a.Params()
+		if !ok {
+			// Already attached.
+			continue
+		}
+		attachmentPlan, err := attachmentPlan(st, a.Machine(), attachmentParams.ReadOnly)
+		if err != nil {
+			return params.StoragePlan{}, errors.Trace(err)
+		}
+		if plan.Create && attachmentPlan.Waiting == "" {
+			attachmentPlan.Waiting = "filesystem to be created"
+		}
+		plan.Attachments = append(plan.Attachments, attachmentPlan)
+	}
+	return plan, nil
+}
+
+// attachmentPlan returns the plan for attaching storage to the given
+// machine, which waits for the machine to be provisioned if necessary.
+func attachmentPlan(st PlanBackend, machineTag names.MachineTag, readOnly bool) (params.StorageAttachmentPlan, error) {
+	plan := params.StorageAttachmentPlan{
+		MachineTag: machineTag.String(),
+		ReadOnly:   readOnly,
+	}
+	instanceId, err := st.MachineInstanceId(machineTag)
+	if errors.IsNotProvisioned(err) {
+		plan.Waiting = fmt.Sprintf("machine %s to be provisioned", machineTag.Id())
+	} else if err != nil {
+		return params.StorageAttachmentPlan{}, errors.Trace(err)
+	} else {
+		plan.InstanceId = string(instanceId)
+	}
+	return plan, nil
+}
@@ -122,6 +122,13 @@ func VolumeToState(v params.Volume) (names.VolumeTag, state.VolumeInfo, error) {
 	}, nil
 }
 
+// VolumeDeleteOnTermination reports whether or not the volume should be
+// deleted when the specified machine's instance is terminated. This is
+// the case when the volume's lifecycle is bound to the machine.
+func VolumeDeleteOnTermination(v state.Volume, machine names.MachineTag) bool {
+	return v.LifeBinding() == machine
+}
+
 // VolumeFromState converts a state.Volume to params.Volume.
 func VolumeFromState(v state.Volume) (params.Volume, error) {
 	info, err := v.Info()
@@ -20,7 +20,10 @@ import (
 	coretools "github.com/juju/juju/tools"
 )
 
-var envtoolsFindTools = envtools.FindTools
+var (
+	envtoolsFindTools                 = envtools.FindTools
+	envtoolsFindControllerMirrorTools = envtools.FindControllerMirrorTools
+)
 
 // ToolsURLGetter is an interface providing the ToolsURL method.
 type ToolsURLGetter interface {
@@ -29,6 +32,16 @@ type ToolsURLGetter interface {
 	ToolsURLs(v version.Binary) ([]string, error)
 }
 
+// ToolsMirrorURLGetter is an interface providing the ToolsMirrorURLs
+// method. If a ToolsFinder's ToolsURLGetter also implements it, then
+// agent binaries for models whose environs use the controller's tools
+// mirror are looked up in the mirrors it returns.
+type ToolsMirrorURLGetter interface {
+	// ToolsMirrorURLs returns the URLs of the controller's
+	// tools mirrors.
+	ToolsMirrorURLs() ([]string, error)
+}
+
 // APIHostPortsGetter is an interface providing the APIHostPorts method.
 type APIHostPortsGetter interface {
 	// APIHostPorst returns the HostPorts for each API server.
@@ -260,9 +273,7 @@ func (f *ToolsFinder) findMatchingTools(args params.FindToolsParams) (coretools.
 	filter := toolsFilter(args)
 	cfg := env.Config()
 	stream := envtools.PreferredStream(&args.Number, cfg.Development(), cfg.AgentStream())
-	simplestreamsList, err := envtoolsFindTools(
-		env, args.MajorVersion, args.MinorVersion, stream, filter,
-	)
+	simplestreamsList, err := f.findSimplestreamsTools(env, args, stream, filter)
 	if len(storageList) == 0 && err != nil {
 		return nil, err
 	}
@@ -281,6 +292,27 @@ func (f *ToolsFinder) findMatchingTools(args params.FindToolsParams) (coretools.
 	return list, nil
 }
 
+// findSimplestreamsTools searches simplestreams for tools matching the
+// given parameters, consulting the controller's tools mirrors if the
+// ToolsURLGetter provides them.
+func (f *ToolsFinder) findSimplestreamsTools(
+	env environs.Environ, args params.FindToolsParams, stream string, filter coretools.Filter,
+) (coretools.List, error) {
+	mirrorURLGetter, ok := f.urlGetter.(ToolsMirrorURLGetter)
+	if !ok {
+		return envtoolsFindTools(
+			env, args.MajorVersion, args.MinorVersion, stream, filter,
+		)
+	}
+	mirrorURLs, err := mirrorURLGetter.ToolsMirrorURLs()
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	return envtoolsFindControllerMirrorTools(
+		env, mirrorURLs, args.MajorVersion, args.MinorVersion, stream, filter,
+	)
+}
+
 // matchingStorageTools returns a coretools.List, with an entry for each
 // metadata entry in the tools storage that matches the given parameters.
 func (f *ToolsFinder) matchingStorageTools(args params.FindToolsParams) (coretools.List, error) {
@@ -293,17 +325,32 @@ func (f *ToolsFinder) matchingStorageTools(args params.FindToolsParams) (coretoo
 	if err != nil {
 		return nil, err
 	}
-	list := make(coretools.List, len(allMetadata))
-	for i, m := range allMetadata {
+	// Unless a specific version is requested, only the tools
+	// published in the model's agent stream are offered, so that
+	// tools staged in a less stable stream are not picked up by
+	// models that have not opted in to that stream.
+	var stream string
+	if args.Number == version.Zero {
+		cfg, err := f.configGetter.ModelConfig()
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		stream = envtools.PreferredStream(&args.Number, cfg.Development(), cfg.AgentStream())
+	}
+	list := make(coretools.List, 0, len(allMetadata))
+	for _, m := range allMetadata {
+		if stream != "" && !toolsStreamIncludes(stream, m.Stream) {
+			continue
+		}
 		vers, err := version.ParseBinary(m.Version)
 		if err != nil {
 			return nil, errors.Annotatef(err, "unexpectedly bad version %q in tools storage", m.Version)
 		}
-		list[i] = &coretools.Tools{
+		list = append(list, &coretools.Tools{
 			Version: vers,
 			Size:    m.Size,
 			SHA256:  m.SHA256,
-		}
+		})
 	}
 	list, err = list.Match(toolsFilter(args))
 	if err != nil {
@@ -325,6 +372,33 @@ func (f *ToolsFinder) matchingStorageTools(args params.FindToolsParams) (coretoo
 	return matching, nil
 }
 
+// toolsStreamOrder orders the tools streams from the most stable
+// to the least stable.
+var toolsStreamOrder = map[string]int{
+	envtools.ReleasedStream: 0,
+	envtools.ProposedStream: 1,
+	envtools.TestingStream:  2,
+	envtools.DevelStream:    3,
+}
+
+// toolsStreamIncludes reports whether models using the preferred
+// stream may be offered tools published in the given stream. Less
+// stable streams include the tools published in more stable ones,
+// e.g. the proposed stream includes the released tools. Tools stored
+// without a stream, such as those cached from simplestreams, are
+// included in every stream.
+func toolsStreamIncludes(preferred, stream string) bool {
+	if stream == "" || stream == preferred {
+		return true
+	}
+	preferredOrder, ok := toolsStreamOrder[preferred]
+	if !ok {
+		return stream == envtools.ReleasedStream
+	}
+	streamOrder, ok := toolsStreamOrder[stream]
+	return ok && streamOrder <= preferredOrder
+}
+
 func toolsFilter(args params.FindToolsParams) coretools.Filter {
 	return coretools.Filter{
 		Number: args.Number,
@@ -336,12 +410,20 @@ func toolsFilter(args params.FindToolsParams) coretools.Filter {
 type toolsURLGetter struct {
 	modelUUID          string
 	apiHostPortsGetter APIHostPortsGetter
+	signer             *ToolsURLSigner
 }
 
 // NewToolsURLGetter creates a new ToolsURLGetter that
 // returns tools URLs pointing at an API server.
 func NewToolsURLGetter(modelUUID string, a APIHostPortsGetter) *toolsURLGetter {
-	return &toolsURLGetter{modelUUID, a}
+	return &toolsURLGetter{modelUUID, a, nil}
+}
+
+// NewSignedToolsURLGetter creates a new ToolsURLGetter that
+// returns tools URLs pointing at an API server, signed by
+// the given ToolsURLSigner.
+func NewSignedToolsURLGetter(modelUUID string, a APIHostPortsGetter, signer *ToolsURLSigner) *toolsURLGetter {
+	return &toolsURLGetter{modelUUID, a, signer}
 }
 
 func (t *toolsURLGetter) ToolsURLs(v version.Binary) ([]string, error) {
@@ -356,11 +438,32 @@ func (t *toolsURLGetter) ToolsURLs(v version.Binary) ([]string, error) {
 	for _, addr := range addrs {
 		serverRoot := fmt.Sprintf("https://%s/model/%s", addr, t.modelUUID)
 		url := ToolsURL(serverRoot, v)
+		if t.signer != nil {
+			url, err = t.signer.SignURL(url, t.modelUUID, v)
+			if err != nil {
+				return nil, errors.Trace(err)
+			}
+		}
 		urls = append(urls, url)
 	}
 	return urls, nil
 }
 
+// ToolsMirrorURLs is part of the ToolsMirrorURLGetter interface. The
+// mirrors are those of the controller model, which hold the agent
+// binaries cached by the controller.
+func (t *toolsURLGetter) ToolsMirrorURLs() ([]string, error) {
+	addrs, err := apiAddresses(t.apiHostPortsGetter)
+	if err != nil {
+		return nil, err
+	}
+	urls := make([]string, len(addrs))
+	for i, addr := range addrs {
+		urls[i] = envtools.ControllerMirrorURL(addr)
+	}
+	return urls, nil
+}
+
 // ToolsURL returns a tools URL pointing the API server
 // specified by the "serverRoot".
 func ToolsURL(serverRoot string, v version.Binary) string {
@@ -205,6 +205,96 @@ func (s *toolsSuite) TestFindTools(c *gc.C) {
 	})
 }
 
+func (s *toolsSuite) TestFindToolsControllerMirror(c *gc.C) {
+	s.PatchValue(common.EnvtoolsFindTools, func(e environs.Environ, major, minor int, stream string, filter coretools.Filter) (coretools.List, error) {
+		c.Fatalf("unexpected call to FindTools")
+		return nil, nil
+	})
+	s.PatchValue(common.EnvtoolsFindControllerMirrorTools, func(e environs.Environ, mirrorURLs []string, major, minor int, stream string, filter coretools.Filter) (coretools.List, error) {
+		c.Assert(mirrorURLs, jc.DeepEquals, []string{"https://0.1.2.3:1234/tools-mirror"})
+		c.Assert(major, gc.Equals, 123)
+		c.Assert(minor, gc.Equals, 456)
+		return coretools.List{{
+			Version: version.MustParseBinary("123.456.1-win81-alpha"),
+		}}, nil
+	})
+	toolsFinder := common.NewToolsFinder(
+		stateenvirons.EnvironConfigGetter{s.State}, &mockToolsStorage{},
+		common.NewToolsURLGetter("my-uuid", mockAPIHostPortsGetter{
+			hostPorts: [][]network.HostPort{
+				network.NewHostPorts(1234, "0.1.2.3"),
+			},
+		}),
+	)
+	result, err := toolsFinder.FindTools(params.FindToolsParams{
+		MajorVersion: 123,
+		MinorVersion: 456,
+		Series:       "win81",
+		Arch:         "alpha",
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(result.Error, gc.IsNil)
+	c.Check(result.List, jc.DeepEquals, coretools.List{{
+		Version: version.MustParseBinary("123.456.1-win81-alpha"),
+		URL:     "https://0.1.2.3:1234/model/my-uuid/tools/123.456.1-win81-alpha",
+	}})
+}
+
+func (s *toolsSuite) TestFindToolsStreams(c *gc.C) {
+	storageMetadata := []binarystorage.Metadata{
+		{Version: "123.456.0-win81-alpha", Stream: "released"},
+		{Version: "123.456.1-win81-alpha", Stream: "proposed"},
+		{Version: "123.456.2-win81-alpha", Stream: "devel"},
+		{Version: "123.456.3-win81-alpha"},
+	}
+	s.PatchValue(common.EnvtoolsFindTools, func(e environs.Environ, major, minor int, stream string, filter coretools.Filter) (coretools.List, error) {
+		return nil, errors.NotFoundf("tools")
+	})
+	toolsFinder := common.NewToolsFinder(
+		stateenvirons.EnvironConfigGetter{s.State}, &mockToolsStorage{metadata: storageMetadata}, sprintfURLGetter("tools:%s"),
+	)
+	findVersions := func() []string {
+		result, err := toolsFinder.FindTools(params.FindToolsParams{
+			MajorVersion: 123,
+			MinorVersion: -1,
+		})
+		c.Assert(err, jc.ErrorIsNil)
+		c.Assert(result.Error, gc.IsNil)
+		var versions []string
+		for _, tools := range result.List {
+			versions = append(versions, tools.Version.String())
+		}
+		return versions
+	}
+
+	// Tools stored without a stream are offered in every stream.
+	c.Assert(findVersions(), jc.DeepEquals, []string{
+		"123.456.0-win81-alpha",
+		"123.456.3-win81-alpha",
+	})
+
+	err := s.State.UpdateModelConfig(map[string]interface{}{"agent-stream": "proposed"}, nil, nil)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(findVersions(), jc.DeepEquals, []string{
+		"123.456.0-win81-alpha",
+		"123.456.1-win81-alpha",
+		"123.456.3-win81-alpha",
+	})
+
+	// A specific version is found regardless of its stream.
+	result, err := toolsFinder.FindTools(params.FindToolsParams{
+		Number:       version.MustParse("123.456.2"),
+		MajorVersion: -1,
+		MinorVersion: -1,
+		Series:       "win81",
+		Arch:         "alpha",
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(result.Error, gc.IsNil)
+	c.Assert(result.List, gc.HasLen, 1)
+	c.Assert(result.List[0].Version.String(), gc.Equals, "123.456.2-win81-alpha")
+}
+
 func (s *toolsSuite) TestFindToolsNotFound(c *gc.C) {
 	s.PatchValue(common.EnvtoolsFindTools, func(e environs.Environ, major, minor int, stream string, filter coretools.Filter) (list coretools.List, err error) {
 		return nil, errors.NotFoundf("tools")
@@ -317,6 +407,17 @@ func (s *toolsSuite) TestToolsURLGetter(c *gc.C) {
 	})
 }
 
+func (s *toolsSuite) TestToolsURLGetterToolsMirrorURLs(c *gc.C) {
+	g := common.NewToolsURLGetter("my-uuid", mockAPIHostPortsGetter{
+		hostPorts: [][]network.HostPort{
+			network.NewHostPorts(1234, "0.1.2.3"),
+		},
+	})
+	urls, err := g.ToolsMirrorURLs()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(urls, jc.DeepEquals, []string{"https://0.1.2.3:1234/tools-mirror"})
+}
+
 type sprintfURLGetter string
 
 func (s sprintfURLGetter) ToolsURLs(v version.Binary) ([]string, error) {
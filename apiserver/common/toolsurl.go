@@ -0,0 +1,124 @@
+// Copyright 2016 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package common
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/juju/errors"
+	"github.com/juju/utils/clock"
+	"github.com/juju/version"
+
+	"github.com/juju/juju/state"
+)
+
+const (
+	// toolsURLExpiresParam and toolsURLSignatureParam are the
+	// names of the query parameters of a signed tools URL.
+	toolsURLExpiresParam   = "expires"
+	toolsURLSignatureParam = "signature"
+
+	// toolsURLKeyPurpose is used to derive the tools URL signing
+	// key from the controller's shared secret, so the secret
+	// itself is never used directly.
+	toolsURLKeyPurpose = "juju-tools-url"
+)
+
+// ToolsURLSigner mints and verifies signed tools URLs. A signed URL
+// allows its holder to download the tools with a specific version
+// until the URL expires, without presenting any credentials. This
+// means that provisioning user-data can embed a tools URL rather
+// than long-lived agent credentials.
+type ToolsURLSigner struct {
+	key    []byte
+	expiry time.Duration
+	clock  clock.Clock
+}
+
+// NewToolsURLSigner returns a ToolsURLSigner whose signing key is
+// derived from the given secret, which must be shared by all of the
+// controller's API servers. URLs signed by the signer remain valid
+// for the given duration after they are minted.
+func NewToolsURLSigner(secret string, expiry time.Duration, clock clock.Clock) (*ToolsURLSigner, error) {
+	if secret == "" {
+		return nil, errors.NotValidf("empty secret")
+	}
+	if expiry <= 0 {
+		return nil, errors.NotValidf("tools URL expiry %s", expiry)
+	}
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(toolsURLKeyPurpose))
+	return &ToolsURLSigner{key: mac.Sum(nil), expiry: expiry, clock: clock}, nil
+}
+
+// NewStateToolsURLSigner returns a ToolsURLSigner using the shared
+// secret and the tools URL expiry of the controller of the given
+// state.
+func NewStateToolsURLSigner(st *state.State, clock clock.Clock) (*ToolsURLSigner, error) {
+	servingInfo, err := st.StateServingInfo()
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	controllerConfig, err := st.ControllerConfig()
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	return NewToolsURLSigner(servingInfo.SharedSecret, controllerConfig.ToolsURLExpiry(), clock)
+}
+
+// SignURL returns the given tools URL, for tools with the specified
+// version in the specified model, with an expiry time and signature
+// added to its query.
+func (s *ToolsURLSigner) SignURL(toolsURL, modelUUID string, v version.Binary) (string, error) {
+	u, err := url.Parse(toolsURL)
+	if err != nil {
+		return "", errors.Trace(err)
+	}
+	expires := s.clock.Now().Add(s.expiry).Unix()
+	query := u.Query()
+	query.Set(toolsURLExpiresParam, strconv.FormatInt(expires, 10))
+	query.Set(toolsURLSignatureParam, s.signature(modelUUID, v, expires))
+	u.RawQuery = query.Encode()
+	return u.String(), nil
+}
+
+// IsSigned reports whether the given tools URL query carries a
+// signature.
+func IsSigned(query url.Values) bool {
+	return query.Get(toolsURLSignatureParam) != ""
+}
+
+// Verify checks that the given tools URL query carries a valid,
+// unexpired signature for tools with the specified version in the
+// specified model.
+func (s *ToolsURLSigner) Verify(modelUUID string, v version.Binary, query url.Values) error {
+	expires, err := strconv.ParseInt(query.Get(toolsURLExpiresParam), 10, 64)
+	if err != nil {
+		return errors.NotValidf("tools URL expiry %q", query.Get(toolsURLExpiresParam))
+	}
+	signature, err := hex.DecodeString(query.Get(toolsURLSignatureParam))
+	if err != nil {
+		return errors.NotValidf("tools URL signature")
+	}
+	expect, _ := hex.DecodeString(s.signature(modelUUID, v, expires))
+	if !hmac.Equal(signature, expect) {
+		return errors.NotValidf("tools URL signature")
+	}
+	if !s.clock.Now().Before(time.Unix(expires, 0)) {
+		return errors.Errorf("tools URL expired")
+	}
+	return nil
+}
+
+func (s *ToolsURLSigner) signature(modelUUID string, v version.Binary, expires int64) string {
+	mac := hmac.New(sha256.New, s.key)
+	fmt.Fprintf(mac, "%s\n%s\n%d", modelUUID, v, expires)
+	return hex.EncodeToString(mac.Sum(nil))
+}
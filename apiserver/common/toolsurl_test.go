@@ -0,0 +1,126 @@
+// Copyright 2016 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package common_test
+
+import (
+	"net/url"
+	"time"
+
+	"github.com/juju/errors"
+	jujutesting "github.com/juju/testing"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/apiserver/common"
+	"github.com/juju/juju/network"
+)
+
+type toolsURLSignerSuite struct {
+	jujutesting.IsolationSuite
+	clock  *jujutesting.Clock
+	signer *common.ToolsURLSigner
+}
+
+var _ = gc.Suite(&toolsURLSignerSuite{})
+
+func (s *toolsURLSignerSuite) SetUpTest(c *gc.C) {
+	s.IsolationSuite.SetUpTest(c)
+	s.clock = jujutesting.NewClock(time.Unix(1000, 0))
+	signer, err := common.NewToolsURLSigner("sekrit", time.Hour, s.clock)
+	c.Assert(err, jc.ErrorIsNil)
+	s.signer = signer
+}
+
+func (s *toolsURLSignerSuite) signedQuery(c *gc.C) url.Values {
+	signed, err := s.signer.SignURL("https://0.1.2.3:1234/tools/"+current.String()+"?foo=bar", "my-uuid", current)
+	c.Assert(err, jc.ErrorIsNil)
+	u, err := url.Parse(signed)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(u.Path, gc.Equals, "/tools/"+current.String())
+	return u.Query()
+}
+
+func (s *toolsURLSignerSuite) TestNewToolsURLSignerEmptySecret(c *gc.C) {
+	_, err := common.NewToolsURLSigner("", time.Hour, s.clock)
+	c.Assert(err, jc.Satisfies, errors.IsNotValid)
+}
+
+func (s *toolsURLSignerSuite) TestNewToolsURLSignerInvalidExpiry(c *gc.C) {
+	_, err := common.NewToolsURLSigner("sekrit", 0, s.clock)
+	c.Assert(err, gc.ErrorMatches, "tools URL expiry 0s not valid")
+}
+
+func (s *toolsURLSignerSuite) TestSignURLExpiry(c *gc.C) {
+	signer, err := common.NewToolsURLSigner("sekrit", 2*time.Hour, s.clock)
+	c.Assert(err, jc.ErrorIsNil)
+	signed, err := signer.SignURL("https://0.1.2.3:1234/tools/"+current.String(), "my-uuid", current)
+	c.Assert(err, jc.ErrorIsNil)
+	u, err := url.Parse(signed)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(u.Query().Get("expires"), gc.Equals, "8200")
+}
+
+func (s *toolsURLSignerSuite) TestSignURL(c *gc.C) {
+	query := s.signedQuery(c)
+	c.Assert(query.Get("foo"), gc.Equals, "bar")
+	c.Assert(query.Get("expires"), gc.Equals, "4600")
+	c.Assert(query.Get("signature"), gc.Matches, "[0-9a-f]{64}")
+	c.Assert(common.IsSigned(query), jc.IsTrue)
+	c.Assert(common.IsSigned(url.Values{}), jc.IsFalse)
+}
+
+func (s *toolsURLSignerSuite) TestVerify(c *gc.C) {
+	query := s.signedQuery(c)
+	s.clock.Advance(time.Hour - time.Second)
+	c.Assert(s.signer.Verify("my-uuid", current, query), jc.ErrorIsNil)
+}
+
+func (s *toolsURLSignerSuite) TestVerifyExpired(c *gc.C) {
+	query := s.signedQuery(c)
+	s.clock.Advance(time.Hour)
+	c.Assert(s.signer.Verify("my-uuid", current, query), gc.ErrorMatches, "tools URL expired")
+}
+
+func (s *toolsURLSignerSuite) TestVerifyWrongVersion(c *gc.C) {
+	query := s.signedQuery(c)
+	other := current
+	other.Number.Patch++
+	c.Assert(s.signer.Verify("my-uuid", other, query), gc.ErrorMatches, "tools URL signature not valid")
+}
+
+func (s *toolsURLSignerSuite) TestVerifyWrongModel(c *gc.C) {
+	query := s.signedQuery(c)
+	c.Assert(s.signer.Verify("other-uuid", current, query), gc.ErrorMatches, "tools URL signature not valid")
+}
+
+func (s *toolsURLSignerSuite) TestVerifyTamperedExpiry(c *gc.C) {
+	query := s.signedQuery(c)
+	query.Set("expires", "999999")
+	c.Assert(s.signer.Verify("my-uuid", current, query), gc.ErrorMatches, "tools URL signature not valid")
+	query.Set("expires", "never")
+	c.Assert(s.signer.Verify("my-uuid", current, query), gc.ErrorMatches, `tools URL expiry "never" not valid`)
+}
+
+func (s *toolsURLSignerSuite) TestVerifyOtherSecret(c *gc.C) {
+	query := s.signedQuery(c)
+	other, err := common.NewToolsURLSigner("other", time.Hour, s.clock)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(other.Verify("my-uuid", current, query), gc.ErrorMatches, "tools URL signature not valid")
+}
+
+func (s *toolsURLSignerSuite) TestSignedToolsURLGetter(c *gc.C) {
+	g := common.NewSignedToolsURLGetter("my-uuid", mockAPIHostPortsGetter{
+		hostPorts: [][]network.HostPort{
+			network.NewHostPorts(1234, "0.1.2.3"),
+		},
+	}, s.signer)
+	urls, err := g.ToolsURLs(current)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(urls, gc.HasLen, 1)
+	u, err := url.Parse(urls[0])
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(u.Host, gc.Equals, "0.1.2.3:1234")
+	c.Assert(u.Path, gc.Equals, "/model/my-uuid/tools/"+current.String())
+	c.Assert(s.signer.Verify("my-uuid", current, u.Query()), jc.ErrorIsNil)
+}
@@ -0,0 +1,129 @@
+// Copyright 2016 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+// Package constraintsvalidator implements the API endpoint for
+// inspecting the constraints accepted by a model, and for validating
+// constraints before they are used.
+package constraintsvalidator
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/juju/errors"
+	"gopkg.in/juju/names.v2"
+
+	"github.com/juju/juju/apiserver/common"
+	"github.com/juju/juju/apiserver/facade"
+	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/constraints"
+	"github.com/juju/juju/permission"
+	"github.com/juju/juju/state"
+)
+
+func init() {
+	common.RegisterStandardFacade("ConstraintsValidator", 1, newFacade)
+}
+
+// Backend defines the State API used by the constraintsvalidator facade.
+type Backend interface {
+	ModelTag() names.ModelTag
+	ConstraintsValidator() (constraints.Validator, error)
+}
+
+// Facade implements the ConstraintsValidator API.
+type Facade struct {
+	backend    Backend
+	authorizer facade.Authorizer
+}
+
+func newFacade(st *state.State, _ facade.Resources, authorizer facade.Authorizer) (*Facade, error) {
+	return New(st, authorizer)
+}
+
+// New returns a new ConstraintsValidator API facade.
+func New(backend Backend, authorizer facade.Authorizer) (*Facade, error) {
+	if !authorizer.AuthClient() {
+		return nil, common.ErrPerm
+	}
+	return &Facade{
+		backend:    backend,
+		authorizer: authorizer,
+	}, nil
+}
+
+func (f *Facade) checkCanRead() error {
+	canRead, err := f.authorizer.HasPermission(permission.ReadAccess, f.backend.ModelTag())
+	if err != nil {
+		return errors.Trace(err)
+	}
+	if !canRead {
+		return common.ErrPerm
+	}
+	return nil
+}
+
+// Vocabulary returns the constraint values accepted by the model:
+// the allowed values for attributes such as instance-type and arch,
+// the attributes that may not be combined, and the attributes that
+// are ignored by the model's provider.
+func (f *Facade) Vocabulary() (params.ConstraintsVocabulary, error) {
+	var result params.ConstraintsVocabulary
+	if err := f.checkCanRead(); err != nil {
+		return result, err
+	}
+	validator, err := f.backend.ConstraintsValidator()
+	if err != nil {
+		return result, errors.Trace(err)
+	}
+	vocab := validator.Vocabulary()
+	if len(vocab) > 0 {
+		result.Vocabulary = make(map[string][]interface{})
+		for attr, values := range vocab {
+			// The validator does not preserve the order in which
+			// values were registered, so sort them for display.
+			sort.Sort(valuesByString(values))
+			result.Vocabulary[attr] = values
+		}
+	}
+	if conflicts := validator.Conflicts(); len(conflicts) > 0 {
+		result.Conflicts = conflicts
+	}
+	if unsupported := validator.Unsupported(); len(unsupported) > 0 {
+		result.Unsupported = unsupported
+	}
+	return result, nil
+}
+
+// ValidateConstraints validates each of the given constraints values
+// against the model's constraints vocabulary, returning any attributes
+// that are unsupported by the model's provider.
+func (f *Facade) ValidateConstraints(args params.ValidateConstraintsArgs) (params.ValidateConstraintsResults, error) {
+	result := params.ValidateConstraintsResults{
+		Results: make([]params.ValidateConstraintsResult, len(args.Constraints)),
+	}
+	if err := f.checkCanRead(); err != nil {
+		return result, err
+	}
+	validator, err := f.backend.ConstraintsValidator()
+	if err != nil {
+		return result, errors.Trace(err)
+	}
+	for i, cons := range args.Constraints {
+		unsupported, err := validator.Validate(cons)
+		result.Results[i].Unsupported = unsupported
+		if err != nil {
+			result.Results[i].Error = common.ServerError(err)
+		}
+	}
+	return result, nil
+}
+
+// valuesByString sorts vocabulary values by their string representation.
+type valuesByString []interface{}
+
+func (v valuesByString) Len() int      { return len(v) }
+func (v valuesByString) Swap(i, j int) { v[i], v[j] = v[j], v[i] }
+func (v valuesByString) Less(i, j int) bool {
+	return fmt.Sprint(v[i]) < fmt.Sprint(v[j])
+}
@@ -0,0 +1,127 @@
+// Copyright 2016 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package constraintsvalidator_test
+
+import (
+	"github.com/juju/errors"
+	jujutesting "github.com/juju/testing"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+	"gopkg.in/juju/names.v2"
+
+	"github.com/juju/juju/apiserver/common"
+	"github.com/juju/juju/apiserver/constraintsvalidator"
+	"github.com/juju/juju/apiserver/params"
+	apiservertesting "github.com/juju/juju/apiserver/testing"
+	"github.com/juju/juju/constraints"
+	coretesting "github.com/juju/juju/testing"
+)
+
+type constraintsValidatorSuite struct {
+	jujutesting.IsolationSuite
+	backend    *mockBackend
+	authorizer apiservertesting.FakeAuthorizer
+	facade     *constraintsvalidator.Facade
+}
+
+var _ = gc.Suite(&constraintsValidatorSuite{})
+
+func (s *constraintsValidatorSuite) SetUpTest(c *gc.C) {
+	s.IsolationSuite.SetUpTest(c)
+	validator := constraints.NewValidator()
+	validator.RegisterVocabulary(constraints.InstanceType, []string{"Standard_D2", "Standard_A1"})
+	validator.RegisterVocabulary(constraints.Arch, []string{"amd64"})
+	validator.RegisterConflicts([]string{constraints.InstanceType}, []string{constraints.Mem})
+	validator.RegisterUnsupported([]string{constraints.CpuPower})
+	s.backend = &mockBackend{validator: validator}
+	s.authorizer = apiservertesting.FakeAuthorizer{
+		Tag: names.NewUserTag("admin"),
+	}
+	var err error
+	s.facade, err = constraintsvalidator.New(s.backend, &s.authorizer)
+	c.Assert(err, jc.ErrorIsNil)
+}
+
+func (s *constraintsValidatorSuite) TestNewNotClient(c *gc.C) {
+	s.authorizer.Tag = names.NewMachineTag("0")
+	_, err := constraintsvalidator.New(s.backend, &s.authorizer)
+	c.Assert(err, gc.Equals, common.ErrPerm)
+}
+
+func (s *constraintsValidatorSuite) TestVocabulary(c *gc.C) {
+	result, err := s.facade.Vocabulary()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(result, jc.DeepEquals, params.ConstraintsVocabulary{
+		Vocabulary: map[string][]interface{}{
+			"arch":          {"amd64"},
+			"instance-type": {"Standard_A1", "Standard_D2"},
+		},
+		Conflicts: map[string][]string{
+			"instance-type": {"mem"},
+			"mem":           {"instance-type"},
+		},
+		Unsupported: []string{"cpu-power"},
+	})
+}
+
+func (s *constraintsValidatorSuite) TestVocabularyEmpty(c *gc.C) {
+	s.backend.validator = constraints.NewValidator()
+	result, err := s.facade.Vocabulary()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(result, jc.DeepEquals, params.ConstraintsVocabulary{})
+}
+
+func (s *constraintsValidatorSuite) TestVocabularyError(c *gc.C) {
+	s.backend.err = errors.New("boom")
+	_, err := s.facade.Vocabulary()
+	c.Assert(err, gc.ErrorMatches, "boom")
+}
+
+func (s *constraintsValidatorSuite) TestVocabularyPermissionDenied(c *gc.C) {
+	s.authorizer.Tag = names.NewUserTag("bob")
+	_, err := s.facade.Vocabulary()
+	c.Assert(err, gc.Equals, common.ErrPerm)
+}
+
+func (s *constraintsValidatorSuite) TestValidateConstraints(c *gc.C) {
+	result, err := s.facade.ValidateConstraints(params.ValidateConstraintsArgs{
+		Constraints: []constraints.Value{
+			constraints.MustParse("instance-type=Standard_A1"),
+			constraints.MustParse("instance-type=Standard_A9"),
+			constraints.MustParse("instance-type=Standard_A1 mem=2G"),
+			constraints.MustParse("arch=amd64 cpu-power=100"),
+		},
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(result.Results, gc.HasLen, 4)
+	c.Assert(result.Results[0], jc.DeepEquals, params.ValidateConstraintsResult{})
+	c.Assert(result.Results[1].Error, gc.ErrorMatches,
+		`invalid constraint value: instance-type=Standard_A9\nvalid values are: .*`)
+	c.Assert(result.Results[2].Error, gc.ErrorMatches,
+		`ambiguous constraints: "instance-type" overlaps with "mem"`)
+	c.Assert(result.Results[3], jc.DeepEquals, params.ValidateConstraintsResult{
+		Unsupported: []string{"cpu-power"},
+	})
+}
+
+func (s *constraintsValidatorSuite) TestValidateConstraintsPermissionDenied(c *gc.C) {
+	s.authorizer.Tag = names.NewUserTag("bob")
+	_, err := s.facade.ValidateConstraints(params.ValidateConstraintsArgs{
+		Constraints: []constraints.Value{{}},
+	})
+	c.Assert(err, gc.Equals, common.ErrPerm)
+}
+
+type mockBackend struct {
+	validator constraints.Validator
+	err       error
+}
+
+func (*mockBackend) ModelTag() names.ModelTag {
+	return coretesting.ModelTag
+}
+
+func (b *mockBackend) ConstraintsValidator() (constraints.Validator, error) {
+	return b.validator, b.err
+}
@@ -9,12 +9,15 @@ import (
 	"encoding/json"
 	"sort"
 
+	"github.com/dustin/go-humanize"
 	"github.com/juju/errors"
 	"github.com/juju/loggo"
 	"github.com/juju/txn"
+	"github.com/juju/utils/du"
 	"github.com/juju/utils/set"
 	"gopkg.in/juju/names.v2"
 	"gopkg.in/macaroon.v1"
+	"gopkg.in/mgo.v2/bson"
 
 	"github.com/juju/juju/api"
 	"github.com/juju/juju/api/migrationtarget"
@@ -23,16 +26,19 @@ import (
 	"github.com/juju/juju/apiserver/facade"
 	"github.com/juju/juju/apiserver/params"
 	coremigration "github.com/juju/juju/core/migration"
+	"github.com/juju/juju/environs"
+	"github.com/juju/juju/instance"
 	"github.com/juju/juju/migration"
 	"github.com/juju/juju/permission"
 	"github.com/juju/juju/state"
 	"github.com/juju/juju/state/stateenvirons"
+	statestorage "github.com/juju/juju/state/storage"
 )
 
 var logger = loggo.GetLogger("juju.apiserver.controller")
 
 func init() {
-	common.RegisterStandardFacade("Controller", 3, NewControllerAPI)
+	common.RegisterStandardFacade("Controller", 4, NewControllerAPI)
 }
 
 // Controller defines the methods on the controller API end point.
@@ -46,9 +52,17 @@ type Controller interface {
 	ListBlockedModels() (params.ModelBlockInfoList, error)
 	RemoveBlocks(args params.RemoveBlocksArgs) error
 	WatchAllModels() (params.AllWatcherId, error)
+	ResumeWatchAllModels(params.ResumeAllWatcher) (params.AllWatcherId, error)
 	ModelStatus(params.Entities) (params.ModelStatusResults, error)
 	InitiateMigration(params.InitiateMigrationArgs) (params.InitiateMigrationResults, error)
 	ModifyControllerAccess(params.ModifyControllerAccessRequest) (params.ErrorResults, error)
+	MigrateBlobBackend(params.MigrateBlobBackendArgs) error
+	SetBucketPolicies(params.BucketPolicies) (params.ErrorResults, error)
+	BucketPolicies() (params.BucketPolicies, error)
+	RotateStorageCredentials(params.Entities) (params.ErrorResults, error)
+	CheckIntegrity() (params.IntegrityReports, error)
+	RepairIntegrity(params.RepairIntegrityArgs) (params.RepairIntegrityResults, error)
+	ControllerHealth() (params.ControllerHealth, error)
 }
 
 // ControllerAPI implements the environment manager interface and is
@@ -297,9 +311,24 @@ func (c *ControllerAPI) WatchAllModels() (params.AllWatcherId, error) {
 		return params.AllWatcherId{}, errors.Trace(err)
 	}
 	w := c.state.WatchAllModels()
-	return params.AllWatcherId{
-		AllWatcherId: c.resources.Register(w),
-	}, nil
+	return common.RegisterAllWatcher(
+		c.resources, w, c.authorizer.GetAuthTag(),
+		c.state.ControllerTag().String(),
+	)
+}
+
+// ResumeWatchAllModels resumes an AllModelWatcher session started by
+// WatchAllModels on an earlier connection. The deltas after the given
+// sequence number are replayed by the resumed watcher before any new
+// deltas.
+func (c *ControllerAPI) ResumeWatchAllModels(args params.ResumeAllWatcher) (params.AllWatcherId, error) {
+	if err := c.checkHasAdmin(); err != nil {
+		return params.AllWatcherId{}, errors.Trace(err)
+	}
+	return common.ResumeAllWatcher(
+		c.resources, args, c.authorizer.GetAuthTag(),
+		c.state.ControllerTag().String(),
+	)
 }
 
 type orderedBlockInfo []params.ModelBlockInfo
@@ -520,6 +549,245 @@ func (c *ControllerAPI) modelStatus(tag string) (params.ModelStatus, error) {
 	}, nil
 }
 
+// CheckIntegrity checks the referential integrity of the documents in
+// each model in the controller, including the unit and relation counts
+// recorded for applications, and reports the problems found along with
+// the transactions, if any, that would repair them.
+func (c *ControllerAPI) CheckIntegrity() (params.IntegrityReports, error) {
+	results := params.IntegrityReports{}
+	if err := c.checkHasAdmin(); err != nil {
+		return results, errors.Trace(err)
+	}
+	models, err := c.state.AllModels()
+	if err != nil {
+		return results, errors.Trace(err)
+	}
+	results.Results = make([]params.IntegrityReport, len(models))
+	for i, model := range models {
+		result := &results.Results[i]
+		result.ModelTag = model.ModelTag().String()
+		problems, err := c.checkModelIntegrity(model.ModelTag())
+		if err != nil {
+			result.Error = common.ServerError(err)
+			continue
+		}
+		result.Problems = problems
+	}
+	return results, nil
+}
+
+func (c *ControllerAPI) checkModelIntegrity(modelTag names.ModelTag) ([]params.IntegrityProblem, error) {
+	st, err := c.state.ForModel(modelTag)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	defer st.Close()
+	problems, err := st.CheckIntegrity()
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	var results []params.IntegrityProblem
+	for _, problem := range problems {
+		repair, err := integrityRepairOps(problem)
+		if err != nil {
+			return nil, errors.Annotatef(err, "reporting integrity problem %q", problem.ID)
+		}
+		results = append(results, params.IntegrityProblem{
+			ID:          problem.ID,
+			Kind:        problem.Kind,
+			Collection:  problem.Collection,
+			DocID:       problem.DocID,
+			Description: problem.Description,
+			Repair:      repair,
+		})
+	}
+	return results, nil
+}
+
+// integrityRepairOps returns the operations of the transaction that
+// would repair the given problem, with their documents converted to
+// maps so that they serialize naturally.
+func integrityRepairOps(problem state.IntegrityProblem) ([]params.IntegrityRepairOp, error) {
+	var results []params.IntegrityRepairOp
+	for _, op := range problem.Repair {
+		result := params.IntegrityRepairOp{
+			Collection: op.C,
+			Id:         op.Id,
+			Remove:     op.Remove,
+		}
+		var err error
+		if result.Assert, err = repairOpDocument(op.Assert); err != nil {
+			return nil, errors.Trace(err)
+		}
+		if result.Insert, err = repairOpDocument(op.Insert); err != nil {
+			return nil, errors.Trace(err)
+		}
+		if result.Update, err = repairOpDocument(op.Update); err != nil {
+			return nil, errors.Trace(err)
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+func repairOpDocument(doc interface{}) (interface{}, error) {
+	switch doc.(type) {
+	case nil, string:
+		// Assertions such as txn.DocExists are strings.
+		return doc, nil
+	}
+	data, err := bson.Marshal(doc)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	var result bson.M
+	if err := bson.Unmarshal(data, &result); err != nil {
+		return nil, errors.Trace(err)
+	}
+	return result, nil
+}
+
+// RepairIntegrity applies the suggested repairs of the specified
+// integrity problems, as reported by CheckIntegrity. The problems
+// are checked again before they are repaired, and only the repairs
+// computed by the controller are applied.
+func (c *ControllerAPI) RepairIntegrity(args params.RepairIntegrityArgs) (params.RepairIntegrityResults, error) {
+	results := params.RepairIntegrityResults{
+		Results: make([]params.RepairIntegrityResult, len(args.Args)),
+	}
+	if err := c.checkHasAdmin(); err != nil {
+		return results, errors.Trace(err)
+	}
+	for i, arg := range args.Args {
+		result := &results.Results[i]
+		modelTag, err := names.ParseModelTag(arg.ModelTag)
+		if err != nil {
+			result.Error = common.ServerError(err)
+			continue
+		}
+		errs, err := c.repairModelIntegrity(modelTag, arg.ProblemIDs)
+		if err != nil {
+			result.Error = common.ServerError(err)
+			continue
+		}
+		result.Results = make([]params.ErrorResult, len(errs))
+		for j, err := range errs {
+			result.Results[j].Error = common.ServerError(err)
+		}
+	}
+	return results, nil
+}
+
+func (c *ControllerAPI) repairModelIntegrity(modelTag names.ModelTag, ids []string) ([]error, error) {
+	st, err := c.state.ForModel(modelTag)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	defer st.Close()
+	return st.RepairIntegrity(ids)
+}
+
+// MigrateBlobBackend starts moving the content of the controller's
+// blobs to the specified backend. New blobs are stored in the backend
+// immediately; existing blobs are moved in the background by the
+// controller, which remains usable while they are moved.
+func (c *ControllerAPI) MigrateBlobBackend(args params.MigrateBlobBackendArgs) error {
+	if err := c.checkHasAdmin(); err != nil {
+		return errors.Trace(err)
+	}
+	return errors.Trace(c.state.StartBlobBackendMigration(args.Backend))
+}
+
+// SetBucketPolicies sets the lifecycle policies of the specified blob
+// buckets. A policy with no limits removes the bucket's policy.
+func (c *ControllerAPI) SetBucketPolicies(args params.BucketPolicies) (params.ErrorResults, error) {
+	result := params.ErrorResults{
+		Results: make([]params.ErrorResult, len(args.Policies)),
+	}
+	if err := c.checkHasAdmin(); err != nil {
+		return result, errors.Trace(err)
+	}
+	for i, arg := range args.Policies {
+		err := c.state.SetBucketPolicy(statestorage.Bucket(arg.Bucket), statestorage.BucketPolicy{
+			MaxAge:      arg.MaxAge,
+			MaxVersions: arg.MaxVersions,
+		})
+		result.Results[i].Error = common.ServerError(err)
+	}
+	return result, nil
+}
+
+// BucketPolicies returns the lifecycle policies of the blob buckets
+// that have them.
+func (c *ControllerAPI) BucketPolicies() (params.BucketPolicies, error) {
+	var result params.BucketPolicies
+	if err := c.checkHasAdmin(); err != nil {
+		return result, errors.Trace(err)
+	}
+	policies, err := c.state.BucketPolicies()
+	if err != nil {
+		return result, errors.Trace(err)
+	}
+	for _, bucket := range statestorage.Buckets {
+		policy, ok := policies[bucket]
+		if !ok {
+			continue
+		}
+		result.Policies = append(result.Policies, params.BucketPolicy{
+			Bucket:      string(bucket),
+			MaxAge:      policy.MaxAge,
+			MaxVersions: policy.MaxVersions,
+		})
+	}
+	return result, nil
+}
+
+// RotateStorageCredentials replaces the credentials that the specified
+// models' environs use to access their storage, recording the new
+// credentials in the models' config. The models' providers must
+// support storage credential rotation.
+func (c *ControllerAPI) RotateStorageCredentials(args params.Entities) (params.ErrorResults, error) {
+	result := params.ErrorResults{
+		Results: make([]params.ErrorResult, len(args.Entities)),
+	}
+	if err := c.checkHasAdmin(); err != nil {
+		return result, errors.Trace(err)
+	}
+	for i, arg := range args.Entities {
+		modelTag, err := names.ParseModelTag(arg.Tag)
+		if err != nil {
+			result.Results[i].Error = common.ServerError(err)
+			continue
+		}
+		err = c.rotateModelStorageCredentials(modelTag)
+		result.Results[i].Error = common.ServerError(err)
+	}
+	return result, nil
+}
+
+func (c *ControllerAPI) rotateModelStorageCredentials(modelTag names.ModelTag) error {
+	st, err := c.state.ForModel(modelTag)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	defer st.Close()
+	env, err := newEnviron(st)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	rotator, ok := env.(environs.StorageCredentialRotator)
+	if !ok {
+		return errors.NotSupportedf("rotating storage credentials for model %q", modelTag.Id())
+	}
+	if err := rotator.RotateStorageCredentials(func(attrs map[string]interface{}) error {
+		return st.UpdateModelConfig(attrs, nil, nil)
+	}); err != nil {
+		return errors.Annotatef(err, "rotating storage credentials for model %q", modelTag.Id())
+	}
+	logger.Infof("rotated storage credentials for model %q", modelTag.Id())
+	return nil
+}
+
 // ModifyControllerAccess changes the model access granted to users.
 func (c *ControllerAPI) ModifyControllerAccess(args params.ModifyControllerAccessRequest) (params.ErrorResults, error) {
 	result := params.ErrorResults{
@@ -558,6 +826,71 @@ func (c *ControllerAPI) ModifyControllerAccess(args params.ModifyControllerAcces
 	return result, nil
 }
 
+// ControllerHealth reports the free disk space of the API server
+// handling the request, and whether the controller is able to query
+// the provider for its instances.
+func (c *ControllerAPI) ControllerHealth() (params.ControllerHealth, error) {
+	var result params.ControllerHealth
+	if err := c.checkHasAdmin(); err != nil {
+		return result, errors.Trace(err)
+	}
+
+	free, err := c.freeDiskSpace()
+	if err != nil {
+		result.DiskError = common.ServerError(err)
+	} else {
+		result.FreeDiskSpace = free
+	}
+
+	ids, err := c.controllerInstances()
+	if err != nil {
+		result.ProviderError = common.ServerError(err)
+	}
+	for _, id := range ids {
+		result.ControllerInstances = append(result.ControllerInstances, string(id))
+	}
+	return result, nil
+}
+
+// freeDiskSpace returns the free space, in MiB, on the disk holding
+// the API server's data directory.
+func (c *ControllerAPI) freeDiskSpace() (uint64, error) {
+	dataDir, ok := c.resources.Get("dataDir").(common.StringResource)
+	if !ok {
+		return 0, errors.NotFoundf("data directory")
+	}
+	return diskFree(dataDir.String()) / humanize.MiByte, nil
+}
+
+// controllerInstances queries the provider for the IDs of the
+// controller's instances.
+func (c *ControllerAPI) controllerInstances() ([]instance.Id, error) {
+	model, err := c.state.ControllerModel()
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	st, err := c.state.ForModel(model.ModelTag())
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	defer st.Close()
+	env, err := newEnviron(st)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	ids, err := env.ControllerInstances(c.state.ControllerUUID())
+	if err != nil {
+		return nil, errors.Annotate(err, "querying provider for controller instances")
+	}
+	return ids, nil
+}
+
+var diskFree = func(dir string) uint64 {
+	return du.NewDiskUsage(dir).Free()
+}
+
+var newEnviron = stateenvirons.GetNewEnvironFunc(environs.New)
+
 var runMigrationPrechecks = func(st *state.State, targetInfo coremigration.TargetInfo) error {
 	// Check model and source controller.
 	if err := migration.SourcePrecheck(migration.PrecheckShim(st)); err != nil {
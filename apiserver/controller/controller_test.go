@@ -15,6 +15,7 @@ import (
 	gc "gopkg.in/check.v1"
 	"gopkg.in/juju/names.v2"
 	"gopkg.in/macaroon.v1"
+	"gopkg.in/mgo.v2/bson"
 
 	"github.com/juju/juju/apiserver"
 	"github.com/juju/juju/apiserver/common"
@@ -883,3 +884,301 @@ func (s *controllerSuite) TestGetControllerAccessPermissions(c *gc.C) {
 		Message: "permission denied", Code: "unauthorized access",
 	})
 }
+
+func (s *controllerSuite) setUpModelWithIntegrityProblem(c *gc.C) *state.State {
+	st := s.Factory.MakeModel(c, &factory.ModelParams{Name: "test"})
+	f := factory.NewFactory(st)
+	app := f.MakeApplication(c, &factory.ApplicationParams{Name: "wordpress"})
+	f.MakeUnit(c, &factory.UnitParams{Application: app})
+
+	// Simulate the unit's machine being removed
+	// without the unit being unassigned.
+	err := st.MongoSession().DB("juju").C("units").UpdateId(
+		st.ModelUUID()+":wordpress/0",
+		bson.D{{"$set", bson.D{{"machineid", "42"}}}},
+	)
+	c.Assert(err, jc.ErrorIsNil)
+	return st
+}
+
+func (s *controllerSuite) TestCheckIntegrity(c *gc.C) {
+	st := s.setUpModelWithIntegrityProblem(c)
+	defer st.Close()
+
+	results, err := s.controller.CheckIntegrity()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(results.Results, jc.SameContents, []params.IntegrityReport{{
+		ModelTag: s.State.ModelTag().String(),
+	}, {
+		ModelTag: st.ModelTag().String(),
+		Problems: []params.IntegrityProblem{{
+			ID:          "dangling-reference:units/wordpress/0:machineid",
+			Kind:        "dangling-reference",
+			Collection:  "units",
+			DocID:       "wordpress/0",
+			Description: `unit "wordpress/0" is assigned to missing machine "42"`,
+			Repair: []params.IntegrityRepairOp{{
+				Collection: "units",
+				Id:         st.ModelUUID() + ":wordpress/0",
+				Assert:     bson.M{"machineid": "42"},
+				Update:     bson.M{"$set": bson.M{"machineid": ""}},
+			}},
+		}},
+	}})
+
+	// Nothing was repaired.
+	problems, err := st.CheckIntegrity()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(problems, gc.HasLen, 1)
+}
+
+func (s *controllerSuite) TestCheckIntegrityRequiresSuperuser(c *gc.C) {
+	user := s.Factory.MakeUser(c, &factory.UserParams{NoModelUser: true})
+	endpoint, err := controller.NewControllerAPI(s.State, s.resources, apiservertesting.FakeAuthorizer{
+		Tag: user.Tag(),
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	_, err = endpoint.CheckIntegrity()
+	c.Assert(err, gc.ErrorMatches, "permission denied")
+}
+
+func (s *controllerSuite) TestRepairIntegrity(c *gc.C) {
+	st := s.setUpModelWithIntegrityProblem(c)
+	defer st.Close()
+
+	results, err := s.controller.RepairIntegrity(params.RepairIntegrityArgs{
+		Args: []params.RepairIntegrityArg{{
+			ModelTag: st.ModelTag().String(),
+			ProblemIDs: []string{
+				"dangling-reference:units/wordpress/0:machineid",
+				"bogus",
+			},
+		}, {
+			ModelTag: "machine-0",
+		}},
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(results, jc.DeepEquals, params.RepairIntegrityResults{
+		Results: []params.RepairIntegrityResult{{
+			Results: []params.ErrorResult{{}, {
+				Error: &params.Error{
+					Message: `integrity problem "bogus" not found`,
+					Code:    params.CodeNotFound,
+				},
+			}},
+		}, {
+			Error: &params.Error{Message: `"machine-0" is not a valid model tag`},
+		}},
+	})
+
+	problems, err := st.CheckIntegrity()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(problems, gc.HasLen, 0)
+}
+
+func (s *controllerSuite) TestRepairIntegrityRequiresSuperuser(c *gc.C) {
+	user := s.Factory.MakeUser(c, &factory.UserParams{NoModelUser: true})
+	endpoint, err := controller.NewControllerAPI(s.State, s.resources, apiservertesting.FakeAuthorizer{
+		Tag: user.Tag(),
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	_, err = endpoint.RepairIntegrity(params.RepairIntegrityArgs{})
+	c.Assert(err, gc.ErrorMatches, "permission denied")
+}
+
+type storageCredentialRotatorEnviron struct {
+	environs.Environ
+	attrs map[string]interface{}
+	err   error
+}
+
+func (e *storageCredentialRotatorEnviron) RotateStorageCredentials(record func(map[string]interface{}) error) error {
+	if e.err != nil {
+		return e.err
+	}
+	return record(e.attrs)
+}
+
+func (s *controllerSuite) TestRotateStorageCredentials(c *gc.C) {
+	controller.SetNewEnviron(s, func(st *state.State) (environs.Environ, error) {
+		c.Check(st.ModelTag(), gc.Equals, s.State.ModelTag())
+		return &storageCredentialRotatorEnviron{
+			attrs: map[string]interface{}{"storage-key": "new"},
+		}, nil
+	})
+	results, err := s.controller.RotateStorageCredentials(params.Entities{
+		Entities: []params.Entity{
+			{Tag: s.State.ModelTag().String()},
+			{Tag: "machine-0"},
+		},
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(results, jc.DeepEquals, params.ErrorResults{
+		Results: []params.ErrorResult{
+			{},
+			{Error: &params.Error{Message: `"machine-0" is not a valid model tag`}},
+		},
+	})
+
+	cfg, err := s.State.ModelConfig()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(cfg.AllAttrs()["storage-key"], gc.Equals, "new")
+}
+
+func (s *controllerSuite) TestRotateStorageCredentialsError(c *gc.C) {
+	controller.SetNewEnviron(s, func(st *state.State) (environs.Environ, error) {
+		return &storageCredentialRotatorEnviron{err: errors.New("blargh")}, nil
+	})
+	results, err := s.controller.RotateStorageCredentials(params.Entities{
+		Entities: []params.Entity{{Tag: s.State.ModelTag().String()}},
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(results.OneError(), gc.ErrorMatches, `rotating storage credentials for model ".*": blargh`)
+}
+
+func (s *controllerSuite) TestRotateStorageCredentialsNotSupported(c *gc.C) {
+	controller.SetNewEnviron(s, func(st *state.State) (environs.Environ, error) {
+		return &struct{ environs.Environ }{}, nil
+	})
+	results, err := s.controller.RotateStorageCredentials(params.Entities{
+		Entities: []params.Entity{{Tag: s.State.ModelTag().String()}},
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(results.OneError(), gc.ErrorMatches, `rotating storage credentials for model ".*" not supported`)
+	c.Assert(results.OneError(), jc.Satisfies, params.IsCodeNotSupported)
+}
+
+func (s *controllerSuite) TestRotateStorageCredentialsRequiresSuperuser(c *gc.C) {
+	user := s.Factory.MakeUser(c, &factory.UserParams{NoModelUser: true})
+	endpoint, err := controller.NewControllerAPI(s.State, s.resources, apiservertesting.FakeAuthorizer{
+		Tag: user.Tag(),
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	_, err = endpoint.RotateStorageCredentials(params.Entities{
+		Entities: []params.Entity{{Tag: s.State.ModelTag().String()}},
+	})
+	c.Assert(err, gc.ErrorMatches, "permission denied")
+}
+
+type controllerInstancesEnviron struct {
+	environs.Environ
+	ids []instance.Id
+	err error
+}
+
+func (e *controllerInstancesEnviron) ControllerInstances(controllerUUID string) ([]instance.Id, error) {
+	return e.ids, e.err
+}
+
+func (s *controllerSuite) TestControllerHealth(c *gc.C) {
+	err := s.resources.RegisterNamed("dataDir", common.StringResource("/var/lib/juju"))
+	c.Assert(err, jc.ErrorIsNil)
+	controller.SetDiskFree(s, func(dir string) uint64 {
+		c.Check(dir, gc.Equals, "/var/lib/juju")
+		return 2048 * 1024 * 1024
+	})
+	controller.SetNewEnviron(s, func(st *state.State) (environs.Environ, error) {
+		c.Check(st.ModelTag(), gc.Equals, s.State.ModelTag())
+		return &controllerInstancesEnviron{ids: []instance.Id{"inst-0"}}, nil
+	})
+	result, err := s.controller.ControllerHealth()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(result, jc.DeepEquals, params.ControllerHealth{
+		FreeDiskSpace:       2048,
+		ControllerInstances: []string{"inst-0"},
+	})
+}
+
+func (s *controllerSuite) TestControllerHealthErrors(c *gc.C) {
+	controller.SetNewEnviron(s, func(st *state.State) (environs.Environ, error) {
+		return &controllerInstancesEnviron{err: errors.New("unauthorized")}, nil
+	})
+	result, err := s.controller.ControllerHealth()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(result.DiskError, gc.ErrorMatches, "data directory not found")
+	c.Assert(result.ProviderError, gc.ErrorMatches, "querying provider for controller instances: unauthorized")
+}
+
+func (s *controllerSuite) TestControllerHealthRequiresSuperuser(c *gc.C) {
+	user := s.Factory.MakeUser(c, &factory.UserParams{NoModelUser: true})
+	endpoint, err := controller.NewControllerAPI(s.State, s.resources, apiservertesting.FakeAuthorizer{
+		Tag: user.Tag(),
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	_, err = endpoint.ControllerHealth()
+	c.Assert(err, gc.ErrorMatches, "permission denied")
+}
+
+func (s *controllerSuite) TestMigrateBlobBackend(c *gc.C) {
+	dir := c.MkDir()
+	err := s.controller.MigrateBlobBackend(params.MigrateBlobBackendArgs{
+		Backend: map[string]string{"type": "filesystem", "directory": dir},
+	})
+	c.Assert(err, jc.ErrorIsNil)
+
+	// The new backend is current immediately; the blobs
+	// are moved from the old one in the background.
+	current, fallback, err := s.State.BlobBackend()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(current.String(), gc.Equals, "filesystem:"+dir)
+	c.Assert(fallback, gc.NotNil)
+	c.Assert(fallback.String(), gc.Equals, "gridfs")
+}
+
+func (s *controllerSuite) TestMigrateBlobBackendInvalid(c *gc.C) {
+	err := s.controller.MigrateBlobBackend(params.MigrateBlobBackendArgs{
+		Backend: map[string]string{"type": "filesystem"},
+	})
+	c.Assert(err, gc.ErrorMatches, `filesystem blob backend without "directory" not valid`)
+}
+
+func (s *controllerSuite) TestMigrateBlobBackendRequiresSuperuser(c *gc.C) {
+	user := s.Factory.MakeUser(c, &factory.UserParams{NoModelUser: true})
+	endpoint, err := controller.NewControllerAPI(s.State, s.resources, apiservertesting.FakeAuthorizer{
+		Tag: user.Tag(),
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	err = endpoint.MigrateBlobBackend(params.MigrateBlobBackendArgs{
+		Backend: map[string]string{"type": "gridfs"},
+	})
+	c.Assert(err, gc.ErrorMatches, "permission denied")
+}
+
+func (s *controllerSuite) TestBucketPolicies(c *gc.C) {
+	result, err := s.controller.SetBucketPolicies(params.BucketPolicies{
+		Policies: []params.BucketPolicy{
+			{Bucket: "backups", MaxAge: 24 * time.Hour, MaxVersions: 7},
+			{Bucket: "charms", MaxVersions: 3},
+			{Bucket: "resources", MaxVersions: 3},
+		},
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(result.Results, gc.HasLen, 3)
+	c.Assert(result.Results[0].Error, gc.IsNil)
+	c.Assert(result.Results[1].Error, gc.IsNil)
+	c.Assert(result.Results[2].Error, gc.ErrorMatches, "max versions for resources bucket not supported")
+
+	policies, err := s.controller.BucketPolicies()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(policies, jc.DeepEquals, params.BucketPolicies{
+		Policies: []params.BucketPolicy{
+			{Bucket: "backups", MaxAge: 24 * time.Hour, MaxVersions: 7},
+			{Bucket: "charms", MaxVersions: 3},
+		},
+	})
+}
+
+func (s *controllerSuite) TestSetBucketPoliciesRequiresSuperuser(c *gc.C) {
+	user := s.Factory.MakeUser(c, &factory.UserParams{NoModelUser: true})
+	endpoint, err := controller.NewControllerAPI(s.State, s.resources, apiservertesting.FakeAuthorizer{
+		Tag: user.Tag(),
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	_, err = endpoint.SetBucketPolicies(params.BucketPolicies{
+		Policies: []params.BucketPolicy{{Bucket: "charms", MaxVersions: 3}},
+	})
+	c.Assert(err, gc.ErrorMatches, "permission denied")
+	policies, err := s.State.BucketPolicies()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(policies, gc.HasLen, 0)
+}
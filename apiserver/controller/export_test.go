@@ -5,6 +5,7 @@ package controller
 
 import (
 	"github.com/juju/juju/core/migration"
+	"github.com/juju/juju/environs"
 	"github.com/juju/juju/state"
 )
 
@@ -17,3 +18,11 @@ func SetPrecheckResult(p patcher, err error) {
 		return err
 	})
 }
+
+func SetNewEnviron(p patcher, f func(*state.State) (environs.Environ, error)) {
+	p.PatchValue(&newEnviron, f)
+}
+
+func SetDiskFree(p patcher, f func(string) uint64) {
+	p.PatchValue(&diskFree, f)
+}
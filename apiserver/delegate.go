@@ -0,0 +1,162 @@
+// Copyright 2016 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package apiserver
+
+import (
+	"sync"
+
+	"github.com/juju/errors"
+	"github.com/juju/utils/clock"
+	"gopkg.in/juju/names.v2"
+
+	"github.com/juju/juju/apiserver/common"
+	"github.com/juju/juju/apiserver/facade"
+	"github.com/juju/juju/permission"
+	"github.com/juju/juju/state"
+)
+
+// unitDelegator authorizes a machine agent to make API requests on
+// behalf of the units assigned to its machine, so that the units'
+// agents can share the machine agent's connection rather than each
+// opening their own.
+type unitDelegator struct {
+	st        *state.State
+	clock     clock.Clock
+	resources *common.Resources
+	machine   facade.Authorizer
+
+	// mu guards presence, which holds the resource ids of the
+	// workers maintaining the agent presence of the units on
+	// whose behalf requests have been made, keyed by unit tag.
+	mu       sync.Mutex
+	presence map[names.UnitTag]string
+}
+
+// delegate returns an authorizer for a request made on behalf of the
+// unit with the given tag. It is called for every such request, so
+// that a unit removed from the machine, or unassigned from it, loses
+// the machine agent's authority as soon as it leaves. The unit's agent
+// presence is maintained from the first request made on its behalf for
+// as long as the connection lives and the unit remains on the machine,
+// as it would be if the unit agent had logged in itself.
+func (d *unitDelegator) delegate(tag string) (facade.Authorizer, error) {
+	unitTag, err := names.ParseUnitTag(tag)
+	if err != nil {
+		return nil, common.ErrPerm
+	}
+	unit, err := d.assignedUnit(unitTag)
+	if err == common.ErrPerm {
+		d.stopPresence(unitTag)
+		return nil, err
+	} else if err != nil {
+		return nil, errors.Trace(err)
+	}
+	if err := d.ensurePresence(unit); err != nil {
+		return nil, errors.Trace(err)
+	}
+	return &unitDelegateAuthorizer{
+		Authorizer: d.machine,
+		st:         d.st,
+		unit:       unitTag,
+	}, nil
+}
+
+// assignedUnit returns the unit with the given tag, or common.ErrPerm
+// if it does not exist or is not assigned to the delegating machine.
+func (d *unitDelegator) assignedUnit(tag names.UnitTag) (*state.Unit, error) {
+	unit, err := d.st.Unit(tag.Id())
+	if errors.IsNotFound(err) {
+		return nil, common.ErrPerm
+	} else if err != nil {
+		return nil, errors.Trace(err)
+	}
+	machineId, err := unit.AssignedMachineId()
+	if errors.IsNotAssigned(err) || errors.IsNotFound(err) {
+		return nil, common.ErrPerm
+	} else if err != nil {
+		return nil, errors.Trace(err)
+	}
+	if names.NewMachineTag(machineId) != d.machine.GetAuthTag() {
+		return nil, common.ErrPerm
+	}
+	return unit, nil
+}
+
+// ensurePresence starts maintaining the agent presence of the given
+// unit, if it is not already being maintained.
+func (d *unitDelegator) ensurePresence(unit *state.Unit) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if _, ok := d.presence[unit.UnitTag()]; ok {
+		return nil
+	}
+	id, err := startPresence(d.clock, d.resources, unit)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	if d.presence == nil {
+		d.presence = make(map[names.UnitTag]string)
+	}
+	d.presence[unit.UnitTag()] = id
+	return nil
+}
+
+// stopPresence stops maintaining the agent presence of the unit with
+// the given tag, if it is being maintained.
+func (d *unitDelegator) stopPresence(tag names.UnitTag) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	id, ok := d.presence[tag]
+	if !ok {
+		return
+	}
+	delete(d.presence, tag)
+	if err := d.resources.Stop(id); err != nil {
+		logger.Warningf("cannot stop presence of %s: %v", tag, err)
+	}
+}
+
+// unitDelegateAuthorizer is a facade.Authorizer for requests made by
+// a machine agent on behalf of a unit. The requests are authorized as
+// though the unit agent had made them.
+type unitDelegateAuthorizer struct {
+	facade.Authorizer
+	st   *state.State
+	unit names.UnitTag
+}
+
+// GetAuthTag is part of the facade.Authorizer interface.
+func (a *unitDelegateAuthorizer) GetAuthTag() names.Tag {
+	return a.unit
+}
+
+// AuthModelManager is part of the facade.Authorizer interface.
+func (a *unitDelegateAuthorizer) AuthModelManager() bool {
+	return false
+}
+
+// AuthMachineAgent is part of the facade.Authorizer interface.
+func (a *unitDelegateAuthorizer) AuthMachineAgent() bool {
+	return false
+}
+
+// AuthUnitAgent is part of the facade.Authorizer interface.
+func (a *unitDelegateAuthorizer) AuthUnitAgent() bool {
+	return true
+}
+
+// AuthOwner is part of the facade.Authorizer interface.
+func (a *unitDelegateAuthorizer) AuthOwner(tag names.Tag) bool {
+	return tag == a.unit
+}
+
+// AuthClient is part of the facade.Authorizer interface.
+func (a *unitDelegateAuthorizer) AuthClient() bool {
+	return false
+}
+
+// HasPermission is part of the facade.Authorizer interface.
+func (a *unitDelegateAuthorizer) HasPermission(operation permission.Access, target names.Tag) (bool, error) {
+	return common.HasPermission(a.st.UserAccess, a.unit, operation, target)
+}
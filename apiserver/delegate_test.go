@@ -0,0 +1,104 @@
+// Copyright 2016 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package apiserver_test
+
+import (
+	"github.com/juju/errors"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/api"
+	apiagent "github.com/juju/juju/api/agent"
+	"github.com/juju/juju/apiserver/params"
+	jujutesting "github.com/juju/juju/juju/testing"
+	"github.com/juju/juju/testing/factory"
+)
+
+type delegateSuite struct {
+	jujutesting.JujuConnSuite
+}
+
+var _ = gc.Suite(&delegateSuite{})
+
+func (s *delegateSuite) TestMachineDelegatesToUnit(c *gc.C) {
+	st, machine := s.OpenAPIAsNewMachine(c)
+	unit := s.Factory.MakeUnit(c, &factory.UnitParams{Machine: machine})
+	alive, err := unit.AgentPresence()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(alive, jc.IsFalse)
+
+	caller, err := api.NewDelegatedAPICaller(st, unit.UnitTag())
+	c.Assert(err, jc.ErrorIsNil)
+	agentState := apiagent.NewState(caller)
+
+	// Requests are authorized as though the unit agent made them.
+	entity, err := agentState.Entity(unit.UnitTag())
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(entity.Tag(), gc.Equals, unit.Tag().String())
+	_, err = agentState.Entity(machine.Tag())
+	c.Assert(err, gc.ErrorMatches, "permission denied")
+	c.Assert(err, jc.Satisfies, params.IsCodeUnauthorized)
+
+	// The unit agent's presence is maintained by the connection.
+	s.State.StartSync()
+	alive, err = unit.AgentPresence()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(alive, jc.IsTrue)
+
+	// The machine agent's own requests are unaffected.
+	_, err = apiagent.NewState(st).Entity(machine.Tag())
+	c.Assert(err, jc.ErrorIsNil)
+}
+
+func (s *delegateSuite) TestMachineCannotDelegateToOtherMachinesUnit(c *gc.C) {
+	st, _ := s.OpenAPIAsNewMachine(c)
+	unit := s.Factory.MakeUnit(c, nil)
+
+	caller, err := api.NewDelegatedAPICaller(st, unit.UnitTag())
+	c.Assert(err, jc.ErrorIsNil)
+	_, err = apiagent.NewState(caller).Entity(unit.UnitTag())
+	c.Assert(err, gc.ErrorMatches, "permission denied")
+	c.Assert(err, jc.Satisfies, params.IsCodeUnauthorized)
+}
+
+func (s *delegateSuite) TestMachineCannotDelegateToMachine(c *gc.C) {
+	st, _ := s.OpenAPIAsNewMachine(c)
+	other := s.Factory.MakeMachine(c, nil)
+
+	caller, err := api.NewDelegatedAPICaller(st, other.Tag())
+	c.Assert(err, jc.ErrorIsNil)
+	_, err = apiagent.NewState(caller).Entity(other.Tag())
+	c.Assert(err, gc.ErrorMatches, "permission denied")
+	c.Assert(err, jc.Satisfies, params.IsCodeUnauthorized)
+}
+
+func (s *delegateSuite) TestDelegationEndsWhenUnitLeavesMachine(c *gc.C) {
+	st, machine := s.OpenAPIAsNewMachine(c)
+	unit := s.Factory.MakeUnit(c, &factory.UnitParams{Machine: machine})
+
+	caller, err := api.NewDelegatedAPICaller(st, unit.UnitTag())
+	c.Assert(err, jc.ErrorIsNil)
+	agentState := apiagent.NewState(caller)
+	_, err = agentState.Entity(unit.UnitTag())
+	c.Assert(err, jc.ErrorIsNil)
+
+	// Authority over the unit is checked for every request, so
+	// the connection loses it as soon as the unit is unassigned.
+	err = unit.UnassignFromMachine()
+	c.Assert(err, jc.ErrorIsNil)
+	_, err = agentState.Entity(unit.UnitTag())
+	c.Assert(err, gc.ErrorMatches, "permission denied")
+	c.Assert(err, jc.Satisfies, params.IsCodeUnauthorized)
+}
+
+func (s *delegateSuite) TestUserCannotDelegate(c *gc.C) {
+	machine := s.Factory.MakeMachine(c, nil)
+	unit := s.Factory.MakeUnit(c, &factory.UnitParams{Machine: machine})
+
+	// The server does not report that users may delegate,
+	// so no delegated requests are made.
+	caller, err := api.NewDelegatedAPICaller(s.APIState, unit.UnitTag())
+	c.Assert(err, jc.Satisfies, errors.IsNotSupported)
+	c.Assert(caller, gc.IsNil)
+}
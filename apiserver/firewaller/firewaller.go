@@ -19,7 +19,7 @@ import (
 
 func init() {
 	// Version 0 is no longer supported.
-	common.RegisterStandardFacade("Firewaller", 3, NewFirewallerAPI)
+	common.RegisterStandardFacade("Firewaller", 4, NewFirewallerAPI)
 }
 
 // FirewallerAPI provides access to the Firewaller API facade.
@@ -118,6 +118,20 @@ func NewFirewallerAPI(
 // WatchOpenedPorts returns a new StringsWatcher for each given
 // environment tag.
 func (f *FirewallerAPI) WatchOpenedPorts(args params.Entities) (params.StringsWatchResults, error) {
+	return f.watchModels(args, f.watchOneEnvironOpenedPorts)
+}
+
+// WatchExposureIntents returns a new StringsWatcher for each given
+// model tag, notifying of changes to the exposure intents of the
+// model's applications. The watchers report application names.
+func (f *FirewallerAPI) WatchExposureIntents(args params.Entities) (params.StringsWatchResults, error) {
+	return f.watchModels(args, f.watchOneModelExposureIntents)
+}
+
+func (f *FirewallerAPI) watchModels(
+	args params.Entities,
+	watchOne func(names.Tag) (string, []string, error),
+) (params.StringsWatchResults, error) {
 	result := params.StringsWatchResults{
 		Results: make([]params.StringsWatchResult, len(args.Entities)),
 	}
@@ -138,7 +152,7 @@ func (f *FirewallerAPI) WatchOpenedPorts(args params.Entities) (params.StringsWa
 			result.Results[i].Error = common.ServerError(common.ErrPerm)
 			continue
 		}
-		watcherId, initial, err := f.watchOneEnvironOpenedPorts(tag)
+		watcherId, initial, err := watchOne(tag)
 		if err != nil {
 			result.Results[i].Error = common.ServerError(err)
 			continue
@@ -160,6 +174,17 @@ func (f *FirewallerAPI) watchOneEnvironOpenedPorts(tag names.Tag) (string, []str
 	return "", nil, watcher.EnsureErr(watch)
 }
 
+func (f *FirewallerAPI) watchOneModelExposureIntents(tag names.Tag) (string, []string, error) {
+	// NOTE: tag is ignored, as there is only one model in the
+	// state DB. Once this changes, change the code below accordingly.
+	watch := f.st.WatchExposureIntents()
+	// Consume the initial event and forward it to the result.
+	if changes, ok := <-watch.Changes(); ok {
+		return f.resources.Register(watch), changes, nil
+	}
+	return "", nil, watcher.EnsureErr(watch)
+}
+
 // GetMachinePorts returns the port ranges opened on a machine for the specified
 // subnet as a map mapping port ranges to the tags of the units that opened
 // them.
@@ -287,6 +312,53 @@ func (f *FirewallerAPI) GetExposed(args params.Entities) (params.BoolResults, er
 	return result, nil
 }
 
+// GetExposureIntents returns the exposure intent recorded for each
+// given application. Applications that have not been exposed since
+// exposure intents were introduced have none, and their results hold
+// a not-found error.
+func (f *FirewallerAPI) GetExposureIntents(args params.Entities) (params.ExposureIntentResults, error) {
+	result := params.ExposureIntentResults{
+		Results: make([]params.ExposureIntentResult, len(args.Entities)),
+	}
+	canAccess, err := f.accessService()
+	if err != nil {
+		return params.ExposureIntentResults{}, err
+	}
+	for i, entity := range args.Entities {
+		tag, err := names.ParseApplicationTag(entity.Tag)
+		if err != nil {
+			result.Results[i].Error = common.ServerError(common.ErrPerm)
+			continue
+		}
+		service, err := f.getService(canAccess, tag)
+		if err != nil {
+			result.Results[i].Error = common.ServerError(err)
+			continue
+		}
+		intent, err := service.ExposureIntent()
+		if err != nil {
+			result.Results[i].Error = common.ServerError(err)
+			continue
+		}
+		portRanges := make([]params.UnitPortRange, len(intent.PortRanges))
+		for j, portRange := range intent.PortRanges {
+			portRanges[j] = params.UnitPortRange{
+				UnitTag: names.NewUnitTag(portRange.UnitName).String(),
+				PortRange: params.PortRange{
+					FromPort: portRange.FromPort,
+					ToPort:   portRange.ToPort,
+					Protocol: portRange.Protocol,
+				},
+			}
+		}
+		result.Results[i].Result = &params.ExposureIntent{
+			Exposed:    intent.Exposed,
+			PortRanges: portRanges,
+		}
+	}
+	return result, nil
+}
+
 // GetAssignedMachine returns the assigned machine tag (if any) for
 // each given unit.
 func (f *FirewallerAPI) GetAssignedMachine(args params.Entities) (params.StringResults, error) {
@@ -138,6 +138,84 @@ func (s *firewallerSuite) TestWatchOpenedPorts(c *gc.C) {
 	wc.AssertNoChange()
 }
 
+func (s *firewallerSuite) TestWatchExposureIntents(c *gc.C) {
+	c.Assert(s.resources.Count(), gc.Equals, 0)
+
+	err := s.service.SetExposed()
+	c.Assert(err, jc.ErrorIsNil)
+
+	args := params.Entities{Entities: []params.Entity{
+		{Tag: s.State.ModelTag().String()},
+		{Tag: s.service.Tag().String()},
+	}}
+	result, err := s.firewaller.WatchExposureIntents(args)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(result, jc.DeepEquals, params.StringsWatchResults{
+		Results: []params.StringsWatchResult{
+			{Changes: []string{s.service.Name()}, StringsWatcherId: "1"},
+			{Error: apiservertesting.ErrUnauthorized},
+		},
+	})
+
+	// Verify the resource was registered and stop when done
+	c.Assert(s.resources.Count(), gc.Equals, 1)
+	resource := s.resources.Get("1")
+	defer statetesting.AssertStop(c, resource)
+
+	// Check that the Watch has consumed the initial event ("returned" in
+	// the Watch call)
+	wc := statetesting.NewStringsWatcherC(c, s.State, resource.(state.StringsWatcher))
+	wc.AssertNoChange()
+
+	err = s.service.ClearExposed()
+	c.Assert(err, jc.ErrorIsNil)
+	wc.AssertChange(s.service.Name())
+	wc.AssertNoChange()
+}
+
+func (s *firewallerSuite) TestGetExposureIntents(c *gc.C) {
+	err := s.units[2].OpenPorts("udp", 1111, 2222)
+	c.Assert(err, jc.ErrorIsNil)
+	err = s.service.SetExposed()
+	c.Assert(err, jc.ErrorIsNil)
+
+	args := params.Entities{Entities: []params.Entity{
+		{Tag: s.service.Tag().String()},
+		{Tag: s.units[0].Tag().String()},
+		{Tag: "application-bar"},
+	}}
+	result, err := s.firewaller.GetExposureIntents(args)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(result, jc.DeepEquals, params.ExposureIntentResults{
+		Results: []params.ExposureIntentResult{{
+			Result: &params.ExposureIntent{
+				Exposed: true,
+				PortRanges: []params.UnitPortRange{{
+					UnitTag:   s.units[2].Tag().String(),
+					PortRange: params.PortRange{FromPort: 1111, ToPort: 2222, Protocol: "udp"},
+				}},
+			},
+		}, {
+			Error: apiservertesting.ErrUnauthorized,
+		}, {
+			Error: apiservertesting.NotFoundError(`application "bar"`),
+		}},
+	})
+}
+
+func (s *firewallerSuite) TestGetExposureIntentsNotExposed(c *gc.C) {
+	args := params.Entities{Entities: []params.Entity{
+		{Tag: s.service.Tag().String()},
+	}}
+	result, err := s.firewaller.GetExposureIntents(args)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(result, jc.DeepEquals, params.ExposureIntentResults{
+		Results: []params.ExposureIntentResult{{
+			Error: apiservertesting.NotFoundError(`exposure intent for application "wordpress"`),
+		}},
+	})
+}
+
 func (s *firewallerSuite) TestGetMachinePorts(c *gc.C) {
 	s.openPorts(c)
 
@@ -8,6 +8,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"math"
 	"net/http"
 	"strings"
 
@@ -215,6 +216,10 @@ func sendStatusAndJSON(w http.ResponseWriter, statusCode int, response interface
 func sendError(w http.ResponseWriter, err error) {
 	err1, statusCode := common.ServerErrorAndStatus(err)
 	logger.Debugf("sending error: %d %v", statusCode, err1)
+	if err1.Info != nil && err1.Info.Retryable && err1.Info.RetryAfter > 0 {
+		seconds := math.Ceil(err1.Info.RetryAfter.Seconds())
+		w.Header().Set("Retry-After", fmt.Sprint(int64(seconds)))
+	}
 	sendStatusAndJSON(w, statusCode, &params.ErrorResult{
 		Error: err1,
 	})
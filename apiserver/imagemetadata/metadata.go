@@ -69,17 +69,12 @@ func NewAPI(
 }
 
 // List returns all found cloud image metadata that satisfy
-// given filter.
+// given filter, and that is either shared by all models or
+// specific to the model.
 // Returned list contains metadata ordered by priority.
 func (api *API) List(filter params.ImageMetadataFilter) (params.ListCloudImageMetadataResult, error) {
-	if api.authorizer.AuthClient() {
-		admin, err := api.authorizer.HasPermission(permission.SuperuserAccess, api.metadata.ControllerTag())
-		if err != nil {
-			return params.ListCloudImageMetadataResult{}, errors.Trace(err)
-		}
-		if !admin {
-			return params.ListCloudImageMetadataResult{}, common.ServerError(common.ErrPerm)
-		}
+	if _, err := api.checkModelAdmin(); err != nil {
+		return params.ListCloudImageMetadataResult{}, err
 	}
 
 	found, err := api.metadata.FindMetadata(cloudimagemetadata.MetadataFilter{
@@ -89,6 +84,7 @@ func (api *API) List(filter params.ImageMetadataFilter) (params.ListCloudImageMe
 		Stream:          filter.Stream,
 		VirtType:        filter.VirtType,
 		RootStorageType: filter.RootStorageType,
+		ModelUUID:       api.metadata.ModelTag().Id(),
 	})
 	if err != nil {
 		return params.ListCloudImageMetadataResult{}, common.ServerError(err)
@@ -137,26 +133,82 @@ func (api *API) Save(metadata params.MetadataSaveParams) (params.ErrorResults, e
 	return params.ErrorResults{Results: all}, nil
 }
 
+// SetModelImages stores given cloud image metadata as specific to the
+// model, so that it is used in preference to metadata shared by all
+// models when provisioning machines in the model.
+// It supports bulk calls.
+func (api *API) SetModelImages(metadata params.MetadataSaveParams) (params.ErrorResults, error) {
+	all := make([]params.ErrorResult, len(metadata.Metadata))
+	if _, err := api.checkModelAdmin(); err != nil {
+		return params.ErrorResults{Results: all}, err
+	}
+	if len(metadata.Metadata) == 0 {
+		return params.ErrorResults{Results: all}, nil
+	}
+	modelCfg, err := api.metadata.ModelConfig()
+	if err != nil {
+		return params.ErrorResults{}, errors.Annotatef(err, "getting model config")
+	}
+	modelUUID := api.metadata.ModelTag().Id()
+	for i, one := range metadata.Metadata {
+		md := api.parseMetadataListFromParams(one, modelCfg)
+		for j := range md {
+			md[j].ModelUUID = modelUUID
+		}
+		err := api.metadata.SaveMetadata(md)
+		all[i] = params.ErrorResult{Error: common.ServerError(err)}
+	}
+	return params.ErrorResults{Results: all}, nil
+}
+
 // Delete deletes cloud image metadata for given image ids.
+// A controller superuser deletes all metadata for the images;
+// a model admin deletes only the metadata specific to the model.
 // It supports bulk calls.
 func (api *API) Delete(images params.MetadataImageIds) (params.ErrorResults, error) {
 	all := make([]params.ErrorResult, len(images.Ids))
-	if api.authorizer.AuthClient() {
-		admin, err := api.authorizer.HasPermission(permission.SuperuserAccess, api.metadata.ControllerTag())
-		if err != nil {
-			return params.ErrorResults{Results: all}, errors.Trace(err)
-		}
-		if !admin {
-			return params.ErrorResults{Results: all}, common.ServerError(common.ErrPerm)
-		}
+	superuser, err := api.checkModelAdmin()
+	if err != nil {
+		return params.ErrorResults{Results: all}, err
 	}
+	modelUUID := api.metadata.ModelTag().Id()
 	for i, imageId := range images.Ids {
-		err := api.metadata.DeleteMetadata(imageId)
+		var err error
+		if superuser {
+			err = api.metadata.DeleteMetadata(imageId)
+		} else {
+			err = api.metadata.DeleteModelMetadata(modelUUID, imageId)
+		}
 		all[i] = params.ErrorResult{common.ServerError(err)}
 	}
 	return params.ErrorResults{Results: all}, nil
 }
 
+// checkModelAdmin returns an error unless the authenticated entity
+// is a controller superuser, or an admin of the model; it reports
+// whether the entity is a superuser. Controller agents are treated
+// as superusers.
+func (api *API) checkModelAdmin() (superuser bool, err error) {
+	if !api.authorizer.AuthClient() {
+		return true, nil
+	}
+	superuser, err = api.authorizer.HasPermission(permission.SuperuserAccess, api.metadata.ControllerTag())
+	if err != nil {
+		return false, errors.Trace(err)
+	}
+	if superuser {
+		return true, nil
+	}
+	admin, err := api.authorizer.HasPermission(permission.AdminAccess, api.metadata.ModelTag())
+	if err != nil {
+		return false, errors.Trace(err)
+	}
+	if !admin {
+		return false, common.ServerError(common.ErrPerm)
+	}
+	return false, nil
+}
+
 func parseMetadataToParams(p cloudimagemetadata.Metadata) params.CloudImageMetadata {
 	result := params.CloudImageMetadata{
 		ImageId:         p.ImageId,
@@ -170,6 +222,7 @@ func parseMetadataToParams(p cloudimagemetadata.Metadata) params.CloudImageMetad
 		RootStorageSize: p.RootStorageSize,
 		Source:          p.Source,
 		Priority:        p.Priority,
+		ModelUUID:       p.ModelUUID,
 	}
 	return result
 }
@@ -7,8 +7,13 @@ import (
 	"github.com/juju/errors"
 	jc "github.com/juju/testing/checkers"
 	gc "gopkg.in/check.v1"
+	"gopkg.in/juju/names.v2"
 
+	"github.com/juju/juju/apiserver/imagemetadata"
 	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/apiserver/testing"
+	"github.com/juju/juju/environs"
+	"github.com/juju/juju/permission"
 	"github.com/juju/juju/state/cloudimagemetadata"
 )
 
@@ -22,7 +27,7 @@ func (s *metadataSuite) TestFindNil(c *gc.C) {
 	found, err := s.api.List(params.ImageMetadataFilter{})
 	c.Assert(err, jc.ErrorIsNil)
 	c.Assert(found.Result, gc.HasLen, 0)
-	s.assertCalls(c, "ControllerTag", findMetadata)
+	s.assertCalls(c, "ControllerTag", "ModelTag", findMetadata)
 }
 
 func (s *metadataSuite) TestFindEmpty(c *gc.C) {
@@ -33,7 +38,7 @@ func (s *metadataSuite) TestFindEmpty(c *gc.C) {
 	found, err := s.api.List(params.ImageMetadataFilter{})
 	c.Assert(err, jc.ErrorIsNil)
 	c.Assert(found.Result, gc.HasLen, 0)
-	s.assertCalls(c, "ControllerTag", findMetadata)
+	s.assertCalls(c, "ControllerTag", "ModelTag", findMetadata)
 }
 
 func (s *metadataSuite) TestFindEmptyGroups(c *gc.C) {
@@ -47,7 +52,7 @@ func (s *metadataSuite) TestFindEmptyGroups(c *gc.C) {
 	found, err := s.api.List(params.ImageMetadataFilter{})
 	c.Assert(err, jc.ErrorIsNil)
 	c.Assert(found.Result, gc.HasLen, 0)
-	s.assertCalls(c, "ControllerTag", findMetadata)
+	s.assertCalls(c, "ControllerTag", "ModelTag", findMetadata)
 }
 
 func (s *metadataSuite) TestFindError(c *gc.C) {
@@ -59,7 +64,7 @@ func (s *metadataSuite) TestFindError(c *gc.C) {
 	found, err := s.api.List(params.ImageMetadataFilter{})
 	c.Assert(err, gc.ErrorMatches, msg)
 	c.Assert(found.Result, gc.HasLen, 0)
-	s.assertCalls(c, "ControllerTag", findMetadata)
+	s.assertCalls(c, "ControllerTag", "ModelTag", findMetadata)
 }
 
 func (s *metadataSuite) TestFindOrder(c *gc.C) {
@@ -92,7 +97,7 @@ func (s *metadataSuite) TestFindOrder(c *gc.C) {
 		params.CloudImageMetadata{ImageId: customImageId2, Priority: 20},
 		params.CloudImageMetadata{ImageId: publicImageId, Priority: 15},
 	})
-	s.assertCalls(c, "ControllerTag", findMetadata)
+	s.assertCalls(c, "ControllerTag", "ModelTag", findMetadata)
 }
 
 func (s *metadataSuite) TestSaveEmpty(c *gc.C) {
@@ -138,11 +143,70 @@ func (s *metadataSuite) TestSave(c *gc.C) {
 	s.assertCalls(c, "ControllerTag", environConfig, saveMetadata, saveMetadata)
 }
 
+func (s *metadataSuite) TestSetModelImagesEmpty(c *gc.C) {
+	errs, err := s.api.SetModelImages(params.MetadataSaveParams{})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(errs.Results, gc.HasLen, 0)
+	s.assertCalls(c, "ControllerTag")
+}
+
+func (s *metadataSuite) TestSetModelImages(c *gc.C) {
+	m := params.CloudImageMetadata{
+		ImageId:   "image-id",
+		Source:    "custom",
+		ModelUUID: "ignored",
+	}
+	msg := "save error"
+
+	saveCalls := 0
+	s.state.saveMetadata = func(m []cloudimagemetadata.Metadata) error {
+		saveCalls += 1
+		c.Assert(m, gc.HasLen, saveCalls)
+		for _, one := range m {
+			c.Assert(one.ModelUUID, gc.Equals, "deadbeef-0bad-400d-8000-4b1d0d06f00d")
+		}
+		if saveCalls == 1 {
+			// don't err on first call
+			return nil
+		}
+		return errors.New(msg)
+	}
+
+	errs, err := s.api.SetModelImages(params.MetadataSaveParams{
+		Metadata: []params.CloudImageMetadataList{{
+			Metadata: []params.CloudImageMetadata{m},
+		}, {
+			Metadata: []params.CloudImageMetadata{m, m},
+		}},
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(errs.Results, gc.HasLen, 2)
+	c.Assert(errs.Results[0].Error, gc.IsNil)
+	c.Assert(errs.Results[1].Error, gc.ErrorMatches, msg)
+	s.assertCalls(c, "ControllerTag", environConfig, "ModelTag", saveMetadata, saveMetadata)
+}
+
+func (s *metadataSuite) TestSetModelImagesPermissionDenied(c *gc.C) {
+	s.authorizer.Tag = names.NewUserTag("someoneelse")
+	api, err := imagemetadata.CreateAPI(s.state, func() (environs.Environ, error) {
+		return &mockEnviron{}, nil
+	}, s.resources, s.authorizer)
+	c.Assert(err, jc.ErrorIsNil)
+
+	_, err = api.SetModelImages(params.MetadataSaveParams{
+		Metadata: []params.CloudImageMetadataList{{
+			Metadata: []params.CloudImageMetadata{{Source: "custom"}},
+		}},
+	})
+	c.Assert(err, gc.ErrorMatches, "permission denied")
+	s.assertCalls(c, "ControllerTag", "ModelTag")
+}
+
 func (s *metadataSuite) TestDeleteEmpty(c *gc.C) {
 	errs, err := s.api.Delete(params.MetadataImageIds{})
 	c.Assert(err, jc.ErrorIsNil)
 	c.Assert(errs.Results, gc.HasLen, 0)
-	s.assertCalls(c, "ControllerTag")
+	s.assertCalls(c, "ControllerTag", "ModelTag")
 }
 
 func (s *metadataSuite) TestDelete(c *gc.C) {
@@ -162,5 +226,87 @@ func (s *metadataSuite) TestDelete(c *gc.C) {
 	c.Assert(errs.Results, gc.HasLen, 2)
 	c.Assert(errs.Results[0].Error, gc.IsNil)
 	c.Assert(errs.Results[1].Error, gc.ErrorMatches, msg)
-	s.assertCalls(c, "ControllerTag", deleteMetadata, deleteMetadata)
+	s.assertCalls(c, "ControllerTag", "ModelTag", deleteMetadata, deleteMetadata)
+}
+
+func (s *metadataSuite) TestFindModelScoped(c *gc.C) {
+	s.state.findMetadata = func(f cloudimagemetadata.MetadataFilter) (map[string][]cloudimagemetadata.Metadata, error) {
+		c.Assert(f.ModelUUID, gc.Equals, "deadbeef-0bad-400d-8000-4b1d0d06f00d")
+		return nil, nil
+	}
+
+	_, err := s.api.List(params.ImageMetadataFilter{})
+	c.Assert(err, jc.ErrorIsNil)
+	s.assertCalls(c, "ControllerTag", "ModelTag", findMetadata)
+}
+
+func (s *metadataSuite) TestFindModelAdmin(c *gc.C) {
+	api := s.modelAdminAPI(c)
+	_, err := api.List(params.ImageMetadataFilter{})
+	c.Assert(err, jc.ErrorIsNil)
+	s.assertCalls(c, "ControllerTag", "ModelTag", "ModelTag", findMetadata)
+}
+
+func (s *metadataSuite) TestFindPermissionDenied(c *gc.C) {
+	s.authorizer.Tag = names.NewUserTag("someoneelse")
+	api, err := imagemetadata.CreateAPI(s.state, func() (environs.Environ, error) {
+		return &mockEnviron{}, nil
+	}, s.resources, s.authorizer)
+	c.Assert(err, jc.ErrorIsNil)
+
+	_, err = api.List(params.ImageMetadataFilter{})
+	c.Assert(err, gc.ErrorMatches, "permission denied")
+	s.assertCalls(c, "ControllerTag", "ModelTag")
+}
+
+func (s *metadataSuite) TestDeleteModelAdmin(c *gc.C) {
+	idOk := "ok"
+	idFail := "fail"
+	msg := "delete error"
+
+	s.state.deleteModelMetadata = func(modelUUID, imageId string) error {
+		c.Assert(modelUUID, gc.Equals, "deadbeef-0bad-400d-8000-4b1d0d06f00d")
+		if imageId == idFail {
+			return errors.New(msg)
+		}
+		return nil
+	}
+
+	api := s.modelAdminAPI(c)
+	errs, err := api.Delete(params.MetadataImageIds{[]string{idOk, idFail}})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(errs.Results, gc.HasLen, 2)
+	c.Assert(errs.Results[0].Error, gc.IsNil)
+	c.Assert(errs.Results[1].Error, gc.ErrorMatches, msg)
+	s.assertCalls(c, "ControllerTag", "ModelTag", "ModelTag", deleteModelMetadata, deleteModelMetadata)
+}
+
+func (s *metadataSuite) TestDeletePermissionDenied(c *gc.C) {
+	s.authorizer.Tag = names.NewUserTag("someoneelse")
+	api, err := imagemetadata.CreateAPI(s.state, func() (environs.Environ, error) {
+		return &mockEnviron{}, nil
+	}, s.resources, s.authorizer)
+	c.Assert(err, jc.ErrorIsNil)
+
+	_, err = api.Delete(params.MetadataImageIds{[]string{"ok"}})
+	c.Assert(err, gc.ErrorMatches, "permission denied")
+	s.assertCalls(c, "ControllerTag", "ModelTag")
+}
+
+// modelAdminAPI returns an API for a client that is an
+// admin of the model, but not a controller superuser.
+func (s *metadataSuite) modelAdminAPI(c *gc.C) *imagemetadata.API {
+	api, err := imagemetadata.CreateAPI(s.state, func() (environs.Environ, error) {
+		return &mockEnviron{}, nil
+	}, s.resources, modelAdminAuthorizer{s.authorizer})
+	c.Assert(err, jc.ErrorIsNil)
+	return api
+}
+
+type modelAdminAuthorizer struct {
+	testing.FakeAuthorizer
+}
+
+func (modelAdminAuthorizer) HasPermission(operation permission.Access, target names.Tag) (bool, error) {
+	return operation == permission.AdminAccess && target.Kind() == names.ModelTagKind, nil
 }
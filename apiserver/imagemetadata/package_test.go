@@ -59,10 +59,11 @@ func (s *baseImageMetadataSuite) assertCalls(c *gc.C, expectedCalls ...string) {
 }
 
 const (
-	findMetadata   = "findMetadata"
-	saveMetadata   = "saveMetadata"
-	deleteMetadata = "deleteMetadata"
-	environConfig  = "environConfig"
+	findMetadata        = "findMetadata"
+	saveMetadata        = "saveMetadata"
+	deleteMetadata      = "deleteMetadata"
+	deleteModelMetadata = "deleteModelMetadata"
+	environConfig       = "environConfig"
 )
 
 func (s *baseImageMetadataSuite) constructState(cfg *config.Config, model imagemetadata.Model) *mockState {
@@ -77,6 +78,9 @@ func (s *baseImageMetadataSuite) constructState(cfg *config.Config, model imagem
 		deleteMetadata: func(imageId string) error {
 			return nil
 		},
+		deleteModelMetadata: func(modelUUID, imageId string) error {
+			return nil
+		},
 		environConfig: func() (*config.Config, error) {
 			return cfg, nil
 		},
@@ -86,18 +90,23 @@ func (s *baseImageMetadataSuite) constructState(cfg *config.Config, model imagem
 		controllerTag: func() names.ControllerTag {
 			return names.NewControllerTag("deadbeef-2f18-4fd2-967d-db9663db7bea")
 		},
+		modelTag: func() names.ModelTag {
+			return names.NewModelTag("deadbeef-0bad-400d-8000-4b1d0d06f00d")
+		},
 	}
 }
 
 type mockState struct {
 	*gitjujutesting.Stub
 
-	findMetadata   func(f cloudimagemetadata.MetadataFilter) (map[string][]cloudimagemetadata.Metadata, error)
-	saveMetadata   func(m []cloudimagemetadata.Metadata) error
-	deleteMetadata func(imageId string) error
-	environConfig  func() (*config.Config, error)
-	model          func() (imagemetadata.Model, error)
-	controllerTag  func() names.ControllerTag
+	findMetadata        func(f cloudimagemetadata.MetadataFilter) (map[string][]cloudimagemetadata.Metadata, error)
+	saveMetadata        func(m []cloudimagemetadata.Metadata) error
+	deleteMetadata      func(imageId string) error
+	deleteModelMetadata func(modelUUID, imageId string) error
+	environConfig       func() (*config.Config, error)
+	model               func() (imagemetadata.Model, error)
+	controllerTag       func() names.ControllerTag
+	modelTag            func() names.ModelTag
 }
 
 func (st *mockState) FindMetadata(f cloudimagemetadata.MetadataFilter) (map[string][]cloudimagemetadata.Metadata, error) {
@@ -115,6 +124,11 @@ func (st *mockState) DeleteMetadata(imageId string) error {
 	return st.deleteMetadata(imageId)
 }
 
+func (st *mockState) DeleteModelMetadata(modelUUID, imageId string) error {
+	st.Stub.MethodCall(st, deleteModelMetadata, modelUUID, imageId)
+	return st.deleteModelMetadata(modelUUID, imageId)
+}
+
 func (st *mockState) ModelConfig() (*config.Config, error) {
 	st.Stub.MethodCall(st, environConfig)
 	return st.environConfig()
@@ -130,6 +144,11 @@ func (st *mockState) ControllerTag() names.ControllerTag {
 	return st.controllerTag()
 }
 
+func (st *mockState) ModelTag() names.ModelTag {
+	st.Stub.MethodCall(st, "ModelTag")
+	return st.modelTag()
+}
+
 type mockModel struct {
 	cloudRegion string
 }
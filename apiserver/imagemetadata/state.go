@@ -14,9 +14,11 @@ type metadataAcess interface {
 	FindMetadata(cloudimagemetadata.MetadataFilter) (map[string][]cloudimagemetadata.Metadata, error)
 	SaveMetadata([]cloudimagemetadata.Metadata) error
 	DeleteMetadata(imageId string) error
+	DeleteModelMetadata(modelUUID, imageId string) error
 	Model() (Model, error)
 	ModelConfig() (*config.Config, error)
 	ControllerTag() names.ControllerTag
+	ModelTag() names.ModelTag
 }
 
 type Model interface {
@@ -43,6 +45,10 @@ func (s stateShim) DeleteMetadata(imageId string) error {
 	return s.State.CloudImageMetadataStorage.DeleteMetadata(imageId)
 }
 
+func (s stateShim) DeleteModelMetadata(modelUUID, imageId string) error {
+	return s.State.CloudImageMetadataStorage.DeleteModelMetadata(modelUUID, imageId)
+}
+
 func (s stateShim) Model() (Model, error) {
 	m, err := s.State.Model()
 	if err != nil {
@@ -0,0 +1,99 @@
+// Copyright 2016 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package apiserver
+
+import (
+	"net/http"
+
+	"github.com/juju/errors"
+
+	"github.com/juju/juju/apiserver/common"
+	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/permission"
+	"github.com/juju/juju/state"
+	"github.com/juju/juju/worker/introspection/pprof"
+)
+
+// introspectionProfiles holds the names of the runtime profiles that
+// may be obtained from the introspection handler.
+var introspectionProfiles = map[string]bool{
+	"goroutine": true,
+	"heap":      true,
+}
+
+// introspectionHandler serves reports of the controller's internal
+// state to controller administrators, so that problems such as hung
+// transactions can be diagnosed remotely. The reports are the same as
+// some of those served on each agent's introspection socket:
+//
+//   /introspection/txns      incomplete transactions and long queues (JSON)
+//   /introspection/watchers  watches on each collection (JSON)
+//   /introspection/goroutine goroutine profile (pprof)
+//   /introspection/heap      heap profile (pprof)
+type introspectionHandler struct {
+	ctxt httpContext
+}
+
+func (h *introspectionHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if err := h.checkAuth(r); err != nil {
+		sendError(w, err)
+		return
+	}
+	if r.Method != "GET" {
+		sendError(w, errors.MethodNotAllowedf("unsupported method: %q", r.Method))
+		return
+	}
+
+	st := h.ctxt.srv.state
+	name := r.URL.Query().Get(":name")
+	switch {
+	case name == "txns":
+		report, err := st.TxnReport()
+		if err != nil {
+			sendError(w, errors.Trace(err))
+			return
+		}
+		sendStatusAndJSON(w, http.StatusOK, txnReportParams(report))
+	case name == "watchers":
+		sendStatusAndJSON(w, http.StatusOK, st.WatcherReport())
+	case introspectionProfiles[name]:
+		pprof.Handler(name).ServeHTTP(w, r)
+	default:
+		sendError(w, errors.NotFoundf("introspection report %q", name))
+	}
+}
+
+// checkAuth checks that the request is made by a controller
+// administrator.
+func (h *introspectionHandler) checkAuth(r *http.Request) error {
+	st, entity, err := h.ctxt.stateForRequestAuthenticatedUser(r)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	isAdmin, err := common.HasPermission(
+		st.UserAccess, entity.Tag(), permission.SuperuserAccess, st.ControllerTag(),
+	)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	if !isAdmin {
+		return common.ErrPerm
+	}
+	return nil
+}
+
+func txnReportParams(report state.TxnReport) params.TxnReport {
+	queues := make([]params.TxnQueue, len(report.Queues))
+	for i, queue := range report.Queues {
+		queues[i] = params.TxnQueue{
+			Collection: queue.Collection,
+			Id:         queue.Id,
+			Length:     queue.Length,
+		}
+	}
+	return params.TxnReport{
+		Incomplete: report.Incomplete,
+		Queues:     queues,
+	}
+}
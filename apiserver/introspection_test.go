@@ -0,0 +1,107 @@
+// Copyright 2016 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package apiserver_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/apiserver/params"
+	statetesting "github.com/juju/juju/state/testing"
+)
+
+type introspectionSuite struct {
+	authHTTPSuite
+}
+
+var _ = gc.Suite(&introspectionSuite{})
+
+func (s *introspectionSuite) introspectionURL(c *gc.C, name string) string {
+	url := s.baseURL(c)
+	url.Path = "/introspection/" + name
+	return url.String()
+}
+
+func (s *introspectionSuite) adminRequest(c *gc.C, name string) *http.Response {
+	return s.sendRequest(c, httpRequestParams{
+		method:   "GET",
+		url:      s.introspectionURL(c, name),
+		tag:      s.AdminUserTag(c).String(),
+		password: "dummy-secret",
+	})
+}
+
+func (s *introspectionSuite) assertErrorResponse(c *gc.C, resp *http.Response, expCode int, expError string) {
+	body := assertResponse(c, resp, expCode, params.ContentTypeJSON)
+	var result params.ErrorResult
+	err := json.Unmarshal(body, &result)
+	c.Assert(err, jc.ErrorIsNil, gc.Commentf("body: %s", body))
+	c.Assert(result.Error, gc.NotNil)
+	c.Assert(result.Error, gc.Matches, expError)
+}
+
+func (s *introspectionSuite) TestRequiresAuth(c *gc.C) {
+	resp := s.sendRequest(c, httpRequestParams{
+		method: "GET",
+		url:    s.introspectionURL(c, "txns"),
+	})
+	s.assertErrorResponse(c, resp, http.StatusUnauthorized, "no credentials provided")
+}
+
+func (s *introspectionSuite) TestRequiresControllerAdmin(c *gc.C) {
+	resp := s.authRequest(c, httpRequestParams{
+		method: "GET",
+		url:    s.introspectionURL(c, "txns"),
+	})
+	s.assertErrorResponse(c, resp, http.StatusUnauthorized, "permission denied")
+}
+
+func (s *introspectionSuite) TestTxns(c *gc.C) {
+	resp := s.adminRequest(c, "txns")
+	body := assertResponse(c, resp, http.StatusOK, params.ContentTypeJSON)
+	var report params.TxnReport
+	err := json.Unmarshal(body, &report)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(report.Incomplete, jc.DeepEquals, map[string]int{
+		"preparing": 0,
+		"prepared":  0,
+		"aborting":  0,
+		"applying":  0,
+	})
+	c.Assert(report.Queues, gc.HasLen, 0)
+}
+
+func (s *introspectionSuite) TestWatchers(c *gc.C) {
+	w := s.BackingState.WatchModels()
+	defer statetesting.AssertStop(c, w)
+	s.BackingState.StartSync()
+
+	resp := s.adminRequest(c, "watchers")
+	body := assertResponse(c, resp, http.StatusOK, params.ContentTypeJSON)
+	var report map[string]map[string]int
+	err := json.Unmarshal(body, &report)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(report["models"]["collection-watches"] > 0, jc.IsTrue)
+}
+
+func (s *introspectionSuite) TestGoroutineProfile(c *gc.C) {
+	url := s.introspectionURL(c, "goroutine") + "?debug=1"
+	resp := s.sendRequest(c, httpRequestParams{
+		method:   "GET",
+		url:      url,
+		tag:      s.AdminUserTag(c).String(),
+		password: "dummy-secret",
+	})
+	body := assertResponse(c, resp, http.StatusOK, "text/plain; charset=utf-8")
+	c.Assert(strings.HasPrefix(string(body), "goroutine profile:"), jc.IsTrue)
+}
+
+func (s *introspectionSuite) TestUnknownReport(c *gc.C) {
+	resp := s.adminRequest(c, "cpu")
+	s.assertErrorResponse(c, resp, http.StatusNotFound, `introspection report "cpu" not found`)
+}
@@ -101,6 +101,38 @@ func (api *MachinerAPI) SetMachineAddresses(args params.SetMachinesAddresses) (p
 	return results, nil
 }
 
+// SetHardwareCharacteristics updates the recorded hardware
+// characteristics of the given machines, whose instances have
+// been resized since they were provisioned.
+func (api *MachinerAPI) SetHardwareCharacteristics(args params.SetMachinesHardwareCharacteristics) (params.ErrorResults, error) {
+	results := params.ErrorResults{
+		Results: make([]params.ErrorResult, len(args.Machines)),
+	}
+	canModify, err := api.getCanModify()
+	if err != nil {
+		return results, err
+	}
+	for i, arg := range args.Machines {
+		tag, err := names.ParseMachineTag(arg.Tag)
+		if err != nil {
+			results.Results[i].Error = common.ServerError(common.ErrPerm)
+			continue
+		}
+		err = common.ErrPerm
+		if canModify(tag) {
+			var m *state.Machine
+			m, err = api.getMachine(tag)
+			if err == nil {
+				err = m.SetHardwareCharacteristics(arg.Characteristics)
+			} else if errors.IsNotFound(err) {
+				err = common.ErrPerm
+			}
+		}
+		results.Results[i].Error = common.ServerError(err)
+	}
+	return results, nil
+}
+
 // Jobs returns the jobs assigned to the given entities.
 func (api *MachinerAPI) Jobs(args params.Entities) (params.JobsResults, error) {
 	result := params.JobsResults{
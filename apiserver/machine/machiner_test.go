@@ -14,6 +14,7 @@ import (
 	"github.com/juju/juju/apiserver/machine"
 	"github.com/juju/juju/apiserver/params"
 	apiservertesting "github.com/juju/juju/apiserver/testing"
+	"github.com/juju/juju/instance"
 	"github.com/juju/juju/network"
 	"github.com/juju/juju/state"
 	"github.com/juju/juju/state/multiwatcher"
@@ -198,6 +199,44 @@ func (s *machinerSuite) TestSetMachineAddresses(c *gc.C) {
 	c.Assert(s.machine0.MachineAddresses(), gc.HasLen, 0)
 }
 
+func (s *machinerSuite) TestSetHardwareCharacteristics(c *gc.C) {
+	err := s.machine1.SetProvisioned("i-1", "fake_nonce", nil)
+	c.Assert(err, jc.ErrorIsNil)
+
+	mem := uint64(8192)
+	hc := instance.HardwareCharacteristics{Mem: &mem}
+	args := params.SetMachinesHardwareCharacteristics{Machines: []params.MachineHardwareCharacteristics{
+		{Tag: "machine-1", Characteristics: hc},
+		{Tag: "machine-0", Characteristics: hc},
+		{Tag: "machine-42", Characteristics: hc},
+	}}
+
+	result, err := s.machiner.SetHardwareCharacteristics(args)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(result, gc.DeepEquals, params.ErrorResults{
+		Results: []params.ErrorResult{
+			{nil},
+			{apiservertesting.ErrUnauthorized},
+			{apiservertesting.ErrUnauthorized},
+		},
+	})
+
+	md, err := s.machine1.HardwareCharacteristics()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(*md, jc.DeepEquals, hc)
+}
+
+func (s *machinerSuite) TestSetHardwareCharacteristicsNotProvisioned(c *gc.C) {
+	mem := uint64(8192)
+	args := params.SetMachinesHardwareCharacteristics{Machines: []params.MachineHardwareCharacteristics{
+		{Tag: "machine-1", Characteristics: instance.HardwareCharacteristics{Mem: &mem}},
+	}}
+	result, err := s.machiner.SetHardwareCharacteristics(args)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(result.Results, gc.HasLen, 1)
+	c.Assert(result.Results[0].Error, jc.Satisfies, params.IsCodeNotProvisioned)
+}
+
 func (s *machinerSuite) TestSetEmptyMachineAddresses(c *gc.C) {
 	// Set some addresses so we can ensure they are removed.
 	addresses := network.NewAddresses("127.0.0.1", "8.8.8.8")
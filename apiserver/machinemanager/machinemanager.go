@@ -7,14 +7,17 @@ import (
 	"fmt"
 
 	"github.com/juju/errors"
+	"gopkg.in/juju/names.v2"
 
 	"github.com/juju/juju/apiserver/common"
 	"github.com/juju/juju/apiserver/facade"
 	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/environs"
 	"github.com/juju/juju/environs/config"
 	"github.com/juju/juju/instance"
 	"github.com/juju/juju/permission"
 	"github.com/juju/juju/state"
+	"github.com/juju/juju/state/stateenvirons"
 )
 
 func init() {
@@ -26,12 +29,17 @@ type MachineManagerAPI struct {
 	st         stateInterface
 	authorizer facade.Authorizer
 	check      *common.BlockChecker
+	newEnviron func() (environs.Environ, error)
 }
 
 var getState = func(st *state.State) stateInterface {
 	return stateShim{st}
 }
 
+var newEnviron = func(st *state.State) (environs.Environ, error) {
+	return stateenvirons.GetNewEnvironFunc(environs.New)(st)
+}
+
 // NewMachineManagerAPI creates a new server-side MachineManager API facade.
 func NewMachineManagerAPI(
 	st *state.State,
@@ -48,6 +56,9 @@ func NewMachineManagerAPI(
 		st:         s,
 		authorizer: authorizer,
 		check:      common.NewBlockChecker(s),
+		newEnviron: func() (environs.Environ, error) {
+			return newEnviron(st)
+		},
 	}, nil
 }
 
@@ -168,3 +179,158 @@ func (mm *MachineManagerAPI) addOneMachine(p params.AddMachineParams) (*state.Ma
 	}
 	return mm.st.AddMachineInsideNewMachine(template, template, p.ContainerType)
 }
+
+// ReplaceMachines requests that the instances of the specified machines
+// be replaced by the provisioner, for machines whose instances were
+// removed from the cloud outside of Juju. Machines whose instances
+// still exist are not replaced.
+func (mm *MachineManagerAPI) ReplaceMachines(args params.Entities) (params.ErrorResults, error) {
+	results := params.ErrorResults{
+		Results: make([]params.ErrorResult, len(args.Entities)),
+	}
+
+	canWrite, err := mm.authorizer.HasPermission(permission.WriteAccess, mm.st.ModelTag())
+	if err != nil {
+		return results, errors.Trace(err)
+	}
+	if !canWrite {
+		return results, common.ErrPerm
+	}
+
+	if err := mm.check.ChangeAllowed(); err != nil {
+		return results, errors.Trace(err)
+	}
+
+	env, err := mm.newEnviron()
+	if err != nil {
+		return results, errors.Annotate(err, "opening environ")
+	}
+	replacer, ok := env.(environs.InstanceReplacer)
+	if !ok {
+		err := errors.NotSupportedf("replacing machines in this model")
+		for i := range results.Results {
+			results.Results[i].Error = common.ServerError(err)
+		}
+		return results, nil
+	}
+	for i, entity := range args.Entities {
+		err := mm.replaceOneMachine(replacer, entity.Tag)
+		results.Results[i].Error = common.ServerError(err)
+	}
+	return results, nil
+}
+
+func (mm *MachineManagerAPI) replaceOneMachine(replacer environs.InstanceReplacer, tagString string) error {
+	tag, err := names.ParseMachineTag(tagString)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	m, err := mm.st.Machine(tag.Id())
+	if err != nil {
+		return errors.Trace(err)
+	}
+	instanceId, err := m.InstanceId()
+	if err != nil {
+		return errors.Trace(err)
+	}
+	if err := replacer.CheckReplaceable(instanceId); err != nil {
+		return errors.Annotatef(err, "cannot replace machine %s", tag.Id())
+	}
+	return m.RequestReplacement()
+}
+
+// PreviewInstanceSpecs reports, for each of the supplied machine
+// parameters, the instance that the provider would start for such a
+// machine. No machines or instances are created.
+func (mm *MachineManagerAPI) PreviewInstanceSpecs(args params.PreviewInstanceSpecs) (params.InstanceSpecPreviewResults, error) {
+	results := params.InstanceSpecPreviewResults{
+		Results: make([]params.InstanceSpecPreviewResult, len(args.Specs)),
+	}
+
+	canRead, err := mm.authorizer.HasPermission(permission.ReadAccess, mm.st.ModelTag())
+	if err != nil {
+		return results, errors.Trace(err)
+	}
+	if !canRead {
+		return results, common.ErrPerm
+	}
+
+	env, err := mm.newEnviron()
+	if err != nil {
+		return results, errors.Annotate(err, "opening environ")
+	}
+	previewer, ok := env.(environs.InstanceSpecPreviewer)
+	if !ok {
+		err := errors.NotSupportedf("previewing instances in this model")
+		for i := range results.Results {
+			results.Results[i].Error = common.ServerError(err)
+		}
+		return results, nil
+	}
+	for i, p := range args.Specs {
+		preview, err := mm.previewOneInstanceSpec(previewer, p)
+		if err != nil {
+			results.Results[i].Error = common.ServerError(err)
+			continue
+		}
+		results.Results[i].Result = &params.InstanceSpecPreview{
+			InstanceType:     preview.InstanceType,
+			Image:            preview.ImageId,
+			Arch:             preview.Arch,
+			Cost:             preview.Cost,
+			AvailabilityZone: preview.AvailabilityZone,
+		}
+	}
+	return results, nil
+}
+
+func (mm *MachineManagerAPI) previewOneInstanceSpec(
+	previewer environs.InstanceSpecPreviewer,
+	p params.PreviewInstanceSpec,
+) (*environs.InstanceSpecPreview, error) {
+	var placementDirective string
+	if p.Placement != nil {
+		if _, err := instance.ParseContainerType(p.Placement.Scope); err == nil {
+			return nil, errors.NotSupportedf("previewing containers")
+		}
+		env, err := mm.st.Model()
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		if p.Placement.Scope != env.Name() && p.Placement.Scope != env.UUID() {
+			return nil, fmt.Errorf("invalid model name %q", p.Placement.Scope)
+		}
+		placementDirective = p.Placement.Directive
+	}
+
+	if p.Series == "" {
+		conf, err := mm.st.ModelConfig()
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		p.Series = config.PreferredSeries(conf)
+	}
+
+	validator, err := mm.st.ConstraintsValidator()
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	modelCons, err := mm.st.ModelConstraints()
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	cons, err := validator.Merge(modelCons, p.Constraints)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+
+	preview, err := previewer.PreviewInstanceSpec(environs.PreviewInstanceSpecParams{
+		Series:      p.Series,
+		Constraints: cons,
+		Placement:   placementDirective,
+	})
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	return preview, nil
+}
@@ -14,6 +14,8 @@ import (
 	"github.com/juju/juju/apiserver/machinemanager"
 	"github.com/juju/juju/apiserver/params"
 	apiservertesting "github.com/juju/juju/apiserver/testing"
+	"github.com/juju/juju/constraints"
+	"github.com/juju/juju/environs"
 	"github.com/juju/juju/environs/config"
 	"github.com/juju/juju/instance"
 	"github.com/juju/juju/state"
@@ -118,10 +120,124 @@ func (s *MachineManagerSuite) TestAddMachinesStateError(c *gc.C) {
 	c.Assert(s.st.calls, gc.Equals, 1)
 }
 
+func (s *MachineManagerSuite) TestPreviewInstanceSpecs(c *gc.C) {
+	env := &mockEnviron{
+		preview: &environs.InstanceSpecPreview{
+			InstanceType:     "m1.small",
+			ImageId:          "ami-0123",
+			Arch:             "amd64",
+			Cost:             100,
+			AvailabilityZone: "zone-1",
+		},
+	}
+	machinemanager.PatchEnviron(s, env)
+	s.st.modelCons = constraints.MustParse("mem=4G")
+
+	results, err := s.api.PreviewInstanceSpecs(params.PreviewInstanceSpecs{
+		Specs: []params.PreviewInstanceSpec{{
+			Series:      "trusty",
+			Constraints: constraints.MustParse("cores=2"),
+		}, {
+			Series:    "trusty",
+			Placement: &instance.Placement{Scope: "lxd", Directive: "0"},
+		}},
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(results, jc.DeepEquals, params.InstanceSpecPreviewResults{
+		Results: []params.InstanceSpecPreviewResult{{
+			Result: &params.InstanceSpecPreview{
+				InstanceType:     "m1.small",
+				Image:            "ami-0123",
+				Arch:             "amd64",
+				Cost:             100,
+				AvailabilityZone: "zone-1",
+			},
+		}, {
+			Error: &params.Error{
+				Message: "previewing containers not supported",
+				Code:    params.CodeNotSupported,
+			},
+		}},
+	})
+	c.Assert(env.args, jc.DeepEquals, []environs.PreviewInstanceSpecParams{{
+		Series:      "trusty",
+		Constraints: constraints.MustParse("cores=2 mem=4G"),
+	}})
+	c.Assert(s.st.calls, gc.Equals, 0)
+}
+
+func (s *MachineManagerSuite) TestPreviewInstanceSpecsNotSupported(c *gc.C) {
+	machinemanager.PatchEnviron(s, struct{ environs.Environ }{})
+	results, err := s.api.PreviewInstanceSpecs(params.PreviewInstanceSpecs{
+		Specs: []params.PreviewInstanceSpec{{Series: "trusty"}},
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(results, jc.DeepEquals, params.InstanceSpecPreviewResults{
+		Results: []params.InstanceSpecPreviewResult{{
+			Error: &params.Error{
+				Message: "previewing instances in this model not supported",
+				Code:    params.CodeNotSupported,
+			},
+		}},
+	})
+}
+
+func (s *MachineManagerSuite) TestReplaceMachines(c *gc.C) {
+	machinemanager.PatchEnviron(s, &mockEnviron{
+		existing: []instance.Id{"inst-2"},
+	})
+	s.st.replaceErrs = map[string]error{
+		"1": errors.New("boom"),
+	}
+	results, err := s.api.ReplaceMachines(params.Entities{
+		Entities: []params.Entity{
+			{Tag: "machine-0"},
+			{Tag: "machine-1"},
+			{Tag: "machine-2"},
+			{Tag: "application-foo"},
+		},
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(results, jc.DeepEquals, params.ErrorResults{
+		Results: []params.ErrorResult{
+			{},
+			{Error: &params.Error{Message: "boom"}},
+			{Error: &params.Error{
+				Message: `cannot replace machine 2: instance "inst-2" already exists`,
+				Code:    params.CodeAlreadyExists,
+			}},
+			{Error: &params.Error{Message: `"application-foo" is not a valid machine tag`}},
+		},
+	})
+	// The machine whose instance still exists is not replaced.
+	c.Assert(s.st.replaced, jc.DeepEquals, []string{"0", "1"})
+}
+
+func (s *MachineManagerSuite) TestReplaceMachinesNotSupported(c *gc.C) {
+	machinemanager.PatchEnviron(s, struct{ environs.Environ }{})
+	results, err := s.api.ReplaceMachines(params.Entities{
+		Entities: []params.Entity{{Tag: "machine-0"}},
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(results, jc.DeepEquals, params.ErrorResults{
+		Results: []params.ErrorResult{{
+			Error: &params.Error{
+				Message: "replacing machines in this model not supported",
+				Code:    params.CodeNotSupported,
+			},
+		}},
+	})
+	c.Assert(s.st.replaced, gc.HasLen, 0)
+}
+
 type mockState struct {
-	calls    int
-	machines []state.MachineTemplate
-	err      error
+	calls     int
+	machines  []state.MachineTemplate
+	modelCons constraints.Value
+	err       error
+
+	replaced    []string
+	replaceErrs map[string]error
 }
 
 func (st *mockState) AddOneMachine(template state.MachineTemplate) (*state.Machine, error) {
@@ -139,6 +255,14 @@ func (st *mockState) ModelTag() names.ModelTag {
 	return names.NewModelTag("deadbeef-2f18-4fd2-967d-db9663db7bea")
 }
 
+func (st *mockState) ModelConstraints() (constraints.Value, error) {
+	return st.modelCons, nil
+}
+
+func (st *mockState) ConstraintsValidator() (constraints.Validator, error) {
+	return constraints.NewValidator(), nil
+}
+
 func (st *mockState) ModelConfig() (*config.Config, error) {
 	panic("not implemented")
 }
@@ -155,6 +279,53 @@ func (st *mockState) AddMachineInsideMachine(template state.MachineTemplate, par
 	panic("not implemented")
 }
 
+func (st *mockState) Machine(id string) (machinemanager.Machine, error) {
+	return &mockMachine{st: st, id: id}, nil
+}
+
+type mockMachine struct {
+	st *mockState
+	id string
+}
+
+func (m *mockMachine) InstanceId() (instance.Id, error) {
+	return instance.Id("inst-" + m.id), nil
+}
+
+func (m *mockMachine) RequestReplacement() error {
+	m.st.replaced = append(m.st.replaced, m.id)
+	return m.st.replaceErrs[m.id]
+}
+
+type mockEnviron struct {
+	environs.Environ
+	preview  *environs.InstanceSpecPreview
+	args     []environs.PreviewInstanceSpecParams
+	existing []instance.Id
+}
+
+func (env *mockEnviron) CheckReplaceable(id instance.Id) error {
+	for _, existing := range env.existing {
+		if id == existing {
+			return errors.AlreadyExistsf("instance %q", id)
+		}
+	}
+	return nil
+}
+
+func (env *mockEnviron) DeleteReplacement(instance.Id) error {
+	panic("not implemented")
+}
+
+func (env *mockEnviron) ReplaceInstance(environs.ReplaceInstanceParams) (*environs.StartInstanceResult, error) {
+	panic("not implemented")
+}
+
+func (env *mockEnviron) PreviewInstanceSpec(args environs.PreviewInstanceSpecParams) (*environs.InstanceSpecPreview, error) {
+	env.args = append(env.args, args)
+	return env.preview, nil
+}
+
 type mockBlock struct {
 	state.Block
 }
@@ -4,6 +4,7 @@
 package machinemanager
 
 import (
+	"github.com/juju/juju/constraints"
 	"github.com/juju/juju/environs/config"
 	"github.com/juju/juju/instance"
 	"github.com/juju/juju/state"
@@ -14,10 +15,19 @@ type stateInterface interface {
 	ModelConfig() (*config.Config, error)
 	Model() (*state.Model, error)
 	ModelTag() names.ModelTag
+	ModelConstraints() (constraints.Value, error)
+	ConstraintsValidator() (constraints.Validator, error)
 	GetBlockForType(t state.BlockType) (state.Block, bool, error)
 	AddOneMachine(template state.MachineTemplate) (*state.Machine, error)
 	AddMachineInsideNewMachine(template, parentTemplate state.MachineTemplate, containerType instance.ContainerType) (*state.Machine, error)
 	AddMachineInsideMachine(template state.MachineTemplate, parentId string, containerType instance.ContainerType) (*state.Machine, error)
+	Machine(id string) (Machine, error)
+}
+
+// Machine defines the machine methods used by the MachineManager facade.
+type Machine interface {
+	InstanceId() (instance.Id, error)
+	RequestReplacement() error
 }
 
 type stateShim struct {
@@ -35,6 +45,14 @@ func (s stateShim) ModelTag() names.ModelTag {
 	return s.State.ModelTag()
 }
 
+func (s stateShim) ModelConstraints() (constraints.Value, error) {
+	return s.State.ModelConstraints()
+}
+
+func (s stateShim) ConstraintsValidator() (constraints.Validator, error) {
+	return s.State.ConstraintsValidator()
+}
+
 func (s stateShim) GetBlockForType(t state.BlockType) (state.Block, bool, error) {
 	return s.State.GetBlockForType(t)
 }
@@ -50,3 +68,7 @@ func (s stateShim) AddMachineInsideNewMachine(template, parentTemplate state.Mac
 func (s stateShim) AddMachineInsideMachine(template state.MachineTemplate, parentId string, containerType instance.ContainerType) (*state.Machine, error) {
 	return s.State.AddMachineInsideMachine(template, parentId, containerType)
 }
+
+func (s stateShim) Machine(id string) (Machine, error) {
+	return s.State.Machine(id)
+}
@@ -0,0 +1,163 @@
+// Copyright 2016 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+// Package machinepower implements the API endpoint for suspending
+// and resuming the instances of a model's machines.
+package machinepower
+
+import (
+	"github.com/juju/errors"
+	"gopkg.in/juju/names.v2"
+
+	"github.com/juju/juju/apiserver/common"
+	"github.com/juju/juju/apiserver/facade"
+	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/environs"
+	"github.com/juju/juju/instance"
+	"github.com/juju/juju/permission"
+	"github.com/juju/juju/state"
+	"github.com/juju/juju/state/stateenvirons"
+)
+
+func init() {
+	common.RegisterStandardFacade("MachinePower", 1, newFacade)
+}
+
+// Backend defines the State API used by the machinepower facade.
+type Backend interface {
+	ModelTag() names.ModelTag
+	GetBlockForType(t state.BlockType) (state.Block, bool, error)
+	Machine(id string) (Machine, error)
+}
+
+// Machine defines the state.Machine API used by the machinepower facade.
+type Machine interface {
+	InstanceId() (instance.Id, error)
+	IsManager() bool
+	ContainerType() instance.ContainerType
+}
+
+// NewEnvironFunc is the type of a function that returns the
+// model's Environ.
+type NewEnvironFunc func() (environs.Environ, error)
+
+// Facade implements the MachinePower API.
+type Facade struct {
+	backend    Backend
+	newEnviron NewEnvironFunc
+	authorizer facade.Authorizer
+	check      *common.BlockChecker
+}
+
+func newFacade(st *state.State, _ facade.Resources, authorizer facade.Authorizer) (*Facade, error) {
+	newEnviron := func() (environs.Environ, error) {
+		return stateenvirons.GetNewEnvironFunc(environs.New)(st)
+	}
+	return New(backendShim{st}, newEnviron, authorizer)
+}
+
+// New returns a new MachinePower API facade.
+func New(backend Backend, newEnviron NewEnvironFunc, authorizer facade.Authorizer) (*Facade, error) {
+	if !authorizer.AuthClient() {
+		return nil, common.ErrPerm
+	}
+	return &Facade{
+		backend:    backend,
+		newEnviron: newEnviron,
+		authorizer: authorizer,
+		check:      common.NewBlockChecker(backend),
+	}, nil
+}
+
+// SuspendMachines stops the instances of the specified machines
+// without destroying them, if the provider supports it.
+func (f *Facade) SuspendMachines(args params.Entities) (params.ErrorResults, error) {
+	return f.powerOp(args, environs.InstanceSuspender.SuspendInstances)
+}
+
+// ResumeMachines starts the instances of the specified machines,
+// which must previously have been suspended.
+func (f *Facade) ResumeMachines(args params.Entities) (params.ErrorResults, error) {
+	return f.powerOp(args, environs.InstanceSuspender.ResumeInstances)
+}
+
+func (f *Facade) powerOp(
+	args params.Entities,
+	op func(environs.InstanceSuspender, ...instance.Id) error,
+) (params.ErrorResults, error) {
+	result := params.ErrorResults{
+		Results: make([]params.ErrorResult, len(args.Entities)),
+	}
+	canWrite, err := f.authorizer.HasPermission(permission.WriteAccess, f.backend.ModelTag())
+	if err != nil {
+		return result, errors.Trace(err)
+	}
+	if !canWrite {
+		return result, common.ErrPerm
+	}
+	if err := f.check.ChangeAllowed(); err != nil {
+		return result, errors.Trace(err)
+	}
+
+	env, err := f.newEnviron()
+	if err != nil {
+		return result, errors.Annotate(err, "opening environ")
+	}
+	suspender, ok := env.(environs.InstanceSuspender)
+	if !ok {
+		return result, errors.NotSupportedf("suspending machines in this model")
+	}
+
+	// The instances are suspended or resumed together, as the
+	// provider may take some time to complete the operation.
+	var ids []instance.Id
+	var indices []int
+	for i, entity := range args.Entities {
+		id, err := f.instanceId(entity.Tag)
+		if err != nil {
+			result.Results[i].Error = common.ServerError(err)
+			continue
+		}
+		ids = append(ids, id)
+		indices = append(indices, i)
+	}
+	if len(ids) == 0 {
+		return result, nil
+	}
+	if err := op(suspender, ids...); err != nil {
+		for _, i := range indices {
+			result.Results[i].Error = common.ServerError(err)
+		}
+	}
+	return result, nil
+}
+
+// instanceId returns the ID of the instance of the machine with
+// the given tag, if the machine may be suspended and resumed.
+func (f *Facade) instanceId(tagString string) (instance.Id, error) {
+	tag, err := names.ParseMachineTag(tagString)
+	if err != nil {
+		return "", err
+	}
+	m, err := f.backend.Machine(tag.Id())
+	if err != nil {
+		return "", err
+	}
+	if m.IsManager() {
+		return "", errors.Errorf("machine %s is a controller machine", tag.Id())
+	}
+	if m.ContainerType() != "" {
+		return "", errors.NotSupportedf("suspending container %s", tag.Id())
+	}
+	return m.InstanceId()
+}
+
+// backendShim wraps a *state.State to implement Backend.
+type backendShim struct {
+	*state.State
+}
+
+// Machine is part of the Backend interface.
+func (s backendShim) Machine(id string) (Machine, error) {
+	return s.State.Machine(id)
+}
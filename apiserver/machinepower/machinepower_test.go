@@ -0,0 +1,198 @@
+// Copyright 2016 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package machinepower_test
+
+import (
+	"github.com/juju/errors"
+	jujutesting "github.com/juju/testing"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+	"gopkg.in/juju/names.v2"
+
+	"github.com/juju/juju/apiserver/common"
+	"github.com/juju/juju/apiserver/machinepower"
+	"github.com/juju/juju/apiserver/params"
+	apiservertesting "github.com/juju/juju/apiserver/testing"
+	"github.com/juju/juju/environs"
+	"github.com/juju/juju/instance"
+	"github.com/juju/juju/state"
+	coretesting "github.com/juju/juju/testing"
+)
+
+type machinePowerSuite struct {
+	jujutesting.IsolationSuite
+	backend    *mockBackend
+	env        *mockEnviron
+	authorizer apiservertesting.FakeAuthorizer
+	facade     *machinepower.Facade
+}
+
+var _ = gc.Suite(&machinePowerSuite{})
+
+func (s *machinePowerSuite) SetUpTest(c *gc.C) {
+	s.IsolationSuite.SetUpTest(c)
+	s.backend = &mockBackend{
+		machines: map[string]*mockMachine{
+			"0": {instanceId: "i-0", manager: true},
+			"1": {instanceId: "i-1"},
+			"2": {instanceId: "i-2"},
+			"2/lxd/0": {
+				instanceId:    "juju-lxd-0",
+				containerType: instance.LXD,
+			},
+			"3": {instanceIdErr: errors.NotProvisionedf("machine 3")},
+		},
+	}
+	s.env = &mockEnviron{}
+	s.authorizer = apiservertesting.FakeAuthorizer{
+		Tag:      names.NewUserTag("admin"),
+		AdminTag: names.NewUserTag("admin"),
+	}
+	var err error
+	s.facade, err = machinepower.New(s.backend, s.newEnviron, &s.authorizer)
+	c.Assert(err, jc.ErrorIsNil)
+}
+
+func (s *machinePowerSuite) newEnviron() (environs.Environ, error) {
+	return s.env, nil
+}
+
+func entities(tags ...string) params.Entities {
+	args := params.Entities{Entities: make([]params.Entity, len(tags))}
+	for i, tag := range tags {
+		args.Entities[i].Tag = tag
+	}
+	return args
+}
+
+func (s *machinePowerSuite) TestNewNotClient(c *gc.C) {
+	s.authorizer.Tag = names.NewMachineTag("0")
+	_, err := machinepower.New(s.backend, s.newEnviron, &s.authorizer)
+	c.Assert(err, gc.Equals, common.ErrPerm)
+}
+
+func (s *machinePowerSuite) TestSuspendMachines(c *gc.C) {
+	result, err := s.facade.SuspendMachines(entities("machine-1", "machine-2"))
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(result, jc.DeepEquals, params.ErrorResults{
+		Results: []params.ErrorResult{{}, {}},
+	})
+	s.env.CheckCallNames(c, "SuspendInstances")
+	s.env.CheckCall(c, 0, "SuspendInstances", []instance.Id{"i-1", "i-2"})
+}
+
+func (s *machinePowerSuite) TestResumeMachines(c *gc.C) {
+	result, err := s.facade.ResumeMachines(entities("machine-1"))
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(result, jc.DeepEquals, params.ErrorResults{
+		Results: []params.ErrorResult{{}},
+	})
+	s.env.CheckCallNames(c, "ResumeInstances")
+	s.env.CheckCall(c, 0, "ResumeInstances", []instance.Id{"i-1"})
+}
+
+func (s *machinePowerSuite) TestSuspendMachinesInvalid(c *gc.C) {
+	result, err := s.facade.SuspendMachines(entities(
+		"machine-0",
+		"machine-2-lxd-0",
+		"machine-3",
+		"machine-42",
+		"unit-mysql-0",
+		"machine-1",
+	))
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(result.Results, gc.HasLen, 6)
+	c.Check(result.Results[0].Error, gc.ErrorMatches, "machine 0 is a controller machine")
+	c.Check(result.Results[1].Error, gc.ErrorMatches, "suspending container 2/lxd/0 not supported")
+	c.Check(result.Results[2].Error, gc.ErrorMatches, "machine 3 not provisioned")
+	c.Check(result.Results[2].Error, jc.Satisfies, params.IsCodeNotProvisioned)
+	c.Check(result.Results[3].Error, gc.ErrorMatches, "machine 42 not found")
+	c.Check(result.Results[4].Error, gc.ErrorMatches, `"unit-mysql-0" is not a valid machine tag`)
+	c.Check(result.Results[5].Error, gc.IsNil)
+	s.env.CheckCall(c, 0, "SuspendInstances", []instance.Id{"i-1"})
+}
+
+func (s *machinePowerSuite) TestSuspendMachinesError(c *gc.C) {
+	s.env.SetErrors(errors.New("boom"))
+	result, err := s.facade.SuspendMachines(entities("machine-1", "machine-42", "machine-2"))
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(result.Results, gc.HasLen, 3)
+	c.Check(result.Results[0].Error, gc.ErrorMatches, "boom")
+	c.Check(result.Results[1].Error, gc.ErrorMatches, "machine 42 not found")
+	c.Check(result.Results[2].Error, gc.ErrorMatches, "boom")
+}
+
+func (s *machinePowerSuite) TestSuspendMachinesNotSupported(c *gc.C) {
+	facade, err := machinepower.New(s.backend, func() (environs.Environ, error) {
+		return plainEnviron{}, nil
+	}, &s.authorizer)
+	c.Assert(err, jc.ErrorIsNil)
+	_, err = facade.SuspendMachines(entities("machine-1"))
+	c.Assert(err, jc.Satisfies, errors.IsNotSupported)
+}
+
+func (s *machinePowerSuite) TestSuspendMachinesNoWriteAccess(c *gc.C) {
+	s.authorizer.Tag = names.NewUserTag("bob")
+	_, err := s.facade.SuspendMachines(entities("machine-1"))
+	c.Assert(err, gc.Equals, common.ErrPerm)
+	s.env.CheckNoCalls(c)
+}
+
+type mockBackend struct {
+	machines map[string]*mockMachine
+}
+
+func (*mockBackend) ModelTag() names.ModelTag {
+	return coretesting.ModelTag
+}
+
+func (*mockBackend) GetBlockForType(t state.BlockType) (state.Block, bool, error) {
+	return nil, false, nil
+}
+
+func (b *mockBackend) Machine(id string) (machinepower.Machine, error) {
+	m, ok := b.machines[id]
+	if !ok {
+		return nil, errors.NotFoundf("machine %s", id)
+	}
+	return m, nil
+}
+
+type mockMachine struct {
+	instanceId    instance.Id
+	instanceIdErr error
+	manager       bool
+	containerType instance.ContainerType
+}
+
+func (m *mockMachine) InstanceId() (instance.Id, error) {
+	return m.instanceId, m.instanceIdErr
+}
+
+func (m *mockMachine) IsManager() bool {
+	return m.manager
+}
+
+func (m *mockMachine) ContainerType() instance.ContainerType {
+	return m.containerType
+}
+
+type plainEnviron struct {
+	environs.Environ
+}
+
+type mockEnviron struct {
+	environs.Environ
+	jujutesting.Stub
+}
+
+func (env *mockEnviron) SuspendInstances(ids ...instance.Id) error {
+	env.MethodCall(env, "SuspendInstances", ids)
+	return env.NextErr()
+}
+
+func (env *mockEnviron) ResumeInstances(ids ...instance.Id) error {
+	env.MethodCall(env, "ResumeInstances", ids)
+	return env.NextErr()
+}
@@ -17,7 +17,9 @@ type Backend interface {
 	ControllerTag() names.ControllerTag
 	ModelTag() names.ModelTag
 	ModelConfigValues() (config.ConfigValues, error)
-	UpdateModelConfig(map[string]interface{}, []string, state.ValidateConfigFunc) error
+	UpdateModelConfigBy(state.ModelConfigChangeSource, map[string]interface{}, []string, state.ValidateConfigFunc) error
+	ModelConfigChanges(limit int) ([]state.ModelConfigChange, error)
+	WatchModelConfigChangeRecords() state.NotifyWatcher
 }
 
 type stateShim struct {
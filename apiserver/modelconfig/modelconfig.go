@@ -5,6 +5,7 @@ package modelconfig
 
 import (
 	"github.com/juju/errors"
+	"gopkg.in/juju/names.v2"
 
 	"github.com/juju/juju/apiserver/common"
 	"github.com/juju/juju/apiserver/facade"
@@ -12,36 +13,50 @@ import (
 	"github.com/juju/juju/environs/config"
 	"github.com/juju/juju/permission"
 	"github.com/juju/juju/state"
+	"github.com/juju/juju/state/watcher"
 )
 
 func init() {
 	common.RegisterStandardFacade("ModelConfig", 1, newFacade)
 }
 
-func newFacade(st *state.State, _ facade.Resources, auth facade.Authorizer) (*ModelConfigAPI, error) {
-	return NewModelConfigAPI(NewStateBackend(st), auth)
+func newFacade(st *state.State, resources facade.Resources, auth facade.Authorizer) (*ModelConfigAPI, error) {
+	return NewModelConfigAPI(NewStateBackend(st), resources, auth)
 }
 
 // ModelConfigAPI is the endpoint which implements the model config facade.
 type ModelConfigAPI struct {
-	backend Backend
-	auth    facade.Authorizer
-	check   *common.BlockChecker
+	backend   Backend
+	resources facade.Resources
+	auth      facade.Authorizer
+	check     *common.BlockChecker
 }
 
 // NewModelConfigAPI creates a new instance of the ModelConfig Facade.
-func NewModelConfigAPI(backend Backend, authorizer facade.Authorizer) (*ModelConfigAPI, error) {
+func NewModelConfigAPI(backend Backend, resources facade.Resources, authorizer facade.Authorizer) (*ModelConfigAPI, error) {
 	if !authorizer.AuthClient() {
 		return nil, common.ErrPerm
 	}
 	client := &ModelConfigAPI{
-		backend: backend,
-		auth:    authorizer,
-		check:   common.NewBlockChecker(backend),
+		backend:   backend,
+		resources: resources,
+		auth:      authorizer,
+		check:     common.NewBlockChecker(backend),
 	}
 	return client, nil
 }
 
+func (c *ModelConfigAPI) checkCanRead() error {
+	canRead, err := c.auth.HasPermission(permission.ReadAccess, c.backend.ModelTag())
+	if err != nil {
+		return errors.Trace(err)
+	}
+	if !canRead {
+		return common.ErrPerm
+	}
+	return nil
+}
+
 func (c *ModelConfigAPI) checkCanWrite() error {
 	canWrite, err := c.auth.HasPermission(permission.WriteAccess, c.backend.ModelTag())
 	if err != nil {
@@ -115,7 +130,7 @@ func (c *ModelConfigAPI) ModelSet(args params.ModelSet) error {
 	}
 	// Replace any deprecated attributes with their new values.
 	attrs := config.ProcessDeprecatedAttributes(args.Config)
-	return c.backend.UpdateModelConfig(attrs, nil, checkAgentVersion)
+	return c.backend.UpdateModelConfigBy(c.changeSource(), attrs, nil, checkAgentVersion)
 }
 
 // ModelUnset implements the server-side part of the
@@ -127,5 +142,70 @@ func (c *ModelConfigAPI) ModelUnset(args params.ModelUnset) error {
 	if err := c.check.ChangeAllowed(); err != nil {
 		return errors.Trace(err)
 	}
-	return c.backend.UpdateModelConfig(nil, args.Keys, nil)
+	return c.backend.UpdateModelConfigBy(c.changeSource(), nil, args.Keys, nil)
+}
+
+// changeSource returns the source recorded against model config
+// changes made through the API.
+func (c *ModelConfigAPI) changeSource() state.ModelConfigChangeSource {
+	source := state.ModelConfigChangeSource{Source: "api"}
+	if user, ok := c.auth.GetAuthTag().(names.UserTag); ok {
+		source.User = user
+	}
+	return source
+}
+
+// ModelConfigChanges returns the most recent changes made to the
+// model's config, newest first.
+func (c *ModelConfigAPI) ModelConfigChanges(args params.ModelConfigChangesArgs) (params.ModelConfigChangesResult, error) {
+	result := params.ModelConfigChangesResult{}
+	if err := c.checkCanRead(); err != nil {
+		return result, errors.Trace(err)
+	}
+
+	changes, err := c.backend.ModelConfigChanges(args.Limit)
+	if err != nil {
+		return result, errors.Trace(err)
+	}
+	result.Changes = make([]params.ModelConfigChange, len(changes))
+	for i, change := range changes {
+		paramsChange := params.ModelConfigChange{
+			Source:     change.Source,
+			When:       change.When,
+			Attributes: make([]params.ModelConfigAttributeChange, len(change.Changes)),
+		}
+		if change.User != (names.UserTag{}) {
+			paramsChange.UserTag = change.User.String()
+		}
+		for j, attr := range change.Changes {
+			paramsChange.Attributes[j] = params.ModelConfigAttributeChange{
+				Key:      attr.Key,
+				OldValue: attr.OldValue,
+				NewValue: attr.NewValue,
+			}
+		}
+		result.Changes[i] = paramsChange
+	}
+	return result, nil
+}
+
+// WatchModelConfigChanges returns a NotifyWatcher that triggers
+// whenever a change to the model's config is recorded, as returned
+// by ModelConfigChanges.
+func (c *ModelConfigAPI) WatchModelConfigChanges() (params.NotifyWatchResult, error) {
+	result := params.NotifyWatchResult{}
+	if err := c.checkCanRead(); err != nil {
+		return result, errors.Trace(err)
+	}
+	watch := c.backend.WatchModelConfigChangeRecords()
+	// Consume the initial event. Technically, API
+	// calls to Watch 'transmit' the initial event
+	// in the Watch response. But NotifyWatchers
+	// have no state to transmit.
+	if _, ok := <-watch.Changes(); ok {
+		result.NotifyWatcherId = c.resources.Register(watch)
+	} else {
+		return result, watcher.EnsureErr(watch)
+	}
+	return result, nil
 }
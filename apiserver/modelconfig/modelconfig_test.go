@@ -4,16 +4,20 @@
 package modelconfig_test
 
 import (
+	"time"
+
 	"github.com/juju/errors"
 	gitjujutesting "github.com/juju/testing"
 	jc "github.com/juju/testing/checkers"
 	gc "gopkg.in/check.v1"
 	"gopkg.in/juju/names.v2"
 
+	"github.com/juju/juju/apiserver/common"
 	"github.com/juju/juju/apiserver/modelconfig"
 	"github.com/juju/juju/apiserver/params"
 	apiservertesting "github.com/juju/juju/apiserver/testing"
 	"github.com/juju/juju/environs/config"
+	"github.com/juju/juju/permission"
 	"github.com/juju/juju/provider/dummy"
 	_ "github.com/juju/juju/provider/dummy"
 	"github.com/juju/juju/state"
@@ -23,6 +27,7 @@ import (
 type modelconfigSuite struct {
 	gitjujutesting.IsolationSuite
 	backend    *mockBackend
+	resources  *common.Resources
 	authorizer apiservertesting.FakeAuthorizer
 	api        *modelconfig.ModelConfigAPI
 }
@@ -43,8 +48,10 @@ func (s *modelconfigSuite) SetUpTest(c *gc.C) {
 			"authorized-keys": {testing.FakeAuthKeys, "model"},
 		},
 	}
+	s.resources = common.NewResources()
+	s.AddCleanup(func(*gc.C) { s.resources.StopAll() })
 	var err error
-	s.api, err = modelconfig.NewModelConfigAPI(s.backend, &s.authorizer)
+	s.api, err = modelconfig.NewModelConfigAPI(s.backend, s.resources, &s.authorizer)
 	c.Assert(err, jc.ErrorIsNil)
 }
 
@@ -153,11 +160,117 @@ func (s *modelconfigSuite) TestModelUnsetMissing(c *gc.C) {
 	c.Assert(err, jc.ErrorIsNil)
 }
 
+func (s *modelconfigSuite) TestModelSetRecordsSource(c *gc.C) {
+	params := params.ModelSet{
+		Config: map[string]interface{}{"some-key": "value"},
+	}
+	err := s.api.ModelSet(params)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(s.backend.source, jc.DeepEquals, state.ModelConfigChangeSource{
+		User:   names.NewUserTag("bruce@local"),
+		Source: "api",
+	})
+}
+
+func (s *modelconfigSuite) TestModelUnsetRecordsSource(c *gc.C) {
+	err := s.api.ModelUnset(params.ModelUnset{[]string{"ftp-proxy"}})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(s.backend.source, jc.DeepEquals, state.ModelConfigChangeSource{
+		User:   names.NewUserTag("bruce@local"),
+		Source: "api",
+	})
+}
+
+func (s *modelconfigSuite) TestModelConfigChanges(c *gc.C) {
+	when := time.Date(2016, 10, 1, 12, 0, 0, 0, time.UTC)
+	s.backend.changes = []state.ModelConfigChange{{
+		User:   names.NewUserTag("bruce@local"),
+		Source: "api",
+		When:   when,
+		Changes: []state.ModelConfigAttributeChange{
+			{Key: "ftp-proxy", OldValue: "http://proxy", NewValue: nil},
+			{Key: "some-key", OldValue: nil, NewValue: "value"},
+		},
+	}, {
+		When: when.Add(-time.Hour),
+		Changes: []state.ModelConfigAttributeChange{
+			{Key: "http-proxy", OldValue: "a", NewValue: "b"},
+		},
+	}}
+	result, err := s.api.ModelConfigChanges(params.ModelConfigChangesArgs{Limit: 2})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(s.backend.limit, gc.Equals, 2)
+	c.Assert(result, jc.DeepEquals, params.ModelConfigChangesResult{
+		Changes: []params.ModelConfigChange{{
+			UserTag: "user-bruce@local",
+			Source:  "api",
+			When:    when,
+			Attributes: []params.ModelConfigAttributeChange{
+				{Key: "ftp-proxy", OldValue: "http://proxy"},
+				{Key: "some-key", NewValue: "value"},
+			},
+		}, {
+			When: when.Add(-time.Hour),
+			Attributes: []params.ModelConfigAttributeChange{
+				{Key: "http-proxy", OldValue: "a", NewValue: "b"},
+			},
+		}},
+	})
+}
+
+func (s *modelconfigSuite) TestModelConfigChangesPermission(c *gc.C) {
+	s.authorizer.Tag = names.NewUserTag("charlie@local")
+	_, err := s.api.ModelConfigChanges(params.ModelConfigChangesArgs{})
+	c.Assert(err, gc.ErrorMatches, "permission denied")
+}
+
+func (s *modelconfigSuite) TestModelConfigChangesReadAccess(c *gc.C) {
+	auth := readOnlyAuthorizer{apiservertesting.FakeAuthorizer{
+		Tag: names.NewUserTag("charlie@local"),
+	}}
+	api, err := modelconfig.NewModelConfigAPI(s.backend, s.resources, auth)
+	c.Assert(err, jc.ErrorIsNil)
+	_, err = api.ModelConfigChanges(params.ModelConfigChangesArgs{})
+	c.Assert(err, jc.ErrorIsNil)
+}
+
+func (s *modelconfigSuite) TestWatchModelConfigChanges(c *gc.C) {
+	auth := readOnlyAuthorizer{apiservertesting.FakeAuthorizer{
+		Tag: names.NewUserTag("charlie@local"),
+	}}
+	api, err := modelconfig.NewModelConfigAPI(s.backend, s.resources, auth)
+	c.Assert(err, jc.ErrorIsNil)
+	result, err := api.WatchModelConfigChanges()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(result, jc.DeepEquals, params.NotifyWatchResult{NotifyWatcherId: "1"})
+	c.Assert(s.resources.Count(), gc.Equals, 1)
+}
+
+func (s *modelconfigSuite) TestWatchModelConfigChangesPermission(c *gc.C) {
+	s.authorizer.Tag = names.NewUserTag("charlie@local")
+	_, err := s.api.WatchModelConfigChanges()
+	c.Assert(err, gc.ErrorMatches, "permission denied")
+	c.Assert(s.resources.Count(), gc.Equals, 0)
+}
+
+// readOnlyAuthorizer is a FakeAuthorizer that grants
+// only read access.
+type readOnlyAuthorizer struct {
+	apiservertesting.FakeAuthorizer
+}
+
+func (readOnlyAuthorizer) HasPermission(operation permission.Access, target names.Tag) (bool, error) {
+	return operation == permission.ReadAccess, nil
+}
+
 type mockBackend struct {
-	cfg config.ConfigValues
-	old *config.Config
-	b   state.BlockType
-	msg string
+	cfg     config.ConfigValues
+	old     *config.Config
+	b       state.BlockType
+	msg     string
+	source  state.ModelConfigChangeSource
+	changes []state.ModelConfigChange
+	limit   int
 }
 
 func (m *mockBackend) ModelConfigValues() (config.ConfigValues, error) {
@@ -180,6 +293,20 @@ func (m *mockBackend) UpdateModelConfig(update map[string]interface{}, remove []
 	return nil
 }
 
+func (m *mockBackend) UpdateModelConfigBy(source state.ModelConfigChangeSource, update map[string]interface{}, remove []string, validate state.ValidateConfigFunc) error {
+	m.source = source
+	return m.UpdateModelConfig(update, remove, validate)
+}
+
+func (m *mockBackend) ModelConfigChanges(limit int) ([]state.ModelConfigChange, error) {
+	m.limit = limit
+	return m.changes, nil
+}
+
+func (m *mockBackend) WatchModelConfigChangeRecords() state.NotifyWatcher {
+	return apiservertesting.NewFakeNotifyWatcher()
+}
+
 func (m *mockBackend) GetBlockForType(t state.BlockType) (state.Block, bool, error) {
 	if m.b == t {
 		return &mockBlock{t: t, m: m.msg}, true, nil
@@ -0,0 +1,288 @@
+// Copyright 2016 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package observer
+
+import (
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/juju/errors"
+	"github.com/juju/utils/clock"
+	"github.com/prometheus/client_golang/prometheus"
+	"gopkg.in/juju/names.v2"
+
+	"github.com/juju/juju/rpc"
+)
+
+const (
+	metricsNamespace = "juju"
+	metricsSubsystem = "api"
+
+	metricsResultSuccess = "success"
+	metricsResultError   = "error"
+
+	// metricsUnknown is recorded in place of the facade and method
+	// of a call that was not bound to a facade method. The names in
+	// such a call are chosen by the client, so recording them would
+	// let any client create arbitrarily many metric series.
+	metricsUnknown = "unknown"
+)
+
+// APIMetricsResourceName is the name under which an API server's
+// APIMetrics are registered in each connection's resources.
+const APIMetricsResourceName = "apiMetrics"
+
+// APIMetricsConfig holds the configuration for an APIMetrics.
+type APIMetricsConfig struct {
+	// Clock is used to time API calls.
+	Clock clock.Clock
+
+	// SlowCallThreshold is the minimum duration of an API call
+	// for it to be recorded in the slow call log.
+	SlowCallThreshold time.Duration
+
+	// SlowCallLogSize is the maximum number of calls retained in
+	// the slow call log. Once the log is full, the oldest calls
+	// are discarded to make room for new ones.
+	SlowCallLogSize int
+}
+
+// Validate returns an error if the config is not valid.
+func (config APIMetricsConfig) Validate() error {
+	if config.Clock == nil {
+		return errors.NotValidf("nil Clock")
+	}
+	if config.SlowCallThreshold <= 0 {
+		return errors.NotValidf("non-positive SlowCallThreshold")
+	}
+	if config.SlowCallLogSize <= 0 {
+		return errors.NotValidf("non-positive SlowCallLogSize")
+	}
+	return nil
+}
+
+// SlowCall holds the details of an API call that took at least
+// the configured slow call threshold to complete.
+type SlowCall struct {
+	// Time is the time at which the call was received.
+	Time time.Time
+
+	// Duration is the time taken to handle the call.
+	Duration time.Duration
+
+	// Facade, Version and Method identify the method called.
+	Facade  string
+	Version int
+	Method  string
+
+	// ModelUUID is the UUID of the model to which the calling
+	// connection is attached, if known.
+	ModelUUID string
+
+	// Entity is the tag of the entity logged in on the calling
+	// connection, if any.
+	Entity string
+
+	// Error holds the error returned by the call, if any.
+	Error string
+}
+
+// APIMetrics records the number, latency and results of the API calls
+// handled by an API server, by facade method and by model, and keeps
+// a rolling log of slow calls. APIMetrics implements
+// prometheus.Collector, and creates Observers with NewObserver that
+// record the calls made on each connection.
+type APIMetrics struct {
+	config APIMetricsConfig
+
+	calls      *prometheus.CounterVec
+	modelCalls *prometheus.CounterVec
+	latency    *prometheus.HistogramVec
+
+	mu        sync.Mutex
+	slowCalls []SlowCall
+}
+
+// NewAPIMetrics returns a new APIMetrics with the given configuration.
+func NewAPIMetrics(config APIMetricsConfig) (*APIMetrics, error) {
+	if err := config.Validate(); err != nil {
+		return nil, errors.Trace(err)
+	}
+	return &APIMetrics{
+		config: config,
+		calls: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Subsystem: metricsSubsystem,
+			Name:      "calls_total",
+			Help:      "Number of API calls handled, by facade, method and result.",
+		}, []string{"facade", "method", "result"}),
+		modelCalls: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Subsystem: metricsSubsystem,
+			Name:      "model_calls_total",
+			Help:      "Number of API calls handled, by model and result.",
+		}, []string{"model", "result"}),
+		latency: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: metricsNamespace,
+			Subsystem: metricsSubsystem,
+			Name:      "call_duration_seconds",
+			Help:      "Latency of API calls, by facade and method.",
+			Buckets:   []float64{0.001, 0.005, 0.01, 0.05, 0.1, 0.5, 1, 5, 10, 30},
+		}, []string{"facade", "method"}),
+	}, nil
+}
+
+// Describe is part of the prometheus.Collector interface.
+func (m *APIMetrics) Describe(ch chan<- *prometheus.Desc) {
+	m.calls.Describe(ch)
+	m.modelCalls.Describe(ch)
+	m.latency.Describe(ch)
+}
+
+// Collect is part of the prometheus.Collector interface.
+func (m *APIMetrics) Collect(ch chan<- prometheus.Metric) {
+	m.calls.Collect(ch)
+	m.modelCalls.Collect(ch)
+	m.latency.Collect(ch)
+}
+
+// Stop is part of the facade.Resource interface. The metrics are shared
+// by all of an API server's connections, and are registered as a resource
+// of each; Stop therefore does nothing.
+func (m *APIMetrics) Stop() error {
+	return nil
+}
+
+// RemoveModel removes the metrics recorded for calls made to the
+// model with the given UUID. It should be called when the model is
+// removed, so that the metrics of removed models do not accumulate.
+func (m *APIMetrics) RemoveModel(modelUUID string) {
+	m.modelCalls.DeleteLabelValues(modelUUID, metricsResultSuccess)
+	m.modelCalls.DeleteLabelValues(modelUUID, metricsResultError)
+}
+
+// SlowCalls returns the calls in the slow call log, oldest first.
+func (m *APIMetrics) SlowCalls() []SlowCall {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	calls := make([]SlowCall, len(m.slowCalls))
+	copy(calls, m.slowCalls)
+	return calls
+}
+
+// NewObserver returns a new Observer that records the calls made on
+// a single API connection. NewObserver may be used as an
+// ObserverFactory.
+func (m *APIMetrics) NewObserver() Observer {
+	return &metricsObserver{metrics: m}
+}
+
+// observeCall records the result and latency of a call. If the call
+// was not bound to a facade method, its facade, method and model are
+// recorded in the metrics as unknown: a connection to a model that
+// does not exist can only make such calls.
+func (m *APIMetrics) observeCall(call SlowCall, bound bool) {
+	result := metricsResultSuccess
+	if call.Error != "" {
+		result = metricsResultError
+	}
+	facade, method, modelUUID := call.Facade, call.Method, call.ModelUUID
+	if !bound {
+		facade, method, modelUUID = metricsUnknown, metricsUnknown, metricsUnknown
+	}
+	m.calls.WithLabelValues(facade, method, result).Inc()
+	m.modelCalls.WithLabelValues(modelUUID, result).Inc()
+	m.latency.WithLabelValues(facade, method).Observe(call.Duration.Seconds())
+	if call.Duration < m.config.SlowCallThreshold {
+		return
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if len(m.slowCalls) < m.config.SlowCallLogSize {
+		m.slowCalls = append(m.slowCalls, call)
+		return
+	}
+	copy(m.slowCalls, m.slowCalls[1:])
+	m.slowCalls[len(m.slowCalls)-1] = call
+}
+
+// metricsObserver records the calls made on an API connection
+// with an APIMetrics.
+type metricsObserver struct {
+	metrics *APIMetrics
+
+	// mu guards the fields below it, which are updated as the
+	// connection is established and its entity logs in.
+	mu        sync.Mutex
+	modelUUID string
+	entity    string
+}
+
+// Join implements Observer.
+func (o *metricsObserver) Join(req *http.Request, _ uint64) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	// Until the entity logs in, we attribute calls to the model
+	// identified in the connection's URL, if any.
+	o.modelUUID = req.URL.Query().Get(":modeluuid")
+}
+
+// Login implements Observer.
+func (o *metricsObserver) Login(entity names.Tag, model names.ModelTag, _ bool, _ string) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.entity = entity.String()
+	o.modelUUID = model.Id()
+}
+
+// Leave implements Observer.
+func (o *metricsObserver) Leave() {}
+
+// RPCObserver implements Observer.
+func (o *metricsObserver) RPCObserver() rpc.Observer {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return &metricsRPCObserver{
+		metrics:   o.metrics,
+		modelUUID: o.modelUUID,
+		entity:    o.entity,
+	}
+}
+
+// metricsRPCObserver records a single API call with an APIMetrics.
+type metricsRPCObserver struct {
+	metrics      *APIMetrics
+	modelUUID    string
+	entity       string
+	requestStart time.Time
+
+	// bound records whether the request was bound to a facade
+	// method, and its arguments read.
+	bound bool
+}
+
+// ServerRequest implements rpc.Observer.
+func (o *metricsRPCObserver) ServerRequest(_ *rpc.Header, body interface{}) {
+	o.requestStart = o.metrics.config.Clock.Now()
+	// The RPC server observes requests with a nil body
+	// only if they could not be bound to a facade method,
+	// or their arguments could not be read.
+	o.bound = body != nil
+}
+
+// ServerReply implements rpc.Observer.
+func (o *metricsRPCObserver) ServerReply(req rpc.Request, hdr *rpc.Header, _ interface{}) {
+	o.metrics.observeCall(SlowCall{
+		Time:      o.requestStart,
+		Duration:  o.metrics.config.Clock.Now().Sub(o.requestStart),
+		Facade:    req.Type,
+		Version:   req.Version,
+		Method:    req.Action,
+		ModelUUID: o.modelUUID,
+		Entity:    o.entity,
+		Error:     hdr.Error,
+	}, o.bound)
+}
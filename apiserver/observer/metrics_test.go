@@ -0,0 +1,223 @@
+// Copyright 2016 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package observer_test
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/juju/testing"
+	jc "github.com/juju/testing/checkers"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	gc "gopkg.in/check.v1"
+	"gopkg.in/juju/names.v2"
+
+	"github.com/juju/juju/apiserver/observer"
+	"github.com/juju/juju/rpc"
+)
+
+const modelUUID = "deadbeef-0bad-400d-8000-4b1d0d06f00d"
+
+type apiMetricsSuite struct {
+	testing.IsolationSuite
+	clock  *testing.Clock
+	config observer.APIMetricsConfig
+}
+
+var _ = gc.Suite(&apiMetricsSuite{})
+
+func (s *apiMetricsSuite) SetUpTest(c *gc.C) {
+	s.IsolationSuite.SetUpTest(c)
+	s.clock = testing.NewClock(time.Date(2016, 10, 1, 0, 0, 0, 0, time.UTC))
+	s.config = observer.APIMetricsConfig{
+		Clock:             s.clock,
+		SlowCallThreshold: time.Second,
+		SlowCallLogSize:   2,
+	}
+}
+
+func (s *apiMetricsSuite) TestValidateConfig(c *gc.C) {
+	s.testValidateConfig(c, func(config *observer.APIMetricsConfig) {
+		config.Clock = nil
+	}, "nil Clock not valid")
+	s.testValidateConfig(c, func(config *observer.APIMetricsConfig) {
+		config.SlowCallThreshold = 0
+	}, "non-positive SlowCallThreshold not valid")
+	s.testValidateConfig(c, func(config *observer.APIMetricsConfig) {
+		config.SlowCallLogSize = 0
+	}, "non-positive SlowCallLogSize not valid")
+}
+
+func (s *apiMetricsSuite) testValidateConfig(c *gc.C, f func(*observer.APIMetricsConfig), expect string) {
+	config := s.config
+	f(&config)
+	_, err := observer.NewAPIMetrics(config)
+	c.Check(err, gc.ErrorMatches, expect)
+}
+
+// call simulates a call on the connection observed by o, taking d
+// to complete and failing with the given error if it is non-empty.
+func (s *apiMetricsSuite) call(o observer.Observer, facade, method string, d time.Duration, errString string) {
+	req := rpc.Request{Type: facade, Version: 1, Action: method}
+	rpcObserver := o.RPCObserver()
+	rpcObserver.ServerRequest(&rpc.Header{Request: req}, struct{}{})
+	s.clock.Advance(d)
+	rpcObserver.ServerReply(req, &rpc.Header{Request: req, Error: errString}, nil)
+}
+
+func (s *apiMetricsSuite) newObserver(metrics *observer.APIMetrics) observer.Observer {
+	o := metrics.NewObserver()
+	o.Join(&http.Request{URL: &url.URL{RawQuery: ":modeluuid=" + modelUUID}}, 1)
+	return o
+}
+
+func (s *apiMetricsSuite) TestCallMetrics(c *gc.C) {
+	metrics, err := observer.NewAPIMetrics(s.config)
+	c.Assert(err, jc.ErrorIsNil)
+	o := s.newObserver(metrics)
+	s.call(o, "Client", "FullStatus", time.Millisecond, "")
+	s.call(o, "Client", "FullStatus", time.Millisecond, "")
+	s.call(o, "Client", "FullStatus", time.Millisecond, "boom")
+	s.call(o, "Uniter", "Watch", time.Millisecond, "")
+
+	c.Assert(collectCounters(c, metrics, "juju_api_calls_total"), jc.DeepEquals, map[string]float64{
+		"facade=Client,method=FullStatus,result=success": 2,
+		"facade=Client,method=FullStatus,result=error":   1,
+		"facade=Uniter,method=Watch,result=success":      1,
+	})
+	c.Assert(collectCounters(c, metrics, "juju_api_model_calls_total"), jc.DeepEquals, map[string]float64{
+		"model=" + modelUUID + ",result=success": 3,
+		"model=" + modelUUID + ",result=error":   1,
+	})
+}
+
+func (s *apiMetricsSuite) TestCallMetricsUnboundRequest(c *gc.C) {
+	metrics, err := observer.NewAPIMetrics(s.config)
+	c.Assert(err, jc.ErrorIsNil)
+	o := s.newObserver(metrics)
+	s.call(o, "Client", "FullStatus", time.Millisecond, "")
+
+	// The RPC server observes a request that cannot be
+	// bound to a facade method with a nil body.
+	req := rpc.Request{Type: "Made", Version: 1, Action: "Up"}
+	rpcObserver := o.RPCObserver()
+	rpcObserver.ServerRequest(&rpc.Header{Request: req}, nil)
+	rpcObserver.ServerReply(req, &rpc.Header{Request: req, Error: "not implemented"}, struct{}{})
+
+	c.Assert(collectCounters(c, metrics, "juju_api_calls_total"), jc.DeepEquals, map[string]float64{
+		"facade=Client,method=FullStatus,result=success": 1,
+		"facade=unknown,method=unknown,result=error":     1,
+	})
+	c.Assert(collectCounters(c, metrics, "juju_api_model_calls_total"), jc.DeepEquals, map[string]float64{
+		"model=" + modelUUID + ",result=success": 1,
+		"model=unknown,result=error":             1,
+	})
+}
+
+func (s *apiMetricsSuite) TestRemoveModel(c *gc.C) {
+	metrics, err := observer.NewAPIMetrics(s.config)
+	c.Assert(err, jc.ErrorIsNil)
+	o := s.newObserver(metrics)
+	s.call(o, "Client", "FullStatus", time.Millisecond, "")
+	s.call(o, "Client", "FullStatus", time.Millisecond, "boom")
+	o = metrics.NewObserver()
+	o.Join(&http.Request{URL: &url.URL{}}, 2)
+	s.call(o, "Controller", "AllModels", time.Millisecond, "")
+
+	metrics.RemoveModel(modelUUID)
+	c.Assert(collectCounters(c, metrics, "juju_api_model_calls_total"), jc.DeepEquals, map[string]float64{
+		"model=,result=success": 1,
+	})
+	c.Assert(collectCounters(c, metrics, "juju_api_calls_total"), jc.DeepEquals, map[string]float64{
+		"facade=Client,method=FullStatus,result=success":    1,
+		"facade=Client,method=FullStatus,result=error":      1,
+		"facade=Controller,method=AllModels,result=success": 1,
+	})
+}
+
+func (s *apiMetricsSuite) TestCallMetricsModelFromLogin(c *gc.C) {
+	metrics, err := observer.NewAPIMetrics(s.config)
+	c.Assert(err, jc.ErrorIsNil)
+	o := metrics.NewObserver()
+	o.Join(&http.Request{URL: &url.URL{}}, 1)
+	s.call(o, "Admin", "Login", time.Millisecond, "")
+	o.Login(names.NewUserTag("bob"), names.NewModelTag(modelUUID), false, "")
+	s.call(o, "Client", "FullStatus", time.Millisecond, "")
+
+	c.Assert(collectCounters(c, metrics, "juju_api_model_calls_total"), jc.DeepEquals, map[string]float64{
+		"model=,result=success":                  1,
+		"model=" + modelUUID + ",result=success": 1,
+	})
+}
+
+func (s *apiMetricsSuite) TestSlowCalls(c *gc.C) {
+	metrics, err := observer.NewAPIMetrics(s.config)
+	c.Assert(err, jc.ErrorIsNil)
+	o := s.newObserver(metrics)
+	o.Login(names.NewUserTag("bob"), names.NewModelTag(modelUUID), false, "")
+
+	start := s.clock.Now()
+	s.call(o, "Client", "FullStatus", time.Second, "")
+	s.call(o, "Client", "FullStatus", time.Millisecond, "")
+	s.call(o, "Uniter", "Watch", 2*time.Second, "boom")
+	c.Assert(metrics.SlowCalls(), jc.DeepEquals, []observer.SlowCall{{
+		Time:      start,
+		Duration:  time.Second,
+		Facade:    "Client",
+		Version:   1,
+		Method:    "FullStatus",
+		ModelUUID: modelUUID,
+		Entity:    "user-bob",
+	}, {
+		Time:      start.Add(time.Second + time.Millisecond),
+		Duration:  2 * time.Second,
+		Facade:    "Uniter",
+		Version:   1,
+		Method:    "Watch",
+		ModelUUID: modelUUID,
+		Entity:    "user-bob",
+		Error:     "boom",
+	}})
+}
+
+func (s *apiMetricsSuite) TestSlowCallsDiscardsOldest(c *gc.C) {
+	metrics, err := observer.NewAPIMetrics(s.config)
+	c.Assert(err, jc.ErrorIsNil)
+	o := s.newObserver(metrics)
+	s.call(o, "Client", "FullStatus", time.Second, "")
+	s.call(o, "Client", "AddMachines", time.Second, "")
+	s.call(o, "Client", "DestroyMachines", time.Second, "")
+
+	calls := metrics.SlowCalls()
+	c.Assert(calls, gc.HasLen, 2)
+	c.Assert(calls[0].Method, gc.Equals, "AddMachines")
+	c.Assert(calls[1].Method, gc.Equals, "DestroyMachines")
+}
+
+// collectCounters collects the values of the counters with the given
+// name from the collector, keyed by their sorted label pairs.
+func collectCounters(c *gc.C, collector prometheus.Collector, name string) map[string]float64 {
+	ch := make(chan prometheus.Metric)
+	go func() {
+		defer close(ch)
+		collector.Collect(ch)
+	}()
+	result := make(map[string]float64)
+	for metric := range ch {
+		if !strings.Contains(metric.Desc().String(), `fqName: "`+name+`"`) {
+			continue
+		}
+		var m dto.Metric
+		c.Assert(metric.Write(&m), jc.ErrorIsNil)
+		labels := make([]string, len(m.Label))
+		for i, label := range m.Label {
+			labels[i] = label.GetName() + "=" + label.GetValue()
+		}
+		result[strings.Join(labels, ",")] = m.Counter.GetValue()
+	}
+	return result
+}
@@ -5,6 +5,7 @@ package params
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/juju/errors"
 	"gopkg.in/macaroon.v1"
@@ -39,6 +40,16 @@ type ErrorInfo struct {
 	// If it is empty, the macaroon will be associated with
 	// the original URL from which the error was returned.
 	MacaroonPath string `json:"macaroon-path,omitempty"`
+
+	// Retryable reports whether the failed operation is expected
+	// to succeed if it is retried without modification, e.g.
+	// because it depends on another operation that has not yet
+	// completed.
+	Retryable bool `json:"retryable,omitempty"`
+
+	// RetryAfter, if non-zero, holds the minimum amount of time
+	// the client should wait before retrying a retryable operation.
+	RetryAfter time.Duration `json:"retry-after,omitempty"`
 }
 
 func (e Error) Error() string {
@@ -92,6 +103,50 @@ const (
 	CodeRetry                     = "retry"
 )
 
+// retryableCodes holds the codes of errors that are always
+// transient, and so are retryable regardless of ErrorInfo.
+var retryableCodes = map[string]bool{
+	CodeCannotEnterScopeYet: true,
+	CodeExcessiveContention: true,
+	CodeTryAgain:            true,
+	CodeUpgradeInProgress:   true,
+	CodeRetry:               true,
+}
+
+// IsRetryable reports whether the given error, returned by the
+// API server, denotes a transient failure: that is, whether the
+// failed operation is expected to succeed if retried.
+func IsRetryable(err error) bool {
+	if info := errorInfo(err); info != nil && info.Retryable {
+		return true
+	}
+	return retryableCodes[ErrCode(err)]
+}
+
+// RetryAfter returns the minimum amount of time the client should
+// wait before retrying the operation that failed with the given
+// error, or zero if the API server did not specify one.
+func RetryAfter(err error) time.Duration {
+	if info := errorInfo(err); info != nil && info.Retryable {
+		return info.RetryAfter
+	}
+	return 0
+}
+
+// errorInfo returns the ErrorInfo of the given error, or nil if
+// it has none.
+func errorInfo(err error) *ErrorInfo {
+	switch err := errors.Cause(err).(type) {
+	case *Error:
+		if err != nil {
+			return err.Info
+		}
+	case Error:
+		return err.Info
+	}
+	return nil
+}
+
 // ErrCode returns the error code associated with
 // the given error, or the empty string if there
 // is none.
@@ -4,6 +4,8 @@
 package params_test
 
 import (
+	"time"
+
 	"github.com/juju/errors"
 	gc "gopkg.in/check.v1"
 
@@ -25,3 +27,31 @@ func (*errorSuite) TestErrCode(c *gc.C) {
 	err = errors.Trace(err)
 	c.Check(params.ErrCode(err), gc.Equals, params.CodeDead)
 }
+
+func (*errorSuite) TestIsRetryable(c *gc.C) {
+	var err error
+	err = &params.Error{Code: params.CodeNotFound, Message: "not found"}
+	c.Check(params.IsRetryable(err), gc.Equals, false)
+	c.Check(params.RetryAfter(err), gc.Equals, time.Duration(0))
+
+	// Some codes are always retryable.
+	err = &params.Error{Code: params.CodeExcessiveContention, Message: "contention"}
+	c.Check(params.IsRetryable(err), gc.Equals, true)
+	c.Check(params.RetryAfter(err), gc.Equals, time.Duration(0))
+
+	// Others are retryable if the server says so.
+	err = &params.Error{
+		Code:    params.CodeNotProvisioned,
+		Message: "machine 0 not provisioned",
+		Info: &params.ErrorInfo{
+			Retryable:  true,
+			RetryAfter: time.Minute,
+		},
+	}
+	c.Check(params.IsRetryable(err), gc.Equals, true)
+	c.Check(params.RetryAfter(err), gc.Equals, time.Minute)
+
+	err = errors.Trace(err)
+	c.Check(params.IsRetryable(err), gc.Equals, true)
+	c.Check(params.RetryAfter(err), gc.Equals, time.Minute)
+}
@@ -92,6 +92,19 @@ type CharmInfo struct {
 	Meta     *CharmMeta             `json:"meta,omitempty"`
 	Actions  *CharmActions          `json:"actions,omitempty"`
 	Metrics  *CharmMetrics          `json:"metrics,omitempty"`
+
+	// ScanFindings holds the findings of the scans run over
+	// the charm's archive when it was uploaded.
+	ScanFindings []CharmScanFinding `json:"scan-findings,omitempty"`
+}
+
+// CharmScanFinding describes a finding of a scan run over
+// an uploaded charm archive.
+type CharmScanFinding struct {
+	Scanner  string `json:"scanner"`
+	Severity string `json:"severity"`
+	Path     string `json:"path,omitempty"`
+	Message  string `json:"message"`
 }
 
 // CharmActions mirrors charm.Actions.
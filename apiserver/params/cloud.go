@@ -107,3 +107,23 @@ type CloudSpecResult struct {
 type CloudSpecResults struct {
 	Results []CloudSpecResult `json:"results,omitempty"`
 }
+
+// ModelCredential holds the cloud credential used by a model.
+type ModelCredential struct {
+	Model string `json:"model-tag"`
+
+	// CloudCredential is the tag of the model's cloud credential,
+	// or the empty string if the model has no credential.
+	CloudCredential string `json:"credential-tag,omitempty"`
+
+	// Invalid is true if the cloud has rejected the model's
+	// credential. InvalidReason describes why.
+	Invalid       bool   `json:"invalid,omitempty"`
+	InvalidReason string `json:"invalid-reason,omitempty"`
+}
+
+// InvalidateCredentialArg holds the reason that the cloud
+// rejected a model's cloud credential.
+type InvalidateCredentialArg struct {
+	Reason string `json:"reason"`
+}
@@ -87,3 +87,92 @@ const (
 	GrantControllerAccess  ControllerAction = "grant"
 	RevokeControllerAccess ControllerAction = "revoke"
 )
+
+// IntegrityRepairOp describes an operation of a transaction that
+// would repair an integrity problem.
+type IntegrityRepairOp struct {
+	Collection string      `json:"collection"`
+	Id         interface{} `json:"id"`
+	Assert     interface{} `json:"assert,omitempty"`
+	Insert     interface{} `json:"insert,omitempty"`
+	Update     interface{} `json:"update,omitempty"`
+	Remove     bool        `json:"remove,omitempty"`
+}
+
+// IntegrityProblem describes a violation of the referential
+// integrity of a model's documents.
+type IntegrityProblem struct {
+	ID          string              `json:"id"`
+	Kind        string              `json:"kind"`
+	Collection  string              `json:"collection"`
+	DocID       string              `json:"doc-id"`
+	Description string              `json:"description"`
+	Repair      []IntegrityRepairOp `json:"repair,omitempty"`
+}
+
+// IntegrityReport holds the integrity problems found in a model.
+type IntegrityReport struct {
+	ModelTag string             `json:"model-tag"`
+	Problems []IntegrityProblem `json:"problems,omitempty"`
+	Error    *Error             `json:"error,omitempty"`
+}
+
+// IntegrityReports holds the results of checking the
+// integrity of each model in a controller.
+type IntegrityReports struct {
+	Results []IntegrityReport `json:"results"`
+}
+
+// RepairIntegrityArg identifies integrity problems, as reported
+// by CheckIntegrity, to repair in a model.
+type RepairIntegrityArg struct {
+	ModelTag   string   `json:"model-tag"`
+	ProblemIDs []string `json:"problem-ids"`
+}
+
+// RepairIntegrityArgs holds the arguments for the RepairIntegrity
+// call on the Controller facade.
+type RepairIntegrityArgs struct {
+	Args []RepairIntegrityArg `json:"args"`
+}
+
+// RepairIntegrityResult holds the result of repairing each of
+// the integrity problems identified in a RepairIntegrityArg.
+type RepairIntegrityResult struct {
+	Results []ErrorResult `json:"results,omitempty"`
+	Error   *Error        `json:"error,omitempty"`
+}
+
+// RepairIntegrityResults holds the results of a
+// RepairIntegrity call.
+type RepairIntegrityResults struct {
+	Results []RepairIntegrityResult `json:"results"`
+}
+
+// MigrateBlobBackendArgs holds the arguments for the MigrateBlobBackend
+// call on the Controller facade.
+type MigrateBlobBackendArgs struct {
+	// Backend holds the attributes of the backend to which the
+	// controller's blobs are moved, including its "type".
+	Backend map[string]string `json:"backend"`
+}
+
+// ControllerHealth holds the results of the checks that a
+// controller's API server runs against itself.
+type ControllerHealth struct {
+	// FreeDiskSpace is the free space, in MiB, on the disk
+	// holding the API server's data directory.
+	FreeDiskSpace uint64 `json:"free-disk-space"`
+
+	// DiskError holds the error, if any, encountered while
+	// determining the free disk space.
+	DiskError *Error `json:"disk-error,omitempty"`
+
+	// ControllerInstances holds the IDs of the controller
+	// instances, as reported by the provider.
+	ControllerInstances []string `json:"controller-instances,omitempty"`
+
+	// ProviderError holds the error, if any, encountered while
+	// querying the provider for the controller instances.
+	ProviderError *Error `json:"provider-error,omitempty"`
+}
@@ -29,6 +29,11 @@ const (
 
 	// ContentTypeXJS is the outdated HTTP content-type value used for javascript.
 	ContentTypeXJS = "application/x-javascript"
+
+	// ContentTypeToolsDelta is the HTTP content-type value used for
+	// deltas between agent binaries, served in place of a tools
+	// tarball to clients that report the binaries they already have.
+	ContentTypeToolsDelta = "application/x-juju-tools-delta"
 )
 
 // EncodeChecksum base64 encodes a sha256 checksum according to RFC 4648 and
@@ -65,6 +65,11 @@ type CloudImageMetadata struct {
 	// Higher number means higher priority.
 	// This will allow to sort metadata by importance.
 	Priority int `json:"priority"`
+
+	// ModelUUID is the UUID of the model to which the image metadata
+	// is specific, or empty if it is shared by all models. It is set
+	// by the controller, and ignored when saving metadata.
+	ModelUUID string `json:"model-uuid,omitempty"`
 }
 
 // ListCloudImageMetadataResult holds the results of querying cloud image metadata.
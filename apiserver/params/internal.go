@@ -28,6 +28,19 @@ type MachineContainers struct {
 	ContainerTypes []instance.ContainerType `json:"container-types"`
 }
 
+// SetMachineProvisioningErrors holds the arguments for making a
+// SetProvisioningErrors call.
+type SetMachineProvisioningErrors struct {
+	Errors []SetMachineProvisioningError `json:"errors"`
+}
+
+// SetMachineProvisioningError holds the provisioning error to record
+// for a machine.
+type SetMachineProvisioningError struct {
+	MachineTag string                   `json:"machine-tag"`
+	Error      MachineProvisioningError `json:"error"`
+}
+
 // WatchContainer identifies a single container type within a machine.
 type WatchContainer struct {
 	MachineTag    string `json:"machine-tag"`
@@ -373,6 +386,37 @@ type InstancesInfo struct {
 	Machines []InstanceInfo `json:"machines"`
 }
 
+// ReplacementInstanceInfo holds a machine tag and the information about
+// the instance that replaced the machine's previous instance.
+type ReplacementInstanceInfo struct {
+	Tag             string                            `json:"tag"`
+	InstanceId      instance.Id                       `json:"instance-id"`
+	Nonce           string                            `json:"nonce"`
+	Characteristics *instance.HardwareCharacteristics `json:"characteristics"`
+	// VolumeAttachments is a mapping from volume tag to the
+	// info of the volume's attachment to the new instance.
+	VolumeAttachments map[string]VolumeAttachmentInfo `json:"volume-attachments"`
+}
+
+// ReplacementInstancesInfo holds the parameters for making a
+// ReplaceInstances call for multiple machines.
+type ReplacementInstancesInfo struct {
+	Machines []ReplacementInstanceInfo `json:"machines"`
+}
+
+// MachineHardwareCharacteristics holds a machine tag and the updated
+// hardware characteristics of its instance.
+type MachineHardwareCharacteristics struct {
+	Tag             string                           `json:"tag"`
+	Characteristics instance.HardwareCharacteristics `json:"characteristics"`
+}
+
+// SetMachinesHardwareCharacteristics holds the parameters for making
+// a SetHardwareCharacteristics call for multiple machines.
+type SetMachinesHardwareCharacteristics struct {
+	Machines []MachineHardwareCharacteristics `json:"machines"`
+}
+
 // EntityStatus holds the status of an entity.
 type EntityStatus struct {
 	Status status.Status          `json:"status"`
@@ -445,6 +489,84 @@ type ToolsResults struct {
 	Results []ToolsResult `json:"results"`
 }
 
+// ToolsGCArgs holds the retention policy used when garbage
+// collecting agent binaries.
+type ToolsGCArgs struct {
+	// KeepLatest is the number of most recent versions to keep
+	// for each major.minor version.
+	KeepLatest int `json:"keep-latest"`
+}
+
+// AgentBinary describes an agent binary held in tools storage.
+type AgentBinary struct {
+	Version string `json:"version"`
+	Size    int64  `json:"size"`
+	SHA256  string `json:"sha256,omitempty"`
+}
+
+// AgentBinariesResult holds the agent binaries reported or removed
+// by a ToolsGC API call.
+type AgentBinariesResult struct {
+	Binaries []AgentBinary `json:"binaries"`
+}
+
+// PromoteToolsArgs holds the arguments for promoting agent binaries
+// to other streams.
+type PromoteToolsArgs struct {
+	Args []PromoteToolsArg `json:"args"`
+}
+
+// PromoteToolsArg identifies the agent binaries of a version, for all
+// series and architectures, and the stream in which to publish them.
+type PromoteToolsArg struct {
+	Version version.Number `json:"version"`
+	Stream  string         `json:"stream"`
+}
+
+// BucketPolicy describes the lifecycle of the blobs in a bucket.
+// A zero field places no limit.
+type BucketPolicy struct {
+	Bucket      string        `json:"bucket"`
+	MaxAge      time.Duration `json:"max-age,omitempty"`
+	MaxVersions int           `json:"max-versions,omitempty"`
+}
+
+// BucketPolicies holds the lifecycle policies of buckets.
+type BucketPolicies struct {
+	Policies []BucketPolicy `json:"policies"`
+}
+
+// RemovedBlob describes a blob removed under its bucket's policy.
+type RemovedBlob struct {
+	Bucket string `json:"bucket"`
+	Name   string `json:"name"`
+}
+
+// RemovedBlobsResult holds the blobs removed by a BlobJanitor
+// API call.
+type RemovedBlobsResult struct {
+	Removed []RemovedBlob `json:"removed"`
+}
+
+// BucketUsage describes the blobs held in a bucket.
+type BucketUsage struct {
+	Bucket string `json:"bucket"`
+	Blobs  int    `json:"blobs"`
+	Length int64  `json:"length"`
+}
+
+// BucketUsageResult holds the usage of each bucket that holds
+// any blobs.
+type BucketUsageResult struct {
+	Usage []BucketUsage `json:"usage"`
+}
+
+// RelationScopeKeys holds the keys of relation scope memberships,
+// each of which identifies a relation, scope and member unit.
+type RelationScopeKeys struct {
+	Keys []string `json:"keys"`
+}
+
 // Version holds a specific binary version.
 type Version struct {
 	Version version.Binary `json:"version"`
@@ -616,6 +738,11 @@ type ProvisioningInfo struct {
 	ImageMetadata    []CloudImageMetadata      `json:"image-metadata,omitempty"`
 	EndpointBindings map[string]string         `json:"endpoint-bindings,omitempty"`
 	ControllerConfig map[string]interface{}    `json:"controller-config,omitempty"`
+
+	// VolumeAttachments holds the parameters for attaching the
+	// machine's existing volumes to a replacement instance. It is
+	// only set for machines whose instances are to be replaced.
+	VolumeAttachments []VolumeAttachmentParams `json:"volume-attachments,omitempty"`
 }
 
 // ProvisioningInfoResult holds machine provisioning info or an error.
@@ -0,0 +1,45 @@
+// Copyright 2016 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package params
+
+import "time"
+
+// TxnReport holds the result of the controller's "txns" introspection
+// report, describing the transactions that are yet to be completed.
+type TxnReport struct {
+	// Incomplete holds the number of transactions in each of the
+	// incomplete states, keyed by the state's name.
+	Incomplete map[string]int `json:"incomplete"`
+
+	// Queues holds the documents with the longest transaction
+	// queues, longest first.
+	Queues []TxnQueue `json:"queues"`
+}
+
+// TxnQueue describes the transaction queue of a document.
+type TxnQueue struct {
+	Collection string `json:"collection"`
+	Id         string `json:"id"`
+	Length     int    `json:"length"`
+}
+
+// SlowCallsResult holds the result of an APIMetrics.SlowCalls call.
+type SlowCallsResult struct {
+	// Calls holds the calls in the API server's slow call
+	// log, oldest first.
+	Calls []SlowCall `json:"calls"`
+}
+
+// SlowCall describes an API call that took longer than the API
+// server's slow call threshold to complete.
+type SlowCall struct {
+	Time      time.Time     `json:"time"`
+	Duration  time.Duration `json:"duration"`
+	Facade    string        `json:"facade"`
+	Version   int           `json:"version"`
+	Method    string        `json:"method"`
+	ModelUUID string        `json:"model-uuid,omitempty"`
+	Entity    string        `json:"entity,omitempty"`
+	Error     string        `json:"error,omitempty"`
+}
@@ -72,6 +72,44 @@ type ModelUnset struct {
 	Keys []string `json:"keys"`
 }
 
+// ModelConfigChangesArgs contains the arguments for the
+// ModelConfigChanges client API call.
+type ModelConfigChangesArgs struct {
+	// Limit is the maximum number of changes to return.
+	// If zero, all recorded changes are returned.
+	Limit int `json:"limit,omitempty"`
+}
+
+// ModelConfigChangesResult contains the result of the
+// ModelConfigChanges client API call.
+type ModelConfigChangesResult struct {
+	// Changes holds the model's config changes, newest first.
+	Changes []ModelConfigChange `json:"changes"`
+}
+
+// ModelConfigChange records a change to a model's config.
+type ModelConfigChange struct {
+	// UserTag is the tag of the user that made the change, if any.
+	UserTag string `json:"user-tag,omitempty"`
+
+	// Source describes how the change was made.
+	Source string `json:"source,omitempty"`
+
+	// When is the time at which the change was made.
+	When time.Time `json:"when"`
+
+	// Attributes holds the changed attributes.
+	Attributes []ModelConfigAttributeChange `json:"attributes"`
+}
+
+// ModelConfigAttributeChange records a change to the value of a
+// single model config attribute.
+type ModelConfigAttributeChange struct {
+	Key      string      `json:"key"`
+	OldValue interface{} `json:"old-value,omitempty"`
+	NewValue interface{} `json:"new-value,omitempty"`
+}
+
 // SetModelDefaults contains the arguments for SetModelDefaults
 // client API call.
 type SetModelDefaults struct {
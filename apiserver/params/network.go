@@ -418,6 +418,33 @@ type MachinePorts struct {
 	SubnetTag  string `json:"subnet-tag"`
 }
 
+// UnitPortRange holds a single port range opened by a unit.
+type UnitPortRange struct {
+	UnitTag   string    `json:"unit-tag"`
+	PortRange PortRange `json:"port-range"`
+}
+
+// ExposureIntent holds the recorded exposure intent of an application:
+// whether it should be exposed, and the port ranges opened by its units
+// that should be made accessible when it is.
+type ExposureIntent struct {
+	Exposed    bool            `json:"exposed"`
+	PortRanges []UnitPortRange `json:"port-ranges"`
+}
+
+// ExposureIntentResult holds the exposure intent of an application,
+// or an error.
+type ExposureIntentResult struct {
+	Result *ExposureIntent `json:"result,omitempty"`
+	Error  *Error          `json:"error,omitempty"`
+}
+
+// ExposureIntentResults holds the results of calling an API method
+// returning the exposure intents of applications.
+type ExposureIntentResults struct {
+	Results []ExposureIntentResult `json:"results"`
+}
+
 // -----
 // API request / response types.
 // -----
@@ -200,6 +200,51 @@ type AddMachinesResult struct {
 	Error   *Error `json:"error,omitempty"`
 }
 
+// PreviewInstanceSpecs holds the parameters for making the
+// PreviewInstanceSpecs call.
+type PreviewInstanceSpecs struct {
+	Specs []PreviewInstanceSpec `json:"specs"`
+}
+
+// PreviewInstanceSpec holds the parameters of a machine whose
+// instance is to be previewed.
+type PreviewInstanceSpec struct {
+	// Series is the series of the machine. If empty, the
+	// model's default series is used.
+	Series string `json:"series,omitempty"`
+
+	// Constraints are the machine's constraints, which are
+	// merged with the model's constraints.
+	Constraints constraints.Value `json:"constraints"`
+
+	// If Placement is non-nil, it contains a placement directive
+	// that will be used to decide how to instantiate the machine.
+	Placement *instance.Placement `json:"placement,omitempty"`
+}
+
+// InstanceSpecPreview describes the instance that the provider
+// would start for a machine.
+type InstanceSpecPreview struct {
+	InstanceType     string `json:"instance-type"`
+	Image            string `json:"image"`
+	Arch             string `json:"arch"`
+	Cost             uint64 `json:"cost,omitempty"`
+	AvailabilityZone string `json:"availability-zone,omitempty"`
+}
+
+// InstanceSpecPreviewResults holds the results of a
+// PreviewInstanceSpecs call.
+type InstanceSpecPreviewResults struct {
+	Results []InstanceSpecPreviewResult `json:"results"`
+}
+
+// InstanceSpecPreviewResult holds the preview of a single
+// machine's instance, or an error.
+type InstanceSpecPreviewResult struct {
+	Result *InstanceSpecPreview `json:"result,omitempty"`
+	Error  *Error               `json:"error,omitempty"`
+}
+
 // DestroyMachines holds parameters for the DestroyMachines call.
 type DestroyMachines struct {
 	MachineNames []string `json:"machine-names"`
@@ -328,6 +373,19 @@ type ApplicationUnexpose struct {
 	ApplicationName string `json:"application"`
 }
 
+// ApplicationRename holds parameters for the application Rename call.
+type ApplicationRename struct {
+	ApplicationName string `json:"application"`
+	NewName         string `json:"new-name"`
+}
+
+// ApplicationSetScale holds parameters for the application SetScale call.
+// If Scale is nil, the application's desired scale is unset.
+type ApplicationSetScale struct {
+	ApplicationName string `json:"application"`
+	Scale           *int   `json:"scale,omitempty"`
+}
+
 // ApplicationMetricCredential holds parameters for the SetApplicationCredentials call.
 type ApplicationMetricCredential struct {
 	ApplicationName   string `json:"application"`
@@ -456,6 +514,41 @@ type SetConstraints struct {
 	Constraints     constraints.Value `json:"constraints"`
 }
 
+// ConstraintsVocabulary holds the constraint values accepted by a model.
+type ConstraintsVocabulary struct {
+	// Vocabulary holds the allowed values for each constraint
+	// attribute that has a restricted set of values.
+	Vocabulary map[string][]interface{} `json:"vocabulary,omitempty"`
+
+	// Conflicts holds, for each constraint attribute that conflicts
+	// with others, the names of the conflicting attributes.
+	Conflicts map[string][]string `json:"conflicts,omitempty"`
+
+	// Unsupported holds the names of the constraint attributes
+	// that are ignored by the model's provider.
+	Unsupported []string `json:"unsupported,omitempty"`
+}
+
+// ValidateConstraintsArgs holds the arguments for the
+// ValidateConstraints call.
+type ValidateConstraintsArgs struct {
+	Constraints []constraints.Value `json:"constraints"`
+}
+
+// ValidateConstraintsResult holds the result of validating a
+// constraints value: the names of any unsupported attributes in
+// the value, or an error if the value is not valid.
+type ValidateConstraintsResult struct {
+	Unsupported []string `json:"unsupported,omitempty"`
+	Error       *Error   `json:"error,omitempty"`
+}
+
+// ValidateConstraintsResults holds the results of the
+// ValidateConstraints call.
+type ValidateConstraintsResults struct {
+	Results []ValidateConstraintsResult `json:"results"`
+}
+
 // ResolveCharms stores charm references for a ResolveCharms call.
 type ResolveCharms struct {
 	References []string `json:"references"`
@@ -476,11 +569,32 @@ type ResolveCharmResults struct {
 // AllWatcherId holds the id of an AllWatcher.
 type AllWatcherId struct {
 	AllWatcherId string `json:"watcher-id"`
+
+	// SessionToken, if non-empty, identifies a resumable session
+	// for the watcher. A client that loses its connection may pass
+	// the token to ResumeWatchAll or ResumeWatchAllModels to
+	// continue receiving deltas from where it left off.
+	SessionToken string `json:"session-token,omitempty"`
 }
 
 // AllWatcherNextResults holds deltas returned from calling AllWatcher.Next().
 type AllWatcherNextResults struct {
 	Deltas []multiwatcher.Delta `json:"deltas"`
+
+	// Sequence is the sequence number of the deltas within the
+	// watcher's session, or zero if the watcher is not resumable.
+	Sequence int64 `json:"sequence,omitempty"`
+}
+
+// ResumeAllWatcher holds the arguments for resuming an AllWatcher
+// session.
+type ResumeAllWatcher struct {
+	// SessionToken is the token of the session to resume.
+	SessionToken string `json:"session-token"`
+
+	// Sequence is the sequence number of the last deltas that the
+	// client received. Deltas after this will be replayed.
+	Sequence int64 `json:"sequence"`
 }
 
 // ListSSHKeys stores parameters used for a KeyManager.ListKeys call.
@@ -680,6 +794,11 @@ type LoginResult struct {
 	// ServerVersion is the string representation of the server version
 	// if the server supports it.
 	ServerVersion string `json:"server-version,omitempty"`
+
+	// CanDelegate reports whether the authenticated entity may make
+	// requests over the connection on behalf of other entities.
+	// Servers that do not support such requests never set it.
+	CanDelegate bool `json:"can-delegate,omitempty"`
 }
 
 // ControllersServersSpec contains arguments for
@@ -888,3 +1007,17 @@ type MacaroonResult struct {
 	Result *macaroon.Macaroon `json:"result,omitempty"`
 	Error  *Error             `json:"error,omitempty"`
 }
+
+// ProviderResource describes a cloud resource that the provider
+// created for a model.
+type ProviderResource struct {
+	Type string            `json:"type"`
+	Id   string            `json:"id"`
+	Tags map[string]string `json:"tags,omitempty"`
+}
+
+// ProviderResourcesResult holds the result of a
+// ListProviderResources or SweepMachineResources call.
+type ProviderResourcesResult struct {
+	Resources []ProviderResource `json:"resources"`
+}
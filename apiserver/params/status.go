@@ -15,6 +15,22 @@ import (
 // StatusParams holds parameters for the Status call.
 type StatusParams struct {
 	Patterns []string `json:"patterns"`
+
+	// ExcludeMachines, ExcludeApplications and ExcludeRelations
+	// omit the model's machines, applications (and so units), and
+	// relations respectively from the status, so that clients need
+	// not pay for the parts of the status that they do not render.
+	ExcludeMachines     bool `json:"exclude-machines,omitempty"`
+	ExcludeApplications bool `json:"exclude-applications,omitempty"`
+	ExcludeRelations    bool `json:"exclude-relations,omitempty"`
+
+	// UnitsOffset and UnitsLimit select a page of each principal
+	// application's units, ordered by unit number. The first
+	// UnitsOffset units are skipped, and at most UnitsLimit units
+	// are returned; if UnitsLimit is zero, all of the remaining
+	// units are returned.
+	UnitsOffset int `json:"units-offset,omitempty"`
+	UnitsLimit  int `json:"units-limit,omitempty"`
 }
 
 // TODO(ericsnow) Add FullStatusResult.
@@ -35,6 +51,10 @@ type ModelStatusInfo struct {
 	Version          string `json:"version"`
 	AvailableVersion string `json:"available-version"`
 	Migration        string `json:"migration,omitempty"`
+
+	// Features holds the Juju features that the model's provider
+	// supports, if the provider declares them.
+	Features []string `json:"features,omitempty"`
 }
 
 // MachineStatus holds status info about a machine.
@@ -51,6 +71,27 @@ type MachineStatus struct {
 	Jobs       []multiwatcher.MachineJob `json:"jobs"`
 	HasVote    bool                      `json:"has-vote"`
 	WantsVote  bool                      `json:"wants-vote"`
+
+	// ProvisioningError holds the reason that the machine could
+	// not be provisioned, if it could not.
+	ProvisioningError *MachineProvisioningError `json:"provisioning-error,omitempty"`
+}
+
+// MachineProvisioningError holds the reason that the provisioner was
+// unable to start an instance for a machine.
+type MachineProvisioningError struct {
+	// Kind classifies the failure, e.g. "quota" or "auth".
+	Kind string `json:"kind"`
+
+	// Message is the error message reported by the provisioner.
+	Message string `json:"message"`
+
+	// Attempts is the number of attempts that were made to
+	// start an instance.
+	Attempts int `json:"attempts"`
+
+	// Since is the time at which the error was recorded.
+	Since *time.Time `json:"since,omitempty"`
 }
 
 // ApplicationStatus holds status info about an application.
@@ -67,6 +108,10 @@ type ApplicationStatus struct {
 	MeterStatuses   map[string]MeterStatus `json:"meter-statuses"`
 	Status          DetailedStatus         `json:"status"`
 	WorkloadVersion string                 `json:"workload-version"`
+
+	// UnitCount is the total number of the application's units,
+	// when only a page of them is reported in Units.
+	UnitCount int `json:"unit-count,omitempty"`
 }
 
 // MeterStatus represents the meter status of a unit.
@@ -162,6 +162,18 @@ type MachineStorageIds struct {
 	Ids []MachineStorageId `json:"ids"`
 }
 
+// StorageClaim identifies a volume or filesystem, and the storage
+// provisioner scope (model or machine) claiming or releasing it.
+type StorageClaim struct {
+	EntityTag string `json:"entity-tag"`
+	OwnerTag  string `json:"owner-tag"`
+}
+
+// StorageClaims holds a set of storage claims.
+type StorageClaims struct {
+	Claims []StorageClaim `json:"claims"`
+}
+
 // Volume identifies and describes a storage volume in the model.
 type Volume struct {
 	VolumeTag string     `json:"volume-tag"`
@@ -221,6 +233,10 @@ type VolumeAttachmentParams struct {
 	InstanceId string `json:"instance-id,omitempty"`
 	Provider   string `json:"provider"`
 	ReadOnly   bool   `json:"read-only,omitempty"`
+
+	// DeleteOnTermination indicates that the volume should be
+	// deleted when the instance it is attached to is terminated.
+	DeleteOnTermination bool `json:"delete-on-termination,omitempty"`
 }
 
 // VolumeAttachmentsResult holds the volume attachments for a single
@@ -668,3 +684,72 @@ type StorageAddParams struct {
 type StoragesAddParams struct {
 	Storages []StorageAddParams `json:"storages"`
 }
+
+// StoragePlan describes the operations that the storage provisioner
+// would perform to provision a pending volume or filesystem.
+type StoragePlan struct {
+	// Tag is the tag of the volume or filesystem.
+	Tag string `json:"tag"`
+
+	// Create reports whether or not the volume or filesystem is
+	// yet to be created.
+	Create bool `json:"create"`
+
+	// Pool is the name of the storage pool from which the volume
+	// or filesystem is provisioned.
+	Pool string `json:"pool"`
+
+	// Provider is the type of the storage provider that will
+	// provision the volume or filesystem.
+	Provider string `json:"provider"`
+
+	// Size is the size of the volume or filesystem, in MiB.
+	Size uint64 `json:"size"`
+
+	// VolumeTag is the tag of the volume backing a filesystem,
+	// if any.
+	VolumeTag string `json:"volume-tag,omitempty"`
+
+	// Waiting describes what the creation of the volume or
+	// filesystem is waiting for before it can proceed, if anything.
+	Waiting string `json:"waiting,omitempty"`
+
+	// Attachments describes the attachments that are yet to be
+	// made.
+	Attachments []StorageAttachmentPlan `json:"attachments,omitempty"`
+}
+
+// StorageAttachmentPlan describes an attachment of a volume or
+// filesystem that the storage provisioner would make.
+type StorageAttachmentPlan struct {
+	// MachineTag is the tag of the machine to attach to.
+	MachineTag string `json:"machine-tag"`
+
+	// InstanceId is the ID of the machine's instance, if the
+	// machine has been provisioned.
+	InstanceId string `json:"instance-id,omitempty"`
+
+	// ReadOnly reports whether or not the attachment is read-only.
+	ReadOnly bool `json:"read-only"`
+
+	// WithCreate reports whether or not the attachment is made as
+	// part of creating the volume or filesystem.
+	WithCreate bool `json:"with-create,omitempty"`
+
+	// Waiting describes what the attachment is waiting for before
+	// it can be made, if anything.
+	Waiting string `json:"waiting,omitempty"`
+}
+
+// StoragePlanResult holds the plan for a volume or filesystem,
+// or an error.
+type StoragePlanResult struct {
+	Result *StoragePlan `json:"result,omitempty"`
+	Error  *Error       `json:"error,omitempty"`
+}
+
+// StoragePlanResults holds the plans for a collection of volumes
+// and filesystems.
+type StoragePlanResults struct {
+	Results []StoragePlanResult `json:"results"`
+}
@@ -0,0 +1,88 @@
+// Copyright 2016 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+// Package providerresources implements the API endpoint for listing
+// the cloud resources that the provider created for a model.
+package providerresources
+
+import (
+	"github.com/juju/errors"
+	"gopkg.in/juju/names.v2"
+
+	"github.com/juju/juju/apiserver/common"
+	"github.com/juju/juju/apiserver/facade"
+	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/environs"
+	"github.com/juju/juju/permission"
+	"github.com/juju/juju/state"
+	"github.com/juju/juju/state/stateenvirons"
+)
+
+func init() {
+	common.RegisterStandardFacade("ProviderResources", 1, newFacade)
+}
+
+// Backend defines the State API used by the providerresources facade.
+type Backend interface {
+	ModelTag() names.ModelTag
+}
+
+// NewEnvironFunc is the type of a function that returns the
+// model's Environ.
+type NewEnvironFunc func() (environs.Environ, error)
+
+// Facade implements the ProviderResources API.
+type Facade struct {
+	backend    Backend
+	newEnviron NewEnvironFunc
+	authorizer facade.Authorizer
+}
+
+func newFacade(st *state.State, _ facade.Resources, authorizer facade.Authorizer) (*Facade, error) {
+	newEnviron := func() (environs.Environ, error) {
+		return stateenvirons.GetNewEnvironFunc(environs.New)(st)
+	}
+	return New(st, newEnviron, authorizer)
+}
+
+// New returns a new ProviderResources API facade.
+func New(backend Backend, newEnviron NewEnvironFunc, authorizer facade.Authorizer) (*Facade, error) {
+	if !authorizer.AuthClient() {
+		return nil, common.ErrPerm
+	}
+	return &Facade{
+		backend:    backend,
+		newEnviron: newEnviron,
+		authorizer: authorizer,
+	}, nil
+}
+
+// ListProviderResources returns the cloud resources that the provider
+// created for the model. Only model administrators may list them.
+func (f *Facade) ListProviderResources() (params.ProviderResourcesResult, error) {
+	var result params.ProviderResourcesResult
+	isModelAdmin, err := f.authorizer.HasPermission(permission.AdminAccess, f.backend.ModelTag())
+	if err != nil {
+		return result, errors.Trace(err)
+	}
+	if !isModelAdmin {
+		return result, common.ErrPerm
+	}
+	env, err := f.newEnviron()
+	if err != nil {
+		return result, errors.Annotate(err, "opening environ")
+	}
+	resources, err := environs.RemainingResources(env)
+	if err != nil {
+		return result, errors.Trace(err)
+	}
+	result.Resources = make([]params.ProviderResource, len(resources))
+	for i, resource := range resources {
+		result.Resources[i] = params.ProviderResource{
+			Type: resource.Type,
+			Id:   resource.Id,
+			Tags: resource.Tags,
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,115 @@
+// Copyright 2016 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package providerresources_test
+
+import (
+	"github.com/juju/errors"
+	jujutesting "github.com/juju/testing"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+	"gopkg.in/juju/names.v2"
+
+	"github.com/juju/juju/apiserver/common"
+	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/apiserver/providerresources"
+	apiservertesting "github.com/juju/juju/apiserver/testing"
+	"github.com/juju/juju/environs"
+	coretesting "github.com/juju/juju/testing"
+)
+
+type providerResourcesSuite struct {
+	jujutesting.IsolationSuite
+	backend    mockBackend
+	env        *mockEnviron
+	authorizer apiservertesting.FakeAuthorizer
+	facade     *providerresources.Facade
+}
+
+var _ = gc.Suite(&providerResourcesSuite{})
+
+func (s *providerResourcesSuite) SetUpTest(c *gc.C) {
+	s.IsolationSuite.SetUpTest(c)
+	s.env = &mockEnviron{}
+	s.authorizer = apiservertesting.FakeAuthorizer{
+		Tag:      names.NewUserTag("admin"),
+		AdminTag: names.NewUserTag("admin"),
+	}
+	var err error
+	s.facade, err = providerresources.New(s.backend, s.newEnviron, &s.authorizer)
+	c.Assert(err, jc.ErrorIsNil)
+}
+
+func (s *providerResourcesSuite) newEnviron() (environs.Environ, error) {
+	return s.env, nil
+}
+
+func (s *providerResourcesSuite) TestNewNotClient(c *gc.C) {
+	s.authorizer.Tag = names.NewMachineTag("0")
+	_, err := providerresources.New(s.backend, s.newEnviron, &s.authorizer)
+	c.Assert(err, gc.Equals, common.ErrPerm)
+}
+
+func (s *providerResourcesSuite) TestListProviderResources(c *gc.C) {
+	s.env.resources = []environs.Resource{{
+		Type: "vm",
+		Id:   "machine-0",
+		Tags: map[string]string{"juju-model-uuid": coretesting.ModelTag.Id()},
+	}, {
+		Type: "disk",
+		Id:   "machine-0-root",
+	}}
+	result, err := s.facade.ListProviderResources()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(result, jc.DeepEquals, params.ProviderResourcesResult{
+		Resources: []params.ProviderResource{{
+			Type: "vm",
+			Id:   "machine-0",
+			Tags: map[string]string{"juju-model-uuid": coretesting.ModelTag.Id()},
+		}, {
+			Type: "disk",
+			Id:   "machine-0-root",
+		}},
+	})
+}
+
+func (s *providerResourcesSuite) TestListProviderResourcesError(c *gc.C) {
+	s.env.err = errors.New("boom")
+	_, err := s.facade.ListProviderResources()
+	c.Assert(err, gc.ErrorMatches, "enumerating resources: boom")
+}
+
+func (s *providerResourcesSuite) TestListProviderResourcesNotSupported(c *gc.C) {
+	facade, err := providerresources.New(s.backend, func() (environs.Environ, error) {
+		return plainEnviron{}, nil
+	}, &s.authorizer)
+	c.Assert(err, jc.ErrorIsNil)
+	_, err = facade.ListProviderResources()
+	c.Assert(err, jc.Satisfies, errors.IsNotSupported)
+}
+
+func (s *providerResourcesSuite) TestListProviderResourcesNotModelAdmin(c *gc.C) {
+	s.authorizer.Tag = names.NewUserTag("bob")
+	_, err := s.facade.ListProviderResources()
+	c.Assert(err, gc.Equals, common.ErrPerm)
+}
+
+type mockBackend struct{}
+
+func (mockBackend) ModelTag() names.ModelTag {
+	return coretesting.ModelTag
+}
+
+type plainEnviron struct {
+	environs.Environ
+}
+
+type mockEnviron struct {
+	environs.Environ
+	resources []environs.Resource
+	err       error
+}
+
+func (env *mockEnviron) AllResources() ([]environs.Resource, error) {
+	return env.resources, env.err
+}
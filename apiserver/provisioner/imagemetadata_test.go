@@ -119,6 +119,72 @@ func (s *ImageMetadataSuite) TestMetadataFromState(c *gc.C) {
 	s.assertImageMetadataResults(c, result, expected...)
 }
 
+func (s *ImageMetadataSuite) TestMetadataFromStateModelSpecific(c *gc.C) {
+	api, err := provisioner.NewProvisionerAPI(s.State, s.resources, s.authorizer)
+	c.Assert(err, jc.ErrorIsNil)
+
+	shared := s.expectedDataSoureImageMetadata()
+	expected := s.expectedDataSoureImageMetadata()
+	for _, ms := range expected {
+		for i := range ms {
+			ms[i].ImageId = "custom-" + ms[i].ImageId
+			ms[i].ModelUUID = s.State.ModelUUID()
+		}
+	}
+
+	// Write shared metadata, and metadata specific to this
+	// and another model, to state.
+	metadata := s.convertCloudImageMetadata(shared[0])
+	for _, m := range s.convertCloudImageMetadata(expected[0]) {
+		metadata = append(metadata, m)
+		m.ModelUUID = "another-model"
+		m.ImageId = "other-" + m.ImageId
+		metadata = append(metadata, m)
+	}
+	for _, m := range metadata {
+		err := s.State.CloudImageMetadataStorage.SaveMetadata(
+			[]cloudimagemetadata.Metadata{m},
+		)
+		c.Assert(err, jc.ErrorIsNil)
+	}
+
+	// Only the metadata specific to this model is used.
+	result, err := api.ProvisioningInfo(s.getTestMachinesTags(c))
+	c.Assert(err, jc.ErrorIsNil)
+
+	s.assertImageMetadataResults(c, result, expected...)
+}
+
+func (s *ImageMetadataSuite) TestMetadataFromStateModelSpecificOverridesOnlySameImage(c *gc.C) {
+	api, err := provisioner.NewProvisionerAPI(s.State, s.resources, s.authorizer)
+	c.Assert(err, jc.ErrorIsNil)
+
+	shared := s.expectedDataSoureImageMetadata()
+	metadata := s.convertCloudImageMetadata(shared[0])
+
+	// Override the image in only one of the regions.
+	custom := metadata[1]
+	custom.ImageId = "custom-" + custom.ImageId
+	custom.ModelUUID = s.State.ModelUUID()
+	metadata = append(metadata, custom)
+	for _, m := range metadata {
+		err := s.State.CloudImageMetadataStorage.SaveMetadata(
+			[]cloudimagemetadata.Metadata{m},
+		)
+		c.Assert(err, jc.ErrorIsNil)
+	}
+
+	result, err := api.ProvisioningInfo(s.getTestMachinesTags(c))
+	c.Assert(err, jc.ErrorIsNil)
+
+	expected := s.expectedDataSoureImageMetadata()
+	for i := range expected {
+		expected[i][1].ImageId = custom.ImageId
+		expected[i][1].ModelUUID = custom.ModelUUID
+	}
+	s.assertImageMetadataResults(c, result, expected...)
+}
+
 func (s *ImageMetadataSuite) getTestMachinesTags(c *gc.C) params.Entities {
 
 	testMachines := make([]params.Entity, len(s.machines))
@@ -142,6 +208,7 @@ func (s *ImageMetadataSuite) convertCloudImageMetadata(all []params.CloudImageMe
 				RootStorageType: one.RootStorageType,
 				Source:          one.Source,
 				Stream:          one.Stream,
+				ModelUUID:       one.ModelUUID,
 			},
 			one.Priority,
 			one.ImageId,
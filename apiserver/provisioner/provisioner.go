@@ -8,6 +8,7 @@ import (
 
 	"github.com/juju/errors"
 	"github.com/juju/loggo"
+	"github.com/juju/utils/clock"
 	"github.com/juju/utils/set"
 	"gopkg.in/juju/names.v2"
 
@@ -107,7 +108,14 @@ func NewProvisionerAPI(st *state.State, resources facade.Resources, authorizer f
 	if err != nil {
 		return nil, err
 	}
-	urlGetter := common.NewToolsURLGetter(model.UUID(), st)
+	// Tools URLs are signed, so that provisioned machines can
+	// download their tools without embedding agent credentials
+	// in their user-data.
+	urlSigner, err := common.NewStateToolsURLSigner(st, clock.WallClock)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	urlGetter := common.NewSignedToolsURLGetter(model.UUID(), st, urlSigner)
 	storageProviderRegistry := stateenvirons.NewStorageProviderRegistry(env)
 	return &ProvisionerAPI{
 		Remover:                 common.NewRemover(st, false, getAuthFunc),
@@ -284,11 +292,10 @@ func (p *ProvisionerAPI) MachinesWithTransientErrors() (params.StatusResults, er
 		return results, err
 	}
 	// TODO (wallyworld) - add state.State API for more efficient machines query
-	machines, err := p.st.AllMachines()
-	if err != nil {
-		return results, err
-	}
-	for _, machine := range machines {
+	iter := p.st.MachinesIter(0)
+	defer iter.Close()
+	for iter.Next() {
+		machine := iter.Machine()
 		if !canAccessFunc(machine.Tag()) {
 			continue
 		}
@@ -316,6 +323,9 @@ func (p *ProvisionerAPI) MachinesWithTransientErrors() (params.StatusResults, er
 		result.Life = params.Life(machine.Life().String())
 		results.Results = append(results.Results, result)
 	}
+	if err := iter.Close(); err != nil {
+		return params.StatusResults{}, err
+	}
 	return results, nil
 }
 
@@ -504,6 +514,92 @@ func (p *ProvisionerAPI) SetInstanceInfo(args params.InstancesInfo) (params.Erro
 	return result, nil
 }
 
+// MachinesToReplace returns the IDs of the machines whose instances have
+// been requested to be replaced.
+func (p *ProvisionerAPI) MachinesToReplace() (params.StringsResult, error) {
+	var result params.StringsResult
+	canAccess, err := p.getAuthFunc()
+	if err != nil {
+		return result, errors.Trace(err)
+	}
+	ids, err := p.st.MachinesToReplace()
+	if err != nil {
+		return result, errors.Trace(err)
+	}
+	for _, id := range ids {
+		if canAccess(names.NewMachineTag(id)) {
+			result.Result = append(result.Result, id)
+		}
+	}
+	return result, nil
+}
+
+// ReplaceInstances records, for each given machine, the instance that
+// replaced the machine's previous instance.
+func (p *ProvisionerAPI) ReplaceInstances(args params.ReplacementInstancesInfo) (params.ErrorResults, error) {
+	result := params.ErrorResults{
+		Results: make([]params.ErrorResult, len(args.Machines)),
+	}
+	canAccess, err := p.getAuthFunc()
+	if err != nil {
+		return result, err
+	}
+	replaceInstance := func(arg params.ReplacementInstanceInfo) error {
+		tag, err := names.ParseMachineTag(arg.Tag)
+		if err != nil {
+			return common.ErrPerm
+		}
+		machine, err := p.getMachine(canAccess, tag)
+		if err != nil {
+			return err
+		}
+		volumeAttachments, err := storagecommon.VolumeAttachmentInfosToState(arg.VolumeAttachments)
+		if err != nil {
+			return err
+		}
+		return machine.ReplaceInstance(
+			arg.InstanceId, arg.Nonce, arg.Characteristics, volumeAttachments,
+		)
+	}
+	for i, arg := range args.Machines {
+		err := replaceInstance(arg)
+		result.Results[i].Error = common.ServerError(err)
+	}
+	return result, nil
+}
+
+// SetProvisioningErrors records, for each given machine, the reason
+// that the provisioner was unable to start an instance for it.
+func (p *ProvisionerAPI) SetProvisioningErrors(args params.SetMachineProvisioningErrors) (params.ErrorResults, error) {
+	result := params.ErrorResults{
+		Results: make([]params.ErrorResult, len(args.Errors)),
+	}
+	canAccess, err := p.getAuthFunc()
+	if err != nil {
+		return result, err
+	}
+	setProvisioningError := func(arg params.SetMachineProvisioningError) error {
+		tag, err := names.ParseMachineTag(arg.MachineTag)
+		if err != nil {
+			return common.ErrPerm
+		}
+		machine, err := p.getMachine(canAccess, tag)
+		if err != nil {
+			return err
+		}
+		return machine.SetProvisioningError(state.MachineProvisioningError{
+			Kind:     arg.Error.Kind,
+			Message:  arg.Error.Message,
+			Attempts: arg.Error.Attempts,
+		})
+	}
+	for i, arg := range args.Errors {
+		err := setProvisioningError(arg)
+		result.Results[i].Error = common.ServerError(err)
+	}
+	return result, nil
+}
+
 // WatchMachineErrorRetry returns a NotifyWatcher that notifies when
 // the provisioner should retry provisioning machines with transient errors.
 func (p *ProvisionerAPI) WatchMachineErrorRetry() (params.NotifyWatchResult, error) {
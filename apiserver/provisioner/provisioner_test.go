@@ -854,6 +854,108 @@ func (s *withoutControllerSuite) TestConstraints(c *gc.C) {
 	})
 }
 
+func (s *withoutControllerSuite) TestSetProvisioningErrors(c *gc.C) {
+	args := params.SetMachineProvisioningErrors{Errors: []params.SetMachineProvisioningError{{
+		MachineTag: s.machines[0].Tag().String(),
+		Error: params.MachineProvisioningError{
+			Kind:     "quota",
+			Message:  "cores quota exhausted",
+			Attempts: 3,
+		},
+	}, {
+		MachineTag: "machine-42",
+		Error:      params.MachineProvisioningError{Kind: "auth"},
+	}, {
+		MachineTag: "application-bar",
+		Error:      params.MachineProvisioningError{Kind: "auth"},
+	}}}
+	results, err := s.provisioner.SetProvisioningErrors(args)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(results, gc.DeepEquals, params.ErrorResults{
+		Results: []params.ErrorResult{
+			{nil},
+			{apiservertesting.NotFoundError("machine 42")},
+			{apiservertesting.ErrUnauthorized},
+		},
+	})
+
+	perr, err := s.machines[0].ProvisioningError()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(perr.Kind, gc.Equals, "quota")
+	c.Assert(perr.Message, gc.Equals, "cores quota exhausted")
+	c.Assert(perr.Attempts, gc.Equals, 3)
+}
+
+func (s *withoutControllerSuite) TestMachinesToReplace(c *gc.C) {
+	for _, m := range s.machines[1:3] {
+		err := m.SetProvisioned(instance.Id("i-"+m.Id()), "fake_nonce", nil)
+		c.Assert(err, jc.ErrorIsNil)
+		err = m.RequestReplacement()
+		c.Assert(err, jc.ErrorIsNil)
+	}
+	result, err := s.provisioner.MachinesToReplace()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(result, jc.DeepEquals, params.StringsResult{
+		Result: []string{"1", "2"},
+	})
+}
+
+func (s *withoutControllerSuite) TestReplaceInstances(c *gc.C) {
+	err := s.machines[0].SetProvisioned("i-0", "fake_nonce", nil)
+	c.Assert(err, jc.ErrorIsNil)
+	err = s.machines[0].RequestReplacement()
+	c.Assert(err, jc.ErrorIsNil)
+	err = s.machines[1].SetProvisioned("i-1", "fake_nonce", nil)
+	c.Assert(err, jc.ErrorIsNil)
+
+	hwChars := instance.MustParseHardware("arch=amd64", "mem=4G")
+	args := params.ReplacementInstancesInfo{Machines: []params.ReplacementInstanceInfo{{
+		Tag:             s.machines[0].Tag().String(),
+		InstanceId:      "i-0-new",
+		Nonce:           "new_nonce",
+		Characteristics: &hwChars,
+	}, {
+		Tag:        s.machines[1].Tag().String(),
+		InstanceId: "i-1-new",
+		Nonce:      "new_nonce",
+	}, {
+		Tag:        "machine-42",
+		InstanceId: "i-42",
+		Nonce:      "new_nonce",
+	}, {
+		Tag:        "application-bar",
+		InstanceId: "i-bar",
+		Nonce:      "new_nonce",
+	}}}
+	results, err := s.provisioner.ReplaceInstances(args)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(results, gc.DeepEquals, params.ErrorResults{
+		Results: []params.ErrorResult{
+			{nil},
+			{&params.Error{
+				Message: `cannot replace instance of machine 1: replacement request not found`,
+				Code:    params.CodeNotFound,
+			}},
+			{apiservertesting.NotFoundError("machine 42")},
+			{apiservertesting.ErrUnauthorized},
+		},
+	})
+
+	err = s.machines[0].Refresh()
+	c.Assert(err, jc.ErrorIsNil)
+	instanceId, err := s.machines[0].InstanceId()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(instanceId, gc.Equals, instance.Id("i-0-new"))
+	c.Assert(s.machines[0].CheckProvisioned("new_nonce"), jc.IsTrue)
+	gotHardware, err := s.machines[0].HardwareCharacteristics()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(gotHardware, gc.DeepEquals, &hwChars)
+
+	instanceId, err = s.machines[1].InstanceId()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(instanceId, gc.Equals, instance.Id("i-1"))
+}
+
 func (s *withoutControllerSuite) TestSetInstanceInfo(c *gc.C) {
 	pm := poolmanager.New(state.NewStateSettings(s.State), dummy.StorageProviders())
 	_, err := pm.Create("static-pool", "static", map[string]interface{}{"foo": "bar"})
@@ -1232,7 +1334,8 @@ func (s *withoutControllerSuite) TestFindTools(c *gc.C) {
 	for _, tools := range result.List {
 		url := fmt.Sprintf("https://%s/model/%s/tools/%s",
 			s.APIState.Addr(), coretesting.ModelTag.Id(), tools.Version)
-		c.Assert(tools.URL, gc.Equals, url)
+		c.Assert(tools.URL, jc.HasPrefix, url+"?")
+		c.Assert(tools.URL, gc.Matches, `.*\?expires=[0-9]+&signature=[0-9a-f]{64}`)
 	}
 }
 
@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"sort"
 	"strings"
+	"time"
 
 	"github.com/juju/errors"
 	"github.com/juju/utils/series"
@@ -57,7 +58,20 @@ func (p *ProvisionerAPI) getProvisioningInfo(m *state.Machine) (*params.Provisio
 		return nil, errors.Trace(err)
 	}
 
-	volumes, err := p.machineVolumeParams(m)
+	// The volumes of a machine whose instance is to be replaced
+	// have already been provisioned, and are to be attached to the
+	// replacement instance.
+	var volumes []params.VolumeParams
+	var volumeAttachments []params.VolumeAttachmentParams
+	replace, err := m.ReplacementRequested()
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	if replace {
+		volumeAttachments, err = p.machineVolumeAttachmentParams(m)
+	} else {
+		volumes, err = p.machineVolumeParams(m)
+	}
 	if err != nil {
 		return nil, errors.Trace(err)
 	}
@@ -91,16 +105,17 @@ func (p *ProvisionerAPI) getProvisioningInfo(m *state.Machine) (*params.Provisio
 	}
 
 	return &params.ProvisioningInfo{
-		Constraints:      cons,
-		Series:           m.Series(),
-		Placement:        m.Placement(),
-		Jobs:             jobs,
-		Volumes:          volumes,
-		Tags:             tags,
-		SubnetsToZones:   subnetsToZones,
-		EndpointBindings: endpointBindings,
-		ImageMetadata:    imageMetadata,
-		ControllerConfig: controllerCfg,
+		Constraints:       cons,
+		Series:            m.Series(),
+		Placement:         m.Placement(),
+		Jobs:              jobs,
+		Volumes:           volumes,
+		Tags:              tags,
+		SubnetsToZones:    subnetsToZones,
+		EndpointBindings:  endpointBindings,
+		ImageMetadata:     imageMetadata,
+		ControllerConfig:  controllerCfg,
+		VolumeAttachments: volumeAttachments,
 	}, nil
 }
 
@@ -168,12 +183,58 @@ func (p *ProvisionerAPI) machineVolumeParams(m *state.Machine) ([]params.VolumeP
 			"", // we're creating the machine, so it has no instance ID.
 			volumeParams.Provider,
 			volumeAttachmentParams.ReadOnly,
+			storagecommon.VolumeDeleteOnTermination(volume, m.MachineTag()),
 		}
 		allVolumeParams = append(allVolumeParams, volumeParams)
 	}
 	return allVolumeParams, nil
 }
 
+// machineVolumeAttachmentParams retrieves VolumeAttachmentParams for the
+// provisioned volumes attached to the machine, so that they may be
+// attached to a replacement for the machine's instance. Attachments that
+// have not been provisioned are left to the storage provisioner.
+func (p *ProvisionerAPI) machineVolumeAttachmentParams(m *state.Machine) ([]params.VolumeAttachmentParams, error) {
+	volumeAttachments, err := m.VolumeAttachments()
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	var allAttachmentParams []params.VolumeAttachmentParams
+	for _, volumeAttachment := range volumeAttachments {
+		volumeAttachmentInfo, err := volumeAttachment.Info()
+		if errors.IsNotProvisioned(err) {
+			continue
+		} else if err != nil {
+			return nil, errors.Trace(err)
+		}
+		volumeTag := volumeAttachment.Volume()
+		volume, err := p.st.Volume(volumeTag)
+		if err != nil {
+			return nil, errors.Annotatef(err, "getting volume %q", volumeTag.Id())
+		}
+		volumeInfo, err := volume.Info()
+		if err != nil {
+			return nil, errors.Annotatef(err, "getting volume %q info", volumeTag.Id())
+		}
+		providerType, _, err := storagecommon.StoragePoolConfig(
+			volumeInfo.Pool, p.storagePoolManager, p.storageProviderRegistry,
+		)
+		if err != nil {
+			return nil, errors.Annotatef(err, "getting volume %q storage provider", volumeTag.Id())
+		}
+		allAttachmentParams = append(allAttachmentParams, params.VolumeAttachmentParams{
+			volumeTag.String(),
+			m.Tag().String(),
+			volumeInfo.VolumeId,
+			"", // the replacement instance has no ID yet.
+			string(providerType),
+			volumeAttachmentInfo.ReadOnly,
+			storagecommon.VolumeDeleteOnTermination(volume, m.MachineTag()),
+		})
+	}
+	return allAttachmentParams, nil
+}
+
 // machineTags returns machine-specific tags to set on the instance.
 func (p *ProvisionerAPI) machineTags(m *state.Machine, jobs []multiwatcher.MachineJob) (map[string]string, error) {
 	// Names of all units deployed to the machine.
@@ -419,6 +480,11 @@ func (p *ProvisionerAPI) obtainEnvCloudConfig() (*simplestreams.CloudSpec, envir
 	return nil, env, nil
 }
 
+// imageMetadataRetryDelay is the amount of time that a client is
+// told to wait before retrying, when image metadata could not be
+// read from the data sources.
+const imageMetadataRetryDelay = 30 * time.Second
+
 // findImageMetadata returns all image metadata or an error fetching them.
 // It looks for image metadata in state.
 // If none are found, we fall back on original image search in simple streams.
@@ -443,7 +509,9 @@ func (p *ProvisionerAPI) findImageMetadata(imageConstraint *imagemetadata.ImageC
 	dsMetadata, err := p.imageMetadataFromDataSources(env, imageConstraint)
 	if err != nil {
 		if !errors.IsNotFound(err) {
-			return nil, errors.Trace(err)
+			// The data sources are typically remote, so
+			// failing to read them is likely to be transient.
+			return nil, common.RetryableError(errors.Trace(err), imageMetadataRetryDelay)
 		}
 	}
 	logger.Debugf("got from data sources %d metadata", len(dsMetadata))
@@ -452,13 +520,16 @@ func (p *ProvisionerAPI) findImageMetadata(imageConstraint *imagemetadata.ImageC
 }
 
 // imageMetadataFromState returns image metadata stored in state
-// that matches given criteria.
+// that matches given criteria. Metadata specific to the model
+// overrides metadata shared by all models for the same series,
+// architecture, region and virtualisation type.
 func (p *ProvisionerAPI) imageMetadataFromState(constraint *imagemetadata.ImageConstraint) ([]params.CloudImageMetadata, error) {
 	filter := cloudimagemetadata.MetadataFilter{
-		Series: constraint.Series,
-		Arches: constraint.Arches,
-		Region: constraint.Region,
-		Stream: constraint.Stream,
+		Series:    constraint.Series,
+		Arches:    constraint.Arches,
+		Region:    constraint.Region,
+		Stream:    constraint.Stream,
+		ModelUUID: p.st.ModelUUID(),
 	}
 	stored, err := p.st.CloudImageMetadataStorage.FindMetadata(filter)
 	if err != nil {
@@ -478,12 +549,31 @@ func (p *ProvisionerAPI) imageMetadataFromState(constraint *imagemetadata.ImageC
 			RootStorageSize: m.RootStorageSize,
 			Source:          m.Source,
 			Priority:        m.Priority,
+			ModelUUID:       m.ModelUUID,
+		}
+	}
+
+	type imageKey struct {
+		series, arch, region, virtType string
+	}
+	keyOf := func(m cloudimagemetadata.Metadata) imageKey {
+		return imageKey{m.Series, m.Arch, m.Region, m.VirtType}
+	}
+	overridden := make(map[imageKey]bool)
+	for _, ms := range stored {
+		for _, m := range ms {
+			if m.ModelUUID != "" {
+				overridden[keyOf(m)] = true
+			}
 		}
 	}
 
 	var all []params.CloudImageMetadata
 	for _, ms := range stored {
 		for _, m := range ms {
+			if m.ModelUUID == "" && overridden[keyOf(m)] {
+				continue
+			}
 			all = append(all, toParams(m))
 		}
 	}
@@ -513,6 +603,12 @@ func (p *ProvisionerAPI) imageMetadataFromDataSources(env environs.Environ, cons
 			Priority: priority,
 			ImageId:  m.Id,
 		}
+		if m.MinRootDisk > 0 {
+			// Root storage size is recorded in GB, so round
+			// up to avoid understating the image's requirement.
+			size := (m.MinRootDisk + 1023) / 1024
+			result.RootStorageSize = &size
+		}
 		// TODO (anastasiamac 2016-08-24) This is a band-aid solution.
 		// Once correct value is read from simplestreams, this needs to go.
 		// Bug# 1616295
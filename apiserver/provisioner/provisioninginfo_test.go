@@ -6,6 +6,7 @@ package provisioner_test
 import (
 	jc "github.com/juju/testing/checkers"
 	gc "gopkg.in/check.v1"
+	"gopkg.in/juju/names.v2"
 
 	"github.com/juju/juju/apiserver/params"
 	"github.com/juju/juju/apiserver/provisioner"
@@ -81,9 +82,10 @@ func (s *withoutControllerSuite) TestProvisioningInfoWithStorage(c *gc.C) {
 						tags.JujuModel:      coretesting.ModelTag.Id(),
 					},
 					Attachment: &params.VolumeAttachmentParams{
-						MachineTag: placementMachine.Tag().String(),
-						VolumeTag:  "volume-0",
-						Provider:   "static",
+						MachineTag:          placementMachine.Tag().String(),
+						VolumeTag:           "volume-0",
+						Provider:            "static",
+						DeleteOnTermination: true,
 					},
 				}, {
 					VolumeTag:  "volume-1",
@@ -95,9 +97,10 @@ func (s *withoutControllerSuite) TestProvisioningInfoWithStorage(c *gc.C) {
 						tags.JujuModel:      coretesting.ModelTag.Id(),
 					},
 					Attachment: &params.VolumeAttachmentParams{
-						MachineTag: placementMachine.Tag().String(),
-						VolumeTag:  "volume-1",
-						Provider:   "static",
+						MachineTag:          placementMachine.Tag().String(),
+						VolumeTag:           "volume-1",
+						Provider:            "static",
+						DeleteOnTermination: true,
 					},
 				}},
 			}},
@@ -113,6 +116,56 @@ func (s *withoutControllerSuite) TestProvisioningInfoWithStorage(c *gc.C) {
 	c.Assert(result, jc.DeepEquals, expected)
 }
 
+func (s *withoutControllerSuite) TestProvisioningInfoWithReplacement(c *gc.C) {
+	pm := poolmanager.New(state.NewStateSettings(s.State), dummy.StorageProviders())
+	_, err := pm.Create("static-pool", "static", map[string]interface{}{"foo": "bar"})
+	c.Assert(err, jc.ErrorIsNil)
+
+	template := state.MachineTemplate{
+		Series: "quantal",
+		Jobs:   []state.MachineJob{state.JobHostUnits},
+		Volumes: []state.MachineVolumeParams{
+			{Volume: state.VolumeParams{Size: 1000, Pool: "static-pool"}},
+			{Volume: state.VolumeParams{Size: 2000, Pool: "static-pool"}},
+		},
+	}
+	machine, err := s.State.AddOneMachine(template)
+	c.Assert(err, jc.ErrorIsNil)
+	err = machine.SetProvisioned("inst-0", "nonce", nil)
+	c.Assert(err, jc.ErrorIsNil)
+
+	// Only volume-0 has been attached; volume-1's attachment
+	// is left to the storage provisioner.
+	err = s.State.SetVolumeInfo(names.NewVolumeTag("0"), state.VolumeInfo{VolumeId: "vol-0"})
+	c.Assert(err, jc.ErrorIsNil)
+	err = s.State.SetVolumeInfo(names.NewVolumeTag("1"), state.VolumeInfo{VolumeId: "vol-1"})
+	c.Assert(err, jc.ErrorIsNil)
+	err = s.State.SetVolumeAttachmentInfo(
+		machine.MachineTag(), names.NewVolumeTag("0"),
+		state.VolumeAttachmentInfo{DeviceName: "sdc", ReadOnly: true},
+	)
+	c.Assert(err, jc.ErrorIsNil)
+	err = machine.RequestReplacement()
+	c.Assert(err, jc.ErrorIsNil)
+
+	args := params.Entities{Entities: []params.Entity{
+		{Tag: machine.Tag().String()},
+	}}
+	result, err := s.provisioner.ProvisioningInfo(args)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(result.Results, gc.HasLen, 1)
+	c.Assert(result.Results[0].Error, gc.IsNil)
+	c.Assert(result.Results[0].Result.Volumes, gc.HasLen, 0)
+	c.Assert(result.Results[0].Result.VolumeAttachments, jc.DeepEquals, []params.VolumeAttachmentParams{{
+		VolumeTag:           "volume-0",
+		MachineTag:          machine.Tag().String(),
+		VolumeId:            "vol-0",
+		Provider:            "static",
+		ReadOnly:            true,
+		DeleteOnTermination: true,
+	}})
+}
+
 func (s *withoutControllerSuite) TestProvisioningInfoWithSingleNegativeAndPositiveSpaceInConstraints(c *gc.C) {
 	s.addSpacesAndSubnets(c)
 
@@ -314,9 +367,10 @@ func (s *withoutControllerSuite) TestStorageProviderFallbackToType(c *gc.C) {
 						tags.JujuModel:      coretesting.ModelTag.Id(),
 					},
 					Attachment: &params.VolumeAttachmentParams{
-						MachineTag: placementMachine.Tag().String(),
-						VolumeTag:  "volume-1",
-						Provider:   "static",
+						MachineTag:          placementMachine.Tag().String(),
+						VolumeTag:           "volume-1",
+						Provider:            "static",
+						DeleteOnTermination: true,
 					},
 				}},
 			}},
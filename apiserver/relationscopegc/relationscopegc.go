@@ -0,0 +1,66 @@
+// Copyright 2016 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+// Package relationscopegc implements the API endpoint for removing
+// relation scope memberships left behind by units that are gone.
+package relationscopegc
+
+import (
+	"github.com/juju/errors"
+
+	"github.com/juju/juju/apiserver/common"
+	"github.com/juju/juju/apiserver/facade"
+	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/state"
+)
+
+func init() {
+	common.RegisterStandardFacade("RelationScopeGC", 1, newFacade)
+}
+
+// Backend defines the State API used by the relationscopegc facade.
+type Backend interface {
+	StaleRelationScopes() ([]string, error)
+	RemoveStaleRelationScope(key string) error
+}
+
+// Facade implements the RelationScopeGC API, which is available only
+// to the controller.
+type Facade struct {
+	backend Backend
+}
+
+func newFacade(st *state.State, _ facade.Resources, authorizer facade.Authorizer) (*Facade, error) {
+	return New(st, authorizer)
+}
+
+// New returns a new RelationScopeGC API facade.
+func New(backend Backend, authorizer facade.Authorizer) (*Facade, error) {
+	if !authorizer.AuthModelManager() {
+		return nil, common.ErrPerm
+	}
+	return &Facade{backend: backend}, nil
+}
+
+// StaleRelationScopes returns the keys of the relation scope
+// memberships whose units, or the units' machines, are gone.
+func (f *Facade) StaleRelationScopes() (params.RelationScopeKeys, error) {
+	keys, err := f.backend.StaleRelationScopes()
+	if err != nil {
+		return params.RelationScopeKeys{}, errors.Trace(err)
+	}
+	return params.RelationScopeKeys{Keys: keys}, nil
+}
+
+// RemoveStaleRelationScopes removes the specified relation scope
+// memberships, each of which must still be stale.
+func (f *Facade) RemoveStaleRelationScopes(args params.RelationScopeKeys) (params.ErrorResults, error) {
+	results := params.ErrorResults{
+		Results: make([]params.ErrorResult, len(args.Keys)),
+	}
+	for i, key := range args.Keys {
+		err := f.backend.RemoveStaleRelationScope(key)
+		results.Results[i].Error = common.ServerError(err)
+	}
+	return results, nil
+}
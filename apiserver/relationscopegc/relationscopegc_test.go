@@ -0,0 +1,100 @@
+// Copyright 2016 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package relationscopegc_test
+
+import (
+	"github.com/juju/errors"
+	jujutesting "github.com/juju/testing"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+	"gopkg.in/juju/names.v2"
+
+	"github.com/juju/juju/apiserver/common"
+	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/apiserver/relationscopegc"
+	apiservertesting "github.com/juju/juju/apiserver/testing"
+)
+
+type relationScopeGCSuite struct {
+	jujutesting.IsolationSuite
+	backend    mockBackend
+	authorizer apiservertesting.FakeAuthorizer
+}
+
+var _ = gc.Suite(&relationScopeGCSuite{})
+
+func (s *relationScopeGCSuite) SetUpTest(c *gc.C) {
+	s.IsolationSuite.SetUpTest(c)
+	s.backend = mockBackend{
+		keys: []string{"r#0#peer#riak/0", "r#1#mysql/0#provider#logging/0"},
+	}
+	s.authorizer = apiservertesting.FakeAuthorizer{
+		Tag:            names.NewMachineTag("0"),
+		EnvironManager: true,
+	}
+}
+
+func (s *relationScopeGCSuite) newFacade(c *gc.C) *relationscopegc.Facade {
+	facade, err := relationscopegc.New(&s.backend, &s.authorizer)
+	c.Assert(err, jc.ErrorIsNil)
+	return facade
+}
+
+func (s *relationScopeGCSuite) TestNewNotAuthorized(c *gc.C) {
+	s.authorizer = apiservertesting.FakeAuthorizer{
+		Tag:      names.NewUserTag("admin"),
+		AdminTag: names.NewUserTag("admin"),
+	}
+	_, err := relationscopegc.New(&s.backend, &s.authorizer)
+	c.Assert(err, gc.Equals, common.ErrPerm)
+}
+
+func (s *relationScopeGCSuite) TestStaleRelationScopes(c *gc.C) {
+	result, err := s.newFacade(c).StaleRelationScopes()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(result, jc.DeepEquals, params.RelationScopeKeys{
+		Keys: []string{"r#0#peer#riak/0", "r#1#mysql/0#provider#logging/0"},
+	})
+	s.backend.CheckCallNames(c, "StaleRelationScopes")
+}
+
+func (s *relationScopeGCSuite) TestStaleRelationScopesError(c *gc.C) {
+	s.backend.SetErrors(errors.New("boom"))
+	_, err := s.newFacade(c).StaleRelationScopes()
+	c.Assert(err, gc.ErrorMatches, "boom")
+}
+
+func (s *relationScopeGCSuite) TestRemoveStaleRelationScopes(c *gc.C) {
+	s.backend.SetErrors(nil, errors.NotValidf("removing relation scope of live unit %q", "riak/1"))
+	result, err := s.newFacade(c).RemoveStaleRelationScopes(params.RelationScopeKeys{
+		Keys: []string{"r#0#peer#riak/0", "r#0#peer#riak/1"},
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(result, jc.DeepEquals, params.ErrorResults{
+		Results: []params.ErrorResult{{}, {
+			Error: &params.Error{
+				Message: `removing relation scope of live unit "riak/1" not valid`,
+			},
+		}},
+	})
+	s.backend.CheckCalls(c, []jujutesting.StubCall{
+		{"RemoveStaleRelationScope", []interface{}{"r#0#peer#riak/0"}},
+		{"RemoveStaleRelationScope", []interface{}{"r#0#peer#riak/1"}},
+	})
+}
+
+type mockBackend struct {
+	jujutesting.Stub
+	keys []string
+}
+
+func (b *mockBackend) StaleRelationScopes() ([]string, error) {
+	b.MethodCall(b, "StaleRelationScopes")
+	return b.keys, b.NextErr()
+}
+
+func (b *mockBackend) RemoveStaleRelationScope(key string) error {
+	b.MethodCall(b, "RemoveStaleRelationScope", key)
+	return b.NextErr()
+}
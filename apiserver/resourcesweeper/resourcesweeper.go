@@ -0,0 +1,97 @@
+// Copyright 2016 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+// Package resourcesweeper implements the API endpoint for deleting
+// the cloud resources left behind by machines that no longer exist.
+package resourcesweeper
+
+import (
+	"github.com/juju/errors"
+
+	"github.com/juju/juju/apiserver/common"
+	"github.com/juju/juju/apiserver/facade"
+	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/environs"
+	"github.com/juju/juju/state"
+	"github.com/juju/juju/state/stateenvirons"
+)
+
+func init() {
+	common.RegisterStandardFacade("ResourceSweeper", 1, newFacade)
+}
+
+// Backend defines the State API used by the resourcesweeper facade.
+type Backend interface {
+	// AllMachineIds returns the IDs of all machines in the model,
+	// whatever their life.
+	AllMachineIds() ([]string, error)
+}
+
+// NewEnvironFunc is the type of a function that returns the
+// model's Environ.
+type NewEnvironFunc func() (environs.Environ, error)
+
+// Facade implements the ResourceSweeper API.
+type Facade struct {
+	backend    Backend
+	newEnviron NewEnvironFunc
+}
+
+func newFacade(st *state.State, _ facade.Resources, authorizer facade.Authorizer) (*Facade, error) {
+	newEnviron := func() (environs.Environ, error) {
+		return stateenvirons.GetNewEnvironFunc(environs.New)(st)
+	}
+	return New(stateShim{st}, newEnviron, authorizer)
+}
+
+// New returns a new ResourceSweeper API facade.
+func New(backend Backend, newEnviron NewEnvironFunc, authorizer facade.Authorizer) (*Facade, error) {
+	if !authorizer.AuthModelManager() {
+		return nil, common.ErrPerm
+	}
+	return &Facade{
+		backend:    backend,
+		newEnviron: newEnviron,
+	}, nil
+}
+
+// SweepMachineResources deletes the unused cloud resources that the
+// provider created for machines that are no longer in the model, and
+// returns the deleted resources. If the provider does not support
+// sweeping, an error satisfying errors.IsNotSupported is returned.
+func (f *Facade) SweepMachineResources() (params.ProviderResourcesResult, error) {
+	var result params.ProviderResourcesResult
+	env, err := f.newEnviron()
+	if err != nil {
+		return result, errors.Annotate(err, "opening environ")
+	}
+	resources, err := environs.SweepMachineResources(env, f.backend.AllMachineIds)
+	if err != nil {
+		return result, errors.Trace(err)
+	}
+	result.Resources = make([]params.ProviderResource, len(resources))
+	for i, resource := range resources {
+		result.Resources[i] = params.ProviderResource{
+			Type: resource.Type,
+			Id:   resource.Id,
+			Tags: resource.Tags,
+		}
+	}
+	return result, nil
+}
+
+type stateShim struct {
+	st *state.State
+}
+
+func (s stateShim) AllMachineIds() ([]string, error) {
+	machines, err := s.st.AllMachines()
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	ids := make([]string, len(machines))
+	for i, m := range machines {
+		ids[i] = m.Id()
+	}
+	return ids, nil
+}
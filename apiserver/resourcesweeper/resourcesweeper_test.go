@@ -0,0 +1,126 @@
+// Copyright 2016 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package resourcesweeper_test
+
+import (
+	"github.com/juju/errors"
+	jujutesting "github.com/juju/testing"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+	"gopkg.in/juju/names.v2"
+
+	"github.com/juju/juju/apiserver/common"
+	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/apiserver/resourcesweeper"
+	apiservertesting "github.com/juju/juju/apiserver/testing"
+	"github.com/juju/juju/environs"
+)
+
+type resourceSweeperSuite struct {
+	jujutesting.IsolationSuite
+	backend    mockBackend
+	env        *mockEnviron
+	authorizer apiservertesting.FakeAuthorizer
+	facade     *resourcesweeper.Facade
+}
+
+var _ = gc.Suite(&resourceSweeperSuite{})
+
+func (s *resourceSweeperSuite) SetUpTest(c *gc.C) {
+	s.IsolationSuite.SetUpTest(c)
+	s.backend = mockBackend{machineIds: []string{"0", "1"}}
+	s.env = &mockEnviron{}
+	s.authorizer = apiservertesting.FakeAuthorizer{
+		Tag:            names.NewMachineTag("0"),
+		EnvironManager: true,
+	}
+	var err error
+	s.facade, err = resourcesweeper.New(&s.backend, s.newEnviron, &s.authorizer)
+	c.Assert(err, jc.ErrorIsNil)
+}
+
+func (s *resourceSweeperSuite) newEnviron() (environs.Environ, error) {
+	return s.env, nil
+}
+
+func (s *resourceSweeperSuite) TestNewNotModelManager(c *gc.C) {
+	s.authorizer.EnvironManager = false
+	_, err := resourcesweeper.New(&s.backend, s.newEnviron, &s.authorizer)
+	c.Assert(err, gc.Equals, common.ErrPerm)
+}
+
+func (s *resourceSweeperSuite) TestNewClient(c *gc.C) {
+	s.authorizer.Tag = names.NewUserTag("admin")
+	s.authorizer.EnvironManager = false
+	_, err := resourcesweeper.New(&s.backend, s.newEnviron, &s.authorizer)
+	c.Assert(err, gc.Equals, common.ErrPerm)
+}
+
+func (s *resourceSweeperSuite) TestSweepMachineResources(c *gc.C) {
+	s.env.resources = []environs.Resource{{
+		Type: "nic",
+		Id:   "machine-2-primary",
+		Tags: map[string]string{"juju-machine-name": "machine-2"},
+	}}
+	result, err := s.facade.SweepMachineResources()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(result, jc.DeepEquals, params.ProviderResourcesResult{
+		Resources: []params.ProviderResource{{
+			Type: "nic",
+			Id:   "machine-2-primary",
+			Tags: map[string]string{"juju-machine-name": "machine-2"},
+		}},
+	})
+	c.Assert(s.env.live, jc.DeepEquals, []string{"0", "1"})
+}
+
+func (s *resourceSweeperSuite) TestSweepMachineResourcesError(c *gc.C) {
+	s.env.err = errors.New("boom")
+	_, err := s.facade.SweepMachineResources()
+	c.Assert(err, gc.ErrorMatches, "sweeping machine resources: boom")
+}
+
+func (s *resourceSweeperSuite) TestSweepMachineResourcesBackendError(c *gc.C) {
+	s.backend.err = errors.New("boom")
+	_, err := s.facade.SweepMachineResources()
+	c.Assert(err, gc.ErrorMatches, "sweeping machine resources: boom")
+}
+
+func (s *resourceSweeperSuite) TestSweepMachineResourcesNotSupported(c *gc.C) {
+	facade, err := resourcesweeper.New(&s.backend, func() (environs.Environ, error) {
+		return plainEnviron{}, nil
+	}, &s.authorizer)
+	c.Assert(err, jc.ErrorIsNil)
+	_, err = facade.SweepMachineResources()
+	c.Assert(err, jc.Satisfies, errors.IsNotSupported)
+}
+
+type mockBackend struct {
+	machineIds []string
+	err        error
+}
+
+func (b *mockBackend) AllMachineIds() ([]string, error) {
+	return b.machineIds, b.err
+}
+
+type plainEnviron struct {
+	environs.Environ
+}
+
+type mockEnviron struct {
+	environs.Environ
+	resources []environs.Resource
+	live      []string
+	err       error
+}
+
+func (env *mockEnviron) SweepMachineResources(liveMachines func() ([]string, error)) ([]environs.Resource, error) {
+	live, err := liveMachines()
+	if err != nil {
+		return nil, err
+	}
+	env.live = live
+	return env.resources, env.err
+}
@@ -4,6 +4,8 @@
 package apiserver
 
 import (
+	"github.com/juju/errors"
+
 	"github.com/juju/juju/rpc"
 	"github.com/juju/juju/rpc/rpcreflect"
 )
@@ -31,6 +33,20 @@ func (r *restrictedRoot) FindMethod(facadeName string, version int, methodName s
 	return r.Root.FindMethod(facadeName, version, methodName)
 }
 
+// Delegate implements rpc.DelegatingRoot, applying the same
+// restrictions to requests made on behalf of other entities.
+func (r *restrictedRoot) Delegate(tag string) (rpc.Root, error) {
+	delegatingRoot, ok := r.Root.(rpc.DelegatingRoot)
+	if !ok {
+		return nil, errors.NotSupportedf("requests on behalf of %q", tag)
+	}
+	root, err := delegatingRoot.Delegate(tag)
+	if err != nil {
+		return nil, err
+	}
+	return restrictRoot(root, r.check), nil
+}
+
 // restrictAll blocks all API requests, returned a fixed error.
 func restrictAll(root rpc.Root, err error) *restrictedRoot {
 	return restrictRoot(root, func(string, string) error {
@@ -13,6 +13,7 @@ import (
 
 	"github.com/juju/juju/apiserver/common"
 	"github.com/juju/juju/apiserver/facade"
+	"github.com/juju/juju/apiserver/observer"
 	"github.com/juju/juju/apiserver/params"
 	"github.com/juju/juju/permission"
 	"github.com/juju/juju/rpc"
@@ -32,6 +33,25 @@ var (
 	// alive. When the ping returns an error, the server will be
 	// terminated.
 	mongoPingInterval = 10 * time.Second
+
+	// defaultAllWatcherSessionTimeout is how long an AllWatcher
+	// session is retained after its connection is closed, if
+	// not otherwise configured.
+	defaultAllWatcherSessionTimeout = 5 * time.Minute
+
+	// defaultAllWatcherReplaySize is the number of delta batches
+	// retained by each AllWatcher session for replay, if not
+	// otherwise configured.
+	defaultAllWatcherReplaySize = 100
+
+	// defaultSlowCallThreshold is the minimum duration of an API
+	// call for it to be recorded in the slow call log, if not
+	// otherwise configured.
+	defaultSlowCallThreshold = time.Second
+
+	// defaultSlowCallLogSize is the number of calls retained in
+	// the slow call log, if not otherwise configured.
+	defaultSlowCallLogSize = 100
 )
 
 type objectKey struct {
@@ -80,6 +100,12 @@ func newAPIHandler(srv *Server, st *state.State, rpcConn *rpc.Conn, modelUUID st
 	if err := r.resources.RegisterNamed("logDir", common.StringResource(srv.logDir)); err != nil {
 		return nil, errors.Trace(err)
 	}
+	if err := r.resources.RegisterNamed(common.AllWatcherSessionsResourceName, srv.allWatcherSessions); err != nil {
+		return nil, errors.Trace(err)
+	}
+	if err := r.resources.RegisterNamed(observer.APIMetricsResourceName, srv.apiMetrics); err != nil {
+		return nil, errors.Trace(err)
+	}
 	return r, nil
 }
 
@@ -92,9 +118,14 @@ func (r *apiHandler) getRpcConn() *rpc.Conn {
 }
 
 // Kill implements rpc.Killer, cleaning up any resources that need
-// cleaning up to ensure that all outstanding requests return.
+// cleaning up to ensure that all outstanding requests return. Any
+// database watches of the connection that remain once its resources
+// are stopped are then stopped too.
 func (r *apiHandler) Kill() {
 	r.resources.StopAll()
+	if owner := r.state.WatchOwner(); owner != "" {
+		r.state.UnwatchOwner(owner)
+	}
 }
 
 // srvCaller is our implementation of the rpcreflect.MethodCaller interface.
@@ -136,6 +167,14 @@ type apiRoot struct {
 	authorizer  facade.Authorizer
 	objectMutex sync.RWMutex
 	objectCache map[objectKey]reflect.Value
+
+	// delegate, if non-nil, returns an authorizer for a request made
+	// on behalf of the entity with the given tag, or an error if the
+	// connection's entity may not make it. delegateRoots holds the
+	// roots serving such requests, keyed by tag.
+	delegate      func(tag string) (facade.Authorizer, error)
+	delegateMutex sync.Mutex
+	delegateRoots map[string]*apiRoot
 }
 
 // newAPIRoot returns a new apiRoot.
@@ -154,6 +193,35 @@ func (r *apiRoot) Kill() {
 	r.resources.StopAll()
 }
 
+// Delegate implements rpc.DelegatingRoot. Requests made on behalf of
+// another entity are dispatched as though made by that entity, sharing
+// the connection's resources. Delegation is permitted only if the root
+// was given a delegate function, e.g. for machine agents acting on
+// behalf of the units assigned to their machines, and is authorized
+// afresh for every request: the root serving an entity's requests is
+// reused, but is discarded as soon as the entity loses the authority.
+func (r *apiRoot) Delegate(tag string) (rpc.Root, error) {
+	if r.delegate == nil {
+		return nil, common.ErrPerm
+	}
+	authorizer, err := r.delegate(tag)
+	r.delegateMutex.Lock()
+	defer r.delegateMutex.Unlock()
+	if err != nil {
+		delete(r.delegateRoots, tag)
+		return nil, errors.Trace(err)
+	}
+	if root, ok := r.delegateRoots[tag]; ok {
+		return root, nil
+	}
+	root := newAPIRoot(r.state, r.resources, authorizer)
+	if r.delegateRoots == nil {
+		r.delegateRoots = make(map[string]*apiRoot)
+	}
+	r.delegateRoots[tag] = root
+	return root, nil
+}
+
 // FindMethod looks up the given rootName and version in our facade registry
 // and returns a MethodCaller that will be used by the RPC code to place calls on
 // that facade.
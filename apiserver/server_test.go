@@ -16,6 +16,7 @@ import (
 	jc "github.com/juju/testing/checkers"
 	"github.com/juju/utils"
 	"github.com/juju/utils/clock"
+	"github.com/prometheus/client_golang/prometheus"
 	"golang.org/x/net/websocket"
 	gc "gopkg.in/check.v1"
 	"gopkg.in/juju/names.v2"
@@ -25,6 +26,7 @@ import (
 	"gopkg.in/macaroon-bakery.v1/httpbakery"
 
 	"github.com/juju/juju/api"
+	"github.com/juju/juju/api/apimetrics"
 	apimachiner "github.com/juju/juju/api/machiner"
 	"github.com/juju/juju/apiserver"
 	"github.com/juju/juju/apiserver/observer"
@@ -522,6 +524,65 @@ func (s *serverSuite) checkAPIHandlerTeardown(c *gc.C, srvSt, st *state.State) {
 	c.Assert(resource.stopped, jc.IsTrue)
 }
 
+func (s *serverSuite) TestAPIMetrics(c *gc.C) {
+	registerer := &metricsRegisterer{}
+	listener, err := net.Listen("tcp", ":0")
+	c.Assert(err, jc.ErrorIsNil)
+	srv, err := apiserver.NewServer(s.State, listener, apiserver.ServerConfig{
+		Clock:       clock.WallClock,
+		Cert:        coretesting.ServerCert,
+		Key:         coretesting.ServerKey,
+		Tag:         names.NewMachineTag("0"),
+		LogDir:      c.MkDir(),
+		NewObserver: func() observer.Observer { return &fakeobserver.Instance{} },
+		AutocertURL: "https://0.1.2.3/no-autocert-here",
+		// Record every call in the slow call log.
+		SlowCallThreshold: time.Nanosecond,
+		MetricsRegisterer: registerer,
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	defer srv.Stop()
+	c.Assert(registerer.collectors, gc.HasLen, 1)
+
+	// The slow call log is only available to controller
+	// administrators, on a controller connection.
+	apiInfo := s.APIInfo(c)
+	apiInfo.Addrs = []string{fmt.Sprintf("localhost:%d", srv.Addr().Port)}
+	apiInfo.ModelTag = names.ModelTag{}
+	st, err := api.Open(apiInfo, fastDialOpts)
+	c.Assert(err, jc.ErrorIsNil)
+	defer st.Close()
+
+	calls, err := apimetrics.NewClient(st).SlowCalls()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(calls, gc.Not(gc.HasLen), 0)
+	c.Assert(calls[0].Facade, gc.Equals, "Admin")
+	c.Assert(calls[0].Method, gc.Equals, "Login")
+
+	err = srv.Stop()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(registerer.collectors, gc.HasLen, 0)
+}
+
+type metricsRegisterer struct {
+	collectors []prometheus.Collector
+}
+
+func (r *metricsRegisterer) Register(c prometheus.Collector) error {
+	r.collectors = append(r.collectors, c)
+	return nil
+}
+
+func (r *metricsRegisterer) Unregister(c prometheus.Collector) bool {
+	for i, collector := range r.collectors {
+		if collector == c {
+			r.collectors = append(r.collectors[:i], r.collectors[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
 // newServer returns a new running API server.
 func newServer(c *gc.C, st *state.State) *apiserver.Server {
 	listener, err := net.Listen("tcp", ":0")
@@ -13,6 +13,7 @@ import (
 	"github.com/juju/juju/apiserver/params"
 	"github.com/juju/juju/apiserver/storage"
 	"github.com/juju/juju/apiserver/testing"
+	"github.com/juju/juju/instance"
 	"github.com/juju/juju/state"
 	jujustorage "github.com/juju/juju/storage"
 	coretesting "github.com/juju/juju/testing"
@@ -86,6 +87,7 @@ const (
 	addStorageForUnitCall                   = "addStorageForUnit"
 	getBlockForTypeCall                     = "getBlockForType"
 	volumeAttachmentCall                    = "volumeAttachment"
+	machineInstanceIdCall                   = "machineInstanceId"
 )
 
 func (s *baseStorageSuite) constructState() *mockState {
@@ -219,6 +221,13 @@ func (s *baseStorageSuite) constructState() *mockState {
 			s.calls = append(s.calls, allFilesystemsCall)
 			return []state.Filesystem{s.filesystem}, nil
 		},
+		machineInstanceId: func(machine names.MachineTag) (instance.Id, error) {
+			s.calls = append(s.calls, machineInstanceIdCall)
+			if machine == s.machineTag {
+				return "inst-66", nil
+			}
+			return "", errors.NotProvisionedf("%s", names.ReadableString(machine))
+		},
 		modelName: "storagetest",
 		addStorageForUnit: func(u names.UnitTag, name string, cons state.StorageConstraints) error {
 			s.calls = append(s.calls, addStorageForUnitCall)
@@ -8,6 +8,7 @@ import (
 	"gopkg.in/juju/charm.v6-unstable"
 	"gopkg.in/juju/names.v2"
 
+	"github.com/juju/juju/instance"
 	"github.com/juju/juju/state"
 	"github.com/juju/juju/status"
 	jujustorage "github.com/juju/juju/storage"
@@ -58,6 +59,7 @@ type mockState struct {
 	filesystem                          func(tag names.FilesystemTag) (state.Filesystem, error)
 	machineFilesystemAttachments        func(machine names.MachineTag) ([]state.FilesystemAttachment, error)
 	filesystemAttachments               func(filesystem names.FilesystemTag) ([]state.FilesystemAttachment, error)
+	machineInstanceId                   func(machine names.MachineTag) (instance.Id, error)
 	allFilesystems                      func() ([]state.Filesystem, error)
 	addStorageForUnit                   func(u names.UnitTag, name string, cons state.StorageConstraints) error
 	getBlockForType                     func(t state.BlockType) (state.Block, bool, error)
@@ -152,6 +154,10 @@ func (st *mockState) Filesystem(tag names.FilesystemTag) (state.Filesystem, erro
 	return st.filesystem(tag)
 }
 
+func (st *mockState) MachineInstanceId(machine names.MachineTag) (instance.Id, error) {
+	return st.machineInstanceId(machine)
+}
+
 func (st *mockState) AddStorageForUnit(u names.UnitTag, name string, cons state.StorageConstraints) error {
 	return st.addStorageForUnit(u, name, cons)
 }
@@ -238,6 +244,16 @@ func (m *mockFilesystem) Volume() (names.VolumeTag, error) {
 	return names.VolumeTag{}, state.ErrNoBackingVolume
 }
 
+func (m *mockFilesystem) Params() (state.FilesystemParams, bool) {
+	if m.info != nil {
+		return state.FilesystemParams{}, false
+	}
+	return state.FilesystemParams{
+		Pool: "rootfs",
+		Size: 2048,
+	}, true
+}
+
 func (m *mockFilesystem) Info() (state.FilesystemInfo, error) {
 	if m.info != nil {
 		return *m.info, nil
@@ -264,6 +280,10 @@ func (m *mockFilesystemAttachment) Machine() names.MachineTag {
 	return m.machine
 }
 
+func (m *mockFilesystemAttachment) Params() (state.FilesystemAttachmentParams, bool) {
+	return state.FilesystemAttachmentParams{ReadOnly: true}, m.info == nil
+}
+
 func (m *mockFilesystemAttachment) Info() (state.FilesystemAttachmentInfo, error) {
 	if m.info != nil {
 		return *m.info, nil
@@ -337,7 +357,7 @@ func (va *mockVolumeAttachment) Info() (state.VolumeAttachmentInfo, error) {
 }
 
 func (va *mockVolumeAttachment) Params() (state.VolumeAttachmentParams, bool) {
-	panic("not implemented for test")
+	return state.VolumeAttachmentParams{}, va.info == nil
 }
 
 type mockBlock struct {
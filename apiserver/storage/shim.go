@@ -10,6 +10,7 @@ import (
 	"github.com/juju/juju/apiserver/common"
 	"github.com/juju/juju/apiserver/facade"
 	"github.com/juju/juju/environs"
+	"github.com/juju/juju/instance"
 	"github.com/juju/juju/state"
 	"github.com/juju/juju/state/stateenvirons"
 	"github.com/juju/juju/storage/poolmanager"
@@ -108,6 +109,9 @@ type storageAccess interface {
 	// Filesystem is required for filesystem functionality.
 	Filesystem(tag names.FilesystemTag) (state.Filesystem, error)
 
+	// MachineInstanceId is required for storage plan functionality.
+	MachineInstanceId(machine names.MachineTag) (instance.Id, error)
+
 	// AddStorageForUnit is required for storage add functionality.
 	AddStorageForUnit(tag names.UnitTag, name string, cons state.StorageConstraints) error
 
@@ -138,6 +142,17 @@ func (s stateShim) UnitAssignedMachine(tag names.UnitTag) (names.MachineTag, err
 	return names.NewMachineTag(mid), nil
 }
 
+// MachineInstanceId returns the ID of the instance of the machine
+// with the given tag, or an error satisfying errors.IsNotProvisioned
+// if the machine has not yet been provisioned.
+func (s stateShim) MachineInstanceId(tag names.MachineTag) (instance.Id, error) {
+	m, err := s.Machine(tag.Id())
+	if err != nil {
+		return "", errors.Trace(err)
+	}
+	return m.InstanceId()
+}
+
 // ModelName returns the name of Juju environment,
 // or an error if environment configuration is not retrievable.
 func (s stateShim) ModelName() (string, error) {
@@ -364,6 +364,40 @@ func (a *API) CreatePool(p params.StoragePool) error {
 	return err
 }
 
+// StoragePlans returns the plans for provisioning the volumes and
+// filesystems with the specified tags, describing the operations that
+// the storage provisioner would perform, without performing them.
+func (a *API) StoragePlans(args params.Entities) (params.StoragePlanResults, error) {
+	if err := a.checkCanRead(); err != nil {
+		return params.StoragePlanResults{}, errors.Trace(err)
+	}
+	results := params.StoragePlanResults{
+		Results: make([]params.StoragePlanResult, len(args.Entities)),
+	}
+	one := func(arg params.Entity) (params.StoragePlan, error) {
+		tag, err := names.ParseTag(arg.Tag)
+		if err != nil {
+			return params.StoragePlan{}, errors.Trace(err)
+		}
+		switch tag := tag.(type) {
+		case names.VolumeTag:
+			return storagecommon.VolumePlan(a.storage, tag, a.poolManager, a.registry)
+		case names.FilesystemTag:
+			return storagecommon.FilesystemPlan(a.storage, tag, a.poolManager, a.registry)
+		}
+		return params.StoragePlan{}, errors.NotValidf("%s is not a volume or filesystem", names.ReadableString(tag))
+	}
+	for i, arg := range args.Entities {
+		plan, err := one(arg)
+		if err != nil {
+			results.Results[i].Error = common.ServerError(err)
+			continue
+		}
+		results.Results[i].Result = &plan
+	}
+	return results, nil
+}
+
 // ListVolumes lists volumes with the given filters. Each filter produces
 // an independent list of volumes, or an error if the filter is invalid
 // or the volumes could not be listed.
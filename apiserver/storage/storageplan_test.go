@@ -0,0 +1,162 @@
+// Copyright 2016 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package storage_test
+
+import (
+	"github.com/juju/errors"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+	"gopkg.in/juju/names.v2"
+
+	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/instance"
+	"github.com/juju/juju/state"
+	jujustorage "github.com/juju/juju/storage"
+)
+
+type storagePlanSuite struct {
+	baseStorageSuite
+}
+
+var _ = gc.Suite(&storagePlanSuite{})
+
+func (s *storagePlanSuite) SetUpTest(c *gc.C) {
+	s.baseStorageSuite.SetUpTest(c)
+	loop, err := jujustorage.NewConfig("loop", "loop", nil)
+	c.Assert(err, jc.ErrorIsNil)
+	rootfs, err := jujustorage.NewConfig("rootfs", "rootfs", nil)
+	c.Assert(err, jc.ErrorIsNil)
+	s.pools["loop"] = loop
+	s.pools["rootfs"] = rootfs
+}
+
+func (s *storagePlanSuite) storagePlans(c *gc.C, tags ...names.Tag) []params.StoragePlanResult {
+	args := params.Entities{Entities: make([]params.Entity, len(tags))}
+	for i, tag := range tags {
+		args.Entities[i].Tag = tag.String()
+	}
+	results, err := s.api.StoragePlans(args)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(results.Results, gc.HasLen, len(tags))
+	return results.Results
+}
+
+func (s *storagePlanSuite) TestStoragePlansVolume(c *gc.C) {
+	results := s.storagePlans(c, s.volumeTag)
+	c.Assert(results[0].Error, gc.IsNil)
+	c.Assert(results[0].Result, jc.DeepEquals, &params.StoragePlan{
+		Tag:      "volume-22",
+		Create:   true,
+		Pool:     "loop",
+		Provider: "loop",
+		Size:     1024,
+		Attachments: []params.StorageAttachmentPlan{{
+			MachineTag: "machine-66",
+			InstanceId: "inst-66",
+			WithCreate: true,
+		}},
+	})
+	s.assertCalls(c, []string{volumeCall, volumeAttachmentsCall, machineInstanceIdCall})
+}
+
+func (s *storagePlanSuite) TestStoragePlansVolumeMachineNotProvisioned(c *gc.C) {
+	s.state.machineInstanceId = func(machine names.MachineTag) (instance.Id, error) {
+		return "", errors.NotProvisionedf("%s", names.ReadableString(machine))
+	}
+	results := s.storagePlans(c, s.volumeTag)
+	c.Assert(results[0].Error, gc.IsNil)
+	c.Assert(results[0].Result.Waiting, gc.Equals, "machine 66 to be provisioned")
+	c.Assert(results[0].Result.Attachments, jc.DeepEquals, []params.StorageAttachmentPlan{{
+		MachineTag: "machine-66",
+		WithCreate: true,
+		Waiting:    "machine 66 to be provisioned",
+	}})
+}
+
+func (s *storagePlanSuite) TestStoragePlansVolumeMultipleAttachments(c *gc.C) {
+	otherAttachment := &mockVolumeAttachment{
+		VolumeTag:  s.volumeTag,
+		MachineTag: names.NewMachineTag("67"),
+	}
+	s.state.volumeAttachments = func(names.VolumeTag) ([]state.VolumeAttachment, error) {
+		return []state.VolumeAttachment{s.volumeAttachment, otherAttachment}, nil
+	}
+	results := s.storagePlans(c, s.volumeTag)
+	c.Assert(results[0].Error, gc.IsNil)
+	c.Assert(results[0].Result.Waiting, gc.Equals, "")
+	c.Assert(results[0].Result.Attachments, jc.DeepEquals, []params.StorageAttachmentPlan{{
+		MachineTag: "machine-66",
+		InstanceId: "inst-66",
+		Waiting:    "volume to be created",
+	}, {
+		MachineTag: "machine-67",
+		Waiting:    "machine 67 to be provisioned",
+	}})
+}
+
+func (s *storagePlanSuite) TestStoragePlansFilesystem(c *gc.C) {
+	results := s.storagePlans(c, s.filesystemTag)
+	c.Assert(results[0].Error, gc.IsNil)
+	c.Assert(results[0].Result, jc.DeepEquals, &params.StoragePlan{
+		Tag:      "filesystem-104",
+		Create:   true,
+		Pool:     "rootfs",
+		Provider: "rootfs",
+		Size:     2048,
+		Attachments: []params.StorageAttachmentPlan{{
+			MachineTag: "machine-66",
+			InstanceId: "inst-66",
+			ReadOnly:   true,
+			Waiting:    "filesystem to be created",
+		}},
+	})
+}
+
+func (s *storagePlanSuite) TestStoragePlansFilesystemVolumeBacked(c *gc.C) {
+	s.filesystem.volume = &s.volumeTag
+	results := s.storagePlans(c, s.filesystemTag)
+	c.Assert(results[0].Error, gc.IsNil)
+	c.Assert(results[0].Result.VolumeTag, gc.Equals, "volume-22")
+	c.Assert(results[0].Result.Waiting, gc.Equals, "volume 22 to be attached")
+}
+
+func (s *storagePlanSuite) TestStoragePlansFilesystemProvisioned(c *gc.C) {
+	s.filesystem.info = &state.FilesystemInfo{Pool: "rootfs", Size: 4096}
+	results := s.storagePlans(c, s.filesystemTag)
+	c.Assert(results[0].Error, gc.IsNil)
+	c.Assert(results[0].Result, jc.DeepEquals, &params.StoragePlan{
+		Tag:      "filesystem-104",
+		Pool:     "rootfs",
+		Provider: "rootfs",
+		Size:     4096,
+		Attachments: []params.StorageAttachmentPlan{{
+			MachineTag: "machine-66",
+			InstanceId: "inst-66",
+			ReadOnly:   true,
+		}},
+	})
+}
+
+func (s *storagePlanSuite) TestStoragePlansErrors(c *gc.C) {
+	results := s.storagePlans(c,
+		names.NewVolumeTag("99"),
+		names.NewUnitTag("mysql/0"),
+	)
+	c.Assert(results[0].Error, gc.ErrorMatches, "volume 99 not found")
+	c.Assert(results[0].Error, jc.Satisfies, params.IsCodeNotFound)
+	c.Assert(results[1].Error, gc.ErrorMatches, "unit mysql/0 is not a volume or filesystem not valid")
+}
+
+func (s *storagePlanSuite) TestStoragePlansUnknownPool(c *gc.C) {
+	delete(s.pools, "loop")
+	results := s.storagePlans(c, s.volumeTag)
+	c.Assert(results[0].Error, gc.ErrorMatches, "mock pool manager: get pool loop not found")
+}
+
+func (s *storagePlanSuite) TestStoragePlansInvalidTag(c *gc.C) {
+	results, err := s.api.StoragePlans(params.Entities{Entities: []params.Entity{{Tag: "volume"}}})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(results.Results, gc.HasLen, 1)
+	c.Assert(results.Results[0].Error, gc.ErrorMatches, `"volume" is not a valid tag`)
+}
@@ -66,6 +66,8 @@ type Backend interface {
 	VolumeAttachment(names.MachineTag, names.VolumeTag) (state.VolumeAttachment, error)
 	VolumeAttachments(names.VolumeTag) ([]state.VolumeAttachment, error)
 
+	MachineAttachmentLives([]state.MachineAttachmentId) (map[state.MachineAttachmentId]state.Life, error)
+
 	RemoveFilesystem(names.FilesystemTag) error
 	RemoveFilesystemAttachment(names.MachineTag, names.FilesystemTag) error
 	RemoveVolume(names.VolumeTag) error
@@ -75,6 +77,9 @@ type Backend interface {
 	SetFilesystemAttachmentInfo(names.MachineTag, names.FilesystemTag, state.FilesystemAttachmentInfo) error
 	SetVolumeInfo(names.VolumeTag, state.VolumeInfo) error
 	SetVolumeAttachmentInfo(names.MachineTag, names.VolumeTag, state.VolumeAttachmentInfo) error
+
+	ClaimStorageEntity(entity, owner names.Tag) error
+	ReleaseStorageEntity(entity, owner names.Tag) error
 }
 
 type stateShim struct {
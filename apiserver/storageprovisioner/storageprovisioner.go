@@ -605,6 +605,7 @@ func (s *StorageProvisionerAPI) VolumeParams(args params.Entities) (params.Volum
 				string(instanceId),
 				volumeParams.Provider,
 				volumeAttachmentParams.ReadOnly,
+				storagecommon.VolumeDeleteOnTermination(volume, machineTag),
 			}
 		}
 		return volumeParams, nil
@@ -743,6 +744,7 @@ func (s *StorageProvisionerAPI) VolumeAttachmentParams(
 			string(instanceId),
 			string(providerType),
 			readOnly,
+			storagecommon.VolumeDeleteOnTermination(volume, volumeAttachment.Machine()),
 		}, nil
 	}
 	for i, arg := range args.Ids {
@@ -972,6 +974,20 @@ func (s *StorageProvisionerAPI) SetFilesystemInfo(args params.Filesystems) (para
 		} else if !canAccessFilesystem(filesystemTag) {
 			return common.ErrPerm
 		}
+		// The pool is immutable, and is taken from the filesystem
+		// params when the filesystem is first provisioned. When
+		// updating a provisioned filesystem's info, e.g. after it
+		// has been grown, carry over the existing pool.
+		filesystem, err := s.st.Filesystem(filesystemTag)
+		if err != nil && !errors.IsNotFound(err) {
+			return errors.Trace(err)
+		} else if err == nil {
+			if oldInfo, err := filesystem.Info(); err == nil {
+				filesystemInfo.Pool = oldInfo.Pool
+			} else if !errors.IsNotProvisioned(err) {
+				return errors.Trace(err)
+			}
+		}
 		err = s.st.SetFilesystemInfo(filesystemTag, filesystemInfo)
 		if errors.IsNotFound(err) {
 			return common.ErrPerm
@@ -1008,6 +1024,10 @@ func (s *StorageProvisionerAPI) SetVolumeAttachmentInfo(
 		err = s.st.SetVolumeAttachmentInfo(machineTag, volumeTag, volumeAttachmentInfo)
 		if errors.IsNotFound(err) {
 			return common.ErrPerm
+		} else if errors.IsNotProvisioned(err) {
+			// The volume or machine has not yet been provisioned,
+			// so the attachment info cannot be recorded until it is.
+			return common.RetryableError(err, 0)
 		}
 		return errors.Trace(err)
 	}
@@ -1041,6 +1061,10 @@ func (s *StorageProvisionerAPI) SetFilesystemAttachmentInfo(
 		err = s.st.SetFilesystemAttachmentInfo(machineTag, filesystemTag, filesystemAttachmentInfo)
 		if errors.IsNotFound(err) {
 			return common.ErrPerm
+		} else if errors.IsNotProvisioned(err) {
+			// The filesystem or machine has not yet been provisioned,
+			// so the attachment info cannot be recorded until it is.
+			return common.RetryableError(err, 0)
 		}
 		return errors.Trace(err)
 	}
@@ -1052,7 +1076,8 @@ func (s *StorageProvisionerAPI) SetFilesystemAttachmentInfo(
 }
 
 // AttachmentLife returns the lifecycle state of each specified machine
-// storage attachment.
+// storage attachment. The lifecycle states of all of the accessible
+// attachments are obtained from state in a single bulk query.
 func (s *StorageProvisionerAPI) AttachmentLife(args params.MachineStorageIds) (params.LifeResults, error) {
 	canAccess, err := s.getAttachmentAuthFunc()
 	if err != nil {
@@ -1061,39 +1086,53 @@ func (s *StorageProvisionerAPI) AttachmentLife(args params.MachineStorageIds) (p
 	results := params.LifeResults{
 		Results: make([]params.LifeResult, len(args.Ids)),
 	}
-	one := func(arg params.MachineStorageId) (params.Life, error) {
+	one := func(arg params.MachineStorageId) (state.MachineAttachmentId, error) {
 		machineTag, err := names.ParseMachineTag(arg.MachineTag)
 		if err != nil {
-			return "", err
+			return state.MachineAttachmentId{}, err
 		}
 		attachmentTag, err := names.ParseTag(arg.AttachmentTag)
 		if err != nil {
-			return "", err
-		}
-		if !canAccess(machineTag, attachmentTag) {
-			return "", common.ErrPerm
+			return state.MachineAttachmentId{}, err
 		}
-		var lifer state.Lifer
-		switch attachmentTag := attachmentTag.(type) {
-		case names.VolumeTag:
-			lifer, err = s.st.VolumeAttachment(machineTag, attachmentTag)
-		case names.FilesystemTag:
-			lifer, err = s.st.FilesystemAttachment(machineTag, attachmentTag)
+		switch attachmentTag.(type) {
+		case names.VolumeTag, names.FilesystemTag:
+		default:
+			return state.MachineAttachmentId{}, common.ErrPerm
 		}
-		if errors.IsNotFound(err) {
-			return "", common.ErrPerm
-		} else if err != nil {
-			return "", errors.Trace(err)
+		if !canAccess(machineTag, attachmentTag) {
+			return state.MachineAttachmentId{}, common.ErrPerm
 		}
-		return params.Life(lifer.Life().String()), nil
+		return state.MachineAttachmentId{machineTag, attachmentTag}, nil
 	}
+	ids := make([]state.MachineAttachmentId, len(args.Ids))
+	valid := make([]state.MachineAttachmentId, 0, len(args.Ids))
 	for i, arg := range args.Ids {
-		life, err := one(arg)
+		id, err := one(arg)
 		if err != nil {
 			results.Results[i].Error = common.ServerError(err)
-		} else {
-			results.Results[i].Life = life
+			continue
+		}
+		ids[i] = id
+		valid = append(valid, id)
+	}
+	if len(valid) == 0 {
+		return results, nil
+	}
+	lives, err := s.st.MachineAttachmentLives(valid)
+	if err != nil {
+		return params.LifeResults{}, errors.Trace(err)
+	}
+	for i, id := range ids {
+		if results.Results[i].Error != nil {
+			continue
 		}
+		life, ok := lives[id]
+		if !ok {
+			results.Results[i].Error = common.ServerError(common.ErrPerm)
+			continue
+		}
+		results.Results[i].Life = params.Life(life.String())
 	}
 	return results, nil
 }
@@ -1171,3 +1210,81 @@ func (s *StorageProvisionerAPI) RemoveAttachment(args params.MachineStorageIds)
 	}
 	return results, nil
 }
+
+// ClaimStorageEntities claims volumes and filesystems on behalf of
+// storage provisioner scopes, so that the storage provisioners of
+// different scopes do not act on the same entity at once. A machine
+// scope may claim the entities accessible to it, and those attached
+// to the machine.
+func (s *StorageProvisionerAPI) ClaimStorageEntities(args params.StorageClaims) (params.ErrorResults, error) {
+	canAccessEntity, err := s.getStorageEntityAuthFunc()
+	if err != nil {
+		return params.ErrorResults{}, err
+	}
+	return s.updateStorageClaims(args, func(entityTag, ownerTag names.Tag) error {
+		if !canAccessEntity(entityTag) && !s.attachedToScope(entityTag, ownerTag) {
+			return common.ErrPerm
+		}
+		return s.st.ClaimStorageEntity(entityTag, ownerTag)
+	})
+}
+
+// ReleaseStorageEntities releases the claims on volumes and filesystems
+// held by storage provisioner scopes.
+func (s *StorageProvisionerAPI) ReleaseStorageEntities(args params.StorageClaims) (params.ErrorResults, error) {
+	// Scopes may only release their own claims, and the
+	// entities may since have been removed, so access to
+	// the scope is sufficient.
+	return s.updateStorageClaims(args, s.st.ReleaseStorageEntity)
+}
+
+func (s *StorageProvisionerAPI) updateStorageClaims(
+	args params.StorageClaims,
+	update func(entityTag, ownerTag names.Tag) error,
+) (params.ErrorResults, error) {
+	canAccessScope, err := s.getScopeAuthFunc()
+	if err != nil {
+		return params.ErrorResults{}, err
+	}
+	results := params.ErrorResults{
+		Results: make([]params.ErrorResult, len(args.Claims)),
+	}
+	one := func(arg params.StorageClaim) error {
+		ownerTag, err := names.ParseTag(arg.OwnerTag)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		if !canAccessScope(ownerTag) {
+			return common.ErrPerm
+		}
+		entityTag, err := names.ParseTag(arg.EntityTag)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		return update(entityTag, ownerTag)
+	}
+	for i, arg := range args.Claims {
+		err := one(arg)
+		results.Results[i].Error = common.ServerError(err)
+	}
+	return results, nil
+}
+
+// attachedToScope reports whether the volume or filesystem with the
+// given tag is attached to the machine scope with the given tag.
+func (s *StorageProvisionerAPI) attachedToScope(entityTag, scopeTag names.Tag) bool {
+	machineTag, ok := scopeTag.(names.MachineTag)
+	if !ok {
+		return false
+	}
+	var err error
+	switch entityTag := entityTag.(type) {
+	case names.VolumeTag:
+		_, err = s.st.VolumeAttachment(machineTag, entityTag)
+	case names.FilesystemTag:
+		_, err = s.st.FilesystemAttachment(machineTag, entityTag)
+	default:
+		return false
+	}
+	return err == nil
+}
@@ -361,10 +361,11 @@ func (s *provisionerSuite) TestVolumeParams(c *gc.C) {
 					tags.JujuModel:      testing.ModelTag.Id(),
 				},
 				Attachment: &params.VolumeAttachmentParams{
-					MachineTag: "machine-0",
-					VolumeTag:  "volume-0-0",
-					Provider:   "machinescoped",
-					InstanceId: "inst-id",
+					MachineTag:          "machine-0",
+					VolumeTag:           "volume-0-0",
+					Provider:            "machinescoped",
+					InstanceId:          "inst-id",
+					DeleteOnTermination: true,
 				},
 			}},
 			{Result: params.VolumeParams{
@@ -376,10 +377,11 @@ func (s *provisionerSuite) TestVolumeParams(c *gc.C) {
 					tags.JujuModel:      testing.ModelTag.Id(),
 				},
 				Attachment: &params.VolumeAttachmentParams{
-					MachineTag: "machine-0",
-					VolumeTag:  "volume-1",
-					Provider:   "environscoped",
-					InstanceId: "inst-id",
+					MachineTag:          "machine-0",
+					VolumeTag:           "volume-1",
+					Provider:            "environscoped",
+					InstanceId:          "inst-id",
+					DeleteOnTermination: true,
 				},
 			}},
 			{Result: params.VolumeParams{
@@ -391,11 +393,12 @@ func (s *provisionerSuite) TestVolumeParams(c *gc.C) {
 					tags.JujuModel:      testing.ModelTag.Id(),
 				},
 				Attachment: &params.VolumeAttachmentParams{
-					MachineTag: "machine-0",
-					VolumeTag:  "volume-3",
-					Provider:   "environscoped",
-					InstanceId: "inst-id",
-					ReadOnly:   true,
+					MachineTag:          "machine-0",
+					VolumeTag:           "volume-3",
+					Provider:            "environscoped",
+					InstanceId:          "inst-id",
+					ReadOnly:            true,
+					DeleteOnTermination: true,
 				},
 			}},
 			{Error: &params.Error{Message: "permission denied", Code: "unauthorized access"}},
@@ -482,30 +485,34 @@ func (s *provisionerSuite) TestVolumeAttachmentParams(c *gc.C) {
 	c.Assert(results, jc.DeepEquals, params.VolumeAttachmentParamsResults{
 		Results: []params.VolumeAttachmentParamsResult{
 			{Result: params.VolumeAttachmentParams{
-				MachineTag: "machine-0",
-				VolumeTag:  "volume-0-0",
-				InstanceId: "inst-id",
-				VolumeId:   "abc",
-				Provider:   "machinescoped",
+				MachineTag:          "machine-0",
+				VolumeTag:           "volume-0-0",
+				InstanceId:          "inst-id",
+				VolumeId:            "abc",
+				Provider:            "machinescoped",
+				DeleteOnTermination: true,
 			}},
 			{Result: params.VolumeAttachmentParams{
-				MachineTag: "machine-0",
-				VolumeTag:  "volume-1",
-				InstanceId: "inst-id",
-				Provider:   "environscoped",
+				MachineTag:          "machine-0",
+				VolumeTag:           "volume-1",
+				InstanceId:          "inst-id",
+				Provider:            "environscoped",
+				DeleteOnTermination: true,
 			}},
 			{Result: params.VolumeAttachmentParams{
-				MachineTag: "machine-0",
-				VolumeTag:  "volume-3",
-				InstanceId: "inst-id",
-				VolumeId:   "xyz",
-				Provider:   "environscoped",
-				ReadOnly:   true,
+				MachineTag:          "machine-0",
+				VolumeTag:           "volume-3",
+				InstanceId:          "inst-id",
+				VolumeId:            "xyz",
+				Provider:            "environscoped",
+				ReadOnly:            true,
+				DeleteOnTermination: true,
 			}},
 			{Result: params.VolumeAttachmentParams{
-				MachineTag: "machine-2",
-				VolumeTag:  "volume-4",
-				Provider:   "environscoped",
+				MachineTag:          "machine-2",
+				VolumeTag:           "volume-4",
+				Provider:            "environscoped",
+				DeleteOnTermination: true,
 			}},
 			{Error: &params.Error{Message: "permission denied", Code: "unauthorized access"}},
 		},
@@ -616,13 +623,56 @@ func (s *provisionerSuite) TestSetVolumeAttachmentInfo(c *gc.C) {
 	c.Assert(results, jc.DeepEquals, params.ErrorResults{
 		Results: []params.ErrorResult{
 			{},
-			{Error: &params.Error{Message: `cannot set info for volume attachment 1:0: volume "1" not provisioned`, Code: "not provisioned"}},
-			{Error: &params.Error{Message: `cannot set info for volume attachment 4:2: machine 2 not provisioned`, Code: "not provisioned"}},
+			{Error: &params.Error{Message: `cannot set info for volume attachment 1:0: volume "1" not provisioned`, Code: "not provisioned", Info: &params.ErrorInfo{Retryable: true}}},
+			{Error: &params.Error{Message: `cannot set info for volume attachment 4:2: machine 2 not provisioned`, Code: "not provisioned", Info: &params.ErrorInfo{Retryable: true}}},
 			{Error: &params.Error{Message: "permission denied", Code: "unauthorized access"}},
 		},
 	})
 }
 
+func (s *provisionerSuite) TestSetFilesystemInfoUpdate(c *gc.C) {
+	s.setupFilesystems(c)
+
+	results, err := s.api.SetFilesystemInfo(params.Filesystems{
+		Filesystems: []params.Filesystem{{
+			FilesystemTag: "filesystem-0-0",
+			Info: params.FilesystemInfo{
+				FilesystemId: "abc",
+				Size:         2048,
+			},
+		}, {
+			FilesystemTag: "filesystem-1",
+			Info: params.FilesystemInfo{
+				FilesystemId: "ghi",
+				Size:         2048,
+			},
+		}},
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(results, jc.DeepEquals, params.ErrorResults{
+		Results: []params.ErrorResult{{}, {}},
+	})
+
+	// The size of the provisioned filesystem is updated,
+	// and its pool is left alone.
+	filesystem, err := s.State.Filesystem(names.NewFilesystemTag("0/0"))
+	c.Assert(err, jc.ErrorIsNil)
+	info, err := filesystem.Info()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(info, jc.DeepEquals, state.FilesystemInfo{
+		FilesystemId: "abc",
+		Pool:         "machinescoped",
+		Size:         2048,
+	})
+
+	// The unprovisioned filesystem's pool is taken from its params.
+	filesystem, err = s.State.Filesystem(names.NewFilesystemTag("1"))
+	c.Assert(err, jc.ErrorIsNil)
+	info, err = filesystem.Info()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(info.Pool, gc.Equals, "environscoped")
+}
+
 func (s *provisionerSuite) TestSetFilesystemAttachmentInfo(c *gc.C) {
 	s.setupFilesystems(c)
 
@@ -664,8 +714,8 @@ func (s *provisionerSuite) TestSetFilesystemAttachmentInfo(c *gc.C) {
 	c.Assert(results, jc.DeepEquals, params.ErrorResults{
 		Results: []params.ErrorResult{
 			{},
-			{Error: &params.Error{Message: `cannot set info for filesystem attachment 1:0: filesystem "1" not provisioned`, Code: "not provisioned"}},
-			{Error: &params.Error{Message: `cannot set info for filesystem attachment 3:2: machine 2 not provisioned`, Code: "not provisioned"}},
+			{Error: &params.Error{Message: `cannot set info for filesystem attachment 1:0: filesystem "1" not provisioned`, Code: "not provisioned", Info: &params.ErrorInfo{Retryable: true}}},
+			{Error: &params.Error{Message: `cannot set info for filesystem attachment 3:2: machine 2 not provisioned`, Code: "not provisioned", Info: &params.ErrorInfo{Retryable: true}}},
 			{Error: &params.Error{Message: "permission denied", Code: "unauthorized access"}},
 		},
 	})
@@ -1008,6 +1058,30 @@ func (s *provisionerSuite) TestAttachmentLife(c *gc.C) {
 	})
 }
 
+func (s *provisionerSuite) TestAttachmentLifeDying(c *gc.C) {
+	s.setupVolumes(c)
+	err := s.State.DetachVolume(names.NewMachineTag("0"), names.NewVolumeTag("1"))
+	c.Assert(err, jc.ErrorIsNil)
+
+	results, err := s.api.AttachmentLife(params.MachineStorageIds{
+		Ids: []params.MachineStorageId{{
+			MachineTag:    "machine-0",
+			AttachmentTag: "volume-1",
+		}, {
+			MachineTag:    "machine-invalid",
+			AttachmentTag: "volume-1",
+		}, {
+			MachineTag:    "machine-0",
+			AttachmentTag: "volume-0-0",
+		}},
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(results.Results, gc.HasLen, 3)
+	c.Assert(results.Results[0], jc.DeepEquals, params.LifeResult{Life: params.Dying})
+	c.Assert(results.Results[1].Error, gc.NotNil)
+	c.Assert(results.Results[2], jc.DeepEquals, params.LifeResult{Life: params.Alive})
+}
+
 func (s *provisionerSuite) TestEnsureDead(c *gc.C) {
 	s.setupVolumes(c)
 	args := params.Entities{Entities: []params.Entity{{"volume-0-0"}, {"volume-1"}, {"volume-42"}}}
@@ -1217,3 +1291,78 @@ func (b byMachineAndEntity) Less(i, j int) bool {
 func (b byMachineAndEntity) Swap(i, j int) {
 	b[i], b[j] = b[j], b[i]
 }
+
+func (s *provisionerSuite) TestClaimStorageEntitiesMachineAgent(c *gc.C) {
+	s.setupVolumes(c)
+	s.authorizer.EnvironManager = false
+	machine0 := names.NewMachineTag("0").String()
+	args := params.StorageClaims{Claims: []params.StorageClaim{
+		{EntityTag: "volume-0-0", OwnerTag: machine0},
+		// Volume 1 is model-scoped, but attached to machine 0.
+		{EntityTag: "volume-1", OwnerTag: machine0},
+		// Volume 4 is attached to machine 2.
+		{EntityTag: "volume-4", OwnerTag: machine0},
+		{EntityTag: "volume-1", OwnerTag: s.State.ModelTag().String()},
+		{EntityTag: "volume-1", OwnerTag: "machine-2"},
+		{EntityTag: "volume-invalid", OwnerTag: machine0},
+	}}
+	result, err := s.api.ClaimStorageEntities(args)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(result, gc.DeepEquals, params.ErrorResults{
+		Results: []params.ErrorResult{
+			{Error: nil},
+			{Error: nil},
+			{Error: &params.Error{Message: "permission denied", Code: "unauthorized access"}},
+			{Error: &params.Error{Message: "permission denied", Code: "unauthorized access"}},
+			{Error: &params.Error{Message: "permission denied", Code: "unauthorized access"}},
+			{Error: &params.Error{Message: `"volume-invalid" is not a valid volume tag`}},
+		},
+	})
+	claim, err := s.State.StorageEntityClaim(names.NewVolumeTag("1"))
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(claim.Owner, gc.Equals, names.NewMachineTag("0"))
+}
+
+func (s *provisionerSuite) TestClaimStorageEntitiesContended(c *gc.C) {
+	s.setupVolumes(c)
+	err := s.State.ClaimStorageEntity(names.NewVolumeTag("1"), names.NewMachineTag("0"))
+	c.Assert(err, jc.ErrorIsNil)
+	modelTag := s.State.ModelTag().String()
+	args := params.StorageClaims{Claims: []params.StorageClaim{
+		{EntityTag: "volume-1", OwnerTag: modelTag},
+		{EntityTag: "volume-2", OwnerTag: modelTag},
+	}}
+	result, err := s.api.ClaimStorageEntities(args)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(result.Results, gc.HasLen, 2)
+	c.Assert(result.Results[0].Error, gc.ErrorMatches, "cannot claim volume 1: volume 1 is claimed by machine 0")
+	c.Assert(result.Results[1].Error, gc.IsNil)
+}
+
+func (s *provisionerSuite) TestReleaseStorageEntities(c *gc.C) {
+	s.setupVolumes(c)
+	err := s.State.ClaimStorageEntity(names.NewVolumeTag("1"), names.NewMachineTag("0"))
+	c.Assert(err, jc.ErrorIsNil)
+	err = s.State.ClaimStorageEntity(names.NewVolumeTag("2"), s.State.ModelTag())
+	c.Assert(err, jc.ErrorIsNil)
+	modelTag := s.State.ModelTag().String()
+	args := params.StorageClaims{Claims: []params.StorageClaim{
+		{EntityTag: "volume-1", OwnerTag: modelTag},
+		{EntityTag: "volume-2", OwnerTag: modelTag},
+		{EntityTag: "volume-42", OwnerTag: modelTag},
+		{EntityTag: "volume-2", OwnerTag: "unit-mysql-0"},
+	}}
+	result, err := s.api.ReleaseStorageEntities(args)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(result, gc.DeepEquals, params.ErrorResults{
+		Results: []params.ErrorResult{
+			{Error: &params.Error{Message: "cannot release volume 1: volume 1 is claimed by machine 0"}},
+			{Error: nil},
+			{Error: nil},
+			{Error: &params.Error{Message: "permission denied", Code: "unauthorized access"}},
+		},
+	})
+	claim, err := s.State.StorageEntityClaim(names.NewVolumeTag("2"))
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(claim.Owner, gc.IsNil)
+}
@@ -12,9 +12,11 @@ import (
 	"net/http"
 	"net/url"
 	"strings"
+	"time"
 
 	"github.com/juju/errors"
 	"github.com/juju/utils"
+	"github.com/juju/utils/clock"
 	"github.com/juju/version"
 
 	"github.com/juju/juju/apiserver/common"
@@ -46,10 +48,23 @@ func (h *toolsDownloadHandler) ServeHTTP(w http.ResponseWriter, r *http.Request)
 
 	switch r.Method {
 	case "GET":
+		if err := h.checkSignature(r, st); err != nil {
+			sendError(w, err)
+			return
+		}
 		tarball, err := h.processGet(r, st)
 		if err != nil {
 			logger.Errorf("GET(%s) failed: %v", r.URL, err)
-			sendError(w, errors.NewBadRequest(err, ""))
+			sendError(w, toolsDownloadError(err))
+			return
+		}
+		// Clients that report the tools they already have are sent
+		// a delta if possible, falling back to the full tarball.
+		toolsDelta, err := h.processGetDelta(r, st, tarball)
+		if err != nil {
+			logger.Warningf("GET(%s) cannot serve delta: %v", r.URL, err)
+		} else if toolsDelta != nil && len(toolsDelta) < len(tarball) {
+			h.sendToolsDelta(w, toolsDelta)
 			return
 		}
 		h.sendTools(w, http.StatusOK, tarball)
@@ -83,6 +98,46 @@ func (h *toolsUploadHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// checkSignature checks the signature of a signed tools URL, minted
+// by a common.ToolsURLSigner for the request's model. Unsigned requests
+// are served as before, unless the controller requires signed tools
+// URLs, in which case they must be authenticated.
+func (h *toolsDownloadHandler) checkSignature(r *http.Request, st *state.State) error {
+	query := r.URL.Query()
+	if !common.IsSigned(query) {
+		return h.checkUnsigned(r)
+	}
+	v, err := version.ParseBinary(query.Get(":version"))
+	if err != nil {
+		return errors.NewBadRequest(err, "error parsing version")
+	}
+	signer, err := common.NewStateToolsURLSigner(h.ctxt.srv.state, h.ctxt.srv.clock)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	if err := signer.Verify(st.ModelUUID(), v, query); err != nil {
+		return errors.NewUnauthorized(err, "")
+	}
+	return nil
+}
+
+// checkUnsigned checks that an unsigned tools download request is
+// permitted. Agents, such as the migration master, authenticate their
+// requests rather than signing them.
+func (h *toolsDownloadHandler) checkUnsigned(r *http.Request) error {
+	controllerConfig, err := h.ctxt.srv.state.ControllerConfig()
+	if err != nil {
+		return errors.Trace(err)
+	}
+	if !controllerConfig.RequireSignedToolsURLs() {
+		return nil
+	}
+	if _, _, err := h.ctxt.stateForRequestAuthenticated(r); err != nil {
+		return errors.NewUnauthorized(err, "tools URL not signed")
+	}
+	return nil
+}
+
 // processGet handles a tools GET request.
 func (h *toolsDownloadHandler) processGet(r *http.Request, st *state.State) ([]byte, error) {
 	version, err := version.ParseBinary(r.URL.Query().Get(":version"))
@@ -95,19 +150,36 @@ func (h *toolsDownloadHandler) processGet(r *http.Request, st *state.State) ([]b
 	}
 	defer storage.Close()
 	_, reader, err := storage.Open(version.String())
-	if errors.IsNotFound(err) {
+	if err == nil {
+		data, err := readToolsTarball(reader)
+		if !binarystorage.IsCorrupt(err) {
+			return data, err
+		}
+		// The stored tools do not match their recorded hash,
+		// so remove them and fetch them again. The tools must
+		// be removed first, or the corrupt blob would be reused
+		// when the fetched tools are cached.
+		logger.Warningf("%v tools are corrupt, fetching: %v", version, err)
+		if err := storage.Remove(version.String()); err != nil && !errors.IsNotFound(err) {
+			return nil, errors.Annotate(err, "error removing corrupt tools")
+		}
+	} else if errors.IsNotFound(err) {
 		// Tools could not be found in tools storage,
 		// so look for them in simplestreams, fetch
 		// them and cache in tools storage.
 		logger.Infof("%v tools not found locally, fetching", version)
-		reader, err = h.fetchAndCacheTools(version, storage, st)
-		if err != nil {
-			err = errors.Annotate(err, "error fetching tools")
-		}
+	} else {
+		return nil, err
 	}
+	reader, err = h.fetchAndCacheTools(version, storage, st)
 	if err != nil {
-		return nil, err
+		return nil, errors.Annotate(err, "error fetching tools")
 	}
+	return readToolsTarball(reader)
+}
+
+// readToolsTarball reads and closes the tools tarball from the given reader.
+func readToolsTarball(reader io.ReadCloser) ([]byte, error) {
 	defer reader.Close()
 	data, err := ioutil.ReadAll(reader)
 	if err != nil {
@@ -116,24 +188,196 @@ func (h *toolsDownloadHandler) processGet(r *http.Request, st *state.State) ([]b
 	return data, nil
 }
 
-// fetchAndCacheTools fetches tools with the specified version by searching for a URL
-// in simplestreams and GETting it, caching the result in tools storage before returning
-// to the caller.
+// fetchAndCacheTools fetches tools with the specified version by searching for
+// URLs in simplestreams and GETting them, caching the result in tools storage
+// before returning to the caller.
 func (h *toolsDownloadHandler) fetchAndCacheTools(v version.Binary, stor binarystorage.Storage, st *state.State) (io.ReadCloser, error) {
 	newEnviron := stateenvirons.GetNewEnvironFunc(environs.New)
 	env, err := newEnviron(st)
 	if err != nil {
 		return nil, err
 	}
-	tools, err := envtools.FindExactTools(env, v.Number, v.Series, v.Arch)
+	mirrors, err := envtools.FindExactToolsMirrors(env, v.Number, v.Series, v.Arch)
 	if err != nil {
-		return nil, err
+		return nil, toolsFetchError(err)
+	}
+	mirrors = healthyToolsMirrors(mirrors, st, h.ctxt.srv.clock)
+	data, tools, err := fetchToolsFromMirrors(v, mirrors, st)
+	if err != nil {
+		return nil, toolsFetchError(err)
+	}
+
+	// Cache tarball in tools storage before returning.
+	metadata := binarystorage.Metadata{
+		Version: v.String(),
+		Size:    tools.Size,
+		SHA256:  tools.SHA256,
+	}
+	if err := stor.Add(bytes.NewReader(data), metadata); err != nil {
+		return nil, errors.Annotate(err, "error caching tools")
+	}
+	return ioutil.NopCloser(bytes.NewReader(data)), nil
+}
+
+// toolsDownloadError returns the error to report to the client
+// when a tools download fails. Retryable errors are reported as
+// they are, so the client is told to retry; anything else is
+// reported as a bad request.
+func toolsDownloadError(err error) error {
+	if params.IsRetryable(common.ServerError(err)) {
+		return err
+	}
+	return errors.NewBadRequest(err, "")
+}
+
+// toolsFetchError returns the error to report to the client when
+// tools could not be fetched. Failures other than the tools not
+// existing are assumed to be transient, e.g. network failures,
+// and so the client is told to retry.
+func toolsFetchError(err error) error {
+	if errors.IsNotFound(err) {
+		return err
+	}
+	return common.RetryableError(err, toolsFetchRetryDelay)
+}
+
+const (
+	// toolsFetchRetryDelay is the amount of time that a client
+	// is told to wait before retrying a failed tools download.
+	toolsFetchRetryDelay = time.Minute
+
+	// toolsMirrorMaxFailures is the number of consecutive failures
+	// after which a tools mirror is considered unhealthy.
+	toolsMirrorMaxFailures = 3
+
+	// toolsMirrorRetryDelay is the amount of time after its last
+	// failure that an unhealthy tools mirror will be tried again.
+	toolsMirrorRetryDelay = 10 * time.Minute
+)
+
+// toolsMirrorHealth is an interface for recording and
+// querying the health of tools mirrors.
+type toolsMirrorHealth interface {
+	ToolsMirrorHealth(mirror string) (state.ToolsMirrorHealth, error)
+	RecordToolsMirrorSuccess(mirror string) error
+	RecordToolsMirrorFailure(mirror string, cause error) error
+}
+
+// toolsMirrorName returns the name with which the health of the
+// mirror hosting the tools at the given URL is recorded.
+func toolsMirrorName(toolsURL string) string {
+	u, err := url.Parse(toolsURL)
+	if err != nil || u.Host == "" {
+		return toolsURL
+	}
+	return u.Scheme + "://" + u.Host
+}
+
+// healthyToolsMirrors returns the tools mirrors that have not failed
+// recently, according to the given clock. If all of the mirrors are
+// unhealthy, they are all returned, so there is always something to try.
+func healthyToolsMirrors(mirrors []envtools.ToolsMirror, health toolsMirrorHealth, clock clock.Clock) []envtools.ToolsMirror {
+	now := clock.Now()
+	var healthy []envtools.ToolsMirror
+	for _, mirror := range mirrors {
+		name := toolsMirrorName(mirror.Tools.URL)
+		info, err := health.ToolsMirrorHealth(name)
+		if err != nil {
+			if !errors.IsNotFound(err) {
+				logger.Warningf("cannot get health of tools mirror %q: %v", name, err)
+			}
+			healthy = append(healthy, mirror)
+			continue
+		}
+		if info.ConsecutiveFailures >= toolsMirrorMaxFailures && now.Before(info.LastFailure.Add(toolsMirrorRetryDelay)) {
+			logger.Debugf(
+				"skipping unhealthy tools mirror %q (%d consecutive failures, last error: %s)",
+				name, info.ConsecutiveFailures, info.LastError,
+			)
+			continue
+		}
+		healthy = append(healthy, mirror)
+	}
+	if len(healthy) == 0 {
+		return mirrors
+	}
+	return healthy
+}
+
+type toolsFetchResult struct {
+	index int
+	data  []byte
+	err   error
+}
+
+// fetchToolsFromMirrors fetches the tools with the specified version
+// from each of the given mirrors in parallel, returning the content
+// from the first to respond with data matching the expected size and
+// SHA-256 hash. Outstanding requests are cancelled once a mirror has
+// succeeded. The outcome of each completed request is recorded in
+// the mirror's health.
+//
+// If every mirror fails, the error from the first mirror is returned.
+func fetchToolsFromMirrors(v version.Binary, mirrors []envtools.ToolsMirror, health toolsMirrorHealth) ([]byte, *tools.Tools, error) {
+	if len(mirrors) == 0 {
+		return nil, nil, errors.NotFoundf("%v tools mirrors", v)
+	}
+	cancel := make(chan struct{})
+	results := make(chan toolsFetchResult, len(mirrors))
+	for i, mirror := range mirrors {
+		go func(i int, tools *tools.Tools) {
+			data, err := fetchTools(v, tools, cancel)
+			results <- toolsFetchResult{i, data, err}
+		}(i, mirror.Tools)
+	}
+
+	errs := make([]error, len(mirrors))
+	for range mirrors {
+		result := <-results
+		mirror := mirrors[result.index]
+		name := toolsMirrorName(mirror.Tools.URL)
+		if result.err == errToolsFetchCancelled {
+			continue
+		}
+		if result.err != nil {
+			logger.Warningf("failed to fetch %v tools from %q: %v", v, mirror.Source, result.err)
+			errs[result.index] = result.err
+			if err := health.RecordToolsMirrorFailure(name, result.err); err != nil {
+				logger.Warningf("%v", err)
+			}
+			continue
+		}
+		if err := health.RecordToolsMirrorSuccess(name); err != nil {
+			logger.Warningf("%v", err)
+		}
+		close(cancel)
+		return result.data, mirror.Tools, nil
+	}
+	close(cancel)
+	return nil, nil, errs[0]
+}
+
+var errToolsFetchCancelled = errors.New("tools fetch cancelled")
+
+// fetchTools GETs the tools tarball from tools.URL, verifying its size
+// and SHA-256 hash. If the cancel channel is closed, the request will
+// be abandoned and errToolsFetchCancelled returned.
+func fetchTools(v version.Binary, tools *tools.Tools, cancel <-chan struct{}) ([]byte, error) {
+	req, err := http.NewRequest("GET", tools.URL, nil)
+	if err != nil {
+		return nil, errors.Trace(err)
 	}
+	req.Cancel = cancel
 
 	// No need to verify the server's identity because we verify the SHA-256 hash.
 	logger.Infof("fetching %v tools from %v", v, tools.URL)
-	resp, err := utils.GetNonValidatingHTTPClient().Get(tools.URL)
+	resp, err := utils.GetNonValidatingHTTPClient().Do(req)
 	if err != nil {
+		select {
+		case <-cancel:
+			return nil, errToolsFetchCancelled
+		default:
+		}
 		return nil, err
 	}
 	defer resp.Body.Close()
@@ -146,6 +390,11 @@ func (h *toolsDownloadHandler) fetchAndCacheTools(v version.Binary, stor binarys
 	}
 	data, sha256, err := readAndHash(resp.Body)
 	if err != nil {
+		select {
+		case <-cancel:
+			return nil, errToolsFetchCancelled
+		default:
+		}
 		return nil, err
 	}
 	if int64(len(data)) != tools.Size {
@@ -154,17 +403,7 @@ func (h *toolsDownloadHandler) fetchAndCacheTools(v version.Binary, stor binarys
 	if sha256 != tools.SHA256 {
 		return nil, errors.Errorf("hash mismatch for %s", tools.URL)
 	}
-
-	// Cache tarball in tools storage before returning.
-	metadata := binarystorage.Metadata{
-		Version: v.String(),
-		Size:    tools.Size,
-		SHA256:  tools.SHA256,
-	}
-	if err := stor.Add(bytes.NewReader(data), metadata); err != nil {
-		return nil, errors.Annotate(err, "error caching tools")
-	}
-	return ioutil.NopCloser(bytes.NewReader(data)), nil
+	return data, nil
 }
 
 // sendTools streams the tools tarball to the client.
@@ -173,8 +412,9 @@ func (h *toolsDownloadHandler) sendTools(w http.ResponseWriter, statusCode int,
 	w.Header().Set("Content-Length", fmt.Sprint(len(tarball)))
 	w.WriteHeader(statusCode)
 	if _, err := w.Write(tarball); err != nil {
-		sendError(w, errors.NewBadRequest(errors.Annotatef(err, "failed to write tools"), ""))
-		return
+		// The status has already been sent, so there is
+		// no way to report the error to the client.
+		logger.Errorf("failed to write tools: %v", err)
 	}
 }
 
@@ -197,6 +437,23 @@ func (h *toolsUploadHandler) processPost(r *http.Request, st *state.State) (*too
 		return nil, errors.BadRequestf("expected Content-Type: application/x-tar-gz, got: %v", contentType)
 	}
 
+	// If the client specified the tools' hash, it is verified
+	// before the tools are stored.
+	expectedSHA256, err := expectedUploadSHA256(query, st)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+
+	// The tools are published in the released stream unless
+	// the client specified another, e.g. to stage them in the
+	// proposed stream for testing before they are promoted.
+	stream := query.Get("stream")
+	if stream == "" {
+		stream = envtools.ReleasedStream
+	} else if !validToolsStream(stream) {
+		return nil, errors.BadRequestf("invalid tools stream %q", stream)
+	}
+
 	// Get the server root, so we know how to form the URL in the Tools returned.
 	serverRoot, err := h.getServerRoot(r, query, st)
 	if err != nil {
@@ -219,7 +476,18 @@ func (h *toolsUploadHandler) processPost(r *http.Request, st *state.State) (*too
 			toolsVersions = append(toolsVersions, v)
 		}
 	}
-	return h.handleUpload(r.Body, toolsVersions, serverRoot, st)
+	return h.handleUpload(r.Body, expectedSHA256, toolsVersions, stream, serverRoot, st)
+}
+
+// validToolsStream reports whether tools may be published
+// in the given stream.
+func validToolsStream(stream string) bool {
+	for _, s := range envtools.AllMetadataStreams {
+		if s == stream {
+			return true
+		}
+	}
+	return false
 }
 
 func (h *toolsUploadHandler) getServerRoot(r *http.Request, query url.Values, st *state.State) (string, error) {
@@ -234,8 +502,9 @@ func (h *toolsUploadHandler) getServerRoot(r *http.Request, query url.Values, st
 	return fmt.Sprintf("https://%s/model/%s", r.Host, uuid), nil
 }
 
-// handleUpload uploads the tools data from the reader to env storage as the specified version.
-func (h *toolsUploadHandler) handleUpload(r io.Reader, toolsVersions []version.Binary, serverRoot string, st *state.State) (*tools.Tools, error) {
+// handleUpload uploads the tools data from the reader to env storage as the specified version,
+// published in the specified stream. If expectedSHA256 is non-empty, the data must have that hash.
+func (h *toolsUploadHandler) handleUpload(r io.Reader, expectedSHA256 string, toolsVersions []version.Binary, stream, serverRoot string, st *state.State) (*tools.Tools, error) {
 	// Check if changes are allowed and the command may proceed.
 	blockChecker := common.NewBlockChecker(st)
 	if err := blockChecker.ChangeAllowed(); err != nil {
@@ -255,6 +524,9 @@ func (h *toolsUploadHandler) handleUpload(r io.Reader, toolsVersions []version.B
 	if len(data) == 0 {
 		return nil, errors.BadRequestf("no tools uploaded")
 	}
+	if err := verifyUploadSHA256(expectedSHA256, sha256); err != nil {
+		return nil, errors.Trace(err)
+	}
 
 	// TODO(wallyworld): check integrity of tools tarball.
 
@@ -264,6 +536,7 @@ func (h *toolsUploadHandler) handleUpload(r io.Reader, toolsVersions []version.B
 			Version: v.String(),
 			Size:    int64(len(data)),
 			SHA256:  sha256,
+			Stream:  stream,
 		}
 		logger.Debugf("uploading tools %+v to storage", metadata)
 		if err := storage.Add(bytes.NewReader(data), metadata); err != nil {
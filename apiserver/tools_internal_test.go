@@ -0,0 +1,245 @@
+// Copyright 2016 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package apiserver
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	"github.com/juju/errors"
+	jujutesting "github.com/juju/testing"
+	jc "github.com/juju/testing/checkers"
+	"github.com/juju/version"
+	gc "gopkg.in/check.v1"
+
+	envtools "github.com/juju/juju/environs/tools"
+	"github.com/juju/juju/state"
+	coretesting "github.com/juju/juju/testing"
+	"github.com/juju/juju/tools"
+)
+
+type toolsMirrorsSuite struct {
+	coretesting.BaseSuite
+	health *fakeToolsMirrorHealth
+	vers   version.Binary
+}
+
+var _ = gc.Suite(&toolsMirrorsSuite{})
+
+func (s *toolsMirrorsSuite) SetUpTest(c *gc.C) {
+	s.BaseSuite.SetUpTest(c)
+	s.health = &fakeToolsMirrorHealth{
+		health:    make(map[string]state.ToolsMirrorHealth),
+		successes: make(map[string]int),
+		failures:  make(map[string]int),
+	}
+	s.vers = version.MustParseBinary("2.0.0-xenial-amd64")
+}
+
+func (s *toolsMirrorsSuite) serveTools(c *gc.C, handler http.HandlerFunc) (*httptest.Server, envtools.ToolsMirror) {
+	server := httptest.NewServer(handler)
+	s.AddCleanup(func(*gc.C) { server.Close() })
+	content := "tools-content"
+	return server, envtools.ToolsMirror{
+		Source: server.URL,
+		Tools: &tools.Tools{
+			Version: s.vers,
+			URL:     server.URL + "/tools.tgz",
+			Size:    int64(len(content)),
+			SHA256:  fmt.Sprintf("%x", sha256.Sum256([]byte(content))),
+		},
+	}
+}
+
+func writeContent(content string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, content)
+	}
+}
+
+func (s *toolsMirrorsSuite) TestFetchFirstSuccessWins(c *gc.C) {
+	block := make(chan struct{})
+	defer close(block)
+	slow, slowMirror := s.serveTools(c, func(w http.ResponseWriter, r *http.Request) {
+		<-block
+	})
+	fast, fastMirror := s.serveTools(c, writeContent("tools-content"))
+
+	data, tools, err := fetchToolsFromMirrors(s.vers, []envtools.ToolsMirror{slowMirror, fastMirror}, s.health)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(string(data), gc.Equals, "tools-content")
+	c.Assert(tools, gc.Equals, fastMirror.Tools)
+	c.Assert(s.health.successes[fast.URL], gc.Equals, 1)
+	c.Assert(s.health.failures[slow.URL], gc.Equals, 0)
+}
+
+func (s *toolsMirrorsSuite) TestFetchVerifiesChecksum(c *gc.C) {
+	bad, badMirror := s.serveTools(c, writeContent("tools-c0ntent"))
+	good, goodMirror := s.serveTools(c, writeContent("tools-content"))
+
+	data, tools, err := fetchToolsFromMirrors(s.vers, []envtools.ToolsMirror{badMirror, goodMirror}, s.health)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(string(data), gc.Equals, "tools-content")
+	c.Assert(tools, gc.Equals, goodMirror.Tools)
+	c.Assert(s.health.successes[good.URL], gc.Equals, 1)
+
+	// The bad mirror's failure may or may not have been
+	// recorded, depending on which mirror responded first.
+	c.Assert(s.health.successes[bad.URL], gc.Equals, 0)
+}
+
+func (s *toolsMirrorsSuite) TestFetchAllFail(c *gc.C) {
+	first, firstMirror := s.serveTools(c, writeContent("tools-c0ntent"))
+	second, secondMirror := s.serveTools(c, func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "go away", http.StatusNotFound)
+	})
+
+	_, _, err := fetchToolsFromMirrors(s.vers, []envtools.ToolsMirror{firstMirror, secondMirror}, s.health)
+	c.Assert(err, gc.ErrorMatches, "hash mismatch for .*")
+	c.Assert(s.health.failures[first.URL], gc.Equals, 1)
+	c.Assert(s.health.failures[second.URL], gc.Equals, 1)
+}
+
+func (s *toolsMirrorsSuite) TestFetchNoMirrors(c *gc.C) {
+	_, _, err := fetchToolsFromMirrors(s.vers, nil, s.health)
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+}
+
+func (s *toolsMirrorsSuite) TestHealthyToolsMirrors(c *gc.C) {
+	now := time.Date(2016, 11, 1, 12, 0, 0, 0, time.UTC)
+	mirror := func(host string) envtools.ToolsMirror {
+		return envtools.ToolsMirror{Tools: &tools.Tools{URL: "https://" + host + "/tools.tgz"}}
+	}
+	mirrors := []envtools.ToolsMirror{mirror("a"), mirror("b"), mirror("c")}
+	s.health.health["https://a"] = state.ToolsMirrorHealth{
+		ConsecutiveFailures: toolsMirrorMaxFailures,
+		LastFailure:         now.Add(-time.Minute),
+	}
+	s.health.health["https://b"] = state.ToolsMirrorHealth{
+		ConsecutiveFailures: toolsMirrorMaxFailures,
+		LastFailure:         now.Add(-toolsMirrorRetryDelay),
+	}
+	s.health.health["https://c"] = state.ToolsMirrorHealth{
+		ConsecutiveFailures: 1,
+		LastFailure:         now,
+	}
+	healthy := healthyToolsMirrors(mirrors, s.health, jujutesting.NewClock(now))
+	c.Assert(healthy, jc.DeepEquals, mirrors[1:])
+}
+
+func (s *toolsMirrorsSuite) TestHealthyToolsMirrorsRetryDelay(c *gc.C) {
+	now := time.Date(2016, 11, 1, 12, 0, 0, 0, time.UTC)
+	clock := jujutesting.NewClock(now)
+	mirrors := []envtools.ToolsMirror{
+		{Tools: &tools.Tools{URL: "https://a/tools.tgz"}},
+		{Tools: &tools.Tools{URL: "https://b/tools.tgz"}},
+	}
+	s.health.health["https://a"] = state.ToolsMirrorHealth{
+		ConsecutiveFailures: toolsMirrorMaxFailures,
+		LastFailure:         now,
+	}
+
+	clock.Advance(toolsMirrorRetryDelay - time.Second)
+	healthy := healthyToolsMirrors(mirrors, s.health, clock)
+	c.Assert(healthy, jc.DeepEquals, mirrors[1:])
+
+	clock.Advance(time.Second)
+	healthy = healthyToolsMirrors(mirrors, s.health, clock)
+	c.Assert(healthy, jc.DeepEquals, mirrors)
+}
+
+func (s *toolsMirrorsSuite) TestHealthyToolsMirrorsAllUnhealthy(c *gc.C) {
+	now := time.Date(2016, 11, 1, 12, 0, 0, 0, time.UTC)
+	mirrors := []envtools.ToolsMirror{{Tools: &tools.Tools{URL: "https://a/tools.tgz"}}}
+	s.health.health["https://a"] = state.ToolsMirrorHealth{
+		ConsecutiveFailures: toolsMirrorMaxFailures,
+		LastFailure:         now,
+	}
+	healthy := healthyToolsMirrors(mirrors, s.health, jujutesting.NewClock(now))
+	c.Assert(healthy, jc.DeepEquals, mirrors)
+}
+
+type fakeToolsMirrorHealth struct {
+	health    map[string]state.ToolsMirrorHealth
+	successes map[string]int
+	failures  map[string]int
+}
+
+func (f *fakeToolsMirrorHealth) ToolsMirrorHealth(mirror string) (state.ToolsMirrorHealth, error) {
+	health, ok := f.health[mirror]
+	if !ok {
+		return state.ToolsMirrorHealth{}, errors.NotFoundf("tools mirror %q", mirror)
+	}
+	return health, nil
+}
+
+func (f *fakeToolsMirrorHealth) RecordToolsMirrorSuccess(mirror string) error {
+	f.successes[mirror]++
+	return nil
+}
+
+func (f *fakeToolsMirrorHealth) RecordToolsMirrorFailure(mirror string, cause error) error {
+	f.failures[mirror]++
+	return nil
+}
+
+type toolsDeltaCacheSuite struct {
+	coretesting.BaseSuite
+}
+
+var _ = gc.Suite(&toolsDeltaCacheSuite{})
+
+func (s *toolsDeltaCacheSuite) TestGetCaches(c *gc.C) {
+	var cache toolsDeltaCache
+	var computed int
+	compute := func() ([]byte, error) {
+		computed++
+		return []byte("delta"), nil
+	}
+	key := toolsDeltaKey{baseSHA256: "a", targetSHA256: "b"}
+	for i := 0; i < 2; i++ {
+		toolsDelta, err := cache.get(key, compute)
+		c.Assert(err, jc.ErrorIsNil)
+		c.Assert(string(toolsDelta), gc.Equals, "delta")
+	}
+	c.Assert(computed, gc.Equals, 1)
+}
+
+func (s *toolsDeltaCacheSuite) TestGetErrorNotCached(c *gc.C) {
+	var cache toolsDeltaCache
+	key := toolsDeltaKey{baseSHA256: "a", targetSHA256: "b"}
+	_, err := cache.get(key, func() ([]byte, error) {
+		return nil, errors.New("boom")
+	})
+	c.Assert(err, gc.ErrorMatches, "boom")
+	toolsDelta, err := cache.get(key, func() ([]byte, error) {
+		return []byte("delta"), nil
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(string(toolsDelta), gc.Equals, "delta")
+}
+
+func (s *toolsDeltaCacheSuite) TestGetEvictsLeastRecentlyUsed(c *gc.C) {
+	var cache toolsDeltaCache
+	var computed []string
+	get := func(base string) {
+		key := toolsDeltaKey{baseSHA256: base, targetSHA256: "target"}
+		_, err := cache.get(key, func() ([]byte, error) {
+			computed = append(computed, base)
+			return []byte(base), nil
+		})
+		c.Assert(err, jc.ErrorIsNil)
+	}
+	for i := 0; i < maxToolsDeltas; i++ {
+		get(fmt.Sprint(i))
+	}
+	get("0")
+	get("new")
+	get("0")
+	get("1")
+	c.Assert(computed, jc.DeepEquals, []string{"0", "1", "2", "3", "new", "1"})
+}
@@ -4,6 +4,7 @@
 package apiserver_test
 
 import (
+	"bytes"
 	"crypto/sha256"
 	"encoding/json"
 	"fmt"
@@ -13,11 +14,14 @@ import (
 	"net/url"
 	"path"
 	"strings"
+	"time"
 
 	"github.com/juju/errors"
+	jujutesting "github.com/juju/testing"
 	jc "github.com/juju/testing/checkers"
 	"github.com/juju/utils"
 	"github.com/juju/utils/arch"
+	"github.com/juju/utils/clock"
 	"github.com/juju/utils/series"
 	"github.com/juju/version"
 	gc "gopkg.in/check.v1"
@@ -25,8 +29,10 @@ import (
 
 	apiauthentication "github.com/juju/juju/api/authentication"
 	apitesting "github.com/juju/juju/api/testing"
+	"github.com/juju/juju/apiserver/common"
 	commontesting "github.com/juju/juju/apiserver/common/testing"
 	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/controller"
 	envtesting "github.com/juju/juju/environs/testing"
 	envtools "github.com/juju/juju/environs/tools"
 	toolstesting "github.com/juju/juju/environs/tools/testing"
@@ -35,6 +41,7 @@ import (
 	"github.com/juju/juju/testing"
 	"github.com/juju/juju/testing/factory"
 	coretools "github.com/juju/juju/tools"
+	"github.com/juju/juju/tools/delta"
 	jujuversion "github.com/juju/juju/version"
 )
 
@@ -201,6 +208,70 @@ func (s *toolsSuite) TestUpload(c *gc.C) {
 	c.Assert(allMetadata, jc.DeepEquals, []binarystorage.Metadata{metadata})
 }
 
+func (s *toolsSuite) TestUploadWithChecksum(c *gc.C) {
+	expectedTools, v, toolPath := s.setupToolsForUpload(c)
+	vers := v.String()
+	resp := s.uploadRequest(
+		c, s.toolsURI(c, "?binaryVersion="+vers+"&sha256="+expectedTools[0].SHA256),
+		"application/x-tar-gz", toolPath,
+	)
+	expectedTools[0].URL = fmt.Sprintf("%s/model/%s/tools/%s", s.baseURL(c), s.State.ModelUUID(), vers)
+	s.assertUploadResponse(c, resp, expectedTools[0])
+}
+
+func (s *toolsSuite) TestUploadWithStream(c *gc.C) {
+	expectedTools, v, toolPath := s.setupToolsForUpload(c)
+	vers := v.String()
+	resp := s.uploadRequest(
+		c, s.toolsURI(c, "?binaryVersion="+vers+"&stream=proposed"),
+		"application/x-tar-gz", toolPath,
+	)
+	expectedTools[0].URL = fmt.Sprintf("%s/model/%s/tools/%s", s.baseURL(c), s.State.ModelUUID(), vers)
+	s.assertUploadResponse(c, resp, expectedTools[0])
+
+	metadata, _ := s.getToolsFromStorage(c, s.State, vers)
+	c.Assert(metadata.Stream, gc.Equals, "proposed")
+}
+
+func (s *toolsSuite) TestUploadInvalidStream(c *gc.C) {
+	_, v, toolPath := s.setupToolsForUpload(c)
+	resp := s.uploadRequest(
+		c, s.toolsURI(c, "?binaryVersion="+v.String()+"&stream=bogus"),
+		"application/x-tar-gz", toolPath,
+	)
+	s.assertErrorResponse(c, resp, http.StatusBadRequest, `invalid tools stream "bogus"`)
+}
+
+func (s *toolsSuite) TestUploadChecksumMismatch(c *gc.C) {
+	expectedTools, v, toolPath := s.setupToolsForUpload(c)
+	vers := v.String()
+	wrongSHA256 := strings.Repeat("0", 64)
+	resp := s.uploadRequest(
+		c, s.toolsURI(c, "?binaryVersion="+vers+"&sha256="+wrongSHA256),
+		"application/x-tar-gz", toolPath,
+	)
+	s.assertErrorResponse(c, resp, http.StatusBadRequest, fmt.Sprintf(
+		"upload corrupted: expected sha256 %s, got %s",
+		wrongSHA256, expectedTools[0].SHA256,
+	))
+
+	// Nothing should have been stored.
+	storage, err := s.State.ToolsStorage()
+	c.Assert(err, jc.ErrorIsNil)
+	defer storage.Close()
+	_, _, err = storage.Open(vers)
+	c.Assert(errors.IsNotFound(err), jc.IsTrue)
+}
+
+func (s *toolsSuite) TestUploadInvalidChecksum(c *gc.C) {
+	_, v, toolPath := s.setupToolsForUpload(c)
+	resp := s.uploadRequest(
+		c, s.toolsURI(c, "?binaryVersion="+v.String()+"&sha256=abc"),
+		"application/x-tar-gz", toolPath,
+	)
+	s.assertErrorResponse(c, resp, http.StatusBadRequest, `invalid sha256 argument "abc"`)
+}
+
 func (s *toolsSuite) TestBlockUpload(c *gc.C) {
 	// Make some fake tools.
 	_, v, toolPath := s.setupToolsForUpload(c)
@@ -360,6 +431,27 @@ func (s *toolsSuite) TestDownloadFetchesAndCaches(c *gc.C) {
 	c.Assert(string(cachedData), gc.Equals, string(data))
 }
 
+func (s *toolsSuite) TestDownloadRefetchesCorruptTools(c *gc.C) {
+	// The tools in binarystorage do not match their recorded hash,
+	// so the download request causes the API server to discard them,
+	// and fetch them again from simplestreams.
+	vers := version.MustParseBinary("1.23.0-trusty-amd64")
+	stor := s.DefaultToolsStorage
+	envtesting.RemoveTools(c, stor, "released")
+	tools := envtesting.AssertUploadFakeToolsVersions(c, stor, "released", "released", vers)[0]
+	s.storeFakeTools(c, s.State, strings.Repeat("!", int(tools.Size)), binarystorage.Metadata{
+		Version: tools.Version.String(),
+		Size:    tools.Size,
+		SHA256:  tools.SHA256,
+	})
+	data := s.testDownload(c, tools, "")
+
+	metadata, cachedData := s.getToolsFromStorage(c, s.State, tools.Version.String())
+	c.Assert(metadata.Size, gc.Equals, tools.Size)
+	c.Assert(metadata.SHA256, gc.Equals, tools.SHA256)
+	c.Assert(string(cachedData), gc.Equals, string(data))
+}
+
 func (s *toolsSuite) TestDownloadFetchesAndVerifiesSize(c *gc.C) {
 	// Upload fake tools, then upload over the top so the SHA256 hash does not match.
 	s.PatchValue(&jujuversion.Current, testing.FakeVersionNumber)
@@ -374,8 +466,11 @@ func (s *toolsSuite) TestDownloadFetchesAndVerifiesSize(c *gc.C) {
 	err := stor.Put(envtools.StorageName(tools.Version, "released"), strings.NewReader("!"), 1)
 	c.Assert(err, jc.ErrorIsNil)
 
+	// A bad mirror is a transient failure on the server's side:
+	// the client is told to try again later.
 	resp := s.downloadRequest(c, tools.Version, "")
-	s.assertErrorResponse(c, resp, http.StatusBadRequest, "error fetching tools: size mismatch for .*")
+	s.assertErrorResponse(c, resp, http.StatusServiceUnavailable, "error fetching tools: size mismatch for .*")
+	c.Assert(resp.Header.Get("Retry-After"), gc.Equals, "60")
 	s.assertToolsNotStored(c, tools.Version.String())
 }
 
@@ -394,12 +489,79 @@ func (s *toolsSuite) TestDownloadFetchesAndVerifiesHash(c *gc.C) {
 	err := stor.Put(envtools.StorageName(tools.Version, "released"), strings.NewReader(sameSize), tools.Size)
 	c.Assert(err, jc.ErrorIsNil)
 
+	// A bad mirror is a transient failure on the server's side:
+	// the client is told to try again later.
 	resp := s.downloadRequest(c, tools.Version, "")
-	s.assertErrorResponse(c, resp, http.StatusBadRequest, "error fetching tools: hash mismatch for .*")
+	s.assertErrorResponse(c, resp, http.StatusServiceUnavailable, "error fetching tools: hash mismatch for .*")
+	c.Assert(resp.Header.Get("Retry-After"), gc.Equals, "60")
 	s.assertToolsNotStored(c, tools.Version.String())
 }
 
-func (s *toolsSuite) storeFakeTools(c *gc.C, st *state.State, content string, metadata binarystorage.Metadata) *coretools.Tools {
+// storeToolsDeltaBases stores two versions of fake tools, whose
+// jujud binaries differ only slightly, returning the tarball
+// content and tools of each.
+func (s *toolsSuite) storeToolsDeltaBases(c *gc.C) (baseData []byte, base *coretools.Tools, targetData []byte, target *coretools.Tools) {
+	jujud := strings.Repeat("0123456789abcdef", 4096) + utils.RandomString(1024, utils.LowerAlpha)
+	baseData, baseSHA256 := testing.TarGz(testing.NewTarFile("jujud", 0755, jujud))
+	targetData, targetSHA256 := testing.TarGz(testing.NewTarFile("jujud", 0755, jujud+"!"))
+	base = s.storeFakeTools(c, s.State, string(baseData), binarystorage.Metadata{
+		Version: "2.0.0-trusty-amd64",
+		Size:    int64(len(baseData)),
+		SHA256:  baseSHA256,
+	})
+	target = s.storeFakeTools(c, s.State, string(targetData), binarystorage.Metadata{
+		Version: "2.0.1-trusty-amd64",
+		Size:    int64(len(targetData)),
+		SHA256:  targetSHA256,
+	})
+	return baseData, base, targetData, target
+}
+
+func (s *toolsSuite) deltaDownloadRequest(c *gc.C, v version.Binary, base *coretools.Tools) *http.Response {
+	query := url.Values{
+		"base-version": {base.Version.String()},
+		"base-sha256":  {base.SHA256},
+	}
+	url := s.toolsURL(c, query.Encode())
+	url.Path = fmt.Sprintf("/model/%s/tools/%s", s.State.ModelUUID(), v)
+	return s.sendRequest(c, httpRequestParams{method: "GET", url: url.String()})
+}
+
+func (s *toolsSuite) TestDownloadDelta(c *gc.C) {
+	baseData, base, targetData, target := s.storeToolsDeltaBases(c)
+	resp := s.deltaDownloadRequest(c, target.Version, base)
+	body := assertResponse(c, resp, http.StatusOK, params.ContentTypeToolsDelta)
+	c.Assert(len(body) < len(targetData), jc.IsTrue)
+
+	baseFiles, err := delta.ReadTarball(bytes.NewReader(baseData))
+	c.Assert(err, jc.ErrorIsNil)
+	files, err := delta.ApplyTools(bytes.NewReader(body), func(name string) ([]byte, error) {
+		c.Assert(name, gc.Equals, "jujud")
+		return baseFiles[0].Data, nil
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	targetFiles, err := delta.ReadTarball(bytes.NewReader(targetData))
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(files, jc.DeepEquals, targetFiles)
+}
+
+func (s *toolsSuite) TestDownloadDeltaBaseMismatch(c *gc.C) {
+	_, base, targetData, target := s.storeToolsDeltaBases(c)
+	base.SHA256 = target.SHA256
+	resp := s.deltaDownloadRequest(c, target.Version, base)
+	body := assertResponse(c, resp, http.StatusOK, "application/x-tar-gz")
+	c.Assert(string(body), gc.Equals, string(targetData))
+}
+
+func (s *toolsSuite) TestDownloadDeltaBaseNotFound(c *gc.C) {
+	_, base, targetData, target := s.storeToolsDeltaBases(c)
+	base.Version = version.MustParseBinary("1.25.0-trusty-amd64")
+	resp := s.deltaDownloadRequest(c, target.Version, base)
+	body := assertResponse(c, resp, http.StatusOK, "application/x-tar-gz")
+	c.Assert(string(body), gc.Equals, string(targetData))
+}
+
+func (s *toolsCommonSuite) storeFakeTools(c *gc.C, st *state.State, content string, metadata binarystorage.Metadata) *coretools.Tools {
 	storage, err := st.ToolsStorage()
 	c.Assert(err, jc.ErrorIsNil)
 	defer storage.Close()
@@ -454,6 +616,67 @@ func (s *toolsSuite) testDownload(c *gc.C, tools *coretools.Tools, uuid string)
 	return data
 }
 
+func (s *toolsCommonSuite) signedDownloadRequest(c *gc.C, v version.Binary, modelUUID string, clock clock.Clock) *http.Response {
+	signer, err := common.NewStateToolsURLSigner(s.State, clock)
+	c.Assert(err, jc.ErrorIsNil)
+	url := s.toolsURL(c, "")
+	url.Path = fmt.Sprintf("/model/%s/tools/%s", s.State.ModelUUID(), v)
+	signed, err := signer.SignURL(url.String(), modelUUID, v)
+	c.Assert(err, jc.ErrorIsNil)
+	return s.sendRequest(c, httpRequestParams{method: "GET", url: signed})
+}
+
+func (s *toolsSuite) TestDownloadSigned(c *gc.C) {
+	v := version.Binary{
+		Number: jujuversion.Current,
+		Arch:   arch.HostArch(),
+		Series: series.HostSeries(),
+	}
+	s.storeFakeTools(c, s.State, "abc", binarystorage.Metadata{
+		Version: v.String(),
+		Size:    3,
+		SHA256:  "ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad",
+	})
+	resp := s.signedDownloadRequest(c, v, s.State.ModelUUID(), clock.WallClock)
+	defer resp.Body.Close()
+	data, err := ioutil.ReadAll(resp.Body)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(string(data), gc.Equals, "abc")
+}
+
+func (s *toolsSuite) TestDownloadSignedExpired(c *gc.C) {
+	v := version.Binary{
+		Number: jujuversion.Current,
+		Arch:   arch.HostArch(),
+		Series: series.HostSeries(),
+	}
+	past := jujutesting.NewClock(time.Now().Add(-controller.DefaultToolsURLExpiry))
+	resp := s.signedDownloadRequest(c, v, s.State.ModelUUID(), past)
+	s.assertErrorResponse(c, resp, http.StatusUnauthorized, "tools URL expired")
+}
+
+func (s *toolsSuite) TestDownloadSignedForOtherModel(c *gc.C) {
+	v := version.Binary{
+		Number: jujuversion.Current,
+		Arch:   arch.HostArch(),
+		Series: series.HostSeries(),
+	}
+	resp := s.signedDownloadRequest(c, v, "deadbeef-0bad-400d-8000-4b1d0d06f00d", clock.WallClock)
+	s.assertErrorResponse(c, resp, http.StatusUnauthorized, "tools URL signature not valid")
+}
+
+func (s *toolsSuite) TestDownloadSignedInvalid(c *gc.C) {
+	v := version.Binary{
+		Number: jujuversion.Current,
+		Arch:   arch.HostArch(),
+		Series: series.HostSeries(),
+	}
+	url := s.toolsURL(c, "expires=9999999999&signature=abcdef")
+	url.Path = fmt.Sprintf("/model/%s/tools/%s", s.State.ModelUUID(), v)
+	resp := s.sendRequest(c, httpRequestParams{method: "GET", url: url.String()})
+	s.assertErrorResponse(c, resp, http.StatusUnauthorized, "tools URL signature not valid")
+}
+
 func (s *toolsSuite) TestDownloadRejectsWrongModelUUIDPath(c *gc.C) {
 	current := version.Binary{
 		Number: jujuversion.Current,
@@ -464,6 +687,61 @@ func (s *toolsSuite) TestDownloadRejectsWrongModelUUIDPath(c *gc.C) {
 	s.assertErrorResponse(c, resp, http.StatusNotFound, `unknown model: "dead-beef-123456"`)
 }
 
+type toolsRequireSignedURLsSuite struct {
+	toolsCommonSuite
+}
+
+var _ = gc.Suite(&toolsRequireSignedURLsSuite{})
+
+func (s *toolsRequireSignedURLsSuite) SetUpTest(c *gc.C) {
+	s.ControllerConfigAttrs = map[string]interface{}{
+		controller.RequireSignedToolsURLsKey: true,
+	}
+	s.toolsCommonSuite.SetUpTest(c)
+}
+
+func (s *toolsRequireSignedURLsSuite) storeTools(c *gc.C) version.Binary {
+	v := version.Binary{
+		Number: jujuversion.Current,
+		Arch:   arch.HostArch(),
+		Series: series.HostSeries(),
+	}
+	s.storeFakeTools(c, s.State, "abc", binarystorage.Metadata{
+		Version: v.String(),
+		Size:    3,
+		SHA256:  "ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad",
+	})
+	return v
+}
+
+func (s *toolsRequireSignedURLsSuite) TestDownloadUnsignedRejected(c *gc.C) {
+	v := s.storeTools(c)
+	resp := s.downloadRequest(c, v, s.State.ModelUUID())
+	s.assertErrorResponse(c, resp, http.StatusUnauthorized, "tools URL not signed: .*")
+}
+
+func (s *toolsRequireSignedURLsSuite) TestDownloadUnsignedAuthenticated(c *gc.C) {
+	v := s.storeTools(c)
+	url := s.toolsURL(c, "")
+	url.Path = fmt.Sprintf("/model/%s/tools/%s", s.State.ModelUUID(), v)
+	resp := s.authRequest(c, httpRequestParams{method: "GET", url: url.String()})
+	defer resp.Body.Close()
+	c.Assert(resp.StatusCode, gc.Equals, http.StatusOK)
+	data, err := ioutil.ReadAll(resp.Body)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(string(data), gc.Equals, "abc")
+}
+
+func (s *toolsRequireSignedURLsSuite) TestDownloadSigned(c *gc.C) {
+	v := s.storeTools(c)
+	resp := s.signedDownloadRequest(c, v, s.State.ModelUUID(), clock.WallClock)
+	defer resp.Body.Close()
+	c.Assert(resp.StatusCode, gc.Equals, http.StatusOK)
+	data, err := ioutil.ReadAll(resp.Body)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(string(data), gc.Equals, "abc")
+}
+
 type toolsWithMacaroonsSuite struct {
 	toolsCommonSuite
 }
@@ -554,3 +832,36 @@ func (s *toolsWithMacaroonsSuite) TestCanPostWithLocalLogin(c *gc.C) {
 func (s *toolsWithMacaroonsSuite) doer() func(*http.Request) (*http.Response, error) {
 	return bakeryDo(nil, bakeryGetError)
 }
+
+type toolsRequireChecksumSuite struct {
+	toolsCommonSuite
+}
+
+var _ = gc.Suite(&toolsRequireChecksumSuite{})
+
+func (s *toolsRequireChecksumSuite) SetUpTest(c *gc.C) {
+	s.ControllerConfigAttrs = map[string]interface{}{
+		controller.RequireUploadChecksumKey: true,
+	}
+	s.toolsCommonSuite.SetUpTest(c)
+}
+
+func (s *toolsRequireChecksumSuite) TestUploadRequiresChecksum(c *gc.C) {
+	localStorage := c.MkDir()
+	vers := version.MustParseBinary("1.9.0-quantal-amd64")
+	expectedTools := toolstesting.MakeToolsWithCheckSum(c, localStorage, "released", []string{vers.String()})
+	toolPath := path.Join(localStorage, envtools.StorageName(vers, "released"))
+
+	resp := s.uploadRequest(
+		c, s.toolsURI(c, "?binaryVersion="+vers.String()),
+		"application/x-tar-gz", toolPath,
+	)
+	s.assertErrorResponse(c, resp, http.StatusBadRequest, "expected sha256 argument")
+
+	resp = s.uploadRequest(
+		c, s.toolsURI(c, "?binaryVersion="+vers.String()+"&sha256="+expectedTools[0].SHA256),
+		"application/x-tar-gz", toolPath,
+	)
+	expectedTools[0].URL = fmt.Sprintf("%s/model/%s/tools/%s", s.baseURL(c), s.State.ModelUUID(), vers)
+	s.assertUploadResponse(c, resp, expectedTools[0])
+}
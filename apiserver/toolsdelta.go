@@ -0,0 +1,145 @@
+// Copyright 2016 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package apiserver
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"fmt"
+	"net/http"
+	"sync"
+
+	"github.com/juju/errors"
+	"github.com/juju/version"
+
+	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/state"
+	"github.com/juju/juju/tools/delta"
+)
+
+// maxToolsDeltas is the number of deltas between agent binaries that
+// are cached by the API server. An upgrade typically involves only
+// a few base versions, so a small number suffices.
+const maxToolsDeltas = 4
+
+// toolsDeltaCache caches deltas between agent binaries, so that the
+// delta for an upgrade is computed once, however many agents request
+// it.
+type toolsDeltaCache struct {
+	mu sync.Mutex
+
+	// entries holds the cached deltas, most recently used last.
+	entries []*toolsDeltaEntry
+}
+
+type toolsDeltaKey struct {
+	baseSHA256   string
+	targetSHA256 string
+}
+
+type toolsDeltaEntry struct {
+	key   toolsDeltaKey
+	done  chan struct{}
+	delta []byte
+	err   error
+}
+
+// get returns the delta between the agent binaries with the given
+// hashes, calling compute to make it if it is not cached. Concurrent
+// requests for the same delta wait for a single computation. Failed
+// computations are not cached.
+func (c *toolsDeltaCache) get(key toolsDeltaKey, compute func() ([]byte, error)) ([]byte, error) {
+	c.mu.Lock()
+	for i, entry := range c.entries {
+		if entry.key != key {
+			continue
+		}
+		c.entries = append(append(c.entries[:i:i], c.entries[i+1:]...), entry)
+		c.mu.Unlock()
+		<-entry.done
+		return entry.delta, entry.err
+	}
+	entry := &toolsDeltaEntry{key: key, done: make(chan struct{})}
+	c.entries = append(c.entries, entry)
+	if len(c.entries) > maxToolsDeltas {
+		c.entries = c.entries[len(c.entries)-maxToolsDeltas:]
+	}
+	c.mu.Unlock()
+
+	entry.delta, entry.err = compute()
+	close(entry.done)
+	if entry.err != nil {
+		c.remove(entry)
+	}
+	return entry.delta, entry.err
+}
+
+func (c *toolsDeltaCache) remove(entry *toolsDeltaEntry) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for i, e := range c.entries {
+		if e == entry {
+			c.entries = append(c.entries[:i:i], c.entries[i+1:]...)
+			return
+		}
+	}
+}
+
+// processGetDelta returns a delta that transforms the agent binaries
+// that the client reports having, in its "base-version" and
+// "base-sha256" query parameters, into the requested binaries, whose
+// tarball is given. If the client does not report its binaries,
+// processGetDelta returns nil.
+func (h *toolsDownloadHandler) processGetDelta(r *http.Request, st *state.State, tarball []byte) ([]byte, error) {
+	query := r.URL.Query()
+	baseVersionParam := query.Get("base-version")
+	baseSHA256 := query.Get("base-sha256")
+	if baseVersionParam == "" || baseSHA256 == "" {
+		return nil, nil
+	}
+	baseVersion, err := version.ParseBinary(baseVersionParam)
+	if err != nil {
+		return nil, errors.Annotate(err, "parsing base version")
+	}
+	storage, err := st.ToolsStorage()
+	if err != nil {
+		return nil, errors.Annotate(err, "getting tools storage")
+	}
+	defer storage.Close()
+	metadata, reader, err := storage.Open(baseVersion.String())
+	if err != nil {
+		return nil, errors.Annotatef(err, "opening %v tools", baseVersion)
+	}
+	defer reader.Close()
+	if metadata.SHA256 != baseSHA256 {
+		return nil, errors.Errorf("%v tools sha256 mismatch", baseVersion)
+	}
+
+	key := toolsDeltaKey{
+		baseSHA256:   baseSHA256,
+		targetSHA256: fmt.Sprintf("%x", sha256.Sum256(tarball)),
+	}
+	return h.ctxt.srv.toolsDeltas.get(key, func() ([]byte, error) {
+		logger.Infof("computing delta from %v tools", baseVersion)
+		baseFiles, err := delta.ReadTarball(reader)
+		if err != nil {
+			return nil, errors.Annotatef(err, "reading %v tools", baseVersion)
+		}
+		targetFiles, err := delta.ReadTarball(bytes.NewReader(tarball))
+		if err != nil {
+			return nil, errors.Annotate(err, "reading tools")
+		}
+		return delta.DiffTools(baseFiles, targetFiles)
+	})
+}
+
+// sendToolsDelta sends a delta between agent binaries to the client.
+func (h *toolsDownloadHandler) sendToolsDelta(w http.ResponseWriter, toolsDelta []byte) {
+	w.Header().Set("Content-Type", params.ContentTypeToolsDelta)
+	w.Header().Set("Content-Length", fmt.Sprint(len(toolsDelta)))
+	w.WriteHeader(http.StatusOK)
+	if _, err := w.Write(toolsDelta); err != nil {
+		logger.Errorf("failed to write tools delta: %v", err)
+	}
+}
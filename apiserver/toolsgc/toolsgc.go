@@ -0,0 +1,98 @@
+// Copyright 2016 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+// Package toolsgc implements the API endpoint for garbage collecting
+// unused agent binaries from a model's tools storage.
+package toolsgc
+
+import (
+	"github.com/juju/errors"
+	"gopkg.in/juju/names.v2"
+
+	"github.com/juju/juju/apiserver/common"
+	"github.com/juju/juju/apiserver/facade"
+	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/permission"
+	"github.com/juju/juju/state"
+	"github.com/juju/juju/state/binarystorage"
+)
+
+func init() {
+	common.RegisterStandardFacade("ToolsGC", 1, newFacade)
+}
+
+// Backend defines the State API used by the toolsgc facade.
+type Backend interface {
+	ModelTag() names.ModelTag
+	UnusedAgentBinaries(keepLatest int) ([]binarystorage.Metadata, error)
+	RemoveUnusedAgentBinaries(keepLatest int) ([]binarystorage.Metadata, error)
+}
+
+// Facade implements the ToolsGC API. Model administrators may report
+// the agent binaries that would be removed, as a dry run; only the
+// controller may remove them.
+type Facade struct {
+	backend    Backend
+	authorizer facade.Authorizer
+}
+
+func newFacade(st *state.State, _ facade.Resources, authorizer facade.Authorizer) (*Facade, error) {
+	return New(st, authorizer)
+}
+
+// New returns a new ToolsGC API facade.
+func New(backend Backend, authorizer facade.Authorizer) (*Facade, error) {
+	if !authorizer.AuthModelManager() && !authorizer.AuthClient() {
+		return nil, common.ErrPerm
+	}
+	return &Facade{
+		backend:    backend,
+		authorizer: authorizer,
+	}, nil
+}
+
+// UnusedAgentBinaries reports the agent binaries that would be removed
+// under the given retention policy, without removing them.
+func (f *Facade) UnusedAgentBinaries(args params.ToolsGCArgs) (params.AgentBinariesResult, error) {
+	if !f.authorizer.AuthModelManager() {
+		isModelAdmin, err := f.authorizer.HasPermission(permission.AdminAccess, f.backend.ModelTag())
+		if err != nil {
+			return params.AgentBinariesResult{}, errors.Trace(err)
+		}
+		if !isModelAdmin {
+			return params.AgentBinariesResult{}, common.ErrPerm
+		}
+	}
+	unused, err := f.backend.UnusedAgentBinaries(args.KeepLatest)
+	if err != nil {
+		return params.AgentBinariesResult{}, errors.Trace(err)
+	}
+	return agentBinariesResult(unused), nil
+}
+
+// RemoveUnusedAgentBinaries removes the agent binaries that are not
+// retained under the given retention policy, returning those removed.
+func (f *Facade) RemoveUnusedAgentBinaries(args params.ToolsGCArgs) (params.AgentBinariesResult, error) {
+	if !f.authorizer.AuthModelManager() {
+		return params.AgentBinariesResult{}, common.ErrPerm
+	}
+	removed, err := f.backend.RemoveUnusedAgentBinaries(args.KeepLatest)
+	if err != nil {
+		return params.AgentBinariesResult{}, errors.Trace(err)
+	}
+	return agentBinariesResult(removed), nil
+}
+
+func agentBinariesResult(metadata []binarystorage.Metadata) params.AgentBinariesResult {
+	result := params.AgentBinariesResult{
+		Binaries: make([]params.AgentBinary, len(metadata)),
+	}
+	for i, m := range metadata {
+		result.Binaries[i] = params.AgentBinary{
+			Version: m.Version,
+			Size:    m.Size,
+			SHA256:  m.SHA256,
+		}
+	}
+	return result
+}
@@ -0,0 +1,136 @@
+// Copyright 2016 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package toolsgc_test
+
+import (
+	"github.com/juju/errors"
+	jujutesting "github.com/juju/testing"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+	"gopkg.in/juju/names.v2"
+
+	"github.com/juju/juju/apiserver/common"
+	"github.com/juju/juju/apiserver/params"
+	apiservertesting "github.com/juju/juju/apiserver/testing"
+	"github.com/juju/juju/apiserver/toolsgc"
+	"github.com/juju/juju/state/binarystorage"
+	coretesting "github.com/juju/juju/testing"
+)
+
+type toolsGCSuite struct {
+	jujutesting.IsolationSuite
+	backend    mockBackend
+	authorizer apiservertesting.FakeAuthorizer
+}
+
+var _ = gc.Suite(&toolsGCSuite{})
+
+func (s *toolsGCSuite) SetUpTest(c *gc.C) {
+	s.IsolationSuite.SetUpTest(c)
+	s.backend = mockBackend{
+		binaries: []binarystorage.Metadata{{
+			Version: "1.25.0-trusty-amd64",
+			Size:    123,
+			SHA256:  "abc",
+		}},
+	}
+	s.authorizer = apiservertesting.FakeAuthorizer{
+		Tag:            names.NewMachineTag("0"),
+		EnvironManager: true,
+	}
+}
+
+func (s *toolsGCSuite) newFacade(c *gc.C) *toolsgc.Facade {
+	facade, err := toolsgc.New(&s.backend, &s.authorizer)
+	c.Assert(err, jc.ErrorIsNil)
+	return facade
+}
+
+var expectedResult = params.AgentBinariesResult{
+	Binaries: []params.AgentBinary{{
+		Version: "1.25.0-trusty-amd64",
+		Size:    123,
+		SHA256:  "abc",
+	}},
+}
+
+func (s *toolsGCSuite) TestNewNotAuthorized(c *gc.C) {
+	s.authorizer.EnvironManager = false
+	_, err := toolsgc.New(&s.backend, &s.authorizer)
+	c.Assert(err, gc.Equals, common.ErrPerm)
+}
+
+func (s *toolsGCSuite) TestUnusedAgentBinaries(c *gc.C) {
+	result, err := s.newFacade(c).UnusedAgentBinaries(params.ToolsGCArgs{KeepLatest: 2})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(result, jc.DeepEquals, expectedResult)
+	s.backend.CheckCalls(c, []jujutesting.StubCall{
+		{"UnusedAgentBinaries", []interface{}{2}},
+	})
+}
+
+func (s *toolsGCSuite) TestUnusedAgentBinariesModelAdmin(c *gc.C) {
+	s.authorizer = apiservertesting.FakeAuthorizer{
+		Tag:      names.NewUserTag("admin"),
+		AdminTag: names.NewUserTag("admin"),
+	}
+	result, err := s.newFacade(c).UnusedAgentBinaries(params.ToolsGCArgs{KeepLatest: 2})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(result, jc.DeepEquals, expectedResult)
+}
+
+func (s *toolsGCSuite) TestUnusedAgentBinariesNotModelAdmin(c *gc.C) {
+	s.authorizer = apiservertesting.FakeAuthorizer{
+		Tag:      names.NewUserTag("bob"),
+		AdminTag: names.NewUserTag("admin"),
+	}
+	_, err := s.newFacade(c).UnusedAgentBinaries(params.ToolsGCArgs{KeepLatest: 2})
+	c.Assert(err, gc.Equals, common.ErrPerm)
+	s.backend.CheckNoCalls(c)
+}
+
+func (s *toolsGCSuite) TestUnusedAgentBinariesError(c *gc.C) {
+	s.backend.SetErrors(errors.New("boom"))
+	_, err := s.newFacade(c).UnusedAgentBinaries(params.ToolsGCArgs{KeepLatest: 2})
+	c.Assert(err, gc.ErrorMatches, "boom")
+}
+
+func (s *toolsGCSuite) TestRemoveUnusedAgentBinaries(c *gc.C) {
+	result, err := s.newFacade(c).RemoveUnusedAgentBinaries(params.ToolsGCArgs{KeepLatest: 3})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(result, jc.DeepEquals, expectedResult)
+	s.backend.CheckCalls(c, []jujutesting.StubCall{
+		{"RemoveUnusedAgentBinaries", []interface{}{3}},
+	})
+}
+
+func (s *toolsGCSuite) TestRemoveUnusedAgentBinariesClient(c *gc.C) {
+	// Clients may only report what would be removed.
+	s.authorizer = apiservertesting.FakeAuthorizer{
+		Tag:      names.NewUserTag("admin"),
+		AdminTag: names.NewUserTag("admin"),
+	}
+	_, err := s.newFacade(c).RemoveUnusedAgentBinaries(params.ToolsGCArgs{KeepLatest: 3})
+	c.Assert(err, gc.Equals, common.ErrPerm)
+	s.backend.CheckNoCalls(c)
+}
+
+type mockBackend struct {
+	jujutesting.Stub
+	binaries []binarystorage.Metadata
+}
+
+func (b *mockBackend) ModelTag() names.ModelTag {
+	return coretesting.ModelTag
+}
+
+func (b *mockBackend) UnusedAgentBinaries(keepLatest int) ([]binarystorage.Metadata, error) {
+	b.MethodCall(b, "UnusedAgentBinaries", keepLatest)
+	return b.binaries, b.NextErr()
+}
+
+func (b *mockBackend) RemoveUnusedAgentBinaries(keepLatest int) ([]binarystorage.Metadata, error) {
+	b.MethodCall(b, "RemoveUnusedAgentBinaries", keepLatest)
+	return b.binaries, b.NextErr()
+}
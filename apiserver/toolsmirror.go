@@ -0,0 +1,48 @@
+// Copyright 2016 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package apiserver
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/juju/errors"
+
+	"github.com/juju/juju/apiserver/params"
+)
+
+// toolsMirrorHandler serves the simplestreams metadata of a model's
+// tools mirror. The agent binaries referred to by the metadata are
+// served by a toolsDownloadHandler.
+type toolsMirrorHandler struct {
+	ctxt httpContext
+}
+
+func (h *toolsMirrorHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	st, err := h.ctxt.stateForRequestUnauthenticated(r)
+	if err != nil {
+		sendError(w, err)
+		return
+	}
+
+	switch r.Method {
+	case "GET":
+		filePath := "streams/v1/" + r.URL.Query().Get(":file")
+		reader, length, err := st.ToolsMirrorMetadata(filePath)
+		if err != nil {
+			sendError(w, err)
+			return
+		}
+		defer reader.Close()
+		w.Header().Set("Content-Type", params.ContentTypeJSON)
+		w.Header().Set("Content-Length", fmt.Sprint(length))
+		w.WriteHeader(http.StatusOK)
+		if _, err := io.Copy(w, reader); err != nil {
+			logger.Errorf("failed to write tools mirror metadata %q: %v", filePath, err)
+		}
+	default:
+		sendError(w, errors.MethodNotAllowedf("unsupported method: %q", r.Method))
+	}
+}
@@ -0,0 +1,99 @@
+// Copyright 2016 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+// Package toolsmirror implements the API endpoint for generating the
+// simplestreams metadata of a model's tools mirror, which describes the
+// agent binaries cached in the model's tools storage.
+package toolsmirror
+
+import (
+	"github.com/juju/errors"
+	"github.com/juju/loggo"
+	"github.com/juju/utils/clock"
+	"github.com/juju/version"
+
+	"github.com/juju/juju/apiserver/common"
+	"github.com/juju/juju/apiserver/facade"
+	"github.com/juju/juju/environs/config"
+	envtools "github.com/juju/juju/environs/tools"
+	"github.com/juju/juju/state"
+	"github.com/juju/juju/state/binarystorage"
+	coretools "github.com/juju/juju/tools"
+)
+
+var logger = loggo.GetLogger("juju.apiserver.toolsmirror")
+
+func init() {
+	common.RegisterStandardFacade("ToolsMirror", 1, newFacade)
+}
+
+// Backend defines the State API used by the toolsmirror facade.
+type Backend interface {
+	ModelConfig() (*config.Config, error)
+	ToolsStorage() (binarystorage.StorageCloser, error)
+	SetToolsMirrorMetadata(files map[string][]byte) error
+}
+
+// Facade implements the ToolsMirror API. Only the controller may
+// update a model's tools mirror.
+type Facade struct {
+	backend Backend
+	clock   clock.Clock
+}
+
+func newFacade(st *state.State, _ facade.Resources, authorizer facade.Authorizer) (*Facade, error) {
+	return New(st, authorizer, clock.WallClock)
+}
+
+// New returns a new ToolsMirror API facade.
+func New(backend Backend, authorizer facade.Authorizer, clock clock.Clock) (*Facade, error) {
+	if !authorizer.AuthModelManager() {
+		return nil, common.ErrPerm
+	}
+	return &Facade{
+		backend: backend,
+		clock:   clock,
+	}, nil
+}
+
+// UpdateToolsMirror regenerates the simplestreams metadata of the
+// model's tools mirror from the agent binaries in the model's tools
+// storage. The binaries are published in the model's agent stream.
+func (f *Facade) UpdateToolsMirror() error {
+	cfg, err := f.backend.ModelConfig()
+	if err != nil {
+		return errors.Trace(err)
+	}
+	storage, err := f.backend.ToolsStorage()
+	if err != nil {
+		return errors.Trace(err)
+	}
+	defer storage.Close()
+	all, err := storage.AllMetadata()
+	if err != nil {
+		return errors.Annotate(err, "cannot get agent binaries")
+	}
+
+	toolsList := make(coretools.List, 0, len(all))
+	for _, m := range all {
+		v, err := version.ParseBinary(m.Version)
+		if err != nil {
+			logger.Warningf("ignoring agent binary with invalid version %q", m.Version)
+			continue
+		}
+		toolsList = append(toolsList, &coretools.Tools{
+			Version: v,
+			Size:    m.Size,
+			SHA256:  m.SHA256,
+		})
+	}
+	metadata, err := envtools.MarshalControllerMirrorMetadata(toolsList, cfg.AgentStream(), f.clock.Now())
+	if err != nil {
+		return errors.Annotate(err, "cannot generate tools mirror metadata")
+	}
+	files := make(map[string][]byte, len(metadata))
+	for _, m := range metadata {
+		files[m.Path] = m.Data
+	}
+	return errors.Trace(f.backend.SetToolsMirrorMetadata(files))
+}
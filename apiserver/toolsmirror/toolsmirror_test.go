@@ -0,0 +1,139 @@
+// Copyright 2016 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package toolsmirror_test
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/juju/errors"
+	jujutesting "github.com/juju/testing"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+	"gopkg.in/juju/names.v2"
+
+	"github.com/juju/juju/apiserver/common"
+	apiservertesting "github.com/juju/juju/apiserver/testing"
+	"github.com/juju/juju/apiserver/toolsmirror"
+	"github.com/juju/juju/environs/config"
+	"github.com/juju/juju/state/binarystorage"
+	coretesting "github.com/juju/juju/testing"
+)
+
+type toolsMirrorSuite struct {
+	jujutesting.IsolationSuite
+	backend    mockBackend
+	authorizer apiservertesting.FakeAuthorizer
+	clock      *jujutesting.Clock
+}
+
+var _ = gc.Suite(&toolsMirrorSuite{})
+
+func (s *toolsMirrorSuite) SetUpTest(c *gc.C) {
+	s.IsolationSuite.SetUpTest(c)
+	cfg, err := config.New(config.UseDefaults, coretesting.FakeConfig().Merge(coretesting.Attrs{
+		"agent-stream": "proposed",
+	}))
+	c.Assert(err, jc.ErrorIsNil)
+	s.backend = mockBackend{
+		config: cfg,
+		binaries: []binarystorage.Metadata{{
+			Version: "2.0.1-xenial-amd64",
+			Size:    123,
+			SHA256:  "abc",
+		}, {
+			Version: "not-a-version",
+		}},
+	}
+	s.authorizer = apiservertesting.FakeAuthorizer{
+		Tag:            names.NewMachineTag("0"),
+		EnvironManager: true,
+	}
+	s.clock = jujutesting.NewClock(time.Date(2016, 10, 1, 0, 0, 0, 0, time.UTC))
+}
+
+func (s *toolsMirrorSuite) newFacade(c *gc.C) *toolsmirror.Facade {
+	facade, err := toolsmirror.New(&s.backend, &s.authorizer, s.clock)
+	c.Assert(err, jc.ErrorIsNil)
+	return facade
+}
+
+func (s *toolsMirrorSuite) TestNewNotAuthorized(c *gc.C) {
+	s.authorizer = apiservertesting.FakeAuthorizer{
+		Tag:      names.NewUserTag("admin"),
+		AdminTag: names.NewUserTag("admin"),
+	}
+	_, err := toolsmirror.New(&s.backend, &s.authorizer, s.clock)
+	c.Assert(err, gc.Equals, common.ErrPerm)
+}
+
+func (s *toolsMirrorSuite) TestUpdateToolsMirror(c *gc.C) {
+	err := s.newFacade(c).UpdateToolsMirror()
+	c.Assert(err, jc.ErrorIsNil)
+	s.backend.CheckCallNames(c, "ModelConfig", "ToolsStorage", "AllMetadata", "Close", "SetToolsMirrorMetadata")
+
+	files := s.backend.Calls()[4].Args[0].(map[string][]byte)
+	c.Assert(files, gc.HasLen, 2)
+	c.Assert(files["streams/v1/index2.json"], gc.NotNil)
+
+	var products struct {
+		Updated  string `json:"updated"`
+		Products map[string]struct {
+			Versions map[string]struct {
+				Items map[string]map[string]interface{} `json:"items"`
+			} `json:"versions"`
+		} `json:"products"`
+	}
+	err = json.Unmarshal(files["streams/v1/com.ubuntu.juju-proposed-tools.json"], &products)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(products.Updated, gc.Equals, "Sat, 01 Oct 2016 00:00:00 +0000")
+	c.Assert(products.Products, gc.HasLen, 1)
+	item := products.Products["com.ubuntu.juju:16.04:amd64"].Versions["20161001"].Items["2.0.1-xenial-amd64"]
+	c.Assert(item["path"], gc.Equals, "tools/2.0.1-xenial-amd64")
+	c.Assert(item["size"], gc.Equals, float64(123))
+	c.Assert(item["sha256"], gc.Equals, "abc")
+}
+
+func (s *toolsMirrorSuite) TestUpdateToolsMirrorError(c *gc.C) {
+	s.backend.SetErrors(nil, nil, errors.New("boom"))
+	err := s.newFacade(c).UpdateToolsMirror()
+	c.Assert(err, gc.ErrorMatches, "cannot get agent binaries: boom")
+	s.backend.CheckCallNames(c, "ModelConfig", "ToolsStorage", "AllMetadata", "Close")
+}
+
+type mockBackend struct {
+	jujutesting.Stub
+	config   *config.Config
+	binaries []binarystorage.Metadata
+}
+
+func (b *mockBackend) ModelConfig() (*config.Config, error) {
+	b.MethodCall(b, "ModelConfig")
+	return b.config, b.NextErr()
+}
+
+func (b *mockBackend) ToolsStorage() (binarystorage.StorageCloser, error) {
+	b.MethodCall(b, "ToolsStorage")
+	return &mockStorage{backend: b}, b.NextErr()
+}
+
+func (b *mockBackend) SetToolsMirrorMetadata(files map[string][]byte) error {
+	b.MethodCall(b, "SetToolsMirrorMetadata", files)
+	return b.NextErr()
+}
+
+type mockStorage struct {
+	binarystorage.Storage
+	backend *mockBackend
+}
+
+func (s *mockStorage) AllMetadata() ([]binarystorage.Metadata, error) {
+	s.backend.MethodCall(s, "AllMetadata")
+	return s.backend.binaries, s.backend.NextErr()
+}
+
+func (s *mockStorage) Close() error {
+	s.backend.MethodCall(s, "Close")
+	return s.backend.NextErr()
+}
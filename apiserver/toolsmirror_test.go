@@ -0,0 +1,94 @@
+// Copyright 2016 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package apiserver_test
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+
+	jc "github.com/juju/testing/checkers"
+	"github.com/juju/version"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/state/binarystorage"
+)
+
+type toolsMirrorSuite struct {
+	toolsCommonSuite
+}
+
+var _ = gc.Suite(&toolsMirrorSuite{})
+
+func (s *toolsMirrorSuite) mirrorRequest(c *gc.C, uuid, path string) *http.Response {
+	url := s.toolsURL(c, "")
+	if uuid == "" {
+		url.Path = "/tools-mirror/" + path
+	} else {
+		url.Path = fmt.Sprintf("/model/%s/tools-mirror/%s", uuid, path)
+	}
+	return s.sendRequest(c, httpRequestParams{method: "GET", url: url.String()})
+}
+
+func (s *toolsMirrorSuite) TestMetadata(c *gc.C) {
+	err := s.State.SetToolsMirrorMetadata(map[string][]byte{
+		"streams/v1/index2.json": []byte(`{"index": true}`),
+	})
+	c.Assert(err, jc.ErrorIsNil)
+
+	for _, uuid := range []string{"", s.State.ModelUUID()} {
+		resp := s.mirrorRequest(c, uuid, "streams/v1/index2.json")
+		s.assertGetFileResponse(c, resp, `{"index": true}`, params.ContentTypeJSON)
+	}
+}
+
+func (s *toolsMirrorSuite) TestMetadataOtherModel(c *gc.C) {
+	envState := s.setupOtherModel(c)
+	err := envState.SetToolsMirrorMetadata(map[string][]byte{
+		"streams/v1/index2.json": []byte(`{"other": true}`),
+	})
+	c.Assert(err, jc.ErrorIsNil)
+
+	resp := s.mirrorRequest(c, envState.ModelUUID(), "streams/v1/index2.json")
+	s.assertGetFileResponse(c, resp, `{"other": true}`, params.ContentTypeJSON)
+
+	resp = s.mirrorRequest(c, "", "streams/v1/index2.json")
+	s.assertErrorResponse(c, resp, http.StatusNotFound, `tools mirror metadata "streams/v1/index2.json" not found`)
+}
+
+func (s *toolsMirrorSuite) TestMetadataNotFound(c *gc.C) {
+	resp := s.mirrorRequest(c, "", "streams/v1/index2.json")
+	s.assertErrorResponse(c, resp, http.StatusNotFound, `tools mirror metadata "streams/v1/index2.json" not found`)
+}
+
+func (s *toolsMirrorSuite) TestMetadataRequiresGET(c *gc.C) {
+	url := s.toolsURL(c, "")
+	url.Path = "/tools-mirror/streams/v1/index2.json"
+	resp := s.sendRequest(c, httpRequestParams{method: "PUT", url: url.String()})
+	s.assertErrorResponse(c, resp, http.StatusMethodNotAllowed, `unsupported method: "PUT"`)
+}
+
+func (s *toolsMirrorSuite) TestDownload(c *gc.C) {
+	v := version.MustParseBinary("2.0.0-xenial-amd64")
+	storage, err := s.State.ToolsStorage()
+	c.Assert(err, jc.ErrorIsNil)
+	defer storage.Close()
+	err = storage.Add(strings.NewReader("abc"), binarystorage.Metadata{
+		Version: v.String(),
+		Size:    3,
+		SHA256:  "ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad",
+	})
+	c.Assert(err, jc.ErrorIsNil)
+
+	for _, uuid := range []string{"", s.State.ModelUUID()} {
+		resp := s.mirrorRequest(c, uuid, "tools/"+v.String())
+		defer resp.Body.Close()
+		c.Assert(resp.StatusCode, gc.Equals, http.StatusOK)
+		data, err := ioutil.ReadAll(resp.Body)
+		c.Assert(err, jc.ErrorIsNil)
+		c.Assert(string(data), gc.Equals, "abc")
+	}
+}
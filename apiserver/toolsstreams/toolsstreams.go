@@ -0,0 +1,167 @@
+// Copyright 2016 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+// Package toolsstreams implements the API endpoint for promoting the
+// agent binaries in a model's tools storage from one stream to another,
+// e.g. from proposed to released, without uploading them again.
+package toolsstreams
+
+import (
+	"github.com/juju/errors"
+	"github.com/juju/version"
+	"gopkg.in/juju/names.v2"
+
+	"github.com/juju/juju/apiserver/common"
+	"github.com/juju/juju/apiserver/facade"
+	"github.com/juju/juju/apiserver/params"
+	envtools "github.com/juju/juju/environs/tools"
+	"github.com/juju/juju/permission"
+	"github.com/juju/juju/state"
+	"github.com/juju/juju/state/binarystorage"
+)
+
+func init() {
+	common.RegisterStandardFacade("ToolsStreams", 1, newFacade)
+}
+
+// Backend defines the State API used by the toolsstreams facade.
+type Backend interface {
+	ModelTag() names.ModelTag
+	ControllerTag() names.ControllerTag
+	IsController() bool
+	ToolsStorage() (binarystorage.StorageCloser, error)
+	ModelToolsStorage() (binarystorage.StorageCloser, error)
+}
+
+// Facade implements the ToolsStreams API. Model administrators may
+// promote the agent binaries stored in a hosted model; promoting those
+// stored in the controller model, which every model sees, requires
+// controller superuser access.
+type Facade struct {
+	backend    Backend
+	authorizer facade.Authorizer
+}
+
+func newFacade(st *state.State, _ facade.Resources, authorizer facade.Authorizer) (*Facade, error) {
+	return New(st, authorizer)
+}
+
+// New returns a new ToolsStreams API facade.
+func New(backend Backend, authorizer facade.Authorizer) (*Facade, error) {
+	if !authorizer.AuthClient() {
+		return nil, common.ErrPerm
+	}
+	return &Facade{
+		backend:    backend,
+		authorizer: authorizer,
+	}, nil
+}
+
+// PromoteTools publishes the agent binaries of each of the given
+// versions, for all series and architectures, in the given stream.
+// The binaries stay in tools storage; only the stream they are
+// published in changes. Models offered binaries from that stream
+// will see the promoted version when they next look for upgrades.
+func (f *Facade) PromoteTools(args params.PromoteToolsArgs) (params.ErrorResults, error) {
+	isSuperuser, err := f.authorizer.HasPermission(permission.SuperuserAccess, f.backend.ControllerTag())
+	if err != nil {
+		return params.ErrorResults{}, errors.Trace(err)
+	}
+	isModelAdmin, err := f.authorizer.HasPermission(permission.AdminAccess, f.backend.ModelTag())
+	if err != nil {
+		return params.ErrorResults{}, errors.Trace(err)
+	}
+	if !isModelAdmin && !isSuperuser {
+		return params.ErrorResults{}, common.ErrPerm
+	}
+	storage, err := f.backend.ToolsStorage()
+	if err != nil {
+		return params.ErrorResults{}, errors.Trace(err)
+	}
+	defer storage.Close()
+	modelStorage, err := f.backend.ModelToolsStorage()
+	if err != nil {
+		return params.ErrorResults{}, errors.Trace(err)
+	}
+	defer modelStorage.Close()
+
+	results := params.ErrorResults{
+		Results: make([]params.ErrorResult, len(args.Args)),
+	}
+	for i, arg := range args.Args {
+		err := f.promoteTools(storage, modelStorage, arg.Version, arg.Stream, isSuperuser)
+		results.Results[i].Error = common.ServerError(err)
+	}
+	return results, nil
+}
+
+func (f *Facade) promoteTools(
+	storage, modelStorage binarystorage.Storage,
+	number version.Number, stream string,
+	isSuperuser bool,
+) error {
+	if !validStream(stream) {
+		return errors.NotValidf("stream %q", stream)
+	}
+	all, err := storage.AllMetadata()
+	if err != nil {
+		return errors.Annotate(err, "cannot get agent binaries")
+	}
+	var versions []string
+	for _, m := range all {
+		v, err := version.ParseBinary(m.Version)
+		if err != nil || v.Number != number {
+			continue
+		}
+		versions = append(versions, m.Version)
+	}
+	if len(versions) == 0 {
+		return errors.NotFoundf("agent binaries for version %s", number)
+	}
+
+	// Check that all of the binaries may be promoted
+	// before promoting any, so that a version is not
+	// left partially promoted.
+	if !isSuperuser {
+		for _, v := range versions {
+			shared, err := f.isControllerTools(modelStorage, v)
+			if err != nil {
+				return errors.Trace(err)
+			}
+			if shared {
+				return common.ErrPerm
+			}
+		}
+	}
+	for _, v := range versions {
+		if err := storage.SetStream(v, stream); err != nil {
+			return errors.Annotatef(err, "cannot promote agent binary %s", v)
+		}
+	}
+	return nil
+}
+
+// isControllerTools reports whether the agent binary with the given
+// version is stored in the controller model, and so is seen by every
+// model, rather than in the hosted model alone.
+func (f *Facade) isControllerTools(modelStorage binarystorage.Storage, v string) (bool, error) {
+	if f.backend.IsController() {
+		return true, nil
+	}
+	_, err := modelStorage.Metadata(v)
+	if errors.IsNotFound(err) {
+		return true, nil
+	} else if err != nil {
+		return false, errors.Annotatef(err, "cannot get agent binary %s", v)
+	}
+	return false, nil
+}
+
+func validStream(stream string) bool {
+	for _, s := range envtools.AllMetadataStreams {
+		if s == stream {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,260 @@
+// Copyright 2016 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package toolsstreams_test
+
+import (
+	"github.com/juju/errors"
+	jujutesting "github.com/juju/testing"
+	jc "github.com/juju/testing/checkers"
+	"github.com/juju/version"
+	gc "gopkg.in/check.v1"
+	"gopkg.in/juju/names.v2"
+
+	"github.com/juju/juju/apiserver/common"
+	"github.com/juju/juju/apiserver/params"
+	apiservertesting "github.com/juju/juju/apiserver/testing"
+	"github.com/juju/juju/apiserver/toolsstreams"
+	"github.com/juju/juju/permission"
+	"github.com/juju/juju/state/binarystorage"
+	coretesting "github.com/juju/juju/testing"
+)
+
+type toolsStreamsSuite struct {
+	jujutesting.IsolationSuite
+	backend    mockBackend
+	authorizer fakeAuthorizer
+}
+
+var _ = gc.Suite(&toolsStreamsSuite{})
+
+func (s *toolsStreamsSuite) SetUpTest(c *gc.C) {
+	s.IsolationSuite.SetUpTest(c)
+	s.backend = mockBackend{}
+	s.backend.storage = &mockStorage{
+		backend: &s.backend,
+		binaries: []binarystorage.Metadata{
+			{Version: "2.1.0-trusty-amd64", Stream: "proposed"},
+			{Version: "2.1.0-xenial-amd64", Stream: "proposed"},
+			{Version: "2.0.2-xenial-amd64", Stream: "released"},
+		},
+	}
+	s.backend.modelStorage = &mockStorage{backend: &s.backend}
+	s.authorizer = fakeAuthorizer{
+		FakeAuthorizer: apiservertesting.FakeAuthorizer{
+			Tag: names.NewUserTag("bob"),
+		},
+		superuser:  true,
+		modelAdmin: true,
+	}
+}
+
+func (s *toolsStreamsSuite) newFacade(c *gc.C) *toolsstreams.Facade {
+	facade, err := toolsstreams.New(&s.backend, &s.authorizer)
+	c.Assert(err, jc.ErrorIsNil)
+	return facade
+}
+
+func (s *toolsStreamsSuite) TestNewNotClient(c *gc.C) {
+	s.authorizer.FakeAuthorizer = apiservertesting.FakeAuthorizer{
+		Tag:            names.NewMachineTag("0"),
+		EnvironManager: true,
+	}
+	_, err := toolsstreams.New(&s.backend, &s.authorizer)
+	c.Assert(err, gc.Equals, common.ErrPerm)
+}
+
+func (s *toolsStreamsSuite) TestPromoteToolsNotModelAdmin(c *gc.C) {
+	s.authorizer.superuser = false
+	s.authorizer.modelAdmin = false
+	_, err := s.newFacade(c).PromoteTools(params.PromoteToolsArgs{
+		Args: []params.PromoteToolsArg{{Version: version.MustParse("2.1.0"), Stream: "released"}},
+	})
+	c.Assert(err, gc.Equals, common.ErrPerm)
+	s.backend.CheckNoCalls(c)
+}
+
+func (s *toolsStreamsSuite) TestPromoteTools(c *gc.C) {
+	results, err := s.newFacade(c).PromoteTools(params.PromoteToolsArgs{
+		Args: []params.PromoteToolsArg{
+			{Version: version.MustParse("2.1.0"), Stream: "released"},
+			{Version: version.MustParse("2.2.0"), Stream: "released"},
+			{Version: version.MustParse("2.0.2"), Stream: "bogus"},
+		},
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(results, jc.DeepEquals, params.ErrorResults{
+		Results: []params.ErrorResult{
+			{},
+			{&params.Error{
+				Message: "agent binaries for version 2.2.0 not found",
+				Code:    params.CodeNotFound,
+			}},
+			{&params.Error{
+				Message: `stream "bogus" not valid`,
+				Code:    params.CodeNotValid,
+			}},
+		},
+	})
+	s.backend.CheckCalls(c, []jujutesting.StubCall{
+		{"ToolsStorage", nil},
+		{"ModelToolsStorage", nil},
+		{"AllMetadata", nil},
+		{"SetStream", []interface{}{"2.1.0-trusty-amd64", "released"}},
+		{"SetStream", []interface{}{"2.1.0-xenial-amd64", "released"}},
+		{"AllMetadata", nil},
+		{"Close", nil},
+		{"Close", nil},
+	})
+}
+
+func (s *toolsStreamsSuite) TestPromoteToolsModelAdminModelTools(c *gc.C) {
+	s.authorizer.superuser = false
+	s.backend.modelStorage.binaries = []binarystorage.Metadata{
+		{Version: "2.1.0-trusty-amd64", Stream: "proposed"},
+		{Version: "2.1.0-xenial-amd64", Stream: "proposed"},
+	}
+	results, err := s.newFacade(c).PromoteTools(params.PromoteToolsArgs{
+		Args: []params.PromoteToolsArg{{Version: version.MustParse("2.1.0"), Stream: "released"}},
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(results, jc.DeepEquals, params.ErrorResults{
+		Results: []params.ErrorResult{{}},
+	})
+	s.backend.CheckCallNames(c,
+		"ToolsStorage", "ModelToolsStorage", "AllMetadata",
+		"Metadata", "Metadata", "SetStream", "SetStream",
+		"Close", "Close",
+	)
+}
+
+func (s *toolsStreamsSuite) TestPromoteToolsModelAdminControllerTools(c *gc.C) {
+	// The binaries are stored in the controller model,
+	// so promoting them changes what every model sees.
+	s.authorizer.superuser = false
+	s.backend.modelStorage.binaries = []binarystorage.Metadata{
+		{Version: "2.1.0-trusty-amd64", Stream: "proposed"},
+	}
+	results, err := s.newFacade(c).PromoteTools(params.PromoteToolsArgs{
+		Args: []params.PromoteToolsArg{{Version: version.MustParse("2.1.0"), Stream: "released"}},
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(results, jc.DeepEquals, params.ErrorResults{
+		Results: []params.ErrorResult{{&params.Error{
+			Message: "permission denied",
+			Code:    params.CodeUnauthorized,
+		}}},
+	})
+	s.backend.CheckCallNames(c,
+		"ToolsStorage", "ModelToolsStorage", "AllMetadata",
+		"Metadata", "Metadata", "Close", "Close",
+	)
+}
+
+func (s *toolsStreamsSuite) TestPromoteToolsModelAdminControllerModel(c *gc.C) {
+	s.authorizer.superuser = false
+	s.backend.isController = true
+	s.backend.modelStorage.binaries = s.backend.storage.binaries
+	results, err := s.newFacade(c).PromoteTools(params.PromoteToolsArgs{
+		Args: []params.PromoteToolsArg{{Version: version.MustParse("2.1.0"), Stream: "released"}},
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(results.Results, gc.HasLen, 1)
+	c.Assert(results.Results[0].Error, jc.Satisfies, params.IsCodeUnauthorized)
+	s.backend.CheckCallNames(c, "ToolsStorage", "ModelToolsStorage", "AllMetadata", "Close", "Close")
+}
+
+func (s *toolsStreamsSuite) TestPromoteToolsSetStreamError(c *gc.C) {
+	s.backend.SetErrors(nil, nil, nil, errors.New("boom"))
+	results, err := s.newFacade(c).PromoteTools(params.PromoteToolsArgs{
+		Args: []params.PromoteToolsArg{{Version: version.MustParse("2.1.0"), Stream: "released"}},
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(results.Results, gc.HasLen, 1)
+	c.Assert(results.Results[0].Error, gc.ErrorMatches, "cannot promote agent binary 2.1.0-trusty-amd64: boom")
+}
+
+type fakeAuthorizer struct {
+	apiservertesting.FakeAuthorizer
+	superuser  bool
+	modelAdmin bool
+}
+
+func (a fakeAuthorizer) HasPermission(operation permission.Access, target names.Tag) (bool, error) {
+	switch target.Kind() {
+	case names.ControllerTagKind:
+		return operation == permission.SuperuserAccess && a.superuser, nil
+	case names.ModelTagKind:
+		return operation == permission.AdminAccess && a.modelAdmin, nil
+	}
+	return false, nil
+}
+
+type mockBackend struct {
+	jujutesting.Stub
+	isController bool
+	storage      *mockStorage
+	modelStorage *mockStorage
+}
+
+func (b *mockBackend) ModelTag() names.ModelTag {
+	return coretesting.ModelTag
+}
+
+func (b *mockBackend) ControllerTag() names.ControllerTag {
+	return coretesting.ControllerTag
+}
+
+func (b *mockBackend) IsController() bool {
+	return b.isController
+}
+
+func (b *mockBackend) ToolsStorage() (binarystorage.StorageCloser, error) {
+	b.MethodCall(b, "ToolsStorage")
+	if err := b.NextErr(); err != nil {
+		return nil, err
+	}
+	return b.storage, nil
+}
+
+func (b *mockBackend) ModelToolsStorage() (binarystorage.StorageCloser, error) {
+	b.MethodCall(b, "ModelToolsStorage")
+	if err := b.NextErr(); err != nil {
+		return nil, err
+	}
+	return b.modelStorage, nil
+}
+
+type mockStorage struct {
+	binarystorage.Storage
+	backend  *mockBackend
+	binaries []binarystorage.Metadata
+}
+
+func (s *mockStorage) AllMetadata() ([]binarystorage.Metadata, error) {
+	s.backend.MethodCall(s, "AllMetadata")
+	return s.binaries, s.backend.NextErr()
+}
+
+func (s *mockStorage) Metadata(version string) (binarystorage.Metadata, error) {
+	s.backend.MethodCall(s, "Metadata", version)
+	if err := s.backend.NextErr(); err != nil {
+		return binarystorage.Metadata{}, err
+	}
+	for _, m := range s.binaries {
+		if m.Version == version {
+			return m, nil
+		}
+	}
+	return binarystorage.Metadata{}, errors.NotFoundf("agent binary %s", version)
+}
+
+func (s *mockStorage) SetStream(version, stream string) error {
+	s.backend.MethodCall(s, "SetStream", version, stream)
+	return s.backend.NextErr()
+}
+
+func (s *mockStorage) Close() error {
+	s.backend.MethodCall(s, "Close")
+	return s.backend.NextErr()
+}
@@ -576,6 +576,56 @@ func (u *UniterAPIV3) charmModifiedVersion(tagStr string, canAccess func(names.T
 	return service.CharmModifiedVersion(), nil
 }
 
+// ApplicationPaused returns whether the applications of all given units
+// or services are paused.
+func (u *UniterAPIV3) ApplicationPaused(args params.Entities) (params.BoolResults, error) {
+	results := params.BoolResults{
+		Results: make([]params.BoolResult, len(args.Entities)),
+	}
+
+	accessUnitOrService := common.AuthEither(u.accessUnit, u.accessService)
+	canAccess, err := accessUnitOrService()
+	if err != nil {
+		return results, err
+	}
+	for i, entity := range args.Entities {
+		paused, err := u.applicationPaused(entity.Tag, canAccess)
+		if err != nil {
+			results.Results[i].Error = common.ServerError(err)
+			continue
+		}
+		results.Results[i].Result = paused
+	}
+	return results, nil
+}
+
+func (u *UniterAPIV3) applicationPaused(tagStr string, canAccess func(names.Tag) bool) (bool, error) {
+	tag, err := names.ParseTag(tagStr)
+	if err != nil {
+		return false, common.ErrPerm
+	}
+	if !canAccess(tag) {
+		return false, common.ErrPerm
+	}
+	unitOrService, err := u.st.FindEntity(tag)
+	if err != nil {
+		return false, err
+	}
+	var service *state.Application
+	switch entity := unitOrService.(type) {
+	case *state.Application:
+		service = entity
+	case *state.Unit:
+		service, err = entity.Application()
+		if err != nil {
+			return false, err
+		}
+	default:
+		return false, errors.BadRequestf("type %T cannot be paused", entity)
+	}
+	return service.IsPaused(), nil
+}
+
 // CharmURL returns the charm URL for all given units or services.
 func (u *UniterAPIV3) CharmURL(args params.Entities) (params.StringBoolResults, error) {
 	result := params.StringBoolResults{
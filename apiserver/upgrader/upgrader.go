@@ -6,6 +6,7 @@ package upgrader
 import (
 	"github.com/juju/errors"
 	"github.com/juju/loggo"
+	"github.com/juju/utils/clock"
 	"github.com/juju/version"
 	"gopkg.in/juju/names.v2"
 
@@ -83,7 +84,13 @@ func NewUpgraderAPI(
 	if err != nil {
 		return nil, err
 	}
-	urlGetter := common.NewToolsURLGetter(env.UUID(), st)
+	// Tools URLs are signed, so that agents can download their
+	// tools when the controller requires signed tools URLs.
+	urlSigner, err := common.NewStateToolsURLSigner(st, clock.WallClock)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	urlGetter := common.NewSignedToolsURLGetter(env.UUID(), st, urlSigner)
 	configGetter := stateenvirons.EnvironConfigGetter{st}
 	return &UpgraderAPI{
 		ToolsGetter: common.NewToolsGetter(st, configGetter, st, urlGetter, getCanReadWrite),
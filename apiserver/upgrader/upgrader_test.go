@@ -171,7 +171,8 @@ func (s *upgraderSuite) TestToolsForAgent(c *gc.C) {
 		agentTools := results.Results[0].ToolsList[0]
 		url := fmt.Sprintf("https://%s/model/%s/tools/%s",
 			s.APIState.Addr(), coretesting.ModelTag.Id(), current)
-		c.Check(agentTools.URL, gc.Equals, url)
+		c.Check(agentTools.URL, jc.HasPrefix, url+"?")
+		c.Check(agentTools.URL, gc.Matches, `.*\?expires=[0-9]+&signature=[0-9a-f]{64}`)
 		c.Check(agentTools.Version, gc.DeepEquals, current)
 	}
 	assertTools()
@@ -0,0 +1,53 @@
+// Copyright 2016 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package apiserver
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/url"
+	"strings"
+
+	"github.com/juju/errors"
+
+	"github.com/juju/juju/state"
+)
+
+// uploadSHA256Param is the name of the query parameter with which
+// clients specify the expected SHA-256 hash of uploaded content.
+const uploadSHA256Param = "sha256"
+
+// expectedUploadSHA256 returns the hex-encoded SHA-256 hash that the
+// client expects the uploaded content to have, or the empty string if
+// the client did not specify one. If the controller is configured to
+// require upload checksums, a request without one is rejected.
+func expectedUploadSHA256(query url.Values, st *state.State) (string, error) {
+	expected := strings.ToLower(query.Get(uploadSHA256Param))
+	if expected != "" {
+		if b, err := hex.DecodeString(expected); err != nil || len(b) != sha256.Size {
+			return "", errors.BadRequestf("invalid %s argument %q", uploadSHA256Param, expected)
+		}
+		return expected, nil
+	}
+	controllerConfig, err := st.ControllerConfig()
+	if err != nil {
+		return "", errors.Trace(err)
+	}
+	if controllerConfig.RequireUploadChecksum() {
+		return "", errors.BadRequestf("expected %s argument", uploadSHA256Param)
+	}
+	return "", nil
+}
+
+// verifyUploadSHA256 returns an error if the expected hash is set,
+// and does not match the actual hash of the uploaded content.
+func verifyUploadSHA256(expected, actual string) error {
+	if expected != "" && expected != actual {
+		return errors.BadRequestf(
+			"upload corrupted: expected sha256 %s, got %s",
+			expected, actual,
+		)
+	}
+	return nil
+}
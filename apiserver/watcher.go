@@ -79,28 +79,45 @@ func NewAllWatcher(context facade.Context) (facade.Facade, error) {
 	if !isAuthorized {
 		return nil, common.ErrPerm
 	}
-	watcher, ok := resources.Get(id).(*state.Multiwatcher)
-	if !ok {
-		return nil, common.ErrUnknownWatcher
-	}
-	return &SrvAllWatcher{
-		watcher:   watcher,
+	aw := &SrvAllWatcher{
 		id:        id,
 		resources: resources,
-	}, nil
+		abort:     context.Abort(),
+	}
+	switch w := resources.Get(id).(type) {
+	case *state.Multiwatcher:
+		aw.watcher = w
+	case *common.AllWatcherSession:
+		aw.session = w
+	default:
+		return nil, common.ErrUnknownWatcher
+	}
+	return aw, nil
 }
 
 // SrvAllWatcher defines the API methods on a state.Multiwatcher.
 // which watches any changes to the state. Each client has its own
 // current set of watchers, stored in resources. It is used by both
 // the AllWatcher and AllModelWatcher facades.
+//
+// If the watcher was started as a resumable session, the session
+// is used in place of the watcher.
 type SrvAllWatcher struct {
 	watcher   *state.Multiwatcher
+	session   *common.AllWatcherSession
 	id        string
 	resources facade.Resources
+	abort     <-chan struct{}
 }
 
 func (aw *SrvAllWatcher) Next() (params.AllWatcherNextResults, error) {
+	if aw.session != nil {
+		deltas, sequence, err := aw.session.Next(aw.abort)
+		return params.AllWatcherNextResults{
+			Deltas:   deltas,
+			Sequence: sequence,
+		}, err
+	}
 	deltas, err := aw.watcher.Next()
 	return params.AllWatcherNextResults{
 		Deltas: deltas,
@@ -108,6 +125,13 @@ func (aw *SrvAllWatcher) Next() (params.AllWatcherNextResults, error) {
 }
 
 func (w *SrvAllWatcher) Stop() error {
+	if w.session != nil {
+		// Stopping the resource only detaches the session;
+		// an explicit Stop closes it for good.
+		if err := w.session.Close(); err != nil {
+			logger.Warningf("error closing AllWatcher session: %v", err)
+		}
+	}
 	return w.resources.Stop(w.id)
 }
 
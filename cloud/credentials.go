@@ -36,6 +36,11 @@ type Credential struct {
 	// Revoked is true if the credential has been revoked.
 	Revoked bool
 
+	// Invalid is true if the cloud has rejected the credential,
+	// e.g. because it has expired. InvalidReason describes why.
+	Invalid       bool
+	InvalidReason string
+
 	// Label is optionally set to describe the credentials to a user.
 	Label string
 }
@@ -183,6 +183,77 @@ type BootstrapConfig struct {
 	// subsequently will acquire their serving info from another
 	// server.
 	StateServingInfo params.StateServingInfo
+
+	// IdentifyInstance, if true, indicates that the bootstrap agent
+	// will discover the ID of the instance it is running on from the
+	// environ (see environs.InstanceIdentifier), and so
+	// BootstrapMachineInstanceId need not be set.
+	IdentifyInstance bool
+
+	// Progress records how far an earlier, failed attempt to configure
+	// the bootstrap machine progressed. Steps already completed are not
+	// repeated. In particular, once the agent's configuration has been
+	// written it is kept, along with the controller's certificates, and
+	// once the controller's state has been initialized it is not
+	// initialized again.
+	Progress BootstrapProgress
+}
+
+// BootstrapProgress records how far the configuration of a bootstrap
+// machine has progressed. The progress is recorded on the machine, in
+// BootstrapProgressFile in the agent data directory.
+type BootstrapProgress string
+
+const (
+	// BootstrapNotStarted indicates that configuration of the
+	// bootstrap machine has not started.
+	BootstrapNotStarted BootstrapProgress = ""
+
+	// BootstrapConfiguring indicates that configuration of the
+	// bootstrap machine has started.
+	BootstrapConfiguring BootstrapProgress = "configuring"
+
+	// BootstrapAgentConfigured indicates that the machine agent's
+	// configuration, and the parameters for initializing the
+	// controller's state, have been written.
+	BootstrapAgentConfigured BootstrapProgress = "agent-configured"
+
+	// BootstrapStateInitialized indicates that the controller's
+	// state has been initialized.
+	BootstrapStateInitialized BootstrapProgress = "state-initialized"
+
+	// BootstrapConfigured indicates that the bootstrap machine has
+	// been configured, and the machine agent started.
+	BootstrapConfigured BootstrapProgress = "configured"
+)
+
+// BootstrapProgressFile is the name of the file, in the agent data
+// directory of the bootstrap machine, in which its BootstrapProgress
+// is recorded.
+const BootstrapProgressFile = "bootstrap-progress"
+
+var bootstrapProgressOrder = []BootstrapProgress{
+	BootstrapNotStarted,
+	BootstrapConfiguring,
+	BootstrapAgentConfigured,
+	BootstrapStateInitialized,
+	BootstrapConfigured,
+}
+
+// Reached reports whether the configuration of the bootstrap machine
+// has progressed at least as far as the given point. Progress that is
+// not recognised is treated as not having started.
+func (p BootstrapProgress) Reached(other BootstrapProgress) bool {
+	return progressIndex(p) >= progressIndex(other)
+}
+
+func progressIndex(p BootstrapProgress) int {
+	for i, q := range bootstrapProgressOrder {
+		if p == q {
+			return i
+		}
+	}
+	return 0
 }
 
 // StateInitializationParams contains parameters for initializing the
@@ -232,6 +303,12 @@ type StateInitializationParams struct {
 	// initial hosted model config.
 	HostedModelConfig map[string]interface{}
 
+	// HostedModelStoragePools holds the attributes of storage pools to
+	// create in the initial hosted model, keyed by pool name. Each
+	// pool's attributes include its storage provider type, with the
+	// key "type".
+	HostedModelStoragePools map[string]map[string]interface{}
+
 	// BootstrapMachineInstanceId is the instance ID of the bootstrap
 	// machine instance being initialized.
 	BootstrapMachineInstanceId instance.Id
@@ -259,6 +336,7 @@ type stateInitializationParamsInternal struct {
 	ControllerInheritedConfig               map[string]interface{}            `yaml:"controller-config-defaults,omitempty"`
 	RegionInheritedConfig                   cloud.RegionConfig                `yaml:"region-inherited-config,omitempty"`
 	HostedModelConfig                       map[string]interface{}            `yaml:"hosted-model-config,omitempty"`
+	HostedModelStoragePools                 map[string]map[string]interface{} `yaml:"hosted-model-storage-pools,omitempty"`
 	BootstrapMachineInstanceId              instance.Id                       `yaml:"bootstrap-machine-instance-id"`
 	BootstrapMachineConstraints             constraints.Value                 `yaml:"bootstrap-machine-constraints"`
 	BootstrapMachineHardwareCharacteristics *instance.HardwareCharacteristics `yaml:"bootstrap-machine-hardware,omitempty"`
@@ -287,6 +365,7 @@ func (p *StateInitializationParams) Marshal() ([]byte, error) {
 		p.ControllerInheritedConfig,
 		p.RegionInheritedConfig,
 		p.HostedModelConfig,
+		p.HostedModelStoragePools,
 		p.BootstrapMachineInstanceId,
 		p.BootstrapMachineConstraints,
 		p.BootstrapMachineHardwareCharacteristics,
@@ -326,6 +405,7 @@ func (p *StateInitializationParams) Unmarshal(data []byte) error {
 		ControllerInheritedConfig:               internal.ControllerInheritedConfig,
 		RegionInheritedConfig:                   internal.RegionInheritedConfig,
 		HostedModelConfig:                       internal.HostedModelConfig,
+		HostedModelStoragePools:                 internal.HostedModelStoragePools,
 		BootstrapMachineInstanceId:              internal.BootstrapMachineInstanceId,
 		BootstrapMachineConstraints:             internal.BootstrapMachineConstraints,
 		BootstrapMachineHardwareCharacteristics: internal.BootstrapMachineHardwareCharacteristics,
@@ -614,7 +694,7 @@ func (cfg *BootstrapConfig) VerifyConfig() (err error) {
 	if cfg.StateServingInfo.APIPort == 0 {
 		return errors.New("missing API port")
 	}
-	if cfg.BootstrapMachineInstanceId == "" {
+	if cfg.BootstrapMachineInstanceId == "" && !cfg.IdentifyInstance {
 		return errors.New("missing bootstrap machine instance ID")
 	}
 	if len(cfg.HostedModelConfig) == 0 {
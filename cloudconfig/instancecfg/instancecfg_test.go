@@ -111,3 +111,17 @@ func (*instancecfgSuite) TestGUITools(c *gc.C) {
 	}
 	c.Assert(icfg.GUITools(), gc.Equals, "/path/to/datadir/gui")
 }
+
+func (*instancecfgSuite) TestBootstrapProgressReached(c *gc.C) {
+	progress := instancecfg.BootstrapAgentConfigured
+	c.Assert(progress.Reached(instancecfg.BootstrapNotStarted), jc.IsTrue)
+	c.Assert(progress.Reached(instancecfg.BootstrapConfiguring), jc.IsTrue)
+	c.Assert(progress.Reached(instancecfg.BootstrapAgentConfigured), jc.IsTrue)
+	c.Assert(progress.Reached(instancecfg.BootstrapStateInitialized), jc.IsFalse)
+	c.Assert(progress.Reached(instancecfg.BootstrapConfigured), jc.IsFalse)
+
+	// Unrecognised progress is treated as not started.
+	unknown := instancecfg.BootstrapProgress("garbage")
+	c.Assert(unknown.Reached(instancecfg.BootstrapNotStarted), jc.IsTrue)
+	c.Assert(unknown.Reached(instancecfg.BootstrapConfiguring), jc.IsFalse)
+}
@@ -335,6 +335,7 @@ mkdir -p /var/lib/juju/locks
 \(id ubuntu &> /dev/null\) && chown ubuntu:ubuntu /var/lib/juju/locks
 mkdir -p /var/log/juju
 chown syslog:adm /var/log/juju
+echo 'configuring' > '/var/lib/juju/bootstrap-progress'
 bin='/var/lib/juju/tools/1\.2\.3-precise-amd64'
 mkdir -p \$bin
 echo 'Fetching Juju agent version.*
@@ -348,12 +349,15 @@ cat > '/var/lib/juju/agents/machine-0/agent\.conf' << 'EOF'\\n.*\\nEOF
 chmod 0600 '/var/lib/juju/agents/machine-0/agent\.conf'
 install -D -m 600 /dev/null '/var/lib/juju/bootstrap-params'
 printf '%s\\n' '.*' > '/var/lib/juju/bootstrap-params'
+echo 'agent-configured' > '/var/lib/juju/bootstrap-progress'
 echo 'Installing Juju machine agent'.*
 /var/lib/juju/tools/1\.2\.3-precise-amd64/jujud bootstrap-state --timeout 10m0s --data-dir '/var/lib/juju' --debug '/var/lib/juju/bootstrap-params'
+echo 'state-initialized' > '/var/lib/juju/bootstrap-progress'
 ln -s 1\.2\.3-precise-amd64 '/var/lib/juju/tools/machine-0'
 echo 'Starting Juju machine agent \(service jujud-machine-0\)'.*
 cat > /etc/init/jujud-machine-0\.conf << 'EOF'\\ndescription "juju agent for machine-0"\\nauthor "Juju Team <juju@lists\.ubuntu\.com>"\\nstart on runlevel \[2345\]\\nstop on runlevel \[!2345\]\\nrespawn\\nnormal exit 0\\n\\nlimit nofile 20000 20000\\n\\nscript\\n\\n\\n  # Ensure log files are properly protected\\n  touch /var/log/juju/machine-0\.log\\n  chown syslog:syslog /var/log/juju/machine-0\.log\\n  chmod 0600 /var/log/juju/machine-0\.log\\n\\n  exec '/var/lib/juju/tools/machine-0/jujud' machine --data-dir '/var/lib/juju' --machine-id 0 --debug >> /var/log/juju/machine-0\.log 2>&1\\nend script\\nEOF\\n
 start jujud-machine-0
+echo 'configured' > '/var/lib/juju/bootstrap-progress'
 rm \$bin/tools\.tar\.gz && rm \$bin/juju1\.2\.3-precise-amd64\.sha256
 `,
 	},
@@ -761,6 +765,43 @@ func (s *cloudinitSuite) TestCloudInitConfigureBootstrapFeatureFlags(c *gc.C) {
 	assertScriptMatch(c, scripts, expected, false)
 }
 
+func (*cloudinitSuite) bootstrapProgressScripts(c *gc.C, progress instancecfg.BootstrapProgress) []string {
+	instConfig := makeBootstrapConfig("quantal").maybeSetModelConfig(minimalModelConfig(c))
+	rendered := instConfig.render()
+	rendered.Bootstrap.Progress = progress
+	cloudcfg, err := cloudinit.New(rendered.Series)
+	c.Assert(err, jc.ErrorIsNil)
+	udata, err := cloudconfig.NewUserdataConfig(&rendered, cloudcfg)
+	c.Assert(err, jc.ErrorIsNil)
+	err = udata.ConfigureJuju()
+	c.Assert(err, jc.ErrorIsNil)
+	return cloudcfg.RunCmds()
+}
+
+func (s *cloudinitSuite) TestCloudInitConfigureBootstrapResumeAgentConfigured(c *gc.C) {
+	scripts := s.bootstrapProgressScripts(c, instancecfg.BootstrapAgentConfigured)
+	script := strings.Join(scripts, "\n")
+	c.Assert(script, gc.Not(jc.Contains), "agent.conf")
+	c.Assert(script, gc.Not(jc.Contains), "> '/var/lib/juju/bootstrap-params'")
+	c.Assert(script, gc.Not(jc.Contains), "echo 'configuring'")
+	assertScriptMatch(c, scripts, `
+.*/jujud bootstrap-state .*
+echo 'state-initialized' > '/var/lib/juju/bootstrap-progress'
+echo 'configured' > '/var/lib/juju/bootstrap-progress'
+`, false)
+}
+
+func (s *cloudinitSuite) TestCloudInitConfigureBootstrapResumeStateInitialized(c *gc.C) {
+	scripts := s.bootstrapProgressScripts(c, instancecfg.BootstrapStateInitialized)
+	script := strings.Join(scripts, "\n")
+	c.Assert(script, gc.Not(jc.Contains), "agent.conf")
+	c.Assert(script, gc.Not(jc.Contains), "bootstrap-state")
+	assertScriptMatch(c, scripts, `
+start jujud-machine-0
+echo 'configured' > '/var/lib/juju/bootstrap-progress'
+`, false)
+}
+
 func (*cloudinitSuite) TestCloudInitConfigureUsesGivenConfig(c *gc.C) {
 	// Create a simple cloudinit config with a 'runcmd' statement.
 	cloudcfg, err := cloudinit.New("quantal")
@@ -1096,6 +1137,18 @@ func (*cloudinitSuite) TestCloudInitVerify(c *gc.C) {
 		err = udata.Configure()
 		c.Check(err, gc.ErrorMatches, "invalid machine configuration: "+test.err)
 	}
+
+	// The bootstrap machine instance ID may be omitted if
+	// the bootstrap agent will identify the instance itself.
+	cfg = makeCfgWithoutTools()
+	err = cfg.SetTools(toolsList)
+	c.Assert(err, jc.ErrorIsNil)
+	cfg.Bootstrap.BootstrapMachineInstanceId = ""
+	cfg.Bootstrap.IdentifyInstance = true
+	udata, err = cloudconfig.NewUserdataConfig(&cfg, ci)
+	c.Assert(err, jc.ErrorIsNil)
+	err = udata.Configure()
+	c.Assert(err, jc.ErrorIsNil)
 }
 
 func (*cloudinitSuite) createInstanceConfig(c *gc.C, environConfig *config.Config) *instancecfg.InstanceConfig {
@@ -27,6 +27,7 @@ import (
 
 	"github.com/juju/juju/agent"
 	"github.com/juju/juju/cloudconfig/cloudinit"
+	"github.com/juju/juju/cloudconfig/instancecfg"
 	"github.com/juju/juju/environs/simplestreams"
 	"github.com/juju/juju/juju/osenv"
 	"github.com/juju/juju/service"
@@ -224,6 +225,7 @@ func (w *unixConfigure) ConfigureJuju() error {
 		fmt.Sprintf("mkdir -p %s", w.icfg.LogDir),
 		w.setDataDirPermissions(),
 	)
+	w.addBootstrapProgressCmd(instancecfg.BootstrapConfiguring)
 
 	// Make a directory for the tools to live in.
 	w.conf.AddScripts(
@@ -248,10 +250,15 @@ func (w *unixConfigure) ConfigureJuju() error {
 	// It would be cleaner to change bootstrap-state to
 	// be responsible for starting the machine agent itself,
 	// but this would not be backwardly compatible.
-	machineTag := names.NewMachineTag(w.icfg.MachineId)
-	_, err := w.addAgentInfo(machineTag)
-	if err != nil {
-		return errors.Trace(err)
+	//
+	// A bootstrap machine whose agent was configured by an earlier
+	// attempt to bootstrap keeps its configuration, as it may have
+	// been rewritten by bootstrap-state.
+	if w.icfg.Bootstrap == nil || !w.icfg.Bootstrap.Progress.Reached(instancecfg.BootstrapAgentConfigured) {
+		machineTag := names.NewMachineTag(w.icfg.MachineId)
+		if _, err := w.addAgentInfo(machineTag); err != nil {
+			return errors.Trace(err)
+		}
 	}
 
 	// Add the cloud archive cloud-tools pocket to apt sources
@@ -268,7 +275,25 @@ func (w *unixConfigure) ConfigureJuju() error {
 		}
 	}
 
-	return w.addMachineAgentToBoot()
+	if err := w.addMachineAgentToBoot(); err != nil {
+		return errors.Trace(err)
+	}
+	w.addBootstrapProgressCmd(instancecfg.BootstrapConfigured)
+	return nil
+}
+
+// addBootstrapProgressCmd adds a command that records on a bootstrap
+// machine that its configuration has progressed to the given point,
+// unless an earlier attempt to configure it progressed further.
+func (w *unixConfigure) addBootstrapProgressCmd(progress instancecfg.BootstrapProgress) {
+	if w.icfg.Bootstrap == nil || w.icfg.Bootstrap.Progress.Reached(progress) {
+		return
+	}
+	w.conf.AddScripts(fmt.Sprintf(
+		"echo %s > %s",
+		shquote(string(progress)),
+		shquote(path.Join(w.icfg.DataDir, instancecfg.BootstrapProgressFile)),
+	))
 }
 
 func (w *unixConfigure) configureBootstrap() error {
@@ -281,12 +306,19 @@ func (w *unixConfigure) configureBootstrap() error {
 		defer cleanup()
 	}
 
+	progress := w.icfg.Bootstrap.Progress
 	bootstrapParamsFile := path.Join(w.icfg.DataDir, "bootstrap-params")
-	bootstrapParams, err := w.icfg.Bootstrap.StateInitializationParams.Marshal()
-	if err != nil {
-		return errors.Annotate(err, "marshalling bootstrap params")
+	if !progress.Reached(instancecfg.BootstrapAgentConfigured) {
+		bootstrapParams, err := w.icfg.Bootstrap.StateInitializationParams.Marshal()
+		if err != nil {
+			return errors.Annotate(err, "marshalling bootstrap params")
+		}
+		w.conf.AddRunTextFile(bootstrapParamsFile, string(bootstrapParams), 0600)
+		w.addBootstrapProgressCmd(instancecfg.BootstrapAgentConfigured)
+	}
+	if progress.Reached(instancecfg.BootstrapStateInitialized) {
+		return nil
 	}
-	w.conf.AddRunTextFile(bootstrapParamsFile, string(bootstrapParams), 0600)
 
 	loggingOption := "--show-log"
 	if loggo.GetLogger("").LogLevel() == loggo.DEBUG {
@@ -308,6 +340,7 @@ func (w *unixConfigure) configureBootstrap() error {
 	}
 	w.conf.AddRunCmd(cloudinit.LogProgressCmd("Installing Juju machine agent"))
 	w.conf.AddScripts(strings.Join(bootstrapAgentArgs, " "))
+	w.addBootstrapProgressCmd(instancecfg.BootstrapStateInitialized)
 
 	return nil
 }
@@ -30,7 +30,11 @@ const (
 displayed if a key is not specified.
 
 Output includes the name of the charm used to deploy the application and a
-listing of the application-specific configuration settings.
+listing of the application-specific configuration settings. The source of
+each setting is shown: "user" for a value that has been set, either at deploy
+time or since; "default" for the charm's default value; or "unset" if there
+is no value. Resetting a key with --reset removes the value that has been set,
+so that the charm's default applies.
 See ` + "`juju status`" + ` for application names.
 
 Examples:
@@ -95,7 +99,7 @@ func (c *configCommand) SetFlags(f *gnuflag.FlagSet) {
 	c.ModelCommandBase.SetFlags(f)
 	c.out.AddFlags(f, "yaml", output.DefaultFormatters)
 	f.Var(&c.configFile, "file", "path to yaml-formatted application config")
-	f.BoolVar(&c.reset, "reset", false, "Reset the provided keys to be empty")
+	f.BoolVar(&c.reset, "reset", false, "Reset the provided keys to their charm defaults")
 }
 
 // getAPI either uses the fake API set at test time or that is nil, gets a real
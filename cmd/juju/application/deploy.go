@@ -24,6 +24,7 @@ import (
 	"github.com/juju/juju/api/annotations"
 	"github.com/juju/juju/api/application"
 	apicharms "github.com/juju/juju/api/charms"
+	"github.com/juju/juju/api/machinemanager"
 	"github.com/juju/juju/api/modelconfig"
 	apiparams "github.com/juju/juju/apiserver/params"
 	"github.com/juju/juju/charmstore"
@@ -96,6 +97,8 @@ type DeployAPI interface {
 
 	GetBundle(*charm.URL) (charm.Bundle, error)
 
+	PreviewInstanceSpecs([]apiparams.PreviewInstanceSpec) ([]apiparams.InstanceSpecPreviewResult, error)
+
 	WatchAll() (*api.AllWatcher, error)
 
 	// AddPendingResources(client.AddPendingResourcesArgs) (ids []string, _ error)
@@ -137,6 +140,10 @@ type annotationsClient struct {
 	*annotations.Client
 }
 
+type machineManagerClient struct {
+	*machinemanager.Client
+}
+
 func (a *charmstoreClient) AuthorizeCharmstoreEntity(url *charm.URL) (*macaroon.Macaroon, error) {
 	return authorizeCharmStoreEntity(a.Client, url)
 }
@@ -150,6 +157,7 @@ type deployAPIAdapter struct {
 	*charmRepoClient
 	*charmstoreClient
 	*annotationsClient
+	*machineManagerClient
 }
 
 func (a *deployAPIAdapter) Client() *api.Client {
@@ -171,6 +179,17 @@ func (a *deployAPIAdapter) Deploy(args application.DeployArgs) error {
 	return errors.Trace(a.applicationClient.Deploy(args))
 }
 
+func (a *deployAPIAdapter) PreviewInstanceSpecs(specs []apiparams.PreviewInstanceSpec) ([]apiparams.InstanceSpecPreviewResult, error) {
+	for i, spec := range specs {
+		if spec.Placement != nil && spec.Placement.Scope == "model-uuid" {
+			p := *spec.Placement
+			p.Scope = a.applicationClient.ModelUUID()
+			specs[i].Placement = &p
+		}
+	}
+	return a.machineManagerClient.PreviewInstanceSpecs(specs)
+}
+
 func (a *deployAPIAdapter) Resolve(cfg *config.Config, url *charm.URL) (
 	*charm.URL,
 	params.Channel,
@@ -222,6 +241,9 @@ func NewDeployCommandWithDefaultAPI(steps []DeployStep) cmd.Command {
 			charmstoreClient:  &charmstoreClient{Client: cstoreClient},
 			annotationsClient: &annotationsClient{Client: annotations.NewClient(apiRoot)},
 			charmRepoClient:   &charmRepoClient{CharmStore: charmrepo.NewCharmStoreFromClient(cstoreClient)},
+			machineManagerClient: &machineManagerClient{
+				Client: machinemanager.NewClient(apiRoot),
+			},
 		}
 
 		return adapter, nil
@@ -256,6 +278,10 @@ type DeployCommand struct {
 	// running an unsupported series.
 	Force bool
 
+	// Preview, if true, reports the instances that would be started
+	// for the application's units, instead of deploying it.
+	Preview bool
+
 	ApplicationName string
 	Config          cmd.FileVar
 	ConstraintsStr  string
@@ -357,6 +383,12 @@ When deploying an application or adding machines, the 'spaces' constraint can
 be used to define a comma-delimited list of required and forbidden spaces (the
 latter prefixed with "^", similar to the 'tags' constraint).
 
+The '--preview' option reports the instance type, image, and availability zone
+that the provider would choose for the application's units, given the series,
+constraints and placement directives, without deploying anything. Not all
+providers support previewing, and units placed on existing machines or in
+containers cannot be previewed.
+
 
 Examples:
     juju deploy mysql --to 23       (deploy to machine 23)
@@ -373,6 +405,9 @@ Examples:
     juju deploy mysql -n 5 --constraints mem=8G
     (deploy 5 units to machines with at least 8 GB of memory)
 
+    juju deploy mysql --preview --constraints mem=8G
+    (show the instance that would be started for the unit, without deploying)
+
     juju deploy haproxy -n 2 --constraints spaces=dmz,^cms,^database
     (deploy 2 units to machines that are part of the 'dmz' space but not of the
     'cmd' or the 'database' spaces)
@@ -422,7 +457,7 @@ func (c *DeployCommand) Info() *cmd.Info {
 var (
 	// charmOnlyFlags and bundleOnlyFlags are used to validate flags based on
 	// whether we are deploying a charm or a bundle.
-	charmOnlyFlags        = []string{"bind", "config", "constraints", "force", "n", "num-units", "series", "to", "resource"}
+	charmOnlyFlags        = []string{"bind", "config", "constraints", "force", "n", "num-units", "series", "to", "resource", "preview"}
 	bundleOnlyFlags       = []string{}
 	modelCommandBaseFlags = []string{"B", "no-browser-login"}
 )
@@ -441,6 +476,7 @@ func (c *DeployCommand) SetFlags(f *gnuflag.FlagSet) {
 	f.Var(storageFlag{&c.Storage, &c.BundleStorage}, "storage", "Charm storage constraints")
 	f.Var(stringMap{&c.Resources}, "resource", "Resource to be uploaded to the controller")
 	f.StringVar(&c.BindToSpaces, "bind", "", "Configure application endpoint bindings to spaces")
+	f.BoolVar(&c.Preview, "preview", false, "Show the instances that would be started, without deploying")
 
 	for _, step := range c.Steps {
 		step.SetFlags(f)
@@ -470,7 +506,17 @@ func (c *DeployCommand) Init(args []string) error {
 	if err := c.parseBind(); err != nil {
 		return err
 	}
-	return c.UnitCommandBase.Init(args)
+	if err := c.UnitCommandBase.Init(args); err != nil {
+		return err
+	}
+	if c.Preview {
+		for _, p := range c.Placement {
+			if p.Scope != "model-uuid" {
+				return errors.Errorf("cannot preview units placed with %q", p)
+			}
+		}
+	}
+	return nil
 }
 
 type ModelConfigGetter interface {
@@ -610,6 +656,39 @@ func (c *DeployCommand) deployCharm(
 	}))
 }
 
+// previewDeploy reports the instances that the provider would start
+// for the application's units, which are placed on new machines.
+func (c *DeployCommand) previewDeploy(ctx *cmd.Context, series string, apiRoot DeployAPI) error {
+	// Units without placement directives are all placed on
+	// identical machines, so they share a single preview.
+	specs := make([]apiparams.PreviewInstanceSpec, 0, len(c.Placement)+1)
+	for _, p := range c.Placement {
+		specs = append(specs, apiparams.PreviewInstanceSpec{
+			Series:      series,
+			Constraints: c.Constraints,
+			Placement:   p,
+		})
+	}
+	if len(c.Placement) < c.NumUnits {
+		specs = append(specs, apiparams.PreviewInstanceSpec{
+			Series:      series,
+			Constraints: c.Constraints,
+		})
+	}
+	results, err := apiRoot.PreviewInstanceSpecs(specs)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	previews := make([]*apiparams.InstanceSpecPreview, len(results))
+	for i, result := range results {
+		if result.Error != nil {
+			return errors.Annotate(result.Error, "cannot preview deployment")
+		}
+		previews[i] = result.Result
+	}
+	return common.PrintInstanceSpecPreviews(ctx.Stdout, previews)
+}
+
 const parseBindErrorPrefix = "--bind must be in the form '[<default-space>] [<endpoint-name>=<space> ...]'. "
 
 // parseBind parses the --bind option. Valid forms are:
@@ -735,6 +814,9 @@ func (c *DeployCommand) maybePredeployedLocalCharm() (deployFn, error) {
 	}
 
 	return func(ctx *cmd.Context, api DeployAPI) error {
+		if c.Preview {
+			return errors.Trace(c.previewDeploy(ctx, userCharmURL.Series, api))
+		}
 		formattedCharmURL := userCharmURL.String()
 		ctx.Infof("Located charm %q.", formattedCharmURL)
 		ctx.Infof("Deploying charm %q.", formattedCharmURL)
@@ -839,6 +921,9 @@ func (c *DeployCommand) maybeReadLocalCharm() (deployFn, error) {
 	}
 
 	return func(ctx *cmd.Context, apiRoot DeployAPI) error {
+		if c.Preview {
+			return errors.Trace(c.previewDeploy(ctx, curl.Series, apiRoot))
+		}
 		if curl, err = apiRoot.AddLocalCharm(curl, ch); err != nil {
 			return errors.Trace(err)
 		}
@@ -954,6 +1039,10 @@ func (c *DeployCommand) charmStoreCharm() (deployFn, error) {
 			return errors.Errorf("%v. Use --force to deploy the charm anyway.", err)
 		}
 
+		if c.Preview {
+			return errors.Trace(c.previewDeploy(ctx, series, apiRoot))
+		}
+
 		// Store the charm in the controller
 		curl, csMac, err := addCharmFromURL(apiRoot, storeCharmOrBundleURL, channel)
 		if err != nil {
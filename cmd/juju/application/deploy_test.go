@@ -1345,6 +1345,67 @@ func (s *DeployUnitTestSuite) TestRedeployLocalCharm_SucceedsWhenDeployed(c *gc.
 	)
 }
 
+func (s *DeployUnitTestSuite) TestDeployLocalCharmPreview(c *gc.C) {
+	charmsPath := c.MkDir()
+	charmDir := testcharms.Repo.ClonedDir(charmsPath, "dummy")
+
+	fakeAPI := vanillaFakeModelAPI(map[string]interface{}{
+		"name": "name",
+		"uuid": "deadbeef-0bad-400d-8000-4b1d0d06f00d",
+		"type": "foo",
+	})
+	cons := constraints.MustParse("mem=8G")
+	fakeAPI.Call("PreviewInstanceSpecs", []params.PreviewInstanceSpec{{
+		Series:      "trusty",
+		Constraints: cons,
+		Placement:   &instance.Placement{Scope: "model-uuid", Directive: "zone=z1"},
+	}, {
+		Series:      "trusty",
+		Constraints: cons,
+	}}).Returns([]params.InstanceSpecPreviewResult{{
+		Result: &params.InstanceSpecPreview{
+			InstanceType:     "m1.large",
+			Image:            "ami-0123",
+			Arch:             "amd64",
+			AvailabilityZone: "z1",
+		},
+	}, {
+		Result: &params.InstanceSpecPreview{
+			InstanceType: "m1.large",
+			Image:        "ami-0123",
+			Arch:         "amd64",
+			Cost:         400,
+		},
+	}}, error(nil))
+
+	deployCmd := NewDeployCommand(func() (DeployAPI, error) {
+		return fakeAPI, nil
+	}, nil)
+	context, err := jtesting.RunCommand(c, deployCmd,
+		charmDir.Path, "--series", "trusty", "--preview",
+		"--constraints", "mem=8G", "-n", "2", "--to", "zone=z1",
+	)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(jtesting.Stdout(context), gc.Equals, `
+Instance type  Image     Arch   Cost     Zone
+m1.large       ami-0123  amd64  unknown  z1
+m1.large       ami-0123  amd64  400      -
+`[1:])
+
+	// Nothing is added or deployed.
+	for _, call := range fakeAPI.Calls() {
+		c.Check(call.FuncName, gc.Not(gc.Matches), "AddLocalCharm|AddCharm.*|Deploy")
+	}
+}
+
+func (s *DeployUnitTestSuite) TestDeployPreviewPlacementOnMachine(c *gc.C) {
+	deployCmd := NewDeployCommand(func() (DeployAPI, error) {
+		return vanillaFakeModelAPI(nil), nil
+	}, nil)
+	_, err := jtesting.RunCommand(c, deployCmd, "mysql", "--preview", "--to", "lxd:0")
+	c.Assert(err, gc.ErrorMatches, `cannot preview units placed with "lxd:0"`)
+}
+
 func (s *DeployUnitTestSuite) TestDeployBundle_OutputsCorrectMessage(c *gc.C) {
 	bundleDir := testcharms.Repo.BundleArchive(c.MkDir(), "wordpress-simple")
 
@@ -1471,6 +1532,11 @@ func (f *fakeDeployAPI) ModelUUID() (string, bool) {
 	return results[0].(string), results[1].(bool)
 }
 
+func (f *fakeDeployAPI) PreviewInstanceSpecs(specs []params.PreviewInstanceSpec) ([]params.InstanceSpecPreviewResult, error) {
+	results := f.MethodCall(f, "PreviewInstanceSpecs", specs)
+	return results[0].([]params.InstanceSpecPreviewResult), typeAssertError(results[1])
+}
+
 func (f *fakeDeployAPI) AddLocalCharm(url *charm.URL, ch charm.Charm) (*charm.URL, error) {
 	results := f.MethodCall(f, "AddLocalCharm", url, ch)
 	return results[0].(*charm.URL), typeAssertError(results[1])
@@ -93,7 +93,10 @@ type ArchiveReader interface {
 	io.Closer
 }
 
-func getArchive(filename string) (rc ArchiveReader, metaResult *params.BackupsMetadataResult, err error) {
+// GetArchive opens the backup archive with the given filename,
+// returning a reader for it along with its metadata. If the archive
+// does not contain metadata, it is built from the archive itself.
+func GetArchive(filename string) (rc ArchiveReader, metaResult *params.BackupsMetadataResult, err error) {
 	defer func() {
 		if err != nil && rc != nil {
 			rc.Close()
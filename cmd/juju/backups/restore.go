@@ -39,7 +39,7 @@ func NewRestoreCommand() cmd.Command {
 	restoreCmd.newAPIClientFunc = func() (RestoreAPI, error) {
 		return restoreCmd.newClient()
 	}
-	restoreCmd.getArchiveFunc = getArchive
+	restoreCmd.getArchiveFunc = GetArchive
 	restoreCmd.waitForAgentFunc = common.WaitForAgentInitialisation
 	return modelcmd.Wrap(restoreCmd)
 }
@@ -46,6 +46,15 @@ An alternative to this command is ` + "`juju add-credential`" + `.
 Below are the cloud types for which credentials may be autoloaded,
 including the locations searched.
 
+Azure
+  Credentials:
+    1. Subscriptions logged in to with the Azure CLI, recorded in
+       $HOME/.azure/azureProfile.json and $HOME/.azure/accessTokens.json
+       (or the directory specified by the AZURE_CONFIG_DIR environment
+       variable)
+    2. Environment variables AZURE_SUBSCRIPTION_ID, AZURE_CLIENT_ID,
+       AZURE_CLIENT_SECRET
+
 EC2
   Credentials and regions:
     1. On Linux, $HOME/.aws/credentials and $HOME/.aws/config
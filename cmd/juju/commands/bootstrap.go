@@ -6,10 +6,13 @@ package commands
 import (
 	"bufio"
 	"fmt"
+	"io"
 	"os"
 	"os/user"
+	"path/filepath"
 	"sort"
 	"strings"
+	"time"
 
 	"github.com/juju/cmd"
 	"github.com/juju/errors"
@@ -17,10 +20,14 @@ import (
 	"github.com/juju/schema"
 	"github.com/juju/utils"
 	"github.com/juju/utils/featureflag"
+	"github.com/juju/utils/keyvalues"
 	"github.com/juju/version"
 	"gopkg.in/juju/charm.v6-unstable"
+	"gopkg.in/juju/environschema.v1"
 
+	"github.com/juju/juju/apiserver/params"
 	jujucloud "github.com/juju/juju/cloud"
+	"github.com/juju/juju/cmd/juju/backups"
 	"github.com/juju/juju/cmd/juju/common"
 	"github.com/juju/juju/cmd/modelcmd"
 	"github.com/juju/juju/constraints"
@@ -29,10 +36,12 @@ import (
 	"github.com/juju/juju/environs/bootstrap"
 	"github.com/juju/juju/environs/config"
 	"github.com/juju/juju/environs/sync"
+	envtools "github.com/juju/juju/environs/tools"
 	"github.com/juju/juju/feature"
 	"github.com/juju/juju/instance"
 	"github.com/juju/juju/juju/osenv"
 	"github.com/juju/juju/jujuclient"
+	"github.com/juju/juju/storage/poolmanager"
 	jujuversion "github.com/juju/juju/version"
 )
 
@@ -92,6 +101,71 @@ The value of '--agent-version' will become the default tools version to
 use in all models for this controller. The full binary version is accepted
 (e.g.: 2.0.1-xenial-amd64) but only the numeric version (e.g.: 2.0.1) is
 used. Otherwise, by default, the version used is that of the client.
+Use '--agent-stream' to choose the stream (released, proposed or devel)
+in which to look for agent binaries. It is equivalent to setting the
+'agent-stream' model configuration, and applies to all models created
+with the controller. A version requested with '--agent-version' must be
+available in the chosen stream for the bootstrap machine's series and
+architecture; if it is not, bootstrap fails before any machine is
+provisioned, listing the versions that are available.
+
+Model configuration may also be specified for a cloud in clouds.yaml, with
+region-specific values taking precedence. This is useful for private clouds
+that only provide images for some series, or only in a particular stream:
+
+    clouds:
+      mycloud:
+        type: openstack
+        config:
+          default-series: xenial
+          image-stream: daily
+        region-config:
+          region-b:
+            image-stream: released
+
+Such values are honored when bootstrapping, and are inherited by all models
+on the cloud, so that deploy and add-machine use them too. They may be
+overridden with '--config', or in a model with ` + "`juju model-config`" + `.
+
+If '--model-default' is used, its values will be set as the default
+configuration for all models in the controller once bootstrap has
+completed, exactly as if they were set with ` + "`juju model-defaults`" + `.
+
+If '--storage-pool' is used, the named storage pool is created in the
+default model, exactly as if it were created with ` + "`juju create-storage-pool`" + `.
+Each pool is specified as <name>=<provider type>, optionally followed by
+comma-separated attributes (e.g. --storage-pool
+azure-premium=azure,account-type=Premium_LRS). The option may be repeated
+to create several pools. Bundles deployed to the default model immediately
+after bootstrap may then refer to the pools.
+
+If '--restore' is used, the new controller's state is restored from the
+specified backup archive (see ` + "`juju create-backup`" + `) once it has been
+provisioned. The controller keeps the CA certificate from the backup, so
+existing agents will trust it, and it starts in provisioner safe mode so
+that instances it does not yet know about are not destroyed. The admin
+password of the backed up controller must be specified with
+'--config admin-secret=<password>'.
+
+If bootstrap fails with '--keep-broken', the controller's resources and its
+details in the client store are kept. The bootstrap may then be resumed by
+running bootstrap again with '--resume' and the same controller and cloud
+names: the existing controller instance is configured, rather than a new
+instance being started. Steps already completed on the instance are not
+repeated. A bootstrap that fails after '--resume' is always kept.
+
+Once the controller is available, a number of checks are run against it
+(API endpoint reachability, API server certificate validity, free disk
+space on the controller, and provider access from the controller), and
+a summary of their results is reported. Problems found by the checks are
+reported as warnings, and do not cause bootstrap to fail.
+
+Progress is reported on stderr. If '--format' is specified, a summary of
+the new controller is written to stdout once bootstrap has completed, in
+the chosen format (json or yaml). The summary includes the controller's
+UUID and API endpoints, the bootstrap machine's instance ID, the agent
+version, and the time taken by each stage of bootstrap, so that scripts
+and CI systems can consume the results without parsing progress messages.
 
 Examples:
     juju bootstrap
@@ -101,6 +175,11 @@ Examples:
     juju bootstrap --config=~/config-rs.yaml joe-syd rackspace
     juju bootstrap --config agent-version=1.25.3 joe-us-east-1 aws
     juju bootstrap --config bootstrap-timeout=1200 joe-eastus azure
+    juju bootstrap --model-default image-stream=daily joe-us-east-1 aws
+    juju bootstrap --storage-pool azure-premium=azure,account-type=Premium_LRS joe-eastus azure
+    juju bootstrap --restore backup.tar.gz --config admin-secret=s3cr3t joe-us-east-1 aws
+    juju bootstrap --resume joe-us-east-1 aws
+    juju bootstrap --format json joe-us-east-1 aws
 
 See also:
     add-credentials
@@ -133,11 +212,15 @@ type bootstrapCommand struct {
 	MetadataSource          string
 	Placement               string
 	KeepBrokenEnvironment   bool
+	Resume                  bool
 	AutoUpgrade             bool
 	AgentVersionParam       string
 	AgentVersion            *version.Number
+	AgentStream             string
 	ForceAPIPort            bool
 	config                  common.ConfigFlag
+	modelDefaults           common.ConfigFlag
+	storagePools            []string
 
 	showClouds          bool
 	showRegionsForCloud string
@@ -148,6 +231,11 @@ type bootstrapCommand struct {
 	Region              string
 	noGUI               bool
 	interactive         bool
+	scanner             *bufio.Scanner
+	restoreFile         string
+	out                 cmd.Output
+
+	hostedModelStoragePools map[string]map[string]interface{}
 }
 
 func (c *bootstrapCommand) Info() *cmd.Info {
@@ -171,16 +259,26 @@ func (c *bootstrapCommand) SetFlags(f *gnuflag.FlagSet) {
 	f.StringVar(&c.MetadataSource, "metadata-source", "", "Local path to use as tools and/or metadata source")
 	f.StringVar(&c.Placement, "to", "", "Placement directive indicating an instance to bootstrap")
 	f.BoolVar(&c.KeepBrokenEnvironment, "keep-broken", false, "Do not destroy the model if bootstrap fails")
+	f.BoolVar(&c.Resume, "resume", false, "Resume a bootstrap that failed with --keep-broken")
 	f.BoolVar(&c.AutoUpgrade, "auto-upgrade", false, "Upgrade to the latest patch release tools on first bootstrap")
 	f.BoolVar(&c.ForceAPIPort, "force-api-port", false, "Allow use of non-standard HTTPS port when official DNS name specified")
 	f.StringVar(&c.AgentVersionParam, "agent-version", "", "Version of tools to use for Juju agents")
+	f.StringVar(&c.AgentStream, "agent-stream", "", "Stream in which to look for agent binaries (released, proposed or devel)")
 	f.StringVar(&c.CredentialName, "credential", "", "Credentials to use when bootstrapping")
 	f.Var(&c.config, "config", "Specify a controller configuration file, or one or more configuration\n    options\n    (--config config.yaml [--config key=value ...])")
+	f.Var(&c.modelDefaults, "model-default", "Specify a configuration file, or one or more configuration\n    options to be set as model defaults once bootstrapped\n    (--model-default config.yaml [--model-default key=value ...])")
+	f.Var(cmd.NewAppendStringsValue(&c.storagePools), "storage-pool", "Specify a storage pool to create in the default model\n    (--storage-pool <name>=<type>[,<attr>=<value>...])")
 	f.StringVar(&c.hostedModelName, "d", defaultHostedModelName, "Name of the default hosted model for the controller")
 	f.StringVar(&c.hostedModelName, "default-model", defaultHostedModelName, "Name of the default hosted model for the controller")
 	f.BoolVar(&c.noGUI, "no-gui", false, "Do not install the Juju GUI in the controller when bootstrapping")
 	f.BoolVar(&c.showClouds, "clouds", false, "Print the available clouds which can be used to bootstrap a Juju environment")
 	f.StringVar(&c.showRegionsForCloud, "regions", "", "Print the available regions for the specified cloud")
+	f.StringVar(&c.restoreFile, "restore", "", "Restore the controller from the specified backup archive once bootstrapped")
+	c.out.AddFlags(f, "none", map[string]cmd.Formatter{
+		"none": formatBootstrapResultNone,
+		"yaml": cmd.FormatYaml,
+		"json": cmd.FormatJson,
+	})
 }
 
 func (c *bootstrapCommand) Init(args []string) (err error) {
@@ -196,9 +294,30 @@ func (c *bootstrapCommand) Init(args []string) (err error) {
 	if c.AgentVersionParam != "" && c.BuildAgent {
 		return errors.New("--agent-version and --build-agent can't be used together")
 	}
+	switch c.AgentStream {
+	case "", envtools.ReleasedStream, envtools.ProposedStream, envtools.DevelStream:
+	default:
+		return errors.NotValidf("agent stream %q", c.AgentStream)
+	}
+	if c.Resume && c.restoreFile != "" {
+		return errors.New("--resume and --restore can't be used together")
+	}
+	if c.Resume {
+		// A bootstrap that fails after resuming is kept,
+		// so that it may be resumed again.
+		c.KeepBrokenEnvironment = true
+	}
 	if c.BootstrapSeries != "" && !charm.IsValidSeries(c.BootstrapSeries) {
 		return errors.NotValidf("series %q", c.BootstrapSeries)
 	}
+	if c.restoreFile != "" {
+		if c.restoreFile, err = filepath.Abs(c.restoreFile); err != nil {
+			return errors.Trace(err)
+		}
+	}
+	if c.hostedModelStoragePools, err = parseStoragePools(c.storagePools); err != nil {
+		return errors.Trace(err)
+	}
 
 	// Parse the placement directive. Bootstrap currently only
 	// supports provider-specific placement directives.
@@ -245,6 +364,42 @@ func (c *bootstrapCommand) Init(args []string) (err error) {
 	return cmd.CheckEmpty(args[2:])
 }
 
+// parseStoragePools parses the values of the --storage-pool option, each
+// of the form <name>=<type>[,<attr>=<value>...], into the attributes of
+// the storage pools to create in the hosted model, keyed by pool name.
+func parseStoragePools(values []string) (map[string]map[string]interface{}, error) {
+	if len(values) == 0 {
+		return nil, nil
+	}
+	pools := make(map[string]map[string]interface{})
+	for _, value := range values {
+		fields := strings.Split(value, ",")
+		nameType := strings.SplitN(fields[0], "=", 2)
+		if len(nameType) != 2 || nameType[0] == "" || nameType[1] == "" {
+			return nil, errors.Errorf(
+				"invalid --storage-pool %q: expected <name>=<type>[,<attr>=<value>...]", value,
+			)
+		}
+		name, providerType := nameType[0], nameType[1]
+		if _, ok := pools[name]; ok {
+			return nil, errors.Errorf("storage pool %q specified more than once", name)
+		}
+		options, err := keyvalues.Parse(fields[1:], false)
+		if err != nil {
+			return nil, errors.Annotatef(err, "invalid --storage-pool %q", value)
+		}
+		attrs := map[string]interface{}{poolmanager.Type: providerType}
+		for k, v := range options {
+			if k == poolmanager.Name || k == poolmanager.Type {
+				return nil, errors.Errorf("invalid --storage-pool %q: %q may not be set as an attribute", value, k)
+			}
+			attrs[k] = v
+		}
+		pools[name] = attrs
+	}
+	return pools, nil
+}
+
 // BootstrapInterface provides bootstrap functionality that Run calls to support cleaner testing.
 type BootstrapInterface interface {
 	Bootstrap(ctx environs.BootstrapContext, environ environs.Environ, args bootstrap.BootstrapParams) error
@@ -340,7 +495,24 @@ func (c *bootstrapCommand) Run(ctx *cmd.Context) (resultErr error) {
 		return printCloudRegions(ctx, c.showRegionsForCloud)
 	}
 
+	// Read the backup archive up front, so we don't provision a
+	// controller only to find that it cannot be restored.
+	var restoreArchive backups.ArchiveReader
+	var restoreMeta *params.BackupsMetadataResult
+	if c.restoreFile != "" {
+		var err error
+		restoreArchive, restoreMeta, err = getBackupArchive(c.restoreFile)
+		if err != nil {
+			return errors.Annotate(err, "reading backup archive")
+		}
+		defer restoreArchive.Close()
+		if c.BootstrapSeries == "" {
+			c.BootstrapSeries = restoreMeta.Series
+		}
+	}
+
 	bootstrapFuncs := getBootstrapFuncs()
+	startTime := time.Now()
 
 	// Get the cloud definition identified by c.Cloud. If c.Cloud does not
 	// identify a cloud in clouds.yaml, but is the name of a provider, and
@@ -484,6 +656,25 @@ func (c *bootstrapCommand) Run(ctx *cmd.Context) (resultErr error) {
 		return errors.Trace(err)
 	}
 
+	// When resuming, the controller must be bootstrapped with the
+	// UUIDs and credentials given to the existing bootstrap instance.
+	var resume *resumeDetails
+	if c.Resume {
+		resume, err = readResumeDetails(store, c.controllerName, c.Cloud, c.hostedModelName)
+		if err != nil {
+			return errors.Annotate(err, "cannot resume bootstrap")
+		}
+		if controllerModelUUID, err = utils.UUIDFromString(resume.controllerModelUUID); err != nil {
+			return errors.Trace(err)
+		}
+		if hostedModelUUID, err = utils.UUIDFromString(resume.hostedModelUUID); err != nil {
+			return errors.Trace(err)
+		}
+		if controllerUUID, err = utils.UUIDFromString(resume.controllerUUID); err != nil {
+			return errors.Trace(err)
+		}
+	}
+
 	// Create a model config, and split out any controller
 	// and bootstrap config attributes.
 	modelConfigAttrs := map[string]interface{}{
@@ -495,6 +686,22 @@ func (c *bootstrapCommand) Run(ctx *cmd.Context) (resultErr error) {
 	if err != nil {
 		return errors.Trace(err)
 	}
+	if c.AgentStream != "" {
+		// The agent stream applies to the controller and
+		// hosted models, so we record it as if it had been
+		// specified with --config.
+		if stream, ok := userConfigAttrs[config.AgentStreamKey]; ok && stream != c.AgentStream {
+			return errors.Errorf(
+				"--agent-stream %q conflicts with %s=%v",
+				c.AgentStream, config.AgentStreamKey, stream,
+			)
+		}
+		userConfigAttrs[config.AgentStreamKey] = c.AgentStream
+	}
+	modelDefaultAttrs, err := c.modelDefaults.ReadAttrs(ctx)
+	if err != nil {
+		return errors.Trace(err)
+	}
 
 	// The provider may define some custom attributes specific
 	// to the provider. These will be added to the model config.
@@ -538,6 +745,20 @@ func (c *bootstrapCommand) Run(ctx *cmd.Context) (resultErr error) {
 		}
 		inheritedControllerAttrs[k] = v
 	}
+	// The region's config in clouds.yaml is shared by all models
+	// in the region, and takes precedence over the cloud's config.
+	// Only model config may be specified for a region.
+	regionConfigAttrs := make(map[string]interface{})
+	for k, v := range cloud.RegionConfig[region.Name] {
+		if bootstrap.IsBootstrapAttribute(k) || controller.ControllerOnlyAttribute(k) {
+			logger.Warningf(
+				"ignoring %q in region-config for %s/%s: only model config may be specified for a region",
+				k, c.Cloud, region.Name,
+			)
+			continue
+		}
+		regionConfigAttrs[k] = v
+	}
 	for k, v := range modelConfigAttrs {
 		switch {
 		case bootstrap.IsBootstrapAttribute(k):
@@ -548,6 +769,32 @@ func (c *bootstrapCommand) Run(ctx *cmd.Context) (resultErr error) {
 			delete(modelConfigAttrs, k)
 		}
 	}
+	if source, ok := provider.(config.RequiredConfigSource); ok {
+		// The model config takes precedence over the region's,
+		// which takes precedence over the cloud's.
+		attrs := make(map[string]interface{})
+		for _, m := range []map[string]interface{}{
+			inheritedControllerAttrs, regionConfigAttrs, modelConfigAttrs,
+		} {
+			for k, v := range m {
+				attrs[k] = v
+			}
+		}
+		if err := c.checkRequiredConfig(
+			ctx, cloud.Type, source.RequiredConfig(attrs), modelConfigAttrs,
+			inheritedControllerAttrs, regionConfigAttrs,
+		); err != nil {
+			return errors.Trace(err)
+		}
+	}
+	if restoreMeta != nil {
+		if err := prepareRestoreAttrs(restoreMeta, bootstrapConfigAttrs, modelConfigAttrs); err != nil {
+			return errors.Trace(err)
+		}
+	}
+	if resume != nil {
+		prepareResumeAttrs(resume, bootstrapConfigAttrs)
+	}
 	bootstrapConfig, err := bootstrap.NewConfig(bootstrapConfigAttrs)
 	if err != nil {
 		return errors.Annotate(err, "constructing bootstrap config")
@@ -576,6 +823,9 @@ func (c *bootstrapCommand) Run(ctx *cmd.Context) (resultErr error) {
 		return errors.Annotate(err, "error reading current controller")
 	}
 
+	// keepController is set if bootstrap fails, but the controller's
+	// resources are kept so that the bootstrap may be resumed.
+	var keepController bool
 	defer func() {
 		if resultErr == nil || errors.IsAlreadyExists(resultErr) {
 			return
@@ -588,6 +838,9 @@ func (c *bootstrapCommand) Run(ctx *cmd.Context) (resultErr error) {
 				)
 			}
 		}
+		if keepController {
+			return
+		}
 		if err := store.RemoveController(c.controllerName); err != nil {
 			logger.Errorf(
 				"cannot destroy newly created controller %q details: %v",
@@ -600,6 +853,9 @@ func (c *bootstrapCommand) Run(ctx *cmd.Context) (resultErr error) {
 	for k, v := range inheritedControllerAttrs {
 		bootstrapModelConfig[k] = v
 	}
+	for k, v := range regionConfigAttrs {
+		bootstrapModelConfig[k] = v
+	}
 	for k, v := range modelConfigAttrs {
 		bootstrapModelConfig[k] = v
 	}
@@ -612,6 +868,13 @@ func (c *bootstrapCommand) Run(ctx *cmd.Context) (resultErr error) {
 		}
 	}
 
+	if resume != nil {
+		// Remove the controller's existing details, so that they
+		// can be replaced by preparing the controller again.
+		if err := store.RemoveController(c.controllerName); err != nil {
+			return errors.Trace(err)
+		}
+	}
 	environ, err := bootstrapPrepare(
 		modelcmd.BootstrapContext(ctx), store,
 		bootstrap.PrepareParams{
@@ -664,8 +927,14 @@ func (c *bootstrapCommand) Run(ctx *cmd.Context) (resultErr error) {
 	defer func() {
 		if resultErr != nil {
 			if c.KeepBrokenEnvironment {
+				if err := recordResumeDetails(store, c.controllerName); err != nil {
+					logger.Errorf("cannot record details to resume bootstrap: %v", err)
+				} else {
+					keepController = true
+				}
 				ctx.Infof(`
 bootstrap failed but --keep-broken was specified so resources are not being destroyed.
+Once the problem has been fixed, the bootstrap may be resumed with the --resume flag.
 When you have finished diagnosing the problem, remember to clean up the failed controller.
 See `[1:] + "`juju kill-controller`" + `.`)
 			} else {
@@ -717,6 +986,9 @@ See `[1:] + "`juju kill-controller`" + `.`)
 	for k, v := range inheritedControllerAttrs {
 		hostedModelConfig[k] = v
 	}
+	for k, v := range regionConfigAttrs {
+		hostedModelConfig[k] = v
+	}
 
 	// We copy across any user supplied attributes to the hosted model config.
 	// But only if the attributes have not been removed from the controller
@@ -748,6 +1020,11 @@ See `[1:] + "`juju kill-controller`" + `.`)
 		credentialName = detectedCredentialName
 	}
 
+	// keptAgentConfig is set if a resumed bootstrap keeps the agent
+	// configuration, and so the CA, given to the bootstrap instance
+	// by the failed bootstrap.
+	var keptAgentConfig bool
+	bootstrapStartTime := time.Now()
 	err = bootstrapFuncs.Bootstrap(modelcmd.BootstrapContext(ctx), environ, bootstrap.BootstrapParams{
 		ModelConstraints:          c.Constraints,
 		BootstrapConstraints:      bootstrapConstraints,
@@ -767,6 +1044,7 @@ See `[1:] + "`juju kill-controller`" + `.`)
 		ControllerInheritedConfig: inheritedControllerAttrs,
 		RegionInheritedConfig:     cloud.RegionConfig,
 		HostedModelConfig:         hostedModelConfig,
+		HostedModelStoragePools:   c.hostedModelStoragePools,
 		GUIDataSourceBaseURL:      guiDataSourceBaseURL,
 		AdminSecret:               bootstrapConfig.AdminSecret,
 		CAPrivateKey:              bootstrapConfig.CAPrivateKey,
@@ -775,10 +1053,19 @@ See `[1:] + "`juju kill-controller`" + `.`)
 			RetryDelay:     bootstrapConfig.BootstrapRetryDelay,
 			AddressesDelay: bootstrapConfig.BootstrapAddressesDelay,
 		},
+		Resume:          c.Resume,
+		KeptAgentConfig: func() { keptAgentConfig = true },
 	})
+	if keptAgentConfig {
+		if err := restoreCACert(store, c.controllerName, resume.caCert); err != nil {
+			return errors.Annotate(err, "restoring controller CA certificate")
+		}
+	}
 	if err != nil {
 		return errors.Annotate(err, "failed to bootstrap model")
 	}
+	var timings bootstrapResultTimings
+	timings.Bootstrap = time.Since(bootstrapStartTime).Seconds()
 
 	if err := c.SetModelName(modelcmd.JoinModelName(c.controllerName, c.hostedModelName)); err != nil {
 		return errors.Trace(err)
@@ -796,13 +1083,104 @@ See `[1:] + "`juju kill-controller`" + `.`)
 	// To avoid race conditions when running scripted bootstraps, wait
 	// for the controller's machine agent to be ready to accept commands
 	// before exiting this bootstrap command.
-	return waitForAgentInitialisation(ctx, &c.ModelCommandBase, c.controllerName, c.hostedModelName)
+	agentStartTime := time.Now()
+	if err := waitForAgentInitialisation(ctx, &c.ModelCommandBase, c.controllerName, c.hostedModelName); err != nil {
+		return err
+	}
+	timings.AgentInitialisation = time.Since(agentStartTime).Seconds()
+	if restoreArchive != nil {
+		restoreStartTime := time.Now()
+		err := restoreBackup(ctx, &c.ModelCommandBase, c.controllerName, c.hostedModelName, restoreArchive, restoreMeta)
+		if err != nil {
+			return errors.Annotate(err, "restoring backup")
+		}
+		timings.Restore = time.Since(restoreStartTime).Seconds()
+	}
+	if err := runPostBootstrap(ctx, &c.ModelCommandBase, c.controllerName, modelDefaultAttrs); err != nil {
+		return err
+	}
+	timings.Total = time.Since(startTime).Seconds()
+
+	if c.out.Name() == "none" {
+		return nil
+	}
+	result, err := c.bootstrapResult(store, environ, timings)
+	if err != nil {
+		return errors.Annotate(err, "getting bootstrap result")
+	}
+	return c.out.Write(ctx, result)
+}
+
+// bootstrapResult describes a newly bootstrapped controller. It is
+// written by bootstrap when --format is specified, so that scripts
+// can consume the results of bootstrap.
+type bootstrapResult struct {
+	ControllerName      string                 `yaml:"controller-name" json:"controller-name"`
+	ControllerUUID      string                 `yaml:"controller-uuid" json:"controller-uuid"`
+	Cloud               string                 `yaml:"cloud" json:"cloud"`
+	Region              string                 `yaml:"region,omitempty" json:"region,omitempty"`
+	APIEndpoints        []string               `yaml:"api-endpoints" json:"api-endpoints"`
+	BootstrapInstanceId string                 `yaml:"bootstrap-instance-id" json:"bootstrap-instance-id"`
+	AgentVersion        string                 `yaml:"agent-version" json:"agent-version"`
+	Model               string                 `yaml:"model" json:"model"`
+	Timings             bootstrapResultTimings `yaml:"timings" json:"timings"`
+}
+
+// bootstrapResultTimings records the time taken by each stage of
+// bootstrap, in seconds. Bootstrap covers provisioning and configuring
+// the bootstrap machine; AgentInitialisation covers waiting for the
+// controller agent to accept API requests; Restore is only set if
+// --restore was specified. Total excludes any interactive prompts.
+type bootstrapResultTimings struct {
+	Bootstrap           float64 `yaml:"bootstrap" json:"bootstrap"`
+	AgentInitialisation float64 `yaml:"agent-initialisation" json:"agent-initialisation"`
+	Restore             float64 `yaml:"restore,omitempty" json:"restore,omitempty"`
+	Total               float64 `yaml:"total" json:"total"`
+}
+
+// bootstrapResult returns a summary of the newly bootstrapped
+// controller, using the details recorded in the client store.
+func (c *bootstrapCommand) bootstrapResult(
+	store jujuclient.ClientStore,
+	environ environs.Environ,
+	timings bootstrapResultTimings,
+) (*bootstrapResult, error) {
+	details, err := store.ControllerByName(c.controllerName)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	instanceIds, err := environ.ControllerInstances(details.ControllerUUID)
+	if err != nil {
+		return nil, errors.Annotate(err, "getting controller instances")
+	}
+	if len(instanceIds) == 0 {
+		return nil, errors.New("no controller instances found")
+	}
+	return &bootstrapResult{
+		ControllerName:      c.controllerName,
+		ControllerUUID:      details.ControllerUUID,
+		Cloud:               details.Cloud,
+		Region:              details.CloudRegion,
+		APIEndpoints:        details.APIEndpoints,
+		BootstrapInstanceId: string(instanceIds[0]),
+		AgentVersion:        details.AgentVersion,
+		Model:               c.hostedModelName,
+		Timings:             timings,
+	}, nil
+}
+
+// formatBootstrapResultNone is the default formatter for bootstrap's
+// result. Bootstrap reports its progress as it goes, so by default
+// nothing more is written.
+func formatBootstrapResultNone(io.Writer, interface{}) error {
+	return nil
 }
 
 // runInteractive queries the user about bootstrap config interactively at the
 // command prompt.
 func (c *bootstrapCommand) runInteractive(ctx *cmd.Context) error {
 	scanner := bufio.NewScanner(ctx.Stdin)
+	c.scanner = scanner
 	clouds, err := assembleClouds()
 	if err != nil {
 		return errors.Trace(err)
@@ -842,6 +1220,44 @@ func (c *bootstrapCommand) runInteractive(ctx *cmd.Context) error {
 	return nil
 }
 
+// checkRequiredConfig checks that each of the provider's required config
+// attributes is specified in the model config attributes, or in the
+// cloud's or region's config. When bootstrapping interactively, the user
+// is asked for any that are missing, and their answers are added to the
+// model config; otherwise, all of the missing attributes are reported in
+// a single error.
+func (c *bootstrapCommand) checkRequiredConfig(
+	ctx *cmd.Context,
+	cloudType string,
+	required environschema.Fields,
+	modelConfigAttrs map[string]interface{},
+	otherAttrs ...map[string]interface{},
+) error {
+	missing := missingRequiredConfig(required, append(otherAttrs, modelConfigAttrs)...)
+	if len(missing) == 0 {
+		return nil
+	}
+	if c.interactive && c.scanner != nil {
+		values, err := queryRequiredConfig(missing, required, c.scanner, ctx.Stdout)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		for k, v := range values {
+			modelConfigAttrs[k] = v
+		}
+		return nil
+	}
+	lines := make([]string, len(missing))
+	for i, name := range missing {
+		lines[i] = fmt.Sprintf("    %s: %s", name, required[name].Description)
+	}
+	return errors.Errorf(
+		"missing required config for %q cloud:\n%s\n"+
+			"specify these with --config, or in the cloud's config in clouds.yaml",
+		cloudType, strings.Join(lines, "\n"),
+	)
+}
+
 // getRegion returns the cloud.Region to use, based on the specified
 // region name.  If no region name is specified, and there is at least
 // one region, we use the first region in the list.
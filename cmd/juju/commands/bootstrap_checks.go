@@ -0,0 +1,364 @@
+// Copyright 2016 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package commands
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/juju/cmd"
+	"github.com/juju/errors"
+	"github.com/juju/utils"
+
+	"github.com/juju/juju/api/controller"
+	"github.com/juju/juju/api/modelmanager"
+	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/cert"
+	"github.com/juju/juju/cmd/modelcmd"
+	"github.com/juju/juju/cmd/output"
+	"github.com/juju/juju/jujuclient"
+)
+
+// minControllerFreeDisk is the least free disk space, in MiB, that
+// a controller should have. Below this, the controller is likely to
+// run out of space for the database and logs.
+const minControllerFreeDisk = 2 * 1024
+
+// apiCertificateDialTimeout is how long to wait for each of the
+// controller's API endpoints to complete a TLS handshake.
+const apiCertificateDialTimeout = 10 * time.Second
+
+// postBootstrapContext holds the information about a newly
+// bootstrapped controller that is available to post-bootstrap
+// checks.
+type postBootstrapContext struct {
+	// Controller holds the client-side details of the controller.
+	Controller *jujuclient.ControllerDetails
+
+	// Endpoints holds the outcome of connecting to each of the
+	// controller's API endpoints.
+	Endpoints []apiEndpointResult
+
+	// Health holds the results of the checks that the controller
+	// ran against itself, or nil if HealthError is set.
+	Health *params.ControllerHealth
+
+	// HealthError holds the error, if any, encountered while
+	// asking the controller to check its health.
+	HealthError error
+
+	// Now is the time at which the checks are run.
+	Now time.Time
+}
+
+// apiEndpointResult holds the outcome of connecting to one of the
+// controller's API endpoints.
+type apiEndpointResult struct {
+	// Address is the address of the endpoint.
+	Address string
+
+	// Certificate is the certificate presented by the API server
+	// at the endpoint, or nil if Error is set.
+	Certificate *x509.Certificate
+
+	// Error holds the error, if any, encountered while connecting
+	// to the endpoint.
+	Error error
+}
+
+// postBootstrapCheck is a check run against a controller once
+// bootstrap has completed.
+type postBootstrapCheck struct {
+	// Name is the name of the check, as shown in the summary.
+	Name string
+
+	// Check runs the check, returning a short description of the
+	// outcome, or an error describing the problem found. Problems
+	// are reported as warnings; they do not cause bootstrap to fail.
+	Check func(postBootstrapContext) (string, error)
+}
+
+// postBootstrapChecks holds the checks that are run against a
+// controller once bootstrap has completed. Add to this to extend
+// the checks that are run.
+var postBootstrapChecks = []postBootstrapCheck{
+	{"api-reachability", checkAPIReachability},
+	{"api-certificate", checkAPICertificate},
+	{"controller-disk", checkControllerDisk},
+	{"provider-access", checkProviderAccess},
+}
+
+// postBootstrapResult records the outcome of a post-bootstrap check,
+// or other post-bootstrap step.
+type postBootstrapResult struct {
+	name   string
+	err    error
+	detail string
+
+	// fatal records whether err causes bootstrap to fail,
+	// rather than being reported as a warning.
+	fatal bool
+}
+
+// postBootstrapAPI provides the API methods required to run the
+// post-bootstrap steps.
+type postBootstrapAPI interface {
+	// ControllerHealth asks the controller to check its health.
+	ControllerHealth() (params.ControllerHealth, error)
+
+	// SetModelDefaults sets the controller-wide model defaults.
+	SetModelDefaults(map[string]interface{}) error
+
+	// Close closes the API connections.
+	Close() error
+}
+
+type postBootstrapAPIClient struct {
+	modelmanager *modelmanager.Client
+	controller   *controller.Client
+}
+
+func (c postBootstrapAPIClient) ControllerHealth() (params.ControllerHealth, error) {
+	return c.controller.ControllerHealth()
+}
+
+func (c postBootstrapAPIClient) SetModelDefaults(attrs map[string]interface{}) error {
+	return c.modelmanager.SetModelDefaults("", "", attrs)
+}
+
+func (c postBootstrapAPIClient) Close() error {
+	// Both clients share the controller connection.
+	return c.controller.Close()
+}
+
+// newPostBootstrapAPI returns a postBootstrapAPI for the controller
+// with the specified name.
+var newPostBootstrapAPI = func(c *modelcmd.ModelCommandBase, controllerName string) (postBootstrapAPI, error) {
+	store := c.ClientStore()
+	controllerRoot, err := c.JujuCommandBase.NewAPIRoot(store, controllerName, "")
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	return postBootstrapAPIClient{
+		modelmanager: modelmanager.NewClient(controllerRoot),
+		controller:   controller.NewClient(controllerRoot),
+	}, nil
+}
+
+// runPostBootstrap applies the specified model defaults to the newly
+// bootstrapped controller, runs the post-bootstrap checks against it,
+// and reports a summary of the outcomes. Problems found by the checks
+// are reported as warnings, and do not cause bootstrap to fail; an
+// error is only returned if the model defaults could not be applied.
+var runPostBootstrap = func(
+	ctx *cmd.Context,
+	c *modelcmd.ModelCommandBase,
+	controllerName string,
+	modelDefaults map[string]interface{},
+) error {
+	api, err := newPostBootstrapAPI(c, controllerName)
+	if err != nil {
+		return errors.Annotate(err, "connecting to controller")
+	}
+	defer api.Close()
+
+	var results []postBootstrapResult
+	var defaultsErr error
+	if len(modelDefaults) > 0 {
+		defaultsErr = api.SetModelDefaults(modelDefaults)
+		keys := make([]string, 0, len(modelDefaults))
+		for key := range modelDefaults {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		results = append(results, postBootstrapResult{
+			name:   "model-defaults",
+			err:    defaultsErr,
+			detail: "set " + strings.Join(keys, ", "),
+			fatal:  true,
+		})
+	}
+
+	checkContext := postBootstrapContext{Now: time.Now()}
+	checkContext.Controller, err = c.ClientStore().ControllerByName(controllerName)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	checkContext.Endpoints = dialAPIEndpoints(checkContext.Controller)
+	if health, err := api.ControllerHealth(); err != nil {
+		checkContext.HealthError = errors.Annotate(err, "getting controller health")
+	} else {
+		checkContext.Health = &health
+	}
+	results = append(results, runPostBootstrapChecks(checkContext)...)
+	writePostBootstrapSummary(ctx, results)
+
+	if defaultsErr != nil {
+		return errors.Annotate(defaultsErr, "setting model defaults")
+	}
+	return nil
+}
+
+// dialAPIEndpoints connects to each of the controller's API endpoints,
+// recording the certificate presented by the API server at each. The
+// certificates are not verified while connecting, so that the checks
+// can report why a certificate is not acceptable.
+var dialAPIEndpoints = func(details *jujuclient.ControllerDetails) []apiEndpointResult {
+	results := make([]apiEndpointResult, len(details.APIEndpoints))
+	for i, addr := range details.APIEndpoints {
+		results[i].Address = addr
+		tlsConfig := utils.SecureTLSConfig()
+		tlsConfig.InsecureSkipVerify = true
+		conn, err := tls.DialWithDialer(
+			&net.Dialer{Timeout: apiCertificateDialTimeout},
+			"tcp", addr, tlsConfig,
+		)
+		if err != nil {
+			results[i].Error = err
+			continue
+		}
+		if certs := conn.ConnectionState().PeerCertificates; len(certs) > 0 {
+			results[i].Certificate = certs[0]
+		} else {
+			results[i].Error = errors.New("no certificate presented")
+		}
+		conn.Close()
+	}
+	return results
+}
+
+// runPostBootstrapChecks runs each of the post-bootstrap checks,
+// returning their results.
+func runPostBootstrapChecks(checkContext postBootstrapContext) []postBootstrapResult {
+	results := make([]postBootstrapResult, len(postBootstrapChecks))
+	for i, check := range postBootstrapChecks {
+		detail, err := check.Check(checkContext)
+		if err != nil {
+			logger.Warningf("post-bootstrap check %q: %v", check.Name, err)
+		}
+		results[i] = postBootstrapResult{name: check.Name, err: err, detail: detail}
+	}
+	return results
+}
+
+func writePostBootstrapSummary(ctx *cmd.Context, results []postBootstrapResult) {
+	tw := output.TabWriter(ctx.Stderr)
+	fmt.Fprintln(tw, "Check\tResult\tDetail")
+	for _, result := range results {
+		outcome, detail := "OK", result.detail
+		if result.err != nil {
+			outcome, detail = "WARNING", result.err.Error()
+			if result.fatal {
+				outcome = "FAILED"
+			}
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", result.name, outcome, detail)
+	}
+	tw.Flush()
+}
+
+// checkAPIReachability checks that each of the controller's API
+// endpoints accepts connections from the client.
+func checkAPIReachability(checkContext postBootstrapContext) (string, error) {
+	if len(checkContext.Endpoints) == 0 {
+		return "", errors.New("no API endpoints known")
+	}
+	var unreachable []string
+	for _, endpoint := range checkContext.Endpoints {
+		if endpoint.Error != nil {
+			unreachable = append(unreachable, fmt.Sprintf("%s (%v)", endpoint.Address, endpoint.Error))
+		}
+	}
+	if len(unreachable) > 0 {
+		return "", errors.Errorf("cannot reach %s", strings.Join(unreachable, ", "))
+	}
+	return fmt.Sprintf("%d endpoint(s) reachable", len(checkContext.Endpoints)), nil
+}
+
+// checkAPICertificate checks that the certificate presented by each
+// reachable API server is currently valid, and is signed by the
+// controller's CA certificate.
+func checkAPICertificate(checkContext postBootstrapContext) (string, error) {
+	caCert, err := cert.ParseCert(checkContext.Controller.CACert)
+	if err != nil {
+		return "", errors.Annotate(err, "parsing CA certificate")
+	}
+	roots := x509.NewCertPool()
+	roots.AddCert(caCert)
+
+	var expiry time.Time
+	for _, endpoint := range checkContext.Endpoints {
+		serverCert := endpoint.Certificate
+		if serverCert == nil {
+			continue
+		}
+		if checkContext.Now.Before(serverCert.NotBefore) {
+			return "", errors.Errorf(
+				"certificate at %s not valid until %s",
+				endpoint.Address, serverCert.NotBefore.UTC().Format(time.RFC3339),
+			)
+		}
+		if !checkContext.Now.Before(serverCert.NotAfter) {
+			return "", errors.Errorf(
+				"certificate at %s expired at %s",
+				endpoint.Address, serverCert.NotAfter.UTC().Format(time.RFC3339),
+			)
+		}
+		if _, err := serverCert.Verify(x509.VerifyOptions{
+			Roots:       roots,
+			DNSName:     "juju-apiserver",
+			CurrentTime: checkContext.Now,
+		}); err != nil {
+			return "", errors.Annotatef(err, "verifying certificate at %s", endpoint.Address)
+		}
+		if expiry.IsZero() || serverCert.NotAfter.Before(expiry) {
+			expiry = serverCert.NotAfter
+		}
+	}
+	if expiry.IsZero() {
+		return "", errors.New("no API server certificate retrieved")
+	}
+	return fmt.Sprintf("valid until %s", expiry.UTC().Format(time.RFC3339)), nil
+}
+
+// checkControllerDisk checks that the controller has enough free
+// disk space to keep running.
+func checkControllerDisk(checkContext postBootstrapContext) (string, error) {
+	if checkContext.HealthError != nil {
+		return "", checkContext.HealthError
+	}
+	health := checkContext.Health
+	if health.DiskError != nil {
+		return "", errors.Annotate(health.DiskError, "getting free disk space")
+	}
+	if health.FreeDiskSpace < minControllerFreeDisk {
+		return "", errors.Errorf(
+			"%dMiB free disk space, less than the recommended %dMiB",
+			health.FreeDiskSpace, minControllerFreeDisk,
+		)
+	}
+	return fmt.Sprintf("%dMiB free disk space", health.FreeDiskSpace), nil
+}
+
+// checkProviderAccess checks that the controller is able to reach
+// the provider's API, by having the controller query the provider
+// for the controller's instances.
+func checkProviderAccess(checkContext postBootstrapContext) (string, error) {
+	if checkContext.HealthError != nil {
+		return "", checkContext.HealthError
+	}
+	health := checkContext.Health
+	if health.ProviderError != nil {
+		return "", health.ProviderError
+	}
+	if len(health.ControllerInstances) == 0 {
+		return "", errors.New("provider reports no controller instances")
+	}
+	return fmt.Sprintf("controller instances %s", strings.Join(health.ControllerInstances, ", ")), nil
+}
@@ -0,0 +1,293 @@
+// Copyright 2016 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package commands
+
+import (
+	"crypto/x509"
+	"time"
+
+	"github.com/juju/errors"
+	"github.com/juju/testing"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/cert"
+	"github.com/juju/juju/cmd/modelcmd"
+	"github.com/juju/juju/jujuclient"
+	"github.com/juju/juju/jujuclient/jujuclienttesting"
+	coretesting "github.com/juju/juju/testing"
+)
+
+type postBootstrapSuite struct {
+	testing.IsolationSuite
+	serverCert   *x509.Certificate
+	checkContext postBootstrapContext
+}
+
+var _ = gc.Suite(&postBootstrapSuite{})
+
+func (s *postBootstrapSuite) SetUpTest(c *gc.C) {
+	s.IsolationSuite.SetUpTest(c)
+	s.serverCert = newAPIServerCert(c, coretesting.CACert, coretesting.CAKey, "juju-apiserver")
+	s.checkContext = postBootstrapContext{
+		Controller: &jujuclient.ControllerDetails{
+			APIEndpoints: []string{"10.0.0.1:17070"},
+			CACert:       coretesting.CACert,
+		},
+		Endpoints: []apiEndpointResult{{
+			Address:     "10.0.0.1:17070",
+			Certificate: s.serverCert,
+		}},
+		Health: &params.ControllerHealth{
+			FreeDiskSpace:       8192,
+			ControllerInstances: []string{"inst-0"},
+		},
+		Now: s.serverCert.NotBefore.Add(time.Hour),
+	}
+}
+
+func newAPIServerCert(c *gc.C, caCert, caKey string, hostnames ...string) *x509.Certificate {
+	certPEM, _, err := cert.NewServer(caCert, caKey, time.Now().AddDate(1, 0, 0), hostnames)
+	c.Assert(err, jc.ErrorIsNil)
+	serverCert, err := cert.ParseCert(string(certPEM))
+	c.Assert(err, jc.ErrorIsNil)
+	return serverCert
+}
+
+func (s *postBootstrapSuite) TestCheckAPIReachability(c *gc.C) {
+	detail, err := checkAPIReachability(s.checkContext)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(detail, gc.Equals, "1 endpoint(s) reachable")
+}
+
+func (s *postBootstrapSuite) TestCheckAPIReachabilityUnreachable(c *gc.C) {
+	s.checkContext.Endpoints = append(s.checkContext.Endpoints, apiEndpointResult{
+		Address: "10.0.0.2:17070",
+		Error:   errors.New("connection refused"),
+	})
+	_, err := checkAPIReachability(s.checkContext)
+	c.Assert(err, gc.ErrorMatches, `cannot reach 10.0.0.2:17070 \(connection refused\)`)
+}
+
+func (s *postBootstrapSuite) TestCheckAPIReachabilityNoEndpoints(c *gc.C) {
+	s.checkContext.Endpoints = nil
+	_, err := checkAPIReachability(s.checkContext)
+	c.Assert(err, gc.ErrorMatches, "no API endpoints known")
+}
+
+func (s *postBootstrapSuite) TestCheckAPICertificate(c *gc.C) {
+	detail, err := checkAPICertificate(s.checkContext)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(detail, gc.Equals, "valid until "+s.serverCert.NotAfter.UTC().Format(time.RFC3339))
+}
+
+func (s *postBootstrapSuite) TestCheckAPICertificateExpired(c *gc.C) {
+	s.checkContext.Now = s.serverCert.NotAfter
+	_, err := checkAPICertificate(s.checkContext)
+	c.Assert(err, gc.ErrorMatches, "certificate at 10.0.0.1:17070 expired at .*")
+}
+
+func (s *postBootstrapSuite) TestCheckAPICertificateNotYetValid(c *gc.C) {
+	s.checkContext.Now = s.serverCert.NotBefore.Add(-time.Second)
+	_, err := checkAPICertificate(s.checkContext)
+	c.Assert(err, gc.ErrorMatches, "certificate at 10.0.0.1:17070 not valid until .*")
+}
+
+func (s *postBootstrapSuite) TestCheckAPICertificateWrongCA(c *gc.C) {
+	s.checkContext.Endpoints[0].Certificate = newAPIServerCert(
+		c, coretesting.OtherCACert, coretesting.OtherCAKey, "juju-apiserver",
+	)
+	_, err := checkAPICertificate(s.checkContext)
+	c.Assert(err, gc.ErrorMatches, "verifying certificate at 10.0.0.1:17070: .*")
+}
+
+func (s *postBootstrapSuite) TestCheckAPICertificateWrongName(c *gc.C) {
+	s.checkContext.Endpoints[0].Certificate = newAPIServerCert(
+		c, coretesting.CACert, coretesting.CAKey, "elsewhere",
+	)
+	_, err := checkAPICertificate(s.checkContext)
+	c.Assert(err, gc.ErrorMatches, "verifying certificate at 10.0.0.1:17070: .*")
+}
+
+func (s *postBootstrapSuite) TestCheckAPICertificateUnreachable(c *gc.C) {
+	s.checkContext.Endpoints[0] = apiEndpointResult{
+		Address: "10.0.0.1:17070",
+		Error:   errors.New("connection refused"),
+	}
+	_, err := checkAPICertificate(s.checkContext)
+	c.Assert(err, gc.ErrorMatches, "no API server certificate retrieved")
+}
+
+func (s *postBootstrapSuite) TestCheckAPICertificateInvalidCA(c *gc.C) {
+	s.checkContext.Controller.CACert = "foo"
+	_, err := checkAPICertificate(s.checkContext)
+	c.Assert(err, gc.ErrorMatches, "parsing CA certificate: .*")
+}
+
+func (s *postBootstrapSuite) TestCheckControllerDisk(c *gc.C) {
+	detail, err := checkControllerDisk(s.checkContext)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(detail, gc.Equals, "8192MiB free disk space")
+}
+
+func (s *postBootstrapSuite) TestCheckControllerDiskLow(c *gc.C) {
+	s.checkContext.Health.FreeDiskSpace = 1024
+	_, err := checkControllerDisk(s.checkContext)
+	c.Assert(err, gc.ErrorMatches, "1024MiB free disk space, less than the recommended 2048MiB")
+}
+
+func (s *postBootstrapSuite) TestCheckControllerDiskError(c *gc.C) {
+	s.checkContext.Health.DiskError = &params.Error{Message: "data directory not found"}
+	_, err := checkControllerDisk(s.checkContext)
+	c.Assert(err, gc.ErrorMatches, "getting free disk space: data directory not found")
+}
+
+func (s *postBootstrapSuite) TestCheckControllerDiskHealthError(c *gc.C) {
+	s.checkContext.Health = nil
+	s.checkContext.HealthError = errors.New("getting controller health: boom")
+	_, err := checkControllerDisk(s.checkContext)
+	c.Assert(err, gc.ErrorMatches, "getting controller health: boom")
+}
+
+func (s *postBootstrapSuite) TestCheckProviderAccess(c *gc.C) {
+	detail, err := checkProviderAccess(s.checkContext)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(detail, gc.Equals, "controller instances inst-0")
+}
+
+func (s *postBootstrapSuite) TestCheckProviderAccessError(c *gc.C) {
+	s.checkContext.Health.ProviderError = &params.Error{Message: "unauthorized"}
+	_, err := checkProviderAccess(s.checkContext)
+	c.Assert(err, gc.ErrorMatches, "unauthorized")
+}
+
+func (s *postBootstrapSuite) TestCheckProviderAccessNoInstances(c *gc.C) {
+	s.checkContext.Health.ControllerInstances = nil
+	_, err := checkProviderAccess(s.checkContext)
+	c.Assert(err, gc.ErrorMatches, "provider reports no controller instances")
+}
+
+func (s *postBootstrapSuite) TestRunPostBootstrap(c *gc.C) {
+	api := s.patchAPI(nil)
+	s.PatchValue(&postBootstrapChecks, []postBootstrapCheck{{
+		Name: "good",
+		Check: func(checkContext postBootstrapContext) (string, error) {
+			c.Check(checkContext.Controller.CACert, gc.Equals, coretesting.CACert)
+			c.Check(checkContext.Endpoints, jc.DeepEquals, s.checkContext.Endpoints)
+			c.Check(checkContext.Health, jc.DeepEquals, s.checkContext.Health)
+			c.Check(checkContext.HealthError, jc.ErrorIsNil)
+			return "all good", nil
+		},
+	}, {
+		Name: "bad",
+		Check: func(postBootstrapContext) (string, error) {
+			return "", errors.New("all bad")
+		},
+	}})
+
+	ctx := coretesting.Context(c)
+	err := runPostBootstrap(ctx, s.newModelCommandBase(), "ctrl", map[string]interface{}{
+		"image-stream": "daily",
+		"ftp-proxy":    "foo",
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(api.defaults, jc.DeepEquals, map[string]interface{}{
+		"image-stream": "daily",
+		"ftp-proxy":    "foo",
+	})
+	c.Assert(api.closed, jc.IsTrue)
+	c.Assert(coretesting.Stderr(ctx), gc.Equals, `
+Check           Result   Detail
+model-defaults  OK       set ftp-proxy, image-stream
+good            OK       all good
+bad             WARNING  all bad
+`[1:])
+}
+
+func (s *postBootstrapSuite) TestRunPostBootstrapHealthError(c *gc.C) {
+	api := s.patchAPI(nil)
+	api.healthErr = errors.New("boom")
+	s.PatchValue(&postBootstrapChecks, []postBootstrapCheck{
+		{"controller-disk", checkControllerDisk},
+	})
+
+	ctx := coretesting.Context(c)
+	err := runPostBootstrap(ctx, s.newModelCommandBase(), "ctrl", nil)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(coretesting.Stderr(ctx), gc.Equals, `
+Check            Result   Detail
+controller-disk  WARNING  getting controller health: boom
+`[1:])
+}
+
+func (s *postBootstrapSuite) TestRunPostBootstrapNoDefaults(c *gc.C) {
+	api := s.patchAPI(nil)
+	s.PatchValue(&postBootstrapChecks, []postBootstrapCheck{})
+
+	ctx := coretesting.Context(c)
+	err := runPostBootstrap(ctx, s.newModelCommandBase(), "ctrl", map[string]interface{}{})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(api.defaults, gc.IsNil)
+	c.Assert(coretesting.Stderr(ctx), gc.Equals, "Check  Result  Detail\n")
+}
+
+func (s *postBootstrapSuite) TestRunPostBootstrapDefaultsError(c *gc.C) {
+	s.patchAPI(errors.New("boom"))
+	s.PatchValue(&postBootstrapChecks, []postBootstrapCheck{})
+
+	ctx := coretesting.Context(c)
+	err := runPostBootstrap(ctx, s.newModelCommandBase(), "ctrl", map[string]interface{}{
+		"ftp-proxy": "foo",
+	})
+	c.Assert(err, gc.ErrorMatches, "setting model defaults: boom")
+	c.Assert(coretesting.Stderr(ctx), gc.Equals, `
+Check           Result  Detail
+model-defaults  FAILED  boom
+`[1:])
+}
+
+func (s *postBootstrapSuite) newModelCommandBase() *modelcmd.ModelCommandBase {
+	store := jujuclienttesting.NewMemStore()
+	store.Controllers["ctrl"] = *s.checkContext.Controller
+	base := &modelcmd.ModelCommandBase{}
+	base.SetClientStore(store)
+	return base
+}
+
+func (s *postBootstrapSuite) patchAPI(setDefaultsErr error) *fakePostBootstrapAPI {
+	api := &fakePostBootstrapAPI{
+		health:         *s.checkContext.Health,
+		setDefaultsErr: setDefaultsErr,
+	}
+	s.PatchValue(&newPostBootstrapAPI, func(*modelcmd.ModelCommandBase, string) (postBootstrapAPI, error) {
+		return api, nil
+	})
+	s.PatchValue(&dialAPIEndpoints, func(details *jujuclient.ControllerDetails) []apiEndpointResult {
+		return s.checkContext.Endpoints
+	})
+	return api
+}
+
+type fakePostBootstrapAPI struct {
+	health         params.ControllerHealth
+	healthErr      error
+	defaults       map[string]interface{}
+	setDefaultsErr error
+	closed         bool
+}
+
+func (api *fakePostBootstrapAPI) ControllerHealth() (params.ControllerHealth, error) {
+	return api.health, api.healthErr
+}
+
+func (api *fakePostBootstrapAPI) SetModelDefaults(attrs map[string]interface{}) error {
+	api.defaults = attrs
+	return api.setDefaultsErr
+}
+
+func (api *fakePostBootstrapAPI) Close() error {
+	api.closed = true
+	return nil
+}
@@ -11,6 +11,8 @@ import (
 	"strings"
 
 	"github.com/juju/errors"
+	"gopkg.in/juju/environschema.v1"
+
 	jujucloud "github.com/juju/juju/cloud"
 	"github.com/juju/juju/cmd/juju/common"
 	"github.com/juju/juju/cmd/juju/interact"
@@ -124,3 +126,60 @@ func sortClouds(maps ...map[string]jujucloud.Cloud) []string {
 	sort.Strings(clouds)
 	return clouds
 }
+
+// missingRequiredConfig returns the sorted names of the required config
+// attributes that are not specified in any of the given attributes.
+func missingRequiredConfig(required environschema.Fields, attrs ...map[string]interface{}) []string {
+	var missing []string
+	for name := range required {
+		specified := false
+		for _, attrs := range attrs {
+			if v, ok := attrs[name]; ok && v != nil && v != "" {
+				specified = true
+				break
+			}
+		}
+		if !specified {
+			missing = append(missing, name)
+		}
+	}
+	sort.Strings(missing)
+	return missing
+}
+
+// queryRequiredConfig asks the user for the value of each of the named
+// required config attributes, returning the values coerced to the types
+// declared in the schema.
+func queryRequiredConfig(
+	names []string,
+	required environschema.Fields,
+	scanner *bufio.Scanner,
+	w io.Writer,
+) (map[string]interface{}, error) {
+	values := make(map[string]interface{})
+	for _, name := range names {
+		attr := required[name]
+		checker, err := attr.Checker()
+		if err != nil {
+			return nil, errors.Annotatef(err, "getting checker for %q", name)
+		}
+		var value interface{}
+		verify := func(answer string) error {
+			if answer == "" {
+				return errors.Errorf("%s must be specified.", name)
+			}
+			v, err := checker.Coerce(answer, nil)
+			if err != nil {
+				return errors.Errorf("Invalid %s: %v.", name, err)
+			}
+			value = v
+			return nil
+		}
+		query := fmt.Sprintf("Enter %s (%s): ", name, attr.Description)
+		if _, err := interact.QueryVerify([]byte(query), scanner, w, verify); err != nil {
+			return nil, errors.Trace(err)
+		}
+		values[name] = value
+	}
+	return values, nil
+}
@@ -12,6 +12,7 @@ import (
 	"github.com/juju/testing"
 	jc "github.com/juju/testing/checkers"
 	gc "gopkg.in/check.v1"
+	"gopkg.in/juju/environschema.v1"
 
 	jujucloud "github.com/juju/juju/cloud"
 	jujutesting "github.com/juju/juju/testing"
@@ -167,3 +168,53 @@ func (BSInteractSuite) TestQueryNameDefault(c *gc.C) {
 	c.Assert(err, jc.ErrorIsNil)
 	c.Assert(name, gc.Equals, "default-cloud")
 }
+
+var testRequiredConfig = environschema.Fields{
+	"datastore": {
+		Description: "the datastore",
+		Type:        environschema.Tstring,
+	},
+	"disk-size": {
+		Description: "the disk size",
+		Type:        environschema.Tint,
+	},
+}
+
+func (BSInteractSuite) TestMissingRequiredConfig(c *gc.C) {
+	missing := missingRequiredConfig(testRequiredConfig,
+		map[string]interface{}{"datastore": ""},
+		map[string]interface{}{"disk-size": nil},
+	)
+	c.Assert(missing, jc.DeepEquals, []string{"datastore", "disk-size"})
+
+	missing = missingRequiredConfig(testRequiredConfig,
+		map[string]interface{}{"datastore": ""},
+		map[string]interface{}{"datastore": "ds1", "disk-size": 10},
+	)
+	c.Assert(missing, gc.HasLen, 0)
+}
+
+func (BSInteractSuite) TestQueryRequiredConfig(c *gc.C) {
+	input := "\nds1\nbig\n10\n"
+
+	scanner := bufio.NewScanner(strings.NewReader(input))
+	buf := bytes.Buffer{}
+	values, err := queryRequiredConfig(
+		[]string{"datastore", "disk-size"}, testRequiredConfig, scanner, &buf,
+	)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(values, jc.DeepEquals, map[string]interface{}{
+		"datastore": "ds1",
+		"disk-size": int64(10),
+	})
+
+	expected := `
+Enter datastore (the datastore): datastore must be specified.
+
+Enter datastore (the datastore): 
+Enter disk-size (the disk size): Invalid disk-size: .*
+
+Enter disk-size (the disk size): 
+`[1:]
+	c.Assert(buf.String(), gc.Matches, expected)
+}
@@ -0,0 +1,122 @@
+// Copyright 2016 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package commands
+
+import (
+	"io"
+
+	"github.com/juju/cmd"
+	"github.com/juju/errors"
+
+	apibackups "github.com/juju/juju/api/backups"
+	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/cmd/juju/backups"
+	"github.com/juju/juju/cmd/modelcmd"
+	"github.com/juju/juju/environs/bootstrap"
+	"github.com/juju/juju/jujuclient"
+)
+
+// getBackupArchive opens the backup archive specified with --restore.
+var getBackupArchive = backups.GetArchive
+
+// prepareRestoreAttrs updates the bootstrap and model config attributes
+// so that the controller being bootstrapped can be restored from the
+// backup with the given metadata.
+//
+// The controller's CA certificate and key are taken from the backup, so
+// that existing agents will continue to trust the restored controller.
+// The admin-secret must be specified, and must be the admin password of
+// the backed up controller: once restored, the controller will only
+// accept that password, and it is recorded as the client's password.
+func prepareRestoreAttrs(
+	meta *params.BackupsMetadataResult,
+	bootstrapAttrs, modelAttrs map[string]interface{},
+) error {
+	if meta.CACert == "" || meta.CAPrivateKey == "" {
+		return errors.New("backup does not contain the controller's CA certificate and key")
+	}
+	if _, ok := bootstrapAttrs[bootstrap.AdminSecretKey]; !ok {
+		return errors.Errorf(
+			"--restore requires the admin password of the backed up controller; specify it with --config %s=<password>",
+			bootstrap.AdminSecretKey,
+		)
+	}
+	bootstrapAttrs[bootstrap.CACertKey] = meta.CACert
+	bootstrapAttrs[bootstrap.CAPrivateKeyKey] = meta.CAPrivateKey
+
+	// Turn on safe mode so that the newly bootstrapped controller
+	// will not destroy the instances it does not yet know about.
+	modelAttrs["provisioner-safe-mode"] = true
+	return nil
+}
+
+// restoreBackup restores the state of the newly bootstrapped controller
+// from the backup archive, and then updates the client's details of the
+// controller and its models to match the restored controller.
+var restoreBackup = func(
+	ctx *cmd.Context,
+	c *modelcmd.ModelCommandBase,
+	controllerName, hostedModelName string,
+	archive io.ReadSeeker,
+	meta *params.BackupsMetadataResult,
+) error {
+	store := c.ClientStore()
+	newClient := func() (*apibackups.Client, error) {
+		root, err := c.JujuCommandBase.NewAPIRoot(store, controllerName, bootstrap.ControllerModelName)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		client, err := apibackups.NewClient(root)
+		if err != nil {
+			root.Close()
+			return nil, errors.Trace(err)
+		}
+		return client, nil
+	}
+	client, err := newClient()
+	if err != nil {
+		return errors.Trace(err)
+	}
+	defer client.Close()
+
+	ctx.Infof("Restoring controller from backup %q", meta.ID)
+	if err := client.RestoreReader(archive, meta, newClient); err != nil {
+		return errors.Trace(err)
+	}
+
+	// The restored controller has the UUID of the backed up controller,
+	// and none of the models created by bootstrap.
+	root, err := c.JujuCommandBase.NewAPIRoot(store, controllerName, "")
+	if err != nil {
+		return errors.Trace(err)
+	}
+	controllerUUID := root.ControllerTag().Id()
+	root.Close()
+	details, err := store.ControllerByName(controllerName)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	details.ControllerUUID = controllerUUID
+	if err := store.UpdateController(controllerName, *details); err != nil {
+		return errors.Trace(err)
+	}
+	for _, modelName := range []string{bootstrap.ControllerModelName, hostedModelName} {
+		if err := store.RemoveModel(controllerName, modelName); err != nil && !errors.IsNotFound(err) {
+			return errors.Trace(err)
+		}
+	}
+	if err := c.RefreshModels(store, controllerName); err != nil {
+		return errors.Annotate(err, "refreshing models")
+	}
+	// Keep the hosted model current if the backup has one of the same
+	// name; otherwise the user must select a model.
+	if _, err := store.ModelByName(controllerName, hostedModelName); err == nil {
+		if err := store.SetCurrentModel(controllerName, hostedModelName); err != nil {
+			return errors.Trace(err)
+		}
+	} else if !errors.IsNotFound(err) {
+		return errors.Trace(err)
+	}
+	return nil
+}
@@ -0,0 +1,106 @@
+// Copyright 2016 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package commands
+
+import (
+	"github.com/juju/errors"
+
+	"github.com/juju/juju/environs/bootstrap"
+	"github.com/juju/juju/jujuclient"
+)
+
+// resumeDetails holds the details of a controller, recorded in the
+// client store by a failed bootstrap, that are required to resume
+// bootstrapping the controller.
+type resumeDetails struct {
+	controllerUUID      string
+	controllerModelUUID string
+	hostedModelUUID     string
+	caCert              string
+	adminSecret         string
+}
+
+// readResumeDetails reads the details of the named controller from the
+// client store, so that its bootstrap may be resumed. The controller's
+// details are only kept in the client store after a failed bootstrap if
+// --keep-broken was specified.
+func readResumeDetails(
+	store jujuclient.ClientStore,
+	controllerName, cloudName, hostedModelName string,
+) (*resumeDetails, error) {
+	controllerDetails, err := store.ControllerByName(controllerName)
+	if errors.IsNotFound(err) {
+		return nil, errors.Errorf(
+			"controller %q not found; only a bootstrap that failed with --keep-broken can be resumed",
+			controllerName,
+		)
+	} else if err != nil {
+		return nil, errors.Trace(err)
+	}
+	bootstrapConfig, err := store.BootstrapConfigForController(controllerName)
+	if err != nil {
+		return nil, errors.Annotate(err, "getting bootstrap config")
+	}
+	if !bootstrapConfig.BootstrapFailed {
+		return nil, errors.Errorf("controller %q has no failed bootstrap to resume", controllerName)
+	}
+	if bootstrapConfig.Cloud != cloudName {
+		return nil, errors.Errorf(
+			"controller %q was bootstrapped on cloud %q, not %q",
+			controllerName, bootstrapConfig.Cloud, cloudName,
+		)
+	}
+	accountDetails, err := store.AccountDetails(controllerName)
+	if err != nil {
+		return nil, errors.Annotate(err, "getting account details")
+	}
+	hostedModel, err := store.ModelByName(controllerName, hostedModelName)
+	if err != nil {
+		return nil, errors.Annotatef(err, "getting model %q", hostedModelName)
+	}
+	return &resumeDetails{
+		controllerUUID:      controllerDetails.ControllerUUID,
+		controllerModelUUID: bootstrapConfig.ControllerModelUUID,
+		hostedModelUUID:     hostedModel.ModelUUID,
+		caCert:              controllerDetails.CACert,
+		adminSecret:         accountDetails.Password,
+	}, nil
+}
+
+// prepareResumeAttrs updates the bootstrap config attributes so that
+// the controller's admin password matches the one given to the bootstrap
+// instance by the failed bootstrap.
+//
+// The controller's CA private key is not recorded by the client, so a
+// new CA is generated. If the bootstrap instance's agent was configured
+// by the failed bootstrap, that configuration is kept instead, and the
+// controller's original CA certificate restored (see restoreCACert).
+func prepareResumeAttrs(details *resumeDetails, bootstrapAttrs map[string]interface{}) {
+	bootstrapAttrs[bootstrap.AdminSecretKey] = details.adminSecret
+}
+
+// recordResumeDetails records that bootstrapping the controller failed
+// and its resources were kept, so that the bootstrap may be resumed.
+// Together with the controller's other details in the client store,
+// this is enough to resume bootstrapping the controller.
+func recordResumeDetails(store jujuclient.ClientStore, controllerName string) error {
+	bootstrapConfig, err := store.BootstrapConfigForController(controllerName)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	bootstrapConfig.BootstrapFailed = true
+	return errors.Trace(store.UpdateBootstrapConfig(controllerName, *bootstrapConfig))
+}
+
+// restoreCACert records the given CA certificate as the named
+// controller's, when a resumed bootstrap keeps the configuration
+// given to the bootstrap instance by the failed bootstrap.
+func restoreCACert(store jujuclient.ClientStore, controllerName, caCert string) error {
+	details, err := store.ControllerByName(controllerName)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	details.CACert = caCert
+	return errors.Trace(store.UpdateController(controllerName, *details))
+}
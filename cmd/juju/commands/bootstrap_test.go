@@ -5,7 +5,9 @@ package commands
 
 import (
 	"bytes"
+	"encoding/json"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"os"
 	"path/filepath"
@@ -24,8 +26,12 @@ import (
 	"github.com/juju/utils/series"
 	"github.com/juju/version"
 	gc "gopkg.in/check.v1"
+	"gopkg.in/juju/environschema.v1"
+	goyaml "gopkg.in/yaml.v2"
 
+	"github.com/juju/juju/apiserver/params"
 	"github.com/juju/juju/cloud"
+	"github.com/juju/juju/cmd/juju/backups"
 	"github.com/juju/juju/cmd/modelcmd"
 	cmdtesting "github.com/juju/juju/cmd/testing"
 	"github.com/juju/juju/constraints"
@@ -71,6 +77,7 @@ func init() {
 	environs.RegisterProvider("no-cloud-regions", noCloudRegionsProvider{dummyProvider})
 	environs.RegisterProvider("no-credentials", noCredentialsProvider{})
 	environs.RegisterProvider("many-credentials", manyCredentialsProvider{dummyProvider})
+	environs.RegisterProvider("required-config", requiredConfigProvider{dummyProvider})
 }
 
 func (s *BootstrapSuite) SetUpSuite(c *gc.C) {
@@ -103,6 +110,9 @@ func (s *BootstrapSuite) SetUpTest(c *gc.C) {
 	s.PatchValue(&waitForAgentInitialisation, func(*cmd.Context, *modelcmd.ModelCommandBase, string, string) error {
 		return nil
 	})
+	s.PatchValue(&runPostBootstrap, func(*cmd.Context, *modelcmd.ModelCommandBase, string, map[string]interface{}) error {
+		return nil
+	})
 
 	// TODO(wallyworld) - add test data when tests are improved
 	s.store = jujuclienttesting.NewMemStore()
@@ -389,6 +399,26 @@ var bootstrapTests = []bootstrapTest{{
 	version: "1.3.3-saucy-ppc64el",
 	args:    []string{"--agent-version", "1.4.0"},
 	err:     `requested agent version major.minor mismatch`,
+}, {
+	info: "invalid --agent-stream value",
+	args: []string{"--agent-stream", "foo"},
+	err:  `agent stream "foo" not valid`,
+}, {
+	info: "invalid --storage-pool value",
+	args: []string{"--storage-pool", "fast"},
+	err:  `invalid --storage-pool "fast": expected <name>=<type>\[,<attr>=<value>...\]`,
+}, {
+	info: "invalid --storage-pool attribute",
+	args: []string{"--storage-pool", "fast=ebs,iops"},
+	err:  `invalid --storage-pool "fast=ebs,iops": expected "key=value", got "iops"`,
+}, {
+	info: "--storage-pool with type attribute",
+	args: []string{"--storage-pool", "fast=ebs,type=loop"},
+	err:  `invalid --storage-pool "fast=ebs,type=loop": "type" may not be set as an attribute`,
+}, {
+	info: "duplicate --storage-pool",
+	args: []string{"--storage-pool", "fast=ebs", "--storage-pool", "fast=loop"},
+	err:  `storage pool "fast" specified more than once`,
 }, {
 	info: "--clouds with --regions",
 	args: []string{"--clouds", "--regions", "aws"},
@@ -462,6 +492,55 @@ func (s *BootstrapSuite) TestBootstrapSetsControllerDetails(c *gc.C) {
 	c.Assert(details.AgentVersion, gc.Equals, jujuversion.Current.String())
 }
 
+func (s *BootstrapSuite) TestBootstrapFormatJSON(c *gc.C) {
+	s.patchVersionAndSeries(c, "raring")
+
+	ctx, err := coretesting.RunCommand(c, s.newBootstrapCommand(), "devcontroller", "dummy", "--auto-upgrade", "--format", "json")
+	c.Assert(err, jc.ErrorIsNil)
+	details, err := s.store.ControllerByName("devcontroller")
+	c.Assert(err, jc.ErrorIsNil)
+
+	var result map[string]interface{}
+	err = json.Unmarshal([]byte(coretesting.Stdout(ctx)), &result)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(result["controller-name"], gc.Equals, "devcontroller")
+	c.Assert(result["controller-uuid"], gc.Equals, details.ControllerUUID)
+	c.Assert(result["cloud"], gc.Equals, "dummy")
+	c.Assert(result["agent-version"], gc.Equals, jujuversion.Current.String())
+	c.Assert(result["model"], gc.Equals, "default")
+	c.Assert(result["bootstrap-instance-id"], gc.Not(gc.Equals), "")
+	c.Assert(result["api-endpoints"], gc.HasLen, len(details.APIEndpoints))
+	timings, ok := result["timings"].(map[string]interface{})
+	c.Assert(ok, jc.IsTrue)
+	for _, key := range []string{"bootstrap", "agent-initialisation", "total"} {
+		c.Check(timings[key], gc.FitsTypeOf, float64(0), gc.Commentf("timing %q", key))
+	}
+	c.Assert(timings["restore"], gc.IsNil)
+}
+
+func (s *BootstrapSuite) TestBootstrapFormatYAML(c *gc.C) {
+	s.patchVersionAndSeries(c, "raring")
+
+	ctx, err := coretesting.RunCommand(c, s.newBootstrapCommand(), "devcontroller", "dummy", "--auto-upgrade", "--format", "yaml")
+	c.Assert(err, jc.ErrorIsNil)
+	details, err := s.store.ControllerByName("devcontroller")
+	c.Assert(err, jc.ErrorIsNil)
+
+	var result map[string]interface{}
+	err = goyaml.Unmarshal([]byte(coretesting.Stdout(ctx)), &result)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(result["controller-uuid"], gc.Equals, details.ControllerUUID)
+	c.Assert(result["timings"], gc.NotNil)
+}
+
+func (s *BootstrapSuite) TestBootstrapNoFormatWritesNothingToStdout(c *gc.C) {
+	s.patchVersionAndSeries(c, "raring")
+
+	ctx, err := coretesting.RunCommand(c, s.newBootstrapCommand(), "devcontroller", "dummy", "--auto-upgrade")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(coretesting.Stdout(ctx), gc.Equals, "")
+}
+
 func (s *BootstrapSuite) TestBootstrapDefaultModel(c *gc.C) {
 	s.patchVersionAndSeries(c, "raring")
 
@@ -482,6 +561,60 @@ func (s *BootstrapSuite) TestBootstrapDefaultModel(c *gc.C) {
 	c.Assert(bootstrap.args.HostedModelConfig["foo"], gc.Equals, "bar")
 }
 
+func (s *BootstrapSuite) TestBootstrapAgentStream(c *gc.C) {
+	s.patchVersionAndSeries(c, "raring")
+
+	var bootstrap fakeBootstrapFuncs
+	s.PatchValue(&getBootstrapFuncs, func() BootstrapInterface {
+		return &bootstrap
+	})
+
+	coretesting.RunCommand(
+		c, s.newBootstrapCommand(),
+		"devcontroller", "dummy",
+		"--auto-upgrade",
+		"--agent-stream", "proposed",
+	)
+	c.Assert(bootstrap.args.HostedModelConfig["agent-stream"], gc.Equals, "proposed")
+}
+
+func (s *BootstrapSuite) TestBootstrapStoragePools(c *gc.C) {
+	s.patchVersionAndSeries(c, "raring")
+
+	var bootstrap fakeBootstrapFuncs
+	s.PatchValue(&getBootstrapFuncs, func() BootstrapInterface {
+		return &bootstrap
+	})
+
+	coretesting.RunCommand(
+		c, s.newBootstrapCommand(),
+		"devcontroller", "dummy",
+		"--auto-upgrade",
+		"--storage-pool", "fast=ebs,volume-type=provisioned-iops,iops=30",
+		"--storage-pool", "scratch=tmpfs",
+	)
+	c.Assert(bootstrap.args.HostedModelStoragePools, jc.DeepEquals, map[string]map[string]interface{}{
+		"fast": {
+			"type":        "ebs",
+			"volume-type": "provisioned-iops",
+			"iops":        "30",
+		},
+		"scratch": {"type": "tmpfs"},
+	})
+}
+
+func (s *BootstrapSuite) TestBootstrapAgentStreamConflict(c *gc.C) {
+	s.patchVersionAndSeries(c, "raring")
+	_, err := coretesting.RunCommand(
+		c, s.newBootstrapCommand(),
+		"devcontroller", "dummy",
+		"--auto-upgrade",
+		"--agent-stream", "proposed",
+		"--config", "agent-stream=devel",
+	)
+	c.Assert(err, gc.ErrorMatches, `--agent-stream "proposed" conflicts with agent-stream=devel`)
+}
+
 func (s *BootstrapSuite) TestBootstrapTimeout(c *gc.C) {
 	s.patchVersionAndSeries(c, "raring")
 
@@ -496,6 +629,83 @@ func (s *BootstrapSuite) TestBootstrapTimeout(c *gc.C) {
 	c.Assert(bootstrap.args.DialOpts.Timeout, gc.Equals, 99*time.Second)
 }
 
+type nopCloseReader struct {
+	io.ReadSeeker
+}
+
+func (nopCloseReader) Close() error {
+	return nil
+}
+
+func (s *BootstrapSuite) patchBackupArchive(c *gc.C) (backups.ArchiveReader, *params.BackupsMetadataResult) {
+	archive := nopCloseReader{strings.NewReader("archive")}
+	meta := &params.BackupsMetadataResult{
+		ID:           "backup-id",
+		Series:       "raring",
+		CACert:       coretesting.CACert,
+		CAPrivateKey: coretesting.CAKey,
+	}
+	s.PatchValue(&getBackupArchive, func(filename string) (backups.ArchiveReader, *params.BackupsMetadataResult, error) {
+		c.Assert(filepath.IsAbs(filename), jc.IsTrue)
+		c.Assert(filepath.Base(filename), gc.Equals, "backup.tar.gz")
+		return archive, meta, nil
+	})
+	return archive, meta
+}
+
+func (s *BootstrapSuite) TestBootstrapRestore(c *gc.C) {
+	s.patchVersionAndSeries(c, "raring")
+	archive, meta := s.patchBackupArchive(c)
+
+	var restored bool
+	s.PatchValue(&restoreBackup, func(
+		_ *cmd.Context,
+		_ *modelcmd.ModelCommandBase,
+		controllerName, hostedModelName string,
+		restoreArchive io.ReadSeeker,
+		restoreMeta *params.BackupsMetadataResult,
+	) error {
+		restored = true
+		c.Assert(controllerName, gc.Equals, "devcontroller")
+		c.Assert(hostedModelName, gc.Equals, "default")
+		c.Assert(restoreArchive, gc.Equals, archive)
+		c.Assert(restoreMeta, gc.Equals, meta)
+		return nil
+	})
+
+	_, err := coretesting.RunCommand(
+		c, s.newBootstrapCommand(), "devcontroller", "dummy", "--auto-upgrade",
+		"--restore", "backup.tar.gz", "--config", "admin-secret=s3cr3t",
+	)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(restored, jc.IsTrue)
+
+	details, err := s.store.ControllerByName("devcontroller")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(details.CACert, gc.Equals, coretesting.CACert)
+	account, err := s.store.AccountDetails("devcontroller")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(account.Password, gc.Equals, "s3cr3t")
+}
+
+func (s *BootstrapSuite) TestBootstrapRestoreRequiresAdminSecret(c *gc.C) {
+	s.patchVersionAndSeries(c, "raring")
+	s.patchBackupArchive(c)
+	s.PatchValue(&restoreBackup, func(
+		*cmd.Context, *modelcmd.ModelCommandBase, string, string,
+		io.ReadSeeker, *params.BackupsMetadataResult,
+	) error {
+		c.Fatalf("unexpected restore")
+		return nil
+	})
+
+	_, err := coretesting.RunCommand(
+		c, s.newBootstrapCommand(), "devcontroller", "dummy", "--auto-upgrade",
+		"--restore", "backup.tar.gz",
+	)
+	c.Assert(err, gc.ErrorMatches, `--restore requires the admin password of the backed up controller; specify it with --config admin-secret=<password>`)
+}
+
 func (s *BootstrapSuite) TestBootstrapDefaultConfigStripsProcessedAttributes(c *gc.C) {
 	s.patchVersionAndSeries(c, "raring")
 
@@ -1036,6 +1246,95 @@ func (s *BootstrapSuite) TestBootstrapKeepBroken(c *gc.C) {
 	c.Assert(stderr, gc.Matches, `.*See .*juju kill\-controller.*`)
 }
 
+// failBootstrapKeepBroken runs a bootstrap that fails with --keep-broken,
+// and returns the arguments it was called with.
+func (s *BootstrapSuite) failBootstrapKeepBroken(c *gc.C, fake *fakeBootstrapFuncs) bootstrap.BootstrapParams {
+	fake.err = errors.New("finalizer failed")
+	_, err := coretesting.RunCommand(
+		c, s.newBootstrapCommand(), "devcontroller", "dummy", "--auto-upgrade", "--keep-broken",
+	)
+	c.Assert(err, gc.ErrorMatches, "failed to bootstrap model: finalizer failed")
+	fake.err = nil
+	return fake.args
+}
+
+func (s *BootstrapSuite) TestBootstrapResume(c *gc.C) {
+	s.patchVersionAndSeries(c, "raring")
+
+	var bootstrap fakeBootstrapFuncs
+	s.PatchValue(&getBootstrapFuncs, func() BootstrapInterface {
+		return &bootstrap
+	})
+	failed := s.failBootstrapKeepBroken(c, &bootstrap)
+	c.Assert(failed.Resume, jc.IsFalse)
+
+	// The controller's details are kept, and the failure recorded,
+	// but the CA private key is not.
+	details, err := s.store.ControllerByName("devcontroller")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(details.ControllerUUID, gc.Equals, failed.ControllerConfig.ControllerUUID())
+	c.Assert(s.store.BootstrapConfig["devcontroller"].BootstrapFailed, jc.IsTrue)
+
+	_, err = coretesting.RunCommand(
+		c, s.newBootstrapCommand(), "devcontroller", "dummy", "--auto-upgrade", "--resume",
+	)
+	c.Assert(err, jc.ErrorIsNil)
+	resumed := bootstrap.args
+	c.Assert(resumed.Resume, jc.IsTrue)
+	c.Assert(resumed.ControllerConfig.ControllerUUID(), gc.Equals, failed.ControllerConfig.ControllerUUID())
+	c.Assert(resumed.AdminSecret, gc.Equals, failed.AdminSecret)
+	c.Assert(resumed.HostedModelConfig[config.UUIDKey], gc.Equals, failed.HostedModelConfig[config.UUIDKey])
+
+	// The bootstrap instance was not configured by the failed
+	// bootstrap, so a new CA is used.
+	c.Assert(resumed.CAPrivateKey, gc.Not(gc.Equals), failed.CAPrivateKey)
+	resumedCACert, _ := resumed.ControllerConfig.CACert()
+	details, err = s.store.ControllerByName("devcontroller")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(details.CACert, gc.Equals, resumedCACert)
+	c.Assert(s.store.BootstrapConfig["devcontroller"].BootstrapFailed, jc.IsFalse)
+}
+
+func (s *BootstrapSuite) TestBootstrapResumeKeptAgentConfig(c *gc.C) {
+	s.patchVersionAndSeries(c, "raring")
+
+	var bootstrap fakeBootstrapFuncs
+	s.PatchValue(&getBootstrapFuncs, func() BootstrapInterface {
+		return &bootstrap
+	})
+	failed := s.failBootstrapKeepBroken(c, &bootstrap)
+	failedCACert, _ := failed.ControllerConfig.CACert()
+
+	// The bootstrap instance keeps the configuration given to
+	// it by the failed bootstrap, so the controller's original
+	// CA certificate is restored.
+	bootstrap.keptAgentConfig = true
+	_, err := coretesting.RunCommand(
+		c, s.newBootstrapCommand(), "devcontroller", "dummy", "--auto-upgrade", "--resume",
+	)
+	c.Assert(err, jc.ErrorIsNil)
+	resumedCACert, _ := bootstrap.args.ControllerConfig.CACert()
+	c.Assert(resumedCACert, gc.Not(gc.Equals), failedCACert)
+	details, err := s.store.ControllerByName("devcontroller")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(details.CACert, gc.Equals, failedCACert)
+}
+
+func (s *BootstrapSuite) TestBootstrapResumeUnknownController(c *gc.C) {
+	s.patchVersionAndSeries(c, "raring")
+	_, err := coretesting.RunCommand(
+		c, s.newBootstrapCommand(), "devcontroller", "dummy", "--auto-upgrade", "--resume",
+	)
+	c.Assert(err, gc.ErrorMatches, `cannot resume bootstrap: controller "devcontroller" not found; only a bootstrap that failed with --keep-broken can be resumed`)
+}
+
+func (s *BootstrapSuite) TestBootstrapResumeWithRestore(c *gc.C) {
+	_, err := coretesting.RunCommand(
+		c, s.newBootstrapCommand(), "devcontroller", "dummy", "--resume", "--restore", "backup.tar.gz",
+	)
+	c.Assert(err, gc.ErrorMatches, "--resume and --restore can't be used together")
+}
+
 func (s *BootstrapSuite) TestBootstrapUnknownCloudOrProvider(c *gc.C) {
 	s.patchVersionAndSeries(c, "raring")
 	_, err := coretesting.RunCommand(c, s.newBootstrapCommand(), "ctrl", "no-such-provider")
@@ -1090,6 +1389,40 @@ func (s *BootstrapSuite) TestBootstrapProviderManyDetectedCredentials(c *gc.C) {
 	c.Assert(err, gc.ErrorMatches, ambiguousDetectedCredentialError.Error())
 }
 
+func (s *BootstrapSuite) TestBootstrapProviderRequiredConfigMissing(c *gc.C) {
+	s.patchVersionAndSeries(c, "raring")
+	_, err := coretesting.RunCommand(
+		c, s.newBootstrapCommand(), "ctrl", "required-config",
+		"--config", "private-cloud=true",
+	)
+	c.Assert(err, gc.ErrorMatches, `
+missing required config for "required-config" cloud:
+    agent-metadata-url: the agent binary metadata URL
+specify these with --config, or in the cloud's config in clouds.yaml`[1:])
+}
+
+func (s *BootstrapSuite) TestBootstrapProviderRequiredConfig(c *gc.C) {
+	var bootstrap fakeBootstrapFuncs
+	s.PatchValue(&getBootstrapFuncs, func() BootstrapInterface {
+		return &bootstrap
+	})
+	s.patchVersionAndSeries(c, "raring")
+
+	// The attributes are only required in combination.
+	_, err := coretesting.RunCommand(c, s.newBootstrapCommand(), "ctrl", "required-config")
+	c.Assert(err, jc.ErrorIsNil)
+
+	_, err = coretesting.RunCommand(
+		c, s.newBootstrapCommand(), "ctrl2", "required-config",
+		"--config", "private-cloud=true",
+		"--config", "agent-metadata-url=https://mirror.example.com/tools",
+	)
+	c.Assert(err, jc.ErrorIsNil)
+	url, ok := bootstrap.env.Config().AgentMetadataURL()
+	c.Assert(ok, jc.IsTrue)
+	c.Assert(url, gc.Equals, "https://mirror.example.com/tools")
+}
+
 func (s *BootstrapSuite) TestBootstrapProviderDetectRegionsInvalid(c *gc.C) {
 	s.patchVersionAndSeries(c, "raring")
 	ctx, err := coretesting.RunCommand(c, s.newBootstrapCommand(), "ctrl", "dummy/not-dummy")
@@ -1256,6 +1589,51 @@ func (s *BootstrapSuite) TestBootstrapConfigFileAndAdHoc(c *gc.C) {
 	c.Assert(err, jc.ErrorIsNil)
 }
 
+func (s *BootstrapSuite) TestBootstrapModelDefaults(c *gc.C) {
+	tmpdir := c.MkDir()
+	configFile := filepath.Join(tmpdir, "defaults.yaml")
+	err := ioutil.WriteFile(configFile, []byte("image-stream: daily\nftp-proxy: foo\n"), 0644)
+	c.Assert(err, jc.ErrorIsNil)
+
+	var seenControllerName string
+	var seenDefaults map[string]interface{}
+	s.PatchValue(&runPostBootstrap, func(
+		_ *cmd.Context, _ *modelcmd.ModelCommandBase,
+		controllerName string, modelDefaults map[string]interface{},
+	) error {
+		seenControllerName = controllerName
+		seenDefaults = modelDefaults
+		return nil
+	})
+
+	s.patchVersionAndSeries(c, "raring")
+	_, err = coretesting.RunCommand(
+		c, s.newBootstrapCommand(), "ctrl", "dummy",
+		"--auto-upgrade",
+		"--model-default", configFile,
+		"--model-default", "ftp-proxy=bar",
+	)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(seenControllerName, gc.Equals, "ctrl")
+	c.Assert(seenDefaults, jc.DeepEquals, map[string]interface{}{
+		"image-stream": "daily",
+		"ftp-proxy":    "bar",
+	})
+}
+
+func (s *BootstrapSuite) TestBootstrapPostBootstrapError(c *gc.C) {
+	s.PatchValue(&runPostBootstrap, func(*cmd.Context, *modelcmd.ModelCommandBase, string, map[string]interface{}) error {
+		return errors.New("setting model defaults: boom")
+	})
+	s.patchVersionAndSeries(c, "raring")
+	_, err := coretesting.RunCommand(
+		c, s.newBootstrapCommand(), "ctrl", "dummy",
+		"--auto-upgrade",
+		"--model-default", "ftp-proxy=bar",
+	)
+	c.Assert(err, gc.ErrorMatches, "setting model defaults: boom")
+}
+
 func (s *BootstrapSuite) TestBootstrapAutocertDNSNameBadPort(c *gc.C) {
 	s.patchVersionAndSeries(c, "raring")
 	_, err := coretesting.RunCommand(
@@ -1297,6 +1675,73 @@ func (s *BootstrapSuite) TestBootstrapCloudConfigAndAdHoc(c *gc.C) {
 	c.Assert(err, gc.ErrorMatches, `invalid attribute value\(s\) for dummy cloud: controller: expected bool, got .*`)
 }
 
+func (s *BootstrapSuite) TestBootstrapCloudAndRegionConfig(c *gc.C) {
+	resetJujuXDGDataHome(c)
+	err := ioutil.WriteFile(cloud.JujuPersonalCloudsPath(), []byte(`
+clouds:
+    private-cloud:
+        type: dummy
+        config:
+            default-series: trusty
+            image-stream: daily
+        regions:
+            region-1:
+            region-2:
+        region-config:
+            region-2:
+                image-stream: devel
+`[1:]), 0644)
+	c.Assert(err, jc.ErrorIsNil)
+
+	var bootstrap fakeBootstrapFuncs
+	s.PatchValue(&getBootstrapFuncs, func() BootstrapInterface {
+		return &bootstrap
+	})
+	s.patchVersionAndSeries(c, "raring")
+
+	_, err = coretesting.RunCommand(
+		c, s.newBootstrapCommand(), "ctrl", "private-cloud/region-2", "--auto-upgrade",
+	)
+	c.Assert(err, jc.ErrorIsNil)
+
+	// The region's config takes precedence over the cloud's, for
+	// both the controller and the hosted model.
+	cfg := bootstrap.env.Config()
+	c.Assert(cfg.DefaultSeries(), gc.Equals, "trusty")
+	c.Assert(cfg.ImageStream(), gc.Equals, "devel")
+	c.Assert(bootstrap.args.HostedModelConfig["default-series"], gc.Equals, "trusty")
+	c.Assert(bootstrap.args.HostedModelConfig["image-stream"], gc.Equals, "devel")
+}
+
+func (s *BootstrapSuite) TestBootstrapRegionConfigOverriddenByAdHoc(c *gc.C) {
+	resetJujuXDGDataHome(c)
+	err := ioutil.WriteFile(cloud.JujuPersonalCloudsPath(), []byte(`
+clouds:
+    private-cloud:
+        type: dummy
+        regions:
+            region-1:
+        region-config:
+            region-1:
+                image-stream: devel
+`[1:]), 0644)
+	c.Assert(err, jc.ErrorIsNil)
+
+	var bootstrap fakeBootstrapFuncs
+	s.PatchValue(&getBootstrapFuncs, func() BootstrapInterface {
+		return &bootstrap
+	})
+	s.patchVersionAndSeries(c, "raring")
+
+	_, err = coretesting.RunCommand(
+		c, s.newBootstrapCommand(), "ctrl", "private-cloud/region-1", "--auto-upgrade",
+		"--config", "image-stream=released",
+	)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(bootstrap.env.Config().ImageStream(), gc.Equals, "released")
+	c.Assert(bootstrap.args.HostedModelConfig["image-stream"], gc.Equals, "released")
+}
+
 func (s *BootstrapSuite) TestBootstrapPrintClouds(c *gc.C) {
 	resetJujuXDGDataHome(c)
 	s.store.Credentials = map[string]cloud.CloudCredential{
@@ -1542,13 +1987,20 @@ func joinBinaryVersions(versions ...[]version.Binary) []version.Binary {
 // test scenarios. This could help improve some of the tests in this
 // file which execute large amounts of external functionality.
 type fakeBootstrapFuncs struct {
+	env                 environs.Environ
 	args                bootstrap.BootstrapParams
+	err                 error
+	keptAgentConfig     bool
 	cloudRegionDetector environs.CloudRegionDetector
 }
 
 func (fake *fakeBootstrapFuncs) Bootstrap(ctx environs.BootstrapContext, env environs.Environ, args bootstrap.BootstrapParams) error {
+	fake.env = env
 	fake.args = args
-	return nil
+	if fake.keptAgentConfig && args.KeptAgentConfig != nil {
+		args.KeptAgentConfig()
+	}
+	return fake.err
 }
 
 func (fake *fakeBootstrapFuncs) CloudRegionDetector(environs.EnvironProvider) (environs.CloudRegionDetector, bool) {
@@ -1614,6 +2066,32 @@ func (manyCredentialsProvider) CredentialSchemas() map[cloud.AuthType]cloud.Cred
 	return map[cloud.AuthType]cloud.CredentialSchema{"one": {}, "two": {}}
 }
 
+// requiredConfigProvider is a dummy provider that, like the Azure
+// provider in private-cloud mode, requires agent-metadata-url.
+type requiredConfigProvider struct {
+	environs.EnvironProvider
+}
+
+func (requiredConfigProvider) DetectRegions() ([]cloud.Region, error) {
+	return nil, errors.NotFoundf("regions")
+}
+
+func (requiredConfigProvider) CredentialSchemas() map[cloud.AuthType]cloud.CredentialSchema {
+	return nil
+}
+
+func (requiredConfigProvider) RequiredConfig(attrs map[string]interface{}) environschema.Fields {
+	if attrs["private-cloud"] != true {
+		return nil
+	}
+	return environschema.Fields{
+		"agent-metadata-url": {
+			Description: "the agent binary metadata URL",
+			Type:        environschema.Tstring,
+		},
+	}
+}
+
 type cloudRegionDetectorFunc func() ([]cloud.Region, error)
 
 func (c cloudRegionDetectorFunc) DetectRegions() ([]cloud.Region, error) {
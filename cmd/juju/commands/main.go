@@ -255,6 +255,7 @@ func registerCommands(r commandRegistry, ctx *cmd.Context) {
 	// Configuration commands.
 	r.Register(model.NewModelGetConstraintsCommand())
 	r.Register(model.NewModelSetConstraintsCommand())
+	r.Register(model.NewConstraintsVocabularyCommand())
 	r.Register(newSyncToolsCommand())
 	r.Register(newUpgradeJujuCommand(nil))
 	r.Register(application.NewUpgradeCharmCommand())
@@ -299,6 +300,9 @@ func registerCommands(r commandRegistry, ctx *cmd.Context) {
 	r.Register(machine.NewRemoveCommand())
 	r.Register(machine.NewListMachinesCommand())
 	r.Register(machine.NewShowMachineCommand())
+	r.Register(machine.NewSuspendCommand())
+	r.Register(machine.NewResumeCommand())
+	r.Register(machine.NewReplaceCommand())
 
 	// Manage model
 	r.Register(model.NewConfigCommand())
@@ -308,6 +312,7 @@ func registerCommands(r commandRegistry, ctx *cmd.Context) {
 	r.Register(model.NewGrantCommand())
 	r.Register(model.NewRevokeCommand())
 	r.Register(model.NewShowCommand())
+	r.Register(model.NewListProviderResourcesCommand())
 
 	if featureflag.Enabled(feature.Migration) {
 		r.Register(newMigrateCommand())
@@ -375,6 +380,8 @@ func registerCommands(r commandRegistry, ctx *cmd.Context) {
 	r.Register(controller.NewEnableDestroyControllerCommand())
 	r.Register(controller.NewShowControllerCommand())
 	r.Register(controller.NewGetConfigCommand())
+	r.Register(controller.NewRepairConsistencyCommand())
+	r.Register(controller.NewMigrateBlobBackendCommand())
 
 	// Debug Metrics
 	r.Register(metricsdebug.New())
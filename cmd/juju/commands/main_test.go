@@ -410,6 +410,7 @@ var commandNames = []string{
 	"clouds",
 	"config",
 	"collect-metrics",
+	"constraints",
 	"controllers",
 	"create-backup",
 	"create-budget",
@@ -451,6 +452,7 @@ var commandNames = []string{
 	"list-machines",
 	"list-models",
 	"list-plans",
+	"list-provider-resources",
 	"list-ssh-keys",
 	"list-spaces",
 	"list-storage",
@@ -461,6 +463,7 @@ var commandNames = []string{
 	"logout",
 	"machines",
 	"metrics",
+	"migrate-blob-backend",
 	"model-config",
 	"model-defaults",
 	"models",
@@ -476,8 +479,11 @@ var commandNames = []string{
 	"remove-relation",
 	"remove-ssh-key",
 	"remove-unit",
+	"repair-consistency",
+	"replace-machine",
 	"resolved",
 	"restore-backup",
+	"resume-machine",
 	"retry-provisioning",
 	"revoke",
 	"run",
@@ -509,6 +515,7 @@ var commandNames = []string{
 	"storage",
 	"storage-pools",
 	"subnets",
+	"suspend-machine",
 	"switch",
 	"sync-tools",
 	"unexpose",
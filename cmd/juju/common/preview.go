@@ -0,0 +1,33 @@
+// Copyright 2016 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package common
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/cmd/output"
+)
+
+// PrintInstanceSpecPreviews writes a tabular description of the
+// instances that the provider would start for machines.
+func PrintInstanceSpecPreviews(writer io.Writer, previews []*params.InstanceSpecPreview) error {
+	tw := output.TabWriter(writer)
+	fmt.Fprintln(tw, "Instance type\tImage\tArch\tCost\tZone")
+	for _, preview := range previews {
+		cost := "unknown"
+		if preview.Cost != 0 {
+			cost = fmt.Sprint(preview.Cost)
+		}
+		zone := preview.AvailabilityZone
+		if zone == "" {
+			zone = "-"
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n",
+			preview.InstanceType, preview.Image, preview.Arch, cost, zone,
+		)
+	}
+	return tw.Flush()
+}
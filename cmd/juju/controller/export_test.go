@@ -87,6 +87,26 @@ func NewEnableDestroyControllerCommandForTest(api removeBlocksAPI, store jujucli
 	return modelcmd.WrapController(c)
 }
 
+// NewRepairConsistencyCommandForTest returns a repairConsistencyCommand
+// with the API mocked out.
+func NewRepairConsistencyCommandForTest(api repairConsistencyAPI, store jujuclient.ClientStore) cmd.Command {
+	c := &repairConsistencyCommand{
+		api: api,
+	}
+	c.SetClientStore(store)
+	return modelcmd.WrapController(c)
+}
+
+// NewMigrateBlobBackendCommandForTest returns a migrateBlobBackendCommand
+// with the API mocked out.
+func NewMigrateBlobBackendCommandForTest(api migrateBlobBackendAPI, store jujuclient.ClientStore) cmd.Command {
+	c := &migrateBlobBackendCommand{
+		api: api,
+	}
+	c.SetClientStore(store)
+	return modelcmd.WrapController(c)
+}
+
 // NewDestroyCommandForTest returns a DestroyCommand with the controller and
 // client endpoints mocked out.
 func NewDestroyCommandForTest(
@@ -167,3 +187,5 @@ func FmtModelStatus(data ModelData) string {
 func NewData(api destroyControllerAPI, ctrUUID string) (ctrData, []modelData, error) {
 	return newData(api, ctrUUID)
 }
+
+var ReportRemainingResources = reportRemainingResources
@@ -16,6 +16,7 @@ import (
 	"github.com/juju/juju/cmd/modelcmd"
 	"github.com/juju/juju/environs"
 	"github.com/juju/juju/environs/config"
+	"github.com/juju/juju/jujuclient"
 )
 
 const killDoc = `
@@ -34,6 +35,9 @@ models do not stop cleanly, there is a default five minute timeout. If no change
 in the model state occurs for the duration of this timeout, the command will
 stop watching and destroy the models directly through the cloud provider.
 
+If the cloud provider is able to list the resources it created, any resources
+remaining once the controller has been destroyed are reported.
+
 See also:
     destroy-controller
     unregister
@@ -131,14 +135,14 @@ func (c *killCommand) Run(ctx *cmd.Context) error {
 	// the environs interface.
 	if api == nil {
 		ctx.Infof("Unable to connect to the API server, destroying through provider")
-		return environs.Destroy(controllerName, controllerEnviron, store)
+		return destroyEnviron(ctx, controllerName, controllerEnviron, store)
 	}
 
 	// Attempt to destroy the controller and all environments.
 	err = api.DestroyController(true)
 	if err != nil {
 		ctx.Infof("Unable to destroy controller through the API: %s\nDestroying through provider", err)
-		return environs.Destroy(controllerName, controllerEnviron, store)
+		return destroyEnviron(ctx, controllerName, controllerEnviron, store)
 	}
 
 	ctx.Infof("Destroying controller %q\nWaiting for resources to be reclaimed", controllerName)
@@ -147,7 +151,38 @@ func (c *killCommand) Run(ctx *cmd.Context) error {
 	if err := c.WaitForModels(ctx, api, uuid); err != nil {
 		c.DirectDestroyRemaining(ctx, api)
 	}
-	return environs.Destroy(controllerName, controllerEnviron, store)
+	return destroyEnviron(ctx, controllerName, controllerEnviron, store)
+}
+
+// destroyEnviron destroys the controller environ through the provider,
+// and then reports any resources that the provider says remain.
+func destroyEnviron(ctx *cmd.Context, controllerName string, env environs.Environ, store jujuclient.ControllerStore) error {
+	if err := environs.Destroy(controllerName, env, store); err != nil {
+		return err
+	}
+	reportRemainingResources(ctx, env)
+	return nil
+}
+
+// reportRemainingResources warns about any resources that remain
+// after the given environ has been destroyed. Providers that cannot
+// enumerate their resources are skipped; failure to enumerate is
+// logged, as the environ has already been destroyed.
+func reportRemainingResources(ctx *cmd.Context, env environs.Environ) {
+	resources, err := environs.RemainingResources(env)
+	if errors.IsNotSupported(err) {
+		return
+	} else if err != nil {
+		logger.Warningf("unable to verify that all resources were released: %v", err)
+		return
+	}
+	if len(resources) == 0 {
+		return
+	}
+	ctx.Infof("%d resource(s) remain after destroying %q, manual intervention may be necessary:", len(resources), env.Config().Name())
+	for _, resource := range resources {
+		ctx.Infof("  %s", resource)
+	}
 }
 
 // DirectDestroyRemaining will attempt to directly destroy any remaining
@@ -181,6 +216,7 @@ func (c *killCommand) DirectDestroyRemaining(ctx *cmd.Context, api destroyContro
 			hasErrors = true
 		} else {
 			ctx.Infof("  done")
+			reportRemainingResources(ctx, env)
 		}
 	}
 	if hasErrors {
@@ -22,6 +22,8 @@ import (
 	"github.com/juju/juju/cmd/juju/controller"
 	"github.com/juju/juju/cmd/modelcmd"
 	cmdtesting "github.com/juju/juju/cmd/testing"
+	"github.com/juju/juju/environs"
+	"github.com/juju/juju/environs/config"
 	"github.com/juju/juju/jujuclient"
 	_ "github.com/juju/juju/provider/dummy"
 	coretesting "github.com/juju/juju/testing"
@@ -510,3 +512,51 @@ func (s *KillSuite) TestFmtEnvironStatus(c *gc.C) {
 	out := controller.FmtModelStatus(data)
 	c.Assert(out, gc.Equals, "\towner@local/envname (dying), 8 machines, 1 application")
 }
+
+func (s *KillSuite) TestReportRemainingResources(c *gc.C) {
+	ctx := coretesting.Context(c)
+	controller.ReportRemainingResources(ctx, &resourceEnumeratorEnviron{
+		cfg: coretesting.ModelConfig(c),
+		resources: []environs.Resource{
+			{Type: "disk", Id: "disk-0"},
+			{Type: "vm", Id: "machine-0"},
+		},
+	})
+	c.Assert(coretesting.Stderr(ctx), gc.Equals, `
+2 resource(s) remain after destroying "testenv", manual intervention may be necessary:
+  disk disk-0
+  vm machine-0
+`[1:])
+}
+
+func (s *KillSuite) TestReportRemainingResourcesNone(c *gc.C) {
+	ctx := coretesting.Context(c)
+	controller.ReportRemainingResources(ctx, &resourceEnumeratorEnviron{
+		cfg: coretesting.ModelConfig(c),
+	})
+	c.Assert(coretesting.Stderr(ctx), gc.Equals, "")
+}
+
+func (s *KillSuite) TestReportRemainingResourcesError(c *gc.C) {
+	ctx := coretesting.Context(c)
+	controller.ReportRemainingResources(ctx, &resourceEnumeratorEnviron{
+		cfg: coretesting.ModelConfig(c),
+		err: errors.New("boom"),
+	})
+	c.Assert(coretesting.Stderr(ctx), gc.Equals, "")
+}
+
+type resourceEnumeratorEnviron struct {
+	environs.Environ
+	cfg       *config.Config
+	resources []environs.Resource
+	err       error
+}
+
+func (e *resourceEnumeratorEnviron) Config() *config.Config {
+	return e.cfg
+}
+
+func (e *resourceEnumeratorEnviron) AllResources() ([]environs.Resource, error) {
+	return e.resources, e.err
+}
@@ -0,0 +1,107 @@
+// Copyright 2016 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package controller
+
+import (
+	"github.com/juju/cmd"
+	"github.com/juju/errors"
+	"github.com/juju/utils/keyvalues"
+
+	"github.com/juju/juju/cmd/modelcmd"
+)
+
+// NewMigrateBlobBackendCommand returns a command that moves the
+// content of the controller's blobs to another backend.
+func NewMigrateBlobBackendCommand() cmd.Command {
+	return modelcmd.WrapController(&migrateBlobBackendCommand{})
+}
+
+type migrateBlobBackendCommand struct {
+	modelcmd.ControllerCommandBase
+	api     migrateBlobBackendAPI
+	backend map[string]string
+}
+
+type migrateBlobBackendAPI interface {
+	Close() error
+	MigrateBlobBackend(backend map[string]string) error
+}
+
+var migrateBlobBackendDoc = `
+The controller stores the content of charms, resources and other blobs
+in a backend chosen with the "blob-backend" controller config attribute
+at bootstrap. By default, blobs are stored in MongoDB's GridFS.
+
+migrate-blob-backend starts moving the content of every blob to the
+given backend, which is described by key=value attributes. The "type"
+attribute is required, and is one of:
+
+    gridfs       MongoDB GridFS; no other attributes.
+    filesystem   A directory on the controller machine:
+                 directory.
+    s3           An Amazon S3 bucket:
+                 bucket, region, access-key, secret-key.
+    azure        An existing Azure Blob Storage container:
+                 account-name, account-key, container.
+
+New blobs are stored in the new backend as soon as the command
+completes. The controller then moves existing blobs in the background,
+resuming after a restart if need be, and remains usable while they are
+moved: blobs not yet moved are read from the old backend. Another
+migration cannot be started until the blobs have been moved.
+
+Only controller administrators may run this command.
+
+Examples:
+
+    juju migrate-blob-backend type=filesystem directory=/var/lib/juju/blobs
+    juju migrate-blob-backend type=gridfs
+`
+
+// Info implements Command.Info.
+func (c *migrateBlobBackendCommand) Info() *cmd.Info {
+	return &cmd.Info{
+		Name:    "migrate-blob-backend",
+		Args:    "type=<type> [<key>=<value> ...]",
+		Purpose: "Moves the controller's blobs to another storage backend.",
+		Doc:     migrateBlobBackendDoc,
+	}
+}
+
+// Init implements Command.Init.
+func (c *migrateBlobBackendCommand) Init(args []string) error {
+	if len(args) == 0 {
+		return errors.New("no backend specified")
+	}
+	backend, err := keyvalues.Parse(args, false)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	if backend["type"] == "" {
+		return errors.New("backend type not specified")
+	}
+	c.backend = backend
+	return nil
+}
+
+func (c *migrateBlobBackendCommand) getAPI() (migrateBlobBackendAPI, error) {
+	if c.api != nil {
+		return c.api, nil
+	}
+	return c.NewControllerAPIClient()
+}
+
+// Run implements Command.Run.
+func (c *migrateBlobBackendCommand) Run(ctx *cmd.Context) error {
+	client, err := c.getAPI()
+	if err != nil {
+		return errors.Trace(err)
+	}
+	defer client.Close()
+	if err := client.MigrateBlobBackend(c.backend); err != nil {
+		return errors.Trace(err)
+	}
+	ctx.Infof("Moving blobs to the %s backend", c.backend["type"])
+	return nil
+}
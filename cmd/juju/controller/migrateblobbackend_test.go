@@ -0,0 +1,79 @@
+// Copyright 2016 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package controller_test
+
+import (
+	"github.com/juju/cmd"
+	"github.com/juju/errors"
+	gitjujutesting "github.com/juju/testing"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/cmd/juju/controller"
+	"github.com/juju/juju/jujuclient"
+	"github.com/juju/juju/jujuclient/jujuclienttesting"
+	"github.com/juju/juju/testing"
+)
+
+type migrateBlobBackendSuite struct {
+	baseControllerSuite
+	api   *fakeMigrateBlobBackendAPI
+	store *jujuclienttesting.MemStore
+}
+
+var _ = gc.Suite(&migrateBlobBackendSuite{})
+
+func (s *migrateBlobBackendSuite) SetUpTest(c *gc.C) {
+	s.baseControllerSuite.SetUpTest(c)
+	s.api = &fakeMigrateBlobBackendAPI{}
+	s.store = jujuclienttesting.NewMemStore()
+	s.store.CurrentControllerName = "fake"
+	s.store.Controllers["fake"] = jujuclient.ControllerDetails{}
+}
+
+func (s *migrateBlobBackendSuite) run(c *gc.C, args ...string) (*cmd.Context, error) {
+	command := controller.NewMigrateBlobBackendCommandForTest(s.api, s.store)
+	return testing.RunCommand(c, command, args...)
+}
+
+func (s *migrateBlobBackendSuite) TestMigrate(c *gc.C) {
+	ctx, err := s.run(c, "type=filesystem", "directory=/var/lib/juju/blobs")
+	c.Assert(err, jc.ErrorIsNil)
+	s.api.CheckCall(c, 0, "MigrateBlobBackend", map[string]string{
+		"type":      "filesystem",
+		"directory": "/var/lib/juju/blobs",
+	})
+	c.Assert(testing.Stderr(ctx), gc.Equals, "Moving blobs to the filesystem backend\n")
+}
+
+func (s *migrateBlobBackendSuite) TestNoArgs(c *gc.C) {
+	_, err := s.run(c)
+	c.Assert(err, gc.ErrorMatches, "no backend specified")
+	s.api.CheckNoCalls(c)
+}
+
+func (s *migrateBlobBackendSuite) TestNoType(c *gc.C) {
+	_, err := s.run(c, "directory=/var/lib/juju/blobs")
+	c.Assert(err, gc.ErrorMatches, "backend type not specified")
+	s.api.CheckNoCalls(c)
+}
+
+func (s *migrateBlobBackendSuite) TestAPIError(c *gc.C) {
+	s.api.SetErrors(errors.New("permission denied"))
+	_, err := s.run(c, "type=gridfs")
+	c.Assert(err, gc.ErrorMatches, "permission denied")
+}
+
+type fakeMigrateBlobBackendAPI struct {
+	gitjujutesting.Stub
+}
+
+func (f *fakeMigrateBlobBackendAPI) Close() error {
+	return nil
+}
+
+func (f *fakeMigrateBlobBackendAPI) MigrateBlobBackend(backend map[string]string) error {
+	f.MethodCall(f, "MigrateBlobBackend", backend)
+	return f.NextErr()
+}
@@ -0,0 +1,160 @@
+// Copyright 2016 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package controller
+
+import (
+	"fmt"
+
+	"github.com/juju/cmd"
+	"github.com/juju/errors"
+	"github.com/juju/gnuflag"
+	"gopkg.in/juju/names.v2"
+
+	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/cmd/modelcmd"
+	"github.com/juju/juju/cmd/output"
+)
+
+// NewRepairConsistencyCommand returns a command that checks and repairs
+// the integrity of the models in the controller.
+func NewRepairConsistencyCommand() cmd.Command {
+	return modelcmd.WrapController(&repairConsistencyCommand{})
+}
+
+type repairConsistencyCommand struct {
+	modelcmd.ControllerCommandBase
+	api    repairConsistencyAPI
+	dryRun bool
+}
+
+type repairConsistencyAPI interface {
+	Close() error
+	CheckIntegrity() ([]params.IntegrityReport, error)
+	RepairIntegrity(model names.ModelTag, problemIDs ...string) ([]error, error)
+}
+
+var repairConsistencyDoc = `
+Juju's records of a model refer to one another: units to their
+applications and machines, relations to their applications, and so on.
+Each application also records the number of its units and of the
+relations it takes part in. If these records no longer agree, for
+example after the controller crashed, operations on the model may fail
+repeatedly, often with "state changing too quickly".
+
+repair-consistency checks the records of every model in the controller,
+lists the problems found, and repairs those that can be repaired
+automatically. Problems are repaired only if the records involved have
+not changed since they were checked. With --dry-run, the problems are
+listed but not repaired.
+
+Only controller administrators may run this command.
+
+Examples:
+
+    juju repair-consistency --dry-run
+    juju repair-consistency
+`
+
+// Info implements Command.Info.
+func (c *repairConsistencyCommand) Info() *cmd.Info {
+	return &cmd.Info{
+		Name:    "repair-consistency",
+		Purpose: "Checks and repairs the integrity of the models in the controller.",
+		Doc:     repairConsistencyDoc,
+	}
+}
+
+// SetFlags implements Command.SetFlags.
+func (c *repairConsistencyCommand) SetFlags(f *gnuflag.FlagSet) {
+	c.ControllerCommandBase.SetFlags(f)
+	f.BoolVar(&c.dryRun, "dry-run", false, "Report problems without repairing them")
+}
+
+func (c *repairConsistencyCommand) getAPI() (repairConsistencyAPI, error) {
+	if c.api != nil {
+		return c.api, nil
+	}
+	return c.NewControllerAPIClient()
+}
+
+// Run implements Command.Run.
+func (c *repairConsistencyCommand) Run(ctx *cmd.Context) error {
+	client, err := c.getAPI()
+	if err != nil {
+		return errors.Trace(err)
+	}
+	defer client.Close()
+	reports, err := client.CheckIntegrity()
+	if err != nil {
+		return errors.Trace(err)
+	}
+
+	var failed bool
+	var count int
+	repairable := make(map[string][]string)
+	tw := output.TabWriter(ctx.Stdout)
+	w := output.Wrapper{tw}
+	for _, report := range reports {
+		modelUUID := report.ModelTag
+		if tag, err := names.ParseModelTag(report.ModelTag); err == nil {
+			modelUUID = tag.Id()
+		}
+		if report.Error != nil {
+			fmt.Fprintf(ctx.Stderr, "failed to check model %s: %v\n", modelUUID, report.Error)
+			failed = true
+			continue
+		}
+		for _, problem := range report.Problems {
+			if count == 0 {
+				w.Println("MODEL", "PROBLEM", "REPAIR", "DESCRIPTION")
+			}
+			repair := "manual"
+			if len(problem.Repair) > 0 {
+				repair = "auto"
+				repairable[report.ModelTag] = append(repairable[report.ModelTag], problem.ID)
+			}
+			w.Println(modelUUID, problem.ID, repair, problem.Description)
+			count++
+		}
+	}
+	tw.Flush()
+
+	switch {
+	case count == 0:
+		ctx.Infof("No problems found")
+	case c.dryRun:
+		ctx.Infof("Found %d problems; run without --dry-run to repair them", count)
+	default:
+		var repaired int
+		for _, report := range reports {
+			ids := repairable[report.ModelTag]
+			if len(ids) == 0 {
+				continue
+			}
+			tag, err := names.ParseModelTag(report.ModelTag)
+			if err != nil {
+				return errors.Trace(err)
+			}
+			errs, err := client.RepairIntegrity(tag, ids...)
+			if err != nil {
+				fmt.Fprintf(ctx.Stderr, "failed to repair model %s: %v\n", tag.Id(), err)
+				failed = true
+				continue
+			}
+			for i, err := range errs {
+				if err != nil {
+					fmt.Fprintf(ctx.Stderr, "failed to repair %s in model %s: %v\n", ids[i], tag.Id(), err)
+					failed = true
+					continue
+				}
+				repaired++
+			}
+		}
+		ctx.Infof("Repaired %d of %d problems", repaired, count)
+	}
+	if failed {
+		return cmd.ErrSilent
+	}
+	return nil
+}
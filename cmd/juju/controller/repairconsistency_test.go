@@ -0,0 +1,157 @@
+// Copyright 2016 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package controller_test
+
+import (
+	"github.com/juju/cmd"
+	"github.com/juju/errors"
+	gitjujutesting "github.com/juju/testing"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+	"gopkg.in/juju/names.v2"
+
+	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/cmd/juju/controller"
+	"github.com/juju/juju/jujuclient"
+	"github.com/juju/juju/jujuclient/jujuclienttesting"
+	"github.com/juju/juju/testing"
+)
+
+type repairConsistencySuite struct {
+	baseControllerSuite
+	api   *fakeRepairConsistencyAPI
+	store *jujuclienttesting.MemStore
+}
+
+var _ = gc.Suite(&repairConsistencySuite{})
+
+func (s *repairConsistencySuite) SetUpTest(c *gc.C) {
+	s.baseControllerSuite.SetUpTest(c)
+	s.api = &fakeRepairConsistencyAPI{
+		reports: []params.IntegrityReport{{
+			ModelTag: "model-deadbeef-0bad-400d-8000-4b1d0d06f00d",
+			Problems: []params.IntegrityProblem{{
+				ID:          "count-mismatch:applications/wordpress",
+				Kind:        "count-mismatch",
+				Collection:  "applications",
+				DocID:       "wordpress",
+				Description: `application "wordpress" records 3 units and 0 relations, but has 1 units and 0 relations`,
+				Repair: []params.IntegrityRepairOp{{
+					Collection: "applications",
+					Id:         "deadbeef-0bad-400d-8000-4b1d0d06f00d:wordpress",
+				}},
+			}, {
+				ID:          "dangling-reference:units/wordpress/0:principal",
+				Kind:        "dangling-reference",
+				Collection:  "units",
+				DocID:       "wordpress/0",
+				Description: `subordinate unit "wordpress/0" refers to missing principal "ghost/0"`,
+			}},
+		}, {
+			ModelTag: "model-c0ffee00-0bad-400d-8000-4b1d0d06f00d",
+		}},
+	}
+	s.store = jujuclienttesting.NewMemStore()
+	s.store.CurrentControllerName = "fake"
+	s.store.Controllers["fake"] = jujuclient.ControllerDetails{}
+}
+
+func (s *repairConsistencySuite) run(c *gc.C, args ...string) (*cmd.Context, error) {
+	command := controller.NewRepairConsistencyCommandForTest(s.api, s.store)
+	return testing.RunCommand(c, command, args...)
+}
+
+func (s *repairConsistencySuite) TestRepair(c *gc.C) {
+	ctx, err := s.run(c)
+	c.Assert(err, jc.ErrorIsNil)
+	s.api.CheckCalls(c, []gitjujutesting.StubCall{
+		{"CheckIntegrity", nil},
+		{"RepairIntegrity", []interface{}{
+			names.NewModelTag("deadbeef-0bad-400d-8000-4b1d0d06f00d"),
+			[]string{"count-mismatch:applications/wordpress"},
+		}},
+	})
+	c.Assert(testing.Stdout(ctx), gc.Equals, `
+MODEL                                 PROBLEM                                         REPAIR  DESCRIPTION
+deadbeef-0bad-400d-8000-4b1d0d06f00d  count-mismatch:applications/wordpress           auto    application "wordpress" records 3 units and 0 relations, but has 1 units and 0 relations
+deadbeef-0bad-400d-8000-4b1d0d06f00d  dangling-reference:units/wordpress/0:principal  manual  subordinate unit "wordpress/0" refers to missing principal "ghost/0"
+`[1:])
+	c.Assert(testing.Stderr(ctx), gc.Equals, "Repaired 1 of 2 problems\n")
+}
+
+func (s *repairConsistencySuite) TestRepairError(c *gc.C) {
+	s.api.repairErrors = []error{errors.New("boom")}
+	ctx, err := s.run(c)
+	c.Assert(err, gc.Equals, cmd.ErrSilent)
+	c.Assert(testing.Stderr(ctx), gc.Equals, `
+failed to repair count-mismatch:applications/wordpress in model deadbeef-0bad-400d-8000-4b1d0d06f00d: boom
+Repaired 0 of 2 problems
+`[1:])
+}
+
+func (s *repairConsistencySuite) TestDryRun(c *gc.C) {
+	ctx, err := s.run(c, "--dry-run")
+	c.Assert(err, jc.ErrorIsNil)
+	s.api.CheckCallNames(c, "CheckIntegrity")
+	c.Assert(testing.Stderr(ctx), gc.Equals, "Found 2 problems; run without --dry-run to repair them\n")
+}
+
+func (s *repairConsistencySuite) TestConsistent(c *gc.C) {
+	s.api.reports = s.api.reports[1:]
+	ctx, err := s.run(c)
+	c.Assert(err, jc.ErrorIsNil)
+	s.api.CheckCallNames(c, "CheckIntegrity")
+	c.Assert(testing.Stdout(ctx), gc.Equals, "")
+	c.Assert(testing.Stderr(ctx), gc.Equals, "No problems found\n")
+}
+
+func (s *repairConsistencySuite) TestModelError(c *gc.C) {
+	s.api.reports[1].Error = &params.Error{Message: "boom"}
+	ctx, err := s.run(c)
+	c.Assert(err, gc.Equals, cmd.ErrSilent)
+	c.Assert(testing.Stderr(ctx), gc.Equals, `
+failed to check model c0ffee00-0bad-400d-8000-4b1d0d06f00d: boom
+Repaired 1 of 2 problems
+`[1:])
+}
+
+func (s *repairConsistencySuite) TestAPIError(c *gc.C) {
+	s.api.SetErrors(errors.New("permission denied"))
+	_, err := s.run(c)
+	c.Assert(err, gc.ErrorMatches, "permission denied")
+}
+
+func (s *repairConsistencySuite) TestUnrecognizedArg(c *gc.C) {
+	_, err := s.run(c, "whoops")
+	c.Assert(err, gc.ErrorMatches, `unrecognized args: \["whoops"\]`)
+	s.api.CheckNoCalls(c)
+}
+
+type fakeRepairConsistencyAPI struct {
+	gitjujutesting.Stub
+	reports      []params.IntegrityReport
+	repairErrors []error
+}
+
+func (f *fakeRepairConsistencyAPI) Close() error {
+	return nil
+}
+
+func (f *fakeRepairConsistencyAPI) CheckIntegrity() ([]params.IntegrityReport, error) {
+	f.MethodCall(f, "CheckIntegrity")
+	if err := f.NextErr(); err != nil {
+		return nil, err
+	}
+	return f.reports, nil
+}
+
+func (f *fakeRepairConsistencyAPI) RepairIntegrity(model names.ModelTag, problemIDs ...string) ([]error, error) {
+	f.MethodCall(f, "RepairIntegrity", model, problemIDs)
+	if err := f.NextErr(); err != nil {
+		return nil, err
+	}
+	errs := make([]error, len(problemIDs))
+	copy(errs, f.repairErrors)
+	return errs, nil
+}
@@ -57,6 +57,12 @@ information about how to allocate the machine. For example, one can direct the
 MAAS provider to acquire a particular node by specifying its hostname.
 For more information on placement directives, see "juju help placement".
 
+With "--preview", add machine reports the instance type, image, and
+availability zone that the provider would choose for the machine, given
+its constraints, series and placement, without adding any machines. Not
+all providers support previewing, and containers and manually provisioned
+machines cannot be previewed.
+
 Examples:
    juju add-machine                      (starts a new machine)
    juju add-machine -n 2                 (starts 2 new machines)
@@ -67,6 +73,8 @@ Examples:
    juju add-machine ssh:user@10.10.0.3   (manually provisions a machine with ssh)
    juju add-machine zone=us-east-1a      (start a machine in zone us-east-1a on AWS)
    juju add-machine maas2.name           (acquire machine maas2.name on MAAS)
+   juju add-machine --preview --constraints mem=8G
+                                         (shows the instance that would be started)
 
 See also:
     remove-machine
@@ -108,6 +116,9 @@ type addCommand struct {
 	NumMachines int
 	// Disks describes disks that are to be attached to the machine.
 	Disks []storage.Constraints
+	// Preview, if true, reports the instance that would be started
+	// for the machine, instead of adding it.
+	Preview bool
 }
 
 func (c *addCommand) Info() *cmd.Info {
@@ -125,6 +136,7 @@ func (c *addCommand) SetFlags(f *gnuflag.FlagSet) {
 	f.IntVar(&c.NumMachines, "n", 1, "The number of machines to add")
 	f.StringVar(&c.ConstraintsStr, "constraints", "", "Additional machine constraints")
 	f.Var(disksFlag{&c.Disks}, "disks", "Constraints for disks to attach to the machine")
+	f.BoolVar(&c.Preview, "preview", false, "Show the instance that would be started, without adding a machine")
 }
 
 func (c *addCommand) Init(args []string) error {
@@ -146,6 +158,14 @@ func (c *addCommand) Init(args []string) error {
 	if c.NumMachines > 1 && c.Placement != nil && c.Placement.Directive != "" {
 		return errors.New("cannot use -n when specifying a placement directive")
 	}
+	if c.Preview && c.Placement != nil {
+		if c.Placement.Scope == "ssh" {
+			return errors.New("cannot preview manually provisioned machines")
+		}
+		if _, err := instance.ParseContainerType(c.Placement.Scope); err == nil {
+			return errors.New("cannot preview containers")
+		}
+	}
 	return nil
 }
 
@@ -164,6 +184,7 @@ type ModelConfigAPI interface {
 
 type MachineManagerAPI interface {
 	AddMachines([]params.AddMachineParams) ([]params.AddMachinesResult, error)
+	PreviewInstanceSpecs([]params.PreviewInstanceSpec) ([]params.InstanceSpecPreviewResult, error)
 	BestAPIVersion() int
 	Close() error
 }
@@ -217,13 +238,13 @@ func (c *addCommand) Run(ctx *cmd.Context) error {
 	defer client.Close()
 
 	var machineManager MachineManagerAPI
-	if len(c.Disks) > 0 {
+	if len(c.Disks) > 0 || c.Preview {
 		machineManager, err = c.getMachineManagerAPI()
 		if err != nil {
 			return errors.Trace(err)
 		}
 		defer machineManager.Close()
-		if machineManager.BestAPIVersion() < 1 {
+		if len(c.Disks) > 0 && machineManager.BestAPIVersion() < 1 {
 			return errors.New("cannot add machines with disks: not supported by the API server")
 		}
 	}
@@ -282,6 +303,10 @@ func (c *addCommand) Run(ctx *cmd.Context) error {
 		return errors.Errorf("machine-id cannot be specified when adding machines")
 	}
 
+	if c.Preview {
+		return c.preview(ctx, machineManager)
+	}
+
 	jobs := []multiwatcher.MachineJob{multiwatcher.JobHostUnits}
 
 	machineParams := params.AddMachineParams{
@@ -338,3 +363,22 @@ func (c *addCommand) Run(ctx *cmd.Context) error {
 	}
 	return nil
 }
+
+// preview reports the instance that the provider would start for
+// the machine described by the command's arguments.
+func (c *addCommand) preview(ctx *cmd.Context, machineManager MachineManagerAPI) error {
+	results, err := machineManager.PreviewInstanceSpecs([]params.PreviewInstanceSpec{{
+		Series:      c.Series,
+		Constraints: c.Constraints,
+		Placement:   c.Placement,
+	}})
+	if err != nil {
+		return errors.Trace(err)
+	}
+	if results[0].Error != nil {
+		return errors.Annotate(results[0].Error, "cannot preview machine")
+	}
+	return common.PrintInstanceSpecPreviews(
+		ctx.Stdout, []*params.InstanceSpecPreview{results[0].Result},
+	)
+}
@@ -195,6 +195,38 @@ func (s *AddMachineSuite) TestAddMachineWithDisksUnsupported(c *gc.C) {
 	c.Assert(err, gc.ErrorMatches, "cannot add machines with disks: not supported by the API server")
 }
 
+func (s *AddMachineSuite) TestPreview(c *gc.C) {
+	context, err := s.run(c, "--preview", "--constraints", "mem=8G", "--series=special", "zone=nz")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(testing.Stdout(context), gc.Equals, `
+Instance type  Image     Arch   Cost  Zone
+m1.large       ami-0123  amd64  400   nz
+`[1:])
+	c.Assert(s.fakeAddMachine.args, gc.HasLen, 0)
+	c.Assert(s.fakeMachineManager.args, gc.HasLen, 0)
+	c.Assert(s.fakeMachineManager.previewArgs, gc.HasLen, 1)
+	param := s.fakeMachineManager.previewArgs[0]
+	c.Assert(param.Placement.String(), gc.Equals, "fake-uuid:zone=nz")
+	c.Assert(param.Series, gc.Equals, "special")
+	c.Assert(param.Constraints.String(), gc.Equals, "mem=8192M")
+}
+
+func (s *AddMachineSuite) TestPreviewError(c *gc.C) {
+	s.fakeMachineManager.previewError = &params.Error{
+		Message: "previewing instances in this model not supported",
+		Code:    params.CodeNotSupported,
+	}
+	_, err := s.run(c, "--preview")
+	c.Assert(err, gc.ErrorMatches, "cannot preview machine: previewing instances in this model not supported")
+}
+
+func (s *AddMachineSuite) TestPreviewInvalidPlacement(c *gc.C) {
+	_, err := s.run(c, "--preview", "lxd")
+	c.Assert(err, gc.ErrorMatches, "cannot preview containers")
+	_, err = s.run(c, "--preview", "ssh:10.1.2.3")
+	c.Assert(err, gc.ErrorMatches, "cannot preview manually provisioned machines")
+}
+
 type fakeAddMachineAPI struct {
 	successOrder []bool
 	currentOp    int
@@ -255,6 +287,27 @@ func (f *fakeAddMachineAPI) ModelGet() (map[string]interface{}, error) {
 type fakeMachineManagerAPI struct {
 	apiVersion int
 	fakeAddMachineAPI
+	previewArgs  []params.PreviewInstanceSpec
+	previewError *params.Error
+}
+
+func (f *fakeMachineManagerAPI) PreviewInstanceSpecs(args []params.PreviewInstanceSpec) ([]params.InstanceSpecPreviewResult, error) {
+	f.previewArgs = append(f.previewArgs, args...)
+	results := make([]params.InstanceSpecPreviewResult, len(args))
+	for i := range args {
+		if f.previewError != nil {
+			results[i].Error = f.previewError
+			continue
+		}
+		results[i].Result = &params.InstanceSpecPreview{
+			InstanceType:     "m1.large",
+			Image:            "ami-0123",
+			Arch:             "amd64",
+			Cost:             400,
+			AvailabilityZone: "nz",
+		}
+	}
+	return results, nil
 }
 
 func (f *fakeMachineManagerAPI) BestAPIVersion() int {
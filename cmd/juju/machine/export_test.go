@@ -52,6 +52,31 @@ func NewRemoveCommandForTest(api RemoveMachineAPI) (cmd.Command, *RemoveCommand)
 	return modelcmd.Wrap(cmd), &RemoveCommand{cmd}
 }
 
+type SuspendCommand struct {
+	*suspendCommand
+}
+
+// NewSuspendCommandForTest returns a SuspendCommand with the api provided as specified.
+func NewSuspendCommandForTest(api MachinePowerAPI) (cmd.Command, *SuspendCommand) {
+	cmd := &suspendCommand{machinePowerCommand{api: api}}
+	return modelcmd.Wrap(cmd), &SuspendCommand{cmd}
+}
+
+// NewResumeCommandForTest returns a resume-machine command with the api provided as specified.
+func NewResumeCommandForTest(api MachinePowerAPI) cmd.Command {
+	return modelcmd.Wrap(&resumeCommand{machinePowerCommand{api: api}})
+}
+
+type ReplaceCommand struct {
+	*replaceCommand
+}
+
+// NewReplaceCommandForTest returns a ReplaceCommand with the api provided as specified.
+func NewReplaceCommandForTest(api ReplaceMachineAPI) (cmd.Command, *ReplaceCommand) {
+	cmd := &replaceCommand{api: api}
+	return modelcmd.Wrap(cmd), &ReplaceCommand{cmd}
+}
+
 func NewDisksFlag(disks *[]storage.Constraints) *disksFlag {
 	return &disksFlag{disks}
 }
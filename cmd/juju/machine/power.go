@@ -0,0 +1,172 @@
+// Copyright 2016 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package machine
+
+import (
+	"fmt"
+
+	"github.com/juju/cmd"
+	"github.com/juju/errors"
+	"gopkg.in/juju/names.v2"
+
+	"github.com/juju/juju/api/machinepower"
+	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/cmd/juju/block"
+	"github.com/juju/juju/cmd/modelcmd"
+)
+
+// NewSuspendCommand returns a command used to suspend machines.
+func NewSuspendCommand() cmd.Command {
+	return modelcmd.Wrap(&suspendCommand{})
+}
+
+// NewResumeCommand returns a command used to resume suspended machines.
+func NewResumeCommand() cmd.Command {
+	return modelcmd.Wrap(&resumeCommand{})
+}
+
+// MachinePowerAPI defines the API methods used by the
+// suspend-machine and resume-machine commands.
+type MachinePowerAPI interface {
+	SuspendMachines(machineIds ...string) ([]params.ErrorResult, error)
+	ResumeMachines(machineIds ...string) ([]params.ErrorResult, error)
+	Close() error
+}
+
+// machinePowerCommand is the base type for the
+// suspend-machine and resume-machine commands.
+type machinePowerCommand struct {
+	modelcmd.ModelCommandBase
+	api        MachinePowerAPI
+	MachineIds []string
+}
+
+// Init implements Command.Init.
+func (c *machinePowerCommand) Init(args []string) error {
+	if len(args) == 0 {
+		return errors.Errorf("no machines specified")
+	}
+	for _, id := range args {
+		if !names.IsValidMachine(id) {
+			return errors.Errorf("invalid machine id %q", id)
+		}
+	}
+	c.MachineIds = args
+	return nil
+}
+
+func (c *machinePowerCommand) getAPI() (MachinePowerAPI, error) {
+	if c.api != nil {
+		return c.api, nil
+	}
+	root, err := c.NewAPIRoot()
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	return machinepower.NewClient(root), nil
+}
+
+// run calls the given API method, and reports any per-machine failures.
+func (c *machinePowerCommand) run(
+	ctx *cmd.Context,
+	call func(MachinePowerAPI, ...string) ([]params.ErrorResult, error),
+	verb string,
+) error {
+	client, err := c.getAPI()
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+	results, err := call(client, c.MachineIds...)
+	if err != nil {
+		return block.ProcessBlockedError(err, block.BlockChange)
+	}
+	var failed bool
+	for i, result := range results {
+		if result.Error != nil {
+			fmt.Fprintf(ctx.Stderr, "failed to %s machine %s: %v\n", verb, c.MachineIds[i], result.Error)
+			failed = true
+		}
+	}
+	if failed {
+		return cmd.ErrSilent
+	}
+	return nil
+}
+
+// suspendCommand stops the instances of machines without destroying them.
+type suspendCommand struct {
+	machinePowerCommand
+}
+
+const suspendMachineDoc = `
+Suspending a machine stops its instance without destroying it: the
+instance's disks are retained, and the machine can later be resumed
+with resume-machine. On clouds that charge only for running instances,
+such as Azure, suspending machines reduces the cost of models that are
+not always in use, e.g. development and test models.
+
+While a machine is suspended, its agents and units are not running.
+Controller machines and containers cannot be suspended. Not all clouds
+support suspending machines.
+
+Machines are specified by their numbers, which may be retrieved from the
+output of ` + "`juju status`." + `
+
+Examples:
+
+    juju suspend-machine 1 2
+
+See also:
+    resume-machine
+    remove-machine
+`
+
+// Info implements Command.Info.
+func (c *suspendCommand) Info() *cmd.Info {
+	return &cmd.Info{
+		Name:    "suspend-machine",
+		Args:    "<machine number> ...",
+		Purpose: "Stops machines without destroying them.",
+		Doc:     suspendMachineDoc,
+	}
+}
+
+// Run implements Command.Run.
+func (c *suspendCommand) Run(ctx *cmd.Context) error {
+	return c.run(ctx, MachinePowerAPI.SuspendMachines, "suspend")
+}
+
+// resumeCommand starts the instances of suspended machines.
+type resumeCommand struct {
+	machinePowerCommand
+}
+
+const resumeMachineDoc = `
+Resuming a machine starts its instance again after it has been suspended
+with suspend-machine. The machine's agents and units will start when the
+instance does. The machine's addresses may change while it is suspended.
+
+Examples:
+
+    juju resume-machine 1 2
+
+See also:
+    suspend-machine
+`
+
+// Info implements Command.Info.
+func (c *resumeCommand) Info() *cmd.Info {
+	return &cmd.Info{
+		Name:    "resume-machine",
+		Args:    "<machine number> ...",
+		Purpose: "Starts suspended machines.",
+		Doc:     resumeMachineDoc,
+	}
+}
+
+// Run implements Command.Run.
+func (c *resumeCommand) Run(ctx *cmd.Context) error {
+	return c.run(ctx, MachinePowerAPI.ResumeMachines, "resume")
+}
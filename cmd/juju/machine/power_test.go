@@ -0,0 +1,112 @@
+// Copyright 2016 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package machine_test
+
+import (
+	"github.com/juju/cmd"
+	jujutesting "github.com/juju/testing"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/apiserver/common"
+	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/cmd/juju/machine"
+	"github.com/juju/juju/testing"
+)
+
+type MachinePowerSuite struct {
+	testing.FakeJujuXDGDataHomeSuite
+	fake *fakeMachinePowerAPI
+}
+
+var _ = gc.Suite(&MachinePowerSuite{})
+
+func (s *MachinePowerSuite) SetUpTest(c *gc.C) {
+	s.FakeJujuXDGDataHomeSuite.SetUpTest(c)
+	s.fake = &fakeMachinePowerAPI{}
+}
+
+func (s *MachinePowerSuite) TestInit(c *gc.C) {
+	for i, test := range []struct {
+		args        []string
+		machines    []string
+		errorString string
+	}{{
+		errorString: "no machines specified",
+	}, {
+		args:     []string{"1"},
+		machines: []string{"1"},
+	}, {
+		args:     []string{"1", "2"},
+		machines: []string{"1", "2"},
+	}, {
+		args:        []string{"lxd"},
+		errorString: `invalid machine id "lxd"`,
+	}} {
+		c.Logf("test %d", i)
+		wrappedCommand, suspendCmd := machine.NewSuspendCommandForTest(s.fake)
+		err := testing.InitCommand(wrappedCommand, test.args)
+		if test.errorString == "" {
+			c.Check(err, jc.ErrorIsNil)
+			c.Check(suspendCmd.MachineIds, jc.DeepEquals, test.machines)
+		} else {
+			c.Check(err, gc.ErrorMatches, test.errorString)
+		}
+	}
+}
+
+func (s *MachinePowerSuite) TestSuspend(c *gc.C) {
+	s.fake.results = []params.ErrorResult{{}, {}}
+	suspend, _ := machine.NewSuspendCommandForTest(s.fake)
+	_, err := testing.RunCommand(c, suspend, "1", "2")
+	c.Assert(err, jc.ErrorIsNil)
+	s.fake.CheckCallNames(c, "SuspendMachines", "Close")
+	s.fake.CheckCall(c, 0, "SuspendMachines", []string{"1", "2"})
+}
+
+func (s *MachinePowerSuite) TestResume(c *gc.C) {
+	s.fake.results = []params.ErrorResult{{}}
+	_, err := testing.RunCommand(c, machine.NewResumeCommandForTest(s.fake), "1")
+	c.Assert(err, jc.ErrorIsNil)
+	s.fake.CheckCallNames(c, "ResumeMachines", "Close")
+	s.fake.CheckCall(c, 0, "ResumeMachines", []string{"1"})
+}
+
+func (s *MachinePowerSuite) TestSuspendFailures(c *gc.C) {
+	s.fake.results = []params.ErrorResult{
+		{Error: &params.Error{Message: "machine 0 is a controller machine"}},
+		{},
+	}
+	suspend, _ := machine.NewSuspendCommandForTest(s.fake)
+	ctx, err := testing.RunCommand(c, suspend, "0", "1")
+	c.Assert(err, gc.Equals, cmd.ErrSilent)
+	c.Assert(testing.Stderr(ctx), gc.Equals, "failed to suspend machine 0: machine 0 is a controller machine\n")
+}
+
+func (s *MachinePowerSuite) TestSuspendBlocked(c *gc.C) {
+	s.fake.SetErrors(common.OperationBlockedError("TestSuspendBlocked"))
+	suspend, _ := machine.NewSuspendCommandForTest(s.fake)
+	_, err := testing.RunCommand(c, suspend, "1")
+	testing.AssertOperationWasBlocked(c, err, ".*TestSuspendBlocked.*")
+}
+
+type fakeMachinePowerAPI struct {
+	jujutesting.Stub
+	results []params.ErrorResult
+}
+
+func (f *fakeMachinePowerAPI) Close() error {
+	f.MethodCall(f, "Close")
+	return nil
+}
+
+func (f *fakeMachinePowerAPI) SuspendMachines(machines ...string) ([]params.ErrorResult, error) {
+	f.MethodCall(f, "SuspendMachines", machines)
+	return f.results, f.NextErr()
+}
+
+func (f *fakeMachinePowerAPI) ResumeMachines(machines ...string) ([]params.ErrorResult, error) {
+	f.MethodCall(f, "ResumeMachines", machines)
+	return f.results, f.NextErr()
+}
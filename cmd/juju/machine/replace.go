@@ -0,0 +1,120 @@
+// Copyright 2016 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package machine
+
+import (
+	"fmt"
+
+	"github.com/juju/cmd"
+	"github.com/juju/errors"
+	"gopkg.in/juju/names.v2"
+
+	"github.com/juju/juju/api/machinemanager"
+	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/cmd/juju/block"
+	"github.com/juju/juju/cmd/modelcmd"
+)
+
+// NewReplaceCommand returns a command used to replace the instances
+// of machines.
+func NewReplaceCommand() cmd.Command {
+	return modelcmd.Wrap(&replaceCommand{})
+}
+
+// ReplaceMachineAPI defines the API methods used by the
+// replace-machine command.
+type ReplaceMachineAPI interface {
+	ReplaceMachines(machineIds ...string) ([]params.ErrorResult, error)
+	Close() error
+}
+
+// replaceCommand requests that the instances of machines be replaced.
+type replaceCommand struct {
+	modelcmd.ModelCommandBase
+	api        ReplaceMachineAPI
+	MachineIds []string
+}
+
+const replaceMachineDoc = `
+Replacing a machine starts a new instance for it, for use when the
+machine's instance has been deleted from the cloud outside of Juju. The
+new instance runs the machine's agent and units, and the machine's
+existing volumes are attached to it. The machine keeps its number, but
+its addresses may change.
+
+The instance is replaced only once the old instance no longer exists;
+machines whose instances are still running are left alone. Controller
+machines, containers and manually provisioned machines cannot be
+replaced. Not all clouds support replacing machines.
+
+Machines are specified by their numbers, which may be retrieved from the
+output of ` + "`juju status`." + `
+
+Examples:
+
+    juju replace-machine 3
+
+See also:
+    remove-machine
+    add-machine
+`
+
+// Info implements Command.Info.
+func (c *replaceCommand) Info() *cmd.Info {
+	return &cmd.Info{
+		Name:    "replace-machine",
+		Args:    "<machine number> ...",
+		Purpose: "Starts new instances for machines whose instances were deleted.",
+		Doc:     replaceMachineDoc,
+	}
+}
+
+// Init implements Command.Init.
+func (c *replaceCommand) Init(args []string) error {
+	if len(args) == 0 {
+		return errors.Errorf("no machines specified")
+	}
+	for _, id := range args {
+		if !names.IsValidMachine(id) {
+			return errors.Errorf("invalid machine id %q", id)
+		}
+	}
+	c.MachineIds = args
+	return nil
+}
+
+func (c *replaceCommand) getAPI() (ReplaceMachineAPI, error) {
+	if c.api != nil {
+		return c.api, nil
+	}
+	root, err := c.NewAPIRoot()
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	return machinemanager.NewClient(root), nil
+}
+
+// Run implements Command.Run.
+func (c *replaceCommand) Run(ctx *cmd.Context) error {
+	client, err := c.getAPI()
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+	results, err := client.ReplaceMachines(c.MachineIds...)
+	if err != nil {
+		return block.ProcessBlockedError(err, block.BlockChange)
+	}
+	var failed bool
+	for i, result := range results {
+		if result.Error != nil {
+			fmt.Fprintf(ctx.Stderr, "failed to replace machine %s: %v\n", c.MachineIds[i], result.Error)
+			failed = true
+		}
+	}
+	if failed {
+		return cmd.ErrSilent
+	}
+	return nil
+}
@@ -0,0 +1,99 @@
+// Copyright 2016 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package machine_test
+
+import (
+	"github.com/juju/cmd"
+	jujutesting "github.com/juju/testing"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/apiserver/common"
+	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/cmd/juju/machine"
+	"github.com/juju/juju/testing"
+)
+
+type ReplaceMachineSuite struct {
+	testing.FakeJujuXDGDataHomeSuite
+	fake *fakeReplaceMachineAPI
+}
+
+var _ = gc.Suite(&ReplaceMachineSuite{})
+
+func (s *ReplaceMachineSuite) SetUpTest(c *gc.C) {
+	s.FakeJujuXDGDataHomeSuite.SetUpTest(c)
+	s.fake = &fakeReplaceMachineAPI{}
+}
+
+func (s *ReplaceMachineSuite) TestInit(c *gc.C) {
+	for i, test := range []struct {
+		args        []string
+		machines    []string
+		errorString string
+	}{{
+		errorString: "no machines specified",
+	}, {
+		args:     []string{"1"},
+		machines: []string{"1"},
+	}, {
+		args:     []string{"1", "2"},
+		machines: []string{"1", "2"},
+	}, {
+		args:        []string{"lxd"},
+		errorString: `invalid machine id "lxd"`,
+	}} {
+		c.Logf("test %d", i)
+		wrappedCommand, replaceCmd := machine.NewReplaceCommandForTest(s.fake)
+		err := testing.InitCommand(wrappedCommand, test.args)
+		if test.errorString == "" {
+			c.Check(err, jc.ErrorIsNil)
+			c.Check(replaceCmd.MachineIds, jc.DeepEquals, test.machines)
+		} else {
+			c.Check(err, gc.ErrorMatches, test.errorString)
+		}
+	}
+}
+
+func (s *ReplaceMachineSuite) TestReplace(c *gc.C) {
+	s.fake.results = []params.ErrorResult{{}, {}}
+	replace, _ := machine.NewReplaceCommandForTest(s.fake)
+	_, err := testing.RunCommand(c, replace, "1", "2")
+	c.Assert(err, jc.ErrorIsNil)
+	s.fake.CheckCallNames(c, "ReplaceMachines", "Close")
+	s.fake.CheckCall(c, 0, "ReplaceMachines", []string{"1", "2"})
+}
+
+func (s *ReplaceMachineSuite) TestReplaceFailures(c *gc.C) {
+	s.fake.results = []params.ErrorResult{
+		{Error: &params.Error{Message: "replacing controller machine not supported"}},
+		{},
+	}
+	replace, _ := machine.NewReplaceCommandForTest(s.fake)
+	ctx, err := testing.RunCommand(c, replace, "0", "1")
+	c.Assert(err, gc.Equals, cmd.ErrSilent)
+	c.Assert(testing.Stderr(ctx), gc.Equals, "failed to replace machine 0: replacing controller machine not supported\n")
+}
+
+func (s *ReplaceMachineSuite) TestReplaceBlocked(c *gc.C) {
+	s.fake.SetErrors(common.OperationBlockedError("TestReplaceBlocked"))
+	replace, _ := machine.NewReplaceCommandForTest(s.fake)
+	_, err := testing.RunCommand(c, replace, "1")
+	testing.AssertOperationWasBlocked(c, err, ".*TestReplaceBlocked.*")
+}
+
+type fakeReplaceMachineAPI struct {
+	jujutesting.Stub
+	results []params.ErrorResult
+}
+
+func (f *fakeReplaceMachineAPI) Close() error {
+	f.MethodCall(f, "Close")
+	return nil
+}
+
+func (f *fakeReplaceMachineAPI) ReplaceMachines(machines ...string) ([]params.ErrorResult, error) {
+	f.MethodCall(f, "ReplaceMachines", machines)
+	return f.results, f.NextErr()
+}
@@ -0,0 +1,212 @@
+// Copyright 2016 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package model
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/juju/cmd"
+	"github.com/juju/errors"
+	"github.com/juju/gnuflag"
+
+	"github.com/juju/juju/api/constraintsvalidator"
+	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/cmd/modelcmd"
+	"github.com/juju/juju/cmd/output"
+	"github.com/juju/juju/constraints"
+)
+
+const constraintsVocabularyDoc = `
+Shows the constraint values accepted by the model's cloud: the valid
+values of constraints such as instance-type and arch, the constraints
+that may not be combined, and the constraints that the cloud ignores.
+
+If constraints are specified, they are checked against the model's
+cloud instead, and an error describing the valid values is displayed
+for any that are not accepted. This can be used to check constraints
+before deploying an application or adding a machine.
+
+Examples:
+
+    juju constraints
+    juju constraints --format yaml
+    juju constraints instance-type=Standard_D2 mem=8G
+
+See also:
+    get-model-constraints
+    set-model-constraints
+    get-constraints
+    set-constraints
+`
+
+// NewConstraintsVocabularyCommand returns a command that shows, or
+// validates constraints against, the constraints accepted by a model.
+func NewConstraintsVocabularyCommand() cmd.Command {
+	return modelcmd.Wrap(&constraintsVocabularyCommand{})
+}
+
+// constraintsVocabularyCommand shows the constraints accepted by a
+// model, or validates constraints against them.
+type constraintsVocabularyCommand struct {
+	modelcmd.ModelCommandBase
+	out         cmd.Output
+	api         ConstraintsVocabularyAPI
+	Constraints *constraints.Value
+}
+
+// ConstraintsVocabularyAPI defines the API methods that the
+// constraints command calls.
+type ConstraintsVocabularyAPI interface {
+	Close() error
+	Vocabulary() (params.ConstraintsVocabulary, error)
+	ValidateConstraints(cons ...constraints.Value) ([]params.ValidateConstraintsResult, error)
+}
+
+// Info implements Command.Info.
+func (c *constraintsVocabularyCommand) Info() *cmd.Info {
+	return &cmd.Info{
+		Name:    "constraints",
+		Args:    "[<constraint>=<value> ...]",
+		Purpose: "Shows or checks the constraints accepted by a model.",
+		Doc:     constraintsVocabularyDoc,
+	}
+}
+
+// SetFlags implements Command.SetFlags.
+func (c *constraintsVocabularyCommand) SetFlags(f *gnuflag.FlagSet) {
+	c.ModelCommandBase.SetFlags(f)
+	c.out.AddFlags(f, "tabular", map[string]cmd.Formatter{
+		"yaml":    cmd.FormatYaml,
+		"json":    cmd.FormatJson,
+		"tabular": formatConstraintsVocabularyTabular,
+	})
+}
+
+// Init implements Command.Init.
+func (c *constraintsVocabularyCommand) Init(args []string) error {
+	if len(args) == 0 {
+		return nil
+	}
+	cons, err := constraints.Parse(args...)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	c.Constraints = &cons
+	return nil
+}
+
+func (c *constraintsVocabularyCommand) getAPI() (ConstraintsVocabularyAPI, error) {
+	if c.api != nil {
+		return c.api, nil
+	}
+	root, err := c.NewAPIRoot()
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	return constraintsvalidator.NewClient(root), nil
+}
+
+// constraintsVocabulary is the output representation of the
+// constraints accepted by a model.
+type constraintsVocabulary struct {
+	Vocabulary  map[string][]interface{} `yaml:"vocabulary,omitempty" json:"vocabulary,omitempty"`
+	Conflicts   map[string][]string      `yaml:"conflicts,omitempty" json:"conflicts,omitempty"`
+	Unsupported []string                 `yaml:"unsupported,omitempty" json:"unsupported,omitempty"`
+}
+
+// Run implements Command.Run.
+func (c *constraintsVocabularyCommand) Run(ctx *cmd.Context) error {
+	api, err := c.getAPI()
+	if err != nil {
+		return err
+	}
+	defer api.Close()
+
+	if c.Constraints != nil {
+		return c.validate(ctx, api)
+	}
+	vocab, err := api.Vocabulary()
+	if err != nil {
+		return errors.Trace(err)
+	}
+	return c.out.Write(ctx, constraintsVocabulary{
+		Vocabulary:  vocab.Vocabulary,
+		Conflicts:   vocab.Conflicts,
+		Unsupported: vocab.Unsupported,
+	})
+}
+
+// validate checks the command's constraints against those accepted
+// by the model, reporting any that are unsupported or invalid.
+func (c *constraintsVocabularyCommand) validate(ctx *cmd.Context, api ConstraintsVocabularyAPI) error {
+	results, err := api.ValidateConstraints(*c.Constraints)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	result := results[0]
+	if result.Error != nil {
+		return result.Error
+	}
+	if len(result.Unsupported) > 0 {
+		ctx.Infof(
+			"WARNING: unsupported constraints will be ignored: %s",
+			strings.Join(result.Unsupported, ","),
+		)
+	}
+	ctx.Infof("constraints %q are valid for this model", c.Constraints.String())
+	return nil
+}
+
+// formatConstraintsVocabularyTabular writes a tabular summary of
+// the constraints accepted by a model.
+func formatConstraintsVocabularyTabular(writer io.Writer, value interface{}) error {
+	vocab, ok := value.(constraintsVocabulary)
+	if !ok {
+		return errors.Errorf("expected value of type %T, got %T", vocab, value)
+	}
+	tw := output.TabWriter(writer)
+	w := output.Wrapper{tw}
+	if len(vocab.Vocabulary) > 0 {
+		w.Println("CONSTRAINT", "VALID VALUES")
+		attrs := make([]string, 0, len(vocab.Vocabulary))
+		for attr := range vocab.Vocabulary {
+			attrs = append(attrs, attr)
+		}
+		sort.Strings(attrs)
+		for _, attr := range attrs {
+			values := make([]string, len(vocab.Vocabulary[attr]))
+			for i, v := range vocab.Vocabulary[attr] {
+				values[i] = fmt.Sprint(v)
+			}
+			w.Println(attr, strings.Join(values, ","))
+		}
+	}
+	if len(vocab.Conflicts) > 0 {
+		if len(vocab.Vocabulary) > 0 {
+			w.Println()
+		}
+		w.Println("CONSTRAINT", "CONFLICTS WITH")
+		attrs := make([]string, 0, len(vocab.Conflicts))
+		for attr := range vocab.Conflicts {
+			attrs = append(attrs, attr)
+		}
+		sort.Strings(attrs)
+		for _, attr := range attrs {
+			w.Println(attr, strings.Join(vocab.Conflicts[attr], ","))
+		}
+	}
+	if len(vocab.Unsupported) > 0 {
+		if len(vocab.Vocabulary) > 0 || len(vocab.Conflicts) > 0 {
+			w.Println()
+		}
+		w.Println("UNSUPPORTED")
+		for _, attr := range vocab.Unsupported {
+			w.Println(attr)
+		}
+	}
+	return tw.Flush()
+}
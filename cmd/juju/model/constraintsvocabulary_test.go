@@ -0,0 +1,164 @@
+// Copyright 2016 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package model_test
+
+import (
+	"github.com/juju/errors"
+	gitjujutesting "github.com/juju/testing"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/cmd/juju/model"
+	"github.com/juju/juju/constraints"
+	"github.com/juju/juju/jujuclient"
+	"github.com/juju/juju/jujuclient/jujuclienttesting"
+	"github.com/juju/juju/testing"
+)
+
+type ConstraintsVocabularyCommandSuite struct {
+	testing.FakeJujuXDGDataHomeSuite
+	fake  fakeConstraintsVocabularyClient
+	store *jujuclienttesting.MemStore
+}
+
+var _ = gc.Suite(&ConstraintsVocabularyCommandSuite{})
+
+type fakeConstraintsVocabularyClient struct {
+	gitjujutesting.Stub
+	vocab   params.ConstraintsVocabulary
+	results []params.ValidateConstraintsResult
+}
+
+func (f *fakeConstraintsVocabularyClient) Close() error {
+	f.MethodCall(f, "Close")
+	return f.NextErr()
+}
+
+func (f *fakeConstraintsVocabularyClient) Vocabulary() (params.ConstraintsVocabulary, error) {
+	f.MethodCall(f, "Vocabulary")
+	return f.vocab, f.NextErr()
+}
+
+func (f *fakeConstraintsVocabularyClient) ValidateConstraints(cons ...constraints.Value) ([]params.ValidateConstraintsResult, error) {
+	f.MethodCall(f, "ValidateConstraints", cons)
+	return f.results, f.NextErr()
+}
+
+func (s *ConstraintsVocabularyCommandSuite) SetUpTest(c *gc.C) {
+	s.FakeJujuXDGDataHomeSuite.SetUpTest(c)
+	s.fake.ResetCalls()
+	s.fake.vocab = params.ConstraintsVocabulary{
+		Vocabulary: map[string][]interface{}{
+			"instance-type": {"Standard_A1", "Standard_D2"},
+			"arch":          {"amd64"},
+		},
+		Conflicts: map[string][]string{
+			"instance-type": {"cores", "mem"},
+		},
+		Unsupported: []string{"cpu-power", "tags"},
+	}
+	s.fake.results = []params.ValidateConstraintsResult{{}}
+	s.store = jujuclienttesting.NewMemStore()
+	s.store.CurrentControllerName = "testing"
+	s.store.Controllers["testing"] = jujuclient.ControllerDetails{}
+	s.store.Accounts["testing"] = jujuclient.AccountDetails{
+		User: "admin@local",
+	}
+	err := s.store.UpdateModel("testing", "admin@local/mymodel", jujuclient.ModelDetails{
+		testing.ModelTag.Id(),
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	s.store.Models["testing"].CurrentModel = "admin@local/mymodel"
+}
+
+func (s *ConstraintsVocabularyCommandSuite) run(c *gc.C, args ...string) (string, string, error) {
+	ctx, err := testing.RunCommand(c, model.NewConstraintsVocabularyCommandForTest(&s.fake, s.store), args...)
+	if ctx == nil {
+		return "", "", err
+	}
+	return testing.Stdout(ctx), testing.Stderr(ctx), err
+}
+
+func (s *ConstraintsVocabularyCommandSuite) TestShowTabular(c *gc.C) {
+	stdout, _, err := s.run(c)
+	c.Assert(err, jc.ErrorIsNil)
+	s.fake.CheckCallNames(c, "Vocabulary", "Close")
+	c.Assert(stdout, gc.Equals, `
+CONSTRAINT     VALID VALUES
+arch           amd64
+instance-type  Standard_A1,Standard_D2
+
+CONSTRAINT     CONFLICTS WITH
+instance-type  cores,mem
+
+UNSUPPORTED
+cpu-power
+tags
+`[1:])
+}
+
+func (s *ConstraintsVocabularyCommandSuite) TestShowYAML(c *gc.C) {
+	stdout, _, err := s.run(c, "--format", "yaml")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(stdout, gc.Equals, `
+vocabulary:
+  arch:
+  - amd64
+  instance-type:
+  - Standard_A1
+  - Standard_D2
+conflicts:
+  instance-type:
+  - cores
+  - mem
+unsupported:
+- cpu-power
+- tags
+`[1:])
+}
+
+func (s *ConstraintsVocabularyCommandSuite) TestShowError(c *gc.C) {
+	s.fake.SetErrors(errors.New("boom"))
+	_, _, err := s.run(c)
+	c.Assert(err, gc.ErrorMatches, "boom")
+	s.fake.CheckCallNames(c, "Vocabulary", "Close")
+}
+
+func (s *ConstraintsVocabularyCommandSuite) TestValidate(c *gc.C) {
+	_, stderr, err := s.run(c, "instance-type=Standard_A1", "arch=amd64")
+	c.Assert(err, jc.ErrorIsNil)
+	s.fake.CheckCalls(c, []gitjujutesting.StubCall{
+		{"ValidateConstraints", []interface{}{
+			[]constraints.Value{constraints.MustParse("instance-type=Standard_A1 arch=amd64")},
+		}},
+		{"Close", nil},
+	})
+	c.Assert(stderr, gc.Equals, `constraints "arch=amd64 instance-type=Standard_A1" are valid for this model`+"\n")
+}
+
+func (s *ConstraintsVocabularyCommandSuite) TestValidateUnsupported(c *gc.C) {
+	s.fake.results = []params.ValidateConstraintsResult{{
+		Unsupported: []string{"cpu-power"},
+	}}
+	_, stderr, err := s.run(c, "cpu-power=100")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(stderr, gc.Equals, `
+WARNING: unsupported constraints will be ignored: cpu-power
+constraints "cpu-power=100" are valid for this model
+`[1:])
+}
+
+func (s *ConstraintsVocabularyCommandSuite) TestValidateInvalid(c *gc.C) {
+	s.fake.results = []params.ValidateConstraintsResult{{
+		Error: &params.Error{Message: "invalid constraint value: instance-type=Standard_A9\nvalid values are: [Standard_A1 Standard_D2]"},
+	}}
+	_, _, err := s.run(c, "instance-type=Standard_A9")
+	c.Assert(err, gc.ErrorMatches, "invalid constraint value: instance-type=Standard_A9\nvalid values are: \\[Standard_A1 Standard_D2\\]")
+}
+
+func (s *ConstraintsVocabularyCommandSuite) TestInitInvalidConstraints(c *gc.C) {
+	_, _, err := s.run(c, "foo=bar")
+	c.Assert(err, gc.ErrorMatches, `unknown constraint "foo"`)
+}
@@ -95,3 +95,17 @@ func NewRevokeCommandForTest(api RevokeModelAPI, store jujuclient.ClientStore) (
 	cmd.SetClientStore(store)
 	return modelcmd.WrapController(cmd), &RevokeCommand{cmd}
 }
+
+// NewListProviderResourcesCommandForTest returns a ListProviderResourcesCommand with the api provided as specified.
+func NewListProviderResourcesCommandForTest(api ListProviderResourcesAPI, store jujuclient.ClientStore) cmd.Command {
+	cmd := &listProviderResourcesCommand{api: api}
+	cmd.SetClientStore(store)
+	return modelcmd.Wrap(cmd)
+}
+
+// NewConstraintsVocabularyCommandForTest returns a constraintsVocabularyCommand with the api provided as specified.
+func NewConstraintsVocabularyCommandForTest(api ConstraintsVocabularyAPI, store jujuclient.ClientStore) cmd.Command {
+	cmd := &constraintsVocabularyCommand{api: api}
+	cmd.SetClientStore(store)
+	return modelcmd.Wrap(cmd)
+}
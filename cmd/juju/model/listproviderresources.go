@@ -0,0 +1,132 @@
+// Copyright 2016 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package model
+
+import (
+	"io"
+
+	"github.com/juju/cmd"
+	"github.com/juju/errors"
+	"github.com/juju/gnuflag"
+
+	"github.com/juju/juju/api/providerresources"
+	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/cmd/modelcmd"
+	"github.com/juju/juju/cmd/output"
+)
+
+const listProviderResourcesDoc = `
+Lists the cloud resources that the provider created for the model, such
+as instances, disks, and network interfaces. This can be used to verify
+that nothing has been left behind by a failed operation.
+
+Not all providers support listing their resources.
+
+Examples:
+
+    juju list-provider-resources
+    juju list-provider-resources -m mymodel --format yaml
+`
+
+// NewListProviderResourcesCommand returns a command that lists the
+// cloud resources created by the provider for a model.
+func NewListProviderResourcesCommand() cmd.Command {
+	return modelcmd.Wrap(&listProviderResourcesCommand{})
+}
+
+// listProviderResourcesCommand lists the cloud resources created
+// by the provider for a model.
+type listProviderResourcesCommand struct {
+	modelcmd.ModelCommandBase
+	out cmd.Output
+	api ListProviderResourcesAPI
+}
+
+// ListProviderResourcesAPI defines the API methods that the
+// list-provider-resources command calls.
+type ListProviderResourcesAPI interface {
+	Close() error
+	ListProviderResources() ([]params.ProviderResource, error)
+}
+
+// Info implements Command.Info.
+func (c *listProviderResourcesCommand) Info() *cmd.Info {
+	return &cmd.Info{
+		Name:    "list-provider-resources",
+		Purpose: "Lists the cloud resources created for a model.",
+		Doc:     listProviderResourcesDoc,
+	}
+}
+
+// SetFlags implements Command.SetFlags.
+func (c *listProviderResourcesCommand) SetFlags(f *gnuflag.FlagSet) {
+	c.ModelCommandBase.SetFlags(f)
+	c.out.AddFlags(f, "tabular", map[string]cmd.Formatter{
+		"yaml":    cmd.FormatYaml,
+		"json":    cmd.FormatJson,
+		"tabular": formatProviderResourcesTabular,
+	})
+}
+
+// Init implements Command.Init.
+func (c *listProviderResourcesCommand) Init(args []string) error {
+	return cmd.CheckEmpty(args)
+}
+
+func (c *listProviderResourcesCommand) getAPI() (ListProviderResourcesAPI, error) {
+	if c.api != nil {
+		return c.api, nil
+	}
+	root, err := c.NewAPIRoot()
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	return providerresources.NewClient(root), nil
+}
+
+// providerResource is the output representation of a cloud resource.
+type providerResource struct {
+	Type string            `yaml:"type" json:"type"`
+	Id   string            `yaml:"id" json:"id"`
+	Tags map[string]string `yaml:"tags,omitempty" json:"tags,omitempty"`
+}
+
+// Run implements Command.Run.
+func (c *listProviderResourcesCommand) Run(ctx *cmd.Context) error {
+	api, err := c.getAPI()
+	if err != nil {
+		return err
+	}
+	defer api.Close()
+
+	resources, err := api.ListProviderResources()
+	if err != nil {
+		return errors.Trace(err)
+	}
+	result := make([]providerResource, len(resources))
+	for i, resource := range resources {
+		result[i] = providerResource{
+			Type: resource.Type,
+			Id:   resource.Id,
+			Tags: resource.Tags,
+		}
+	}
+	return c.out.Write(ctx, result)
+}
+
+// formatProviderResourcesTabular writes a tabular summary of
+// provider resources.
+func formatProviderResourcesTabular(writer io.Writer, value interface{}) error {
+	resources, ok := value.([]providerResource)
+	if !ok {
+		return errors.Errorf("expected value of type %T, got %T", resources, value)
+	}
+	tw := output.TabWriter(writer)
+	w := output.Wrapper{tw}
+	w.Println("TYPE", "ID")
+	for _, resource := range resources {
+		w.Println(resource.Type, resource.Id)
+	}
+	return tw.Flush()
+}
@@ -0,0 +1,100 @@
+// Copyright 2016 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package model_test
+
+import (
+	"github.com/juju/errors"
+	gitjujutesting "github.com/juju/testing"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/cmd/juju/model"
+	"github.com/juju/juju/jujuclient"
+	"github.com/juju/juju/jujuclient/jujuclienttesting"
+	"github.com/juju/juju/testing"
+)
+
+type ListProviderResourcesCommandSuite struct {
+	testing.FakeJujuXDGDataHomeSuite
+	fake  fakeListProviderResourcesClient
+	store *jujuclienttesting.MemStore
+}
+
+var _ = gc.Suite(&ListProviderResourcesCommandSuite{})
+
+type fakeListProviderResourcesClient struct {
+	gitjujutesting.Stub
+	resources []params.ProviderResource
+}
+
+func (f *fakeListProviderResourcesClient) Close() error {
+	f.MethodCall(f, "Close")
+	return f.NextErr()
+}
+
+func (f *fakeListProviderResourcesClient) ListProviderResources() ([]params.ProviderResource, error) {
+	f.MethodCall(f, "ListProviderResources")
+	return f.resources, f.NextErr()
+}
+
+func (s *ListProviderResourcesCommandSuite) SetUpTest(c *gc.C) {
+	s.FakeJujuXDGDataHomeSuite.SetUpTest(c)
+	s.fake.ResetCalls()
+	s.fake.resources = []params.ProviderResource{{
+		Type: "Microsoft.Compute/virtualMachines",
+		Id:   "/subscriptions/sub/resourceGroups/rg/providers/Microsoft.Compute/virtualMachines/machine-0",
+		Tags: map[string]string{"juju-model-uuid": testing.ModelTag.Id()},
+	}, {
+		Type: "Microsoft.Network/networkInterfaces",
+		Id:   "/subscriptions/sub/resourceGroups/rg/providers/Microsoft.Network/networkInterfaces/machine-0-primary",
+	}}
+	s.store = jujuclienttesting.NewMemStore()
+	s.store.CurrentControllerName = "testing"
+	s.store.Controllers["testing"] = jujuclient.ControllerDetails{}
+	s.store.Accounts["testing"] = jujuclient.AccountDetails{
+		User: "admin@local",
+	}
+	err := s.store.UpdateModel("testing", "admin@local/mymodel", jujuclient.ModelDetails{
+		testing.ModelTag.Id(),
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	s.store.Models["testing"].CurrentModel = "admin@local/mymodel"
+}
+
+func (s *ListProviderResourcesCommandSuite) TestListTabular(c *gc.C) {
+	ctx, err := testing.RunCommand(c, model.NewListProviderResourcesCommandForTest(&s.fake, s.store))
+	c.Assert(err, jc.ErrorIsNil)
+	s.fake.CheckCallNames(c, "ListProviderResources", "Close")
+	c.Assert(testing.Stdout(ctx), gc.Equals, `
+TYPE                                 ID
+Microsoft.Compute/virtualMachines    /subscriptions/sub/resourceGroups/rg/providers/Microsoft.Compute/virtualMachines/machine-0
+Microsoft.Network/networkInterfaces  /subscriptions/sub/resourceGroups/rg/providers/Microsoft.Network/networkInterfaces/machine-0-primary
+`[1:])
+}
+
+func (s *ListProviderResourcesCommandSuite) TestListYAML(c *gc.C) {
+	ctx, err := testing.RunCommand(c, model.NewListProviderResourcesCommandForTest(&s.fake, s.store), "--format", "yaml")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(testing.Stdout(ctx), gc.Equals, `
+- type: Microsoft.Compute/virtualMachines
+  id: /subscriptions/sub/resourceGroups/rg/providers/Microsoft.Compute/virtualMachines/machine-0
+  tags:
+    juju-model-uuid: `+testing.ModelTag.Id()+`
+- type: Microsoft.Network/networkInterfaces
+  id: /subscriptions/sub/resourceGroups/rg/providers/Microsoft.Network/networkInterfaces/machine-0-primary
+`[1:])
+}
+
+func (s *ListProviderResourcesCommandSuite) TestListError(c *gc.C) {
+	s.fake.SetErrors(errors.NotSupportedf("enumerating resources"))
+	_, err := testing.RunCommand(c, model.NewListProviderResourcesCommandForTest(&s.fake, s.store))
+	c.Assert(err, gc.ErrorMatches, "enumerating resources not supported")
+	s.fake.CheckCallNames(c, "ListProviderResources", "Close")
+}
+
+func (s *ListProviderResourcesCommandSuite) TestInitRejectsArgs(c *gc.C) {
+	_, err := testing.RunCommand(c, model.NewListProviderResourcesCommandForTest(&s.fake, s.store), "foo")
+	c.Assert(err, gc.ErrorMatches, `unrecognized args: \["foo"\]`)
+}
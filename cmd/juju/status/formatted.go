@@ -29,13 +29,14 @@ type errorStatus struct {
 }
 
 type modelStatus struct {
-	Name             string `json:"name" yaml:"name"`
-	Controller       string `json:"controller" yaml:"controller"`
-	Cloud            string `json:"cloud" yaml:"cloud"`
-	CloudRegion      string `json:"region,omitempty" yaml:"region,omitempty"`
-	Version          string `json:"version" yaml:"version"`
-	AvailableVersion string `json:"upgrade-available,omitempty" yaml:"upgrade-available,omitempty"`
-	Migration        string `json:"migration,omitempty" yaml:"migration,omitempty"`
+	Name             string   `json:"name" yaml:"name"`
+	Controller       string   `json:"controller" yaml:"controller"`
+	Cloud            string   `json:"cloud" yaml:"cloud"`
+	CloudRegion      string   `json:"region,omitempty" yaml:"region,omitempty"`
+	Version          string   `json:"version" yaml:"version"`
+	AvailableVersion string   `json:"upgrade-available,omitempty" yaml:"upgrade-available,omitempty"`
+	Migration        string   `json:"migration,omitempty" yaml:"migration,omitempty"`
+	Features         []string `json:"features,omitempty" yaml:"features,omitempty"`
 }
 
 type machineStatus struct {
@@ -49,6 +50,18 @@ type machineStatus struct {
 	Containers    map[string]machineStatus `json:"containers,omitempty" yaml:"containers,omitempty"`
 	Hardware      string                   `json:"hardware,omitempty" yaml:"hardware,omitempty"`
 	HAStatus      string                   `json:"controller-member-status,omitempty" yaml:"controller-member-status,omitempty"`
+
+	ProvisioningError *provisioningErrorStatus `json:"provisioning-error,omitempty" yaml:"provisioning-error,omitempty"`
+}
+
+// provisioningErrorStatus describes why an instance could not be
+// started for a machine, and how to retry provisioning it.
+type provisioningErrorStatus struct {
+	Kind     string `json:"kind" yaml:"kind"`
+	Message  string `json:"message" yaml:"message"`
+	Attempts int    `json:"attempts" yaml:"attempts"`
+	Since    string `json:"since,omitempty" yaml:"since,omitempty"`
+	Retry    string `json:"retry,omitempty" yaml:"retry,omitempty"`
 }
 
 // A goyaml bug means we can't declare these types
@@ -114,12 +127,13 @@ type unitStatus struct {
 	JujuStatusInfo     statusInfoContents `json:"juju-status,omitempty" yaml:"juju-status"`
 	MeterStatus        *meterStatus       `json:"meter-status,omitempty" yaml:"meter-status,omitempty"`
 
-	Leader        bool                  `json:"leader,omitempty" yaml:"leader,omitempty"`
-	Charm         string                `json:"upgrading-from,omitempty" yaml:"upgrading-from,omitempty"`
-	Machine       string                `json:"machine,omitempty" yaml:"machine,omitempty"`
-	OpenedPorts   []string              `json:"open-ports,omitempty" yaml:"open-ports,omitempty"`
-	PublicAddress string                `json:"public-address,omitempty" yaml:"public-address,omitempty"`
-	Subordinates  map[string]unitStatus `json:"subordinates,omitempty" yaml:"subordinates,omitempty"`
+	Leader          bool                  `json:"leader,omitempty" yaml:"leader,omitempty"`
+	Charm           string                `json:"upgrading-from,omitempty" yaml:"upgrading-from,omitempty"`
+	Machine         string                `json:"machine,omitempty" yaml:"machine,omitempty"`
+	OpenedPorts     []string              `json:"open-ports,omitempty" yaml:"open-ports,omitempty"`
+	PublicAddress   string                `json:"public-address,omitempty" yaml:"public-address,omitempty"`
+	WorkloadVersion string                `json:"workload-version,omitempty" yaml:"workload-version,omitempty"`
+	Subordinates    map[string]unitStatus `json:"subordinates,omitempty" yaml:"subordinates,omitempty"`
 }
 
 func (s *formattedStatus) applicationScale(name string) (string, bool) {
@@ -59,6 +59,7 @@ func (sf *statusFormatter) format() (formattedStatus, error) {
 			Version:          sf.status.Model.Version,
 			AvailableVersion: sf.status.Model.AvailableVersion,
 			Migration:        sf.status.Model.Migration,
+			Features:         sf.status.Model.Features,
 		},
 		Machines:     make(map[string]machineStatus),
 		Applications: make(map[string]applicationStatus),
@@ -109,6 +110,21 @@ func (sf *statusFormatter) formatMachine(machine params.MachineStatus) machineSt
 		Hardware:      machine.Hardware,
 	}
 
+	if perr := machine.ProvisioningError; perr != nil {
+		out.ProvisioningError = &provisioningErrorStatus{
+			Kind:     perr.Kind,
+			Message:  perr.Message,
+			Attempts: perr.Attempts,
+		}
+		if perr.Since != nil {
+			out.ProvisioningError.Since = common.FormatTime(perr.Since, sf.isoTime)
+		}
+		// The retry-provisioning command does not support containers.
+		if !names.IsContainerMachine(machine.Id) {
+			out.ProvisioningError.Retry = "juju retry-provisioning " + machine.Id
+		}
+	}
+
 	for k, m := range machine.Containers {
 		out.Containers[k] = sf.formatMachine(m)
 	}
@@ -205,6 +221,7 @@ func (sf *statusFormatter) formatUnit(info unitFormatInfo) unitStatus {
 		Machine:            info.unit.Machine,
 		OpenedPorts:        info.unit.OpenedPorts,
 		PublicAddress:      info.unit.PublicAddress,
+		WorkloadVersion:    info.unit.WorkloadVersion,
 		Charm:              info.unit.Charm,
 		Subordinates:       make(map[string]unitStatus),
 		Leader:             info.unit.Leader,
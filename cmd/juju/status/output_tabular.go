@@ -244,6 +244,33 @@ func printMachines(tw *ansiterm.TabWriter, machines map[string]machineStatus) {
 	for _, name := range utils.SortStringsNaturally(stringKeysFromMap(machines)) {
 		printMachine(w, machines[name])
 	}
+	printProvisioningErrors(w, machines)
+}
+
+// printProvisioningErrors writes a table of the machines that could
+// not be provisioned, with the reason and the command to retry.
+func printProvisioningErrors(w output.Wrapper, machines map[string]machineStatus) {
+	var failed []machineStatus
+	var collect func(machines map[string]machineStatus)
+	collect = func(machines map[string]machineStatus) {
+		for _, name := range utils.SortStringsNaturally(stringKeysFromMap(machines)) {
+			m := machines[name]
+			if m.ProvisioningError != nil {
+				failed = append(failed, m)
+			}
+			collect(m.Containers)
+		}
+	}
+	collect(machines)
+	if len(failed) == 0 {
+		return
+	}
+	w.Println()
+	w.Println("MACHINE", "PROVISIONING-ERROR", "ATTEMPTS", "RETRY", "MESSAGE")
+	for _, m := range failed {
+		perr := m.ProvisioningError
+		w.Println(m.Id, perr.Kind, perr.Attempts, perr.Retry, perr.Message)
+	}
 }
 
 func printMachine(w output.Wrapper, m machineStatus) {
@@ -2453,7 +2453,8 @@ var statusTests = []testCase{
 									"current": "allocating",
 									"since":   "01 Apr 15 01:23+10:00",
 								},
-								"public-address": "controller-1.dns",
+								"public-address":   "controller-1.dns",
+								"workload-version": "the best!",
 							},
 						},
 					}),
@@ -2514,7 +2515,8 @@ var statusTests = []testCase{
 									"current": "allocating",
 									"since":   "01 Apr 15 01:23+10:00",
 								},
-								"public-address": "controller-1.dns",
+								"public-address":   "controller-1.dns",
+								"workload-version": "the best!",
 							},
 							"mysql/1": M{
 								"machine": "2",
@@ -2527,7 +2529,8 @@ var statusTests = []testCase{
 									"current": "allocating",
 									"since":   "01 Apr 15 01:23+10:00",
 								},
-								"public-address": "controller-2.dns",
+								"public-address":   "controller-2.dns",
+								"workload-version": "not as good",
 							},
 						},
 					}),
@@ -3674,6 +3677,28 @@ func (s *StatusSuite) TestStatusWithNilStatusAPI(c *gc.C) {
 	c.Check(string(stderr), gc.Equals, "error: unable to obtain the current status\n")
 }
 
+func (s *StatusSuite) TestStatusModelFeatures(c *gc.C) {
+	client := fakeAPIClient{statusReturn: &params.FullStatus{
+		Model: params.ModelStatusInfo{
+			Name:     "hosted",
+			CloudTag: "cloud-dummy",
+			Version:  "2.0.0",
+			Features: []string{"suspend-instances", "volumes"},
+		},
+	}}
+	s.PatchValue(&newAPIClientForStatus, func(_ *statusCommand) (statusAPI, error) {
+		return &client, nil
+	})
+
+	code, stdout, stderr := runStatus(c, "--format", "yaml")
+	c.Assert(code, gc.Equals, 0, gc.Commentf("stderr: %s", stderr))
+	c.Assert(string(stdout), jc.Contains, `
+  features:
+  - suspend-instances
+  - volumes
+`[1:])
+}
+
 func (s *StatusSuite) TestFormatTabularMetering(c *gc.C) {
 	status := formattedStatus{
 		Applications: map[string]applicationStatus{
@@ -4241,6 +4266,72 @@ func (s *StatusSuite) TestFormatProvisioningError(c *gc.C) {
 	})
 }
 
+func (s *StatusSuite) TestFormatMachineProvisioningErrorRecord(c *gc.C) {
+	since := time.Date(2016, 10, 1, 12, 0, 0, 0, time.UTC)
+	status := &params.FullStatus{
+		Model: params.ModelStatusInfo{
+			CloudTag: "cloud-dummy",
+		},
+		Machines: map[string]params.MachineStatus{
+			"1": {
+				AgentStatus: params.DetailedStatus{
+					Status: "error",
+					Info:   "cores quota exhausted",
+				},
+				InstanceId: "pending",
+				Series:     "trusty",
+				Id:         "1",
+				Jobs:       []multiwatcher.MachineJob{"JobHostUnits"},
+				ProvisioningError: &params.MachineProvisioningError{
+					Kind:     "quota",
+					Message:  "cores quota exhausted",
+					Attempts: 3,
+					Since:    &since,
+				},
+			},
+		},
+	}
+	formatter := NewStatusFormatter(status, true)
+	formatted, err := formatter.format()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(formatted.Machines["1"].ProvisioningError, jc.DeepEquals, &provisioningErrorStatus{
+		Kind:     "quota",
+		Message:  "cores quota exhausted",
+		Attempts: 3,
+		Since:    "2016-10-01 12:00:00Z",
+		Retry:    "juju retry-provisioning 1",
+	})
+}
+
+func (s *StatusSuite) TestFormatMachineTabularProvisioningError(c *gc.C) {
+	status := formattedMachineStatus{
+		Machines: map[string]machineStatus{
+			"1": {
+				JujuStatus: statusInfoContents{Current: status.Error},
+				InstanceId: "pending",
+				Series:     "trusty",
+				Id:         "1",
+				ProvisioningError: &provisioningErrorStatus{
+					Kind:     "quota",
+					Message:  "cores quota exhausted",
+					Attempts: 3,
+					Retry:    "juju retry-provisioning 1",
+				},
+			},
+		},
+	}
+	out := &bytes.Buffer{}
+	err := FormatMachineTabular(out, false, status)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(out.String(), gc.Equals, `
+MACHINE  STATE  DNS  INS-ID   SERIES  AZ
+1        error       pending  trusty  
+
+MACHINE  PROVISIONING-ERROR  ATTEMPTS  RETRY                      MESSAGE
+1        quota               3         juju retry-provisioning 1  cores quota exhausted
+`[1:])
+}
+
 type tableSections map[string][]string
 
 func sectionTitle(lines []string) string {
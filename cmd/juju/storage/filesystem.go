@@ -12,6 +12,7 @@ import (
 
 	"github.com/juju/juju/apiserver/params"
 	"github.com/juju/juju/cmd/juju/common"
+	"github.com/juju/juju/status"
 )
 
 // FilesystemCommandBase is a helper base structure for filesystem commands.
@@ -69,6 +70,15 @@ func (c *listCommand) generateListFilesystemsOutput(ctx *cmd.Context, api Storag
 		// display individual error
 		fmt.Fprintf(ctx.Stderr, "%v\n", result.Error)
 	}
+	if c.pending {
+		var pending []params.FilesystemDetails
+		for _, details := range valid {
+			if details.Status.Status == status.Pending {
+				pending = append(pending, details)
+			}
+		}
+		valid = pending
+	}
 	if len(valid) == 0 {
 		return nil, nil
 	}
@@ -9,9 +9,11 @@ import (
 	"github.com/juju/cmd"
 	"github.com/juju/errors"
 	"github.com/juju/gnuflag"
+	"gopkg.in/juju/names.v2"
 
 	"github.com/juju/juju/apiserver/params"
 	"github.com/juju/juju/cmd/modelcmd"
+	"github.com/juju/juju/status"
 )
 
 // NewListCommand returns a command for listing storage instances.
@@ -25,6 +27,17 @@ func NewListCommand() cmd.Command {
 
 const listCommandDoc = `
 List information about storage instances.
+
+With --pending, only storage that has not yet been provisioned is listed.
+Adding --plan shows what the storage provisioner will do to provision
+the pending volumes and filesystems: the provider and pool each will be
+created with, their sizes, the machines they will be attached to, and
+anything they are waiting for. Nothing is provisioned by listing a plan.
+
+Examples:
+    juju storage --pending
+    juju storage --pending --plan
+    juju storage --volume --pending --plan
 `
 
 // listCommand returns storage instances.
@@ -34,12 +47,17 @@ type listCommand struct {
 	ids        []string
 	filesystem bool
 	volume     bool
+	pending    bool
+	plan       bool
 	newAPIFunc func() (StorageListAPI, error)
 }
 
 // Init implements Command.Init.
 func (c *listCommand) Init(args []string) (err error) {
 	c.ids = args
+	if c.plan && !c.pending {
+		return errors.New("--plan requires --pending")
+	}
 	return nil
 }
 
@@ -64,6 +82,8 @@ func (c *listCommand) SetFlags(f *gnuflag.FlagSet) {
 	})
 	f.BoolVar(&c.filesystem, "filesystem", false, "List filesystem storage")
 	f.BoolVar(&c.volume, "volume", false, "List volume storage")
+	f.BoolVar(&c.pending, "pending", false, "List only storage that has not yet been provisioned")
+	f.BoolVar(&c.plan, "plan", false, "Show the plan for provisioning pending storage")
 }
 
 // Run implements Command.Run.
@@ -75,7 +95,9 @@ func (c *listCommand) Run(ctx *cmd.Context) (err error) {
 	defer api.Close()
 
 	var output interface{}
-	if c.filesystem {
+	if c.plan {
+		output, err = c.generateListPlanOutput(ctx, api)
+	} else if c.filesystem {
 		output, err = c.generateListFilesystemsOutput(ctx, api)
 	} else if c.volume {
 		output, err = c.generateListVolumeOutput(ctx, api)
@@ -98,6 +120,7 @@ type StorageListAPI interface {
 	ListStorageDetails() ([]params.StorageDetails, error)
 	ListFilesystems(machines []string) ([]params.FilesystemDetailsListResult, error)
 	ListVolumes(machines []string) ([]params.VolumeDetailsListResult, error)
+	StoragePlans(tags []names.Tag) ([]params.StoragePlanResult, error)
 }
 
 // generateListOutput returns a map of storage details
@@ -107,6 +130,15 @@ func (c *listCommand) generateListOutput(ctx *cmd.Context, api StorageListAPI) (
 	if err != nil {
 		return nil, err
 	}
+	if c.pending {
+		var pending []params.StorageDetails
+		for _, result := range results {
+			if result.Status.Status == status.Pending {
+				pending = append(pending, result)
+			}
+		}
+		results = pending
+	}
 	if len(results) == 0 {
 		return nil, nil
 	}
@@ -134,6 +166,9 @@ func formatListTabular(writer io.Writer, value interface{}) error {
 	case map[string]VolumeInfo:
 		return formatVolumeListTabular(writer, value)
 
+	case StoragePlansInfo:
+		return formatStoragePlansTabular(writer, value)
+
 	default:
 		return errors.Errorf("unexpected value of type %T", value)
 	}
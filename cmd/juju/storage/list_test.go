@@ -9,6 +9,7 @@ import (
 	"github.com/juju/cmd"
 	jc "github.com/juju/testing/checkers"
 	gc "gopkg.in/check.v1"
+	"gopkg.in/juju/names.v2"
 
 	"github.com/juju/juju/apiserver/params"
 	"github.com/juju/juju/cmd/juju/storage"
@@ -132,6 +133,7 @@ type mockListAPI struct {
 	listErrors      bool
 	listFilesystems func([]string) ([]params.FilesystemDetailsListResult, error)
 	listVolumes     func([]string) ([]params.VolumeDetailsListResult, error)
+	storagePlans    func([]names.Tag) ([]params.StoragePlanResult, error)
 }
 
 func (s mockListAPI) Close() error {
@@ -0,0 +1,179 @@
+// Copyright 2016 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package storage
+
+import (
+	"fmt"
+
+	"github.com/juju/cmd"
+	"github.com/juju/errors"
+	"gopkg.in/juju/names.v2"
+
+	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/status"
+)
+
+// StoragePlansInfo holds the plans for provisioning pending volumes
+// and filesystems, keyed by volume and filesystem ID respectively.
+type StoragePlansInfo struct {
+	Volumes     map[string]StoragePlanInfo `yaml:"volumes,omitempty" json:"volumes,omitempty"`
+	Filesystems map[string]StoragePlanInfo `yaml:"filesystems,omitempty" json:"filesystems,omitempty"`
+}
+
+// StoragePlanInfo describes how a pending volume or filesystem will
+// be provisioned.
+type StoragePlanInfo struct {
+	Create   bool   `yaml:"create" json:"create"`
+	Pool     string `yaml:"pool" json:"pool"`
+	Provider string `yaml:"provider" json:"provider"`
+	// Size is the size of the storage, in MiB.
+	Size uint64 `yaml:"size" json:"size"`
+	// Volume is the ID of the volume backing a filesystem, if any.
+	Volume      string                               `yaml:"volume,omitempty" json:"volume,omitempty"`
+	Waiting     string                               `yaml:"waiting,omitempty" json:"waiting,omitempty"`
+	Attachments map[string]StorageAttachmentPlanInfo `yaml:"attachments,omitempty" json:"attachments,omitempty"`
+}
+
+// StorageAttachmentPlanInfo describes how a pending volume or
+// filesystem will be attached to a machine.
+type StorageAttachmentPlanInfo struct {
+	InstanceId string `yaml:"instance-id,omitempty" json:"instance-id,omitempty"`
+	ReadOnly   bool   `yaml:"read-only,omitempty" json:"read-only,omitempty"`
+	WithCreate bool   `yaml:"with-create,omitempty" json:"with-create,omitempty"`
+	Waiting    string `yaml:"waiting,omitempty" json:"waiting,omitempty"`
+}
+
+// generateListPlanOutput returns the plans for provisioning the
+// pending volumes and filesystems.
+func (c *listCommand) generateListPlanOutput(ctx *cmd.Context, api StorageListAPI) (output interface{}, err error) {
+	// With neither --volume nor --filesystem, plan both.
+	listVolumes := c.volume || !c.filesystem
+	listFilesystems := c.filesystem || !c.volume
+
+	var tags []names.Tag
+	if listVolumes {
+		results, err := api.ListVolumes(c.ids)
+		if err != nil {
+			return nil, err
+		}
+		for _, result := range results {
+			if result.Error != nil {
+				fmt.Fprintf(ctx.Stderr, "%v\n", result.Error)
+				continue
+			}
+			for _, details := range result.Result {
+				if details.Status.Status != status.Pending {
+					continue
+				}
+				tag, err := names.ParseVolumeTag(details.VolumeTag)
+				if err != nil {
+					return nil, errors.Trace(err)
+				}
+				tags = append(tags, tag)
+			}
+		}
+	}
+	if listFilesystems {
+		results, err := api.ListFilesystems(c.ids)
+		if err != nil {
+			return nil, err
+		}
+		for _, result := range results {
+			if result.Error != nil {
+				fmt.Fprintf(ctx.Stderr, "%v\n", result.Error)
+				continue
+			}
+			for _, details := range result.Result {
+				if details.Status.Status != status.Pending {
+					continue
+				}
+				tag, err := names.ParseFilesystemTag(details.FilesystemTag)
+				if err != nil {
+					return nil, errors.Trace(err)
+				}
+				tags = append(tags, tag)
+			}
+		}
+	}
+	if len(tags) == 0 {
+		return nil, nil
+	}
+
+	results, err := api.StoragePlans(tags)
+	if err != nil {
+		return nil, err
+	}
+	var plans StoragePlansInfo
+	for _, result := range results {
+		if result.Error != nil {
+			fmt.Fprintf(ctx.Stderr, "%v\n", result.Error)
+			continue
+		}
+		tag, info, err := createStoragePlanInfo(*result.Result)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		switch tag := tag.(type) {
+		case names.VolumeTag:
+			if plans.Volumes == nil {
+				plans.Volumes = make(map[string]StoragePlanInfo)
+			}
+			plans.Volumes[tag.Id()] = info
+		case names.FilesystemTag:
+			if plans.Filesystems == nil {
+				plans.Filesystems = make(map[string]StoragePlanInfo)
+			}
+			plans.Filesystems[tag.Id()] = info
+		default:
+			return nil, errors.Errorf("unexpected plan for %s", names.ReadableString(tag))
+		}
+	}
+	if plans.Volumes == nil && plans.Filesystems == nil {
+		return nil, nil
+	}
+	switch c.out.Name() {
+	case "yaml", "json":
+		output = map[string]StoragePlansInfo{"plans": plans}
+	default:
+		output = plans
+	}
+	return output, nil
+}
+
+func createStoragePlanInfo(plan params.StoragePlan) (names.Tag, StoragePlanInfo, error) {
+	tag, err := names.ParseTag(plan.Tag)
+	if err != nil {
+		return nil, StoragePlanInfo{}, errors.Trace(err)
+	}
+	info := StoragePlanInfo{
+		Create:   plan.Create,
+		Pool:     plan.Pool,
+		Provider: plan.Provider,
+		Size:     plan.Size,
+		Waiting:  plan.Waiting,
+	}
+	if plan.VolumeTag != "" {
+		volumeTag, err := names.ParseVolumeTag(plan.VolumeTag)
+		if err != nil {
+			return nil, StoragePlanInfo{}, errors.Trace(err)
+		}
+		info.Volume = volumeTag.Id()
+	}
+	for _, attachment := range plan.Attachments {
+		machineTag, err := names.ParseMachineTag(attachment.MachineTag)
+		if err != nil {
+			return nil, StoragePlanInfo{}, errors.Trace(err)
+		}
+		if info.Attachments == nil {
+			info.Attachments = make(map[string]StorageAttachmentPlanInfo)
+		}
+		info.Attachments[machineTag.Id()] = StorageAttachmentPlanInfo{
+			InstanceId: attachment.InstanceId,
+			ReadOnly:   attachment.ReadOnly,
+			WithCreate: attachment.WithCreate,
+			Waiting:    attachment.Waiting,
+		}
+	}
+	return tag, info, nil
+}
@@ -0,0 +1,230 @@
+// Copyright 2016 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package storage_test
+
+import (
+	"encoding/json"
+
+	"github.com/juju/errors"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+	"gopkg.in/juju/names.v2"
+	goyaml "gopkg.in/yaml.v2"
+
+	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/cmd/juju/storage"
+	"github.com/juju/juju/status"
+	"github.com/juju/juju/testing"
+)
+
+func (s *ListSuite) TestListPlanRequiresPending(c *gc.C) {
+	_, err := s.runList(c, []string{"--plan"})
+	c.Assert(err, gc.ErrorMatches, "--plan requires --pending")
+}
+
+func (s *ListSuite) TestListPending(c *gc.C) {
+	s.assertValidList(
+		c,
+		[]string{"--pending"},
+		`
+\[Storage\]    
+UNIT         ID           LOCATION  STATUS   MESSAGE  
+transcode/0  db-dir/1000  thither   pending           
+
+`[1:])
+}
+
+func (s *ListSuite) TestVolumeListPending(c *gc.C) {
+	context, err := s.runVolumeList(c, "--pending", "--format", "yaml")
+	c.Assert(err, jc.ErrorIsNil)
+	var result struct {
+		Volumes map[string]storage.VolumeInfo
+	}
+	err = goyaml.Unmarshal([]byte(testing.Stdout(context)), &result)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(result.Volumes, gc.HasLen, 1)
+	c.Assert(result.Volumes["3"].Status.Current, gc.Equals, status.Pending)
+}
+
+func (s *ListSuite) TestFilesystemListPending(c *gc.C) {
+	context, err := s.runFilesystemList(c, "--pending", "--format", "yaml")
+	c.Assert(err, jc.ErrorIsNil)
+	var result struct {
+		Filesystems map[string]storage.FilesystemInfo
+	}
+	err = goyaml.Unmarshal([]byte(testing.Stdout(context)), &result)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(result.Filesystems, gc.HasLen, 1)
+	c.Assert(result.Filesystems["3"].Status.Current, gc.Equals, status.Pending)
+}
+
+func (s *ListSuite) TestListPlanArgs(c *gc.C) {
+	var planned []names.Tag
+	s.mockAPI.storagePlans = func(tags []names.Tag) ([]params.StoragePlanResult, error) {
+		planned = tags
+		return mockListAPI{}.StoragePlans(tags)
+	}
+	_, err := s.runList(c, []string{"--pending", "--plan"})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(planned, jc.DeepEquals, []names.Tag{
+		names.NewVolumeTag("3"),
+		names.NewFilesystemTag("3"),
+	})
+
+	_, err = s.runList(c, []string{"--pending", "--plan", "--volume"})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(planned, jc.DeepEquals, []names.Tag{names.NewVolumeTag("3")})
+
+	_, err = s.runList(c, []string{"--pending", "--plan", "--filesystem"})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(planned, jc.DeepEquals, []names.Tag{names.NewFilesystemTag("3")})
+}
+
+func (s *ListSuite) TestListPlanTabular(c *gc.C) {
+	context, err := s.runList(c, []string{"--pending", "--plan"})
+	c.Assert(err, jc.ErrorIsNil)
+	s.assertUserFacingOutput(c, context, `
+KIND        ID  ACTION         POOL    PROVIDER  SIZE   MACHINE  INSTANCE  WAITING
+volume      3   create+attach  loop    loop      42MiB  1                  machine 1 to be provisioned
+filesystem  3   create         rootfs  rootfs    42MiB                     
+filesystem  3   attach         rootfs  rootfs    42MiB  1        inst-1    filesystem to be created
+`[1:], "")
+}
+
+func (s *ListSuite) TestListPlanYaml(c *gc.C) {
+	s.assertUnmarshalledPlanOutput(c, goyaml.Unmarshal, "--format", "yaml")
+}
+
+func (s *ListSuite) TestListPlanJSON(c *gc.C) {
+	s.assertUnmarshalledPlanOutput(c, json.Unmarshal, "--format", "json")
+}
+
+func (s *ListSuite) TestListPlanWithErrorResults(c *gc.C) {
+	s.mockAPI.storagePlans = func(tags []names.Tag) ([]params.StoragePlanResult, error) {
+		results, _ := mockListAPI{}.StoragePlans(tags)
+		results[1] = params.StoragePlanResult{
+			Error: &params.Error{Message: "bad"},
+		}
+		return results, nil
+	}
+	context, err := s.runList(c, []string{"--pending", "--plan", "--format", "yaml"})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(testing.Stderr(context), gc.Equals, "bad\n")
+	var result struct {
+		Plans storage.StoragePlansInfo
+	}
+	err = goyaml.Unmarshal([]byte(testing.Stdout(context)), &result)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(result.Plans.Volumes, gc.HasLen, 1)
+	c.Assert(result.Plans.Filesystems, gc.HasLen, 0)
+}
+
+func (s *ListSuite) TestListPlanError(c *gc.C) {
+	s.mockAPI.storagePlans = func([]names.Tag) ([]params.StoragePlanResult, error) {
+		return nil, errors.New("just my luck")
+	}
+	context, err := s.runList(c, []string{"--pending", "--plan"})
+	c.Assert(err, gc.ErrorMatches, "just my luck")
+	s.assertUserFacingOutput(c, context, "", "")
+}
+
+func (s *ListSuite) TestListPlanNothingPending(c *gc.C) {
+	s.mockAPI.listVolumes = func([]string) ([]params.VolumeDetailsListResult, error) {
+		return nil, nil
+	}
+	s.mockAPI.listFilesystems = func([]string) ([]params.FilesystemDetailsListResult, error) {
+		return nil, nil
+	}
+	s.mockAPI.storagePlans = func([]names.Tag) ([]params.StoragePlanResult, error) {
+		c.Fatalf("unexpected call to StoragePlans")
+		return nil, nil
+	}
+	context, err := s.runList(c, []string{"--pending", "--plan"})
+	c.Assert(err, jc.ErrorIsNil)
+	s.assertUserFacingOutput(c, context, "", "No storage to display.\n")
+}
+
+func (s *ListSuite) assertUnmarshalledPlanOutput(c *gc.C, unmarshal unmarshaller, args ...string) {
+	context, err := s.runList(c, append([]string{"--pending", "--plan"}, args...))
+	c.Assert(err, jc.ErrorIsNil)
+
+	var result struct {
+		Plans storage.StoragePlansInfo
+	}
+	err = unmarshal([]byte(testing.Stdout(context)), &result)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(result.Plans, jc.DeepEquals, storage.StoragePlansInfo{
+		Volumes: map[string]storage.StoragePlanInfo{
+			"3": {
+				Create:   true,
+				Pool:     "loop",
+				Provider: "loop",
+				Size:     42,
+				Waiting:  "machine 1 to be provisioned",
+				Attachments: map[string]storage.StorageAttachmentPlanInfo{
+					"1": {
+						WithCreate: true,
+						Waiting:    "machine 1 to be provisioned",
+					},
+				},
+			},
+		},
+		Filesystems: map[string]storage.StoragePlanInfo{
+			"3": {
+				Create:   true,
+				Pool:     "rootfs",
+				Provider: "rootfs",
+				Size:     42,
+				Attachments: map[string]storage.StorageAttachmentPlanInfo{
+					"1": {
+						InstanceId: "inst-1",
+						Waiting:    "filesystem to be created",
+					},
+				},
+			},
+		},
+	})
+	c.Assert(testing.Stderr(context), gc.Equals, "")
+}
+
+func (s mockListAPI) StoragePlans(tags []names.Tag) ([]params.StoragePlanResult, error) {
+	if s.storagePlans != nil {
+		return s.storagePlans(tags)
+	}
+	results := make([]params.StoragePlanResult, len(tags))
+	for i, tag := range tags {
+		switch tag.Kind() {
+		case names.VolumeTagKind:
+			results[i].Result = &params.StoragePlan{
+				Tag:      tag.String(),
+				Create:   true,
+				Pool:     "loop",
+				Provider: "loop",
+				Size:     42,
+				Waiting:  "machine 1 to be provisioned",
+				Attachments: []params.StorageAttachmentPlan{{
+					MachineTag: "machine-1",
+					WithCreate: true,
+					Waiting:    "machine 1 to be provisioned",
+				}},
+			}
+		case names.FilesystemTagKind:
+			results[i].Result = &params.StoragePlan{
+				Tag:      tag.String(),
+				Create:   true,
+				Pool:     "rootfs",
+				Provider: "rootfs",
+				Size:     42,
+				Attachments: []params.StorageAttachmentPlan{{
+					MachineTag: "machine-1",
+					InstanceId: "inst-1",
+					Waiting:    "filesystem to be created",
+				}},
+			}
+		default:
+			results[i].Error = &params.Error{Message: "unexpected tag"}
+		}
+	}
+	return results, nil
+}
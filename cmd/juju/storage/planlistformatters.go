@@ -0,0 +1,71 @@
+// Copyright 2016 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package storage
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/dustin/go-humanize"
+
+	"github.com/juju/juju/cmd/output"
+)
+
+// formatStoragePlansTabular returns a tabular summary of the plans for
+// provisioning pending volumes and filesystems. Each volume or filesystem
+// that is created separately from its attachments has a "create" row, and
+// each attachment has an "attach" row, or a "create+attach" row if the
+// storage is created and attached in one operation.
+func formatStoragePlansTabular(writer io.Writer, plans StoragePlansInfo) error {
+	tw := output.TabWriter(writer)
+
+	print := func(values ...string) {
+		fmt.Fprintln(tw, strings.Join(values, "\t"))
+	}
+	print("KIND", "ID", "ACTION", "POOL", "PROVIDER", "SIZE", "MACHINE", "INSTANCE", "WAITING")
+
+	printPlans := func(kind string, plans map[string]StoragePlanInfo) {
+		ids := make([]string, 0, len(plans))
+		for id := range plans {
+			ids = append(ids, id)
+		}
+		sort.Strings(ids)
+		for _, id := range ids {
+			plan := plans[id]
+			var size string
+			if plan.Size > 0 {
+				size = humanize.IBytes(plan.Size * humanize.MiByte)
+			}
+			createWithAttachment := false
+			for _, attachment := range plan.Attachments {
+				createWithAttachment = createWithAttachment || attachment.WithCreate
+			}
+			if plan.Create && !createWithAttachment {
+				print(kind, id, "create", plan.Pool, plan.Provider, size, "", "", plan.Waiting)
+			}
+			machineIds := make([]string, 0, len(plan.Attachments))
+			for machineId := range plan.Attachments {
+				machineIds = append(machineIds, machineId)
+			}
+			sort.Strings(machineIds)
+			for _, machineId := range machineIds {
+				attachment := plan.Attachments[machineId]
+				action := "attach"
+				if attachment.WithCreate {
+					action = "create+attach"
+				}
+				print(
+					kind, id, action, plan.Pool, plan.Provider, size,
+					machineId, attachment.InstanceId, attachment.Waiting,
+				)
+			}
+		}
+	}
+	printPlans("volume", plans.Volumes)
+	printPlans("filesystem", plans.Filesystems)
+
+	return tw.Flush()
+}
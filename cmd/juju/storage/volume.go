@@ -76,6 +76,15 @@ func (c *listCommand) generateListVolumeOutput(ctx *cmd.Context, api StorageList
 		// display individual error
 		fmt.Fprintf(ctx.Stderr, "%v\n", result.Error)
 	}
+	if c.pending {
+		var pending []params.VolumeDetails
+		for _, details := range valid {
+			if details.Status.Status == status.Pending {
+				pending = append(pending, details)
+			}
+		}
+		valid = pending
+	}
 	if len(valid) == 0 {
 		return nil, nil
 	}
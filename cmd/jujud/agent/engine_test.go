@@ -37,6 +37,7 @@ var (
 		"spaces-imported-gate",
 	}
 	aliveModelWorkers = []string{
+		"blob-janitor",
 		"charm-revision-updater",
 		"compute-provisioner",
 		"environ-tracker",
@@ -48,17 +49,23 @@ var (
 		"migration-inactive-flag",
 		"migration-master",
 		"application-scaler",
+		"relation-scope-gc",
+		"resource-sweeper",
 		"space-importer",
 		"state-cleaner",
 		"status-history-pruner",
 		"storage-provisioner",
+		"tools-gc",
+		"tools-mirror",
 		"unit-assigner",
+		"valid-credential-flag",
 	}
 	migratingModelWorkers = []string{
 		"environ-tracker",
 		"migration-fortress",
 		"migration-inactive-flag",
 		"migration-master",
+		"valid-credential-flag",
 	}
 	// ReallyLongTimeout should be long enough for the model-tracker
 	// tests that depend on a hosted model; its backing state is not
@@ -7,8 +7,10 @@ import (
 	"runtime"
 
 	"github.com/juju/errors"
+	"github.com/prometheus/client_golang/prometheus"
 
 	"github.com/juju/juju/agent"
+	"github.com/juju/juju/environs"
 	"github.com/juju/juju/worker"
 	"github.com/juju/juju/worker/dependency"
 	"github.com/juju/juju/worker/introspection"
@@ -36,8 +38,10 @@ func startIntrospection(cfg introspectionConfig) error {
 
 	socketName := "jujud-" + cfg.Agent.CurrentConfig().Tag().String()
 	w, err := cfg.WorkerFunc(introspection.Config{
-		SocketName: socketName,
-		Reporter:   cfg.Engine,
+		SocketName:        socketName,
+		Reporter:          cfg.Engine,
+		PrometheusHandler: prometheus.Handler(),
+		RequestTracers:    providerRequestTracers(),
 	})
 	if err != nil {
 		return errors.Trace(err)
@@ -52,3 +56,37 @@ func startIntrospection(cfg introspectionConfig) error {
 
 	return nil
 }
+
+// providerRequestTracers returns the registered environ providers that
+// trace the requests they make on behalf of machines, keyed by provider
+// type.
+func providerRequestTracers() map[string]introspection.RequestTracer {
+	tracers := make(map[string]introspection.RequestTracer)
+	for _, providerType := range environs.RegisteredProviders() {
+		provider, err := environs.Provider(providerType)
+		if err != nil {
+			continue
+		}
+		if tracer, ok := provider.(environs.RequestTracer); ok {
+			tracers[providerType] = tracer
+		}
+	}
+	return tracers
+}
+
+// defaultPrometheusRegisterer registers Prometheus metrics collectors
+// with the default registry, which is served by the introspection
+// worker.
+type defaultPrometheusRegisterer struct{}
+
+// Register is part of the storageprovisioner.MetricsRegisterer and
+// apiserver.MetricsRegisterer interfaces.
+func (defaultPrometheusRegisterer) Register(c prometheus.Collector) error {
+	return prometheus.Register(c)
+}
+
+// Unregister is part of the storageprovisioner.MetricsRegisterer and
+// apiserver.MetricsRegisterer interfaces.
+func (defaultPrometheusRegisterer) Unregister(c prometheus.Collector) bool {
+	return prometheus.Unregister(c)
+}
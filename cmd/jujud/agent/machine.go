@@ -71,6 +71,7 @@ import (
 	"github.com/juju/juju/watcher"
 	"github.com/juju/juju/worker"
 	"github.com/juju/juju/worker/apicaller"
+	"github.com/juju/juju/worker/blobbackendmigrator"
 	"github.com/juju/juju/worker/certupdater"
 	"github.com/juju/juju/worker/conv2state"
 	"github.com/juju/juju/worker/dblogpruner"
@@ -471,6 +472,7 @@ func (a *MachineAgent) makeEngineCreator(previousAgentVersion version.Number) fu
 			NewDeployContext:     newDeployContext,
 			Clock:                clock.WallClock,
 			ValidateMigration:    a.validateMigration,
+			PrometheusRegisterer: defaultPrometheusRegisterer{},
 		})
 		if err := dependency.Install(engine, manifolds); err != nil {
 			if err := worker.Stop(engine); err != nil {
@@ -974,6 +976,14 @@ func (a *MachineAgent) startStateWorkers(st *state.State) (worker.Worker, error)
 			a.startWorkerAfterUpgrade(singularRunner, "txnpruner", func() (worker.Worker, error) {
 				return txnpruner.New(st, time.Hour*2, clock.WallClock), nil
 			})
+
+			a.startWorkerAfterUpgrade(singularRunner, "blobbackendmigrator", func() (worker.Worker, error) {
+				return blobbackendmigrator.NewWorker(blobbackendmigrator.Config{
+					Migrator: st,
+					Clock:    clock.WallClock,
+					Period:   time.Minute,
+				})
+			})
 		default:
 			return nil, errors.Errorf("unknown job type %q", job)
 		}
@@ -1013,9 +1023,17 @@ func (a *MachineAgent) startModelWorkers(controllerUUID, modelUUID string) (work
 		StatusHistoryPrunerMaxHistoryTime: 336 * time.Hour, // 2 weeks
 		StatusHistoryPrunerMaxHistoryMB:   5120,            // 5G
 		StatusHistoryPrunerInterval:       5 * time.Minute,
+		ToolsGCInterval:                   24 * time.Hour,
+		ToolsGCKeepLatest:                 2,
+		RelationScopeGCInterval:           5 * time.Minute,
+		RelationScopeGCGracePeriod:        time.Hour,
+		BlobJanitorInterval:               time.Hour,
+		ToolsMirrorInterval:               10 * time.Minute,
+		ResourceSweeperInterval:           time.Hour,
 		SpacesImportedGate:                a.discoverSpacesComplete,
 		NewEnvironFunc:                    newEnvirons,
 		NewMigrationMaster:                migrationmaster.NewWorker,
+		PrometheusRegisterer:              defaultPrometheusRegisterer{},
 	})
 	if err := dependency.Install(engine, manifolds); err != nil {
 		if err := worker.Stop(engine); err != nil {
@@ -1102,6 +1120,7 @@ func (a *MachineAgent) newAPIserverWorker(st *state.State, certChanged chan para
 			newAuditEntrySink(st, logDir),
 			auditErrorHandler,
 		),
+		MetricsRegisterer: defaultPrometheusRegisterer{},
 	})
 	if err != nil {
 		return nil, errors.Annotate(err, "cannot start api server worker")
@@ -1328,14 +1347,44 @@ func openState(agentConfig agent.Config, dialOpts mongo.DialOpts) (_ *state.Stat
 	}
 	// Check the machine nonce as provisioned matches the agent.Conf value.
 	if !m.CheckProvisioned(agentConfig.Nonce()) {
-		// The agent is running on a different machine to the one it
-		// should be according to state. It must stop immediately.
-		logger.Errorf("running machine %v agent on inappropriate instance", m)
-		return nil, nil, worker.ErrTerminateAgent
+		if !identifiesAsMachineInstance(st, m) {
+			// The agent is running on a different machine to the one it
+			// should be according to state. It must stop immediately.
+			logger.Errorf("running machine %v agent on inappropriate instance", m)
+			return nil, nil, worker.ErrTerminateAgent
+		}
+		// The nonce has been lost or corrupted, but the environ
+		// vouches for the instance that the agent is running on.
+		logger.Warningf("machine %v nonce mismatch; instance identified by environ", m)
 	}
 	return st, m, nil
 }
 
+// identifiesAsMachineInstance reports whether the model's environ
+// identifies the instance that the agent is running on as the machine's
+// instance. The environ only identifies instances that belong to it,
+// e.g. in the model's Azure subscription and resource group. This allows
+// agents to recover when their nonce has been lost.
+func identifiesAsMachineInstance(st *state.State, m *state.Machine) bool {
+	instId, err := m.InstanceId()
+	if err != nil {
+		return false
+	}
+	env, err := stateenvirons.GetNewEnvironFunc(environs.New)(st)
+	if err != nil {
+		logger.Warningf("cannot identify instance: getting environ from state: %v", err)
+		return false
+	}
+	identity, err := environs.IdentifyInstance(env)
+	if err != nil {
+		if !errors.IsNotSupported(err) {
+			logger.Warningf("cannot identify instance: %v", err)
+		}
+		return false
+	}
+	return identity.InstanceId == instId
+}
+
 func getMachine(st *state.State, tag names.Tag) (*state.Machine, error) {
 	m0, err := st.FindEntity(tag)
 	if err != nil {
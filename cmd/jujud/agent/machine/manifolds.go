@@ -23,6 +23,7 @@ import (
 	"github.com/juju/juju/worker/apiaddressupdater"
 	"github.com/juju/juju/worker/apicaller"
 	"github.com/juju/juju/worker/apiconfigwatcher"
+	"github.com/juju/juju/worker/apirelay"
 	"github.com/juju/juju/worker/authenticationworker"
 	"github.com/juju/juju/worker/dependency"
 	"github.com/juju/juju/worker/deployer"
@@ -126,6 +127,10 @@ type ManifoldsConfig struct {
 	// migration process to check that the agent will be ok when
 	// connected to the new target controller.
 	ValidateMigration func(base.APICaller) error
+
+	// PrometheusRegisterer is used to register the Prometheus
+	// metrics collectors of workers that report metrics.
+	PrometheusRegisterer storageprovisioner.MetricsRegisterer
 }
 
 // Manifolds returns a set of co-configured manifolds covering the
@@ -396,6 +401,15 @@ func Manifolds(config ManifoldsConfig) dependency.Manifolds {
 			APICallerName:    apiCallerName,
 		})),
 
+		// The API relay lets the unit agents deployed by the machine
+		// agent make their API calls over its connection, on behalf of
+		// their units, rather than each opening a connection of its own.
+		apiRelayName: apirelay.Manifold(apirelay.ManifoldConfig{
+			AgentName:     agentName,
+			APICallerName: apiCallerName,
+			NewWorker:     apirelay.NewWorker,
+		}),
+
 		authenticationWorkerName: ifNotMigrating(authenticationworker.Manifold(authenticationworker.ManifoldConfig{
 			AgentName:     agentName,
 			APICallerName: apiCallerName,
@@ -405,9 +419,10 @@ func Manifolds(config ManifoldsConfig) dependency.Manifolds {
 		// (deprovisioning), and attachment (detachment) of first-class
 		// volumes and filesystems.
 		storageProvisionerName: ifNotMigrating(storageprovisioner.MachineManifold(storageprovisioner.MachineManifoldConfig{
-			AgentName:     agentName,
-			APICallerName: apiCallerName,
-			Clock:         config.Clock,
+			AgentName:         agentName,
+			APICallerName:     apiCallerName,
+			Clock:             config.Clock,
+			MetricsRegisterer: config.PrometheusRegisterer,
 		})),
 
 		resumerName: ifNotMigrating(resumer.Manifold(resumer.ManifoldConfig{
@@ -498,6 +513,7 @@ const (
 	machinerName             = "machiner"
 	logSenderName            = "log-sender"
 	deployerName             = "unit-agent-deployer"
+	apiRelayName             = "api-relay"
 	authenticationWorkerName = "ssh-authkeys-updater"
 	storageProvisionerName   = "storage-provisioner"
 	resumerName              = "mgo-txn-resumer"
@@ -46,6 +46,7 @@ func (*ManifoldsSuite) TestManifoldNames(c *gc.C) {
 		"api-address-updater",
 		"api-caller",
 		"api-config-watcher",
+		"api-relay",
 		"disk-manager",
 		"host-key-reporter",
 		"log-forwarder",
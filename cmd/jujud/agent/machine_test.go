@@ -328,6 +328,7 @@ func (s *MachineSuite) TestManageModel(c *gc.C) {
 	c.Logf("started test agent, waiting for workers...")
 	r0 := s.singularRecord.nextRunner(c)
 	r0.waitForWorker(c, "txnpruner")
+	r0.waitForWorker(c, "blobbackendmigrator")
 
 	// Check that the provisioner and firewaller are alive by doing
 	// a rudimentary check that it responds to state changes.
@@ -887,7 +888,11 @@ func (s *MachineSuite) TestMachineAgentRunsAPIAddressUpdaterWorker(c *gc.C) {
 func (s *MachineSuite) TestMachineAgentRunsDiskManagerWorker(c *gc.C) {
 	// Patch out the worker func before starting the agent.
 	started := newSignal()
-	newWorker := func(diskmanager.ListBlockDevicesFunc, diskmanager.BlockDeviceSetter) worker.Worker {
+	newWorker := func(
+		diskmanager.ListBlockDevicesFunc,
+		diskmanager.BlockDeviceSetter,
+		diskmanager.WatchBlockDevicesFunc,
+	) worker.Worker {
 		started.trigger()
 		return worker.NewNoOpWorker()
 	}
@@ -20,9 +20,11 @@ import (
 	"github.com/juju/juju/worker/apicaller"
 	"github.com/juju/juju/worker/apiconfigwatcher"
 	"github.com/juju/juju/worker/applicationscaler"
+	"github.com/juju/juju/worker/blobjanitor"
 	"github.com/juju/juju/worker/charmrevision"
 	"github.com/juju/juju/worker/charmrevision/charmrevisionmanifold"
 	"github.com/juju/juju/worker/cleaner"
+	"github.com/juju/juju/worker/credentialflag"
 	"github.com/juju/juju/worker/dependency"
 	"github.com/juju/juju/worker/discoverspaces"
 	"github.com/juju/juju/worker/environ"
@@ -36,9 +38,13 @@ import (
 	"github.com/juju/juju/worker/migrationflag"
 	"github.com/juju/juju/worker/migrationmaster"
 	"github.com/juju/juju/worker/provisioner"
+	"github.com/juju/juju/worker/relationscopegc"
+	"github.com/juju/juju/worker/resourcesweeper"
 	"github.com/juju/juju/worker/singular"
 	"github.com/juju/juju/worker/statushistorypruner"
 	"github.com/juju/juju/worker/storageprovisioner"
+	"github.com/juju/juju/worker/toolsgc"
+	"github.com/juju/juju/worker/toolsmirror"
 	"github.com/juju/juju/worker/undertaker"
 	"github.com/juju/juju/worker/unitassigner"
 )
@@ -84,6 +90,28 @@ type ManifoldsConfig struct {
 	StatusHistoryPrunerMaxHistoryMB   uint
 	StatusHistoryPrunerInterval       time.Duration
 
+	// ToolsGC* values control the removal of unused agent binaries
+	// from the model's tools storage.
+	ToolsGCInterval   time.Duration
+	ToolsGCKeepLatest int
+
+	// RelationScopeGC* values control the removal of relation scope
+	// memberships left behind by units that will never leave them.
+	RelationScopeGCInterval    time.Duration
+	RelationScopeGCGracePeriod time.Duration
+
+	// BlobJanitorInterval is the time between enforcements of the
+	// lifecycle policies of the model's blob buckets.
+	BlobJanitorInterval time.Duration
+
+	// ToolsMirrorInterval is the time between updates of the
+	// metadata describing the model's tools mirror.
+	ToolsMirrorInterval time.Duration
+
+	// ResourceSweeperInterval is the time between sweeps for cloud
+	// resources left behind by the model's removed machines.
+	ResourceSweeperInterval time.Duration
+
 	// SpacesImportedGate will be unlocked when spaces are known to
 	// have been imported.
 	SpacesImportedGate gate.Lock
@@ -95,6 +123,10 @@ type ManifoldsConfig struct {
 	// NewMigrationMaster is called to create a new migrationmaster
 	// worker.
 	NewMigrationMaster func(migrationmaster.Config) (worker.Worker, error)
+
+	// PrometheusRegisterer is used to register the Prometheus
+	// metrics collectors of workers that report metrics.
+	PrometheusRegisterer storageprovisioner.MetricsRegisterer
 }
 
 // Manifolds returns a set of interdependent dependency manifolds that will
@@ -216,6 +248,16 @@ func Manifolds(config ManifoldsConfig) dependency.Manifolds {
 			NewEnvironFunc: config.NewEnvironFunc,
 		})),
 
+		// The valid-credential flag is set unless the cloud has
+		// rejected the model's cloud credential, in which case the
+		// workers that act on the cloud are stopped until the
+		// credential is updated.
+		validCredentialFlagName: ifNotDead(credentialflag.Manifold(credentialflag.ManifoldConfig{
+			APICallerName: apiCallerName,
+			NewFacade:     credentialflag.NewFacade,
+			NewWorker:     credentialflag.NewWorker,
+		})),
+
 		// The undertaker is currently the only ifNotAlive worker.
 		undertakerName: ifNotAlive(undertaker.Manifold(undertaker.ManifoldConfig{
 			APICallerName: apiCallerName,
@@ -225,7 +267,8 @@ func Manifolds(config ManifoldsConfig) dependency.Manifolds {
 			NewWorker: undertaker.NewWorker,
 		})),
 
-		// All the rest depend on ifNotMigrating.
+		// All the rest depend on ifNotMigrating; the provisioners
+		// depend on ifCredentialValid, which implies it.
 		spaceImporterName: ifNotMigrating(discoverspaces.Manifold(discoverspaces.ManifoldConfig{
 			EnvironName:   environTrackerName,
 			APICallerName: apiCallerName,
@@ -234,17 +277,18 @@ func Manifolds(config ManifoldsConfig) dependency.Manifolds {
 			NewFacade: discoverspaces.NewFacade,
 			NewWorker: discoverspaces.NewWorker,
 		})),
-		computeProvisionerName: ifNotMigrating(provisioner.Manifold(provisioner.ManifoldConfig{
+		computeProvisionerName: ifCredentialValid(provisioner.Manifold(provisioner.ManifoldConfig{
 			AgentName:          agentName,
 			APICallerName:      apiCallerName,
 			EnvironName:        environTrackerName,
 			NewProvisionerFunc: provisioner.NewEnvironProvisioner,
 		})),
-		storageProvisionerName: ifNotMigrating(storageprovisioner.ModelManifold(storageprovisioner.ModelManifoldConfig{
-			APICallerName: apiCallerName,
-			ClockName:     clockName,
-			EnvironName:   environTrackerName,
-			Scope:         modelTag,
+		storageProvisionerName: ifCredentialValid(storageprovisioner.ModelManifold(storageprovisioner.ModelManifoldConfig{
+			APICallerName:     apiCallerName,
+			ClockName:         clockName,
+			EnvironName:       environTrackerName,
+			Scope:             modelTag,
+			MetricsRegisterer: config.PrometheusRegisterer,
 		})),
 		firewallerName: ifNotMigrating(firewaller.Manifold(firewaller.ManifoldConfig{
 			APICallerName: apiCallerName,
@@ -285,6 +329,45 @@ func Manifolds(config ManifoldsConfig) dependency.Manifolds {
 			// TODO(fwereade): 2016-03-17 lp:1558657
 			NewTimer: worker.NewTimer,
 		})),
+		toolsGCName: ifNotMigrating(toolsgc.Manifold(toolsgc.ManifoldConfig{
+			APICallerName: apiCallerName,
+			ClockName:     clockName,
+			Period:        config.ToolsGCInterval,
+			KeepLatest:    config.ToolsGCKeepLatest,
+			NewFacade:     toolsgc.NewAPIFacade,
+			NewWorker:     toolsgc.NewWorker,
+		})),
+		relationScopeGCName: ifNotMigrating(relationscopegc.Manifold(relationscopegc.ManifoldConfig{
+			APICallerName: apiCallerName,
+			ClockName:     clockName,
+			Period:        config.RelationScopeGCInterval,
+			GracePeriod:   config.RelationScopeGCGracePeriod,
+			NewFacade:     relationscopegc.NewAPIFacade,
+			NewWorker:     relationscopegc.NewWorker,
+		})),
+		blobJanitorName: ifNotMigrating(blobjanitor.Manifold(blobjanitor.ManifoldConfig{
+			APICallerName:     apiCallerName,
+			ClockName:         clockName,
+			Period:            config.BlobJanitorInterval,
+			ModelTag:          modelTag,
+			MetricsRegisterer: config.PrometheusRegisterer,
+			NewFacade:         blobjanitor.NewAPIFacade,
+			NewWorker:         blobjanitor.NewWorker,
+		})),
+		toolsMirrorName: ifNotMigrating(toolsmirror.Manifold(toolsmirror.ManifoldConfig{
+			APICallerName: apiCallerName,
+			ClockName:     clockName,
+			Period:        config.ToolsMirrorInterval,
+			NewFacade:     toolsmirror.NewAPIFacade,
+			NewWorker:     toolsmirror.NewWorker,
+		})),
+		resourceSweeperName: ifNotMigrating(resourcesweeper.Manifold(resourcesweeper.ManifoldConfig{
+			APICallerName: apiCallerName,
+			ClockName:     clockName,
+			Period:        config.ResourceSweeperInterval,
+			NewFacade:     resourcesweeper.NewAPIFacade,
+			NewWorker:     resourcesweeper.NewWorker,
+		})),
 		machineUndertakerName: ifNotMigrating(machineundertaker.Manifold(machineundertaker.ManifoldConfig{
 			APICallerName: apiCallerName,
 			EnvironName:   environTrackerName,
@@ -352,6 +435,16 @@ var (
 		},
 		Occupy: migrationFortressName,
 	}.Decorate
+
+	// ifCredentialValid wraps a manifold such that it only runs if
+	// ifNotMigrating would, and the valid-credential flag is set.
+	ifCredentialValid = engine.Housing{
+		Flags: []string{
+			migrationInactiveFlagName,
+			validCredentialFlagName,
+		},
+		Occupy: migrationFortressName,
+	}.Decorate
 )
 
 const (
@@ -365,6 +458,8 @@ const (
 	notDeadFlagName        = "not-dead-flag"
 	notAliveFlagName       = "not-alive-flag"
 
+	validCredentialFlagName = "valid-credential-flag"
+
 	migrationFortressName     = "migration-fortress"
 	migrationInactiveFlagName = "migration-inactive-flag"
 	migrationMasterName       = "migration-master"
@@ -382,5 +477,10 @@ const (
 	metricWorkerName         = "metric-worker"
 	stateCleanerName         = "state-cleaner"
 	statusHistoryPrunerName  = "status-history-pruner"
+	toolsGCName              = "tools-gc"
+	relationScopeGCName      = "relation-scope-gc"
+	blobJanitorName          = "blob-janitor"
+	toolsMirrorName          = "tools-mirror"
+	resourceSweeperName      = "resource-sweeper"
 	machineUndertakerName    = "machine-undertaker"
 )
@@ -35,6 +35,7 @@ func (s *ManifoldsSuite) TestNames(c *gc.C) {
 		"api-caller",
 		"api-config-watcher",
 		"application-scaler",
+		"blob-janitor",
 		"charm-revision-updater",
 		"clock",
 		"compute-provisioner",
@@ -49,13 +50,18 @@ func (s *ManifoldsSuite) TestNames(c *gc.C) {
 		"migration-master",
 		"not-alive-flag",
 		"not-dead-flag",
+		"relation-scope-gc",
+		"resource-sweeper",
 		"space-importer",
 		"spaces-imported-gate",
 		"state-cleaner",
 		"status-history-pruner",
 		"storage-provisioner",
+		"tools-gc",
+		"tools-mirror",
 		"undertaker",
 		"unit-assigner",
+		"valid-credential-flag",
 	})
 }
 
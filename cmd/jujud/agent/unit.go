@@ -18,12 +18,14 @@ import (
 	"gopkg.in/tomb.v1"
 
 	"github.com/juju/juju/agent"
+	"github.com/juju/juju/api"
 	"github.com/juju/juju/api/base"
 	"github.com/juju/juju/api/uniter"
 	"github.com/juju/juju/cmd/jujud/agent/unit"
 	cmdutil "github.com/juju/juju/cmd/jujud/util"
 	jujuversion "github.com/juju/juju/version"
 	"github.com/juju/juju/worker"
+	"github.com/juju/juju/worker/apirelay"
 	"github.com/juju/juju/worker/dependency"
 	"github.com/juju/juju/worker/introspection"
 	"github.com/juju/juju/worker/logsender"
@@ -143,6 +145,10 @@ func (a *UnitAgent) APIWorkers() (worker.Worker, error) {
 		LeadershipGuarantee: 30 * time.Second,
 		AgentConfigChanged:  a.configChangedVal,
 		ValidateMigration:   a.validateMigration,
+		APIOpen: apirelay.NewOpenFunc(
+			apirelay.SocketPath(a.CurrentConfig().DataDir()),
+			api.Open,
+		),
 	})
 
 	config := dependency.EngineConfig{
@@ -58,6 +58,9 @@ type ManifoldsConfig struct {
 	// migration process to check that the agent will be ok when
 	// connected to the new target controller.
 	ValidateMigration func(base.APICaller) error
+
+	// APIOpen is used to open the unit agent's API connection.
+	APIOpen api.OpenFunc
 }
 
 // Manifolds returns a set of co-configured manifolds covering the various
@@ -100,11 +103,13 @@ func Manifolds(config ManifoldsConfig) dependency.Manifolds {
 		// to some API server. It's used by many other manifolds, which all
 		// select their own desired facades. It will be interesting to see
 		// how this works when we consolidate the agents; might be best to
-		// handle the auth changes server-side..?
+		// handle the auth changes server-side..? The connection is opened
+		// with config.APIOpen, which connects through the machine agent's
+		// API relay when it can.
 		apiCallerName: apicaller.Manifold(apicaller.ManifoldConfig{
 			AgentName:            agentName,
 			APIConfigWatcherName: apiConfigWatcherName,
-			APIOpen:              api.Open,
+			APIOpen:              config.APIOpen,
 			NewConnection:        apicaller.ScaryConnect,
 			Filter:               connectFilter,
 		}),
@@ -173,6 +173,16 @@ func (c *BootstrapCommand) Run(_ *cmd.Context) error {
 		}
 	}
 
+	if args.BootstrapMachineInstanceId == "" {
+		// The bootstrap machine's instance ID was not passed in,
+		// so the environ must be able to tell us what it is.
+		identity, err := environs.IdentifyInstance(env)
+		if err != nil {
+			return errors.Annotate(err, "identifying bootstrap instance")
+		}
+		logger.Infof("identified bootstrap instance as %q", identity.InstanceId)
+		args.BootstrapMachineInstanceId = identity.InstanceId
+	}
 	instances, err := env.Instances([]instance.Id{args.BootstrapMachineInstanceId})
 	if err != nil {
 		return errors.Annotate(err, "getting bootstrap instance")
@@ -58,6 +58,7 @@ func NewSuperCommand() cmd.Command {
 	if featureflag.Enabled(feature.ImageMetadata) {
 		metadatacmd.Register(newListImagesCommand())
 		metadatacmd.Register(newAddImageMetadataCommand())
+		metadatacmd.Register(newSetImageMetadataCommand())
 		metadatacmd.Register(newDeleteImageMetadataCommand())
 	}
 	return metadatacmd
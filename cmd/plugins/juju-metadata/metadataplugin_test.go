@@ -36,6 +36,7 @@ var metadataCommandNames = []string{
 	"generate-tools",
 	"help",
 	"list-images",
+	"set-image",
 	"sign",
 	"validate-images",
 	"validate-tools",
@@ -83,7 +84,7 @@ func (s *MetadataSuite) TestHelpCommands(c *gc.C) {
 
 	// Remove add/list-image for the first test because the feature is not
 	// enabled by default.
-	devFeatures := set.NewStrings("add-image", "list-images", "delete-image")
+	devFeatures := set.NewStrings("add-image", "list-images", "delete-image", "set-image")
 
 	// Remove features behind dev_flag for the first test since they are not
 	// enabled.
@@ -128,6 +129,11 @@ func (s *MetadataSuite) TestHelpAddImage(c *gc.C) {
 	s.assertHelpOutput(c, "add-image")
 }
 
+func (s *MetadataSuite) TestHelpSetImage(c *gc.C) {
+	s.SetFeatureFlags(feature.ImageMetadata)
+	s.assertHelpOutput(c, "set-image")
+}
+
 func (s *MetadataSuite) TestHelpDeleteImage(c *gc.C) {
 	s.SetFeatureFlags(feature.ImageMetadata)
 	s.assertHelpOutput(c, "delete-image")
@@ -0,0 +1,107 @@
+// Copyright 2016 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package main
+
+import (
+	"github.com/juju/cmd"
+	"github.com/juju/errors"
+
+	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/cmd/modelcmd"
+)
+
+func newSetImageMetadataCommand() cmd.Command {
+	return modelcmd.Wrap(&setImageMetadataCommand{})
+}
+
+const setImageCommandDoc = `
+Set image metadata for a Juju model.
+
+Image metadata set with this command is stored in the controller, and is
+specific to the model. When provisioning machines in the model, it is used
+in preference to image metadata shared by all models, including that found
+in simplestreams. This allows images to be registered for clouds that have
+no access to published image metadata, without serving simplestreams.
+
+Image metadata properties vary between providers. Consequently, some properties
+are optional for this command but they may still be needed by your provider.
+
+This command takes only one positional argument - an image id.
+
+arguments:
+image-id
+   image identifier
+
+options:
+-m, --model (= "")
+   juju model to operate in
+--region
+   cloud region (= region of current model)
+--series (= current model preferred series)
+   image series
+--arch (= "amd64")
+   image architectures
+--virt-type
+   virtualisation type [provider specific], e.g. hmv
+--storage-type
+   root storage type [provider specific], e.g. ebs
+--storage-size
+   root storage size [provider specific]
+--stream (= "released")
+   image stream
+
+`
+
+// setImageMetadataCommand stores image metadata specific to a Juju model.
+type setImageMetadataCommand struct {
+	addImageMetadataCommand
+}
+
+// Init implements Command.Init.
+func (c *setImageMetadataCommand) Init(args []string) (err error) {
+	if len(args) == 0 {
+		return errors.New("image id must be supplied when setting image metadata")
+	}
+	if len(args) != 1 {
+		return errors.New("only one image id can be supplied as an argument to this command")
+	}
+	c.ImageId = args[0]
+	return c.validate()
+}
+
+// Info implements Command.Info.
+func (c *setImageMetadataCommand) Info() *cmd.Info {
+	return &cmd.Info{
+		Name:    "set-image",
+		Purpose: "sets image metadata specific to model",
+		Doc:     setImageCommandDoc,
+	}
+}
+
+// Run implements Command.Run.
+func (c *setImageMetadataCommand) Run(ctx *cmd.Context) (err error) {
+	api, err := getImageMetadataSetAPI(c)
+	if err != nil {
+		return err
+	}
+	defer api.Close()
+
+	m := c.constructMetadataParam()
+	if err := api.SetModelImages([]params.CloudImageMetadata{m}); err != nil {
+		return errors.Trace(err)
+	}
+	return nil
+}
+
+// MetadataSetAPI defines the API methods that set image metadata command uses.
+type MetadataSetAPI interface {
+	Close() error
+	SetModelImages(metadata []params.CloudImageMetadata) error
+}
+
+var getImageMetadataSetAPI = (*setImageMetadataCommand).getImageMetadataSetAPI
+
+func (c *setImageMetadataCommand) getImageMetadataSetAPI() (MetadataSetAPI, error) {
+	return c.NewImageMetadataAPI()
+}
@@ -0,0 +1,92 @@
+// Copyright 2016 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package main
+
+import (
+	"fmt"
+
+	"github.com/juju/cmd"
+	"github.com/juju/errors"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/testing"
+)
+
+type setImageSuite struct {
+	BaseCloudImageMetadataSuite
+
+	data []params.CloudImageMetadata
+
+	mockAPI *mockSetAPI
+}
+
+var _ = gc.Suite(&setImageSuite{})
+
+func (s *setImageSuite) SetUpTest(c *gc.C) {
+	s.BaseCloudImageMetadataSuite.SetUpTest(c)
+
+	s.data = emptyMetadata
+
+	s.mockAPI = &mockSetAPI{}
+	s.mockAPI.set = func(metadata []params.CloudImageMetadata) error {
+		s.data = append(s.data, metadata...)
+		return nil
+	}
+	s.PatchValue(&getImageMetadataSetAPI, func(c *setImageMetadataCommand) (MetadataSetAPI, error) {
+		return s.mockAPI, nil
+	})
+}
+
+func (s *setImageSuite) TestSetImageMetadata(c *gc.C) {
+	m := constructTestImageMetadata()
+	m.Region = "region"
+	m.VirtType = "vType"
+	m.RootStorageType = "sType"
+	m.Stream = "streamV"
+
+	_, err := runSetImageMetadata(c, getAddImageMetadataCmdFlags(c, m)...)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(s.data, gc.DeepEquals, []params.CloudImageMetadata{m})
+}
+
+func (s *setImageSuite) TestSetImageMetadataFailed(c *gc.C) {
+	msg := "failed"
+	s.mockAPI.set = func(metadata []params.CloudImageMetadata) error {
+		return errors.New(msg)
+	}
+
+	s.assertSetImageMetadataErr(c, constructTestImageMetadata(), msg)
+}
+
+func (s *setImageSuite) TestSetImageMetadataNoImageId(c *gc.C) {
+	m := constructTestImageMetadata()
+	m.ImageId = ""
+
+	s.assertSetImageMetadataErr(c, m, "image id must be supplied when setting image metadata")
+}
+
+func runSetImageMetadata(c *gc.C, args ...string) (*cmd.Context, error) {
+	return testing.RunCommand(c, newSetImageMetadataCommand(), args...)
+}
+
+func (s *setImageSuite) assertSetImageMetadataErr(c *gc.C, m params.CloudImageMetadata, msg string) {
+	args := getAddImageMetadataCmdFlags(c, m)
+	_, err := runSetImageMetadata(c, args...)
+	c.Assert(err, gc.ErrorMatches, fmt.Sprintf(".*%v.*", msg))
+	c.Assert(s.data, gc.DeepEquals, emptyMetadata)
+}
+
+type mockSetAPI struct {
+	set func(metadata []params.CloudImageMetadata) error
+}
+
+func (s mockSetAPI) Close() error {
+	return nil
+}
+
+func (s mockSetAPI) SetModelImages(metadata []params.CloudImageMetadata) error {
+	return s.set(metadata)
+}
@@ -23,15 +23,16 @@ const (
 	Arch      = "arch"
 	Container = "container"
 	// cpuCores is an alias for Cores.
-	cpuCores     = "cpu-cores"
-	Cores        = "cores"
-	CpuPower     = "cpu-power"
-	Mem          = "mem"
-	RootDisk     = "root-disk"
-	Tags         = "tags"
-	InstanceType = "instance-type"
-	Spaces       = "spaces"
-	VirtType     = "virt-type"
+	cpuCores      = "cpu-cores"
+	Cores         = "cores"
+	CpuPower      = "cpu-power"
+	Mem           = "mem"
+	RootDisk      = "root-disk"
+	Tags          = "tags"
+	InstanceType  = "instance-type"
+	Spaces        = "spaces"
+	VirtType      = "virt-type"
+	MaxHourlyCost = "max-hourly-cost"
 )
 
 // Value describes a user's requirements of the hardware on which units
@@ -85,6 +86,13 @@ type Value struct {
 	// VirtType, if not nil or empty, indicates that a machine must run the named
 	// virtual type. Only valid for clouds with multi-hypervisor support.
 	VirtType *string `json:"virt-type,omitempty" yaml:"virt-type,omitempty"`
+
+	// MaxHourlyCost, if not nil, indicates that a machine must not cost
+	// more than that amount per hour to run. The amount is expressed in
+	// the units of the cloud's instance type cost data, i.e. thousandths
+	// of a US dollar for clouds with published prices. Only valid for
+	// clouds which support instance types.
+	MaxHourlyCost *uint64 `json:"max-hourly-cost,omitempty" yaml:"max-hourly-cost,omitempty"`
 }
 
 var rawAliases = map[string]string{
@@ -162,6 +170,12 @@ func (v *Value) HasVirtType() bool {
 	return v.VirtType != nil && *v.VirtType != ""
 }
 
+// HasMaxHourlyCost returns true if the constraints.Value specifies a
+// maximum hourly cost.
+func (v *Value) HasMaxHourlyCost() bool {
+	return v.MaxHourlyCost != nil && *v.MaxHourlyCost > 0
+}
+
 // String expresses a constraints.Value in the language in which it was specified.
 func (v Value) String() string {
 	var strs []string
@@ -205,6 +219,9 @@ func (v Value) String() string {
 	if v.VirtType != nil {
 		strs = append(strs, "virt-type="+string(*v.VirtType))
 	}
+	if v.MaxHourlyCost != nil {
+		strs = append(strs, "max-hourly-cost="+uintStr(*v.MaxHourlyCost))
+	}
 	return strings.Join(strs, " ")
 }
 
@@ -246,6 +263,9 @@ func (v Value) GoString() string {
 	if v.VirtType != nil {
 		values = append(values, fmt.Sprintf("VirtType: %q", *v.VirtType))
 	}
+	if v.MaxHourlyCost != nil {
+		values = append(values, fmt.Sprintf("MaxHourlyCost: %v", *v.MaxHourlyCost))
+	}
 	return fmt.Sprintf("{%s}", strings.Join(values, ", "))
 }
 
@@ -402,6 +422,8 @@ func (v *Value) setRaw(name, str string) error {
 		err = v.setSpaces(str)
 	case VirtType:
 		err = v.setVirtType(str)
+	case MaxHourlyCost:
+		err = v.setMaxHourlyCost(str)
 	default:
 		return errors.Errorf("unknown constraint %q", name)
 	}
@@ -465,6 +487,8 @@ func (v *Value) UnmarshalYAML(unmarshal func(interface{}) error) error {
 			}
 		case VirtType:
 			v.VirtType = &vstr
+		case MaxHourlyCost:
+			v.MaxHourlyCost, err = parseUint64(vstr)
 		default:
 			return errors.Errorf("unknown constraint value: %v", k)
 		}
@@ -589,6 +613,14 @@ func (v *Value) setVirtType(str string) error {
 	return nil
 }
 
+func (v *Value) setMaxHourlyCost(str string) (err error) {
+	if v.MaxHourlyCost != nil {
+		return errors.Errorf("already set")
+	}
+	v.MaxHourlyCost, err = parseUint64(str)
+	return
+}
+
 func parseUint64(str string) (*uint64, error) {
 	var value uint64
 	if str != "" {
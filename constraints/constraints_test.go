@@ -313,6 +313,31 @@ var parseConstraintsTests = []struct {
 		err:     `bad "virt-type" constraint: already set`,
 	},
 
+	// "max-hourly-cost" in detail.
+	{
+		summary: "set max-hourly-cost empty",
+		args:    []string{"max-hourly-cost="},
+	}, {
+		summary: "set max-hourly-cost",
+		args:    []string{"max-hourly-cost=250"},
+	}, {
+		summary: "set nonsense max-hourly-cost",
+		args:    []string{"max-hourly-cost=cheap"},
+		err:     `bad "max-hourly-cost" constraint: must be a non-negative integer`,
+	}, {
+		summary: "set negative max-hourly-cost",
+		args:    []string{"max-hourly-cost=-1"},
+		err:     `bad "max-hourly-cost" constraint: must be a non-negative integer`,
+	}, {
+		summary: "double set max-hourly-cost together",
+		args:    []string{"max-hourly-cost=100 max-hourly-cost=200"},
+		err:     `bad "max-hourly-cost" constraint: already set`,
+	}, {
+		summary: "double set max-hourly-cost separately",
+		args:    []string{"max-hourly-cost=100", "max-hourly-cost="},
+		err:     `bad "max-hourly-cost" constraint: already set`,
+	},
+
 	// Everything at once.
 	{
 		summary: "kitchen sink together",
@@ -510,6 +535,9 @@ var constraintsRoundtripTests = []roundTrip{
 	{"Spaces3", constraints.Value{Spaces: &[]string{"space1", "^space2"}}},
 	{"InstanceType1", constraints.Value{InstanceType: strp("")}},
 	{"InstanceType2", constraints.Value{InstanceType: strp("foo")}},
+	{"MaxHourlyCost1", constraints.Value{MaxHourlyCost: nil}},
+	{"MaxHourlyCost2", constraints.Value{MaxHourlyCost: uint64p(0)}},
+	{"MaxHourlyCost3", constraints.Value{MaxHourlyCost: uint64p(250)}},
 	{"All", constraints.Value{
 		Arch:         strp("i386"),
 		Container:    ctypep("lxd"),
@@ -597,6 +625,15 @@ func (s *ConstraintsSuite) TestHasInstanceType(c *gc.C) {
 	c.Check(cons.HasInstanceType(), jc.IsTrue)
 }
 
+func (s *ConstraintsSuite) TestHasMaxHourlyCost(c *gc.C) {
+	cons := constraints.MustParse("arch=amd64")
+	c.Check(cons.HasMaxHourlyCost(), jc.IsFalse)
+	cons = constraints.MustParse("max-hourly-cost=")
+	c.Check(cons.HasMaxHourlyCost(), jc.IsFalse)
+	cons = constraints.MustParse("max-hourly-cost=100")
+	c.Check(cons.HasMaxHourlyCost(), jc.IsTrue)
+}
+
 const initialWithoutCons = "root-disk=8G mem=4G arch=amd64 cpu-power=1000 cores=4 spaces=space1,^space2 tags=foo container=lxd instance-type=bar"
 
 var withoutTests = []struct {
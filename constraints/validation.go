@@ -46,6 +46,18 @@ type Validator interface {
 	//     and new values are {c, d},
 	//     then the merge result would be {a, b, c, d}.
 	UpdateVocabulary(attributeName string, newValues interface{})
+
+	// Vocabulary returns the allowed values for each constraint
+	// attribute with a registered vocabulary.
+	Vocabulary() map[string][]interface{}
+
+	// Conflicts returns, for each constraint attribute that conflicts
+	// with others, the sorted names of the conflicting attributes.
+	Conflicts() map[string][]string
+
+	// Unsupported returns the sorted names of the unsupported
+	// constraint attributes.
+	Unsupported() []string
 }
 
 // NewValidator returns a new constraints Validator instance.
@@ -135,6 +147,29 @@ func (v *validator) updateVocabularyFromMap(attributeName string, valuesMap map[
 	v.RegisterVocabulary(attributeName, merged)
 }
 
+// Vocabulary is defined on Validator.
+func (v *validator) Vocabulary() map[string][]interface{} {
+	vocab := make(map[string][]interface{})
+	for attr, values := range v.vocab {
+		vocab[attr] = append([]interface{}(nil), values...)
+	}
+	return vocab
+}
+
+// Conflicts is defined on Validator.
+func (v *validator) Conflicts() map[string][]string {
+	conflicts := make(map[string][]string)
+	for attr, attrConflicts := range v.conflicts {
+		conflicts[attr] = attrConflicts.SortedValues()
+	}
+	return conflicts
+}
+
+// Unsupported is defined on Validator.
+func (v *validator) Unsupported() []string {
+	return v.unsupported.SortedValues()
+}
+
 // checkConflicts returns an error if the constraints Value contains conflicting attributes.
 func (v *validator) checkConflicts(cons Value) error {
 	attrValues := cons.attributesWithValues()
@@ -387,3 +387,31 @@ valid values are: [amd64]`))
 	_, err = validator.Validate(cons2)
 	c.Assert(err, jc.ErrorIsNil)
 }
+
+func (s *validationSuite) TestVocabulary(c *gc.C) {
+	validator := constraints.NewValidator()
+	c.Assert(validator.Vocabulary(), gc.HasLen, 0)
+	c.Assert(validator.Conflicts(), gc.HasLen, 0)
+	c.Assert(validator.Unsupported(), gc.HasLen, 0)
+
+	validator.RegisterVocabulary("arch", []string{"amd64", "arm64"})
+	validator.RegisterVocabulary("instance-type", []string{"Standard_D1"})
+	validator.RegisterConflicts([]string{"instance-type"}, []string{"mem", "arch"})
+	validator.RegisterUnsupported([]string{"tags", "cpu-power"})
+
+	vocab := validator.Vocabulary()
+	c.Assert(vocab, jc.DeepEquals, map[string][]interface{}{
+		"arch":          {"amd64", "arm64"},
+		"instance-type": {"Standard_D1"},
+	})
+	c.Assert(validator.Conflicts(), jc.DeepEquals, map[string][]string{
+		"instance-type": {"arch", "mem"},
+		"mem":           {"instance-type"},
+		"arch":          {"instance-type"},
+	})
+	c.Assert(validator.Unsupported(), jc.DeepEquals, []string{"cpu-power", "tags"})
+
+	// The returned vocabulary is a copy.
+	vocab["arch"][0] = "ppc64el"
+	c.Assert(validator.Vocabulary()["arch"], jc.DeepEquals, []interface{}{"amd64", "arm64"})
+}
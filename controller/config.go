@@ -5,6 +5,8 @@ package controller
 
 import (
 	"net/url"
+	"strings"
+	"time"
 
 	"github.com/juju/errors"
 	"github.com/juju/loggo"
@@ -56,16 +58,116 @@ const (
 	// "https://acme-staging.api.letsencrypt.org/directory".
 	AutocertURLKey = "autocert-url"
 
+	// RequireUploadChecksumKey sets whether clients uploading tools
+	// and charms to the controller must specify the SHA-256 hash of
+	// the content being uploaded. Uploads are verified against the
+	// hash whenever one is specified, regardless of this setting.
+	RequireUploadChecksumKey = "require-upload-checksum"
+
+	// RequireSignedToolsURLsKey sets whether unauthenticated requests
+	// to download tools must use a signed tools URL, as handed out by
+	// the controller to provisioned machines and upgrading agents.
+	// Unsigned requests are still served to authenticated agents.
+	RequireSignedToolsURLsKey = "require-signed-tools-urls"
+
+	// ToolsURLExpiryKey sets how long a signed tools URL remains valid
+	// after it is handed out. It must be long enough for a newly
+	// provisioned machine to boot and download its tools. The value is
+	// a duration, e.g. "2h".
+	ToolsURLExpiryKey = "tools-url-expiry"
+
+	// BlobBackendKey sets the backend in which the controller stores
+	// the content of charms, resources and other blobs. The value is
+	// a map of attributes, whose "type" attribute is one of "gridfs"
+	// (the default), "filesystem", "s3" or "azure"; the other
+	// attributes depend on the type. The backend is recorded when
+	// the controller is bootstrapped, and the attributes are then
+	// removed from the controller config, as they may include
+	// credentials; use migrate-blob-backend to change it afterwards.
+	BlobBackendKey = "blob-backend"
+
+	// WatcherCoalesceWindowKey sets the time for which the controller's
+	// txn watchers hold observed changes before delivering them, so that
+	// a burst of changes to a document results in a single event. The
+	// value is a duration, e.g. "100ms"; by default, changes are
+	// delivered as soon as they are observed. The window is read when
+	// the txn watchers start, so a change takes effect when the
+	// controller agents are restarted.
+	WatcherCoalesceWindowKey = "watcher-coalesce-window"
+
+	// MaxAnnotationValueSizeKey sets the maximum size, in bytes, of the
+	// value of a single annotation on an entity.
+	MaxAnnotationValueSizeKey = "max-annotation-value-size"
+
+	// MaxAnnotationsSizeKey sets the maximum total size, in bytes, of
+	// the keys and values of all of the annotations on an entity,
+	// including the previous values retained as history.
+	MaxAnnotationsSizeKey = "max-annotations-size"
+
+	// AnnotationHistoryKey sets the number of previous values of each
+	// annotation that are retained when the annotation is changed or
+	// removed. By default, no history is retained.
+	AnnotationHistoryKey = "annotation-history"
+
+	// CharmScannersKey sets the scanners run over each charm archive
+	// added to a model, whether uploaded or fetched from the charm
+	// store. The value is a comma-separated list of scanner names, or
+	// "none" to disable scanning; by default, all scanners are run.
+	CharmScannersKey = "charm-scanners"
+
+	// MaxCharmHookSizeKey sets the maximum size, in bytes, of each
+	// hook and action file in a charm archive, enforced by the
+	// "hook-size" charm scanner.
+	MaxCharmHookSizeKey = "max-charm-hook-size"
+
 	// Attribute Defaults
 
 	// DefaultAuditingEnabled contains the default value for the
 	// AuditingEnabled config value.
 	DefaultAuditingEnabled = false
 
+	// DefaultRequireUploadChecksum contains the default value for
+	// the RequireUploadChecksum config value.
+	DefaultRequireUploadChecksum = false
+
+	// DefaultRequireSignedToolsURLs contains the default value for
+	// the RequireSignedToolsURLs config value.
+	DefaultRequireSignedToolsURLs = false
+
+	// DefaultToolsURLExpiry contains the default value for the
+	// ToolsURLExpiry config value.
+	DefaultToolsURLExpiry = time.Hour
+
+	// DefaultMaxAnnotationValueSize contains the default value for
+	// the MaxAnnotationValueSize config value.
+	DefaultMaxAnnotationValueSize = 64 * 1024
+
+	// DefaultMaxAnnotationsSize contains the default value for
+	// the MaxAnnotationsSize config value.
+	DefaultMaxAnnotationsSize = 1024 * 1024
+
+	// DefaultAnnotationHistory contains the default value for
+	// the AnnotationHistory config value.
+	DefaultAnnotationHistory = 0
+
+	// MaxAnnotationsSizeLimit is the largest value that the
+	// MaxAnnotationsSize config value may take. An entity's
+	// annotations and their history are stored in one document,
+	// which must stay within MongoDB's 16MiB document limit.
+	MaxAnnotationsSizeLimit = 8 * 1024 * 1024
+
+	// DefaultMaxCharmHookSize contains the default value for
+	// the MaxCharmHookSize config value.
+	DefaultMaxCharmHookSize = 10 * 1024 * 1024
+
 	// DefaultNUMAControlPolicy should not be used by default.
 	// Only use numactl if user specifically requests it
 	DefaultNUMAControlPolicy = false
 
+	// MaxWatcherCoalesceWindow is the maximum value of the
+	// WatcherCoalesceWindow config value.
+	MaxWatcherCoalesceWindow = time.Second
+
 	// DefaultStatePort is the default port the controller is listening on.
 	DefaultStatePort int = 37017
 
@@ -85,6 +187,16 @@ var ControllerOnlyConfigAttributes = []string{
 	SetNUMAControlPolicyKey,
 	AutocertDNSNameKey,
 	AutocertURLKey,
+	RequireUploadChecksumKey,
+	RequireSignedToolsURLsKey,
+	ToolsURLExpiryKey,
+	BlobBackendKey,
+	WatcherCoalesceWindowKey,
+	MaxAnnotationValueSizeKey,
+	MaxAnnotationsSizeKey,
+	AnnotationHistoryKey,
+	CharmScannersKey,
+	MaxCharmHookSizeKey,
 }
 
 // ControllerOnlyAttribute returns true if the specified attribute name
@@ -138,6 +250,21 @@ func (c Config) mustInt(name string) int {
 	return value
 }
 
+// intOrDefault returns the named attribute as an integer, or the
+// given default if it is not set.
+func (c Config) intOrDefault(name string, defaultValue int) int {
+	switch value := c[name].(type) {
+	case int:
+		return value
+	case int64:
+		return int(value)
+	case float64:
+		// Values obtained over the api are encoded as float64.
+		return int(value)
+	}
+	return defaultValue
+}
+
 // asString is a private helper method to keep the ugly string casting
 // in once place. It returns the given named attribute as a string,
 // returning "" if it isn't found.
@@ -231,6 +358,108 @@ func (c Config) NUMACtlPreference() bool {
 	return DefaultNUMAControlPolicy
 }
 
+// RequireUploadChecksum returns whether clients uploading tools and
+// charms must specify the SHA-256 hash of the uploaded content.
+func (c Config) RequireUploadChecksum() bool {
+	if v, ok := c[RequireUploadChecksumKey]; ok {
+		return v.(bool)
+	}
+	return DefaultRequireUploadChecksum
+}
+
+// RequireSignedToolsURLs returns whether unauthenticated requests to
+// download tools must use a signed tools URL.
+func (c Config) RequireSignedToolsURLs() bool {
+	if v, ok := c[RequireSignedToolsURLsKey]; ok {
+		return v.(bool)
+	}
+	return DefaultRequireSignedToolsURLs
+}
+
+// ToolsURLExpiry returns how long a signed tools URL remains valid
+// after it is handed out.
+func (c Config) ToolsURLExpiry() time.Duration {
+	// Validate ensures that the value, if set, is a valid duration.
+	if expiry, err := time.ParseDuration(c.asString(ToolsURLExpiryKey)); err == nil {
+		return expiry
+	}
+	return DefaultToolsURLExpiry
+}
+
+// BlobBackend returns the attributes of the backend in which the
+// controller stores blob content, or nil if the default backend
+// should be used. See BlobBackendKey for more details.
+func (c Config) BlobBackend() map[string]string {
+	switch v := c[BlobBackendKey].(type) {
+	case map[string]string:
+		return v
+	case map[string]interface{}:
+		// Values obtained over the api are decoded
+		// as map[string]interface{}.
+		attrs := make(map[string]string)
+		for k, v := range v {
+			attrs[k], _ = v.(string)
+		}
+		return attrs
+	}
+	return nil
+}
+
+// WatcherCoalesceWindow returns the time for which the controller's
+// txn watchers hold observed changes before delivering them. See
+// WatcherCoalesceWindowKey for more details.
+func (c Config) WatcherCoalesceWindow() time.Duration {
+	// Validate ensures that the value is a valid duration.
+	window, _ := time.ParseDuration(c.asString(WatcherCoalesceWindowKey))
+	return window
+}
+
+// MaxAnnotationValueSize returns the maximum size, in bytes, of the
+// value of a single annotation on an entity.
+func (c Config) MaxAnnotationValueSize() int {
+	return c.intOrDefault(MaxAnnotationValueSizeKey, DefaultMaxAnnotationValueSize)
+}
+
+// MaxAnnotationsSize returns the maximum total size, in bytes, of the
+// keys and values of all of the annotations on an entity, including the
+// previous values retained as history.
+func (c Config) MaxAnnotationsSize() int {
+	return c.intOrDefault(MaxAnnotationsSizeKey, DefaultMaxAnnotationsSize)
+}
+
+// AnnotationHistory returns the number of previous values of each
+// annotation that are retained when the annotation is changed or
+// removed.
+func (c Config) AnnotationHistory() int {
+	return c.intOrDefault(AnnotationHistoryKey, DefaultAnnotationHistory)
+}
+
+// CharmScanners returns the names of the scanners run over each charm
+// archive added to a model, or nil if all scanners should be run. See
+// CharmScannersKey for more details.
+func (c Config) CharmScanners() []string {
+	v := strings.TrimSpace(c.asString(CharmScannersKey))
+	if v == "" {
+		return nil
+	}
+	if v == "none" {
+		return []string{}
+	}
+	var names []string
+	for _, name := range strings.Split(v, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// MaxCharmHookSize returns the maximum size, in bytes, of each hook
+// and action file in a charm archive.
+func (c Config) MaxCharmHookSize() int {
+	return c.intOrDefault(MaxCharmHookSizeKey, DefaultMaxCharmHookSize)
+}
+
 // Validate ensures that config is a valid configuration.
 func Validate(c Config) error {
 	if v, ok := c[IdentityURL].(string); ok {
@@ -259,6 +488,45 @@ func Validate(c Config) error {
 		return errors.Annotate(err, "bad CA certificate in configuration")
 	}
 
+	if attrs := c.BlobBackend(); attrs != nil && attrs["type"] == "" {
+		return errors.Errorf("%s: missing type", BlobBackendKey)
+	}
+
+	if v, ok := c[WatcherCoalesceWindowKey].(string); ok && v != "" {
+		window, err := time.ParseDuration(v)
+		if err != nil {
+			return errors.Annotatef(err, "invalid %s", WatcherCoalesceWindowKey)
+		}
+		if window < 0 || window > MaxWatcherCoalesceWindow {
+			return errors.Errorf(
+				"%s: expected a duration between 0 and %s, got %s",
+				WatcherCoalesceWindowKey, MaxWatcherCoalesceWindow, window,
+			)
+		}
+	}
+
+	if v, ok := c[ToolsURLExpiryKey].(string); ok && v != "" {
+		expiry, err := time.ParseDuration(v)
+		if err != nil {
+			return errors.Annotatef(err, "invalid %s", ToolsURLExpiryKey)
+		}
+		if expiry <= 0 {
+			return errors.Errorf("%s: expected a positive duration, got %s", ToolsURLExpiryKey, expiry)
+		}
+	}
+
+	for _, key := range []string{MaxAnnotationValueSizeKey, MaxAnnotationsSizeKey, MaxCharmHookSizeKey} {
+		if _, ok := c[key]; ok && c.intOrDefault(key, 0) <= 0 {
+			return errors.Errorf("%s: expected a positive number of bytes, got %v", key, c[key])
+		}
+	}
+	if size := c.intOrDefault(MaxAnnotationsSizeKey, 0); size > MaxAnnotationsSizeLimit {
+		return errors.Errorf("%s: expected at most %d bytes, got %d", MaxAnnotationsSizeKey, MaxAnnotationsSizeLimit, size)
+	}
+	if _, ok := c[AnnotationHistoryKey]; ok && c.intOrDefault(AnnotationHistoryKey, -1) < 0 {
+		return errors.Errorf("%s: expected a non-negative number, got %v", AnnotationHistoryKey, c[AnnotationHistoryKey])
+	}
+
 	if uuid, ok := c[ControllerUUIDKey].(string); ok && !utils.IsValidUUIDString(uuid) {
 		return errors.Errorf("controller-uuid: expected UUID, got string(%q)", uuid)
 	}
@@ -273,21 +541,41 @@ func GenerateControllerCertAndKey(caCert, caKey string, hostAddresses []string)
 }
 
 var configChecker = schema.FieldMap(schema.Fields{
-	AuditingEnabled:         schema.Bool(),
-	APIPort:                 schema.ForceInt(),
-	StatePort:               schema.ForceInt(),
-	IdentityURL:             schema.String(),
-	IdentityPublicKey:       schema.String(),
-	SetNUMAControlPolicyKey: schema.Bool(),
-	AutocertURLKey:          schema.String(),
-	AutocertDNSNameKey:      schema.String(),
+	AuditingEnabled:           schema.Bool(),
+	APIPort:                   schema.ForceInt(),
+	StatePort:                 schema.ForceInt(),
+	IdentityURL:               schema.String(),
+	IdentityPublicKey:         schema.String(),
+	SetNUMAControlPolicyKey:   schema.Bool(),
+	AutocertURLKey:            schema.String(),
+	AutocertDNSNameKey:        schema.String(),
+	RequireUploadChecksumKey:  schema.Bool(),
+	RequireSignedToolsURLsKey: schema.Bool(),
+	ToolsURLExpiryKey:         schema.String(),
+	BlobBackendKey:            schema.StringMap(schema.String()),
+	WatcherCoalesceWindowKey:  schema.String(),
+	MaxAnnotationValueSizeKey: schema.ForceInt(),
+	MaxAnnotationsSizeKey:     schema.ForceInt(),
+	AnnotationHistoryKey:      schema.ForceInt(),
+	CharmScannersKey:          schema.String(),
+	MaxCharmHookSizeKey:       schema.ForceInt(),
 }, schema.Defaults{
-	APIPort:                 DefaultAPIPort,
-	AuditingEnabled:         DefaultAuditingEnabled,
-	StatePort:               DefaultStatePort,
-	IdentityURL:             schema.Omit,
-	IdentityPublicKey:       schema.Omit,
-	SetNUMAControlPolicyKey: DefaultNUMAControlPolicy,
-	AutocertURLKey:          schema.Omit,
-	AutocertDNSNameKey:      schema.Omit,
+	APIPort:                   DefaultAPIPort,
+	AuditingEnabled:           DefaultAuditingEnabled,
+	StatePort:                 DefaultStatePort,
+	IdentityURL:               schema.Omit,
+	IdentityPublicKey:         schema.Omit,
+	SetNUMAControlPolicyKey:   DefaultNUMAControlPolicy,
+	AutocertURLKey:            schema.Omit,
+	AutocertDNSNameKey:        schema.Omit,
+	RequireUploadChecksumKey:  schema.Omit,
+	RequireSignedToolsURLsKey: schema.Omit,
+	ToolsURLExpiryKey:         schema.Omit,
+	BlobBackendKey:            schema.Omit,
+	WatcherCoalesceWindowKey:  schema.Omit,
+	MaxAnnotationValueSizeKey: schema.Omit,
+	MaxAnnotationsSizeKey:     schema.Omit,
+	AnnotationHistoryKey:      schema.Omit,
+	CharmScannersKey:          schema.Omit,
+	MaxCharmHookSizeKey:       schema.Omit,
 })
@@ -72,3 +72,142 @@ func (s *ConfigSuite) TestGenerateControllerCertAndKey(c *gc.C) {
 		c.Assert(sanIPs, jc.SameContents, test.sanValues)
 	}
 }
+
+func (s *ConfigSuite) TestWatcherCoalesceWindow(c *gc.C) {
+	cfg, err := controller.NewConfig(testing.ControllerTag.Id(), testing.CACert, map[string]interface{}{
+		controller.WatcherCoalesceWindowKey: "150ms",
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(cfg.WatcherCoalesceWindow(), gc.Equals, 150*time.Millisecond)
+
+	cfg, err = controller.NewConfig(testing.ControllerTag.Id(), testing.CACert, map[string]interface{}{})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(cfg.WatcherCoalesceWindow(), gc.Equals, time.Duration(0))
+}
+
+func (s *ConfigSuite) TestWatcherCoalesceWindowInvalid(c *gc.C) {
+	for _, test := range []struct {
+		value  string
+		expect string
+	}{{
+		value:  "soon",
+		expect: `invalid watcher-coalesce-window: time: invalid duration "?soon"?`,
+	}, {
+		value:  "-1ms",
+		expect: `watcher-coalesce-window: expected a duration between 0 and 1s, got -1ms`,
+	}, {
+		value:  "2s",
+		expect: `watcher-coalesce-window: expected a duration between 0 and 1s, got 2s`,
+	}} {
+		_, err := controller.NewConfig(testing.ControllerTag.Id(), testing.CACert, map[string]interface{}{
+			controller.WatcherCoalesceWindowKey: test.value,
+		})
+		c.Check(err, gc.ErrorMatches, test.expect)
+	}
+}
+
+func (s *ConfigSuite) TestToolsURLSigning(c *gc.C) {
+	cfg, err := controller.NewConfig(testing.ControllerTag.Id(), testing.CACert, map[string]interface{}{})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(cfg.RequireSignedToolsURLs(), jc.IsFalse)
+	c.Assert(cfg.ToolsURLExpiry(), gc.Equals, controller.DefaultToolsURLExpiry)
+
+	cfg, err = controller.NewConfig(testing.ControllerTag.Id(), testing.CACert, map[string]interface{}{
+		controller.RequireSignedToolsURLsKey: true,
+		controller.ToolsURLExpiryKey:         "3h",
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(cfg.RequireSignedToolsURLs(), jc.IsTrue)
+	c.Assert(cfg.ToolsURLExpiry(), gc.Equals, 3*time.Hour)
+}
+
+func (s *ConfigSuite) TestToolsURLExpiryInvalid(c *gc.C) {
+	for _, test := range []struct {
+		value  string
+		expect string
+	}{{
+		value:  "later",
+		expect: `invalid tools-url-expiry: time: invalid duration "?later"?`,
+	}, {
+		value:  "0s",
+		expect: `tools-url-expiry: expected a positive duration, got 0s`,
+	}} {
+		_, err := controller.NewConfig(testing.ControllerTag.Id(), testing.CACert, map[string]interface{}{
+			controller.ToolsURLExpiryKey: test.value,
+		})
+		c.Check(err, gc.ErrorMatches, test.expect)
+	}
+}
+
+func (s *ConfigSuite) TestAnnotationLimits(c *gc.C) {
+	cfg, err := controller.NewConfig(testing.ControllerTag.Id(), testing.CACert, map[string]interface{}{})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(cfg.MaxAnnotationValueSize(), gc.Equals, controller.DefaultMaxAnnotationValueSize)
+	c.Assert(cfg.MaxAnnotationsSize(), gc.Equals, controller.DefaultMaxAnnotationsSize)
+	c.Assert(cfg.AnnotationHistory(), gc.Equals, 0)
+
+	cfg, err = controller.NewConfig(testing.ControllerTag.Id(), testing.CACert, map[string]interface{}{
+		controller.MaxAnnotationValueSizeKey: 1024,
+		controller.MaxAnnotationsSizeKey:     "4096",
+		controller.AnnotationHistoryKey:      float64(5),
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(cfg.MaxAnnotationValueSize(), gc.Equals, 1024)
+	c.Assert(cfg.MaxAnnotationsSize(), gc.Equals, 4096)
+	c.Assert(cfg.AnnotationHistory(), gc.Equals, 5)
+}
+
+func (s *ConfigSuite) TestCharmScanners(c *gc.C) {
+	cfg, err := controller.NewConfig(testing.ControllerTag.Id(), testing.CACert, map[string]interface{}{})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(cfg.CharmScanners(), gc.IsNil)
+	c.Assert(cfg.MaxCharmHookSize(), gc.Equals, controller.DefaultMaxCharmHookSize)
+
+	cfg, err = controller.NewConfig(testing.ControllerTag.Id(), testing.CACert, map[string]interface{}{
+		controller.CharmScannersKey:    "structure, setuid,",
+		controller.MaxCharmHookSizeKey: 1024,
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(cfg.CharmScanners(), jc.DeepEquals, []string{"structure", "setuid"})
+	c.Assert(cfg.MaxCharmHookSize(), gc.Equals, 1024)
+
+	cfg, err = controller.NewConfig(testing.ControllerTag.Id(), testing.CACert, map[string]interface{}{
+		controller.CharmScannersKey: "none",
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(cfg.CharmScanners(), gc.NotNil)
+	c.Assert(cfg.CharmScanners(), gc.HasLen, 0)
+}
+
+func (s *ConfigSuite) TestAnnotationLimitsInvalid(c *gc.C) {
+	for _, test := range []struct {
+		key    string
+		value  interface{}
+		expect string
+	}{{
+		key:    controller.MaxAnnotationValueSizeKey,
+		value:  0,
+		expect: `max-annotation-value-size: expected a positive number of bytes, got 0`,
+	}, {
+		key:    controller.MaxAnnotationsSizeKey,
+		value:  -1,
+		expect: `max-annotations-size: expected a positive number of bytes, got -1`,
+	}, {
+		key:    controller.MaxAnnotationsSizeKey,
+		value:  controller.MaxAnnotationsSizeLimit + 1,
+		expect: `max-annotations-size: expected at most 8388608 bytes, got 8388609`,
+	}, {
+		key:    controller.AnnotationHistoryKey,
+		value:  -1,
+		expect: `annotation-history: expected a non-negative number, got -1`,
+	}, {
+		key:    controller.MaxCharmHookSizeKey,
+		value:  0,
+		expect: `max-charm-hook-size: expected a positive number of bytes, got 0`,
+	}} {
+		_, err := controller.NewConfig(testing.ControllerTag.Id(), testing.CACert, map[string]interface{}{
+			test.key: test.value,
+		})
+		c.Check(err, gc.ErrorMatches, test.expect)
+	}
+}
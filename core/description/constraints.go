@@ -22,6 +22,8 @@ type ConstraintsArgs struct {
 	Tags   []string
 
 	VirtType string
+
+	MaxHourlyCost uint64
 }
 
 func newConstraints(args ConstraintsArgs) *constraints {
@@ -36,17 +38,18 @@ func newConstraints(args ConstraintsArgs) *constraints {
 	spaces := make([]string, len(args.Spaces))
 	copy(spaces, args.Spaces)
 	return &constraints{
-		Version:       1,
-		Architecture_: args.Architecture,
-		Container_:    args.Container,
-		CpuCores_:     args.CpuCores,
-		CpuPower_:     args.CpuPower,
-		InstanceType_: args.InstanceType,
-		Memory_:       args.Memory,
-		RootDisk_:     args.RootDisk,
-		Spaces_:       spaces,
-		Tags_:         tags,
-		VirtType_:     args.VirtType,
+		Version:        1,
+		Architecture_:  args.Architecture,
+		Container_:     args.Container,
+		CpuCores_:      args.CpuCores,
+		CpuPower_:      args.CpuPower,
+		InstanceType_:  args.InstanceType,
+		Memory_:        args.Memory,
+		RootDisk_:      args.RootDisk,
+		Spaces_:        spaces,
+		Tags_:          tags,
+		VirtType_:      args.VirtType,
+		MaxHourlyCost_: args.MaxHourlyCost,
 	}
 }
 
@@ -65,6 +68,8 @@ type constraints struct {
 	Tags_   []string `yaml:"tags,omitempty"`
 
 	VirtType_ string `yaml:"virt-type,omitempty"`
+
+	MaxHourlyCost_ uint64 `yaml:"max-hourly-cost,omitempty"`
 }
 
 // Architecture implements Constraints.
@@ -127,6 +132,11 @@ func (c *constraints) VirtType() string {
 	return c.VirtType_
 }
 
+// MaxHourlyCost implements Constraints.
+func (c *constraints) MaxHourlyCost() uint64 {
+	return c.MaxHourlyCost_
+}
+
 func importConstraints(source map[string]interface{}) (*constraints, error) {
 	version, err := getVersion(source)
 	if err != nil {
@@ -162,6 +172,8 @@ func importConstraintsV1(source map[string]interface{}) (*constraints, error) {
 		"tags":   schema.List(schema.String()),
 
 		"virt-type": schema.String(),
+
+		"max-hourly-cost": schema.ForceUint(),
 	}
 	// Some values don't have to be there.
 	defaults := schema.Defaults{
@@ -178,6 +190,8 @@ func importConstraintsV1(source map[string]interface{}) (*constraints, error) {
 		"tags":   schema.Omit,
 
 		"virt-type": "",
+
+		"max-hourly-cost": uint64(0),
 	}
 	checker := schema.FieldMap(fields, defaults)
 
@@ -218,6 +232,8 @@ func importConstraintsV1(source map[string]interface{}) (*constraints, error) {
 		Tags_:   convertToStringSlice(valid["tags"]),
 
 		VirtType_: valid["virt-type"].(string),
+
+		MaxHourlyCost_: valid["max-hourly-cost"].(uint64),
 	}, nil
 }
 
@@ -236,5 +252,6 @@ func (c ConstraintsArgs) empty() bool {
 		c.RootDisk == 0 &&
 		c.Spaces == nil &&
 		c.Tags == nil &&
-		c.VirtType == ""
+		c.VirtType == "" &&
+		c.MaxHourlyCost == 0
 }
@@ -72,6 +72,18 @@ func (s *ConstraintsSerializationSuite) TestNewConstraintsWithVirt(c *gc.C) {
 	c.Assert(instance.VirtType(), gc.Equals, args.VirtType)
 }
 
+func (s *ConstraintsSerializationSuite) TestNewConstraintsWithMaxHourlyCost(c *gc.C) {
+	args := s.allArgs()
+	args.MaxHourlyCost = 250
+	instance := newConstraints(args)
+	c.Assert(instance.MaxHourlyCost(), gc.Equals, args.MaxHourlyCost)
+}
+
+func (s *ConstraintsSerializationSuite) TestNewConstraintsOnlyMaxHourlyCost(c *gc.C) {
+	instance := newConstraints(ConstraintsArgs{MaxHourlyCost: 250})
+	c.Assert(instance, gc.NotNil)
+}
+
 func (s *ConstraintsSerializationSuite) TestNewConstraintsEmpty(c *gc.C) {
 	instance := newConstraints(ConstraintsArgs{})
 	c.Assert(instance, gc.IsNil)
@@ -111,3 +123,9 @@ func (s *ConstraintsSerializationSuite) TestParsingSerializedVirt(c *gc.C) {
 	args.VirtType = "kvm"
 	s.assertParsingSerializedConstraints(c, newConstraints(args))
 }
+
+func (s *ConstraintsSerializationSuite) TestParsingSerializedMaxHourlyCost(c *gc.C) {
+	args := s.allArgs()
+	args.MaxHourlyCost = 250
+	s.assertParsingSerializedConstraints(c, newConstraints(args))
+}
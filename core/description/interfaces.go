@@ -231,6 +231,8 @@ type Constraints interface {
 	Tags() []string
 
 	VirtType() string
+
+	MaxHourlyCost() uint64
 }
 
 // Status represents an agent, application, or workload status.
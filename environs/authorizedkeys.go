@@ -0,0 +1,22 @@
+// Copyright 2016 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package environs
+
+import (
+	"github.com/juju/juju/instance"
+)
+
+// InstanceAuthorizedKeysUpdater is an interface that may be implemented
+// by an Environ to update the SSH authorized keys of existing instances
+// through a provider-specific channel. Authorized keys are otherwise
+// only injected when an instance is created, and then maintained by
+// the machine agent; updating them through the cloud means that keys
+// can be rotated even on instances whose agents are not running.
+type InstanceAuthorizedKeysUpdater interface {
+	// UpdateAuthorizedKeys adds the given authorized keys, in the
+	// format of the "authorized-keys" model config attribute, to
+	// the specified instances. Instances that do not support SSH
+	// keys are skipped.
+	UpdateAuthorizedKeys(ids []instance.Id, authorizedKeys string) error
+}
@@ -60,10 +60,22 @@ type BootstrapParams struct {
 	// that rely on it for selecting images. This will be empty for
 	// providers that do not implements simplestreams.HasRegion.
 	ImageMetadata []*imagemetadata.ImageMetadata
+
+	// Resume, if true, indicates that a previous attempt to bootstrap
+	// the controller failed after the bootstrap instance was started.
+	// The existing bootstrap instance is finalized, rather than a new
+	// instance being started.
+	Resume bool
 }
 
 // BootstrapFinalizer is a function returned from Environ.Bootstrap.
 // The caller must pass a InstanceConfig with the Tools field set.
+//
+// Finalizers should determine how far an earlier attempt to configure
+// the bootstrap instance progressed, and record it in the InstanceConfig
+// (see instancecfg.BootstrapProgress), so that calling a finalizer again
+// for the same instance, after a failure, resumes the bootstrap process
+// rather than repeating steps already completed.
 type BootstrapFinalizer func(BootstrapContext, *instancecfg.InstanceConfig, BootstrapDialOpts) error
 
 // BootstrapDialOpts contains the options for the synchronous part of the
@@ -108,6 +108,12 @@ type BootstrapParams struct {
 	// config.
 	HostedModelConfig map[string]interface{}
 
+	// HostedModelStoragePools holds the attributes of storage pools to
+	// create in the initial hosted model, keyed by pool name. Each
+	// pool's attributes must include its storage provider type, with
+	// the key "type".
+	HostedModelStoragePools map[string]map[string]interface{}
+
 	// Placement, if non-empty, holds an environment-specific placement
 	// directive used to choose the initial instance.
 	Placement string
@@ -143,6 +149,20 @@ type BootstrapParams struct {
 
 	// DialOpts contains the bootstrap dial options.
 	DialOpts environs.BootstrapDialOpts
+
+	// Resume, if true, indicates that a previous attempt to bootstrap
+	// the controller failed after the bootstrap instance was started,
+	// and that the existing instance should be finalized rather than
+	// a new one started.
+	Resume bool
+
+	// KeptAgentConfig, if non-nil, is called when resuming bootstrap
+	// if the agent on the bootstrap instance was configured by the
+	// failed attempt. That configuration is kept, so the controller's
+	// CA certificate is the one given to the failed attempt rather
+	// than the one in ControllerConfig. It is called whether or not
+	// finalizing the instance then succeeds.
+	KeptAgentConfig func()
 }
 
 // Validate validates the bootstrap parameters.
@@ -318,6 +338,12 @@ func Bootstrap(ctx environs.BootstrapContext, environ environs.Environ, args Boo
 		}
 	}
 	if len(availableTools) == 0 {
+		if args.AgentVersion != nil && !args.BuildAgent && isCompatibleMajorMinor(*args.AgentVersion, jujuversion.Current) {
+			// A specific patch version was requested, and we
+			// cannot build it locally; tell the user which
+			// versions they may choose from instead.
+			return agentVersionNotFoundError(environ, *args.AgentVersion, bootstrapArch, bootstrapSeries)
+		}
 		return errors.New(noToolsMessage)
 	}
 
@@ -339,7 +365,11 @@ func Bootstrap(ctx environs.BootstrapContext, environ environs.Environ, args Boo
 		return err
 	}
 
-	ctx.Verbosef("Starting new instance for initial controller")
+	if args.Resume {
+		ctx.Verbosef("Resuming bootstrap of existing controller instance")
+	} else {
+		ctx.Verbosef("Starting new instance for initial controller")
+	}
 
 	result, err := environ.Bootstrap(ctx, environs.BootstrapParams{
 		CloudName:            args.CloudName,
@@ -351,6 +381,7 @@ func Bootstrap(ctx environs.BootstrapContext, environ environs.Environ, args Boo
 		Placement:            args.Placement,
 		AvailableTools:       availableTools,
 		ImageMetadata:        imageMetadata,
+		Resume:               args.Resume,
 	})
 	if err != nil {
 		return err
@@ -399,7 +430,12 @@ func Bootstrap(ctx environs.BootstrapContext, environ environs.Environ, args Boo
 	if err := finalizeInstanceBootstrapConfig(ctx, instanceConfig, args, cfg, customImageMetadata); err != nil {
 		return errors.Annotate(err, "finalizing bootstrap instance config")
 	}
-	if err := result.Finalize(ctx, instanceConfig, args.DialOpts); err != nil {
+	err = result.Finalize(ctx, instanceConfig, args.DialOpts)
+	if args.Resume && args.KeptAgentConfig != nil &&
+		instanceConfig.Bootstrap.Progress.Reached(instancecfg.BootstrapAgentConfigured) {
+		args.KeptAgentConfig()
+	}
+	if err != nil {
 		return err
 	}
 	ctx.Infof("Bootstrap agent now started")
@@ -461,6 +497,7 @@ func finalizeInstanceBootstrapConfig(
 	icfg.Bootstrap.ControllerInheritedConfig = args.ControllerInheritedConfig
 	icfg.Bootstrap.RegionInheritedConfig = args.Cloud.RegionConfig
 	icfg.Bootstrap.HostedModelConfig = args.HostedModelConfig
+	icfg.Bootstrap.HostedModelStoragePools = args.HostedModelStoragePools
 	icfg.Bootstrap.Timeout = args.DialOpts.Timeout
 	icfg.Bootstrap.GUI = guiArchive(args.GUIDataSourceBaseURL, func(msg string) {
 		ctx.Infof(msg)
@@ -641,6 +678,10 @@ func isCompatibleVersion(v1, v2 version.Number) bool {
 	return v1.Compare(v2) == 0
 }
 
+func isCompatibleMajorMinor(v1, v2 version.Number) bool {
+	return v1.Major == v2.Major && v1.Minor == v2.Minor
+}
+
 // setPrivateMetadataSources sets the default tools metadata source
 // for tools syncing, and adds an image metadata source after verifying
 // the contents.
@@ -187,6 +187,48 @@ func (s *bootstrapSuite) TestBootstrapSpecifiedPlacement(c *gc.C) {
 	c.Assert(env.args.Placement, gc.DeepEquals, placement)
 }
 
+func (s *bootstrapSuite) TestBootstrapResume(c *gc.C) {
+	env := newEnviron("foo", useDefaultKeys, nil)
+	s.setDummyStorage(c, env)
+	err := bootstrap.Bootstrap(envtesting.BootstrapContext(c), env, bootstrap.BootstrapParams{
+		ControllerConfig: coretesting.FakeControllerConfig(),
+		AdminSecret:      "admin-secret",
+		CAPrivateKey:     coretesting.CAKey,
+		Resume:           true,
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(env.bootstrapCount, gc.Equals, 1)
+	c.Assert(env.args.Resume, jc.IsTrue)
+}
+
+func (s *bootstrapSuite) TestBootstrapResumeKeptAgentConfig(c *gc.C) {
+	for _, test := range []struct {
+		progress instancecfg.BootstrapProgress
+		kept     bool
+	}{
+		{instancecfg.BootstrapNotStarted, false},
+		{instancecfg.BootstrapConfiguring, false},
+		{instancecfg.BootstrapAgentConfigured, true},
+		{instancecfg.BootstrapStateInitialized, true},
+		{instancecfg.BootstrapConfigured, true},
+	} {
+		c.Logf("progress %q", test.progress)
+		env := newEnviron("foo", useDefaultKeys, nil)
+		env.progress = test.progress
+		s.setDummyStorage(c, env)
+		var kept bool
+		err := bootstrap.Bootstrap(envtesting.BootstrapContext(c), env, bootstrap.BootstrapParams{
+			ControllerConfig: coretesting.FakeControllerConfig(),
+			AdminSecret:      "admin-secret",
+			CAPrivateKey:     coretesting.CAKey,
+			Resume:           true,
+			KeptAgentConfig:  func() { kept = true },
+		})
+		c.Assert(err, jc.ErrorIsNil)
+		c.Check(kept, gc.Equals, test.kept)
+	}
+}
+
 func (s *bootstrapSuite) TestBootstrapImage(c *gc.C) {
 	s.PatchValue(&series.HostSeries, func() string { return "precise" })
 	s.PatchValue(&arch.HostArch, func() string { return arch.AMD64 })
@@ -1137,6 +1179,17 @@ func (s *bootstrapSuite) TestBootstrapNoSpecificVersion(c *gc.C) {
 	})
 }
 
+func (s *bootstrapSuite) TestBootstrapSpecificVersionNotFound(c *gc.C) {
+	// A specific version that is not available in the agent stream
+	// cannot be built locally, so bootstrap fails before starting an
+	// instance, listing the versions that are available.
+	toolsVersion := version.MustParse("10.11.99")
+	err, bootstrapCount, _ := s.setupBootstrapSpecificVersion(c, 10, 11, &toolsVersion)
+	c.Assert(err, gc.ErrorMatches, `no agent binaries for version 10.11.99 found in the "released" stream for amd64; `+
+		`available versions: 10.11-beta1, 10.11.12, 10.11.13`)
+	c.Assert(bootstrapCount, gc.Equals, 0)
+}
+
 func (s *bootstrapSuite) TestBootstrapSpecificVersionClientMinorMismatch(c *gc.C) {
 	// bootstrap using a specified version only works if the patch number is different.
 	// The bootstrap client major and minor versions need to match the tools asked for.
@@ -1183,6 +1236,10 @@ type bootstrapEnviron struct {
 	cfg              *config.Config
 	environs.Environ // stub out all methods we don't care about.
 
+	// progress is recorded in the instance config by the finalizer,
+	// as if read from the bootstrap instance.
+	progress instancecfg.BootstrapProgress
+
 	// The following fields are filled in when Bootstrap is called.
 	bootstrapCount            int
 	finalizerCount            int
@@ -1226,6 +1283,7 @@ func (e *bootstrapEnviron) Bootstrap(ctx environs.BootstrapContext, args environ
 	finalizer := func(_ environs.BootstrapContext, icfg *instancecfg.InstanceConfig, _ environs.BootstrapDialOpts) error {
 		e.finalizerCount++
 		e.instanceConfig = icfg
+		icfg.Bootstrap.Progress = e.progress
 		return nil
 	}
 	series := series.HostSeries()
@@ -5,6 +5,8 @@ package bootstrap
 
 import (
 	"fmt"
+	"sort"
+	"strings"
 
 	"github.com/juju/errors"
 	"github.com/juju/utils/arch"
@@ -121,3 +123,51 @@ func findBootstrapTools(env environs.Environ, vers *version.Number, arch, series
 	stream := envtools.PreferredStream(vers, env.Config().Development(), env.Config().AgentStream())
 	return findTools(env, cliVersion.Major, cliVersion.Minor, stream, filter)
 }
+
+// agentVersionNotFoundError returns an error describing the absence of
+// agent binaries with the given version in the model's agent stream,
+// listing the versions with the same major and minor version numbers
+// that are available for the given arch and series.
+func agentVersionNotFoundError(env environs.Environ, vers version.Number, arch string, series *string) error {
+	cfg := env.Config()
+	stream := envtools.PreferredStream(&vers, cfg.Development(), cfg.AgentStream())
+	target := arch
+	filter := coretools.Filter{Arch: arch}
+	if series != nil {
+		filter.Series = *series
+		target = fmt.Sprintf("%s/%s", *series, arch)
+	}
+	message := fmt.Sprintf(
+		"no agent binaries for version %s found in the %q stream for %s",
+		vers, stream, target,
+	)
+	list, err := findTools(env, vers.Major, vers.Minor, stream, filter)
+	if err != nil && !errors.IsNotFound(err) {
+		logger.Debugf("cannot list available agent binaries: %v", err)
+		return errors.New(message)
+	}
+	seen := make(map[version.Number]bool)
+	var numbers versionNumbers
+	for _, tools := range list {
+		if seen[tools.Version.Number] {
+			continue
+		}
+		seen[tools.Version.Number] = true
+		numbers = append(numbers, tools.Version.Number)
+	}
+	if len(numbers) == 0 {
+		return errors.Errorf("%s; no %d.%d versions are available", message, vers.Major, vers.Minor)
+	}
+	sort.Sort(numbers)
+	available := make([]string, len(numbers))
+	for i, n := range numbers {
+		available[i] = n.String()
+	}
+	return errors.Errorf("%s; available versions: %s", message, strings.Join(available, ", "))
+}
+
+type versionNumbers []version.Number
+
+func (v versionNumbers) Len() int           { return len(v) }
+func (v versionNumbers) Swap(i, j int)      { v[i], v[j] = v[j], v[i] }
+func (v versionNumbers) Less(i, j int) bool { return v[i].Compare(v[j]) < 0 }
@@ -0,0 +1,32 @@
+// Copyright 2016 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package environs
+
+import (
+	"github.com/juju/juju/instance"
+)
+
+// InstanceCommandResult holds the result of running a command on an
+// instance with an InstanceCommandRunner.
+type InstanceCommandResult struct {
+	// Code is the exit code of the command.
+	Code int
+
+	// Stdout and Stderr hold the command's output, which
+	// providers may truncate.
+	Stdout string
+	Stderr string
+}
+
+// InstanceCommandRunner is an interface that may be implemented by an
+// Environ to run commands on its instances through a provider-specific
+// channel, such as an agent installed in the instance by the cloud,
+// rather than SSH. This makes it possible to inspect instances in
+// networks that the client cannot reach with SSH.
+type InstanceCommandRunner interface {
+	// RunCommand runs the given bash script as root on the specified
+	// instance, and returns its result once it has completed. A non-zero
+	// exit code is reported in the result, not as an error.
+	RunCommand(id instance.Id, script string) (*InstanceCommandResult, error)
+}
@@ -5,6 +5,7 @@ package config
 
 import (
 	"github.com/juju/schema"
+	"gopkg.in/juju/environschema.v1"
 )
 
 // These constants define named sources of model config attributes.
@@ -64,6 +65,18 @@ type ConfigSchemaSource interface {
 	ConfigDefaults() schema.Defaults
 }
 
+// RequiredConfigSource is an interface that a provider may implement
+// to declare the config attributes that must be specified when
+// bootstrapping a controller, so that any that are missing can be
+// reported, or prompted for, before bootstrap begins.
+type RequiredConfigSource interface {
+	// RequiredConfig returns the schema of the config attributes
+	// that must be specified when bootstrapping with the given
+	// config attributes. Attributes may be required only in
+	// combination with others, e.g. when a mode is enabled.
+	RequiredConfig(attrs map[string]interface{}) environschema.Fields
+}
+
 // ModelDefaultAttributes is a map of configuration values to a list of possible
 // values.
 type ModelDefaultAttributes map[string]AttributeDefaultValues
@@ -40,3 +40,19 @@ func GetEnviron(st EnvironConfigGetter, newEnviron NewEnvironFunc) (Environ, err
 	}
 	return env, nil
 }
+
+// IdentifyInstance returns the identity of the instance that the
+// caller is running on, as reported by the given environ. If the
+// environ does not implement InstanceIdentifier, an error satisfying
+// errors.IsNotSupported is returned.
+func IdentifyInstance(env Environ) (InstanceIdentity, error) {
+	identifier, ok := env.(InstanceIdentifier)
+	if !ok {
+		return InstanceIdentity{}, errors.NotSupportedf("identifying instances")
+	}
+	identity, err := identifier.IdentifyInstance()
+	if err != nil {
+		return InstanceIdentity{}, errors.Annotate(err, "identifying instance")
+	}
+	return identity, nil
+}
@@ -4,6 +4,7 @@
 package environs_test
 
 import (
+	"github.com/juju/errors"
 	jc "github.com/juju/testing/checkers"
 	gc "gopkg.in/check.v1"
 
@@ -27,3 +28,25 @@ func (s *environSuite) TestGetEnvironment(c *gc.C) {
 	c.Check(env.Config().UUID(), jc.DeepEquals, config.UUID())
 	c.Check(env, gc.Not(gc.Equals), s.Environ)
 }
+
+type identifyingEnviron struct {
+	environs.Environ
+	identity environs.InstanceIdentity
+}
+
+func (e identifyingEnviron) IdentifyInstance() (environs.InstanceIdentity, error) {
+	return e.identity, nil
+}
+
+func (s *environSuite) TestIdentifyInstance(c *gc.C) {
+	identity := environs.InstanceIdentity{InstanceId: "machine-0", Region: "westus"}
+	result, err := environs.IdentifyInstance(identifyingEnviron{identity: identity})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(result, jc.DeepEquals, identity)
+}
+
+func (s *environSuite) TestIdentifyInstanceNotSupported(c *gc.C) {
+	_, err := environs.IdentifyInstance(s.Environ)
+	c.Assert(err, jc.Satisfies, errors.IsNotSupported)
+	c.Assert(err, gc.ErrorMatches, "identifying instances not supported")
+}
@@ -12,3 +12,61 @@ var (
 	ErrNoInstances      = errors.NotFoundf("instances")
 	ErrPartialInstances = errors.New("only some instances were found")
 )
+
+// ProvisioningErrorKind classifies the reason that an instance could
+// not be provisioned.
+type ProvisioningErrorKind string
+
+const (
+	// ProvisioningErrorUnknown is the kind of provisioning error
+	// that has not been classified by the provider.
+	ProvisioningErrorUnknown ProvisioningErrorKind = "unknown"
+
+	// ProvisioningErrorQuota is the kind of provisioning error
+	// that occurs when a resource quota has been exhausted.
+	ProvisioningErrorQuota ProvisioningErrorKind = "quota"
+
+	// ProvisioningErrorAuth is the kind of provisioning error
+	// that occurs when the credentials used by the provider are
+	// invalid, or do not permit the operation.
+	ProvisioningErrorAuth ProvisioningErrorKind = "auth"
+
+	// ProvisioningErrorCapacity is the kind of provisioning error
+	// that occurs when the cloud does not currently have capacity
+	// for the requested instance.
+	ProvisioningErrorCapacity ProvisioningErrorKind = "capacity"
+
+	// ProvisioningErrorConfig is the kind of provisioning error
+	// that occurs when the requested instance is invalid, e.g.
+	// because of an unsatisfiable constraint.
+	ProvisioningErrorConfig ProvisioningErrorKind = "config"
+
+	// ProvisioningErrorPolicy is the kind of provisioning error
+	// that occurs when the requested instance is denied by a policy
+	// defined in the cloud, e.g. one restricting regions or sizes.
+	ProvisioningErrorPolicy ProvisioningErrorKind = "policy"
+)
+
+// provisioningError is an error that records the kind of
+// provisioning failure that caused it.
+type provisioningError struct {
+	error
+	kind ProvisioningErrorKind
+}
+
+// NewProvisioningError returns an error that wraps the given error,
+// classifying it as the specified kind of provisioning error.
+func NewProvisioningError(err error, kind ProvisioningErrorKind) error {
+	return &provisioningError{err, kind}
+}
+
+// ProvisioningErrorKindOf returns the kind of provisioning error with
+// which the given error, or its cause, was classified by a call to
+// NewProvisioningError. If the error was not classified, then
+// ProvisioningErrorUnknown is returned.
+func ProvisioningErrorKindOf(err error) ProvisioningErrorKind {
+	if err, ok := errors.Cause(err).(*provisioningError); ok {
+		return err.kind
+	}
+	return ProvisioningErrorUnknown
+}
@@ -0,0 +1,30 @@
+// Copyright 2016 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package environs_test
+
+import (
+	"github.com/juju/errors"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/environs"
+)
+
+type errorsSuite struct{}
+
+var _ = gc.Suite(&errorsSuite{})
+
+func (s *errorsSuite) TestProvisioningErrorKindOf(c *gc.C) {
+	err := environs.NewProvisioningError(errors.New("no cores left"), environs.ProvisioningErrorQuota)
+	c.Assert(err, gc.ErrorMatches, "no cores left")
+	c.Assert(environs.ProvisioningErrorKindOf(err), gc.Equals, environs.ProvisioningErrorQuota)
+
+	err = errors.Annotate(err, "starting instance")
+	c.Assert(err, gc.ErrorMatches, "starting instance: no cores left")
+	c.Assert(environs.ProvisioningErrorKindOf(err), gc.Equals, environs.ProvisioningErrorQuota)
+}
+
+func (s *errorsSuite) TestProvisioningErrorKindOfUnclassified(c *gc.C) {
+	err := errors.New("boom")
+	c.Assert(environs.ProvisioningErrorKindOf(err), gc.Equals, environs.ProvisioningErrorUnknown)
+}
@@ -0,0 +1,137 @@
+// Copyright 2016 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package environs
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/juju/errors"
+)
+
+// Feature identifies a Juju feature that may or may not be supported
+// by a provider, e.g. spaces or storage volumes.
+type Feature string
+
+const (
+	// FeatureSpaces is the feature of binding applications to
+	// network spaces.
+	FeatureSpaces Feature = "spaces"
+
+	// FeatureAvailabilityZones is the feature of distributing
+	// machines across availability zones, and of placing machines
+	// in specific zones.
+	FeatureAvailabilityZones Feature = "availability-zones"
+
+	// FeatureVolumes is the feature of provisioning storage
+	// volumes with the provider's native storage.
+	FeatureVolumes Feature = "volumes"
+
+	// FeatureManagedDisks is the feature of using disks whose
+	// underlying storage is managed by the cloud.
+	FeatureManagedDisks Feature = "managed-disks"
+
+	// FeatureGlobalFirewall is the feature of the "global"
+	// firewall-mode, in which ports are opened for all machines
+	// in the model.
+	FeatureGlobalFirewall Feature = "global-firewall"
+
+	// FeatureSuspendInstances is the feature of stopping machines'
+	// instances without destroying them; see InstanceSuspender.
+	FeatureSuspendInstances Feature = "suspend-instances"
+)
+
+var (
+	featuresMu sync.Mutex
+	features   = map[Feature]string{
+		FeatureSpaces:            "applications may be bound to network spaces",
+		FeatureAvailabilityZones: "machines may be placed in availability zones",
+		FeatureVolumes:           "storage volumes may be provisioned",
+		FeatureManagedDisks:      "disks are managed by the cloud",
+		FeatureGlobalFirewall:    `firewall-mode may be "global"`,
+		FeatureSuspendInstances:  "instances may be suspended and resumed",
+	}
+)
+
+// RegisterFeature registers a feature with the given description, so
+// that providers may report supporting it. RegisterFeature panics if
+// the feature is already registered.
+func RegisterFeature(f Feature, description string) {
+	featuresMu.Lock()
+	defer featuresMu.Unlock()
+	if _, ok := features[f]; ok {
+		panic(fmt.Sprintf("feature %q already registered", f))
+	}
+	features[f] = description
+}
+
+// RegisteredFeatures returns the registered features, sorted by name.
+func RegisteredFeatures() []Feature {
+	featuresMu.Lock()
+	defer featuresMu.Unlock()
+	result := make([]Feature, 0, len(features))
+	for f := range features {
+		result = append(result, f)
+	}
+	sortFeatures(result)
+	return result
+}
+
+// FeatureDescription returns the description of the given feature. If
+// the feature is not registered, an error satisfying errors.IsNotFound
+// is returned.
+func FeatureDescription(f Feature) (string, error) {
+	featuresMu.Lock()
+	defer featuresMu.Unlock()
+	description, ok := features[f]
+	if !ok {
+		return "", errors.NotFoundf("feature %q", f)
+	}
+	return description, nil
+}
+
+// FeatureEnumerator is an interface that may be implemented by an
+// Environ to declare which of the registered features the provider
+// supports for the model.
+type FeatureEnumerator interface {
+	// SupportedFeatures returns the features that the provider
+	// supports for the model.
+	SupportedFeatures() ([]Feature, error)
+}
+
+// SupportedFeatures returns the features that the given Environ's
+// provider supports for its model, sorted by name. If the Environ does
+// not implement FeatureEnumerator, SupportedFeatures returns an error
+// satisfying errors.IsNotSupported; the features that it supports are
+// then unknown.
+func SupportedFeatures(env Environ) ([]Feature, error) {
+	enumerator, ok := env.(FeatureEnumerator)
+	if !ok {
+		return nil, errors.NotSupportedf("enumerating features")
+	}
+	supported, err := enumerator.SupportedFeatures()
+	if err != nil {
+		return nil, errors.Annotate(err, "enumerating features")
+	}
+	result := make([]Feature, len(supported))
+	for i, f := range supported {
+		if _, err := FeatureDescription(f); err != nil {
+			return nil, errors.Annotate(err, "enumerating features")
+		}
+		result[i] = f
+	}
+	sortFeatures(result)
+	return result, nil
+}
+
+func sortFeatures(features []Feature) {
+	sort.Sort(featuresByName(features))
+}
+
+type featuresByName []Feature
+
+func (f featuresByName) Len() int           { return len(f) }
+func (f featuresByName) Swap(i, j int)      { f[i], f[j] = f[j], f[i] }
+func (f featuresByName) Less(i, j int) bool { return f[i] < f[j] }
@@ -0,0 +1,85 @@
+// Copyright 2016 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package environs_test
+
+import (
+	"github.com/juju/errors"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/environs"
+)
+
+type featuresSuite struct{}
+
+var _ = gc.Suite(&featuresSuite{})
+
+func (s *featuresSuite) TestRegisteredFeatures(c *gc.C) {
+	c.Assert(environs.RegisteredFeatures(), jc.DeepEquals, []environs.Feature{
+		environs.FeatureAvailabilityZones,
+		environs.FeatureGlobalFirewall,
+		environs.FeatureManagedDisks,
+		environs.FeatureSpaces,
+		environs.FeatureSuspendInstances,
+		environs.FeatureVolumes,
+	})
+}
+
+func (s *featuresSuite) TestFeatureDescription(c *gc.C) {
+	description, err := environs.FeatureDescription(environs.FeatureVolumes)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(description, gc.Equals, "storage volumes may be provisioned")
+}
+
+func (s *featuresSuite) TestFeatureDescriptionNotFound(c *gc.C) {
+	_, err := environs.FeatureDescription("teleportation")
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+	c.Assert(err, gc.ErrorMatches, `feature "teleportation" not found`)
+}
+
+func (s *featuresSuite) TestRegisterFeatureDuplicate(c *gc.C) {
+	c.Assert(func() {
+		environs.RegisterFeature(environs.FeatureSpaces, "again")
+	}, gc.PanicMatches, `feature "spaces" already registered`)
+}
+
+func (s *featuresSuite) TestSupportedFeatures(c *gc.C) {
+	env := &featureEnumeratorEnviron{features: []environs.Feature{
+		environs.FeatureVolumes,
+		environs.FeatureSpaces,
+	}}
+	features, err := environs.SupportedFeatures(env)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(features, jc.DeepEquals, []environs.Feature{
+		environs.FeatureSpaces,
+		environs.FeatureVolumes,
+	})
+}
+
+func (s *featuresSuite) TestSupportedFeaturesUnregistered(c *gc.C) {
+	env := &featureEnumeratorEnviron{features: []environs.Feature{"teleportation"}}
+	_, err := environs.SupportedFeatures(env)
+	c.Assert(err, gc.ErrorMatches, `enumerating features: feature "teleportation" not found`)
+}
+
+func (s *featuresSuite) TestSupportedFeaturesError(c *gc.C) {
+	env := &featureEnumeratorEnviron{err: errors.New("boom")}
+	_, err := environs.SupportedFeatures(env)
+	c.Assert(err, gc.ErrorMatches, "enumerating features: boom")
+}
+
+func (s *featuresSuite) TestSupportedFeaturesNotSupported(c *gc.C) {
+	_, err := environs.SupportedFeatures(plainEnviron{})
+	c.Assert(err, jc.Satisfies, errors.IsNotSupported)
+}
+
+type featureEnumeratorEnviron struct {
+	environs.Environ
+	features []environs.Feature
+	err      error
+}
+
+func (env *featureEnumeratorEnviron) SupportedFeatures() ([]environs.Feature, error) {
+	return env.features, env.err
+}
@@ -216,6 +216,10 @@ type ImageMetadata struct {
 	RegionName  string `json:"region,omitempty"`
 	Endpoint    string `json:"endpoint,omitempty"`
 	Stream      string `json:"-"`
+
+	// MinRootDisk is the minimum size of root disk, in MiB,
+	// that the image may be run with, if any.
+	MinRootDisk uint64 `json:"min_root_disk,omitempty"`
 }
 
 func (im *ImageMetadata) String() string {
@@ -6,6 +6,7 @@ package instances
 import (
 	"fmt"
 	"sort"
+	"strings"
 
 	"github.com/juju/loggo"
 	"github.com/juju/utils/arch"
@@ -111,7 +112,38 @@ func FindInstanceSpec(possibleImages []Image, ic *InstanceConstraint, allInstanc
 	for i, itype := range matchingTypes {
 		names[i] = itype.Name
 	}
-	return nil, fmt.Errorf("no %q images in %s matching instance types %v", ic.Series, ic.Region, names)
+	reasons := mismatchReasons(possibleImages, matchingTypes)
+	if len(reasons) == 0 {
+		return nil, fmt.Errorf("no %q images in %s matching instance types %v", ic.Series, ic.Region, names)
+	}
+	return nil, fmt.Errorf(
+		"no %q images in %s matching instance types %v: %s",
+		ic.Series, ic.Region, names, strings.Join(reasons, "; "),
+	)
+}
+
+// mismatchReasons returns descriptions of the requirements of the
+// images that are not met by the instance types that they could
+// otherwise run on, to explain why no instance spec was found.
+func mismatchReasons(images []Image, itypes []InstanceType) []string {
+	var reasons []string
+	reasonTypes := make(map[string][]string)
+	for _, image := range images {
+		for _, itype := range itypes {
+			reason := image.unmetRequirement(itype)
+			if reason == "" {
+				continue
+			}
+			if _, ok := reasonTypes[reason]; !ok {
+				reasons = append(reasons, reason)
+			}
+			reasonTypes[reason] = append(reasonTypes[reason], itype.Name)
+		}
+	}
+	for i, reason := range reasons {
+		reasons[i] = fmt.Sprintf("%s, not met by instance types %v", reason, reasonTypes[reason])
+	}
+	return reasons
 }
 
 // byArch sorts InstanceSpecs first by descending word-size, then
@@ -156,6 +188,12 @@ type Image struct {
 	Arch string
 	// The type of virtualisation supported by this image.
 	VirtType string
+	// MinRootDisk is the minimum size of root disk, in MiB, that
+	// the image may be run with, or zero if the image does not
+	// require one. Instance types with smaller root disks cannot
+	// run the image, and providers that can size root disks must
+	// give instances at least this much.
+	MinRootDisk uint64
 }
 
 type imageMatch int
@@ -171,6 +209,9 @@ func (image Image) match(itype InstanceType) imageMatch {
 	if !image.matchArch(itype.Arches) {
 		return nonMatch
 	}
+	if !image.matchRootDisk(itype) {
+		return nonMatch
+	}
 	if itype.VirtType == nil || image.VirtType == *itype.VirtType {
 		return exactMatch
 	}
@@ -182,6 +223,36 @@ func (image Image) match(itype InstanceType) imageMatch {
 	return nonMatch
 }
 
+// matchRootDisk reports whether the instance type's root disk is large
+// enough for the image. Instance types that do not specify a root disk
+// size are assumed to be able to run any image.
+func (image Image) matchRootDisk(itype InstanceType) bool {
+	return itype.RootDisk == 0 || itype.RootDisk >= image.MinRootDisk
+}
+
+// unmetRequirement describes the image's requirement that prevents it
+// from running on the supplied instance type, or returns the empty
+// string if there is none. Images are not considered to require an
+// architecture, as images of other architectures are expected.
+func (image Image) unmetRequirement(itype InstanceType) string {
+	if !image.matchArch(itype.Arches) {
+		return ""
+	}
+	if !image.matchRootDisk(itype) {
+		return fmt.Sprintf(
+			"image %q requires a root disk of at least %dM",
+			image.Id, image.MinRootDisk,
+		)
+	}
+	if image.match(itype) == nonMatch {
+		return fmt.Sprintf(
+			"image %q requires virt-type %q",
+			image.Id, image.VirtType,
+		)
+	}
+	return ""
+}
+
 func (image Image) matchArch(arches []string) bool {
 	for _, arch := range arches {
 		if arch == image.Arch {
@@ -198,9 +269,10 @@ func ImageMetadataToImages(inputs []*imagemetadata.ImageMetadata) []Image {
 	result := make([]Image, len(inputs))
 	for index, input := range inputs {
 		result[index] = Image{
-			Id:       input.Id,
-			VirtType: input.VirtType,
-			Arch:     input.Arch,
+			Id:          input.Id,
+			VirtType:    input.VirtType,
+			Arch:        input.Arch,
+			MinRootDisk: input.MinRootDisk,
 		}
 	}
 	return result
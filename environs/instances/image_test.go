@@ -425,6 +425,18 @@ var imageMatchtests = []struct {
 		image: Image{Arch: "amd64", VirtType: "pv"},
 		itype: InstanceType{Arches: []string{"amd64"}, VirtType: &hvm},
 		match: nonMatch,
+	}, {
+		image: Image{Arch: "amd64", MinRootDisk: 8192},
+		itype: InstanceType{Arches: []string{"amd64"}, RootDisk: 8192},
+		match: exactMatch,
+	}, {
+		image: Image{Arch: "amd64", MinRootDisk: 8192},
+		itype: InstanceType{Arches: []string{"amd64"}}, // no known root disk size
+		match: exactMatch,
+	}, {
+		image: Image{Arch: "amd64", MinRootDisk: 8192},
+		itype: InstanceType{Arches: []string{"amd64"}, RootDisk: 4096},
+		match: nonMatch,
 	},
 }
 
@@ -435,6 +447,46 @@ func (s *imageSuite) TestImageMatch(c *gc.C) {
 	}
 }
 
+func (*imageSuite) TestFindInstanceSpecExplainsUnmetRequirements(c *gc.C) {
+	images := []Image{
+		{Id: "image-1", Arch: "amd64", VirtType: "Hyper-V"},
+		{Id: "image-2", Arch: "amd64", MinRootDisk: 8192},
+		{Id: "image-3", Arch: "armhf"},
+	}
+	instanceTypes := []InstanceType{
+		{Name: "it-1", Arches: []string{"amd64"}, VirtType: &hvm, RootDisk: 4096, Mem: 2048},
+		{Name: "it-2", Arches: []string{"amd64"}, VirtType: &hvm, RootDisk: 2048, Mem: 4096},
+	}
+	ic := &InstanceConstraint{
+		Series:      "precise",
+		Region:      "test",
+		Arches:      []string{"amd64"},
+		Constraints: constraints.MustParse(""),
+	}
+	_, err := FindInstanceSpec(images, ic, instanceTypes)
+	c.Assert(err, gc.ErrorMatches, `no "precise" images in test matching instance types \[it-1 it-2\]: `+
+		`image "image-1" requires virt-type "Hyper-V", not met by instance types \[it-1 it-2\]; `+
+		`image "image-2" requires a root disk of at least 8192M, not met by instance types \[it-1 it-2\]`)
+}
+
+func (*imageSuite) TestFindInstanceSpecRootDisk(c *gc.C) {
+	images := []Image{{Id: "image-1", Arch: "amd64", MinRootDisk: 8192}}
+	instanceTypes := []InstanceType{
+		{Name: "it-1", Arches: []string{"amd64"}, RootDisk: 4096, Mem: 2048},
+		{Name: "it-2", Arches: []string{"amd64"}, RootDisk: 16384, Mem: 4096},
+	}
+	ic := &InstanceConstraint{
+		Series:      "precise",
+		Region:      "test",
+		Arches:      []string{"amd64"},
+		Constraints: constraints.MustParse(""),
+	}
+	spec, err := FindInstanceSpec(images, ic, instanceTypes)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(spec.InstanceType.Name, gc.Equals, "it-2")
+	c.Assert(spec.Image.MinRootDisk, gc.Equals, uint64(8192))
+}
+
 func (*imageSuite) TestImageMetadataToImagesAcceptsNil(c *gc.C) {
 	c.Check(ImageMetadataToImages(nil), gc.HasLen, 0)
 }
@@ -449,13 +501,15 @@ func (*imageSuite) TestImageMetadataToImagesConvertsSelectMetadata(c *gc.C) {
 			RegionAlias: "region-alias-is-ignored",
 			RegionName:  "region-name-is-ignored",
 			Endpoint:    "endpoint-is-ignored",
+			MinRootDisk: 8192,
 		},
 	}
 	expectation := []Image{
 		{
-			Id:       "id",
-			VirtType: "vtype",
-			Arch:     "arch",
+			Id:          "id",
+			VirtType:    "vtype",
+			Arch:        "arch",
+			MinRootDisk: 8192,
 		},
 	}
 	c.Check(ImageMetadataToImages(input), gc.DeepEquals, expectation)
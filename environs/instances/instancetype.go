@@ -6,6 +6,7 @@ package instances
 import (
 	"fmt"
 	"sort"
+	"strings"
 
 	"github.com/juju/juju/constraints"
 )
@@ -65,6 +66,9 @@ func (itype InstanceType) match(cons constraints.Value) (InstanceType, bool) {
 	if cons.HasVirtType() && (itype.VirtType == nil || *itype.VirtType != *cons.VirtType) {
 		return nothing, false
 	}
+	if cons.HasMaxHourlyCost() && itype.Cost > *cons.MaxHourlyCost {
+		return nothing, false
+	}
 	return itype, true
 }
 
@@ -81,6 +85,10 @@ func filterArches(src, filter []string) (dst []string) {
 	return dst
 }
 
+// maxCostAlternatives is the maximum number of alternative instance
+// types reported when none match the max-hourly-cost constraint.
+const maxCostAlternatives = 3
+
 // minMemoryHeuristic is the assumed minimum amount of memory (in MB) we prefer in order to run a server (1GB)
 const minMemoryHeuristic = 1024
 
@@ -130,7 +138,26 @@ func MatchingInstanceTypes(allInstanceTypes []InstanceType, region string, cons
 		return itypes, nil
 	}
 
-	// No luck, so report the error.
+	// No luck, so report the error. If a cost ceiling was specified,
+	// report the cheapest instance types that would otherwise have
+	// matched, so the user can decide whether to raise the ceiling.
+	if origCons.HasMaxHourlyCost() {
+		uncapped := origCons
+		uncapped.MaxHourlyCost = nil
+		if alternatives, err := MatchingInstanceTypes(allInstanceTypes, region, uncapped); err == nil {
+			if len(alternatives) > maxCostAlternatives {
+				alternatives = alternatives[:maxCostAlternatives]
+			}
+			descriptions := make([]string, len(alternatives))
+			for i, itype := range alternatives {
+				descriptions[i] = fmt.Sprintf("%s (cost %d)", itype.Name, itype.Cost)
+			}
+			return nil, fmt.Errorf(
+				"no instance types in %s matching constraints %q; cheapest instance types exceeding max-hourly-cost: %s",
+				region, origCons, strings.Join(descriptions, ", "),
+			)
+		}
+	}
 	return nil, fmt.Errorf("no instance types in %s matching constraints %q", region, origCons)
 }
 
@@ -221,6 +221,10 @@ var getInstanceTypesTest = []struct {
 		cons:           "virt-type=hvm",
 		expectedItypes: []string{"cc1.4xlarge", "cc2.8xlarge"},
 		itypesToUse:    nil,
+	}, {
+		about:          "max-hourly-cost filtered by constraint",
+		cons:           "max-hourly-cost=240",
+		expectedItypes: []string{"m1.small", "m1.medium", "c1.medium", "m1.large"},
 	}, {
 		about:          "deprecated image type requested by name",
 		cons:           "instance-type=dep.small",
@@ -269,6 +273,20 @@ func (s *instanceTypeSuite) TestGetMatchingInstanceTypesErrors(c *gc.C) {
 
 	_, err = MatchingInstanceTypes(instanceTypes, "test", constraints.MustParse("instance-type=dep.medium mem=8G"))
 	c.Check(err, gc.ErrorMatches, `no instance types in test matching constraints "instance-type=dep.medium mem=8192M"`)
+
+	_, err = MatchingInstanceTypes(instanceTypes, "test", constraints.MustParse("cores=9000 max-hourly-cost=10"))
+	c.Check(err, gc.ErrorMatches, `no instance types in test matching constraints "cores=9000 max-hourly-cost=10"`)
+}
+
+func (s *instanceTypeSuite) TestGetMatchingInstanceTypesCostAlternatives(c *gc.C) {
+	_, err := MatchingInstanceTypes(instanceTypes, "test", constraints.MustParse("cores=8 max-hourly-cost=500"))
+	c.Check(err, gc.ErrorMatches, `no instance types in test matching constraints "cores=8 max-hourly-cost=500"; `+
+		`cheapest instance types exceeding max-hourly-cost: c1.xlarge \(cost 580\), cc1.4xlarge \(cost 1300\), cc2.8xlarge \(cost 2400\)`)
+
+	// At most three alternatives are reported.
+	_, err = MatchingInstanceTypes(instanceTypes, "test", constraints.MustParse("mem=4G max-hourly-cost=1"))
+	c.Check(err, gc.ErrorMatches, `no instance types in test matching constraints "mem=4096M max-hourly-cost=1"; `+
+		`cheapest instance types exceeding max-hourly-cost: m1.large \(cost 240\), m1.xlarge \(cost 480\), c1.xlarge \(cost 580\)`)
 }
 
 var instanceTypeMatchTests = []struct {
@@ -286,12 +304,14 @@ var instanceTypeMatchTests = []struct {
 	{"cpu-power=2000", "c1.xlarge", []string{"amd64"}},
 	{"cpu-power=2001", "cc1.4xlarge", []string{"amd64"}},
 	{"mem=2G", "m1.medium", []string{"amd64", "armhf"}},
+	{"max-hourly-cost=60", "m1.small", []string{"amd64", "armhf"}},
 
 	{"arch=i386", "m1.small", nil},
 	{"cpu-power=100", "t1.micro", nil},
 	{"cpu-power=9001", "cc2.8xlarge", nil},
 	{"mem=1G", "t1.micro", nil},
 	{"arch=armhf", "c1.xlarge", nil},
+	{"max-hourly-cost=59", "m1.small", nil},
 }
 
 func (s *instanceTypeSuite) TestMatch(c *gc.C) {
@@ -0,0 +1,57 @@
+// Copyright 2016 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package environs
+
+import (
+	"github.com/juju/juju/constraints"
+)
+
+// PreviewInstanceSpecParams holds the parameters for
+// InstanceSpecPreviewer.PreviewInstanceSpec.
+type PreviewInstanceSpecParams struct {
+	// Series is the series of the machine's operating system.
+	Series string
+
+	// Constraints are the machine's constraints, already
+	// merged with the model's constraints.
+	Constraints constraints.Value
+
+	// Placement is the machine's provider-specific placement
+	// directive, if any.
+	Placement string
+}
+
+// InstanceSpecPreview describes the instance that a provider would
+// start for a machine.
+type InstanceSpecPreview struct {
+	// InstanceType is the name of the instance type.
+	InstanceType string
+
+	// ImageId is the provider-specific ID of the image.
+	ImageId string
+
+	// Arch is the architecture of the image.
+	Arch string
+
+	// Cost is the relative cost of the instance type, as reported
+	// by the provider's instance types; zero if unknown.
+	Cost uint64
+
+	// AvailabilityZone is the availability zone in which the
+	// instance would be started, or the empty string if the
+	// provider has no zones, or chooses one only when the
+	// instance is started.
+	AvailabilityZone string
+}
+
+// InstanceSpecPreviewer is an interface that may be implemented by an
+// Environ to report the instance it would start for a machine, without
+// starting it, so that users may check how constraints are resolved
+// before adding machines.
+type InstanceSpecPreviewer interface {
+	// PreviewInstanceSpec returns a description of the instance that
+	// StartInstance would start for a machine with the given series,
+	// constraints and placement. No resources are created.
+	PreviewInstanceSpec(PreviewInstanceSpecParams) (*InstanceSpecPreview, error)
+}
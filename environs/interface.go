@@ -155,6 +155,81 @@ type ModelConfigUpgrader interface {
 	UpgradeConfig(cfg *config.Config) (*config.Config, error)
 }
 
+// CredentialValidator is an interface that an EnvironProvider may
+// implement in order to validate cloud credentials before they are
+// used, e.g. when a credential is updated on a running controller.
+type CredentialValidator interface {
+	// ValidateCredential checks that the credential in the given
+	// cloud spec may be used to authenticate with the cloud. This
+	// may involve acquiring an access token, and making one or more
+	// non-modifying requests to the cloud.
+	ValidateCredential(spec CloudSpec) error
+}
+
+// RequestTracer is an interface that an EnvironProvider may implement
+// in order to report the requests it has made to the cloud on behalf
+// of machines, e.g. for debugging slow provisioning.
+type RequestTracer interface {
+	// WriteRequestTrace writes a human-readable trace of the requests
+	// made on behalf of the machine with the given tag, in the model
+	// with the given UUID, to w. Machine tags are only unique within
+	// a model: if the model UUID is empty, the requests made for
+	// machines with the tag in all models are written. If the tag is
+	// empty, the requests made for all machines are written. If no
+	// requests have been traced, an error satisfying
+	// errors.IsNotFound is returned.
+	WriteRequestTrace(w io.Writer, modelUUID, machineTag string) error
+}
+
+// InstanceIdentifier is an interface that an Environ may implement
+// in order for agents to discover the identity of the instance they
+// are running on, e.g. by querying a metadata service that is
+// reachable only from instances, without credentials.
+type InstanceIdentifier interface {
+	// IdentifyInstance returns the identity of the instance that
+	// the caller is running on. An error satisfying errors.IsNotFound
+	// is returned if the caller is not running on one of the environ's
+	// instances; instance IDs need not be unique outside the environ,
+	// so implementations must check that the instance belongs to it.
+	IdentifyInstance() (InstanceIdentity, error)
+}
+
+// InstanceIdentity identifies an instance, as discovered
+// by the instance itself.
+type InstanceIdentity struct {
+	// InstanceId is the provider-specific ID of the instance.
+	InstanceId instance.Id
+
+	// Region is the name of the cloud region that the
+	// instance is running in.
+	Region string
+}
+
+// CloudSpecSetter is an interface that an Environ may implement in
+// order to have its cloud spec updated without being reopened, e.g.
+// when the model's cloud credential is rotated.
+type CloudSpecSetter interface {
+	// SetCloudSpec updates the Environ's cloud spec. Calls to
+	// SetCloudSpec do not affect the cloud specs of other Environs.
+	SetCloudSpec(spec CloudSpec) error
+}
+
+// InvalidateCredentialFunc records that the cloud has rejected the
+// credential of the model that an Environ was opened for, for the given
+// reason, so that the model can be suspended until the credential is
+// updated.
+type InvalidateCredentialFunc func(reason string) error
+
+// CredentialInvalidatorSetter is an interface that an Environ may
+// implement in order to report that the cloud has rejected its
+// credential, e.g. because a service principal's secret has expired.
+type CredentialInvalidatorSetter interface {
+	// SetCredentialInvalidator sets the function that the Environ
+	// calls when the cloud rejects its credential. Requests that
+	// are rejected continue to return errors as usual.
+	SetCredentialInvalidator(InvalidateCredentialFunc)
+}
+
 // ConfigGetter implements access to an environment's configuration.
 type ConfigGetter interface {
 	// Config returns the configuration data with which the Environ was created.
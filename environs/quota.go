@@ -0,0 +1,109 @@
+// Copyright 2016 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package environs
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Names of the resources for which quotas may be reported.
+const (
+	// QuotaCores is the number of CPU cores that may be
+	// allocated to instances.
+	QuotaCores = "cores"
+
+	// QuotaVirtualMachines is the number of instances that
+	// may be allocated.
+	QuotaVirtualMachines = "virtual-machines"
+
+	// QuotaPublicIPAddresses is the number of public IP
+	// addresses that may be allocated.
+	QuotaPublicIPAddresses = "public-ip-addresses"
+
+	// QuotaPremiumDisks is the number of premium (SSD-backed)
+	// disks that may be allocated.
+	QuotaPremiumDisks = "premium-disks"
+
+	// QuotaStorageAccounts is the number of storage accounts
+	// that may be allocated.
+	QuotaStorageAccounts = "storage-accounts"
+)
+
+// Quota describes the usage and limit of a resource that is subject
+// to a quota in the environment's region.
+type Quota struct {
+	// Resource is the name of the resource, e.g. QuotaCores.
+	// Providers may report quotas for resources other than
+	// those named by the Quota* constants.
+	Resource string
+
+	// Used is the amount of the resource currently in use.
+	Used int64
+
+	// Limit is the maximum amount of the resource that
+	// may be in use.
+	Limit int64
+}
+
+// Remaining returns the amount of the resource that may still be
+// allocated before the quota is reached.
+func (q Quota) Remaining() int64 {
+	if q.Used >= q.Limit {
+		return 0
+	}
+	return q.Limit - q.Used
+}
+
+// String returns a user-readable description of the quota.
+func (q Quota) String() string {
+	return fmt.Sprintf("%s quota: %d of %d used", q.Resource, q.Used, q.Limit)
+}
+
+// QuotaInfo is an interface that may be implemented by an Environ to
+// report the quotas that limit the resources that may be allocated in
+// the environment's region.
+type QuotaInfo interface {
+	// Quotas returns the quotas applying to the environment's
+	// region. Providers report only the quotas that they are able
+	// to determine; there is no guarantee that a quota will be
+	// reported for each of the resources named by the Quota*
+	// constants.
+	Quotas() ([]Quota, error)
+}
+
+// ExhaustedQuotas returns a user-readable description of the quotas
+// in the given list that have no remaining capacity, or the empty
+// string if there are none.
+func ExhaustedQuotas(quotas []Quota) string {
+	return InsufficientQuotas(quotas, nil)
+}
+
+// InsufficientQuotas returns a user-readable description of the quotas
+// in the given list that have too little remaining capacity for a
+// request, or the empty string if there are none. The required map
+// holds the amount of each resource that the request needs; quotas
+// for resources not in the map are insufficient only if they have no
+// remaining capacity.
+func InsufficientQuotas(quotas []Quota, required map[string]int64) string {
+	var insufficient []string
+	for _, q := range quotas {
+		if q.Limit <= 0 {
+			continue
+		}
+		need, ok := required[q.Resource]
+		if !ok || need < 1 {
+			need = 1
+		}
+		if q.Remaining() >= need {
+			continue
+		}
+		description := q.String()
+		if ok && need > 1 {
+			description = fmt.Sprintf("%s, %d required", description, need)
+		}
+		insufficient = append(insufficient, description)
+	}
+	return strings.Join(insufficient, ", ")
+}
@@ -0,0 +1,51 @@
+// Copyright 2016 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package environs_test
+
+import (
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/environs"
+)
+
+type quotaSuite struct{}
+
+var _ = gc.Suite(&quotaSuite{})
+
+func (s *quotaSuite) TestRemaining(c *gc.C) {
+	c.Assert(environs.Quota{Used: 3, Limit: 10}.Remaining(), gc.Equals, int64(7))
+	c.Assert(environs.Quota{Used: 10, Limit: 10}.Remaining(), gc.Equals, int64(0))
+	c.Assert(environs.Quota{Used: 12, Limit: 10}.Remaining(), gc.Equals, int64(0))
+}
+
+func (s *quotaSuite) TestExhaustedQuotas(c *gc.C) {
+	quotas := []environs.Quota{
+		{Resource: environs.QuotaCores, Used: 20, Limit: 20},
+		{Resource: environs.QuotaPublicIPAddresses, Used: 1, Limit: 20},
+		{Resource: environs.QuotaPremiumDisks, Used: 0, Limit: 0},
+		{Resource: "virtual-machines", Used: 21, Limit: 20},
+	}
+	c.Assert(environs.ExhaustedQuotas(quotas), gc.Equals,
+		"cores quota: 20 of 20 used, virtual-machines quota: 21 of 20 used",
+	)
+	c.Assert(environs.ExhaustedQuotas(quotas[1:3]), gc.Equals, "")
+}
+
+func (s *quotaSuite) TestInsufficientQuotas(c *gc.C) {
+	quotas := []environs.Quota{
+		{Resource: environs.QuotaCores, Used: 18, Limit: 20},
+		{Resource: environs.QuotaVirtualMachines, Used: 3, Limit: 20},
+		{Resource: "standardDFamily", Used: 10, Limit: 10},
+		{Resource: environs.QuotaStorageAccounts, Used: 5, Limit: 100},
+	}
+	required := map[string]int64{
+		environs.QuotaCores:           4,
+		environs.QuotaVirtualMachines: 1,
+	}
+	c.Assert(environs.InsufficientQuotas(quotas, required), gc.Equals,
+		"cores quota: 18 of 20 used, 4 required, standardDFamily quota: 10 of 10 used",
+	)
+	required[environs.QuotaCores] = 2
+	c.Assert(environs.InsufficientQuotas(quotas[:2], required), gc.Equals, "")
+}
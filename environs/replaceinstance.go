@@ -0,0 +1,51 @@
+// Copyright 2016 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package environs
+
+import (
+	"github.com/juju/juju/instance"
+	"github.com/juju/juju/storage"
+)
+
+// ReplaceInstanceParams holds the parameters for
+// InstanceReplacer.ReplaceInstance.
+type ReplaceInstanceParams struct {
+	// StartInstanceParams holds the parameters with which the
+	// replacement instance is started. The instance config must be
+	// freshly rendered, with a new nonce and the current API
+	// addresses and agent version.
+	StartInstanceParams
+
+	// InstanceId is the ID of the instance being replaced.
+	InstanceId instance.Id
+
+	// VolumeAttachments holds the parameters for attaching the
+	// machine's existing volumes to the replacement instance.
+	VolumeAttachments []storage.VolumeAttachmentParams
+}
+
+// InstanceReplacer is an interface that may be implemented by an
+// Environ to start a replacement for a machine's instance that no
+// longer exists, e.g. because it was deleted outside of Juju, with the
+// machine's existing volumes attached.
+type InstanceReplacer interface {
+	// CheckReplaceable returns an error satisfying
+	// errors.IsAlreadyExists if the instance with the given ID
+	// still exists, and so cannot be replaced.
+	CheckReplaceable(instance.Id) error
+
+	// ReplaceInstance starts an instance to replace the instance
+	// with the given ID, attaching to it the given volumes. If the
+	// instance being replaced still exists, an error satisfying
+	// errors.IsAlreadyExists is returned and no instance is started.
+	//
+	// The result's VolumeAttachments describe the attachments of
+	// the existing volumes to the replacement instance.
+	ReplaceInstance(ReplaceInstanceParams) (*StartInstanceResult, error)
+
+	// DeleteReplacement deletes an instance started by
+	// ReplaceInstance, leaving the volumes attached to it intact.
+	// It is used when the replacement cannot be recorded.
+	DeleteReplacement(instance.Id) error
+}
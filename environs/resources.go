@@ -0,0 +1,109 @@
+// Copyright 2016 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package environs
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/juju/errors"
+)
+
+// Resource describes a cloud resource that a provider created for
+// a model, e.g. a virtual machine, disk or network interface.
+type Resource struct {
+	// Type is the provider-specific type of the resource,
+	// e.g. "Microsoft.Compute/virtualMachines".
+	Type string
+
+	// Id is the provider-specific ID of the resource.
+	Id string
+
+	// Tags holds the tags associated with the resource.
+	Tags map[string]string
+}
+
+// String returns a user-readable description of the resource.
+func (r Resource) String() string {
+	return fmt.Sprintf("%s %s", r.Type, r.Id)
+}
+
+// ResourceEnumerator is an interface that may be implemented by an
+// Environ to enumerate the cloud resources that the provider created
+// for the model.
+//
+// ResourceEnumerator forms a cleanup contract: once the Environ's
+// Destroy method has returned successfully, AllResources must return
+// no resources. Anything it does return has been leaked.
+type ResourceEnumerator interface {
+	// AllResources returns all of the resources that the provider
+	// created for the model, sorted by type and ID.
+	AllResources() ([]Resource, error)
+}
+
+// RemainingResources returns the resources that remain for the given
+// Environ's model, e.g. after the Environ has been destroyed. If the
+// Environ does not implement ResourceEnumerator, RemainingResources
+// returns an error satisfying errors.IsNotSupported.
+func RemainingResources(env Environ) ([]Resource, error) {
+	enumerator, ok := env.(ResourceEnumerator)
+	if !ok {
+		return nil, errors.NotSupportedf("enumerating resources")
+	}
+	resources, err := enumerator.AllResources()
+	if err != nil {
+		return nil, errors.Annotate(err, "enumerating resources")
+	}
+	return resources, nil
+}
+
+// MachineResourceSweeper is an interface that may be implemented by
+// an Environ to delete the cloud resources left behind by machines
+// that no longer exist, e.g. because they were force-destroyed, or
+// failed to provision.
+type MachineResourceSweeper interface {
+	// SweepMachineResources deletes the unused resources that the
+	// provider created for machines whose IDs are not returned by
+	// liveMachines, and returns the deleted resources.
+	//
+	// The provider must list its resources before calling
+	// liveMachines, so that the resources of a machine added in
+	// the meantime are not mistaken for orphans. Machine IDs are
+	// never reused, so a machine that is not live after the
+	// resources are listed will never be live again.
+	SweepMachineResources(liveMachines func() ([]string, error)) ([]Resource, error)
+}
+
+// SweepMachineResources deletes the unused resources left behind by
+// machines of the given Environ's model that are not returned by
+// liveMachines, and returns the deleted resources. If the Environ does
+// not implement MachineResourceSweeper, SweepMachineResources returns
+// an error satisfying errors.IsNotSupported.
+func SweepMachineResources(env Environ, liveMachines func() ([]string, error)) ([]Resource, error) {
+	sweeper, ok := env.(MachineResourceSweeper)
+	if !ok {
+		return nil, errors.NotSupportedf("sweeping machine resources")
+	}
+	resources, err := sweeper.SweepMachineResources(liveMachines)
+	if err != nil {
+		return nil, errors.Annotate(err, "sweeping machine resources")
+	}
+	return resources, nil
+}
+
+// SortResources sorts the given resources by type, and then by ID.
+func SortResources(resources []Resource) {
+	sort.Sort(resourcesByTypeAndId(resources))
+}
+
+type resourcesByTypeAndId []Resource
+
+func (r resourcesByTypeAndId) Len() int      { return len(r) }
+func (r resourcesByTypeAndId) Swap(i, j int) { r[i], r[j] = r[j], r[i] }
+func (r resourcesByTypeAndId) Less(i, j int) bool {
+	if r[i].Type != r[j].Type {
+		return r[i].Type < r[j].Type
+	}
+	return r[i].Id < r[j].Id
+}
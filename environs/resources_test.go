@@ -0,0 +1,114 @@
+// Copyright 2016 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package environs_test
+
+import (
+	"github.com/juju/errors"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/environs"
+)
+
+type resourcesSuite struct{}
+
+var _ = gc.Suite(&resourcesSuite{})
+
+func (s *resourcesSuite) TestSortResources(c *gc.C) {
+	resources := []environs.Resource{
+		{Type: "vm", Id: "b"},
+		{Type: "disk", Id: "c"},
+		{Type: "vm", Id: "a"},
+	}
+	environs.SortResources(resources)
+	c.Assert(resources, jc.DeepEquals, []environs.Resource{
+		{Type: "disk", Id: "c"},
+		{Type: "vm", Id: "a"},
+		{Type: "vm", Id: "b"},
+	})
+}
+
+func (s *resourcesSuite) TestResourceString(c *gc.C) {
+	r := environs.Resource{Type: "vm", Id: "machine-0"}
+	c.Assert(r.String(), gc.Equals, "vm machine-0")
+}
+
+func (s *resourcesSuite) TestRemainingResources(c *gc.C) {
+	env := &resourceEnumeratorEnviron{resources: []environs.Resource{
+		{Type: "vm", Id: "machine-0"},
+	}}
+	resources, err := environs.RemainingResources(env)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(resources, jc.DeepEquals, env.resources)
+}
+
+func (s *resourcesSuite) TestRemainingResourcesError(c *gc.C) {
+	env := &resourceEnumeratorEnviron{err: errors.New("boom")}
+	_, err := environs.RemainingResources(env)
+	c.Assert(err, gc.ErrorMatches, "enumerating resources: boom")
+}
+
+func (s *resourcesSuite) TestRemainingResourcesNotSupported(c *gc.C) {
+	_, err := environs.RemainingResources(plainEnviron{})
+	c.Assert(err, jc.Satisfies, errors.IsNotSupported)
+}
+
+func (s *resourcesSuite) TestSweepMachineResources(c *gc.C) {
+	env := &machineResourceSweeperEnviron{resources: []environs.Resource{
+		{Type: "nic", Id: "machine-1-primary"},
+	}}
+	liveMachines := func() ([]string, error) { return []string{"0"}, nil }
+	resources, err := environs.SweepMachineResources(env, liveMachines)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(resources, jc.DeepEquals, env.resources)
+	c.Assert(env.live, jc.DeepEquals, []string{"0"})
+}
+
+func (s *resourcesSuite) TestSweepMachineResourcesError(c *gc.C) {
+	env := &machineResourceSweeperEnviron{err: errors.New("boom")}
+	liveMachines := func() ([]string, error) { return nil, nil }
+	_, err := environs.SweepMachineResources(env, liveMachines)
+	c.Assert(err, gc.ErrorMatches, "sweeping machine resources: boom")
+}
+
+func (s *resourcesSuite) TestSweepMachineResourcesNotSupported(c *gc.C) {
+	liveMachines := func() ([]string, error) {
+		panic("unexpected call")
+	}
+	_, err := environs.SweepMachineResources(plainEnviron{}, liveMachines)
+	c.Assert(err, jc.Satisfies, errors.IsNotSupported)
+}
+
+type plainEnviron struct {
+	environs.Environ
+}
+
+type resourceEnumeratorEnviron struct {
+	environs.Environ
+	resources []environs.Resource
+	err       error
+}
+
+func (env *resourceEnumeratorEnviron) AllResources() ([]environs.Resource, error) {
+	return env.resources, env.err
+}
+
+type machineResourceSweeperEnviron struct {
+	environs.Environ
+	resources []environs.Resource
+	live      []string
+	err       error
+}
+
+func (env *machineResourceSweeperEnviron) SweepMachineResources(liveMachines func() ([]string, error)) ([]environs.Resource, error) {
+	if env.err != nil {
+		return nil, env.err
+	}
+	live, err := liveMachines()
+	if err != nil {
+		return nil, err
+	}
+	env.live = live
+	return env.resources, nil
+}
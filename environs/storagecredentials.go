@@ -0,0 +1,24 @@
+// Copyright 2016 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package environs
+
+// StorageCredentialRotator is an interface that may be implemented by
+// an Environ whose provider issues credentials for the storage it uses
+// to hold the model's disks and other data, so that the credentials
+// may be replaced periodically, or if they are compromised.
+type StorageCredentialRotator interface {
+	// RotateStorageCredentials replaces the credentials used to
+	// access the Environ's storage, without interrupting access.
+	// New credentials are issued and put to use before the old
+	// credentials are revoked.
+	//
+	// Once the Environ has switched to the new credentials, and
+	// before the old credentials are revoked, recordCredentials is
+	// called with model config attributes identifying the new
+	// credentials. recordCredentials must update the model config,
+	// so that other Environs for the model switch to them too. If
+	// recordCredentials returns an error, the old credentials are
+	// not revoked.
+	RotateStorageCredentials(recordCredentials func(attrs map[string]interface{}) error) error
+}
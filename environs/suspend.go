@@ -0,0 +1,26 @@
+// Copyright 2016 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package environs
+
+import (
+	"github.com/juju/juju/instance"
+)
+
+// InstanceSuspender is an interface that may be implemented by an
+// Environ to stop instances without destroying them, and to start
+// them again later. Suspended instances keep their disks, network
+// interfaces and identity, but do not consume compute resources;
+// on clouds that bill only for running instances, this makes it
+// possible to reduce the cost of models that are not always in use.
+type InstanceSuspender interface {
+	// SuspendInstances stops the specified instances, releasing their
+	// compute resources but retaining everything required to resume
+	// them. Suspending an instance that is already suspended is not
+	// an error.
+	SuspendInstances(ids ...instance.Id) error
+
+	// ResumeInstances starts the specified suspended instances.
+	// Resuming an instance that is running is not an error.
+	ResumeInstances(ids ...instance.Id) error
+}
@@ -0,0 +1,105 @@
+// Copyright 2016 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package tools
+
+import (
+	"time"
+
+	"github.com/juju/utils"
+
+	"github.com/juju/juju/environs"
+	"github.com/juju/juju/environs/simplestreams"
+	coretools "github.com/juju/juju/tools"
+)
+
+// ControllerMirrorPath is the path, relative to the root of a controller's
+// API server, at which the controller serves simplestreams metadata for
+// the agent binaries cached in its tools storage. The agent binaries
+// themselves are served beneath the same path, so the controller's mirror
+// may be used as a simplestreams datasource in its own right.
+const ControllerMirrorPath = "/tools-mirror"
+
+// ControllerMirrorEndpoint is the simplestreams cloud endpoint that
+// identifies a controller's tools mirror. Environs that should look up
+// agent binaries in the controller's mirror, rather than any public
+// mirror, report it as their AgentMirror endpoint.
+const ControllerMirrorEndpoint = "juju-controller"
+
+// ControllerMirrorURL returns the URL of the tools mirror served by the
+// controller API server with the given address.
+func ControllerMirrorURL(apiAddress string) string {
+	return "https://" + apiAddress + ControllerMirrorPath
+}
+
+// FindControllerMirrorTools is like FindTools, but if the environ's
+// AgentMirror is the controller's tools mirror, the agent binaries are
+// looked up in the mirrors with the given URLs before any of the
+// environ's other metadata sources.
+//
+// The mirrors' metadata is unsigned, and the API servers' certificates
+// are not issued for their addresses, so hostnames are not verified.
+func FindControllerMirrorTools(
+	env environs.Environ, mirrorURLs []string,
+	majorVersion, minorVersion int, stream string, filter coretools.Filter,
+) (_ coretools.List, err error) {
+	cloudSpec, err := toolsCloudSpec(env)
+	if err != nil {
+		return nil, err
+	}
+	if cloudSpec.Endpoint != ControllerMirrorEndpoint || len(mirrorURLs) == 0 {
+		return FindTools(env, majorVersion, minorVersion, stream, filter)
+	}
+	logger.Infof("finding agent binaries in the controller's mirror, in stream %q", stream)
+	defer convertToolsError(&err)
+	var sources []simplestreams.DataSource
+	for _, url := range mirrorURLs {
+		sources = append(sources, simplestreams.NewURLDataSource(
+			"controller tools mirror", url, utils.NoVerifySSLHostnames,
+			simplestreams.SPECIFIC_CLOUD_DATA, false,
+		))
+	}
+	envSources, err := GetMetadataSources(env)
+	if err != nil {
+		return nil, err
+	}
+	sources = append(sources, envSources...)
+	return FindToolsForCloud(sources, cloudSpec, stream, majorVersion, minorVersion, filter)
+}
+
+// MarshalControllerMirrorMetadata returns the simplestreams metadata files
+// for a controller's tools mirror, describing the given agent binaries in
+// the specified stream. The files' paths are relative to the root of the
+// mirror; the agent binaries are referred to by the paths at which the
+// controller serves them. updated is the time at which the metadata was
+// generated.
+func MarshalControllerMirrorMetadata(toolsList coretools.List, stream string, updated time.Time) ([]MetadataFile, error) {
+	metadata := make([]*ToolsMetadata, len(toolsList))
+	for i, t := range toolsList {
+		metadata[i] = &ToolsMetadata{
+			Release:  t.Version.Series,
+			Version:  t.Version.Number.String(),
+			Arch:     t.Version.Arch,
+			Path:     "tools/" + t.Version.String(),
+			FileType: "tar.gz",
+			Size:     t.Size,
+			SHA256:   t.SHA256,
+		}
+	}
+	Sort(metadata)
+	streamMetadata := map[string][]*ToolsMetadata{stream: metadata}
+	index, legacyIndex, products, err := MarshalToolsMetadataJSON(streamMetadata, updated)
+	if err != nil {
+		return nil, err
+	}
+	files := []MetadataFile{
+		{simplestreams.UnsignedIndex(currentStreamsVersion, IndexFileVersion), index},
+	}
+	if legacyIndex != nil {
+		files = append(files, MetadataFile{
+			simplestreams.UnsignedIndex(currentStreamsVersion, 1), legacyIndex,
+		})
+	}
+	files = append(files, MetadataFile{ProductMetadataPath(stream), products[stream]})
+	return files, nil
+}
@@ -0,0 +1,180 @@
+// Copyright 2016 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package tools_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	jc "github.com/juju/testing/checkers"
+	"github.com/juju/utils"
+	"github.com/juju/version"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/environs"
+	"github.com/juju/juju/environs/config"
+	"github.com/juju/juju/environs/simplestreams"
+	"github.com/juju/juju/environs/tools"
+	coretesting "github.com/juju/juju/testing"
+	coretools "github.com/juju/juju/tools"
+)
+
+type mirrorSuite struct {
+	coretesting.BaseSuite
+}
+
+var _ = gc.Suite(&mirrorSuite{})
+
+func (s *mirrorSuite) TestControllerMirrorURL(c *gc.C) {
+	c.Assert(tools.ControllerMirrorURL("10.0.0.1:17070"), gc.Equals, "https://10.0.0.1:17070/tools-mirror")
+}
+
+func (s *mirrorSuite) TestMarshalControllerMirrorMetadataPaths(c *gc.C) {
+	files, err := tools.MarshalControllerMirrorMetadata(nil, "released", time.Unix(0, 0).UTC())
+	c.Assert(err, jc.ErrorIsNil)
+	paths := make([]string, len(files))
+	for i, f := range files {
+		paths[i] = f.Path
+	}
+	c.Assert(paths, jc.DeepEquals, []string{
+		"streams/v1/index2.json",
+		"streams/v1/index.json",
+		"streams/v1/com.ubuntu.juju-released-tools.json",
+	})
+
+	files, err = tools.MarshalControllerMirrorMetadata(nil, "devel", time.Unix(0, 0).UTC())
+	c.Assert(err, jc.ErrorIsNil)
+	paths = make([]string, len(files))
+	for i, f := range files {
+		paths[i] = f.Path
+	}
+	c.Assert(paths, jc.DeepEquals, []string{
+		"streams/v1/index2.json",
+		"streams/v1/com.ubuntu.juju-devel-tools.json",
+	})
+}
+
+func (s *mirrorSuite) TestMarshalControllerMirrorMetadata(c *gc.C) {
+	toolsList := coretools.List{{
+		Version: version.MustParseBinary("2.0.1-xenial-amd64"),
+		Size:    123,
+		SHA256:  "abc",
+	}, {
+		Version: version.MustParseBinary("2.0.1-trusty-amd64"),
+		Size:    456,
+		SHA256:  "def",
+	}, {
+		Version: version.MustParseBinary("1.25.6-trusty-amd64"),
+		Size:    789,
+		SHA256:  "ghi",
+	}}
+	files, err := tools.MarshalControllerMirrorMetadata(toolsList, "released", time.Now())
+	c.Assert(err, jc.ErrorIsNil)
+
+	// Write the files out, and check that they can be read
+	// back as a simplestreams datasource.
+	dir := c.MkDir()
+	for _, f := range files {
+		path := filepath.Join(dir, filepath.FromSlash(f.Path))
+		err := os.MkdirAll(filepath.Dir(path), 0755)
+		c.Assert(err, jc.ErrorIsNil)
+		err = ioutil.WriteFile(path, f.Data, 0644)
+		c.Assert(err, jc.ErrorIsNil)
+	}
+	source := simplestreams.NewURLDataSource(
+		"controller mirror", "file://"+dir, utils.VerifySSLHostnames,
+		simplestreams.DEFAULT_CLOUD_DATA, false,
+	)
+	found, err := tools.FindToolsForCloud(
+		[]simplestreams.DataSource{source}, simplestreams.CloudSpec{},
+		"released", 2, 0, coretools.Filter{},
+	)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(found, gc.HasLen, 2)
+	for _, t := range found {
+		c.Assert(t.URL, gc.Equals, "file://"+dir+"/tools/"+t.Version.String())
+		c.Assert(t.Version.Number, gc.Equals, version.MustParse("2.0.1"))
+	}
+}
+
+func (s *mirrorSuite) TestFindControllerMirrorTools(c *gc.C) {
+	mirrorURL, env := s.setUpFindControllerMirrorTools(c, tools.ControllerMirrorEndpoint)
+	found, err := tools.FindControllerMirrorTools(
+		env, []string{mirrorURL}, 2, 0, "released", coretools.Filter{},
+	)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(found, gc.HasLen, 1)
+	c.Assert(found[0].Version, gc.Equals, version.MustParseBinary("2.0.1-xenial-amd64"))
+	c.Assert(found[0].URL, gc.Equals, mirrorURL+"/tools/2.0.1-xenial-amd64")
+}
+
+func (s *mirrorSuite) TestFindControllerMirrorToolsNotControllerMirror(c *gc.C) {
+	mirrorURL, env := s.setUpFindControllerMirrorTools(c, "https://storage.example.com/")
+	found, err := tools.FindControllerMirrorTools(
+		env, []string{mirrorURL}, 2, 0, "released", coretools.Filter{},
+	)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(found, gc.HasLen, 1)
+	c.Assert(found[0].Version, gc.Equals, version.MustParseBinary("2.0.2-xenial-amd64"))
+}
+
+// setUpFindControllerMirrorTools writes a controller mirror with
+// 2.0.1 agent binaries, and returns its URL and an environ with the
+// given agent mirror endpoint, whose agent-metadata-url refers to
+// metadata for 2.0.2 agent binaries.
+func (s *mirrorSuite) setUpFindControllerMirrorTools(c *gc.C, endpoint string) (string, environs.Environ) {
+	s.PatchValue(&tools.DefaultBaseURL, "")
+	mirrorDir := writeControllerMirror(c, coretools.List{{
+		Version: version.MustParseBinary("2.0.1-xenial-amd64"),
+		Size:    123,
+		SHA256:  "abc",
+	}})
+	metadataDir := writeControllerMirror(c, coretools.List{{
+		Version: version.MustParseBinary("2.0.2-xenial-amd64"),
+		Size:    456,
+		SHA256:  "def",
+	}})
+	env := &mirrorEnviron{
+		cfg: coretesting.CustomModelConfig(c, coretesting.Attrs{
+			"agent-metadata-url": utils.MakeFileURL(metadataDir),
+		}),
+		cloudSpec: simplestreams.CloudSpec{
+			Region:   "westus",
+			Endpoint: endpoint,
+		},
+	}
+	return utils.MakeFileURL(mirrorDir), env
+}
+
+func writeControllerMirror(c *gc.C, toolsList coretools.List) string {
+	files, err := tools.MarshalControllerMirrorMetadata(toolsList, "released", time.Now())
+	c.Assert(err, jc.ErrorIsNil)
+	dir := c.MkDir()
+	for _, f := range files {
+		path := filepath.Join(dir, filepath.FromSlash(f.Path))
+		err := os.MkdirAll(filepath.Dir(path), 0755)
+		c.Assert(err, jc.ErrorIsNil)
+		err = ioutil.WriteFile(path, f.Data, 0644)
+		c.Assert(err, jc.ErrorIsNil)
+	}
+	return dir
+}
+
+// mirrorEnviron is an environs.Environ that reports the
+// given agent mirror.
+type mirrorEnviron struct {
+	environs.Environ
+	cfg       *config.Config
+	cloudSpec simplestreams.CloudSpec
+}
+
+func (e *mirrorEnviron) Config() *config.Config {
+	return e.cfg
+}
+
+func (e *mirrorEnviron) AgentMirror() (simplestreams.CloudSpec, error) {
+	return e.cloudSpec, nil
+}
@@ -82,20 +82,9 @@ type HasAgentMirror interface {
 // If no *available* tools have the supplied major.minor version number, or match the
 // supplied filter, the function returns a *NotFoundError.
 func FindTools(env environs.Environ, majorVersion, minorVersion int, stream string, filter coretools.Filter) (_ coretools.List, err error) {
-	var cloudSpec simplestreams.CloudSpec
-	switch env := env.(type) {
-	case simplestreams.HasRegion:
-		if cloudSpec, err = env.Region(); err != nil {
-			return nil, err
-		}
-	case HasAgentMirror:
-		if cloudSpec, err = env.AgentMirror(); err != nil {
-			return nil, err
-		}
-	}
-	// If only one of region or endpoint is provided, that is a problem.
-	if cloudSpec.Region != cloudSpec.Endpoint && (cloudSpec.Region == "" || cloudSpec.Endpoint == "") {
-		return nil, errors.New("cannot find agent binaries without a complete cloud configuration")
+	cloudSpec, err := toolsCloudSpec(env)
+	if err != nil {
+		return nil, err
 	}
 
 	logger.Infof("finding agent binaries in stream %q", stream)
@@ -123,6 +112,26 @@ func FindTools(env environs.Environ, majorVersion, minorVersion int, stream stri
 	return FindToolsForCloud(sources, cloudSpec, stream, majorVersion, minorVersion, filter)
 }
 
+// toolsCloudSpec returns the CloudSpec to use for looking up
+// agent binaries for the given environment.
+func toolsCloudSpec(env environs.Environ) (cloudSpec simplestreams.CloudSpec, err error) {
+	switch env := env.(type) {
+	case simplestreams.HasRegion:
+		if cloudSpec, err = env.Region(); err != nil {
+			return simplestreams.CloudSpec{}, err
+		}
+	case HasAgentMirror:
+		if cloudSpec, err = env.AgentMirror(); err != nil {
+			return simplestreams.CloudSpec{}, err
+		}
+	}
+	// If only one of region or endpoint is provided, that is a problem.
+	if cloudSpec.Region != cloudSpec.Endpoint && (cloudSpec.Region == "" || cloudSpec.Endpoint == "") {
+		return simplestreams.CloudSpec{}, errors.New("cannot find agent binaries without a complete cloud configuration")
+	}
+	return cloudSpec, nil
+}
+
 // FindToolsForCloud returns a List containing all tools in the given stream, with a given
 // major.minor version number and cloudSpec, filtered by filter.
 // If minorVersion = -1, then only majorVersion is considered.
@@ -188,6 +197,76 @@ func FindExactTools(env environs.Environ, vers version.Number, series string, ar
 	return availableTools[0], nil
 }
 
+// ToolsMirror describes the location of an agent binary
+// as advertised by a single simplestreams data source.
+type ToolsMirror struct {
+	// Source is the description of the data source
+	// that advertised the agent binary.
+	Source string
+
+	// Tools holds the details of the agent binary, including
+	// the URL from which it may be fetched, and its expected
+	// size and SHA-256 hash.
+	Tools *coretools.Tools
+}
+
+// FindExactToolsMirrors returns the locations of the tools that match
+// the supplied version, as advertised by each of the environment's
+// metadata sources. Unlike FindExactTools, which stops at the first
+// source with matching metadata, each source is consulted so that the
+// caller may fetch the agent binary from whichever mirror responds
+// first. The mirrors are returned in order of source priority, and
+// duplicate URLs are omitted.
+func FindExactToolsMirrors(env environs.Environ, vers version.Number, series string, arch string) (_ []ToolsMirror, err error) {
+	logger.Infof("finding mirrors for exact version %s", vers)
+	defer convertToolsError(&err)
+	cloudSpec, err := toolsCloudSpec(env)
+	if err != nil {
+		return nil, err
+	}
+	sources, err := GetMetadataSources(env)
+	if err != nil {
+		return nil, err
+	}
+	filter := coretools.Filter{
+		Number: vers,
+		Series: series,
+		Arch:   arch,
+	}
+	stream := PreferredStream(&vers, env.Config().Development(), env.Config().AgentStream())
+	seen := make(map[string]bool)
+	var mirrors []ToolsMirror
+	for _, source := range sources {
+		list, err := FindToolsForCloud(
+			[]simplestreams.DataSource{source}, cloudSpec, stream,
+			vers.Major, vers.Minor, filter,
+		)
+		if isToolsError(err) || errors.IsNotFound(err) {
+			logger.Debugf("no agent binaries for %s in %q", vers, source.Description())
+			continue
+		} else if err != nil {
+			logger.Warningf("cannot read agent binaries metadata from %q: %v", source.Description(), err)
+			continue
+		}
+		if len(list) != 1 {
+			logger.Warningf("expected one tools from %q, got %d tools", source.Description(), len(list))
+			continue
+		}
+		if seen[list[0].URL] {
+			continue
+		}
+		seen[list[0].URL] = true
+		mirrors = append(mirrors, ToolsMirror{
+			Source: source.Description(),
+			Tools:  list[0],
+		})
+	}
+	if len(mirrors) == 0 {
+		return nil, ErrNoTools
+	}
+	return mirrors, nil
+}
+
 // checkToolsSeries verifies that all the given possible tools are for the
 // given OS series.
 func checkToolsSeries(toolsList coretools.List, series string) error {
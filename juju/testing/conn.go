@@ -4,6 +4,7 @@
 package testing
 
 import (
+	"crypto/sha256"
 	"fmt"
 	"io/ioutil"
 	"os"
@@ -431,7 +432,7 @@ func (s *JujuConnSuite) AddToolsToState(c *gc.C, versions ...version.Binary) {
 	defer stor.Close()
 	for _, v := range versions {
 		content := v.String()
-		hash := fmt.Sprintf("sha256(%s)", content)
+		hash := fmt.Sprintf("%x", sha256.Sum256([]byte(content)))
 		err := stor.Add(strings.NewReader(content), binarystorage.Metadata{
 			Version: v.String(),
 			Size:    int64(len(content)),
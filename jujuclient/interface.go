@@ -124,6 +124,11 @@ type BootstrapConfig struct {
 	// when communicating with the cloud's storage service. This will
 	// be empty for clouds that have no storage-specific API endpoint.
 	CloudStorageEndpoint string `yaml:"storage-endpoint,omitempty"`
+
+	// BootstrapFailed is true if bootstrapping the controller failed,
+	// and its resources were kept so that the bootstrap may later be
+	// resumed.
+	BootstrapFailed bool `yaml:"bootstrap-failed,omitempty"`
 }
 
 // ControllerUpdater stores controller details.
@@ -0,0 +1,151 @@
+// Copyright 2016 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package azure
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/arm/compute"
+	"github.com/Azure/azure-sdk-for-go/arm/network"
+	"github.com/Azure/azure-sdk-for-go/arm/resources/resources"
+	"github.com/Azure/azure-sdk-for-go/arm/storage"
+	"github.com/Azure/go-autorest/autorest"
+	"github.com/Azure/go-autorest/autorest/to"
+	"github.com/juju/errors"
+	"github.com/juju/utils/set"
+)
+
+const (
+	// apiProfileLatest is the name of the API profile made up of
+	// the API versions of the Azure SDK that Juju is built with.
+	apiProfileLatest = "latest"
+
+	// apiProfileAzureStack20160601 is the name of the API profile
+	// supported by Azure Stack, which lags behind public Azure.
+	apiProfileAzureStack20160601 = "2016-06-01"
+)
+
+// apiProfile records the versions of the Azure Resource Manager APIs
+// used to manage a model's resources. The SDK's API versions change as
+// it is upgraded, whereas some clouds (notably Azure Stack) support only
+// older versions; pinning the versions keeps such clouds working.
+type apiProfile struct {
+	// name is the name of the profile, as specified in the
+	// "api-profile" model config.
+	name string
+
+	compute   string
+	network   string
+	storage   string
+	resources string
+}
+
+// apiProfiles holds the known API profiles, keyed by name.
+var apiProfiles = map[string]apiProfile{
+	apiProfileLatest: {
+		name:      apiProfileLatest,
+		compute:   compute.APIVersion,
+		network:   network.APIVersion,
+		storage:   storage.APIVersion,
+		resources: resources.APIVersion,
+	},
+	apiProfileAzureStack20160601: {
+		name:      apiProfileAzureStack20160601,
+		compute:   "2016-03-30",
+		network:   "2016-06-01",
+		storage:   "2016-01-01",
+		resources: "2016-02-01",
+	},
+}
+
+// apiProfileNames returns the sorted names of the known API profiles.
+func apiProfileNames() []string {
+	names := make([]string, 0, len(apiProfiles))
+	for name := range apiProfiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// apiProfileResourceTypes identifies, for each API of a profile, a
+// resource type whose supported API versions are checked when the
+// profile is verified. The resources API is not checked this way, as
+// the request that lists the supported versions uses it.
+var apiProfileResourceTypes = []struct {
+	namespace    string
+	resourceType string
+	apiVersion   func(apiProfile) string
+}{{
+	"Microsoft.Compute", "virtualMachines",
+	func(p apiProfile) string { return p.compute },
+}, {
+	"Microsoft.Network", "virtualNetworks",
+	func(p apiProfile) string { return p.network },
+}, {
+	"Microsoft.Storage", "storageAccounts",
+	func(p apiProfile) string { return p.storage },
+}}
+
+// setAPIProfile configures the environ's API clients to use the
+// API versions of the given profile.
+func (env *azureEnviron) setAPIProfile(profile apiProfile) {
+	env.apiProfile = profile
+	env.compute.APIVersion = profile.compute
+	env.network.APIVersion = profile.network
+	env.storage.APIVersion = profile.storage
+	env.resources.APIVersion = profile.resources
+}
+
+// verifyAPIProfile checks that the cloud's Resource Manager endpoint
+// supports the API versions of the environ's API profile, so that an
+// unsupported profile is reported before any resources are created,
+// rather than as a failure part way through provisioning.
+var verifyAPIProfile = func(env *azureEnviron) error {
+	profile := env.apiProfile
+	providersClient := resources.ProvidersClient{env.resources}
+	for _, t := range apiProfileResourceTypes {
+		var provider resources.Provider
+		if err := env.callAPI(func() (autorest.Response, error) {
+			var err error
+			provider, err = providersClient.Get(t.namespace, "")
+			return provider.Response, err
+		}); err != nil {
+			return errors.Annotatef(
+				err, "querying %s API versions for %q API profile",
+				t.namespace, profile.name,
+			)
+		}
+		apiVersion := t.apiVersion(profile)
+		supported := resourceTypeAPIVersions(provider, t.resourceType)
+		if !set.NewStrings(supported...).Contains(apiVersion) {
+			return errors.NewNotSupported(nil, fmt.Sprintf(
+				"%q API profile not supported by the cloud: "+
+					"%s/%s API version %s is not one of %q",
+				profile.name, t.namespace, t.resourceType, apiVersion, supported,
+			))
+		}
+	}
+	return nil
+}
+
+// resourceTypeAPIVersions returns the API versions of the resource
+// provider's resource type with the given name.
+func resourceTypeAPIVersions(provider resources.Provider, resourceType string) []string {
+	if provider.ResourceTypes == nil {
+		return nil
+	}
+	for _, t := range *provider.ResourceTypes {
+		if !strings.EqualFold(to.String(t.ResourceType), resourceType) {
+			continue
+		}
+		if t.APIVersions == nil {
+			return nil
+		}
+		return *t.APIVersions
+	}
+	return nil
+}
@@ -22,6 +22,10 @@ type cloudSpecAuth struct {
 	sender autorest.Sender
 	mu     sync.Mutex
 	token  *azure.ServicePrincipalToken
+
+	// tokenSendDecorator, if non-nil, decorates the sender
+	// with which tokens are refreshed.
+	tokenSendDecorator autorest.SendDecorator
 }
 
 // WithAuthorization is part of the autorest.Authorizer interface.
@@ -41,6 +45,15 @@ func (c *cloudSpecAuth) WithAuthorization() autorest.PrepareDecorator {
 	}
 }
 
+// setCloudSpec updates the cloud spec used to acquire tokens,
+// discarding the current token.
+func (c *cloudSpecAuth) setCloudSpec(spec environs.CloudSpec) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.cloud = spec
+	c.token = nil
+}
+
 func (c *cloudSpecAuth) refresh() error {
 	token, err := c.getToken()
 	if err != nil {
@@ -59,6 +72,9 @@ func (c *cloudSpecAuth) getToken() (*azure.ServicePrincipalToken, error) {
 	if err != nil {
 		return nil, errors.Trace(err)
 	}
+	if c.sender != nil && c.tokenSendDecorator != nil {
+		token.SetSender(autorest.DecorateSender(c.sender, c.tokenSendDecorator))
+	}
 	c.token = token
 	return c.token, nil
 }
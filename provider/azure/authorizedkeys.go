@@ -0,0 +1,109 @@
+// Copyright 2016 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package azure
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/arm/compute"
+	"github.com/Azure/go-autorest/autorest"
+	"github.com/Azure/go-autorest/autorest/to"
+	"github.com/juju/errors"
+
+	"github.com/juju/juju/environs"
+	"github.com/juju/juju/instance"
+)
+
+const (
+	vmAccessExtensionName     = "JujuVMAccessExtension"
+	linuxVMAccessPublisher    = "Microsoft.OSTCExtensions"
+	linuxVMAccessType         = "VMAccessForLinux"
+	linuxVMAccessVersion      = "1.4"
+	defaultLinuxAdminUsername = "ubuntu"
+)
+
+var _ environs.InstanceAuthorizedKeysUpdater = (*azureEnviron)(nil)
+
+// UpdateAuthorizedKeys is specified in the
+// environs.InstanceAuthorizedKeysUpdater interface.
+//
+// The keys are added to the administrator's authorized keys by the
+// VMAccessForLinux VM extension, through the Azure VM agent. The
+// extension does not remove keys; keys removed from the model are
+// removed by the machine agent. Windows instances, and instances that
+// no longer exist, are skipped. The instances are updated one at a
+// time; an error updating one instance does not prevent the others
+// from being updated, and the first error encountered is returned.
+func (env *azureEnviron) UpdateAuthorizedKeys(ids []instance.Id, authorizedKeys string) error {
+	var firstErr error
+	for _, id := range ids {
+		err := env.updateAuthorizedKeys(string(id), authorizedKeys)
+		if errors.IsNotFound(err) || errors.IsNotSupported(err) {
+			logger.Debugf("not updating authorized keys: %v", err)
+			continue
+		}
+		if err != nil {
+			err = errors.Annotatef(err, "updating authorized keys on %q", id)
+			logger.Errorf("%v", err)
+			if firstErr == nil {
+				firstErr = err
+			}
+		}
+	}
+	return firstErr
+}
+
+func (env *azureEnviron) updateAuthorizedKeys(vmName, authorizedKeys string) error {
+	vmClient := compute.VirtualMachinesClient{env.compute}
+	extensionsClient := compute.VirtualMachineExtensionsClient{env.compute}
+
+	var vm compute.VirtualMachine
+	if err := env.callAPI(func() (autorest.Response, error) {
+		var err error
+		vm, err = vmClient.Get(env.resourceGroup, vmName, "")
+		return vm.Response, err
+	}); err != nil {
+		if vm.Response.Response != nil && vm.StatusCode == http.StatusNotFound {
+			return errors.NotFoundf("instance %q", vmName)
+		}
+		return errors.Annotate(err, "getting virtual machine")
+	}
+	if osType := virtualMachineOSType(vm); osType != compute.Linux {
+		return errors.NotSupportedf("authorized keys on %q instances", osType)
+	}
+	username := defaultLinuxAdminUsername
+	if vm.Properties.OsProfile != nil && vm.Properties.OsProfile.AdminUsername != nil {
+		username = to.String(vm.Properties.OsProfile.AdminUsername)
+	}
+
+	// The keys are passed in the protected settings, which Azure
+	// does not report back. The extension's public settings must
+	// change for it to run again, so we include a timestamp there.
+	settings := map[string]interface{}{
+		"timestamp": time.Now().Unix(),
+	}
+	protectedSettings := map[string]interface{}{
+		"username": username,
+		"ssh_key":  authorizedKeys,
+	}
+	extension := compute.VirtualMachineExtension{
+		Location: vm.Location,
+		Tags:     vm.Tags,
+		Properties: &compute.VirtualMachineExtensionProperties{
+			Publisher:               to.StringPtr(linuxVMAccessPublisher),
+			Type:                    to.StringPtr(linuxVMAccessType),
+			TypeHandlerVersion:      to.StringPtr(linuxVMAccessVersion),
+			AutoUpgradeMinorVersion: to.BoolPtr(true),
+			Settings:                &settings,
+			ProtectedSettings:       &protectedSettings,
+		},
+	}
+	logger.Debugf("updating authorized keys on %s", vmName)
+	return env.callAPI(func() (autorest.Response, error) {
+		return extensionsClient.CreateOrUpdate(
+			env.resourceGroup, vmName, vmAccessExtensionName, extension, nil,
+		)
+	})
+}
@@ -0,0 +1,101 @@
+// Copyright 2016 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package azure
+
+import (
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/arm/compute"
+	"github.com/Azure/go-autorest/autorest/to"
+	"github.com/juju/errors"
+	"github.com/juju/utils/set"
+	"gopkg.in/juju/names.v2"
+
+	"github.com/juju/juju/environs/tags"
+)
+
+// jujuAvailabilitySetTag is the tag recording the name of the
+// availability set that a virtual machine was placed in, or the
+// empty string if it was not placed in one. Virtual machines
+// without the tag were created before the decision was recorded,
+// and are in the availability set named after their application,
+// if they host one.
+const jujuAvailabilitySetTag = tags.JujuTagPrefix + "availability-set"
+
+// availabilitySetName returns the name of the availability set in
+// which to place a new virtual machine, or the empty string if it
+// should not be placed in one. The algorithm used for choosing the
+// availability set is:
+//  - if the machine is a controller, use the availability set name
+//    "juju-controller";
+//  - if the machine has units assigned, take the application of the
+//    first unit in the tags.JujuUnitsDeployed tag in vmTags. If an
+//    existing virtual machine hosts a unit of the application, use
+//    the availability set it was placed in, so that the application's
+//    machines are placed consistently even if the configuration has
+//    since changed. Otherwise, use an availability set named after
+//    the application, prefixed with namePrefix, unless availability
+//    sets are disabled, or the application is excluded from them;
+//  - otherwise, do not assign the machine to an availability set.
+func availabilitySetName(
+	vmName string,
+	vmTags map[string]string,
+	controller bool,
+	enabled bool,
+	exclusions set.Strings,
+	namePrefix string,
+	vms []compute.VirtualMachine,
+) (string, error) {
+	logger.Debugf("selecting availability set for %q", vmName)
+	if controller {
+		return controllerAvailabilitySet, nil
+	}
+
+	applicationName, err := deployedApplication(vmTags)
+	if err != nil {
+		return "", errors.Trace(err)
+	}
+	if applicationName == "" {
+		return "", nil
+	}
+	for _, vm := range vms {
+		if to.String(vm.Name) == vmName || vm.Tags == nil {
+			continue
+		}
+		existingTags := to.StringMap(*vm.Tags)
+		existingApplicationName, err := deployedApplication(existingTags)
+		if err != nil || existingApplicationName != applicationName {
+			continue
+		}
+		if name, ok := existingTags[jujuAvailabilitySetTag]; ok {
+			return name, nil
+		}
+		return applicationName, nil
+	}
+	if !enabled || exclusions.Contains(applicationName) {
+		return "", nil
+	}
+	return prefixResourceName(namePrefix, applicationName), nil
+}
+
+// deployedApplication returns the name of the application of the
+// first valid unit named in the tags.JujuUnitsDeployed tag in vmTags,
+// or the empty string if there is none.
+func deployedApplication(vmTags map[string]string) (string, error) {
+	unitNames, ok := vmTags[tags.JujuUnitsDeployed]
+	if !ok {
+		return "", nil
+	}
+	for _, unitName := range strings.Fields(unitNames) {
+		if !names.IsValidUnit(unitName) {
+			continue
+		}
+		applicationName, err := names.UnitApplication(unitName)
+		if err != nil {
+			return "", errors.Annotate(err, "getting application name")
+		}
+		return applicationName, nil
+	}
+	return "", nil
+}
@@ -0,0 +1,180 @@
+// Copyright 2016 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package azure
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+
+	"github.com/juju/errors"
+	"github.com/juju/utils"
+
+	"github.com/juju/juju/cloud"
+)
+
+const (
+	// azureConfigDirEnvVar is the environment variable that the
+	// Azure CLI consults to override its configuration directory.
+	azureConfigDirEnvVar = "AZURE_CONFIG_DIR"
+
+	// The environment variables conventionally used by the Azure
+	// SDKs to specify service principal credentials.
+	azureSubscriptionIdEnvVar = "AZURE_SUBSCRIPTION_ID"
+	azureClientIdEnvVar       = "AZURE_CLIENT_ID"
+	azureClientSecretEnvVar   = "AZURE_CLIENT_SECRET"
+
+	azureCLIProfileFile      = "azureProfile.json"
+	azureCLIAccessTokensFile = "accessTokens.json"
+
+	azureCLIUserType             = "user"
+	azureCLIServicePrincipalType = "servicePrincipal"
+)
+
+// azureCLIProfile is the content of the Azure CLI's azureProfile.json,
+// which records the subscriptions that the user has logged in to.
+type azureCLIProfile struct {
+	Subscriptions []azureCLISubscription `json:"subscriptions"`
+}
+
+type azureCLISubscription struct {
+	Id        string `json:"id"`
+	Name      string `json:"name"`
+	State     string `json:"state"`
+	IsDefault bool   `json:"isDefault"`
+	User      struct {
+		Name string `json:"name"`
+		Type string `json:"type"`
+	} `json:"user"`
+}
+
+// azureCLIAccessToken is an entry in the Azure CLI's accessTokens.json.
+// For service principal logins, the CLI records the service principal's
+// password in the AccessToken field.
+type azureCLIAccessToken struct {
+	ServicePrincipalId string `json:"servicePrincipalId"`
+	AccessToken        string `json:"accessToken"`
+}
+
+// azureConfigDir returns the directory in which the Azure CLI
+// stores its configuration.
+func azureConfigDir() string {
+	if dir := os.Getenv(azureConfigDirEnvVar); dir != "" {
+		return dir
+	}
+	if runtime.GOOS == "windows" {
+		return filepath.Join(os.Getenv("USERPROFILE"), ".azure")
+	}
+	return filepath.Join(utils.Home(), ".azure")
+}
+
+// detectAzureCLICredentials returns credentials for each of the enabled
+// subscriptions that the Azure CLI is logged in to. Subscriptions logged
+// in to by a user are given "interactive" credentials, from which a
+// service principal is created when the credential is finalized;
+// subscriptions logged in to by a service principal are given the
+// service principal's credentials.
+func detectAzureCLICredentials(dir string) (*cloud.CloudCredential, error) {
+	var profile azureCLIProfile
+	if err := readAzureCLIFile(filepath.Join(dir, azureCLIProfileFile), &profile); err != nil {
+		if os.IsNotExist(errors.Cause(err)) {
+			return nil, errors.NotFoundf("Azure CLI profile")
+		}
+		return nil, errors.Trace(err)
+	}
+	var tokens []azureCLIAccessToken
+	if err := readAzureCLIFile(filepath.Join(dir, azureCLIAccessTokensFile), &tokens); err != nil {
+		if !os.IsNotExist(errors.Cause(err)) {
+			return nil, errors.Trace(err)
+		}
+	}
+	servicePrincipalPasswords := make(map[string]string)
+	for _, token := range tokens {
+		if token.ServicePrincipalId != "" && token.AccessToken != "" {
+			servicePrincipalPasswords[token.ServicePrincipalId] = token.AccessToken
+		}
+	}
+
+	result := cloud.CloudCredential{
+		AuthCredentials: make(map[string]cloud.Credential),
+	}
+	for _, sub := range profile.Subscriptions {
+		if sub.State != "" && sub.State != "Enabled" {
+			logger.Debugf("ignoring %s Azure subscription %q", sub.State, sub.Name)
+			continue
+		}
+		var credential cloud.Credential
+		switch sub.User.Type {
+		case azureCLIUserType:
+			credential = cloud.NewCredential(deviceCodeAuthType, map[string]string{
+				credAttrSubscriptionId: sub.Id,
+			})
+		case azureCLIServicePrincipalType:
+			password, ok := servicePrincipalPasswords[sub.User.Name]
+			if !ok {
+				logger.Debugf("no password found for Azure service principal %q", sub.User.Name)
+				continue
+			}
+			credential = cloud.NewCredential(clientCredentialsAuthType, map[string]string{
+				credAttrAppId:          sub.User.Name,
+				credAttrSubscriptionId: sub.Id,
+				credAttrAppPassword:    password,
+			})
+		default:
+			logger.Debugf("ignoring Azure subscription %q with user type %q", sub.Name, sub.User.Type)
+			continue
+		}
+		credential.Label = fmt.Sprintf("azure credential for subscription %q", sub.Name)
+		result.AuthCredentials[sub.Id] = credential
+		if sub.IsDefault {
+			result.DefaultCredential = sub.Id
+		}
+	}
+	if len(result.AuthCredentials) == 0 {
+		return nil, errors.NotFoundf("Azure CLI credentials")
+	}
+	return &result, nil
+}
+
+// detectEnvCredentials returns a service principal credential from
+// the environment variables conventionally used by the Azure SDKs.
+func detectEnvCredentials() (*cloud.CloudCredential, error) {
+	subscriptionId := os.Getenv(azureSubscriptionIdEnvVar)
+	appId := os.Getenv(azureClientIdEnvVar)
+	password := os.Getenv(azureClientSecretEnvVar)
+	if subscriptionId == "" || appId == "" || password == "" {
+		return nil, errors.NotFoundf("Azure credentials in environment")
+	}
+	user, err := utils.LocalUsername()
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	credential := cloud.NewCredential(clientCredentialsAuthType, map[string]string{
+		credAttrAppId:          appId,
+		credAttrSubscriptionId: subscriptionId,
+		credAttrAppPassword:    password,
+	})
+	credential.Label = fmt.Sprintf("azure credential %q", user)
+	return &cloud.CloudCredential{
+		AuthCredentials: map[string]cloud.Credential{user: credential},
+	}, nil
+}
+
+// readAzureCLIFile reads and decodes a JSON file written by the
+// Azure CLI, which may begin with a UTF-8 byte order mark.
+func readAzureCLIFile(path string, v interface{}) error {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))
+	if err := json.Unmarshal(data, v); err != nil {
+		return errors.Annotatef(err, "parsing %s", path)
+	}
+	return nil
+}
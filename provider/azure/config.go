@@ -4,11 +4,18 @@
 package azure
 
 import (
+	"crypto/x509"
+	"net"
+	"regexp"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/Azure/azure-sdk-for-go/arm/storage"
 	"github.com/juju/errors"
 	"github.com/juju/schema"
+	"github.com/juju/utils/set"
+	"gopkg.in/juju/environschema.v1"
 	"gopkg.in/juju/names.v2"
 
 	"github.com/juju/juju/environs/config"
@@ -17,31 +24,227 @@ import (
 const (
 	configAttrStorageAccountType = "storage-account-type"
 
+	// configAttrShutdownGracePeriod is the maximum amount of time to
+	// wait for a virtual machine to shut down gracefully before it is
+	// deleted. If unset, or zero, virtual machines are deleted without
+	// first being shut down.
+	configAttrShutdownGracePeriod = "shutdown-grace-period"
+
+	// configAttrDNSLabelPrefix is the prefix of the DNS labels assigned
+	// to virtual machines' public IP addresses. A machine's label is the
+	// prefix followed by the machine's name, e.g. "prefix-machine-0",
+	// giving it the DNS name "prefix-machine-0.<location>.cloudapp.azure.com".
+	// If unset, a prefix derived from the model UUID is used.
+	configAttrDNSLabelPrefix = "dns-label-prefix"
+
+	// configAttrEgressAllowList is a comma-separated list of CIDRs to
+	// which machines in the model may make outbound connections. If
+	// set, outbound connections to all other Internet addresses are
+	// denied. If unset, outbound connections are not restricted.
+	configAttrEgressAllowList = "egress-allow-list"
+
+	// configAttrIngressDefaultDeny, if true, denies inbound connections
+	// to machines in the model other than on ports opened by Juju, even
+	// from other machines in the model.
+	configAttrIngressDefaultDeny = "ingress-default-deny"
+
+	// configAttrNetworkSecurityGroup is the resource ID of an existing
+	// network security group to attach to the model's subnets, in place
+	// of the group that Juju would otherwise create. Juju manages only
+	// the rules for the ports opened on the model's machines in such a
+	// group, so that it may also hold baseline rules managed by the
+	// operator. The group may be shared with other models: the rules
+	// are named for the model as well as the machine.
+	configAttrNetworkSecurityGroup = "network-security-group"
+
+	// configAttrSecurityRulePriorities is the range of priorities, in
+	// the form "min-max", reserved for the security rules that Juju
+	// creates for the ports opened on the model's machines.
+	configAttrSecurityRulePriorities = "security-rule-priorities"
+
+	// configAttrPrivateCloud, if true, indicates that the model's
+	// machines cannot reach the Internet, and must obtain agent
+	// binaries from the controller's tools mirror rather than from
+	// the public mirrors in Azure storage.
+	configAttrPrivateCloud = "private-cloud"
+
+	// configAttrCACertificates is a bundle of PEM-encoded CA
+	// certificates. If specified, the Azure API endpoints' TLS
+	// certificates are verified against these CAs instead of the
+	// system's, as required behind TLS-intercepting proxies and
+	// for Azure Stack endpoints with self-signed certificates.
+	configAttrCACertificates = "ca-certificates"
+
+	// configAttrAvailabilitySets, if false, prevents machines hosting
+	// applications from being placed in availability sets, so that
+	// they may be of any size, and are provisioned more quickly.
+	// Controller machines are always placed in an availability set.
+	configAttrAvailabilitySets = "availability-sets"
+
+	// configAttrAvailabilitySetExclusions is a comma-separated list
+	// of applications whose machines are not placed in availability
+	// sets, regardless of the availability-sets config.
+	configAttrAvailabilitySetExclusions = "availability-set-exclusions"
+
+	// configAttrResourceNamePrefix is the prefix of the names of the
+	// virtual machines, network interfaces, public IP addresses,
+	// availability sets and disks that Juju creates for the model,
+	// separated from the rest of each name by a hyphen. Some
+	// organisations route alerts and billing by resource name. The
+	// controller availability set is not prefixed, as controller
+	// machines are identified by it.
+	configAttrResourceNamePrefix = "resource-name-prefix"
+
+	// configAttrAPIProfile is the name of the profile of Azure Resource
+	// Manager API versions used to manage the model's resources. The
+	// "latest" profile uses the versions of the Azure SDK Juju is built
+	// with; the "2016-06-01" profile is supported by Azure Stack.
+	configAttrAPIProfile = "api-profile"
+
+	// configAttrValidateDeployments, if true, causes the deployment of
+	// each virtual machine to be validated by Azure Resource Manager
+	// before any of its resources are created, so that failures such as
+	// exceeded quotas, unavailable sizes and policy denials are reported
+	// immediately, rather than part way through provisioning. This costs
+	// an additional request for each machine.
+	configAttrValidateDeployments = "validate-deployments"
+
 	// The below bits are internal book-keeping things, rather than
 	// configuration. Config is just what we have to work with.
 
+	// configAttrStorageAccountKeyName is the name of the key of the
+	// model's primary storage account that is used to access it,
+	// recorded when the keys are rotated. If unset, the first key with
+	// full permissions is used.
+	configAttrStorageAccountKeyName = "storage-account-key-name"
+
 	// resourceNameLengthMax is the maximum length of resource
 	// names in Azure.
 	resourceNameLengthMax = 80
+
+	// dnsLabelPrefixLengthMax is the maximum length of the DNS label
+	// prefix, leaving room for the machine name within the 63
+	// characters allowed for a DNS label.
+	dnsLabelPrefixLengthMax = 40
+
+	// resourceNamePrefixLengthMax is the maximum length of the
+	// resource name prefix, leaving room for the rest of each name
+	// within the limits on the lengths of resource names.
+	resourceNamePrefixLengthMax = 20
 )
 
 var configFields = schema.Fields{
-	configAttrStorageAccountType: schema.String(),
+	configAttrStorageAccountType:        schema.String(),
+	configAttrShutdownGracePeriod:       schema.String(),
+	configAttrDNSLabelPrefix:            schema.String(),
+	configAttrEgressAllowList:           schema.String(),
+	configAttrIngressDefaultDeny:        schema.Bool(),
+	configAttrNetworkSecurityGroup:      schema.String(),
+	configAttrSecurityRulePriorities:    schema.String(),
+	configAttrPrivateCloud:              schema.Bool(),
+	configAttrCACertificates:            schema.String(),
+	configAttrAvailabilitySets:          schema.Bool(),
+	configAttrAvailabilitySetExclusions: schema.String(),
+	configAttrResourceNamePrefix:        schema.String(),
+	configAttrAPIProfile:                schema.String(),
+	configAttrValidateDeployments:       schema.Bool(),
+	configAttrStorageAccountKeyName:     schema.String(),
 }
 
 var configDefaults = schema.Defaults{
-	configAttrStorageAccountType: string(storage.StandardLRS),
+	configAttrStorageAccountType:        string(storage.StandardLRS),
+	configAttrShutdownGracePeriod:       "",
+	configAttrDNSLabelPrefix:            "",
+	configAttrEgressAllowList:           "",
+	configAttrIngressDefaultDeny:        false,
+	configAttrNetworkSecurityGroup:      "",
+	configAttrSecurityRulePriorities:    "",
+	configAttrPrivateCloud:              false,
+	configAttrCACertificates:            "",
+	configAttrAvailabilitySets:          true,
+	configAttrAvailabilitySetExclusions: "",
+	configAttrResourceNamePrefix:        "",
+	configAttrAPIProfile:                apiProfileLatest,
+	configAttrValidateDeployments:       false,
+	configAttrStorageAccountKeyName:     "",
 }
 
 var immutableConfigAttributes = []string{
 	configAttrStorageAccountType,
+	configAttrNetworkSecurityGroup,
+	configAttrSecurityRulePriorities,
+	configAttrResourceNamePrefix,
+	configAttrAPIProfile,
 }
 
 type azureModelConfig struct {
 	*config.Config
-	storageAccountType string
+	storageAccountType  string
+	shutdownGracePeriod time.Duration
+	dnsLabelPrefix      string
+	egressAllowList     []string
+	ingressDefaultDeny  bool
+
+	// securityGroup identifies the network security group in which
+	// the security rules for the ports opened on machines are
+	// managed, and securityRulePriorities the range of priorities
+	// reserved for those rules.
+	securityGroup          securityGroupRef
+	securityRulePriorities priorityRange
+
+	// privateCloud reports whether agent binaries should be
+	// obtained from the controller's tools mirror.
+	privateCloud bool
+
+	// caCertificates holds the PEM-encoded CA certificates against
+	// which the Azure API endpoints' certificates are verified, and
+	// caCertPool the parsed certificates. If caCertificates is empty,
+	// caCertPool is nil, and the system's CAs are used.
+	caCertificates string
+	caCertPool     *x509.CertPool
+
+	// availabilitySets reports whether machines hosting applications
+	// are placed in availability sets, other than those of the
+	// applications in availabilitySetExclusions.
+	availabilitySets          bool
+	availabilitySetExclusions set.Strings
+
+	// resourceNamePrefix is the prefix of the names of the resources
+	// created for the model's machines, or the empty string if they
+	// are not prefixed.
+	resourceNamePrefix string
+
+	// apiProfile holds the versions of the Azure APIs
+	// used to manage the model's resources.
+	apiProfile apiProfile
+
+	// validateDeployments reports whether virtual machine
+	// deployments are validated before they are created.
+	validateDeployments bool
+
+	// storageAccountKeyName is the name of the storage account key
+	// in use, or the empty string if any key with full permissions
+	// may be used.
+	storageAccountKeyName string
 }
 
+// dnsLabelPrefixRegexp matches valid DNS label prefixes: Azure
+// requires DNS labels to start with a lowercase letter, and to
+// contain only lowercase letters, digits and hyphens.
+var dnsLabelPrefixRegexp = regexp.MustCompile("^[a-z][a-z0-9-]*$")
+
+// resourceNamePrefixRegexp matches valid resource name prefixes. The
+// characters allowed are those permitted in the names of all of the
+// prefixed resource types. Computer names are not prefixed, as Windows
+// limits them to 15 characters.
+var resourceNamePrefixRegexp = regexp.MustCompile("^[a-zA-Z][a-zA-Z0-9-]*$")
+
+// securityGroupIDRegexp matches the resource IDs of network security
+// groups, capturing the resource group and name.
+var securityGroupIDRegexp = regexp.MustCompile(
+	`(?i)^/subscriptions/[^/]+/resourceGroups/([^/]+)/providers/Microsoft\.Network/networkSecurityGroups/([^/]+)$`,
+)
+
 var knownStorageAccountTypes = []string{
 	"Standard_LRS", "Standard_GRS", "Standard_RAGRS", "Standard_ZRS", "Premium_LRS",
 }
@@ -57,6 +260,26 @@ func (*azureEnvironProvider) Validate(newCfg, oldCfg *config.Config) (*config.Co
 	return newCfg, nil
 }
 
+// RequiredConfig is specified in the config.RequiredConfigSource
+// interface.
+//
+// In private-cloud mode, the bootstrap machine cannot reach the public
+// mirrors of agent binaries, and the controller's tools mirror does not
+// yet exist, so agent-metadata-url must refer to a mirror that it can
+// reach.
+func (*azureEnvironProvider) RequiredConfig(attrs map[string]interface{}) environschema.Fields {
+	privateCloud, err := schema.Bool().Coerce(attrs[configAttrPrivateCloud], nil)
+	if err != nil || !privateCloud.(bool) {
+		return nil
+	}
+	return environschema.Fields{
+		config.AgentMetadataURLKey: {
+			Description: "the URL of agent binary metadata reachable from the private cloud",
+			Type:        environschema.Tstring,
+		},
+	}
+}
+
 func validateConfig(newCfg, oldCfg *config.Config) (*azureModelConfig, error) {
 	err := config.Validate(newCfg, oldCfg)
 	if err != nil {
@@ -119,13 +342,276 @@ Please choose a model name of no more than %d characters.`,
 		)
 	}
 
+	var shutdownGracePeriod time.Duration
+	if v := validated[configAttrShutdownGracePeriod].(string); v != "" {
+		shutdownGracePeriod, err = time.ParseDuration(v)
+		if err != nil {
+			return nil, errors.Annotatef(err, "invalid %q config", configAttrShutdownGracePeriod)
+		}
+		if shutdownGracePeriod < 0 {
+			return nil, errors.Errorf(
+				"invalid %q config: negative duration %v",
+				configAttrShutdownGracePeriod, shutdownGracePeriod,
+			)
+		}
+	}
+
+	dnsLabelPrefix := validated[configAttrDNSLabelPrefix].(string)
+	if dnsLabelPrefix == "" {
+		dnsLabelPrefix = defaultDNSLabelPrefix(newCfg.UUID())
+	} else if !dnsLabelPrefixRegexp.MatchString(dnsLabelPrefix) {
+		return nil, errors.Errorf(
+			"invalid %q config %q: must start with a lowercase letter, "+
+				"and contain only lowercase letters, digits and hyphens",
+			configAttrDNSLabelPrefix, dnsLabelPrefix,
+		)
+	} else if len(dnsLabelPrefix) > dnsLabelPrefixLengthMax {
+		return nil, errors.Errorf(
+			"invalid %q config %q: must be no more than %d characters",
+			configAttrDNSLabelPrefix, dnsLabelPrefix, dnsLabelPrefixLengthMax,
+		)
+	}
+
+	egressAllowList, err := parseEgressAllowList(validated[configAttrEgressAllowList].(string))
+	if err != nil {
+		return nil, errors.Annotatef(err, "invalid %q config", configAttrEgressAllowList)
+	}
+	ingressDefaultDeny := validated[configAttrIngressDefaultDeny].(bool)
+
+	securityGroup := securityGroupRef{
+		resourceGroup: resourceGroup,
+		name:          internalSecurityGroupName,
+	}
+	priorityBounds := defaultInstanceSecurityRulePriorities
+	if id := validated[configAttrNetworkSecurityGroup].(string); id != "" {
+		securityGroup, err = parseSecurityGroupID(id)
+		if err != nil {
+			return nil, errors.Annotatef(err, "invalid %q config", configAttrNetworkSecurityGroup)
+		}
+		// The network policy rules would have to be interleaved with
+		// the operator's rules, so enforcing the policy is left to the
+		// operator when using an existing network security group.
+		if len(egressAllowList) > 0 || ingressDefaultDeny {
+			return nil, errors.Errorf(
+				"%q and %q config cannot be used with %q config",
+				configAttrEgressAllowList, configAttrIngressDefaultDeny,
+				configAttrNetworkSecurityGroup,
+			)
+		}
+		// Juju creates no other rules in an existing group, so
+		// any valid priority may be reserved for Juju's rules.
+		priorityBounds = priorityRange{securityRuleInternalMin, securityRuleMax}
+	}
+	securityRulePriorities := defaultInstanceSecurityRulePriorities
+	if v := validated[configAttrSecurityRulePriorities].(string); v != "" {
+		securityRulePriorities, err = parsePriorityRange(v, priorityBounds)
+		if err != nil {
+			return nil, errors.Annotatef(err, "invalid %q config", configAttrSecurityRulePriorities)
+		}
+	}
+
+	caCertificates := validated[configAttrCACertificates].(string)
+	var caCertPool *x509.CertPool
+	if strings.TrimSpace(caCertificates) != "" {
+		caCertPool = x509.NewCertPool()
+		if !caCertPool.AppendCertsFromPEM([]byte(caCertificates)) {
+			return nil, errors.Errorf(
+				"invalid %q config: no PEM-encoded certificates found",
+				configAttrCACertificates,
+			)
+		}
+	}
+
+	availabilitySetExclusions, err := parseAvailabilitySetExclusions(
+		validated[configAttrAvailabilitySetExclusions].(string),
+	)
+	if err != nil {
+		return nil, errors.Annotatef(err, "invalid %q config", configAttrAvailabilitySetExclusions)
+	}
+
+	resourceNamePrefix := validated[configAttrResourceNamePrefix].(string)
+	if resourceNamePrefix != "" && !resourceNamePrefixRegexp.MatchString(resourceNamePrefix) {
+		return nil, errors.Errorf(
+			"invalid %q config %q: must start with a letter, "+
+				"and contain only letters, digits and hyphens",
+			configAttrResourceNamePrefix, resourceNamePrefix,
+		)
+	} else if len(resourceNamePrefix) > resourceNamePrefixLengthMax {
+		return nil, errors.Errorf(
+			"invalid %q config %q: must be no more than %d characters",
+			configAttrResourceNamePrefix, resourceNamePrefix, resourceNamePrefixLengthMax,
+		)
+	}
+
+	apiProfileName := validated[configAttrAPIProfile].(string)
+	apiProfile, ok := apiProfiles[apiProfileName]
+	if !ok {
+		return nil, errors.Errorf(
+			"invalid %q config %q, expected one of: %q",
+			configAttrAPIProfile, apiProfileName, apiProfileNames(),
+		)
+	}
+
+	storageAccountKeyName := validated[configAttrStorageAccountKeyName].(string)
+	if storageAccountKeyName != "" && !isStorageAccountKeyName(storageAccountKeyName) {
+		return nil, errors.Errorf(
+			"invalid %q config %q, expected one of: %q",
+			configAttrStorageAccountKeyName, storageAccountKeyName, storageAccountKeyNames,
+		)
+	}
+
 	azureConfig := &azureModelConfig{
 		newCfg,
 		storageAccountType,
+		shutdownGracePeriod,
+		dnsLabelPrefix,
+		egressAllowList,
+		ingressDefaultDeny,
+		securityGroup,
+		securityRulePriorities,
+		validated[configAttrPrivateCloud].(bool),
+		caCertificates,
+		caCertPool,
+		validated[configAttrAvailabilitySets].(bool),
+		availabilitySetExclusions,
+		resourceNamePrefix,
+		apiProfile,
+		validated[configAttrValidateDeployments].(bool),
+		storageAccountKeyName,
 	}
 	return azureConfig, nil
 }
 
+// prefixResourceName returns the given resource name, prefixed with
+// the resource name prefix if it is non-empty.
+func prefixResourceName(prefix, name string) string {
+	if prefix == "" {
+		return name
+	}
+	return prefix + "-" + name
+}
+
+// unprefixResourceName returns the given resource name, with the
+// resource name prefix removed if it is non-empty. It is the inverse
+// of prefixResourceName.
+func unprefixResourceName(prefix, name string) string {
+	if prefix == "" {
+		return name
+	}
+	return strings.TrimPrefix(name, prefix+"-")
+}
+
+// defaultDNSLabelPrefix returns the DNS label prefix to use for a model
+// if none is configured. DNS labels must be unique within a location, so
+// the prefix is derived from the model UUID.
+func defaultDNSLabelPrefix(modelUUID string) string {
+	return "juju-" + modelUUID[:8]
+}
+
+// parseEgressAllowList parses the comma-separated list of CIDRs
+// in the egress-allow-list config, returning the CIDRs in their
+// canonical form.
+func parseEgressAllowList(s string) ([]string, error) {
+	var cidrs []string
+	for _, field := range strings.Split(s, ",") {
+		field = strings.TrimSpace(field)
+		if field == "" {
+			continue
+		}
+		_, ipNet, err := net.ParseCIDR(field)
+		if err != nil || ipNet.IP.To4() == nil {
+			// Network security groups support only IPv4.
+			return nil, errors.Errorf("%q is not a valid IPv4 CIDR", field)
+		}
+		cidrs = append(cidrs, ipNet.String())
+	}
+	if len(cidrs) > securityRuleEgressAllowMax {
+		return nil, errors.Errorf(
+			"%d CIDRs specified, exceeding the limit of %d",
+			len(cidrs), securityRuleEgressAllowMax,
+		)
+	}
+	return cidrs, nil
+}
+
+// parseAvailabilitySetExclusions parses the comma-separated list of
+// application names in the availability-set-exclusions config.
+func parseAvailabilitySetExclusions(s string) (set.Strings, error) {
+	exclusions := set.NewStrings()
+	for _, field := range strings.Split(s, ",") {
+		field = strings.TrimSpace(field)
+		if field == "" {
+			continue
+		}
+		if !names.IsValidApplication(field) {
+			return nil, errors.Errorf("%q is not a valid application name", field)
+		}
+		exclusions.Add(field)
+	}
+	return exclusions, nil
+}
+
+// parseSecurityGroupID parses the resource ID of a network security
+// group in the network-security-group config.
+func parseSecurityGroupID(id string) (securityGroupRef, error) {
+	match := securityGroupIDRegexp.FindStringSubmatch(id)
+	if match == nil {
+		return securityGroupRef{}, errors.Errorf(
+			"%q is not the resource ID of a network security group", id,
+		)
+	}
+	return securityGroupRef{id, match[1], match[2]}, nil
+}
+
+// parsePriorityRange parses a range of security rule priorities of
+// the form "min-max" in the security-rule-priorities config. The range
+// must be within the given bounds.
+func parsePriorityRange(s string, bounds priorityRange) (priorityRange, error) {
+	fields := strings.SplitN(s, "-", 2)
+	if len(fields) != 2 {
+		return priorityRange{}, errors.Errorf("expected a range of the form \"min-max\", got %q", s)
+	}
+	var r priorityRange
+	for i, p := range []*int32{&r.min, &r.max} {
+		v, err := strconv.ParseInt(strings.TrimSpace(fields[i]), 10, 32)
+		if err != nil {
+			return priorityRange{}, errors.Errorf("expected a range of the form \"min-max\", got %q", s)
+		}
+		*p = int32(v)
+	}
+	if r.min > r.max {
+		return priorityRange{}, errors.Errorf("range %s is empty", r)
+	}
+	if !bounds.contains(r.min) || !bounds.contains(r.max) {
+		return priorityRange{}, errors.Errorf("range %s is not within %s", r, bounds)
+	}
+	return r, nil
+}
+
+// policyConfigEqual reports whether or not the two configurations
+// specify the same network policy.
+func policyConfigEqual(a, b *azureModelConfig) bool {
+	if a.ingressDefaultDeny != b.ingressDefaultDeny {
+		return false
+	}
+	if len(a.egressAllowList) != len(b.egressAllowList) {
+		return false
+	}
+	for i, cidr := range a.egressAllowList {
+		if b.egressAllowList[i] != cidr {
+			return false
+		}
+	}
+	return true
+}
+
+// httpConfigEqual reports whether or not the two configurations
+// specify the same proxy and CA certificate settings for the HTTP
+// client used to make Azure API requests.
+func httpConfigEqual(a, b *azureModelConfig) bool {
+	return a.ProxySettings() == b.ProxySettings() && a.caCertificates == b.caCertificates
+}
+
 // isKnownStorageAccountType reports whether or not the given string identifies
 // a known storage account type.
 func isKnownStorageAccountType(t string) bool {
@@ -4,9 +4,12 @@
 package azure_test
 
 import (
+	"strings"
+
 	"github.com/Azure/go-autorest/autorest/mocks"
 	jc "github.com/juju/testing/checkers"
 	gc "gopkg.in/check.v1"
+	"gopkg.in/juju/environschema.v1"
 
 	"github.com/juju/juju/environs"
 	"github.com/juju/juju/environs/config"
@@ -18,6 +21,11 @@ const (
 	fakeApplicationId     = "00000000-0000-0000-0000-000000000000"
 	fakeSubscriptionId    = "22222222-2222-2222-2222-222222222222"
 	fakeStorageAccountKey = "quay"
+
+	// testSecurityGroupID is the resource ID of an operator-provided
+	// network security group, used in the network-security-group config.
+	testSecurityGroupID = "/subscriptions/" + fakeSubscriptionId +
+		"/resourceGroups/network/providers/Microsoft.Network/networkSecurityGroups/baseline-nsg"
 )
 
 type configSuite struct {
@@ -61,6 +69,199 @@ func (s *configSuite) TestValidateModelNameLength(c *gc.C) {
 Please choose a model name of no more than 32 characters.`)
 }
 
+func (s *configSuite) TestValidateShutdownGracePeriod(c *gc.C) {
+	s.assertConfigValid(c, testing.Attrs{"shutdown-grace-period": "5m"})
+	s.assertConfigInvalid(
+		c, testing.Attrs{"shutdown-grace-period": "soon"},
+		`invalid "shutdown-grace-period" config: time: invalid duration soon`,
+	)
+	s.assertConfigInvalid(
+		c, testing.Attrs{"shutdown-grace-period": "-1m"},
+		`invalid "shutdown-grace-period" config: negative duration -1m0s`,
+	)
+}
+
+func (s *configSuite) TestValidateDNSLabelPrefix(c *gc.C) {
+	s.assertConfigValid(c, testing.Attrs{"dns-label-prefix": "myprefix-1"})
+	s.assertConfigInvalid(
+		c, testing.Attrs{"dns-label-prefix": "1prefix"},
+		`invalid "dns-label-prefix" config "1prefix": must start with a lowercase letter, and contain only lowercase letters, digits and hyphens`,
+	)
+	s.assertConfigInvalid(
+		c, testing.Attrs{"dns-label-prefix": "MyPrefix"},
+		`invalid "dns-label-prefix" config "MyPrefix": must start .*`,
+	)
+	s.assertConfigInvalid(
+		c, testing.Attrs{"dns-label-prefix": "a" + strings.Repeat("b", 40)},
+		`invalid "dns-label-prefix" config "ab+": must be no more than 40 characters`,
+	)
+}
+
+func (s *configSuite) TestValidateEgressAllowList(c *gc.C) {
+	s.assertConfigValid(c, testing.Attrs{"egress-allow-list": "10.0.0.0/8, 203.0.113.7/32"})
+	s.assertConfigInvalid(
+		c, testing.Attrs{"egress-allow-list": "10.0.0.0/8,internet"},
+		`invalid "egress-allow-list" config: "internet" is not a valid IPv4 CIDR`,
+	)
+	s.assertConfigInvalid(
+		c, testing.Attrs{"egress-allow-list": "2001:db8::/32"},
+		`invalid "egress-allow-list" config: "2001:db8::/32" is not a valid IPv4 CIDR`,
+	)
+	s.assertConfigInvalid(
+		c, testing.Attrs{"egress-allow-list": strings.Repeat("10.0.0.0/8,", 96)},
+		`invalid "egress-allow-list" config: 96 CIDRs specified, exceeding the limit of 94`,
+	)
+}
+
+func (s *configSuite) TestValidateIngressDefaultDeny(c *gc.C) {
+	s.assertConfigValid(c, testing.Attrs{"ingress-default-deny": true})
+	s.assertConfigInvalid(
+		c, testing.Attrs{"ingress-default-deny": "sometimes"},
+		`.*expected bool, got string\("sometimes"\)`,
+	)
+}
+
+func (s *configSuite) TestValidatePrivateCloud(c *gc.C) {
+	s.assertConfigValid(c, testing.Attrs{"private-cloud": true})
+	s.assertConfigInvalid(
+		c, testing.Attrs{"private-cloud": "yes please"},
+		`.*expected bool, got string\("yes please"\)`,
+	)
+}
+
+func (s *configSuite) TestRequiredConfig(c *gc.C) {
+	source, ok := s.provider.(config.RequiredConfigSource)
+	c.Assert(ok, jc.IsTrue)
+	c.Assert(source.RequiredConfig(nil), gc.HasLen, 0)
+	c.Assert(source.RequiredConfig(map[string]interface{}{"private-cloud": false}), gc.HasLen, 0)
+
+	required := source.RequiredConfig(map[string]interface{}{"private-cloud": true})
+	c.Assert(required, gc.HasLen, 1)
+	c.Assert(required["agent-metadata-url"].Type, gc.Equals, environschema.Tstring)
+}
+
+func (s *configSuite) TestValidateValidateDeployments(c *gc.C) {
+	s.assertConfigValid(c, testing.Attrs{"validate-deployments": true})
+	s.assertConfigInvalid(
+		c, testing.Attrs{"validate-deployments": "maybe"},
+		`.*expected bool, got string\("maybe"\)`,
+	)
+}
+
+func (s *configSuite) TestValidateAvailabilitySets(c *gc.C) {
+	s.assertConfigValid(c, testing.Attrs{"availability-sets": false})
+	s.assertConfigInvalid(
+		c, testing.Attrs{"availability-sets": "never"},
+		`.*expected bool, got string\("never"\)`,
+	)
+}
+
+func (s *configSuite) TestValidateAvailabilitySetExclusions(c *gc.C) {
+	s.assertConfigValid(c, testing.Attrs{"availability-set-exclusions": "mysql, wordpress"})
+	s.assertConfigInvalid(
+		c, testing.Attrs{"availability-set-exclusions": "mysql,mysql/0"},
+		`invalid "availability-set-exclusions" config: "mysql/0" is not a valid application name`,
+	)
+}
+
+func (s *configSuite) TestValidateCACertificates(c *gc.C) {
+	s.assertConfigValid(c, testing.Attrs{"ca-certificates": testing.CACert})
+	s.assertConfigInvalid(
+		c, testing.Attrs{"ca-certificates": "not a certificate"},
+		`invalid "ca-certificates" config: no PEM-encoded certificates found`,
+	)
+}
+
+func (s *configSuite) TestValidateNetworkSecurityGroup(c *gc.C) {
+	s.assertConfigValid(c, testing.Attrs{"network-security-group": testSecurityGroupID})
+	s.assertConfigInvalid(
+		c, testing.Attrs{"network-security-group": "baseline-nsg"},
+		`invalid "network-security-group" config: "baseline-nsg" is not the resource ID of a network security group`,
+	)
+	s.assertConfigInvalid(
+		c, testing.Attrs{
+			"network-security-group": testSecurityGroupID,
+			"ingress-default-deny":   true,
+		},
+		`"egress-allow-list" and "ingress-default-deny" config cannot be used with "network-security-group" config`,
+	)
+}
+
+func (s *configSuite) TestValidateSecurityRulePriorities(c *gc.C) {
+	s.assertConfigValid(c, testing.Attrs{"security-rule-priorities": "1000-1999"})
+	s.assertConfigValid(c, testing.Attrs{
+		"network-security-group":   testSecurityGroupID,
+		"security-rule-priorities": "100-4096",
+	})
+	s.assertConfigInvalid(
+		c, testing.Attrs{"security-rule-priorities": "1000"},
+		`invalid "security-rule-priorities" config: expected a range of the form "min-max", got "1000"`,
+	)
+	s.assertConfigInvalid(
+		c, testing.Attrs{"security-rule-priorities": "2000-1000"},
+		`invalid "security-rule-priorities" config: range 2000-1000 is empty`,
+	)
+	s.assertConfigInvalid(
+		c, testing.Attrs{"security-rule-priorities": "100-4096"},
+		`invalid "security-rule-priorities" config: range 100-4096 is not within 200-3999`,
+	)
+}
+
+func (s *configSuite) TestValidateResourceNamePrefix(c *gc.C) {
+	s.assertConfigValid(c, testing.Attrs{"resource-name-prefix": "Acme-Prod1"})
+	s.assertConfigInvalid(
+		c, testing.Attrs{"resource-name-prefix": "1acme"},
+		`invalid "resource-name-prefix" config "1acme": must start with a letter, and contain only letters, digits and hyphens`,
+	)
+	s.assertConfigInvalid(
+		c, testing.Attrs{"resource-name-prefix": "acme_prod"},
+		`invalid "resource-name-prefix" config "acme_prod": must start with a letter, and contain only letters, digits and hyphens`,
+	)
+	s.assertConfigInvalid(
+		c, testing.Attrs{"resource-name-prefix": strings.Repeat("a", 21)},
+		`invalid "resource-name-prefix" config "a{21}": must be no more than 20 characters`,
+	)
+}
+
+func (s *configSuite) TestValidateResourceNamePrefixCantChange(c *gc.C) {
+	cfgOld := makeTestModelConfig(c, testing.Attrs{"resource-name-prefix": "acme"})
+	cfgNew := makeTestModelConfig(c, testing.Attrs{"resource-name-prefix": "initech"})
+	_, err := s.provider.Validate(cfgNew, cfgOld)
+	c.Assert(err, gc.ErrorMatches, `cannot change immutable "resource-name-prefix" config \(acme -> initech\)`)
+}
+
+func (s *configSuite) TestValidateAPIProfile(c *gc.C) {
+	s.assertConfigValid(c, testing.Attrs{"api-profile": "latest"})
+	s.assertConfigValid(c, testing.Attrs{"api-profile": "2016-06-01"})
+	s.assertConfigInvalid(
+		c, testing.Attrs{"api-profile": "2015-01-01"},
+		`invalid "api-profile" config "2015-01-01", expected one of: \["2016-06-01" "latest"\]`,
+	)
+}
+
+func (s *configSuite) TestValidateStorageAccountKeyName(c *gc.C) {
+	s.assertConfigValid(c, testing.Attrs{"storage-account-key-name": "key1"})
+	s.assertConfigValid(c, testing.Attrs{"storage-account-key-name": "key2"})
+	s.assertConfigInvalid(
+		c, testing.Attrs{"storage-account-key-name": "key3"},
+		`invalid "storage-account-key-name" config "key3", expected one of: \["key1" "key2"\]`,
+	)
+}
+
+func (s *configSuite) TestValidateAPIProfileCantChange(c *gc.C) {
+	cfgOld := makeTestModelConfig(c, testing.Attrs{"api-profile": "2016-06-01"})
+	cfgNew := makeTestModelConfig(c, testing.Attrs{"api-profile": "latest"})
+	_, err := s.provider.Validate(cfgNew, cfgOld)
+	c.Assert(err, gc.ErrorMatches, `cannot change immutable "api-profile" config \(2016-06-01 -> latest\)`)
+}
+
+func (s *configSuite) TestValidateNetworkSecurityGroupCantChange(c *gc.C) {
+	cfgOld := makeTestModelConfig(c, testing.Attrs{"network-security-group": testSecurityGroupID})
+	cfgNew := makeTestModelConfig(c, testing.Attrs{"network-security-group": ""})
+	_, err := s.provider.Validate(cfgNew, cfgOld)
+	c.Assert(err, gc.ErrorMatches, `cannot change immutable "network-security-group" config \(.* -> \)`)
+}
+
 func (s *configSuite) TestValidateStorageAccountTypeCantChange(c *gc.C) {
 	cfgOld := makeTestModelConfig(c, testing.Attrs{"storage-account-type": "Standard_LRS"})
 	_, err := s.provider.Validate(cfgOld, cfgOld)
@@ -0,0 +1,129 @@
+// Copyright 2016 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package azure
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/Azure/go-autorest/autorest"
+
+	"github.com/juju/juju/environs"
+)
+
+var _ environs.CredentialInvalidatorSetter = (*azureEnviron)(nil)
+
+// SetCredentialInvalidator is specified in the
+// environs.CredentialInvalidatorSetter interface.
+func (env *azureEnviron) SetCredentialInvalidator(f environs.InvalidateCredentialFunc) {
+	env.credentialMu.Lock()
+	defer env.credentialMu.Unlock()
+	env.invalidateCredential = f
+}
+
+// credentialRejected calls the environ's credential invalidator, if it
+// has one, to record that Azure rejected the credential for the given
+// reason. The invalidator is called at most once for each credential;
+// it is called again only after the credential is updated with
+// SetCloudSpec.
+func (env *azureEnviron) credentialRejected(reason string) {
+	env.credentialMu.Lock()
+	invalidate := env.invalidateCredential
+	if invalidate == nil || env.credentialInvalidated {
+		env.credentialMu.Unlock()
+		return
+	}
+	env.credentialInvalidated = true
+	env.credentialMu.Unlock()
+
+	logger.Warningf("invalidating cloud credential: %s", reason)
+	if err := invalidate(reason); err != nil {
+		logger.Errorf("cannot invalidate cloud credential: %v", err)
+		env.credentialMu.Lock()
+		env.credentialInvalidated = false
+		env.credentialMu.Unlock()
+	}
+}
+
+// credentialInvalidatorRespondDecorator returns an autorest.RespondDecorator
+// that invalidates the environ's credential when Azure responds to a
+// request with 401 (Unauthorized). The response is passed on to the
+// decorated responder unchanged.
+//
+// 403 (Forbidden) responses do not invalidate the credential: they are
+// returned when a role assignment or policy denies a single operation
+// (e.g. RequestDisallowedByPolicy), and the credential is shared by
+// every model that uses it.
+func (env *azureEnviron) credentialInvalidatorRespondDecorator() autorest.RespondDecorator {
+	return func(r autorest.Responder) autorest.Responder {
+		return autorest.ResponderFunc(func(resp *http.Response) error {
+			if resp != nil && resp.StatusCode == http.StatusUnauthorized {
+				env.credentialRejected(fmt.Sprintf(
+					"Azure rejected the credential: %s", resp.Status,
+				))
+			}
+			return r.Respond(resp)
+		})
+	}
+}
+
+// credentialInvalidatorSendDecorator returns an autorest.SendDecorator
+// that invalidates the environ's credential when Azure rejects a token
+// refresh request because the client is invalid or not authorized, e.g.
+// because the service principal's secret has expired or the service
+// principal has been deleted. Token refreshes are sent outside of the
+// API clients, so their responses are not seen by the clients' response
+// inspectors.
+func (env *azureEnviron) credentialInvalidatorSendDecorator() autorest.SendDecorator {
+	return func(s autorest.Sender) autorest.Sender {
+		return autorest.SenderFunc(func(r *http.Request) (*http.Response, error) {
+			resp, err := s.Do(r)
+			if code := tokenRefreshErrorCode(resp); isCredentialErrorCode(code) {
+				env.credentialRejected(fmt.Sprintf(
+					"Azure rejected the credential: %s (%s)", resp.Status, code,
+				))
+			}
+			return resp, err
+		})
+	}
+}
+
+// tokenRefreshErrorCode returns the OAuth2 error code in the given
+// token refresh response, or "" if there is none. The response body
+// is restored so that it may be read again.
+func tokenRefreshErrorCode(resp *http.Response) string {
+	if resp == nil || resp.Body == nil || resp.StatusCode < 400 {
+		return ""
+	}
+	var buf bytes.Buffer
+	_, err := buf.ReadFrom(resp.Body)
+	resp.Body.Close()
+	resp.Body = ioutil.NopCloser(&buf)
+	if err != nil {
+		return ""
+	}
+	var body struct {
+		Error string `json:"error"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &body); err != nil {
+		// Don't treat failure to decode the body as an error,
+		// or we may get in the way of response handling.
+		return ""
+	}
+	return body.Error
+}
+
+// isCredentialErrorCode reports whether the given OAuth2 error code
+// indicates that the credential itself is invalid, rather than that
+// the request failed for some transient or unrelated reason.
+func isCredentialErrorCode(code string) bool {
+	switch code {
+	case "invalid_client", "unauthorized_client":
+		return true
+	}
+	return false
+}
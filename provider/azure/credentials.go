@@ -69,8 +69,18 @@ func (environProviderCredentials) CredentialSchemas() map[cloud.AuthType]cloud.C
 }
 
 // DetectCredentials is part of the environs.ProviderCredentials interface.
+//
+// Credentials are detected from the subscriptions that the Azure CLI
+// is logged in to, or failing that, from the environment variables
+// used by the Azure SDKs.
 func (environProviderCredentials) DetectCredentials() (*cloud.CloudCredential, error) {
-	return nil, errors.NotFoundf("credentials")
+	credentials, err := detectAzureCLICredentials(azureConfigDir())
+	if err == nil {
+		return credentials, nil
+	} else if !errors.IsNotFound(err) {
+		return nil, errors.Trace(err)
+	}
+	return detectEnvCredentials()
 }
 
 // FinalizeCredential is part of the environs.ProviderCredentials interface.
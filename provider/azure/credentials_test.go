@@ -5,6 +5,8 @@ package azure_test
 
 import (
 	"io"
+	"io/ioutil"
+	"path/filepath"
 
 	"github.com/Azure/go-autorest/autorest"
 	"github.com/juju/errors"
@@ -62,11 +64,95 @@ func (s *credentialsSuite) TestServicePrincipalSecretHiddenAttributes(c *gc.C) {
 	envtesting.AssertProviderCredentialsAttributesHidden(c, s.provider, "service-principal-secret", "application-password")
 }
 
-func (s *credentialsSuite) TestDetectCredentials(c *gc.C) {
+func (s *credentialsSuite) TestDetectCredentialsNotFound(c *gc.C) {
+	s.PatchEnvironment("AZURE_CONFIG_DIR", c.MkDir())
 	_, err := s.provider.DetectCredentials()
 	c.Assert(err, jc.Satisfies, errors.IsNotFound)
 }
 
+func (s *credentialsSuite) TestDetectCredentialsAzureCLI(c *gc.C) {
+	dir := c.MkDir()
+	s.PatchEnvironment("AZURE_CONFIG_DIR", dir)
+	// The Azure CLI writes its profile with a byte order mark.
+	writeFile(c, filepath.Join(dir, "azureProfile.json"), "\ufeff"+`{
+  "subscriptions": [{
+    "id": "user-subscription",
+    "name": "User Subscription",
+    "state": "Enabled",
+    "isDefault": true,
+    "user": {"name": "fred@example.com", "type": "user"}
+  }, {
+    "id": "sp-subscription",
+    "name": "SP Subscription",
+    "state": "Enabled",
+    "user": {"name": "sp-app-id", "type": "servicePrincipal"}
+  }, {
+    "id": "disabled-subscription",
+    "name": "Disabled Subscription",
+    "state": "Disabled",
+    "user": {"name": "fred@example.com", "type": "user"}
+  }]
+}`)
+	writeFile(c, filepath.Join(dir, "accessTokens.json"), `[{
+  "userId": "fred@example.com",
+  "accessToken": "user-token"
+}, {
+  "servicePrincipalId": "sp-app-id",
+  "servicePrincipalTenant": "tenant",
+  "accessToken": "sp-password"
+}]`)
+
+	userCredential := cloud.NewCredential("interactive", map[string]string{
+		"subscription-id": "user-subscription",
+	})
+	userCredential.Label = `azure credential for subscription "User Subscription"`
+	spCredential := cloud.NewCredential("service-principal-secret", map[string]string{
+		"application-id":       "sp-app-id",
+		"application-password": "sp-password",
+		"subscription-id":      "sp-subscription",
+	})
+	spCredential.Label = `azure credential for subscription "SP Subscription"`
+
+	credentials, err := s.provider.DetectCredentials()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(credentials, jc.DeepEquals, &cloud.CloudCredential{
+		DefaultCredential: "user-subscription",
+		AuthCredentials: map[string]cloud.Credential{
+			"user-subscription": userCredential,
+			"sp-subscription":   spCredential,
+		},
+	})
+}
+
+func (s *credentialsSuite) TestDetectCredentialsAzureCLIInvalid(c *gc.C) {
+	dir := c.MkDir()
+	s.PatchEnvironment("AZURE_CONFIG_DIR", dir)
+	writeFile(c, filepath.Join(dir, "azureProfile.json"), "{")
+	_, err := s.provider.DetectCredentials()
+	c.Assert(err, gc.ErrorMatches, "parsing .*azureProfile.json: .*")
+}
+
+func (s *credentialsSuite) TestDetectCredentialsEnvironmentVariables(c *gc.C) {
+	s.PatchEnvironment("AZURE_CONFIG_DIR", c.MkDir())
+	s.PatchEnvironment("USER", "fred")
+	s.PatchEnvironment("AZURE_SUBSCRIPTION_ID", "subscription")
+	s.PatchEnvironment("AZURE_CLIENT_ID", "application")
+	s.PatchEnvironment("AZURE_CLIENT_SECRET", "password")
+
+	credentials, err := s.provider.DetectCredentials()
+	c.Assert(err, jc.ErrorIsNil)
+	expected := cloud.NewCredential("service-principal-secret", sampleCredentialAttributes)
+	expected.Label = `azure credential "fred"`
+	c.Assert(credentials.AuthCredentials, jc.DeepEquals, map[string]cloud.Credential{
+		"fred": expected,
+	})
+}
+
+func writeFile(c *gc.C, path, content string) {
+	err := ioutil.WriteFile(path, []byte(content), 0600)
+	c.Assert(err, jc.ErrorIsNil)
+}
+
 func (s *credentialsSuite) TestFinalizeCredentialInteractive(c *gc.C) {
 	in := cloud.NewCredential("interactive", map[string]string{"subscription-id": "subscription"})
 	ctx := coretesting.Context(c)
@@ -4,8 +4,13 @@
 package azure
 
 import (
+	"encoding/json"
+	"net/http"
+
 	"github.com/Azure/azure-sdk-for-go/arm/resources/resources"
 	"github.com/Azure/go-autorest/autorest"
+	"github.com/Azure/go-autorest/autorest/azure"
+	"github.com/Azure/go-autorest/autorest/to"
 	"github.com/juju/errors"
 
 	"github.com/juju/juju/provider/azure/internal/armtemplates"
@@ -18,16 +23,10 @@ func createDeployment(
 	deploymentName string,
 	t armtemplates.Template,
 ) error {
-	templateMap, err := t.Map()
+	deployment, err := newDeployment(t)
 	if err != nil {
 		return errors.Trace(err)
 	}
-	deployment := resources.Deployment{
-		&resources.DeploymentProperties{
-			Template: &templateMap,
-			Mode:     resources.Incremental,
-		},
-	}
 	if err := callAPI(func() (autorest.Response, error) {
 		return client.CreateOrUpdate(
 			resourceGroup,
@@ -40,3 +39,77 @@ func createDeployment(
 	}
 	return nil
 }
+
+// validateDeployment asks Azure Resource Manager to validate the
+// deployment of the given template, without creating any resources.
+// If the deployment would fail, or cannot be validated, the returned
+// error is a *deploymentValidationError; if Azure rejected the
+// deployment, its cause is the service error describing why.
+func validateDeployment(
+	callAPI callAPIFunc,
+	client resources.DeploymentsClient,
+	resourceGroup string,
+	deploymentName string,
+	t armtemplates.Template,
+) error {
+	deployment, err := newDeployment(t)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	var result resources.DeploymentValidateResult
+	if err := callAPI(func() (autorest.Response, error) {
+		var err error
+		result, err = client.Validate(resourceGroup, deploymentName, deployment)
+		return result.Response, err
+	}); err != nil {
+		return &deploymentValidationError{
+			errors.Annotatef(err, "validating deployment %q", deploymentName),
+		}
+	}
+	if result.Error == nil {
+		return nil
+	}
+	// The validation failure is reported in the body of the response,
+	// rather than as a request error. It is converted to the service
+	// error that the deployment itself would have failed with, so that
+	// it may be classified in the same way.
+	serviceErr := &azure.ServiceError{
+		Code:    to.String(result.Error.Code),
+		Message: to.String(result.Error.Message),
+	}
+	if result.Error.Details != nil {
+		var details []interface{}
+		if data, err := json.Marshal(result.Error.Details); err == nil {
+			if err := json.Unmarshal(data, &details); err == nil {
+				serviceErr.Details = &details
+			}
+		}
+	}
+	return &deploymentValidationError{errors.Annotatef(
+		autorest.DetailedError{
+			Original:   &azure.RequestError{ServiceError: serviceErr},
+			StatusCode: http.StatusBadRequest,
+		},
+		"validating deployment %q", deploymentName,
+	)}
+}
+
+// deploymentValidationError is returned by validateDeployment when a
+// deployment fails validation. No resources have been created when
+// such an error is returned, so there is nothing to clean up.
+type deploymentValidationError struct {
+	error
+}
+
+func newDeployment(t armtemplates.Template) (resources.Deployment, error) {
+	templateMap, err := t.Map()
+	if err != nil {
+		return resources.Deployment{}, errors.Trace(err)
+	}
+	return resources.Deployment{
+		&resources.DeploymentProperties{
+			Template: &templateMap,
+			Mode:     resources.Incremental,
+		},
+	}, nil
+}
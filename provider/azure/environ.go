@@ -10,6 +10,7 @@ import (
 	"sort"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/Azure/azure-sdk-for-go/arm/compute"
 	"github.com/Azure/azure-sdk-for-go/arm/network"
@@ -33,14 +34,17 @@ import (
 	"github.com/juju/juju/environs/instances"
 	"github.com/juju/juju/environs/simplestreams"
 	"github.com/juju/juju/environs/tags"
+	envtools "github.com/juju/juju/environs/tools"
 	"github.com/juju/juju/instance"
 	jujunetwork "github.com/juju/juju/network"
 	"github.com/juju/juju/provider/azure/internal/armtemplates"
 	internalazurestorage "github.com/juju/juju/provider/azure/internal/azurestorage"
 	"github.com/juju/juju/provider/azure/internal/errorutils"
+	"github.com/juju/juju/provider/azure/internal/imds"
 	"github.com/juju/juju/provider/azure/internal/tracing"
 	"github.com/juju/juju/provider/common"
 	"github.com/juju/juju/state"
+	jujustorage "github.com/juju/juju/storage"
 	"github.com/juju/juju/tools"
 )
 
@@ -90,6 +94,10 @@ type azureEnviron struct {
 	// authorizer is the authorizer we use for Azure.
 	authorizer *cloudSpecAuth
 
+	// sender sends the Azure API clients' requests, honouring the
+	// model's proxy and CA certificate settings.
+	sender *configuredSender
+
 	compute            compute.ManagementClient
 	resources          resources.ManagementClient
 	storage            storage.ManagementClient
@@ -97,15 +105,37 @@ type azureEnviron struct {
 	storageClient      azurestorage.Client
 	storageAccountName string
 
+	// apiProfile holds the versions of the Azure APIs used to manage
+	// the model's resources. The API profile is immutable, so this
+	// is set once, when the environ is created.
+	apiProfile apiProfile
+
 	mu                sync.Mutex
 	config            *azureModelConfig
 	instanceTypes     map[string]instances.InstanceType
+	maxDataDisks      map[string]int
 	storageAccount    *storage.Account
 	storageAccountKey *storage.AccountKey
+
+	// policyReconciled records whether or not the rules enforcing the
+	// model's network policy have been reconciled with the model
+	// config since the environ was opened.
+	policyReconciled bool
+
+	// invalidateCredential, if non-nil, is called when Azure rejects
+	// the environ's credential. credentialInvalidated records that it
+	// has been called for the current credential. These are guarded
+	// by credentialMu rather than mu, as API requests are made, and
+	// their responses inspected, while mu is held.
+	credentialMu          sync.Mutex
+	invalidateCredential  environs.InvalidateCredentialFunc
+	credentialInvalidated bool
 }
 
 var _ environs.Environ = (*azureEnviron)(nil)
+var _ environs.CloudSpecSetter = (*azureEnviron)(nil)
 var _ state.Prechecker = (*azureEnviron)(nil)
+var _ environs.InstanceCommandRunner = (*azureEnviron)(nil)
 
 // newEnviron creates a new azureEnviron.
 func newEnviron(
@@ -133,22 +163,16 @@ func newEnviron(
 	if err := env.SetConfig(cfg); err != nil {
 		return nil, errors.Trace(err)
 	}
+	env.setAPIProfile(env.config.apiProfile)
 
 	modelTag := names.NewModelTag(cfg.UUID())
 	env.resourceGroup = resourceGroupName(modelTag, cfg.Name())
 	env.envName = cfg.Name()
 
-	// We need a deterministic storage account name, so that we can
-	// defer creation of the storage account to the VM deployment,
-	// and retain the ability to create multiple deployments in
-	// parallel.
-	//
-	// We use the last 20 non-hyphen hex characters of the model's
-	// UUID as the storage account name, prefixed with "juju". The
-	// probability of clashing with another storage account should
-	// be negligible.
-	uuidAlphaNumeric := strings.Replace(env.config.Config.UUID(), "-", "", -1)
-	env.storageAccountName = "juju" + uuidAlphaNumeric[len(uuidAlphaNumeric)-20:]
+	// The primary storage account holds the model's data disks, and
+	// the OS disks of its machines until it is full; see
+	// modelStorageAccountName and chooseStorageAccount.
+	env.storageAccountName = modelStorageAccountName(cfg.UUID(), 0)
 
 	return &env, nil
 }
@@ -156,9 +180,15 @@ func newEnviron(
 func (env *azureEnviron) initEnviron() error {
 	credAttrs := env.cloud.Credential.Attributes()
 	env.subscriptionId = credAttrs[credAttrSubscriptionId]
+	env.sender = &configuredSender{}
+	var sender autorest.Sender = env.sender
+	if env.provider.config.Sender != nil {
+		sender = env.provider.config.Sender
+	}
 	env.authorizer = &cloudSpecAuth{
-		cloud:  env.cloud,
-		sender: env.provider.config.Sender,
+		cloud:              env.cloud,
+		sender:             sender,
+		tokenSendDecorator: env.credentialInvalidatorSendDecorator(),
 	}
 
 	env.compute = compute.NewWithBaseURI(env.cloud.Endpoint, env.subscriptionId)
@@ -173,11 +203,13 @@ func (env *azureEnviron) initEnviron() error {
 	}
 	for id, client := range clients {
 		client.Authorizer = env.authorizer
+		client.Sender = sender
 		logger := loggo.GetLogger(id)
-		if env.provider.config.Sender != nil {
-			client.Sender = env.provider.config.Sender
-		}
-		client.ResponseInspector = tracing.RespondDecorator(logger)
+		client.ResponseInspector = respondDecorators(
+			tracing.RespondDecorator(logger),
+			env.provider.tracer.RespondDecorator(),
+			env.credentialInvalidatorRespondDecorator(),
+		)
 		client.RequestInspector = tracing.PrepareDecorator(logger)
 		if env.provider.config.RequestInspector != nil {
 			tracer := client.RequestInspector
@@ -199,7 +231,7 @@ func (env *azureEnviron) PrepareForBootstrap(ctx environs.BootstrapContext) erro
 			return errors.Trace(err)
 		}
 	}
-	return nil
+	return errors.Trace(verifyAPIProfile(env))
 }
 
 // Create is part of the Environ interface.
@@ -207,6 +239,9 @@ func (env *azureEnviron) Create(args environs.CreateParams) error {
 	if err := verifyCredentials(env); err != nil {
 		return errors.Trace(err)
 	}
+	if err := verifyAPIProfile(env); err != nil {
+		return errors.Trace(err)
+	}
 	return errors.Trace(env.initResourceGroup(args.ControllerUUID))
 }
 
@@ -279,18 +314,81 @@ func (env *azureEnviron) Config() *config.Config {
 // SetConfig is specified in the Environ interface.
 func (env *azureEnviron) SetConfig(cfg *config.Config) error {
 	env.mu.Lock()
-	defer env.mu.Unlock()
-
+	oldConfig := env.config
 	var old *config.Config
-	if env.config != nil {
-		old = env.config.Config
+	if oldConfig != nil {
+		old = oldConfig.Config
 	}
 	ecfg, err := validateConfig(cfg, old)
 	if err != nil {
+		env.mu.Unlock()
 		return err
 	}
+	if oldConfig == nil || !httpConfigEqual(oldConfig, ecfg) {
+		client, err := newHTTPClient(ecfg.ProxySettings(), ecfg.caCertPool)
+		if err != nil {
+			env.mu.Unlock()
+			return errors.Annotate(err, "configuring HTTP client")
+		}
+		env.sender.setHTTPClient(client)
+	}
+	if old != nil && old.ImageStream() != ecfg.ImageStream() {
+		// The image stream has changed, so discard any images
+		// resolved for the old stream; they will be resolved
+		// again should the stream be changed back.
+		env.provider.imageCache.InvalidateStream(old.ImageStream())
+	}
+	if oldConfig != nil && oldConfig.storageAccountKeyName != ecfg.storageAccountKeyName {
+		// The storage account keys have been rotated, so the
+		// cached key will no longer be accepted.
+		env.storageAccountKey = nil
+	}
 	env.config = ecfg
+	env.mu.Unlock()
 
+	// The network policy rules are created along with the network
+	// security group, when the first machine is started. Thereafter,
+	// they must be updated whenever the policy is changed. Opening
+	// the environ does not incur network communication, so a policy
+	// changed while it was not open is reconciled when the security
+	// group is next queried for the machines' ports.
+	if oldConfig != nil && !policyConfigEqual(oldConfig, ecfg) {
+		if err := env.updatePolicySecurityRules(); err != nil {
+			return errors.Annotate(err, "updating network policy")
+		}
+	}
+	return nil
+}
+
+// securityGroup returns the network security group in which the
+// security rules for the ports opened on the model's machines are
+// managed, and the range of priorities reserved for those rules.
+func (env *azureEnviron) securityGroup() (securityGroupRef, priorityRange) {
+	env.mu.Lock()
+	defer env.mu.Unlock()
+	return env.config.securityGroup, env.config.securityRulePriorities
+}
+
+// SetCloudSpec is specified in the environs.CloudSpecSetter interface.
+// Only the credential may be changed; the subscription and endpoints
+// must be the same as those with which the Environ was opened.
+func (env *azureEnviron) SetCloudSpec(spec environs.CloudSpec) error {
+	if err := validateCloudSpec(spec); err != nil {
+		return errors.Annotate(err, "validating cloud spec")
+	}
+	env.mu.Lock()
+	defer env.mu.Unlock()
+	if spec.Credential.Attributes()[credAttrSubscriptionId] != env.subscriptionId {
+		return errors.NotSupportedf("changing subscription")
+	}
+	if spec.Endpoint != env.cloud.Endpoint || spec.StorageEndpoint != env.cloud.StorageEndpoint {
+		return errors.NotSupportedf("changing endpoints")
+	}
+	env.cloud = spec
+	env.authorizer.setCloudSpec(spec)
+	env.credentialMu.Lock()
+	env.credentialInvalidated = false
+	env.credentialMu.Unlock()
 	return nil
 }
 
@@ -311,6 +409,7 @@ func (env *azureEnviron) ConstraintsValidator() (constraints.Validator, error) {
 		constraints.CpuPower,
 		constraints.Tags,
 		constraints.VirtType,
+		constraints.MaxHourlyCost,
 	})
 	validator.RegisterVocabulary(
 		constraints.Arch,
@@ -334,7 +433,9 @@ func (env *azureEnviron) ConstraintsValidator() (constraints.Validator, error) {
 // PrecheckInstance is defined on the state.Prechecker interface.
 func (env *azureEnviron) PrecheckInstance(series string, cons constraints.Value, placement string) error {
 	if placement != "" {
-		return fmt.Errorf("unknown placement directive: %s", placement)
+		if _, err := parsePlacement(placement); err != nil {
+			return err
+		}
 	}
 	if !cons.HasInstanceType() {
 		return nil
@@ -359,9 +460,34 @@ func (*azureEnviron) MaintainInstance(args environs.StartInstanceParams) error {
 
 // StartInstance is specified in the InstanceBroker interface.
 func (env *azureEnviron) StartInstance(args environs.StartInstanceParams) (*environs.StartInstanceResult, error) {
+	return env.startInstance(args, "start-instance", nil)
+}
+
+// startInstance starts an instance with the given parameters, tracing
+// its requests as the named operation. The volumes described by
+// volumeAttachments, which must already exist, are attached to the
+// instance when it is created; this is done only when replacing an
+// instance, in which case any OS disk left behind by the replaced
+// instance is deleted first.
+func (env *azureEnviron) startInstance(
+	args environs.StartInstanceParams,
+	operation string,
+	volumeAttachments []jujustorage.VolumeAttachmentParams,
+) (*environs.StartInstanceResult, error) {
 	if args.ControllerUUID == "" {
 		return nil, errors.New("missing controller UUID")
 	}
+	var placement azurePlacement
+	if args.Placement != "" {
+		p, err := parsePlacement(args.Placement)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		if p.availabilitySet != "" && args.InstanceConfig.Controller != nil {
+			return nil, errors.New("cannot place controller in a named availability set")
+		}
+		placement = *p
+	}
 
 	// Get the required configuration and config-dependent information
 	// required to create the instance. We take the lock just once, to
@@ -372,7 +498,16 @@ func (env *azureEnviron) StartInstance(args environs.StartInstanceParams) (*envi
 		names.NewControllerTag(args.ControllerUUID),
 		env.config,
 	)
+	modelUUID := env.config.Config.UUID()
 	storageAccountType := env.config.storageAccountType
+	dnsLabelPrefix := env.config.dnsLabelPrefix
+	egressAllowList := env.config.egressAllowList
+	ingressDefaultDeny := env.config.ingressDefaultDeny
+	securityGroupID := env.config.securityGroup.id
+	availabilitySets := env.config.availabilitySets
+	availabilitySetExclusions := env.config.availabilitySetExclusions
+	resourceNamePrefix := env.config.resourceNamePrefix
+	validateDeployments := env.config.validateDeployments
 	imageStream := env.config.ImageStream()
 	instanceTypes, err := env.getInstanceTypesLocked()
 	if err != nil {
@@ -381,6 +516,14 @@ func (env *azureEnviron) StartInstance(args environs.StartInstanceParams) (*envi
 	}
 	env.mu.Unlock()
 
+	// Requests made for the machine are traced as part of a single
+	// operation, so that slow provisioning can be diagnosed.
+	machineTag := names.NewMachineTag(args.InstanceConfig.MachineId)
+	clients, err := env.machineClients(modelUUID, machineTag.String(), operation)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+
 	// If the user has not specified a root-disk size, then
 	// set a sensible default.
 	var rootDisk uint64
@@ -394,7 +537,8 @@ func (env *azureEnviron) StartInstance(args environs.StartInstanceParams) (*envi
 	// Identify the instance type and image to provision.
 	series := args.Tools.OneSeries()
 	instanceSpec, err := findInstanceSpec(
-		compute.VirtualMachineImagesClient{env.compute},
+		compute.VirtualMachineImagesClient{clients.compute},
+		env.provider.imageCache,
 		instanceTypes,
 		&instances.InstanceConstraint{
 			Region:      env.location,
@@ -405,7 +549,11 @@ func (env *azureEnviron) StartInstance(args environs.StartInstanceParams) (*envi
 		imageStream,
 	)
 	if err != nil {
-		return nil, err
+		if _, ok := errors.Cause(err).(autorest.DetailedError); !ok {
+			// No instance type or image satisfies the constraints.
+			return nil, environs.NewProvisioningError(err, environs.ProvisioningErrorConfig)
+		}
+		return nil, errorutils.ClassifyProvisioningError(err)
 	}
 	if rootDisk < uint64(instanceSpec.InstanceType.RootDisk) {
 		// The InstanceType's RootDisk is set to the maximum
@@ -414,16 +562,14 @@ func (env *azureEnviron) StartInstance(args environs.StartInstanceParams) (*envi
 		instanceSpec.InstanceType.RootDisk = rootDisk
 	}
 
-	// Windows images are 127GiB, and cannot be made smaller.
-	const windowsMinRootDiskMB = 127 * 1024
-	seriesOS, err := jujuseries.GetOSFromSeries(series)
-	if err != nil {
-		return nil, errors.Trace(err)
-	}
-	if seriesOS == os.Windows {
-		if instanceSpec.InstanceType.RootDisk < windowsMinRootDiskMB {
-			instanceSpec.InstanceType.RootDisk = windowsMinRootDiskMB
-		}
+	if minRootDisk := instanceSpec.Image.MinRootDisk; instanceSpec.InstanceType.RootDisk < minRootDisk {
+		// Some images (e.g. Windows) have OS disks
+		// that cannot be made smaller than their own.
+		logger.Debugf(
+			"increasing root disk size from %dM to %dM, as required by image %q",
+			instanceSpec.InstanceType.RootDisk, minRootDisk, instanceSpec.Image.Id,
+		)
+		instanceSpec.InstanceType.RootDisk = minRootDisk
 	}
 
 	// Pick tools by filtering the available tools down to the architecture of
@@ -446,8 +592,7 @@ func (env *azureEnviron) StartInstance(args environs.StartInstanceParams) (*envi
 		return nil, err
 	}
 
-	machineTag := names.NewMachineTag(args.InstanceConfig.MachineId)
-	vmName := resourceName(machineTag)
+	vmName := prefixResourceName(resourceNamePrefix, resourceName(machineTag))
 	vmTags := make(map[string]string)
 	for k, v := range args.InstanceConfig.Tags {
 		vmTags[k] = v
@@ -457,16 +602,111 @@ func (env *azureEnviron) StartInstance(args environs.StartInstanceParams) (*envi
 	// machine with this.
 	vmTags[jujuMachineNameTag] = vmName
 
+	// Network resources left behind by a previous machine with the
+	// same ID (e.g. one that was force-destroyed) are discovered by
+	// their tags and deleted; otherwise they would hold on to the
+	// machine's private IP address, and report stale addresses for
+	// the new instance.
+	if err := env.deleteOrphanedMachineResources(clients, instance.Id(vmName)); err != nil {
+		return nil, errorutils.ClassifyProvisioningError(
+			errors.Annotatef(err, "deleting orphaned resources for %q", vmName),
+		)
+	}
+
+	// The names of the machine's network resources are made unique,
+	// so they never collide with those of a previous machine with the
+	// same ID that have not yet been deleted.
+	uuid, err := env.provider.config.NewUUID()
+	if err != nil {
+		return nil, errors.Annotate(err, "generating resource name suffix")
+	}
+	resourceSuffix := uuid.String()[:8]
+
+	// The model's existing virtual machines record the storage
+	// accounts and availability sets that they were placed in.
+	vms, err := env.listVirtualMachines(clients)
+	if err != nil {
+		return nil, errorutils.ClassifyProvisioningError(err)
+	}
+
+	// The machine's OS disk is placed in the least-loaded of the
+	// model's storage accounts, which is created along with the
+	// machine if necessary.
+	storageAccountName := chooseStorageAccount(
+		env.storageAccountName,
+		func(index int) string {
+			return modelStorageAccountName(modelUUID, index)
+		},
+		vms,
+	)
+
+	availabilitySetName, err := availabilitySetName(
+		vmName, vmTags, args.InstanceConfig.Controller != nil,
+		availabilitySets, availabilitySetExclusions, resourceNamePrefix, vms,
+	)
+	if err != nil {
+		return nil, errors.Annotate(err, "getting availability set name")
+	}
+	if placement.availabilitySet != "" {
+		// The availability set named in the placement directive
+		// overrides the implicit choice of availability set.
+		availabilitySetName = prefixResourceName(resourceNamePrefix, placement.availabilitySet)
+	}
+
+	// The DNS label is formed from the DNS label prefix and the
+	// machine's name, and not the prefixed resource name, so that
+	// it fits within the 63 characters allowed.
+	dnsLabel := dnsLabelPrefix + "-" + resourceName(machineTag)
+
+	var dataDisks []compute.DataDisk
+	var deleteDataDisks []string
+	var attachments []jujustorage.VolumeAttachment
+	if volumeAttachments != nil {
+		// The replaced instance had the same name, so its OS
+		// disk, if it remains, would prevent the creation of the
+		// replacement's.
+		if err := env.deleteStaleOSDisk(storageAccountName, vmName); err != nil {
+			return nil, errorutils.ClassifyProvisioningError(err)
+		}
+		dataDisks, deleteDataDisks, attachments, err = env.existingDataDisks(volumeAttachments)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+	}
+
 	if err := env.createVirtualMachine(
-		vmName, vmTags, envTags,
+		clients, vmName, resourceSuffix, vmTags, envTags,
 		instanceSpec, args.InstanceConfig,
-		storageAccountType,
+		storageAccountName, storageAccountType, dnsLabel,
+		availabilitySetName, placement.proximityPlacementGroup,
+		securityGroupID, egressAllowList, ingressDefaultDeny,
+		dataDisks, deleteDataDisks,
+		validateDeployments,
 	); err != nil {
+		if verr, ok := errors.Cause(err).(*deploymentValidationError); ok {
+			// The deployment was rejected before any
+			// resources were created, so there is
+			// nothing to destroy.
+			return nil, errorutils.ClassifyProvisioningError(
+				errors.Annotatef(verr.error, "creating virtual machine %q", vmName),
+			)
+		}
 		logger.Errorf("creating instance failed, destroying: %v", err)
-		if err := env.StopInstances(instance.Id(vmName)); err != nil {
-			logger.Errorf("could not destroy failed virtual machine: %v", err)
+		var stopErr error
+		if volumeAttachments != nil {
+			// The machine's existing data disks must
+			// not be deleted along with the failed
+			// virtual machine.
+			stopErr = env.DeleteReplacement(instance.Id(vmName))
+		} else {
+			stopErr = env.StopInstances(instance.Id(vmName))
+		}
+		if stopErr != nil {
+			logger.Errorf("could not destroy failed virtual machine: %v", stopErr)
 		}
-		return nil, errors.Annotatef(err, "creating virtual machine %q", vmName)
+		return nil, errorutils.ClassifyProvisioningError(
+			errors.Annotatef(err, "creating virtual machine %q", vmName),
+		)
 	}
 
 	// Note: the instance is initialised without addresses to keep the
@@ -481,24 +721,68 @@ func (env *azureEnviron) StartInstance(args environs.StartInstanceParams) (*envi
 		CpuCores: &instanceSpec.InstanceType.CpuCores,
 	}
 	return &environs.StartInstanceResult{
-		Instance: inst,
-		Hardware: hc,
+		Instance:          inst,
+		Hardware:          hc,
+		VolumeAttachments: attachments,
 	}, nil
 }
 
+// listVirtualMachines returns the virtual machines in the model's
+// resource group. Requests are made with the given clients.
+func (env *azureEnviron) listVirtualMachines(clients machineClients) ([]compute.VirtualMachine, error) {
+	vmClient := compute.VirtualMachinesClient{clients.compute}
+	var result compute.VirtualMachineListResult
+	if err := env.callAPI(func() (autorest.Response, error) {
+		var err error
+		result, err = vmClient.List(env.resourceGroup)
+		return result.Response, err
+	}); err != nil {
+		return nil, errors.Annotate(err, "listing virtual machines")
+	}
+	if result.Value == nil {
+		return nil, nil
+	}
+	return *result.Value, nil
+}
+
 // createVirtualMachine creates a virtual machine and related resources.
 //
 // All resources created are tagged with the specified "vmTags", so if
-// this function fails then all resources can be deleted by tag.
+// this function fails then all resources can be deleted by tag. The
+// names of the network resources are suffixed with resourceSuffix.
+// The virtual machine's public IP address is assigned the given DNS
+// label. The model's network security group is created, if necessary,
+// with rules enforcing the network policy given by egressAllowList and
+// ingressDefaultDeny, unless securityGroupID identifies an existing
+// group to use instead.
+// The virtual machine's OS disk is placed in the named storage account,
+// which is created, if necessary, with the given type. The virtual
+// machine is placed in the named availability set, if availabilitySetName
+// is non-empty, and in the named proximity placement group, if
+// proximityPlacementGroupName is non-empty; each is created if
+// necessary. The given data disks, which must already exist, are
+// attached to the virtual machine; those named in deleteDataDisks are
+// to be deleted along with it. If validate is true, the deployment is
+// validated before it is created, and a *deploymentValidationError is
+// returned if it fails validation. Requests are made with the given
+// clients.
 func (env *azureEnviron) createVirtualMachine(
-	vmName string,
+	clients machineClients,
+	vmName, resourceSuffix string,
 	vmTags, envTags map[string]string,
 	instanceSpec *instances.InstanceSpec,
 	instanceConfig *instancecfg.InstanceConfig,
-	storageAccountType string,
+	storageAccountName, storageAccountType, dnsLabel string,
+	availabilitySetName, proximityPlacementGroupName string,
+	securityGroupID string,
+	egressAllowList []string,
+	ingressDefaultDeny bool,
+	dataDisks []compute.DataDisk,
+	deleteDataDisks []string,
+	validate bool,
 ) error {
 
-	deploymentsClient := resources.DeploymentsClient{env.resources}
+	deploymentsClient := resources.DeploymentsClient{clients.resources}
 
 	var apiPort int
 	if instanceConfig.Controller != nil {
@@ -511,10 +795,14 @@ func (env *azureEnviron) createVirtualMachine(
 		}
 		apiPort = apiPorts[0]
 	}
-	resources := networkTemplateResources(env.location, envTags, apiPort)
+	policyRules := policySecurityRules(egressAllowList, ingressDefaultDeny, apiPort)
+	resources := networkTemplateResources(
+		env.apiProfile, env.location, envTags, apiPort,
+		securityGroupID, policyRules,
+	)
 	resources = append(resources, storageAccountTemplateResource(
-		env.location, envTags,
-		env.storageAccountName, storageAccountType,
+		env.apiProfile, env.location, envTags,
+		storageAccountName, storageAccountType,
 	))
 
 	osProfile, seriesOS, err := newOSProfile(
@@ -524,47 +812,91 @@ func (env *azureEnviron) createVirtualMachine(
 	if err != nil {
 		return errors.Annotate(err, "creating OS profile")
 	}
-	storageProfile, err := newStorageProfile(vmName, env.storageAccountName, instanceSpec)
+	storageProfile, err := newStorageProfile(
+		env.apiProfile, vmName, storageAccountName, instanceSpec,
+	)
 	if err != nil {
 		return errors.Annotate(err, "creating storage profile")
 	}
 
 	var vmDependsOn []string
-	var availabilitySetSubResource *compute.SubResource
-	availabilitySetName, err := availabilitySetName(
-		vmName, vmTags, instanceConfig.Controller != nil,
-	)
-	if err != nil {
-		return errors.Annotate(err, "getting availability set name")
+	vmAPIVersion := env.apiProfile.compute
+	var proximityPlacementGroupSubResource *compute.SubResource
+	if proximityPlacementGroupName != "" {
+		if env.apiProfile.name != apiProfileLatest {
+			return errors.NotSupportedf(
+				"proximity placement groups with %q API profile",
+				env.apiProfile.name,
+			)
+		}
+		// Proximity placement groups, and the resources placed in
+		// them, require a newer API version than the SDK's.
+		vmAPIVersion = proximityPlacementGroupAPIVersion
+		proximityPlacementGroupId := fmt.Sprintf(
+			`[resourceId('Microsoft.Compute/proximityPlacementGroups','%s')]`,
+			proximityPlacementGroupName,
+		)
+		resources = append(resources, armtemplates.Resource{
+			APIVersion: proximityPlacementGroupAPIVersion,
+			Type:       "Microsoft.Compute/proximityPlacementGroups",
+			Name:       proximityPlacementGroupName,
+			Location:   env.location,
+			Tags:       envTags,
+			Properties: &proximityPlacementGroupProperties{
+				ProximityPlacementGroupType: "Standard",
+			},
+		})
+		proximityPlacementGroupSubResource = &compute.SubResource{
+			ID: to.StringPtr(proximityPlacementGroupId),
+		}
+		vmDependsOn = append(vmDependsOn, proximityPlacementGroupId)
 	}
+
+	var availabilitySetSubResource *compute.SubResource
 	if availabilitySetName != "" {
 		availabilitySetId := fmt.Sprintf(
 			`[resourceId('Microsoft.Compute/availabilitySets','%s')]`,
 			availabilitySetName,
 		)
-		resources = append(resources, armtemplates.Resource{
-			APIVersion: compute.APIVersion,
+		availabilitySet := armtemplates.Resource{
+			APIVersion: env.apiProfile.compute,
 			Type:       "Microsoft.Compute/availabilitySets",
 			Name:       availabilitySetName,
 			Location:   env.location,
 			Tags:       envTags,
-		})
+		}
+		if proximityPlacementGroupSubResource != nil {
+			// A virtual machine in both an availability set and
+			// a proximity placement group requires the set to be
+			// in the same group.
+			availabilitySet.APIVersion = proximityPlacementGroupAPIVersion
+			availabilitySet.Properties = &availabilitySetProperties{
+				ProximityPlacementGroup: proximityPlacementGroupSubResource,
+			}
+			availabilitySet.DependsOn = []string{
+				to.String(proximityPlacementGroupSubResource.ID),
+			}
+		}
+		resources = append(resources, availabilitySet)
 		availabilitySetSubResource = &compute.SubResource{
 			ID: to.StringPtr(availabilitySetId),
 		}
 		vmDependsOn = append(vmDependsOn, availabilitySetId)
 	}
 
-	publicIPAddressName := vmName + "-public-ip"
+	publicIPAddressName := vmName + "-public-ip-" + resourceSuffix
 	publicIPAddressId := fmt.Sprintf(`[resourceId('Microsoft.Network/publicIPAddresses', '%s')]`, publicIPAddressName)
 	resources = append(resources, armtemplates.Resource{
-		APIVersion: network.APIVersion,
+		APIVersion: env.apiProfile.network,
 		Type:       "Microsoft.Network/publicIPAddresses",
 		Name:       publicIPAddressName,
 		Location:   env.location,
 		Tags:       vmTags,
 		Properties: &network.PublicIPAddressPropertiesFormat{
 			PublicIPAllocationMethod: network.Dynamic,
+			DNSSettings: &network.PublicIPAddressDNSSettings{
+				DomainNameLabel: to.StringPtr(dnsLabel),
+			},
 		},
 	})
 
@@ -586,7 +918,7 @@ func (env *azureEnviron) createVirtualMachine(
 	if err != nil {
 		return errors.Annotatef(err, "computing private IP address")
 	}
-	nicName := vmName + "-primary"
+	nicName := vmName + "-primary-" + resourceSuffix
 	nicId := fmt.Sprintf(`[resourceId('Microsoft.Network/networkInterfaces', '%s')]`, nicName)
 	ipConfigurations := []network.InterfaceIPConfiguration{{
 		Name: to.StringPtr("primary"),
@@ -601,13 +933,18 @@ func (env *azureEnviron) createVirtualMachine(
 		},
 	}}
 	resources = append(resources, armtemplates.Resource{
-		APIVersion: network.APIVersion,
+		APIVersion: env.apiProfile.network,
 		Type:       "Microsoft.Network/networkInterfaces",
 		Name:       nicName,
 		Location:   env.location,
 		Tags:       vmTags,
 		Properties: &network.InterfacePropertiesFormat{
 			IPConfigurations: &ipConfigurations,
+			// Label the NIC with the VM's name, so its internal
+			// DNS name is reported along with its private address.
+			DNSSettings: &network.InterfaceDNSSettings{
+				InternalDNSNameLabel: to.StringPtr(vmName),
+			},
 		},
 		DependsOn: []string{
 			publicIPAddressId,
@@ -627,28 +964,56 @@ func (env *azureEnviron) createVirtualMachine(
 	vmDependsOn = append(vmDependsOn, nicId)
 	vmDependsOn = append(vmDependsOn, fmt.Sprintf(
 		`[resourceId('Microsoft.Storage/storageAccounts', '%s')]`,
-		env.storageAccountName,
+		storageAccountName,
 	))
+	// The storage account holding the OS disk is recorded in the
+	// virtual machine's tags, so the disk can be found and deleted
+	// along with the virtual machine. The availability set is also
+	// recorded, so that later machines for the same application are
+	// placed consistently, as is the proximity placement group, if any.
+	vmResourceTags := make(map[string]string)
+	for k, v := range vmTags {
+		vmResourceTags[k] = v
+	}
+	vmResourceTags[jujuStorageAccountTag] = storageAccountName
+	vmResourceTags[jujuAvailabilitySetTag] = availabilitySetName
+	if proximityPlacementGroupName != "" {
+		vmResourceTags[jujuProximityPlacementGroupTag] = proximityPlacementGroupName
+	}
+	if len(deleteDataDisks) > 0 {
+		vmResourceTags[jujuDeleteOnTerminationTag] = strings.Join(deleteDataDisks, " ")
+	}
+	if len(dataDisks) > 0 {
+		storageProfile.DataDisks = &dataDisks
+	}
+	vmProperties := compute.VirtualMachineProperties{
+		HardwareProfile: &compute.HardwareProfile{
+			VMSize: compute.VirtualMachineSizeTypes(
+				instanceSpec.InstanceType.Name,
+			),
+		},
+		StorageProfile: storageProfile,
+		OsProfile:      osProfile,
+		NetworkProfile: &compute.NetworkProfile{
+			&nics,
+		},
+		AvailabilitySet: availabilitySetSubResource,
+	}
+	var vmResourceProperties interface{} = &vmProperties
+	if proximityPlacementGroupSubResource != nil {
+		vmResourceProperties = &virtualMachineProperties{
+			VirtualMachineProperties: vmProperties,
+			ProximityPlacementGroup:  proximityPlacementGroupSubResource,
+		}
+	}
 	resources = append(resources, armtemplates.Resource{
-		APIVersion: compute.APIVersion,
+		APIVersion: vmAPIVersion,
 		Type:       "Microsoft.Compute/virtualMachines",
 		Name:       vmName,
 		Location:   env.location,
-		Tags:       vmTags,
-		Properties: &compute.VirtualMachineProperties{
-			HardwareProfile: &compute.HardwareProfile{
-				VMSize: compute.VirtualMachineSizeTypes(
-					instanceSpec.InstanceType.Name,
-				),
-			},
-			StorageProfile: storageProfile,
-			OsProfile:      osProfile,
-			NetworkProfile: &compute.NetworkProfile{
-				&nics,
-			},
-			AvailabilitySet: availabilitySetSubResource,
-		},
-		DependsOn: vmDependsOn,
+		Tags:       vmResourceTags,
+		Properties: vmResourceProperties,
+		DependsOn:  vmDependsOn,
 	})
 
 	// On Windows and CentOS, we must add the CustomScript VM
@@ -662,7 +1027,7 @@ func (env *azureEnviron) createVirtualMachine(
 			)
 		}
 		resources = append(resources, armtemplates.Resource{
-			APIVersion: compute.APIVersion,
+			APIVersion: env.apiProfile.compute,
 			Type:       "Microsoft.Compute/virtualMachines/extensions",
 			Name:       vmName + "/" + extensionName,
 			Location:   env.location,
@@ -672,8 +1037,21 @@ func (env *azureEnviron) createVirtualMachine(
 		})
 	}
 
-	logger.Debugf("- creating virtual machine deployment")
 	template := armtemplates.Template{Resources: resources}
+	if validate {
+		logger.Debugf("- validating virtual machine deployment")
+		if err := validateDeployment(
+			env.callAPI,
+			deploymentsClient,
+			env.resourceGroup,
+			vmName, // deployment name
+			template,
+		); err != nil {
+			return errors.Trace(err)
+		}
+	}
+
+	logger.Debugf("- creating virtual machine deployment")
 	// NOTE(axw) VMs take a long time to go to "Succeeded", so we do not
 	// block waiting for them to be fully provisioned. This means we won't
 	// return an error from StartInstance if the VM fails provisioning;
@@ -693,47 +1071,10 @@ func (env *azureEnviron) createVirtualMachine(
 	return nil
 }
 
-// createAvailabilitySet creates the availability set for a machine to use
-// if it doesn't already exist, and returns the availability set's ID. The
-// algorithm used for choosing the availability set is:
-//  - if the machine is a controller, use the availability set name
-//    "juju-controller";
-//  - if the machine has units assigned, create an availability
-//    name with a name based on the value of the tags.JujuUnitsDeployed tag
-//    in vmTags, if it exists;
-//  - otherwise, do not assign the machine to an availability set
-func availabilitySetName(
-	vmName string,
-	vmTags map[string]string,
-	controller bool,
-) (string, error) {
-	logger.Debugf("selecting availability set for %q", vmName)
-	if controller {
-		return controllerAvailabilitySet, nil
-	}
-
-	// We'll have to create an availability set. Use the name of one of the
-	// services assigned to the machine.
-	var availabilitySetName string
-	if unitNames, ok := vmTags[tags.JujuUnitsDeployed]; ok {
-		for _, unitName := range strings.Fields(unitNames) {
-			if !names.IsValidUnit(unitName) {
-				continue
-			}
-			serviceName, err := names.UnitApplication(unitName)
-			if err != nil {
-				return "", errors.Annotate(err, "getting service name")
-			}
-			availabilitySetName = serviceName
-			break
-		}
-	}
-	return availabilitySetName, nil
-}
-
 // newStorageProfile creates the storage profile for a virtual machine,
 // based on the series and chosen instance spec.
 func newStorageProfile(
+	profile apiProfile,
 	vmName string,
 	storageAccountName string,
 	instanceSpec *instances.InstanceSpec,
@@ -751,7 +1092,7 @@ func newStorageProfile(
 
 	osDisksRoot := fmt.Sprintf(
 		`reference(resourceId('Microsoft.Storage/storageAccounts', '%s'), '%s').primaryEndpoints.blob`,
-		storageAccountName, storage.APIVersion,
+		storageAccountName, profile.storage,
 	)
 	osDiskName := vmName
 	osDiskURI := fmt.Sprintf(
@@ -794,8 +1135,11 @@ func newOSProfile(
 		return nil, os.Unknown, errors.Annotate(err, "composing user data")
 	}
 
+	// The computer name is the machine's unprefixed resource name,
+	// as Windows limits computer names to 15 characters.
+	computerName := resourceName(names.NewMachineTag(instanceConfig.MachineId))
 	osProfile := &compute.OSProfile{
-		ComputerName: to.StringPtr(vmName),
+		ComputerName: to.StringPtr(computerName),
 		CustomData:   to.StringPtr(string(customData)),
 	}
 
@@ -836,10 +1180,46 @@ func newOSProfile(
 
 // StopInstances is specified in the InstanceBroker interface.
 func (env *azureEnviron) StopInstances(ids ...instance.Id) error {
+	return env.stopInstances("stop-instance", true, ids...)
+}
+
+// stopInstances deletes the instances with the given IDs, tracing the
+// requests made for each as the named operation. The data disks that
+// are to be deleted along with each virtual machine are deleted only
+// if deleteDataDisks is true.
+func (env *azureEnviron) stopInstances(operation string, deleteDataDisks bool, ids ...instance.Id) error {
 	if len(ids) == 0 {
 		return nil
 	}
 
+	// The requests made for each instance are traced as part of a
+	// single operation on the instance's machine. Instances are named
+	// for their machines' tags, so we can recover the tag from the ID.
+	modelUUID := env.Config().UUID()
+	env.mu.Lock()
+	resourceNamePrefix := env.config.resourceNamePrefix
+	env.mu.Unlock()
+	instanceClients := make([]machineClients, len(ids))
+	for i, id := range ids {
+		machineTag, err := names.ParseMachineTag(
+			unprefixResourceName(resourceNamePrefix, string(id)),
+		)
+		if err != nil {
+			logger.Debugf("not tracing requests to stop instance %q: %v", id, err)
+			instanceClients[i] = machineClients{
+				compute:   env.compute,
+				network:   env.network,
+				resources: env.resources,
+			}
+			continue
+		}
+		clients, err := env.machineClients(modelUUID, machineTag.String(), operation)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		instanceClients[i] = clients
+	}
+
 	// First up, cancel the deployments. Then we can identify the resources
 	// that need to be deleted without racing with their creation.
 	var wg sync.WaitGroup
@@ -851,7 +1231,7 @@ func (env *azureEnviron) StopInstances(ids ...instance.Id) error {
 		go func(i int, id instance.Id) {
 			defer wg.Done()
 			cancelResults[i] = errors.Annotatef(
-				env.cancelDeployment(string(id)),
+				env.cancelDeployment(instanceClients[i], string(id)),
 				"canceling deployment %q", id,
 			)
 		}(i, id)
@@ -869,6 +1249,26 @@ func (env *azureEnviron) StopInstances(ids ...instance.Id) error {
 		return nil
 	}
 
+	env.mu.Lock()
+	shutdownGracePeriod := env.config.shutdownGracePeriod
+	env.mu.Unlock()
+	if shutdownGracePeriod > 0 {
+		// Give the instances' operating systems, and the agents
+		// running on them, an opportunity to shut down cleanly
+		// before the virtual machines are deleted.
+		for i, id := range ids {
+			if errors.IsNotFound(cancelResults[i]) {
+				continue
+			}
+			wg.Add(1)
+			go func(i int, id instance.Id) {
+				defer wg.Done()
+				env.shutdownVirtualMachine(instanceClients[i], id, shutdownGracePeriod)
+			}(i, id)
+		}
+		wg.Wait()
+	}
+
 	maybeStorageClient, err := env.getStorageClient()
 	if errors.IsNotFound(err) {
 		// It is possible, if unlikely, that the first deployment for a
@@ -908,8 +1308,8 @@ func (env *azureEnviron) StopInstances(ids ...instance.Id) error {
 		go func(i int, id instance.Id) {
 			defer wg.Done()
 			err := env.deleteVirtualMachine(
-				id,
-				maybeStorageClient,
+				instanceClients[i], id,
+				maybeStorageClient, deleteDataDisks,
 				instanceNics[id],
 				instancePips[id],
 			)
@@ -929,8 +1329,8 @@ func (env *azureEnviron) StopInstances(ids ...instance.Id) error {
 }
 
 // cancelDeployment cancels a template deployment.
-func (env *azureEnviron) cancelDeployment(name string) error {
-	deploymentsClient := resources.DeploymentsClient{env.resources}
+func (env *azureEnviron) cancelDeployment(clients machineClients, name string) error {
+	deploymentsClient := resources.DeploymentsClient{clients.resources}
 	logger.Debugf("- canceling deployment %q", name)
 	var cancelResult autorest.Response
 	if err := env.callAPI(func() (autorest.Response, error) {
@@ -956,22 +1356,92 @@ func (env *azureEnviron) cancelDeployment(name string) error {
 	return nil
 }
 
+// shutdownVirtualMachine deallocates a virtual machine, which shuts down
+// its operating system gracefully, waiting at most gracePeriod for the
+// shutdown to complete. Failing to shut down is not fatal, as the virtual
+// machine is about to be deleted regardless.
+func (env *azureEnviron) shutdownVirtualMachine(
+	clients machineClients,
+	instId instance.Id,
+	gracePeriod time.Duration,
+) {
+	vmClient := compute.VirtualMachinesClient{clients.compute}
+	vmName := string(instId)
+	logger.Debugf("- deallocating virtual machine (%s)", vmName)
+
+	cancel := make(chan struct{})
+	done := make(chan error, 1)
+	go func() {
+		var result autorest.Response
+		err := env.callAPI(func() (autorest.Response, error) {
+			var err error
+			result, err = vmClient.Deallocate(env.resourceGroup, vmName, cancel)
+			return result, err
+		})
+		if err != nil && result.Response != nil && result.StatusCode == http.StatusNotFound {
+			err = nil
+		}
+		done <- err
+	}()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			logger.Warningf("failed to shut down virtual machine %q: %v", vmName, err)
+		}
+	case <-env.provider.config.ShutdownClock.After(gracePeriod):
+		close(cancel)
+		logger.Warningf(
+			"virtual machine %q did not shut down within %v, deleting it anyway",
+			vmName, gracePeriod,
+		)
+	}
+}
+
 // deleteVirtualMachine deletes a virtual machine and all of the resources that
-// it owns, and any corresponding network security rules.
+// it owns, and any corresponding network security rules. The data disks that
+// are to be deleted along with the virtual machine are deleted only if
+// deleteDataDisks is true.
 func (env *azureEnviron) deleteVirtualMachine(
+	clients machineClients,
 	instId instance.Id,
 	maybeStorageClient internalazurestorage.Client,
+	deleteDataDisks bool,
 	networkInterfaces []network.Interface,
 	publicIPAddresses []network.PublicIPAddress,
 ) error {
-	vmClient := compute.VirtualMachinesClient{env.compute}
-	nicClient := network.InterfacesClient{env.network}
-	nsgClient := network.SecurityGroupsClient{env.network}
-	securityRuleClient := network.SecurityRulesClient{env.network}
-	pipClient := network.PublicIPAddressesClient{env.network}
-	deploymentsClient := resources.DeploymentsClient{env.resources}
+	vmClient := compute.VirtualMachinesClient{clients.compute}
+	nicClient := network.InterfacesClient{clients.network}
+	nsgClient := network.SecurityGroupsClient{clients.network}
+	securityRuleClient := network.SecurityRulesClient{clients.network}
+	pipClient := network.PublicIPAddressesClient{clients.network}
+	deploymentsClient := resources.DeploymentsClient{clients.resources}
 	vmName := string(instId)
 
+	// The data disks to delete along with the virtual machine, and
+	// the storage account holding its OS disk, are recorded in its
+	// tags, so we must identify them before deleting the virtual
+	// machine.
+	var dataDiskNames []string
+	osDiskStorageAccount := env.storageAccountName
+	if maybeStorageClient != nil {
+		var vm compute.VirtualMachine
+		if err := env.callAPI(func() (autorest.Response, error) {
+			var err error
+			vm, err = vmClient.Get(env.resourceGroup, vmName, "")
+			return vm.Response, err
+		}); err != nil {
+			if vm.Response.Response == nil || vm.StatusCode != http.StatusNotFound {
+				return errors.Annotate(err, "getting virtual machine")
+			}
+		} else {
+			if deleteDataDisks {
+				dataDiskNames = deleteOnTerminationDisks(vm.Tags)
+			}
+			osDiskStorageAccount = virtualMachineStorageAccount(vm, env.storageAccountName)
+		}
+	}
+
 	logger.Debugf("- deleting virtual machine (%s)", vmName)
 	if err := deleteResource(env.callAPI, vmClient, env.resourceGroup, vmName); err != nil {
 		if !errors.IsNotFound(err) {
@@ -981,15 +1451,39 @@ func (env *azureEnviron) deleteVirtualMachine(
 
 	if maybeStorageClient != nil {
 		logger.Debugf("- deleting OS VHD (%s)", vmName)
+		osDiskStorageClient := maybeStorageClient
+		if osDiskStorageAccount != env.storageAccountName {
+			var err error
+			osDiskStorageClient, err = env.getAccountStorageClient(osDiskStorageAccount)
+			if errors.IsNotFound(err) {
+				// The storage account was never created,
+				// so there is no OS VHD to delete.
+				osDiskStorageClient = nil
+			} else if err != nil {
+				return errors.Annotate(err, "getting OS VHD storage client")
+			}
+		}
+		if osDiskStorageClient != nil {
+			blobClient := osDiskStorageClient.GetBlobService()
+			if _, err := blobClient.DeleteBlobIfExists(osDiskVHDContainer, vmName, nil); err != nil {
+				return errors.Annotate(err, "deleting OS VHD")
+			}
+		}
 		blobClient := maybeStorageClient.GetBlobService()
-		if _, err := blobClient.DeleteBlobIfExists(osDiskVHDContainer, vmName, nil); err != nil {
-			return errors.Annotate(err, "deleting OS VHD")
+		for _, dataDiskName := range dataDiskNames {
+			logger.Debugf("- deleting data disk VHD (%s)", dataDiskName)
+			if _, err := blobClient.DeleteBlobIfExists(
+				dataDiskVHDContainer, dataDiskName+vhdExtension, nil,
+			); err != nil {
+				return errors.Annotatef(err, "deleting data disk VHD %q", dataDiskName)
+			}
 		}
 	}
 
 	logger.Debugf("- deleting security rules (%s)", vmName)
+	securityGroup, _ := env.securityGroup()
 	if err := deleteInstanceNetworkSecurityRules(
-		env.resourceGroup, instId, nsgClient,
+		securityGroup, env.Config().UUID(), instId, nsgClient,
 		securityRuleClient, env.callAPI,
 	); err != nil {
 		return errors.Annotate(err, "deleting network security rules")
@@ -1027,6 +1521,116 @@ func (env *azureEnviron) deleteVirtualMachine(
 	return nil
 }
 
+// deleteOrphanedMachineResources deletes the network interfaces and
+// public IP addresses tagged with the given instance ID that are not
+// in use. Such resources are left behind when a machine is removed
+// without its instance being fully stopped.
+func (env *azureEnviron) deleteOrphanedMachineResources(clients machineClients, instId instance.Id) error {
+	nicClient := network.InterfacesClient{clients.network}
+	pipClient := network.PublicIPAddressesClient{clients.network}
+	instanceNics, err := instanceNetworkInterfaces(
+		env.callAPI, env.resourceGroup, nicClient,
+	)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	_, err = env.deleteUnusedNetworkResources(
+		nicClient, pipClient, instanceNics, []instance.Id{instId},
+	)
+	return errors.Trace(err)
+}
+
+// deleteUnusedNetworkResources deletes the network interfaces in
+// instanceNics, and the public IP addresses, that are tagged with
+// any of the given instance IDs and are not in use. The deleted
+// resources are returned.
+func (env *azureEnviron) deleteUnusedNetworkResources(
+	nicClient network.InterfacesClient,
+	pipClient network.PublicIPAddressesClient,
+	instanceNics map[instance.Id][]network.Interface,
+	instIds []instance.Id,
+) ([]environs.Resource, error) {
+	var deleted []environs.Resource
+	for _, instId := range instIds {
+		for _, nic := range instanceNics[instId] {
+			if nic.Properties != nil && nic.Properties.VirtualMachine != nil {
+				// The NIC is still attached to a virtual machine.
+				continue
+			}
+			nicName := to.String(nic.Name)
+			logger.Debugf("deleting orphaned NIC %q", nicName)
+			if err := deleteResource(env.callAPI, nicClient, env.resourceGroup, nicName); err != nil {
+				if !errors.IsNotFound(err) {
+					return nil, errors.Annotatef(err, "deleting NIC %q", nicName)
+				}
+			}
+			deleted = append(deleted, environs.Resource{
+				Type: "Microsoft.Network/networkInterfaces",
+				Id:   to.String(nic.ID),
+				Tags: toTags(nic.Tags),
+			})
+		}
+	}
+
+	// The public IP addresses must be listed after the NICs are
+	// deleted, as a public IP address cannot be deleted while it
+	// is associated with a NIC.
+	instancePips, err := instancePublicIPAddresses(
+		env.callAPI, env.resourceGroup, pipClient,
+	)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	for _, instId := range instIds {
+		for _, pip := range instancePips[instId] {
+			if pip.Properties != nil && pip.Properties.IPConfiguration != nil {
+				// The public IP address is still associated with a NIC.
+				continue
+			}
+			pipName := to.String(pip.Name)
+			logger.Debugf("deleting orphaned public IP %q", pipName)
+			if err := deleteResource(env.callAPI, pipClient, env.resourceGroup, pipName); err != nil {
+				if !errors.IsNotFound(err) {
+					return nil, errors.Annotatef(err, "deleting public IP %q", pipName)
+				}
+			}
+			deleted = append(deleted, environs.Resource{
+				Type: "Microsoft.Network/publicIPAddresses",
+				Id:   to.String(pip.ID),
+				Tags: toTags(pip.Tags),
+			})
+		}
+	}
+	return deleted, nil
+}
+
+var _ environs.InstanceIdentifier = (*azureEnviron)(nil)
+
+// IdentifyInstance is part of the environs.InstanceIdentifier
+// interface. The instance is identified by querying the Azure
+// Instance Metadata Service, which requires no credentials. Virtual
+// machine names are only unique within a resource group, so the
+// instance is identified only if it is in the model's subscription
+// and resource group.
+func (env *azureEnviron) IdentifyInstance() (environs.InstanceIdentity, error) {
+	client := imds.Client{Sender: env.provider.config.InstanceMetadataSender}
+	compute, err := client.Compute()
+	if err != nil {
+		return environs.InstanceIdentity{}, errors.Trace(err)
+	}
+	if compute.SubscriptionID != env.subscriptionId ||
+		!strings.EqualFold(compute.ResourceGroupName, env.resourceGroup) {
+		return environs.InstanceIdentity{}, errors.NotFoundf(
+			"instance %q in subscription %q, resource group %q",
+			compute.Name, env.subscriptionId, env.resourceGroup,
+		)
+	}
+	return environs.InstanceIdentity{
+		InstanceId: instance.Id(compute.Name),
+		Region:     canonicalLocation(compute.Location),
+	}, nil
+}
+
 // Instances is specified in the Environ interface.
 func (env *azureEnviron) Instances(ids []instance.Id) ([]instance.Instance, error) {
 	return env.instances(env.resourceGroup, ids, true /* refresh addresses */)
@@ -1040,6 +1644,18 @@ func (env *azureEnviron) instances(
 	if len(ids) == 0 {
 		return nil, nil
 	}
+	if len(ids) == 1 {
+		// Single instances are queried frequently, e.g. by the
+		// machiner, so avoid listing all of the instances in
+		// the resource group.
+		inst, err := env.instance(resourceGroup, ids[0], refreshAddresses)
+		if errors.IsNotFound(err) {
+			return nil, environs.ErrNoInstances
+		} else if err != nil {
+			return nil, errors.Trace(err)
+		}
+		return []instance.Instance{inst}, nil
+	}
 	all, err := env.allInstances(resourceGroup, refreshAddresses, false)
 	if err != nil {
 		return nil, errors.Trace(err)
@@ -1066,6 +1682,51 @@ func (env *azureEnviron) instances(
 	return matching, nil
 }
 
+// instance returns the instance with the given ID in the given resource
+// group, and optionally ensures that the instance's addresses are
+// up-to-date. Only the instance's own deployment and network resources
+// are fetched. If the instance does not exist, an error satisfying
+// errors.IsNotFound is returned.
+func (env *azureEnviron) instance(
+	resourceGroup string,
+	id instance.Id,
+	refreshAddresses bool,
+) (*azureInstance, error) {
+	deploymentsClient := resources.DeploymentsClient{env.resources}
+	var deployment resources.DeploymentExtended
+	if err := env.callAPI(func() (autorest.Response, error) {
+		var err error
+		deployment, err = deploymentsClient.Get(resourceGroup, string(id))
+		return deployment.Response, err
+	}); err != nil {
+		if deployment.Response.Response != nil && deployment.StatusCode == http.StatusNotFound {
+			return nil, errors.NotFoundf("instance %q", id)
+		}
+		return nil, errors.Annotatef(err, "getting deployment %q", id)
+	}
+	if deployment.Properties == nil || deployment.Properties.Dependencies == nil {
+		// As in allInstances, deployments without dependencies
+		// are not considered to be instances.
+		return nil, errors.NotFoundf("instance %q", id)
+	}
+
+	provisioningState := to.String(deployment.Properties.ProvisioningState)
+	inst := &azureInstance{string(id), provisioningState, env, nil, nil}
+	if refreshAddresses {
+		if err := setInstanceAddresses(
+			env.callAPI,
+			resourceGroup,
+			resources.GroupsClient{env.resources},
+			network.InterfacesClient{env.network},
+			network.PublicIPAddressesClient{env.network},
+			[]*azureInstance{inst},
+		); err != nil {
+			return nil, errors.Trace(err)
+		}
+	}
+	return inst, nil
+}
+
 // AllInstances is specified in the InstanceBroker interface.
 func (env *azureEnviron) AllInstances() ([]instance.Instance, error) {
 	return env.allInstances(env.resourceGroup, true /* refresh addresses */, false /* all instances */)
@@ -1114,6 +1775,7 @@ func (env *azureEnviron) allInstances(
 		if err := setInstanceAddresses(
 			env.callAPI,
 			resourceGroup,
+			resources.GroupsClient{env.resources},
 			network.InterfacesClient{env.network},
 			network.PublicIPAddressesClient{env.network},
 			azureInstances,
@@ -1152,6 +1814,15 @@ func isControllerDeployment(deployment resources.DeploymentExtended) bool {
 // Destroy is specified in the Environ interface.
 func (env *azureEnviron) Destroy() error {
 	logger.Debugf("destroying model %q", env.envName)
+	if securityGroup, _ := env.securityGroup(); securityGroup.id != "" {
+		// The security rules for the model's machines in an existing
+		// network security group are outside the resource group, so
+		// they must be deleted explicitly.
+		logger.Debugf("- deleting security rules from %q", securityGroup.id)
+		if err := env.deleteInstancesNetworkSecurityRules(securityGroup); err != nil {
+			return errors.Trace(err)
+		}
+	}
 	logger.Debugf("- deleting resource group %q", env.resourceGroup)
 	if err := env.deleteResourceGroup(env.resourceGroup); err != nil {
 		return errors.Trace(err)
@@ -1162,6 +1833,26 @@ func (env *azureEnviron) Destroy() error {
 	return nil
 }
 
+// deleteInstancesNetworkSecurityRules deletes the network security rules
+// for all of the model's instances from the given network security group.
+// Only the rules named for this model are deleted, as the group may be
+// shared with other models; see modelNetworkSecurityRulePrefix.
+func (env *azureEnviron) deleteInstancesNetworkSecurityRules(securityGroup securityGroupRef) error {
+	prefix := modelNetworkSecurityRulePrefix(securityGroup, env.Config().UUID())
+	if prefix == "" {
+		return errors.Errorf("cannot identify the model's rules in %q", securityGroup.name)
+	}
+	nsgClient := network.SecurityGroupsClient{env.network}
+	securityRuleClient := network.SecurityRulesClient{env.network}
+	if err := deleteNetworkSecurityRules(
+		securityGroup, prefix, nsgClient,
+		securityRuleClient, env.callAPI,
+	); err != nil {
+		return errors.Annotate(err, "deleting network security rules")
+	}
+	return nil
+}
+
 // DestroyController is specified in the Environ interface.
 func (env *azureEnviron) DestroyController(controllerUUID string) error {
 	logger.Debugf("destroying model %q", env.envName)
@@ -1317,10 +2008,12 @@ func (env *azureEnviron) getInstanceTypesLocked() (map[string]instances.Instance
 		return nil, errors.Annotate(err, "listing VM sizes")
 	}
 	instanceTypes := make(map[string]instances.InstanceType)
+	maxDataDisks := make(map[string]int)
 	if result.Value != nil {
 		for _, size := range *result.Value {
 			instanceType := newInstanceType(size)
 			instanceTypes[instanceType.Name] = instanceType
+			maxDataDisks[instanceType.Name] = int(to.Int32(size.MaxDataDiskCount))
 			// Create aliases for standard role sizes.
 			if strings.HasPrefix(instanceType.Name, "Standard_") {
 				instanceTypes[instanceType.Name[len("Standard_"):]] = instanceType
@@ -1328,12 +2021,39 @@ func (env *azureEnviron) getInstanceTypesLocked() (map[string]instances.Instance
 		}
 	}
 	env.instanceTypes = instanceTypes
+	env.maxDataDisks = maxDataDisks
 	return instanceTypes, nil
 }
 
+// getMaxDataDisks returns the maximum number of data disks that may be
+// attached to a virtual machine of the given size.
+func (env *azureEnviron) getMaxDataDisks(vmSize compute.VirtualMachineSizeTypes) (int, error) {
+	env.mu.Lock()
+	defer env.mu.Unlock()
+	if _, err := env.getInstanceTypesLocked(); err != nil {
+		return 0, errors.Annotate(err, "getting instance types")
+	}
+	maxDataDisks, ok := env.maxDataDisks[string(vmSize)]
+	if !ok {
+		return 0, errors.NotFoundf("VM size %q", vmSize)
+	}
+	return maxDataDisks, nil
+}
+
 // getStorageClient queries the storage account key, and uses it to construct
-// a new storage client.
+// a new storage client. If the key is rejected, for example because another
+// environ has rotated it, the client fetches the key afresh and retries.
 func (env *azureEnviron) getStorageClient() (internalazurestorage.Client, error) {
+	client, err := env.newStorageClient(false)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	return &keyRefreshingClient{env: env, client: client}, nil
+}
+
+// newStorageClient constructs a new storage client, using the storage
+// account key. If refreshKey is true, the key will be fetched afresh.
+func (env *azureEnviron) newStorageClient(refreshKey bool) (internalazurestorage.Client, error) {
 	env.mu.Lock()
 	defer env.mu.Unlock()
 	storageAccount, err := env.getStorageAccountLocked(false)
@@ -1341,7 +2061,7 @@ func (env *azureEnviron) getStorageClient() (internalazurestorage.Client, error)
 		return nil, errors.Annotate(err, "getting storage account")
 	}
 	storageAccountKey, err := env.getStorageAccountKeyLocked(
-		to.String(storageAccount.Name), false,
+		to.String(storageAccount.Name), refreshKey,
 	)
 	if err != nil {
 		return nil, errors.Annotate(err, "getting storage account key")
@@ -1351,6 +2071,7 @@ func (env *azureEnviron) getStorageClient() (internalazurestorage.Client, error)
 		env.storageEndpoint,
 		storageAccount,
 		storageAccountKey,
+		env.sender.httpClient(),
 	)
 	if err != nil {
 		return nil, errors.Annotate(err, "getting storage client")
@@ -1399,6 +2120,7 @@ func (env *azureEnviron) getStorageAccountKeyLocked(accountName string, refresh
 		client,
 		env.resourceGroup,
 		accountName,
+		env.config.storageAccountKeyName,
 	)
 	if err != nil {
 		return nil, errors.Trace(err)
@@ -1409,10 +2131,24 @@ func (env *azureEnviron) getStorageAccountKeyLocked(accountName string, refresh
 
 // AgentMirror is specified in the tools.HasAgentMirror interface.
 //
+// In private-cloud mode, the model's machines cannot reach the
+// storage endpoints, so the controller's tools mirror is reported
+// instead; the controller then looks up the model's agent binaries
+// in its own mirror, before any other metadata source.
+//
 // TODO(axw) 2016-04-11 #1568715
 // When we have image simplestreams, we should rename this to "Region",
 // to implement simplestreams.HasRegion.
 func (env *azureEnviron) AgentMirror() (simplestreams.CloudSpec, error) {
+	env.mu.Lock()
+	privateCloud := env.config.privateCloud
+	env.mu.Unlock()
+	if privateCloud {
+		return simplestreams.CloudSpec{
+			Region:   env.location,
+			Endpoint: envtools.ControllerMirrorEndpoint,
+		}, nil
+	}
 	return simplestreams.CloudSpec{
 		Region: env.location,
 		// The endpoints published in simplestreams
@@ -4,8 +4,8 @@
 package azure_test
 
 import (
+	"bytes"
 	"encoding/json"
-	"errors"
 	"fmt"
 	"io/ioutil"
 	"net/http"
@@ -17,9 +17,11 @@ import (
 	"github.com/Azure/azure-sdk-for-go/arm/network"
 	"github.com/Azure/azure-sdk-for-go/arm/resources/resources"
 	"github.com/Azure/azure-sdk-for-go/arm/storage"
+	azurestorage "github.com/Azure/azure-sdk-for-go/storage"
 	autorestazure "github.com/Azure/go-autorest/autorest/azure"
 	"github.com/Azure/go-autorest/autorest/mocks"
 	"github.com/Azure/go-autorest/autorest/to"
+	"github.com/juju/errors"
 	gitjujutesting "github.com/juju/testing"
 	jc "github.com/juju/testing/checkers"
 	"github.com/juju/utils"
@@ -28,6 +30,7 @@ import (
 	"gopkg.in/juju/names.v2"
 
 	"github.com/juju/juju/api"
+	"github.com/juju/juju/cloud"
 	"github.com/juju/juju/cloudconfig/instancecfg"
 	"github.com/juju/juju/constraints"
 	"github.com/juju/juju/environs"
@@ -41,6 +44,7 @@ import (
 	"github.com/juju/juju/provider/azure/internal/armtemplates"
 	"github.com/juju/juju/provider/azure/internal/azureauth"
 	"github.com/juju/juju/provider/azure/internal/azuretesting"
+	jujustorage "github.com/juju/juju/storage"
 	"github.com/juju/juju/testing"
 	"github.com/juju/juju/tools"
 	"github.com/juju/version"
@@ -134,6 +138,9 @@ func (s *environSuite) SetUpTest(c *gc.C) {
 		},
 		RandomWindowsAdminPassword:        func() string { return "sorandom" },
 		InteractiveCreateServicePrincipal: azureauth.InteractiveCreateServicePrincipal,
+		NewUUID: func() (utils.UUID, error) {
+			return utils.UUIDFromString("c0ffee00-0bad-4000-8000-000000000000")
+		},
 	})
 
 	s.controllerUUID = testing.ControllerTag.Id()
@@ -253,11 +260,36 @@ func prepareForBootstrap(
 		discoverAuthSender(),
 		tokenRefreshSender(),
 	}
+	*sender = append(*sender, resourceProvidersSenders(
+		compute.APIVersion, network.APIVersion, storage.APIVersion,
+	)...)
 	err = env.PrepareForBootstrap(ctx)
 	c.Assert(err, jc.ErrorIsNil)
 	return env
 }
 
+// resourceProvidersSenders returns senders for the requests made to
+// verify a model's API profile, reporting that the cloud supports the
+// given compute, network and storage API versions.
+func resourceProvidersSenders(computeVersion, networkVersion, storageVersion string) azuretesting.Senders {
+	providerSender := func(namespace, resourceType, apiVersion string) *azuretesting.MockSender {
+		sender := azuretesting.NewSenderWithValue(resources.Provider{
+			Namespace: to.StringPtr(namespace),
+			ResourceTypes: &[]resources.ProviderResourceType{{
+				ResourceType: to.StringPtr(resourceType),
+				APIVersions:  &[]string{apiVersion},
+			}},
+		})
+		sender.PathPattern = ".*/providers/" + namespace
+		return sender
+	}
+	return azuretesting.Senders{
+		providerSender("Microsoft.Compute", "virtualMachines", computeVersion),
+		providerSender("Microsoft.Network", "virtualNetworks", networkVersion),
+		providerSender("Microsoft.Storage", "storageAccounts", storageVersion),
+	}
+}
+
 func fakeCloudSpec() environs.CloudSpec {
 	return environs.CloudSpec{
 		Type:             "azure",
@@ -308,6 +340,9 @@ func (s *environSuite) startInstanceSenders(controller bool) azuretesting.Sender
 	if s.ubuntuServerSKUs != nil {
 		senders = append(senders, s.makeSender(".*/Canonical/.*/UbuntuServer/skus", s.ubuntuServerSKUs))
 	}
+	senders = append(senders, s.networkInterfacesSender())
+	senders = append(senders, s.publicIPAddressesSender())
+	senders = append(senders, s.virtualMachinesSender())
 	senders = append(senders, s.makeSender("/deployments/machine-0", s.deployment))
 	return senders
 }
@@ -425,6 +460,103 @@ func (s *environSuite) TestCloudEndpointManagementURI(c *gc.C) {
 	c.Assert(s.requests[0].URL.Host, gc.Equals, "api.azurestack.local")
 }
 
+func (s *environSuite) TestAPIProfile(c *gc.C) {
+	env := s.openEnviron(c, testing.Attrs{"api-profile": "2016-06-01"})
+
+	sender := mocks.NewSender()
+	sender.AppendResponse(mocks.NewResponseWithContent("{}"))
+	s.sender = azuretesting.Senders{sender}
+	s.requests = nil
+	env.AllInstances() // trigger a query
+
+	c.Assert(s.requests, gc.HasLen, 1)
+	c.Assert(s.requests[0].URL.Query().Get("api-version"), gc.Equals, "2016-02-01")
+}
+
+func (s *environSuite) TestPrepareForBootstrapAPIProfileSupported(c *gc.C) {
+	ctx := envtesting.BootstrapContext(c)
+	prepareForBootstrap(c, ctx, s.provider, &s.sender)
+
+	var namespaces []string
+	for _, req := range s.requests {
+		namespace := path.Base(req.URL.Path)
+		if path.Base(path.Dir(req.URL.Path)) != "providers" {
+			continue
+		}
+		c.Check(req.Method, gc.Equals, "GET")
+		c.Check(req.URL.Query().Get("api-version"), gc.Equals, resources.APIVersion)
+		namespaces = append(namespaces, namespace)
+	}
+	c.Assert(namespaces, jc.DeepEquals, []string{
+		"Microsoft.Compute", "Microsoft.Network", "Microsoft.Storage",
+	})
+}
+
+func (s *environSuite) TestPrepareForBootstrapAPIProfileUnsupported(c *gc.C) {
+	cfg, err := s.provider.PrepareConfig(environs.PrepareConfigParams{
+		Config: makeTestModelConfig(c, testing.Attrs{"api-profile": "2016-06-01"}),
+		Cloud:  fakeCloudSpec(),
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	env, err := s.provider.Open(environs.OpenParams{
+		Cloud:  fakeCloudSpec(),
+		Config: cfg,
+	})
+	c.Assert(err, jc.ErrorIsNil)
+
+	s.sender = azuretesting.Senders{
+		discoverAuthSender(),
+		tokenRefreshSender(),
+	}
+	s.sender = append(s.sender, resourceProvidersSenders(
+		compute.APIVersion, network.APIVersion, storage.APIVersion,
+	)...)
+	err = env.PrepareForBootstrap(envtesting.BootstrapContext(c))
+	c.Assert(err, jc.Satisfies, errors.IsNotSupported)
+	c.Assert(err, gc.ErrorMatches, fmt.Sprintf(
+		`"2016-06-01" API profile not supported by the cloud: `+
+			`Microsoft.Compute/virtualMachines API version 2016-03-30 is not one of \["%s"\]`,
+		compute.APIVersion,
+	))
+}
+
+func (s *environSuite) TestPreviewInstanceSpec(c *gc.C) {
+	env := s.openEnviron(c)
+	s.sender = azuretesting.Senders{
+		s.vmSizesSender(),
+		s.makeSender(".*/Canonical/.*/UbuntuServer/skus", s.ubuntuServerSKUs),
+	}
+	s.requests = nil
+	preview, err := env.(environs.InstanceSpecPreviewer).PreviewInstanceSpec(
+		environs.PreviewInstanceSpecParams{
+			Series:      "quantal",
+			Constraints: constraints.MustParse("mem=1G"),
+		},
+	)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(preview.InstanceType, gc.Equals, "Standard_D1")
+	c.Check(preview.ImageId, gc.Equals, "Canonical:UbuntuServer:12.10:latest")
+	c.Check(preview.Arch, gc.Equals, arch.AMD64)
+	c.Check(preview.AvailabilityZone, gc.Equals, "")
+
+	// Nothing is created.
+	for _, req := range s.requests {
+		c.Check(req.Method, gc.Equals, "GET")
+	}
+}
+
+func (s *environSuite) TestPreviewInstanceSpecNoMatch(c *gc.C) {
+	env := s.openEnviron(c)
+	s.sender = azuretesting.Senders{s.vmSizesSender()}
+	_, err := env.(environs.InstanceSpecPreviewer).PreviewInstanceSpec(
+		environs.PreviewInstanceSpecParams{
+			Series:      "quantal",
+			Constraints: constraints.MustParse("mem=1T"),
+		},
+	)
+	c.Assert(err, gc.ErrorMatches, "no instance types in westus matching constraints .*")
+}
+
 func (s *environSuite) TestStartInstance(c *gc.C) {
 	env := s.openEnviron(c)
 	s.sender = s.startInstanceSenders(false)
@@ -454,245 +586,1367 @@ func (s *environSuite) TestStartInstance(c *gc.C) {
 	})
 }
 
-func (s *environSuite) TestStartInstanceWindowsMinRootDisk(c *gc.C) {
-	// The minimum OS disk size for Windows machines is 127GiB.
-	cons := constraints.MustParse("root-disk=44G")
-	s.testStartInstanceWindows(c, cons, 127*1024, 136)
-}
-
-func (s *environSuite) TestStartInstanceWindowsGrowableRootDisk(c *gc.C) {
-	// The OS disk size may be grown larger than 127GiB.
-	cons := constraints.MustParse("root-disk=200G")
-	s.testStartInstanceWindows(c, cons, 200*1024, 214)
-}
-
-func (s *environSuite) testStartInstanceWindows(
-	c *gc.C, cons constraints.Value,
-	expect uint64, requestValue int,
-) {
-	// Starting a Windows VM, we should not expect an image query.
-	s.PatchValue(&s.ubuntuServerSKUs, nil)
-
+func (s *environSuite) TestReplaceInstance(c *gc.C) {
 	env := s.openEnviron(c)
-	s.sender = s.startInstanceSenders(false)
+	vmSender := mocks.NewSender()
+	vmSender.AppendResponse(mocks.NewResponseWithStatus(
+		"vm not found", http.StatusNotFound,
+	))
+	startInstanceSenders := s.startInstanceSenders(false)
+	n := len(startInstanceSenders)
+	s.sender = append(azuretesting.Senders{vmSender}, startInstanceSenders[:n-1]...)
+	s.sender = append(s.sender,
+		s.storageAccountSender(),
+		s.storageAccountKeysSender(),
+		startInstanceSenders[n-1], // deployment
+	)
 	s.requests = nil
-	args := makeStartInstanceParams(c, s.controllerUUID, "win2012")
-	args.Constraints = cons
-	result, err := env.StartInstance(args)
+
+	machineTag := names.NewMachineTag("0")
+	volumeTag := names.NewVolumeTag("0")
+	result, err := env.(environs.InstanceReplacer).ReplaceInstance(environs.ReplaceInstanceParams{
+		StartInstanceParams: makeStartInstanceParams(c, s.controllerUUID, "quantal"),
+		InstanceId:          "machine-0",
+		VolumeAttachments: []jujustorage.VolumeAttachmentParams{{
+			AttachmentParams: jujustorage.AttachmentParams{
+				Provider: "azure",
+				Machine:  machineTag,
+			},
+			Volume:              volumeTag,
+			VolumeId:            "volume-0",
+			DeleteOnTermination: true,
+		}},
+	})
 	c.Assert(err, jc.ErrorIsNil)
-	c.Assert(result, gc.NotNil)
-	c.Assert(result.Hardware.RootDisk, jc.DeepEquals, &expect)
+	c.Assert(result.Instance.Id(), gc.Equals, instance.Id("machine-0"))
+	c.Assert(result.VolumeAttachments, jc.DeepEquals, []jujustorage.VolumeAttachment{{
+		volumeTag, machineTag,
+		jujustorage.VolumeAttachmentInfo{BusAddress: "scsi@5:0.0.0"},
+	}})
+
+	// The replaced virtual machine's OS disk is deleted, so
+	// that the replacement's can be created in its place.
+	s.storageClient.CheckCallNames(c, "NewClient", "DeleteBlobIfExists")
+	s.storageClient.CheckCall(c, 1, "DeleteBlobIfExists", "osvhds", "machine-0")
 
-	vmExtensionSettings := map[string]interface{}{
-		"commandToExecute": `` +
-			`move C:\AzureData\CustomData.bin C:\AzureData\CustomData.ps1 && ` +
-			`powershell.exe -ExecutionPolicy Unrestricted -File C:\AzureData\CustomData.ps1 && ` +
-			`del /q C:\AzureData\CustomData.ps1`,
+	// The virtual machine is created with the existing data
+	// disk attached, and will delete it on termination.
+	c.Assert(s.requests, gc.HasLen, numExpectedStartInstanceRequests+3)
+	deploymentRequest := s.requests[len(s.requests)-1]
+	c.Assert(deploymentRequest.Method, gc.Equals, "PUT")
+	var deployment resources.Deployment
+	unmarshalRequestBody(c, deploymentRequest, &deployment)
+	templateResources := (*deployment.Properties.Template)["resources"].([]interface{})
+	vmResource := templateResources[len(templateResources)-1].(map[string]interface{})
+	c.Assert(vmResource["name"], gc.Equals, "machine-0")
+	vmTags := vmResource["tags"].(map[string]interface{})
+	c.Assert(vmTags["juju-delete-on-termination"], gc.Equals, "volume-0")
+
+	expectedDataDisks := []compute.DataDisk{{
+		Lun:  to.Int32Ptr(0),
+		Name: to.StringPtr("volume-0"),
+		Vhd: &compute.VirtualHardDisk{to.StringPtr(fmt.Sprintf(
+			"https://%s.blob.storage.azurestack.local/datavhds/volume-0.vhd",
+			storageAccountName,
+		))},
+		Caching:      compute.ReadWrite,
+		CreateOption: compute.Attach,
+	}}
+	var expected interface{}
+	data, err := json.Marshal(expectedDataDisks)
+	c.Assert(err, jc.ErrorIsNil)
+	err = json.Unmarshal(data, &expected)
+	c.Assert(err, jc.ErrorIsNil)
+	vmProperties := vmResource["properties"].(map[string]interface{})
+	storageProfile := vmProperties["storageProfile"].(map[string]interface{})
+	c.Assert(storageProfile["dataDisks"], jc.DeepEquals, expected)
+}
+
+func (s *environSuite) TestReplaceInstanceExists(c *gc.C) {
+	env := s.openEnviron(c)
+	s.sender = azuretesting.Senders{
+		s.makeSender(".*/virtualMachines/machine-0", &compute.VirtualMachine{
+			Name: to.StringPtr("machine-0"),
+		}),
 	}
-	s.assertStartInstanceRequests(c, s.requests, assertStartInstanceRequestsParams{
-		imageReference: &win2012ImageReference,
-		diskSizeGB:     requestValue,
-		vmExtension: &compute.VirtualMachineExtensionProperties{
-			Publisher:               to.StringPtr("Microsoft.Compute"),
-			Type:                    to.StringPtr("CustomScriptExtension"),
-			TypeHandlerVersion:      to.StringPtr("1.4"),
-			AutoUpgradeMinorVersion: to.BoolPtr(true),
-			Settings:                &vmExtensionSettings,
-		},
-		osProfile: &windowsOsProfile,
+	_, err := env.(environs.InstanceReplacer).ReplaceInstance(environs.ReplaceInstanceParams{
+		StartInstanceParams: makeStartInstanceParams(c, s.controllerUUID, "quantal"),
+		InstanceId:          "machine-0",
 	})
+	c.Assert(err, gc.ErrorMatches, `instance "machine-0" already exists`)
+	c.Assert(err, jc.Satisfies, errors.IsAlreadyExists)
 }
 
-func (s *environSuite) TestStartInstanceCentOS(c *gc.C) {
-	// Starting a CentOS VM, we should not expect an image query.
-	s.PatchValue(&s.ubuntuServerSKUs, nil)
-
-	env := s.openEnviron(c)
+func (s *environSuite) TestStartInstancePolicySecurityRules(c *gc.C) {
+	env := s.openEnviron(c, testing.Attrs{
+		"egress-allow-list":    "10.0.0.0/8,203.0.113.7/32",
+		"ingress-default-deny": true,
+	})
 	s.sender = s.startInstanceSenders(false)
 	s.requests = nil
-	args := makeStartInstanceParams(c, s.controllerUUID, "centos7")
-	_, err := env.StartInstance(args)
+	_, err := env.StartInstance(makeStartInstanceParams(c, s.controllerUUID, "quantal"))
 	c.Assert(err, jc.ErrorIsNil)
-
-	vmExtensionSettings := map[string]interface{}{
-		"commandToExecute": `bash -c 'base64 -d /var/lib/waagent/CustomData | bash'`,
-	}
 	s.assertStartInstanceRequests(c, s.requests, assertStartInstanceRequestsParams{
-		imageReference: &centos7ImageReference,
+		imageReference: &quantalImageReference,
 		diskSizeGB:     32,
-		vmExtension: &compute.VirtualMachineExtensionProperties{
-			Publisher:               to.StringPtr("Microsoft.OSTCExtensions"),
-			Type:                    to.StringPtr("CustomScriptForLinux"),
-			TypeHandlerVersion:      to.StringPtr("1.4"),
-			AutoUpgradeMinorVersion: to.BoolPtr(true),
-			Settings:                &vmExtensionSettings,
+		osProfile:      &linuxOsProfile,
+		policyRules: []network.SecurityRule{
+			egressAllowSecurityRule(0, "10.0.0.0/8"),
+			egressAllowSecurityRule(1, "203.0.113.7/32"),
+			egressAllowAPISecurityRule(17777),
+			egressDenySecurityRule,
+			ingressDenySecurityRule,
 		},
-		osProfile: &linuxOsProfile,
 	})
 }
 
-func (s *environSuite) TestStartInstanceTooManyRequests(c *gc.C) {
-	env := s.openEnviron(c)
+func (s *environSuite) TestStartInstanceValidateDeployment(c *gc.C) {
+	env := s.openEnviron(c, testing.Attrs{"validate-deployments": true})
 	senders := s.startInstanceSenders(false)
+	n := len(senders)
+	s.sender = append(senders[:n-1:n-1],
+		s.makeSender("/deployments/machine-0/validate", resources.DeploymentValidateResult{}),
+		senders[n-1], // deployment
+	)
 	s.requests = nil
-
-	// 6 failures to get to 1 minute, and show that we cap it there.
-	const failures = 6
-
-	// Make the VirtualMachines.CreateOrUpdate call respond with
-	// 429 (StatusTooManyRequests) failures, and then with success.
-	rateLimitedSender := mocks.NewSender()
-	rateLimitedSender.AppendAndRepeatResponse(mocks.NewResponseWithBodyAndStatus(
-		mocks.NewBody("{}"), // empty JSON response to appease go-autorest
-		http.StatusTooManyRequests,
-		"(」゜ロ゜)」",
-	), failures)
-	successSender := senders[len(senders)-1]
-	senders = senders[:len(senders)-1]
-	for i := 0; i < failures; i++ {
-		senders = append(senders, rateLimitedSender)
-	}
-	senders = append(senders, successSender)
-	s.sender = senders
-
 	_, err := env.StartInstance(makeStartInstanceParams(c, s.controllerUUID, "quantal"))
 	c.Assert(err, jc.ErrorIsNil)
 
-	c.Assert(s.requests, gc.HasLen, numExpectedStartInstanceRequests+failures)
-	s.assertStartInstanceRequests(c, s.requests[:numExpectedStartInstanceRequests], assertStartInstanceRequestsParams{
+	// The deployment is validated before it is created.
+	c.Assert(s.requests, gc.HasLen, numExpectedStartInstanceRequests+1)
+	validateRequest := s.requests[numExpectedStartInstanceRequests-1]
+	c.Assert(validateRequest.Method, gc.Equals, "POST")
+	deploymentRequest := s.requests[numExpectedStartInstanceRequests]
+	var validated, created resources.Deployment
+	unmarshalRequestBody(c, validateRequest, &validated)
+	unmarshalRequestBody(c, deploymentRequest, &created)
+	c.Assert(validated, jc.DeepEquals, created)
+
+	requests := append(s.requests[:numExpectedStartInstanceRequests-1:numExpectedStartInstanceRequests-1], deploymentRequest)
+	s.assertStartInstanceRequests(c, requests, assertStartInstanceRequestsParams{
 		imageReference: &quantalImageReference,
 		diskSizeGB:     32,
 		osProfile:      &linuxOsProfile,
 	})
-
-	// The final requests should all be identical.
-	for i := numExpectedStartInstanceRequests; i < numExpectedStartInstanceRequests+failures; i++ {
-		c.Assert(s.requests[i].Method, gc.Equals, "PUT")
-		c.Assert(s.requests[i].URL.Path, gc.Equals, s.requests[numExpectedStartInstanceRequests-1].URL.Path)
-	}
-
-	s.retryClock.CheckCalls(c, []gitjujutesting.StubCall{
-		{"After", []interface{}{5 * time.Second}},
-		{"After", []interface{}{10 * time.Second}},
-		{"After", []interface{}{20 * time.Second}},
-		{"After", []interface{}{40 * time.Second}},
-		{"After", []interface{}{1 * time.Minute}},
-		{"After", []interface{}{1 * time.Minute}},
-	})
 }
 
-func (s *environSuite) TestStartInstanceTooManyRequestsTimeout(c *gc.C) {
-	env := s.openEnviron(c)
+func (s *environSuite) TestStartInstanceValidateDeploymentFails(c *gc.C) {
+	env := s.openEnviron(c, testing.Attrs{"validate-deployments": true})
 	senders := s.startInstanceSenders(false)
-	s.requests = nil
-
-	// 8 failures to get to 5 minutes, which is as long as we'll keep
-	// retrying before giving up.
-	const failures = 8
-
-	// Make the VirtualMachines.Get call respond with enough 429
-	// (StatusTooManyRequests) failures to cause the method to give
-	// up retrying.
-	rateLimitedSender := mocks.NewSender()
-	rateLimitedSender.AppendAndRepeatResponse(mocks.NewResponseWithBodyAndStatus(
-		mocks.NewBody("{}"), // empty JSON response to appease go-autorest
-		http.StatusTooManyRequests,
-		"(」゜ロ゜)」",
-	), failures)
-	senders = senders[:len(senders)-1]
-	for i := 0; i < failures; i++ {
-		senders = append(senders, rateLimitedSender)
+	validateSender := &azuretesting.MockSender{
+		Sender:      mocks.NewSender(),
+		PathPattern: "/deployments/machine-0/validate",
 	}
-	s.sender = senders
-
+	validateSender.AppendResponse(mocks.NewResponseWithBodyAndStatus(
+		mocks.NewBody(`{"error": {
+			"code": "InvalidTemplateDeployment",
+			"message": "The template deployment 'machine-0' is not valid according to the validation procedure.",
+			"details": [{
+				"code": "SkuNotAvailable",
+				"message": "The requested size for resource 'machine-0' is currently not available."
+			}]
+		}}`),
+		http.StatusBadRequest,
+		"400 Bad Request",
+	))
+	s.sender = append(senders[:len(senders)-1], validateSender)
+	s.requests = nil
 	_, err := env.StartInstance(makeStartInstanceParams(c, s.controllerUUID, "quantal"))
-	c.Assert(err, gc.ErrorMatches, `creating virtual machine "machine-0": creating deployment "machine-0": max duration exceeded: .*`)
-
-	s.retryClock.CheckCalls(c, []gitjujutesting.StubCall{
-		{"After", []interface{}{5 * time.Second}},  // t0 + 5s
-		{"After", []interface{}{10 * time.Second}}, // t0 + 15s
-		{"After", []interface{}{20 * time.Second}}, // t0 + 35s
-		{"After", []interface{}{40 * time.Second}}, // t0 + 1m15s
-		{"After", []interface{}{1 * time.Minute}},  // t0 + 2m15s
-		{"After", []interface{}{1 * time.Minute}},  // t0 + 3m15s
-		{"After", []interface{}{1 * time.Minute}},  // t0 + 4m15s
-		// There would be another call here, but since the time
-		// exceeds the give minute limit, retrying is aborted.
-	})
-}
+	c.Assert(err, gc.ErrorMatches, `creating virtual machine "machine-0": validating deployment "machine-0": .*`)
+	c.Assert(environs.ProvisioningErrorKindOf(err), gc.Equals, environs.ProvisioningErrorCapacity)
 
-func (s *environSuite) TestStartInstanceDistributionGroup(c *gc.C) {
-	c.Skip("TODO: test StartInstance's DistributionGroup behaviour")
+	// Nothing was created, so nothing is destroyed.
+	c.Assert(s.requests, gc.HasLen, numExpectedStartInstanceRequests)
+	c.Assert(s.requests[numExpectedStartInstanceRequests-1].Method, gc.Equals, "POST")
 }
 
-func (s *environSuite) TestStartInstanceServiceAvailabilitySet(c *gc.C) {
-	env := s.openEnviron(c)
-	unitsDeployed := "mysql/0 wordpress/0"
-	s.vmTags[tags.JujuUnitsDeployed] = &unitsDeployed
+func (s *environSuite) TestStartInstanceExistingSecurityGroup(c *gc.C) {
+	env := s.openEnviron(c, testing.Attrs{"network-security-group": testSecurityGroupID})
 	s.sender = s.startInstanceSenders(false)
 	s.requests = nil
-	params := makeStartInstanceParams(c, s.controllerUUID, "quantal")
-	params.InstanceConfig.Tags[tags.JujuUnitsDeployed] = unitsDeployed
-
-	_, err := env.StartInstance(params)
+	_, err := env.StartInstance(makeStartInstanceParams(c, s.controllerUUID, "quantal"))
 	c.Assert(err, jc.ErrorIsNil)
 	s.assertStartInstanceRequests(c, s.requests, assertStartInstanceRequestsParams{
-		availabilitySetName: "mysql",
-		imageReference:      &quantalImageReference,
-		diskSizeGB:          32,
-		osProfile:           &linuxOsProfile,
+		imageReference:  &quantalImageReference,
+		diskSizeGB:      32,
+		osProfile:       &linuxOsProfile,
+		securityGroupID: testSecurityGroupID,
 	})
 }
 
-const numExpectedStartInstanceRequests = 3
-
-type assertStartInstanceRequestsParams struct {
-	availabilitySetName string
-	imageReference      *compute.ImageReference
-	vmExtension         *compute.VirtualMachineExtensionProperties
-	diskSizeGB          int
-	osProfile           *compute.OSProfile
-}
-
-func (s *environSuite) assertStartInstanceRequests(
-	c *gc.C,
-	requests []*http.Request,
-	args assertStartInstanceRequestsParams,
-) startInstanceRequests {
-	nsgId := `[resourceId('Microsoft.Network/networkSecurityGroups', 'juju-internal-nsg')]`
-	securityRules := []network.SecurityRule{{
-		Name: to.StringPtr("SSHInbound"),
+func egressAllowSecurityRule(i int, cidr string) network.SecurityRule {
+	return network.SecurityRule{
+		Name: to.StringPtr(fmt.Sprintf("EgressAllow%d", i)),
 		Properties: &network.SecurityRulePropertiesFormat{
-			Description:              to.StringPtr("Allow SSH access to all machines"),
-			Protocol:                 network.TCP,
+			Description:              to.StringPtr("Allow outbound connections to " + cidr),
+			Protocol:                 network.Asterisk,
 			SourceAddressPrefix:      to.StringPtr("*"),
 			SourcePortRange:          to.StringPtr("*"),
-			DestinationAddressPrefix: to.StringPtr("*"),
-			DestinationPortRange:     to.StringPtr("22"),
+			DestinationAddressPrefix: to.StringPtr(cidr),
+			DestinationPortRange:     to.StringPtr("*"),
 			Access:                   network.Allow,
-			Priority:                 to.Int32Ptr(100),
-			Direction:                network.Inbound,
+			Priority:                 to.Int32Ptr(4000 + int32(i)),
+			Direction:                network.Outbound,
 		},
-	}, {
-		Name: to.StringPtr("JujuAPIInbound"),
+	}
+}
+
+func egressAllowAPISecurityRule(apiPort int) network.SecurityRule {
+	return network.SecurityRule{
+		Name: to.StringPtr("EgressAllowJujuAPI"),
 		Properties: &network.SecurityRulePropertiesFormat{
-			Description:              to.StringPtr("Allow API connections to controller machines"),
+			Description:              to.StringPtr("Allow outbound connections to the Juju API"),
 			Protocol:                 network.TCP,
 			SourceAddressPrefix:      to.StringPtr("*"),
 			SourcePortRange:          to.StringPtr("*"),
-			DestinationAddressPrefix: to.StringPtr("192.168.16.0/20"),
-			DestinationPortRange:     to.StringPtr("17777"),
+			DestinationAddressPrefix: to.StringPtr("*"),
+			DestinationPortRange:     to.StringPtr(fmt.Sprint(apiPort)),
+			Access:                   network.Allow,
+			Priority:                 to.Int32Ptr(4094),
+			Direction:                network.Outbound,
+		},
+	}
+}
+
+var egressDenySecurityRule = network.SecurityRule{
+	Name: to.StringPtr("EgressDenyInternet"),
+	Properties: &network.SecurityRulePropertiesFormat{
+		Description:              to.StringPtr("Deny outbound connections to the Internet"),
+		Protocol:                 network.Asterisk,
+		SourceAddressPrefix:      to.StringPtr("*"),
+		SourcePortRange:          to.StringPtr("*"),
+		DestinationAddressPrefix: to.StringPtr("Internet"),
+		DestinationPortRange:     to.StringPtr("*"),
+		Access:                   network.Deny,
+		Priority:                 to.Int32Ptr(4095),
+		Direction:                network.Outbound,
+	},
+}
+
+var ingressDenySecurityRule = network.SecurityRule{
+	Name: to.StringPtr("IngressDenyAll"),
+	Properties: &network.SecurityRulePropertiesFormat{
+		Description:              to.StringPtr("Deny inbound connections not otherwise allowed"),
+		Protocol:                 network.Asterisk,
+		SourceAddressPrefix:      to.StringPtr("*"),
+		SourcePortRange:          to.StringPtr("*"),
+		DestinationAddressPrefix: to.StringPtr("*"),
+		DestinationPortRange:     to.StringPtr("*"),
+		Access:                   network.Deny,
+		Priority:                 to.Int32Ptr(4096),
+		Direction:                network.Inbound,
+	},
+}
+
+func (s *environSuite) TestSetConfigUpdatesPolicySecurityRules(c *gc.C) {
+	env := s.openEnviron(c, testing.Attrs{
+		"egress-allow-list": "10.0.0.0/8,192.0.2.0/24",
+	})
+
+	// The rules enforcing the old policy are in place, alongside
+	// a rule created by the user, which must be left alone.
+	userRule := network.SecurityRule{
+		Name: to.StringPtr("EgressAllowMine"),
+		Properties: &network.SecurityRulePropertiesFormat{
+			Access:    network.Allow,
+			Priority:  to.Int32Ptr(300),
+			Direction: network.Outbound,
+		},
+	}
+	apiRule := network.SecurityRule{
+		Name: to.StringPtr("JujuAPIInbound"),
+		Properties: &network.SecurityRulePropertiesFormat{
+			DestinationPortRange: to.StringPtr("17777"),
+			Access:               network.Allow,
+			Priority:             to.Int32Ptr(101),
+			Direction:            network.Inbound,
+		},
+	}
+	nsgSender := networkSecurityGroupSender([]network.SecurityRule{
+		userRule,
+		apiRule,
+		egressAllowSecurityRule(0, "10.0.0.0/8"),
+		egressAllowSecurityRule(1, "192.0.2.0/24"),
+		egressAllowAPISecurityRule(17070),
+		egressDenySecurityRule,
+	})
+	okSender := mocks.NewSender()
+	okSender.AppendResponse(mocks.NewResponseWithContent("{}"))
+	s.sender = azuretesting.Senders{nsgSender, okSender, okSender, okSender, okSender}
+	s.requests = nil
+
+	cfg := makeTestModelConfig(c, testing.Attrs{
+		"egress-allow-list":    "198.51.100.0/24",
+		"ingress-default-deny": true,
+	})
+	err := env.SetConfig(cfg)
+	c.Assert(err, jc.ErrorIsNil)
+
+	// The API port is taken from the rule allowing inbound API
+	// connections to the controller machines.
+	c.Assert(s.requests, gc.HasLen, 5)
+	c.Assert(s.requests[0].Method, gc.Equals, "GET")
+	c.Assert(s.requests[0].URL.Path, gc.Equals, internalSecurityGroupPath)
+	c.Assert(s.requests[1].Method, gc.Equals, "PUT")
+	c.Assert(s.requests[1].URL.Path, gc.Equals, securityRulePath("EgressAllow0"))
+	assertRequestBody(c, s.requests[1], &network.SecurityRule{
+		Properties: egressAllowSecurityRule(0, "198.51.100.0/24").Properties,
+	})
+	c.Assert(s.requests[2].Method, gc.Equals, "PUT")
+	c.Assert(s.requests[2].URL.Path, gc.Equals, securityRulePath("EgressAllowJujuAPI"))
+	assertRequestBody(c, s.requests[2], &network.SecurityRule{
+		Properties: egressAllowAPISecurityRule(17777).Properties,
+	})
+	c.Assert(s.requests[3].Method, gc.Equals, "PUT")
+	c.Assert(s.requests[3].URL.Path, gc.Equals, securityRulePath("IngressDenyAll"))
+	assertRequestBody(c, s.requests[3], &network.SecurityRule{
+		Properties: ingressDenySecurityRule.Properties,
+	})
+	c.Assert(s.requests[4].Method, gc.Equals, "DELETE")
+	c.Assert(s.requests[4].URL.Path, gc.Equals, securityRulePath("EgressAllow1"))
+}
+
+func (s *environSuite) TestSetConfigRemovesPolicySecurityRules(c *gc.C) {
+	env := s.openEnviron(c, testing.Attrs{
+		"egress-allow-list": "10.0.0.0/8",
+	})
+	nsgSender := networkSecurityGroupSender([]network.SecurityRule{
+		egressAllowSecurityRule(0, "10.0.0.0/8"),
+		egressDenySecurityRule,
+	})
+	okSender := mocks.NewSender()
+	okSender.AppendResponse(mocks.NewResponseWithContent("{}"))
+	s.sender = azuretesting.Senders{nsgSender, okSender, okSender}
+	s.requests = nil
+
+	err := env.SetConfig(makeTestModelConfig(c))
+	c.Assert(err, jc.ErrorIsNil)
+
+	// The rule denying the Internet must be deleted before
+	// the rules allowing parts of it.
+	c.Assert(s.requests, gc.HasLen, 3)
+	c.Assert(s.requests[1].Method, gc.Equals, "DELETE")
+	c.Assert(s.requests[1].URL.Path, gc.Equals, securityRulePath("EgressDenyInternet"))
+	c.Assert(s.requests[2].Method, gc.Equals, "DELETE")
+	c.Assert(s.requests[2].URL.Path, gc.Equals, securityRulePath("EgressAllow0"))
+}
+
+func (s *environSuite) TestSetConfigUpdatesPolicySecurityRulesUnchanged(c *gc.C) {
+	env := s.openEnviron(c, testing.Attrs{"ingress-default-deny": true})
+	s.sender = azuretesting.Senders{}
+	s.requests = nil
+	cfg := makeTestModelConfig(c, testing.Attrs{
+		"ingress-default-deny":  true,
+		"shutdown-grace-period": "1m",
+	})
+	err := env.SetConfig(cfg)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(s.requests, gc.HasLen, 0)
+}
+
+func (s *environSuite) TestSetConfigUpdatesPolicySecurityRulesNoSecurityGroup(c *gc.C) {
+	env := s.openEnviron(c)
+	sender := mocks.NewSender()
+	sender.AppendResponse(mocks.NewResponseWithStatus(
+		"network security group not found", http.StatusNotFound,
+	))
+	s.sender = azuretesting.Senders{sender}
+	s.requests = nil
+	cfg := makeTestModelConfig(c, testing.Attrs{"ingress-default-deny": true})
+	err := env.SetConfig(cfg)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(s.requests, gc.HasLen, 1)
+}
+
+func (s *environSuite) TestStartInstanceDeletesOrphanedResources(c *gc.C) {
+	env := s.openEnviron(c)
+
+	// machine-0's NIC and public IP were left behind by a previous
+	// machine with the same ID, while machine-1's are in use. The
+	// attached NIC and associated public IP must not be deleted.
+	orphanedNic := makeNetworkInterface("machine-0-primary", "machine-0")
+	attachedNic := makeNetworkInterface("machine-0-primary-deadbeef", "machine-0")
+	attachedNic.Properties.VirtualMachine = &network.SubResource{ID: to.StringPtr("machine-0")}
+	otherNic := makeNetworkInterface("machine-1-primary", "machine-1")
+	orphanedPip := makePublicIPAddress("machine-0-public-ip", "machine-0", "1.2.3.4")
+	associatedPip := makePublicIPAddress("machine-0-public-ip-deadbeef", "machine-0", "1.2.3.5")
+	associatedPip.Properties.IPConfiguration = &network.IPConfiguration{ID: to.StringPtr("primary")}
+	otherPip := makePublicIPAddress("machine-1-public-ip", "machine-1", "1.2.3.6")
+
+	s.sender = azuretesting.Senders{
+		s.vmSizesSender(),
+		s.makeSender(".*/Canonical/.*/UbuntuServer/skus", s.ubuntuServerSKUs),
+		s.networkInterfacesSender(orphanedNic, attachedNic, otherNic),
+		s.makeSender(".*/networkInterfaces/machine-0-primary", nil), // DELETE
+		s.publicIPAddressesSender(orphanedPip, associatedPip, otherPip),
+		s.makeSender(".*/publicIPAddresses/machine-0-public-ip", nil), // DELETE
+		s.virtualMachinesSender(),
+		s.makeSender("/deployments/machine-0", s.deployment),
+	}
+	s.requests = nil
+	_, err := env.StartInstance(makeStartInstanceParams(c, s.controllerUUID, "quantal"))
+	c.Assert(err, jc.ErrorIsNil)
+
+	c.Assert(s.requests, gc.HasLen, numExpectedStartInstanceRequests+2)
+	c.Assert(s.requests[3].Method, gc.Equals, "DELETE")
+	c.Assert(path.Base(s.requests[3].URL.Path), gc.Equals, "machine-0-primary")
+	c.Assert(s.requests[5].Method, gc.Equals, "DELETE")
+	c.Assert(path.Base(s.requests[5].URL.Path), gc.Equals, "machine-0-public-ip")
+	c.Assert(s.requests[7].Method, gc.Equals, "PUT")
+}
+
+func (s *environSuite) TestStartInstanceTracesRequests(c *gc.C) {
+	env := s.openEnviron(c)
+	s.sender = s.startInstanceSenders(false)
+	s.requests = nil
+	_, err := env.StartInstance(makeStartInstanceParams(c, s.controllerUUID, "quantal"))
+	c.Assert(err, jc.ErrorIsNil)
+
+	// The instance types are shared by all machines, so the
+	// request to list them is not traced as part of the operation.
+	c.Assert(s.requests, gc.HasLen, numExpectedStartInstanceRequests)
+	c.Assert(s.requests[0].Header.Get("x-juju-operation-id"), gc.Equals, "")
+	for _, req := range s.requests[1:] {
+		c.Assert(req.Header.Get("x-juju-operation-id"), gc.Equals, "machine-0.start-instance.c0ffee00")
+		c.Assert(req.Header.Get("x-ms-client-request-id"), gc.Equals, "c0ffee00-0bad-4000-8000-000000000000")
+	}
+
+	var buf bytes.Buffer
+	tracer := s.provider.(environs.RequestTracer)
+	err = tracer.WriteRequestTrace(&buf, testing.ModelTag.Id(), "machine-0")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(buf.String(), gc.Matches, "(?s)MODEL .* machine-0.start-instance.c0ffee00 .* PUT .*/deployments/machine-0 .*")
+	err = tracer.WriteRequestTrace(&buf, testing.ModelTag.Id(), "machine-1")
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+	err = tracer.WriteRequestTrace(&buf, "deadbeef-0bad-400d-8000-4b1d0d06f00d", "machine-0")
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+}
+
+func (s *environSuite) TestStartInstanceCachesImage(c *gc.C) {
+	env := s.openEnviron(c)
+	s.sender = s.startInstanceSenders(false)
+	_, err := env.StartInstance(makeStartInstanceParams(c, s.controllerUUID, "quantal"))
+	c.Assert(err, jc.ErrorIsNil)
+
+	// Environs opened with the same provider share the image cache,
+	// so the Ubuntu SKUs should not be listed again.
+	env = s.openEnviron(c)
+	s.sender = azuretesting.Senders{
+		s.vmSizesSender(),
+		s.networkInterfacesSender(),
+		s.publicIPAddressesSender(),
+		s.virtualMachinesSender(),
+		s.makeSender("/deployments/machine-0", s.deployment),
+	}
+	s.requests = nil
+	_, err = env.StartInstance(makeStartInstanceParams(c, s.controllerUUID, "quantal"))
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(s.requests, gc.HasLen, numExpectedStartInstanceRequests-1)
+	c.Assert(s.requests[0].Method, gc.Equals, "GET") // vmSizes
+	c.Assert(s.requests[1].Method, gc.Equals, "GET") // NICs
+	c.Assert(s.requests[2].Method, gc.Equals, "GET") // public IPs
+	c.Assert(s.requests[3].Method, gc.Equals, "GET") // virtual machines
+	c.Assert(s.requests[4].Method, gc.Equals, "PUT") // create deployment
+}
+
+func (s *environSuite) TestQuotas(c *gc.C) {
+	env := s.openEnviron(c)
+	usages := map[string]interface{}{
+		"value": []interface{}{
+			map[string]interface{}{
+				"name":         map[string]interface{}{"value": "cores"},
+				"currentValue": 20,
+				"limit":        20,
+			},
+			map[string]interface{}{
+				"name":         map[string]interface{}{"value": "standardDFamily"},
+				"currentValue": 4,
+				"limit":        10,
+			},
+		},
+	}
+	networkUsages := map[string]interface{}{
+		"value": []interface{}{
+			map[string]interface{}{
+				"name":         map[string]interface{}{"value": "PublicIPAddresses"},
+				"currentValue": 3,
+				"limit":        60,
+			},
+		},
+	}
+	storageUsages := map[string]interface{}{
+		"value": []interface{}{
+			map[string]interface{}{
+				"name":         map[string]interface{}{"value": "StorageAccounts"},
+				"currentValue": 100,
+				"limit":        100,
+			},
+		},
+	}
+	s.sender = azuretesting.Senders{
+		s.makeSender(".*/Microsoft.Compute/locations/westus/usages", usages),
+		s.makeSender(".*/Microsoft.Network/locations/westus/usages", networkUsages),
+		s.makeSender(".*/Microsoft.Storage/usages", storageUsages),
+	}
+	quotaInfo, ok := env.(environs.QuotaInfo)
+	c.Assert(ok, jc.IsTrue)
+	quotas, err := quotaInfo.Quotas()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(quotas, jc.DeepEquals, []environs.Quota{
+		{Resource: environs.QuotaCores, Used: 20, Limit: 20},
+		{Resource: "standardDFamily", Used: 4, Limit: 10},
+		{Resource: environs.QuotaPublicIPAddresses, Used: 3, Limit: 60},
+		{Resource: environs.QuotaStorageAccounts, Used: 100, Limit: 100},
+	})
+}
+
+func (s *environSuite) TestAllResources(c *gc.C) {
+	env := s.openEnviron(c)
+	result := resources.ResourceListResult{
+		Value: &[]resources.GenericResource{{
+			ID:   to.StringPtr("/subscriptions/sub/resourceGroups/rg/providers/Microsoft.Network/networkInterfaces/machine-0-primary"),
+			Type: to.StringPtr("Microsoft.Network/networkInterfaces"),
+		}, {
+			ID:   to.StringPtr("/subscriptions/sub/resourceGroups/rg/providers/Microsoft.Compute/virtualMachines/machine-0"),
+			Type: to.StringPtr("Microsoft.Compute/virtualMachines"),
+			Tags: to.StringMapPtr(map[string]string{"juju-machine-name": "machine-0"}),
+		}},
+	}
+	s.sender = azuretesting.Senders{s.makeSender(".*/resourceGroups/.*/resources", result)}
+	enumerator, ok := env.(environs.ResourceEnumerator)
+	c.Assert(ok, jc.IsTrue)
+	all, err := enumerator.AllResources()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(all, jc.DeepEquals, []environs.Resource{{
+		Type: "Microsoft.Compute/virtualMachines",
+		Id:   "/subscriptions/sub/resourceGroups/rg/providers/Microsoft.Compute/virtualMachines/machine-0",
+		Tags: map[string]string{"juju-machine-name": "machine-0"},
+	}, {
+		Type: "Microsoft.Network/networkInterfaces",
+		Id:   "/subscriptions/sub/resourceGroups/rg/providers/Microsoft.Network/networkInterfaces/machine-0-primary",
+	}})
+}
+
+func (s *environSuite) TestSupportedFeatures(c *gc.C) {
+	env := s.openEnviron(c)
+	features, err := environs.SupportedFeatures(env)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(features, jc.DeepEquals, []environs.Feature{
+		environs.FeatureSuspendInstances,
+		environs.FeatureVolumes,
+	})
+}
+
+func (s *environSuite) TestAllResourcesResourceGroupNotFound(c *gc.C) {
+	env := s.openEnviron(c)
+	sender := mocks.NewSender()
+	sender.AppendResponse(mocks.NewResponseWithStatus(
+		"resource group not found", http.StatusNotFound,
+	))
+	s.sender = azuretesting.Senders{sender}
+	all, err := env.(environs.ResourceEnumerator).AllResources()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(all, gc.HasLen, 0)
+}
+
+func (s *environSuite) TestSweepMachineResources(c *gc.C) {
+	env := s.openEnviron(c)
+
+	// machine-0 is live, so its resources must not be deleted. The
+	// resources of machine-1 and machine-2 are orphaned, except for
+	// machine-1's NIC that is still attached to a virtual machine.
+	liveNic := makeNetworkInterface("machine-0-primary", "machine-0")
+	orphanedNic := makeNetworkInterface("machine-1-primary", "machine-1")
+	orphanedNic.ID = to.StringPtr("machine-1-primary-id")
+	attachedNic := makeNetworkInterface("machine-1-primary-deadbeef", "machine-1")
+	attachedNic.Properties.VirtualMachine = &network.SubResource{ID: to.StringPtr("machine-1")}
+	untaggedNic := makeNetworkInterface("other", "")
+	livePip := makePublicIPAddress("machine-0-public-ip", "machine-0", "1.2.3.4")
+	orphanedPip1 := makePublicIPAddress("machine-1-public-ip", "machine-1", "1.2.3.5")
+	orphanedPip1.ID = to.StringPtr("machine-1-public-ip-id")
+	orphanedPip2 := makePublicIPAddress("machine-2-public-ip", "machine-2", "1.2.3.6")
+	orphanedPip2.ID = to.StringPtr("machine-2-public-ip-id")
+
+	s.sender = azuretesting.Senders{
+		s.networkInterfacesSender(liveNic, orphanedNic, attachedNic, untaggedNic),
+		s.publicIPAddressesSender(livePip, orphanedPip1, orphanedPip2),
+		s.makeSender(".*/networkInterfaces/machine-1-primary", nil), // DELETE
+		s.publicIPAddressesSender(livePip, orphanedPip1, orphanedPip2),
+		s.makeSender(".*/publicIPAddresses/machine-1-public-ip", nil), // DELETE
+		s.makeSender(".*/publicIPAddresses/machine-2-public-ip", nil), // DELETE
+	}
+	s.requests = nil
+	var liveMachinesCalls int
+	deleted, err := env.(environs.MachineResourceSweeper).SweepMachineResources(func() ([]string, error) {
+		// The resources must have been listed first.
+		c.Assert(s.requests, gc.HasLen, 2)
+		liveMachinesCalls++
+		return []string{"0"}, nil
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(liveMachinesCalls, gc.Equals, 1)
+	c.Assert(deleted, jc.DeepEquals, []environs.Resource{{
+		Type: "Microsoft.Network/networkInterfaces",
+		Id:   "machine-1-primary-id",
+		Tags: map[string]string{"juju-machine-name": "machine-1"},
+	}, {
+		Type: "Microsoft.Network/publicIPAddresses",
+		Id:   "machine-1-public-ip-id",
+		Tags: map[string]string{"juju-machine-name": "machine-1"},
+	}, {
+		Type: "Microsoft.Network/publicIPAddresses",
+		Id:   "machine-2-public-ip-id",
+		Tags: map[string]string{"juju-machine-name": "machine-2"},
+	}})
+
+	c.Assert(s.requests, gc.HasLen, 6)
+	c.Assert(s.requests[2].Method, gc.Equals, "DELETE")
+	c.Assert(path.Base(s.requests[2].URL.Path), gc.Equals, "machine-1-primary")
+	c.Assert(s.requests[4].Method, gc.Equals, "DELETE")
+	c.Assert(path.Base(s.requests[4].URL.Path), gc.Equals, "machine-1-public-ip")
+	c.Assert(s.requests[5].Method, gc.Equals, "DELETE")
+	c.Assert(path.Base(s.requests[5].URL.Path), gc.Equals, "machine-2-public-ip")
+}
+
+func (s *environSuite) TestSweepMachineResourcesNothingOrphaned(c *gc.C) {
+	env := s.openEnviron(c)
+	nic := makeNetworkInterface("machine-0-primary", "machine-0")
+	pip := makePublicIPAddress("machine-0-public-ip", "machine-0", "1.2.3.4")
+	s.sender = azuretesting.Senders{
+		s.networkInterfacesSender(nic),
+		s.publicIPAddressesSender(pip),
+	}
+	s.requests = nil
+	deleted, err := env.(environs.MachineResourceSweeper).SweepMachineResources(func() ([]string, error) {
+		return []string{"0"}, nil
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(deleted, gc.HasLen, 0)
+	c.Assert(s.requests, gc.HasLen, 2)
+}
+
+func (s *environSuite) TestSweepMachineResourcesResourceNamePrefix(c *gc.C) {
+	env := s.openEnviron(c, testing.Attrs{"resource-name-prefix": "acme"})
+	liveNic := makeNetworkInterface("acme-machine-0-primary", "acme-machine-0")
+	livePip := makePublicIPAddress("acme-machine-0-public-ip", "acme-machine-0", "1.2.3.4")
+	orphanedPip := makePublicIPAddress("acme-machine-1-public-ip", "acme-machine-1", "1.2.3.5")
+	orphanedPip.ID = to.StringPtr("acme-machine-1-public-ip-id")
+	s.sender = azuretesting.Senders{
+		s.networkInterfacesSender(liveNic),
+		s.publicIPAddressesSender(livePip, orphanedPip),
+		s.publicIPAddressesSender(livePip, orphanedPip),
+		s.makeSender(".*/publicIPAddresses/acme-machine-1-public-ip", nil), // DELETE
+	}
+	s.requests = nil
+	deleted, err := env.(environs.MachineResourceSweeper).SweepMachineResources(func() ([]string, error) {
+		return []string{"0"}, nil
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(deleted, jc.DeepEquals, []environs.Resource{{
+		Type: "Microsoft.Network/publicIPAddresses",
+		Id:   "acme-machine-1-public-ip-id",
+		Tags: map[string]string{"juju-machine-name": "acme-machine-1"},
+	}})
+	c.Assert(s.requests, gc.HasLen, 4)
+	c.Assert(s.requests[3].Method, gc.Equals, "DELETE")
+	c.Assert(path.Base(s.requests[3].URL.Path), gc.Equals, "acme-machine-1-public-ip")
+}
+
+func (s *environSuite) TestSweepMachineResourcesLiveMachinesError(c *gc.C) {
+	env := s.openEnviron(c)
+	s.sender = azuretesting.Senders{
+		s.networkInterfacesSender(makeNetworkInterface("machine-1-primary", "machine-1")),
+		s.publicIPAddressesSender(),
+	}
+	_, err := env.(environs.MachineResourceSweeper).SweepMachineResources(func() ([]string, error) {
+		return nil, errors.New("boom")
+	})
+	c.Assert(err, gc.ErrorMatches, "getting live machines: boom")
+}
+
+func (s *environSuite) runCommandSenders(osType compute.OperatingSystemTypes, status compute.InstanceViewStatus) azuretesting.Senders {
+	vm := &compute.VirtualMachine{
+		Name:     to.StringPtr("machine-0"),
+		Location: to.StringPtr("westus"),
+		Properties: &compute.VirtualMachineProperties{
+			StorageProfile: &compute.StorageProfile{
+				OsDisk: &compute.OSDisk{OsType: osType},
+			},
+		},
+	}
+	extension := &compute.VirtualMachineExtension{
+		Properties: &compute.VirtualMachineExtensionProperties{
+			InstanceView: &compute.VirtualMachineExtensionInstanceView{
+				Statuses: &[]compute.InstanceViewStatus{status},
+			},
+		},
+	}
+	return azuretesting.Senders{
+		s.makeSender(".*/virtualMachines/machine-0", vm),                                             // GET
+		s.makeSender(".*/virtualMachines/machine-0/extensions/JujuCustomScriptExtension", nil),       // PUT
+		s.makeSender(".*/virtualMachines/machine-0/extensions/JujuCustomScriptExtension", extension), // GET
+	}
+}
+
+func (s *environSuite) TestRunCommand(c *gc.C) {
+	env := s.openEnviron(c)
+	s.requests = nil
+	s.sender = s.runCommandSenders(compute.Linux, compute.InstanceViewStatus{
+		Code:    to.StringPtr("ProvisioningState/succeeded"),
+		Level:   compute.Info,
+		Message: to.StringPtr("Command is finished.\n---stdout---\nhello\n\n---errout---\n\n"),
+	})
+	result, err := env.(environs.InstanceCommandRunner).RunCommand("machine-0", "echo hello")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(result, jc.DeepEquals, &environs.InstanceCommandResult{
+		Stdout: "hello\n\n",
+		Stderr: "\n",
+	})
+
+	c.Assert(s.requests, gc.HasLen, 3)
+	c.Assert(s.requests[1].Method, gc.Equals, "PUT")
+	var extension compute.VirtualMachineExtension
+	unmarshalRequestBody(c, s.requests[1], &extension)
+	settings := *extension.Properties.Settings
+	c.Assert(settings["commandToExecute"], gc.Equals, "bash -c 'echo ZWNobyBoZWxsbw== | base64 -d | bash'")
+	c.Assert(settings["timestamp"], gc.NotNil)
+	c.Assert(to.String(extension.Properties.Type), gc.Equals, "CustomScriptForLinux")
+}
+
+func (s *environSuite) TestRunCommandExitCode(c *gc.C) {
+	env := s.openEnviron(c)
+	s.sender = s.runCommandSenders(compute.Linux, compute.InstanceViewStatus{
+		Code:    to.StringPtr("ProvisioningState/failed/3"),
+		Level:   compute.Error,
+		Message: to.StringPtr("Command is finished.\n---stdout---\n\n---errout---\nno nonce\n"),
+	})
+	result, err := env.(environs.InstanceCommandRunner).RunCommand("machine-0", "exit 3")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(result, jc.DeepEquals, &environs.InstanceCommandResult{
+		Code:   3,
+		Stdout: "\n",
+		Stderr: "no nonce\n",
+	})
+}
+
+func (s *environSuite) TestRunCommandWindows(c *gc.C) {
+	env := s.openEnviron(c)
+	s.sender = s.runCommandSenders(compute.Windows, compute.InstanceViewStatus{})[:1]
+	_, err := env.(environs.InstanceCommandRunner).RunCommand("machine-0", "echo hello")
+	c.Assert(err, jc.Satisfies, errors.IsNotSupported)
+	c.Assert(err, gc.ErrorMatches, `running commands on "Windows" instances not supported`)
+}
+
+func (s *environSuite) TestRunCommandInstanceNotFound(c *gc.C) {
+	env := s.openEnviron(c)
+	sender := mocks.NewSender()
+	sender.AppendResponse(mocks.NewResponseWithStatus(
+		"vm not found", http.StatusNotFound,
+	))
+	s.sender = azuretesting.Senders{sender}
+	_, err := env.(environs.InstanceCommandRunner).RunCommand("machine-0", "echo hello")
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+}
+
+func (s *environSuite) authorizedKeysVirtualMachine(name string, osType compute.OperatingSystemTypes) *compute.VirtualMachine {
+	return &compute.VirtualMachine{
+		Name:     to.StringPtr(name),
+		Location: to.StringPtr("westus"),
+		Properties: &compute.VirtualMachineProperties{
+			OsProfile: &compute.OSProfile{AdminUsername: to.StringPtr("ubuntu")},
+			StorageProfile: &compute.StorageProfile{
+				OsDisk: &compute.OSDisk{OsType: osType},
+			},
+		},
+	}
+}
+
+func (s *environSuite) TestUpdateAuthorizedKeys(c *gc.C) {
+	env := s.openEnviron(c)
+	s.requests = nil
+	s.sender = azuretesting.Senders{
+		s.makeSender(".*/virtualMachines/machine-0", s.authorizedKeysVirtualMachine("machine-0", compute.Linux)), // GET
+		s.makeSender(".*/virtualMachines/machine-0/extensions/JujuVMAccessExtension", nil),                       // PUT
+	}
+	err := env.(environs.InstanceAuthorizedKeysUpdater).UpdateAuthorizedKeys(
+		[]instance.Id{"machine-0"}, "ssh-rsa new-key",
+	)
+	c.Assert(err, jc.ErrorIsNil)
+
+	c.Assert(s.requests, gc.HasLen, 2)
+	c.Assert(s.requests[1].Method, gc.Equals, "PUT")
+	var extension compute.VirtualMachineExtension
+	unmarshalRequestBody(c, s.requests[1], &extension)
+	c.Assert(to.String(extension.Properties.Publisher), gc.Equals, "Microsoft.OSTCExtensions")
+	c.Assert(to.String(extension.Properties.Type), gc.Equals, "VMAccessForLinux")
+	c.Assert((*extension.Properties.Settings)["timestamp"], gc.NotNil)
+	c.Assert(*extension.Properties.ProtectedSettings, jc.DeepEquals, map[string]interface{}{
+		"username": "ubuntu",
+		"ssh_key":  "ssh-rsa new-key",
+	})
+}
+
+func (s *environSuite) TestUpdateAuthorizedKeysSkipsWindowsAndMissing(c *gc.C) {
+	env := s.openEnviron(c)
+	notFoundSender := mocks.NewSender()
+	notFoundSender.AppendResponse(mocks.NewResponseWithStatus(
+		"vm not found", http.StatusNotFound,
+	))
+	s.requests = nil
+	s.sender = azuretesting.Senders{
+		notFoundSender, // GET machine-0
+		s.makeSender(".*/virtualMachines/machine-1", s.authorizedKeysVirtualMachine("machine-1", compute.Windows)), // GET
+		s.makeSender(".*/virtualMachines/machine-2", s.authorizedKeysVirtualMachine("machine-2", compute.Linux)),   // GET
+		s.makeSender(".*/virtualMachines/machine-2/extensions/JujuVMAccessExtension", nil),                         // PUT
+	}
+	err := env.(environs.InstanceAuthorizedKeysUpdater).UpdateAuthorizedKeys(
+		[]instance.Id{"machine-0", "machine-1", "machine-2"}, "ssh-rsa new-key",
+	)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(s.requests, gc.HasLen, 4)
+	c.Assert(s.requests[3].Method, gc.Equals, "PUT")
+	c.Assert(s.requests[3].URL.Path, gc.Matches, ".*/virtualMachines/machine-2/extensions/JujuVMAccessExtension")
+}
+
+func (s *environSuite) TestUpdateAuthorizedKeysContinuesAfterError(c *gc.C) {
+	env := s.openEnviron(c)
+	failSender := mocks.NewSender()
+	failSender.AppendResponse(mocks.NewResponseWithStatus(
+		"conflict", http.StatusConflict,
+	))
+	s.requests = nil
+	s.sender = azuretesting.Senders{
+		s.makeSender(".*/virtualMachines/machine-0", s.authorizedKeysVirtualMachine("machine-0", compute.Linux)), // GET
+		failSender, // PUT
+		s.makeSender(".*/virtualMachines/machine-1", s.authorizedKeysVirtualMachine("machine-1", compute.Linux)), // GET
+		s.makeSender(".*/virtualMachines/machine-1/extensions/JujuVMAccessExtension", nil),                       // PUT
+	}
+	err := env.(environs.InstanceAuthorizedKeysUpdater).UpdateAuthorizedKeys(
+		[]instance.Id{"machine-0", "machine-1"}, "ssh-rsa new-key",
+	)
+	c.Assert(err, gc.ErrorMatches, `updating authorized keys on "machine-0": .*`)
+	c.Assert(s.requests, gc.HasLen, 4)
+}
+
+func (s *environSuite) TestStartInstanceWindowsMinRootDisk(c *gc.C) {
+	// The minimum OS disk size for Windows machines is 127GiB.
+	cons := constraints.MustParse("root-disk=44G")
+	s.testStartInstanceWindows(c, cons, 127*1024, 136)
+}
+
+func (s *environSuite) TestStartInstanceWindowsGrowableRootDisk(c *gc.C) {
+	// The OS disk size may be grown larger than 127GiB.
+	cons := constraints.MustParse("root-disk=200G")
+	s.testStartInstanceWindows(c, cons, 200*1024, 214)
+}
+
+func (s *environSuite) testStartInstanceWindows(
+	c *gc.C, cons constraints.Value,
+	expect uint64, requestValue int,
+) {
+	// Starting a Windows VM, we should not expect an image query.
+	s.PatchValue(&s.ubuntuServerSKUs, nil)
+
+	env := s.openEnviron(c)
+	s.sender = s.startInstanceSenders(false)
+	s.requests = nil
+	args := makeStartInstanceParams(c, s.controllerUUID, "win2012")
+	args.Constraints = cons
+	result, err := env.StartInstance(args)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(result, gc.NotNil)
+	c.Assert(result.Hardware.RootDisk, jc.DeepEquals, &expect)
+
+	vmExtensionSettings := map[string]interface{}{
+		"commandToExecute": `` +
+			`move C:\AzureData\CustomData.bin C:\AzureData\CustomData.ps1 && ` +
+			`powershell.exe -ExecutionPolicy Unrestricted -File C:\AzureData\CustomData.ps1 && ` +
+			`del /q C:\AzureData\CustomData.ps1`,
+	}
+	s.assertStartInstanceRequests(c, s.requests, assertStartInstanceRequestsParams{
+		imageReference: &win2012ImageReference,
+		diskSizeGB:     requestValue,
+		vmExtension: &compute.VirtualMachineExtensionProperties{
+			Publisher:               to.StringPtr("Microsoft.Compute"),
+			Type:                    to.StringPtr("CustomScriptExtension"),
+			TypeHandlerVersion:      to.StringPtr("1.4"),
+			AutoUpgradeMinorVersion: to.BoolPtr(true),
+			Settings:                &vmExtensionSettings,
+		},
+		osProfile: &windowsOsProfile,
+	})
+}
+
+func (s *environSuite) TestStartInstanceCentOS(c *gc.C) {
+	// Starting a CentOS VM, we should not expect an image query.
+	s.PatchValue(&s.ubuntuServerSKUs, nil)
+
+	env := s.openEnviron(c)
+	s.sender = s.startInstanceSenders(false)
+	s.requests = nil
+	args := makeStartInstanceParams(c, s.controllerUUID, "centos7")
+	_, err := env.StartInstance(args)
+	c.Assert(err, jc.ErrorIsNil)
+
+	vmExtensionSettings := map[string]interface{}{
+		"commandToExecute": `bash -c 'base64 -d /var/lib/waagent/CustomData | bash'`,
+	}
+	s.assertStartInstanceRequests(c, s.requests, assertStartInstanceRequestsParams{
+		imageReference: &centos7ImageReference,
+		diskSizeGB:     32,
+		vmExtension: &compute.VirtualMachineExtensionProperties{
+			Publisher:               to.StringPtr("Microsoft.OSTCExtensions"),
+			Type:                    to.StringPtr("CustomScriptForLinux"),
+			TypeHandlerVersion:      to.StringPtr("1.4"),
+			AutoUpgradeMinorVersion: to.BoolPtr(true),
+			Settings:                &vmExtensionSettings,
+		},
+		osProfile: &linuxOsProfile,
+	})
+}
+
+func (s *environSuite) TestStartInstanceTooManyRequests(c *gc.C) {
+	env := s.openEnviron(c)
+	senders := s.startInstanceSenders(false)
+	s.requests = nil
+
+	// 6 failures to get to 1 minute, and show that we cap it there.
+	const failures = 6
+
+	// Make the VirtualMachines.CreateOrUpdate call respond with
+	// 429 (StatusTooManyRequests) failures, and then with success.
+	rateLimitedSender := mocks.NewSender()
+	rateLimitedSender.AppendAndRepeatResponse(mocks.NewResponseWithBodyAndStatus(
+		mocks.NewBody("{}"), // empty JSON response to appease go-autorest
+		http.StatusTooManyRequests,
+		"(」゜ロ゜)」",
+	), failures)
+	successSender := senders[len(senders)-1]
+	senders = senders[:len(senders)-1]
+	for i := 0; i < failures; i++ {
+		senders = append(senders, rateLimitedSender)
+	}
+	senders = append(senders, successSender)
+	s.sender = senders
+
+	_, err := env.StartInstance(makeStartInstanceParams(c, s.controllerUUID, "quantal"))
+	c.Assert(err, jc.ErrorIsNil)
+
+	c.Assert(s.requests, gc.HasLen, numExpectedStartInstanceRequests+failures)
+	s.assertStartInstanceRequests(c, s.requests[:numExpectedStartInstanceRequests], assertStartInstanceRequestsParams{
+		imageReference: &quantalImageReference,
+		diskSizeGB:     32,
+		osProfile:      &linuxOsProfile,
+	})
+
+	// The final requests should all be identical.
+	for i := numExpectedStartInstanceRequests; i < numExpectedStartInstanceRequests+failures; i++ {
+		c.Assert(s.requests[i].Method, gc.Equals, "PUT")
+		c.Assert(s.requests[i].URL.Path, gc.Equals, s.requests[numExpectedStartInstanceRequests-1].URL.Path)
+	}
+
+	s.retryClock.CheckCalls(c, []gitjujutesting.StubCall{
+		{"After", []interface{}{5 * time.Second}},
+		{"After", []interface{}{10 * time.Second}},
+		{"After", []interface{}{20 * time.Second}},
+		{"After", []interface{}{40 * time.Second}},
+		{"After", []interface{}{1 * time.Minute}},
+		{"After", []interface{}{1 * time.Minute}},
+	})
+}
+
+func (s *environSuite) TestStartInstanceTooManyRequestsTimeout(c *gc.C) {
+	env := s.openEnviron(c)
+	senders := s.startInstanceSenders(false)
+	s.requests = nil
+
+	// 8 failures to get to 5 minutes, which is as long as we'll keep
+	// retrying before giving up.
+	const failures = 8
+
+	// Make the VirtualMachines.Get call respond with enough 429
+	// (StatusTooManyRequests) failures to cause the method to give
+	// up retrying.
+	rateLimitedSender := mocks.NewSender()
+	rateLimitedSender.AppendAndRepeatResponse(mocks.NewResponseWithBodyAndStatus(
+		mocks.NewBody("{}"), // empty JSON response to appease go-autorest
+		http.StatusTooManyRequests,
+		"(」゜ロ゜)」",
+	), failures)
+	senders = senders[:len(senders)-1]
+	for i := 0; i < failures; i++ {
+		senders = append(senders, rateLimitedSender)
+	}
+	s.sender = senders
+
+	_, err := env.StartInstance(makeStartInstanceParams(c, s.controllerUUID, "quantal"))
+	c.Assert(err, gc.ErrorMatches, `creating virtual machine "machine-0": creating deployment "machine-0": max duration exceeded: .*`)
+
+	s.retryClock.CheckCalls(c, []gitjujutesting.StubCall{
+		{"After", []interface{}{5 * time.Second}},  // t0 + 5s
+		{"After", []interface{}{10 * time.Second}}, // t0 + 15s
+		{"After", []interface{}{20 * time.Second}}, // t0 + 35s
+		{"After", []interface{}{40 * time.Second}}, // t0 + 1m15s
+		{"After", []interface{}{1 * time.Minute}},  // t0 + 2m15s
+		{"After", []interface{}{1 * time.Minute}},  // t0 + 3m15s
+		{"After", []interface{}{1 * time.Minute}},  // t0 + 4m15s
+		// There would be another call here, but since the time
+		// exceeds the give minute limit, retrying is aborted.
+	})
+}
+
+func (s *environSuite) TestStartInstanceDistributionGroup(c *gc.C) {
+	c.Skip("TODO: test StartInstance's DistributionGroup behaviour")
+}
+
+func (s *environSuite) TestStartInstanceServiceAvailabilitySet(c *gc.C) {
+	env := s.openEnviron(c)
+	unitsDeployed := "mysql/0 wordpress/0"
+	s.vmTags[tags.JujuUnitsDeployed] = &unitsDeployed
+	s.sender = s.startInstanceSenders(false)
+	s.requests = nil
+	params := makeStartInstanceParams(c, s.controllerUUID, "quantal")
+	params.InstanceConfig.Tags[tags.JujuUnitsDeployed] = unitsDeployed
+
+	_, err := env.StartInstance(params)
+	c.Assert(err, jc.ErrorIsNil)
+	s.assertStartInstanceRequests(c, s.requests, assertStartInstanceRequestsParams{
+		availabilitySetName: "mysql",
+		imageReference:      &quantalImageReference,
+		diskSizeGB:          32,
+		osProfile:           &linuxOsProfile,
+	})
+}
+
+func (s *environSuite) TestStartInstanceAvailabilitySetsDisabled(c *gc.C) {
+	s.testStartInstanceAvailabilitySet(c, testing.Attrs{"availability-sets": false}, nil, "")
+}
+
+func (s *environSuite) TestStartInstanceAvailabilitySetExcluded(c *gc.C) {
+	s.testStartInstanceAvailabilitySet(c, testing.Attrs{"availability-set-exclusions": "wordpress,mysql"}, nil, "")
+}
+
+func (s *environSuite) TestStartInstanceAvailabilitySetRecorded(c *gc.C) {
+	// An existing machine of the application was placed in an
+	// availability set, so later machines are too, even though
+	// availability sets have since been disabled.
+	s.testStartInstanceAvailabilitySet(c, testing.Attrs{"availability-sets": false}, map[string]*string{
+		"juju-units-deployed":   to.StringPtr("mysql/1"),
+		"juju-availability-set": to.StringPtr("mysql"),
+	}, "mysql")
+}
+
+func (s *environSuite) TestStartInstanceAvailabilitySetRecordedNone(c *gc.C) {
+	// An existing machine of the application was not placed in
+	// an availability set, so later machines are not either.
+	s.testStartInstanceAvailabilitySet(c, nil, map[string]*string{
+		"juju-units-deployed":   to.StringPtr("mysql/1"),
+		"juju-availability-set": to.StringPtr(""),
+	}, "")
+}
+
+func (s *environSuite) TestStartInstanceAvailabilitySetUnrecorded(c *gc.C) {
+	// Machines created before the availability set was recorded
+	// are in the application's availability set.
+	s.testStartInstanceAvailabilitySet(c, testing.Attrs{"availability-sets": false}, map[string]*string{
+		"juju-units-deployed": to.StringPtr("mysql/1"),
+	}, "mysql")
+}
+
+func (s *environSuite) testStartInstanceAvailabilitySet(
+	c *gc.C, attrs testing.Attrs, existingTags map[string]*string, expect string,
+) {
+	env := s.openEnviron(c, attrs)
+	unitsDeployed := "mysql/0 wordpress/0"
+	s.vmTags[tags.JujuUnitsDeployed] = &unitsDeployed
+	senders := s.startInstanceSenders(false)
+	if existingTags != nil {
+		senders[len(senders)-2] = s.virtualMachinesSender(compute.VirtualMachine{
+			Name: to.StringPtr("machine-1"),
+			Tags: &existingTags,
+		})
+	}
+	s.sender = senders
+	s.requests = nil
+	params := makeStartInstanceParams(c, s.controllerUUID, "quantal")
+	params.InstanceConfig.Tags[tags.JujuUnitsDeployed] = unitsDeployed
+
+	_, err := env.StartInstance(params)
+	c.Assert(err, jc.ErrorIsNil)
+	s.assertStartInstanceRequests(c, s.requests, assertStartInstanceRequestsParams{
+		availabilitySetName: expect,
+		imageReference:      &quantalImageReference,
+		diskSizeGB:          32,
+		osProfile:           &linuxOsProfile,
+	})
+}
+
+func (s *environSuite) TestStartInstanceResourceNamePrefix(c *gc.C) {
+	env := s.openEnviron(c, testing.Attrs{"resource-name-prefix": "acme"})
+	unitsDeployed := "mysql/0"
+	s.vmTags[tags.JujuUnitsDeployed] = &unitsDeployed
+	s.sender = s.startInstanceSenders(false)
+	s.requests = nil
+	params := makeStartInstanceParams(c, s.controllerUUID, "quantal")
+	params.InstanceConfig.Tags[tags.JujuUnitsDeployed] = unitsDeployed
+
+	result, err := env.StartInstance(params)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(result.Instance.Id(), gc.Equals, instance.Id("acme-machine-0"))
+
+	c.Assert(s.requests, gc.HasLen, numExpectedStartInstanceRequests)
+	var deployment struct {
+		Properties struct {
+			Template struct {
+				Resources []struct {
+					Type       string                 `json:"type"`
+					Name       string                 `json:"name"`
+					Properties map[string]interface{} `json:"properties"`
+				} `json:"resources"`
+			} `json:"template"`
+		} `json:"properties"`
+	}
+	unmarshalRequestBody(c, s.requests[5], &deployment)
+	names := make(map[string]string)
+	for _, resource := range deployment.Properties.Template.Resources {
+		names[resource.Type] = resource.Name
+		switch resource.Type {
+		case "Microsoft.Network/publicIPAddresses":
+			// The DNS label is not prefixed.
+			dnsSettings := resource.Properties["dnsSettings"].(map[string]interface{})
+			c.Check(dnsSettings["domainNameLabel"], gc.Equals, "juju-deadbeef-machine-0")
+		case "Microsoft.Compute/virtualMachines":
+			// Neither is the computer name.
+			osProfile := resource.Properties["osProfile"].(map[string]interface{})
+			c.Check(osProfile["computerName"], gc.Equals, "machine-0")
+		}
+	}
+	c.Assert(names, jc.DeepEquals, map[string]string{
+		"Microsoft.Network/networkSecurityGroups": "juju-internal-nsg",
+		"Microsoft.Network/virtualNetworks":       "juju-internal-network",
+		"Microsoft.Storage/storageAccounts":       storageAccountName,
+		"Microsoft.Compute/availabilitySets":      "acme-mysql",
+		"Microsoft.Network/publicIPAddresses":     "acme-machine-0-public-ip-c0ffee00",
+		"Microsoft.Network/networkInterfaces":     "acme-machine-0-primary-c0ffee00",
+		"Microsoft.Compute/virtualMachines":       "acme-machine-0",
+	})
+}
+
+func (s *environSuite) TestStartInstancePlacementAvailabilitySet(c *gc.C) {
+	// The named availability set overrides the application's.
+	s.testStartInstancePlacement(c, "aset=db", assertStartInstanceRequestsParams{
+		availabilitySetName: "db",
+	})
+}
+
+func (s *environSuite) TestStartInstancePlacementProximityPlacementGroup(c *gc.C) {
+	s.testStartInstancePlacement(c, "ppg=group1", assertStartInstanceRequestsParams{
+		availabilitySetName:         "mysql",
+		proximityPlacementGroupName: "group1",
+	})
+}
+
+func (s *environSuite) TestStartInstancePlacementBoth(c *gc.C) {
+	s.testStartInstancePlacement(c, "ppg=group1,aset=db", assertStartInstanceRequestsParams{
+		availabilitySetName:         "db",
+		proximityPlacementGroupName: "group1",
+	})
+}
+
+func (s *environSuite) testStartInstancePlacement(
+	c *gc.C, placement string, expect assertStartInstanceRequestsParams,
+) {
+	env := s.openEnviron(c)
+	unitsDeployed := "mysql/0 wordpress/0"
+	s.vmTags[tags.JujuUnitsDeployed] = &unitsDeployed
+	s.sender = s.startInstanceSenders(false)
+	s.requests = nil
+	params := makeStartInstanceParams(c, s.controllerUUID, "quantal")
+	params.InstanceConfig.Tags[tags.JujuUnitsDeployed] = unitsDeployed
+	params.Placement = placement
+
+	_, err := env.StartInstance(params)
+	c.Assert(err, jc.ErrorIsNil)
+	expect.imageReference = &quantalImageReference
+	expect.diskSizeGB = 32
+	expect.osProfile = &linuxOsProfile
+	s.assertStartInstanceRequests(c, s.requests, expect)
+}
+
+func (s *environSuite) TestStartInstancePlacementInvalid(c *gc.C) {
+	env := s.openEnviron(c)
+	s.sender = nil
+	s.requests = nil
+	params := makeStartInstanceParams(c, s.controllerUUID, "quantal")
+	params.Placement = "zone=westus"
+	_, err := env.StartInstance(params)
+	c.Assert(err, gc.ErrorMatches, "unknown placement directive: zone=westus")
+	c.Assert(s.requests, gc.HasLen, 0)
+}
+
+func (s *environSuite) TestStartInstancePlacementControllerAvailabilitySet(c *gc.C) {
+	env := s.openEnviron(c)
+	s.sender = nil
+	s.requests = nil
+	params := makeStartInstanceParams(c, s.controllerUUID, "quantal")
+	params.InstanceConfig.Controller = &instancecfg.ControllerConfig{}
+	params.Placement = "aset=db"
+	_, err := env.StartInstance(params)
+	c.Assert(err, gc.ErrorMatches, "cannot place controller in a named availability set")
+	c.Assert(s.requests, gc.HasLen, 0)
+}
+
+func (s *environSuite) TestPrecheckInstancePlacement(c *gc.C) {
+	env := s.openEnviron(c)
+	for _, placement := range []string{"aset=db", "ppg=group1", "aset=db,ppg=group_1.a-b"} {
+		err := env.PrecheckInstance("quantal", constraints.Value{}, placement)
+		c.Check(err, jc.ErrorIsNil, gc.Commentf("placement %q", placement))
+	}
+}
+
+func (s *environSuite) TestPrecheckInstancePlacementInvalid(c *gc.C) {
+	env := s.openEnviron(c)
+	for placement, expect := range map[string]string{
+		"zone=westus":          "unknown placement directive: zone=westus",
+		"db":                   "unknown placement directive: db",
+		"aset=a,aset=b":        `duplicate "aset" placement directive`,
+		"ppg=":                 `ppg name "" not valid`,
+		"aset=db-":             `aset name "db-" not valid`,
+		"aset=juju-controller": `availability set "juju-controller" is reserved for controllers`,
+	} {
+		err := env.PrecheckInstance("quantal", constraints.Value{}, placement)
+		c.Check(err, gc.ErrorMatches, expect, gc.Commentf("placement %q", placement))
+	}
+}
+
+func (s *environSuite) TestStartInstanceStorageAccountSpillOver(c *gc.C) {
+	// The primary storage account is full, so a new one is created.
+	s.testStartInstanceStorageAccount(c, map[string]int{
+		"": 40,
+	}, "juju400d80004b1d0d06f0x1")
+}
+
+func (s *environSuite) TestStartInstanceStorageAccountLeastLoaded(c *gc.C) {
+	// The least-loaded storage account with capacity remaining is
+	// chosen; VMs without a storage account tag have their OS disks
+	// in the primary storage account.
+	s.testStartInstanceStorageAccount(c, map[string]int{
+		"":                         10,
+		storageAccountName:         30,
+		"juju400d80004b1d0d06f0x1": 40,
+		"juju400d80004b1d0d06f0x2": 39,
+		"juju400d80004b1d0d06f0x3": 5,
+	}, "juju400d80004b1d0d06f0x3")
+}
+
+func (s *environSuite) TestStartInstanceStorageAccountAllFull(c *gc.C) {
+	// The first unused storage account name is chosen.
+	s.testStartInstanceStorageAccount(c, map[string]int{
+		storageAccountName:         40,
+		"juju400d80004b1d0d06f0x1": 40,
+		"juju400d80004b1d0d06f0x3": 41,
+	}, "juju400d80004b1d0d06f0x2")
+}
+
+func (s *environSuite) testStartInstanceStorageAccount(c *gc.C, accountVMs map[string]int, expect string) {
+	var vms []compute.VirtualMachine
+	for accountName, n := range accountVMs {
+		for i := 0; i < n; i++ {
+			vm := compute.VirtualMachine{
+				Name: to.StringPtr(fmt.Sprintf("machine-%d", len(vms)+1)),
+			}
+			if accountName != "" {
+				vm.Tags = &map[string]*string{
+					"juju-storage-account": to.StringPtr(accountName),
+				}
+			}
+			vms = append(vms, vm)
+		}
+	}
+
+	env := s.openEnviron(c)
+	senders := s.startInstanceSenders(false)
+	senders[len(senders)-2] = s.virtualMachinesSender(vms...)
+	s.sender = senders
+	s.requests = nil
+	_, err := env.StartInstance(makeStartInstanceParams(c, s.controllerUUID, "quantal"))
+	c.Assert(err, jc.ErrorIsNil)
+	s.assertStartInstanceRequests(c, s.requests, assertStartInstanceRequestsParams{
+		imageReference:     &quantalImageReference,
+		diskSizeGB:         32,
+		osProfile:          &linuxOsProfile,
+		storageAccountName: expect,
+	})
+}
+
+const numExpectedStartInstanceRequests = 6
+
+type assertStartInstanceRequestsParams struct {
+	availabilitySetName         string
+	proximityPlacementGroupName string
+	imageReference              *compute.ImageReference
+	vmExtension                 *compute.VirtualMachineExtensionProperties
+	diskSizeGB                  int
+	osProfile                   *compute.OSProfile
+	policyRules                 []network.SecurityRule
+	securityGroupID             string
+	storageAccountName          string
+}
+
+func (s *environSuite) assertStartInstanceRequests(
+	c *gc.C,
+	requests []*http.Request,
+	args assertStartInstanceRequestsParams,
+) startInstanceRequests {
+	nsgId := `[resourceId('Microsoft.Network/networkSecurityGroups', 'juju-internal-nsg')]`
+	subnetSecurityGroupID := nsgId
+	if args.securityGroupID != "" {
+		subnetSecurityGroupID = args.securityGroupID
+	}
+	vmStorageAccountName := storageAccountName
+	if args.storageAccountName != "" {
+		vmStorageAccountName = args.storageAccountName
+	}
+	vmResourceTags := to.StringMap(s.vmTags)
+	vmResourceTags["juju-storage-account"] = vmStorageAccountName
+	vmResourceTags["juju-availability-set"] = args.availabilitySetName
+	if args.proximityPlacementGroupName != "" {
+		vmResourceTags["juju-proximity-placement-group"] = args.proximityPlacementGroupName
+	}
+	securityRules := []network.SecurityRule{{
+		Name: to.StringPtr("SSHInbound"),
+		Properties: &network.SecurityRulePropertiesFormat{
+			Description:              to.StringPtr("Allow SSH access to all machines"),
+			Protocol:                 network.TCP,
+			SourceAddressPrefix:      to.StringPtr("*"),
+			SourcePortRange:          to.StringPtr("*"),
+			DestinationAddressPrefix: to.StringPtr("*"),
+			DestinationPortRange:     to.StringPtr("22"),
+			Access:                   network.Allow,
+			Priority:                 to.Int32Ptr(100),
+			Direction:                network.Inbound,
+		},
+	}, {
+		Name: to.StringPtr("JujuAPIInbound"),
+		Properties: &network.SecurityRulePropertiesFormat{
+			Description:              to.StringPtr("Allow API connections to controller machines"),
+			Protocol:                 network.TCP,
+			SourceAddressPrefix:      to.StringPtr("*"),
+			SourcePortRange:          to.StringPtr("*"),
+			DestinationAddressPrefix: to.StringPtr("192.168.16.0/20"),
+			DestinationPortRange:     to.StringPtr("17777"),
 			Access:                   network.Allow,
 			Priority:                 to.Int32Ptr(101),
 			Direction:                network.Inbound,
 		},
 	}}
+	securityRules = append(securityRules, args.policyRules...)
 	subnets := []network.Subnet{{
 		Name: to.StringPtr("juju-internal-subnet"),
 		Properties: &network.SubnetPropertiesFormat{
 			AddressPrefix: to.StringPtr("192.168.0.0/20"),
 			NetworkSecurityGroup: &network.SecurityGroup{
-				ID: to.StringPtr(nsgId),
+				ID: to.StringPtr(subnetSecurityGroupID),
 			},
 		},
 	}, {
@@ -700,7 +1954,7 @@ func (s *environSuite) assertStartInstanceRequests(
 		Properties: &network.SubnetPropertiesFormat{
 			AddressPrefix: to.StringPtr("192.168.16.0/20"),
 			NetworkSecurityGroup: &network.SecurityGroup{
-				ID: to.StringPtr(nsgId),
+				ID: to.StringPtr(subnetSecurityGroupID),
 			},
 		},
 	}}
@@ -716,7 +1970,7 @@ func (s *environSuite) assertStartInstanceRequests(
 		subnetName,
 	)
 
-	publicIPAddressId := `[resourceId('Microsoft.Network/publicIPAddresses', 'machine-0-public-ip')]`
+	publicIPAddressId := `[resourceId('Microsoft.Network/publicIPAddresses', 'machine-0-public-ip-c0ffee00')]`
 
 	ipConfigurations := []network.InterfaceIPConfiguration{{
 		Name: to.StringPtr("primary"),
@@ -731,7 +1985,7 @@ func (s *environSuite) assertStartInstanceRequests(
 		},
 	}}
 
-	nicId := `[resourceId('Microsoft.Network/networkInterfaces', 'machine-0-primary')]`
+	nicId := `[resourceId('Microsoft.Network/networkInterfaces', 'machine-0-primary-c0ffee00')]`
 	nics := []compute.NetworkInterfaceReference{{
 		ID: to.StringPtr(nicId),
 		Properties: &compute.NetworkInterfaceReferenceProperties{
@@ -740,7 +1994,7 @@ func (s *environSuite) assertStartInstanceRequests(
 	}}
 	vmDependsOn := []string{
 		nicId,
-		`[resourceId('Microsoft.Storage/storageAccounts', '` + storageAccountName + `')]`,
+		`[resourceId('Microsoft.Storage/storageAccounts', '` + vmStorageAccountName + `')]`,
 	}
 
 	addressPrefixes := []string{"192.168.0.0/20", "192.168.16.0/20"}
@@ -767,7 +2021,7 @@ func (s *environSuite) assertStartInstanceRequests(
 	}, {
 		APIVersion: storage.APIVersion,
 		Type:       "Microsoft.Storage/storageAccounts",
-		Name:       storageAccountName,
+		Name:       vmStorageAccountName,
 		Location:   "westus",
 		Tags:       to.StringMap(s.envTags),
 		StorageSku: &storage.Sku{
@@ -775,53 +2029,99 @@ func (s *environSuite) assertStartInstanceRequests(
 		},
 	}}
 
+	if args.securityGroupID != "" {
+		// The existing network security group is referenced
+		// by the subnets, and not created.
+		templateResources = templateResources[1:]
+		templateResources[0].DependsOn = nil
+	}
+
+	vmAPIVersion := compute.APIVersion
+	var proximityPlacementGroupId string
+	if args.proximityPlacementGroupName != "" {
+		vmAPIVersion = "2018-04-01"
+		proximityPlacementGroupId = fmt.Sprintf(
+			`[resourceId('Microsoft.Compute/proximityPlacementGroups','%s')]`,
+			args.proximityPlacementGroupName,
+		)
+		templateResources = append(templateResources, armtemplates.Resource{
+			APIVersion: "2018-04-01",
+			Type:       "Microsoft.Compute/proximityPlacementGroups",
+			Name:       args.proximityPlacementGroupName,
+			Location:   "westus",
+			Tags:       to.StringMap(s.envTags),
+			Properties: map[string]interface{}{
+				"proximityPlacementGroupType": "Standard",
+			},
+		})
+	}
+
 	var availabilitySetSubResource *compute.SubResource
 	if args.availabilitySetName != "" {
 		availabilitySetId := fmt.Sprintf(
 			`[resourceId('Microsoft.Compute/availabilitySets','%s')]`,
 			args.availabilitySetName,
 		)
-		templateResources = append(templateResources, armtemplates.Resource{
+		availabilitySet := armtemplates.Resource{
 			APIVersion: compute.APIVersion,
 			Type:       "Microsoft.Compute/availabilitySets",
 			Name:       args.availabilitySetName,
 			Location:   "westus",
 			Tags:       to.StringMap(s.envTags),
-		})
+		}
+		if proximityPlacementGroupId != "" {
+			availabilitySet.APIVersion = "2018-04-01"
+			availabilitySet.Properties = map[string]interface{}{
+				"proximityPlacementGroup": map[string]interface{}{
+					"id": proximityPlacementGroupId,
+				},
+			}
+			availabilitySet.DependsOn = []string{proximityPlacementGroupId}
+		}
+		templateResources = append(templateResources, availabilitySet)
 		availabilitySetSubResource = &compute.SubResource{
 			ID: to.StringPtr(availabilitySetId),
 		}
 		vmDependsOn = append([]string{availabilitySetId}, vmDependsOn...)
 	}
+	if proximityPlacementGroupId != "" {
+		vmDependsOn = append([]string{proximityPlacementGroupId}, vmDependsOn...)
+	}
 
 	templateResources = append(templateResources, []armtemplates.Resource{{
 		APIVersion: network.APIVersion,
 		Type:       "Microsoft.Network/publicIPAddresses",
-		Name:       "machine-0-public-ip",
+		Name:       "machine-0-public-ip-c0ffee00",
 		Location:   "westus",
 		Tags:       to.StringMap(s.vmTags),
 		Properties: &network.PublicIPAddressPropertiesFormat{
 			PublicIPAllocationMethod: network.Dynamic,
+			DNSSettings: &network.PublicIPAddressDNSSettings{
+				DomainNameLabel: to.StringPtr("juju-deadbeef-machine-0"),
+			},
 		},
 	}, {
 		APIVersion: network.APIVersion,
 		Type:       "Microsoft.Network/networkInterfaces",
-		Name:       "machine-0-primary",
+		Name:       "machine-0-primary-c0ffee00",
 		Location:   "westus",
 		Tags:       to.StringMap(s.vmTags),
 		Properties: &network.InterfacePropertiesFormat{
 			IPConfigurations: &ipConfigurations,
+			DNSSettings: &network.InterfaceDNSSettings{
+				InternalDNSNameLabel: to.StringPtr("machine-0"),
+			},
 		},
 		DependsOn: []string{
 			publicIPAddressId,
 			`[resourceId('Microsoft.Network/virtualNetworks', 'juju-internal-network')]`,
 		},
 	}, {
-		APIVersion: compute.APIVersion,
+		APIVersion: vmAPIVersion,
 		Type:       "Microsoft.Compute/virtualMachines",
 		Name:       "machine-0",
 		Location:   "westus",
-		Tags:       to.StringMap(s.vmTags),
+		Tags:       vmResourceTags,
 		Properties: &compute.VirtualMachineProperties{
 			HardwareProfile: &compute.HardwareProfile{
 				VMSize: "Standard_D1",
@@ -835,7 +2135,7 @@ func (s *environSuite) assertStartInstanceRequests(
 					Vhd: &compute.VirtualHardDisk{
 						URI: to.StringPtr(fmt.Sprintf(
 							`[concat(reference(resourceId('Microsoft.Storage/storageAccounts', '%s'), '%s').primaryEndpoints.blob, 'osvhds/machine-0.vhd')]`,
-							storageAccountName, storage.APIVersion,
+							vmStorageAccountName, storage.APIVersion,
 						)),
 					},
 					DiskSizeGB: to.Int32Ptr(int32(args.diskSizeGB)),
@@ -847,6 +2147,16 @@ func (s *environSuite) assertStartInstanceRequests(
 		},
 		DependsOn: vmDependsOn,
 	}}...)
+	if proximityPlacementGroupId != "" {
+		vmResource := &templateResources[len(templateResources)-1]
+		vmResource.Properties = struct {
+			*compute.VirtualMachineProperties
+			ProximityPlacementGroup *compute.SubResource `json:"proximityPlacementGroup"`
+		}{
+			vmResource.Properties.(*compute.VirtualMachineProperties),
+			&compute.SubResource{ID: to.StringPtr(proximityPlacementGroupId)},
+		}
+	}
 	if args.vmExtension != nil {
 		templateResources = append(templateResources, armtemplates.Resource{
 			APIVersion: compute.APIVersion,
@@ -877,17 +2187,23 @@ func (s *environSuite) assertStartInstanceRequests(
 		// there should be no image query.
 		c.Assert(requests, gc.HasLen, numExpectedStartInstanceRequests-1)
 		c.Assert(requests[0].Method, gc.Equals, "GET") // vmSizes
-		c.Assert(requests[1].Method, gc.Equals, "PUT") // create deployment
+		c.Assert(requests[1].Method, gc.Equals, "GET") // NICs
+		c.Assert(requests[2].Method, gc.Equals, "GET") // public IPs
+		c.Assert(requests[3].Method, gc.Equals, "GET") // virtual machines
+		c.Assert(requests[4].Method, gc.Equals, "PUT") // create deployment
 		startInstanceRequests.vmSizes = requests[0]
-		startInstanceRequests.deployment = requests[1]
+		startInstanceRequests.deployment = requests[4]
 	} else {
 		c.Assert(requests, gc.HasLen, numExpectedStartInstanceRequests)
 		c.Assert(requests[0].Method, gc.Equals, "GET") // vmSizes
 		c.Assert(requests[1].Method, gc.Equals, "GET") // skus
-		c.Assert(requests[2].Method, gc.Equals, "PUT") // create deployment
+		c.Assert(requests[2].Method, gc.Equals, "GET") // NICs
+		c.Assert(requests[3].Method, gc.Equals, "GET") // public IPs
+		c.Assert(requests[4].Method, gc.Equals, "GET") // virtual machines
+		c.Assert(requests[5].Method, gc.Equals, "PUT") // create deployment
 		startInstanceRequests.vmSizes = requests[0]
 		startInstanceRequests.skus = requests[1]
-		startInstanceRequests.deployment = requests[2]
+		startInstanceRequests.deployment = requests[5]
 	}
 
 	// Marshal/unmarshal the deployment we expect, so it's in map form.
@@ -926,45 +2242,284 @@ type startInstanceRequests struct {
 	deployment *http.Request
 }
 
-func (s *environSuite) TestBootstrap(c *gc.C) {
-	defer envtesting.DisableFinishBootstrap()()
+func (s *environSuite) TestBootstrap(c *gc.C) {
+	defer envtesting.DisableFinishBootstrap()()
+
+	ctx := envtesting.BootstrapContext(c)
+	env := prepareForBootstrap(c, ctx, s.provider, &s.sender)
+
+	s.sender = s.initResourceGroupSenders()
+	s.sender = append(s.sender, s.startInstanceSenders(true)...)
+	s.requests = nil
+	result, err := env.Bootstrap(
+		ctx, environs.BootstrapParams{
+			ControllerConfig: testing.FakeControllerConfig(),
+			AvailableTools:   makeToolsList("quantal"),
+			BootstrapSeries:  "quantal",
+		},
+	)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(result.Arch, gc.Equals, "amd64")
+	c.Assert(result.Series, gc.Equals, "quantal")
+
+	c.Assert(len(s.requests), gc.Equals, numExpectedStartInstanceRequests+1)
+	s.vmTags[tags.JujuIsController] = to.StringPtr("true")
+	s.assertStartInstanceRequests(c, s.requests[1:], assertStartInstanceRequestsParams{
+		availabilitySetName: "juju-controller",
+		imageReference:      &quantalImageReference,
+		diskSizeGB:          32,
+		osProfile:           &linuxOsProfile,
+	})
+}
+
+func (s *environSuite) openIdentifyingEnviron(c *gc.C, compute map[string]string) environs.Environ {
+	imdsSender := azuretesting.NewSenderWithValue(compute)
+	imdsSender.PathPattern = "^/metadata/instance/compute$"
+	provider := newProvider(c, azure.ProviderConfig{
+		Sender:                            azuretesting.NewSerialSender(&s.sender),
+		RandomWindowsAdminPassword:        func() string { return "sorandom" },
+		InteractiveCreateServicePrincipal: azureauth.InteractiveCreateServicePrincipal,
+		InstanceMetadataSender:            imdsSender,
+	})
+	return openEnviron(c, provider, &s.sender)
+}
+
+func (s *environSuite) TestIdentifyInstance(c *gc.C) {
+	env := s.openIdentifyingEnviron(c, map[string]string{
+		"name":              "machine-0",
+		"location":          "West US",
+		"resourceGroupName": "JUJU-TESTENV-MODEL-" + testing.ModelTag.Id(),
+		"subscriptionId":    fakeSubscriptionId,
+	})
+	identity, err := env.(environs.InstanceIdentifier).IdentifyInstance()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(identity, jc.DeepEquals, environs.InstanceIdentity{
+		InstanceId: "machine-0",
+		Region:     "westus",
+	})
+}
+
+func (s *environSuite) TestIdentifyInstanceOtherResourceGroup(c *gc.C) {
+	env := s.openIdentifyingEnviron(c, map[string]string{
+		"name":              "machine-0",
+		"location":          "westus",
+		"resourceGroupName": "juju-othermodel-model-" + testing.ModelTag.Id(),
+		"subscriptionId":    fakeSubscriptionId,
+	})
+	_, err := env.(environs.InstanceIdentifier).IdentifyInstance()
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+}
+
+func (s *environSuite) TestIdentifyInstanceOtherSubscription(c *gc.C) {
+	env := s.openIdentifyingEnviron(c, map[string]string{
+		"name":              "machine-0",
+		"location":          "westus",
+		"resourceGroupName": "juju-testenv-model-" + testing.ModelTag.Id(),
+		"subscriptionId":    "33333333-3333-3333-3333-333333333333",
+	})
+	_, err := env.(environs.InstanceIdentifier).IdentifyInstance()
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+	c.Assert(err, gc.ErrorMatches, `instance "machine-0" in subscription .* not found`)
+}
+
+func (s *environSuite) TestIdentifyInstanceError(c *gc.C) {
+	imdsSender := mocks.NewSender()
+	imdsSender.AppendResponse(mocks.NewResponseWithStatus("internal error", http.StatusInternalServerError))
+	provider := newProvider(c, azure.ProviderConfig{
+		Sender:                            azuretesting.NewSerialSender(&s.sender),
+		RandomWindowsAdminPassword:        func() string { return "sorandom" },
+		InteractiveCreateServicePrincipal: azureauth.InteractiveCreateServicePrincipal,
+		InstanceMetadataSender:            imdsSender,
+	})
+	env := openEnviron(c, provider, &s.sender)
+	_, err := env.(environs.InstanceIdentifier).IdentifyInstance()
+	c.Assert(err, gc.ErrorMatches, "reading compute metadata: .*")
+}
+
+func (s *environSuite) TestAllInstancesResourceGroupNotFound(c *gc.C) {
+	env := s.openEnviron(c)
+	sender := mocks.NewSender()
+	sender.AppendResponse(mocks.NewResponseWithStatus(
+		"resource group not found", http.StatusNotFound,
+	))
+	s.sender = azuretesting.Senders{sender}
+	_, err := env.AllInstances()
+	c.Assert(err, jc.ErrorIsNil)
+}
+
+func (s *environSuite) TestSetCloudSpec(c *gc.C) {
+	env := s.openEnviron(c)
+	spec := fakeCloudSpec()
+	credential := cloud.NewCredential(
+		"service-principal-secret",
+		map[string]string{
+			"application-id":       fakeApplicationId,
+			"subscription-id":      fakeSubscriptionId,
+			"application-password": "rotated",
+		},
+	)
+	spec.Credential = &credential
+	err := env.(environs.CloudSpecSetter).SetCloudSpec(spec)
+	c.Assert(err, jc.ErrorIsNil)
+
+	// The next request must acquire a new token
+	// using the updated credential.
+	s.sender = azuretesting.Senders{
+		discoverAuthSender(),
+		tokenRefreshSender(),
+		s.makeSender(".*/deployments", resources.DeploymentListResult{}),
+	}
+	_, err = env.AllInstances()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(s.sender, gc.HasLen, 0)
+}
+
+func (s *environSuite) TestSetCloudSpecChangeSubscription(c *gc.C) {
+	env := s.openEnviron(c)
+	spec := fakeCloudSpec()
+	credential := cloud.NewCredential(
+		"service-principal-secret",
+		map[string]string{
+			"application-id":       fakeApplicationId,
+			"subscription-id":      "another-subscription",
+			"application-password": "opensezme",
+		},
+	)
+	spec.Credential = &credential
+	err := env.(environs.CloudSpecSetter).SetCloudSpec(spec)
+	c.Assert(err, gc.ErrorMatches, "changing subscription not supported")
+}
+
+func (s *environSuite) TestSetCloudSpecChangeEndpoint(c *gc.C) {
+	env := s.openEnviron(c)
+	spec := fakeCloudSpec()
+	spec.Endpoint = "https://api.elsewhere.invalid"
+	err := env.(environs.CloudSpecSetter).SetCloudSpec(spec)
+	c.Assert(err, gc.ErrorMatches, "changing endpoints not supported")
+}
+
+func (s *environSuite) TestCredentialInvalidator(c *gc.C) {
+	env := s.openEnviron(c)
+	var reasons []string
+	env.(environs.CredentialInvalidatorSetter).SetCredentialInvalidator(func(reason string) error {
+		reasons = append(reasons, reason)
+		return nil
+	})
+
+	unauthorizedSender := func() *mocks.Sender {
+		sender := mocks.NewSender()
+		sender.AppendResponse(mocks.NewResponseWithStatus(
+			"401 Unauthorized", http.StatusUnauthorized,
+		))
+		return sender
+	}
+	s.sender = azuretesting.Senders{unauthorizedSender()}
+	_, err := env.AllInstances()
+	c.Assert(err, gc.NotNil)
+	c.Assert(reasons, jc.DeepEquals, []string{
+		"Azure rejected the credential: 401 Unauthorized",
+	})
+
+	// The credential is invalidated only once.
+	s.sender = azuretesting.Senders{unauthorizedSender()}
+	_, err = env.AllInstances()
+	c.Assert(err, gc.NotNil)
+	c.Assert(reasons, gc.HasLen, 1)
+
+	// Once the credential is updated, it may be invalidated again.
+	err = env.(environs.CloudSpecSetter).SetCloudSpec(fakeCloudSpec())
+	c.Assert(err, jc.ErrorIsNil)
+	s.sender = azuretesting.Senders{
+		discoverAuthSender(),
+		tokenRefreshSender(),
+		unauthorizedSender(),
+	}
+	_, err = env.AllInstances()
+	c.Assert(err, gc.NotNil)
+	c.Assert(reasons, gc.HasLen, 2)
+}
+
+func (s *environSuite) TestCredentialInvalidatorTokenRefresh(c *gc.C) {
+	env := s.openEnviron(c)
+	var reasons []string
+	env.(environs.CredentialInvalidatorSetter).SetCredentialInvalidator(func(reason string) error {
+		reasons = append(reasons, reason)
+		return nil
+	})
+	err := env.(environs.CloudSpecSetter).SetCloudSpec(fakeCloudSpec())
+	c.Assert(err, jc.ErrorIsNil)
 
-	ctx := envtesting.BootstrapContext(c)
-	env := prepareForBootstrap(c, ctx, s.provider, &s.sender)
+	// The service principal's secret has expired,
+	// so the token cannot be refreshed.
+	s.sender = azuretesting.Senders{
+		discoverAuthSender(),
+		tokenRefreshErrorSender(http.StatusUnauthorized, "invalid_client"),
+	}
+	_, err = env.AllInstances()
+	c.Assert(err, gc.NotNil)
+	c.Assert(reasons, jc.DeepEquals, []string{
+		"Azure rejected the credential: 401 Unauthorized (invalid_client)",
+	})
+}
 
-	s.sender = s.initResourceGroupSenders()
-	s.sender = append(s.sender, s.startInstanceSenders(true)...)
-	s.requests = nil
-	result, err := env.Bootstrap(
-		ctx, environs.BootstrapParams{
-			ControllerConfig: testing.FakeControllerConfig(),
-			AvailableTools:   makeToolsList("quantal"),
-			BootstrapSeries:  "quantal",
-		},
-	)
+func (s *environSuite) TestCredentialInvalidatorTokenRefreshOtherError(c *gc.C) {
+	env := s.openEnviron(c)
+	var reasons []string
+	env.(environs.CredentialInvalidatorSetter).SetCredentialInvalidator(func(reason string) error {
+		reasons = append(reasons, reason)
+		return nil
+	})
+	err := env.(environs.CloudSpecSetter).SetCloudSpec(fakeCloudSpec())
 	c.Assert(err, jc.ErrorIsNil)
-	c.Assert(result.Arch, gc.Equals, "amd64")
-	c.Assert(result.Series, gc.Equals, "quantal")
 
-	c.Assert(len(s.requests), gc.Equals, numExpectedStartInstanceRequests+1)
-	s.vmTags[tags.JujuIsController] = to.StringPtr("true")
-	s.assertStartInstanceRequests(c, s.requests[1:], assertStartInstanceRequestsParams{
-		availabilitySetName: "juju-controller",
-		imageReference:      &quantalImageReference,
-		diskSizeGB:          32,
-		osProfile:           &linuxOsProfile,
-	})
+	// A token refresh failing for a reason other than
+	// the client being rejected does not invalidate
+	// the credential.
+	s.sender = azuretesting.Senders{
+		discoverAuthSender(),
+		tokenRefreshErrorSender(http.StatusBadRequest, "invalid_request"),
+	}
+	_, err = env.AllInstances()
+	c.Assert(err, gc.NotNil)
+	c.Assert(reasons, gc.HasLen, 0)
 }
 
-func (s *environSuite) TestAllInstancesResourceGroupNotFound(c *gc.C) {
+func (s *environSuite) TestCredentialInvalidatorForbidden(c *gc.C) {
 	env := s.openEnviron(c)
-	sender := mocks.NewSender()
-	sender.AppendResponse(mocks.NewResponseWithStatus(
-		"resource group not found", http.StatusNotFound,
+	var reasons []string
+	env.(environs.CredentialInvalidatorSetter).SetCredentialInvalidator(func(reason string) error {
+		reasons = append(reasons, reason)
+		return nil
+	})
+
+	// A policy denying an operation does not
+	// invalidate the credential.
+	forbiddenSender := mocks.NewSender()
+	forbiddenSender.AppendResponse(mocks.NewResponseWithBodyAndStatus(
+		mocks.NewBody(`{"error": {
+			"code": "RequestDisallowedByPolicy",
+			"message": "Resource 'machine-0' was disallowed by policy."
+		}}`),
+		http.StatusForbidden,
+		"403 Forbidden",
 	))
-	s.sender = azuretesting.Senders{sender}
+	s.sender = azuretesting.Senders{forbiddenSender}
 	_, err := env.AllInstances()
-	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(err, gc.NotNil)
+	c.Assert(reasons, gc.HasLen, 0)
+}
+
+func tokenRefreshErrorSender(status int, code string) *azuretesting.MockSender {
+	tokenSender := mocks.NewSender()
+	tokenSender.AppendResponse(mocks.NewResponseWithBodyAndStatus(
+		mocks.NewBody(fmt.Sprintf(`{"error": %q}`, code)),
+		status,
+		fmt.Sprintf("%d %s", status, http.StatusText(status)),
+	))
+	return &azuretesting.MockSender{
+		Sender:      tokenSender,
+		PathPattern: ".*/oauth2/token",
+	}
 }
 
 func (s *environSuite) TestStopInstancesNotFound(c *gc.C) {
@@ -998,12 +2553,21 @@ func (s *environSuite) TestStopInstances(c *gc.C) {
 	nic0IPConfiguration.Properties.PublicIPAddress = &network.PublicIPAddress{}
 	nic0 := makeNetworkInterface("nic-0", "machine-0", nic0IPConfiguration)
 
+	// volume-0 should be deleted along with machine-0.
+	vm := &compute.VirtualMachine{
+		Name: to.StringPtr("machine-0"),
+		Tags: &map[string]*string{
+			"juju-delete-on-termination": to.StringPtr("volume-0"),
+		},
+	}
+
 	s.sender = azuretesting.Senders{
 		s.makeSender(".*/deployments/machine-0/cancel", nil), // POST
 		s.storageAccountSender(),
 		s.storageAccountKeysSender(),
 		s.networkInterfacesSender(nic0),
 		s.publicIPAddressesSender(makePublicIPAddress("pip-0", "machine-0", "1.2.3.4")),
+		s.makeSender(".*/virtualMachines/machine-0", vm),                                                  // GET
 		s.makeSender(".*/virtualMachines/machine-0", nil),                                                 // DELETE
 		s.makeSender(".*/networkSecurityGroups/juju-internal-nsg", nsg),                                   // GET
 		s.makeSender(".*/networkSecurityGroups/juju-internal-nsg/securityRules/machine-0-80", nil),        // DELETE
@@ -1016,18 +2580,158 @@ func (s *environSuite) TestStopInstances(c *gc.C) {
 	c.Assert(err, jc.ErrorIsNil)
 
 	s.storageClient.CheckCallNames(c,
-		"NewClient", "DeleteBlobIfExists",
+		"NewClient", "DeleteBlobIfExists", "DeleteBlobIfExists",
 	)
 	s.storageClient.CheckCall(c, 1, "DeleteBlobIfExists", "osvhds", "machine-0")
+	s.storageClient.CheckCall(c, 2, "DeleteBlobIfExists", "datavhds", "volume-0.vhd")
+
+	// The requests are traced under the machine's tag, not
+	// the instance ID.
+	var buf bytes.Buffer
+	tracer := s.provider.(environs.RequestTracer)
+	err = tracer.WriteRequestTrace(&buf, testing.ModelTag.Id(), "machine-0")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(buf.String(), gc.Matches, "(?s)MODEL .* machine-0.stop-instance.c0ffee00 .* POST .*/deployments/machine-0/cancel .*")
+}
+
+func (s *environSuite) TestDeleteReplacement(c *gc.C) {
+	env := s.openEnviron(c)
+	vm := &compute.VirtualMachine{
+		Name: to.StringPtr("machine-0"),
+		Tags: &map[string]*string{
+			"juju-delete-on-termination": to.StringPtr("volume-0"),
+		},
+	}
+	s.sender = azuretesting.Senders{
+		s.makeSender(".*/deployments/machine-0/cancel", nil), // POST
+		s.storageAccountSender(),
+		s.storageAccountKeysSender(),
+		s.networkInterfacesSender(),
+		s.publicIPAddressesSender(),
+		s.makeSender(".*/virtualMachines/machine-0", vm),                                // GET
+		s.makeSender(".*/virtualMachines/machine-0", nil),                               // DELETE
+		s.makeSender(".*/networkSecurityGroups/juju-internal-nsg", makeSecurityGroup()), // GET
+		s.makeSender(".*/deployments/machine-0", nil),                                   // DELETE
+	}
+	err := env.(environs.InstanceReplacer).DeleteReplacement("machine-0")
+	c.Assert(err, jc.ErrorIsNil)
+
+	// The OS disk is deleted, but the machine's
+	// existing data disk is not.
+	s.storageClient.CheckCallNames(c, "NewClient", "DeleteBlobIfExists")
+	s.storageClient.CheckCall(c, 1, "DeleteBlobIfExists", "osvhds", "machine-0")
+}
+
+func (s *environSuite) TestStopInstancesSpilledStorageAccount(c *gc.C) {
+	env := s.openEnviron(c)
+
+	// machine-0's OS disk is in a storage account other than the
+	// model's primary storage account, while its data disks are
+	// in the primary storage account.
+	const otherAccountName = "juju400d80004b1d0d06f0x1"
+	vm := &compute.VirtualMachine{
+		Name: to.StringPtr("machine-0"),
+		Tags: &map[string]*string{
+			"juju-delete-on-termination": to.StringPtr("volume-0"),
+			"juju-storage-account":       to.StringPtr(otherAccountName),
+		},
+	}
+	otherAccount := *s.storageAccount
+	otherAccount.Name = to.StringPtr(otherAccountName)
+
+	s.sender = azuretesting.Senders{
+		s.makeSender(".*/deployments/machine-0/cancel", nil), // POST
+		s.storageAccountSender(),
+		s.storageAccountKeysSender(),
+		s.networkInterfacesSender(),
+		s.publicIPAddressesSender(),
+		s.makeSender(".*/virtualMachines/machine-0", vm),  // GET
+		s.makeSender(".*/virtualMachines/machine-0", nil), // DELETE
+		s.makeSender(".*/storageAccounts/"+otherAccountName, &otherAccount),
+		s.storageAccountKeysSender(),
+		s.makeSender(".*/networkSecurityGroups/juju-internal-nsg", makeSecurityGroup()),
+		s.makeSender(".*/deployments/machine-0", nil), // DELETE
+	}
+	err := env.StopInstances("machine-0")
+	c.Assert(err, jc.ErrorIsNil)
+
+	s.storageClient.CheckCallNames(c,
+		"NewClient", "NewClient", "DeleteBlobIfExists", "DeleteBlobIfExists",
+	)
+	calls := s.storageClient.Calls()
+	c.Assert(calls[0].Args[0], gc.Equals, "my-storage-account")
+	c.Assert(calls[1].Args[0], gc.Equals, otherAccountName)
+	s.storageClient.CheckCall(c, 2, "DeleteBlobIfExists", "osvhds", "machine-0")
+	s.storageClient.CheckCall(c, 3, "DeleteBlobIfExists", "datavhds", "volume-0.vhd")
+}
+
+func (s *environSuite) TestStopInstancesShutdownGracePeriod(c *gc.C) {
+	env := s.openEnviron(c, testing.Attrs{"shutdown-grace-period": "5m"})
+	s.requests = nil
+	s.sender = azuretesting.Senders{
+		s.makeSender(".*/deployments/machine-0/cancel", nil),         // POST
+		s.makeSender(".*/virtualMachines/machine-0/deallocate", nil), // POST
+		s.storageAccountSender(),
+		s.storageAccountKeysSender(),
+		s.networkInterfacesSender(),
+		s.publicIPAddressesSender(),
+		s.makeSender(".*/virtualMachines/machine-0", &compute.VirtualMachine{}), // GET
+		s.makeSender(".*/virtualMachines/machine-0", nil),                       // DELETE
+		s.makeSender(".*/networkSecurityGroups/juju-internal-nsg", makeSecurityGroup()),
+		s.makeSender(".*/deployments/machine-0", nil), // DELETE
+	}
+	err := env.StopInstances("machine-0")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(s.requests, gc.HasLen, len(s.sender))
+	c.Assert(s.requests[1].Method, gc.Equals, "POST")
+	c.Assert(s.requests[1].URL.Path, jc.HasSuffix, "/virtualMachines/machine-0/deallocate")
+}
+
+func (s *environSuite) TestSuspendInstances(c *gc.C) {
+	env := s.openEnviron(c)
+	s.requests = nil
+	s.sender = azuretesting.Senders{
+		s.makeSender(".*/virtualMachines/machine-0/deallocate", nil), // POST
+	}
+	err := env.(environs.InstanceSuspender).SuspendInstances("machine-0")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(s.requests, gc.HasLen, 1)
+	c.Assert(s.requests[0].Method, gc.Equals, "POST")
+	c.Assert(s.requests[0].URL.Path, jc.HasSuffix, "/virtualMachines/machine-0/deallocate")
+}
+
+func (s *environSuite) TestSuspendInstancesNotFound(c *gc.C) {
+	env := s.openEnviron(c)
+	sender := mocks.NewSender()
+	sender.AppendResponse(mocks.NewResponseWithStatus(
+		"vm not found", http.StatusNotFound,
+	))
+	s.sender = azuretesting.Senders{sender}
+	err := env.(environs.InstanceSuspender).SuspendInstances("machine-0")
+	c.Assert(err, gc.ErrorMatches, `deallocating virtual machine "machine-0": instance "machine-0" not found`)
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+}
+
+func (s *environSuite) TestResumeInstances(c *gc.C) {
+	env := s.openEnviron(c)
+	s.requests = nil
+	s.sender = azuretesting.Senders{
+		s.makeSender(".*/virtualMachines/machine-0/start", nil), // POST
+	}
+	err := env.(environs.InstanceSuspender).ResumeInstances("machine-0")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(s.requests, gc.HasLen, 1)
+	c.Assert(s.requests[0].Method, gc.Equals, "POST")
+	c.Assert(s.requests[0].URL.Path, jc.HasSuffix, "/virtualMachines/machine-0/start")
 }
 
 func (s *environSuite) TestStopInstancesMultiple(c *gc.C) {
 	env := s.openEnviron(c)
 
-	vmDeleteSender0 := s.makeSender(".*/virtualMachines/machine-[01]", nil)
-	vmDeleteSender1 := s.makeSender(".*/virtualMachines/machine-[01]", nil)
-	vmDeleteSender0.SetError(errors.New("blargh"))
-	vmDeleteSender1.SetError(errors.New("blargh"))
+	vmSender0 := s.makeSender(".*/virtualMachines/machine-[01]", nil)
+	vmSender1 := s.makeSender(".*/virtualMachines/machine-[01]", nil)
+	vmSender0.SetError(errors.New("blargh"))
+	vmSender1.SetError(errors.New("blargh"))
 
 	s.sender = azuretesting.Senders{
 		s.makeSender(".*/deployments/machine-[01]/cancel", nil), // POST
@@ -1040,8 +2744,8 @@ func (s *environSuite) TestStopInstancesMultiple(c *gc.C) {
 		s.networkInterfacesSender(),
 		s.publicIPAddressesSender(),
 
-		vmDeleteSender0,
-		vmDeleteSender1,
+		vmSender0,
+		vmSender1,
 	}
 	err := env.StopInstances("machine-0", "machine-1")
 	c.Assert(err, gc.ErrorMatches, `deleting instance "machine-[01]":.*blargh`)
@@ -1111,13 +2815,226 @@ func (s *environSuite) TestStopInstancesStorageAccountKeysError(c *gc.C) {
 	c.Assert(err, gc.ErrorMatches, "getting storage account key:.*blargh")
 }
 
+func (s *environSuite) TestRotateStorageCredentials(c *gc.C) {
+	env := s.openEnviron(c)
+	s.setStorageAccountKeys("key-1", "key-2")
+	s.requests = nil
+	s.sender = azuretesting.Senders{
+		s.storageAccountSender(),
+		s.storageAccountKeysSender(),
+		s.storageAccountRegenerateKeySender("key-1", "key-2-new"),
+		s.storageAccountRegenerateKeySender("key-1-new", "key-2-new"),
+	}
+	var recorded []map[string]interface{}
+	err := env.(environs.StorageCredentialRotator).RotateStorageCredentials(
+		func(attrs map[string]interface{}) error {
+			// The key in use must not be regenerated
+			// until the new key has been recorded.
+			c.Check(s.requests, gc.HasLen, 3)
+			recorded = append(recorded, attrs)
+			return nil
+		},
+	)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(recorded, jc.DeepEquals, []map[string]interface{}{{
+		"storage-account-key-name": "key2",
+	}})
+
+	c.Assert(s.requests, gc.HasLen, 4)
+	for i, keyName := range []string{"key2", "key1"} {
+		req := s.requests[2+i]
+		c.Assert(req.Method, gc.Equals, "POST")
+		c.Assert(req.URL.Path, jc.HasSuffix, "/storageAccounts/"+storageAccountName+"/regenerateKey")
+		var params storage.AccountRegenerateKeyParameters
+		unmarshalRequestBody(c, req, &params)
+		c.Assert(to.String(params.KeyName), gc.Equals, keyName)
+	}
+
+	// The environ uses the new key from now on.
+	s.sender = nil
+	s.assertStorageClientKey(c, env, "key-2-new")
+}
+
+func (s *environSuite) TestRotateStorageCredentialsFromKey2(c *gc.C) {
+	env := s.openEnviron(c, testing.Attrs{"storage-account-key-name": "key2"})
+	s.setStorageAccountKeys("key-1", "key-2")
+	s.requests = nil
+	s.sender = azuretesting.Senders{
+		s.storageAccountSender(),
+		s.storageAccountKeysSender(),
+		s.storageAccountRegenerateKeySender("key-1-new", "key-2"),
+		s.storageAccountRegenerateKeySender("key-1-new", "key-2-new"),
+	}
+	var recorded []map[string]interface{}
+	err := env.(environs.StorageCredentialRotator).RotateStorageCredentials(
+		func(attrs map[string]interface{}) error {
+			recorded = append(recorded, attrs)
+			return nil
+		},
+	)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(recorded, jc.DeepEquals, []map[string]interface{}{{
+		"storage-account-key-name": "key1",
+	}})
+	s.sender = nil
+	s.assertStorageClientKey(c, env, "key-1-new")
+}
+
+func (s *environSuite) TestRotateStorageCredentialsRecordError(c *gc.C) {
+	env := s.openEnviron(c)
+	s.setStorageAccountKeys("key-1", "key-2")
+	s.requests = nil
+	s.sender = azuretesting.Senders{
+		s.storageAccountSender(),
+		s.storageAccountKeysSender(),
+		s.storageAccountRegenerateKeySender("key-1", "key-2-new"),
+	}
+	err := env.(environs.StorageCredentialRotator).RotateStorageCredentials(
+		func(map[string]interface{}) error {
+			return errors.New("blargh")
+		},
+	)
+	c.Assert(err, gc.ErrorMatches, `recording storage account key "key2": blargh`)
+
+	// The key in use is not regenerated.
+	c.Assert(s.requests, gc.HasLen, 3)
+}
+
+func (s *environSuite) TestRotateStorageCredentialsRegenerateError(c *gc.C) {
+	env := s.openEnviron(c)
+	s.setStorageAccountKeys("key-1", "key-2")
+	errorSender := s.storageAccountRegenerateKeySender("key-1", "key-2")
+	errorSender.SetError(errors.New("blargh"))
+	s.sender = azuretesting.Senders{
+		s.storageAccountSender(),
+		s.storageAccountKeysSender(),
+		errorSender,
+	}
+	err := env.(environs.StorageCredentialRotator).RotateStorageCredentials(
+		func(map[string]interface{}) error {
+			c.Fatalf("unexpected call to recordCredentials")
+			return nil
+		},
+	)
+	c.Assert(err, gc.ErrorMatches, `regenerating storage account key "key2": .*blargh`)
+}
+
+func (s *environSuite) TestStorageClientRefreshesRejectedKey(c *gc.C) {
+	env := s.openEnviron(c)
+	s.setStorageAccountKeys("key-1", "key-2")
+	staleKeysSender := s.storageAccountKeysSender()
+	// Another environ rotates the keys after
+	// this one has fetched the key in use.
+	s.setStorageAccountKeys("key-1-new", "key-2-new")
+	s.sender = azuretesting.Senders{
+		s.storageAccountSender(),
+		staleKeysSender,
+		s.storageAccountKeysSender(),
+	}
+	s.storageClient.SetErrors(nil, azurestorage.AzureStorageServiceError{
+		StatusCode: http.StatusForbidden,
+		Code:       "AuthenticationFailed",
+	})
+
+	provider, err := env.StorageProvider("azure")
+	c.Assert(err, jc.ErrorIsNil)
+	storageConfig, err := jujustorage.NewConfig("azure", "azure", nil)
+	c.Assert(err, jc.ErrorIsNil)
+	volumeSource, err := provider.VolumeSource(storageConfig)
+	c.Assert(err, jc.ErrorIsNil)
+	_, err = volumeSource.ListVolumes()
+	c.Assert(err, jc.ErrorIsNil)
+
+	// The rejected key is fetched afresh, and the call retried.
+	s.storageClient.CheckCallNames(c, "NewClient", "ListBlobs", "NewClient", "ListBlobs")
+	c.Assert(s.storageClient.Calls()[0].Args[1], gc.Equals, "key-1")
+	c.Assert(s.storageClient.Calls()[2].Args[1], gc.Equals, "key-1-new")
+
+	// The environ uses the new key from now on.
+	s.sender = nil
+	s.assertStorageClientKey(c, env, "key-1-new")
+}
+
+func (s *environSuite) TestStorageClientKeyRejectedAgain(c *gc.C) {
+	env := s.openEnviron(c)
+	s.sender = azuretesting.Senders{
+		s.storageAccountSender(),
+		s.storageAccountKeysSender(),
+		s.storageAccountKeysSender(),
+	}
+	authErr := azurestorage.AzureStorageServiceError{
+		StatusCode: http.StatusForbidden,
+		Code:       "AuthenticationFailed",
+	}
+	s.storageClient.SetErrors(nil, authErr, nil, authErr)
+
+	provider, err := env.StorageProvider("azure")
+	c.Assert(err, jc.ErrorIsNil)
+	storageConfig, err := jujustorage.NewConfig("azure", "azure", nil)
+	c.Assert(err, jc.ErrorIsNil)
+	volumeSource, err := provider.VolumeSource(storageConfig)
+	c.Assert(err, jc.ErrorIsNil)
+	_, err = volumeSource.ListVolumes()
+	c.Assert(err, gc.ErrorMatches, "listing volumes: listing blobs: .*")
+
+	// The call is retried only once.
+	s.storageClient.CheckCallNames(c, "NewClient", "ListBlobs", "NewClient", "ListBlobs")
+}
+
+func (s *environSuite) setStorageAccountKeys(key1, key2 string) {
+	keys := []storage.AccountKey{{
+		KeyName:     to.StringPtr("key1"),
+		Value:       to.StringPtr(key1),
+		Permissions: storage.FULL,
+	}, {
+		KeyName:     to.StringPtr("key2"),
+		Value:       to.StringPtr(key2),
+		Permissions: storage.FULL,
+	}}
+	s.storageAccountKeys = &storage.AccountListKeysResult{Keys: &keys}
+}
+
+func (s *environSuite) storageAccountRegenerateKeySender(key1, key2 string) *azuretesting.MockSender {
+	keys := []storage.AccountKey{{
+		KeyName:     to.StringPtr("key1"),
+		Value:       to.StringPtr(key1),
+		Permissions: storage.FULL,
+	}, {
+		KeyName:     to.StringPtr("key2"),
+		Value:       to.StringPtr(key2),
+		Permissions: storage.FULL,
+	}}
+	return s.makeSender(
+		".*/storageAccounts/.*/regenerateKey",
+		&storage.AccountListKeysResult{Keys: &keys},
+	)
+}
+
+// assertStorageClientKey asserts that the environ's storage client is
+// created with the given storage account key, without first fetching
+// the storage account or its keys.
+func (s *environSuite) assertStorageClientKey(c *gc.C, env environs.Environ, key string) {
+	provider, err := env.StorageProvider("azure")
+	c.Assert(err, jc.ErrorIsNil)
+	storageConfig, err := jujustorage.NewConfig("azure", "azure", nil)
+	c.Assert(err, jc.ErrorIsNil)
+	volumeSource, err := provider.VolumeSource(storageConfig)
+	c.Assert(err, jc.ErrorIsNil)
+
+	s.storageClient.ResetCalls()
+	_, err = volumeSource.ListVolumes()
+	c.Assert(err, jc.ErrorIsNil)
+	s.storageClient.CheckCallNames(c, "NewClient", "ListBlobs")
+	c.Assert(s.storageClient.Calls()[0].Args[1], gc.Equals, key)
+}
+
 func (s *environSuite) TestConstraintsValidatorUnsupported(c *gc.C) {
 	validator := s.constraintsValidator(c)
 	unsupported, err := validator.Validate(constraints.MustParse(
-		"arch=amd64 tags=foo cpu-power=100 virt-type=kvm",
+		"arch=amd64 tags=foo cpu-power=100 virt-type=kvm max-hourly-cost=100",
 	))
 	c.Assert(err, jc.ErrorIsNil)
-	c.Assert(unsupported, jc.SameContents, []string{"tags", "cpu-power", "virt-type"})
+	c.Assert(unsupported, jc.SameContents, []string{"tags", "cpu-power", "virt-type", "max-hourly-cost"})
 }
 
 func (s *environSuite) TestConstraintsValidatorVocabulary(c *gc.C) {
@@ -1161,6 +3078,44 @@ func (s *environSuite) TestAgentMirror(c *gc.C) {
 	})
 }
 
+func (s *environSuite) TestAgentMirrorPrivateCloud(c *gc.C) {
+	env := s.openEnviron(c, testing.Attrs{"private-cloud": true})
+	cloudSpec, err := env.(envtools.HasAgentMirror).AgentMirror()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(cloudSpec, gc.Equals, simplestreams.CloudSpec{
+		Region:   "westus",
+		Endpoint: envtools.ControllerMirrorEndpoint,
+	})
+}
+
+func (s *environSuite) TestHTTPClientConfig(c *gc.C) {
+	env := s.openEnviron(c, testing.Attrs{
+		"https-proxy":     "http://proxy.example.com:3128",
+		"ca-certificates": testing.CACert,
+	})
+	transport := azure.EnvironHTTPClient(env).Transport.(*http.Transport)
+	c.Assert(transport.TLSClientConfig.RootCAs, gc.NotNil)
+	req, err := http.NewRequest("GET", "https://management.azure.com/", nil)
+	c.Assert(err, jc.ErrorIsNil)
+	proxyURL, err := transport.Proxy(req)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(proxyURL.String(), gc.Equals, "http://proxy.example.com:3128")
+
+	// Changing the proxy settings replaces the HTTP client.
+	cfg, err := env.Config().Apply(map[string]interface{}{
+		"https-proxy":     "",
+		"ca-certificates": "",
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	err = env.SetConfig(cfg)
+	c.Assert(err, jc.ErrorIsNil)
+	transport = azure.EnvironHTTPClient(env).Transport.(*http.Transport)
+	c.Assert(transport.TLSClientConfig.RootCAs, gc.IsNil)
+	proxyURL, err = transport.Proxy(req)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(proxyURL, gc.IsNil)
+}
+
 func (s *environSuite) TestDestroyHostedModel(c *gc.C) {
 	env := s.openEnviron(c, testing.Attrs{"controller-uuid": utils.MustNewUUID().String()})
 	s.sender = azuretesting.Senders{
@@ -1172,6 +3127,34 @@ func (s *environSuite) TestDestroyHostedModel(c *gc.C) {
 	c.Assert(s.requests[0].Method, gc.Equals, "DELETE")
 }
 
+func (s *environSuite) TestDestroyExistingSecurityGroup(c *gc.C) {
+	env := s.openEnviron(c, testing.Attrs{"network-security-group": testSecurityGroupID})
+
+	// The group is shared with another model, which has
+	// a machine with the same name; its rule is left alone.
+	modelPrefix := testing.ModelTag.Id() + "-"
+	nsg := makeSecurityGroup(
+		makeSecurityRule(modelPrefix+"machine-0-80", "192.168.0.4", "80"),
+		makeSecurityRule(modelPrefix+"machine-1-80", "192.168.0.5", "80"),
+		makeSecurityRule("c0ffee00-0bad-4000-8000-000000000000-machine-0-80", "192.168.0.6", "80"),
+	)
+	s.sender = azuretesting.Senders{
+		s.makeSender(".*/networkSecurityGroups/baseline-nsg", nsg),                                           // GET
+		s.makeSender(".*/networkSecurityGroups/baseline-nsg/securityRules/"+modelPrefix+"machine-0-80", nil), // DELETE
+		s.makeSender(".*/networkSecurityGroups/baseline-nsg/securityRules/"+modelPrefix+"machine-1-80", nil), // DELETE
+		s.makeSender(".*/resourcegroups/juju-testenv-model-"+testing.ModelTag.Id(), nil),                     // DELETE
+	}
+	err := env.Destroy()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(s.requests, gc.HasLen, 4)
+	c.Assert(s.requests[0].URL.Path, gc.Equals, testSecurityGroupID)
+	c.Assert(s.requests[1].Method, gc.Equals, "DELETE")
+	c.Assert(s.requests[1].URL.Path, gc.Equals, testSecurityGroupID+"/securityRules/"+modelPrefix+"machine-0-80")
+	c.Assert(s.requests[2].Method, gc.Equals, "DELETE")
+	c.Assert(s.requests[2].URL.Path, gc.Equals, testSecurityGroupID+"/securityRules/"+modelPrefix+"machine-1-80")
+	c.Assert(s.requests[3].Method, gc.Equals, "DELETE")
+}
+
 func (s *environSuite) TestDestroyController(c *gc.C) {
 	groups := []resources.ResourceGroup{{
 		Name: to.StringPtr("group1"),
@@ -4,15 +4,23 @@
 package azure
 
 import (
+	"io"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/arm/resources/resources"
 	"github.com/Azure/go-autorest/autorest"
+	"github.com/Azure/go-autorest/autorest/to"
 	"github.com/juju/errors"
 	"github.com/juju/loggo"
+	"github.com/juju/utils"
 	"github.com/juju/utils/clock"
 
 	"github.com/juju/juju/environs"
 	"github.com/juju/juju/environs/config"
 	"github.com/juju/juju/provider/azure/internal/azureauth"
 	"github.com/juju/juju/provider/azure/internal/azurestorage"
+	"github.com/juju/juju/provider/azure/internal/imageutils"
+	"github.com/juju/juju/provider/azure/internal/tracing"
 )
 
 // Logger for the Azure provider.
@@ -44,6 +52,34 @@ type ProviderConfig struct {
 	// interactively create/update service principals with
 	// password credentials.
 	InteractiveCreateServicePrincipal azureauth.InteractiveCreateServicePrincipalFunc
+
+	// ImageCacheClock is used to expire the images resolved for
+	// StartInstance, which are cached and shared by all environs
+	// opened with the provider. If ImageCacheClock is nil, the
+	// wall clock will be used.
+	ImageCacheClock clock.Clock
+
+	// NewUUID is used to generate the UUIDs that make the names of
+	// a machine's resources unique, and the IDs that identify the
+	// requests made on behalf of machines. If NewUUID is nil,
+	// utils.NewUUID will be used.
+	NewUUID func() (utils.UUID, error)
+
+	// ShutdownClock is used to time the graceful shutdown of virtual
+	// machines in StopInstances. If ShutdownClock is nil, the wall
+	// clock will be used.
+	ShutdownClock clock.Clock
+
+	// TraceClock is used to measure the latency of the requests made
+	// to Azure on behalf of machines. If TraceClock is nil, the wall
+	// clock will be used.
+	TraceClock clock.Clock
+
+	// InstanceMetadataSender is the autorest.Sender that will be
+	// used to query the Azure Instance Metadata Service, when an
+	// agent identifies the instance it is running on. If it is nil,
+	// requests are sent directly to the service, without proxies.
+	InstanceMetadataSender autorest.Sender
 }
 
 // Validate validates the Azure provider configuration.
@@ -63,27 +99,63 @@ func (cfg ProviderConfig) Validate() error {
 	return nil
 }
 
+// imageCacheTTL is the length of time for which resolved images
+// are cached. New Ubuntu point releases are published infrequently,
+// so there is no need to query for them on every StartInstance.
+const imageCacheTTL = time.Hour
+
 type azureEnvironProvider struct {
 	environProviderCredentials
 
 	config ProviderConfig
+
+	// imageCache caches the images resolved for StartInstance,
+	// and is shared by all environs opened with the provider.
+	imageCache *imageutils.Cache
+
+	// tracer records the requests made to Azure on behalf of
+	// machines, and is shared by all environs opened with the
+	// provider.
+	tracer *tracing.Tracer
 }
 
+var _ environs.RequestTracer = (*azureEnvironProvider)(nil)
+
 // NewEnvironProvider returns a new EnvironProvider for Azure.
 func NewEnvironProvider(config ProviderConfig) (*azureEnvironProvider, error) {
 	if err := config.Validate(); err != nil {
 		return nil, errors.Annotate(err, "validating environ provider configuration")
 	}
+	imageCacheClock := config.ImageCacheClock
+	if imageCacheClock == nil {
+		imageCacheClock = clock.WallClock
+	}
+	if config.NewUUID == nil {
+		config.NewUUID = utils.NewUUID
+	}
+	if config.ShutdownClock == nil {
+		config.ShutdownClock = clock.WallClock
+	}
+	if config.TraceClock == nil {
+		config.TraceClock = clock.WallClock
+	}
 	return &azureEnvironProvider{
 		environProviderCredentials: environProviderCredentials{
 			sender:                            config.Sender,
 			requestInspector:                  config.RequestInspector,
 			interactiveCreateServicePrincipal: config.InteractiveCreateServicePrincipal,
 		},
-		config: config,
+		config:     config,
+		imageCache: imageutils.NewCache(imageCacheClock, imageCacheTTL),
+		tracer:     tracing.NewTracer(config.TraceClock, config.NewUUID),
 	}, nil
 }
 
+// WriteRequestTrace is part of the environs.RequestTracer interface.
+func (prov *azureEnvironProvider) WriteRequestTrace(w io.Writer, modelUUID, machineTag string) error {
+	return prov.tracer.WriteTrace(w, modelUUID, machineTag)
+}
+
 // Open is part of the EnvironProvider interface.
 func (prov *azureEnvironProvider) Open(args environs.OpenParams) (environs.Environ, error) {
 	logger.Debugf("opening model %q", args.Config.Name())
@@ -97,6 +169,36 @@ func (prov *azureEnvironProvider) Open(args environs.OpenParams) (environs.Envir
 	return environ, nil
 }
 
+var _ environs.CredentialValidator = (*azureEnvironProvider)(nil)
+
+// ValidateCredential is part of the environs.CredentialValidator
+// interface. The credential is validated by acquiring a token, and
+// listing the resource groups in the subscription.
+func (prov *azureEnvironProvider) ValidateCredential(spec environs.CloudSpec) error {
+	if err := validateCloudSpec(spec); err != nil {
+		return errors.Annotate(err, "validating cloud spec")
+	}
+	env := &azureEnviron{
+		provider: prov,
+		cloud:    spec,
+		location: canonicalLocation(spec.Region),
+	}
+	if err := env.initEnviron(); err != nil {
+		return errors.Trace(err)
+	}
+	if err := env.authorizer.refresh(); err != nil {
+		return errors.Annotate(err, "acquiring token")
+	}
+	client := resources.GroupsClient{env.resources}
+	if err := env.callAPI(func() (autorest.Response, error) {
+		result, err := client.List("", to.Int32Ptr(1))
+		return result.Response, err
+	}); err != nil {
+		return errors.Annotate(err, "listing resource groups")
+	}
+	return nil
+}
+
 // PrepareConfig is part of the EnvironProvider interface.
 func (prov *azureEnvironProvider) PrepareConfig(args environs.PrepareConfigParams) (*config.Config, error) {
 	if err := validateCloudSpec(args.Cloud); err != nil {
@@ -7,6 +7,8 @@ import (
 	"net/http"
 	"time"
 
+	"github.com/Azure/azure-sdk-for-go/arm/resources/resources"
+	"github.com/Azure/go-autorest/autorest/mocks"
 	jujutesting "github.com/juju/testing"
 	jc "github.com/juju/testing/checkers"
 	gc "gopkg.in/check.v1"
@@ -92,6 +94,43 @@ func (s *environProviderSuite) TestOpenUnsupportedCredential(c *gc.C) {
 	s.testOpenError(c, s.spec, `validating cloud spec: "oauth1" auth-type not supported`)
 }
 
+func (s *environProviderSuite) TestValidateCredential(c *gc.C) {
+	groupsSender := azuretesting.NewSenderWithValue(resources.ResourceGroupListResult{})
+	groupsSender.PathPattern = ".*/resourcegroups"
+	s.sender = azuretesting.Senders{
+		discoverAuthSender(),
+		tokenRefreshSender(),
+		groupsSender,
+	}
+	err := s.provider.(environs.CredentialValidator).ValidateCredential(s.spec)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(s.sender, gc.HasLen, 0)
+	c.Assert(s.requests, gc.HasLen, 1)
+	c.Assert(s.requests[0].Method, gc.Equals, "GET")
+	c.Assert(s.requests[0].URL.Query().Get("$top"), gc.Equals, "1")
+}
+
+func (s *environProviderSuite) TestValidateCredentialListFails(c *gc.C) {
+	forbiddenSender := mocks.NewSender()
+	forbiddenSender.AppendResponse(mocks.NewResponseWithStatus(
+		"forbidden", http.StatusForbidden,
+	))
+	s.sender = azuretesting.Senders{
+		discoverAuthSender(),
+		tokenRefreshSender(),
+		forbiddenSender,
+	}
+	err := s.provider.(environs.CredentialValidator).ValidateCredential(s.spec)
+	c.Assert(err, gc.ErrorMatches, "listing resource groups: .*")
+}
+
+func (s *environProviderSuite) TestValidateCredentialUnsupported(c *gc.C) {
+	credential := cloud.NewCredential(cloud.OAuth1AuthType, map[string]string{})
+	s.spec.Credential = &credential
+	err := s.provider.(environs.CredentialValidator).ValidateCredential(s.spec)
+	c.Assert(err, gc.ErrorMatches, `validating cloud spec: "oauth1" auth-type not supported`)
+}
+
 func (s *environProviderSuite) testOpenError(c *gc.C, spec environs.CloudSpec, expect string) {
 	_, err := s.provider.Open(environs.OpenParams{
 		Cloud:  spec,
@@ -4,6 +4,11 @@
 package azure
 
 import (
+	"crypto/x509"
+	"net/http"
+
+	"github.com/juju/utils/proxy"
+
 	"github.com/juju/juju/environs"
 	"github.com/juju/juju/storage"
 )
@@ -15,3 +20,11 @@ func ForceVolumeSourceTokenRefresh(vs storage.VolumeSource) error {
 func ForceTokenRefresh(env environs.Environ) error {
 	return env.(*azureEnviron).authorizer.refresh()
 }
+
+func NewHTTPClient(proxySettings proxy.Settings, caCertPool *x509.CertPool) (*http.Client, error) {
+	return newHTTPClient(proxySettings, caCertPool)
+}
+
+func EnvironHTTPClient(env environs.Environ) *http.Client {
+	return env.(*azureEnviron).sender.httpClient()
+}
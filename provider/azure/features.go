@@ -0,0 +1,23 @@
+// Copyright 2016 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package azure
+
+import (
+	"github.com/juju/juju/environs"
+)
+
+var _ environs.FeatureEnumerator = (*azureEnviron)(nil)
+
+// SupportedFeatures is specified in the environs.FeatureEnumerator
+// interface.
+//
+// The Azure provider does not yet support spaces or availability
+// zones. Machines' disks are unmanaged VHDs in the model's storage
+// accounts, and the "global" firewall-mode is rejected.
+func (env *azureEnviron) SupportedFeatures() ([]environs.Feature, error) {
+	return []environs.Feature{
+		environs.FeatureSuspendInstances,
+		environs.FeatureVolumes,
+	}, nil
+}
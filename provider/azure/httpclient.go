@@ -0,0 +1,154 @@
+// Copyright 2016 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package azure
+
+import (
+	"crypto/x509"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/juju/errors"
+	"github.com/juju/utils"
+	"github.com/juju/utils/proxy"
+)
+
+// configuredSender is an autorest.Sender that sends requests with an
+// http.Client configured from the model's proxy and CA certificate
+// settings. The Azure API clients share a configuredSender, so that
+// replacing its http.Client when the settings change affects them all.
+type configuredSender struct {
+	mu     sync.Mutex
+	client *http.Client
+}
+
+// Do is part of the autorest.Sender interface.
+func (s *configuredSender) Do(req *http.Request) (*http.Response, error) {
+	return s.httpClient().Do(req)
+}
+
+// httpClient returns the http.Client with which requests are sent.
+func (s *configuredSender) httpClient() *http.Client {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.client == nil {
+		return http.DefaultClient
+	}
+	return s.client
+}
+
+// setHTTPClient sets the http.Client with which requests are sent.
+func (s *configuredSender) setHTTPClient(client *http.Client) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.client = client
+}
+
+// newHTTPClient returns an http.Client that sends requests via the
+// proxies in the given proxy settings. If caCertPool is non-nil, the
+// TLS certificates of servers are verified against the CAs in the pool
+// rather than the system's.
+func newHTTPClient(proxySettings proxy.Settings, caCertPool *x509.CertPool) (*http.Client, error) {
+	proxyFunc, err := newProxyFunc(proxySettings)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	tlsConfig := utils.SecureTLSConfig()
+	if caCertPool != nil {
+		tlsConfig.RootCAs = caCertPool
+	}
+	return &http.Client{
+		Transport: &http.Transport{
+			Proxy:               proxyFunc,
+			TLSClientConfig:     tlsConfig,
+			TLSHandshakeTimeout: 10 * time.Second,
+		},
+	}, nil
+}
+
+// newProxyFunc returns a function, suitable for http.Transport.Proxy,
+// that returns the proxy to use for requests based on the given proxy
+// settings. This mirrors http.ProxyFromEnvironment, but takes the
+// settings from the model config rather than from the environment.
+func newProxyFunc(settings proxy.Settings) (func(*http.Request) (*url.URL, error), error) {
+	httpProxy, err := parseProxyURL(settings.Http)
+	if err != nil {
+		return nil, errors.Annotate(err, "parsing http-proxy")
+	}
+	httpsProxy, err := parseProxyURL(settings.Https)
+	if err != nil {
+		return nil, errors.Annotate(err, "parsing https-proxy")
+	}
+	if httpsProxy == nil {
+		httpsProxy = httpProxy
+	}
+	noProxy := settings.NoProxy
+	return func(req *http.Request) (*url.URL, error) {
+		proxyURL := httpProxy
+		if req.URL.Scheme == "https" {
+			proxyURL = httpsProxy
+		}
+		if proxyURL == nil || bypassProxy(req.URL.Host, noProxy) {
+			return nil, nil
+		}
+		return proxyURL, nil
+	}, nil
+}
+
+// parseProxyURL parses the given proxy address, which may omit the
+// scheme, in which case "http" is assumed. An empty address yields a
+// nil URL.
+func parseProxyURL(proxy string) (*url.URL, error) {
+	if proxy == "" {
+		return nil, nil
+	}
+	proxyURL, err := url.Parse(proxy)
+	if err != nil || !strings.HasPrefix(proxyURL.Scheme, "http") {
+		if proxyURL, err := url.Parse("http://" + proxy); err == nil {
+			return proxyURL, nil
+		}
+	}
+	if err != nil {
+		return nil, errors.Errorf("invalid proxy address %q: %v", proxy, err)
+	}
+	return proxyURL, nil
+}
+
+// bypassProxy reports whether requests to the given host, which may
+// include a port, should be sent directly rather than via a proxy. Hosts
+// on the loopback interface are never proxied. Otherwise, a host bypasses
+// the proxy if noProxy, a comma-separated list of host names and domain
+// suffixes, matches it; a noProxy of "*" matches all hosts.
+func bypassProxy(host, noProxy string) bool {
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	host = strings.ToLower(host)
+	if host == "localhost" {
+		return true
+	}
+	if ip := net.ParseIP(host); ip != nil && ip.IsLoopback() {
+		return true
+	}
+	for _, p := range strings.Split(noProxy, ",") {
+		p = strings.ToLower(strings.TrimSpace(p))
+		if p == "" {
+			continue
+		}
+		if p == "*" {
+			return true
+		}
+		if h, _, err := net.SplitHostPort(p); err == nil {
+			p = h
+		}
+		p = strings.TrimPrefix(p, ".")
+		if host == p || strings.HasSuffix(host, "."+p) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,92 @@
+// Copyright 2016 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package azure_test
+
+import (
+	"crypto/x509"
+	"net/http"
+
+	jc "github.com/juju/testing/checkers"
+	"github.com/juju/utils/proxy"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/provider/azure"
+	"github.com/juju/juju/testing"
+)
+
+type httpClientSuite struct {
+	testing.BaseSuite
+}
+
+var _ = gc.Suite(&httpClientSuite{})
+
+func (s *httpClientSuite) assertProxy(c *gc.C, client *http.Client, requestURL, expect string) {
+	transport, ok := client.Transport.(*http.Transport)
+	c.Assert(ok, jc.IsTrue)
+	req, err := http.NewRequest("GET", requestURL, nil)
+	c.Assert(err, jc.ErrorIsNil)
+	proxyURL, err := transport.Proxy(req)
+	c.Assert(err, jc.ErrorIsNil)
+	if expect == "" {
+		c.Assert(proxyURL, gc.IsNil)
+	} else {
+		c.Assert(proxyURL, gc.NotNil)
+		c.Assert(proxyURL.String(), gc.Equals, expect)
+	}
+}
+
+func (s *httpClientSuite) TestNewHTTPClientProxies(c *gc.C) {
+	client, err := azure.NewHTTPClient(proxy.Settings{
+		Http:    "proxy.example.com:3128",
+		Https:   "https://secure-proxy.example.com:3129",
+		NoProxy: "localhost, .internal.example.com,azurestack.local:443",
+	}, nil)
+	c.Assert(err, jc.ErrorIsNil)
+	s.assertProxy(c, client, "http://management.azure.com/", "http://proxy.example.com:3128")
+	s.assertProxy(c, client, "https://management.azure.com/", "https://secure-proxy.example.com:3129")
+	s.assertProxy(c, client, "https://foo.internal.example.com/", "")
+	s.assertProxy(c, client, "https://internal.example.com/", "")
+	s.assertProxy(c, client, "https://management.azurestack.local/", "")
+	s.assertProxy(c, client, "https://azurestack.local:8443/", "")
+	s.assertProxy(c, client, "https://notazurestack.local/", "https://secure-proxy.example.com:3129")
+	s.assertProxy(c, client, "http://127.0.0.1:8080/", "")
+}
+
+func (s *httpClientSuite) TestNewHTTPClientHTTPSProxyDefaultsToHTTPProxy(c *gc.C) {
+	client, err := azure.NewHTTPClient(proxy.Settings{Http: "http://proxy.example.com:3128"}, nil)
+	c.Assert(err, jc.ErrorIsNil)
+	s.assertProxy(c, client, "https://management.azure.com/", "http://proxy.example.com:3128")
+}
+
+func (s *httpClientSuite) TestNewHTTPClientNoProxyWildcard(c *gc.C) {
+	client, err := azure.NewHTTPClient(proxy.Settings{
+		Http:    "http://proxy.example.com:3128",
+		NoProxy: "*",
+	}, nil)
+	c.Assert(err, jc.ErrorIsNil)
+	s.assertProxy(c, client, "https://management.azure.com/", "")
+}
+
+func (s *httpClientSuite) TestNewHTTPClientNoProxies(c *gc.C) {
+	client, err := azure.NewHTTPClient(proxy.Settings{}, nil)
+	c.Assert(err, jc.ErrorIsNil)
+	s.assertProxy(c, client, "https://management.azure.com/", "")
+}
+
+func (s *httpClientSuite) TestNewHTTPClientInvalidProxy(c *gc.C) {
+	_, err := azure.NewHTTPClient(proxy.Settings{Https: "%zz"}, nil)
+	c.Assert(err, gc.ErrorMatches, `parsing https-proxy: invalid proxy address "%zz": .*`)
+}
+
+func (s *httpClientSuite) TestNewHTTPClientCACertificates(c *gc.C) {
+	client, err := azure.NewHTTPClient(proxy.Settings{}, nil)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(client.Transport.(*http.Transport).TLSClientConfig.RootCAs, gc.IsNil)
+
+	pool := x509.NewCertPool()
+	pool.AddCert(testing.CACertX509)
+	client, err = azure.NewHTTPClient(proxy.Settings{}, pool)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(client.Transport.(*http.Transport).TLSClientConfig.RootCAs, gc.Equals, pool)
+}
@@ -9,14 +9,16 @@ import (
 	"strings"
 
 	"github.com/Azure/azure-sdk-for-go/arm/network"
+	"github.com/Azure/azure-sdk-for-go/arm/resources/resources"
 	"github.com/Azure/go-autorest/autorest"
 	"github.com/Azure/go-autorest/autorest/to"
 
 	"github.com/juju/errors"
+	"github.com/juju/utils/set"
+
 	"github.com/juju/juju/instance"
 	jujunetwork "github.com/juju/juju/network"
 	"github.com/juju/juju/status"
-	"gopkg.in/juju/names.v2"
 )
 
 type azureInstance struct {
@@ -67,36 +69,133 @@ func (inst *azureInstance) Status() instance.InstanceStatus {
 	}
 }
 
-// setInstanceAddresses queries Azure for the NICs and public IPs associated
-// with the given set of instances. This assumes that the instances'
-// VirtualMachines are up-to-date, and that there are no concurrent accesses
-// to the instances.
+// setInstanceAddresses ensures that the given instances' addresses are
+// up-to-date. Rather than listing all of the network interfaces and public
+// IP addresses in the resource group, the resources tagged with a Juju
+// machine name are listed, narrowed to the given instance's ID if there is
+// only one, and only the network interfaces and public IP addresses tagged
+// with one of the instances' IDs are fetched. The network interfaces are
+// fetched with their public IP addresses expanded, so only those public IP
+// addresses not attached to one of them are fetched separately. This
+// assumes that there are no concurrent accesses to the instances.
 func setInstanceAddresses(
 	callAPI callAPIFunc,
 	resourceGroup string,
+	groupsClient resources.GroupsClient,
 	nicClient network.InterfacesClient,
 	pipClient network.PublicIPAddressesClient,
 	instances []*azureInstance,
-) (err error) {
-	instanceNics, err := instanceNetworkInterfaces(
-		callAPI, resourceGroup, nicClient,
-	)
-	if err != nil {
-		return errors.Annotate(err, "listing network interfaces")
+) error {
+	filter := fmt.Sprintf("tagname eq '%s'", jujuMachineNameTag)
+	if len(instances) == 1 {
+		filter += fmt.Sprintf(" and tagvalue eq '%s'", instances[0].Id())
 	}
-	instancePips, err := instancePublicIPAddresses(
-		callAPI, resourceGroup, pipClient,
-	)
-	if err != nil {
-		return errors.Annotate(err, "listing public IP addresses")
+	var result resources.ResourceListResult
+	if err := callAPI(func() (autorest.Response, error) {
+		var err error
+		result, err = groupsClient.ListResources(resourceGroup, filter, nil)
+		return result.Response, err
+	}); err != nil {
+		return errors.Annotate(err, "listing network resources")
 	}
+
+	byId := make(map[instance.Id]*azureInstance)
 	for _, inst := range instances {
-		inst.networkInterfaces = instanceNics[inst.Id()]
-		inst.publicIPAddresses = instancePips[inst.Id()]
+		inst.networkInterfaces = nil
+		inst.publicIPAddresses = nil
+		byId[inst.Id()] = inst
+	}
+	type taggedResource struct {
+		name string
+		inst *azureInstance
+	}
+	var taggedNics, taggedPips []taggedResource
+	if result.Value != nil {
+		for _, resource := range *result.Value {
+			inst, ok := byId[instance.Id(toTags(resource.Tags)[jujuMachineNameTag])]
+			if !ok {
+				// The resource belongs to an instance
+				// we're not interested in.
+				continue
+			}
+			tagged := taggedResource{to.String(resource.Name), inst}
+			switch resourceType := to.String(resource.Type); {
+			case strings.EqualFold(resourceType, "Microsoft.Network/networkInterfaces"):
+				taggedNics = append(taggedNics, tagged)
+			case strings.EqualFold(resourceType, "Microsoft.Network/publicIPAddresses"):
+				taggedPips = append(taggedPips, tagged)
+			}
+		}
+	}
+
+	expandedPips := make(map[string]network.PublicIPAddress)
+	for _, tagged := range taggedNics {
+		var nic network.Interface
+		if err := callAPI(func() (autorest.Response, error) {
+			var err error
+			nic, err = nicClient.Get(resourceGroup, tagged.name, nicPublicIPAddressExpand)
+			return nic.Response, err
+		}); err != nil {
+			if nic.Response.Response != nil && nic.StatusCode == http.StatusNotFound {
+				// Deleted since the resources were listed.
+				continue
+			}
+			return errors.Annotatef(err, "getting network interface %q", tagged.name)
+		}
+		tagged.inst.networkInterfaces = append(tagged.inst.networkInterfaces, nic)
+		for _, pip := range expandedPublicIPAddresses(nic) {
+			expandedPips[to.String(pip.Name)] = pip
+		}
+	}
+
+	for _, tagged := range taggedPips {
+		if pip, ok := expandedPips[tagged.name]; ok {
+			tagged.inst.publicIPAddresses = append(tagged.inst.publicIPAddresses, pip)
+			continue
+		}
+		var pip network.PublicIPAddress
+		if err := callAPI(func() (autorest.Response, error) {
+			var err error
+			pip, err = pipClient.Get(resourceGroup, tagged.name, "")
+			return pip.Response, err
+		}); err != nil {
+			if pip.Response.Response != nil && pip.StatusCode == http.StatusNotFound {
+				// Deleted since the resources were listed.
+				continue
+			}
+			return errors.Annotatef(err, "getting public IP address %q", tagged.name)
+		}
+		tagged.inst.publicIPAddresses = append(tagged.inst.publicIPAddresses, pip)
 	}
 	return nil
 }
 
+// nicPublicIPAddressExpand is the value of the $expand parameter that
+// causes Azure to include the public IP addresses attached to a network
+// interface's IP configurations in the network interface.
+const nicPublicIPAddressExpand = "ipConfigurations/publicIPAddress"
+
+// expandedPublicIPAddresses returns the public IP addresses expanded
+// in the given network interface's IP configurations. Public IP
+// addresses that are only referenced by ID are omitted.
+func expandedPublicIPAddresses(nic network.Interface) []network.PublicIPAddress {
+	if nic.Properties == nil || nic.Properties.IPConfigurations == nil {
+		return nil
+	}
+	var pips []network.PublicIPAddress
+	for _, ipConfiguration := range *nic.Properties.IPConfigurations {
+		if ipConfiguration.Properties == nil {
+			continue
+		}
+		pip := ipConfiguration.Properties.PublicIPAddress
+		if pip == nil || pip.Name == nil || pip.Properties == nil {
+			continue
+		}
+		pips = append(pips, *pip)
+	}
+	return pips
+}
+
 // instanceNetworkInterfaces lists all network interfaces in the resource
 // group, and returns a mapping from instance ID to the network interfaces
 // associated with that instance.
@@ -151,10 +250,16 @@ func instancePublicIPAddresses(
 	return instancePips, nil
 }
 
-// Addresses is specified in the Instance interface.
+// Addresses is specified in the Instance interface. The addresses
+// include the DNS names assigned by Azure, as well as IP addresses.
 func (inst *azureInstance) Addresses() ([]jujunetwork.Address, error) {
-	addresses := make([]jujunetwork.Address, 0, len(inst.networkInterfaces)+len(inst.publicIPAddresses))
+	addresses := make([]jujunetwork.Address, 0, 2*(len(inst.networkInterfaces)+len(inst.publicIPAddresses)))
 	for _, nic := range inst.networkInterfaces {
+		if fqdn := nicInternalFQDN(nic); fqdn != "" {
+			addresses = append(addresses, jujunetwork.NewScopedAddress(
+				fqdn, jujunetwork.ScopeCloudLocal,
+			))
+		}
 		if nic.Properties.IPConfigurations == nil {
 			continue
 		}
@@ -177,10 +282,41 @@ func (inst *azureInstance) Addresses() ([]jujunetwork.Address, error) {
 			to.String(pip.Properties.IPAddress),
 			jujunetwork.ScopePublic,
 		))
+		// The public DNS name is only resolvable while the
+		// public IP address is allocated, so we only report
+		// it along with the IP address.
+		if dnsSettings := pip.Properties.DNSSettings; dnsSettings != nil {
+			if fqdn := to.String(dnsSettings.Fqdn); fqdn != "" {
+				addresses = append(addresses, jujunetwork.NewScopedAddress(
+					fqdn, jujunetwork.ScopePublic,
+				))
+			}
+		}
 	}
 	return addresses, nil
 }
 
+// nicInternalFQDN returns the fully qualified domain name by which the
+// network interface may be addressed within the virtual network, or the
+// empty string if it has none.
+func nicInternalFQDN(nic network.Interface) string {
+	if nic.Properties == nil || nic.Properties.DNSSettings == nil {
+		return ""
+	}
+	dnsSettings := nic.Properties.DNSSettings
+	if fqdn := to.String(dnsSettings.InternalFqdn); fqdn != "" {
+		return fqdn
+	}
+	// The FQDN is not always reported, in which case we
+	// compose it from the label and the domain name suffix.
+	label := to.String(dnsSettings.InternalDNSNameLabel)
+	suffix := to.String(dnsSettings.InternalDomainNameSuffix)
+	if label == "" || suffix == "" {
+		return ""
+	}
+	return label + "." + suffix
+}
+
 // primaryNetworkAddress returns the instance's primary jujunetwork.Address for
 // the internal virtual network. This address is used to identify the machine in
 // network security rules.
@@ -211,166 +347,243 @@ func (inst *azureInstance) primaryNetworkAddress() (jujunetwork.Address, error)
 
 // OpenPorts is specified in the Instance interface.
 func (inst *azureInstance) OpenPorts(machineId string, ports []jujunetwork.PortRange) error {
+	if err := validatePortRangeProtocols(ports); err != nil {
+		return errors.Trace(err)
+	}
+	return inst.updatePorts(machineId, func(current []jujunetwork.PortRange) []jujunetwork.PortRange {
+		return append(current, ports...)
+	})
+}
+
+// ClosePorts is specified in the Instance interface.
+func (inst *azureInstance) ClosePorts(machineId string, ports []jujunetwork.PortRange) error {
+	if err := validatePortRangeProtocols(ports); err != nil {
+		return errors.Trace(err)
+	}
+	return inst.updatePorts(machineId, func(current []jujunetwork.PortRange) []jujunetwork.PortRange {
+		return subtractPortRanges(current, ports)
+	})
+}
+
+// updatePorts computes the port ranges that should be open on the
+// instance by applying the given function to those currently open,
+// and then rewrites the instance's security rules to match.
+//
+// Port ranges are consolidated into as few rules as possible. Rules
+// are created or updated before any superseded rules are deleted, so
+// ports are never closed unexpectedly. If any rule cannot be created,
+// the rules created so far are removed again, leaving the security
+// group as it was found.
+func (inst *azureInstance) updatePorts(
+	machineId string,
+	update func([]jujunetwork.PortRange) []jujunetwork.PortRange,
+) error {
 	nsgClient := network.SecurityGroupsClient{inst.env.network}
-	securityRuleClient := network.SecurityRulesClient{inst.env.network}
 	primaryNetworkAddress, err := inst.primaryNetworkAddress()
 	if err != nil {
 		return errors.Trace(err)
 	}
 
-	securityGroupName := internalSecurityGroupName
+	securityGroup, priorities := inst.env.securityGroup()
 	var nsg network.SecurityGroup
 	if err := inst.env.callAPI(func() (autorest.Response, error) {
 		var err error
-		nsg, err = nsgClient.Get(inst.env.resourceGroup, securityGroupName, "")
+		nsg, err = nsgClient.Get(securityGroup.resourceGroup, securityGroup.name, "")
 		return nsg.Response, err
 	}); err != nil {
 		return errors.Annotate(err, "querying network security group")
 	}
+	if err := inst.env.ensurePolicySecurityRules(securityGroup, nsg); err != nil {
+		return errors.Annotate(err, "updating network policy")
+	}
 
 	var securityRules []network.SecurityRule
 	if nsg.Properties.SecurityRules != nil {
 		securityRules = *nsg.Properties.SecurityRules
-	} else {
-		nsg.Properties.SecurityRules = &securityRules
 	}
+	nsg.Properties.SecurityRules = &securityRules
 
-	// Create rules one at a time; this is necessary to avoid trampling
-	// on changes made by the provisioner. We still record rules in the
-	// NSG in memory, so we can easily tell which priorities are available.
-	vmName := resourceName(names.NewMachineTag(machineId))
-	prefix := instanceNetworkSecurityRulePrefix(instance.Id(vmName))
-	for _, ports := range ports {
+	prefix := instanceNetworkSecurityRulePrefix(securityGroup, inst.env.Config().UUID(), inst.Id())
+	existingRules := instanceSecurityRules(securityRules, prefix, priorities)
+	current, err := securityRulesPortRanges(existingRules)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	desired := securityRulePortRanges(update(current))
+	if len(desired) > securityRuleInstanceMax {
+		return errors.Errorf(
+			"%d security rules required for machine %q, exceeding the limit of %d",
+			len(desired), machineId, securityRuleInstanceMax,
+		)
+	}
+
+	existingByName := make(map[string]network.SecurityRule)
+	for _, rule := range existingRules {
+		existingByName[to.String(rule.Name)] = rule
+	}
+
+	// Create or update rules one at a time; this is necessary to avoid
+	// trampling on changes made by the provisioner. We still record rules
+	// in the NSG in memory, so we can easily tell which priorities are
+	// available.
+	var created []string
+	desiredNames := set.NewStrings()
+	for _, ports := range desired {
 		ruleName := securityRuleName(prefix, ports)
+		desiredNames.Add(ruleName)
 
-		// Check if the rule already exists; OpenPorts must be idempotent.
-		var found bool
-		for _, rule := range securityRules {
-			if to.String(rule.Name) == ruleName {
-				found = true
-				break
-			}
-		}
-		if found {
+		existing, ok := existingByName[ruleName]
+		if ok && securityRuleMatches(existing, ports) {
 			logger.Debugf("security rule %q already exists", ruleName)
 			continue
 		}
-		logger.Debugf("creating security rule %q", ruleName)
-
-		priority, err := nextSecurityRulePriority(nsg, securityRuleInternalMax+1, securityRuleMax)
-		if err != nil {
-			return errors.Annotatef(err, "getting security rule priority for %s", ports)
-		}
 
-		var protocol network.SecurityRuleProtocol
-		switch ports.Protocol {
-		case "tcp":
-			protocol = network.TCP
-		case "udp":
-			protocol = network.UDP
-		default:
-			return errors.Errorf("invalid protocol %q", ports.Protocol)
+		var priority int32
+		if ok {
+			// Keep the priority of the rule we're updating.
+			priority = to.Int32(existing.Properties.Priority)
+		} else {
+			priority, err = nextSecurityRulePriority(nsg, priorities.min, priorities.max)
+			if err != nil {
+				inst.deleteSecurityRules(securityGroup, created)
+				return errors.Annotatef(err, "getting security rule priority for %s", ports)
+			}
 		}
 
-		var portRange string
-		if ports.FromPort != ports.ToPort {
-			portRange = fmt.Sprintf("%d-%d", ports.FromPort, ports.ToPort)
-		} else {
-			portRange = fmt.Sprint(ports.FromPort)
-		}
-
-		rule := network.SecurityRule{
-			Properties: &network.SecurityRulePropertiesFormat{
-				Description:              to.StringPtr(ports.String()),
-				Protocol:                 protocol,
-				SourcePortRange:          to.StringPtr("*"),
-				DestinationPortRange:     to.StringPtr(portRange),
-				SourceAddressPrefix:      to.StringPtr("*"),
-				DestinationAddressPrefix: to.StringPtr(primaryNetworkAddress.Value),
-				Access:    network.Allow,
-				Priority:  to.Int32Ptr(priority),
-				Direction: network.Inbound,
-			},
-		}
-		if err := inst.env.callAPI(func() (autorest.Response, error) {
-			return securityRuleClient.CreateOrUpdate(
-				inst.env.resourceGroup, securityGroupName, ruleName, rule,
-				nil, // abort channel
-			)
-		}); err != nil {
+		logger.Debugf("creating security rule %q", ruleName)
+		rule := makeSecurityRule(ruleName, ports, primaryNetworkAddress.Value, priority)
+		if err := inst.createSecurityRule(securityGroup, rule); err != nil {
+			inst.deleteSecurityRules(securityGroup, created)
 			return errors.Annotatef(err, "creating security rule for %s", ports)
 		}
+		if !ok {
+			created = append(created, ruleName)
+		}
 		securityRules = append(securityRules, rule)
 	}
+
+	// Only now that all of the desired rules are in place do we
+	// delete the rules that they supersede.
+	var superseded []string
+	for _, rule := range existingRules {
+		if ruleName := to.String(rule.Name); !desiredNames.Contains(ruleName) {
+			superseded = append(superseded, ruleName)
+		}
+	}
+	for _, ruleName := range superseded {
+		if err := inst.deleteSecurityRule(securityGroup, ruleName); err != nil {
+			return errors.Trace(err)
+		}
+	}
 	return nil
 }
 
-// ClosePorts is specified in the Instance interface.
-func (inst *azureInstance) ClosePorts(machineId string, ports []jujunetwork.PortRange) error {
+// createSecurityRule creates or updates the given rule in the
+// given network security group.
+func (inst *azureInstance) createSecurityRule(securityGroup securityGroupRef, rule network.SecurityRule) error {
 	securityRuleClient := network.SecurityRulesClient{inst.env.network}
-	securityGroupName := internalSecurityGroupName
+	return inst.env.callAPI(func() (autorest.Response, error) {
+		return securityRuleClient.CreateOrUpdate(
+			securityGroup.resourceGroup, securityGroup.name,
+			to.String(rule.Name), rule,
+			nil, // abort channel
+		)
+	})
+}
 
-	// Delete rules one at a time; this is necessary to avoid trampling
-	// on changes made by the provisioner.
-	vmName := resourceName(names.NewMachineTag(machineId))
-	prefix := instanceNetworkSecurityRulePrefix(instance.Id(vmName))
-	for _, ports := range ports {
-		ruleName := securityRuleName(prefix, ports)
-		logger.Debugf("deleting security rule %q", ruleName)
-		var result autorest.Response
-		if err := inst.env.callAPI(func() (autorest.Response, error) {
-			var err error
-			result, err = securityRuleClient.Delete(
-				inst.env.resourceGroup, securityGroupName, ruleName,
-				nil, // abort channel
-			)
-			return result, err
-		}); err != nil {
-			if result.Response == nil || result.StatusCode != http.StatusNotFound {
-				return errors.Annotatef(err, "deleting security rule %q", ruleName)
-			}
+// deleteSecurityRule deletes the named rule from the given network
+// security group. It is not an error for the rule not to exist.
+func (inst *azureInstance) deleteSecurityRule(securityGroup securityGroupRef, ruleName string) error {
+	securityRuleClient := network.SecurityRulesClient{inst.env.network}
+	logger.Debugf("deleting security rule %q", ruleName)
+	var result autorest.Response
+	if err := inst.env.callAPI(func() (autorest.Response, error) {
+		var err error
+		result, err = securityRuleClient.Delete(
+			securityGroup.resourceGroup, securityGroup.name, ruleName,
+			nil, // abort channel
+		)
+		return result, err
+	}); err != nil {
+		if result.Response == nil || result.StatusCode != http.StatusNotFound {
+			return errors.Annotatef(err, "deleting security rule %q", ruleName)
 		}
 	}
 	return nil
 }
 
+// deleteSecurityRules deletes the named rules, logging rather than
+// returning any errors. This is used to roll back partial changes.
+func (inst *azureInstance) deleteSecurityRules(securityGroup securityGroupRef, ruleNames []string) {
+	for _, ruleName := range ruleNames {
+		if err := inst.deleteSecurityRule(securityGroup, ruleName); err != nil {
+			logger.Warningf("rolling back security rules: %v", err)
+		}
+	}
+}
+
 // Ports is specified in the Instance interface.
 func (inst *azureInstance) Ports(machineId string) (ports []jujunetwork.PortRange, err error) {
 	nsgClient := network.SecurityGroupsClient{inst.env.network}
-	securityGroupName := internalSecurityGroupName
+	securityGroup, priorities := inst.env.securityGroup()
 	var nsg network.SecurityGroup
 	if err := inst.env.callAPI(func() (autorest.Response, error) {
 		var err error
-		nsg, err = nsgClient.Get(inst.env.resourceGroup, securityGroupName, "")
+		nsg, err = nsgClient.Get(securityGroup.resourceGroup, securityGroup.name, "")
 		return nsg.Response, err
 	}); err != nil {
 		return nil, errors.Annotate(err, "querying network security group")
 	}
+	if err := inst.env.ensurePolicySecurityRules(securityGroup, nsg); err != nil {
+		return nil, errors.Annotate(err, "updating network policy")
+	}
 	if nsg.Properties.SecurityRules == nil {
 		return nil, nil
 	}
 
-	vmName := resourceName(names.NewMachineTag(machineId))
-	prefix := instanceNetworkSecurityRulePrefix(instance.Id(vmName))
-	for _, rule := range *nsg.Properties.SecurityRules {
+	prefix := instanceNetworkSecurityRulePrefix(securityGroup, inst.env.Config().UUID(), inst.Id())
+	rules := instanceSecurityRules(*nsg.Properties.SecurityRules, prefix, priorities)
+	return securityRulesPortRanges(rules)
+}
+
+// instanceSecurityRules returns the security rules that were created
+// by OpenPorts for the instance with the given rule name prefix, within
+// the given range of priorities.
+func instanceSecurityRules(
+	rules []network.SecurityRule,
+	prefix string,
+	priorities priorityRange,
+) []network.SecurityRule {
+	var result []network.SecurityRule
+	for _, rule := range rules {
 		if rule.Properties.Direction != network.Inbound {
 			continue
 		}
 		if rule.Properties.Access != network.Allow {
 			continue
 		}
-		if to.Int32(rule.Properties.Priority) <= securityRuleInternalMax {
+		if !priorities.contains(to.Int32(rule.Properties.Priority)) {
 			continue
 		}
 		if !strings.HasPrefix(to.String(rule.Name), prefix) {
 			continue
 		}
+		result = append(result, rule)
+	}
+	return result
+}
 
+// securityRulesPortRanges returns the port ranges opened by the
+// given security rules.
+func securityRulesPortRanges(rules []network.SecurityRule) ([]jujunetwork.PortRange, error) {
+	var ports []jujunetwork.PortRange
+	for _, rule := range rules {
 		var portRange jujunetwork.PortRange
 		if *rule.Properties.DestinationPortRange == "*" {
 			portRange.FromPort = 0
 			portRange.ToPort = 65535
 		} else {
+			var err error
 			portRange, err = jujunetwork.ParsePortRange(
 				*rule.Properties.DestinationPortRange,
 			)
@@ -399,14 +612,186 @@ func (inst *azureInstance) Ports(machineId string) (ports []jujunetwork.PortRang
 	return ports, nil
 }
 
+// makeSecurityRule returns a security rule allowing inbound access
+// to the given port range on the specified address.
+func makeSecurityRule(
+	ruleName string,
+	ports jujunetwork.PortRange,
+	destinationAddress string,
+	priority int32,
+) network.SecurityRule {
+	return network.SecurityRule{
+		Name: to.StringPtr(ruleName),
+		Properties: &network.SecurityRulePropertiesFormat{
+			Description:              to.StringPtr(ports.String()),
+			Protocol:                 securityRuleProtocol(ports.Protocol),
+			SourcePortRange:          to.StringPtr("*"),
+			DestinationPortRange:     to.StringPtr(securityRulePortRange(ports)),
+			SourceAddressPrefix:      to.StringPtr("*"),
+			DestinationAddressPrefix: to.StringPtr(destinationAddress),
+			Access:    network.Allow,
+			Priority:  to.Int32Ptr(priority),
+			Direction: network.Inbound,
+		},
+	}
+}
+
+// securityRuleMatches reports whether the given rule opens exactly
+// the given port range.
+func securityRuleMatches(rule network.SecurityRule, ports jujunetwork.PortRange) bool {
+	return rule.Properties.Protocol == securityRuleProtocol(ports.Protocol) &&
+		to.String(rule.Properties.DestinationPortRange) == securityRulePortRange(ports)
+}
+
+// securityRuleProtocol returns the security rule protocol for the
+// given port range protocol, which must be "tcp", "udp" or "*".
+func securityRuleProtocol(protocol string) network.SecurityRuleProtocol {
+	switch protocol {
+	case "tcp":
+		return network.TCP
+	case "udp":
+		return network.UDP
+	}
+	return network.Asterisk
+}
+
+// securityRulePortRange returns the destination port range for a
+// security rule opening the given ports.
+func securityRulePortRange(ports jujunetwork.PortRange) string {
+	if ports.FromPort != ports.ToPort {
+		return fmt.Sprintf("%d-%d", ports.FromPort, ports.ToPort)
+	}
+	return fmt.Sprint(ports.FromPort)
+}
+
+// validatePortRangeProtocols returns an error if any of the given
+// port ranges has a protocol other than "tcp" or "udp".
+func validatePortRangeProtocols(ports []jujunetwork.PortRange) error {
+	for _, ports := range ports {
+		switch ports.Protocol {
+		case "tcp", "udp":
+		default:
+			return errors.Errorf("invalid protocol %q", ports.Protocol)
+		}
+	}
+	return nil
+}
+
+// consolidatePortRanges merges contiguous and overlapping port ranges
+// with the same protocol, returning the result ordered by protocol and
+// then by port.
+func consolidatePortRanges(ports []jujunetwork.PortRange) []jujunetwork.PortRange {
+	if len(ports) == 0 {
+		return nil
+	}
+	sorted := make([]jujunetwork.PortRange, len(ports))
+	copy(sorted, ports)
+	jujunetwork.SortPortRanges(sorted)
+
+	result := []jujunetwork.PortRange{sorted[0]}
+	for _, ports := range sorted[1:] {
+		last := &result[len(result)-1]
+		if ports.Protocol == last.Protocol && ports.FromPort <= last.ToPort+1 {
+			if ports.ToPort > last.ToPort {
+				last.ToPort = ports.ToPort
+			}
+			continue
+		}
+		result = append(result, ports)
+	}
+	return result
+}
+
+// subtractPortRanges returns the port ranges in ports that are not
+// covered by any of the port ranges in remove, splitting ranges where
+// necessary.
+func subtractPortRanges(ports, remove []jujunetwork.PortRange) []jujunetwork.PortRange {
+	result := ports
+	for _, r := range remove {
+		var next []jujunetwork.PortRange
+		for _, p := range result {
+			if p.Protocol != r.Protocol || r.ToPort < p.FromPort || r.FromPort > p.ToPort {
+				next = append(next, p)
+				continue
+			}
+			if p.FromPort < r.FromPort {
+				next = append(next, jujunetwork.PortRange{
+					Protocol: p.Protocol,
+					FromPort: p.FromPort,
+					ToPort:   r.FromPort - 1,
+				})
+			}
+			if p.ToPort > r.ToPort {
+				next = append(next, jujunetwork.PortRange{
+					Protocol: p.Protocol,
+					FromPort: r.ToPort + 1,
+					ToPort:   p.ToPort,
+				})
+			}
+		}
+		result = next
+	}
+	return result
+}
+
+// securityRulePortRanges returns the consolidated port ranges to
+// create security rules for. A port range that is open for both
+// TCP and UDP is represented by a single range with the protocol
+// "*", so that it requires only one rule.
+func securityRulePortRanges(ports []jujunetwork.PortRange) []jujunetwork.PortRange {
+	ports = consolidatePortRanges(ports)
+	type fromTo struct{ from, to int }
+	udp := make(map[fromTo]bool)
+	for _, p := range ports {
+		if p.Protocol == "udp" {
+			udp[fromTo{p.FromPort, p.ToPort}] = true
+		}
+	}
+	var result []jujunetwork.PortRange
+	for _, p := range ports {
+		key := fromTo{p.FromPort, p.ToPort}
+		switch p.Protocol {
+		case "tcp":
+			if udp[key] {
+				p.Protocol = "*"
+				delete(udp, key)
+			}
+		case "udp":
+			if !udp[key] {
+				// Already consolidated with the TCP range.
+				continue
+			}
+		}
+		result = append(result, p)
+	}
+	jujunetwork.SortPortRanges(result)
+	return result
+}
+
 // deleteInstanceNetworkSecurityRules deletes network security rules in the
-// internal network security group that correspond to the specified machine.
+// given network security group that correspond to the specified machine,
+// in the model with the given UUID.
 //
 // This is expected to delete *all* security rules related to the instance,
 // i.e. both the ones opened by OpenPorts above, and the ones opened for API
 // access.
 func deleteInstanceNetworkSecurityRules(
-	resourceGroup string, id instance.Id,
+	securityGroup securityGroupRef, modelUUID string, id instance.Id,
+	nsgClient network.SecurityGroupsClient,
+	securityRuleClient network.SecurityRulesClient,
+	callAPI callAPIFunc,
+) error {
+	return deleteNetworkSecurityRules(
+		securityGroup,
+		instanceNetworkSecurityRulePrefix(securityGroup, modelUUID, id),
+		nsgClient, securityRuleClient, callAPI,
+	)
+}
+
+// deleteNetworkSecurityRules deletes the network security rules in the
+// given network security group whose names have the given prefix.
+func deleteNetworkSecurityRules(
+	securityGroup securityGroupRef, prefix string,
 	nsgClient network.SecurityGroupsClient,
 	securityRuleClient network.SecurityRulesClient,
 	callAPI callAPIFunc,
@@ -414,7 +799,7 @@ func deleteInstanceNetworkSecurityRules(
 	var nsg network.SecurityGroup
 	if err := callAPI(func() (autorest.Response, error) {
 		var err error
-		nsg, err = nsgClient.Get(resourceGroup, internalSecurityGroupName, "")
+		nsg, err = nsgClient.Get(securityGroup.resourceGroup, securityGroup.name, "")
 		return nsg.Response, err
 	}); err != nil {
 		return errors.Annotate(err, "querying network security group")
@@ -422,7 +807,6 @@ func deleteInstanceNetworkSecurityRules(
 	if nsg.Properties.SecurityRules == nil {
 		return nil
 	}
-	prefix := instanceNetworkSecurityRulePrefix(id)
 	for _, rule := range *nsg.Properties.SecurityRules {
 		ruleName := to.String(rule.Name)
 		if !strings.HasPrefix(ruleName, prefix) {
@@ -432,8 +816,8 @@ func deleteInstanceNetworkSecurityRules(
 		err := callAPI(func() (autorest.Response, error) {
 			var err error
 			result, err = securityRuleClient.Delete(
-				resourceGroup,
-				internalSecurityGroupName,
+				securityGroup.resourceGroup,
+				securityGroup.name,
 				ruleName,
 				nil, // abort channel
 			)
@@ -449,15 +833,34 @@ func deleteInstanceNetworkSecurityRules(
 }
 
 // instanceNetworkSecurityRulePrefix returns the unique prefix for network
-// security rule names that relate to the instance with the given ID.
-func instanceNetworkSecurityRulePrefix(id instance.Id) string {
-	return string(id) + "-"
+// security rule names that relate to the instance with the given ID, in
+// the model with the given UUID, in the given network security group.
+func instanceNetworkSecurityRulePrefix(securityGroup securityGroupRef, modelUUID string, id instance.Id) string {
+	return modelNetworkSecurityRulePrefix(securityGroup, modelUUID) + string(id) + "-"
+}
+
+// modelNetworkSecurityRulePrefix returns the prefix for the names of the
+// network security rules that relate to the model with the given UUID, in
+// the given network security group. An operator-provided group may be
+// shared by several models, whose machines' names are not unique, so
+// the rules in it are prefixed with the model UUID. The group that Juju
+// creates belongs to the model alone, so the rules in it are not.
+func modelNetworkSecurityRulePrefix(securityGroup securityGroupRef, modelUUID string) string {
+	if securityGroup.id == "" {
+		return ""
+	}
+	return modelUUID + "-"
 }
 
 // securityRuleName returns the security rule name for the given port range,
-// and prefix returned by instanceNetworkSecurityRulePrefix.
+// and prefix returned by instanceNetworkSecurityRulePrefix. Port ranges with
+// the protocol "*" are given the protocol name "any".
 func securityRuleName(prefix string, ports jujunetwork.PortRange) string {
-	ruleName := fmt.Sprintf("%s%s-%d", prefix, ports.Protocol, ports.FromPort)
+	protocol := ports.Protocol
+	if protocol == "*" {
+		protocol = "any"
+	}
+	ruleName := fmt.Sprintf("%s%s-%d", prefix, protocol, ports.FromPort)
 	if ports.FromPort != ports.ToPort {
 		ruleName += fmt.Sprintf("-%d", ports.ToPort)
 	}
@@ -108,6 +108,19 @@ func makeIPConfiguration(privateIPAddress string) network.InterfaceIPConfigurati
 	return ipConfiguration
 }
 
+func makePrimaryIPConfiguration(privateIPAddress string) network.InterfaceIPConfiguration {
+	ipConfiguration := makeIPConfiguration(privateIPAddress)
+	ipConfiguration.Properties.Primary = to.BoolPtr(true)
+	ipConfiguration.Properties.Subnet = &network.Subnet{
+		ID: to.StringPtr(path.Join(
+			"/subscriptions", fakeSubscriptionId,
+			"resourceGroups/juju-testenv-model-deadbeef-0bad-400d-8000-4b1d0d06f00d",
+			"providers/Microsoft.Network/virtualnetworks/juju-internal-network/subnets/juju-internal-subnet",
+		)),
+	}
+	return ipConfiguration
+}
+
 func makePublicIPAddress(pipName, vmName, ipAddress string) network.PublicIPAddress {
 	tags := map[string]*string{"juju-machine-name": &vmName}
 	pip := network.PublicIPAddress{
@@ -143,6 +156,13 @@ func makeSecurityRule(name, ipAddress, ports string) network.SecurityRule {
 	}
 }
 
+func makeUDPSecurityRule(name, ipAddress, ports string, priority int32) network.SecurityRule {
+	rule := makeSecurityRule(name, ipAddress, ports)
+	rule.Properties.Protocol = network.UDP
+	rule.Properties.Priority = to.Int32Ptr(priority)
+	return rule
+}
+
 func (s *instanceSuite) getInstance(c *gc.C) instance.Instance {
 	instances := s.getInstances(c, "machine-0")
 	c.Assert(instances, gc.HasLen, 1)
@@ -150,7 +170,11 @@ func (s *instanceSuite) getInstance(c *gc.C) instance.Instance {
 }
 
 func (s *instanceSuite) getInstances(c *gc.C, ids ...instance.Id) []instance.Instance {
-	s.sender = s.getInstancesSender()
+	if len(ids) == 1 {
+		s.sender = s.getInstanceSender(ids[0])
+	} else {
+		s.sender = s.getInstancesSender()
+	}
 	instances, err := s.env.Instances(ids)
 	c.Assert(err, jc.ErrorIsNil)
 	s.sender = azuretesting.Senders{}
@@ -158,20 +182,92 @@ func (s *instanceSuite) getInstances(c *gc.C, ids ...instance.Id) []instance.Ins
 	return instances
 }
 
+// getInstancesSender returns senders for the requests made to list all
+// instances: the deployments, the resources tagged with a machine name,
+// and then the NICs and public IPs of the listed instances.
 func (s *instanceSuite) getInstancesSender() azuretesting.Senders {
 	deploymentsSender := azuretesting.NewSenderWithValue(&resources.DeploymentListResult{
 		Value: &s.deployments,
 	})
 	deploymentsSender.PathPattern = ".*/deployments"
-	nicsSender := azuretesting.NewSenderWithValue(&network.InterfaceListResult{
-		Value: &s.networkInterfaces,
-	})
-	nicsSender.PathPattern = ".*/networkInterfaces"
-	pipsSender := azuretesting.NewSenderWithValue(&network.PublicIPAddressListResult{
-		Value: &s.publicIPAddresses,
+	ids := make(map[instance.Id]bool)
+	for _, deployment := range s.deployments {
+		ids[instance.Id(to.String(deployment.Name))] = true
+	}
+	return append(azuretesting.Senders{deploymentsSender}, s.networkResourcesSenders(ids)...)
+}
+
+// getInstanceSender returns senders for the requests made to get a
+// single instance: the instance's deployment, the resources tagged
+// with its ID, and then each of its NICs and public IPs in turn.
+func (s *instanceSuite) getInstanceSender(id instance.Id) azuretesting.Senders {
+	var senders azuretesting.Senders
+	for _, deployment := range s.deployments {
+		if to.String(deployment.Name) == string(id) {
+			deploymentSender := azuretesting.NewSenderWithValue(&deployment)
+			deploymentSender.PathPattern = ".*/deployments/" + string(id)
+			senders = append(senders, deploymentSender)
+			break
+		}
+	}
+	return append(senders, s.networkResourcesSenders(map[instance.Id]bool{id: true})...)
+}
+
+// networkResourcesSenders returns senders for the requests made to
+// refresh the addresses of the instances with the given IDs: the
+// resources tagged with their IDs, and then each of their NICs and
+// public IPs in turn. Public IPs expanded in one of the NICs are not
+// fetched.
+func (s *instanceSuite) networkResourcesSenders(ids map[instance.Id]bool) azuretesting.Senders {
+	var taggedResources []resources.GenericResource
+	var resourceSenders azuretesting.Senders
+	expanded := make(map[string]bool)
+	for _, nic := range s.networkInterfaces {
+		if !ids[instance.Id(toTags(nic.Tags)["juju-machine-name"])] {
+			continue
+		}
+		for _, ipConfiguration := range *nic.Properties.IPConfigurations {
+			if pip := ipConfiguration.Properties.PublicIPAddress; pip != nil {
+				expanded[to.String(pip.Name)] = true
+			}
+		}
+		taggedResources = append(taggedResources, resources.GenericResource{
+			Name: nic.Name,
+			Type: to.StringPtr("Microsoft.Network/networkInterfaces"),
+			Tags: nic.Tags,
+		})
+		nicSender := azuretesting.NewSenderWithValue(&nic)
+		nicSender.PathPattern = ".*/networkInterfaces/" + to.String(nic.Name)
+		resourceSenders = append(resourceSenders, nicSender)
+	}
+	for _, pip := range s.publicIPAddresses {
+		if !ids[instance.Id(toTags(pip.Tags)["juju-machine-name"])] {
+			continue
+		}
+		taggedResources = append(taggedResources, resources.GenericResource{
+			Name: pip.Name,
+			Type: to.StringPtr("Microsoft.Network/publicIPAddresses"),
+			Tags: pip.Tags,
+		})
+		if expanded[to.String(pip.Name)] {
+			continue
+		}
+		pipSender := azuretesting.NewSenderWithValue(&pip)
+		pipSender.PathPattern = ".*/publicIPAddresses/" + to.String(pip.Name)
+		resourceSenders = append(resourceSenders, pipSender)
+	}
+	resourcesSender := azuretesting.NewSenderWithValue(&resources.ResourceListResult{
+		Value: &taggedResources,
 	})
-	pipsSender.PathPattern = ".*/publicIPAddresses"
-	return azuretesting.Senders{deploymentsSender, nicsSender, pipsSender}
+	resourcesSender.PathPattern = ".*/resources"
+	return append(azuretesting.Senders{resourcesSender}, resourceSenders...)
+}
+
+func toTags(tags *map[string]*string) map[string]string {
+	if tags == nil {
+		return nil
+	}
+	return to.StringMap(*tags)
 }
 
 func networkSecurityGroupSender(rules []network.SecurityRule) *azuretesting.MockSender {
@@ -248,6 +344,188 @@ func (s *instanceSuite) TestInstanceAddresses(c *gc.C) {
 	))
 }
 
+func (s *instanceSuite) TestInstanceAddressesDNSNames(c *gc.C) {
+	nic0 := makeNetworkInterface("nic-0", "machine-0", makeIPConfiguration("10.0.0.4"))
+	nic0.Properties.DNSSettings = &network.InterfaceDNSSettings{
+		InternalDNSNameLabel: to.StringPtr("machine-0"),
+		InternalFqdn:         to.StringPtr("machine-0.abc.internal.cloudapp.net"),
+	}
+	nic1 := makeNetworkInterface("nic-1", "machine-0", makeIPConfiguration("10.0.0.5"))
+	nic1.Properties.DNSSettings = &network.InterfaceDNSSettings{
+		InternalDNSNameLabel:     to.StringPtr("machine-0-secondary"),
+		InternalDomainNameSuffix: to.StringPtr("abc.internal.cloudapp.net"),
+	}
+	s.networkInterfaces = []network.Interface{nic0, nic1}
+
+	pip0 := makePublicIPAddress("pip-0", "machine-0", "1.2.3.4")
+	pip0.Properties.DNSSettings = &network.PublicIPAddressDNSSettings{
+		DomainNameLabel: to.StringPtr("juju-deadbeef-machine-0"),
+		Fqdn:            to.StringPtr("juju-deadbeef-machine-0.westus.cloudapp.azure.com"),
+	}
+	// The FQDN of an unallocated public IP address is not reported.
+	pip1 := makePublicIPAddress("pip-1", "machine-0", "")
+	pip1.Properties.DNSSettings = &network.PublicIPAddressDNSSettings{
+		DomainNameLabel: to.StringPtr("juju-deadbeef-machine-0-secondary"),
+		Fqdn:            to.StringPtr("juju-deadbeef-machine-0-secondary.westus.cloudapp.azure.com"),
+	}
+	s.publicIPAddresses = []network.PublicIPAddress{pip0, pip1}
+
+	addresses, err := s.getInstance(c).Addresses()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(addresses, jc.DeepEquals, []jujunetwork.Address{
+		jujunetwork.NewScopedAddress("machine-0.abc.internal.cloudapp.net", jujunetwork.ScopeCloudLocal),
+		jujunetwork.NewScopedAddress("10.0.0.4", jujunetwork.ScopeCloudLocal),
+		jujunetwork.NewScopedAddress("machine-0-secondary.abc.internal.cloudapp.net", jujunetwork.ScopeCloudLocal),
+		jujunetwork.NewScopedAddress("10.0.0.5", jujunetwork.ScopeCloudLocal),
+		jujunetwork.NewScopedAddress("1.2.3.4", jujunetwork.ScopePublic),
+		jujunetwork.NewScopedAddress("juju-deadbeef-machine-0.westus.cloudapp.azure.com", jujunetwork.ScopePublic),
+	})
+}
+
+func (s *instanceSuite) TestInstanceRequests(c *gc.C) {
+	s.networkInterfaces = []network.Interface{
+		makeNetworkInterface("nic-0", "machine-0", makeIPConfiguration("10.0.0.4")),
+		makeNetworkInterface("nic-1", "machine-1", makeIPConfiguration("10.0.0.5")),
+	}
+	s.publicIPAddresses = []network.PublicIPAddress{
+		makePublicIPAddress("pip-0", "machine-0", "1.2.3.4"),
+	}
+	s.sender = s.getInstanceSender("machine-0")
+	instances, err := s.env.Instances([]instance.Id{"machine-0"})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(instances, gc.HasLen, 1)
+
+	// Only the instance's own deployment and network
+	// resources are fetched; nothing is listed in full.
+	c.Assert(s.requests, gc.HasLen, 4)
+	c.Assert(s.requests[0].URL.Path, gc.Matches, ".*/deployments/machine-0")
+	c.Assert(s.requests[1].URL.Path, gc.Matches, ".*/resources")
+	c.Assert(
+		s.requests[1].URL.Query().Get("$filter"), gc.Equals,
+		"tagname eq 'juju-machine-name' and tagvalue eq 'machine-0'",
+	)
+	c.Assert(s.requests[2].URL.Path, gc.Matches, ".*/networkInterfaces/nic-0")
+	c.Assert(
+		s.requests[2].URL.Query().Get("$expand"), gc.Equals,
+		"ipConfigurations/publicIPAddress",
+	)
+	c.Assert(s.requests[3].URL.Path, gc.Matches, ".*/publicIPAddresses/pip-0")
+
+	addresses, err := instances[0].Addresses()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(addresses, jc.DeepEquals, jujunetwork.NewAddresses(
+		"10.0.0.4", "1.2.3.4",
+	))
+}
+
+func (s *instanceSuite) TestInstanceRequestsExpandedPublicIPAddress(c *gc.C) {
+	pip := makePublicIPAddress("pip-0", "machine-0", "1.2.3.4")
+	ipConfiguration := makeIPConfiguration("10.0.0.4")
+	ipConfiguration.Properties.PublicIPAddress = &pip
+	s.networkInterfaces = []network.Interface{
+		makeNetworkInterface("nic-0", "machine-0", ipConfiguration),
+	}
+	s.publicIPAddresses = []network.PublicIPAddress{pip}
+	s.sender = s.getInstanceSender("machine-0")
+	instances, err := s.env.Instances([]instance.Id{"machine-0"})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(instances, gc.HasLen, 1)
+
+	// The public IP is expanded in the NIC, so it
+	// is not fetched separately.
+	c.Assert(s.requests, gc.HasLen, 3)
+	c.Assert(s.requests[0].URL.Path, gc.Matches, ".*/deployments/machine-0")
+	c.Assert(s.requests[1].URL.Path, gc.Matches, ".*/resources")
+	c.Assert(s.requests[2].URL.Path, gc.Matches, ".*/networkInterfaces/nic-0")
+
+	addresses, err := instances[0].Addresses()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(addresses, jc.DeepEquals, jujunetwork.NewAddresses(
+		"10.0.0.4", "1.2.3.4",
+	))
+}
+
+func (s *instanceSuite) TestAllInstancesRequests(c *gc.C) {
+	pip := makePublicIPAddress("pip-0", "machine-0", "1.2.3.4")
+	ipConfiguration := makeIPConfiguration("10.0.0.4")
+	ipConfiguration.Properties.PublicIPAddress = &pip
+	s.networkInterfaces = []network.Interface{
+		makeNetworkInterface("nic-0", "machine-0", ipConfiguration),
+		makeNetworkInterface("nic-1", "machine-1", makeIPConfiguration("10.0.0.5")),
+	}
+	s.publicIPAddresses = []network.PublicIPAddress{
+		pip,
+		makePublicIPAddress("pip-1", "machine-1", "1.2.3.5"),
+	}
+	s.sender = s.getInstancesSender()
+	instances, err := s.env.AllInstances()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(instances, gc.HasLen, 2)
+
+	// The network interfaces and public IP addresses are not
+	// listed in full; only those tagged with a machine name
+	// are fetched, and public IPs expanded in a NIC are not
+	// fetched separately.
+	c.Assert(s.requests, gc.HasLen, 5)
+	c.Assert(s.requests[0].URL.Path, gc.Matches, ".*/deployments")
+	c.Assert(s.requests[1].URL.Path, gc.Matches, ".*/resources")
+	c.Assert(
+		s.requests[1].URL.Query().Get("$filter"), gc.Equals,
+		"tagname eq 'juju-machine-name'",
+	)
+	c.Assert(s.requests[2].URL.Path, gc.Matches, ".*/networkInterfaces/nic-0")
+	c.Assert(s.requests[3].URL.Path, gc.Matches, ".*/networkInterfaces/nic-1")
+	c.Assert(s.requests[4].URL.Path, gc.Matches, ".*/publicIPAddresses/pip-1")
+
+	inst0Addresses, err := instances[0].Addresses()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(inst0Addresses, jc.DeepEquals, jujunetwork.NewAddresses(
+		"10.0.0.4", "1.2.3.4",
+	))
+	inst1Addresses, err := instances[1].Addresses()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(inst1Addresses, jc.DeepEquals, jujunetwork.NewAddresses(
+		"10.0.0.5", "1.2.3.5",
+	))
+}
+
+func (s *instanceSuite) TestAllInstancesIgnoresUnknownResources(c *gc.C) {
+	// Network resources tagged with the name of a machine that has
+	// no deployment, or with no machine name, are not fetched.
+	s.deployments = s.deployments[:1]
+	resourcesSender := azuretesting.NewSenderWithValue(&resources.ResourceListResult{
+		Value: &[]resources.GenericResource{{
+			Name: to.StringPtr("nic-9"),
+			Type: to.StringPtr("Microsoft.Network/networkInterfaces"),
+			Tags: to.StringMapPtr(map[string]string{"juju-machine-name": "machine-9"}),
+		}, {
+			Name: to.StringPtr("pip-9"),
+			Type: to.StringPtr("Microsoft.Network/publicIPAddresses"),
+		}},
+	})
+	resourcesSender.PathPattern = ".*/resources"
+	deploymentsSender := azuretesting.NewSenderWithValue(&resources.DeploymentListResult{
+		Value: &s.deployments,
+	})
+	deploymentsSender.PathPattern = ".*/deployments"
+	s.sender = azuretesting.Senders{deploymentsSender, resourcesSender}
+	instances, err := s.env.AllInstances()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(instances, gc.HasLen, 1)
+	c.Assert(s.requests, gc.HasLen, 2)
+}
+
+func (s *instanceSuite) TestInstanceNotFound(c *gc.C) {
+	sender := mocks.NewSender()
+	sender.AppendResponse(mocks.NewResponseWithStatus(
+		"deployment not found", http.StatusNotFound,
+	))
+	s.sender = azuretesting.Senders{sender}
+	_, err := s.env.Instances([]instance.Id{"machine-1"})
+	c.Assert(err, gc.Equals, environs.ErrNoInstances)
+	c.Assert(s.requests, gc.HasLen, 1)
+}
+
 func (s *instanceSuite) TestMultipleInstanceAddresses(c *gc.C) {
 	nic0IPConfiguration := makeIPConfiguration("10.0.0.4")
 	nic1IPConfiguration := makeIPConfiguration("10.0.0.5")
@@ -373,14 +651,51 @@ func (s *instanceSuite) TestInstancePorts(c *gc.C) {
 	}})
 }
 
+func (s *instanceSuite) TestInstancePortsReconcilesPolicySecurityRules(c *gc.C) {
+	// The policy was configured while no environ was open to
+	// create the rules enforcing it.
+	s.env = openEnviron(c, s.provider, &s.sender, testing.Attrs{
+		"egress-allow-list": "10.0.0.0/8",
+	})
+	inst := s.getInstance(c)
+	nsgSender := networkSecurityGroupSender(nil)
+	okSender := mocks.NewSender()
+	okSender.AppendResponse(mocks.NewResponseWithContent("{}"))
+	s.sender = azuretesting.Senders{nsgSender, okSender, okSender, okSender}
+
+	_, err := inst.Ports("0")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(s.requests, gc.HasLen, 4)
+	c.Assert(s.requests[1].Method, gc.Equals, "PUT")
+	c.Assert(s.requests[1].URL.Path, gc.Equals, securityRulePath("EgressAllow0"))
+	c.Assert(s.requests[2].Method, gc.Equals, "PUT")
+	c.Assert(s.requests[2].URL.Path, gc.Equals, securityRulePath("EgressAllowJujuAPI"))
+	c.Assert(s.requests[3].Method, gc.Equals, "PUT")
+	c.Assert(s.requests[3].URL.Path, gc.Equals, securityRulePath("EgressDenyInternet"))
+
+	// The policy is reconciled only once.
+	s.sender = azuretesting.Senders{networkSecurityGroupSender(nil)}
+	s.requests = nil
+	_, err = inst.Ports("0")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(s.requests, gc.HasLen, 1)
+}
+
 func (s *instanceSuite) TestInstanceClosePorts(c *gc.C) {
+	s.networkInterfaces = []network.Interface{
+		makeNetworkInterface("nic-0", "machine-0", makePrimaryIPConfiguration("10.0.0.4")),
+	}
 	inst := s.getInstance(c)
+	nsgSender := networkSecurityGroupSender([]network.SecurityRule{
+		makeSecurityRule("machine-0-tcp-1000", "10.0.0.4", "1000"),
+		makeUDPSecurityRule("machine-0-udp-1000-2000", "10.0.0.4", "1000-2000", 201),
+	})
 	sender := mocks.NewSender()
 	notFoundSender := mocks.NewSender()
 	notFoundSender.AppendResponse(mocks.NewResponseWithStatus(
 		"rule not found", http.StatusNotFound,
 	))
-	s.sender = azuretesting.Senders{sender, notFoundSender}
+	s.sender = azuretesting.Senders{nsgSender, sender, notFoundSender}
 
 	err := inst.ClosePorts("0", []jujunetwork.PortRange{{
 		Protocol: "tcp",
@@ -393,11 +708,57 @@ func (s *instanceSuite) TestInstanceClosePorts(c *gc.C) {
 	}})
 	c.Assert(err, jc.ErrorIsNil)
 
-	c.Assert(s.requests, gc.HasLen, 2)
-	c.Assert(s.requests[0].Method, gc.Equals, "DELETE")
-	c.Assert(s.requests[0].URL.Path, gc.Equals, securityRulePath("machine-0-tcp-1000"))
+	c.Assert(s.requests, gc.HasLen, 3)
+	c.Assert(s.requests[0].Method, gc.Equals, "GET")
+	c.Assert(s.requests[0].URL.Path, gc.Equals, internalSecurityGroupPath)
 	c.Assert(s.requests[1].Method, gc.Equals, "DELETE")
-	c.Assert(s.requests[1].URL.Path, gc.Equals, securityRulePath("machine-0-udp-1000-2000"))
+	c.Assert(s.requests[1].URL.Path, gc.Equals, securityRulePath("machine-0-tcp-1000"))
+	c.Assert(s.requests[2].Method, gc.Equals, "DELETE")
+	c.Assert(s.requests[2].URL.Path, gc.Equals, securityRulePath("machine-0-udp-1000-2000"))
+}
+
+func (s *instanceSuite) TestInstanceClosePortsSplitsRange(c *gc.C) {
+	s.networkInterfaces = []network.Interface{
+		makeNetworkInterface("nic-0", "machine-0", makePrimaryIPConfiguration("10.0.0.4")),
+	}
+	inst := s.getInstance(c)
+	nsgSender := networkSecurityGroupSender([]network.SecurityRule{
+		makeSecurityRule("machine-0-tcp-1000-2000", "10.0.0.4", "1000-2000"),
+	})
+	okSender := mocks.NewSender()
+	okSender.AppendResponse(mocks.NewResponseWithContent("{}"))
+	s.sender = azuretesting.Senders{nsgSender, okSender, okSender, okSender}
+
+	err := inst.ClosePorts("0", []jujunetwork.PortRange{{
+		Protocol: "tcp",
+		FromPort: 1500,
+		ToPort:   1500,
+	}})
+	c.Assert(err, jc.ErrorIsNil)
+
+	// The replacement rules are created before
+	// the superseded rule is deleted.
+	c.Assert(s.requests, gc.HasLen, 4)
+	c.Assert(s.requests[0].Method, gc.Equals, "GET")
+	c.Assert(s.requests[1].Method, gc.Equals, "PUT")
+	c.Assert(s.requests[1].URL.Path, gc.Equals, securityRulePath("machine-0-tcp-1000-1499"))
+	assertRequestBody(c, s.requests[1], &network.SecurityRule{
+		Properties: &network.SecurityRulePropertiesFormat{
+			Description:              to.StringPtr("1000-1499/tcp"),
+			Protocol:                 network.TCP,
+			SourcePortRange:          to.StringPtr("*"),
+			SourceAddressPrefix:      to.StringPtr("*"),
+			DestinationPortRange:     to.StringPtr("1000-1499"),
+			DestinationAddressPrefix: to.StringPtr("10.0.0.4"),
+			Access:    network.Allow,
+			Priority:  to.Int32Ptr(201),
+			Direction: network.Inbound,
+		},
+	})
+	c.Assert(s.requests[2].Method, gc.Equals, "PUT")
+	c.Assert(s.requests[2].URL.Path, gc.Equals, securityRulePath("machine-0-tcp-1501-2000"))
+	c.Assert(s.requests[3].Method, gc.Equals, "DELETE")
+	c.Assert(s.requests[3].URL.Path, gc.Equals, securityRulePath("machine-0-tcp-1000-2000"))
 }
 
 func (s *instanceSuite) TestInstanceOpenPorts(c *gc.C) {
@@ -471,6 +832,111 @@ func (s *instanceSuite) TestInstanceOpenPorts(c *gc.C) {
 	})
 }
 
+func (s *instanceSuite) TestInstanceOpenPortsResourceNamePrefix(c *gc.C) {
+	// The security rules are named for the instance,
+	// so that they are deleted along with it.
+	s.env = openEnviron(c, s.provider, &s.sender, testing.Attrs{"resource-name-prefix": "acme"})
+	s.deployments = []resources.DeploymentExtended{makeDeployment("acme-machine-0")}
+	s.networkInterfaces = []network.Interface{
+		makeNetworkInterface("nic-0", "acme-machine-0", makePrimaryIPConfiguration("10.0.0.4")),
+	}
+	instances := s.getInstances(c, "acme-machine-0")
+	c.Assert(instances, gc.HasLen, 1)
+
+	okSender := mocks.NewSender()
+	okSender.AppendResponse(mocks.NewResponseWithContent("{}"))
+	s.sender = azuretesting.Senders{networkSecurityGroupSender(nil), okSender}
+	err := instances[0].OpenPorts("0", []jujunetwork.PortRange{{
+		Protocol: "tcp",
+		FromPort: 1000,
+		ToPort:   1000,
+	}})
+	c.Assert(err, jc.ErrorIsNil)
+
+	c.Assert(s.requests, gc.HasLen, 2)
+	c.Assert(s.requests[1].Method, gc.Equals, "PUT")
+	c.Assert(s.requests[1].URL.Path, gc.Equals, securityRulePath("acme-machine-0-tcp-1000"))
+}
+
+func (s *instanceSuite) TestInstanceOpenPortsExistingSecurityGroup(c *gc.C) {
+	s.env = openEnviron(c, s.provider, &s.sender, testing.Attrs{
+		"network-security-group":   testSecurityGroupID,
+		"security-rule-priorities": "3000-3099",
+	})
+	s.sender = nil
+	s.requests = nil
+	internalSubnetId := path.Join(
+		"/subscriptions", fakeSubscriptionId,
+		"resourceGroups/juju-testenv-model-deadbeef-0bad-400d-8000-4b1d0d06f00d",
+		"providers/Microsoft.Network/virtualnetworks/juju-internal-network/subnets/juju-internal-subnet",
+	)
+	ipConfiguration := network.InterfaceIPConfiguration{
+		Properties: &network.InterfaceIPConfigurationPropertiesFormat{
+			Primary:          to.BoolPtr(true),
+			PrivateIPAddress: to.StringPtr("10.0.0.4"),
+			Subnet: &network.Subnet{
+				ID: to.StringPtr(internalSubnetId),
+			},
+		},
+	}
+	s.networkInterfaces = []network.Interface{
+		makeNetworkInterface("nic-0", "machine-0", ipConfiguration),
+	}
+
+	// The operator's baseline rules are outside the range of
+	// priorities reserved for Juju, and are left alone.
+	baselineRule := network.SecurityRule{
+		Name: to.StringPtr("AllowBastionSSH"),
+		Properties: &network.SecurityRulePropertiesFormat{
+			Protocol:                 network.TCP,
+			SourceAddressPrefix:      to.StringPtr("10.100.0.0/24"),
+			DestinationAddressPrefix: to.StringPtr("*"),
+			DestinationPortRange:     to.StringPtr("22"),
+			Access:                   network.Allow,
+			Priority:                 to.Int32Ptr(200),
+			Direction:                network.Inbound,
+		},
+	}
+	inst := s.getInstance(c)
+	okSender := mocks.NewSender()
+	okSender.AppendResponse(mocks.NewResponseWithContent("{}"))
+	nsgSender := azuretesting.NewSenderWithValue(&network.SecurityGroup{
+		Properties: &network.SecurityGroupPropertiesFormat{
+			SecurityRules: &[]network.SecurityRule{baselineRule},
+		},
+	})
+	nsgSender.PathPattern = ".*/networkSecurityGroups/baseline-nsg"
+	s.sender = azuretesting.Senders{nsgSender, okSender}
+
+	err := inst.OpenPorts("0", []jujunetwork.PortRange{{
+		Protocol: "tcp",
+		FromPort: 1000,
+		ToPort:   1000,
+	}})
+	c.Assert(err, jc.ErrorIsNil)
+
+	c.Assert(s.requests, gc.HasLen, 2)
+	c.Assert(s.requests[0].Method, gc.Equals, "GET")
+	c.Assert(s.requests[0].URL.Path, gc.Equals, testSecurityGroupID)
+	c.Assert(s.requests[1].Method, gc.Equals, "PUT")
+	// The group may be shared by other models, so the
+	// rule is named for the model as well as the machine.
+	c.Assert(s.requests[1].URL.Path, gc.Equals, testSecurityGroupID+"/securityRules/"+testing.ModelTag.Id()+"-machine-0-tcp-1000")
+	assertRequestBody(c, s.requests[1], &network.SecurityRule{
+		Properties: &network.SecurityRulePropertiesFormat{
+			Description:              to.StringPtr("1000/tcp"),
+			Protocol:                 network.TCP,
+			SourcePortRange:          to.StringPtr("*"),
+			SourceAddressPrefix:      to.StringPtr("*"),
+			DestinationPortRange:     to.StringPtr("1000"),
+			DestinationAddressPrefix: to.StringPtr("10.0.0.4"),
+			Access:                   network.Allow,
+			Priority:                 to.Int32Ptr(3000),
+			Direction:                network.Inbound,
+		},
+	})
+}
+
 func (s *instanceSuite) TestInstanceOpenPortsAlreadyOpen(c *gc.C) {
 	internalSubnetId := path.Join(
 		"/subscriptions", fakeSubscriptionId,
@@ -496,7 +962,7 @@ func (s *instanceSuite) TestInstanceOpenPortsAlreadyOpen(c *gc.C) {
 	nsgSender := networkSecurityGroupSender([]network.SecurityRule{{
 		Name: to.StringPtr("machine-0-tcp-1000"),
 		Properties: &network.SecurityRulePropertiesFormat{
-			Protocol:             network.Asterisk,
+			Protocol:             network.TCP,
 			DestinationPortRange: to.StringPtr("1000"),
 			Access:               network.Allow,
 			Priority:             to.Int32Ptr(202),
@@ -536,6 +1002,158 @@ func (s *instanceSuite) TestInstanceOpenPortsAlreadyOpen(c *gc.C) {
 	})
 }
 
+func (s *instanceSuite) TestInstanceOpenPortsConsolidates(c *gc.C) {
+	s.networkInterfaces = []network.Interface{
+		makeNetworkInterface("nic-0", "machine-0", makePrimaryIPConfiguration("10.0.0.4")),
+	}
+	inst := s.getInstance(c)
+	nsgSender := networkSecurityGroupSender([]network.SecurityRule{
+		makeSecurityRule("machine-0-tcp-80", "10.0.0.4", "80"),
+	})
+	okSender := mocks.NewSender()
+	okSender.AppendResponse(mocks.NewResponseWithContent("{}"))
+	s.sender = azuretesting.Senders{nsgSender, okSender, okSender}
+
+	// The TCP ranges are contiguous or overlapping, and
+	// match the UDP range, so only one rule is required.
+	err := inst.OpenPorts("0", []jujunetwork.PortRange{{
+		Protocol: "tcp",
+		FromPort: 81,
+		ToPort:   90,
+	}, {
+		Protocol: "tcp",
+		FromPort: 85,
+		ToPort:   100,
+	}, {
+		Protocol: "udp",
+		FromPort: 80,
+		ToPort:   100,
+	}})
+	c.Assert(err, jc.ErrorIsNil)
+
+	c.Assert(s.requests, gc.HasLen, 3)
+	c.Assert(s.requests[0].Method, gc.Equals, "GET")
+	c.Assert(s.requests[1].Method, gc.Equals, "PUT")
+	c.Assert(s.requests[1].URL.Path, gc.Equals, securityRulePath("machine-0-any-80-100"))
+	assertRequestBody(c, s.requests[1], &network.SecurityRule{
+		Properties: &network.SecurityRulePropertiesFormat{
+			Description:              to.StringPtr("80-100/*"),
+			Protocol:                 network.Asterisk,
+			SourcePortRange:          to.StringPtr("*"),
+			SourceAddressPrefix:      to.StringPtr("*"),
+			DestinationPortRange:     to.StringPtr("80-100"),
+			DestinationAddressPrefix: to.StringPtr("10.0.0.4"),
+			Access:    network.Allow,
+			Priority:  to.Int32Ptr(201),
+			Direction: network.Inbound,
+		},
+	})
+	c.Assert(s.requests[2].Method, gc.Equals, "DELETE")
+	c.Assert(s.requests[2].URL.Path, gc.Equals, securityRulePath("machine-0-tcp-80"))
+}
+
+func (s *instanceSuite) TestInstanceOpenPortsRollback(c *gc.C) {
+	s.networkInterfaces = []network.Interface{
+		makeNetworkInterface("nic-0", "machine-0", makePrimaryIPConfiguration("10.0.0.4")),
+	}
+	inst := s.getInstance(c)
+	nsgSender := networkSecurityGroupSender([]network.SecurityRule{
+		makeSecurityRule("machine-0-tcp-80", "10.0.0.4", "80"),
+	})
+	okSender := mocks.NewSender()
+	okSender.AppendResponse(mocks.NewResponseWithContent("{}"))
+	failSender := mocks.NewSender()
+	failSender.AppendResponse(mocks.NewResponseWithStatus("", http.StatusBadRequest))
+	s.sender = azuretesting.Senders{nsgSender, okSender, failSender, okSender}
+
+	err := inst.OpenPorts("0", []jujunetwork.PortRange{{
+		Protocol: "tcp",
+		FromPort: 1000,
+		ToPort:   1000,
+	}, {
+		Protocol: "udp",
+		FromPort: 2000,
+		ToPort:   2000,
+	}})
+	c.Assert(err, gc.ErrorMatches, "creating security rule for 2000/udp: .*")
+
+	// The rule created before the failure is deleted,
+	// and the pre-existing rule is left alone.
+	c.Assert(s.requests, gc.HasLen, 4)
+	c.Assert(s.requests[0].Method, gc.Equals, "GET")
+	c.Assert(s.requests[1].Method, gc.Equals, "PUT")
+	c.Assert(s.requests[1].URL.Path, gc.Equals, securityRulePath("machine-0-tcp-1000"))
+	c.Assert(s.requests[2].Method, gc.Equals, "PUT")
+	c.Assert(s.requests[2].URL.Path, gc.Equals, securityRulePath("machine-0-udp-2000"))
+	c.Assert(s.requests[3].Method, gc.Equals, "DELETE")
+	c.Assert(s.requests[3].URL.Path, gc.Equals, securityRulePath("machine-0-tcp-1000"))
+}
+
+func (s *instanceSuite) TestInstanceOpenPortsRuleLimit(c *gc.C) {
+	s.networkInterfaces = []network.Interface{
+		makeNetworkInterface("nic-0", "machine-0", makePrimaryIPConfiguration("10.0.0.4")),
+	}
+	inst := s.getInstance(c)
+
+	// 100 disjoint port ranges fill the instance's band of rules.
+	okSender := mocks.NewSender()
+	okSender.AppendResponse(mocks.NewResponseWithContent("{}"))
+	s.sender = azuretesting.Senders{networkSecurityGroupSender(nil)}
+	var ports []jujunetwork.PortRange
+	for i := 0; i < 100; i++ {
+		ports = append(ports, jujunetwork.PortRange{
+			Protocol: "tcp",
+			FromPort: 1000 + i*2,
+			ToPort:   1000 + i*2,
+		})
+		s.sender = append(s.sender, okSender)
+	}
+	err := inst.OpenPorts("0", ports)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(s.requests, gc.HasLen, 101)
+	assertRequestBody(c, s.requests[100], &network.SecurityRule{
+		Properties: &network.SecurityRulePropertiesFormat{
+			Description:              to.StringPtr("1198/tcp"),
+			Protocol:                 network.TCP,
+			SourcePortRange:          to.StringPtr("*"),
+			SourceAddressPrefix:      to.StringPtr("*"),
+			DestinationPortRange:     to.StringPtr("1198"),
+			DestinationAddressPrefix: to.StringPtr("10.0.0.4"),
+			Access:    network.Allow,
+			Priority:  to.Int32Ptr(299),
+			Direction: network.Inbound,
+		},
+	})
+
+	// One more disjoint port range exceeds the limit,
+	// and no changes are made.
+	s.requests = nil
+	s.sender = azuretesting.Senders{networkSecurityGroupSender(nil)}
+	err = inst.OpenPorts("0", append(ports, jujunetwork.PortRange{
+		Protocol: "tcp",
+		FromPort: 2000,
+		ToPort:   2000,
+	}))
+	c.Assert(err, gc.ErrorMatches, `101 security rules required for machine "0", exceeding the limit of 100`)
+	c.Assert(s.requests, gc.HasLen, 1)
+
+	// Filling in the gaps allows the ranges to be
+	// consolidated into a single rule.
+	s.requests = nil
+	s.sender = azuretesting.Senders{networkSecurityGroupSender(nil), okSender}
+	for i := 0; i < 100; i++ {
+		ports = append(ports, jujunetwork.PortRange{
+			Protocol: "tcp",
+			FromPort: 1001 + i*2,
+			ToPort:   1001 + i*2,
+		})
+	}
+	err = inst.OpenPorts("0", ports)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(s.requests, gc.HasLen, 2)
+	c.Assert(s.requests[1].URL.Path, gc.Equals, securityRulePath("machine-0-tcp-1000-1199"))
+}
+
 func (s *instanceSuite) TestInstanceOpenPortsNoInternalAddress(c *gc.C) {
 	err := s.getInstance(c).OpenPorts("0", nil)
 	c.Assert(err, gc.ErrorMatches, "internal network address not found")
@@ -0,0 +1,67 @@
+// Copyright 2016 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package azure
+
+import (
+	"github.com/Azure/azure-sdk-for-go/arm/compute"
+	"github.com/juju/errors"
+	"github.com/juju/utils/arch"
+
+	"github.com/juju/juju/environs"
+	"github.com/juju/juju/environs/instances"
+)
+
+var _ environs.InstanceSpecPreviewer = (*azureEnviron)(nil)
+
+// PreviewInstanceSpec is specified in the environs.InstanceSpecPreviewer
+// interface. The instance type and image are chosen as by StartInstance.
+// Azure has no availability zones, so none is reported.
+func (env *azureEnviron) PreviewInstanceSpec(
+	args environs.PreviewInstanceSpecParams,
+) (*environs.InstanceSpecPreview, error) {
+	if args.Placement != "" {
+		if _, err := parsePlacement(args.Placement); err != nil {
+			return nil, errors.Trace(err)
+		}
+	}
+
+	env.mu.Lock()
+	imageStream := env.config.ImageStream()
+	instanceTypes, err := env.getInstanceTypesLocked()
+	env.mu.Unlock()
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+
+	cons := args.Constraints
+	if cons.RootDisk == nil {
+		rootDisk := uint64(defaultRootDiskSize)
+		cons.RootDisk = &rootDisk
+	}
+	arches := arch.AllSupportedArches
+	if cons.Arch != nil {
+		arches = []string{*cons.Arch}
+	}
+	instanceSpec, err := findInstanceSpec(
+		compute.VirtualMachineImagesClient{env.compute},
+		env.provider.imageCache,
+		instanceTypes,
+		&instances.InstanceConstraint{
+			Region:      env.location,
+			Series:      args.Series,
+			Arches:      arches,
+			Constraints: cons,
+		},
+		imageStream,
+	)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	return &environs.InstanceSpecPreview{
+		InstanceType: instanceSpec.InstanceType.Name,
+		ImageId:      instanceSpec.Image.Id,
+		Arch:         instanceSpec.Image.Arch,
+		Cost:         instanceSpec.InstanceType.Cost,
+	}, nil
+}
@@ -129,6 +129,7 @@ func mbToMib(mb uint64) uint64 {
 // Azure's image registry.
 func findInstanceSpec(
 	client compute.VirtualMachineImagesClient,
+	imageCache *imageutils.Cache,
 	instanceTypesMap map[string]instances.InstanceType,
 	constraint *instances.InstanceConstraint,
 	imageStream string,
@@ -139,7 +140,7 @@ func findInstanceSpec(
 		return nil, errors.NotFoundf("%s in arch constraints", arch.AMD64)
 	}
 
-	image, err := imageutils.SeriesImage(constraint.Series, imageStream, constraint.Region, client)
+	image, err := imageCache.SeriesImage(constraint.Series, imageStream, constraint.Region, client)
 	if err != nil {
 		return nil, errors.Trace(err)
 	}
@@ -4,6 +4,8 @@
 package azuretesting
 
 import (
+	"net/http"
+
 	"github.com/Azure/azure-sdk-for-go/storage"
 	"github.com/juju/testing"
 
@@ -21,6 +23,7 @@ type MockStorageClient struct {
 func (c *MockStorageClient) NewClient(
 	accountName, accountKey, blobServiceBaseURL, apiVersion string,
 	useHTTPS bool,
+	httpClient *http.Client,
 ) (azurestorage.Client, error) {
 	c.AddCall("NewClient", accountName, accountKey, blobServiceBaseURL, apiVersion, useHTTPS)
 	return c, c.NextErr()
@@ -4,10 +4,14 @@
 package errorutils
 
 import (
+	"net/http"
+
 	"github.com/juju/errors"
 
 	"github.com/Azure/go-autorest/autorest"
 	"github.com/Azure/go-autorest/autorest/azure"
+
+	"github.com/juju/juju/environs"
 )
 
 // ServiceError returns the *azure.ServiceError underlying the
@@ -23,3 +27,81 @@ func ServiceError(err error) (*azure.ServiceError, bool) {
 	}
 	return nil, false
 }
+
+// serviceErrorKinds maps Azure service error codes to the kinds of
+// provisioning error that they describe.
+var serviceErrorKinds = map[string]environs.ProvisioningErrorKind{
+	"QuotaExceeded":                    environs.ProvisioningErrorQuota,
+	"OperationNotAllowed":              environs.ProvisioningErrorQuota,
+	"AuthorizationFailed":              environs.ProvisioningErrorAuth,
+	"InvalidAuthenticationToken":       environs.ProvisioningErrorAuth,
+	"ExpiredAuthenticationToken":       environs.ProvisioningErrorAuth,
+	"AllocationFailed":                 environs.ProvisioningErrorCapacity,
+	"ZonalAllocationFailed":            environs.ProvisioningErrorCapacity,
+	"OverconstrainedAllocationRequest": environs.ProvisioningErrorCapacity,
+	"SkuNotAvailable":                  environs.ProvisioningErrorCapacity,
+	"InvalidParameter":                 environs.ProvisioningErrorConfig,
+	"InvalidTemplate":                  environs.ProvisioningErrorConfig,
+	"InvalidTemplateDeployment":        environs.ProvisioningErrorConfig,
+	policyDeniedCode:                   environs.ProvisioningErrorPolicy,
+}
+
+// statusCodeKinds maps HTTP response status codes to the kinds of
+// provisioning error that they describe, for errors whose service
+// error code is not recognised.
+var statusCodeKinds = map[int]environs.ProvisioningErrorKind{
+	http.StatusUnauthorized: environs.ProvisioningErrorAuth,
+	http.StatusForbidden:    environs.ProvisioningErrorAuth,
+	http.StatusBadRequest:   environs.ProvisioningErrorConfig,
+}
+
+// ProvisioningErrorKind returns the kind of provisioning error described
+// by the Azure service error code or response status code underlying the
+// supplied error, or environs.ProvisioningErrorUnknown if the error does
+// not identify the cause of the failure.
+func ProvisioningErrorKind(err error) environs.ProvisioningErrorKind {
+	if len(PolicyViolations(err)) > 0 {
+		// Policy denials may be reported as details of
+		// another error, e.g. InvalidTemplateDeployment.
+		return environs.ProvisioningErrorPolicy
+	}
+	if serviceErr, ok := ServiceError(err); ok && serviceErr != nil {
+		// Template deployment and validation failures are reported
+		// as InvalidTemplateDeployment, with the underlying causes
+		// described by the error's details.
+		for _, detail := range serviceErrorDetails(serviceErr) {
+			if kind, ok := serviceErrorKinds[detail.Code]; ok {
+				return kind
+			}
+		}
+		if kind, ok := serviceErrorKinds[serviceErr.Code]; ok {
+			return kind
+		}
+	}
+	if d, ok := errors.Cause(err).(autorest.DetailedError); ok {
+		if statusCode, ok := d.StatusCode.(int); ok {
+			if kind, ok := statusCodeKinds[statusCode]; ok {
+				return kind
+			}
+		}
+	}
+	return environs.ProvisioningErrorUnknown
+}
+
+// ClassifyProvisioningError returns the supplied error, classified with
+// environs.NewProvisioningError if its kind can be determined by
+// ProvisioningErrorKind. If the request was denied by Azure Policy, the
+// returned error describes the violated policies in place of the raw
+// service error.
+func ClassifyProvisioningError(err error) error {
+	if violations := PolicyViolations(err); len(violations) > 0 {
+		return environs.NewProvisioningError(
+			&policyError{violations}, environs.ProvisioningErrorPolicy,
+		)
+	}
+	kind := ProvisioningErrorKind(err)
+	if kind == environs.ProvisioningErrorUnknown {
+		return err
+	}
+	return environs.NewProvisioningError(err, kind)
+}
@@ -0,0 +1,96 @@
+// Copyright 2016 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package errorutils_test
+
+import (
+	"net/http"
+
+	"github.com/Azure/go-autorest/autorest"
+	"github.com/Azure/go-autorest/autorest/azure"
+	"github.com/juju/errors"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/environs"
+	"github.com/juju/juju/provider/azure/internal/errorutils"
+)
+
+type errorsSuite struct{}
+
+var _ = gc.Suite(&errorsSuite{})
+
+func serviceError(statusCode int, code string) error {
+	return autorest.DetailedError{
+		Original: &azure.RequestError{
+			ServiceError: &azure.ServiceError{Code: code},
+		},
+		StatusCode: statusCode,
+	}
+}
+
+func deploymentError(detailCodes ...string) error {
+	details := make([]interface{}, len(detailCodes))
+	for i, code := range detailCodes {
+		details[i] = map[string]interface{}{"code": code, "message": "computer says no"}
+	}
+	return autorest.DetailedError{
+		Original: &azure.RequestError{
+			ServiceError: &azure.ServiceError{
+				Code:    "InvalidTemplateDeployment",
+				Details: &details,
+			},
+		},
+		StatusCode: http.StatusBadRequest,
+	}
+}
+
+func (s *errorsSuite) TestProvisioningErrorKind(c *gc.C) {
+	for i, test := range []struct {
+		err  error
+		kind environs.ProvisioningErrorKind
+	}{{
+		err:  serviceError(http.StatusConflict, "QuotaExceeded"),
+		kind: environs.ProvisioningErrorQuota,
+	}, {
+		err:  serviceError(http.StatusConflict, "AllocationFailed"),
+		kind: environs.ProvisioningErrorCapacity,
+	}, {
+		err:  serviceError(http.StatusForbidden, "AuthorizationFailed"),
+		kind: environs.ProvisioningErrorAuth,
+	}, {
+		err:  serviceError(http.StatusUnauthorized, "Unrecognised"),
+		kind: environs.ProvisioningErrorAuth,
+	}, {
+		err:  serviceError(http.StatusBadRequest, "InvalidParameter"),
+		kind: environs.ProvisioningErrorConfig,
+	}, {
+		err:  errors.Annotate(serviceError(http.StatusBadRequest, "Unrecognised"), "creating virtual machine"),
+		kind: environs.ProvisioningErrorConfig,
+	}, {
+		err:  deploymentError("SkuNotAvailable"),
+		kind: environs.ProvisioningErrorCapacity,
+	}, {
+		err:  deploymentError("Unrecognised", "QuotaExceeded"),
+		kind: environs.ProvisioningErrorQuota,
+	}, {
+		err:  deploymentError("Unrecognised"),
+		kind: environs.ProvisioningErrorConfig,
+	}, {
+		err:  serviceError(http.StatusInternalServerError, "InternalError"),
+		kind: environs.ProvisioningErrorUnknown,
+	}, {
+		err:  errors.New("boom"),
+		kind: environs.ProvisioningErrorUnknown,
+	}} {
+		c.Logf("test %d: %v", i, test.err)
+		c.Check(errorutils.ProvisioningErrorKind(test.err), gc.Equals, test.kind)
+	}
+}
+
+func (s *errorsSuite) TestClassifyProvisioningError(c *gc.C) {
+	err := errorutils.ClassifyProvisioningError(serviceError(http.StatusConflict, "QuotaExceeded"))
+	c.Assert(environs.ProvisioningErrorKindOf(err), gc.Equals, environs.ProvisioningErrorQuota)
+
+	plain := errors.New("boom")
+	c.Assert(errorutils.ClassifyProvisioningError(plain), gc.Equals, plain)
+}
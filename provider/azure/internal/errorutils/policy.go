@@ -0,0 +1,252 @@
+// Copyright 2016 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package errorutils
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/Azure/go-autorest/autorest/azure"
+)
+
+// policyDeniedCode is the Azure service error code returned when a
+// request is denied by an Azure Policy assignment.
+const policyDeniedCode = "RequestDisallowedByPolicy"
+
+var (
+	// policyResourceRegexp extracts the name of the denied resource
+	// from the message of a policy denial.
+	policyResourceRegexp = regexp.MustCompile(`Resource '([^']*)' was disallowed by policy`)
+
+	// policyIdentifiersRegexp extracts the JSON-encoded policy
+	// identifiers from the message of a policy denial.
+	policyIdentifiersRegexp = regexp.MustCompile(`Policy identifiers: '(.*)'`)
+)
+
+// PolicyViolation describes the denial of a request by an Azure Policy.
+type PolicyViolation struct {
+	// Resource is the name of the resource that was denied.
+	Resource string
+
+	// PolicyAssignment is the name of the policy assignment that
+	// denied the request.
+	PolicyAssignment string
+
+	// PolicyDefinition is the name of the policy definition that
+	// denied the request.
+	PolicyDefinition string
+
+	// Property is the path of the resource property that violated
+	// the policy, e.g. "location", if Azure reported it.
+	Property string
+
+	// Value is the value of the property that violated the policy,
+	// and Allowed the values that the policy permits, if Azure
+	// reported them.
+	Value   string
+	Allowed string
+}
+
+// String returns a description of the policy violation.
+func (v PolicyViolation) String() string {
+	var buf []string
+	if v.Resource != "" {
+		buf = append(buf, fmt.Sprintf("resource %q", v.Resource))
+	} else {
+		buf = append(buf, "request")
+	}
+	policy := v.PolicyAssignment
+	if policy == "" {
+		policy = v.PolicyDefinition
+	}
+	if policy != "" {
+		buf = append(buf, fmt.Sprintf("denied by policy %q", policy))
+	} else {
+		buf = append(buf, "denied by policy")
+	}
+	if v.Property != "" {
+		property := fmt.Sprintf("(property %q", v.Property)
+		if v.Value != "" {
+			property += fmt.Sprintf(" has value %s", v.Value)
+			if v.Allowed != "" {
+				property += fmt.Sprintf(", policy allows %s", v.Allowed)
+			}
+		}
+		buf = append(buf, property+")")
+	}
+	return strings.Join(buf, " ")
+}
+
+// PolicyViolations returns the policy violations described by the
+// Azure service error underlying the supplied error, including those
+// reported as details of the error, such as the failures of a template
+// deployment. If the error does not describe any policy violations,
+// then PolicyViolations returns nil.
+func PolicyViolations(err error) []PolicyViolation {
+	serviceErr, ok := ServiceError(err)
+	if !ok || serviceErr == nil {
+		return nil
+	}
+	var violations []PolicyViolation
+	if serviceErr.Code == policyDeniedCode {
+		violations = append(violations, parsePolicyViolation(serviceErrorDetail{
+			Code:    serviceErr.Code,
+			Message: serviceErr.Message,
+		})...)
+	}
+	for _, detail := range serviceErrorDetails(serviceErr) {
+		if detail.Code == policyDeniedCode {
+			violations = append(violations, parsePolicyViolation(detail)...)
+		}
+	}
+	return violations
+}
+
+// serviceErrorDetails returns the details of the given service error.
+// The details are decoded generically; they are round-tripped through
+// JSON to extract the fields we are interested in.
+func serviceErrorDetails(serviceErr *azure.ServiceError) []serviceErrorDetail {
+	if serviceErr.Details == nil {
+		return nil
+	}
+	data, err := json.Marshal(serviceErr.Details)
+	if err != nil {
+		return nil
+	}
+	var details []serviceErrorDetail
+	if err := json.Unmarshal(data, &details); err != nil {
+		return nil
+	}
+	return details
+}
+
+// serviceErrorDetail is an error detail in an Azure service error.
+type serviceErrorDetail struct {
+	Code           string                   `json:"code"`
+	Target         string                   `json:"target"`
+	Message        string                   `json:"message"`
+	AdditionalInfo []serviceErrorAdditional `json:"additionalInfo"`
+}
+
+// serviceErrorAdditional is additional information in an Azure service
+// error; policy violations are described by information of the type
+// "PolicyViolation".
+type serviceErrorAdditional struct {
+	Type string `json:"type"`
+	Info struct {
+		PolicyAssignmentName        string `json:"policyAssignmentName"`
+		PolicyAssignmentDisplayName string `json:"policyAssignmentDisplayName"`
+		PolicyDefinitionName        string `json:"policyDefinitionName"`
+		PolicyDefinitionDisplayName string `json:"policyDefinitionDisplayName"`
+		EvaluationDetails           struct {
+			EvaluatedExpressions []struct {
+				Path            string      `json:"path"`
+				ExpressionValue interface{} `json:"expressionValue"`
+				TargetValue     interface{} `json:"targetValue"`
+			} `json:"evaluatedExpressions"`
+		} `json:"evaluationDetails"`
+	} `json:"info"`
+}
+
+// policyIdentifier identifies a policy assignment and definition in the
+// message of a policy denial.
+type policyIdentifier struct {
+	PolicyAssignment struct {
+		Name string `json:"name"`
+	} `json:"policyAssignment"`
+	PolicyDefinition struct {
+		Name string `json:"name"`
+	} `json:"policyDefinition"`
+}
+
+// parsePolicyViolation returns the policy violations described by
+// the given RequestDisallowedByPolicy error. Structured additional
+// information is used if present; otherwise the policies are parsed
+// from the error message. At least one violation is always returned.
+func parsePolicyViolation(detail serviceErrorDetail) []PolicyViolation {
+	resource := detail.Target
+	if match := policyResourceRegexp.FindStringSubmatch(detail.Message); match != nil {
+		resource = match[1]
+	}
+
+	var violations []PolicyViolation
+	for _, info := range detail.AdditionalInfo {
+		if info.Type != "PolicyViolation" {
+			continue
+		}
+		v := PolicyViolation{
+			Resource:         resource,
+			PolicyAssignment: firstNonEmpty(info.Info.PolicyAssignmentDisplayName, info.Info.PolicyAssignmentName),
+			PolicyDefinition: firstNonEmpty(info.Info.PolicyDefinitionDisplayName, info.Info.PolicyDefinitionName),
+		}
+		if exprs := info.Info.EvaluationDetails.EvaluatedExpressions; len(exprs) > 0 {
+			v.Property = exprs[0].Path
+			v.Value = jsonString(exprs[0].ExpressionValue)
+			v.Allowed = jsonString(exprs[0].TargetValue)
+		}
+		violations = append(violations, v)
+	}
+	if len(violations) > 0 {
+		return violations
+	}
+
+	if match := policyIdentifiersRegexp.FindStringSubmatch(detail.Message); match != nil {
+		var ids []policyIdentifier
+		if err := json.Unmarshal([]byte(match[1]), &ids); err == nil {
+			for _, id := range ids {
+				violations = append(violations, PolicyViolation{
+					Resource:         resource,
+					PolicyAssignment: id.PolicyAssignment.Name,
+					PolicyDefinition: id.PolicyDefinition.Name,
+				})
+			}
+		}
+	}
+	if len(violations) == 0 {
+		violations = append(violations, PolicyViolation{Resource: resource})
+	}
+	return violations
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+func jsonString(v interface{}) string {
+	if v == nil {
+		return ""
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(data)
+}
+
+// policyError is an error describing requests denied by Azure Policy.
+// It replaces the raw service error, which is not meaningful to users,
+// with a description of the violated policies.
+type policyError struct {
+	violations []PolicyViolation
+}
+
+// Error is part of the error interface.
+func (e *policyError) Error() string {
+	descriptions := make([]string, len(e.violations))
+	for i, v := range e.violations {
+		descriptions[i] = v.String()
+	}
+	return fmt.Sprintf(
+		"%s; ask the subscription administrator to change the policy, "+
+			"or change the model config or constraints to comply with it",
+		strings.Join(descriptions, "; "),
+	)
+}
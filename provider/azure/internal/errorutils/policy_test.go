@@ -0,0 +1,125 @@
+// Copyright 2016 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package errorutils_test
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/Azure/go-autorest/autorest"
+	"github.com/Azure/go-autorest/autorest/azure"
+	"github.com/juju/errors"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/environs"
+	"github.com/juju/juju/provider/azure/internal/errorutils"
+)
+
+type policySuite struct{}
+
+var _ = gc.Suite(&policySuite{})
+
+const policyMessage = `Resource 'machine-0' was disallowed by policy. ` +
+	`Policy identifiers: '[{"policyAssignment":{"name":"Allowed locations","id":"/subscriptions/sub/providers/Microsoft.Authorization/policyAssignments/abc"},` +
+	`"policyDefinition":{"name":"Allowed locations","id":"/providers/Microsoft.Authorization/policyDefinitions/def"}}]'.`
+
+func policyError(message string, details ...string) error {
+	serviceError := &azure.ServiceError{
+		Code:    "RequestDisallowedByPolicy",
+		Message: message,
+	}
+	if len(details) > 0 {
+		serviceError.Code = "InvalidTemplateDeployment"
+		serviceError.Message = "The template deployment failed because of policy violation."
+		decoded := make([]interface{}, len(details))
+		for i, detail := range details {
+			if err := json.Unmarshal([]byte(detail), &decoded[i]); err != nil {
+				panic(err)
+			}
+		}
+		serviceError.Details = &decoded
+	}
+	return autorest.DetailedError{
+		Original:   &azure.RequestError{ServiceError: serviceError},
+		StatusCode: http.StatusForbidden,
+	}
+}
+
+func (s *policySuite) TestPolicyViolationsFromMessage(c *gc.C) {
+	violations := errorutils.PolicyViolations(policyError(policyMessage))
+	c.Assert(violations, jc.DeepEquals, []errorutils.PolicyViolation{{
+		Resource:         "machine-0",
+		PolicyAssignment: "Allowed locations",
+		PolicyDefinition: "Allowed locations",
+	}})
+}
+
+func (s *policySuite) TestPolicyViolationsUnparseableMessage(c *gc.C) {
+	violations := errorutils.PolicyViolations(policyError("computer says no"))
+	c.Assert(violations, jc.DeepEquals, []errorutils.PolicyViolation{{}})
+	c.Assert(violations[0].String(), gc.Equals, "request denied by policy")
+}
+
+func (s *policySuite) TestPolicyViolationsFromDetails(c *gc.C) {
+	err := policyError("", `{
+		"code": "RequestDisallowedByPolicy",
+		"target": "machine-0",
+		"message": "Resource 'machine-0' was disallowed by policy.",
+		"additionalInfo": [{
+			"type": "PolicyViolation",
+			"info": {
+				"policyAssignmentName": "abc",
+				"policyAssignmentDisplayName": "Allowed VM sizes",
+				"policyDefinitionName": "def",
+				"evaluationDetails": {
+					"evaluatedExpressions": [{
+						"path": "Microsoft.Compute/virtualMachines/sku.name",
+						"expressionValue": "Standard_D2",
+						"targetValue": ["Standard_A1", "Standard_A2"]
+					}]
+				}
+			}
+		}]
+	}`, `{"code": "InvalidParameter", "message": "irrelevant"}`)
+	violations := errorutils.PolicyViolations(err)
+	c.Assert(violations, jc.DeepEquals, []errorutils.PolicyViolation{{
+		Resource:         "machine-0",
+		PolicyAssignment: "Allowed VM sizes",
+		PolicyDefinition: "def",
+		Property:         "Microsoft.Compute/virtualMachines/sku.name",
+		Value:            `"Standard_D2"`,
+		Allowed:          `["Standard_A1","Standard_A2"]`,
+	}})
+	c.Assert(violations[0].String(), gc.Equals,
+		`resource "machine-0" denied by policy "Allowed VM sizes" `+
+			`(property "Microsoft.Compute/virtualMachines/sku.name" has value "Standard_D2", `+
+			`policy allows ["Standard_A1","Standard_A2"])`,
+	)
+}
+
+func (s *policySuite) TestPolicyViolationsNone(c *gc.C) {
+	c.Assert(errorutils.PolicyViolations(serviceError(http.StatusConflict, "QuotaExceeded")), gc.IsNil)
+	c.Assert(errorutils.PolicyViolations(errors.New("boom")), gc.IsNil)
+}
+
+func (s *policySuite) TestProvisioningErrorKind(c *gc.C) {
+	err := errors.Annotate(policyError(policyMessage), "creating virtual machine")
+	c.Assert(errorutils.ProvisioningErrorKind(err), gc.Equals, environs.ProvisioningErrorPolicy)
+
+	err = policyError("", `{"code": "RequestDisallowedByPolicy", "message": "no"}`)
+	c.Assert(errorutils.ProvisioningErrorKind(err), gc.Equals, environs.ProvisioningErrorPolicy)
+}
+
+func (s *policySuite) TestClassifyProvisioningError(c *gc.C) {
+	err := errorutils.ClassifyProvisioningError(
+		errors.Annotate(policyError(policyMessage), "creating virtual machine"),
+	)
+	c.Assert(environs.ProvisioningErrorKindOf(err), gc.Equals, environs.ProvisioningErrorPolicy)
+	c.Assert(err, gc.ErrorMatches,
+		`resource "machine-0" denied by policy "Allowed locations"; `+
+			`ask the subscription administrator to change the policy, `+
+			`or change the model config or constraints to comply with it`,
+	)
+}
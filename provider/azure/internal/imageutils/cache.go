@@ -0,0 +1,91 @@
+// Copyright 2016 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package imageutils
+
+import (
+	"sync"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/arm/compute"
+	"github.com/juju/errors"
+	"github.com/juju/utils/clock"
+
+	"github.com/juju/juju/environs/instances"
+)
+
+// Cache is a time-bounded cache of resolved series images. A Cache may
+// be shared by multiple environs, so that the publisher, offering and
+// SKU of an image are resolved at most once per period for each series,
+// stream and location.
+type Cache struct {
+	clock clock.Clock
+	ttl   time.Duration
+
+	mu      sync.Mutex
+	entries map[cacheKey]cacheEntry
+}
+
+type cacheKey struct {
+	series   string
+	stream   string
+	location string
+}
+
+type cacheEntry struct {
+	image   instances.Image
+	expires time.Time
+}
+
+// NewCache returns a new Cache, whose entries expire after the specified
+// duration, as measured by the given clock.
+func NewCache(clock clock.Clock, ttl time.Duration) *Cache {
+	return &Cache{
+		clock:   clock,
+		ttl:     ttl,
+		entries: make(map[cacheKey]cacheEntry),
+	}
+}
+
+// SeriesImage returns the cached instances.Image for the specified series,
+// image stream and location, if there is an unexpired one. Otherwise, the
+// image is resolved as for the SeriesImage function, and cached.
+func (c *Cache) SeriesImage(
+	series, stream, location string,
+	client compute.VirtualMachineImagesClient,
+) (*instances.Image, error) {
+	key := cacheKey{series, stream, location}
+	c.mu.Lock()
+	entry, ok := c.entries[key]
+	c.mu.Unlock()
+	if ok && c.clock.Now().Before(entry.expires) {
+		image := entry.image
+		return &image, nil
+	}
+
+	// The lock is not held while resolving the image, so concurrent
+	// callers may resolve the same image; the last one wins.
+	image, err := SeriesImage(series, stream, location, client)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	c.mu.Lock()
+	c.entries[key] = cacheEntry{
+		image:   *image,
+		expires: c.clock.Now().Add(c.ttl),
+	}
+	c.mu.Unlock()
+	return image, nil
+}
+
+// InvalidateStream removes all cached images for the specified
+// image stream.
+func (c *Cache) InvalidateStream(stream string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for key := range c.entries {
+		if key.stream == stream {
+			delete(c.entries, key)
+		}
+	}
+}
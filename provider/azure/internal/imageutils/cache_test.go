@@ -0,0 +1,66 @@
+// Copyright 2016 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package imageutils_test
+
+import (
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/arm/compute"
+	"github.com/Azure/go-autorest/autorest/mocks"
+	gitjujutesting "github.com/juju/testing"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/provider/azure/internal/imageutils"
+	"github.com/juju/juju/testing"
+)
+
+type cacheSuite struct {
+	testing.BaseSuite
+
+	mockSender *mocks.Sender
+	client     compute.VirtualMachineImagesClient
+	clock      *gitjujutesting.Clock
+	cache      *imageutils.Cache
+}
+
+var _ = gc.Suite(&cacheSuite{})
+
+func (s *cacheSuite) SetUpTest(c *gc.C) {
+	s.BaseSuite.SetUpTest(c)
+	s.mockSender = mocks.NewSender()
+	s.client.ManagementClient = compute.New("subscription-id")
+	s.client.Sender = s.mockSender
+	s.clock = gitjujutesting.NewClock(time.Time{})
+	s.cache = imageutils.NewCache(s.clock, time.Hour)
+
+	s.mockSender.AppendResponse(mocks.NewResponseWithContent(`[{"name": "14.04.3"}]`))
+	s.mockSender.AppendResponse(mocks.NewResponseWithContent(`[{"name": "14.04.4"}]`))
+}
+
+func (s *cacheSuite) TestSeriesImageCached(c *gc.C) {
+	s.assertImageId(c, "released", "Canonical:UbuntuServer:14.04.3:latest")
+	s.clock.Advance(time.Hour - time.Nanosecond)
+	s.assertImageId(c, "released", "Canonical:UbuntuServer:14.04.3:latest")
+}
+
+func (s *cacheSuite) TestSeriesImageExpires(c *gc.C) {
+	s.assertImageId(c, "released", "Canonical:UbuntuServer:14.04.3:latest")
+	s.clock.Advance(time.Hour)
+	s.assertImageId(c, "released", "Canonical:UbuntuServer:14.04.4:latest")
+}
+
+func (s *cacheSuite) TestInvalidateStream(c *gc.C) {
+	s.assertImageId(c, "released", "Canonical:UbuntuServer:14.04.3:latest")
+	s.cache.InvalidateStream("daily")
+	s.assertImageId(c, "released", "Canonical:UbuntuServer:14.04.3:latest")
+	s.cache.InvalidateStream("released")
+	s.assertImageId(c, "released", "Canonical:UbuntuServer:14.04.4:latest")
+}
+
+func (s *cacheSuite) assertImageId(c *gc.C, stream, id string) {
+	image, err := s.cache.SeriesImage("trusty", stream, "westus", s.client)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(image.Id, gc.Equals, id)
+}
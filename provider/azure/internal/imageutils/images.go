@@ -37,6 +37,10 @@ const (
 	windowsOffering  = "Windows"
 
 	dailyStream = "daily"
+
+	// windowsMinRootDiskMB is the size of the Windows images'
+	// OS disks, which cannot be made smaller.
+	windowsMinRootDiskMB = 127 * 1024
 )
 
 // SeriesImage gets an instances.Image for the specified series, image stream
@@ -55,6 +59,7 @@ func SeriesImage(
 	}
 
 	var publisher, offering, sku string
+	var minRootDisk uint64
 	switch seriesOS {
 	case os.Ubuntu:
 		publisher = ubuntuPublisher
@@ -65,6 +70,7 @@ func SeriesImage(
 		}
 
 	case os.Windows:
+		minRootDisk = windowsMinRootDiskMB
 		switch series {
 		case "win81":
 			publisher = windowsPublisher
@@ -102,9 +108,10 @@ func SeriesImage(
 	}
 
 	return &instances.Image{
-		Id:       fmt.Sprintf("%s:%s:%s:latest", publisher, offering, sku),
-		Arch:     arch.AMD64,
-		VirtType: "Hyper-V",
+		Id:          fmt.Sprintf("%s:%s:%s:latest", publisher, offering, sku),
+		Arch:        arch.AMD64,
+		VirtType:    "Hyper-V",
+		MinRootDisk: minRootDisk,
 	}, nil
 }
 
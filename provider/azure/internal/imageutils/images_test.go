@@ -66,6 +66,17 @@ func (s *imageutilsSuite) TestSeriesImageWindows(c *gc.C) {
 	s.assertImageId(c, "win10", "daily", "MicrosoftVisualStudio:Windows:10-Enterprise:latest")
 }
 
+func (s *imageutilsSuite) TestSeriesImageWindowsMinRootDisk(c *gc.C) {
+	image, err := imageutils.SeriesImage("win2012r2", "daily", "westus", s.client)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(image, jc.DeepEquals, &instances.Image{
+		Id:          "MicrosoftWindowsServer:WindowsServer:2012-R2-Datacenter:latest",
+		Arch:        arch.AMD64,
+		VirtType:    "Hyper-V",
+		MinRootDisk: 127 * 1024,
+	})
+}
+
 func (s *imageutilsSuite) TestSeriesImageCentOS(c *gc.C) {
 	s.assertImageId(c, "centos7", "released", "OpenLogic:CentOS:7.1:latest")
 }
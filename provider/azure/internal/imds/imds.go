@@ -0,0 +1,109 @@
+// Copyright 2016 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+// Package imds provides a client for the Azure Instance Metadata
+// Service, which virtual machines may query, without credentials,
+// to discover information about themselves.
+package imds
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/Azure/go-autorest/autorest"
+	"github.com/juju/errors"
+)
+
+const (
+	// DefaultEndpoint is the endpoint of the Instance Metadata
+	// Service. It is reachable only from Azure virtual machines.
+	DefaultEndpoint = "http://169.254.169.254"
+
+	// APIVersion is the version of the Instance Metadata
+	// Service API used by Client.
+	APIVersion = "2017-08-01"
+
+	// defaultTimeout is the timeout for requests made with the
+	// default sender. The service is local to the host, so a
+	// request that takes longer than this is not going to
+	// succeed, e.g. because we are not running in Azure.
+	defaultTimeout = 10 * time.Second
+)
+
+// Compute holds the compute metadata of a virtual machine.
+type Compute struct {
+	// Name is the name of the virtual machine.
+	Name string `json:"name"`
+
+	// Location is the Azure location (region)
+	// that the virtual machine is running in.
+	Location string `json:"location"`
+
+	// ResourceGroupName is the name of the resource
+	// group that contains the virtual machine.
+	ResourceGroupName string `json:"resourceGroupName"`
+
+	// SubscriptionID is the ID of the subscription
+	// that contains the virtual machine.
+	SubscriptionID string `json:"subscriptionId"`
+
+	// VMID is the unique ID of the virtual machine.
+	VMID string `json:"vmId"`
+}
+
+// Client is a client for the Instance Metadata Service.
+type Client struct {
+	// Endpoint is the endpoint of the service. If Endpoint
+	// is empty, DefaultEndpoint is used.
+	Endpoint string
+
+	// Sender is used to send requests to the service. If Sender
+	// is nil, an HTTP client that does not use proxies is used;
+	// requests to the service must not be proxied.
+	Sender autorest.Sender
+}
+
+// Compute returns the compute metadata of the virtual
+// machine that the client is running on.
+func (c Client) Compute() (*Compute, error) {
+	endpoint := c.Endpoint
+	if endpoint == "" {
+		endpoint = DefaultEndpoint
+	}
+	req, err := autorest.Prepare(
+		&http.Request{},
+		autorest.AsGet(),
+		autorest.WithBaseURL(endpoint),
+		autorest.WithPath("/metadata/instance/compute"),
+		autorest.WithQueryParameters(map[string]interface{}{
+			"api-version": APIVersion,
+		}),
+		autorest.WithHeader("Metadata", "true"),
+	)
+	if err != nil {
+		return nil, errors.Annotate(err, "preparing request")
+	}
+
+	sender := c.Sender
+	if sender == nil {
+		sender = &http.Client{
+			Transport: &http.Transport{Proxy: nil},
+			Timeout:   defaultTimeout,
+		}
+	}
+	resp, err := autorest.SendWithSender(sender, req)
+	if err != nil {
+		return nil, errors.Annotate(err, "querying instance metadata service")
+	}
+
+	var result Compute
+	if err := autorest.Respond(
+		resp,
+		autorest.WithErrorUnlessStatusCode(http.StatusOK),
+		autorest.ByUnmarshallingJSON(&result),
+		autorest.ByClosing(),
+	); err != nil {
+		return nil, errors.Annotate(err, "reading compute metadata")
+	}
+	return &result, nil
+}
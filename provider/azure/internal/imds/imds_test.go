@@ -0,0 +1,65 @@
+// Copyright 2016 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package imds_test
+
+import (
+	"net/http"
+
+	"github.com/Azure/go-autorest/autorest"
+	"github.com/Azure/go-autorest/autorest/mocks"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/provider/azure/internal/azuretesting"
+	"github.com/juju/juju/provider/azure/internal/imds"
+	"github.com/juju/juju/testing"
+)
+
+type imdsSuite struct {
+	testing.BaseSuite
+}
+
+var _ = gc.Suite(&imdsSuite{})
+
+func (s *imdsSuite) TestCompute(c *gc.C) {
+	sender := azuretesting.NewSenderWithValue(map[string]string{
+		"name":              "machine-0",
+		"location":          "westus",
+		"resourceGroupName": "juju-testmodel-deadbeef",
+		"subscriptionId":    "22222222-2222-2222-2222-222222222222",
+		"vmId":              "13f56399-bd52-4150-9748-7190aae1ff21",
+		"vmSize":            "Standard_D1",
+	})
+	sender.PathPattern = "^/metadata/instance/compute$"
+	var requests []*http.Request
+	client := imds.Client{
+		Endpoint: "http://imds.invalid",
+		Sender: autorest.SenderFunc(func(req *http.Request) (*http.Response, error) {
+			requests = append(requests, req)
+			return sender.Do(req)
+		}),
+	}
+
+	compute, err := client.Compute()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(compute, jc.DeepEquals, &imds.Compute{
+		Name:              "machine-0",
+		Location:          "westus",
+		ResourceGroupName: "juju-testmodel-deadbeef",
+		SubscriptionID:    "22222222-2222-2222-2222-222222222222",
+		VMID:              "13f56399-bd52-4150-9748-7190aae1ff21",
+	})
+	c.Assert(requests, gc.HasLen, 1)
+	c.Assert(requests[0].URL.Host, gc.Equals, "imds.invalid")
+	c.Assert(requests[0].Header.Get("Metadata"), gc.Equals, "true")
+	c.Assert(requests[0].URL.Query().Get("api-version"), gc.Equals, imds.APIVersion)
+}
+
+func (s *imdsSuite) TestComputeError(c *gc.C) {
+	sender := mocks.NewSender()
+	sender.AppendResponse(mocks.NewResponseWithStatus("not found", http.StatusNotFound))
+	client := imds.Client{Sender: sender}
+	_, err := client.Compute()
+	c.Assert(err, gc.ErrorMatches, "reading compute metadata: .*404.*")
+}
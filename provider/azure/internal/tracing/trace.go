@@ -0,0 +1,305 @@
+// Copyright 2016 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package tracing
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"github.com/Azure/go-autorest/autorest"
+	"github.com/juju/errors"
+	"github.com/juju/utils"
+	"github.com/juju/utils/clock"
+)
+
+const (
+	// OperationIDHeader is the name of the request header that
+	// carries the ID of the Juju operation a request is made for.
+	OperationIDHeader = "x-juju-operation-id"
+
+	// ClientRequestIDHeader is the name of the request header that
+	// carries the client-generated ID of a request. Azure records
+	// the ID in its logs, and echoes it in the response.
+	ClientRequestIDHeader = "x-ms-client-request-id"
+
+	// CorrelationIDHeader is the name of the response header that
+	// carries the ID that Azure uses to correlate the requests it
+	// makes internally in servicing a request.
+	CorrelationIDHeader = "x-ms-correlation-request-id"
+
+	// RequestIDHeader is the name of the response header that
+	// carries the ID that Azure assigned to a request.
+	RequestIDHeader = "x-ms-request-id"
+
+	returnClientRequestIDHeader = "x-ms-return-client-request-id"
+)
+
+// maxMachineRecords is the maximum number of requests recorded
+// for each machine. Once reached, the oldest records are dropped.
+const maxMachineRecords = 50
+
+// Operation identifies a Juju operation on a machine, for which
+// requests are made to Azure.
+type Operation struct {
+	// ID uniquely identifies the operation. The ID is derived from
+	// the machine tag and action.
+	ID string
+
+	// Model is the UUID of the model containing the machine.
+	Model string
+
+	// Machine is the tag of the machine.
+	Machine string
+
+	// Action names the operation, e.g. "start-instance".
+	Action string
+}
+
+// Record records a request made to Azure for an operation.
+type Record struct {
+	// Operation is the operation that the request was made for.
+	Operation Operation
+
+	// Method is the HTTP method of the request.
+	Method string
+
+	// Path is the path of the request URL.
+	Path string
+
+	// ClientRequestID is the client-generated ID of the request.
+	ClientRequestID string
+
+	// CorrelationID and RequestID are the IDs assigned to the request
+	// by Azure. They are empty until a response has been received.
+	CorrelationID string
+	RequestID     string
+
+	// StatusCode is the HTTP status code of the response, or zero
+	// if no response has been received.
+	StatusCode int
+
+	// Started is the time at which the request was prepared.
+	Started time.Time
+
+	// Latency is the time between the request being prepared and
+	// the response being received.
+	Latency time.Duration
+}
+
+// machineKey identifies a machine across models. Machine tags are
+// only unique within a model, and a provider is shared by the models
+// of a controller.
+type machineKey struct {
+	model   string
+	machine string
+}
+
+// Tracer attaches operation IDs to the requests made to Azure, and
+// records the requests and their responses by model and machine.
+type Tracer struct {
+	clock   clock.Clock
+	newUUID func() (utils.UUID, error)
+
+	mu       sync.Mutex
+	machines map[machineKey][]*Record
+	pending  map[string]*Record
+}
+
+// NewTracer returns a new Tracer that uses the given clock to measure
+// request latencies, and newUUID to generate request IDs.
+func NewTracer(clock clock.Clock, newUUID func() (utils.UUID, error)) *Tracer {
+	return &Tracer{
+		clock:    clock,
+		newUUID:  newUUID,
+		machines: make(map[machineKey][]*Record),
+		pending:  make(map[string]*Record),
+	}
+}
+
+// NewOperation returns a new Operation with the given action on the
+// machine with the given tag, in the model with the given UUID.
+func (t *Tracer) NewOperation(modelUUID, machineTag, action string) (Operation, error) {
+	uuid, err := t.newUUID()
+	if err != nil {
+		return Operation{}, errors.Annotate(err, "generating operation ID")
+	}
+	return Operation{
+		ID:      fmt.Sprintf("%s.%s.%s", machineTag, action, uuid.String()[:8]),
+		Model:   modelUUID,
+		Machine: machineTag,
+		Action:  action,
+	}, nil
+}
+
+// PrepareDecorator returns an autorest.PrepareDecorator that attaches
+// the operation and a new client request ID to each request, and
+// records the request against the operation's machine.
+func (t *Tracer) PrepareDecorator(op Operation) autorest.PrepareDecorator {
+	return func(p autorest.Preparer) autorest.Preparer {
+		return autorest.PreparerFunc(func(r *http.Request) (*http.Request, error) {
+			uuid, err := t.newUUID()
+			if err != nil {
+				return nil, errors.Annotate(err, "generating client request ID")
+			}
+			clientRequestID := uuid.String()
+			if r.Header == nil {
+				r.Header = make(http.Header)
+			}
+			r.Header.Set(OperationIDHeader, op.ID)
+			r.Header.Set(ClientRequestIDHeader, clientRequestID)
+			r.Header.Set(returnClientRequestIDHeader, "true")
+			record := &Record{
+				Operation:       op,
+				Method:          r.Method,
+				ClientRequestID: clientRequestID,
+				Started:         t.clock.Now(),
+			}
+			if r.URL != nil {
+				record.Path = r.URL.Path
+			}
+			t.addRecord(record)
+			return p.Prepare(r)
+		})
+	}
+}
+
+// RespondDecorator returns an autorest.RespondDecorator that records
+// the latency and Azure request IDs of responses to requests that
+// were prepared by the tracer.
+func (t *Tracer) RespondDecorator() autorest.RespondDecorator {
+	return func(r autorest.Responder) autorest.Responder {
+		return autorest.ResponderFunc(func(resp *http.Response) error {
+			if resp != nil && resp.Request != nil {
+				t.recordResponse(resp)
+			}
+			return r.Respond(resp)
+		})
+	}
+}
+
+func (t *Tracer) addRecord(record *Record) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	key := machineKey{record.Operation.Model, record.Operation.Machine}
+	records := append(t.machines[key], record)
+	if len(records) > maxMachineRecords {
+		dropped := records[0]
+		delete(t.pending, dropped.ClientRequestID)
+		records = records[1:]
+	}
+	t.machines[key] = records
+	t.pending[record.ClientRequestID] = record
+}
+
+func (t *Tracer) recordResponse(resp *http.Response) {
+	clientRequestID := resp.Request.Header.Get(ClientRequestIDHeader)
+	if clientRequestID == "" {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	record, ok := t.pending[clientRequestID]
+	if !ok {
+		return
+	}
+	delete(t.pending, clientRequestID)
+	record.StatusCode = resp.StatusCode
+	record.CorrelationID = resp.Header.Get(CorrelationIDHeader)
+	record.RequestID = resp.Header.Get(RequestIDHeader)
+	record.Latency = t.clock.Now().Sub(record.Started)
+}
+
+// MachineRecords returns the records of the requests most recently
+// made for the machine with the given tag in the model with the given
+// UUID, oldest first.
+func (t *Tracer) MachineRecords(modelUUID, machineTag string) []Record {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.machineRecords(machineKey{modelUUID, machineTag})
+}
+
+func (t *Tracer) machineRecords(key machineKey) []Record {
+	records := make([]Record, len(t.machines[key]))
+	for i, record := range t.machines[key] {
+		records[i] = *record
+	}
+	return records
+}
+
+// WriteTrace writes a table of the requests recorded for the machine
+// with the given tag in the model with the given UUID to w. If
+// modelUUID is empty, the requests for machines with the tag in all
+// models are written; if machineTag is empty, the requests for all
+// machines in the model are written. If there are no requests
+// recorded, an error satisfying errors.IsNotFound is returned.
+func (t *Tracer) WriteTrace(w io.Writer, modelUUID, machineTag string) error {
+	t.mu.Lock()
+	var keys []machineKey
+	for key := range t.machines {
+		if modelUUID != "" && key.model != modelUUID {
+			continue
+		}
+		if machineTag != "" && key.machine != machineTag {
+			continue
+		}
+		keys = append(keys, key)
+	}
+	sort.Sort(byModelMachine(keys))
+	var records []Record
+	for _, key := range keys {
+		records = append(records, t.machineRecords(key)...)
+	}
+	t.mu.Unlock()
+
+	if len(records) == 0 {
+		switch {
+		case modelUUID != "" && machineTag != "":
+			return errors.NotFoundf("requests for %s in model %s", machineTag, modelUUID)
+		case machineTag != "":
+			return errors.NotFoundf("requests for %s", machineTag)
+		case modelUUID != "":
+			return errors.NotFoundf("requests for model %s", modelUUID)
+		}
+		return errors.NotFoundf("requests")
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 1, 1, ' ', 0)
+	fmt.Fprintln(tw, "MODEL\tMACHINE\tOPERATION\tSTARTED\tMETHOD\tPATH\tSTATUS\tLATENCY\tCLIENT-REQUEST-ID\tCORRELATION-ID")
+	for _, record := range records {
+		status, latency := "pending", "-"
+		if record.StatusCode != 0 {
+			status = fmt.Sprint(record.StatusCode)
+			latency = record.Latency.String()
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+			record.Operation.Model,
+			record.Operation.Machine,
+			record.Operation.ID,
+			record.Started.UTC().Format(time.RFC3339),
+			record.Method,
+			record.Path,
+			status,
+			latency,
+			record.ClientRequestID,
+			record.CorrelationID,
+		)
+	}
+	return errors.Trace(tw.Flush())
+}
+
+type byModelMachine []machineKey
+
+func (s byModelMachine) Len() int      { return len(s) }
+func (s byModelMachine) Swap(i, j int) { s[i], s[j] = s[j], s[i] }
+func (s byModelMachine) Less(i, j int) bool {
+	if s[i].model != s[j].model {
+		return s[i].model < s[j].model
+	}
+	return s[i].machine < s[j].machine
+}
@@ -0,0 +1,204 @@
+// Copyright 2016 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package tracing_test
+
+import (
+	"bytes"
+	"net/http"
+	"time"
+
+	"github.com/Azure/go-autorest/autorest"
+	"github.com/juju/errors"
+	"github.com/juju/testing"
+	jc "github.com/juju/testing/checkers"
+	"github.com/juju/utils"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/provider/azure/internal/tracing"
+)
+
+type TracerSuite struct {
+	testing.IsolationSuite
+	clock  *testing.Clock
+	uuids  []string
+	tracer *tracing.Tracer
+}
+
+var _ = gc.Suite(&TracerSuite{})
+
+func (s *TracerSuite) SetUpTest(c *gc.C) {
+	s.IsolationSuite.SetUpTest(c)
+	s.clock = testing.NewClock(time.Date(2016, 10, 1, 12, 0, 0, 0, time.UTC))
+	s.uuids = []string{
+		"11111111-1111-4111-8111-111111111111",
+		"22222222-2222-4222-8222-222222222222",
+		"33333333-3333-4333-8333-333333333333",
+	}
+	s.tracer = tracing.NewTracer(s.clock, s.newUUID)
+}
+
+func (s *TracerSuite) newUUID() (utils.UUID, error) {
+	if len(s.uuids) == 0 {
+		return utils.UUID{}, errors.New("no more UUIDs")
+	}
+	uuid := s.uuids[0]
+	s.uuids = s.uuids[1:]
+	return utils.UUIDFromString(uuid)
+}
+
+func (s *TracerSuite) newOperation(c *gc.C) tracing.Operation {
+	return s.newModelOperation(c, "model-uuid")
+}
+
+func (s *TracerSuite) newModelOperation(c *gc.C, modelUUID string) tracing.Operation {
+	op, err := s.tracer.NewOperation(modelUUID, "machine-0", "start-instance")
+	c.Assert(err, jc.ErrorIsNil)
+	return op
+}
+
+// do prepares a request with the tracer's decorator, and passes the
+// given response through the tracer's response decorator.
+func (s *TracerSuite) do(c *gc.C, op tracing.Operation, resp *http.Response) *http.Request {
+	req, err := http.NewRequest("PUT", "https://management.azure.com/subscriptions/sub/deployments/machine-0?api-version=1", nil)
+	c.Assert(err, jc.ErrorIsNil)
+	req, err = autorest.Prepare(req, s.tracer.PrepareDecorator(op))
+	c.Assert(err, jc.ErrorIsNil)
+	if resp != nil {
+		s.clock.Advance(1500 * time.Millisecond)
+		resp.Request = req
+		err = autorest.Respond(resp, s.tracer.RespondDecorator())
+		c.Assert(err, jc.ErrorIsNil)
+	}
+	return req
+}
+
+func newResponse(statusCode int) *http.Response {
+	header := make(http.Header)
+	header.Set(tracing.CorrelationIDHeader, "correlation-id")
+	header.Set(tracing.RequestIDHeader, "request-id")
+	return &http.Response{StatusCode: statusCode, Header: header}
+}
+
+func (s *TracerSuite) TestNewOperation(c *gc.C) {
+	op := s.newOperation(c)
+	c.Assert(op, jc.DeepEquals, tracing.Operation{
+		ID:      "machine-0.start-instance.11111111",
+		Model:   "model-uuid",
+		Machine: "machine-0",
+		Action:  "start-instance",
+	})
+}
+
+func (s *TracerSuite) TestPrepareDecoratorSetsHeaders(c *gc.C) {
+	op := s.newOperation(c)
+	req := s.do(c, op, nil)
+	c.Assert(req.Header.Get(tracing.OperationIDHeader), gc.Equals, "machine-0.start-instance.11111111")
+	c.Assert(req.Header.Get(tracing.ClientRequestIDHeader), gc.Equals, "22222222-2222-4222-8222-222222222222")
+	c.Assert(req.Header.Get("x-ms-return-client-request-id"), gc.Equals, "true")
+}
+
+func (s *TracerSuite) TestRecordsRequestAndResponse(c *gc.C) {
+	op := s.newOperation(c)
+	s.do(c, op, newResponse(http.StatusCreated))
+	c.Assert(s.tracer.MachineRecords("model-uuid", "machine-0"), jc.DeepEquals, []tracing.Record{{
+		Operation:       op,
+		Method:          "PUT",
+		Path:            "/subscriptions/sub/deployments/machine-0",
+		ClientRequestID: "22222222-2222-4222-8222-222222222222",
+		CorrelationID:   "correlation-id",
+		RequestID:       "request-id",
+		StatusCode:      http.StatusCreated,
+		Started:         time.Date(2016, 10, 1, 12, 0, 0, 0, time.UTC),
+		Latency:         1500 * time.Millisecond,
+	}})
+	c.Assert(s.tracer.MachineRecords("model-uuid", "machine-1"), gc.HasLen, 0)
+	c.Assert(s.tracer.MachineRecords("other-model-uuid", "machine-0"), gc.HasLen, 0)
+}
+
+func (s *TracerSuite) TestUntracedResponse(c *gc.C) {
+	err := autorest.Respond(&http.Response{
+		StatusCode: http.StatusOK,
+		Request:    &http.Request{Header: http.Header{}},
+	}, s.tracer.RespondDecorator())
+	c.Assert(err, jc.ErrorIsNil)
+}
+
+func (s *TracerSuite) TestPrepareDecoratorUUIDError(c *gc.C) {
+	op := s.newOperation(c)
+	s.uuids = nil
+	req, err := http.NewRequest("GET", "https://management.azure.com/", nil)
+	c.Assert(err, jc.ErrorIsNil)
+	_, err = autorest.Prepare(req, s.tracer.PrepareDecorator(op))
+	c.Assert(err, gc.ErrorMatches, "generating client request ID: no more UUIDs")
+}
+
+func (s *TracerSuite) TestWriteTrace(c *gc.C) {
+	op := s.newOperation(c)
+	s.do(c, op, newResponse(http.StatusCreated))
+	s.do(c, op, nil)
+
+	var buf bytes.Buffer
+	err := s.tracer.WriteTrace(&buf, "model-uuid", "machine-0")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(buf.String(), gc.Equals, `
+MODEL      MACHINE   OPERATION                         STARTED              METHOD PATH                                     STATUS  LATENCY CLIENT-REQUEST-ID                    CORRELATION-ID
+model-uuid machine-0 machine-0.start-instance.11111111 2016-10-01T12:00:00Z PUT    /subscriptions/sub/deployments/machine-0 201     1.5s    22222222-2222-4222-8222-222222222222 correlation-id
+model-uuid machine-0 machine-0.start-instance.11111111 2016-10-01T12:00:01Z PUT    /subscriptions/sub/deployments/machine-0 pending -       33333333-3333-4333-8333-333333333333 
+`[1:])
+
+	buf.Reset()
+	err = s.tracer.WriteTrace(&buf, "", "")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(buf.String(), gc.Matches, "(?s)MODEL .*machine-0.*machine-0.*")
+}
+
+func (s *TracerSuite) TestWriteTraceNotFound(c *gc.C) {
+	var buf bytes.Buffer
+	err := s.tracer.WriteTrace(&buf, "", "machine-0")
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+	c.Assert(err, gc.ErrorMatches, "requests for machine-0 not found")
+	err = s.tracer.WriteTrace(&buf, "model-uuid", "machine-0")
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+	c.Assert(err, gc.ErrorMatches, "requests for machine-0 in model model-uuid not found")
+	err = s.tracer.WriteTrace(&buf, "model-uuid", "")
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+	c.Assert(err, gc.ErrorMatches, "requests for model model-uuid not found")
+	err = s.tracer.WriteTrace(&buf, "", "")
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+}
+
+func (s *TracerSuite) TestRecordsKeyedByModel(c *gc.C) {
+	s.tracer = tracing.NewTracer(s.clock, utils.NewUUID)
+	op0 := s.newModelOperation(c, "model-0")
+	op1 := s.newModelOperation(c, "model-1")
+	s.do(c, op0, newResponse(http.StatusCreated))
+	s.do(c, op1, nil)
+	s.do(c, op1, nil)
+
+	records := s.tracer.MachineRecords("model-0", "machine-0")
+	c.Assert(records, gc.HasLen, 1)
+	c.Assert(records[0].Operation, jc.DeepEquals, op0)
+	records = s.tracer.MachineRecords("model-1", "machine-0")
+	c.Assert(records, gc.HasLen, 2)
+	c.Assert(records[0].Operation, jc.DeepEquals, op1)
+
+	var buf bytes.Buffer
+	err := s.tracer.WriteTrace(&buf, "model-1", "machine-0")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(buf.String(), gc.Not(gc.Matches), "(?s).*model-0.*")
+
+	buf.Reset()
+	err = s.tracer.WriteTrace(&buf, "", "machine-0")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(buf.String(), gc.Matches, "(?s)MODEL [^\\n]*\\nmodel-0 [^\\n]*\\nmodel-1 [^\\n]*\\nmodel-1 .*")
+}
+
+func (s *TracerSuite) TestRecordsBounded(c *gc.C) {
+	op := s.newOperation(c)
+	s.tracer = tracing.NewTracer(s.clock, utils.NewUUID)
+	for i := 0; i < 60; i++ {
+		s.do(c, op, nil)
+	}
+	c.Assert(s.tracer.MachineRecords("model-uuid", "machine-0"), gc.HasLen, 50)
+}
@@ -6,12 +6,17 @@ package azure
 import (
 	"fmt"
 	"net"
+	"net/http"
+	"sort"
 	"strconv"
+	"strings"
 
 	"github.com/Azure/azure-sdk-for-go/arm/network"
+	"github.com/Azure/go-autorest/autorest"
 	"github.com/Azure/go-autorest/autorest/to"
 	"github.com/juju/errors"
 
+	"github.com/juju/juju/controller"
 	"github.com/juju/juju/provider/azure/internal/armtemplates"
 	"github.com/juju/juju/provider/azure/internal/iputils"
 )
@@ -26,8 +31,9 @@ const (
 	internalNetworkName = "juju-internal-network"
 
 	// internalSecurityGroupName is the name of the network security
-	// group that each machine's primary (internal network) NIC is
-	// attached to.
+	// group that Juju creates and attaches to the model's subnets,
+	// unless the network-security-group model config specifies an
+	// existing group.
 	internalSecurityGroupName = "juju-internal-nsg"
 
 	// internalSubnetName is the name of the subnet that each
@@ -59,8 +65,82 @@ const (
 	// securityRuleMax is the maximum allowable security rule
 	// priority.
 	securityRuleMax = 4096
+
+	// securityRuleInstanceMax is the maximum number of security
+	// rules that may be created for the ports opened on a single
+	// instance. Each instance is limited to a band of 100 rules
+	// so that one machine cannot exhaust the priorities available
+	// to the whole security group.
+	securityRuleInstanceMax = 100
+
+	// securityRulePolicyMin is the beginning of the range of security
+	// rules that enforce the model's network policy, as configured by
+	// the egress-allow-list and ingress-default-deny model config. The
+	// range extends to securityRuleMax, so the policy rules take
+	// precedence only over Azure's default rules.
+	securityRulePolicyMin = 4000
+)
+
+const (
+	// securityRuleEgressDeny is the priority of the security rule
+	// that denies outbound connections to the Internet, other than
+	// those allowed by the egress-allow-list model config.
+	securityRuleEgressDeny = securityRuleMax - 1
+
+	// securityRuleEgressAllowAPI is the priority of the security rule
+	// that allows outbound connections to the Juju API port when the
+	// egress-allow-list model config is set, so that agents can
+	// always reach the controller.
+	securityRuleEgressAllowAPI = securityRuleEgressDeny - 1
+
+	// securityRuleIngressDeny is the priority of the security rule
+	// that denies inbound connections other than those allowed by
+	// rules of a higher priority.
+	securityRuleIngressDeny = securityRuleMax
+
+	// securityRuleEgressAllowMax is the maximum number of CIDRs that
+	// may be specified in the egress-allow-list model config; each
+	// is allowed by a rule with a priority preceding the API rule.
+	securityRuleEgressAllowMax = securityRuleEgressAllowAPI - securityRulePolicyMin
 )
 
+// defaultInstanceSecurityRulePriorities is the range of priorities of
+// the security rules created for the ports opened on instances, unless
+// otherwise configured by the security-rule-priorities model config.
+var defaultInstanceSecurityRulePriorities = priorityRange{
+	securityRuleInternalMax + 1,
+	securityRulePolicyMin - 1,
+}
+
+// securityGroupRef identifies the network security group in which
+// the security rules for a model's machines are managed.
+type securityGroupRef struct {
+	// id is the resource ID of the operator-provided network security
+	// group, as configured by the network-security-group model config.
+	// If id is empty, the group is the one that Juju creates in the
+	// model's resource group.
+	id string
+
+	resourceGroup string
+	name          string
+}
+
+// priorityRange is an inclusive range of security rule priorities.
+type priorityRange struct {
+	min, max int32
+}
+
+// contains reports whether or not the given priority is in the range.
+func (r priorityRange) contains(priority int32) bool {
+	return priority >= r.min && priority <= r.max
+}
+
+// String returns the range in the form accepted by the
+// security-rule-priorities model config.
+func (r priorityRange) String() string {
+	return fmt.Sprintf("%d-%d", r.min, r.max)
+}
+
 const (
 	// securityRuleInternalSSHInbound is the priority of the
 	// security rule that allows inbound SSH access to all
@@ -106,29 +186,57 @@ var (
 )
 
 // networkTemplateResources returns resource definitions for creating network
-// resources shared by all machines in a model.
+// resources shared by all machines in a model. If securityGroupID is empty,
+// a network security group is created with the given rules enforcing the
+// model's network policy, in addition to Juju's internal rules. Otherwise,
+// the subnets are attached to the existing network security group with the
+// given resource ID, whose rules are left to the operator.
 func networkTemplateResources(
+	profile apiProfile,
 	location string,
 	envTags map[string]string,
 	apiPort int,
+	securityGroupID string,
+	policyRules []network.SecurityRule,
 ) []armtemplates.Resource {
-	// Create a network security group for the environment. There is only
-	// one NSG per environment (there's a limit of 100 per subscription),
-	// in which we manage rules for each exposed machine.
-	apiSecurityRule := apiSecurityRule
-	properties := *apiSecurityRule.Properties
-	properties.DestinationPortRange = to.StringPtr(fmt.Sprint(apiPort))
-	apiSecurityRule.Properties = &properties
-	securityRules := []network.SecurityRule{sshSecurityRule, apiSecurityRule}
-
-	// NOTE(axw) we create the API rule for all models to avoid having to
-	// make queries when creating resources, making deployment faster and
-	// more robust. The controller subnet is never used in non-controller
-	// models, so there are no security implications.
-	nsgId := fmt.Sprintf(
-		`[resourceId('Microsoft.Network/networkSecurityGroups', '%s')]`,
-		internalSecurityGroupName,
-	)
+	var resources []armtemplates.Resource
+	var vnetDependsOn []string
+	nsgId := securityGroupID
+	if nsgId == "" {
+		// Create a network security group for the environment. There is only
+		// one NSG per environment (there's a limit of 100 per subscription),
+		// in which we manage rules for each exposed machine.
+		apiSecurityRule := apiSecurityRule
+		properties := *apiSecurityRule.Properties
+		properties.DestinationPortRange = to.StringPtr(fmt.Sprint(apiPort))
+		apiSecurityRule.Properties = &properties
+		securityRules := []network.SecurityRule{sshSecurityRule, apiSecurityRule}
+		securityRules = append(securityRules, policyRules...)
+
+		// NOTE(axw) we create the API rule for all models to avoid having to
+		// make queries when creating resources, making deployment faster and
+		// more robust. The controller subnet is never used in non-controller
+		// models, so there are no security implications.
+		nsgId = fmt.Sprintf(
+			`[resourceId('Microsoft.Network/networkSecurityGroups', '%s')]`,
+			internalSecurityGroupName,
+		)
+		resources = append(resources, armtemplates.Resource{
+			APIVersion: profile.network,
+			Type:       "Microsoft.Network/networkSecurityGroups",
+			Name:       internalSecurityGroupName,
+			Location:   location,
+			Tags:       envTags,
+			Properties: &network.SecurityGroupPropertiesFormat{
+				SecurityRules: &securityRules,
+			},
+		})
+		vnetDependsOn = []string{nsgId}
+	}
+
+	// The network security group is attached to the subnets rather than
+	// to each machine's NIC, so that an operator-provided group applies
+	// to all machines in the model, and the NICs do not depend on it.
 	subnets := []network.Subnet{{
 		Name: to.StringPtr(internalSubnetName),
 		Properties: &network.SubnetPropertiesFormat{
@@ -148,17 +256,8 @@ func networkTemplateResources(
 	}}
 
 	addressPrefixes := []string{internalSubnetPrefix, controllerSubnetPrefix}
-	resources := []armtemplates.Resource{{
-		APIVersion: network.APIVersion,
-		Type:       "Microsoft.Network/networkSecurityGroups",
-		Name:       internalSecurityGroupName,
-		Location:   location,
-		Tags:       envTags,
-		Properties: &network.SecurityGroupPropertiesFormat{
-			SecurityRules: &securityRules,
-		},
-	}, {
-		APIVersion: network.APIVersion,
+	resources = append(resources, armtemplates.Resource{
+		APIVersion: profile.network,
 		Type:       "Microsoft.Network/virtualNetworks",
 		Name:       internalNetworkName,
 		Location:   location,
@@ -167,11 +266,261 @@ func networkTemplateResources(
 			AddressSpace: &network.AddressSpace{&addressPrefixes},
 			Subnets:      &subnets,
 		},
-		DependsOn: []string{nsgId},
-	}}
+		DependsOn: vnetDependsOn,
+	})
 	return resources
 }
 
+// policySecurityRules returns the security rules that enforce the
+// network policy configured for a model. Outbound connections are
+// allowed to the CIDRs in egressAllowList and to the Juju API on
+// apiPort, and denied to all other Internet addresses; if
+// egressAllowList is empty, outbound connections are not restricted.
+// If ingressDefaultDeny is true, inbound connections are denied unless
+// allowed by a rule of a higher priority, such as those created for
+// the ports opened by Juju.
+func policySecurityRules(egressAllowList []string, ingressDefaultDeny bool, apiPort int) []network.SecurityRule {
+	var rules []network.SecurityRule
+	for i, cidr := range egressAllowList {
+		rules = append(rules, network.SecurityRule{
+			Name: to.StringPtr(fmt.Sprintf("EgressAllow%d", i)),
+			Properties: &network.SecurityRulePropertiesFormat{
+				Description:              to.StringPtr("Allow outbound connections to " + cidr),
+				Protocol:                 network.Asterisk,
+				SourceAddressPrefix:      to.StringPtr("*"),
+				SourcePortRange:          to.StringPtr("*"),
+				DestinationAddressPrefix: to.StringPtr(cidr),
+				DestinationPortRange:     to.StringPtr("*"),
+				Access:                   network.Allow,
+				Priority:                 to.Int32Ptr(securityRulePolicyMin + int32(i)),
+				Direction:                network.Outbound,
+			},
+		})
+	}
+	if len(egressAllowList) > 0 {
+		// The controller may be outside the model's virtual
+		// network, so the agents' connections to it would
+		// otherwise be denied.
+		rules = append(rules, network.SecurityRule{
+			Name: to.StringPtr("EgressAllowJujuAPI"),
+			Properties: &network.SecurityRulePropertiesFormat{
+				Description:              to.StringPtr("Allow outbound connections to the Juju API"),
+				Protocol:                 network.TCP,
+				SourceAddressPrefix:      to.StringPtr("*"),
+				SourcePortRange:          to.StringPtr("*"),
+				DestinationAddressPrefix: to.StringPtr("*"),
+				DestinationPortRange:     to.StringPtr(fmt.Sprint(apiPort)),
+				Access:                   network.Allow,
+				Priority:                 to.Int32Ptr(securityRuleEgressAllowAPI),
+				Direction:                network.Outbound,
+			},
+		})
+		// Outbound connections within the virtual network are
+		// allowed by Azure's default rules, which this rule
+		// does not supersede.
+		rules = append(rules, network.SecurityRule{
+			Name: to.StringPtr("EgressDenyInternet"),
+			Properties: &network.SecurityRulePropertiesFormat{
+				Description:              to.StringPtr("Deny outbound connections to the Internet"),
+				Protocol:                 network.Asterisk,
+				SourceAddressPrefix:      to.StringPtr("*"),
+				SourcePortRange:          to.StringPtr("*"),
+				DestinationAddressPrefix: to.StringPtr("Internet"),
+				DestinationPortRange:     to.StringPtr("*"),
+				Access:                   network.Deny,
+				Priority:                 to.Int32Ptr(securityRuleEgressDeny),
+				Direction:                network.Outbound,
+			},
+		})
+	}
+	if ingressDefaultDeny {
+		rules = append(rules, network.SecurityRule{
+			Name: to.StringPtr("IngressDenyAll"),
+			Properties: &network.SecurityRulePropertiesFormat{
+				Description:              to.StringPtr("Deny inbound connections not otherwise allowed"),
+				Protocol:                 network.Asterisk,
+				SourceAddressPrefix:      to.StringPtr("*"),
+				SourcePortRange:          to.StringPtr("*"),
+				DestinationAddressPrefix: to.StringPtr("*"),
+				DestinationPortRange:     to.StringPtr("*"),
+				Access:                   network.Deny,
+				Priority:                 to.Int32Ptr(securityRuleIngressDeny),
+				Direction:                network.Inbound,
+			},
+		})
+	}
+	return rules
+}
+
+// isPolicySecurityRule reports whether or not the given rule is one of
+// those returned by policySecurityRules.
+func isPolicySecurityRule(rule network.SecurityRule) bool {
+	if to.Int32(rule.Properties.Priority) < securityRulePolicyMin {
+		return false
+	}
+	name := to.String(rule.Name)
+	return strings.HasPrefix(name, "EgressAllow") ||
+		name == "EgressDenyInternet" ||
+		name == "IngressDenyAll"
+}
+
+// policySecurityRuleMatches reports whether or not the existing rule
+// enforces the same policy as the desired rule.
+func policySecurityRuleMatches(existing, desired network.SecurityRule) bool {
+	return to.Int32(existing.Properties.Priority) == to.Int32(desired.Properties.Priority) &&
+		existing.Properties.Access == desired.Properties.Access &&
+		existing.Properties.Direction == desired.Properties.Direction &&
+		existing.Properties.Protocol == desired.Properties.Protocol &&
+		to.String(existing.Properties.DestinationAddressPrefix) ==
+			to.String(desired.Properties.DestinationAddressPrefix) &&
+		to.String(existing.Properties.DestinationPortRange) ==
+			to.String(desired.Properties.DestinationPortRange)
+}
+
+// securityGroupAPIPort returns the Juju API port allowed by the given
+// network security group's API rule, or the default API port if the
+// group has no such rule.
+func securityGroupAPIPort(nsg network.SecurityGroup) int {
+	if nsg.Properties == nil || nsg.Properties.SecurityRules == nil {
+		return controller.DefaultAPIPort
+	}
+	for _, rule := range *nsg.Properties.SecurityRules {
+		if to.String(rule.Name) != to.String(apiSecurityRule.Name) || rule.Properties == nil {
+			continue
+		}
+		port, err := strconv.Atoi(to.String(rule.Properties.DestinationPortRange))
+		if err != nil {
+			break
+		}
+		return port
+	}
+	return controller.DefaultAPIPort
+}
+
+// updatePolicySecurityRules updates the rules in the model's network
+// security group that enforce the model's network policy, so that they
+// are the same as those configured. If the network security group does
+// not yet exist, then no machines have been started, and the rules will
+// be created along with the group.
+func (env *azureEnviron) updatePolicySecurityRules() error {
+	securityGroup, _ := env.securityGroup()
+	nsgClient := network.SecurityGroupsClient{env.network}
+	var nsg network.SecurityGroup
+	if err := env.callAPI(func() (autorest.Response, error) {
+		var err error
+		nsg, err = nsgClient.Get(securityGroup.resourceGroup, securityGroup.name, "")
+		return nsg.Response, err
+	}); err != nil {
+		if nsg.Response.Response != nil && nsg.StatusCode == http.StatusNotFound {
+			env.mu.Lock()
+			env.policyReconciled = true
+			env.mu.Unlock()
+			return nil
+		}
+		return errors.Annotate(err, "querying network security group")
+	}
+	return env.reconcilePolicySecurityRules(securityGroup, nsg)
+}
+
+// ensurePolicySecurityRules reconciles the rules enforcing the model's
+// network policy with those in the given network security group, if it
+// has not been done since the environ was opened. The policy may have
+// been changed while no environ was open to update the rules.
+func (env *azureEnviron) ensurePolicySecurityRules(securityGroup securityGroupRef, nsg network.SecurityGroup) error {
+	env.mu.Lock()
+	reconciled := env.policyReconciled
+	env.mu.Unlock()
+	if reconciled || securityGroup.id != "" {
+		// The rules in an operator-provided network
+		// security group are left to the operator.
+		return nil
+	}
+	return env.reconcilePolicySecurityRules(securityGroup, nsg)
+}
+
+// reconcilePolicySecurityRules updates the rules in the given network
+// security group that enforce the model's network policy, so that they
+// are the same as those configured.
+func (env *azureEnviron) reconcilePolicySecurityRules(securityGroup securityGroupRef, nsg network.SecurityGroup) error {
+	env.mu.Lock()
+	desired := policySecurityRules(
+		env.config.egressAllowList,
+		env.config.ingressDefaultDeny,
+		securityGroupAPIPort(nsg),
+	)
+	env.mu.Unlock()
+
+	existingByName := make(map[string]network.SecurityRule)
+	if nsg.Properties != nil && nsg.Properties.SecurityRules != nil {
+		for _, rule := range *nsg.Properties.SecurityRules {
+			if isPolicySecurityRule(rule) {
+				existingByName[to.String(rule.Name)] = rule
+			}
+		}
+	}
+
+	// Create or update the desired rules before deleting any that
+	// are no longer desired. The rules are ordered so that the allow
+	// rules are in place before the deny rules that they qualify.
+	securityRuleClient := network.SecurityRulesClient{env.network}
+	for _, rule := range desired {
+		ruleName := to.String(rule.Name)
+		existing, ok := existingByName[ruleName]
+		delete(existingByName, ruleName)
+		if ok && policySecurityRuleMatches(existing, rule) {
+			continue
+		}
+		logger.Debugf("creating security rule %q", ruleName)
+		if err := env.callAPI(func() (autorest.Response, error) {
+			return securityRuleClient.CreateOrUpdate(
+				securityGroup.resourceGroup, securityGroup.name,
+				ruleName, rule,
+				nil, // abort channel
+			)
+		}); err != nil {
+			return errors.Annotatef(err, "creating security rule %q", ruleName)
+		}
+	}
+
+	// Delete the deny rules before the allow rules that they qualify,
+	// so that connections are never denied by the policy being relaxed.
+	superseded := make([]network.SecurityRule, 0, len(existingByName))
+	for _, rule := range existingByName {
+		superseded = append(superseded, rule)
+	}
+	sort.Sort(byPriorityDescending(superseded))
+	for _, rule := range superseded {
+		ruleName := to.String(rule.Name)
+		logger.Debugf("deleting security rule %q", ruleName)
+		var result autorest.Response
+		if err := env.callAPI(func() (autorest.Response, error) {
+			var err error
+			result, err = securityRuleClient.Delete(
+				securityGroup.resourceGroup, securityGroup.name, ruleName,
+				nil, // abort channel
+			)
+			return result, err
+		}); err != nil {
+			if result.Response == nil || result.StatusCode != http.StatusNotFound {
+				return errors.Annotatef(err, "deleting security rule %q", ruleName)
+			}
+		}
+	}
+
+	env.mu.Lock()
+	env.policyReconciled = true
+	env.mu.Unlock()
+	return nil
+}
+
+type byPriorityDescending []network.SecurityRule
+
+func (r byPriorityDescending) Len() int      { return len(r) }
+func (r byPriorityDescending) Swap(i, j int) { r[i], r[j] = r[j], r[i] }
+func (r byPriorityDescending) Less(i, j int) bool {
+	return to.Int32(r[i].Properties.Priority) > to.Int32(r[j].Properties.Priority)
+}
+
 // nextSecurityRulePriority returns the next available priority in the given
 // security group within a specified range.
 func nextSecurityRulePriority(group network.SecurityGroup, min, max int32) (int32, error) {
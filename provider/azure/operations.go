@@ -0,0 +1,64 @@
+// Copyright 2016 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package azure
+
+import (
+	"github.com/Azure/azure-sdk-for-go/arm/compute"
+	"github.com/Azure/azure-sdk-for-go/arm/network"
+	"github.com/Azure/azure-sdk-for-go/arm/resources/resources"
+	"github.com/Azure/go-autorest/autorest"
+	"github.com/juju/errors"
+)
+
+// machineClients holds copies of the environ's Azure clients, whose
+// requests are made as part of an operation on a machine.
+type machineClients struct {
+	compute   compute.ManagementClient
+	network   network.ManagementClient
+	resources resources.ManagementClient
+}
+
+// machineClients returns copies of the environ's Azure clients that
+// attach the ID of a new operation, with the given action on the given
+// machine, to each request. The requests and their responses are
+// recorded by the provider's tracer.
+func (env *azureEnviron) machineClients(modelUUID, machineTag, action string) (machineClients, error) {
+	op, err := env.provider.tracer.NewOperation(modelUUID, machineTag, action)
+	if err != nil {
+		return machineClients{}, errors.Trace(err)
+	}
+	clients := machineClients{
+		compute:   env.compute,
+		network:   env.network,
+		resources: env.resources,
+	}
+	traceOperation := env.provider.tracer.PrepareDecorator(op)
+	for _, client := range []*autorest.Client{
+		&clients.compute.Client,
+		&clients.network.Client,
+		&clients.resources.Client,
+	} {
+		inspector := client.RequestInspector
+		client.RequestInspector = func(p autorest.Preparer) autorest.Preparer {
+			// The operation decorator is applied last, so that
+			// the headers it adds are seen by the other inspectors.
+			if inspector != nil {
+				p = inspector(p)
+			}
+			return traceOperation(p)
+		}
+	}
+	return clients, nil
+}
+
+// respondDecorators returns an autorest.RespondDecorator that applies
+// each of the given decorators in turn.
+func respondDecorators(decorators ...autorest.RespondDecorator) autorest.RespondDecorator {
+	return func(r autorest.Responder) autorest.Responder {
+		for _, decorate := range decorators {
+			r = decorate(r)
+		}
+		return r
+	}
+}
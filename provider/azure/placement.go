@@ -0,0 +1,112 @@
+// Copyright 2016 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package azure
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/arm/compute"
+	"github.com/juju/errors"
+
+	"github.com/juju/juju/environs/tags"
+)
+
+const (
+	// availabilitySetPlacementKey is the key of the placement
+	// directive naming the availability set to place a machine in.
+	availabilitySetPlacementKey = "aset"
+
+	// proximityPlacementGroupPlacementKey is the key of the placement
+	// directive naming the proximity placement group to place a
+	// machine in.
+	proximityPlacementGroupPlacementKey = "ppg"
+
+	// proximityPlacementGroupAPIVersion is the API version used for
+	// proximity placement groups, and the resources that refer to
+	// them; they are not supported by compute.APIVersion.
+	proximityPlacementGroupAPIVersion = "2018-04-01"
+)
+
+// jujuProximityPlacementGroupTag is the tag recording the name of the
+// proximity placement group that a virtual machine was placed in.
+const jujuProximityPlacementGroupTag = tags.JujuTagPrefix + "proximity-placement-group"
+
+// placementNameRegexp matches the names that may be given to
+// availability sets and proximity placement groups.
+var placementNameRegexp = regexp.MustCompile(`^[a-zA-Z0-9]([a-zA-Z0-9._-]{0,78}[a-zA-Z0-9_])?$`)
+
+// azurePlacement describes where a machine is to be placed, as
+// specified by a placement directive such as "aset=db,ppg=group1".
+type azurePlacement struct {
+	// availabilitySet is the name of the availability set to place
+	// the machine in, overriding the implicit choice of set.
+	availabilitySet string
+
+	// proximityPlacementGroup is the name of the proximity placement
+	// group to place the machine in, if any.
+	proximityPlacementGroup string
+}
+
+// parsePlacement parses the given placement directive, which is a
+// comma-separated list of key=value pairs, and returns the names of
+// the availability set and proximity placement group it specifies.
+// Names that are not specified are left empty.
+func parsePlacement(placement string) (*azurePlacement, error) {
+	var result azurePlacement
+	for _, directive := range strings.Split(placement, ",") {
+		pos := strings.IndexRune(directive, '=')
+		if pos == -1 {
+			return nil, fmt.Errorf("unknown placement directive: %v", placement)
+		}
+		key, value := directive[:pos], directive[pos+1:]
+		var field *string
+		switch key {
+		case availabilitySetPlacementKey:
+			field = &result.availabilitySet
+		case proximityPlacementGroupPlacementKey:
+			field = &result.proximityPlacementGroup
+		default:
+			return nil, fmt.Errorf("unknown placement directive: %v", placement)
+		}
+		if *field != "" {
+			return nil, errors.Errorf("duplicate %q placement directive", key)
+		}
+		if !placementNameRegexp.MatchString(value) {
+			return nil, errors.NotValidf("%s name %q", key, value)
+		}
+		*field = value
+	}
+	if result.availabilitySet == controllerAvailabilitySet {
+		// Controller deployments are identified by their
+		// dependency on the controller availability set,
+		// so no other machine may be placed in it.
+		return nil, errors.Errorf(
+			"availability set %q is reserved for controllers",
+			controllerAvailabilitySet,
+		)
+	}
+	return &result, nil
+}
+
+// proximityPlacementGroupProperties are the properties of a
+// proximity placement group resource.
+type proximityPlacementGroupProperties struct {
+	ProximityPlacementGroupType string `json:"proximityPlacementGroupType,omitempty"`
+}
+
+// availabilitySetProperties are the properties of an availability
+// set resource placed in a proximity placement group.
+type availabilitySetProperties struct {
+	ProximityPlacementGroup *compute.SubResource `json:"proximityPlacementGroup,omitempty"`
+}
+
+// virtualMachineProperties extends compute.VirtualMachineProperties
+// with a reference to the proximity placement group that the virtual
+// machine is placed in, which the SDK does not support.
+type virtualMachineProperties struct {
+	compute.VirtualMachineProperties
+	ProximityPlacementGroup *compute.SubResource `json:"proximityPlacementGroup,omitempty"`
+}
@@ -0,0 +1,114 @@
+// Copyright 2016 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package azure
+
+import (
+	"github.com/Azure/azure-sdk-for-go/arm/compute"
+	"github.com/Azure/azure-sdk-for-go/arm/network"
+	"github.com/Azure/azure-sdk-for-go/arm/storage"
+	"github.com/Azure/go-autorest/autorest"
+	"github.com/Azure/go-autorest/autorest/to"
+	"github.com/juju/errors"
+
+	"github.com/juju/juju/environs"
+)
+
+var _ environs.QuotaInfo = (*azureEnviron)(nil)
+
+// usageResources maps the names of the resources reported by the
+// Azure compute, network and storage usage APIs to the names of
+// environs quotas. Usages not in the map are reported under their
+// Azure names, e.g. the cores quota for each VM size family
+// ("standardDFamily").
+var usageResources = map[string]string{
+	"cores":             environs.QuotaCores,
+	"virtualMachines":   environs.QuotaVirtualMachines,
+	"PublicIPAddresses": environs.QuotaPublicIPAddresses,
+	"StorageAccounts":   environs.QuotaStorageAccounts,
+}
+
+// Quotas is specified in the environs.QuotaInfo interface.
+//
+// The quotas are obtained from the compute and network usage APIs
+// for the environment's location, and from the storage usage API
+// for the subscription.
+func (env *azureEnviron) Quotas() ([]environs.Quota, error) {
+	var quotas []environs.Quota
+
+	computeClient := compute.UsageOperationsClient{env.compute}
+	var computeResult compute.ListUsagesResult
+	if err := env.callAPI(func() (autorest.Response, error) {
+		var err error
+		computeResult, err = computeClient.List(env.location)
+		return computeResult.Response, err
+	}); err != nil {
+		return nil, errors.Annotate(err, "listing compute usage")
+	}
+	if computeResult.Value != nil {
+		for _, usage := range *computeResult.Value {
+			if usage.Name == nil || usage.CurrentValue == nil || usage.Limit == nil {
+				continue
+			}
+			quotas = append(quotas, newQuota(
+				usage.Name.Value, int64(*usage.CurrentValue), int64(*usage.Limit),
+			))
+		}
+	}
+
+	networkClient := network.UsagesClient{env.network}
+	var networkResult network.UsagesListResult
+	if err := env.callAPI(func() (autorest.Response, error) {
+		var err error
+		networkResult, err = networkClient.List(env.location)
+		return networkResult.Response, err
+	}); err != nil {
+		return nil, errors.Annotate(err, "listing network usage")
+	}
+	if networkResult.Value != nil {
+		for _, usage := range *networkResult.Value {
+			if usage.Name == nil || usage.CurrentValue == nil || usage.Limit == nil {
+				continue
+			}
+			quotas = append(quotas, newQuota(
+				usage.Name.Value, int64(*usage.CurrentValue), int64(*usage.Limit),
+			))
+		}
+	}
+
+	storageClient := storage.UsageOperationsClient{env.storage}
+	var storageResult storage.UsageListResult
+	if err := env.callAPI(func() (autorest.Response, error) {
+		var err error
+		storageResult, err = storageClient.List()
+		return storageResult.Response, err
+	}); err != nil {
+		return nil, errors.Annotate(err, "listing storage usage")
+	}
+	if storageResult.Value != nil {
+		for _, usage := range *storageResult.Value {
+			if usage.Name == nil || usage.CurrentValue == nil || usage.Limit == nil {
+				continue
+			}
+			quotas = append(quotas, newQuota(
+				usage.Name.Value, int64(*usage.CurrentValue), int64(*usage.Limit),
+			))
+		}
+	}
+	return quotas, nil
+}
+
+// newQuota returns an environs.Quota for the Azure usage with the
+// given name, mapping the name to an environs quota name if there
+// is one.
+func newQuota(name *string, used, limit int64) environs.Quota {
+	resource, ok := usageResources[to.String(name)]
+	if !ok {
+		resource = to.String(name)
+	}
+	return environs.Quota{
+		Resource: resource,
+		Used:     used,
+		Limit:    limit,
+	}
+}
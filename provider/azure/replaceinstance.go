@@ -0,0 +1,143 @@
+// Copyright 2016 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package azure
+
+import (
+	"net/http"
+
+	"github.com/Azure/azure-sdk-for-go/arm/compute"
+	"github.com/Azure/go-autorest/autorest"
+	"github.com/Azure/go-autorest/autorest/to"
+	"github.com/juju/errors"
+
+	"github.com/juju/juju/environs"
+	"github.com/juju/juju/instance"
+	"github.com/juju/juju/storage"
+)
+
+// maxDataDisks is the maximum number of data disks that may be attached
+// to a virtual machine, as limited by the number of LUNs.
+const maxDataDisks = 32
+
+var _ environs.InstanceReplacer = (*azureEnviron)(nil)
+
+// CheckReplaceable is specified in the environs.InstanceReplacer
+// interface. The instance's deployment is left behind when the virtual
+// machine is deleted outside of Juju, so the virtual machine itself is
+// checked.
+func (env *azureEnviron) CheckReplaceable(id instance.Id) error {
+	vmClient := compute.VirtualMachinesClient{env.compute}
+	var vm compute.VirtualMachine
+	if err := env.callAPI(func() (autorest.Response, error) {
+		var err error
+		vm, err = vmClient.Get(env.resourceGroup, string(id), "")
+		return vm.Response, err
+	}); err == nil {
+		return errors.AlreadyExistsf("instance %q", id)
+	} else if vm.Response.Response == nil || vm.StatusCode != http.StatusNotFound {
+		return errors.Annotatef(err, "getting virtual machine %q", id)
+	}
+	return nil
+}
+
+// ReplaceInstance is specified in the environs.InstanceReplacer
+// interface. The replacement virtual machine has the same name as the
+// one it replaces, and is created with the machine's existing data
+// disks attached.
+func (env *azureEnviron) ReplaceInstance(args environs.ReplaceInstanceParams) (*environs.StartInstanceResult, error) {
+	// The virtual machine being replaced must not exist, or the
+	// replacement would compete with it for the machine's disks.
+	if err := env.CheckReplaceable(args.InstanceId); err != nil {
+		return nil, errors.Trace(err)
+	}
+	volumeAttachments := args.VolumeAttachments
+	if volumeAttachments == nil {
+		volumeAttachments = []storage.VolumeAttachmentParams{}
+	}
+	return env.startInstance(args.StartInstanceParams, "replace-instance", volumeAttachments)
+}
+
+// DeleteReplacement is specified in the environs.InstanceReplacer
+// interface. The virtual machine and the resources created for it are
+// deleted, but its data disks are not, even those that would be deleted
+// along with it by StopInstances.
+func (env *azureEnviron) DeleteReplacement(id instance.Id) error {
+	return env.stopInstances("delete-replacement", false, id)
+}
+
+// existingDataDisks returns the data disks with which to attach the
+// volumes described by the given parameters to a virtual machine as it
+// is created, along with the names of those that are to be deleted
+// along with the virtual machine, and the resulting attachments. The
+// disks are assigned LUNs in order, as they must be contiguous.
+func (env *azureEnviron) existingDataDisks(
+	attachParams []storage.VolumeAttachmentParams,
+) ([]compute.DataDisk, []string, []storage.VolumeAttachment, error) {
+	if len(attachParams) == 0 {
+		return nil, nil, nil, nil
+	}
+	if len(attachParams) > maxDataDisks {
+		return nil, nil, nil, errors.Errorf(
+			"cannot attach %d volumes, the maximum is %d",
+			len(attachParams), maxDataDisks,
+		)
+	}
+	storageAccount, err := env.getStorageAccount(false)
+	if err != nil {
+		return nil, nil, nil, errors.Trace(err)
+	}
+	dataDisksRoot := dataDiskVhdRoot(storageAccount)
+
+	dataDisks := make([]compute.DataDisk, len(attachParams))
+	attachments := make([]storage.VolumeAttachment, len(attachParams))
+	var deleteDataDisks []string
+	for i, p := range attachParams {
+		if p.VolumeId == "" {
+			return nil, nil, nil, errors.NotValidf("volume %q with no volume ID", p.Volume.Id())
+		}
+		lun := int32(i)
+		dataDisks[i] = compute.DataDisk{
+			Lun:          to.Int32Ptr(lun),
+			Name:         to.StringPtr(p.VolumeId),
+			Vhd:          &compute.VirtualHardDisk{to.StringPtr(dataDisksRoot + p.VolumeId + vhdExtension)},
+			Caching:      compute.ReadWrite,
+			CreateOption: compute.Attach,
+		}
+		if p.DeleteOnTermination {
+			deleteDataDisks = append(deleteDataDisks, p.VolumeId)
+		}
+		attachments[i] = storage.VolumeAttachment{
+			p.Volume,
+			p.Machine,
+			storage.VolumeAttachmentInfo{
+				BusAddress: diskBusAddress(lun),
+				ReadOnly:   p.ReadOnly,
+			},
+		}
+	}
+	return dataDisks, deleteDataDisks, attachments, nil
+}
+
+// deleteStaleOSDisk deletes the OS disk of the named virtual machine
+// from the named storage account, if it exists. The OS disks of virtual
+// machines deleted outside of Juju are left behind.
+func (env *azureEnviron) deleteStaleOSDisk(storageAccountName, vmName string) error {
+	storageClient, err := env.getAccountStorageClient(storageAccountName)
+	if errors.IsNotFound(err) {
+		// The storage account has not been created yet,
+		// so there is no OS disk to delete.
+		return nil
+	} else if err != nil {
+		return errors.Annotate(err, "getting OS VHD storage client")
+	}
+	blobClient := storageClient.GetBlobService()
+	deleted, err := blobClient.DeleteBlobIfExists(osDiskVHDContainer, vmName, nil)
+	if err != nil {
+		return errors.Annotatef(err, "deleting OS VHD of %q", vmName)
+	}
+	if deleted {
+		logger.Infof("deleted stale OS VHD of %q", vmName)
+	}
+	return nil
+}
@@ -0,0 +1,128 @@
+// Copyright 2016 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package azure
+
+import (
+	"net/http"
+	"sort"
+
+	"github.com/Azure/azure-sdk-for-go/arm/network"
+	"github.com/Azure/azure-sdk-for-go/arm/resources/resources"
+	"github.com/Azure/go-autorest/autorest"
+	"github.com/Azure/go-autorest/autorest/to"
+	"github.com/juju/errors"
+	"gopkg.in/juju/names.v2"
+
+	"github.com/juju/juju/environs"
+	"github.com/juju/juju/instance"
+)
+
+var (
+	_ environs.ResourceEnumerator     = (*azureEnviron)(nil)
+	_ environs.MachineResourceSweeper = (*azureEnviron)(nil)
+)
+
+// AllResources is specified in the environs.ResourceEnumerator interface.
+//
+// All of a model's resources are created in the model's resource group,
+// so the resources are enumerated by listing the group's contents. If
+// the resource group does not exist, e.g. because the model has been
+// destroyed, there are no resources.
+func (env *azureEnviron) AllResources() ([]environs.Resource, error) {
+	client := resources.GroupsClient{env.resources}
+	var result resources.ResourceListResult
+	if err := env.callAPI(func() (autorest.Response, error) {
+		var err error
+		result, err = client.ListResources(env.resourceGroup, "", nil)
+		return result.Response, err
+	}); err != nil {
+		if result.Response.Response != nil && result.StatusCode == http.StatusNotFound {
+			return nil, nil
+		}
+		return nil, errors.Annotate(err, "listing resources")
+	}
+	if result.Value == nil {
+		return nil, nil
+	}
+	all := make([]environs.Resource, len(*result.Value))
+	for i, resource := range *result.Value {
+		all[i] = environs.Resource{
+			Type: to.String(resource.Type),
+			Id:   to.String(resource.ID),
+			Tags: toTags(resource.Tags),
+		}
+	}
+	environs.SortResources(all)
+	return all, nil
+}
+
+// SweepMachineResources is specified in the environs.MachineResourceSweeper
+// interface.
+//
+// Network interfaces and public IP addresses are tagged with the name
+// of the machine that they were created for. Those tagged for machines
+// that are not live are deleted, unless they are still attached to a
+// virtual machine or associated with a network interface respectively.
+func (env *azureEnviron) SweepMachineResources(liveMachines func() ([]string, error)) ([]environs.Resource, error) {
+	nicClient := network.InterfacesClient{env.network}
+	pipClient := network.PublicIPAddressesClient{env.network}
+	instanceNics, err := instanceNetworkInterfaces(
+		env.callAPI, env.resourceGroup, nicClient,
+	)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	instancePips, err := instancePublicIPAddresses(
+		env.callAPI, env.resourceGroup, pipClient,
+	)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+
+	machineIds, err := liveMachines()
+	if err != nil {
+		return nil, errors.Annotate(err, "getting live machines")
+	}
+	env.mu.Lock()
+	resourceNamePrefix := env.config.resourceNamePrefix
+	env.mu.Unlock()
+	live := make(map[instance.Id]bool)
+	for _, id := range machineIds {
+		vmName := prefixResourceName(resourceNamePrefix, resourceName(names.NewMachineTag(id)))
+		live[instance.Id(vmName)] = true
+	}
+	dead := make(map[instance.Id]bool)
+	for id := range instanceNics {
+		if id != "" && !live[id] {
+			dead[id] = true
+		}
+	}
+	for id := range instancePips {
+		if id != "" && !live[id] {
+			dead[id] = true
+		}
+	}
+	if len(dead) == 0 {
+		return nil, nil
+	}
+
+	// Sort the instance IDs so that resources are deleted in
+	// a deterministic order.
+	deadIds := make([]string, 0, len(dead))
+	for id := range dead {
+		deadIds = append(deadIds, string(id))
+	}
+	sort.Strings(deadIds)
+	instIds := make([]instance.Id, len(deadIds))
+	for i, id := range deadIds {
+		instIds[i] = instance.Id(id)
+	}
+	deleted, err := env.deleteUnusedNetworkResources(
+		nicClient, pipClient, instanceNics, instIds,
+	)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	return deleted, nil
+}
@@ -0,0 +1,173 @@
+// Copyright 2016 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package azure
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/arm/compute"
+	"github.com/Azure/go-autorest/autorest"
+	"github.com/Azure/go-autorest/autorest/to"
+	"github.com/juju/errors"
+
+	"github.com/juju/juju/environs"
+	"github.com/juju/juju/instance"
+)
+
+const (
+	// runCommandLinuxCommand is the command executed by the CustomScript
+	// extension to run a base64-encoded script. As with the command used
+	// to run CustomData, it is split and executed by Python's
+	// subprocess.call, not interpreted as a shell command.
+	runCommandLinuxCommand = `bash -c 'echo %s | base64 -d | bash'`
+
+	// The markers that the CustomScriptForLinux extension places before
+	// the command's stdout and stderr in its status message.
+	customScriptStdoutMarker = "---stdout---"
+	customScriptStderrMarker = "---errout---"
+)
+
+// RunCommand is specified in the environs.InstanceCommandRunner interface.
+//
+// The script is run by the CustomScript VM extension, through the Azure
+// VM agent, so no network access to the instance is required. Only Linux
+// instances are supported. Azure reports only the tail of the script's
+// output.
+func (env *azureEnviron) RunCommand(id instance.Id, script string) (*environs.InstanceCommandResult, error) {
+	vmClient := compute.VirtualMachinesClient{env.compute}
+	extensionsClient := compute.VirtualMachineExtensionsClient{env.compute}
+	vmName := string(id)
+
+	var vm compute.VirtualMachine
+	if err := env.callAPI(func() (autorest.Response, error) {
+		var err error
+		vm, err = vmClient.Get(env.resourceGroup, vmName, "")
+		return vm.Response, err
+	}); err != nil {
+		if vm.Response.Response != nil && vm.StatusCode == http.StatusNotFound {
+			return nil, errors.NotFoundf("instance %q", id)
+		}
+		return nil, errors.Annotate(err, "getting virtual machine")
+	}
+	if osType := virtualMachineOSType(vm); osType != compute.Linux {
+		return nil, errors.NotSupportedf("running commands on %q instances", osType)
+	}
+
+	// The extension's settings must change for it to run again,
+	// so we include a timestamp along with the command.
+	settings := map[string]interface{}{
+		"commandToExecute": fmt.Sprintf(
+			runCommandLinuxCommand,
+			base64.StdEncoding.EncodeToString([]byte(script)),
+		),
+		"timestamp": time.Now().Unix(),
+	}
+	extension := compute.VirtualMachineExtension{
+		Location: vm.Location,
+		Tags:     vm.Tags,
+		Properties: &compute.VirtualMachineExtensionProperties{
+			Publisher:               to.StringPtr(linuxCustomScriptPublisher),
+			Type:                    to.StringPtr(linuxCustomScriptType),
+			TypeHandlerVersion:      to.StringPtr(linuxCustomScriptVersion),
+			AutoUpgradeMinorVersion: to.BoolPtr(true),
+			Settings:                &settings,
+		},
+	}
+	logger.Debugf("running command on %s", vmName)
+	// The extension fails to provision if the script exits with a
+	// non-zero code, in which case we still want to report the
+	// script's output, so we defer reporting the error until we
+	// have obtained the extension's instance view.
+	runErr := env.callAPI(func() (autorest.Response, error) {
+		return extensionsClient.CreateOrUpdate(
+			env.resourceGroup, vmName, extensionName, extension, nil,
+		)
+	})
+
+	var result compute.VirtualMachineExtension
+	if err := env.callAPI(func() (autorest.Response, error) {
+		var err error
+		result, err = extensionsClient.Get(
+			env.resourceGroup, vmName, extensionName, "instanceView",
+		)
+		return result.Response, err
+	}); err != nil {
+		if runErr != nil {
+			return nil, errors.Annotate(runErr, "running command")
+		}
+		return nil, errors.Annotate(err, "getting command result")
+	}
+	commandResult, ok := customScriptResult(result)
+	if !ok {
+		if runErr != nil {
+			return nil, errors.Annotate(runErr, "running command")
+		}
+		return nil, errors.New("command result not reported")
+	}
+	return commandResult, nil
+}
+
+// virtualMachineOSType returns the OS type of the virtual machine's
+// OS disk, or the empty string if it is unknown.
+func virtualMachineOSType(vm compute.VirtualMachine) compute.OperatingSystemTypes {
+	if vm.Properties == nil ||
+		vm.Properties.StorageProfile == nil ||
+		vm.Properties.StorageProfile.OsDisk == nil {
+		return ""
+	}
+	return vm.Properties.StorageProfile.OsDisk.OsType
+}
+
+// customScriptResult returns the result of the command run by the
+// CustomScriptForLinux extension, as reported in the extension's
+// instance view, and a boolean indicating whether the result was
+// found.
+func customScriptResult(extension compute.VirtualMachineExtension) (*environs.InstanceCommandResult, bool) {
+	if extension.Properties == nil ||
+		extension.Properties.InstanceView == nil ||
+		extension.Properties.InstanceView.Statuses == nil {
+		return nil, false
+	}
+	for _, status := range *extension.Properties.InstanceView.Statuses {
+		if status.Message == nil {
+			continue
+		}
+		message := to.String(status.Message)
+		stdoutIndex := strings.Index(message, customScriptStdoutMarker)
+		stderrIndex := strings.Index(message, customScriptStderrMarker)
+		if stdoutIndex == -1 || stderrIndex < stdoutIndex {
+			continue
+		}
+		result := &environs.InstanceCommandResult{
+			Stdout: strings.TrimPrefix(
+				message[stdoutIndex+len(customScriptStdoutMarker):stderrIndex], "\n",
+			),
+			Stderr: strings.TrimPrefix(
+				message[stderrIndex+len(customScriptStderrMarker):], "\n",
+			),
+		}
+		if status.Level == compute.Error {
+			result.Code = customScriptExitCode(to.String(status.Code))
+		}
+		return result, true
+	}
+	return nil, false
+}
+
+// customScriptExitCode returns the exit code of a failed command, given
+// the code of the CustomScript extension's status. The exit code is
+// included in the status code as "ProvisioningState/failed/<code>"; if
+// it is not, the command is assumed to have exited with code 1.
+func customScriptExitCode(statusCode string) int {
+	i := strings.LastIndex(statusCode, "/")
+	if code, err := strconv.Atoi(statusCode[i+1:]); err == nil && code != 0 {
+		return code
+	}
+	return 1
+}
@@ -17,6 +17,7 @@ import (
 	"github.com/juju/schema"
 	"gopkg.in/juju/names.v2"
 
+	"github.com/juju/juju/environs/tags"
 	"github.com/juju/juju/instance"
 	"github.com/juju/juju/provider/azure/internal/armtemplates"
 	internalazurestorage "github.com/juju/juju/provider/azure/internal/azurestorage"
@@ -41,6 +42,11 @@ const (
 
 	// vhdExtension is the filename extension we give to VHDs we create.
 	vhdExtension = ".vhd"
+
+	// jujuDeleteOnTerminationTag is the name of the virtual machine tag
+	// that records the data disks to delete along with the virtual
+	// machine. The value is a space-separated list of the disk names.
+	jujuDeleteOnTerminationTag = tags.JujuTagPrefix + "delete-on-termination"
 )
 
 // StorageProviderTypes implements storage.ProviderRegistry.
@@ -206,7 +212,7 @@ func (v *azureVolumeSource) createVolume(
 	}
 
 	dataDisksRoot := dataDiskVhdRoot(storageAccount)
-	dataDiskName := p.Tag.String()
+	dataDiskName := prefixResourceName(v.resourceNamePrefix(), p.Tag.String())
 	vhdURI := dataDisksRoot + dataDiskName + vhdExtension
 
 	sizeInGib := mibToGib(p.Size)
@@ -225,18 +231,19 @@ func (v *azureVolumeSource) createVolume(
 	}
 	dataDisks = append(dataDisks, dataDisk)
 	vm.Properties.StorageProfile.DataDisks = &dataDisks
+	setDeleteOnTermination(vm, dataDiskName, p.Attachment.DeleteOnTermination)
 
 	// Data disks associate VHDs to machines. In Juju's storage model,
 	// the VHD is the volume and the disk is the volume attachment.
+	//
+	// Volumes that are deleted on termination do not outlive the
+	// machine; all others are persistent.
 	volume := storage.Volume{
 		p.Tag,
 		storage.VolumeInfo{
-			VolumeId: dataDiskName,
-			Size:     gibToMib(sizeInGib),
-			// We don't currently support persistent volumes in
-			// Azure, as it requires removal of "comp=media" when
-			// deleting VMs, complicating cleanup.
-			Persistent: true,
+			VolumeId:   dataDiskName,
+			Size:       gibToMib(sizeInGib),
+			Persistent: !p.Attachment.DeleteOnTermination,
 		},
 	}
 	volumeAttachment := storage.VolumeAttachment{
@@ -249,15 +256,24 @@ func (v *azureVolumeSource) createVolume(
 	return &volume, &volumeAttachment, nil
 }
 
+// resourceNamePrefix returns the model's resource name prefix, with
+// which the names of the volumes' blobs begin.
+func (v *azureVolumeSource) resourceNamePrefix() string {
+	v.env.mu.Lock()
+	defer v.env.mu.Unlock()
+	return v.env.config.resourceNamePrefix
+}
+
 // ListVolumes is specified on the storage.VolumeSource interface.
 func (v *azureVolumeSource) ListVolumes() ([]string, error) {
 	blobs, err := v.listBlobs()
 	if err != nil {
 		return nil, errors.Annotate(err, "listing volumes")
 	}
+	resourceNamePrefix := v.resourceNamePrefix()
 	volumeIds := make([]string, 0, len(blobs))
 	for _, blob := range blobs {
-		volumeId, ok := blobVolumeId(blob)
+		volumeId, ok := blobVolumeId(resourceNamePrefix, blob)
 		if !ok {
 			continue
 		}
@@ -298,9 +314,10 @@ func (v *azureVolumeSource) DescribeVolumes(volumeIds []string) ([]storage.Descr
 		return nil, errors.Annotate(err, "listing volumes")
 	}
 
+	resourceNamePrefix := v.resourceNamePrefix()
 	byVolumeId := make(map[string]azurestorage.Blob)
 	for _, blob := range blobs {
-		volumeId, ok := blobVolumeId(blob)
+		volumeId, ok := blobVolumeId(resourceNamePrefix, blob)
 		if !ok {
 			continue
 		}
@@ -422,6 +439,45 @@ func (v *azureVolumeSource) AttachVolumes(attachParams []storage.VolumeAttachmen
 	return results, nil
 }
 
+var _ storage.VolumeAttachmentLimiter = (*azureVolumeSource)(nil)
+
+// VolumeAttachmentLimits is specified on the storage.VolumeAttachmentLimiter
+// interface. The maximum number of volumes that may be attached to a
+// virtual machine is the number of data disks supported by its size.
+func (v *azureVolumeSource) VolumeAttachmentLimits(instanceIds []instance.Id) ([]storage.VolumeAttachmentLimitsResult, error) {
+	results := make([]storage.VolumeAttachmentLimitsResult, len(instanceIds))
+	if len(instanceIds) == 0 {
+		return results, nil
+	}
+	virtualMachines, err := v.virtualMachines(instanceIds)
+	if err != nil {
+		return nil, errors.Annotate(err, "getting virtual machines")
+	}
+	for i, id := range instanceIds {
+		vm := virtualMachines[id]
+		if vm.err != nil {
+			results[i].Error = vm.err
+			continue
+		}
+		var vmSize compute.VirtualMachineSizeTypes
+		if vm.vm.Properties.HardwareProfile != nil {
+			vmSize = vm.vm.Properties.HardwareProfile.VMSize
+		}
+		maxDataDisks, err := v.env.getMaxDataDisks(vmSize)
+		if err != nil {
+			results[i].Error = errors.Trace(err)
+			continue
+		}
+		results[i].Max = maxDataDisks
+		if vm.vm.Properties.StorageProfile.DataDisks != nil {
+			for _, disk := range *vm.vm.Properties.StorageProfile.DataDisks {
+				results[i].Attached = append(results[i].Attached, to.String(disk.Name))
+			}
+		}
+	}
+	return results, nil
+}
+
 func (v *azureVolumeSource) attachVolume(
 	vm *compute.VirtualMachine,
 	p storage.VolumeAttachmentParams,
@@ -448,7 +504,8 @@ func (v *azureVolumeSource) attachVolume(
 		if to.String(disk.Vhd.URI) != vhdURI {
 			continue
 		}
-		// Disk is already attached.
+		// Disk is already attached; the VM only needs updating
+		// if the delete-on-termination policy has changed.
 		volumeAttachment := &storage.VolumeAttachment{
 			p.Volume,
 			p.Machine,
@@ -456,7 +513,8 @@ func (v *azureVolumeSource) attachVolume(
 				BusAddress: diskBusAddress(to.Int32(disk.Lun)),
 			},
 		}
-		return volumeAttachment, false, nil
+		updated := setDeleteOnTermination(vm, dataDiskName, p.DeleteOnTermination)
+		return volumeAttachment, updated, nil
 	}
 
 	lun, err := nextAvailableLUN(vm)
@@ -473,6 +531,7 @@ func (v *azureVolumeSource) attachVolume(
 	}
 	dataDisks = append(dataDisks, dataDisk)
 	vm.Properties.StorageProfile.DataDisks = &dataDisks
+	setDeleteOnTermination(vm, dataDiskName, p.DeleteOnTermination)
 
 	volumeAttachment := storage.VolumeAttachment{
 		p.Volume,
@@ -552,6 +611,9 @@ func (v *azureVolumeSource) detachVolume(
 	dataDiskName := p.VolumeId
 	vhdURI := dataDisksRoot + dataDiskName + vhdExtension
 
+	// A detached disk must not be deleted along with the VM.
+	updated = setDeleteOnTermination(vm, dataDiskName, false)
+
 	var dataDisks []compute.DataDisk
 	if vm.Properties.StorageProfile.DataDisks != nil {
 		dataDisks = *vm.Properties.StorageProfile.DataDisks
@@ -571,7 +633,44 @@ func (v *azureVolumeSource) detachVolume(
 		}
 		return true
 	}
-	return false
+	return updated
+}
+
+// deleteOnTerminationDisks returns the names of the data disks that
+// should be deleted along with the virtual machine with the given tags.
+func deleteOnTerminationDisks(vmTags *map[string]*string) []string {
+	return strings.Fields(toTags(vmTags)[jujuDeleteOnTerminationTag])
+}
+
+// setDeleteOnTermination adds the named data disk to, or removes it from,
+// the virtual machine's list of data disks to delete along with it. The
+// result reports whether the virtual machine's tags were changed.
+func setDeleteOnTermination(vm *compute.VirtualMachine, diskName string, deleteOnTermination bool) bool {
+	diskNames := deleteOnTerminationDisks(vm.Tags)
+	index := -1
+	for i, name := range diskNames {
+		if name == diskName {
+			index = i
+			break
+		}
+	}
+	if deleteOnTermination == (index >= 0) {
+		return false
+	}
+	if deleteOnTermination {
+		diskNames = append(diskNames, diskName)
+	} else {
+		diskNames = append(diskNames[:index], diskNames[index+1:]...)
+	}
+	if vm.Tags == nil {
+		vm.Tags = &map[string]*string{}
+	}
+	if len(diskNames) == 0 {
+		delete(*vm.Tags, jujuDeleteOnTerminationTag)
+	} else {
+		(*vm.Tags)[jujuDeleteOnTerminationTag] = to.StringPtr(strings.Join(diskNames, " "))
+	}
+	return true
 }
 
 type maybeVirtualMachine struct {
@@ -705,25 +804,28 @@ func blobContainerURL(storageAccount *armstorage.Account, container string) stri
 }
 
 // blobVolumeId returns the volume ID for a blob, and a boolean reporting
-// whether or not the blob's name matches the scheme we use.
-func blobVolumeId(blob azurestorage.Blob) (string, bool) {
+// whether or not the blob's name matches the scheme we use. The names of
+// blobs created for a model with a resource name prefix begin with it.
+func blobVolumeId(resourceNamePrefix string, blob azurestorage.Blob) (string, bool) {
 	if !strings.HasSuffix(blob.Name, vhdExtension) {
 		return "", false
 	}
 	volumeId := blob.Name[:len(blob.Name)-len(vhdExtension)]
-	if _, err := names.ParseVolumeTag(volumeId); err != nil {
+	volumeTag := unprefixResourceName(resourceNamePrefix, volumeId)
+	if _, err := names.ParseVolumeTag(volumeTag); err != nil {
 		return "", false
 	}
 	return volumeId, true
 }
 
 // getStorageClient returns a new storage client, given an environ config
-// and a constructor.
+// and a constructor. The client sends requests with the given http.Client.
 func getStorageClient(
 	newClient internalazurestorage.NewClientFunc,
 	storageEndpoint string,
 	storageAccount *armstorage.Account,
 	storageAccountKey *armstorage.AccountKey,
+	httpClient *http.Client,
 ) (internalazurestorage.Client, error) {
 	storageAccountName := to.String(storageAccount.Name)
 	const useHTTPS = true
@@ -733,14 +835,17 @@ func getStorageClient(
 		storageEndpoint,
 		azurestorage.DefaultAPIVersion,
 		useHTTPS,
+		httpClient,
 	)
 }
 
-// getStorageAccountKey returns the key for the storage account.
+// getStorageAccountKey returns the key for the storage account with
+// the given name. If keyName is empty, the first key with full
+// permissions is returned.
 func getStorageAccountKey(
 	callAPI callAPIFunc,
 	client armstorage.AccountsClient,
-	resourceGroup, accountName string,
+	resourceGroup, accountName, keyName string,
 ) (*armstorage.AccountKey, error) {
 	logger.Debugf("getting keys for storage account %q", accountName)
 	var listKeysResult armstorage.AccountListKeysResult
@@ -754,23 +859,38 @@ func getStorageAccountKey(
 		}
 		return nil, errors.Annotate(err, "listing storage account keys")
 	}
-	if listKeysResult.Keys == nil {
+	return findStorageAccountKey(listKeysResult, keyName)
+}
+
+// findStorageAccountKey returns the key with full permissions, and the
+// given name if non-empty, from the result of listing or regenerating
+// a storage account's keys.
+func findStorageAccountKey(result armstorage.AccountListKeysResult, keyName string) (*armstorage.AccountKey, error) {
+	if result.Keys == nil {
 		return nil, errors.NotFoundf("storage account keys")
 	}
 
 	// We need a storage key with full permissions.
 	var fullKey *armstorage.AccountKey
-	for _, key := range *listKeysResult.Keys {
-		logger.Debugf("storage account key: %#v", key)
+	for _, key := range *result.Keys {
 		// At least some of the time, Azure returns the permissions
 		// in title-case, which does not match the constant.
 		if strings.ToUpper(string(key.Permissions)) != string(armstorage.FULL) {
 			continue
 		}
+		if keyName != "" && to.String(key.KeyName) != keyName {
+			continue
+		}
 		fullKey = &key
 		break
 	}
 	if fullKey == nil {
+		if keyName != "" {
+			return nil, errors.NotFoundf(
+				"storage account key %q with %q permission",
+				keyName, armstorage.FULL,
+			)
+		}
 		return nil, errors.NotFoundf(
 			"storage account key with %q permission",
 			armstorage.FULL,
@@ -782,12 +902,13 @@ func getStorageAccountKey(
 // storageAccountTemplateResource returns a template resource definition
 // for creating a storage account.
 func storageAccountTemplateResource(
+	profile apiProfile,
 	location string,
 	envTags map[string]string,
 	accountName, accountType string,
 ) armtemplates.Resource {
 	return armtemplates.Resource{
-		APIVersion: armstorage.APIVersion,
+		APIVersion: profile.storage,
 		Type:       "Microsoft.Storage/storageAccounts",
 		Name:       accountName,
 		Location:   location,
@@ -16,6 +16,7 @@ import (
 	gc "gopkg.in/check.v1"
 	"gopkg.in/juju/names.v2"
 
+	"github.com/juju/juju/environs"
 	"github.com/juju/juju/instance"
 	"github.com/juju/juju/provider/azure"
 	"github.com/juju/juju/provider/azure/internal/azureauth"
@@ -28,6 +29,7 @@ type storageSuite struct {
 	testing.BaseSuite
 
 	storageClient azuretesting.MockStorageClient
+	envProvider   environs.EnvironProvider
 	provider      storage.Provider
 	requests      []*http.Request
 	sender        azuretesting.Senders
@@ -39,7 +41,7 @@ func (s *storageSuite) SetUpTest(c *gc.C) {
 	s.BaseSuite.SetUpTest(c)
 	s.storageClient = azuretesting.MockStorageClient{}
 	s.requests = nil
-	envProvider := newProvider(c, azure.ProviderConfig{
+	s.envProvider = newProvider(c, azure.ProviderConfig{
 		Sender:                            &s.sender,
 		NewStorageClient:                  s.storageClient.NewClient,
 		RequestInspector:                  azuretesting.RequestRecorder(&s.requests),
@@ -49,7 +51,7 @@ func (s *storageSuite) SetUpTest(c *gc.C) {
 	s.sender = nil
 
 	var err error
-	env := openEnviron(c, envProvider, &s.sender)
+	env := openEnviron(c, s.envProvider, &s.sender)
 	s.provider, err = env.StorageProvider("azure")
 	c.Assert(err, jc.ErrorIsNil)
 }
@@ -258,6 +260,69 @@ func (s *storageSuite) TestCreateVolumes(c *gc.C) {
 	assertRequestBody(c, s.requests[3], &virtualMachines[1])
 }
 
+func (s *storageSuite) TestCreateVolumesDeleteOnTermination(c *gc.C) {
+	params := []storage.VolumeParams{{
+		Tag:      names.NewVolumeTag("0"),
+		Size:     1024,
+		Provider: "azure",
+		Attachment: &storage.VolumeAttachmentParams{
+			AttachmentParams: storage.AttachmentParams{
+				Provider:   "azure",
+				Machine:    names.NewMachineTag("0"),
+				InstanceId: instance.Id("machine-0"),
+			},
+			Volume:              names.NewVolumeTag("0"),
+			DeleteOnTermination: true,
+		},
+	}}
+
+	machine0Tags := map[string]*string{
+		"juju-delete-on-termination": to.StringPtr("volume-1"),
+	}
+	virtualMachines := []compute.VirtualMachine{{
+		Name: to.StringPtr("machine-0"),
+		Tags: &machine0Tags,
+		Properties: &compute.VirtualMachineProperties{
+			StorageProfile: &compute.StorageProfile{},
+		},
+	}}
+	virtualMachinesSender := azuretesting.NewSenderWithValue(compute.VirtualMachineListResult{
+		Value: &virtualMachines,
+	})
+	virtualMachinesSender.PathPattern = `.*/Microsoft\.Compute/virtualMachines`
+	updateVirtualMachine0Sender := azuretesting.NewSenderWithValue(&compute.VirtualMachine{})
+	updateVirtualMachine0Sender.PathPattern = `.*/Microsoft\.Compute/virtualMachines/machine-0`
+	volumeSource := s.volumeSource(c)
+	s.sender = azuretesting.Senders{
+		virtualMachinesSender,
+		s.accountSender(),
+		updateVirtualMachine0Sender,
+	}
+
+	results, err := volumeSource.CreateVolumes(params)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(results, gc.HasLen, 1)
+	c.Assert(results[0].Error, jc.ErrorIsNil)
+	c.Assert(results[0].Volume.Persistent, jc.IsFalse)
+
+	c.Assert(s.requests, gc.HasLen, 3)
+	c.Assert(s.requests[2].Method, gc.Equals, "PUT") // update machine-0
+	machine0DataDisks := []compute.DataDisk{{
+		Lun:        to.Int32Ptr(0),
+		DiskSizeGB: to.Int32Ptr(1),
+		Name:       to.StringPtr("volume-0"),
+		Vhd: &compute.VirtualHardDisk{URI: to.StringPtr(fmt.Sprintf(
+			"https://%s.blob.storage.azurestack.local/datavhds/volume-0.vhd",
+			storageAccountName,
+		))},
+		Caching:      compute.ReadWrite,
+		CreateOption: compute.Empty,
+	}}
+	virtualMachines[0].Properties.StorageProfile.DataDisks = &machine0DataDisks
+	machine0Tags["juju-delete-on-termination"] = to.StringPtr("volume-1 volume-0")
+	assertRequestBody(c, s.requests[2], &virtualMachines[0])
+}
+
 func (s *storageSuite) TestListVolumes(c *gc.C) {
 	s.storageClient.ListBlobsFunc = func(
 		container string,
@@ -298,6 +363,37 @@ func (s *storageSuite) TestListVolumes(c *gc.C) {
 	c.Assert(volumeIds, jc.DeepEquals, []string{"volume-1", "volume-0"})
 }
 
+func (s *storageSuite) TestListVolumesResourceNamePrefix(c *gc.C) {
+	env := openEnviron(c, s.envProvider, &s.sender, testing.Attrs{"resource-name-prefix": "acme"})
+	var err error
+	s.provider, err = env.StorageProvider("azure")
+	c.Assert(err, jc.ErrorIsNil)
+
+	s.storageClient.ListBlobsFunc = func(
+		container string,
+		params azurestorage.ListBlobsParameters,
+	) (azurestorage.BlobListResponse, error) {
+		return azurestorage.BlobListResponse{
+			Blobs: []azurestorage.Blob{{
+				Name: "acme-volume-1.vhd",
+			}, {
+				Name: "acme-junk.vhd",
+			}, {
+				Name: "initech-volume-0.vhd",
+			}},
+		}, nil
+	}
+
+	volumeSource := s.volumeSource(c)
+	s.sender = azuretesting.Senders{
+		s.accountSender(),
+		s.accountKeysSender(),
+	}
+	volumeIds, err := volumeSource.ListVolumes()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(volumeIds, jc.DeepEquals, []string{"acme-volume-1"})
+}
+
 func (s *storageSuite) TestListVolumesErrors(c *gc.C) {
 	volumeSource := s.volumeSource(c)
 	s.sender = azuretesting.Senders{
@@ -503,6 +599,111 @@ func (s *storageSuite) TestAttachVolumes(c *gc.C) {
 	assertRequestBody(c, s.requests[2], &virtualMachines[0])
 }
 
+func (s *storageSuite) TestVolumeAttachmentLimits(c *gc.C) {
+	dataDisks := []compute.DataDisk{{
+		Lun:  to.Int32Ptr(0),
+		Name: to.StringPtr("volume-1"),
+	}, {
+		Lun:  to.Int32Ptr(1),
+		Name: to.StringPtr("unmanaged-disk"),
+	}}
+	virtualMachines := []compute.VirtualMachine{{
+		Name: to.StringPtr("machine-0"),
+		Properties: &compute.VirtualMachineProperties{
+			HardwareProfile: &compute.HardwareProfile{VMSize: "Standard_D1"},
+			StorageProfile:  &compute.StorageProfile{DataDisks: &dataDisks},
+		},
+	}, {
+		Name: to.StringPtr("machine-1"),
+		Properties: &compute.VirtualMachineProperties{
+			HardwareProfile: &compute.HardwareProfile{VMSize: "Standard_Unknown"},
+			StorageProfile:  &compute.StorageProfile{},
+		},
+	}}
+	virtualMachinesSender := azuretesting.NewSenderWithValue(compute.VirtualMachineListResult{
+		Value: &virtualMachines,
+	})
+	virtualMachinesSender.PathPattern = `.*/Microsoft\.Compute/virtualMachines`
+	vmSizes := []compute.VirtualMachineSize{{
+		Name:             to.StringPtr("Standard_D1"),
+		MaxDataDiskCount: to.Int32Ptr(2),
+	}}
+	vmSizesSender := azuretesting.NewSenderWithValue(compute.VirtualMachineSizeListResult{
+		Value: &vmSizes,
+	})
+	vmSizesSender.PathPattern = ".*/vmSizes"
+
+	volumeSource := s.volumeSource(c)
+	s.sender = azuretesting.Senders{
+		virtualMachinesSender,
+		vmSizesSender,
+	}
+	limiter, ok := volumeSource.(storage.VolumeAttachmentLimiter)
+	c.Assert(ok, jc.IsTrue)
+	results, err := limiter.VolumeAttachmentLimits([]instance.Id{
+		"machine-0", "machine-1", "machine-42",
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(results, gc.HasLen, 3)
+	c.Assert(results[0].Error, jc.ErrorIsNil)
+	c.Assert(results[0].Max, gc.Equals, 2)
+	c.Assert(results[0].Attached, jc.DeepEquals, []string{"volume-1", "unmanaged-disk"})
+	c.Assert(results[1].Error, gc.ErrorMatches, `VM size "Standard_Unknown" not found`)
+	c.Assert(results[2].Error, gc.ErrorMatches, "instance machine-42 not found")
+}
+
+func (s *storageSuite) TestAttachVolumesDeleteOnTerminationChanged(c *gc.C) {
+	// machine-0 already has volume-0 attached, but it is not
+	// recorded as being deleted on termination.
+	machine0DataDisks := []compute.DataDisk{{
+		Lun:  to.Int32Ptr(0),
+		Name: to.StringPtr("volume-0"),
+		Vhd: &compute.VirtualHardDisk{URI: to.StringPtr(fmt.Sprintf(
+			"https://%s.blob.storage.azurestack.local/datavhds/volume-0.vhd",
+			storageAccountName,
+		))},
+	}}
+	virtualMachines := []compute.VirtualMachine{{
+		Name: to.StringPtr("machine-0"),
+		Properties: &compute.VirtualMachineProperties{
+			StorageProfile: &compute.StorageProfile{DataDisks: &machine0DataDisks},
+		},
+	}}
+	virtualMachinesSender := azuretesting.NewSenderWithValue(compute.VirtualMachineListResult{
+		Value: &virtualMachines,
+	})
+	virtualMachinesSender.PathPattern = `.*/Microsoft\.Compute/virtualMachines`
+	updateVirtualMachine0Sender := azuretesting.NewSenderWithValue(&compute.VirtualMachine{})
+	updateVirtualMachine0Sender.PathPattern = `.*/Microsoft\.Compute/virtualMachines/machine-0`
+	volumeSource := s.volumeSource(c)
+	s.sender = azuretesting.Senders{
+		virtualMachinesSender,
+		s.accountSender(),
+		updateVirtualMachine0Sender,
+	}
+
+	results, err := volumeSource.AttachVolumes([]storage.VolumeAttachmentParams{{
+		AttachmentParams: storage.AttachmentParams{
+			Provider:   "azure",
+			Machine:    names.NewMachineTag("0"),
+			InstanceId: instance.Id("machine-0"),
+		},
+		Volume:              names.NewVolumeTag("0"),
+		VolumeId:            "volume-0",
+		DeleteOnTermination: true,
+	}})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(results, gc.HasLen, 1)
+	c.Assert(results[0].Error, jc.ErrorIsNil)
+
+	c.Assert(s.requests, gc.HasLen, 3)
+	c.Assert(s.requests[2].Method, gc.Equals, "PUT") // update machine-0
+	virtualMachines[0].Tags = &map[string]*string{
+		"juju-delete-on-termination": to.StringPtr("volume-0"),
+	}
+	assertRequestBody(c, s.requests[2], &virtualMachines[0])
+}
+
 func (s *storageSuite) TestDetachVolumes(c *gc.C) {
 	// machine-0 has a three data disks: volume-0, volume-1 and volume-2
 	machine0DataDisks := []compute.DataDisk{{
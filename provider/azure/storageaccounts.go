@@ -0,0 +1,158 @@
+// Copyright 2016 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package azure
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/arm/compute"
+	"github.com/Azure/azure-sdk-for-go/arm/storage"
+	"github.com/Azure/go-autorest/autorest"
+	"github.com/Azure/go-autorest/autorest/to"
+	"github.com/juju/errors"
+
+	"github.com/juju/juju/environs/tags"
+	internalazurestorage "github.com/juju/juju/provider/azure/internal/azurestorage"
+)
+
+const (
+	// jujuStorageAccountTag is the tag recording the name of the
+	// storage account holding a virtual machine's OS disk VHD.
+	// Virtual machines without the tag were created before the
+	// model's storage accounts were pooled, and their OS disks
+	// are in the model's primary storage account.
+	jujuStorageAccountTag = tags.JujuTagPrefix + "storage-account"
+
+	// maxVirtualMachinesPerStorageAccount is the number of virtual
+	// machines whose OS disks we place in a storage account before
+	// spilling over to another. A standard storage account supports
+	// a total of 20000 IOPS, and each standard disk up to 500 IOPS,
+	// so beyond 40 disks an account's disks will be throttled.
+	maxVirtualMachinesPerStorageAccount = 40
+)
+
+// modelStorageAccountName returns the name of the model's storage
+// account with the given index. The name is deterministic, so that
+// we can defer creation of the storage account to the VM deployment,
+// and retain the ability to create multiple deployments in parallel.
+//
+// The model's primary storage account, with index 0, is named with
+// the last 20 non-hyphen hex characters of the model's UUID, prefixed
+// with "juju". The probability of clashing with another storage account
+// should be negligible. Additional storage accounts replace the end
+// of the UUID with "x" and the index, which cannot clash with the
+// primary account's name, and keeps the name within the 24 character
+// limit for storage account names.
+func modelStorageAccountName(modelUUID string, index int) string {
+	uuidAlphaNumeric := strings.Replace(modelUUID, "-", "", -1)
+	uuidSuffix := uuidAlphaNumeric[len(uuidAlphaNumeric)-20:]
+	if index == 0 {
+		return "juju" + uuidSuffix
+	}
+	indexSuffix := fmt.Sprintf("x%d", index)
+	return "juju" + uuidSuffix[:len(uuidSuffix)-len(indexSuffix)] + indexSuffix
+}
+
+// chooseStorageAccount returns the name of the storage account in
+// which to place the OS disk of a new virtual machine, given the
+// existing virtual machines. The least-loaded of the storage accounts
+// with capacity remaining is chosen; if they are all full, a new
+// storage account is chosen, to be created along with the virtual
+// machine. The storage accounts in use are those recorded in the
+// virtual machines' tags, along with the primary account. The name
+// of the storage account with each index is returned by accountName.
+//
+// Machines started concurrently may choose the same storage account,
+// so an account may exceed maxVirtualMachinesPerStorageAccount by the
+// number of machines started in parallel.
+func chooseStorageAccount(
+	primaryAccountName string,
+	accountName func(index int) string,
+	vms []compute.VirtualMachine,
+) string {
+	counts := map[string]int{primaryAccountName: 0}
+	for _, vm := range vms {
+		counts[virtualMachineStorageAccount(vm, primaryAccountName)]++
+	}
+
+	// Sort the accounts so that ties are broken deterministically.
+	inUse := make([]string, 0, len(counts))
+	for name := range counts {
+		inUse = append(inUse, name)
+	}
+	sort.Strings(inUse)
+	var chosen string
+	for _, name := range inUse {
+		n := counts[name]
+		if n >= maxVirtualMachinesPerStorageAccount {
+			continue
+		}
+		if chosen == "" || n < counts[chosen] {
+			chosen = name
+		}
+	}
+	if chosen != "" {
+		return chosen
+	}
+
+	// All of the storage accounts are full, so spill over
+	// into a new one.
+	for index := 1; ; index++ {
+		name := accountName(index)
+		if _, ok := counts[name]; !ok {
+			return name
+		}
+	}
+}
+
+// virtualMachineStorageAccount returns the name of the storage account
+// holding the virtual machine's OS disk VHD, as recorded in its tags.
+func virtualMachineStorageAccount(vm compute.VirtualMachine, primaryAccountName string) string {
+	if vm.Tags != nil {
+		if name := to.String((*vm.Tags)[jujuStorageAccountTag]); name != "" {
+			return name
+		}
+	}
+	return primaryAccountName
+}
+
+// getAccountStorageClient returns a storage client for the model's
+// storage account with the given name. Only the details of the primary
+// storage account are cached, as the others are needed only to delete
+// virtual machines' OS disks.
+func (env *azureEnviron) getAccountStorageClient(accountName string) (internalazurestorage.Client, error) {
+	if accountName == env.storageAccountName {
+		return env.getStorageClient()
+	}
+	client := storage.AccountsClient{env.storage}
+	var account storage.Account
+	if err := env.callAPI(func() (autorest.Response, error) {
+		var err error
+		account, err = client.GetProperties(env.resourceGroup, accountName)
+		return account.Response, err
+	}); err != nil {
+		if account.Response.Response != nil && account.Response.StatusCode == http.StatusNotFound {
+			return nil, errors.NewNotFound(err, fmt.Sprintf("storage account %q not found", accountName))
+		}
+		return nil, errors.Annotatef(err, "getting storage account %q", accountName)
+	}
+	key, err := getStorageAccountKey(env.callAPI, client, env.resourceGroup, accountName, "")
+	if err != nil {
+		return nil, errors.Annotatef(err, "getting storage account %q key", accountName)
+	}
+	storageClient, err := getStorageClient(
+		env.provider.config.NewStorageClient,
+		env.storageEndpoint,
+		&account,
+		key,
+		env.sender.httpClient(),
+	)
+	if err != nil {
+		return nil, errors.Annotate(err, "getting storage client")
+	}
+	return storageClient, nil
+}
@@ -0,0 +1,181 @@
+// Copyright 2016 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package azure
+
+import (
+	"net/http"
+
+	"github.com/Azure/azure-sdk-for-go/arm/storage"
+	azurestorage "github.com/Azure/azure-sdk-for-go/storage"
+	"github.com/Azure/go-autorest/autorest"
+	"github.com/Azure/go-autorest/autorest/to"
+	"github.com/juju/errors"
+
+	"github.com/juju/juju/environs"
+	internalazurestorage "github.com/juju/juju/provider/azure/internal/azurestorage"
+)
+
+// Azure storage accounts have two keys, so that one may be
+// regenerated while clients continue to use the other.
+const (
+	storageAccountKey1 = "key1"
+	storageAccountKey2 = "key2"
+)
+
+var storageAccountKeyNames = []string{storageAccountKey1, storageAccountKey2}
+
+func isStorageAccountKeyName(name string) bool {
+	return name == storageAccountKey1 || name == storageAccountKey2
+}
+
+var _ environs.StorageCredentialRotator = (*azureEnviron)(nil)
+
+// RotateStorageCredentials is specified in the
+// environs.StorageCredentialRotator interface.
+//
+// The key of the model's primary storage account that is not in use
+// is regenerated, and the environ switches to it. Once the key name
+// has been recorded in the model config, the key previously in use is
+// regenerated, so that it is no longer valid. Other environs for the
+// model may still hold the old key; their storage clients fetch the
+// key afresh and retry when it is rejected. The keys of any other
+// storage accounts holding OS disks are left alone; they are fetched
+// afresh each time they are used.
+func (env *azureEnviron) RotateStorageCredentials(recordCredentials func(map[string]interface{}) error) error {
+	env.mu.Lock()
+	storageAccount, err := env.getStorageAccountLocked(false)
+	if err != nil {
+		env.mu.Unlock()
+		return errors.Annotate(err, "getting storage account")
+	}
+	accountName := to.String(storageAccount.Name)
+	currentKey, err := env.getStorageAccountKeyLocked(accountName, false)
+	if err != nil {
+		env.mu.Unlock()
+		return errors.Annotate(err, "getting storage account key")
+	}
+	currentKeyName, nextKeyName := storageAccountKey1, storageAccountKey2
+	if to.String(currentKey.KeyName) == storageAccountKey2 {
+		currentKeyName, nextKeyName = nextKeyName, currentKeyName
+	}
+	nextKey, err := env.regenerateStorageAccountKey(accountName, nextKeyName)
+	if err != nil {
+		env.mu.Unlock()
+		return errors.Trace(err)
+	}
+	env.storageAccountKey = nextKey
+	env.mu.Unlock()
+
+	if err := recordCredentials(map[string]interface{}{
+		configAttrStorageAccountKeyName: nextKeyName,
+	}); err != nil {
+		return errors.Annotatef(err, "recording storage account key %q", nextKeyName)
+	}
+
+	env.mu.Lock()
+	defer env.mu.Unlock()
+	if _, err := env.regenerateStorageAccountKey(accountName, currentKeyName); err != nil {
+		return errors.Trace(err)
+	}
+	return nil
+}
+
+// regenerateStorageAccountKey regenerates the named key of the storage
+// account with the given name, and returns the new key. This method
+// assumes that env.mu is held.
+func (env *azureEnviron) regenerateStorageAccountKey(accountName, keyName string) (*storage.AccountKey, error) {
+	logger.Debugf("regenerating key %q of storage account %q", keyName, accountName)
+	client := storage.AccountsClient{env.storage}
+	var result storage.AccountListKeysResult
+	if err := env.callAPI(func() (autorest.Response, error) {
+		var err error
+		result, err = client.RegenerateKey(
+			env.resourceGroup, accountName,
+			storage.AccountRegenerateKeyParameters{
+				KeyName: to.StringPtr(keyName),
+			},
+		)
+		return result.Response, err
+	}); err != nil {
+		return nil, errors.Annotatef(err, "regenerating storage account key %q", keyName)
+	}
+	key, err := findStorageAccountKey(result, keyName)
+	if err != nil {
+		return nil, errors.Annotatef(err, "regenerating storage account key %q", keyName)
+	}
+	return key, nil
+}
+
+// isStorageAuthenticationError reports whether the given error was
+// returned by the Azure storage service because it rejected the
+// storage account key used to sign the request.
+func isStorageAuthenticationError(err error) bool {
+	serviceErr, ok := errors.Cause(err).(azurestorage.AzureStorageServiceError)
+	if !ok {
+		return false
+	}
+	return serviceErr.StatusCode == http.StatusForbidden && serviceErr.Code == "AuthenticationFailed"
+}
+
+// keyRefreshingClient is an internalazurestorage.Client whose blob
+// service clients fetch the storage account key afresh, and retry,
+// if the key they were created with is rejected.
+type keyRefreshingClient struct {
+	env    *azureEnviron
+	client internalazurestorage.Client
+}
+
+// GetBlobService is part of the internalazurestorage.Client interface.
+func (c *keyRefreshingClient) GetBlobService() internalazurestorage.BlobStorageClient {
+	return keyRefreshingBlobClient{c}
+}
+
+// retry calls f with the client's blob service client. If the storage
+// account key is rejected, the key is fetched afresh, and f is called
+// once more with a client using the new key.
+func (c *keyRefreshingClient) retry(f func(internalazurestorage.BlobStorageClient) error) error {
+	err := f(c.client.GetBlobService())
+	if !isStorageAuthenticationError(err) {
+		return err
+	}
+	logger.Debugf("storage account key rejected, refreshing: %v", err)
+	client, refreshErr := c.env.newStorageClient(true)
+	if refreshErr != nil {
+		return errors.Annotate(refreshErr, "refreshing storage account key")
+	}
+	c.client = client
+	return f(client.GetBlobService())
+}
+
+// keyRefreshingBlobClient is the internalazurestorage.BlobStorageClient
+// returned by keyRefreshingClient.
+type keyRefreshingBlobClient struct {
+	*keyRefreshingClient
+}
+
+// ListBlobs is part of the internalazurestorage.BlobStorageClient interface.
+func (c keyRefreshingBlobClient) ListBlobs(
+	container string, params azurestorage.ListBlobsParameters,
+) (azurestorage.BlobListResponse, error) {
+	var result azurestorage.BlobListResponse
+	err := c.retry(func(client internalazurestorage.BlobStorageClient) error {
+		var err error
+		result, err = client.ListBlobs(container, params)
+		return err
+	})
+	return result, err
+}
+
+// DeleteBlobIfExists is part of the internalazurestorage.BlobStorageClient interface.
+func (c keyRefreshingBlobClient) DeleteBlobIfExists(
+	container, name string, extraHeaders map[string]string,
+) (bool, error) {
+	var result bool
+	err := c.retry(func(client internalazurestorage.BlobStorageClient) error {
+		var err error
+		result, err = client.DeleteBlobIfExists(container, name, extraHeaders)
+		return err
+	})
+	return result, err
+}
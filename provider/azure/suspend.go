@@ -0,0 +1,77 @@
+// Copyright 2016 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package azure
+
+import (
+	"net/http"
+	"sync"
+
+	"github.com/Azure/azure-sdk-for-go/arm/compute"
+	"github.com/Azure/go-autorest/autorest"
+	"github.com/juju/errors"
+
+	"github.com/juju/juju/environs"
+	"github.com/juju/juju/instance"
+)
+
+var _ environs.InstanceSuspender = (*azureEnviron)(nil)
+
+// SuspendInstances is specified in the environs.InstanceSuspender interface.
+//
+// Virtual machines are deallocated, rather than just stopped: Azure does
+// not charge for the compute resources of deallocated virtual machines.
+// Deallocating a virtual machine releases its dynamic public IP address,
+// so the machine may have a different address once it is resumed.
+func (env *azureEnviron) SuspendInstances(ids ...instance.Id) error {
+	vmClient := compute.VirtualMachinesClient{env.compute}
+	return env.virtualMachinePowerOp(ids, "deallocating", func(vmName string) (autorest.Response, error) {
+		return vmClient.Deallocate(env.resourceGroup, vmName, nil)
+	})
+}
+
+// ResumeInstances is specified in the environs.InstanceSuspender interface.
+func (env *azureEnviron) ResumeInstances(ids ...instance.Id) error {
+	vmClient := compute.VirtualMachinesClient{env.compute}
+	return env.virtualMachinePowerOp(ids, "starting", func(vmName string) (autorest.Response, error) {
+		return vmClient.Start(env.resourceGroup, vmName, nil)
+	})
+}
+
+// virtualMachinePowerOp calls the given function for each of the
+// specified virtual machines concurrently, and waits for them all
+// to complete. The first error encountered is returned.
+func (env *azureEnviron) virtualMachinePowerOp(
+	ids []instance.Id,
+	verb string,
+	op func(vmName string) (autorest.Response, error),
+) error {
+	errs := make([]error, len(ids))
+	var wg sync.WaitGroup
+	for i, id := range ids {
+		wg.Add(1)
+		go func(i int, vmName string) {
+			defer wg.Done()
+			logger.Debugf("- %s virtual machine (%s)", verb, vmName)
+			var result autorest.Response
+			err := env.callAPI(func() (autorest.Response, error) {
+				var err error
+				result, err = op(vmName)
+				return result, err
+			})
+			if err != nil {
+				if result.Response != nil && result.StatusCode == http.StatusNotFound {
+					err = errors.NotFoundf("instance %q", vmName)
+				}
+				errs[i] = errors.Annotatef(err, "%s virtual machine %q", verb, vmName)
+			}
+		}(i, string(id))
+	}
+	wg.Wait()
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -12,6 +12,7 @@ var unsupportedConstraints = []string{
 	constraints.InstanceType,
 	constraints.Tags,
 	constraints.VirtType,
+	constraints.MaxHourlyCost,
 }
 
 // ConstraintsValidator returns a Validator instance which
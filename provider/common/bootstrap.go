@@ -37,6 +37,9 @@ import (
 
 var logger = loggo.GetLogger("juju.provider.common")
 
+// errInterrupted is returned by WaitSSH if it is interrupted.
+var errInterrupted = errors.New("interrupted")
+
 // Bootstrap is a common implementation of the Bootstrap method defined on
 // environs.Environ; we strongly recommend that this implementation be used
 // when writing a new provider.
@@ -136,52 +139,68 @@ func BootstrapInstance(ctx environs.BootstrapContext, env environs.Environ, args
 	if args.CloudRegion != "" {
 		cloudRegion += "/" + args.CloudRegion
 	}
-	fmt.Fprintf(ctx.GetStderr(), "Launching controller instance(s) on %s...\n", cloudRegion)
-	// Print instance status reports status changes during provisioning.
-	// Note the carriage returns, meaning subsequent prints are to the same
-	// line of stderr, not a new line.
-	instanceStatus := func(settableStatus status.Status, info string, data map[string]interface{}) error {
-		// The data arg is not expected to be used in this case, but
-		// print it, rather than ignore it, if we get something.
-		dataString := ""
-		if len(data) > 0 {
-			dataString = fmt.Sprintf(" %v", data)
+	var result *environs.StartInstanceResult
+	if args.Resume {
+		fmt.Fprintf(ctx.GetStderr(), "Resuming bootstrap of controller instance on %s...\n", cloudRegion)
+		result, err = existingBootstrapInstance(env, args, availableTools)
+		if err != nil {
+			return nil, "", nil, errors.Annotate(err, "cannot resume bootstrap")
 		}
-		fmt.Fprintf(ctx.GetStderr(), " - %s%s\r", info, dataString)
-		return nil
-	}
-	// Likely used after the final instanceStatus call to white-out the
-	// current stderr line before the next use, removing any residual status
-	// reporting output.
-	statusCleanup := func(info string) error {
-		// The leading spaces account for the leading characters
-		// emitted by instanceStatus above.
-		fmt.Fprintf(ctx.GetStderr(), "   %s\r", info)
-		return nil
-	}
-	result, err := env.StartInstance(environs.StartInstanceParams{
-		ControllerUUID:  args.ControllerConfig.ControllerUUID(),
-		Constraints:     args.BootstrapConstraints,
-		Tools:           availableTools,
-		InstanceConfig:  instanceConfig,
-		Placement:       args.Placement,
-		ImageMetadata:   imageMetadata,
-		StatusCallback:  instanceStatus,
-		CleanupCallback: statusCleanup,
-	})
-	if err != nil {
-		return nil, "", nil, errors.Annotate(err, "cannot start bootstrap instance")
-	}
-	// We need some padding below to overwrite any previous messages. We'll use a width of 40.
-	msg := fmt.Sprintf(" - %s", result.Instance.Id())
-	if len(msg) < 40 {
-		padding := make([]string, 40-len(msg))
-		msg += strings.Join(padding, " ")
+		fmt.Fprintf(ctx.GetStderr(), " - %s\n", result.Instance.Id())
+	} else {
+		fmt.Fprintf(ctx.GetStderr(), "Launching controller instance(s) on %s...\n", cloudRegion)
+		// Print instance status reports status changes during provisioning.
+		// Note the carriage returns, meaning subsequent prints are to the same
+		// line of stderr, not a new line.
+		instanceStatus := func(settableStatus status.Status, info string, data map[string]interface{}) error {
+			// The data arg is not expected to be used in this case, but
+			// print it, rather than ignore it, if we get something.
+			dataString := ""
+			if len(data) > 0 {
+				dataString = fmt.Sprintf(" %v", data)
+			}
+			fmt.Fprintf(ctx.GetStderr(), " - %s%s\r", info, dataString)
+			return nil
+		}
+		// Likely used after the final instanceStatus call to white-out the
+		// current stderr line before the next use, removing any residual status
+		// reporting output.
+		statusCleanup := func(info string) error {
+			// The leading spaces account for the leading characters
+			// emitted by instanceStatus above.
+			fmt.Fprintf(ctx.GetStderr(), "   %s\r", info)
+			return nil
+		}
+		result, err = env.StartInstance(environs.StartInstanceParams{
+			ControllerUUID:  args.ControllerConfig.ControllerUUID(),
+			Constraints:     args.BootstrapConstraints,
+			Tools:           availableTools,
+			InstanceConfig:  instanceConfig,
+			Placement:       args.Placement,
+			ImageMetadata:   imageMetadata,
+			StatusCallback:  instanceStatus,
+			CleanupCallback: statusCleanup,
+		})
+		if err != nil {
+			return nil, "", nil, errors.Annotate(err, "cannot start bootstrap instance")
+		}
+		// We need some padding below to overwrite any previous messages. We'll use a width of 40.
+		msg := fmt.Sprintf(" - %s", result.Instance.Id())
+		if len(msg) < 40 {
+			padding := make([]string, 40-len(msg))
+			msg += strings.Join(padding, " ")
+		}
+		fmt.Fprintln(ctx.GetStderr(), msg)
 	}
-	fmt.Fprintln(ctx.GetStderr(), msg)
 
 	finalize := func(ctx environs.BootstrapContext, icfg *instancecfg.InstanceConfig, opts environs.BootstrapDialOpts) error {
-		icfg.Bootstrap.BootstrapMachineInstanceId = result.Instance.Id()
+		if _, ok := env.(environs.InstanceIdentifier); ok {
+			// The bootstrap agent will identify the instance
+			// itself, so there is no need to pass its ID.
+			icfg.Bootstrap.IdentifyInstance = true
+		} else {
+			icfg.Bootstrap.BootstrapMachineInstanceId = result.Instance.Id()
+		}
 		icfg.Bootstrap.BootstrapMachineHardwareCharacteristics = result.Hardware
 		envConfig := env.Config()
 		if result.Config != nil {
@@ -200,6 +219,41 @@ func BootstrapInstance(ctx environs.BootstrapContext, env environs.Environ, args
 	return result, selectedSeries, finalize, nil
 }
 
+// existingBootstrapInstance returns a StartInstanceResult describing
+// the instance started by a previous, failed attempt to bootstrap the
+// controller. The instance's hardware characteristics are not known,
+// so its architecture is taken from the bootstrap constraints, or
+// else from the available tools.
+func existingBootstrapInstance(
+	env environs.Environ, args environs.BootstrapParams, availableTools coretools.List,
+) (*environs.StartInstanceResult, error) {
+	ids, err := env.ControllerInstances(args.ControllerConfig.ControllerUUID())
+	if errors.Cause(err) == environs.ErrNotBootstrapped || errors.Cause(err) == environs.ErrNoInstances {
+		return nil, errors.NotFoundf("bootstrap instance")
+	} else if err != nil {
+		return nil, errors.Trace(err)
+	}
+	instances, err := env.Instances(ids[:1])
+	if err != nil {
+		return nil, errors.Annotatef(err, "getting bootstrap instance %s", ids[0])
+	}
+	var bootstrapArch string
+	if args.BootstrapConstraints.Arch != nil {
+		bootstrapArch = *args.BootstrapConstraints.Arch
+	} else if arches := availableTools.Arches(); len(arches) == 1 {
+		bootstrapArch = arches[0]
+	} else {
+		return nil, errors.Errorf(
+			"cannot determine architecture of bootstrap instance %s; "+
+				"specify it with --bootstrap-constraints", ids[0],
+		)
+	}
+	return &environs.StartInstanceResult{
+		Instance: instances[0],
+		Hardware: &instance.HardwareCharacteristics{Arch: &bootstrapArch},
+	}, nil
+}
+
 // FinishBootstrap completes the bootstrap process by connecting
 // to the instance via SSH and carrying out the cloud-config.
 //
@@ -215,20 +269,92 @@ var FinishBootstrap = func(
 	interrupted := make(chan os.Signal, 1)
 	ctx.InterruptNotify(interrupted)
 	defer ctx.StopInterruptNotify(interrupted)
+	checkNonceCommand := GetCheckNonceCommand(instanceConfig)
 	addr, err := WaitSSH(
 		ctx.GetStderr(),
 		interrupted,
 		client,
-		GetCheckNonceCommand(instanceConfig),
+		checkNonceCommand,
 		&RefreshableInstance{inst, env},
 		opts,
 	)
-	if err != nil {
+	if err == errInterrupted {
 		return err
+	} else if err != nil {
+		return verifyWithoutSSH(ctx, env, inst.Id(), checkNonceCommand, err)
+	}
+	progress, err := readBootstrapProgress(client, addr, instanceConfig)
+	if err != nil {
+		return errors.Annotate(err, "reading bootstrap progress")
+	}
+	instanceConfig.Bootstrap.Progress = progress
+	switch {
+	case progress.Reached(instancecfg.BootstrapConfigured):
+		fmt.Fprintf(ctx.GetStderr(), "Bootstrap instance %s has already been configured\n", inst.Id())
+		return nil
+	case progress.Reached(instancecfg.BootstrapConfiguring):
+		fmt.Fprintf(ctx.GetStderr(), "Resuming configuration of bootstrap instance %s (%s)\n", inst.Id(), progress)
 	}
 	return ConfigureMachine(ctx, client, addr, instanceConfig)
 }
 
+// bootstrapProgressFile returns the path of the file on the bootstrap
+// instance in which its progress is recorded.
+func bootstrapProgressFile(instanceConfig *instancecfg.InstanceConfig) string {
+	return path.Join(instanceConfig.DataDir, instancecfg.BootstrapProgressFile)
+}
+
+// readBootstrapProgress returns the progress recorded on the bootstrap
+// instance at the given address. If no progress has been recorded,
+// instancecfg.BootstrapNotStarted is returned.
+var readBootstrapProgress = func(client ssh.Client, host string, instanceConfig *instancecfg.InstanceConfig) (instancecfg.BootstrapProgress, error) {
+	script := fmt.Sprintf(
+		"cat %s 2>/dev/null || true",
+		utils.ShQuote(bootstrapProgressFile(instanceConfig)),
+	)
+	cmd := client.Command("ubuntu@"+host, []string{"/bin/bash"}, nil)
+	cmd.Stdin = strings.NewReader(script)
+	output, err := cmd.Output()
+	if err != nil {
+		return "", errors.Trace(err)
+	}
+	return instancecfg.BootstrapProgress(strings.TrimSpace(string(output))), nil
+}
+
+// verifyWithoutSSH is called when the bootstrap instance could not be
+// reached with SSH. If the Environ implements InstanceCommandRunner,
+// the instance is checked through the provider, so that the error
+// returned distinguishes an unreachable instance from one that failed
+// to provision. Otherwise, sshErr is returned unchanged.
+func verifyWithoutSSH(
+	ctx environs.BootstrapContext,
+	env environs.Environ,
+	id instance.Id,
+	checkNonceCommand string,
+	sshErr error,
+) error {
+	runner, ok := env.(environs.InstanceCommandRunner)
+	if !ok {
+		return sshErr
+	}
+	fmt.Fprintf(ctx.GetStderr(), "Verifying instance %s without SSH\n", id)
+	result, err := runner.RunCommand(id, checkNonceCommand)
+	if err != nil {
+		logger.Warningf("cannot verify instance %s without SSH: %v", id, err)
+		return sshErr
+	}
+	if result.Code != 0 {
+		return errors.Annotatef(
+			sshErr, "instance %s has not been provisioned (%s)",
+			id, strings.TrimSpace(result.Stderr),
+		)
+	}
+	return errors.Annotatef(
+		sshErr, "instance %s has been provisioned, but cannot be reached with SSH",
+		id,
+	)
+}
+
 func GetCheckNonceCommand(instanceConfig *instancecfg.InstanceConfig) string {
 	// Each attempt to connect to an address must verify the machine is the
 	// bootstrap machine by checking its nonce file exists and contains the
@@ -510,7 +636,7 @@ func WaitSSH(
 			}
 			return "", fmt.Errorf(format, args...)
 		case <-interrupted:
-			return "", fmt.Errorf("interrupted")
+			return "", errInterrupted
 		case <-checker.Dead():
 			result, err := checker.Result()
 			if err != nil {
@@ -232,6 +232,94 @@ func (s *BootstrapSuite) TestSuccess(c *gc.C) {
 	c.Assert(result.Series, gc.Equals, config.PreferredSeries(mocksConfig))
 }
 
+func (s *BootstrapSuite) TestBootstrapResume(c *gc.C) {
+	s.PatchValue(&jujuversion.Current, coretesting.FakeVersionNumber)
+	env := &mockEnviron{
+		storage: newStorage(s, c),
+		config:  configGetter(c),
+		startInstance: func(
+			string, constraints.Value, []string, tools.List, *instancecfg.InstanceConfig,
+		) (instance.Instance, *instance.HardwareCharacteristics, []network.InterfaceInfo, error) {
+			c.Fatalf("unexpected call to StartInstance")
+			return nil, nil, nil, nil
+		},
+		controllerInstances: func(controllerUUID string) ([]instance.Id, error) {
+			c.Assert(controllerUUID, gc.Equals, coretesting.ControllerTag.Id())
+			return []instance.Id{"i-existing"}, nil
+		},
+		instances: func(ids []instance.Id) ([]instance.Instance, error) {
+			c.Assert(ids, jc.DeepEquals, []instance.Id{"i-existing"})
+			return []instance.Instance{&mockInstance{id: "i-existing"}}, nil
+		},
+	}
+
+	var finished instance.Instance
+	s.PatchValue(&common.FinishBootstrap, func(
+		_ environs.BootstrapContext,
+		_ ssh.Client,
+		_ environs.Environ,
+		inst instance.Instance,
+		icfg *instancecfg.InstanceConfig,
+		_ environs.BootstrapDialOpts,
+	) error {
+		finished = inst
+		c.Check(icfg.Bootstrap.BootstrapMachineInstanceId, gc.Equals, instance.Id("i-existing"))
+		return nil
+	})
+
+	ctx := envtesting.BootstrapContext(c)
+	cons := constraints.MustParse("arch=ppc64el")
+	result, err := common.Bootstrap(ctx, env, environs.BootstrapParams{
+		ControllerConfig:     coretesting.FakeControllerConfig(),
+		BootstrapConstraints: cons,
+		Resume:               true,
+		AvailableTools: tools.List{
+			&tools.Tools{
+				Version: version.Binary{
+					Number: jujuversion.Current,
+					Arch:   "ppc64el",
+					Series: series.HostSeries(),
+				},
+			},
+		}})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(result.Arch, gc.Equals, "ppc64el")
+
+	icfg, err := instancecfg.NewBootstrapInstanceConfig(
+		coretesting.FakeControllerConfig(), cons, cons, result.Series, "",
+	)
+	c.Assert(err, jc.ErrorIsNil)
+	err = result.Finalize(ctx, icfg, environs.BootstrapDialOpts{})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(finished, gc.NotNil)
+	c.Assert(finished.Id(), gc.Equals, instance.Id("i-existing"))
+}
+
+func (s *BootstrapSuite) TestBootstrapResumeNoInstance(c *gc.C) {
+	s.PatchValue(&jujuversion.Current, coretesting.FakeVersionNumber)
+	env := &mockEnviron{
+		storage: newStorage(s, c),
+		config:  configGetter(c),
+		controllerInstances: func(string) ([]instance.Id, error) {
+			return nil, environs.ErrNotBootstrapped
+		},
+	}
+	ctx := envtesting.BootstrapContext(c)
+	_, err := common.Bootstrap(ctx, env, environs.BootstrapParams{
+		ControllerConfig: coretesting.FakeControllerConfig(),
+		Resume:           true,
+		AvailableTools: tools.List{
+			&tools.Tools{
+				Version: version.Binary{
+					Number: jujuversion.Current,
+					Arch:   arch.HostArch(),
+					Series: series.HostSeries(),
+				},
+			},
+		}})
+	c.Assert(err, gc.ErrorMatches, "cannot resume bootstrap: bootstrap instance not found")
+}
+
 type neverRefreshes struct {
 }
 
@@ -293,6 +381,49 @@ func (s *BootstrapSuite) TestWaitSSHNoticesProvisioningFailures(c *gc.C) {
 	c.Check(err, gc.ErrorMatches, `instance provisioning failed \(blargh\)`)
 }
 
+type commandRunnerEnviron struct {
+	environs.Environ
+	result *environs.InstanceCommandResult
+	err    error
+	script string
+}
+
+func (env *commandRunnerEnviron) RunCommand(id instance.Id, script string) (*environs.InstanceCommandResult, error) {
+	env.script = script
+	return env.result, env.err
+}
+
+func (s *BootstrapSuite) TestVerifyWithoutSSHNotSupported(c *gc.C) {
+	sshErr := errors.New("waited for 10m0s without being able to connect")
+	err := common.VerifyWithoutSSH(envtesting.BootstrapContext(c), nil, "i-0", "check", sshErr)
+	c.Assert(err, gc.Equals, sshErr)
+}
+
+func (s *BootstrapSuite) TestVerifyWithoutSSHProvisioned(c *gc.C) {
+	env := &commandRunnerEnviron{result: &environs.InstanceCommandResult{}}
+	sshErr := errors.New("waited for 10m0s without being able to connect")
+	err := common.VerifyWithoutSSH(envtesting.BootstrapContext(c), env, "i-0", "check", sshErr)
+	c.Assert(err, gc.ErrorMatches, "instance i-0 has been provisioned, but cannot be reached with SSH: waited for 10m0s without being able to connect")
+	c.Assert(env.script, gc.Equals, "check")
+}
+
+func (s *BootstrapSuite) TestVerifyWithoutSSHNotProvisioned(c *gc.C) {
+	env := &commandRunnerEnviron{result: &environs.InstanceCommandResult{
+		Code:   1,
+		Stderr: "/var/lib/juju/nonce.txt does not exist\n",
+	}}
+	sshErr := errors.New("waited for 10m0s without being able to connect")
+	err := common.VerifyWithoutSSH(envtesting.BootstrapContext(c), env, "i-0", "check", sshErr)
+	c.Assert(err, gc.ErrorMatches, `instance i-0 has not been provisioned \(/var/lib/juju/nonce.txt does not exist\): waited for 10m0s without being able to connect`)
+}
+
+func (s *BootstrapSuite) TestVerifyWithoutSSHRunCommandFails(c *gc.C) {
+	env := &commandRunnerEnviron{err: errors.New("no agent")}
+	sshErr := errors.New("waited for 10m0s without being able to connect")
+	err := common.VerifyWithoutSSH(envtesting.BootstrapContext(c), env, "i-0", "check", sshErr)
+	c.Assert(err, gc.Equals, sshErr)
+}
+
 type brokenAddresses struct {
 	neverRefreshes
 }
@@ -20,6 +20,8 @@ import (
 )
 
 type allInstancesFunc func() ([]instance.Instance, error)
+type instancesFunc func([]instance.Id) ([]instance.Instance, error)
+type controllerInstancesFunc func(string) ([]instance.Id, error)
 type startInstanceFunc func(string, constraints.Value, []string, tools.List, *instancecfg.InstanceConfig) (instance.Instance, *instance.HardwareCharacteristics, []network.InterfaceInfo, error)
 type stopInstancesFunc func([]instance.Id) error
 type getToolsSourcesFunc func() ([]simplestreams.DataSource, error)
@@ -27,15 +29,17 @@ type configFunc func() *config.Config
 type setConfigFunc func(*config.Config) error
 
 type mockEnviron struct {
-	storage          storage.Storage
-	allInstances     allInstancesFunc
-	startInstance    startInstanceFunc
-	stopInstances    stopInstancesFunc
-	getToolsSources  getToolsSourcesFunc
-	config           configFunc
-	setConfig        setConfigFunc
-	storageProviders jujustorage.StaticProviderRegistry
-	environs.Environ // stub out other methods with panics
+	storage             storage.Storage
+	allInstances        allInstancesFunc
+	instances           instancesFunc
+	controllerInstances controllerInstancesFunc
+	startInstance       startInstanceFunc
+	stopInstances       stopInstancesFunc
+	getToolsSources     getToolsSourcesFunc
+	config              configFunc
+	setConfig           setConfigFunc
+	storageProviders    jujustorage.StaticProviderRegistry
+	environs.Environ    // stub out other methods with panics
 }
 
 func (env *mockEnviron) Storage() storage.Storage {
@@ -45,6 +49,14 @@ func (env *mockEnviron) Storage() storage.Storage {
 func (env *mockEnviron) AllInstances() ([]instance.Instance, error) {
 	return env.allInstances()
 }
+
+func (env *mockEnviron) Instances(ids []instance.Id) ([]instance.Instance, error) {
+	return env.instances(ids)
+}
+
+func (env *mockEnviron) ControllerInstances(controllerUUID string) ([]instance.Id, error) {
+	return env.controllerInstances(controllerUUID)
+}
 func (env *mockEnviron) StartInstance(args environs.StartInstanceParams) (*environs.StartInstanceResult, error) {
 	inst, hw, networkInfo, err := env.startInstance(
 		args.Placement,
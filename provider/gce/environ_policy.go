@@ -28,6 +28,7 @@ func (env *environ) PrecheckInstance(series string, cons constraints.Value, plac
 var unsupportedConstraints = []string{
 	constraints.Tags,
 	constraints.VirtType,
+	constraints.MaxHourlyCost,
 }
 
 // instanceTypeConstraints defines the fields defined on each of the
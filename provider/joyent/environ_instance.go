@@ -54,6 +54,7 @@ var unsupportedConstraints = []string{
 	constraints.CpuPower,
 	constraints.Tags,
 	constraints.VirtType,
+	constraints.MaxHourlyCost,
 }
 
 // ConstraintsValidator is defined on the Environs interface.
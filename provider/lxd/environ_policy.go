@@ -33,6 +33,7 @@ var unsupportedConstraints = []string{
 	constraints.InstanceType,
 	constraints.Tags,
 	constraints.VirtType,
+	constraints.MaxHourlyCost,
 }
 
 // ConstraintsValidator returns a Validator value which is used to
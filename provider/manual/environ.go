@@ -300,6 +300,7 @@ var unsupportedConstraints = []string{
 	constraints.InstanceType,
 	constraints.Tags,
 	constraints.VirtType,
+	constraints.MaxHourlyCost,
 }
 
 // ConstraintsValidator is defined on the Environs interface.
@@ -357,6 +357,7 @@ func (e *Environ) nova() *nova.Client {
 var unsupportedConstraints = []string{
 	constraints.Tags,
 	constraints.CpuPower,
+	constraints.MaxHourlyCost,
 }
 
 // ConstraintsValidator is defined on the Environs interface.
@@ -15,18 +15,21 @@ import (
 // The vmware-specific config keys.
 const (
 	cfgExternalNetwork = "external-network"
+	cfgDatastore       = "datastore"
 )
 
 // configFields is the spec for each vmware config value's type.
 var (
 	configFields = schema.Fields{
 		cfgExternalNetwork: schema.String(),
+		cfgDatastore:       schema.String(),
 	}
 
 	requiredFields = []string{}
 
 	configDefaults = schema.Defaults{
 		cfgExternalNetwork: "",
+		cfgDatastore:       "",
 	}
 
 	configImmutableFields = []string{}
@@ -79,6 +82,10 @@ func (c *environConfig) externalNetwork() string {
 	return c.attrs[cfgExternalNetwork].(string)
 }
 
+func (c *environConfig) datastore() string {
+	return c.attrs[cfgDatastore].(string)
+}
+
 // validate checks vmware-specific config values.
 func (c environConfig) validate() error {
 	// All fields must be populated, even with just the default.
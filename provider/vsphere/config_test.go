@@ -89,6 +89,13 @@ var newConfigTests = []configTestSpec{{
 	info:   "unknown field is not touched",
 	insert: testing.Attrs{"unknown-field": "12345"},
 	expect: testing.Attrs{"unknown-field": "12345"},
+}, {
+	info:   "datastore is optional",
+	expect: testing.Attrs{"datastore": ""},
+}, {
+	info:   "datastore can be set",
+	insert: testing.Attrs{"datastore": "datastore1"},
+	expect: testing.Attrs{"datastore": "datastore1"},
 }}
 
 func (*ConfigSuite) TestNewModelConfig(c *gc.C) {
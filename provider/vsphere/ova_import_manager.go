@@ -79,7 +79,13 @@ func (m *ovaImportManager) importOva(ecfg *environConfig, instSpec *instanceSpec
 
 	ovfManager := object.NewOvfManager(m.client.connection.Client)
 	resourcePool := object.NewReference(m.client.connection.Client, *instSpec.zone.r.ResourcePool)
-	datastore := object.NewReference(m.client.connection.Client, instSpec.zone.r.Datastore[0])
+	var datastore object.Reference = object.NewReference(m.client.connection.Client, instSpec.zone.r.Datastore[0])
+	if name := ecfg.datastore(); name != "" {
+		datastore, err = m.client.finder.Datastore(context.TODO(), name)
+		if err != nil {
+			return nil, errors.Annotatef(err, "finding datastore %q", name)
+		}
+	}
 	spec, err := ovfManager.CreateImportSpec(context.TODO(), string(ovf), resourcePool, datastore, cisp)
 	if err != nil {
 		return nil, errors.Trace(err)
@@ -63,15 +63,16 @@ type inMsgV0 struct {
 }
 
 type inMsgV1 struct {
-	RequestId uint64          `json:"request-id"`
-	Type      string          `json:"type"`
-	Version   int             `json:"version"`
-	Id        string          `json:"id"`
-	Request   string          `json:"request"`
-	Params    json.RawMessage `json:"params"`
-	Error     string          `json:"error"`
-	ErrorCode string          `json:"error-code"`
-	Response  json.RawMessage `json:"response"`
+	RequestId  uint64          `json:"request-id"`
+	Type       string          `json:"type"`
+	Version    int             `json:"version"`
+	Id         string          `json:"id"`
+	Request    string          `json:"request"`
+	OnBehalfOf string          `json:"on-behalf-of"`
+	Params     json.RawMessage `json:"params"`
+	Error      string          `json:"error"`
+	ErrorCode  string          `json:"error-code"`
+	Response   json.RawMessage `json:"response"`
 }
 
 // outMsg holds an outgoing message.
@@ -88,15 +89,16 @@ type outMsgV0 struct {
 }
 
 type outMsgV1 struct {
-	RequestId uint64      `json:"request-id,omitempty"`
-	Type      string      `json:"type,omitempty"`
-	Version   int         `json:"version,omitempty"`
-	Id        string      `json:"id,omitempty"`
-	Request   string      `json:"request,omitempty"`
-	Params    interface{} `json:"params,omitempty"`
-	Error     string      `json:"error,omitempty"`
-	ErrorCode string      `json:"error-code,omitempty"`
-	Response  interface{} `json:"response,omitempty"`
+	RequestId  uint64      `json:"request-id,omitempty"`
+	Type       string      `json:"type,omitempty"`
+	Version    int         `json:"version,omitempty"`
+	Id         string      `json:"id,omitempty"`
+	Request    string      `json:"request,omitempty"`
+	OnBehalfOf string      `json:"on-behalf-of,omitempty"`
+	Params     interface{} `json:"params,omitempty"`
+	Error      string      `json:"error,omitempty"`
+	ErrorCode  string      `json:"error-code,omitempty"`
+	Response   interface{} `json:"response,omitempty"`
 }
 
 func (c *Codec) Close() error {
@@ -132,10 +134,11 @@ func (c *Codec) ReadHeader(hdr *rpc.Header) error {
 	}
 	hdr.RequestId = c.msg.RequestId
 	hdr.Request = rpc.Request{
-		Type:    c.msg.Type,
-		Version: c.msg.Version,
-		Id:      c.msg.Id,
-		Action:  c.msg.Request,
+		Type:       c.msg.Type,
+		Version:    c.msg.Version,
+		Id:         c.msg.Id,
+		Action:     c.msg.Request,
+		OnBehalfOf: c.msg.OnBehalfOf,
 	}
 	hdr.Error = c.msg.Error
 	hdr.ErrorCode = c.msg.ErrorCode
@@ -265,13 +268,14 @@ func newOutMsgV0(hdr *rpc.Header, body interface{}) outMsgV0 {
 // reflect, but no.
 func newOutMsgV1(hdr *rpc.Header, body interface{}) outMsgV1 {
 	result := outMsgV1{
-		RequestId: hdr.RequestId,
-		Type:      hdr.Request.Type,
-		Version:   hdr.Request.Version,
-		Id:        hdr.Request.Id,
-		Request:   hdr.Request.Action,
-		Error:     hdr.Error,
-		ErrorCode: hdr.ErrorCode,
+		RequestId:  hdr.RequestId,
+		Type:       hdr.Request.Type,
+		Version:    hdr.Request.Version,
+		Id:         hdr.Request.Id,
+		Request:    hdr.Request.Action,
+		OnBehalfOf: hdr.Request.OnBehalfOf,
+		Error:      hdr.Error,
+		ErrorCode:  hdr.ErrorCode,
 	}
 	if hdr.IsRequest() {
 		result.Params = body
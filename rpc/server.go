@@ -79,6 +79,12 @@ type Request struct {
 
 	// Action holds the action to perform on the object.
 	Action string
+
+	// OnBehalfOf holds the tag of the entity on whose behalf the
+	// request is made, if it is not made on behalf of the entity
+	// that owns the connection. Such requests are served only by
+	// roots that implement DelegatingRoot.
+	OnBehalfOf string
 }
 
 // IsRequest returns whether the header represents an RPC request.  If
@@ -310,6 +316,16 @@ type Root interface {
 	Killer
 }
 
+// DelegatingRoot is an optional interface that may be implemented by a
+// Root to serve requests made on behalf of other entities, so that
+// several entities may share a single connection.
+type DelegatingRoot interface {
+	// Delegate returns a Root that serves requests made on behalf
+	// of the entity with the given tag, or an error if the entity
+	// that owns the connection may not act on its behalf.
+	Delegate(tag string) (Root, error)
+}
+
 // Killer represents a type that can be asked to abort any outstanding
 // requests.  The Kill method should return immediately.
 type Killer interface {
@@ -465,6 +481,19 @@ func (conn *Conn) bindRequest(hdr *Header) (boundRequest, error) {
 	if root == nil {
 		return boundRequest{}, errors.New("no service")
 	}
+	if hdr.Request.OnBehalfOf != "" {
+		delegatingRoot, ok := root.(DelegatingRoot)
+		if !ok {
+			return boundRequest{}, &serverError{
+				errors.Errorf("requests on behalf of %q not supported", hdr.Request.OnBehalfOf),
+			}
+		}
+		var err error
+		root, err = delegatingRoot.Delegate(hdr.Request.OnBehalfOf)
+		if err != nil {
+			return boundRequest{}, transformErrors(err)
+		}
+	}
 	caller, err := root.FindMethod(
 		hdr.Request.Type, hdr.Request.Version, hdr.Request.Action)
 	if err != nil {
@@ -197,6 +197,13 @@ func allCollections() collectionSchema {
 			rawAccess: true,
 		},
 
+		// This collection records the health of the simplestreams
+		// mirrors from which the controller fetches agent binaries.
+		toolsMirrorsC: {
+			global:    true,
+			rawAccess: true,
+		},
+
 		// This collection holds the last time the model user connected
 		// to the model.
 		modelUserLastConnectionC: {
@@ -252,11 +259,28 @@ func allCollections() collectionSchema {
 		},
 		minUnitsC: {},
 
+		// This collection holds the progress records of applications
+		// being renamed; see State.RenameApplication.
+		applicationRenamesC: {},
+
+		// This collection holds documents that track changes relevant
+		// to applications with a desired scale. It is used exclusively
+		// to trigger the application scaler.
+		scalesC: {},
+
 		// This collection holds documents that indicate units which are queued
 		// to be assigned to machines. It is used exclusively by the
 		// AssignUnitWorker.
 		assignUnitC: {},
 
+		// This collection holds the offers of applications' endpoints
+		// to other models, for cross-model relations.
+		applicationOffersC: {
+			indexes: []mgo.Index{{
+				Key: []string{"model-uuid", "application"},
+			}},
+		},
+
 		// meterStatusC is the collection used to store meter status information.
 		meterStatusC: {},
 		refcountsC:   {},
@@ -272,11 +296,13 @@ func allCollections() collectionSchema {
 		// -----
 
 		// These collections hold information associated with machines.
-		containerRefsC: {},
-		instanceDataC:  {},
-		machinesC:      {},
-		rebootC:        {},
-		sshHostKeysC:   {},
+		containerRefsC:       {},
+		instanceDataC:        {},
+		machinesC:            {},
+		machineReplacementsC: {},
+		provisioningErrorsC:  {},
+		rebootC:              {},
+		sshHostKeysC:         {},
 
 		// This collection contains information from removed machines
 		// that needs to be cleaned up in the provider.
@@ -315,6 +341,11 @@ func allCollections() collectionSchema {
 		},
 		volumeAttachmentsC: {},
 
+		// This collection holds the claims made on volumes and
+		// filesystems by the storage provisioners, so that those
+		// of different scopes do not act on an entity at once.
+		storageClaimsC: {},
+
 		// -----
 
 		providerIDsC:          {},
@@ -326,6 +357,11 @@ func allCollections() collectionSchema {
 		endpointBindingsC:     {},
 		openedPortsC:          {},
 
+		// This collection holds the exposure intents of applications,
+		// recording whether each should be exposed and the port ranges
+		// opened by its units. It is consumed by the firewaller.
+		exposureIntentsC: {},
+
 		// -----
 
 		// These collections hold information associated with actions.
@@ -369,6 +405,16 @@ func allCollections() collectionSchema {
 		// unit relation settings, model config, etc etc etc.
 		settingsC: {},
 
+		// This collection holds a record of changes to model config.
+		// Records are written in the same transaction as the change
+		// to the model's settings, and the oldest are removed as new
+		// ones are added.
+		modelConfigChangesC: {
+			indexes: []mgo.Index{{
+				Key: []string{"model-uuid", "seq"},
+			}},
+		},
+
 		constraintsC:        {},
 		storageConstraintsC: {},
 		statusesC:           {},
@@ -407,6 +453,8 @@ const (
 	actionresultsC           = "actionresults"
 	actionsC                 = "actions"
 	annotationsC             = "annotations"
+	applicationOffersC       = "applicationOffers"
+	applicationRenamesC      = "applicationRenames"
 	autocertCacheC           = "autocertCache"
 	assignUnitC              = "assignUnits"
 	auditingC                = "audit.log"
@@ -422,6 +470,7 @@ const (
 	containerRefsC           = "containerRefs"
 	controllersC             = "controllers"
 	controllerUsersC         = "controllerusers"
+	exposureIntentsC         = "exposureintents"
 	filesystemAttachmentsC   = "filesystemAttachments"
 	filesystemsC             = "filesystems"
 	globalSettingsC          = "globalSettings"
@@ -431,6 +480,7 @@ const (
 	leasesC                  = "leases"
 	machinesC                = "machines"
 	machineRemovalsC         = "machineremovals"
+	machineReplacementsC     = "machineReplacements"
 	meterStatusC             = "meterStatus"
 	metricsC                 = "metrics"
 	metricsManagerC          = "metricsmanager"
@@ -439,6 +489,7 @@ const (
 	migrationsC              = "migrations"
 	migrationsMinionSyncC    = "migrations.minionsync"
 	migrationsStatusC        = "migrations.status"
+	modelConfigChangesC      = "modelConfigChanges"
 	modelUserLastConnectionC = "modelUserLastConnection"
 	modelUsersC              = "modelusers"
 	modelsC                  = "models"
@@ -447,10 +498,12 @@ const (
 	payloadsC                = "payloads"
 	permissionsC             = "permissions"
 	providerIDsC             = "providerIDs"
+	provisioningErrorsC      = "provisioningErrors"
 	rebootC                  = "reboot"
 	relationScopesC          = "relationscopes"
 	relationsC               = "relations"
 	restoreInfoC             = "restoreInfo"
+	scalesC                  = "scales"
 	sequenceC                = "sequence"
 	applicationsC            = "applications"
 	endpointBindingsC        = "endpointbindings"
@@ -461,6 +514,7 @@ const (
 	statusesC                = "statuses"
 	statusesHistoryC         = "statuseshistory"
 	storageAttachmentsC      = "storageattachments"
+	storageClaimsC           = "storageclaims"
 	storageConstraintsC      = "storageconstraints"
 	storageInstancesC        = "storageinstances"
 	subnetsC                 = "subnets"
@@ -468,6 +522,7 @@ const (
 	linkLayerDevicesRefsC    = "linklayerdevicesrefs"
 	ipAddressesC             = "ip.addresses"
 	toolsmetadataC           = "toolsmetadata"
+	toolsMirrorsC            = "toolsmirrors"
 	txnLogC                  = "txns.log"
 	txnsC                    = "txns"
 	unitsC                   = "units"
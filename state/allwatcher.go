@@ -89,6 +89,9 @@ func makeAllWatcherCollectionInfo(collNames ...string) map[string]allWatcherStat
 		case openedPortsC:
 			collection.docType = reflect.TypeOf(backingOpenedPorts{})
 			collection.subsidiary = true
+		case instanceDataC:
+			collection.docType = reflect.TypeOf(backingInstanceData{})
+			collection.subsidiary = true
 		default:
 			panic(errors.Errorf("unknown collection %q", collName))
 		}
@@ -379,13 +382,14 @@ func (svc *backingApplication) updated(st *State, store *multiwatcherStore, id s
 		return errors.Errorf("charm url is nil")
 	}
 	info := &multiwatcher.ApplicationInfo{
-		ModelUUID:   st.ModelUUID(),
-		Name:        svc.Name,
-		Exposed:     svc.Exposed,
-		CharmURL:    svc.CharmURL.String(),
-		Life:        multiwatcher.Life(svc.Life.String()),
-		MinUnits:    svc.MinUnits,
-		Subordinate: svc.Subordinate,
+		ModelUUID:       st.ModelUUID(),
+		Name:            svc.Name,
+		Exposed:         svc.Exposed,
+		CharmURL:        svc.CharmURL.String(),
+		Life:            multiwatcher.Life(svc.Life.String()),
+		MinUnits:        svc.MinUnits,
+		Subordinate:     svc.Subordinate,
+		WorkloadVersion: svc.WorkloadVersion,
 	}
 	oldInfo := store.Get(info.EntityId())
 	needConfig := false
@@ -727,6 +731,38 @@ func (c *backingConstraints) mongoId() string {
 	panic("cannot find mongo id from constraints document")
 }
 
+type backingInstanceData instanceData
+
+func (i *backingInstanceData) updated(st *State, store *multiwatcherStore, id string) error {
+	parentID := multiwatcher.EntityId{
+		Kind:      "machine",
+		ModelUUID: st.ModelUUID(),
+		Id:        i.MachineId,
+	}
+	info, ok := store.Get(parentID).(*multiwatcher.MachineInfo)
+	if !ok {
+		// The machine info doesn't exist. Ignore the instance
+		// data until it does; the machine will read it then.
+		return nil
+	}
+	// The instance data is updated when an instance is resized,
+	// so keep the machine's hardware characteristics up to date.
+	newInfo := *info
+	newInfo.InstanceId = string(i.InstanceId)
+	newInfo.HardwareCharacteristics = hardwareCharacteristics(instanceData(*i))
+	store.Update(&newInfo)
+	return nil
+}
+
+func (i *backingInstanceData) removed(*multiwatcherStore, string, string, *State) error {
+	// The instance data is removed along with the machine.
+	return nil
+}
+
+func (i *backingInstanceData) mongoId() string {
+	return i.DocID
+}
+
 type backingSettings settingsDoc
 
 func (s *backingSettings) updated(st *State, store *multiwatcherStore, id string) error {
@@ -991,6 +1027,7 @@ func newAllWatcherStateBacking(st *State) Backing {
 		constraintsC,
 		settingsC,
 		openedPortsC,
+		instanceDataC,
 		actionsC,
 		blocksC,
 	)
@@ -1072,6 +1109,7 @@ func NewAllModelWatcherStateBacking(st *State) Backing {
 		constraintsC,
 		settingsC,
 		openedPortsC,
+		instanceDataC,
 	)
 	return &allModelWatcherStateBacking{
 		st:               st,
@@ -1923,6 +1923,53 @@ func testChangeMachines(c *gc.C, runChangeTests func(*gc.C, []changeTestFunc)) {
 						},
 					}}}
 		},
+		func(c *gc.C, st *State) changeTestCase {
+			return changeTestCase{
+				about: "no change if instance data is not in backing",
+				initialContents: []multiwatcher.EntityInfo{&multiwatcher.MachineInfo{
+					ModelUUID: st.ModelUUID(),
+					Id:        "0",
+				}},
+				change: watcher.Change{
+					C:  "instanceData",
+					Id: st.docID("0"),
+				},
+				expectContents: []multiwatcher.EntityInfo{
+					&multiwatcher.MachineInfo{
+						ModelUUID: st.ModelUUID(),
+						Id:        "0",
+					}}}
+		},
+		func(c *gc.C, st *State) changeTestCase {
+			m, err := st.AddMachine("quantal", JobHostUnits)
+			c.Assert(err, jc.ErrorIsNil)
+			mem := uint64(4096)
+			err = m.SetProvisioned("i-0", "fake_nonce", &instance.HardwareCharacteristics{Mem: &mem})
+			c.Assert(err, jc.ErrorIsNil)
+			newMem := uint64(8192)
+			err = m.SetHardwareCharacteristics(instance.HardwareCharacteristics{Mem: &newMem})
+			c.Assert(err, jc.ErrorIsNil)
+
+			return changeTestCase{
+				about: "hardware characteristics are changed if the machine exists in the store",
+				initialContents: []multiwatcher.EntityInfo{&multiwatcher.MachineInfo{
+					ModelUUID:               st.ModelUUID(),
+					Id:                      "0",
+					InstanceId:              "i-0",
+					HardwareCharacteristics: &instance.HardwareCharacteristics{Mem: &mem},
+				}},
+				change: watcher.Change{
+					C:  "instanceData",
+					Id: st.docID("0"),
+				},
+				expectContents: []multiwatcher.EntityInfo{
+					&multiwatcher.MachineInfo{
+						ModelUUID:               st.ModelUUID(),
+						Id:                      "0",
+						InstanceId:              "i-0",
+						HardwareCharacteristics: &instance.HardwareCharacteristics{Mem: &newMem},
+					}}}
+		},
 	}
 	runChangeTests(c, changeTestFuncs)
 }
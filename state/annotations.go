@@ -6,8 +6,11 @@ package state
 import (
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/juju/errors"
+	jujutxn "github.com/juju/txn"
+	"github.com/juju/utils/set"
 	"gopkg.in/juju/names.v2"
 	"gopkg.in/mgo.v2"
 	"gopkg.in/mgo.v2/bson"
@@ -24,28 +27,60 @@ type annotatorDoc struct {
 	GlobalKey   string            `bson:"globalkey"`
 	Tag         string            `bson:"tag"`
 	Annotations map[string]string `bson:"annotations"`
+
+	// History holds the previous values of changed or removed
+	// annotations, most recent first, keyed by annotation key.
+	History map[string][]annotationHistoryDoc `bson:"history,omitempty"`
+
+	// TxnRevno is used to assert that the annotations have not
+	// changed since the document was read.
+	TxnRevno int64 `bson:"txn-revno"`
+}
+
+// annotationHistoryDoc records a previous value of an annotation.
+type annotationHistoryDoc struct {
+	Value    string `bson:"value"`
+	Replaced int64  `bson:"replaced"`
+}
+
+// AnnotationChange records a previous value of an annotation, and
+// when it was replaced.
+type AnnotationChange struct {
+	Value    string
+	Replaced time.Time
+}
+
+// annotationLimits holds the limits on the annotations of an entity,
+// taken from the controller config.
+type annotationLimits struct {
+	maxValueSize int
+	maxSize      int
+	history      int
 }
 
 // SetAnnotations adds key/value pairs to annotations in MongoDB.
+// The annotations are subject to the controller's size limits; if
+// they would be exceeded, an error satisfying IsAnnotationLimitError
+// is returned. If the controller retains annotation history, the
+// previous values of changed and removed annotations are recorded.
 func (st *State) SetAnnotations(entity GlobalEntity, annotations map[string]string) (err error) {
 	defer errors.DeferredAnnotatef(&err, "cannot update annotations on %s", entity.Tag())
 	if len(annotations) == 0 {
 		return nil
 	}
-	// Collect in separate maps pairs to be inserted/updated or removed.
-	toRemove := make(bson.M)
-	toInsert := make(map[string]string)
-	toUpdate := make(bson.M)
-	for key, value := range annotations {
+	for key := range annotations {
 		if strings.Contains(key, ".") {
 			return fmt.Errorf("invalid key %q", key)
 		}
-		if value == "" {
-			toRemove[key] = true
-		} else {
-			toInsert[key] = value
-			toUpdate[key] = value
-		}
+	}
+	controllerConfig, err := st.ControllerConfig()
+	if err != nil {
+		return errors.Trace(err)
+	}
+	limits := annotationLimits{
+		maxValueSize: controllerConfig.MaxAnnotationValueSize(),
+		maxSize:      controllerConfig.MaxAnnotationsSize(),
+		history:      controllerConfig.AnnotationHistory(),
 	}
 	// Set up and call the necessary transactions - if the document does not
 	// already exist, one of the clients will create it and the others will
@@ -54,20 +89,161 @@ func (st *State) SetAnnotations(entity GlobalEntity, annotations map[string]stri
 	// annotations in the meantime, we consider that worthy of an error
 	// (will be fixed when new entities can never share names with old ones).
 	buildTxn := func(attempt int) ([]txn.Op, error) {
-		annotations, closer := st.getCollection(annotationsC)
-		defer closer()
-		if count, err := annotations.FindId(entity.globalKey()).Count(); err != nil {
-			return nil, err
-		} else if count == 0 {
-			// Check that the annotator entity was not previously destroyed.
-			if attempt != 0 {
-				return nil, fmt.Errorf("%s no longer exists", entity.Tag())
+		return annotationSetOps(st, entity, annotations, limits, attempt)
+	}
+	return st.run(buildTxn)
+}
+
+// annotationSetOps returns the operations required to set the given
+// annotations on the entity, checking that the entity's annotations
+// stay within the given limits.
+func annotationSetOps(
+	st *State,
+	entity GlobalEntity,
+	annotations map[string]string,
+	limits annotationLimits,
+	attempt int,
+) ([]txn.Op, error) {
+	coll, closer := st.getCollection(annotationsC)
+	defer closer()
+	var doc annotatorDoc
+	exists := true
+	if err := coll.FindId(entity.globalKey()).One(&doc); err == mgo.ErrNotFound {
+		// Check that the annotator entity was not previously destroyed.
+		if attempt != 0 {
+			return nil, fmt.Errorf("%s no longer exists", entity.Tag())
+		}
+		exists = false
+	} else if err != nil {
+		return nil, errors.Trace(err)
+	}
+
+	// Collect in separate maps pairs to be inserted/updated or removed,
+	// and compute the resulting annotations to check the limits.
+	toRemove := make(bson.M)
+	toInsert := make(map[string]string)
+	toUpdate := make(bson.M)
+	result := make(map[string]string)
+	for key, value := range doc.Annotations {
+		result[key] = value
+	}
+	for key, value := range annotations {
+		if value == "" {
+			if _, ok := result[key]; ok {
+				toRemove[key] = true
+				delete(result, key)
+			}
+			continue
+		}
+		if len(value) > limits.maxValueSize {
+			return nil, &ErrAnnotationLimit{
+				Key:   key,
+				Size:  len(value),
+				Limit: limits.maxValueSize,
 			}
-			return insertAnnotationsOps(st, entity, toInsert)
 		}
-		return updateAnnotations(st, entity, toUpdate, toRemove), nil
+		toInsert[key] = value
+		toUpdate[key] = value
+		result[key] = value
 	}
-	return st.run(buildTxn)
+	var size int
+	for key, value := range result {
+		size += len(key) + len(value)
+	}
+	if size > limits.maxSize {
+		return nil, &ErrAnnotationLimit{Size: size, Limit: limits.maxSize}
+	}
+
+	if !exists {
+		return insertAnnotationsOps(st, entity, toInsert)
+	}
+	if len(toUpdate) == 0 && len(toRemove) == 0 {
+		return nil, jujutxn.ErrNoOperations
+	}
+	history, changed := updatedAnnotationHistory(doc, annotations, limits, size, st.clock.Now())
+	historySet := make(bson.M)
+	historyUnset := make(bson.M)
+	for key := range changed {
+		if len(history[key]) == 0 {
+			historyUnset[key] = true
+		} else {
+			historySet[key] = history[key]
+		}
+	}
+	return updateAnnotations(st, entity, toUpdate, toRemove, historySet, historyUnset, doc.TxnRevno), nil
+}
+
+// updatedAnnotationHistory returns the history of the entity's annotations
+// once the given annotations are set, along with the keys whose history
+// changed. If history is retained, the replaced values of the annotations
+// are added, and at most limits.history previous values of each annotation
+// are kept. History counts toward the limit on the total size of the
+// entity's annotations, so the oldest previous values are dropped until
+// the annotations, whose size is given, and their history fit within it.
+func updatedAnnotationHistory(
+	doc annotatorDoc,
+	annotations map[string]string,
+	limits annotationLimits,
+	size int,
+	now time.Time,
+) (map[string][]annotationHistoryDoc, set.Strings) {
+	changed := set.NewStrings()
+	history := make(map[string][]annotationHistoryDoc)
+	for key, h := range doc.History {
+		history[key] = h
+	}
+	if limits.history > 0 {
+		replaced := now.UnixNano()
+		for key, value := range annotations {
+			old, ok := doc.Annotations[key]
+			if !ok || old == value {
+				continue
+			}
+			h := append([]annotationHistoryDoc{{
+				Value:    old,
+				Replaced: replaced,
+			}}, history[key]...)
+			if len(h) > limits.history {
+				h = h[:limits.history]
+			}
+			history[key] = h
+			changed.Add(key)
+		}
+	}
+
+	for key, h := range history {
+		if len(h) > 0 {
+			size += len(key)
+		}
+		for _, entry := range h {
+			size += len(entry.Value)
+		}
+	}
+	for size > limits.maxSize {
+		// Drop the oldest previous value of any annotation.
+		var oldestKey string
+		var oldest int64
+		for key, h := range history {
+			if len(h) == 0 {
+				continue
+			}
+			if replaced := h[len(h)-1].Replaced; oldestKey == "" || replaced < oldest {
+				oldestKey, oldest = key, replaced
+			}
+		}
+		if oldestKey == "" {
+			break
+		}
+		h := history[oldestKey]
+		size -= len(h[len(h)-1].Value)
+		h = h[:len(h)-1]
+		if len(h) == 0 {
+			size -= len(oldestKey)
+		}
+		history[oldestKey] = h
+		changed.Add(oldestKey)
+	}
+	return history, changed
 }
 
 // Annotations returns all the annotations corresponding to an entity.
@@ -96,6 +272,34 @@ func (st *State) Annotation(entity GlobalEntity, key string) (string, error) {
 	return ann[key], nil
 }
 
+// AnnotationHistory returns the previous values of the annotation with
+// the given key, most recent first. History is only retained if the
+// controller is configured to do so; see controller.AnnotationHistoryKey.
+func (st *State) AnnotationHistory(entity GlobalEntity, key string) ([]AnnotationChange, error) {
+	doc := new(annotatorDoc)
+	annotations, closer := st.getCollection(annotationsC)
+	defer closer()
+	err := annotations.FindId(entity.globalKey()).One(doc)
+	if err == mgo.ErrNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	history := doc.History[key]
+	if len(history) == 0 {
+		return nil, nil
+	}
+	changes := make([]AnnotationChange, len(history))
+	for i, h := range history {
+		changes[i] = AnnotationChange{
+			Value:    h.Value,
+			Replaced: time.Unix(0, h.Replaced).UTC(),
+		}
+	}
+	return changes, nil
+}
+
 // insertAnnotationsOps returns the operations required to insert annotations in MongoDB.
 func insertAnnotationsOps(st *State, entity GlobalEntity, toInsert map[string]string) ([]txn.Op, error) {
 	tag := entity.Tag()
@@ -136,13 +340,16 @@ func insertAnnotationsOps(st *State, entity GlobalEntity, toInsert map[string]st
 	}), nil
 }
 
-// updateAnnotations returns the operations required to update or remove annotations in MongoDB.
-func updateAnnotations(st *State, entity GlobalEntity, toUpdate, toRemove bson.M) []txn.Op {
+// updateAnnotations returns the operations required to update or remove
+// annotations in MongoDB, and to record or remove the given annotation
+// history, asserting that the annotations have not changed since they
+// were read.
+func updateAnnotations(st *State, entity GlobalEntity, toUpdate, toRemove, history, historyRemove bson.M, txnRevno int64) []txn.Op {
 	return []txn.Op{{
 		C:      annotationsC,
 		Id:     st.docID(entity.globalKey()),
-		Assert: txn.DocExists,
-		Update: setUnsetUpdateAnnotations(toUpdate, toRemove),
+		Assert: bson.D{{"txn-revno", txnRevno}},
+		Update: setUnsetUpdateAnnotations(toUpdate, toRemove, history, historyRemove),
 	}}
 }
 
@@ -159,16 +366,24 @@ func annotationRemoveOp(st *State, id string) txn.Op {
 // setUnsetUpdateAnnotations returns a bson.D for use
 // in an annotationsC txn.Op's Update field, containing $set and
 // $unset operators if the corresponding operands
-// are non-empty.
-func setUnsetUpdateAnnotations(set, unset bson.M) bson.D {
+// are non-empty. The history and historyUnset operands hold
+// annotation history to $set and $unset, keyed by annotation key.
+func setUnsetUpdateAnnotations(set, unset, history, historyUnset bson.M) bson.D {
 	var update bson.D
 	replace := inSubdocReplacer("annotations")
-	if len(set) > 0 {
+	replaceHistory := inSubdocReplacer("history")
+	if len(set) > 0 || len(history) > 0 {
 		set = bson.M(copyMap(map[string]interface{}(set), replace))
+		for key, value := range history {
+			set[replaceHistory(key)] = value
+		}
 		update = append(update, bson.DocElem{"$set", set})
 	}
-	if len(unset) > 0 {
+	if len(unset) > 0 || len(historyUnset) > 0 {
 		unset = bson.M(copyMap(map[string]interface{}(unset), replace))
+		for key, value := range historyUnset {
+			unset[replaceHistory(key)] = value
+		}
 		update = append(update, bson.DocElem{"$unset", unset})
 	}
 	return update
@@ -10,6 +10,7 @@ import (
 	gc "gopkg.in/check.v1"
 	"gopkg.in/juju/names.v2"
 
+	"github.com/juju/juju/controller"
 	"github.com/juju/juju/state"
 	"github.com/juju/juju/storage"
 	"github.com/juju/juju/testing"
@@ -134,6 +135,108 @@ func (s *AnnotationsSuite) TestSetAnnotationsConcurrently(c *gc.C) {
 	assertAnnotation(c, s.State, s.testEntity, key, last)
 }
 
+func (s *AnnotationsSuite) setControllerConfig(c *gc.C, attrs map[string]interface{}) {
+	settings, err := s.State.ReadSettings(state.ControllersC, "controllerSettings")
+	c.Assert(err, jc.ErrorIsNil)
+	for key, value := range attrs {
+		settings.Set(key, value)
+	}
+	_, err = settings.Write()
+	c.Assert(err, jc.ErrorIsNil)
+}
+
+func (s *AnnotationsSuite) TestSetAnnotationsValueSizeLimit(c *gc.C) {
+	s.setControllerConfig(c, map[string]interface{}{
+		controller.MaxAnnotationValueSizeKey: 4,
+	})
+	s.assertSetAnnotation(c, "key", "1234")
+	err := s.setAnnotationResult(c, "key", "12345")
+	c.Assert(err, gc.ErrorMatches, `cannot update annotations on machine-0: value of annotation "key" is 5 bytes, exceeding the limit of 4 bytes`)
+	c.Assert(err, jc.Satisfies, state.IsAnnotationLimitError)
+	c.Assert(errors.Cause(err), jc.DeepEquals, &state.ErrAnnotationLimit{
+		Key:   "key",
+		Size:  5,
+		Limit: 4,
+	})
+	assertAnnotation(c, s.State, s.testEntity, "key", "1234")
+}
+
+func (s *AnnotationsSuite) TestSetAnnotationsSizeLimit(c *gc.C) {
+	s.setControllerConfig(c, map[string]interface{}{
+		controller.MaxAnnotationsSizeKey: 10,
+	})
+	s.assertSetAnnotation(c, "a", "1234")
+	s.assertSetAnnotation(c, "b", "1234")
+	err := s.setAnnotationResult(c, "c", "1")
+	c.Assert(err, gc.ErrorMatches, `cannot update annotations on machine-0: annotations total 12 bytes, exceeding the limit of 10 bytes`)
+	c.Assert(err, jc.Satisfies, state.IsAnnotationLimitError)
+
+	// Removing an annotation makes room for another.
+	err = s.State.SetAnnotations(s.testEntity, map[string]string{"a": "", "c": "1"})
+	c.Assert(err, jc.ErrorIsNil)
+	annotations, err := s.State.Annotations(s.testEntity)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(annotations, jc.DeepEquals, map[string]string{"b": "1234", "c": "1"})
+}
+
+func (s *AnnotationsSuite) TestAnnotationHistoryDisabled(c *gc.C) {
+	key := s.createTestAnnotation(c)
+	s.assertSetAnnotation(c, key, "fixed")
+
+	history, err := s.State.AnnotationHistory(s.testEntity, key)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(history, gc.HasLen, 0)
+}
+
+func (s *AnnotationsSuite) TestAnnotationHistory(c *gc.C) {
+	s.setControllerConfig(c, map[string]interface{}{
+		controller.AnnotationHistoryKey: 2,
+	})
+	s.assertSetAnnotation(c, "key", "one")
+	s.assertSetAnnotation(c, "key", "two")
+	s.assertSetAnnotation(c, "key", "two")
+	s.assertSetAnnotation(c, "key", "three")
+	s.assertSetAnnotation(c, "key", "")
+
+	history, err := s.State.AnnotationHistory(s.testEntity, "key")
+	c.Assert(err, jc.ErrorIsNil)
+	values := make([]string, len(history))
+	for i, change := range history {
+		values[i] = change.Value
+		c.Assert(change.Replaced.IsZero(), jc.IsFalse)
+	}
+	c.Assert(values, jc.DeepEquals, []string{"three", "two"})
+	assertAnnotation(c, s.State, s.testEntity, "key", "")
+}
+
+func (s *AnnotationsSuite) TestAnnotationHistoryCountsTowardSizeLimit(c *gc.C) {
+	s.setControllerConfig(c, map[string]interface{}{
+		controller.AnnotationHistoryKey:  5,
+		controller.MaxAnnotationsSizeKey: 20,
+	})
+	// Each change adds 4 bytes of history. Once the annotation
+	// (5 bytes) and its history (1 byte for the key, plus the
+	// values) would exceed 20 bytes, the oldest values are
+	// dropped rather than the change being refused.
+	for _, value := range []string{"aaaa", "bbbb", "cccc", "dddd", "eeee"} {
+		s.assertSetAnnotation(c, "k", value)
+	}
+	history, err := s.State.AnnotationHistory(s.testEntity, "k")
+	c.Assert(err, jc.ErrorIsNil)
+	values := make([]string, len(history))
+	for i, change := range history {
+		values[i] = change.Value
+	}
+	c.Assert(values, jc.DeepEquals, []string{"dddd", "cccc", "bbbb"})
+
+	// Annotations that fill the limit leave no room for history.
+	s.assertSetAnnotation(c, "k", "0123456789abcdefghi")
+	history, err = s.State.AnnotationHistory(s.testEntity, "k")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(history, gc.HasLen, 0)
+	assertAnnotation(c, s.State, s.testEntity, "k", "0123456789abcdefghi")
+}
+
 type AnnotationsEnvSuite struct {
 	ConnSuite
 }
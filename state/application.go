@@ -47,7 +47,12 @@ type applicationDoc struct {
 	UnitCount            int        `bson:"unitcount"`
 	RelationCount        int        `bson:"relationcount"`
 	Exposed              bool       `bson:"exposed"`
+	Paused               bool       `bson:"paused,omitempty"`
+	CharmPinned          bool       `bson:"charm-pinned,omitempty"`
+	CharmPinMessage      string     `bson:"charm-pin-message,omitempty"`
 	MinUnits             int        `bson:"minunits"`
+	DesiredScale         *int       `bson:"desiredscale,omitempty"`
+	WorkloadVersion      string     `bson:"workload-version,omitempty"`
 	TxnRevno             int64      `bson:"txn-revno"`
 	MetricCredentials    []byte     `bson:"metric-credentials"`
 }
@@ -126,33 +131,58 @@ var errRefresh = stderrors.New("state seems inconsistent, refresh and try again"
 // application has any units in scope, they are all removed immediately.
 func (a *Application) Destroy() (err error) {
 	defer errors.DeferredAnnotatef(&err, "cannot destroy application %q", a)
-	defer func() {
-		if err == nil {
-			// This is a white lie; the document might actually be removed.
-			a.doc.Life = Dying
-		}
-	}()
-	svc := &Application{st: a.st, doc: a.doc}
-	buildTxn := func(attempt int) ([]txn.Op, error) {
-		if attempt > 0 {
-			if err := svc.Refresh(); errors.IsNotFound(err) {
-				return nil, jujutxn.ErrNoOperations
-			} else if err != nil {
-				return nil, err
-			}
-		}
-		switch ops, err := svc.destroyOps(); err {
-		case errRefresh:
-		case errAlreadyDying:
+	op := &destroyApplicationOperation{
+		app:    &Application{st: a.st, doc: a.doc},
+		target: a,
+	}
+	return a.st.applyEntityOperation(op)
+}
+
+// destroyApplicationOperation is an entityOperation that
+// destroys an application.
+type destroyApplicationOperation struct {
+	// app is the copy of the application that is
+	// refreshed while building the operation.
+	app *Application
+
+	// target is the application that Destroy was called on,
+	// which is updated when the operation is applied.
+	target *Application
+}
+
+// Entities is part of the entityOperation interface.
+func (op *destroyApplicationOperation) Entities() []txnDoc {
+	return []txnDoc{{applicationsC, op.app.doc.DocID}}
+}
+
+// Build is part of the entityOperation interface.
+func (op *destroyApplicationOperation) Build(attempt int) ([]txn.Op, error) {
+	if attempt > 0 {
+		if err := op.app.Refresh(); errors.IsNotFound(err) {
 			return nil, jujutxn.ErrNoOperations
-		case nil:
-			return ops, nil
-		default:
+		} else if err != nil {
 			return nil, err
 		}
-		return nil, jujutxn.ErrTransientFailure
 	}
-	return a.st.run(buildTxn)
+	switch ops, err := op.app.destroyOps(); err {
+	case errRefresh:
+	case errAlreadyDying:
+		return nil, jujutxn.ErrNoOperations
+	case nil:
+		return ops, nil
+	default:
+		return nil, err
+	}
+	return nil, jujutxn.ErrTransientFailure
+}
+
+// Done is part of the entityOperation interface.
+func (op *destroyApplicationOperation) Done(err error) error {
+	if err == nil {
+		// This is a white lie; the document might actually be removed.
+		op.target.doc.Life = Dying
+	}
+	return err
 }
 
 // destroyOps returns the operations required to destroy the service. If it
@@ -172,7 +202,22 @@ func (a *Application) destroyOps() ([]txn.Op, error) {
 		// asserts on relationcount and on each known relation, below.
 		return nil, errRefresh
 	}
-	ops := []txn.Op{minUnitsRemoveOp(a.st, a.doc.Name)}
+	// The application may not be destroyed while it is being renamed,
+	// as its units and relations may be found under either name.
+	if _, err := a.st.applicationRename(a.doc.Name); err == nil {
+		return nil, errors.New("application is being renamed")
+	} else if !errors.IsNotFound(err) {
+		return nil, errors.Trace(err)
+	}
+	ops := []txn.Op{
+		minUnitsRemoveOp(a.st, a.doc.Name),
+		scaleRemoveOp(a.st, a.doc.Name),
+		{
+			C:      applicationRenamesC,
+			Id:     a.st.docID(a.doc.Name),
+			Assert: txn.DocMissing,
+		},
+	}
 	removeCount := 0
 	for _, rel := range rels {
 		relOps, isRemove, err := rel.destroyOps(a.doc.Name)
@@ -293,10 +338,24 @@ func (a *Application) removeOps(asserts bson.D) ([]txn.Op, error) {
 		removeLeadershipSettingsOp(name),
 		removeStatusOp(a.st, globalKey),
 		removeModelServiceRefOp(a.st, name),
+		removeExposureIntentOp(a.st, name),
 	)
+	offerOps, err := removeApplicationOffersOps(a.st, name)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	ops = append(ops, offerOps...)
 	return ops, nil
 }
 
+// WorkloadVersion returns the version of the workload most recently
+// reported by any of the application's units, or the empty string if
+// none has reported one. The versions run by the individual units are
+// returned by Unit.WorkloadVersion.
+func (a *Application) WorkloadVersion() string {
+	return a.doc.WorkloadVersion
+}
+
 // IsExposed returns whether this application is exposed. The explicitly open
 // ports (with open-port) for exposed services may be accessed from machines
 // outside of the local deployment network. See SetExposed and ClearExposed.
@@ -316,20 +375,192 @@ func (a *Application) ClearExposed() error {
 	return a.setExposed(false)
 }
 
+// setExposed sets the application's exposed flag, and records the intent
+// to expose or unexpose the application along with the port ranges opened
+// by its units. Setting the flag to its current value is a no-op, unless
+// no intent has yet been recorded.
 func (a *Application) setExposed(exposed bool) (err error) {
-	ops := []txn.Op{{
-		C:      applicationsC,
-		Id:     a.doc.DocID,
-		Assert: isAliveDoc,
-		Update: bson.D{{"$set", bson.D{{"exposed", exposed}}}},
-	}}
-	if err := a.st.runTransaction(ops); err != nil {
+	app := &Application{st: a.st, doc: a.doc}
+	buildTxn := func(attempt int) ([]txn.Op, error) {
+		if attempt > 0 {
+			if err := app.Refresh(); errors.IsNotFound(err) {
+				return nil, errNotAlive
+			} else if err != nil {
+				return nil, errors.Trace(err)
+			}
+		}
+		if app.doc.Life != Alive {
+			return nil, errNotAlive
+		}
+		if app.doc.Exposed == exposed {
+			intent, err := app.ExposureIntent()
+			if err != nil && !errors.IsNotFound(err) {
+				return nil, errors.Trace(err)
+			} else if err == nil && intent.Exposed == exposed {
+				return nil, jujutxn.ErrNoOperations
+			}
+		}
+		intentOps, err := app.exposureIntentOps(exposed)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		ops := []txn.Op{{
+			C:      applicationsC,
+			Id:     app.doc.DocID,
+			Assert: bson.D{{"life", Alive}, {"exposed", app.doc.Exposed}},
+			Update: bson.D{{"$set", bson.D{{"exposed", exposed}}}},
+		}}
+		return append(ops, intentOps...), nil
+	}
+	if err := a.st.run(buildTxn); err != nil {
 		return errors.Errorf("cannot set exposed flag for application %q to %v: %v", a, exposed, onAbort(err, errNotAlive))
 	}
 	a.doc.Exposed = exposed
 	return nil
 }
 
+// IsPaused returns whether this application has been administratively
+// paused. While an application is paused its units defer hook execution,
+// and no new units may be added to it. See Pause and Resume.
+func (a *Application) IsPaused() bool {
+	return a.doc.Paused
+}
+
+// Pause marks the application as paused.
+// See Resume and IsPaused.
+func (a *Application) Pause() error {
+	return a.setPaused(true)
+}
+
+// Resume removes the paused flag from the application.
+// See Pause and IsPaused.
+func (a *Application) Resume() error {
+	return a.setPaused(false)
+}
+
+// setPaused sets the application's paused flag. Setting the flag to
+// its current value is a no-op.
+func (a *Application) setPaused(paused bool) (err error) {
+	app := &Application{st: a.st, doc: a.doc}
+	buildTxn := func(attempt int) ([]txn.Op, error) {
+		if attempt > 0 {
+			if err := app.Refresh(); errors.IsNotFound(err) {
+				return nil, errNotAlive
+			} else if err != nil {
+				return nil, errors.Trace(err)
+			}
+		}
+		if app.doc.Life != Alive {
+			return nil, errNotAlive
+		}
+		if app.doc.Paused == paused {
+			return nil, jujutxn.ErrNoOperations
+		}
+		ops := []txn.Op{{
+			C:      applicationsC,
+			Id:     app.doc.DocID,
+			Assert: bson.D{{"life", Alive}, {"paused", pausedAssert(app.doc.Paused)}},
+			Update: bson.D{{"$set", bson.D{{"paused", paused}}}},
+		}}
+		if !paused {
+			// Units are not added to maintain the minimum
+			// units or desired scale while the application
+			// is paused; trigger the scaler to add them now.
+			ops = append(ops,
+				minUnitsTriggerOp(app.st, app.Name()),
+				scaleTriggerOp(app.st, app.Name()),
+			)
+		}
+		return ops, nil
+	}
+	if err := a.st.run(buildTxn); err != nil {
+		return errors.Errorf("cannot set paused flag for application %q to %v: %v", a, paused, onAbort(err, errNotAlive))
+	}
+	a.doc.Paused = paused
+	return nil
+}
+
+// pausedAssert returns the value to assert for the paused, or
+// any other omitempty boolean, field of an application document.
+// The field is omitted when false, so asserting on an explicit
+// false would not match.
+func pausedAssert(paused bool) interface{} {
+	if paused {
+		return true
+	}
+	return bson.D{{"$ne", true}}
+}
+
+// CharmPin returns whether the application's charm is pinned, and
+// the message recorded when it was pinned. While the charm is pinned,
+// SetCharm may not change the application's charm URL.
+// See SetCharmPin and RemoveCharmPin.
+func (a *Application) CharmPin() (message string, pinned bool) {
+	return a.doc.CharmPinMessage, a.doc.CharmPinned
+}
+
+// SetCharmPin pins the application's charm, recording the given
+// message to explain why. Pinning an already pinned charm replaces
+// the message. Unlike model-wide blocks, the pin applies only to this
+// application.
+func (a *Application) SetCharmPin(message string) error {
+	return a.setCharmPin(true, message)
+}
+
+// RemoveCharmPin unpins the application's charm.
+// See SetCharmPin.
+func (a *Application) RemoveCharmPin() error {
+	return a.setCharmPin(false, "")
+}
+
+// setCharmPin sets or clears the application's charm pin. Setting the
+// pin to its current state is a no-op.
+func (a *Application) setCharmPin(pinned bool, message string) (err error) {
+	app := &Application{st: a.st, doc: a.doc}
+	buildTxn := func(attempt int) ([]txn.Op, error) {
+		if attempt > 0 {
+			if err := app.Refresh(); errors.IsNotFound(err) {
+				return nil, errNotAlive
+			} else if err != nil {
+				return nil, errors.Trace(err)
+			}
+		}
+		if app.doc.Life != Alive {
+			return nil, errNotAlive
+		}
+		if app.doc.CharmPinned == pinned && app.doc.CharmPinMessage == message {
+			return nil, jujutxn.ErrNoOperations
+		}
+		var update bson.D
+		if pinned {
+			update = bson.D{{"$set", bson.D{
+				{"charm-pinned", true},
+				{"charm-pin-message", message},
+			}}}
+		} else {
+			update = bson.D{{"$unset", bson.D{
+				{"charm-pinned", nil},
+				{"charm-pin-message", nil},
+			}}}
+		}
+		return []txn.Op{{
+			C:  applicationsC,
+			Id: app.doc.DocID,
+			Assert: bson.D{
+				{"life", Alive},
+				{"charm-pinned", pausedAssert(app.doc.CharmPinned)},
+			},
+			Update: update,
+		}}, nil
+	}
+	if err := a.st.run(buildTxn); err != nil {
+		return errors.Errorf("cannot set charm pin for application %q to %v: %v", a, pinned, onAbort(err, errNotAlive))
+	}
+	a.doc.CharmPinned = pinned
+	a.doc.CharmPinMessage = message
+	return nil
+}
+
 // Charm returns the service's charm and whether units should upgrade to that
 // charm even if they are in an error state.
 func (a *Application) Charm() (ch *Charm, force bool, err error) {
@@ -850,7 +1081,9 @@ type SetCharmConfig struct {
 	StorageConstraints map[string]StorageConstraints
 }
 
-// SetCharm changes the charm for the application.
+// SetCharm changes the charm for the application. If the application's
+// charm is pinned, the charm URL may not be changed, and an error
+// satisfying IsCharmPinnedError is returned.
 func (a *Application) SetCharm(cfg SetCharmConfig) (err error) {
 	defer errors.DeferredAnnotatef(
 		&err, "cannot upgrade application %q to charm %q", a, cfg.Charm,
@@ -912,75 +1145,118 @@ func (a *Application) SetCharm(cfg SetCharmConfig) (err error) {
 		return errors.Annotate(err, "validating config settings")
 	}
 
-	var newCharmModifiedVersion int
-	channel := string(cfg.Channel)
-	acopy := &Application{a.st, a.doc}
-	buildTxn := func(attempt int) ([]txn.Op, error) {
-		a := acopy
-		if attempt > 0 {
-			if err := a.Refresh(); err != nil {
-				return nil, errors.Trace(err)
-			}
-		}
+	op := &setCharmOperation{
+		app:             &Application{st: a.st, doc: a.doc},
+		target:          a,
+		cfg:             cfg,
+		updatedSettings: updatedSettings,
+	}
+	return a.st.applyEntityOperation(op)
+}
+
+// setCharmOperation is an entityOperation that changes
+// an application's charm.
+type setCharmOperation struct {
+	// app is the copy of the application that is
+	// refreshed while building the operation.
+	app *Application
 
-		// NOTE: We're explicitly allowing SetCharm to succeed
-		// when the application is Dying, because service/charm
-		// upgrades should still be allowed to apply to dying
-		// services and units, so that bugs in departed/broken
-		// hooks can be addressed at runtime.
-		if a.Life() == Dead {
-			return nil, ErrDead
+	// target is the application that SetCharm was called on,
+	// which is updated when the operation is applied.
+	target *Application
+
+	cfg             SetCharmConfig
+	updatedSettings charm.Settings
+
+	// newCharmModifiedVersion records the application's
+	// charm modified version, as of the last build.
+	newCharmModifiedVersion int
+}
+
+// Entities is part of the entityOperation interface.
+func (op *setCharmOperation) Entities() []txnDoc {
+	return []txnDoc{{applicationsC, op.app.doc.DocID}}
+}
+
+// Build is part of the entityOperation interface.
+func (op *setCharmOperation) Build(attempt int) ([]txn.Op, error) {
+	a := op.app
+	if attempt > 0 {
+		if err := a.Refresh(); err != nil {
+			return nil, errors.Trace(err)
 		}
+	}
 
-		// Record the current value of charmModifiedVersion, so we can
-		// set the value on the method receiver's in-memory document
-		// structure. We increment the version only when we change the
-		// charm URL.
-		newCharmModifiedVersion = a.doc.CharmModifiedVersion
+	// NOTE: We're explicitly allowing SetCharm to succeed
+	// when the application is Dying, because service/charm
+	// upgrades should still be allowed to apply to dying
+	// services and units, so that bugs in departed/broken
+	// hooks can be addressed at runtime.
+	if a.Life() == Dead {
+		return nil, ErrDead
+	}
 
-		ops := []txn.Op{{
+	// Record the current value of charmModifiedVersion, so we can
+	// set the value on the method receiver's in-memory document
+	// structure. We increment the version only when we change the
+	// charm URL.
+	op.newCharmModifiedVersion = a.doc.CharmModifiedVersion
+
+	ops := []txn.Op{{
+		C:  applicationsC,
+		Id: a.doc.DocID,
+		Assert: append(notDeadDoc, bson.DocElem{
+			"charmmodifiedversion", a.doc.CharmModifiedVersion,
+		}),
+	}}
+
+	channel := string(op.cfg.Channel)
+	if a.doc.CharmURL.String() == op.cfg.Charm.URL().String() {
+		// Charm URL already set; just update the force flag and channel.
+		ops = append(ops, txn.Op{
 			C:  applicationsC,
 			Id: a.doc.DocID,
-			Assert: append(notDeadDoc, bson.DocElem{
-				"charmmodifiedversion", a.doc.CharmModifiedVersion,
-			}),
-		}}
-
-		if a.doc.CharmURL.String() == cfg.Charm.URL().String() {
-			// Charm URL already set; just update the force flag and channel.
-			ops = append(ops, txn.Op{
-				C:  applicationsC,
-				Id: a.doc.DocID,
-				Update: bson.D{{"$set", bson.D{
-					{"cs-channel", channel},
-					{"forcecharm", cfg.ForceUnits},
-				}}},
-			})
-		} else {
-			chng, err := a.changeCharmOps(
-				cfg.Charm,
-				channel,
-				updatedSettings,
-				cfg.ForceUnits,
-				cfg.ResourceIDs,
-				cfg.StorageConstraints,
-			)
-			if err != nil {
-				return nil, errors.Trace(err)
-			}
-			ops = append(ops, chng...)
-			newCharmModifiedVersion++
+			Update: bson.D{{"$set", bson.D{
+				{"cs-channel", channel},
+				{"forcecharm", op.cfg.ForceUnits},
+			}}},
+		})
+	} else {
+		if a.doc.CharmPinned {
+			return nil, &ErrCharmPinned{a.doc.CharmPinMessage}
 		}
-
-		return ops, nil
+		ops = append(ops, txn.Op{
+			C:      applicationsC,
+			Id:     a.doc.DocID,
+			Assert: bson.D{{"charm-pinned", pausedAssert(false)}},
+		})
+		chng, err := a.changeCharmOps(
+			op.cfg.Charm,
+			channel,
+			op.updatedSettings,
+			op.cfg.ForceUnits,
+			op.cfg.ResourceIDs,
+			op.cfg.StorageConstraints,
+		)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		ops = append(ops, chng...)
+		op.newCharmModifiedVersion++
 	}
-	if err := a.st.run(buildTxn); err != nil {
+
+	return ops, nil
+}
+
+// Done is part of the entityOperation interface.
+func (op *setCharmOperation) Done(err error) error {
+	if err != nil {
 		return err
 	}
-	a.doc.CharmURL = cfg.Charm.URL()
-	a.doc.Channel = channel
-	a.doc.ForceCharm = cfg.ForceUnits
-	a.doc.CharmModifiedVersion = newCharmModifiedVersion
+	op.target.doc.CharmURL = op.cfg.Charm.URL()
+	op.target.doc.Channel = string(op.cfg.Channel)
+	op.target.doc.ForceCharm = op.cfg.ForceUnits
+	op.target.doc.CharmModifiedVersion = op.newCharmModifiedVersion
 	return nil
 }
 
@@ -1052,7 +1328,7 @@ func (a *Application) addUnitOps(principalName string, asserts bson.D) (string,
 	}
 	// we verify the application is alive
 	asserts = append(isAliveDoc, asserts...)
-	ops = append(ops, a.incUnitCountOp(asserts))
+	ops = append(ops, a.incUnitCountOp(asserts), scaleTriggerOp(a.st, a.doc.Name))
 	return names, ops, err
 }
 
@@ -1191,10 +1467,14 @@ func (a *Application) incUnitCountOp(asserts bson.D) txn.Op {
 	return op
 }
 
-// AddUnit adds a new principal unit to the service.
+// AddUnit adds a new principal unit to the service. Units may not be
+// added to a paused application.
 func (a *Application) AddUnit() (unit *Unit, err error) {
 	defer errors.DeferredAnnotatef(&err, "cannot add unit to application %q", a)
-	name, ops, err := a.addUnitOps("", nil)
+	if a.doc.Paused {
+		return nil, errApplicationPaused
+	}
+	name, ops, err := a.addUnitOps("", bson.D{{"paused", pausedAssert(false)}})
 	if err != nil {
 		return nil, err
 	}
@@ -1205,6 +1485,11 @@ func (a *Application) AddUnit() (unit *Unit, err error) {
 		} else if !alive {
 			return nil, errors.New("application is not alive")
 		}
+		if err := a.Refresh(); err != nil {
+			return nil, errors.Trace(err)
+		} else if a.doc.Paused {
+			return nil, errApplicationPaused
+		}
 		return nil, errors.New("inconsistent state")
 	} else if err != nil {
 		return nil, err
@@ -1358,6 +1643,69 @@ func (a *Application) ConfigSettings() (charm.Settings, error) {
 	return settings.Map(), nil
 }
 
+// ConfigSource identifies the layer of an application's charm config
+// from which an option's effective value is taken.
+type ConfigSource string
+
+const (
+	// ConfigSourceUser identifies a value set by the user, whether
+	// at deploy time or since.
+	ConfigSourceUser ConfigSource = "user"
+
+	// ConfigSourceDefault identifies the charm's default value for
+	// an option that the user has not set.
+	ConfigSourceDefault ConfigSource = "default"
+
+	// ConfigSourceUnset identifies an option that the user has not
+	// set, and for which the charm has no default.
+	ConfigSourceUnset ConfigSource = "unset"
+)
+
+// ConfigValue holds the effective value of a charm config option,
+// and the layer from which the value is taken.
+type ConfigValue struct {
+	Value  interface{}
+	Source ConfigSource
+}
+
+// ConfigValues returns the effective values of all of the options in
+// the application's charm config. The values set by the user are stored
+// separately from, and layered over, the charm's defaults; the source
+// of each value records which layer it was taken from. The Value of an
+// option whose Source is ConfigSourceUnset is nil.
+func (a *Application) ConfigValues() (map[string]ConfigValue, error) {
+	ch, _, err := a.Charm()
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	settings, err := a.ConfigSettings()
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	values := make(map[string]ConfigValue)
+	for name, option := range ch.Config().Options {
+		if value, ok := settings[name]; ok {
+			values[name] = ConfigValue{value, ConfigSourceUser}
+		} else if option.Default != nil {
+			values[name] = ConfigValue{option.Default, ConfigSourceDefault}
+		} else {
+			values[name] = ConfigValue{Source: ConfigSourceUnset}
+		}
+	}
+	return values, nil
+}
+
+// ResetConfigSettings removes the user-set values of the named charm
+// config options, so that they revert to the charm's defaults. It is
+// an error to name an option that is not in the charm's config.
+func (a *Application) ResetConfigSettings(keys []string) error {
+	changes := make(charm.Settings)
+	for _, key := range keys {
+		changes[key] = nil
+	}
+	return a.UpdateConfigSettings(changes)
+}
+
 // UpdateConfigSettings changes a service's charm config settings. Values set
 // to nil will be deleted; unknown and invalid values will return an error.
 func (a *Application) UpdateConfigSettings(changes charm.Settings) error {
@@ -646,6 +646,55 @@ func (s *ApplicationSuite) TestSetCharmWhenDyingIsOK(c *gc.C) {
 	assertLife(c, s.mysql, state.Dying)
 }
 
+func (s *ApplicationSuite) TestSetCharmRetriesUnderContention(c *gc.C) {
+	sch := s.AddMetaCharm(c, "mysql", metaBase, 2)
+
+	// Change the application before each of more attempts
+	// than the transaction runner would make by itself.
+	bumpVersion := func() {
+		err := state.RunTransaction(s.State, []txn.Op{{
+			C:      state.ApplicationsC,
+			Id:     state.DocID(s.State, s.mysql.Name()),
+			Update: bson.D{{"$inc", bson.D{{"charmmodifiedversion", 1}}}},
+		}})
+		c.Assert(err, jc.ErrorIsNil)
+	}
+	hooks := make([]func(), 25)
+	for i := range hooks {
+		hooks[i] = bumpVersion
+	}
+	defer state.SetBeforeHooks(c, s.State, hooks...).Check()
+
+	err := s.mysql.SetCharm(state.SetCharmConfig{Charm: sch})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(s.mysql.CharmModifiedVersion(), gc.Equals, 26)
+	err = s.mysql.Refresh()
+	c.Assert(err, jc.ErrorIsNil)
+	curl, _ := s.mysql.CharmURL()
+	c.Assert(curl, jc.DeepEquals, sch.URL())
+	c.Assert(s.mysql.CharmModifiedVersion(), gc.Equals, 26)
+}
+
+func (s *ApplicationSuite) TestSetCharmInconsistentAssertions(c *gc.C) {
+	sch := s.AddMetaCharm(c, "mysql", metaBase, 2)
+
+	// Change the application outside of a transaction, so
+	// that the rebuilt transaction is aborted although the
+	// application's revision has not changed.
+	bumpVersion := func() {
+		applications := s.State.MongoSession().DB("juju").C(state.ApplicationsC)
+		err := applications.UpdateId(
+			state.DocID(s.State, s.mysql.Name()),
+			bson.D{{"$inc", bson.D{{"charmmodifiedversion", 1}}}},
+		)
+		c.Assert(err, jc.ErrorIsNil)
+	}
+	defer state.SetBeforeHooks(c, s.State, bumpVersion, bumpVersion).Check()
+
+	err := s.mysql.SetCharm(state.SetCharmConfig{Charm: sch})
+	c.Assert(err, gc.ErrorMatches, "state seems inconsistent, refresh and try again")
+}
+
 func (s *ApplicationSuite) TestSetCharmRetriesWithSameCharmURL(c *gc.C) {
 	sch := s.AddMetaCharm(c, "mysql", metaBase, 2)
 
@@ -979,6 +1028,51 @@ func (s *ApplicationSuite) TestUpdateConfigSettings(c *gc.C) {
 	}
 }
 
+func (s *ApplicationSuite) TestConfigValues(c *gc.C) {
+	svc := s.AddTestingService(c, "dummy-application", s.AddTestingCharm(c, "dummy"))
+	err := svc.UpdateConfigSettings(charm.Settings{
+		"outlook":  "positive",
+		"username": "admin001",
+	})
+	c.Assert(err, jc.ErrorIsNil)
+
+	values, err := svc.ConfigValues()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(values, jc.DeepEquals, map[string]state.ConfigValue{
+		"title":       {"My Title", state.ConfigSourceDefault},
+		"outlook":     {"positive", state.ConfigSourceUser},
+		"username":    {"admin001", state.ConfigSourceUser},
+		"skill-level": {nil, state.ConfigSourceUnset},
+	})
+}
+
+func (s *ApplicationSuite) TestResetConfigSettings(c *gc.C) {
+	svc := s.AddTestingService(c, "dummy-application", s.AddTestingCharm(c, "dummy"))
+	err := svc.UpdateConfigSettings(charm.Settings{
+		"title":       "sir",
+		"outlook":     "positive",
+		"skill-level": 303,
+	})
+	c.Assert(err, jc.ErrorIsNil)
+
+	err = svc.ResetConfigSettings([]string{"title", "outlook", "username"})
+	c.Assert(err, jc.ErrorIsNil)
+	settings, err := svc.ConfigSettings()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(settings, gc.DeepEquals, charm.Settings{"skill-level": int64(303)})
+
+	values, err := svc.ConfigValues()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(values["title"], jc.DeepEquals, state.ConfigValue{"My Title", state.ConfigSourceDefault})
+	c.Assert(values["outlook"], jc.DeepEquals, state.ConfigValue{nil, state.ConfigSourceUnset})
+}
+
+func (s *ApplicationSuite) TestResetConfigSettingsUnknownOption(c *gc.C) {
+	svc := s.AddTestingService(c, "dummy-application", s.AddTestingCharm(c, "dummy"))
+	err := svc.ResetConfigSettings([]string{"foo"})
+	c.Assert(err, gc.ErrorMatches, `unknown option "foo"`)
+}
+
 func assertNoSettingsRef(c *gc.C, st *state.State, svcName string, sch *state.Charm) {
 	_, err := state.ServiceSettingsRefCount(st, svcName, sch.URL())
 	c.Assert(errors.Cause(err), jc.Satisfies, errors.IsNotFound)
@@ -1440,6 +1534,151 @@ func (s *ApplicationSuite) TestServiceExposed(c *gc.C) {
 	c.Assert(err, gc.ErrorMatches, notAliveErr)
 }
 
+func (s *ApplicationSuite) TestServicePaused(c *gc.C) {
+	c.Assert(s.mysql.IsPaused(), jc.IsFalse)
+
+	err := s.mysql.Pause()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(s.mysql.IsPaused(), jc.IsTrue)
+
+	// Check the flag is persisted.
+	service, err := s.State.Application(s.mysql.Name())
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(service.IsPaused(), jc.IsTrue)
+
+	// Check that pausing and resuming repeatedly does not fail.
+	err = s.mysql.Pause()
+	c.Assert(err, jc.ErrorIsNil)
+	err = s.mysql.Resume()
+	c.Assert(err, jc.ErrorIsNil)
+	err = s.mysql.Resume()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(s.mysql.IsPaused(), jc.IsFalse)
+
+	// Check that a stale application document is refreshed.
+	err = service.Resume()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(service.IsPaused(), jc.IsFalse)
+
+	// Make the service Dying and check that Pause and Resume fail.
+	_, err = s.mysql.AddUnit()
+	c.Assert(err, jc.ErrorIsNil)
+	err = s.mysql.Destroy()
+	c.Assert(err, jc.ErrorIsNil)
+	err = s.mysql.Pause()
+	c.Assert(err, gc.ErrorMatches, `cannot set paused flag for application "mysql" to true: not found or not alive`)
+	err = s.mysql.Resume()
+	c.Assert(err, gc.ErrorMatches, `cannot set paused flag for application "mysql" to false: not found or not alive`)
+}
+
+func (s *ApplicationSuite) TestCharmPin(c *gc.C) {
+	_, pinned := s.mysql.CharmPin()
+	c.Assert(pinned, jc.IsFalse)
+
+	err := s.mysql.SetCharmPin("production")
+	c.Assert(err, jc.ErrorIsNil)
+	message, pinned := s.mysql.CharmPin()
+	c.Assert(pinned, jc.IsTrue)
+	c.Assert(message, gc.Equals, "production")
+
+	// Check the pin is persisted.
+	service, err := s.State.Application(s.mysql.Name())
+	c.Assert(err, jc.ErrorIsNil)
+	message, pinned = service.CharmPin()
+	c.Assert(pinned, jc.IsTrue)
+	c.Assert(message, gc.Equals, "production")
+
+	// Pinning again replaces the message.
+	err = s.mysql.SetCharmPin("frozen")
+	c.Assert(err, jc.ErrorIsNil)
+	message, _ = s.mysql.CharmPin()
+	c.Assert(message, gc.Equals, "frozen")
+
+	// Check that removing the pin repeatedly does not fail.
+	err = s.mysql.RemoveCharmPin()
+	c.Assert(err, jc.ErrorIsNil)
+	err = s.mysql.RemoveCharmPin()
+	c.Assert(err, jc.ErrorIsNil)
+	message, pinned = s.mysql.CharmPin()
+	c.Assert(pinned, jc.IsFalse)
+	c.Assert(message, gc.Equals, "")
+
+	// Check that a stale application document is refreshed.
+	err = service.RemoveCharmPin()
+	c.Assert(err, jc.ErrorIsNil)
+	_, pinned = service.CharmPin()
+	c.Assert(pinned, jc.IsFalse)
+
+	// Make the service Dying and check that pinning fails.
+	_, err = s.mysql.AddUnit()
+	c.Assert(err, jc.ErrorIsNil)
+	err = s.mysql.Destroy()
+	c.Assert(err, jc.ErrorIsNil)
+	err = s.mysql.SetCharmPin("production")
+	c.Assert(err, gc.ErrorMatches, `cannot set charm pin for application "mysql" to true: not found or not alive`)
+}
+
+func (s *ApplicationSuite) TestSetCharmWhenPinned(c *gc.C) {
+	err := s.mysql.SetCharmPin("production")
+	c.Assert(err, jc.ErrorIsNil)
+
+	sch := s.AddMetaCharm(c, "mysql", metaBase, 2)
+	err = s.mysql.SetCharm(state.SetCharmConfig{Charm: sch})
+	c.Assert(err, gc.ErrorMatches, `cannot upgrade application "mysql" to charm "local:quantal/quantal-mysql-2": application charm is pinned: production`)
+	c.Assert(err, jc.Satisfies, state.IsCharmPinnedError)
+	url, _ := s.mysql.CharmURL()
+	c.Assert(url, gc.DeepEquals, s.charm.URL())
+
+	// Setting the same charm, e.g. to change the force flag, is allowed.
+	err = s.mysql.SetCharm(state.SetCharmConfig{Charm: s.charm, ForceUnits: true})
+	c.Assert(err, jc.ErrorIsNil)
+
+	err = s.mysql.RemoveCharmPin()
+	c.Assert(err, jc.ErrorIsNil)
+	err = s.mysql.SetCharm(state.SetCharmConfig{Charm: sch})
+	c.Assert(err, jc.ErrorIsNil)
+	url, _ = s.mysql.CharmURL()
+	c.Assert(url, gc.DeepEquals, sch.URL())
+}
+
+func (s *ApplicationSuite) TestSetCharmWhenPinnedConcurrently(c *gc.C) {
+	defer state.SetBeforeHooks(c, s.State, func() {
+		service, err := s.State.Application(s.mysql.Name())
+		c.Assert(err, jc.ErrorIsNil)
+		err = service.SetCharmPin("")
+		c.Assert(err, jc.ErrorIsNil)
+	}).Check()
+
+	sch := s.AddMetaCharm(c, "mysql", metaBase, 2)
+	err := s.mysql.SetCharm(state.SetCharmConfig{Charm: sch})
+	c.Assert(err, jc.Satisfies, state.IsCharmPinnedError)
+	c.Assert(err, gc.ErrorMatches, `.*: application charm is pinned`)
+}
+
+func (s *ApplicationSuite) TestAddUnitWhenPaused(c *gc.C) {
+	err := s.mysql.Pause()
+	c.Assert(err, jc.ErrorIsNil)
+	_, err = s.mysql.AddUnit()
+	c.Assert(err, gc.ErrorMatches, `cannot add unit to application "mysql": application is paused`)
+
+	err = s.mysql.Resume()
+	c.Assert(err, jc.ErrorIsNil)
+	_, err = s.mysql.AddUnit()
+	c.Assert(err, jc.ErrorIsNil)
+}
+
+func (s *ApplicationSuite) TestAddUnitWhenPausedConcurrently(c *gc.C) {
+	defer state.SetBeforeHooks(c, s.State, func() {
+		service, err := s.State.Application(s.mysql.Name())
+		c.Assert(err, jc.ErrorIsNil)
+		err = service.Pause()
+		c.Assert(err, jc.ErrorIsNil)
+	}).Check()
+
+	_, err := s.mysql.AddUnit()
+	c.Assert(err, gc.ErrorMatches, `cannot add unit to application "mysql": application is paused`)
+}
+
 func (s *ApplicationSuite) TestAddUnit(c *gc.C) {
 	// Check that principal units can be added on their own.
 	unitZero, err := s.mysql.AddUnit()
@@ -2148,6 +2387,14 @@ func (s *ApplicationSuite) TestWatchService(c *gc.C) {
 	c.Assert(err, jc.ErrorIsNil)
 	wc.AssertOneChange()
 
+	// Pause and resume, check an event for each.
+	err = service.Pause()
+	c.Assert(err, jc.ErrorIsNil)
+	wc.AssertOneChange()
+	err = service.Resume()
+	c.Assert(err, jc.ErrorIsNil)
+	wc.AssertOneChange()
+
 	// Stop, check closed.
 	testing.AssertStop(c, w)
 	wc.AssertClosed()
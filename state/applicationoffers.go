@@ -0,0 +1,316 @@
+// Copyright 2016 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package state
+
+import (
+	"sort"
+
+	"github.com/juju/errors"
+	"github.com/juju/utils/set"
+	"gopkg.in/juju/charm.v6-unstable"
+	"gopkg.in/juju/names.v2"
+	"gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
+	"gopkg.in/mgo.v2/txn"
+)
+
+// ApplicationOffer describes an offer of some of an application's
+// endpoints to other models, so that applications in those models
+// may consume the offer and relate to the offered application.
+//
+// Offers are the groundwork for cross-model relations. Each offer
+// records who may consume it: the user that made the offer, and the
+// users that have been granted access to it.
+type ApplicationOffer struct {
+	// OfferName is the name of the offer, which is unique within
+	// the model.
+	OfferName string
+
+	// Application is the name of the offered application.
+	Application string
+
+	// Endpoints holds the names of the application's endpoints
+	// that are offered, in lexical order.
+	Endpoints []string
+
+	// Description describes the offer to its consumers.
+	Description string
+
+	// Owner is the user that made the offer.
+	Owner names.UserTag
+
+	// Users holds the users, other than the owner, that may
+	// consume the offer, ordered by name.
+	Users []names.UserTag
+}
+
+// CanConsume reports whether or not the given user may consume the
+// offer.
+func (o ApplicationOffer) CanConsume(user names.UserTag) bool {
+	if user.Id() == o.Owner.Id() {
+		return true
+	}
+	for _, u := range o.Users {
+		if user.Id() == u.Id() {
+			return true
+		}
+	}
+	return false
+}
+
+// AddApplicationOfferArgs holds the arguments for adding an
+// application offer with AddApplicationOffer.
+type AddApplicationOfferArgs struct {
+	// OfferName is the name of the offer. Offer names are
+	// subject to the same rules as application names.
+	OfferName string
+
+	// Application is the name of the application to offer.
+	Application string
+
+	// Endpoints holds the names of the application's endpoints
+	// to offer. At least one endpoint must be offered, and peer
+	// endpoints may not be.
+	Endpoints []string
+
+	// Description describes the offer to its consumers.
+	Description string
+
+	// Owner is the user making the offer.
+	Owner names.UserTag
+
+	// Users holds the users, other than the owner, that may
+	// consume the offer.
+	Users []names.UserTag
+}
+
+// applicationOfferDoc represents an application offer in MongoDB.
+type applicationOfferDoc struct {
+	DocID       string   `bson:"_id"`
+	ModelUUID   string   `bson:"model-uuid"`
+	OfferName   string   `bson:"offer-name"`
+	Application string   `bson:"application"`
+	Endpoints   []string `bson:"endpoints"`
+	Description string   `bson:"description"`
+	Owner       string   `bson:"owner"`
+	Users       []string `bson:"users"`
+}
+
+func (doc *applicationOfferDoc) offer() ApplicationOffer {
+	endpoints := make([]string, len(doc.Endpoints))
+	copy(endpoints, doc.Endpoints)
+	sort.Strings(endpoints)
+	userNames := make([]string, len(doc.Users))
+	copy(userNames, doc.Users)
+	sort.Strings(userNames)
+	users := make([]names.UserTag, len(userNames))
+	for i, name := range userNames {
+		users[i] = names.NewUserTag(name)
+	}
+	return ApplicationOffer{
+		OfferName:   doc.OfferName,
+		Application: doc.Application,
+		Endpoints:   endpoints,
+		Description: doc.Description,
+		Owner:       names.NewUserTag(doc.Owner),
+		Users:       users,
+	}
+}
+
+// AddApplicationOffer offers the endpoints of an application to other
+// models, and returns the offer. If an offer with the same name already
+// exists, an error satisfying errors.IsAlreadyExists is returned.
+func (st *State) AddApplicationOffer(args AddApplicationOfferArgs) (_ ApplicationOffer, err error) {
+	defer errors.DeferredAnnotatef(&err, "cannot add application offer %q", args.OfferName)
+
+	if !names.IsValidApplication(args.OfferName) {
+		return ApplicationOffer{}, errors.NotValidf("offer name %q", args.OfferName)
+	}
+	if len(args.Endpoints) == 0 {
+		return ApplicationOffer{}, errors.New("no endpoints specified")
+	}
+	app, err := st.Application(args.Application)
+	if err != nil {
+		return ApplicationOffer{}, errors.Trace(err)
+	}
+	endpoints := set.NewStrings()
+	for _, name := range args.Endpoints {
+		ep, err := app.Endpoint(name)
+		if err != nil {
+			return ApplicationOffer{}, errors.Trace(err)
+		}
+		if ep.Role == charm.RolePeer {
+			return ApplicationOffer{}, errors.Errorf("cannot offer peer endpoint %q", name)
+		}
+		endpoints.Add(name)
+	}
+	users := make([]string, len(args.Users))
+	for i, user := range args.Users {
+		users[i] = user.Id()
+	}
+
+	doc := &applicationOfferDoc{
+		DocID:       st.docID(args.OfferName),
+		ModelUUID:   st.ModelUUID(),
+		OfferName:   args.OfferName,
+		Application: args.Application,
+		Endpoints:   endpoints.SortedValues(),
+		Description: args.Description,
+		Owner:       args.Owner.Id(),
+		Users:       users,
+	}
+	buildTxn := func(attempt int) ([]txn.Op, error) {
+		if attempt > 0 {
+			if _, err := getApplicationOfferDoc(st, args.OfferName); err == nil {
+				return nil, errors.AlreadyExistsf("application offer %q", args.OfferName)
+			} else if !errors.IsNotFound(err) {
+				return nil, errors.Trace(err)
+			}
+			if err := app.Refresh(); err != nil {
+				return nil, errors.Trace(err)
+			}
+		}
+		if app.Life() != Alive {
+			return nil, errors.Errorf("application %q is not alive", args.Application)
+		}
+		return []txn.Op{{
+			C:      applicationsC,
+			Id:     app.doc.DocID,
+			Assert: isAliveDoc,
+		}, {
+			C:      applicationOffersC,
+			Id:     doc.DocID,
+			Assert: txn.DocMissing,
+			Insert: doc,
+		}}, nil
+	}
+	if err := st.run(buildTxn); err != nil {
+		return ApplicationOffer{}, errors.Trace(err)
+	}
+	return doc.offer(), nil
+}
+
+// ApplicationOffer returns the application offer with the given name.
+func (st *State) ApplicationOffer(offerName string) (ApplicationOffer, error) {
+	doc, err := getApplicationOfferDoc(st, offerName)
+	if err != nil {
+		return ApplicationOffer{}, errors.Trace(err)
+	}
+	return doc.offer(), nil
+}
+
+// AllApplicationOffers returns all of the application offers in the
+// model, ordered by offer name.
+func (st *State) AllApplicationOffers() ([]ApplicationOffer, error) {
+	coll, closer := st.getCollection(applicationOffersC)
+	defer closer()
+
+	var docs []applicationOfferDoc
+	if err := coll.Find(nil).Sort("offer-name").All(&docs); err != nil {
+		return nil, errors.Annotate(err, "cannot get application offers")
+	}
+	offers := make([]ApplicationOffer, len(docs))
+	for i, doc := range docs {
+		offers[i] = doc.offer()
+	}
+	return offers, nil
+}
+
+// RemoveApplicationOffer removes the application offer with the given
+// name. Applications in other models will no longer be able to consume
+// the offer. If the offer does not exist, an error satisfying
+// errors.IsNotFound is returned.
+func (st *State) RemoveApplicationOffer(offerName string) error {
+	err := st.runTransaction([]txn.Op{{
+		C:      applicationOffersC,
+		Id:     st.docID(offerName),
+		Assert: txn.DocExists,
+		Remove: true,
+	}})
+	if err == txn.ErrAborted {
+		return errors.NotFoundf("application offer %q", offerName)
+	}
+	return errors.Annotatef(err, "cannot remove application offer %q", offerName)
+}
+
+// GrantApplicationOfferAccess allows the user to consume the named
+// application offer. Granting access to a user that already has it
+// is not an error.
+func (st *State) GrantApplicationOfferAccess(offerName string, user names.UserTag) error {
+	return errors.Annotatef(
+		st.updateApplicationOfferUsers(offerName, "$addToSet", user),
+		"cannot grant %q access to application offer %q", user.Id(), offerName,
+	)
+}
+
+// RevokeApplicationOfferAccess prevents the user from consuming the
+// named application offer. The owner of the offer may always consume
+// it; revoking access from a user that does not have it is not an error.
+func (st *State) RevokeApplicationOfferAccess(offerName string, user names.UserTag) error {
+	return errors.Annotatef(
+		st.updateApplicationOfferUsers(offerName, "$pull", user),
+		"cannot revoke %q access to application offer %q", user.Id(), offerName,
+	)
+}
+
+// updateApplicationOfferUsers applies the given update operator to
+// the users that may consume the named application offer.
+func (st *State) updateApplicationOfferUsers(offerName, operator string, user names.UserTag) error {
+	err := st.runTransaction([]txn.Op{{
+		C:      applicationOffersC,
+		Id:     st.docID(offerName),
+		Assert: txn.DocExists,
+		Update: bson.D{{operator, bson.D{{"users", user.Id()}}}},
+	}})
+	if err == txn.ErrAborted {
+		return errors.NotFoundf("application offer %q", offerName)
+	}
+	return errors.Trace(err)
+}
+
+// WatchApplicationOffers returns a StringsWatcher that notifies of
+// changes to the application offers in the model, including changes
+// to the users that may consume them. The names of the offers that
+// have changed are reported.
+func (st *State) WatchApplicationOffers() StringsWatcher {
+	return newcollectionWatcher(st, colWCfg{col: applicationOffersC})
+}
+
+func getApplicationOfferDoc(st *State, offerName string) (*applicationOfferDoc, error) {
+	coll, closer := st.getCollection(applicationOffersC)
+	defer closer()
+
+	var doc applicationOfferDoc
+	err := coll.FindId(offerName).One(&doc)
+	if err == mgo.ErrNotFound {
+		return nil, errors.NotFoundf("application offer %q", offerName)
+	} else if err != nil {
+		return nil, errors.Annotatef(err, "cannot get application offer %q", offerName)
+	}
+	return &doc, nil
+}
+
+// removeApplicationOffersOps returns the operations required to remove
+// the offers of the named application.
+func removeApplicationOffersOps(st *State, applicationName string) ([]txn.Op, error) {
+	coll, closer := st.getCollection(applicationOffersC)
+	defer closer()
+
+	var docs []struct {
+		DocID string `bson:"_id"`
+	}
+	err := coll.Find(bson.D{{"application", applicationName}}).Select(bson.D{{"_id", 1}}).All(&docs)
+	if err != nil {
+		return nil, errors.Annotatef(err, "cannot get offers of application %q", applicationName)
+	}
+	ops := make([]txn.Op, len(docs))
+	for i, doc := range docs {
+		ops[i] = txn.Op{
+			C:      applicationOffersC,
+			Id:     doc.DocID,
+			Remove: true,
+		}
+	}
+	return ops, nil
+}
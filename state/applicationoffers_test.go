@@ -0,0 +1,201 @@
+// Copyright 2016 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package state_test
+
+import (
+	"github.com/juju/errors"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+	"gopkg.in/juju/names.v2"
+
+	"github.com/juju/juju/state"
+	statetesting "github.com/juju/juju/state/testing"
+	"github.com/juju/juju/testing/factory"
+)
+
+type ApplicationOfferSuite struct {
+	ConnSuite
+	application *state.Application
+}
+
+var _ = gc.Suite(&ApplicationOfferSuite{})
+
+func (s *ApplicationOfferSuite) SetUpTest(c *gc.C) {
+	s.ConnSuite.SetUpTest(c)
+	f := factory.NewFactory(s.State)
+	s.application = f.MakeApplication(c, &factory.ApplicationParams{Name: "wordpress"})
+}
+
+func (s *ApplicationOfferSuite) addOffer(c *gc.C, offerName string) state.ApplicationOffer {
+	offer, err := s.State.AddApplicationOffer(state.AddApplicationOfferArgs{
+		OfferName:   offerName,
+		Application: "wordpress",
+		Endpoints:   []string{"url", "db", "url"},
+		Description: "a blog",
+		Owner:       names.NewUserTag("admin"),
+		Users:       []names.UserTag{names.NewUserTag("mary")},
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	return offer
+}
+
+func (s *ApplicationOfferSuite) TestAddApplicationOffer(c *gc.C) {
+	offer := s.addOffer(c, "blog")
+	expected := state.ApplicationOffer{
+		OfferName:   "blog",
+		Application: "wordpress",
+		Endpoints:   []string{"db", "url"},
+		Description: "a blog",
+		Owner:       names.NewUserTag("admin"),
+		Users:       []names.UserTag{names.NewUserTag("mary")},
+	}
+	c.Assert(offer, jc.DeepEquals, expected)
+
+	offer, err := s.State.ApplicationOffer("blog")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(offer, jc.DeepEquals, expected)
+}
+
+func (s *ApplicationOfferSuite) TestAddApplicationOfferAlreadyExists(c *gc.C) {
+	s.addOffer(c, "blog")
+	_, err := s.State.AddApplicationOffer(state.AddApplicationOfferArgs{
+		OfferName:   "blog",
+		Application: "wordpress",
+		Endpoints:   []string{"url"},
+		Owner:       names.NewUserTag("admin"),
+	})
+	c.Assert(err, jc.Satisfies, errors.IsAlreadyExists)
+	c.Assert(err, gc.ErrorMatches, `cannot add application offer "blog": application offer "blog" already exists`)
+}
+
+func (s *ApplicationOfferSuite) TestAddApplicationOfferInvalid(c *gc.C) {
+	f := factory.NewFactory(s.State)
+	f.MakeApplication(c, &factory.ApplicationParams{
+		Name:  "riak",
+		Charm: f.MakeCharm(c, &factory.CharmParams{Name: "riak"}),
+	})
+	for _, test := range []struct {
+		args   state.AddApplicationOfferArgs
+		expect string
+	}{{
+		args:   state.AddApplicationOfferArgs{OfferName: "-blog", Application: "wordpress", Endpoints: []string{"url"}},
+		expect: `cannot add application offer "-blog": offer name "-blog" not valid`,
+	}, {
+		args:   state.AddApplicationOfferArgs{OfferName: "blog", Application: "wordpress"},
+		expect: `cannot add application offer "blog": no endpoints specified`,
+	}, {
+		args:   state.AddApplicationOfferArgs{OfferName: "blog", Application: "joomla", Endpoints: []string{"url"}},
+		expect: `cannot add application offer "blog": application "joomla" not found`,
+	}, {
+		args:   state.AddApplicationOfferArgs{OfferName: "blog", Application: "wordpress", Endpoints: []string{"admin"}},
+		expect: `cannot add application offer "blog": application "wordpress" has no "admin" relation`,
+	}, {
+		args:   state.AddApplicationOfferArgs{OfferName: "kv", Application: "riak", Endpoints: []string{"ring"}},
+		expect: `cannot add application offer "kv": cannot offer peer endpoint "ring"`,
+	}} {
+		test.args.Owner = names.NewUserTag("admin")
+		_, err := s.State.AddApplicationOffer(test.args)
+		c.Check(err, gc.ErrorMatches, test.expect)
+	}
+}
+
+func (s *ApplicationOfferSuite) TestAddApplicationOfferApplicationDying(c *gc.C) {
+	f := factory.NewFactory(s.State)
+	f.MakeUnit(c, &factory.UnitParams{Application: s.application})
+	err := s.application.Destroy()
+	c.Assert(err, jc.ErrorIsNil)
+
+	_, err = s.State.AddApplicationOffer(state.AddApplicationOfferArgs{
+		OfferName:   "blog",
+		Application: "wordpress",
+		Endpoints:   []string{"url"},
+		Owner:       names.NewUserTag("admin"),
+	})
+	c.Assert(err, gc.ErrorMatches, `cannot add application offer "blog": application "wordpress" is not alive`)
+}
+
+func (s *ApplicationOfferSuite) TestAllApplicationOffers(c *gc.C) {
+	offers, err := s.State.AllApplicationOffers()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(offers, gc.HasLen, 0)
+
+	s.addOffer(c, "wiki")
+	s.addOffer(c, "blog")
+	offers, err = s.State.AllApplicationOffers()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(offers, gc.HasLen, 2)
+	c.Assert(offers[0].OfferName, gc.Equals, "blog")
+	c.Assert(offers[1].OfferName, gc.Equals, "wiki")
+}
+
+func (s *ApplicationOfferSuite) TestRemoveApplicationOffer(c *gc.C) {
+	s.addOffer(c, "blog")
+	err := s.State.RemoveApplicationOffer("blog")
+	c.Assert(err, jc.ErrorIsNil)
+
+	_, err = s.State.ApplicationOffer("blog")
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+	err = s.State.RemoveApplicationOffer("blog")
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+}
+
+func (s *ApplicationOfferSuite) TestRemoveApplicationRemovesOffers(c *gc.C) {
+	s.addOffer(c, "blog")
+	err := s.application.Destroy()
+	c.Assert(err, jc.ErrorIsNil)
+
+	_, err = s.State.ApplicationOffer("blog")
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+}
+
+func (s *ApplicationOfferSuite) TestGrantRevokeApplicationOfferAccess(c *gc.C) {
+	s.addOffer(c, "blog")
+	bob := names.NewUserTag("bob")
+	mary := names.NewUserTag("mary")
+
+	offer, err := s.State.ApplicationOffer("blog")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(offer.CanConsume(names.NewUserTag("admin")), jc.IsTrue)
+	c.Assert(offer.CanConsume(mary), jc.IsTrue)
+	c.Assert(offer.CanConsume(bob), jc.IsFalse)
+
+	err = s.State.GrantApplicationOfferAccess("blog", bob)
+	c.Assert(err, jc.ErrorIsNil)
+	err = s.State.GrantApplicationOfferAccess("blog", bob)
+	c.Assert(err, jc.ErrorIsNil)
+	err = s.State.RevokeApplicationOfferAccess("blog", mary)
+	c.Assert(err, jc.ErrorIsNil)
+
+	offer, err = s.State.ApplicationOffer("blog")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(offer.Users, jc.DeepEquals, []names.UserTag{bob})
+	c.Assert(offer.CanConsume(bob), jc.IsTrue)
+	c.Assert(offer.CanConsume(mary), jc.IsFalse)
+
+	err = s.State.GrantApplicationOfferAccess("wiki", bob)
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+	c.Assert(err, gc.ErrorMatches, `cannot grant "bob" access to application offer "wiki": application offer "wiki" not found`)
+}
+
+func (s *ApplicationOfferSuite) TestWatchApplicationOffers(c *gc.C) {
+	w := s.State.WatchApplicationOffers()
+	defer statetesting.AssertStop(c, w)
+	wc := statetesting.NewStringsWatcherC(c, s.State, w)
+	wc.AssertChange()
+	wc.AssertNoChange()
+
+	s.addOffer(c, "blog")
+	wc.AssertChange("blog")
+	wc.AssertNoChange()
+
+	err := s.State.GrantApplicationOfferAccess("blog", names.NewUserTag("bob"))
+	c.Assert(err, jc.ErrorIsNil)
+	wc.AssertChange("blog")
+	wc.AssertNoChange()
+
+	err = s.State.RemoveApplicationOffer("blog")
+	c.Assert(err, jc.ErrorIsNil)
+	wc.AssertChange("blog")
+	wc.AssertNoChange()
+}
@@ -0,0 +1,747 @@
+// Copyright 2016 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package state
+
+import (
+	stderrors "errors"
+	"strings"
+
+	"github.com/juju/errors"
+	jujutxn "github.com/juju/txn"
+	"gopkg.in/juju/names.v2"
+	"gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
+	"gopkg.in/mgo.v2/txn"
+)
+
+// applicationRenameDoc records the progress of renaming an application.
+// A document is recorded under each of the old and new names, so that
+// neither name can be involved in another rename, and the application
+// cannot be destroyed, until the rename completes. The counts of units
+// and relations renamed, the status history to be moved, and whether
+// the application itself has been renamed, are updated on the document
+// recorded under the old name.
+type applicationRenameDoc struct {
+	DocID            string `bson:"_id"`
+	ModelUUID        string `bson:"model-uuid"`
+	OldName          string `bson:"old-name"`
+	NewName          string `bson:"new-name"`
+	UnitsRenamed     int    `bson:"units-renamed"`
+	RelationsRenamed int    `bson:"relations-renamed"`
+	Renamed          bool   `bson:"renamed"`
+
+	// History records the global keys whose status history is
+	// to be moved, once the application and its units have been
+	// renamed. Keys are recorded in the same transactions that
+	// rename the entities they belong to.
+	History []applicationRenameHistoryDoc `bson:"history"`
+}
+
+// applicationRenameHistoryDoc records the old and new global keys
+// of an entity whose status history is moved by a rename.
+type applicationRenameHistoryDoc struct {
+	OldKey string `bson:"old-key"`
+	NewKey string `bson:"new-key"`
+}
+
+// errApplicationRenameIncomplete is returned when completing the rename
+// of an application, if units or relations have been added to the
+// application since they were last renamed.
+var errApplicationRenameIncomplete = stderrors.New("application rename incomplete")
+
+// RenameApplication renames the application with the given name, along
+// with its units, relations, settings, annotations, status history and
+// other documents keyed on the application's name.
+//
+// The rename is performed in a series of transactions: one to record the
+// rename's progress, one for each unit and relation, one to rename the
+// application itself, and one to remove the progress record once the
+// status history of the application and its units has been moved. Until
+// the rename completes, the application's units and relations may be
+// found under either name; renames should therefore only be performed
+// while the model is idle. If a rename is interrupted, it may be resumed
+// by calling RenameApplication again with the same names.
+//
+// Units are identified to their agents by name, so an application may
+// only be renamed before any of its units have been deployed. Units with
+// storage or pending actions, and applications with resources, may not
+// be renamed.
+func (st *State) RenameApplication(oldName, newName string) (err error) {
+	defer errors.DeferredAnnotatef(&err, "cannot rename application %q to %q", oldName, newName)
+	if !names.IsValidApplication(newName) {
+		return errors.NotValidf("application name %q", newName)
+	}
+	if oldName == newName {
+		return errors.New("application already has that name")
+	}
+	if err := st.beginApplicationRename(oldName, newName); err != nil {
+		return errors.Trace(err)
+	}
+	rename, err := st.applicationRename(oldName)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	if !rename.Renamed {
+		if err := st.renameApplicationEntities(oldName, newName); err != nil {
+			return errors.Trace(err)
+		}
+	}
+	if err := st.moveApplicationRenameHistory(oldName); err != nil {
+		return errors.Trace(err)
+	}
+	return errors.Trace(st.endApplicationRename(oldName, newName))
+}
+
+// renameApplicationEntities renames the units and relations of the
+// application being renamed, and then the application itself.
+func (st *State) renameApplicationEntities(oldName, newName string) error {
+	for {
+		renamed, err := st.renameNextApplicationUnit(oldName, newName)
+		if err != nil {
+			return errors.Trace(err)
+		} else if renamed {
+			continue
+		}
+		renamed, err = st.renameNextApplicationRelation(oldName, newName)
+		if err != nil {
+			return errors.Trace(err)
+		} else if renamed {
+			continue
+		}
+		err = st.finishApplicationRename(oldName, newName)
+		if errors.Cause(err) == errApplicationRenameIncomplete {
+			continue
+		}
+		return errors.Trace(err)
+	}
+}
+
+// beginApplicationRename records the rename of the application, or does
+// nothing if the same rename is already in progress.
+func (st *State) beginApplicationRename(oldName, newName string) error {
+	buildTxn := func(int) ([]txn.Op, error) {
+		oldRename, err := st.applicationRename(oldName)
+		if err != nil && !errors.IsNotFound(err) {
+			return nil, errors.Trace(err)
+		} else if err == nil {
+			if oldRename.OldName != oldName || oldRename.NewName != newName {
+				return nil, errApplicationRenaming(oldRename)
+			}
+			logger.Infof(
+				"resuming rename of application %q to %q (%d units and %d relations renamed)",
+				oldName, newName, oldRename.UnitsRenamed, oldRename.RelationsRenamed,
+			)
+			return nil, jujutxn.ErrNoOperations
+		}
+		if newRename, err := st.applicationRename(newName); err == nil {
+			return nil, errApplicationRenaming(newRename)
+		} else if !errors.IsNotFound(err) {
+			return nil, errors.Trace(err)
+		}
+
+		app, err := st.Application(oldName)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		if app.doc.Life != Alive {
+			return nil, errors.Errorf("application %q is not alive", oldName)
+		}
+		if _, err := st.Application(newName); err == nil {
+			return nil, errors.AlreadyExistsf("application %q", newName)
+		} else if !errors.IsNotFound(err) {
+			return nil, errors.Trace(err)
+		}
+		if err := checkApplicationRenameable(st, oldName); err != nil {
+			return nil, errors.Trace(err)
+		}
+
+		ops := []txn.Op{{
+			C:      applicationsC,
+			Id:     app.doc.DocID,
+			Assert: isAliveDoc,
+		}, {
+			C:      applicationsC,
+			Id:     st.docID(newName),
+			Assert: txn.DocMissing,
+		}}
+		for _, name := range []string{oldName, newName} {
+			ops = append(ops, txn.Op{
+				C:      applicationRenamesC,
+				Id:     st.docID(name),
+				Assert: txn.DocMissing,
+				Insert: &applicationRenameDoc{
+					DocID:     st.docID(name),
+					ModelUUID: st.ModelUUID(),
+					OldName:   oldName,
+					NewName:   newName,
+					History: []applicationRenameHistoryDoc{{
+						OldKey: applicationGlobalKey(oldName),
+						NewKey: applicationGlobalKey(newName),
+					}},
+				},
+			})
+		}
+		return ops, nil
+	}
+	return st.run(buildTxn)
+}
+
+// applicationRename returns the progress record of the rename involving
+// the application with the given name, or an error satisfying
+// errors.IsNotFound if there is none.
+func (st *State) applicationRename(name string) (*applicationRenameDoc, error) {
+	renames, closer := st.getCollection(applicationRenamesC)
+	defer closer()
+
+	var doc applicationRenameDoc
+	if err := renames.FindId(name).One(&doc); err == mgo.ErrNotFound {
+		return nil, errors.NotFoundf("rename of application %q", name)
+	} else if err != nil {
+		return nil, errors.Annotatef(err, "getting rename of application %q", name)
+	}
+	return &doc, nil
+}
+
+func errApplicationRenaming(doc *applicationRenameDoc) error {
+	return errors.Errorf("application %q is being renamed to %q", doc.OldName, doc.NewName)
+}
+
+// checkApplicationRenameable returns an error if the application with
+// the given name has units or resources that prevent it from being
+// renamed. The per-unit conditions are also asserted when each unit
+// is renamed.
+func checkApplicationRenameable(st *State, appName string) error {
+	units, closer := st.getCollection(unitsC)
+	defer closer()
+
+	var docs []unitDoc
+	if err := units.Find(bson.D{{"application", appName}}).All(&docs); err != nil {
+		return errors.Annotatef(err, "getting units of application %q", appName)
+	}
+	for _, doc := range docs {
+		if err := checkUnitRenameable(st, &doc); err != nil {
+			return errors.Trace(err)
+		}
+	}
+
+	resources, closer := st.getCollection(resourcesC)
+	defer closer()
+
+	if n, err := resources.Find(bson.D{{"application-id", appName}}).Count(); err != nil {
+		return errors.Annotatef(err, "getting resources of application %q", appName)
+	} else if n > 0 {
+		return errors.NotSupportedf("renaming application with resources")
+	}
+	return nil
+}
+
+// unitRenameableAssert asserts the conditions checked by
+// checkUnitRenameable.
+var unitRenameableAssert = bson.D{
+	{"passwordhash", ""},
+	{"storageattachmentcount", 0},
+}
+
+// checkUnitRenameable returns an error if the unit may not be renamed.
+// Deployed units are refused, as their agents know them by name, and
+// would lose their identity if the unit were renamed.
+func checkUnitRenameable(st *State, doc *unitDoc) error {
+	if doc.PasswordHash != "" {
+		return errors.Errorf("unit %q has been deployed", doc.Name)
+	}
+	if doc.StorageAttachmentCount > 0 {
+		return errors.NotSupportedf("renaming unit %q with storage", doc.Name)
+	}
+
+	actions, closer := st.getCollection(actionsC)
+	defer closer()
+	n, err := actions.Find(bson.D{
+		{"receiver", doc.Name},
+		{"status", bson.D{{"$in", []ActionStatus{ActionPending, ActionRunning}}}},
+	}).Count()
+	if err != nil {
+		return errors.Annotatef(err, "getting actions of unit %q", doc.Name)
+	} else if n > 0 {
+		return errors.NotSupportedf("renaming unit %q with pending actions", doc.Name)
+	}
+	return nil
+}
+
+// renameNextApplicationUnit renames one of the units remaining in the
+// application being renamed, and reports whether there was one to rename.
+func (st *State) renameNextApplicationUnit(oldAppName, newAppName string) (bool, error) {
+	units, closer := st.getCollection(unitsC)
+	defer closer()
+
+	var renamed bool
+	buildTxn := func(attempt int) ([]txn.Op, error) {
+		renamed = false
+		var doc unitDoc
+		err := units.Find(bson.D{{"application", oldAppName}}).Sort("name").One(&doc)
+		if err == mgo.ErrNotFound {
+			return nil, jujutxn.ErrNoOperations
+		} else if err != nil {
+			return nil, errors.Annotatef(err, "getting units of application %q", oldAppName)
+		}
+		if err := checkUnitRenameable(st, &doc); err != nil {
+			return nil, errors.Trace(err)
+		}
+		newName := newAppName + "/" + strings.TrimPrefix(doc.Name, oldAppName+"/")
+		if attempt > 0 {
+			if _, err := st.Unit(newName); err == nil {
+				return nil, errors.AlreadyExistsf("unit %q", newName)
+			}
+		}
+		ops, err := renameUnitOps(st, &doc, newName)
+		if err != nil {
+			return nil, errors.Annotatef(err, "renaming unit %q", doc.Name)
+		}
+		var history []applicationRenameHistoryDoc
+		for _, key := range []func(string) string{
+			unitGlobalKey, unitAgentGlobalKey, globalWorkloadVersionKey,
+		} {
+			history = append(history, applicationRenameHistoryDoc{
+				OldKey: key(doc.Name),
+				NewKey: key(newName),
+			})
+		}
+		renamed = true
+		return append(ops, txn.Op{
+			C:      applicationRenamesC,
+			Id:     st.docID(oldAppName),
+			Assert: bson.D{{"new-name", newAppName}, {"renamed", false}},
+			Update: bson.D{
+				{"$inc", bson.D{{"units-renamed", 1}}},
+				{"$push", bson.D{{"history", bson.D{{"$each", history}}}}},
+			},
+		}), nil
+	}
+	if err := st.run(buildTxn); err != nil {
+		return false, errors.Trace(err)
+	}
+	return renamed, nil
+}
+
+// renameUnitOps returns the operations required to rename the unit, and
+// the documents keyed on its name, to the given name.
+func renameUnitOps(st *State, doc *unitDoc, newName string) ([]txn.Op, error) {
+	oldName := doc.Name
+	newAppName, err := names.UnitApplication(newName)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	var ops []txn.Op
+	addMoveDocOps := func(collName, oldID, newID string, set bson.D, asserts bson.D) error {
+		moveOps, err := moveDocOps(st, collName, oldID, newID, set, asserts)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		ops = append(ops, moveOps...)
+		return nil
+	}
+
+	if err := addMoveDocOps(unitsC, oldName, newName, bson.D{
+		{"name", newName},
+		{"application", newAppName},
+	}, unitRenameableAssert); err != nil {
+		return nil, errors.Trace(err)
+	}
+	for _, keys := range [][2]string{
+		{unitAgentGlobalKey(oldName), unitAgentGlobalKey(newName)},
+		{unitGlobalKey(oldName), unitGlobalKey(newName)},
+		{globalWorkloadVersionKey(oldName), globalWorkloadVersionKey(newName)},
+	} {
+		if err := addMoveDocOps(statusesC, keys[0], keys[1], nil, nil); err != nil {
+			return nil, errors.Trace(err)
+		}
+	}
+	if err := addMoveDocOps(
+		constraintsC, unitAgentGlobalKey(oldName), unitAgentGlobalKey(newName), nil, nil,
+	); err != nil {
+		return nil, errors.Trace(err)
+	}
+	if err := addMoveDocOps(
+		meterStatusC, unitAgentGlobalKey(oldName), unitAgentGlobalKey(newName), nil, nil,
+	); err != nil {
+		return nil, errors.Trace(err)
+	}
+	if err := addMoveDocOps(annotationsC, unitGlobalKey(oldName), unitGlobalKey(newName), bson.D{
+		{"globalkey", unitGlobalKey(newName)},
+		{"tag", names.NewUnitTag(newName).String()},
+	}, nil); err != nil {
+		return nil, errors.Trace(err)
+	}
+	if err := addMoveDocOps(assignUnitC, oldName, newName, nil, nil); err != nil {
+		return nil, errors.Trace(err)
+	}
+
+	// Update the references to the unit held by its machine,
+	// its principal and its subordinates.
+	if doc.Principal == "" && doc.MachineId != "" {
+		m, err := st.Machine(doc.MachineId)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		ops = append(ops, txn.Op{
+			C:      machinesC,
+			Id:     m.doc.DocID,
+			Assert: bson.D{{"principals", m.doc.Principals}},
+			Update: bson.D{{"$set", bson.D{
+				{"principals", replaceString(m.doc.Principals, oldName, newName)},
+			}}},
+		})
+	}
+	if doc.Principal != "" {
+		principal, err := st.Unit(doc.Principal)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		ops = append(ops, txn.Op{
+			C:      unitsC,
+			Id:     principal.doc.DocID,
+			Assert: bson.D{{"subordinates", principal.doc.Subordinates}},
+			Update: bson.D{{"$set", bson.D{
+				{"subordinates", replaceString(principal.doc.Subordinates, oldName, newName)},
+			}}},
+		})
+	}
+	for _, subordinate := range doc.Subordinates {
+		ops = append(ops, txn.Op{
+			C:      unitsC,
+			Id:     st.docID(subordinate),
+			Assert: bson.D{{"principal", oldName}},
+			Update: bson.D{{"$set", bson.D{{"principal", newName}}}},
+		})
+	}
+	return ops, nil
+}
+
+// renameNextApplicationRelation renames one of the relations remaining
+// in the application being renamed, and reports whether there was one
+// to rename.
+func (st *State) renameNextApplicationRelation(oldAppName, newAppName string) (bool, error) {
+	relations, closer := st.getCollection(relationsC)
+	defer closer()
+
+	var renamed bool
+	buildTxn := func(attempt int) ([]txn.Op, error) {
+		renamed = false
+		var doc relationDoc
+		err := relations.Find(bson.D{{"endpoints.applicationname", oldAppName}}).Sort("id").One(&doc)
+		if err == mgo.ErrNotFound {
+			return nil, jujutxn.ErrNoOperations
+		} else if err != nil {
+			return nil, errors.Annotatef(err, "getting relations of application %q", oldAppName)
+		}
+		endpoints := make([]Endpoint, len(doc.Endpoints))
+		for i, ep := range doc.Endpoints {
+			if ep.ApplicationName == oldAppName {
+				ep.ApplicationName = newAppName
+			}
+			endpoints[i] = ep
+		}
+		newKey := relationKey(endpoints)
+		if attempt > 0 {
+			if _, err := st.KeyRelation(newKey); err == nil {
+				return nil, errors.AlreadyExistsf("relation %q", newKey)
+			}
+		}
+		ops, err := moveDocOps(st, relationsC, doc.Key, newKey, bson.D{
+			{"key", newKey},
+			{"endpoints", endpoints},
+		}, nil)
+		if err != nil {
+			return nil, errors.Annotatef(err, "renaming relation %q", doc.Key)
+		}
+		renamed = true
+		return append(ops, txn.Op{
+			C:      applicationRenamesC,
+			Id:     st.docID(oldAppName),
+			Assert: bson.D{{"new-name", newAppName}, {"renamed", false}},
+			Update: bson.D{{"$inc", bson.D{{"relations-renamed", 1}}}},
+		}), nil
+	}
+	if err := st.run(buildTxn); err != nil {
+		return false, errors.Trace(err)
+	}
+	return renamed, nil
+}
+
+// finishApplicationRename renames the application document, and the
+// documents keyed on the application's name, and records that it has
+// done so in the rename's progress record. If any units or relations
+// of the application remain to be renamed,
+// errApplicationRenameIncomplete is returned.
+func (st *State) finishApplicationRename(oldName, newName string) error {
+	buildTxn := func(attempt int) ([]txn.Op, error) {
+		app, err := st.Application(oldName)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		if app.doc.Life != Alive {
+			return nil, errors.Errorf("application %q is not alive", oldName)
+		}
+		if attempt > 0 {
+			if remaining, err := applicationRenameRemaining(st, oldName); err != nil {
+				return nil, errors.Trace(err)
+			} else if remaining {
+				return nil, errApplicationRenameIncomplete
+			}
+		}
+		if err := checkApplicationRenameable(st, oldName); err != nil {
+			return nil, errors.Trace(err)
+		}
+
+		// New units of the renamed application must not reuse
+		// the numbers of the units that were renamed.
+		if err := st.advanceSequence(
+			names.NewApplicationTag(newName).String(),
+			names.NewApplicationTag(oldName).String(),
+		); err != nil {
+			return nil, errors.Trace(err)
+		}
+		return renameApplicationOps(st, app, newName)
+	}
+	return st.run(buildTxn)
+}
+
+// applicationRenameRemaining reports whether any units or relations
+// of the named application remain to be renamed.
+func applicationRenameRemaining(st *State, appName string) (bool, error) {
+	units, closer := st.getCollection(unitsC)
+	defer closer()
+	if n, err := units.Find(bson.D{{"application", appName}}).Count(); err != nil {
+		return false, errors.Annotatef(err, "getting units of application %q", appName)
+	} else if n > 0 {
+		return true, nil
+	}
+
+	relations, closer := st.getCollection(relationsC)
+	defer closer()
+	if n, err := relations.Find(bson.D{{"endpoints.applicationname", appName}}).Count(); err != nil {
+		return false, errors.Annotatef(err, "getting relations of application %q", appName)
+	} else if n > 0 {
+		return true, nil
+	}
+	return false, nil
+}
+
+// renameApplicationOps returns the operations required to rename the
+// application, and the documents keyed on its name, to the given name,
+// and to record that it has been renamed.
+func renameApplicationOps(st *State, app *Application, newName string) ([]txn.Op, error) {
+	oldName := app.doc.Name
+	curl := app.doc.CharmURL
+	var ops []txn.Op
+	addMoveDocOps := func(collName, oldID, newID string, set bson.D, asserts bson.D) error {
+		moveOps, err := moveDocOps(st, collName, oldID, newID, set, asserts)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		ops = append(ops, moveOps...)
+		return nil
+	}
+
+	// The application document's txn-revno is asserted, so units
+	// and relations added since they were last renamed will cause
+	// the transaction to abort.
+	if err := addMoveDocOps(applicationsC, oldName, newName, bson.D{
+		{"name", newName},
+	}, isAliveDoc); err != nil {
+		return nil, errors.Trace(err)
+	}
+	for _, keys := range []struct {
+		collName     string
+		oldID, newID string
+	}{
+		{settingsC, applicationSettingsKey(oldName, curl), applicationSettingsKey(newName, curl)},
+		{settingsC, leadershipSettingsKey(oldName), leadershipSettingsKey(newName)},
+		{storageConstraintsC, applicationStorageConstraintsKey(oldName, curl), applicationStorageConstraintsKey(newName, curl)},
+		{refcountsC, applicationSettingsKey(oldName, curl), applicationSettingsKey(newName, curl)},
+		{refcountsC, applicationStorageConstraintsKey(oldName, curl), applicationStorageConstraintsKey(newName, curl)},
+		{statusesC, applicationGlobalKey(oldName), applicationGlobalKey(newName)},
+		{constraintsC, applicationGlobalKey(oldName), applicationGlobalKey(newName)},
+		{endpointBindingsC, applicationGlobalKey(oldName), applicationGlobalKey(newName)},
+	} {
+		if err := addMoveDocOps(keys.collName, keys.oldID, keys.newID, nil, nil); err != nil {
+			return nil, errors.Trace(err)
+		}
+	}
+	if err := addMoveDocOps(annotationsC, applicationGlobalKey(oldName), applicationGlobalKey(newName), bson.D{
+		{"globalkey", applicationGlobalKey(newName)},
+		{"tag", names.NewApplicationTag(newName).String()},
+	}, nil); err != nil {
+		return nil, errors.Trace(err)
+	}
+	if err := addMoveDocOps(minUnitsC, oldName, newName, bson.D{
+		{"applicationname", newName},
+	}, nil); err != nil {
+		return nil, errors.Trace(err)
+	}
+	if err := addMoveDocOps(scalesC, oldName, newName, bson.D{
+		{"applicationname", newName},
+	}, nil); err != nil {
+		return nil, errors.Trace(err)
+	}
+	if err := addMoveDocOps(exposureIntentsC, oldName, newName, bson.D{
+		{"application", newName},
+	}, nil); err != nil {
+		return nil, errors.Trace(err)
+	}
+	ops = append(ops,
+		removeModelServiceRefOp(st, oldName),
+		addModelServiceRefOp(st, newName),
+	)
+
+	offers, closer := st.getCollection(applicationOffersC)
+	defer closer()
+	var offerDocs []struct {
+		DocID string `bson:"_id"`
+	}
+	if err := offers.Find(bson.D{{"application", oldName}}).Select(bson.D{{"_id", 1}}).All(&offerDocs); err != nil {
+		return nil, errors.Annotatef(err, "getting offers of application %q", oldName)
+	}
+	for _, doc := range offerDocs {
+		ops = append(ops, txn.Op{
+			C:      applicationOffersC,
+			Id:     doc.DocID,
+			Assert: bson.D{{"application", oldName}},
+			Update: bson.D{{"$set", bson.D{{"application", newName}}}},
+		})
+	}
+
+	return append(ops, txn.Op{
+		C:      applicationRenamesC,
+		Id:     st.docID(oldName),
+		Assert: bson.D{{"new-name", newName}, {"renamed", false}},
+		Update: bson.D{{"$set", bson.D{{"renamed", true}}}},
+	}), nil
+}
+
+// moveApplicationRenameHistory moves the status history recorded in
+// the progress record of the rename of the named application to the
+// entities' new global keys. Status history is not written in
+// transactions, so it is moved once the application and its units have
+// been renamed, but before the progress record is removed; if moving
+// it fails, the rename can be resumed, and the history moved again.
+func (st *State) moveApplicationRenameHistory(oldName string) error {
+	rename, err := st.applicationRename(oldName)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	if !rename.Renamed {
+		return errors.Errorf("application %q has not been renamed", oldName)
+	}
+	history, closer := st.getCollection(statusesHistoryC)
+	defer closer()
+	historyW := history.Writeable().Underlying()
+	for _, keys := range rename.History {
+		if _, err := historyW.UpdateAll(bson.D{
+			{"model-uuid", st.ModelUUID()},
+			{"globalkey", keys.OldKey},
+		}, bson.D{
+			{"$set", bson.D{{"globalkey", keys.NewKey}}},
+		}); err != nil {
+			return errors.Annotatef(err, "moving status history from %q to %q", keys.OldKey, keys.NewKey)
+		}
+	}
+	return nil
+}
+
+// endApplicationRename removes the progress records of the rename of
+// the application, once it has been renamed and its status history
+// moved.
+func (st *State) endApplicationRename(oldName, newName string) error {
+	var ops []txn.Op
+	for _, name := range []string{oldName, newName} {
+		ops = append(ops, txn.Op{
+			C:  applicationRenamesC,
+			Id: st.docID(name),
+			Assert: bson.D{
+				{"old-name", oldName},
+				{"new-name", newName},
+			},
+			Remove: true,
+		})
+	}
+	ops[0].Assert = append(ops[0].Assert, bson.DocElem{"renamed", true})
+	return st.runTransaction(ops)
+}
+
+// moveDocOps returns the operations required to move the document with
+// the given ID in the named collection to the new ID, with the fields in
+// set replaced. The move is guarded by the document's txn-revno, and by
+// any additional asserts. If there is no such document, the operations
+// assert that there still isn't.
+func moveDocOps(st *State, collName, oldID, newID string, set, asserts bson.D) ([]txn.Op, error) {
+	coll, closer := st.getCollection(collName)
+	defer closer()
+
+	var doc bson.D
+	if err := coll.FindId(oldID).One(&doc); err == mgo.ErrNotFound {
+		return []txn.Op{{
+			C:      collName,
+			Id:     oldID,
+			Assert: txn.DocMissing,
+		}}, nil
+	} else if err != nil {
+		return nil, errors.Annotatef(err, "getting %s document %q", collName, oldID)
+	}
+
+	var txnRevno interface{}
+	newDoc := bson.D{{"_id", st.docID(newID)}}
+	for _, elem := range doc {
+		switch elem.Name {
+		case "_id", "txn-queue":
+			continue
+		case "txn-revno":
+			txnRevno = elem.Value
+			continue
+		}
+		for _, setElem := range set {
+			if setElem.Name == elem.Name {
+				elem.Value = setElem.Value
+				break
+			}
+		}
+		newDoc = append(newDoc, elem)
+	}
+	for _, setElem := range set {
+		found := false
+		for _, elem := range newDoc {
+			if elem.Name == setElem.Name {
+				found = true
+				break
+			}
+		}
+		if !found {
+			newDoc = append(newDoc, setElem)
+		}
+	}
+
+	return []txn.Op{{
+		C:      collName,
+		Id:     oldID,
+		Assert: append(bson.D{{"txn-revno", txnRevno}}, asserts...),
+		Remove: true,
+	}, {
+		C:      collName,
+		Id:     newID,
+		Assert: txn.DocMissing,
+		Insert: newDoc,
+	}}, nil
+}
+
+// replaceString returns a copy of values, with occurrences of
+// oldValue replaced with newValue.
+func replaceString(values []string, oldValue, newValue string) []string {
+	result := make([]string, len(values))
+	for i, value := range values {
+		if value == oldValue {
+			value = newValue
+		}
+		result[i] = value
+	}
+	return result
+}
@@ -0,0 +1,284 @@
+// Copyright 2016 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package state_test
+
+import (
+	"time"
+
+	"github.com/juju/errors"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+	"gopkg.in/juju/charm.v6-unstable"
+
+	"github.com/juju/juju/state"
+	"github.com/juju/juju/status"
+)
+
+type ApplicationRenameSuite struct {
+	ConnSuite
+	mysql *state.Application
+}
+
+var _ = gc.Suite(&ApplicationRenameSuite{})
+
+func (s *ApplicationRenameSuite) SetUpTest(c *gc.C) {
+	s.ConnSuite.SetUpTest(c)
+	s.mysql = s.AddTestingService(c, "mysql", s.AddTestingCharm(c, "mysql"))
+}
+
+func (s *ApplicationRenameSuite) addUnit(c *gc.C) *state.Unit {
+	unit, err := s.mysql.AddUnit()
+	c.Assert(err, jc.ErrorIsNil)
+	return unit
+}
+
+func (s *ApplicationRenameSuite) unitNames(c *gc.C, app *state.Application) []string {
+	units, err := app.AllUnits()
+	c.Assert(err, jc.ErrorIsNil)
+	names := make([]string, len(units))
+	for i, unit := range units {
+		names[i] = unit.Name()
+	}
+	return names
+}
+
+func (s *ApplicationRenameSuite) TestRenameApplication(c *gc.C) {
+	unit0 := s.addUnit(c)
+	s.addUnit(c)
+	err := unit0.AssignToNewMachine()
+	c.Assert(err, jc.ErrorIsNil)
+	machineId, err := unit0.AssignedMachineId()
+	c.Assert(err, jc.ErrorIsNil)
+	err = s.State.SetAnnotations(s.mysql, map[string]string{"app": "yes"})
+	c.Assert(err, jc.ErrorIsNil)
+	err = s.State.SetAnnotations(unit0, map[string]string{"unit": "yes"})
+	c.Assert(err, jc.ErrorIsNil)
+	err = s.mysql.SetMinUnits(1)
+	c.Assert(err, jc.ErrorIsNil)
+
+	s.AddTestingService(c, "wordpress", s.AddTestingCharm(c, "wordpress"))
+	eps, err := s.State.InferEndpoints("wordpress", "mysql")
+	c.Assert(err, jc.ErrorIsNil)
+	_, err = s.State.AddRelation(eps...)
+	c.Assert(err, jc.ErrorIsNil)
+
+	err = s.State.RenameApplication("mysql", "db")
+	c.Assert(err, jc.ErrorIsNil)
+
+	_, err = s.State.Application("mysql")
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+	_, err = s.State.Unit("mysql/0")
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+
+	app, err := s.State.Application("db")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(app.MinUnits(), gc.Equals, 1)
+	c.Assert(s.unitNames(c, app), jc.SameContents, []string{"db/0", "db/1"})
+	annotations, err := s.State.Annotations(app)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(annotations, jc.DeepEquals, map[string]string{"app": "yes"})
+
+	unit, err := s.State.Unit("db/0")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(unit.ApplicationName(), gc.Equals, "db")
+	_, err = unit.Status()
+	c.Assert(err, jc.ErrorIsNil)
+	annotations, err = s.State.Annotations(unit)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(annotations, jc.DeepEquals, map[string]string{"unit": "yes"})
+	assignedId, err := unit.AssignedMachineId()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(assignedId, gc.Equals, machineId)
+	machine, err := s.State.Machine(machineId)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(machine.Principals(), jc.DeepEquals, []string{"db/0"})
+
+	rels, err := app.Relations()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(rels, gc.HasLen, 1)
+	c.Assert(rels[0].String(), gc.Equals, "wordpress:db db:server")
+
+	// New units are numbered after the renamed units.
+	unit, err = app.AddUnit()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(unit.Name(), gc.Equals, "db/2")
+}
+
+func (s *ApplicationRenameSuite) TestRenameApplicationSettings(c *gc.C) {
+	ch := s.AddTestingCharm(c, "dummy")
+	app := s.AddTestingService(c, "dummy", ch)
+	err := app.UpdateConfigSettings(charm.Settings{"title": "renamed"})
+	c.Assert(err, jc.ErrorIsNil)
+
+	err = s.State.RenameApplication("dummy", "dummy2")
+	c.Assert(err, jc.ErrorIsNil)
+
+	app, err = s.State.Application("dummy2")
+	c.Assert(err, jc.ErrorIsNil)
+	settings, err := app.ConfigSettings()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(settings["title"], gc.Equals, "renamed")
+	refcount, err := state.ServiceSettingsRefCount(s.State, "dummy2", ch.URL())
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(refcount, gc.Equals, 1)
+	_, err = state.ServiceSettingsRefCount(s.State, "dummy", ch.URL())
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+}
+
+func (s *ApplicationRenameSuite) TestRenameApplicationInvalidName(c *gc.C) {
+	err := s.State.RenameApplication("mysql", "no/way")
+	c.Assert(err, gc.ErrorMatches, `cannot rename application "mysql" to "no/way": application name "no/way" not valid`)
+	c.Assert(err, jc.Satisfies, errors.IsNotValid)
+}
+
+func (s *ApplicationRenameSuite) TestRenameApplicationNotFound(c *gc.C) {
+	err := s.State.RenameApplication("missing", "db")
+	c.Assert(err, gc.ErrorMatches, `cannot rename application "missing" to "db": application "missing" not found`)
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+}
+
+func (s *ApplicationRenameSuite) TestRenameApplicationNameInUse(c *gc.C) {
+	s.AddTestingService(c, "db", s.AddTestingCharm(c, "mysql"))
+	err := s.State.RenameApplication("mysql", "db")
+	c.Assert(err, gc.ErrorMatches, `cannot rename application "mysql" to "db": application "db" already exists`)
+	c.Assert(err, jc.Satisfies, errors.IsAlreadyExists)
+}
+
+func (s *ApplicationRenameSuite) TestRenameApplicationNotAlive(c *gc.C) {
+	s.addUnit(c)
+	err := s.mysql.Destroy()
+	c.Assert(err, jc.ErrorIsNil)
+	err = s.State.RenameApplication("mysql", "db")
+	c.Assert(err, gc.ErrorMatches, `cannot rename application "mysql" to "db": application "mysql" is not alive`)
+}
+
+func (s *ApplicationRenameSuite) TestRenameApplicationDeployedUnit(c *gc.C) {
+	unit := s.addUnit(c)
+	err := unit.SetPassword("password1234567890123456")
+	c.Assert(err, jc.ErrorIsNil)
+	err = s.State.RenameApplication("mysql", "db")
+	c.Assert(err, gc.ErrorMatches, `cannot rename application "mysql" to "db": unit "mysql/0" has been deployed`)
+	_, err = s.State.Application("mysql")
+	c.Assert(err, jc.ErrorIsNil)
+}
+
+func (s *ApplicationRenameSuite) TestRenameApplicationInProgress(c *gc.C) {
+	s.addUnit(c)
+	err := state.BeginApplicationRename(s.State, "mysql", "db")
+	c.Assert(err, jc.ErrorIsNil)
+
+	// Neither name may be involved in another rename,
+	// and the application may not be destroyed.
+	err = s.State.RenameApplication("mysql", "sql")
+	c.Assert(err, gc.ErrorMatches, `cannot rename application "mysql" to "sql": application "mysql" is being renamed to "db"`)
+	s.AddTestingService(c, "pgsql", s.AddTestingCharm(c, "mysql"))
+	err = s.State.RenameApplication("pgsql", "db")
+	c.Assert(err, gc.ErrorMatches, `cannot rename application "pgsql" to "db": application "mysql" is being renamed to "db"`)
+	err = s.mysql.Destroy()
+	c.Assert(err, gc.ErrorMatches, `cannot destroy application "mysql": application is being renamed`)
+
+	// The rename can be resumed.
+	err = s.State.RenameApplication("mysql", "db")
+	c.Assert(err, jc.ErrorIsNil)
+	app, err := s.State.Application("db")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(s.unitNames(c, app), jc.DeepEquals, []string{"db/0"})
+	err = app.Destroy()
+	c.Assert(err, jc.ErrorIsNil)
+}
+
+func (s *ApplicationRenameSuite) TestRenameApplicationPendingAction(c *gc.C) {
+	app := s.AddTestingService(c, "dummy", s.AddTestingCharm(c, "dummy"))
+	unit, err := app.AddUnit()
+	c.Assert(err, jc.ErrorIsNil)
+	_, err = s.State.EnqueueAction(unit.Tag(), "snapshot", nil)
+	c.Assert(err, jc.ErrorIsNil)
+	err = s.State.RenameApplication("dummy", "dummy2")
+	c.Assert(err, gc.ErrorMatches, `cannot rename application "dummy" to "dummy2": renaming unit "dummy/0" with pending actions not supported`)
+	c.Assert(err, jc.Satisfies, errors.IsNotSupported)
+}
+
+func (s *ApplicationRenameSuite) TestRenameApplicationStatusHistory(c *gc.C) {
+	unit := s.addUnit(c)
+	now := time.Now()
+	err := unit.SetStatus(status.StatusInfo{
+		Status:  status.Waiting,
+		Message: "unit waiting",
+		Since:   &now,
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	err = s.mysql.SetStatus(status.StatusInfo{
+		Status:  status.Waiting,
+		Message: "application waiting",
+		Since:   &now,
+	})
+	c.Assert(err, jc.ErrorIsNil)
+
+	err = s.State.RenameApplication("mysql", "db")
+	c.Assert(err, jc.ErrorIsNil)
+
+	unit, err = s.State.Unit("db/0")
+	c.Assert(err, jc.ErrorIsNil)
+	history, err := unit.StatusHistory(status.StatusHistoryFilter{Size: 10})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(history, gc.Not(gc.HasLen), 0)
+	c.Assert(history[0].Message, gc.Equals, "unit waiting")
+
+	app, err := s.State.Application("db")
+	c.Assert(err, jc.ErrorIsNil)
+	history, err = app.StatusHistory(status.StatusHistoryFilter{Size: 10})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(history, gc.Not(gc.HasLen), 0)
+	c.Assert(history[0].Message, gc.Equals, "application waiting")
+}
+
+func (s *ApplicationRenameSuite) TestRenameApplicationResumesHistoryMove(c *gc.C) {
+	unit := s.addUnit(c)
+	now := time.Now()
+	err := unit.SetStatus(status.StatusInfo{
+		Status:  status.Waiting,
+		Message: "unit waiting",
+		Since:   &now,
+	})
+	c.Assert(err, jc.ErrorIsNil)
+
+	// Interrupt the rename once the application and its
+	// units have been renamed, but before their status
+	// history has been moved.
+	err = state.BeginApplicationRename(s.State, "mysql", "db")
+	c.Assert(err, jc.ErrorIsNil)
+	err = state.RenameApplicationEntities(s.State, "mysql", "db")
+	c.Assert(err, jc.ErrorIsNil)
+	app, err := s.State.Application("db")
+	c.Assert(err, jc.ErrorIsNil)
+	err = app.Destroy()
+	c.Assert(err, gc.ErrorMatches, `cannot destroy application "db": application is being renamed`)
+
+	err = s.State.RenameApplication("mysql", "db")
+	c.Assert(err, jc.ErrorIsNil)
+	unit, err = s.State.Unit("db/0")
+	c.Assert(err, jc.ErrorIsNil)
+	history, err := unit.StatusHistory(status.StatusHistoryFilter{Size: 10})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(history, gc.Not(gc.HasLen), 0)
+	c.Assert(history[0].Message, gc.Equals, "unit waiting")
+	err = app.Destroy()
+	c.Assert(err, jc.ErrorIsNil)
+}
+
+func (s *ApplicationRenameSuite) TestRenameApplicationUnitAddedConcurrently(c *gc.C) {
+	s.addUnit(c)
+	defer state.SetBeforeHooks(c, s.State, nil, nil, func() {
+		// Add a unit before the application document is renamed.
+		s.addUnit(c)
+	}).Check()
+
+	err := s.State.RenameApplication("mysql", "db")
+	c.Assert(err, jc.ErrorIsNil)
+	app, err := s.State.Application("db")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(s.unitNames(c, app), jc.SameContents, []string{"db/0", "db/1"})
+	_, err = s.State.Application("mysql")
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+}
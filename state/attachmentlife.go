@@ -0,0 +1,73 @@
+// Copyright 2016 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package state
+
+import (
+	"github.com/juju/errors"
+	"gopkg.in/juju/names.v2"
+	"gopkg.in/mgo.v2/bson"
+)
+
+// MachineAttachmentId identifies a volume or filesystem attachment by
+// the tags of the machine and of the attached volume or filesystem.
+type MachineAttachmentId struct {
+	Machine    names.MachineTag
+	Attachment names.Tag
+}
+
+// MachineAttachmentLives returns the life of each of the specified
+// volume and filesystem attachments. Attachments that do not exist are
+// omitted from the result. Regardless of the number of ids specified,
+// each of the attachment collections is queried at most once.
+func (st *State) MachineAttachmentLives(ids []MachineAttachmentId) (map[MachineAttachmentId]Life, error) {
+	volumeAttachments := make(map[string]MachineAttachmentId)
+	filesystemAttachments := make(map[string]MachineAttachmentId)
+	for _, id := range ids {
+		switch tag := id.Attachment.(type) {
+		case names.VolumeTag:
+			docID := st.docID(volumeAttachmentId(id.Machine.Id(), tag.Id()))
+			volumeAttachments[docID] = id
+		case names.FilesystemTag:
+			docID := st.docID(filesystemAttachmentId(id.Machine.Id(), tag.Id()))
+			filesystemAttachments[docID] = id
+		default:
+			return nil, errors.NotValidf("attachment tag %q", id.Attachment)
+		}
+	}
+	lives := make(map[MachineAttachmentId]Life)
+	if err := st.attachmentLives(volumeAttachmentsC, volumeAttachments, lives); err != nil {
+		return nil, errors.Annotate(err, "getting volume attachment lives")
+	}
+	if err := st.attachmentLives(filesystemAttachmentsC, filesystemAttachments, lives); err != nil {
+		return nil, errors.Annotate(err, "getting filesystem attachment lives")
+	}
+	return lives, nil
+}
+
+// attachmentLives records in lives the life of each of the attachment
+// documents in the named collection with the given doc IDs.
+func (st *State) attachmentLives(
+	collName string,
+	ids map[string]MachineAttachmentId,
+	lives map[MachineAttachmentId]Life,
+) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	docIDs := make([]string, 0, len(ids))
+	for docID := range ids {
+		docIDs = append(docIDs, docID)
+	}
+	coll, closer := st.getCollection(collName)
+	defer closer()
+
+	var doc lifeDoc
+	iter := coll.Find(bson.D{{"_id", bson.D{{"$in", docIDs}}}}).Select(lifeFields).Iter()
+	for iter.Next(&doc) {
+		if id, ok := ids[doc.Id]; ok {
+			lives[id] = doc.Life
+		}
+	}
+	return errors.Trace(iter.Close())
+}
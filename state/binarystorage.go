@@ -6,19 +6,40 @@ package state
 import (
 	"github.com/juju/errors"
 	jujutxn "github.com/juju/txn"
-	"gopkg.in/juju/blobstore.v2"
 
 	"github.com/juju/juju/mongo"
 	"github.com/juju/juju/state/binarystorage"
+	statestorage "github.com/juju/juju/state/storage"
 )
 
 var binarystorageNew = binarystorage.New
 
 // ToolsStorage returns a new binarystorage.StorageCloser that stores tools
-// metadata in the "juju" database "toolsmetadata" collection.
+// metadata in the "juju" database "toolsmetadata" collection. The contents
+// of tools are verified against their recorded size and hash as they are
+// read; see binarystorage.NewVerifyingStorage.
 func (st *State) ToolsStorage() (binarystorage.StorageCloser, error) {
+	storage, err := st.toolsStorage()
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	return &storageCloser{
+		binarystorage.NewVerifyingStorage(storage),
+		func() { storage.Close() },
+	}, nil
+}
+
+// ModelToolsStorage returns a new binarystorage.StorageCloser for the
+// model's own tools catalogue. Unlike ToolsStorage, the catalogue of a
+// hosted model is not combined with the controller's, so only binaries
+// stored in the hosted model itself are found.
+func (st *State) ModelToolsStorage() (binarystorage.StorageCloser, error) {
+	return st.newBinaryStorageCloser(toolsmetadataC, st.ModelUUID())
+}
+
+func (st *State) toolsStorage() (binarystorage.StorageCloser, error) {
 	if st.IsController() {
-		return st.newBinaryStorageCloser(toolsmetadataC, st.ModelUUID()), nil
+		return st.newBinaryStorageCloser(toolsmetadataC, st.ModelUUID())
 	}
 
 	// This is a hosted model. Hosted models have their own tools
@@ -33,12 +54,16 @@ func (st *State) ToolsStorage() (binarystorage.StorageCloser, error) {
 		return nil, errors.Trace(err)
 	}
 	defer controllerSt.Close()
-	controllerStorage, err := controllerSt.ToolsStorage()
+	controllerStorage, err := controllerSt.toolsStorage()
 	if err != nil {
 		return nil, errors.Trace(err)
 	}
 
-	modelStorage := st.newBinaryStorageCloser(toolsmetadataC, st.ModelUUID())
+	modelStorage, err := st.newBinaryStorageCloser(toolsmetadataC, st.ModelUUID())
+	if err != nil {
+		controllerStorage.Close()
+		return nil, errors.Trace(err)
+	}
 	storage, err := binarystorage.NewLayeredStorage(modelStorage, controllerStorage)
 	if err != nil {
 		modelStorage.Close()
@@ -58,10 +83,10 @@ func (st *State) GUIStorage() (binarystorage.StorageCloser, error) {
 	if err != nil {
 		return nil, errors.Trace(err)
 	}
-	return st.newBinaryStorageCloser(guimetadataC, controllerModel.UUID()), nil
+	return st.newBinaryStorageCloser(guimetadataC, controllerModel.UUID())
 }
 
-func (st *State) newBinaryStorageCloser(collectionName, uuid string) binarystorage.StorageCloser {
+func (st *State) newBinaryStorageCloser(collectionName, uuid string) (binarystorage.StorageCloser, error) {
 	db, closer1 := st.database.Copy()
 	metadataCollection, closer2 := db.GetCollection(collectionName)
 	txnRunner, closer3 := db.TransactionRunner()
@@ -70,15 +95,25 @@ func (st *State) newBinaryStorageCloser(collectionName, uuid string) binarystora
 		closer2()
 		closer1()
 	}
-	storage := newBinaryStorage(uuid, metadataCollection, txnRunner)
-	return &storageCloser{storage, closer}
+	storage, err := newBinaryStorage(uuid, metadataCollection, txnRunner)
+	if err != nil {
+		closer()
+		return nil, errors.Trace(err)
+	}
+	return &storageCloser{storage, closer}, nil
 }
 
-func newBinaryStorage(uuid string, metadataCollection mongo.Collection, txnRunner jujutxn.Runner) binarystorage.Storage {
+// newBinaryStorage returns a binarystorage.Storage that stores content
+// in the controller's blob backend. Binaries share the blobstore catalog
+// with other blobs, so their content must move with the backend.
+func newBinaryStorage(uuid string, metadataCollection mongo.Collection, txnRunner jujutxn.Runner) (binarystorage.Storage, error) {
 	db := metadataCollection.Writeable().Underlying().Database
-	rs := blobstore.NewGridFS(blobstoreDB, blobstoreDB, db.Session)
-	managedStorage := blobstore.NewManagedStorage(db, rs)
-	return binarystorageNew(uuid, managedStorage, metadataCollection, txnRunner)
+	rs, err := statestorage.NewResourceStorage(db.Session)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	managedStorage := statestorage.NewManagedStorage(db, rs)
+	return binarystorageNew(uuid, managedStorage, metadataCollection, txnRunner), nil
 }
 
 type storageCloser struct {
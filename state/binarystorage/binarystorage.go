@@ -68,6 +68,7 @@ func (s *binaryStorage) Add(r io.Reader, metadata Metadata) (resultErr error) {
 		Size:    metadata.Size,
 		SHA256:  metadata.SHA256,
 		Path:    path,
+		Stream:  metadata.Stream,
 	}
 
 	// Add or replace metadata. If replacing, record the existing path so we
@@ -99,8 +100,13 @@ func (s *binaryStorage) Add(r io.Reader, metadata Metadata) (resultErr error) {
 						{"size", metadata.Size},
 						{"sha256", metadata.SHA256},
 						{"path", path},
+						{"stream", metadata.Stream},
 					},
 				}}
+			} else if oldDoc.Stream != metadata.Stream {
+				op.Update = bson.D{{
+					"$set", bson.D{{"stream", metadata.Stream}},
+				}}
 			}
 		}
 		return []txn.Op{op}, nil
@@ -131,12 +137,7 @@ func (s *binaryStorage) Open(version string) (Metadata, io.ReadCloser, error) {
 	if err != nil {
 		return Metadata{}, nil, err
 	}
-	metadata := Metadata{
-		Version: metadataDoc.Version,
-		Size:    metadataDoc.Size,
-		SHA256:  metadataDoc.SHA256,
-	}
-	return metadata, r, nil
+	return metadataDoc.metadata(), r, nil
 }
 
 func (s *binaryStorage) Metadata(version string) (Metadata, error) {
@@ -144,11 +145,34 @@ func (s *binaryStorage) Metadata(version string) (Metadata, error) {
 	if err != nil {
 		return Metadata{}, err
 	}
-	return Metadata{
-		Version: metadataDoc.Version,
-		Size:    metadataDoc.Size,
-		SHA256:  metadataDoc.SHA256,
-	}, nil
+	return metadataDoc.metadata(), nil
+}
+
+// Remove implements Storage.Remove.
+func (s *binaryStorage) Remove(version string) error {
+	var path string
+	buildTxn := func(attempt int) ([]txn.Op, error) {
+		doc, err := s.findMetadata(version)
+		if err != nil {
+			return nil, err
+		}
+		path = doc.Path
+		return []txn.Op{{
+			C:      s.metadataCollection.Name(),
+			Id:     doc.Id,
+			Assert: bson.D{{"path", doc.Path}},
+			Remove: true,
+		}}, nil
+	}
+	if err := s.txnRunner.Run(buildTxn); err != nil {
+		return errors.Annotate(err, "cannot remove binary metadata")
+	}
+	// The metadata is gone, so failure to remove the
+	// blob is non-fatal.
+	if err := s.managedStorage.RemoveForBucket(s.modelUUID, path); err != nil {
+		logger.Errorf("failed to remove binary blob: %v", err)
+	}
+	return nil
 }
 
 func (s *binaryStorage) AllMetadata() ([]Metadata, error) {
@@ -158,21 +182,50 @@ func (s *binaryStorage) AllMetadata() ([]Metadata, error) {
 	}
 	list := make([]Metadata, len(docs))
 	for i, doc := range docs {
-		list[i] = Metadata{
-			Version: doc.Version,
-			Size:    doc.Size,
-			SHA256:  doc.SHA256,
-		}
+		list[i] = doc.metadata()
 	}
 	return list, nil
 }
 
+// SetStream implements Storage.SetStream.
+func (s *binaryStorage) SetStream(version, stream string) error {
+	buildTxn := func(attempt int) ([]txn.Op, error) {
+		doc, err := s.findMetadata(version)
+		if err != nil {
+			return nil, err
+		}
+		if doc.Stream == stream {
+			return nil, jujutxn.ErrNoOperations
+		}
+		return []txn.Op{{
+			C:      s.metadataCollection.Name(),
+			Id:     doc.Id,
+			Assert: bson.D{{"path", doc.Path}},
+			Update: bson.D{{"$set", bson.D{{"stream", stream}}}},
+		}}, nil
+	}
+	if err := s.txnRunner.Run(buildTxn); err != nil {
+		return errors.Annotate(err, "cannot set binary stream")
+	}
+	return nil
+}
+
 type metadataDoc struct {
 	Id      string `bson:"_id"`
 	Version string `bson:"version"`
 	Size    int64  `bson:"size"`
 	SHA256  string `bson:"sha256,omitempty"`
 	Path    string `bson:"path"`
+	Stream  string `bson:"stream,omitempty"`
+}
+
+func (doc metadataDoc) metadata() Metadata {
+	return Metadata{
+		Version: doc.Version,
+		Size:    doc.Size,
+		SHA256:  doc.SHA256,
+		Stream:  doc.Stream,
+	}
 }
 
 func (s *binaryStorage) findMetadata(version string) (metadataDoc, error) {
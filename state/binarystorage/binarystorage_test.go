@@ -174,6 +174,58 @@ func (s *binaryStorageSuite) TestOpen(c *gc.C) {
 	c.Assert(string(data), gc.Equals, "blah")
 }
 
+func (s *binaryStorageSuite) TestRemove(c *gc.C) {
+	err := s.storage.Remove(current)
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+
+	s.addMetadataDoc(c, current, 4, "hash(blah)", "path")
+	err = s.managedStorage.PutForBucket("my-uuid", "path", strings.NewReader("blah"), 4)
+	c.Assert(err, jc.ErrorIsNil)
+
+	err = s.storage.Remove(current)
+	c.Assert(err, jc.ErrorIsNil)
+	_, err = s.storage.Metadata(current)
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+	_, _, err = s.managedStorage.GetForBucket("my-uuid", "path")
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+}
+
+func (s *binaryStorageSuite) TestSetStream(c *gc.C) {
+	err := s.storage.SetStream(current, "released")
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+
+	addedMetadata := binarystorage.Metadata{
+		Version: current,
+		Size:    4,
+		SHA256:  "hash(blah)",
+		Stream:  "proposed",
+	}
+	err = s.storage.Add(strings.NewReader("blah"), addedMetadata)
+	c.Assert(err, jc.ErrorIsNil)
+
+	err = s.storage.SetStream(current, "released")
+	c.Assert(err, jc.ErrorIsNil)
+	// Setting the same stream again is a no-op.
+	err = s.storage.SetStream(current, "released")
+	c.Assert(err, jc.ErrorIsNil)
+
+	// The stream is updated without changing the content.
+	addedMetadata.Stream = "released"
+	s.assertMetadataAndContent(c, addedMetadata, "blah")
+}
+
+func (s *binaryStorageSuite) TestRemoveBlobRemoveFails(c *gc.C) {
+	// Failure to remove the blob is logged, and otherwise ignored.
+	managedStorage := removeFailsManagedStorage{s.managedStorage}
+	storage := binarystorage.New("my-uuid", managedStorage, s.metadataCollection, s.txnRunner)
+	s.addMetadataDoc(c, current, 4, "hash(blah)", "path")
+
+	err := storage.Remove(current)
+	c.Assert(err, jc.ErrorIsNil)
+	_, err = storage.Metadata(current)
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+}
+
 func (s *binaryStorageSuite) TestAddRemovesExisting(c *gc.C) {
 	// Add a metadata doc and a blob at a known path, then
 	// call Add and ensure the original blob is removed.
@@ -12,6 +12,11 @@ type Metadata struct {
 	Version string
 	Size    int64
 	SHA256  string
+
+	// Stream is the stream in which the binary file is published,
+	// e.g. "released" or "proposed". Binary files stored without a
+	// stream are not published in any particular stream.
+	Stream string
 }
 
 // Storage provides methods for storing and retrieving binary files by version.
@@ -31,6 +36,16 @@ type Storage interface {
 	// Metadata returns the Metadata for the specified version if it exists,
 	// else an error satisfying errors.IsNotFound.
 	Metadata(version string) (Metadata, error)
+
+	// Remove removes the binary file and metadata for the specified
+	// version if it exists, else returns an error satisfying
+	// errors.IsNotFound.
+	Remove(version string) error
+
+	// SetStream updates the stream in which the binary file with the
+	// specified version is published, without changing its contents,
+	// if it exists, else returns an error satisfying errors.IsNotFound.
+	SetStream(version, stream string) error
 }
 
 // StorageCloser extends the Storage interface with a Close method.
@@ -15,7 +15,7 @@ type layeredStorage []Storage
 // NewLayeredStorage wraps multiple Storages such all of their metadata
 // can be listed and fetched. The later entries in the list have lower
 // precedence than the earlier ones. The first entry in the list is always
-// used for mutating operations.
+// used for adding and removing binary files.
 func NewLayeredStorage(s ...Storage) (Storage, error) {
 	if len(s) <= 1 {
 		return nil, errors.Errorf("expected multiple stores")
@@ -30,6 +30,30 @@ func (s layeredStorage) Add(r io.Reader, m Metadata) error {
 	return s[0].Add(r, m)
 }
 
+// Remove implements Storage.Remove.
+//
+// This method operates on the first Storage passed to NewLayeredStorage.
+func (s layeredStorage) Remove(v string) error {
+	return s[0].Remove(v)
+}
+
+// SetStream implements Storage.SetStream.
+//
+// This method operates on the first Storage passed to NewLayeredStorage
+// that holds the binary file with the specified version, in the order
+// given; i.e. the one whose metadata Metadata and AllMetadata return.
+func (s layeredStorage) SetStream(v, stream string) error {
+	var err error
+	for _, s := range s {
+		if _, err = s.Metadata(v); err == nil {
+			return s.SetStream(v, stream)
+		} else if !errors.IsNotFound(err) {
+			break
+		}
+	}
+	return err
+}
+
 // Open implements Storage.Open.
 //
 // This method calls Open for each Storage passed to NewLayeredStorage in
@@ -64,6 +64,58 @@ func (s *layeredStorageSuite) TestAdd(c *gc.C) {
 	s.stores[1].CheckNoCalls(c)
 }
 
+func (s *layeredStorageSuite) TestRemove(c *gc.C) {
+	expectedErr := errors.New("wut")
+	s.stores[0].SetErrors(expectedErr)
+	err := s.store.Remove("4.0")
+	c.Assert(err, gc.Equals, expectedErr)
+	s.stores[0].CheckCalls(c, []testing.StubCall{{"Remove", []interface{}{"4.0"}}})
+	s.stores[1].CheckNoCalls(c)
+}
+
+func (s *layeredStorageSuite) TestSetStream(c *gc.C) {
+	expectedErr := errors.New("wut")
+	s.stores[0].SetErrors(nil, expectedErr)
+	err := s.store.SetStream("1.0", "released")
+	c.Assert(err, gc.Equals, expectedErr)
+	s.stores[0].CheckCalls(c, []testing.StubCall{
+		{"Metadata", []interface{}{"1.0"}},
+		{"SetStream", []interface{}{"1.0", "released"}},
+	})
+	s.stores[1].CheckNoCalls(c)
+}
+
+func (s *layeredStorageSuite) TestSetStreamLowerLayer(c *gc.C) {
+	s.stores[0].SetErrors(errors.NotFoundf("3.0"))
+	err := s.store.SetStream("3.0", "released")
+	c.Assert(err, jc.ErrorIsNil)
+	s.stores[0].CheckCalls(c, []testing.StubCall{
+		{"Metadata", []interface{}{"3.0"}},
+	})
+	s.stores[1].CheckCalls(c, []testing.StubCall{
+		{"Metadata", []interface{}{"3.0"}},
+		{"SetStream", []interface{}{"3.0", "released"}},
+	})
+}
+
+func (s *layeredStorageSuite) TestSetStreamNotFound(c *gc.C) {
+	s.stores[0].SetErrors(errors.NotFoundf("4.0"))
+	s.stores[1].SetErrors(errors.NotFoundf("4.0"))
+	err := s.store.SetStream("4.0", "released")
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+	s.stores[0].CheckCallNames(c, "Metadata")
+	s.stores[1].CheckCallNames(c, "Metadata")
+}
+
+func (s *layeredStorageSuite) TestSetStreamFatalError(c *gc.C) {
+	expectedErr := errors.New("wut")
+	s.stores[0].SetErrors(expectedErr)
+	err := s.store.SetStream("1.0", "released")
+	c.Assert(err, gc.Equals, expectedErr)
+	s.stores[0].CheckCallNames(c, "Metadata")
+	s.stores[1].CheckNoCalls(c)
+}
+
 func (s *layeredStorageSuite) TestAllMetadata(c *gc.C) {
 	all, err := s.store.AllMetadata()
 	c.Assert(err, jc.ErrorIsNil)
@@ -176,6 +228,16 @@ func (s *mockStorage) Metadata(version string) (binarystorage.Metadata, error) {
 	return s.metadata[0], s.NextErr()
 }
 
+func (s *mockStorage) Remove(version string) error {
+	s.MethodCall(s, "Remove", version)
+	return s.NextErr()
+}
+
+func (s *mockStorage) SetStream(version, stream string) error {
+	s.MethodCall(s, "SetStream", version, stream)
+	return s.NextErr()
+}
+
 func (s *mockStorage) Open(version string) (binarystorage.Metadata, io.ReadCloser, error) {
 	s.MethodCall(s, "Open", version)
 	return s.metadata[0], &s.rc, s.NextErr()
@@ -0,0 +1,109 @@
+// Copyright 2016 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package binarystorage
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"hash"
+	"io"
+
+	"github.com/juju/errors"
+)
+
+type verifyingStorage struct {
+	Storage
+}
+
+// NewVerifyingStorage wraps a Storage such that the contents of binary
+// files opened with Open are verified against their recorded size and
+// SHA256 hash as they are read. If the contents do not match, reading
+// fails with an error satisfying IsCorrupt once the mismatch is detected.
+// This protects against serving blobs that have been corrupted in the
+// underlying storage.
+func NewVerifyingStorage(s Storage) Storage {
+	return verifyingStorage{s}
+}
+
+// Open implements Storage.Open.
+func (s verifyingStorage) Open(v string) (Metadata, io.ReadCloser, error) {
+	m, rc, err := s.Storage.Open(v)
+	if err != nil {
+		return Metadata{}, nil, err
+	}
+	return m, &verifyingReader{
+		ReadCloser: rc,
+		metadata:   m,
+		hash:       sha256.New(),
+	}, nil
+}
+
+// verifyingReader is an io.ReadCloser that computes the size and SHA256
+// hash of the data read through it, and compares them with the expected
+// metadata.
+type verifyingReader struct {
+	io.ReadCloser
+	metadata Metadata
+	hash     hash.Hash
+	size     int64
+	err      error
+}
+
+// Read is part of the io.Reader interface.
+func (r *verifyingReader) Read(p []byte) (int, error) {
+	if r.err != nil {
+		return 0, r.err
+	}
+	n, err := r.ReadCloser.Read(p)
+	r.hash.Write(p[:n])
+	r.size += int64(n)
+	if r.size > r.metadata.Size {
+		r.err = r.corrupt("expected %d bytes, read more", r.metadata.Size)
+		return n, r.err
+	}
+	if err == io.EOF {
+		if r.size != r.metadata.Size {
+			r.err = r.corrupt("expected %d bytes, read %d", r.metadata.Size, r.size)
+			return n, r.err
+		}
+		// Binary files added before hashes were recorded
+		// have no hash to check against.
+		if r.metadata.SHA256 != "" {
+			sum := fmt.Sprintf("%x", r.hash.Sum(nil))
+			if sum != r.metadata.SHA256 {
+				r.err = r.corrupt("expected SHA256 %s, got %s", r.metadata.SHA256, sum)
+				return n, r.err
+			}
+		}
+	}
+	return n, err
+}
+
+func (r *verifyingReader) corrupt(format string, args ...interface{}) error {
+	return &corruptError{
+		version: r.metadata.Version,
+		reason:  fmt.Sprintf(format, args...),
+	}
+}
+
+// corruptError is returned when the contents of a binary file do not
+// match the file's recorded metadata.
+type corruptError struct {
+	version string
+	reason  string
+}
+
+// Error is part of the error interface.
+func (e *corruptError) Error() string {
+	return fmt.Sprintf("%s binary file is corrupt: %s", e.version, e.reason)
+}
+
+// IsCorrupt reports whether the error was caused by the contents of a
+// binary file not matching its recorded metadata. Callers that encounter
+// such an error should discard the stored file, and fetch it again from
+// its original source.
+func IsCorrupt(err error) bool {
+	_, ok := errors.Cause(err).(*corruptError)
+	return ok
+}
@@ -0,0 +1,109 @@
+// Copyright 2016 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package binarystorage_test
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"io/ioutil"
+	"strings"
+
+	"github.com/juju/errors"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/state/binarystorage"
+	coretesting "github.com/juju/juju/testing"
+)
+
+type verifyingStorageSuite struct {
+	coretesting.BaseSuite
+	storage *mockStorage
+	store   binarystorage.Storage
+}
+
+var _ = gc.Suite(&verifyingStorageSuite{})
+
+func (s *verifyingStorageSuite) SetUpTest(c *gc.C) {
+	s.BaseSuite.SetUpTest(c)
+	s.storage = &mockStorage{}
+	s.store = binarystorage.NewVerifyingStorage(s.storage)
+}
+
+func (s *verifyingStorageSuite) setContent(content string, m binarystorage.Metadata) {
+	s.storage.rc.ReadCloser = ioutil.NopCloser(strings.NewReader(content))
+	s.storage.metadata = []binarystorage.Metadata{m}
+}
+
+func sha256sum(content string) string {
+	return fmt.Sprintf("%x", sha256.Sum256([]byte(content)))
+}
+
+func (s *verifyingStorageSuite) TestOpen(c *gc.C) {
+	m := binarystorage.Metadata{Version: "1.0", Size: 5, SHA256: sha256sum("hello")}
+	s.setContent("hello", m)
+	metadata, rc, err := s.store.Open("1.0")
+	c.Assert(err, jc.ErrorIsNil)
+	defer rc.Close()
+	c.Assert(metadata, jc.DeepEquals, m)
+	data, err := ioutil.ReadAll(rc)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(string(data), gc.Equals, "hello")
+	s.storage.CheckCallNames(c, "Open")
+}
+
+func (s *verifyingStorageSuite) TestOpenNoHash(c *gc.C) {
+	s.setContent("hello", binarystorage.Metadata{Version: "1.0", Size: 5})
+	_, rc, err := s.store.Open("1.0")
+	c.Assert(err, jc.ErrorIsNil)
+	defer rc.Close()
+	data, err := ioutil.ReadAll(rc)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(string(data), gc.Equals, "hello")
+}
+
+func (s *verifyingStorageSuite) TestOpenHashMismatch(c *gc.C) {
+	s.setContent("jello", binarystorage.Metadata{Version: "1.0", Size: 5, SHA256: sha256sum("hello")})
+	_, rc, err := s.store.Open("1.0")
+	c.Assert(err, jc.ErrorIsNil)
+	defer rc.Close()
+	_, err = ioutil.ReadAll(rc)
+	c.Assert(err, jc.Satisfies, binarystorage.IsCorrupt)
+	c.Assert(err, gc.ErrorMatches, fmt.Sprintf(
+		"1.0 binary file is corrupt: expected SHA256 %s, got %s",
+		sha256sum("hello"), sha256sum("jello"),
+	))
+}
+
+func (s *verifyingStorageSuite) TestOpenTooShort(c *gc.C) {
+	s.setContent("hell", binarystorage.Metadata{Version: "1.0", Size: 5, SHA256: sha256sum("hello")})
+	_, rc, err := s.store.Open("1.0")
+	c.Assert(err, jc.ErrorIsNil)
+	defer rc.Close()
+	_, err = ioutil.ReadAll(rc)
+	c.Assert(err, jc.Satisfies, binarystorage.IsCorrupt)
+	c.Assert(err, gc.ErrorMatches, "1.0 binary file is corrupt: expected 5 bytes, read 4")
+}
+
+func (s *verifyingStorageSuite) TestOpenTooLong(c *gc.C) {
+	s.setContent("hello!", binarystorage.Metadata{Version: "1.0", Size: 5, SHA256: sha256sum("hello")})
+	_, rc, err := s.store.Open("1.0")
+	c.Assert(err, jc.ErrorIsNil)
+	defer rc.Close()
+	_, err = ioutil.ReadAll(rc)
+	c.Assert(err, jc.Satisfies, binarystorage.IsCorrupt)
+	c.Assert(err, gc.ErrorMatches, "1.0 binary file is corrupt: expected 5 bytes, read more")
+}
+
+func (s *verifyingStorageSuite) TestOpenError(c *gc.C) {
+	s.setContent("hello", binarystorage.Metadata{Version: "1.0", Size: 5})
+	s.storage.SetErrors(errors.NotFoundf("1.0 binary metadata"))
+	_, _, err := s.store.Open("1.0")
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+}
+
+func (s *verifyingStorageSuite) TestIsCorrupt(c *gc.C) {
+	c.Assert(binarystorage.IsCorrupt(errors.New("foo")), jc.IsFalse)
+	c.Assert(binarystorage.IsCorrupt(nil), jc.IsFalse)
+}
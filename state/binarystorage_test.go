@@ -197,3 +197,27 @@ func (s *binaryStorageSuite) TestToolsStorageLayered(c *gc.C) {
 	assertContents("1.0", "abc")
 	assertContents("2.0", "def")
 }
+
+func (s *binaryStorageSuite) TestModelToolsStorage(c *gc.C) {
+	modelTools, err := s.st.ModelToolsStorage()
+	c.Assert(err, jc.ErrorIsNil)
+	defer modelTools.Close()
+
+	controllerTools, err := s.State.ToolsStorage()
+	c.Assert(err, jc.ErrorIsNil)
+	defer controllerTools.Close()
+
+	err = modelTools.Add(strings.NewReader("abc"), binarystorage.Metadata{Version: "1.0", Size: 3})
+	c.Assert(err, jc.ErrorIsNil)
+	err = controllerTools.Add(strings.NewReader("def"), binarystorage.Metadata{Version: "2.0", Size: 3})
+	c.Assert(err, jc.ErrorIsNil)
+
+	// Binaries stored in the controller model
+	// are not found in the hosted model's own
+	// catalogue.
+	all, err := modelTools.AllMetadata()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(all, jc.DeepEquals, []binarystorage.Metadata{{Version: "1.0", Size: 3}})
+	_, err = modelTools.Metadata("2.0")
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+}
@@ -0,0 +1,55 @@
+// Copyright 2016 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package state
+
+import (
+	"github.com/juju/errors"
+
+	"github.com/juju/juju/state/storage"
+)
+
+// initBlobBackend records the backend, described by the given
+// attributes, that will hold the content of the controller's blobs.
+func (st *State) initBlobBackend(attrs map[string]string) error {
+	backend, err := storage.ParseBackendConfig(attrs)
+	if err != nil {
+		return errors.Annotate(err, "parsing blob backend")
+	}
+	// The backend may have been recorded by an earlier,
+	// failed attempt to initialize.
+	if err := storage.InitBackend(st.session, backend); err != nil && !errors.IsAlreadyExists(err) {
+		return errors.Trace(err)
+	}
+	return nil
+}
+
+// BlobBackend returns the configuration of the backend holding the
+// content of the controller's blobs, and of the backend they are being
+// migrated from, if any.
+func (st *State) BlobBackend() (storage.BackendConfig, *storage.BackendConfig, error) {
+	return storage.CurrentBackend(st.session)
+}
+
+// StartBlobBackendMigration makes the backend described by the given
+// attributes current, so that new blob content is stored there. The
+// content of existing blobs is moved to it in the background by
+// ResumeBlobBackendMigration; the controller remains usable while it
+// is moved. See storage.StartBackendMigration for more details.
+func (st *State) StartBlobBackendMigration(attrs map[string]string) error {
+	backend, err := storage.ParseBackendConfig(attrs)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	return errors.Trace(storage.StartBackendMigration(st.session, backend))
+}
+
+// ResumeBlobBackendMigration moves the content of the controller's
+// blobs to the current backend, if a migration has been started, and
+// returns the number of blobs moved. If abort is closed before the
+// migration completes, storage.ErrBackendMigrationAborted is returned.
+// See storage.ResumeBackendMigration for more details.
+func (st *State) ResumeBlobBackendMigration(abort <-chan struct{}) (int, error) {
+	moved, err := storage.ResumeBackendMigration(st.session, st.clock, abort)
+	return moved, errors.Trace(err)
+}
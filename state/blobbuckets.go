@@ -0,0 +1,82 @@
+// Copyright 2016 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package state
+
+import (
+	"sort"
+
+	"github.com/juju/errors"
+	"gopkg.in/juju/charm.v6-unstable"
+
+	"github.com/juju/juju/state/storage"
+)
+
+// SetBucketPolicy sets the lifecycle policy of the given bucket, which
+// applies to the blobs of all of the controller's models.
+func (st *State) SetBucketPolicy(bucket storage.Bucket, policy storage.BucketPolicy) error {
+	return errors.Trace(storage.SetBucketPolicy(st.session, bucket, policy))
+}
+
+// BucketPolicies returns the lifecycle policies of the buckets that
+// have them.
+func (st *State) BucketPolicies() (map[storage.Bucket]storage.BucketPolicy, error) {
+	policies, err := storage.BucketPolicies(st.session)
+	return policies, errors.Trace(err)
+}
+
+// ListBlobs returns the blobs stored for the model, ordered by path.
+func (st *State) ListBlobs() ([]storage.Blob, error) {
+	blobs, err := storage.ListBlobs(st.ModelUUID(), st.session)
+	return blobs, errors.Trace(err)
+}
+
+// RemoveExcessCharmRevisions removes the archives of the model's local
+// charms that are not in use and are not among the keepLatest most
+// recent revisions of their name, returning the URLs of the charms
+// removed. Charms from the charm store are never removed, as there is
+// no way to reinstate them.
+func (st *State) RemoveExcessCharmRevisions(keepLatest int) ([]*charm.URL, error) {
+	if keepLatest < 1 {
+		return nil, errors.NotValidf("keeping %d latest charm revisions", keepLatest)
+	}
+	charms, err := st.AllCharms()
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	byName := make(map[string][]*Charm)
+	for _, ch := range charms {
+		if ch.URL().Schema != "local" || !ch.IsUploaded() || ch.IsPlaceholder() {
+			continue
+		}
+		name := ch.URL().WithRevision(-1).String()
+		byName[name] = append(byName[name], ch)
+	}
+
+	var removed []*charm.URL
+	for _, revisions := range byName {
+		sort.Sort(byRevisionDescending(revisions))
+		if len(revisions) <= keepLatest {
+			continue
+		}
+		for _, ch := range revisions[keepLatest:] {
+			if err := ch.Destroy(); errors.Cause(err) == errCharmInUse {
+				continue
+			} else if err != nil {
+				return removed, errors.Annotatef(err, "destroying charm %s", ch.URL())
+			}
+			if err := ch.Remove(); err != nil {
+				return removed, errors.Annotatef(err, "removing charm %s", ch.URL())
+			}
+			logger.Infof("removed unused charm %s", ch.URL())
+			removed = append(removed, ch.URL())
+		}
+	}
+	return removed, nil
+}
+
+type byRevisionDescending []*Charm
+
+func (s byRevisionDescending) Len() int           { return len(s) }
+func (s byRevisionDescending) Swap(i, j int)      { s[i], s[j] = s[j], s[i] }
+func (s byRevisionDescending) Less(i, j int) bool { return s[i].Revision() > s[j].Revision() }
@@ -0,0 +1,142 @@
+// Copyright 2016 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package state_test
+
+import (
+	"strings"
+	"time"
+
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+	"gopkg.in/juju/charm.v6-unstable"
+
+	"github.com/juju/juju/state"
+	"github.com/juju/juju/state/storage"
+	"github.com/juju/juju/testcharms"
+	"github.com/juju/juju/testing/factory"
+)
+
+type BlobBucketsSuite struct {
+	ConnSuite
+}
+
+var _ = gc.Suite(&BlobBucketsSuite{})
+
+func (s *BlobBucketsSuite) TestBucketPolicies(c *gc.C) {
+	policies, err := s.State.BucketPolicies()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(policies, gc.HasLen, 0)
+
+	err = s.State.SetBucketPolicy(storage.BucketBackups, storage.BucketPolicy{
+		MaxAge:      24 * time.Hour,
+		MaxVersions: 7,
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	err = s.State.SetBucketPolicy(storage.BucketCharms, storage.BucketPolicy{MaxVersions: 2})
+	c.Assert(err, jc.ErrorIsNil)
+
+	policies, err = s.State.BucketPolicies()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(policies, jc.DeepEquals, map[storage.Bucket]storage.BucketPolicy{
+		storage.BucketBackups: {MaxAge: 24 * time.Hour, MaxVersions: 7},
+		storage.BucketCharms:  {MaxVersions: 2},
+	})
+
+	err = s.State.SetBucketPolicy(storage.BucketCharms, storage.BucketPolicy{})
+	c.Assert(err, jc.ErrorIsNil)
+	policies, err = s.State.BucketPolicies()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(policies, jc.DeepEquals, map[storage.Bucket]storage.BucketPolicy{
+		storage.BucketBackups: {MaxAge: 24 * time.Hour, MaxVersions: 7},
+	})
+}
+
+func (s *BlobBucketsSuite) TestSetBucketPolicyNotSupported(c *gc.C) {
+	err := s.State.SetBucketPolicy(storage.BucketCharms, storage.BucketPolicy{MaxAge: time.Hour})
+	c.Assert(err, gc.ErrorMatches, "max age for charms bucket not supported")
+	err = s.State.SetBucketPolicy(storage.BucketResources, storage.BucketPolicy{MaxVersions: 1})
+	c.Assert(err, gc.ErrorMatches, "max versions for resources bucket not supported")
+	err = s.State.SetBucketPolicy("floppies", storage.BucketPolicy{MaxVersions: 1})
+	c.Assert(err, gc.ErrorMatches, `bucket "floppies" not valid`)
+}
+
+func (s *BlobBucketsSuite) TestListBlobs(c *gc.C) {
+	stor := storage.NewStorage(s.State.ModelUUID(), s.State.MongoSession())
+	err := stor.Put("charms/local:quantal/dummy-1", strings.NewReader("abc"), 3)
+	c.Assert(err, jc.ErrorIsNil)
+	err = stor.Put("elsewhere/blob", strings.NewReader("defg"), 4)
+	c.Assert(err, jc.ErrorIsNil)
+
+	blobs, err := s.State.ListBlobs()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(blobs, jc.DeepEquals, []storage.Blob{{
+		Path:   "charms/local:quantal/dummy-1",
+		Bucket: storage.BucketCharms,
+		Length: 3,
+	}, {
+		Path:   "elsewhere/blob",
+		Length: 4,
+	}})
+	c.Assert(storage.Usage(blobs), jc.DeepEquals, map[storage.Bucket]storage.BucketUsage{
+		storage.BucketCharms: {Blobs: 1, Length: 3},
+	})
+}
+
+func (s *BlobBucketsSuite) TestRemoveExcessCharmRevisions(c *gc.C) {
+	inUse := s.addLocalCharm(c, 1)
+	s.Factory.MakeApplication(c, &factory.ApplicationParams{Charm: inUse})
+	s.addLocalCharm(c, 2)
+	s.addLocalCharm(c, 3)
+	s.addLocalCharm(c, 4)
+
+	removed, err := s.State.RemoveExcessCharmRevisions(2)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(removed, jc.DeepEquals, []*charm.URL{
+		charm.MustParseURL("local:quantal/dummy-2"),
+	})
+
+	charms, err := s.State.AllCharms()
+	c.Assert(err, jc.ErrorIsNil)
+	var remaining []string
+	for _, ch := range charms {
+		remaining = append(remaining, ch.URL().String())
+	}
+	c.Assert(remaining, jc.SameContents, []string{
+		"local:quantal/dummy-1",
+		"local:quantal/dummy-3",
+		"local:quantal/dummy-4",
+	})
+}
+
+func (s *BlobBucketsSuite) TestRemoveExcessCharmRevisionsKeepsStoreCharms(c *gc.C) {
+	for _, url := range []string{"cs:quantal/dummy-1", "cs:quantal/dummy-2"} {
+		_, err := s.State.AddCharm(state.CharmInfo{
+			Charm:       testcharms.Repo.CharmDir("dummy"),
+			ID:          charm.MustParseURL(url),
+			StoragePath: url,
+			SHA256:      url + "-sha256",
+		})
+		c.Assert(err, jc.ErrorIsNil)
+	}
+	removed, err := s.State.RemoveExcessCharmRevisions(1)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(removed, gc.HasLen, 0)
+}
+
+func (s *BlobBucketsSuite) TestRemoveExcessCharmRevisionsInvalid(c *gc.C) {
+	_, err := s.State.RemoveExcessCharmRevisions(0)
+	c.Assert(err, gc.ErrorMatches, "keeping 0 latest charm revisions not valid")
+}
+
+func (s *BlobBucketsSuite) addLocalCharm(c *gc.C, revision int) *state.Charm {
+	curl := charm.MustParseURL("local:quantal/dummy").WithRevision(revision)
+	ch, err := s.State.AddCharm(state.CharmInfo{
+		Charm:       testcharms.Repo.CharmDir("dummy"),
+		ID:          curl,
+		StoragePath: "charms/" + curl.String(),
+		SHA256:      curl.String() + "-sha256",
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	return ch
+}
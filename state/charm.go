@@ -86,15 +86,57 @@ type charmDoc struct {
 	Config  *charm.Config  `bson:"config"`
 	Actions *charm.Actions `bson:"actions"`
 	Metrics *charm.Metrics `bson:"metrics"`
+
+	// ScanFindings holds the findings of the scans
+	// run over the charm's archive when it was uploaded.
+	ScanFindings []charmScanFindingDoc `bson:"scanfindings,omitempty"`
+}
+
+// charmScanFindingDoc records a CharmScanFinding.
+type charmScanFindingDoc struct {
+	Scanner  string `bson:"scanner"`
+	Severity string `bson:"severity"`
+	Path     string `bson:"path,omitempty"`
+	Message  string `bson:"message"`
+}
+
+// CharmScanFinding describes a finding of a scan run over
+// a charm's archive when it was uploaded.
+type CharmScanFinding struct {
+	// Scanner is the name of the scanner that
+	// reported the finding.
+	Scanner string
+
+	// Severity describes how serious the finding is.
+	Severity string
+
+	// Path is the path of the archive entry that the
+	// finding concerns, if any.
+	Path string
+
+	// Message describes the finding.
+	Message string
+}
+
+func newCharmScanFindingDocs(findings []CharmScanFinding) []charmScanFindingDoc {
+	if len(findings) == 0 {
+		return nil
+	}
+	docs := make([]charmScanFindingDoc, len(findings))
+	for i, f := range findings {
+		docs[i] = charmScanFindingDoc(f)
+	}
+	return docs
 }
 
 // CharmInfo contains all the data necessary to store a charm's metadata.
 type CharmInfo struct {
-	Charm       charm.Charm
-	ID          *charm.URL
-	StoragePath string
-	SHA256      string
-	Macaroon    macaroon.Slice
+	Charm        charm.Charm
+	ID           *charm.URL
+	StoragePath  string
+	SHA256       string
+	Macaroon     macaroon.Slice
+	ScanFindings []CharmScanFinding
 }
 
 // insertCharmOps returns the txn operations necessary to insert the supplied
@@ -113,6 +155,7 @@ func insertCharmOps(st *State, info CharmInfo) ([]txn.Op, error) {
 		Actions:      info.Charm.Actions(),
 		BundleSha256: info.SHA256,
 		StoragePath:  info.StoragePath,
+		ScanFindings: newCharmScanFindingDocs(info.ScanFindings),
 	}
 	if info.Macaroon != nil {
 		mac, err := info.Macaroon.MarshalBinary()
@@ -219,6 +262,7 @@ func updateCharmOps(
 		{"bundlesha256", info.SHA256},
 		{"pendingupload", false},
 		{"placeholder", false},
+		{"scanfindings", newCharmScanFindingDocs(info.ScanFindings)},
 	}
 	if len(info.Macaroon) > 0 {
 		mac, err := info.Macaroon.MarshalBinary()
@@ -466,6 +510,19 @@ func (c *Charm) BundleSha256() string {
 	return c.doc.BundleSha256
 }
 
+// ScanFindings returns the findings of the scans run over the
+// charm's archive when it was uploaded.
+func (c *Charm) ScanFindings() []CharmScanFinding {
+	if len(c.doc.ScanFindings) == 0 {
+		return nil
+	}
+	findings := make([]CharmScanFinding, len(c.doc.ScanFindings))
+	for i, doc := range c.doc.ScanFindings {
+		findings[i] = CharmScanFinding(doc)
+	}
+	return findings
+}
+
 // IsUploaded returns whether the charm has been uploaded to the
 // model storage.
 func (c *Charm) IsUploaded() bool {
@@ -495,11 +552,12 @@ func (c *Charm) Macaroon() (macaroon.Slice, error) {
 // UpdateMacaroon updates the stored macaroon for this charm.
 func (c *Charm) UpdateMacaroon(m macaroon.Slice) error {
 	info := CharmInfo{
-		Charm:       c,
-		ID:          c.URL(),
-		StoragePath: c.StoragePath(),
-		SHA256:      c.BundleSha256(),
-		Macaroon:    m,
+		Charm:        c,
+		ID:           c.URL(),
+		StoragePath:  c.StoragePath(),
+		SHA256:       c.BundleSha256(),
+		Macaroon:     m,
+		ScanFindings: c.ScanFindings(),
 	}
 	ops, err := updateCharmOps(c.st, info, nil)
 	if err != nil {
@@ -465,6 +465,31 @@ func (s *CharmSuite) TestUpdateUploadedCharm(c *gc.C) {
 	c.Assert(ms, gc.DeepEquals, info.Macaroon)
 }
 
+func (s *CharmSuite) TestUpdateUploadedCharmScanFindings(c *gc.C) {
+	info := s.dummyCharm(c, "")
+	_, err := s.State.PrepareLocalCharmUpload(info.ID)
+	c.Assert(err, jc.ErrorIsNil)
+
+	info.ScanFindings = []state.CharmScanFinding{{
+		Scanner:  "metadata",
+		Severity: "warning",
+		Path:     "metadata.yaml",
+		Message:  "no supported series declared",
+	}}
+	sch, err := s.State.UpdateUploadedCharm(info)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(sch.ScanFindings(), jc.DeepEquals, info.ScanFindings)
+
+	// The findings are retained when the macaroon is updated.
+	m, err := macaroon.New([]byte("rootkey"), "id", "loc")
+	c.Assert(err, jc.ErrorIsNil)
+	err = sch.UpdateMacaroon(macaroon.Slice{m})
+	c.Assert(err, jc.ErrorIsNil)
+	sch, err = s.State.Charm(info.ID)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(sch.ScanFindings(), jc.DeepEquals, info.ScanFindings)
+}
+
 func (s *CharmSuite) TestUpdateUploadedCharmEscapesSpecialCharsInConfig(c *gc.C) {
 	// Make sure when we have mongodb special characters like "$" and
 	// "." in the name of any charm config option, we do proper
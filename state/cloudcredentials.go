@@ -15,6 +15,7 @@ import (
 	"gopkg.in/mgo.v2/txn"
 
 	"github.com/juju/juju/cloud"
+	"github.com/juju/juju/status"
 )
 
 // cloudCredentialDoc records information about a user's cloud credentials.
@@ -26,6 +27,11 @@ type cloudCredentialDoc struct {
 	Revoked    bool              `bson:"revoked"`
 	AuthType   string            `bson:"auth-type"`
 	Attributes map[string]string `bson:"attributes,omitempty"`
+
+	// Invalid is true if the cloud has rejected the credential.
+	// InvalidReason records why.
+	Invalid       bool   `bson:"invalid,omitempty"`
+	InvalidReason string `bson:"invalid-reason,omitempty"`
 }
 
 // CloudCredential returns the cloud credential for the given tag.
@@ -102,6 +108,94 @@ func (st *State) UpdateCloudCredential(tag names.CloudCredentialTag, credential
 	if err := st.run(buildTxn); err != nil {
 		return errors.Annotate(err, "updating cloud credentials")
 	}
+	// Updating a credential makes it valid again, so the models
+	// that were suspended because it was rejected may resume.
+	if err := st.resumeSuspendedModels(tag); err != nil {
+		return errors.Annotate(err, "resuming models")
+	}
+	return nil
+}
+
+// InvalidateModelCredential records that the cloud has rejected the
+// model's cloud credential for the given reason, e.g. because it has
+// expired, and suspends the model. The model's status tells the user
+// how to resume it, which happens when the credential is next updated.
+func (st *State) InvalidateModelCredential(reason string) error {
+	m, err := st.Model()
+	if err != nil {
+		return errors.Trace(err)
+	}
+	tag, ok := m.CloudCredential()
+	if !ok {
+		return errors.NotFoundf("cloud credential for model %q", m.Name())
+	}
+	if err := st.InvalidateCloudCredential(tag, reason); err != nil {
+		return errors.Trace(err)
+	}
+	now := st.clock.Now()
+	message := fmt.Sprintf(
+		"suspended since cloud credential %q is not valid: %s; "+
+			"update it with juju update-credential to resume",
+		tag.Name(), reason,
+	)
+	if err := m.SetStatus(status.StatusInfo{
+		Status:  status.Suspended,
+		Message: message,
+		Since:   &now,
+	}); err != nil {
+		return errors.Annotate(err, "suspending model")
+	}
+	return nil
+}
+
+// InvalidateCloudCredential records that the cloud has rejected the
+// cloud credential with the given tag, for the given reason. The
+// credential remains invalid until it is updated.
+func (st *State) InvalidateCloudCredential(tag names.CloudCredentialTag, reason string) error {
+	ops := []txn.Op{{
+		C:      cloudCredentialsC,
+		Id:     cloudCredentialDocID(tag),
+		Assert: txn.DocExists,
+		Update: bson.D{{"$set", bson.D{
+			{"invalid", true},
+			{"invalid-reason", reason},
+		}}},
+	}}
+	if err := st.runTransaction(ops); err == txn.ErrAborted {
+		return errors.NotFoundf("cloud credential %q", tag.Id())
+	} else if err != nil {
+		return errors.Annotatef(err, "invalidating cloud credential %q", tag.Id())
+	}
+	return nil
+}
+
+// resumeSuspendedModels sets the status of the suspended models that
+// use the cloud credential with the given tag to available.
+func (st *State) resumeSuspendedModels(tag names.CloudCredentialTag) error {
+	coll, closer := st.getCollection(modelsC)
+	defer closer()
+
+	var docs []modelDoc
+	if err := coll.Find(bson.D{{"cloud-credential", tag.Id()}}).All(&docs); err != nil {
+		return errors.Trace(err)
+	}
+	for _, doc := range docs {
+		m := &Model{st: st, doc: doc}
+		info, err := m.Status()
+		if err != nil {
+			return errors.Trace(err)
+		}
+		if info.Status != status.Suspended {
+			continue
+		}
+		now := st.clock.Now()
+		if err := m.SetStatus(status.StatusInfo{
+			Status: status.Available,
+			Since:  &now,
+		}); err != nil {
+			return errors.Trace(err)
+		}
+	}
 	return nil
 }
 
@@ -152,6 +246,8 @@ func updateCloudCredentialOp(tag names.CloudCredentialTag, cred cloud.Credential
 			{"auth-type", string(cred.AuthType())},
 			{"attributes", cred.Attributes()},
 			{"revoked", cred.Revoked},
+			{"invalid", false},
+			{"invalid-reason", ""},
 		}}},
 	}
 }
@@ -183,6 +279,8 @@ func (c cloudCredentialDoc) cloudCredentialTag() (names.CloudCredentialTag, erro
 func (c cloudCredentialDoc) toCredential() cloud.Credential {
 	out := cloud.NewCredential(cloud.AuthType(c.AuthType), c.Attributes)
 	out.Revoked = c.Revoked
+	out.Invalid = c.Invalid
+	out.InvalidReason = c.InvalidReason
 	out.Label = c.Name
 	return out
 }
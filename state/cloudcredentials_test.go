@@ -160,6 +160,40 @@ func (s *CloudCredentialsSuite) TestRemoveCredentials(c *gc.C) {
 	c.Assert(err, jc.Satisfies, errors.IsNotFound)
 }
 
+func (s *CloudCredentialsSuite) TestInvalidateCloudCredential(c *gc.C) {
+	err := s.State.AddCloud("stratus", cloud.Cloud{
+		Type:      "low",
+		AuthTypes: cloud.AuthTypes{cloud.AccessKeyAuthType},
+	})
+	c.Assert(err, jc.ErrorIsNil)
+
+	tag := names.NewCloudCredentialTag("stratus/bob@local/bobcred1")
+	err = s.State.InvalidateCloudCredential(tag, "expired")
+	c.Assert(err, gc.ErrorMatches, `cloud credential "stratus/bob@local/bobcred1" not found`)
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+
+	cred := cloud.NewCredential(cloud.AccessKeyAuthType, map[string]string{
+		"foo": "foo val",
+	})
+	err = s.State.UpdateCloudCredential(tag, cred)
+	c.Assert(err, jc.ErrorIsNil)
+	err = s.State.InvalidateCloudCredential(tag, "expired")
+	c.Assert(err, jc.ErrorIsNil)
+
+	out, err := s.State.CloudCredential(tag)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(out.Invalid, jc.IsTrue)
+	c.Assert(out.InvalidReason, gc.Equals, "expired")
+
+	// Updating the credential makes it valid again.
+	err = s.State.UpdateCloudCredential(tag, cred)
+	c.Assert(err, jc.ErrorIsNil)
+	out, err = s.State.CloudCredential(tag)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(out.Invalid, jc.IsFalse)
+	c.Assert(out.InvalidReason, gc.Equals, "")
+}
+
 func (s *CloudCredentialsSuite) createCredentialWatcher(c *gc.C, st *state.State, cred names.CloudCredentialTag) (
 	state.NotifyWatcher, statetesting.NotifyWatcherC,
 ) {
@@ -89,6 +89,23 @@ func (s *storage) SaveMetadata(metadata []Metadata) error {
 
 // DeleteMetadata implements Storage.DeleteMetadata.
 func (s *storage) DeleteMetadata(imageId string) error {
+	return s.deleteMetadata(imageId, bson.D{{"image_id", imageId}})
+}
+
+// DeleteModelMetadata implements Storage.DeleteModelMetadata.
+func (s *storage) DeleteModelMetadata(modelUUID, imageId string) error {
+	if modelUUID == "" {
+		return errors.NotValidf("empty model UUID")
+	}
+	return s.deleteMetadata(imageId, bson.D{
+		{"image_id", imageId},
+		{"model_uuid", modelUUID},
+	})
+}
+
+// deleteMetadata deletes the metadata for the given image
+// that matches the given query.
+func (s *storage) deleteMetadata(imageId string, query bson.D) error {
 	deleteOperation := func(docId string) txn.Op {
 		logger.Debugf("deleting metadata (ID=%v) for image (ID=%v)", docId, imageId)
 		return txn.Op{
@@ -106,7 +123,7 @@ func (s *storage) DeleteMetadata(imageId string) error {
 
 	buildTxn := func(attempt int) ([]txn.Op, error) {
 		// find all metadata docs with given image id
-		imageMetadata, err := s.metadataForImageId(imageId)
+		imageMetadata, err := s.findMetadataDocs(query)
 		if err != nil {
 			if err == mgo.ErrNotFound {
 				return noOp()
@@ -131,12 +148,11 @@ func (s *storage) DeleteMetadata(imageId string) error {
 	return nil
 }
 
-func (s *storage) metadataForImageId(imageId string) ([]imagesMetadataDoc, error) {
+func (s *storage) findMetadataDocs(query bson.D) ([]imagesMetadataDoc, error) {
 	coll, closer := s.store.GetCollection(s.collection)
 	defer closer()
 
 	var docs []imagesMetadataDoc
-	query := bson.D{{"image_id", imageId}}
 	if err := coll.Find(query).All(&docs); err != nil {
 		return nil, err
 	}
@@ -220,6 +236,10 @@ type imagesMetadataDoc struct {
 	// Higher number means higher priority.
 	// This will allow to sort metadata by importance.
 	Priority int `bson:"priority"`
+
+	// ModelUUID is the UUID of the model to which the image
+	// metadata is specific, or empty if it is shared.
+	ModelUUID string `bson:"model_uuid,omitempty"`
 }
 
 func (m imagesMetadataDoc) metadata() Metadata {
@@ -233,6 +253,7 @@ func (m imagesMetadataDoc) metadata() Metadata {
 			Arch:            m.Arch,
 			RootStorageType: m.RootStorageType,
 			VirtType:        m.VirtType,
+			ModelUUID:       m.ModelUUID,
 		},
 		m.Priority,
 		m.ImageId,
@@ -263,6 +284,7 @@ func (s *storage) mongoDoc(m Metadata) imagesMetadataDoc {
 		DateCreated:     dateCreated,
 		Source:          m.Source,
 		Priority:        m.Priority,
+		ModelUUID:       m.ModelUUID,
 	}
 	if m.RootStorageSize != nil {
 		r.RootStorageSize = *m.RootStorageSize
@@ -271,7 +293,7 @@ func (s *storage) mongoDoc(m Metadata) imagesMetadataDoc {
 }
 
 func buildKey(m Metadata) string {
-	return fmt.Sprintf("%s:%s:%s:%s:%s:%s:%s",
+	key := fmt.Sprintf("%s:%s:%s:%s:%s:%s:%s",
 		m.Stream,
 		m.Region,
 		m.Series,
@@ -279,6 +301,13 @@ func buildKey(m Metadata) string {
 		m.VirtType,
 		m.RootStorageType,
 		m.Source)
+	if m.ModelUUID != "" {
+		// Model-specific metadata is keyed separately, so it
+		// may coexist with shared metadata for the same image
+		// attributes.
+		key += ":" + m.ModelUUID
+	}
+	return key
 }
 
 func validateMetadata(m *imagesMetadataDoc) error {
@@ -358,6 +387,11 @@ func buildSearchClauses(criteria MetadataFilter) bson.D {
 		all = append(all, bson.DocElem{"root_storage_type", criteria.RootStorageType})
 	}
 
+	if criteria.ModelUUID != "" {
+		// Shared metadata has no model UUID.
+		all = append(all, bson.DocElem{"model_uuid", bson.D{{"$in", []interface{}{criteria.ModelUUID, nil}}}})
+	}
+
 	if len(all.Map()) == 0 {
 		return nil
 	}
@@ -387,6 +421,12 @@ type MetadataFilter struct {
 
 	// RootStorageType stores storage type.
 	RootStorageType string `json:"root-storage-type,omitempty"`
+
+	// ModelUUID, if set, restricts the search to metadata that is
+	// shared by all models or specific to the model with this UUID.
+	// If empty, metadata specific to any model is also found, so
+	// searches made on behalf of a model should always set it.
+	ModelUUID string `json:"model-uuid,omitempty"`
 }
 
 // SupportedArchitectures implements Storage.SupportedArchitectures.
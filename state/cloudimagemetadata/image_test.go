@@ -117,7 +117,8 @@ func buildAttributesFilter(attrs cloudimagemetadata.MetadataAttributes) cloudima
 		Stream:          attrs.Stream,
 		Region:          attrs.Region,
 		VirtType:        attrs.VirtType,
-		RootStorageType: attrs.RootStorageType}
+		RootStorageType: attrs.RootStorageType,
+		ModelUUID:       attrs.ModelUUID}
 	if attrs.Series != "" {
 		filter.Series = []string{attrs.Series}
 	}
@@ -156,6 +157,35 @@ func (s *cloudImageMetadataSuite) TestFindMetadata(c *gc.C) {
 	s.assertMetadataRecorded(c, cloudimagemetadata.MetadataAttributes{Region: "region"}, expected...)
 }
 
+func (s *cloudImageMetadataSuite) TestFindMetadataModelSpecific(c *gc.C) {
+	attrs := cloudimagemetadata.MetadataAttributes{
+		Stream:  "stream",
+		Region:  "region",
+		Version: "14.04",
+		Series:  "trusty",
+		Arch:    "arch",
+		Source:  "custom",
+	}
+	shared := cloudimagemetadata.Metadata{attrs, 0, "1", 0}
+	s.assertRecordMetadata(c, shared)
+
+	attrs.ModelUUID = "model-a"
+	modelA := cloudimagemetadata.Metadata{attrs, 0, "2", 0}
+	s.assertRecordMetadata(c, modelA)
+
+	attrs.ModelUUID = "model-b"
+	modelB := cloudimagemetadata.Metadata{attrs, 0, "3", 0}
+	s.assertRecordMetadata(c, modelB)
+
+	// Metadata for a model is found along with the shared metadata,
+	// but not metadata specific to other models.
+	s.assertMetadataRecorded(c, cloudimagemetadata.MetadataAttributes{ModelUUID: "model-a"}, shared, modelA)
+	s.assertMetadataRecorded(c, cloudimagemetadata.MetadataAttributes{ModelUUID: "model-c"}, shared)
+
+	// Without a model UUID, all metadata is found.
+	s.assertMetadataRecorded(c, cloudimagemetadata.MetadataAttributes{}, shared, modelA, modelB)
+}
+
 func (s *cloudImageMetadataSuite) TestSaveMetadataUpdateSameAttrsAndImages(c *gc.C) {
 	attrs := cloudimagemetadata.MetadataAttributes{
 		Stream:  "stream",
@@ -438,6 +468,35 @@ func (s *cloudImageMetadataSuite) TestSupportedArchitectures(c *gc.C) {
 	c.Assert(uniqueArches, gc.DeepEquals, expected)
 }
 
+func (s *cloudImageMetadataSuite) TestSupportedArchitecturesModelSpecific(c *gc.C) {
+	attrs := cloudimagemetadata.MetadataAttributes{
+		Stream:  "stream",
+		Region:  "region-test",
+		Version: "14.04",
+		Series:  "trusty",
+		Arch:    "arch",
+		Source:  "test",
+	}
+	s.assertRecordMetadata(c, cloudimagemetadata.Metadata{attrs, 0, "1", 0})
+
+	attrs.Arch = "model-a-arch"
+	attrs.ModelUUID = "model-a"
+	s.assertRecordMetadata(c, cloudimagemetadata.Metadata{attrs, 0, "2", 0})
+
+	attrs.Arch = "model-b-arch"
+	attrs.ModelUUID = "model-b"
+	s.assertRecordMetadata(c, cloudimagemetadata.Metadata{attrs, 0, "3", 0})
+
+	// Architectures of images specific to other models are not supported.
+	uniqueArches, err := s.storage.SupportedArchitectures(cloudimagemetadata.MetadataFilter{
+		Stream:    "stream",
+		Region:    "region-test",
+		ModelUUID: "model-a",
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(uniqueArches, jc.SameContents, []string{"arch", "model-a-arch"})
+}
+
 func (s *cloudImageMetadataSuite) TestSupportedArchitecturesUnmatchedStreams(c *gc.C) {
 	stream := "stream"
 	region := "region-test"
@@ -564,6 +623,35 @@ func (s *cloudImageMetadataSuite) addTestImageMetadata(c *gc.C, imageId string)
 	s.assertMetadataRecorded(c, attrs, added)
 }
 
+func (s *cloudImageMetadataSuite) TestDeleteModelMetadata(c *gc.C) {
+	attrs := cloudimagemetadata.MetadataAttributes{
+		Stream:  "stream",
+		Region:  "region",
+		Version: "14.04",
+		Series:  "trusty",
+		Arch:    "arch",
+		Source:  "custom",
+	}
+	shared := cloudimagemetadata.Metadata{attrs, 0, "1", 0}
+	s.assertRecordMetadata(c, shared)
+
+	attrs.ModelUUID = "model-a"
+	modelA := cloudimagemetadata.Metadata{attrs, 0, "1", 0}
+	s.assertRecordMetadata(c, modelA)
+
+	attrs.ModelUUID = "model-b"
+	modelB := cloudimagemetadata.Metadata{attrs, 0, "1", 0}
+	s.assertRecordMetadata(c, modelB)
+
+	// Only the metadata specific to the model is deleted.
+	err := s.storage.DeleteModelMetadata("model-a", "1")
+	c.Assert(err, jc.ErrorIsNil)
+	s.assertMetadataRecorded(c, cloudimagemetadata.MetadataAttributes{}, shared, modelB)
+
+	err = s.storage.DeleteModelMetadata("", "1")
+	c.Assert(err, gc.ErrorMatches, "empty model UUID not valid")
+}
+
 func (s *cloudImageMetadataSuite) assertDeleteMetadata(c *gc.C, imageId string) {
 	err := s.storage.DeleteMetadata(imageId)
 	c.Assert(err, jc.ErrorIsNil)
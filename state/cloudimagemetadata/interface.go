@@ -38,6 +38,12 @@ type MetadataAttributes struct {
 
 	// Source describes where this image is coming from: is it public? custom?
 	Source string
+
+	// ModelUUID, if set, is the UUID of the model to which the image
+	// metadata is specific. Metadata specific to a model is used when
+	// provisioning machines in that model in preference to metadata
+	// shared by all models, and is not used for any other model.
+	ModelUUID string
 }
 
 // Metadata describes a cloud image metadata.
@@ -66,6 +72,10 @@ type Storage interface {
 	// DeleteMetadata deletes cloud image metadata from state.
 	DeleteMetadata(imageId string) error
 
+	// DeleteModelMetadata deletes cloud image metadata for the given
+	// image that is specific to the model with the given UUID.
+	DeleteModelMetadata(modelUUID, imageId string) error
+
 	// FindMetadata returns all Metadata that match specified
 	// criteria or a "not found" error if none match.
 	// Empty criteria will return all cloud image metadata.
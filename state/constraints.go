@@ -17,47 +17,50 @@ import (
 
 // constraintsDoc is the mongodb representation of a constraints.Value.
 type constraintsDoc struct {
-	ModelUUID    string `bson:"model-uuid"`
-	Arch         *string
-	CpuCores     *uint64
-	CpuPower     *uint64
-	Mem          *uint64
-	RootDisk     *uint64
-	InstanceType *string
-	Container    *instance.ContainerType
-	Tags         *[]string
-	Spaces       *[]string
-	VirtType     *string
+	ModelUUID     string `bson:"model-uuid"`
+	Arch          *string
+	CpuCores      *uint64
+	CpuPower      *uint64
+	Mem           *uint64
+	RootDisk      *uint64
+	InstanceType  *string
+	Container     *instance.ContainerType
+	Tags          *[]string
+	Spaces        *[]string
+	VirtType      *string
+	MaxHourlyCost *uint64
 }
 
 func (doc constraintsDoc) value() constraints.Value {
 	result := constraints.Value{
-		Arch:         doc.Arch,
-		CpuCores:     doc.CpuCores,
-		CpuPower:     doc.CpuPower,
-		Mem:          doc.Mem,
-		RootDisk:     doc.RootDisk,
-		InstanceType: doc.InstanceType,
-		Container:    doc.Container,
-		Tags:         doc.Tags,
-		Spaces:       doc.Spaces,
-		VirtType:     doc.VirtType,
+		Arch:          doc.Arch,
+		CpuCores:      doc.CpuCores,
+		CpuPower:      doc.CpuPower,
+		Mem:           doc.Mem,
+		RootDisk:      doc.RootDisk,
+		InstanceType:  doc.InstanceType,
+		Container:     doc.Container,
+		Tags:          doc.Tags,
+		Spaces:        doc.Spaces,
+		VirtType:      doc.VirtType,
+		MaxHourlyCost: doc.MaxHourlyCost,
 	}
 	return result
 }
 
 func newConstraintsDoc(st *State, cons constraints.Value) constraintsDoc {
 	result := constraintsDoc{
-		Arch:         cons.Arch,
-		CpuCores:     cons.CpuCores,
-		CpuPower:     cons.CpuPower,
-		Mem:          cons.Mem,
-		RootDisk:     cons.RootDisk,
-		InstanceType: cons.InstanceType,
-		Container:    cons.Container,
-		Tags:         cons.Tags,
-		Spaces:       cons.Spaces,
-		VirtType:     cons.VirtType,
+		Arch:          cons.Arch,
+		CpuCores:      cons.CpuCores,
+		CpuPower:      cons.CpuPower,
+		Mem:           cons.Mem,
+		RootDisk:      cons.RootDisk,
+		InstanceType:  cons.InstanceType,
+		Container:     cons.Container,
+		Tags:          cons.Tags,
+		Spaces:        cons.Spaces,
+		VirtType:      cons.VirtType,
+		MaxHourlyCost: cons.MaxHourlyCost,
 	}
 	return result
 }
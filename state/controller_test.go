@@ -24,10 +24,20 @@ func (s *ControllerConfigSuite) TestControllerAndModelConfigInitialisation(c *gc
 	c.Assert(err, jc.ErrorIsNil)
 
 	optional := map[string]bool{
-		controller.IdentityURL:        true,
-		controller.IdentityPublicKey:  true,
-		controller.AutocertURLKey:     true,
-		controller.AutocertDNSNameKey: true,
+		controller.IdentityURL:               true,
+		controller.IdentityPublicKey:         true,
+		controller.AutocertURLKey:            true,
+		controller.AutocertDNSNameKey:        true,
+		controller.RequireUploadChecksumKey:  true,
+		controller.RequireSignedToolsURLsKey: true,
+		controller.ToolsURLExpiryKey:         true,
+		controller.BlobBackendKey:            true,
+		controller.WatcherCoalesceWindowKey:  true,
+		controller.MaxAnnotationValueSizeKey: true,
+		controller.MaxAnnotationsSizeKey:     true,
+		controller.AnnotationHistoryKey:      true,
+		controller.CharmScannersKey:          true,
+		controller.MaxCharmHookSizeKey:       true,
 	}
 	for _, controllerAttr := range controller.ControllerOnlyConfigAttributes {
 		v, ok := controllerSettings.Get(controllerAttr)
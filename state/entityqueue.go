@@ -0,0 +1,298 @@
+// Copyright 2016 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package state
+
+import (
+	stderrors "errors"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/juju/errors"
+	jujutxn "github.com/juju/txn"
+	"gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/txn"
+)
+
+// entityOperation is an operation on one or more entities, applied
+// by State.applyEntityOperation.
+type entityOperation interface {
+	// Entities returns the documents of the entities that the
+	// operation changes. Document IDs must include the model
+	// UUID, if the collection has one.
+	Entities() []txnDoc
+
+	// Build returns the transaction operations that apply the
+	// operation. If attempt is greater than zero, a previous
+	// transaction was aborted; the operation must re-read the
+	// entities' state, and derive its assertions from that.
+	//
+	// Build may return jujutxn.ErrNoOperations if there is nothing
+	// to do, or jujutxn.ErrTransientFailure if the operation should
+	// be rebuilt after re-reading. Build must not itself apply
+	// entity operations.
+	Build(attempt int) ([]txn.Op, error)
+
+	// Done is called with the result of applying the operation,
+	// and returns the error to report to the operation's caller.
+	Done(error) error
+}
+
+// composedOperation is an entityOperation that applies several
+// entityOperations in a single transaction.
+type composedOperation []entityOperation
+
+// composeOperations returns an entityOperation that applies each of
+// the given operations in a single transaction, such that either all
+// of them are applied, or none are.
+func composeOperations(ops ...entityOperation) entityOperation {
+	return composedOperation(ops)
+}
+
+// Entities is part of the entityOperation interface.
+func (c composedOperation) Entities() []txnDoc {
+	var docs []txnDoc
+	for _, op := range c {
+		docs = append(docs, op.Entities()...)
+	}
+	return docs
+}
+
+// Build is part of the entityOperation interface.
+func (c composedOperation) Build(attempt int) ([]txn.Op, error) {
+	var all []txn.Op
+	for _, op := range c {
+		ops, err := op.Build(attempt)
+		if err == jujutxn.ErrNoOperations {
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+		all = append(all, ops...)
+	}
+	if len(all) == 0 {
+		return nil, jujutxn.ErrNoOperations
+	}
+	return all, nil
+}
+
+// Done is part of the entityOperation interface. Each operation is
+// told the result; the first error returned by any is reported.
+func (c composedOperation) Done(err error) error {
+	var result error
+	for _, op := range c {
+		if opErr := op.Done(err); opErr != nil && result == nil {
+			result = opErr
+		}
+	}
+	return result
+}
+
+// entityQueues holds the operations being applied to entities by
+// this process, through any State.
+var entityQueues = newEntityQueue()
+
+// entityQueue serialises the application of contended operations to
+// entities, so that operations on the same entity made by the same
+// process do not repeatedly abort one another's transactions.
+type entityQueue struct {
+	mu       sync.Mutex
+	entities map[string]*entityQueueEntry
+}
+
+// entityQueueEntry records the operations queued for an entity.
+type entityQueueEntry struct {
+	// turn holds a token while an operation is being applied
+	// to the entity; operations wait to send on it in turn.
+	turn chan struct{}
+
+	// queued is the number of operations applying or waiting
+	// to apply to the entity. The entry is removed from the
+	// queue when it reaches zero.
+	queued int
+}
+
+func newEntityQueue() *entityQueue {
+	return &entityQueue{entities: make(map[string]*entityQueueEntry)}
+}
+
+// acquire waits until no other operation is being applied to any of
+// the entities with the given keys, and returns a function that must
+// be called when the caller's operation has been applied. Entities
+// are acquired in order of their keys, so that operations on several
+// entities cannot deadlock.
+func (q *entityQueue) acquire(keys ...string) func() {
+	keys = uniqueSortedKeys(keys)
+	entries := make([]*entityQueueEntry, len(keys))
+	for i, key := range keys {
+		q.mu.Lock()
+		entry, ok := q.entities[key]
+		if !ok {
+			entry = &entityQueueEntry{turn: make(chan struct{}, 1)}
+			q.entities[key] = entry
+		}
+		entry.queued++
+		q.mu.Unlock()
+
+		entry.turn <- struct{}{}
+		entries[i] = entry
+	}
+	return func() {
+		q.mu.Lock()
+		defer q.mu.Unlock()
+		for i, entry := range entries {
+			<-entry.turn
+			entry.queued--
+			if entry.queued == 0 {
+				delete(q.entities, keys[i])
+			}
+		}
+	}
+}
+
+func uniqueSortedKeys(keys []string) []string {
+	seen := make(map[string]bool)
+	unique := make([]string, 0, len(keys))
+	for _, key := range keys {
+		if !seen[key] {
+			seen[key] = true
+			unique = append(unique, key)
+		}
+	}
+	sort.Strings(unique)
+	return unique
+}
+
+// errEntityOperationContended is returned by attemptEntityOperation
+// when the operation's transaction is aborted, or it must be rebuilt.
+var errEntityOperationContended = stderrors.New("entity operation contended")
+
+// applyEntityOperation applies the operation. The first attempt is
+// made optimistically, possibly from the caller's copy of the entities;
+// if its transaction is aborted, the operation is queued behind any
+// other contended operations that this process is applying to the same
+// entities, and then rebuilt from fresh reads until it is applied.
+//
+// A rebuilt operation is attempted again for as long as the documents
+// its transactions touch are changed in the meantime by other writers,
+// such as agents or other controllers; there is no limit on the number
+// of attempts, as each is made only after another writer has made
+// progress. If a rebuilt transaction is aborted although none of those
+// documents changed since it was built, the operation's assertions are
+// inconsistent with what it read, and errRefresh is returned.
+func (st *State) applyEntityOperation(op entityOperation) error {
+	runner, closer := st.database.TransactionRunner()
+	defer closer()
+
+	// docs holds the entities' documents, and those touched
+	// by the operation's transactions so far. Their revisions
+	// are read before each queued attempt, so that we can tell
+	// whether an aborted transaction was contended.
+	entities := op.Entities()
+	keys := make([]string, len(entities))
+	docs := make(map[string]txnDoc)
+	for i, doc := range entities {
+		keys[i] = doc.key()
+		docs[keys[i]] = doc
+	}
+
+	err := attemptEntityOperation(runner, op, 0, docs)
+	if err == errEntityOperationContended {
+		release := entityQueues.acquire(keys...)
+		defer release()
+		for attempt := 1; err == errEntityOperationContended; attempt++ {
+			err = st.attemptQueuedEntityOperation(runner, op, attempt, docs)
+		}
+	}
+	return op.Done(err)
+}
+
+// attemptQueuedEntityOperation is like attemptEntityOperation, but
+// returns errRefresh if the operation's transaction is aborted or
+// must be rebuilt although none of the given documents changed while
+// it was being attempted.
+func (st *State) attemptQueuedEntityOperation(
+	runner jujutxn.Runner, op entityOperation, attempt int, docs map[string]txnDoc,
+) error {
+	before, err := st.readTxnRevnos(docs)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	if err := attemptEntityOperation(runner, op, attempt, docs); err != errEntityOperationContended {
+		return err
+	}
+	after, err := st.readTxnRevnos(docs)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	if !revnosChanged(before, after) {
+		return errRefresh
+	}
+	return errEntityOperationContended
+}
+
+// attemptEntityOperation builds the operation's transaction and runs
+// it, adding the documents it touches to docs. It returns
+// errEntityOperationContended if the transaction is aborted, or if
+// the operation must be rebuilt.
+func attemptEntityOperation(runner jujutxn.Runner, op entityOperation, attempt int, docs map[string]txnDoc) error {
+	ops, err := op.Build(attempt)
+	switch err {
+	case nil:
+	case jujutxn.ErrTransientFailure:
+		return errEntityOperationContended
+	case jujutxn.ErrNoOperations:
+		return nil
+	default:
+		return err
+	}
+	for _, op := range ops {
+		doc := txnDoc{op.C, op.Id}
+		docs[doc.key()] = doc
+	}
+	if err := runner.RunTransaction(ops); err == txn.ErrAborted {
+		return errEntityOperationContended
+	} else if err != nil {
+		return err
+	}
+	return nil
+}
+
+// txnDoc identifies a document touched by a transaction.
+type txnDoc struct {
+	collection string
+	id         interface{}
+}
+
+func (doc txnDoc) key() string {
+	return fmt.Sprintf("%s/%v", doc.collection, doc.id)
+}
+
+// readTxnRevnos returns the txn-revno of each of the given documents,
+// or -1 for those that do not exist.
+func (st *State) readTxnRevnos(docs map[string]txnDoc) (map[string]int64, error) {
+	revnos := make(map[string]int64)
+	for key, doc := range docs {
+		revno, err := st.readTxnRevno(doc.collection, doc.id)
+		if errors.Cause(err) == mgo.ErrNotFound {
+			revno = -1
+		} else if err != nil {
+			return nil, errors.Annotatef(err, "reading %q document %v", doc.collection, doc.id)
+		}
+		revnos[key] = revno
+	}
+	return revnos, nil
+}
+
+// revnosChanged reports whether any of the documents in after have
+// changed since before was read. Documents that were not read
+// before are assumed to have changed.
+func revnosChanged(before, after map[string]int64) bool {
+	for key, revno := range after {
+		if prev, ok := before[key]; !ok || prev != revno {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,162 @@
+// Copyright 2016 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package state
+
+import (
+	"time"
+
+	"github.com/juju/errors"
+	"github.com/juju/testing"
+	jc "github.com/juju/testing/checkers"
+	jujutxn "github.com/juju/txn"
+	gc "gopkg.in/check.v1"
+	"gopkg.in/mgo.v2/txn"
+
+	coretesting "github.com/juju/juju/testing"
+)
+
+type entityQueueSuite struct {
+	testing.IsolationSuite
+}
+
+var _ = gc.Suite(&entityQueueSuite{})
+
+func (s *entityQueueSuite) TestComposeOperationsBuild(c *gc.C) {
+	op := composeOperations(
+		&mockEntityOperation{ops: []txn.Op{{C: "a", Id: "1"}}},
+		&mockEntityOperation{err: jujutxn.ErrNoOperations},
+		&mockEntityOperation{ops: []txn.Op{{C: "b", Id: "2"}}},
+	)
+	ops, err := op.Build(0)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(ops, jc.DeepEquals, []txn.Op{{C: "a", Id: "1"}, {C: "b", Id: "2"}})
+}
+
+func (s *entityQueueSuite) TestComposeOperationsBuildNoOperations(c *gc.C) {
+	op := composeOperations(
+		&mockEntityOperation{err: jujutxn.ErrNoOperations},
+		&mockEntityOperation{err: jujutxn.ErrNoOperations},
+	)
+	_, err := op.Build(0)
+	c.Assert(err, gc.Equals, jujutxn.ErrNoOperations)
+}
+
+func (s *entityQueueSuite) TestComposeOperationsBuildError(c *gc.C) {
+	op := composeOperations(
+		&mockEntityOperation{ops: []txn.Op{{C: "a", Id: "1"}}},
+		&mockEntityOperation{err: errors.New("boom")},
+	)
+	_, err := op.Build(0)
+	c.Assert(err, gc.ErrorMatches, "boom")
+}
+
+func (s *entityQueueSuite) TestComposeOperationsDone(c *gc.C) {
+	op1 := &mockEntityOperation{}
+	op2 := &mockEntityOperation{doneErr: errors.New("first")}
+	op3 := &mockEntityOperation{doneErr: errors.New("second")}
+	err := composeOperations(op1, op2, op3).Done(errRefresh)
+	c.Assert(err, gc.ErrorMatches, "first")
+	for _, op := range []*mockEntityOperation{op1, op2, op3} {
+		c.Assert(op.done, jc.IsTrue)
+		c.Assert(op.result, gc.Equals, errRefresh)
+	}
+}
+
+func (s *entityQueueSuite) TestAcquireSerialises(c *gc.C) {
+	q := newEntityQueue()
+	release := q.acquire("app")
+
+	acquired := make(chan struct{})
+	go func() {
+		defer q.acquire("app")()
+		close(acquired)
+	}()
+	select {
+	case <-acquired:
+		c.Fatalf("acquired entity while another operation was applying")
+	case <-time.After(coretesting.ShortWait):
+	}
+
+	// Operations on other entities are not held up.
+	q.acquire("other")()
+
+	release()
+	select {
+	case <-acquired:
+	case <-time.After(coretesting.LongWait):
+		c.Fatalf("timed out waiting to acquire entity")
+	}
+}
+
+func (s *entityQueueSuite) TestAcquireSeveral(c *gc.C) {
+	q := newEntityQueue()
+	release := q.acquire("b")
+
+	acquired := make(chan struct{})
+	go func() {
+		defer q.acquire("b", "a", "b")()
+		close(acquired)
+	}()
+	select {
+	case <-acquired:
+		c.Fatalf("acquired entities while another operation was applying")
+	case <-time.After(coretesting.ShortWait):
+	}
+
+	release()
+	select {
+	case <-acquired:
+	case <-time.After(coretesting.LongWait):
+		c.Fatalf("timed out waiting to acquire entities")
+	}
+}
+
+func (s *entityQueueSuite) TestAcquireRemovesIdleEntities(c *gc.C) {
+	q := newEntityQueue()
+	release := q.acquire("app", "other")
+	c.Assert(q.entities, gc.HasLen, 2)
+	release()
+	c.Assert(q.entities, gc.HasLen, 0)
+}
+
+func (s *entityQueueSuite) TestComposeOperationsEntities(c *gc.C) {
+	op := composeOperations(
+		&mockEntityOperation{entities: []txnDoc{{"a", "1"}}},
+		&mockEntityOperation{entities: []txnDoc{{"b", "2"}}},
+	)
+	c.Assert(op.Entities(), jc.DeepEquals, []txnDoc{{"a", "1"}, {"b", "2"}})
+}
+
+func (s *entityQueueSuite) TestRevnosChanged(c *gc.C) {
+	before := map[string]int64{"a/1": 1, "b/2": -1}
+	c.Assert(revnosChanged(before, map[string]int64{"a/1": 1, "b/2": -1}), jc.IsFalse)
+	c.Assert(revnosChanged(before, map[string]int64{"a/1": 2, "b/2": -1}), jc.IsTrue)
+	c.Assert(revnosChanged(before, map[string]int64{"a/1": 1, "b/2": 3}), jc.IsTrue)
+	// Documents that were not read before may have changed.
+	c.Assert(revnosChanged(before, map[string]int64{"a/1": 1, "c/3": 1}), jc.IsTrue)
+}
+
+type mockEntityOperation struct {
+	entities []txnDoc
+	ops      []txn.Op
+	err      error
+	doneErr  error
+
+	done   bool
+	result error
+}
+
+func (op *mockEntityOperation) Entities() []txnDoc {
+	return op.entities
+}
+
+func (op *mockEntityOperation) Build(attempt int) ([]txn.Op, error) {
+	return op.ops, op.err
+}
+
+func (op *mockEntityOperation) Done(err error) error {
+	op.done = true
+	op.result = err
+	return op.doneErr
+}
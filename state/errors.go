@@ -48,6 +48,7 @@ var ErrCharmRevisionAlreadyModified = fmt.Errorf("charm revision already modifie
 
 var ErrDead = fmt.Errorf("not found or dead")
 var errNotAlive = fmt.Errorf("not found or not alive")
+var errApplicationPaused = fmt.Errorf("application is paused")
 
 func onAbort(txnErr, err error) error {
 	if txnErr == txn.ErrAborted ||
@@ -134,3 +135,76 @@ func IsParentDeviceHasChildrenError(err interface{}) bool {
 	_, ok := value.(*ErrParentDeviceHasChildren)
 	return ok
 }
+
+// ErrCharmPinned is returned by Application.SetCharm when the
+// application's charm is pinned, and the charm URL would change.
+type ErrCharmPinned struct {
+	message string
+}
+
+func (e *ErrCharmPinned) Error() string {
+	if e.message == "" {
+		return "application charm is pinned"
+	}
+	return fmt.Sprintf("application charm is pinned: %s", e.message)
+}
+
+// IsCharmPinnedError returns if the given error or its cause is
+// ErrCharmPinned.
+func IsCharmPinnedError(err interface{}) bool {
+	if err == nil {
+		return false
+	}
+	// In case of a wrapped error, check the cause first.
+	value := err
+	cause := errors.Cause(err.(error))
+	if cause != nil {
+		value = cause
+	}
+	_, ok := value.(*ErrCharmPinned)
+	return ok
+}
+
+// ErrAnnotationLimit is returned by SetAnnotations when the annotations
+// of an entity would exceed one of the controller's annotation size
+// limits.
+type ErrAnnotationLimit struct {
+	// Key is the key of the annotation whose value is too large, or
+	// empty if the total size of the entity's annotations is too large.
+	Key string
+
+	// Size is the size, in bytes, that would exceed the limit.
+	Size int
+
+	// Limit is the limit, in bytes, that would be exceeded.
+	Limit int
+}
+
+func (e *ErrAnnotationLimit) Error() string {
+	if e.Key != "" {
+		return fmt.Sprintf(
+			"value of annotation %q is %d bytes, exceeding the limit of %d bytes",
+			e.Key, e.Size, e.Limit,
+		)
+	}
+	return fmt.Sprintf(
+		"annotations total %d bytes, exceeding the limit of %d bytes",
+		e.Size, e.Limit,
+	)
+}
+
+// IsAnnotationLimitError returns if the given error or its cause is
+// ErrAnnotationLimit.
+func IsAnnotationLimitError(err interface{}) bool {
+	if err == nil {
+		return false
+	}
+	// In case of a wrapped error, check the cause first.
+	value := err
+	cause := errors.Cause(err.(error))
+	if cause != nil {
+		value = cause
+	}
+	_, ok := value.(*ErrAnnotationLimit)
+	return ok
+}
@@ -43,8 +43,13 @@ const (
 	GUISettingsC      = guisettingsC
 	GlobalSettingsC   = globalSettingsC
 	SettingsC         = settingsC
+	UnitsC            = unitsC
+	RelationsC        = relationsC
+	RefcountsC        = refcountsC
 )
 
+const MaxModelConfigChanges = maxModelConfigChanges
+
 var (
 	BinarystorageNew                     = &binarystorageNew
 	ImageStorageNewStorage               = &imageStorageNewStorage
@@ -53,6 +58,8 @@ var (
 	GetOrCreatePorts                     = getOrCreatePorts
 	GetPorts                             = getPorts
 	AddVolumeOps                         = (*State).addVolumeOps
+	BeginApplicationRename               = (*State).beginApplicationRename
+	RenameApplicationEntities            = (*State).renameApplicationEntities
 	CombineMeterStatus                   = combineMeterStatus
 	ApplicationGlobalKey                 = applicationGlobalKey
 	ReadSettings                         = readSettings
@@ -107,6 +114,10 @@ func (doc *MachineDoc) String() string {
 	return m.String()
 }
 
+func ApplicationSettingsKey(appName string, curl *charm.URL) string {
+	return applicationSettingsKey(appName, curl)
+}
+
 func ServiceSettingsRefCount(st *State, appName string, curl *charm.URL) (int, error) {
 	refcounts, closer := st.getCollection(refcountsC)
 	defer closer()
@@ -0,0 +1,283 @@
+// Copyright 2016 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package state
+
+import (
+	"sort"
+	"time"
+
+	"github.com/juju/errors"
+	"gopkg.in/juju/names.v2"
+	"gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
+	"gopkg.in/mgo.v2/txn"
+)
+
+// ExposureIntent records the desired exposure of an application: whether
+// or not it should be exposed, and the port ranges opened by its units
+// that should be made accessible when it is.
+//
+// An exposure intent is created the first time an application is exposed,
+// and is updated whenever the application is exposed or unexposed, or one
+// of its units opens or closes ports.
+type ExposureIntent struct {
+	// Application is the name of the application.
+	Application string
+
+	// Exposed records whether or not the application should be
+	// exposed.
+	Exposed bool
+
+	// PortRanges holds the port ranges opened by the
+	// application's units.
+	PortRanges []PortRange
+
+	// Updated is the time at which the application was most
+	// recently exposed or unexposed.
+	Updated time.Time
+}
+
+// exposureIntentDoc represents the exposure intent of an application
+// in MongoDB.
+type exposureIntentDoc struct {
+	DocID       string      `bson:"_id"`
+	ModelUUID   string      `bson:"model-uuid"`
+	Application string      `bson:"application"`
+	Exposed     bool        `bson:"exposed"`
+	PortRanges  []PortRange `bson:"port-ranges"`
+	Updated     int64       `bson:"updated"`
+	TxnRevno    int64       `bson:"txn-revno"`
+}
+
+func (doc *exposureIntentDoc) intent() ExposureIntent {
+	portRanges := make([]PortRange, len(doc.PortRanges))
+	copy(portRanges, doc.PortRanges)
+	return ExposureIntent{
+		Application: doc.Application,
+		Exposed:     doc.Exposed,
+		PortRanges:  portRanges,
+		Updated:     time.Unix(0, doc.Updated).UTC(),
+	}
+}
+
+// ExposureIntent returns the exposure intent recorded for the application.
+// If the application has never been exposed, an error satisfying
+// errors.IsNotFound is returned.
+func (a *Application) ExposureIntent() (ExposureIntent, error) {
+	doc, err := getExposureIntentDoc(a.st, a.doc.Name)
+	if err != nil {
+		return ExposureIntent{}, errors.Trace(err)
+	}
+	return doc.intent(), nil
+}
+
+// WatchExposureIntents returns a StringsWatcher that notifies of changes
+// to the exposure intents of applications in the model. The names of the
+// applications whose intents have changed are reported.
+func (st *State) WatchExposureIntents() StringsWatcher {
+	return newcollectionWatcher(st, colWCfg{col: exposureIntentsC, snapshot: true})
+}
+
+func getExposureIntentDoc(st *State, applicationName string) (*exposureIntentDoc, error) {
+	coll, closer := st.getCollection(exposureIntentsC)
+	defer closer()
+
+	var doc exposureIntentDoc
+	err := coll.FindId(applicationName).One(&doc)
+	if err == mgo.ErrNotFound {
+		return nil, errors.NotFoundf("exposure intent for application %q", applicationName)
+	} else if err != nil {
+		return nil, errors.Annotatef(err, "cannot get exposure intent for application %q", applicationName)
+	}
+	return &doc, nil
+}
+
+// exposureIntentOps returns the operations required to record the
+// application's intent to be exposed or unexposed, along with the
+// port ranges currently opened by its units. The intent document is
+// created if it does not already exist. The operations assert that
+// neither the intent nor the ports documents the port ranges were
+// read from have changed since they were read.
+func (a *Application) exposureIntentOps(exposed bool) ([]txn.Op, error) {
+	portRanges, portsAsserts, err := a.openedPortRanges()
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	updated := a.st.clock.Now().UnixNano()
+	doc, err := getExposureIntentDoc(a.st, a.doc.Name)
+	if errors.IsNotFound(err) {
+		return append(portsAsserts, txn.Op{
+			C:      exposureIntentsC,
+			Id:     a.doc.DocID,
+			Assert: txn.DocMissing,
+			Insert: &exposureIntentDoc{
+				DocID:       a.doc.DocID,
+				ModelUUID:   a.st.ModelUUID(),
+				Application: a.doc.Name,
+				Exposed:     exposed,
+				PortRanges:  portRanges,
+				Updated:     updated,
+			},
+		}), nil
+	} else if err != nil {
+		return nil, errors.Trace(err)
+	}
+	return append(portsAsserts, txn.Op{
+		C:      exposureIntentsC,
+		Id:     a.doc.DocID,
+		Assert: bson.D{{"txn-revno", doc.TxnRevno}},
+		Update: bson.D{{"$set", bson.D{
+			{"exposed", exposed},
+			{"port-ranges", portRanges},
+			{"updated", updated},
+		}}},
+	}), nil
+}
+
+// openedPortRanges returns the port ranges opened by the application's
+// units on all subnets, ordered by unit name and then by port range,
+// along with operations asserting that the ports documents they were
+// read from are unchanged. For machines with no ports document on the
+// default subnet, the operations assert that one has not been created.
+func (a *Application) openedPortRanges() ([]PortRange, []txn.Op, error) {
+	units, err := a.AllUnits()
+	if err != nil {
+		return nil, nil, errors.Trace(err)
+	}
+	machinePorts := make(map[string][]*Ports)
+	portRanges := []PortRange{}
+	var ops []txn.Op
+	for _, unit := range units {
+		machineId, err := unit.AssignedMachineId()
+		if errors.IsNotAssigned(err) {
+			continue
+		} else if err != nil {
+			return nil, nil, errors.Trace(err)
+		}
+		allPorts, ok := machinePorts[machineId]
+		if !ok {
+			machine, err := a.st.Machine(machineId)
+			if errors.IsNotFound(err) {
+				continue
+			} else if err != nil {
+				return nil, nil, errors.Trace(err)
+			}
+			if allPorts, err = machine.AllPorts(); err != nil {
+				return nil, nil, errors.Trace(err)
+			}
+			machinePorts[machineId] = allPorts
+			ops = append(ops, assertPortsUnchangedOps(a.st, machineId, allPorts)...)
+		}
+		for _, ports := range allPorts {
+			portRanges = append(portRanges, ports.PortsForUnit(unit.Name())...)
+		}
+	}
+	sort.Sort(portRangesByUnit(portRanges))
+	return portRanges, ops, nil
+}
+
+// assertPortsUnchangedOps returns operations asserting that the given
+// ports documents for the machine are unchanged, and that no ports
+// document has been created for the machine on the default subnet if
+// there was none.
+func assertPortsUnchangedOps(st *State, machineId string, allPorts []*Ports) []txn.Op {
+	ops := make([]txn.Op, 0, len(allPorts)+1)
+	haveDefault := false
+	for _, ports := range allPorts {
+		if ports.doc.SubnetID == "" {
+			haveDefault = true
+		}
+		ops = append(ops, txn.Op{
+			C:      openedPortsC,
+			Id:     ports.doc.DocID,
+			Assert: bson.D{{"txn-revno", ports.doc.TxnRevno}},
+		})
+	}
+	if !haveDefault {
+		ops = append(ops, txn.Op{
+			C:      openedPortsC,
+			Id:     st.docID(portsGlobalKey(machineId, "")),
+			Assert: txn.DocMissing,
+		})
+	}
+	return ops
+}
+
+// addExposureIntentPortRangeOps returns the operations required to
+// add the port range to the exposure intent of the unit's application.
+// If the application has no exposure intent, the operations are no-ops.
+func addExposureIntentPortRangeOps(st *State, portRange PortRange) ([]txn.Op, error) {
+	appName, err := names.UnitApplication(portRange.UnitName)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	return []txn.Op{{
+		C:      exposureIntentsC,
+		Id:     st.docID(appName),
+		Update: bson.D{{"$addToSet", bson.D{{"port-ranges", portRange}}}},
+	}}, nil
+}
+
+// removeExposureIntentPortRangeOps returns the operations required to
+// remove the port range from the exposure intent of the unit's
+// application. If the application has no exposure intent, the
+// operations are no-ops.
+func removeExposureIntentPortRangeOps(st *State, portRange PortRange) ([]txn.Op, error) {
+	appName, err := names.UnitApplication(portRange.UnitName)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	return []txn.Op{{
+		C:      exposureIntentsC,
+		Id:     st.docID(appName),
+		Update: bson.D{{"$pull", bson.D{{"port-ranges", portRange}}}},
+	}}, nil
+}
+
+// removeExposureIntentUnitOps returns the operations required to remove
+// all port ranges opened by the unit from the exposure intent of its
+// application.
+func removeExposureIntentUnitOps(st *State, unitName string) ([]txn.Op, error) {
+	appName, err := names.UnitApplication(unitName)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	return []txn.Op{{
+		C:  exposureIntentsC,
+		Id: st.docID(appName),
+		Update: bson.D{{"$pull", bson.D{{"port-ranges", bson.D{
+			{"unitname", unitName},
+		}}}}},
+	}}, nil
+}
+
+// removeExposureIntentOp returns the operation required to remove the
+// exposure intent of the named application, if it has one.
+func removeExposureIntentOp(st *State, applicationName string) txn.Op {
+	return txn.Op{
+		C:      exposureIntentsC,
+		Id:     st.docID(applicationName),
+		Remove: true,
+	}
+}
+
+// portRangesByUnit sorts port ranges by unit name, then protocol,
+// then port numbers.
+type portRangesByUnit []PortRange
+
+func (p portRangesByUnit) Len() int      { return len(p) }
+func (p portRangesByUnit) Swap(i, j int) { p[i], p[j] = p[j], p[i] }
+func (p portRangesByUnit) Less(i, j int) bool {
+	a, b := p[i], p[j]
+	if a.UnitName != b.UnitName {
+		return a.UnitName < b.UnitName
+	}
+	if a.Protocol != b.Protocol {
+		return a.Protocol < b.Protocol
+	}
+	if a.FromPort != b.FromPort {
+		return a.FromPort < b.FromPort
+	}
+	return a.ToPort < b.ToPort
+}
@@ -0,0 +1,184 @@
+// Copyright 2016 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package state_test
+
+import (
+	"time"
+
+	"github.com/juju/errors"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/state"
+	statetesting "github.com/juju/juju/state/testing"
+	"github.com/juju/juju/testing/factory"
+)
+
+type ExposureIntentSuite struct {
+	ConnSuite
+	application *state.Application
+	unit        *state.Unit
+}
+
+var _ = gc.Suite(&ExposureIntentSuite{})
+
+func (s *ExposureIntentSuite) SetUpTest(c *gc.C) {
+	s.ConnSuite.SetUpTest(c)
+	f := factory.NewFactory(s.State)
+	s.application = f.MakeApplication(c, &factory.ApplicationParams{Name: "wordpress"})
+	machine := f.MakeMachine(c, &factory.MachineParams{Series: "quantal"})
+	s.unit = f.MakeUnit(c, &factory.UnitParams{Application: s.application, Machine: machine})
+}
+
+func (s *ExposureIntentSuite) TestExposureIntentNotFound(c *gc.C) {
+	_, err := s.application.ExposureIntent()
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+	c.Assert(err, gc.ErrorMatches, `exposure intent for application "wordpress" not found`)
+}
+
+func (s *ExposureIntentSuite) TestSetExposedRecordsOpenedPorts(c *gc.C) {
+	err := s.unit.OpenPorts("tcp", 80, 80)
+	c.Assert(err, jc.ErrorIsNil)
+	err = s.application.SetExposed()
+	c.Assert(err, jc.ErrorIsNil)
+
+	intent, err := s.application.ExposureIntent()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(intent.Application, gc.Equals, "wordpress")
+	c.Assert(intent.Exposed, jc.IsTrue)
+	c.Assert(intent.Updated.IsZero(), jc.IsFalse)
+	c.Assert(intent.PortRanges, jc.DeepEquals, []state.PortRange{{
+		UnitName: s.unit.Name(), FromPort: 80, ToPort: 80, Protocol: "tcp",
+	}})
+
+	err = s.application.ClearExposed()
+	c.Assert(err, jc.ErrorIsNil)
+	intent, err = s.application.ExposureIntent()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(intent.Exposed, jc.IsFalse)
+}
+
+func (s *ExposureIntentSuite) TestPortsOpenedAfterExpose(c *gc.C) {
+	err := s.application.SetExposed()
+	c.Assert(err, jc.ErrorIsNil)
+	err = s.unit.OpenPorts("tcp", 80, 80)
+	c.Assert(err, jc.ErrorIsNil)
+	err = s.unit.OpenPorts("udp", 53, 53)
+	c.Assert(err, jc.ErrorIsNil)
+
+	intent, err := s.application.ExposureIntent()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(intent.PortRanges, jc.SameContents, []state.PortRange{{
+		UnitName: s.unit.Name(), FromPort: 80, ToPort: 80, Protocol: "tcp",
+	}, {
+		UnitName: s.unit.Name(), FromPort: 53, ToPort: 53, Protocol: "udp",
+	}})
+
+	err = s.unit.ClosePorts("tcp", 80, 80)
+	c.Assert(err, jc.ErrorIsNil)
+	intent, err = s.application.ExposureIntent()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(intent.PortRanges, jc.DeepEquals, []state.PortRange{{
+		UnitName: s.unit.Name(), FromPort: 53, ToPort: 53, Protocol: "udp",
+	}})
+}
+
+func (s *ExposureIntentSuite) TestRemoveUnitRemovesPortRanges(c *gc.C) {
+	err := s.unit.OpenPorts("tcp", 80, 80)
+	c.Assert(err, jc.ErrorIsNil)
+	err = s.application.SetExposed()
+	c.Assert(err, jc.ErrorIsNil)
+
+	err = s.unit.EnsureDead()
+	c.Assert(err, jc.ErrorIsNil)
+	err = s.unit.Remove()
+	c.Assert(err, jc.ErrorIsNil)
+
+	intent, err := s.application.ExposureIntent()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(intent.Exposed, jc.IsTrue)
+	c.Assert(intent.PortRanges, gc.HasLen, 0)
+}
+
+func (s *ExposureIntentSuite) TestSetExposedWithConcurrentOpenPorts(c *gc.C) {
+	defer state.SetBeforeHooks(c, s.State, func() {
+		err := s.unit.OpenPorts("tcp", 80, 80)
+		c.Assert(err, jc.ErrorIsNil)
+	}).Check()
+
+	err := s.application.SetExposed()
+	c.Assert(err, jc.ErrorIsNil)
+	intent, err := s.application.ExposureIntent()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(intent.PortRanges, jc.DeepEquals, []state.PortRange{{
+		UnitName: s.unit.Name(), FromPort: 80, ToPort: 80, Protocol: "tcp",
+	}})
+}
+
+func (s *ExposureIntentSuite) TestClearExposedWithConcurrentClosePorts(c *gc.C) {
+	err := s.unit.OpenPorts("tcp", 80, 80)
+	c.Assert(err, jc.ErrorIsNil)
+	err = s.unit.OpenPorts("tcp", 443, 443)
+	c.Assert(err, jc.ErrorIsNil)
+	err = s.application.SetExposed()
+	c.Assert(err, jc.ErrorIsNil)
+
+	defer state.SetBeforeHooks(c, s.State, func() {
+		err := s.unit.ClosePorts("tcp", 80, 80)
+		c.Assert(err, jc.ErrorIsNil)
+	}).Check()
+
+	err = s.application.ClearExposed()
+	c.Assert(err, jc.ErrorIsNil)
+	intent, err := s.application.ExposureIntent()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(intent.Exposed, jc.IsFalse)
+	c.Assert(intent.PortRanges, jc.DeepEquals, []state.PortRange{{
+		UnitName: s.unit.Name(), FromPort: 443, ToPort: 443, Protocol: "tcp",
+	}})
+}
+
+func (s *ExposureIntentSuite) TestSetExposedIdempotent(c *gc.C) {
+	err := s.application.SetExposed()
+	c.Assert(err, jc.ErrorIsNil)
+	intent, err := s.application.ExposureIntent()
+	c.Assert(err, jc.ErrorIsNil)
+
+	s.Clock.Advance(time.Minute)
+	err = s.application.SetExposed()
+	c.Assert(err, jc.ErrorIsNil)
+	again, err := s.application.ExposureIntent()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(again, jc.DeepEquals, intent)
+}
+
+func (s *ExposureIntentSuite) TestWatchExposureIntents(c *gc.C) {
+	w := s.State.WatchExposureIntents()
+	defer statetesting.AssertStop(c, w)
+	wc := statetesting.NewStringsWatcherC(c, s.State, w)
+	wc.AssertChange()
+	wc.AssertNoChange()
+
+	err := s.application.SetExposed()
+	c.Assert(err, jc.ErrorIsNil)
+	wc.AssertChange("wordpress")
+	wc.AssertNoChange()
+
+	// Exposing an exposed application changes nothing.
+	err = s.application.SetExposed()
+	c.Assert(err, jc.ErrorIsNil)
+	wc.AssertNoChange()
+
+	// Opening ports on an exposed application's unit is
+	// reported, so the firewaller can open them too.
+	err = s.unit.OpenPorts("tcp", 80, 80)
+	c.Assert(err, jc.ErrorIsNil)
+	wc.AssertChange("wordpress")
+	wc.AssertNoChange()
+
+	err = s.application.ClearExposed()
+	c.Assert(err, jc.ErrorIsNil)
+	wc.AssertChange("wordpress")
+	wc.AssertNoChange()
+}
@@ -472,7 +472,7 @@ func (st *State) removeMachineFilesystemsOps(machine names.MachineTag) ([]txn.Op
 			Id:     filesystemTag.Id(),
 			Assert: txn.DocExists,
 			Remove: true,
-		})
+		}, removeStorageClaimOp(filesystemGlobalKey(filesystemTag.Id())))
 	}
 	return ops, nil
 }
@@ -653,6 +653,7 @@ func removeFilesystemOps(st *State, filesystem Filesystem) ([]txn.Op, error) {
 			Remove: true,
 		},
 		removeStatusOp(st, filesystem.globalKey()),
+		removeStorageClaimOp(filesystem.globalKey()),
 	}
 	// If the filesystem is backed by a volume, the volume should
 	// be destroyed once the filesystem is removed if it is bound
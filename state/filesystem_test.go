@@ -1012,6 +1012,25 @@ func (s *FilesystemStateSuite) TestFilesystemAttachmentLocationConflict(c *gc.C)
 			`mount point "/srv/within" for "data" storage`)
 }
 
+func (s *FilesystemStateSuite) TestMachineAttachmentLives(c *gc.C) {
+	filesystem0, machine0 := s.setupFilesystemAttachment(c, "rootfs")
+	filesystem1, machine1 := s.setupFilesystemAttachment(c, "rootfs")
+	err := s.State.DetachFilesystem(machine1.MachineTag(), filesystem1.FilesystemTag())
+	c.Assert(err, jc.ErrorIsNil)
+
+	attached := state.MachineAttachmentId{machine0.MachineTag(), filesystem0.FilesystemTag()}
+	detaching := state.MachineAttachmentId{machine1.MachineTag(), filesystem1.FilesystemTag()}
+	missing := state.MachineAttachmentId{machine0.MachineTag(), names.NewFilesystemTag("42")}
+	lives, err := s.State.MachineAttachmentLives([]state.MachineAttachmentId{
+		attached, detaching, missing,
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(lives, jc.DeepEquals, map[state.MachineAttachmentId]state.Life{
+		attached:  state.Alive,
+		detaching: state.Dying,
+	})
+}
+
 func (s *FilesystemStateSuite) setupFilesystemAttachment(c *gc.C, pool string) (state.Filesystem, *state.Machine) {
 	machine, err := s.State.AddOneMachine(state.MachineTemplate{
 		Series: "quantal",
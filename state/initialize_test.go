@@ -267,6 +267,52 @@ func (s *InitializeSuite) TestInitializeWithControllerInheritedConfig(c *gc.C) {
 	c.Assert(cfg.AllAttrs(), jc.DeepEquals, expected)
 }
 
+func (s *InitializeSuite) TestInitializeWithBlobBackend(c *gc.C) {
+	cfg := testing.ModelConfig(c)
+	owner := names.NewLocalUserTag("initialize-admin")
+	dir := c.MkDir()
+	controllerCfg := testing.FakeControllerConfig()
+	controllerCfg[controller.BlobBackendKey] = map[string]string{
+		"type":      "filesystem",
+		"directory": dir,
+	}
+
+	st, err := state.Initialize(state.InitializeParams{
+		Clock:            clock.WallClock,
+		ControllerConfig: controllerCfg,
+		ControllerModelArgs: state.ModelArgs{
+			CloudName:               "dummy",
+			Owner:                   owner,
+			Config:                  cfg,
+			StorageProviderRegistry: storage.StaticProviderRegistry{},
+		},
+		CloudName: "dummy",
+		Cloud: cloud.Cloud{
+			Type:      "dummy",
+			AuthTypes: []cloud.AuthType{cloud.EmptyAuthType},
+		},
+		MongoInfo:     statetesting.NewMongoInfo(),
+		MongoDialOpts: mongotest.DialOpts(),
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	modelTag := st.ModelTag()
+	err = st.Close()
+	c.Assert(err, jc.ErrorIsNil)
+
+	s.openState(c, modelTag)
+
+	current, _, err := s.State.BlobBackend()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(current.String(), gc.Equals, "filesystem:"+dir)
+
+	// The backend's attributes, which may include credentials,
+	// are not recorded in the controller config.
+	stored, err := s.State.ControllerConfig()
+	c.Assert(err, jc.ErrorIsNil)
+	_, ok := stored[controller.BlobBackendKey]
+	c.Assert(ok, jc.IsFalse)
+}
+
 func (s *InitializeSuite) TestDoubleInitializeConfig(c *gc.C) {
 	cfg := testing.ModelConfig(c)
 	owner := names.NewLocalUserTag("initialize-admin")
@@ -0,0 +1,556 @@
+// Copyright 2016 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package state
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/juju/errors"
+	"github.com/juju/utils/set"
+	"gopkg.in/mgo.v2/bson"
+	"gopkg.in/mgo.v2/txn"
+)
+
+// Kinds of integrity problem reported by CheckIntegrity.
+const (
+	// IntegrityDanglingReference describes a document that refers
+	// to another document that does not exist.
+	IntegrityDanglingReference = "dangling-reference"
+
+	// IntegrityNegativeRefcount describes a reference count
+	// that has fallen below zero.
+	IntegrityNegativeRefcount = "negative-refcount"
+
+	// IntegrityMissingSettings describes a settings document
+	// that is referred to, but does not exist.
+	IntegrityMissingSettings = "missing-settings"
+
+	// IntegrityCountMismatch describes an application whose
+	// recorded unit or relation count does not match the number
+	// of unit or relation documents referring to it.
+	IntegrityCountMismatch = "count-mismatch"
+)
+
+// maxRepairAttempts is the number of times RepairIntegrity will
+// attempt to repair a problem whose documents change concurrently.
+const maxRepairAttempts = 3
+
+// IntegrityProblem describes a violation of the referential integrity
+// of a model's documents, along with a transaction that would repair
+// it if one can be suggested.
+type IntegrityProblem struct {
+	// ID identifies the problem. The ID is derived from the
+	// documents involved, so the same problem has the same ID
+	// each time the model is checked.
+	ID string
+
+	// Kind is the kind of problem: one of IntegrityDanglingReference,
+	// IntegrityNegativeRefcount, IntegrityMissingSettings or
+	// IntegrityCountMismatch.
+	Kind string
+
+	// Collection and DocID identify the document with the problem.
+	Collection string
+	DocID      string
+
+	// Description is a human-readable description of the problem.
+	Description string
+
+	// Repair holds the operations of a transaction that would repair
+	// the problem, asserting that the documents involved are as they
+	// were when checked. Repair is empty if the problem cannot be
+	// repaired automatically.
+	Repair []txn.Op
+}
+
+// CheckIntegrity checks that the references between the model's
+// applications, units, machines, relations, settings and settings
+// reference counts are consistent, and returns the problems found.
+//
+// The unit and relation counts recorded for each application are
+// checked too. Many transactions assert on these counts; if they are
+// wrong, such transactions are aborted repeatedly, until they fail
+// with jujutxn.ErrExcessiveContention.
+//
+// The documents are read without a transaction, so problems may be
+// reported that are only transient, caused by concurrent changes;
+// the repairs suggested assert on the documents' contents, and will
+// not be applied if the documents have changed.
+func (st *State) CheckIntegrity() ([]IntegrityProblem, error) {
+	checker, err := newIntegrityChecker(st)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	if err := checker.check(); err != nil {
+		return nil, errors.Trace(err)
+	}
+	return checker.problems, nil
+}
+
+// RepairIntegrity applies the suggested repairs of the integrity
+// problems with the given IDs, as reported by CheckIntegrity, and
+// returns an error for each ID. The model is checked again first, so
+// only repairs computed from the current documents are applied; each
+// in its own transaction. If the documents involved in a problem
+// change before its repair is applied, for example because another
+// repair changed them, the model is checked again and the problem's
+// new repair applied instead.
+//
+// If a problem no longer exists, its error satisfies errors.IsNotFound;
+// if it cannot be repaired automatically, errors.IsNotSupported.
+func (st *State) RepairIntegrity(ids []string) ([]error, error) {
+	byID, err := st.integrityProblemsByID()
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	results := make([]error, len(ids))
+	for i, id := range ids {
+		for attempt := 1; ; attempt++ {
+			problem, ok := byID[id]
+			if !ok {
+				results[i] = errors.NotFoundf("integrity problem %q", id)
+				break
+			}
+			if len(problem.Repair) == 0 {
+				results[i] = errors.NotSupportedf("automatic repair of integrity problem %q", id)
+				break
+			}
+			err := st.runTransaction(problem.Repair)
+			if err == txn.ErrAborted {
+				if attempt < maxRepairAttempts {
+					if byID, err = st.integrityProblemsByID(); err != nil {
+						return nil, errors.Trace(err)
+					}
+					continue
+				}
+				results[i] = errors.Errorf(
+					"cannot repair integrity problem %q: documents changed since checked", id,
+				)
+			} else if err != nil {
+				results[i] = errors.Annotatef(err, "cannot repair integrity problem %q", id)
+			} else {
+				logger.Infof("repaired integrity problem %q: %s", id, problem.Description)
+			}
+			break
+		}
+	}
+	return results, nil
+}
+
+// integrityProblemsByID checks the model's integrity, and returns
+// the problems found keyed by their IDs.
+func (st *State) integrityProblemsByID() (map[string]IntegrityProblem, error) {
+	problems, err := st.CheckIntegrity()
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	byID := make(map[string]IntegrityProblem)
+	for _, problem := range problems {
+		byID[problem.ID] = problem
+	}
+	return byID, nil
+}
+
+// integrityChecker holds the documents of a model that are
+// cross-checked by CheckIntegrity. The applications are read first,
+// so that a unit or relation added or removed while the documents are
+// read changes the application document it is counted in, aborting
+// any repair of the application's counts.
+type integrityChecker struct {
+	st           *State
+	applications map[string]applicationDoc
+	units        map[string]unitDoc
+	machines     map[string]machineDoc
+	relations    []relationDoc
+	refcounts    []integrityRefcountDoc
+	problems     []IntegrityProblem
+}
+
+// integrityRefcountDoc is a refcountDoc along with its ID.
+type integrityRefcountDoc struct {
+	DocID    string `bson:"_id"`
+	RefCount int    `bson:"refcount"`
+}
+
+func newIntegrityChecker(st *State) (*integrityChecker, error) {
+	c := &integrityChecker{
+		st:           st,
+		applications: make(map[string]applicationDoc),
+		units:        make(map[string]unitDoc),
+		machines:     make(map[string]machineDoc),
+	}
+	var applications []applicationDoc
+	if err := c.readAll(applicationsC, &applications); err != nil {
+		return nil, errors.Trace(err)
+	}
+	for _, doc := range applications {
+		c.applications[doc.Name] = doc
+	}
+	var units []unitDoc
+	if err := c.readAll(unitsC, &units); err != nil {
+		return nil, errors.Trace(err)
+	}
+	for _, doc := range units {
+		c.units[doc.Name] = doc
+	}
+	var machines []machineDoc
+	if err := c.readAll(machinesC, &machines); err != nil {
+		return nil, errors.Trace(err)
+	}
+	for _, doc := range machines {
+		c.machines[doc.Id] = doc
+	}
+	if err := c.readAll(relationsC, &c.relations); err != nil {
+		return nil, errors.Trace(err)
+	}
+	if err := c.readAll(refcountsC, &c.refcounts); err != nil {
+		return nil, errors.Trace(err)
+	}
+	return c, nil
+}
+
+func (c *integrityChecker) readAll(collection string, out interface{}) error {
+	coll, closer := c.st.getCollection(collection)
+	defer closer()
+	if err := coll.Find(nil).All(out); err != nil {
+		return errors.Annotatef(err, "reading %s", collection)
+	}
+	return nil
+}
+
+func (c *integrityChecker) check() error {
+	c.checkApplicationCounts()
+	c.checkUnits()
+	c.checkMachines()
+	if err := c.checkRelations(); err != nil {
+		return errors.Trace(err)
+	}
+	c.checkRefcounts()
+	if err := c.checkSettings(); err != nil {
+		return errors.Trace(err)
+	}
+	return nil
+}
+
+// checkApplicationCounts checks that each application's recorded unit
+// and relation counts match the number of unit and relation documents
+// referring to the application.
+func (c *integrityChecker) checkApplicationCounts() {
+	unitCounts := make(map[string]int)
+	for _, unit := range c.units {
+		unitCounts[unit.Application]++
+	}
+	relationCounts := make(map[string]int)
+	for _, relation := range c.relations {
+		for _, ep := range relation.Endpoints {
+			relationCounts[ep.ApplicationName]++
+		}
+	}
+	appNames := set.NewStrings()
+	for name := range c.applications {
+		appNames.Add(name)
+	}
+	for _, name := range appNames.SortedValues() {
+		app := c.applications[name]
+		units, relations := unitCounts[name], relationCounts[name]
+		if units == app.UnitCount && relations == app.RelationCount {
+			continue
+		}
+		// The repair asserts that the application document has not
+		// changed at all since it was read, as the documents it
+		// counts may have changed since, and been recounted wrongly.
+		c.addProblem(
+			IntegrityCountMismatch, applicationsC, name, "",
+			[]txn.Op{{
+				C:      applicationsC,
+				Id:     app.DocID,
+				Assert: bson.D{{"txn-revno", app.TxnRevno}},
+				Update: bson.D{{"$set", bson.D{
+					{"unitcount", units},
+					{"relationcount", relations},
+				}}},
+			}},
+			"application %q records %d units and %d relations, but has %d units and %d relations",
+			name, app.UnitCount, app.RelationCount, units, relations,
+		)
+	}
+}
+
+func (c *integrityChecker) addProblem(
+	kind, collection, docID, detail string,
+	repair []txn.Op,
+	format string, args ...interface{},
+) {
+	id := fmt.Sprintf("%s:%s/%s", kind, collection, docID)
+	if detail != "" {
+		id += ":" + detail
+	}
+	c.problems = append(c.problems, IntegrityProblem{
+		ID:          id,
+		Kind:        kind,
+		Collection:  collection,
+		DocID:       docID,
+		Description: fmt.Sprintf(format, args...),
+		Repair:      repair,
+	})
+}
+
+// checkUnits checks that each unit's application, machine, principal
+// and subordinates exist.
+func (c *integrityChecker) checkUnits() {
+	unitNames := set.NewStrings()
+	for name := range c.units {
+		unitNames.Add(name)
+	}
+	for _, name := range unitNames.SortedValues() {
+		unit := c.units[name]
+		if _, ok := c.applications[unit.Application]; !ok {
+			// The unit's application is gone, so the
+			// unit can never be used or removed.
+			c.addProblem(
+				IntegrityDanglingReference, unitsC, name, "application",
+				[]txn.Op{{
+					C:      unitsC,
+					Id:     unit.DocID,
+					Assert: bson.D{{"application", unit.Application}},
+					Remove: true,
+				}},
+				"unit %q refers to missing application %q", name, unit.Application,
+			)
+		}
+		if unit.MachineId != "" {
+			if _, ok := c.machines[unit.MachineId]; !ok {
+				c.addProblem(
+					IntegrityDanglingReference, unitsC, name, "machineid",
+					[]txn.Op{{
+						C:      unitsC,
+						Id:     unit.DocID,
+						Assert: bson.D{{"machineid", unit.MachineId}},
+						Update: bson.D{{"$set", bson.D{{"machineid", ""}}}},
+					}},
+					"unit %q is assigned to missing machine %q", name, unit.MachineId,
+				)
+			}
+		}
+		if unit.Principal != "" {
+			if _, ok := c.units[unit.Principal]; !ok {
+				// There is no safe way to remove a
+				// subordinate without its principal.
+				c.addProblem(
+					IntegrityDanglingReference, unitsC, name, "principal", nil,
+					"subordinate unit %q refers to missing principal %q", name, unit.Principal,
+				)
+			}
+		}
+		for _, subordinate := range unit.Subordinates {
+			if _, ok := c.units[subordinate]; ok {
+				continue
+			}
+			c.addProblem(
+				IntegrityDanglingReference, unitsC, name, "subordinates/"+subordinate,
+				[]txn.Op{{
+					C:      unitsC,
+					Id:     unit.DocID,
+					Assert: bson.D{{"subordinates", subordinate}},
+					Update: bson.D{{"$pull", bson.D{{"subordinates", subordinate}}}},
+				}},
+				"unit %q refers to missing subordinate %q", name, subordinate,
+			)
+		}
+	}
+}
+
+// checkMachines checks that each machine's principal units exist.
+func (c *integrityChecker) checkMachines() {
+	machineIds := set.NewStrings()
+	for id := range c.machines {
+		machineIds.Add(id)
+	}
+	for _, id := range machineIds.SortedValues() {
+		machine := c.machines[id]
+		for _, principal := range machine.Principals {
+			if _, ok := c.units[principal]; ok {
+				continue
+			}
+			c.addProblem(
+				IntegrityDanglingReference, machinesC, id, "principals/"+principal,
+				[]txn.Op{{
+					C:      machinesC,
+					Id:     machine.DocID,
+					Assert: bson.D{{"principals", principal}},
+					Update: bson.D{{"$pull", bson.D{{"principals", principal}}}},
+				}},
+				"machine %q refers to missing principal unit %q", id, principal,
+			)
+		}
+	}
+}
+
+// checkRelations checks that the applications of each
+// relation's endpoints exist.
+func (c *integrityChecker) checkRelations() error {
+	for _, relation := range c.relations {
+		var missing, remaining []string
+		for _, ep := range relation.Endpoints {
+			if _, ok := c.applications[ep.ApplicationName]; ok {
+				remaining = append(remaining, ep.ApplicationName)
+			} else {
+				missing = append(missing, ep.ApplicationName)
+			}
+		}
+		if len(missing) == 0 {
+			continue
+		}
+		repair, err := c.removeRelationOps(relation, missing, remaining)
+		if err != nil {
+			return errors.Annotatef(err, "computing repair of relation %q", relation.Key)
+		}
+		c.addProblem(
+			IntegrityDanglingReference, relationsC, relation.Key, "", repair,
+			"relation %q refers to missing applications %q", relation.Key, missing,
+		)
+	}
+	return nil
+}
+
+// removeRelationOps returns the operations necessary to remove a
+// relation with missing applications, along with its scopes and
+// settings. A relation cannot be destroyed as usual once one of its
+// applications is missing: units leaving its scope must update the
+// documents of all its applications, so the relation would never be
+// removed. Instead, the relation and its scopes are removed together;
+// the remaining applications release the relation, and its settings
+// are cleaned up, just as when the last unit leaves its scope.
+func (c *integrityChecker) removeRelationOps(doc relationDoc, missing, remaining []string) ([]txn.Op, error) {
+	ops := []txn.Op{{
+		C:      relationsC,
+		Id:     doc.DocID,
+		Assert: bson.D{{"life", doc.Life}, {"unitcount", doc.UnitCount}},
+		Remove: true,
+	}}
+	for _, name := range missing {
+		ops = append(ops, txn.Op{
+			C:      applicationsC,
+			Id:     c.st.docID(name),
+			Assert: txn.DocMissing,
+		})
+	}
+	rel := newRelation(c.st, &doc)
+	for _, name := range remaining {
+		appOps, err := rel.releaseApplicationOps(name)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		ops = append(ops, appOps...)
+	}
+
+	relationScopes, closer := c.st.getCollection(relationScopesC)
+	defer closer()
+	prefix := fmt.Sprintf("r#%d#", doc.Id)
+	var scopes []relationScopeDoc
+	sel := bson.D{{"key", bson.D{{"$regex", "^" + prefix}}}}
+	if err := relationScopes.Find(sel).All(&scopes); err != nil {
+		return nil, errors.Annotate(err, "reading relation scopes")
+	}
+	for _, scope := range scopes {
+		ops = append(ops, txn.Op{
+			C:      relationScopesC,
+			Id:     scope.DocID,
+			Assert: txn.DocExists,
+			Remove: true,
+		})
+	}
+	return append(ops, newCleanupOp(cleanupRelationSettings, prefix)), nil
+}
+
+// checkRefcounts checks that no reference count is negative. The
+// counts of references to applications' settings and storage
+// constraints can be recomputed, and so repaired.
+func (c *integrityChecker) checkRefcounts() {
+	for _, doc := range c.refcounts {
+		if doc.RefCount >= 0 {
+			continue
+		}
+		key := c.st.localID(doc.DocID)
+		var repair []txn.Op
+		if actual, ok := c.applicationCharmRefs(key); ok {
+			repair = []txn.Op{{
+				C:      refcountsC,
+				Id:     doc.DocID,
+				Assert: bson.D{{"refcount", doc.RefCount}},
+				Update: bson.D{{"$set", bson.D{{"refcount", actual}}}},
+			}}
+		}
+		c.addProblem(
+			IntegrityNegativeRefcount, refcountsC, key, "", repair,
+			"reference count %q is negative (%d)", key, doc.RefCount,
+		)
+	}
+}
+
+// applicationCharmRefs returns the number of references to the
+// application settings or storage constraints with the given key,
+// which are held by the application and its units using the charm
+// identified in the key. If the key does not identify application
+// settings or storage constraints, applicationCharmRefs returns false.
+func (c *integrityChecker) applicationCharmRefs(key string) (int, bool) {
+	parts := strings.SplitN(key, "#", 3)
+	if len(parts) != 3 || (parts[0] != "a" && parts[0] != "asc") {
+		return 0, false
+	}
+	appName, curl := parts[1], parts[2]
+	var count int
+	if app, ok := c.applications[appName]; ok && app.CharmURL != nil && app.CharmURL.String() == curl {
+		count++
+	}
+	for _, unit := range c.units {
+		if unit.Application == appName && unit.CharmURL != nil && unit.CharmURL.String() == curl {
+			count++
+		}
+	}
+	return count, true
+}
+
+// checkSettings checks that the settings documents for the charms used
+// by each application and its units exist.
+func (c *integrityChecker) checkSettings() error {
+	keys := set.NewStrings()
+	applicationNames := make(map[string]string)
+	for name, app := range c.applications {
+		if app.CharmURL != nil {
+			key := applicationSettingsKey(name, app.CharmURL)
+			keys.Add(key)
+			applicationNames[key] = name
+		}
+	}
+	for _, unit := range c.units {
+		if _, ok := c.applications[unit.Application]; !ok || unit.CharmURL == nil {
+			continue
+		}
+		key := applicationSettingsKey(unit.Application, unit.CharmURL)
+		keys.Add(key)
+		applicationNames[key] = unit.Application
+	}
+
+	settings, closer := c.st.getCollection(settingsC)
+	defer closer()
+	for _, key := range keys.SortedValues() {
+		n, err := settings.FindId(key).Count()
+		if err != nil {
+			return errors.Annotatef(err, "reading settings %q", key)
+		}
+		if n > 0 {
+			continue
+		}
+		// The settings hold only the values that differ from the
+		// charm's defaults, so empty settings are a safe repair.
+		c.addProblem(
+			IntegrityMissingSettings, settingsC, key, "",
+			[]txn.Op{createSettingsOp(settingsC, key, map[string]interface{}{})},
+			"settings %q for application %q are missing", key, applicationNames[key],
+		)
+	}
+	return nil
+}
@@ -0,0 +1,328 @@
+// Copyright 2016 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package state_test
+
+import (
+	"github.com/juju/errors"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+	"gopkg.in/mgo.v2/bson"
+	"gopkg.in/mgo.v2/txn"
+
+	"github.com/juju/juju/state"
+)
+
+type IntegritySuite struct {
+	ConnSuite
+	wordpress *state.Application
+	mysql     *state.Application
+	unit      *state.Unit
+	machine   *state.Machine
+	relation  *state.Relation
+}
+
+var _ = gc.Suite(&IntegritySuite{})
+
+func (s *IntegritySuite) SetUpTest(c *gc.C) {
+	s.ConnSuite.SetUpTest(c)
+	s.wordpress = s.AddTestingService(c, "wordpress", s.AddTestingCharm(c, "wordpress"))
+	s.mysql = s.AddTestingService(c, "mysql", s.AddTestingCharm(c, "mysql"))
+	var err error
+	s.unit, err = s.wordpress.AddUnit()
+	c.Assert(err, jc.ErrorIsNil)
+	s.machine, err = s.State.AddMachine("quantal", state.JobHostUnits)
+	c.Assert(err, jc.ErrorIsNil)
+	err = s.unit.AssignToMachine(s.machine)
+	c.Assert(err, jc.ErrorIsNil)
+	eps, err := s.State.InferEndpoints("wordpress", "mysql")
+	c.Assert(err, jc.ErrorIsNil)
+	s.relation, err = s.State.AddRelation(eps...)
+	c.Assert(err, jc.ErrorIsNil)
+}
+
+func (s *IntegritySuite) runTransaction(c *gc.C, ops ...txn.Op) {
+	err := state.RunTransaction(s.State, ops)
+	c.Assert(err, jc.ErrorIsNil)
+}
+
+// checkOneProblem checks the model's integrity, asserts that the
+// single problem found matches the expected problem, and returns it.
+func (s *IntegritySuite) checkOneProblem(c *gc.C, expect state.IntegrityProblem) state.IntegrityProblem {
+	problems, err := s.State.CheckIntegrity()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(problems, gc.HasLen, 1)
+	problem := problems[0]
+	c.Assert(problem.ID, gc.Equals, expect.ID)
+	c.Assert(problem.Kind, gc.Equals, expect.Kind)
+	c.Assert(problem.Collection, gc.Equals, expect.Collection)
+	c.Assert(problem.DocID, gc.Equals, expect.DocID)
+	c.Assert(problem.Description, gc.Equals, expect.Description)
+	return problem
+}
+
+// repair repairs the problem with the given ID, and asserts that
+// no problems remain.
+func (s *IntegritySuite) repair(c *gc.C, id string) {
+	errs, err := s.State.RepairIntegrity([]string{id})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(errs, jc.DeepEquals, []error{nil})
+
+	problems, err := s.State.CheckIntegrity()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(problems, gc.HasLen, 0)
+}
+
+func (s *IntegritySuite) TestCheckIntegrityConsistent(c *gc.C) {
+	problems, err := s.State.CheckIntegrity()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(problems, gc.HasLen, 0)
+}
+
+func (s *IntegritySuite) TestUnitMissingMachine(c *gc.C) {
+	s.runTransaction(c, txn.Op{
+		C:      state.UnitsC,
+		Id:     state.DocID(s.State, "wordpress/0"),
+		Update: bson.D{{"$set", bson.D{{"machineid", "42"}}}},
+	})
+	problem := s.checkOneProblem(c, state.IntegrityProblem{
+		ID:          "dangling-reference:units/wordpress/0:machineid",
+		Kind:        state.IntegrityDanglingReference,
+		Collection:  "units",
+		DocID:       "wordpress/0",
+		Description: `unit "wordpress/0" is assigned to missing machine "42"`,
+	})
+	c.Assert(problem.Repair, gc.HasLen, 1)
+
+	s.repair(c, problem.ID)
+	err := s.unit.Refresh()
+	c.Assert(err, jc.ErrorIsNil)
+	_, err = s.unit.AssignedMachineId()
+	c.Assert(err, jc.Satisfies, errors.IsNotAssigned)
+}
+
+func (s *IntegritySuite) TestMachineMissingPrincipal(c *gc.C) {
+	s.runTransaction(c, txn.Op{
+		C:      state.MachinesC,
+		Id:     state.DocID(s.State, s.machine.Id()),
+		Update: bson.D{{"$push", bson.D{{"principals", "ghost/0"}}}},
+	})
+	problem := s.checkOneProblem(c, state.IntegrityProblem{
+		ID:          "dangling-reference:machines/0:principals/ghost/0",
+		Kind:        state.IntegrityDanglingReference,
+		Collection:  "machines",
+		DocID:       "0",
+		Description: `machine "0" refers to missing principal unit "ghost/0"`,
+	})
+
+	s.repair(c, problem.ID)
+	err := s.machine.Refresh()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(s.machine.Principals(), jc.DeepEquals, []string{"wordpress/0"})
+}
+
+func (s *IntegritySuite) TestRelationMissingApplication(c *gc.C) {
+	s.runTransaction(c, txn.Op{
+		C:      state.ApplicationsC,
+		Id:     state.DocID(s.State, "mysql"),
+		Remove: true,
+	})
+	problem := s.checkOneProblem(c, state.IntegrityProblem{
+		ID:          "dangling-reference:relations/wordpress:db mysql:server",
+		Kind:        state.IntegrityDanglingReference,
+		Collection:  "relations",
+		DocID:       "wordpress:db mysql:server",
+		Description: `relation "wordpress:db mysql:server" refers to missing applications ["mysql"]`,
+	})
+
+	s.repair(c, problem.ID)
+	err := s.wordpress.Refresh()
+	c.Assert(err, jc.ErrorIsNil)
+	relations, err := s.wordpress.Relations()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(relations, gc.HasLen, 0)
+
+	// The relation's settings are cleaned up as usual.
+	needsCleanup, err := s.State.NeedsCleanup()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(needsCleanup, jc.IsTrue)
+}
+
+func (s *IntegritySuite) TestRelationMissingApplicationInScope(c *gc.C) {
+	ru, err := s.relation.Unit(s.unit)
+	c.Assert(err, jc.ErrorIsNil)
+	err = ru.EnterScope(nil)
+	c.Assert(err, jc.ErrorIsNil)
+	s.runTransaction(c, txn.Op{
+		C:      state.ApplicationsC,
+		Id:     state.DocID(s.State, "mysql"),
+		Remove: true,
+	})
+	problem := s.checkOneProblem(c, state.IntegrityProblem{
+		ID:          "dangling-reference:relations/wordpress:db mysql:server",
+		Kind:        state.IntegrityDanglingReference,
+		Collection:  "relations",
+		DocID:       "wordpress:db mysql:server",
+		Description: `relation "wordpress:db mysql:server" refers to missing applications ["mysql"]`,
+	})
+
+	// The relation could never be removed once its remaining
+	// members left its scope, so it is removed with its scopes.
+	s.repair(c, problem.ID)
+	err = s.relation.Refresh()
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+	inScope, err := ru.InScope()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(inScope, jc.IsFalse)
+}
+
+func (s *IntegritySuite) setCounts(c *gc.C, app *state.Application, units, relations int) {
+	s.runTransaction(c, txn.Op{
+		C:  state.ApplicationsC,
+		Id: state.DocID(s.State, app.Name()),
+		Update: bson.D{{"$set", bson.D{
+			{"unitcount", units},
+			{"relationcount", relations},
+		}}},
+	})
+}
+
+func (s *IntegritySuite) TestApplicationCountMismatch(c *gc.C) {
+	s.setCounts(c, s.wordpress, 5, 0)
+	problem := s.checkOneProblem(c, state.IntegrityProblem{
+		ID:          "count-mismatch:applications/wordpress",
+		Kind:        state.IntegrityCountMismatch,
+		Collection:  "applications",
+		DocID:       "wordpress",
+		Description: `application "wordpress" records 5 units and 0 relations, but has 1 units and 1 relations`,
+	})
+
+	s.repair(c, problem.ID)
+
+	// Once repaired, units can be removed as usual.
+	err := s.wordpress.Refresh()
+	c.Assert(err, jc.ErrorIsNil)
+	removeAllUnits(c, s.wordpress)
+	assertAllUnits(c, s.wordpress, 0)
+}
+
+func (s *IntegritySuite) TestApplicationCountMismatchConcurrentChange(c *gc.C) {
+	s.setCounts(c, s.wordpress, 5, 1)
+	defer state.SetBeforeHooks(c, s.State, func() {
+		// A unit added concurrently changes the recorded
+		// count, so the repair must be recomputed.
+		_, err := s.wordpress.AddUnit()
+		c.Assert(err, jc.ErrorIsNil)
+	}).Check()
+
+	s.repair(c, "count-mismatch:applications/wordpress")
+	assertAllUnits(c, s.wordpress, 2)
+}
+
+func (s *IntegritySuite) TestNegativeRefcount(c *gc.C) {
+	curl, _ := s.wordpress.CharmURL()
+	key := state.ApplicationSettingsKey("wordpress", curl)
+	s.runTransaction(c, txn.Op{
+		C:      state.RefcountsC,
+		Id:     state.DocID(s.State, key),
+		Update: bson.D{{"$set", bson.D{{"refcount", -1}}}},
+	})
+	problem := s.checkOneProblem(c, state.IntegrityProblem{
+		ID:          "negative-refcount:refcounts/" + key,
+		Kind:        state.IntegrityNegativeRefcount,
+		Collection:  "refcounts",
+		DocID:       key,
+		Description: `reference count "` + key + `" is negative (-1)`,
+	})
+
+	s.repair(c, problem.ID)
+	count, err := state.ServiceSettingsRefCount(s.State, "wordpress", curl)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(count, gc.Equals, 1)
+}
+
+func (s *IntegritySuite) TestMissingSettings(c *gc.C) {
+	curl, _ := s.wordpress.CharmURL()
+	key := state.ApplicationSettingsKey("wordpress", curl)
+	s.runTransaction(c, txn.Op{
+		C:      state.SettingsC,
+		Id:     state.DocID(s.State, key),
+		Remove: true,
+	})
+	problem := s.checkOneProblem(c, state.IntegrityProblem{
+		ID:          "missing-settings:settings/" + key,
+		Kind:        state.IntegrityMissingSettings,
+		Collection:  "settings",
+		DocID:       key,
+		Description: `settings "` + key + `" for application "wordpress" are missing`,
+	})
+
+	s.repair(c, problem.ID)
+	settings, err := s.wordpress.ConfigSettings()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(settings, gc.HasLen, 0)
+}
+
+func (s *IntegritySuite) TestRepairIntegrityErrors(c *gc.C) {
+	s.runTransaction(c, txn.Op{
+		C:      state.UnitsC,
+		Id:     state.DocID(s.State, "wordpress/0"),
+		Update: bson.D{{"$set", bson.D{{"principal", "ghost/0"}}}},
+	})
+	problem := s.checkOneProblem(c, state.IntegrityProblem{
+		ID:          "dangling-reference:units/wordpress/0:principal",
+		Kind:        state.IntegrityDanglingReference,
+		Collection:  "units",
+		DocID:       "wordpress/0",
+		Description: `subordinate unit "wordpress/0" refers to missing principal "ghost/0"`,
+	})
+	c.Assert(problem.Repair, gc.HasLen, 0)
+
+	errs, err := s.State.RepairIntegrity([]string{problem.ID, "bogus"})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(errs, gc.HasLen, 2)
+	c.Assert(errs[0], gc.ErrorMatches, `automatic repair of integrity problem ".*" not supported`)
+	c.Assert(errs[0], jc.Satisfies, errors.IsNotSupported)
+	c.Assert(errs[1], gc.ErrorMatches, `integrity problem "bogus" not found`)
+	c.Assert(errs[1], jc.Satisfies, errors.IsNotFound)
+}
+
+func (s *IntegritySuite) setUnitMachineId(c *gc.C, machineId string) {
+	s.runTransaction(c, txn.Op{
+		C:      state.UnitsC,
+		Id:     state.DocID(s.State, "wordpress/0"),
+		Update: bson.D{{"$set", bson.D{{"machineid", machineId}}}},
+	})
+}
+
+func (s *IntegritySuite) TestRepairIntegrityConcurrentChange(c *gc.C) {
+	s.setUnitMachineId(c, "42")
+	defer state.SetBeforeHooks(c, s.State, func() {
+		s.setUnitMachineId(c, "43")
+	}).Check()
+
+	// The problem is checked again, and its new repair applied.
+	s.repair(c, "dangling-reference:units/wordpress/0:machineid")
+	err := s.unit.Refresh()
+	c.Assert(err, jc.ErrorIsNil)
+	_, err = s.unit.AssignedMachineId()
+	c.Assert(err, jc.Satisfies, errors.IsNotAssigned)
+}
+
+func (s *IntegritySuite) TestRepairIntegrityConcurrentChanges(c *gc.C) {
+	s.setUnitMachineId(c, "42")
+	defer state.SetBeforeHooks(c, s.State, func() {
+		s.setUnitMachineId(c, "43")
+	}, func() {
+		s.setUnitMachineId(c, "44")
+	}, func() {
+		s.setUnitMachineId(c, "45")
+	}).Check()
+
+	errs, err := s.State.RepairIntegrity([]string{
+		"dangling-reference:units/wordpress/0:machineid",
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(errs, gc.HasLen, 1)
+	c.Assert(errs[0], gc.ErrorMatches, `cannot repair integrity problem ".*": documents changed since checked`)
+}
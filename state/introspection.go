@@ -0,0 +1,145 @@
+// Copyright 2016 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package state
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/juju/errors"
+	"gopkg.in/mgo.v2/bson"
+)
+
+const (
+	// txnQueueReportMinLength is the minimum length of a document's
+	// transaction queue for the document to be included in a TxnReport.
+	txnQueueReportMinLength = 10
+
+	// txnQueueReportMax is the maximum number of documents included
+	// in a TxnReport.
+	txnQueueReportMax = 20
+)
+
+// txnStates maps the states of incomplete transactions, as recorded
+// by mgo/txn, to their names.
+var txnStates = []struct {
+	state int
+	name  string
+}{
+	{1, "preparing"},
+	{2, "prepared"},
+	{3, "aborting"},
+	{4, "applying"},
+}
+
+// TxnReport describes the transactions that are yet to be completed,
+// to help diagnose transactions that are not progressing.
+type TxnReport struct {
+	// Incomplete holds the number of transactions in each of the
+	// incomplete states, keyed by the state's name.
+	Incomplete map[string]int
+
+	// Queues holds the documents with the longest transaction queues,
+	// longest first. Only documents whose queues have at least
+	// txnQueueReportMinLength transactions are included.
+	Queues []TxnQueue
+}
+
+// TxnQueue describes the transaction queue of a document.
+type TxnQueue struct {
+	// Collection and Id identify the document.
+	Collection string
+	Id         string
+
+	// Length is the number of transactions in the queue.
+	Length int
+}
+
+// TxnReport returns a report of the transactions that are yet to be
+// completed, across all models. The report is intended for diagnosis
+// only; it may be expensive to compute on a large database.
+func (st *State) TxnReport() (TxnReport, error) {
+	report := TxnReport{Incomplete: make(map[string]int)}
+
+	txns, closer := st.getRawCollection(txnsC)
+	defer closer()
+	for _, s := range txnStates {
+		n, err := txns.Find(bson.D{{"s", s.state}}).Count()
+		if err != nil {
+			return TxnReport{}, errors.Annotatef(err, "counting %s transactions", s.name)
+		}
+		report.Incomplete[s.name] = n
+	}
+
+	var collections []string
+	for name, info := range allCollections() {
+		if !info.rawAccess {
+			collections = append(collections, name)
+		}
+	}
+	sort.Strings(collections)
+	for _, name := range collections {
+		queues, err := st.longTxnQueues(name)
+		if err != nil {
+			return TxnReport{}, errors.Trace(err)
+		}
+		report.Queues = append(report.Queues, queues...)
+	}
+	sort.Stable(byTxnQueueLength(report.Queues))
+	if len(report.Queues) > txnQueueReportMax {
+		report.Queues = report.Queues[:txnQueueReportMax]
+	}
+	return report, nil
+}
+
+// longTxnQueues returns the documents in the named collection whose
+// transaction queues have at least txnQueueReportMinLength transactions.
+func (st *State) longTxnQueues(collection string) ([]TxnQueue, error) {
+	coll, closer := st.getRawCollection(collection)
+	defer closer()
+
+	// The query matches documents whose queues have an element at
+	// the index txnQueueReportMinLength-1, without examining every
+	// queue in the collection.
+	longQueue := fmt.Sprintf("txn-queue.%d", txnQueueReportMinLength-1)
+	iter := coll.Find(bson.D{{longQueue, bson.D{{"$exists", true}}}}).
+		Select(bson.D{{"_id", 1}, {"txn-queue", 1}}).
+		Limit(txnQueueReportMax).
+		Iter()
+	var doc struct {
+		Id    interface{} `bson:"_id"`
+		Queue []string    `bson:"txn-queue"`
+	}
+	var queues []TxnQueue
+	for iter.Next(&doc) {
+		queues = append(queues, TxnQueue{
+			Collection: collection,
+			Id:         fmt.Sprint(doc.Id),
+			Length:     len(doc.Queue),
+		})
+	}
+	if err := iter.Close(); err != nil {
+		return nil, errors.Annotatef(err, "reading transaction queues in %s", collection)
+	}
+	return queues, nil
+}
+
+type byTxnQueueLength []TxnQueue
+
+func (q byTxnQueueLength) Len() int           { return len(q) }
+func (q byTxnQueueLength) Swap(i, j int)      { q[i], q[j] = q[j], q[i] }
+func (q byTxnQueueLength) Less(i, j int) bool { return q[i].Length > q[j].Length }
+
+// WatcherReport returns a report of the documents and collections being
+// watched for changes in the database, keyed by collection name.
+func (st *State) WatcherReport() map[string]interface{} {
+	return st.workers.TxnLogWatcher().Report()
+}
+
+// UnwatchOwner stops all of the watches registered in the database
+// watcher on behalf of the given owner, such as the watches of an API
+// connection that has been closed.
+func (st *State) UnwatchOwner(owner string) {
+	st.workers.TxnLogWatcher().UnwatchOwner(owner)
+}
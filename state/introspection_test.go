@@ -0,0 +1,71 @@
+// Copyright 2016 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package state_test
+
+import (
+	"fmt"
+
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+	"gopkg.in/mgo.v2/bson"
+
+	"github.com/juju/juju/state"
+)
+
+type IntrospectionSuite struct {
+	ConnSuite
+}
+
+var _ = gc.Suite(&IntrospectionSuite{})
+
+func (s *IntrospectionSuite) TestTxnReportEmpty(c *gc.C) {
+	report, err := s.State.TxnReport()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(report, jc.DeepEquals, state.TxnReport{
+		Incomplete: map[string]int{
+			"preparing": 0,
+			"prepared":  0,
+			"aborting":  0,
+			"applying":  0,
+		},
+	})
+}
+
+func (s *IntrospectionSuite) TestTxnReport(c *gc.C) {
+	// Documents are inserted directly, so as not to disturb the
+	// transactions of the documents that state manages.
+	txns, closer := state.GetRawCollection(s.State, "txns")
+	defer closer()
+	err := txns.Insert(bson.D{{"_id", bson.NewObjectId()}, {"s", 2}})
+	c.Assert(err, jc.ErrorIsNil)
+	defer txns.RemoveAll(bson.D{{"s", 2}})
+
+	annotations, closer := state.GetRawCollection(s.State, "annotations")
+	defer closer()
+	for id, length := range map[string]int{"short": 9, "long": 10, "longer": 15} {
+		queue := make([]string, length)
+		for i := range queue {
+			queue[i] = fmt.Sprintf("%s_%d", bson.NewObjectId().Hex(), i)
+		}
+		err := annotations.Insert(bson.D{{"_id", id}, {"txn-queue", queue}})
+		c.Assert(err, jc.ErrorIsNil)
+	}
+
+	report, err := s.State.TxnReport()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(report.Incomplete["prepared"], gc.Equals, 1)
+	c.Assert(report.Queues, jc.DeepEquals, []state.TxnQueue{
+		{Collection: "annotations", Id: "longer", Length: 15},
+		{Collection: "annotations", Id: "long", Length: 10},
+	})
+}
+
+func (s *IntrospectionSuite) TestWatcherReport(c *gc.C) {
+	w := s.State.WatchModels()
+	defer w.Stop()
+	s.State.StartSync()
+
+	report := s.State.WatcherReport()
+	c.Assert(report["models"], gc.NotNil)
+}
@@ -0,0 +1,136 @@
+// Copyright 2016 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package state
+
+import (
+	"github.com/juju/errors"
+	"gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
+)
+
+// defaultIterBatchSize is the number of documents fetched from the
+// database at a time by iterators, when no batch size is specified.
+const defaultIterBatchSize = 500
+
+// MachineIterator iterates over the machines in a model, fetching
+// their documents from the database in batches, so that the model's
+// machines need not all be held in memory at once.
+//
+// MachineIterator is not safe for concurrent use. The iterator must
+// be closed when it is no longer needed.
+type MachineIterator struct {
+	st      *State
+	iter    *mgo.Iter
+	closer  SessionCloser
+	machine *Machine
+	err     error
+}
+
+// MachinesIter returns an iterator over the machines in the model,
+// fetching batchSize machine documents at a time. If batchSize is zero,
+// a default batch size is used.
+//
+// Unlike AllMachines, the machines are ordered by their IDs' string
+// representation, e.g. "10" comes before "2". Every container is
+// nevertheless preceded by its parent machine.
+func (st *State) MachinesIter(batchSize int) *MachineIterator {
+	if batchSize <= 0 {
+		batchSize = defaultIterBatchSize
+	}
+	machinesCollection, closer := st.getCollection(machinesC)
+	// A machine document's ID is the model UUID followed by the machine
+	// ID, so sorting on the ID orders machines before their containers.
+	iter := machinesCollection.Find(nil).Sort("_id").Batch(batchSize).Iter()
+	return &MachineIterator{st: st, iter: iter, closer: closer}
+}
+
+// Next advances the iterator to the next machine, returning false if
+// there are no more machines or an error occurred, in which case the
+// error is returned by Close.
+func (it *MachineIterator) Next() bool {
+	var doc machineDoc
+	if !it.iter.Next(&doc) {
+		it.machine = nil
+		return false
+	}
+	it.machine = newMachine(it.st, &doc)
+	return true
+}
+
+// Machine returns the machine most recently returned by Next.
+func (it *MachineIterator) Machine() *Machine {
+	return it.machine
+}
+
+// Close closes the iterator, and returns any error that occurred
+// while iterating. Close may be called more than once.
+func (it *MachineIterator) Close() error {
+	if it.closer != nil {
+		it.err = it.iter.Close()
+		it.closer()
+		it.closer = nil
+	}
+	return errors.Annotate(it.err, "cannot iterate over machines")
+}
+
+// UnitIterator iterates over the units of an application, fetching
+// their documents from the database in batches, so that the units
+// need not all be held in memory at once.
+//
+// UnitIterator is not safe for concurrent use. The iterator must be
+// closed when it is no longer needed.
+type UnitIterator struct {
+	st          *State
+	application string
+	iter        *mgo.Iter
+	closer      SessionCloser
+	unit        *Unit
+	err         error
+}
+
+// UnitsIter returns an iterator over the application's units, fetching
+// batchSize unit documents at a time. If batchSize is zero, a default
+// batch size is used. The units are returned in no particular order.
+func (a *Application) UnitsIter(batchSize int) *UnitIterator {
+	if batchSize <= 0 {
+		batchSize = defaultIterBatchSize
+	}
+	unitsCollection, closer := a.st.getCollection(unitsC)
+	iter := unitsCollection.Find(bson.D{{"application", a.doc.Name}}).Batch(batchSize).Iter()
+	return &UnitIterator{
+		st:          a.st,
+		application: a.doc.Name,
+		iter:        iter,
+		closer:      closer,
+	}
+}
+
+// Next advances the iterator to the next unit, returning false if
+// there are no more units or an error occurred, in which case the
+// error is returned by Close.
+func (it *UnitIterator) Next() bool {
+	var doc unitDoc
+	if !it.iter.Next(&doc) {
+		it.unit = nil
+		return false
+	}
+	it.unit = newUnit(it.st, &doc)
+	return true
+}
+
+// Unit returns the unit most recently returned by Next.
+func (it *UnitIterator) Unit() *Unit {
+	return it.unit
+}
+
+// Close closes the iterator, and returns any error that occurred
+// while iterating. Close may be called more than once.
+func (it *UnitIterator) Close() error {
+	if it.closer != nil {
+		it.err = it.iter.Close()
+		it.closer()
+		it.closer = nil
+	}
+	return errors.Annotatef(it.err, "cannot iterate over units of application %q", it.application)
+}
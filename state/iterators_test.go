@@ -0,0 +1,80 @@
+// Copyright 2016 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package state_test
+
+import (
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/instance"
+	"github.com/juju/juju/state"
+)
+
+type IteratorsSuite struct {
+	ConnSuite
+}
+
+var _ = gc.Suite(&IteratorsSuite{})
+
+func (s *IteratorsSuite) TestMachinesIter(c *gc.C) {
+	for i := 0; i < 12; i++ {
+		_, err := s.State.AddMachine("quantal", state.JobHostUnits)
+		c.Assert(err, jc.ErrorIsNil)
+	}
+	_, err := s.State.AddMachineInsideMachine(state.MachineTemplate{
+		Series: "quantal",
+		Jobs:   []state.MachineJob{state.JobHostUnits},
+	}, "1", instance.LXD)
+	c.Assert(err, jc.ErrorIsNil)
+
+	iter := s.State.MachinesIter(5)
+	var ids []string
+	for iter.Next() {
+		ids = append(ids, iter.Machine().Id())
+	}
+	c.Assert(iter.Close(), jc.ErrorIsNil)
+	c.Assert(iter.Machine(), gc.IsNil)
+	c.Assert(ids, jc.DeepEquals, []string{
+		"0", "1", "1/lxd/0", "10", "11", "2", "3", "4", "5", "6", "7", "8", "9",
+	})
+
+	// The machines are fully populated.
+	m, err := s.State.Machine("1/lxd/0")
+	c.Assert(err, jc.ErrorIsNil)
+	iter = s.State.MachinesIter(0)
+	defer iter.Close()
+	for iter.Next() {
+		if iter.Machine().Id() == m.Id() {
+			c.Assert(iter.Machine().Series(), gc.Equals, m.Series())
+			c.Assert(iter.Machine().Jobs(), jc.DeepEquals, m.Jobs())
+		}
+	}
+}
+
+func (s *IteratorsSuite) TestMachinesIterNoMachines(c *gc.C) {
+	iter := s.State.MachinesIter(0)
+	c.Assert(iter.Next(), jc.IsFalse)
+	c.Assert(iter.Close(), jc.ErrorIsNil)
+	// Close may be called more than once.
+	c.Assert(iter.Close(), jc.ErrorIsNil)
+}
+
+func (s *IteratorsSuite) TestUnitsIter(c *gc.C) {
+	wordpress := s.AddTestingService(c, "wordpress", s.AddTestingCharm(c, "wordpress"))
+	mysql := s.AddTestingService(c, "mysql", s.AddTestingCharm(c, "mysql"))
+	for i := 0; i < 3; i++ {
+		_, err := wordpress.AddUnit()
+		c.Assert(err, jc.ErrorIsNil)
+	}
+	_, err := mysql.AddUnit()
+	c.Assert(err, jc.ErrorIsNil)
+
+	iter := wordpress.UnitsIter(2)
+	var names []string
+	for iter.Next() {
+		names = append(names, iter.Unit().Name())
+	}
+	c.Assert(iter.Close(), jc.ErrorIsNil)
+	c.Assert(names, jc.SameContents, []string{"wordpress/0", "wordpress/1", "wordpress/2"})
+}
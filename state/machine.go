@@ -239,6 +239,59 @@ func (m *Machine) HardwareCharacteristics() (*instance.HardwareCharacteristics,
 	return hardwareCharacteristics(instData), nil
 }
 
+// SetHardwareCharacteristics updates the recorded hardware characteristics
+// of the provisioned machine, for when its instance has been resized since
+// it was provisioned. Only the specified characteristics are updated. The
+// architecture and availability zone of an instance cannot change, and so
+// must not be specified.
+func (m *Machine) SetHardwareCharacteristics(characteristics instance.HardwareCharacteristics) (err error) {
+	defer errors.DeferredAnnotatef(&err, "cannot set hardware characteristics for machine %q", m)
+	if characteristics.Arch != nil {
+		return errors.NotValidf("changing architecture")
+	}
+	if characteristics.AvailabilityZone != nil {
+		return errors.NotValidf("changing availability zone")
+	}
+	var fields bson.D
+	if characteristics.Mem != nil {
+		fields = append(fields, bson.DocElem{"mem", *characteristics.Mem})
+	}
+	if characteristics.RootDisk != nil {
+		fields = append(fields, bson.DocElem{"rootdisk", *characteristics.RootDisk})
+	}
+	if characteristics.CpuCores != nil {
+		fields = append(fields, bson.DocElem{"cpucores", *characteristics.CpuCores})
+	}
+	if characteristics.CpuPower != nil {
+		fields = append(fields, bson.DocElem{"cpupower", *characteristics.CpuPower})
+	}
+	if characteristics.Tags != nil {
+		fields = append(fields, bson.DocElem{"tags", *characteristics.Tags})
+	}
+	if len(fields) == 0 {
+		return nil
+	}
+	ops := []txn.Op{{
+		C:      machinesC,
+		Id:     m.doc.DocID,
+		Assert: notDeadDoc,
+	}, {
+		C:      instanceDataC,
+		Id:     m.doc.DocID,
+		Assert: txn.DocExists,
+		Update: bson.D{{"$set", fields}},
+	}}
+	if err = m.st.runTransaction(ops); err != txn.ErrAborted {
+		return err
+	}
+	if notDead, err := isNotDead(m.st, machinesC, m.doc.DocID); err != nil {
+		return err
+	} else if !notDead {
+		return ErrDead
+	}
+	return errors.NotProvisionedf("machine %v", m.Id())
+}
+
 func getInstanceData(st *State, id string) (instanceData, error) {
 	instanceDataCollection, closer := st.getCollection(instanceDataC)
 	defer closer()
@@ -848,6 +901,8 @@ func (m *Machine) removeOps() ([]txn.Op, error) {
 		removeMachineBlockDevicesOp(m.Id()),
 		removeModelMachineRefOp(m.st, m.Id()),
 		removeSSHHostKeyOp(m.st, m.globalKey()),
+		removeProvisioningErrorOp(m.doc.DocID),
+		removeMachineReplacementOp(m.doc.DocID),
 	}
 	linkLayerDevicesOps, err := m.removeAllLinkLayerDevicesOps()
 	if err != nil {
@@ -861,6 +916,12 @@ func (m *Machine) removeOps() ([]txn.Op, error) {
 	if err != nil {
 		return nil, errors.Trace(err)
 	}
+	// The machine's claims are released before its filesystems and
+	// volumes are removed, as those claims are removed with them.
+	claimOps, err := m.st.removeMachineStorageClaimsOps(m.MachineTag())
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
 	filesystemOps, err := m.st.removeMachineFilesystemsOps(m.MachineTag())
 	if err != nil {
 		return nil, errors.Trace(err)
@@ -873,6 +934,7 @@ func (m *Machine) removeOps() ([]txn.Op, error) {
 	ops = append(ops, devicesAddressesOps...)
 	ops = append(ops, portsOps...)
 	ops = append(ops, removeContainerRefOps(m.st, m.Id())...)
+	ops = append(ops, claimOps...)
 	ops = append(ops, filesystemOps...)
 	ops = append(ops, volumeOps...)
 	return ops, nil
@@ -1109,6 +1171,7 @@ func (m *Machine) SetProvisioned(id instance.Id, nonce string, characteristics *
 			Assert: txn.DocMissing,
 			Insert: instData,
 		},
+		removeProvisioningErrorOp(m.doc.DocID),
 	}
 
 	if err = m.st.runTransaction(ops); err == nil {
@@ -1525,11 +1588,21 @@ func (m *Machine) Status() (status.StatusInfo, error) {
 
 // SetStatus sets the status of the machine.
 func (m *Machine) SetStatus(statusInfo status.StatusInfo) error {
+	params, err := m.statusParams(statusInfo)
+	if err != nil {
+		return err
+	}
+	return setStatus(m.st, params)
+}
+
+// statusParams validates the given status, and returns the
+// parameters with which to set it on the machine.
+func (m *Machine) statusParams(statusInfo status.StatusInfo) (setStatusParams, error) {
 	switch statusInfo.Status {
 	case status.Started, status.Stopped:
 	case status.Error:
 		if statusInfo.Message == "" {
-			return errors.Errorf("cannot set status %q without info", statusInfo.Status)
+			return setStatusParams{}, errors.Errorf("cannot set status %q without info", statusInfo.Status)
 		}
 	case status.Pending:
 		// If a machine is not yet provisioned, we allow its status
@@ -1541,18 +1614,18 @@ func (m *Machine) SetStatus(statusInfo status.StatusInfo) error {
 		}
 		fallthrough
 	case status.Down:
-		return errors.Errorf("cannot set status %q", statusInfo.Status)
+		return setStatusParams{}, errors.Errorf("cannot set status %q", statusInfo.Status)
 	default:
-		return errors.Errorf("cannot set invalid status %q", statusInfo.Status)
+		return setStatusParams{}, errors.Errorf("cannot set invalid status %q", statusInfo.Status)
 	}
-	return setStatus(m.st, setStatusParams{
+	return setStatusParams{
 		badge:     "machine",
 		globalKey: m.globalKey(),
 		status:    statusInfo.Status,
 		message:   statusInfo.Message,
 		rawData:   statusInfo.Data,
 		updated:   statusInfo.Since,
-	})
+	}, nil
 }
 
 // StatusHistory returns a slice of at most filter.Size StatusInfo items
@@ -759,6 +759,70 @@ func (s *MachineSuite) TestMachineSetProvisionedUpdatesCharacteristics(c *gc.C)
 	c.Assert(*md, gc.DeepEquals, *expected)
 }
 
+func (s *MachineSuite) TestMachineSetHardwareCharacteristics(c *gc.C) {
+	arch := "amd64"
+	mem := uint64(4096)
+	cores := uint64(2)
+	err := s.machine.SetProvisioned("umbrella/0", "fake_nonce", &instance.HardwareCharacteristics{
+		Arch:     &arch,
+		Mem:      &mem,
+		CpuCores: &cores,
+	})
+	c.Assert(err, jc.ErrorIsNil)
+
+	w := s.machine.WatchHardwareCharacteristics()
+	defer testing.AssertStop(c, w)
+	wc := testing.NewNotifyWatcherC(c, s.State, w)
+	wc.AssertOneChange()
+
+	newMem := uint64(8192)
+	rootDisk := uint64(32768)
+	err = s.machine.SetHardwareCharacteristics(instance.HardwareCharacteristics{
+		Mem:      &newMem,
+		RootDisk: &rootDisk,
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	wc.AssertOneChange()
+
+	md, err := s.machine.HardwareCharacteristics()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(*md, jc.DeepEquals, instance.HardwareCharacteristics{
+		Arch:     &arch,
+		Mem:      &newMem,
+		RootDisk: &rootDisk,
+		CpuCores: &cores,
+	})
+}
+
+func (s *MachineSuite) TestMachineSetHardwareCharacteristicsInvalid(c *gc.C) {
+	err := s.machine.SetProvisioned("umbrella/0", "fake_nonce", nil)
+	c.Assert(err, jc.ErrorIsNil)
+	arch := "arm64"
+	err = s.machine.SetHardwareCharacteristics(instance.HardwareCharacteristics{Arch: &arch})
+	c.Assert(err, jc.Satisfies, errors.IsNotValid)
+	c.Assert(err, gc.ErrorMatches, `cannot set hardware characteristics for machine "1": changing architecture not valid`)
+	zone := "a_zone"
+	err = s.machine.SetHardwareCharacteristics(instance.HardwareCharacteristics{AvailabilityZone: &zone})
+	c.Assert(err, gc.ErrorMatches, `cannot set hardware characteristics for machine "1": changing availability zone not valid`)
+}
+
+func (s *MachineSuite) TestMachineSetHardwareCharacteristicsNotProvisioned(c *gc.C) {
+	mem := uint64(8192)
+	err := s.machine.SetHardwareCharacteristics(instance.HardwareCharacteristics{Mem: &mem})
+	c.Assert(err, jc.Satisfies, errors.IsNotProvisioned)
+	c.Assert(err, gc.ErrorMatches, `cannot set hardware characteristics for machine "1": machine 1 not provisioned`)
+}
+
+func (s *MachineSuite) TestMachineSetHardwareCharacteristicsDead(c *gc.C) {
+	err := s.machine.SetProvisioned("umbrella/0", "fake_nonce", nil)
+	c.Assert(err, jc.ErrorIsNil)
+	err = s.machine.EnsureDead()
+	c.Assert(err, jc.ErrorIsNil)
+	mem := uint64(8192)
+	err = s.machine.SetHardwareCharacteristics(instance.HardwareCharacteristics{Mem: &mem})
+	c.Assert(err, gc.ErrorMatches, `cannot set hardware characteristics for machine "1": not found or dead`)
+}
+
 func (s *MachineSuite) TestMachineAvailabilityZone(c *gc.C) {
 	zone := "a_zone"
 	hwc := &instance.HardwareCharacteristics{
@@ -0,0 +1,212 @@
+// Copyright 2016 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package state
+
+import (
+	"sort"
+
+	"github.com/juju/errors"
+	jujutxn "github.com/juju/txn"
+	"gopkg.in/juju/names.v2"
+	"gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
+	"gopkg.in/mgo.v2/txn"
+
+	"github.com/juju/juju/instance"
+)
+
+// machineReplacementDoc records that the instance of a machine is to
+// be replaced by the provisioner, e.g. because it was deleted outside
+// of Juju. The record is removed when the instance is replaced.
+type machineReplacementDoc struct {
+	DocID      string      `bson:"_id"`
+	ModelUUID  string      `bson:"model-uuid"`
+	MachineId  string      `bson:"machine-id"`
+	InstanceId instance.Id `bson:"instance-id"`
+}
+
+// RequestReplacement records that the machine's instance is to be
+// replaced by the provisioner with a new instance, which will run the
+// machine's agent and have the machine's volumes attached. This is
+// intended for recovering machines whose instances were removed from
+// the cloud outside of Juju. Controller machines, containers and
+// manually provisioned machines cannot be replaced. It is not an error
+// to request the replacement of a machine more than once.
+func (m *Machine) RequestReplacement() (err error) {
+	defer errors.DeferredAnnotatef(&err, "cannot request replacement of machine %v", m)
+	if m.IsManager() {
+		return errors.NotSupportedf("replacing controller machine")
+	}
+	if m.ContainerType() != "" {
+		return errors.NotSupportedf("replacing container")
+	}
+	manual, err := m.IsManual()
+	if err != nil {
+		return errors.Trace(err)
+	}
+	if manual {
+		return errors.NotSupportedf("replacing manually provisioned machine")
+	}
+	buildTxn := func(attempt int) ([]txn.Op, error) {
+		if attempt > 0 {
+			if err := m.Refresh(); err != nil {
+				return nil, errors.Trace(err)
+			}
+		}
+		if m.doc.Life != Alive {
+			return nil, errNotAlive
+		}
+		requested, err := m.ReplacementRequested()
+		if err != nil {
+			return nil, errors.Trace(err)
+		} else if requested {
+			return nil, jujutxn.ErrNoOperations
+		}
+		instId, err := m.InstanceId()
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		return []txn.Op{{
+			C:      machinesC,
+			Id:     m.doc.DocID,
+			Assert: isAliveDoc,
+		}, {
+			C:      instanceDataC,
+			Id:     m.doc.DocID,
+			Assert: bson.D{{"instanceid", instId}},
+		}, {
+			C:      machineReplacementsC,
+			Id:     m.doc.DocID,
+			Assert: txn.DocMissing,
+			Insert: &machineReplacementDoc{
+				DocID:      m.doc.DocID,
+				ModelUUID:  m.st.ModelUUID(),
+				MachineId:  m.doc.Id,
+				InstanceId: instId,
+			},
+		}}, nil
+	}
+	return m.st.run(buildTxn)
+}
+
+// ReplacementRequested reports whether the replacement of the machine's
+// instance has been requested, and not yet carried out.
+func (m *Machine) ReplacementRequested() (bool, error) {
+	coll, closer := m.st.getCollection(machineReplacementsC)
+	defer closer()
+
+	n, err := coll.FindId(m.doc.DocID).Count()
+	if err != nil {
+		return false, errors.Annotatef(err, "cannot get replacement of machine %v", m)
+	}
+	return n > 0, nil
+}
+
+// MachinesToReplace returns the sorted IDs of the machines whose
+// instances have been requested to be replaced.
+func (st *State) MachinesToReplace() ([]string, error) {
+	coll, closer := st.getCollection(machineReplacementsC)
+	defer closer()
+
+	var docs []machineReplacementDoc
+	if err := coll.Find(nil).All(&docs); err != nil {
+		return nil, errors.Annotate(err, "cannot get machine replacements")
+	}
+	ids := make([]string, len(docs))
+	for i, doc := range docs {
+		ids[i] = doc.MachineId
+	}
+	sort.Strings(ids)
+	return ids, nil
+}
+
+// ReplaceInstance records that the machine's instance, whose replacement
+// was requested with RequestReplacement, has been replaced by the
+// instance with the given ID and hardware characteristics. The nonce is
+// that with which the new instance's agent was provisioned; the agent of
+// the old instance, if it still exists, will no longer be able to act
+// for the machine. The given info is recorded for the volume attachments
+// that were re-established on the new instance.
+//
+// The machine's instance ID, nonce and volume attachment info are
+// updated in a single transaction.
+func (m *Machine) ReplaceInstance(
+	id instance.Id, nonce string,
+	characteristics *instance.HardwareCharacteristics,
+	volumeAttachments map[names.VolumeTag]VolumeAttachmentInfo,
+) (err error) {
+	defer errors.DeferredAnnotatef(&err, "cannot replace instance of machine %v", m)
+	if id == "" || nonce == "" {
+		return errors.New("instance id and nonce cannot be empty")
+	}
+	if characteristics == nil {
+		characteristics = &instance.HardwareCharacteristics{}
+	}
+	replacements, closer := m.st.getCollection(machineReplacementsC)
+	defer closer()
+
+	buildTxn := func(attempt int) ([]txn.Op, error) {
+		if attempt > 0 {
+			if err := m.Refresh(); err != nil {
+				return nil, errors.Trace(err)
+			}
+		}
+		if m.doc.Life != Alive {
+			return nil, errNotAlive
+		}
+		var doc machineReplacementDoc
+		if err := replacements.FindId(m.doc.DocID).One(&doc); err == mgo.ErrNotFound {
+			return nil, errors.NotFoundf("replacement request")
+		} else if err != nil {
+			return nil, errors.Trace(err)
+		}
+		ops := []txn.Op{{
+			C:      machinesC,
+			Id:     m.doc.DocID,
+			Assert: isAliveDoc,
+			Update: bson.D{{"$set", bson.D{{"nonce", nonce}}}},
+		}, {
+			C:      instanceDataC,
+			Id:     m.doc.DocID,
+			Assert: bson.D{{"instanceid", doc.InstanceId}},
+			Update: bson.D{{"$set", bson.D{
+				{"instanceid", id},
+				{"arch", characteristics.Arch},
+				{"mem", characteristics.Mem},
+				{"rootdisk", characteristics.RootDisk},
+				{"cpucores", characteristics.CpuCores},
+				{"cpupower", characteristics.CpuPower},
+				{"tags", characteristics.Tags},
+				{"availzone", characteristics.AvailabilityZone},
+			}}},
+		}, {
+			C:      machineReplacementsC,
+			Id:     m.doc.DocID,
+			Assert: txn.DocExists,
+			Remove: true,
+		}}
+		for volumeTag, info := range volumeAttachments {
+			ops = append(ops, setVolumeAttachmentInfoOps(
+				m.MachineTag(), volumeTag, info, false,
+			)...)
+		}
+		return ops, nil
+	}
+	if err := m.st.run(buildTxn); err != nil {
+		return errors.Trace(err)
+	}
+	m.doc.Nonce = nonce
+	return nil
+}
+
+// removeMachineReplacementOp returns the operation required to remove
+// the replacement request of the machine with the given doc ID, if it
+// has one.
+func removeMachineReplacementOp(machineDocID string) txn.Op {
+	return txn.Op{
+		C:      machineReplacementsC,
+		Id:     machineDocID,
+		Remove: true,
+	}
+}
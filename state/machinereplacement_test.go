@@ -0,0 +1,171 @@
+// Copyright 2016 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package state_test
+
+import (
+	"github.com/juju/errors"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+	"gopkg.in/juju/names.v2"
+
+	"github.com/juju/juju/instance"
+	"github.com/juju/juju/state"
+)
+
+type MachineReplacementSuite struct {
+	StorageStateSuiteBase
+}
+
+var _ = gc.Suite(&MachineReplacementSuite{})
+
+func (s *MachineReplacementSuite) addProvisionedMachine(c *gc.C) *state.Machine {
+	m, err := s.State.AddMachine("quantal", state.JobHostUnits)
+	c.Assert(err, jc.ErrorIsNil)
+	err = m.SetProvisioned("inst-0", "nonce-0", nil)
+	c.Assert(err, jc.ErrorIsNil)
+	return m
+}
+
+func (s *MachineReplacementSuite) TestRequestReplacement(c *gc.C) {
+	m := s.addProvisionedMachine(c)
+	requested, err := m.ReplacementRequested()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(requested, jc.IsFalse)
+
+	err = m.RequestReplacement()
+	c.Assert(err, jc.ErrorIsNil)
+	// Requesting it again is a no-op.
+	err = m.RequestReplacement()
+	c.Assert(err, jc.ErrorIsNil)
+
+	requested, err = m.ReplacementRequested()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(requested, jc.IsTrue)
+	ids, err := s.State.MachinesToReplace()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(ids, jc.DeepEquals, []string{m.Id()})
+}
+
+func (s *MachineReplacementSuite) TestRequestReplacementNotProvisioned(c *gc.C) {
+	m, err := s.State.AddMachine("quantal", state.JobHostUnits)
+	c.Assert(err, jc.ErrorIsNil)
+	err = m.RequestReplacement()
+	c.Assert(err, gc.ErrorMatches, `cannot request replacement of machine 0: machine 0 not provisioned`)
+	c.Assert(err, jc.Satisfies, errors.IsNotProvisioned)
+}
+
+func (s *MachineReplacementSuite) TestRequestReplacementNotSupported(c *gc.C) {
+	controller, err := s.State.AddMachine("quantal", state.JobManageModel)
+	c.Assert(err, jc.ErrorIsNil)
+	err = controller.RequestReplacement()
+	c.Assert(err, gc.ErrorMatches, `cannot request replacement of machine 0: replacing controller machine not supported`)
+
+	container, err := s.State.AddMachineInsideNewMachine(
+		state.MachineTemplate{Series: "quantal", Jobs: []state.MachineJob{state.JobHostUnits}},
+		state.MachineTemplate{Series: "quantal", Jobs: []state.MachineJob{state.JobHostUnits}},
+		instance.LXD,
+	)
+	c.Assert(err, jc.ErrorIsNil)
+	err = container.RequestReplacement()
+	c.Assert(err, gc.ErrorMatches, `cannot request replacement of machine 1/lxd/0: replacing container not supported`)
+	c.Assert(err, jc.Satisfies, errors.IsNotSupported)
+}
+
+func (s *MachineReplacementSuite) TestRequestReplacementNotAlive(c *gc.C) {
+	m := s.addProvisionedMachine(c)
+	err := m.Destroy()
+	c.Assert(err, jc.ErrorIsNil)
+	err = m.RequestReplacement()
+	c.Assert(err, gc.ErrorMatches, `cannot request replacement of machine 0: not found or not alive`)
+}
+
+func (s *MachineReplacementSuite) TestReplaceInstance(c *gc.C) {
+	_, u, storageTag := s.setupSingleStorage(c, "block", "loop-pool")
+	err := s.State.AssignUnit(u, state.AssignCleanEmpty)
+	c.Assert(err, jc.ErrorIsNil)
+	machineId, err := u.AssignedMachineId()
+	c.Assert(err, jc.ErrorIsNil)
+	m, err := s.State.Machine(machineId)
+	c.Assert(err, jc.ErrorIsNil)
+	err = m.SetProvisioned("inst-0", "nonce-0", nil)
+	c.Assert(err, jc.ErrorIsNil)
+
+	volumeTag := s.storageInstanceVolume(c, storageTag).VolumeTag()
+	err = s.State.SetVolumeInfo(volumeTag, state.VolumeInfo{VolumeId: "vol-0"})
+	c.Assert(err, jc.ErrorIsNil)
+	err = s.State.SetVolumeAttachmentInfo(m.MachineTag(), volumeTag, state.VolumeAttachmentInfo{
+		DeviceName: "sdc",
+	})
+	c.Assert(err, jc.ErrorIsNil)
+
+	err = m.RequestReplacement()
+	c.Assert(err, jc.ErrorIsNil)
+
+	arch := "amd64"
+	err = m.ReplaceInstance("inst-1", "nonce-1", &instance.HardwareCharacteristics{Arch: &arch},
+		map[names.VolumeTag]state.VolumeAttachmentInfo{
+			volumeTag: {BusAddress: "scsi@5:0.0.0"},
+		},
+	)
+	c.Assert(err, jc.ErrorIsNil)
+
+	err = m.Refresh()
+	c.Assert(err, jc.ErrorIsNil)
+	instId, err := m.InstanceId()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(instId, gc.Equals, instance.Id("inst-1"))
+	hc, err := m.HardwareCharacteristics()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(hc, jc.DeepEquals, &instance.HardwareCharacteristics{Arch: &arch})
+	c.Assert(m.CheckProvisioned("nonce-0"), jc.IsFalse)
+	c.Assert(m.CheckProvisioned("nonce-1"), jc.IsTrue)
+
+	attachment, err := s.State.VolumeAttachment(m.MachineTag(), volumeTag)
+	c.Assert(err, jc.ErrorIsNil)
+	info, err := attachment.Info()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(info, jc.DeepEquals, state.VolumeAttachmentInfo{BusAddress: "scsi@5:0.0.0"})
+
+	requested, err := m.ReplacementRequested()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(requested, jc.IsFalse)
+}
+
+func (s *MachineReplacementSuite) TestReplaceInstanceNotRequested(c *gc.C) {
+	m := s.addProvisionedMachine(c)
+	err := m.ReplaceInstance("inst-1", "nonce-1", nil, nil)
+	c.Assert(err, gc.ErrorMatches, `cannot replace instance of machine 0: replacement request not found`)
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+
+	instId, err := m.InstanceId()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(instId, gc.Equals, instance.Id("inst-0"))
+}
+
+func (s *MachineReplacementSuite) TestReplaceInstanceOnce(c *gc.C) {
+	m := s.addProvisionedMachine(c)
+	err := m.RequestReplacement()
+	c.Assert(err, jc.ErrorIsNil)
+	err = m.ReplaceInstance("inst-1", "nonce-1", nil, nil)
+	c.Assert(err, jc.ErrorIsNil)
+
+	// The request was fulfilled, so the instance cannot
+	// be replaced again without another request.
+	err = m.ReplaceInstance("inst-2", "nonce-2", nil, nil)
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+}
+
+func (s *MachineReplacementSuite) TestRemoveMachineRemovesReplacement(c *gc.C) {
+	m := s.addProvisionedMachine(c)
+	err := m.RequestReplacement()
+	c.Assert(err, jc.ErrorIsNil)
+	err = m.EnsureDead()
+	c.Assert(err, jc.ErrorIsNil)
+	err = m.Remove()
+	c.Assert(err, jc.ErrorIsNil)
+
+	ids, err := s.State.MachinesToReplace()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(ids, gc.HasLen, 0)
+}
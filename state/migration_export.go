@@ -1183,16 +1183,17 @@ func (e *exporter) constraintsArgs(globalKey string) (description.ConstraintsArg
 		return nil
 	}
 	result := description.ConstraintsArgs{
-		Architecture: optionalString("arch"),
-		Container:    optionalString("container"),
-		CpuCores:     optionalInt("cpucores"),
-		CpuPower:     optionalInt("cpupower"),
-		InstanceType: optionalString("instancetype"),
-		Memory:       optionalInt("mem"),
-		RootDisk:     optionalInt("rootdisk"),
-		Spaces:       optionalStringSlice("spaces"),
-		Tags:         optionalStringSlice("tags"),
-		VirtType:     optionalString("virttype"),
+		Architecture:  optionalString("arch"),
+		Container:     optionalString("container"),
+		CpuCores:      optionalInt("cpucores"),
+		CpuPower:      optionalInt("cpupower"),
+		InstanceType:  optionalString("instancetype"),
+		Memory:        optionalInt("mem"),
+		RootDisk:      optionalInt("rootdisk"),
+		Spaces:        optionalStringSlice("spaces"),
+		Tags:          optionalStringSlice("tags"),
+		VirtType:      optionalString("virttype"),
+		MaxHourlyCost: optionalInt("maxhourlycost"),
 	}
 	if optionalErr != nil {
 		return description.ConstraintsArgs{}, errors.Trace(optionalErr)
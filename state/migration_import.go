@@ -663,6 +663,18 @@ func (i *importer) application(s description.Application) error {
 		}
 	}
 
+	// The exposure intent records the ports opened by the units, so it
+	// must be created after they have been imported.
+	if s.Exposed() {
+		ops, err := svc.exposureIntentOps(true)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		if err := i.st.runTransaction(ops); err != nil {
+			return errors.Trace(err)
+		}
+	}
+
 	if s.Leader() != "" {
 		if err := i.st.LeadershipClaimer().ClaimLeadership(
 			s.Name(),
@@ -824,9 +836,27 @@ func (i *importer) makeApplicationDoc(s description.Application) (*applicationDo
 		Exposed:              s.Exposed(),
 		MinUnits:             s.MinUnits(),
 		MetricCredentials:    s.MetricsCredentials(),
+		WorkloadVersion:      applicationWorkloadVersion(s.Units()),
 	}, nil
 }
 
+// applicationWorkloadVersion returns the workload version most recently
+// reported by any of the given units, as recorded in their workload
+// version histories.
+func applicationWorkloadVersion(units []description.Unit) string {
+	var version string
+	var updated time.Time
+	for _, u := range units {
+		for _, entry := range u.WorkloadVersionHistory() {
+			if entry.Updated().After(updated) {
+				version = entry.Message()
+				updated = entry.Updated()
+			}
+		}
+	}
+	return version
+}
+
 func (i *importer) relationCount(application string) int {
 	count := 0
 
@@ -1314,6 +1344,9 @@ func (i *importer) constraints(cons description.Constraints) constraints.Value {
 	if virt := cons.VirtType(); virt != "" {
 		result.VirtType = &virt
 	}
+	if cost := cons.MaxHourlyCost(); cost != 0 {
+		result.MaxHourlyCost = &cost
+	}
 	return result
 }
 
@@ -426,6 +426,7 @@ func (s *MigrationImportSuite) assertUnitsMigrated(c *gc.C, cons constraints.Val
 	version, err := imported.WorkloadVersion()
 	c.Assert(err, jc.ErrorIsNil)
 	c.Assert(version, gc.Equals, "amethyst")
+	c.Assert(importedApplications[0].WorkloadVersion(), gc.Equals, "amethyst")
 
 	exportedMachineId, err := exported.AssignedMachineId()
 	c.Assert(err, jc.ErrorIsNil)
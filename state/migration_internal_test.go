@@ -114,6 +114,9 @@ func (s *MigrationSuite) TestKnownCollections(c *gc.C) {
 		// Not exported, but the tools will possibly need to be either bundled
 		// with the representation or sent separately.
 		toolsmetadataC,
+		// Tools mirror health is controller global, and is
+		// rebuilt as the controller fetches agent binaries.
+		toolsMirrorsC,
 		// Bakery storage items are non-critical. We store root keys for
 		// temporary credentials in there; after migration you'll just have
 		// to log back in.
@@ -137,9 +140,33 @@ func (s *MigrationSuite) TestKnownCollections(c *gc.C) {
 		// number of units is changed. The Service doc has all we need
 		// for migratino.
 		minUnitsC,
+		// Similarly, the scales collection is only used to trigger
+		// the application scaler.
+		scalesC,
 		// This is a transitory collection of units that need to be assigned
 		// to machines.
 		assignUnitC,
+		// Application renames are only recorded while a rename is
+		// in progress.
+		applicationRenamesC,
+		// Exposure intents are recreated when importing exposed
+		// applications.
+		exposureIntentsC,
+
+		// Provisioning errors are only recorded for machines
+		// that have not been provisioned, and are recorded
+		// afresh by the provisioner if provisioning fails again.
+		provisioningErrorsC,
+
+		// Machine replacements are only requested while the
+		// provisioner replaces a machine's missing instance,
+		// and may be requested again after migration.
+		machineReplacementsC,
+
+		// Storage claims are only held while the storage
+		// provisioners act on storage entities, and those are
+		// not running while the model is being migrated.
+		storageClaimsC,
 
 		// The model entity references collection will be repopulated
 		// after importing the model. It does not need to be migrated
@@ -170,9 +197,13 @@ func (s *MigrationSuite) TestKnownCollections(c *gc.C) {
 		"resources",
 		endpointBindingsC,
 
+		// cross-model relations
+		applicationOffersC,
+
 		// uncategorised
 		metricsManagerC, // should really be copied across
 		auditingC,
+		modelConfigChangesC,
 	)
 
 	envCollections := set.NewStrings()
@@ -333,6 +364,16 @@ func (s *MigrationSuite) TestApplicationDocFields(c *gc.C) {
 		// RelationCount is handled by the number of times the application name
 		// appears in relation endpoints.
 		"RelationCount",
+		// Paused is an administrative flag for maintenance windows,
+		// and is not carried across to the target controller.
+		"Paused",
+		// The charm pin is an administrative safeguard, and is
+		// not carried across to the target controller.
+		"CharmPinned",
+		"CharmPinMessage",
+		// The desired scale is not part of the model description;
+		// the exported units reflect it.
+		"DesiredScale",
 	)
 	migrated := set.NewStrings(
 		"Name",
@@ -458,6 +499,11 @@ func (s *MigrationSuite) TestAnnatatorDocFields(c *gc.C) {
 		"GlobalKey",
 		"Tag",
 		"Annotations",
+		// History isn't exported; only the current
+		// annotations are migrated.
+		"History",
+		// TxnRevno is mgo/txn's internal revision number.
+		"TxnRevno",
 	)
 	s.AssertExportedFields(c, annotatorDoc{}, fields)
 }
@@ -509,6 +555,7 @@ func (s *MigrationSuite) TestConstraintsDocFields(c *gc.C) {
 		"Tags",
 		"Spaces",
 		"VirtType",
+		"MaxHourlyCost",
 	)
 	s.AssertExportedFields(c, constraintsDoc{}, fields)
 }
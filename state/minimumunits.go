@@ -150,6 +150,11 @@ func (s *Application) EnsureMinUnits() (err error) {
 		if missing <= 0 {
 			return nil
 		}
+		// Units may not be added to a paused application. The
+		// missing units are added when the application is resumed.
+		if service.doc.Paused {
+			return nil
+		}
 		name, ops, err := ensureMinUnitsOps(service)
 		if err != nil {
 			return err
@@ -192,8 +197,12 @@ func aliveUnitsCount(service *Application) (int, error) {
 
 // ensureMinUnitsOps returns the operations required to add a unit for the
 // service in MongoDB and the name for the new unit. The resulting transaction
-// will be aborted if the service document changes when running the operations.
+// will be aborted if the service document changes when running the operations,
+// or if the service is paused, as with AddUnit.
 func ensureMinUnitsOps(service *Application) (string, []txn.Op, error) {
-	asserts := bson.D{{"txn-revno", service.doc.TxnRevno}}
+	asserts := bson.D{
+		{"txn-revno", service.doc.TxnRevno},
+		{"paused", pausedAssert(false)},
+	}
 	return service.addUnitOps("", asserts)
 }
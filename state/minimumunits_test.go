@@ -335,6 +335,32 @@ func (s *MinUnitsSuite) TestEnsureMinUnitsServiceNotAlive(c *gc.C) {
 	c.Assert(s.service.EnsureMinUnits(), gc.ErrorMatches, expectedErr)
 }
 
+func (s *MinUnitsSuite) TestEnsureMinUnitsPaused(c *gc.C) {
+	err := s.service.SetMinUnits(2)
+	c.Assert(err, jc.ErrorIsNil)
+	err = s.service.Pause()
+	c.Assert(err, jc.ErrorIsNil)
+
+	// No units are added while the application is paused.
+	err = s.service.EnsureMinUnits()
+	c.Assert(err, jc.ErrorIsNil)
+	assertAllUnits(c, s.service, 0)
+
+	err = s.service.Resume()
+	c.Assert(err, jc.ErrorIsNil)
+	err = s.service.EnsureMinUnits()
+	c.Assert(err, jc.ErrorIsNil)
+	assertAllUnits(c, s.service, 2)
+}
+
+func (s *MinUnitsSuite) TestEnsureMinUnitsPausedBefore(c *gc.C) {
+	f := func() {
+		err := s.service.Pause()
+		c.Assert(err, jc.ErrorIsNil)
+	}
+	s.testEnsureMinUnitsBefore(c, f, 2, 0)
+}
+
 func (s *MinUnitsSuite) TestEnsureMinUnitsUpdateMinUnitsRetry(c *gc.C) {
 	s.addUnits(c, 1)
 	err := s.service.SetMinUnits(4)
@@ -179,6 +179,12 @@ type ModelArgs struct {
 	// details of the default storage pools.
 	StorageProviderRegistry storage.ProviderRegistry
 
+	// StoragePools holds the attributes of storage pools to create in
+	// the model, in addition to the default storage pools, keyed by
+	// pool name. Each pool's attributes must include its storage
+	// provider type, with the key "type".
+	StoragePools map[string]map[string]interface{}
+
 	// Owner is the user that owns the model.
 	Owner names.UserTag
 
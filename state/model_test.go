@@ -20,7 +20,10 @@ import (
 	"github.com/juju/juju/permission"
 	"github.com/juju/juju/state"
 	statetesting "github.com/juju/juju/state/testing"
+	"github.com/juju/juju/status"
 	"github.com/juju/juju/storage"
+	"github.com/juju/juju/storage/poolmanager"
+	dummystorage "github.com/juju/juju/storage/provider/dummy"
 	"github.com/juju/juju/testing"
 	"github.com/juju/juju/testing/factory"
 )
@@ -195,6 +198,48 @@ func (s *ModelSuite) TestNewModelImportingMode(c *gc.C) {
 	c.Assert(env.MigrationMode(), gc.Equals, state.MigrationModeImporting)
 }
 
+func (s *ModelSuite) TestNewModelStoragePools(c *gc.C) {
+	cfg, _ := s.createTestModelConfig(c)
+	registry := storage.StaticProviderRegistry{
+		map[storage.ProviderType]storage.Provider{
+			"static": &dummystorage.StorageProvider{IsDynamic: false},
+		},
+	}
+	_, st, err := s.State.NewModel(state.ModelArgs{
+		CloudName:               "dummy",
+		CloudRegion:             "dummy-region",
+		Config:                  cfg,
+		Owner:                   names.NewUserTag("test@remote"),
+		StorageProviderRegistry: registry,
+		StoragePools: map[string]map[string]interface{}{
+			"fast": {"type": "static", "speed": "fast"},
+		},
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	defer st.Close()
+
+	pm := poolmanager.New(state.NewStateSettings(st), registry)
+	pool, err := pm.Get("fast")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(pool.Provider(), gc.Equals, storage.ProviderType("static"))
+	c.Assert(pool.Attrs(), jc.DeepEquals, map[string]interface{}{"speed": "fast"})
+}
+
+func (s *ModelSuite) TestNewModelStoragePoolsInvalid(c *gc.C) {
+	cfg, _ := s.createTestModelConfig(c)
+	_, _, err := s.State.NewModel(state.ModelArgs{
+		CloudName:               "dummy",
+		CloudRegion:             "dummy-region",
+		Config:                  cfg,
+		Owner:                   names.NewUserTag("test@remote"),
+		StorageProviderRegistry: storage.StaticProviderRegistry{},
+		StoragePools: map[string]map[string]interface{}{
+			"fast": {"type": "static"},
+		},
+	})
+	c.Assert(err, gc.ErrorMatches, `creating storage pool "fast": .*`)
+}
+
 func (s *ModelSuite) TestSetMigrationMode(c *gc.C) {
 	cfg, _ := s.createTestModelConfig(c)
 	owner := names.NewUserTag("test@remote")
@@ -953,6 +998,52 @@ func (s *ModelCloudValidationSuite) TestNewModelOtherUserCloudCredential(c *gc.C
 	c.Assert(err, gc.ErrorMatches, `credential "dummy/test@remote/controller-credential" not found`)
 }
 
+func (s *ModelCloudValidationSuite) TestInvalidateModelCredential(c *gc.C) {
+	controllerCredentialTag := names.NewCloudCredentialTag("dummy/test@remote/controller-credential")
+	credential := cloud.NewCredential(cloud.UserPassAuthType, nil)
+	st, _ := s.initializeState(
+		c, nil, []cloud.AuthType{cloud.UserPassAuthType}, map[names.CloudCredentialTag]cloud.Credential{
+			controllerCredentialTag: credential,
+		},
+	)
+	defer st.Close()
+	model, err := st.Model()
+	c.Assert(err, jc.ErrorIsNil)
+
+	err = st.InvalidateModelCredential("secret expired")
+	c.Assert(err, jc.ErrorIsNil)
+
+	out, err := st.CloudCredential(controllerCredentialTag)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(out.Invalid, jc.IsTrue)
+	c.Assert(out.InvalidReason, gc.Equals, "secret expired")
+	info, err := model.Status()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(info.Status, gc.Equals, status.Suspended)
+	c.Assert(info.Message, gc.Equals, `suspended since cloud credential "controller-credential" is not valid: `+
+		`secret expired; update it with juju update-credential to resume`)
+
+	// Updating the credential makes it valid,
+	// and resumes the suspended model.
+	err = st.UpdateCloudCredential(controllerCredentialTag, credential)
+	c.Assert(err, jc.ErrorIsNil)
+	out, err = st.CloudCredential(controllerCredentialTag)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(out.Invalid, jc.IsFalse)
+	c.Assert(out.InvalidReason, gc.Equals, "")
+	info, err = model.Status()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(info.Status, gc.Equals, status.Available)
+}
+
+func (s *ModelCloudValidationSuite) TestInvalidateModelCredentialNoCredential(c *gc.C) {
+	st, _ := s.initializeState(c, nil, []cloud.AuthType{cloud.EmptyAuthType}, nil)
+	defer st.Close()
+	err := st.InvalidateModelCredential("secret expired")
+	c.Assert(err, gc.ErrorMatches, `cloud credential for model "testing" not found`)
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+}
+
 func (s *ModelCloudValidationSuite) initializeState(
 	c *gc.C,
 	regions []cloud.Region,
@@ -268,6 +268,17 @@ type ValidateConfigFunc func(updateAttrs map[string]interface{}, removeAttrs []s
 // configuration of the model with the provided updateAttrs and
 // removeAttrs.
 func (st *State) UpdateModelConfig(updateAttrs map[string]interface{}, removeAttrs []string, additionalValidation ValidateConfigFunc) error {
+	return st.UpdateModelConfigBy(ModelConfigChangeSource{}, updateAttrs, removeAttrs, additionalValidation)
+}
+
+// UpdateModelConfigBy is like UpdateModelConfig, but records the
+// given source against the change, as returned by ModelConfigChanges.
+func (st *State) UpdateModelConfigBy(
+	source ModelConfigChangeSource,
+	updateAttrs map[string]interface{},
+	removeAttrs []string,
+	additionalValidation ValidateConfigFunc,
+) error {
 	if len(updateAttrs)+len(removeAttrs) == 0 {
 		return nil
 	}
@@ -335,8 +346,13 @@ func (st *State) UpdateModelConfig(updateAttrs map[string]interface{}, removeAtt
 	validAttrs = config.CoerceForStorage(validAttrs)
 
 	modelSettings.Update(validAttrs)
-	_, ops := modelSettings.settingsUpdateOps()
-	return modelSettings.write(ops)
+	changes, ops := modelSettings.settingsUpdateOps()
+	recordOps, err := st.recordModelConfigChangeOps(source, changes)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	ops = append(ops, recordOps...)
+	return errors.Trace(modelSettings.write(ops))
 }
 
 type modelConfigSourceFunc func() (attrValues, error)
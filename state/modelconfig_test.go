@@ -5,6 +5,7 @@ package state_test
 
 import (
 	"strings"
+	"time"
 
 	"github.com/juju/errors"
 	jc "github.com/juju/testing/checkers"
@@ -541,3 +542,129 @@ func (s *ModelConfigSourceSuite) TestUpdateModelConfigRegionDefaults(c *gc.C) {
 		}}}
 	c.Assert(cfg, jc.DeepEquals, expectedValues)
 }
+
+func (s *ModelConfigSuite) TestUpdateModelConfigRecordsChanges(c *gc.C) {
+	err := s.State.UpdateModelConfig(map[string]interface{}{
+		"arbitrary-key": "shazam!",
+	}, nil, nil)
+	c.Assert(err, jc.ErrorIsNil)
+
+	user := names.NewUserTag("bob")
+	before := time.Now()
+	err = s.State.UpdateModelConfigBy(state.ModelConfigChangeSource{
+		User:   user,
+		Source: "api",
+	}, map[string]interface{}{
+		"automatically-retry-hooks": false,
+	}, []string{"arbitrary-key"}, nil)
+	c.Assert(err, jc.ErrorIsNil)
+
+	changes, err := s.State.ModelConfigChanges(0)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(changes, gc.HasLen, 2)
+
+	// Changes are returned newest first.
+	c.Assert(changes[0].User, gc.Equals, user)
+	c.Assert(changes[0].Source, gc.Equals, "api")
+	c.Assert(changes[0].When.Before(before), jc.IsFalse)
+	c.Assert(changes[0].Changes, jc.DeepEquals, []state.ModelConfigAttributeChange{{
+		Key:      "arbitrary-key",
+		OldValue: "shazam!",
+	}, {
+		Key:      "automatically-retry-hooks",
+		OldValue: true,
+		NewValue: false,
+	}})
+
+	c.Assert(changes[1].User, gc.Equals, names.UserTag{})
+	c.Assert(changes[1].Source, gc.Equals, "")
+	c.Assert(changes[1].Changes, jc.DeepEquals, []state.ModelConfigAttributeChange{{
+		Key:      "arbitrary-key",
+		NewValue: "shazam!",
+	}})
+}
+
+func (s *ModelConfigSuite) TestUpdateModelConfigNoChangeNotRecorded(c *gc.C) {
+	cfg, err := s.State.ModelConfig()
+	c.Assert(err, jc.ErrorIsNil)
+	err = s.State.UpdateModelConfig(map[string]interface{}{
+		"logging-config": cfg.AllAttrs()["logging-config"],
+	}, nil, nil)
+	c.Assert(err, jc.ErrorIsNil)
+
+	changes, err := s.State.ModelConfigChanges(0)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(changes, gc.HasLen, 0)
+}
+
+func (s *ModelConfigSuite) TestModelConfigChangesLimit(c *gc.C) {
+	for _, value := range []string{"a", "b", "c"} {
+		err := s.State.UpdateModelConfig(map[string]interface{}{
+			"arbitrary-key": value,
+		}, nil, nil)
+		c.Assert(err, jc.ErrorIsNil)
+	}
+
+	changes, err := s.State.ModelConfigChanges(2)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(changes, gc.HasLen, 2)
+	c.Assert(changes[0].Changes[0].NewValue, gc.Equals, "c")
+	c.Assert(changes[1].Changes[0].NewValue, gc.Equals, "b")
+}
+
+func (s *ModelConfigSuite) TestModelConfigChangesOtherModel(c *gc.C) {
+	err := s.State.UpdateModelConfig(map[string]interface{}{
+		"arbitrary-key": "shazam!",
+	}, nil, nil)
+	c.Assert(err, jc.ErrorIsNil)
+
+	st := s.Factory.MakeModel(c, nil)
+	defer st.Close()
+	changes, err := st.ModelConfigChanges(0)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(changes, gc.HasLen, 0)
+}
+
+func (s *ModelConfigSuite) TestModelConfigChangesPruned(c *gc.C) {
+	for i := 0; i < state.MaxModelConfigChanges+2; i++ {
+		err := s.State.UpdateModelConfig(map[string]interface{}{
+			"arbitrary-key": i,
+		}, nil, nil)
+		c.Assert(err, jc.ErrorIsNil)
+	}
+
+	changes, err := s.State.ModelConfigChanges(0)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(changes, gc.HasLen, state.MaxModelConfigChanges)
+	c.Assert(changes[0].Changes[0].NewValue, gc.Equals, state.MaxModelConfigChanges+1)
+	c.Assert(changes[len(changes)-1].Changes[0].NewValue, gc.Equals, 2)
+}
+
+func (s *ModelConfigSuite) TestWatchModelConfigChangeRecords(c *gc.C) {
+	w := s.State.WatchModelConfigChangeRecords()
+	defer statetesting.AssertStop(c, w)
+	wc := statetesting.NewNotifyWatcherC(c, s.State, w)
+	wc.AssertOneChange()
+
+	err := s.State.UpdateModelConfig(map[string]interface{}{
+		"arbitrary-key": "shazam!",
+	}, nil, nil)
+	c.Assert(err, jc.ErrorIsNil)
+	wc.AssertOneChange()
+
+	// An update that changes nothing records nothing.
+	err = s.State.UpdateModelConfig(map[string]interface{}{
+		"arbitrary-key": "shazam!",
+	}, nil, nil)
+	c.Assert(err, jc.ErrorIsNil)
+	wc.AssertNoChange()
+
+	// Changes to another model's config are not seen.
+	st := s.Factory.MakeModel(c, nil)
+	defer st.Close()
+	err = st.UpdateModelConfig(map[string]interface{}{
+		"arbitrary-key": "kapow!",
+	}, nil, nil)
+	c.Assert(err, jc.ErrorIsNil)
+	wc.AssertNoChange()
+}
@@ -0,0 +1,181 @@
+// Copyright 2016 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package state
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/juju/errors"
+	"gopkg.in/juju/names.v2"
+	"gopkg.in/mgo.v2/txn"
+)
+
+// maxModelConfigChanges is the maximum number of model config
+// changes recorded for each model. When a new change is recorded,
+// the oldest change beyond this number is removed.
+const maxModelConfigChanges = 100
+
+// ModelConfigChangeSource identifies who changed a model's config,
+// and by what means.
+type ModelConfigChangeSource struct {
+	// User is the user that made the change. This may be
+	// empty, if the change was not made on behalf of a user.
+	User names.UserTag
+
+	// Source describes how the change was made, e.g. "api".
+	Source string
+}
+
+// ModelConfigChange records a change to a model's config.
+type ModelConfigChange struct {
+	// User is the user that made the change, if any.
+	User names.UserTag
+
+	// Source describes how the change was made.
+	Source string
+
+	// When is the time at which the change was made.
+	When time.Time
+
+	// Changes holds the changed attributes, sorted by key.
+	Changes []ModelConfigAttributeChange
+}
+
+// ModelConfigAttributeChange records a change to the value
+// of a single model config attribute.
+type ModelConfigAttributeChange struct {
+	// Key is the name of the attribute.
+	Key string
+
+	// OldValue is the attribute's value before the change,
+	// or nil if the attribute was added.
+	OldValue interface{}
+
+	// NewValue is the attribute's value after the change,
+	// or nil if the attribute was removed.
+	NewValue interface{}
+}
+
+// modelConfigChangeDoc is the persistent representation of a
+// ModelConfigChange.
+type modelConfigChangeDoc struct {
+	DocID     string                     `bson:"_id"`
+	ModelUUID string                     `bson:"model-uuid"`
+	Seq       int                        `bson:"seq"`
+	User      string                     `bson:"user,omitempty"`
+	Source    string                     `bson:"source,omitempty"`
+	When      int64                      `bson:"when"`
+	Changes   []modelConfigAttrChangeDoc `bson:"changes"`
+}
+
+type modelConfigAttrChangeDoc struct {
+	Key      string      `bson:"key"`
+	OldValue interface{} `bson:"old,omitempty"`
+	NewValue interface{} `bson:"new,omitempty"`
+}
+
+func modelConfigChangeId(seq int) string {
+	return strconv.Itoa(seq)
+}
+
+// recordModelConfigChangeOps returns the operations required to
+// record the given changes to the model's settings, and to remove
+// the oldest recorded change beyond maxModelConfigChanges. The
+// changes are those computed for the settings write, so the old
+// and new values are both as stored. If there are no changes, no
+// operations are returned.
+func (st *State) recordModelConfigChangeOps(
+	source ModelConfigChangeSource,
+	itemChanges []ItemChange,
+) ([]txn.Op, error) {
+	if len(itemChanges) == 0 {
+		return nil, nil
+	}
+	seq, err := st.sequence(modelConfigChangesC)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	doc := modelConfigChangeDoc{
+		DocID:     st.docID(modelConfigChangeId(seq)),
+		ModelUUID: st.ModelUUID(),
+		Seq:       seq,
+		Source:    source.Source,
+		When:      st.clock.Now().UnixNano(),
+		Changes:   make([]modelConfigAttrChangeDoc, len(itemChanges)),
+	}
+	if source.User != (names.UserTag{}) {
+		doc.User = source.User.Id()
+	}
+	// itemChanges are sorted by key.
+	for i, change := range itemChanges {
+		doc.Changes[i] = modelConfigAttrChangeDoc{
+			Key:      change.Key,
+			OldValue: change.OldValue,
+			NewValue: change.NewValue,
+		}
+	}
+	ops := []txn.Op{{
+		C:      modelConfigChangesC,
+		Id:     doc.DocID,
+		Assert: txn.DocMissing,
+		Insert: &doc,
+	}}
+	if oldest := seq - maxModelConfigChanges; oldest >= 0 {
+		// The oldest change may already have been removed, or
+		// never have been written if its transaction aborted;
+		// removing a missing document is a no-op.
+		ops = append(ops, txn.Op{
+			C:      modelConfigChangesC,
+			Id:     st.docID(modelConfigChangeId(oldest)),
+			Remove: true,
+		})
+	}
+	return ops, nil
+}
+
+// WatchModelConfigChangeRecords returns a NotifyWatcher that
+// triggers whenever a change to the model's config is recorded,
+// as returned by ModelConfigChanges.
+func (st *State) WatchModelConfigChangeRecords() NotifyWatcher {
+	return newNotifyCollWatcher(st, modelConfigChangesC, isLocalID(st))
+}
+
+// ModelConfigChanges returns the most recent changes to the model's
+// config, newest first. If limit is positive, at most that many
+// changes are returned. At most maxModelConfigChanges changes are
+// retained for each model.
+func (st *State) ModelConfigChanges(limit int) ([]ModelConfigChange, error) {
+	coll, closer := st.getCollection(modelConfigChangesC)
+	defer closer()
+
+	query := coll.Find(nil).Sort("-seq")
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+	var docs []modelConfigChangeDoc
+	if err := query.All(&docs); err != nil {
+		return nil, errors.Annotate(err, "cannot get model config changes")
+	}
+	changes := make([]ModelConfigChange, len(docs))
+	for i, doc := range docs {
+		change := ModelConfigChange{
+			Source:  doc.Source,
+			When:    time.Unix(0, doc.When),
+			Changes: make([]ModelConfigAttributeChange, len(doc.Changes)),
+		}
+		if doc.User != "" {
+			change.User = names.NewUserTag(doc.User)
+		}
+		for j, attrChange := range doc.Changes {
+			change.Changes[j] = ModelConfigAttributeChange{
+				Key:      attrChange.Key,
+				OldValue: attrChange.OldValue,
+				NewValue: attrChange.NewValue,
+			}
+		}
+		changes[i] = change
+	}
+	return changes, nil
+}
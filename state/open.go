@@ -234,6 +234,21 @@ func Initialize(args InitializeParams) (_ *State, err error) {
 
 	logger.Infof("initializing controller model %s", modelTag.Id())
 
+	// The blob backend's attributes may include credentials, so
+	// they are recorded only with the backend, and not in the
+	// controller config, which is readable by clients.
+	controllerCfg := args.ControllerConfig
+	if attrs := controllerCfg.BlobBackend(); attrs != nil {
+		if err := st.initBlobBackend(attrs); err != nil {
+			return nil, errors.Trace(err)
+		}
+		controllerCfg = make(controller.Config)
+		for k, v := range args.ControllerConfig {
+			controllerCfg[k] = v
+		}
+		delete(controllerCfg, controller.BlobBackendKey)
+	}
+
 	modelOps, err := st.modelSetupOps(
 		args.ControllerConfig.ControllerUUID(),
 		args.ControllerModelArgs,
@@ -287,7 +302,7 @@ func Initialize(args InitializeParams) (_ *State, err error) {
 			Assert: txn.DocMissing,
 			Insert: &hostedModelCountDoc{},
 		},
-		createSettingsOp(controllersC, controllerSettingsGlobalKey, args.ControllerConfig),
+		createSettingsOp(controllersC, controllerSettingsGlobalKey, controllerCfg),
 		createSettingsOp(globalSettingsC, controllerInheritedSettingsGlobalKey, args.ControllerInheritedConfig),
 	)
 	for k, v := range args.Cloud.RegionConfig {
@@ -350,12 +365,15 @@ func (st *State) modelSetupOps(controllerUUID string, args ModelArgs, inherited
 		ops = append(ops, incHostedModelCountOp())
 	}
 
-	// Create the default storage pools for the model.
-	defaultStoragePoolsOps, err := st.createDefaultStoragePoolsOps(args.StorageProviderRegistry)
+	// Create the default storage pools for the model, along with
+	// any storage pools specified by the caller.
+	storagePoolsOps, err := st.createStoragePoolsOps(
+		args.StorageProviderRegistry, args.StoragePools,
+	)
 	if err != nil {
 		return nil, errors.Trace(err)
 	}
-	ops = append(ops, defaultStoragePoolsOps...)
+	ops = append(ops, storagePoolsOps...)
 
 	// Create the final map of config attributes for the model.
 	// If we have ControllerInheritedConfig passed in, that means state
@@ -407,7 +425,13 @@ func (st *State) modelSetupOps(controllerUUID string, args ModelArgs, inherited
 	return ops, nil
 }
 
-func (st *State) createDefaultStoragePoolsOps(registry storage.ProviderRegistry) ([]txn.Op, error) {
+// createStoragePoolsOps returns the operations required to create the
+// default storage pools of each of the registry's providers, and then
+// the given storage pools.
+func (st *State) createStoragePoolsOps(
+	registry storage.ProviderRegistry,
+	pools map[string]map[string]interface{},
+) ([]txn.Op, error) {
 	m := poolmanager.MemSettings{make(map[string]map[string]interface{})}
 	pm := poolmanager.New(m, registry)
 	providerTypes, err := registry.StorageProviderTypes()
@@ -425,6 +449,18 @@ func (st *State) createDefaultStoragePoolsOps(registry storage.ProviderRegistry)
 			)
 		}
 	}
+	for name, attrs := range pools {
+		poolAttrs := make(map[string]interface{})
+		for k, v := range attrs {
+			poolAttrs[k] = v
+		}
+		providerType, _ := poolAttrs[poolmanager.Type].(string)
+		delete(poolAttrs, poolmanager.Type)
+		delete(poolAttrs, poolmanager.Name)
+		if _, err := pm.Create(name, storage.ProviderType(providerType), poolAttrs); err != nil {
+			return nil, errors.Annotatef(err, "creating storage pool %q", name)
+		}
+	}
 
 	var ops []txn.Op
 	for key, settings := range m.Settings {
@@ -521,6 +557,9 @@ func (st *State) CACert() string {
 // Close the connection to the database.
 func (st *State) Close() (err error) {
 	defer errors.DeferredAnnotatef(&err, "closing state failed")
+	if st.base != nil {
+		return errors.New("cannot close a State returned by WithWatchOwner")
+	}
 
 	var errs []error
 	handle := func(name string, err error) {
@@ -79,7 +79,10 @@ func (st *State) precheckInstance(series string, cons constraints.Value, placeme
 	return prechecker.PrecheckInstance(series, cons, placement)
 }
 
-func (st *State) constraintsValidator() (constraints.Validator, error) {
+// ConstraintsValidator returns the constraints.Validator for the model.
+// The validator combines that of the model's provider with the
+// architectures for which there is cloud image metadata.
+func (st *State) ConstraintsValidator() (constraints.Validator, error) {
 	// Default behaviour is to simply use a standard validator with
 	// no model specific behaviour built in.
 	var validator constraints.Validator
@@ -110,8 +113,9 @@ func (st *State) constraintsValidator() (constraints.Validator, error) {
 		}
 		arches, err := st.CloudImageMetadataStorage.SupportedArchitectures(
 			cloudimagemetadata.MetadataFilter{
-				Stream: cfg.AgentStream(),
-				Region: region,
+				Stream:    cfg.AgentStream(),
+				Region:    region,
+				ModelUUID: st.ModelUUID(),
 			},
 		)
 		if err != nil {
@@ -127,7 +131,7 @@ func (st *State) constraintsValidator() (constraints.Validator, error) {
 // resolveConstraints combines the given constraints with the environ constraints to get
 // a constraints which will be used to create a new instance.
 func (st *State) resolveConstraints(cons constraints.Value) (constraints.Value, error) {
-	validator, err := st.constraintsValidator()
+	validator, err := st.ConstraintsValidator()
 	if err != nil {
 		return constraints.Value{}, err
 	}
@@ -141,7 +145,7 @@ func (st *State) resolveConstraints(cons constraints.Value) (constraints.Value,
 // validateConstraints returns an error if the given constraints are not valid for the
 // current model, and also any unsupported attributes.
 func (st *State) validateConstraints(cons constraints.Value) ([]string, error) {
-	validator, err := st.constraintsValidator()
+	validator, err := st.ConstraintsValidator()
 	if err != nil {
 		return nil, err
 	}
@@ -240,7 +240,11 @@ func (p *Ports) OpenPorts(portRange PortRange) (err error) {
 			assert := bson.D{{"txn-revno", ports.doc.TxnRevno}}
 			ops = append(ops, updatePortsDocOps(p.st, ports.doc, assert, portRange)...)
 		}
-		return ops, nil
+		intentOps, err := addExposureIntentPortRangeOps(p.st, portRange)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		return append(ops, intentOps...), nil
 	}
 	// Run the transaction using the state transaction runner.
 	if err = p.st.run(buildTxn); err != nil {
@@ -306,12 +310,16 @@ func (p *Ports) ClosePorts(portRange PortRange) (err error) {
 		if !found {
 			return nil, statetxn.ErrNoOperations
 		}
+		ops, err := removeExposureIntentPortRangeOps(p.st, portRange)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
 		if len(newPorts) == 0 {
 			// All ports closed, so remove the ports doc instead.
-			return p.removeOps(), nil
+			return append(ops, p.removeOps()...), nil
 		} else {
 			assert := bson.D{{"txn-revno", ports.doc.TxnRevno}}
-			return setPortsDocOps(p.st, ports.doc, assert, newPorts...), nil
+			return append(ops, setPortsDocOps(p.st, ports.doc, assert, newPorts...)...), nil
 		}
 	}
 	if err = p.st.run(buildTxn); err != nil {
@@ -526,6 +534,13 @@ func removePortsForUnitOps(st *State, unit *Unit) ([]txn.Op, error) {
 			ops = append(ops, ports.removeOps()...)
 		}
 	}
+	if len(ops) > 0 {
+		intentOps, err := removeExposureIntentUnitOps(st, unit.Name())
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		ops = append(ops, intentOps...)
+	}
 	return ops, nil
 }
 
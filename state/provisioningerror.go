@@ -0,0 +1,142 @@
+// Copyright 2016 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package state
+
+import (
+	"time"
+
+	"github.com/juju/errors"
+	"gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
+	"gopkg.in/mgo.v2/txn"
+)
+
+// MachineProvisioningError records why the provisioner was unable to
+// start an instance for a machine. The record is removed when the
+// machine is provisioned.
+type MachineProvisioningError struct {
+	// Kind classifies the failure, e.g. "quota" or "auth". The
+	// kinds are defined by the environs package.
+	Kind string
+
+	// Message is the error message reported by the provisioner.
+	Message string
+
+	// Attempts is the number of attempts that were made to start
+	// an instance before the provisioner gave up.
+	Attempts int
+
+	// Updated is the time at which the error was recorded.
+	Updated time.Time
+}
+
+// provisioningErrorDoc represents the provisioning error of a machine
+// in MongoDB.
+type provisioningErrorDoc struct {
+	DocID     string `bson:"_id"`
+	ModelUUID string `bson:"model-uuid"`
+	MachineId string `bson:"machine-id"`
+	Kind      string `bson:"kind"`
+	Message   string `bson:"message"`
+	Attempts  int    `bson:"attempts"`
+	Updated   int64  `bson:"updated"`
+}
+
+// ProvisioningError returns the provisioning error recorded for the
+// machine. If there is none, an error satisfying errors.IsNotFound is
+// returned.
+func (m *Machine) ProvisioningError() (MachineProvisioningError, error) {
+	coll, closer := m.st.getCollection(provisioningErrorsC)
+	defer closer()
+
+	var doc provisioningErrorDoc
+	err := coll.FindId(m.doc.DocID).One(&doc)
+	if err == mgo.ErrNotFound {
+		return MachineProvisioningError{}, errors.NotFoundf("provisioning error for machine %v", m)
+	} else if err != nil {
+		return MachineProvisioningError{}, errors.Annotatef(err, "cannot get provisioning error for machine %v", m)
+	}
+	return MachineProvisioningError{
+		Kind:     doc.Kind,
+		Message:  doc.Message,
+		Attempts: doc.Attempts,
+		Updated:  time.Unix(0, doc.Updated).UTC(),
+	}, nil
+}
+
+// SetProvisioningError records the reason that the provisioner was
+// unable to start an instance for the machine, replacing any previously
+// recorded provisioning error. The Updated field of the argument is
+// ignored; the current time is recorded instead.
+func (m *Machine) SetProvisioningError(perr MachineProvisioningError) error {
+	doc := provisioningErrorDoc{
+		DocID:     m.doc.DocID,
+		ModelUUID: m.st.ModelUUID(),
+		MachineId: m.doc.Id,
+		Kind:      perr.Kind,
+		Message:   perr.Message,
+		Attempts:  perr.Attempts,
+		Updated:   m.st.clock.Now().UnixNano(),
+	}
+	buildTxn := func(attempt int) ([]txn.Op, error) {
+		if attempt > 0 {
+			if err := m.Refresh(); err != nil {
+				return nil, errors.Trace(err)
+			}
+		}
+		if m.doc.Life == Dead {
+			return nil, ErrDead
+		}
+		ops := []txn.Op{{
+			C:      machinesC,
+			Id:     m.doc.DocID,
+			Assert: notDeadDoc,
+		}}
+		_, err := m.ProvisioningError()
+		if errors.IsNotFound(err) {
+			return append(ops, txn.Op{
+				C:      provisioningErrorsC,
+				Id:     doc.DocID,
+				Assert: txn.DocMissing,
+				Insert: &doc,
+			}), nil
+		} else if err != nil {
+			return nil, errors.Trace(err)
+		}
+		return append(ops, txn.Op{
+			C:      provisioningErrorsC,
+			Id:     doc.DocID,
+			Assert: txn.DocExists,
+			Update: bson.D{{"$set", bson.D{
+				{"kind", doc.Kind},
+				{"message", doc.Message},
+				{"attempts", doc.Attempts},
+				{"updated", doc.Updated},
+			}}},
+		}), nil
+	}
+	if err := m.st.run(buildTxn); err != nil {
+		return errors.Annotatef(err, "cannot set provisioning error for machine %v", m)
+	}
+	return nil
+}
+
+// ClearProvisioningError removes the provisioning error recorded for the
+// machine, if any.
+func (m *Machine) ClearProvisioningError() error {
+	if err := m.st.runTransaction([]txn.Op{removeProvisioningErrorOp(m.doc.DocID)}); err != nil {
+		return errors.Annotatef(err, "cannot clear provisioning error for machine %v", m)
+	}
+	return nil
+}
+
+// removeProvisioningErrorOp returns the operation required to remove the
+// provisioning error of the machine with the given doc ID, if it has one.
+func removeProvisioningErrorOp(machineDocID string) txn.Op {
+	return txn.Op{
+		C:      provisioningErrorsC,
+		Id:     machineDocID,
+		Remove: true,
+	}
+}
@@ -0,0 +1,114 @@
+// Copyright 2016 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package state_test
+
+import (
+	"time"
+
+	"github.com/juju/errors"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/instance"
+	"github.com/juju/juju/state"
+)
+
+type ProvisioningErrorSuite struct {
+	ConnSuite
+	machine *state.Machine
+}
+
+var _ = gc.Suite(&ProvisioningErrorSuite{})
+
+func (s *ProvisioningErrorSuite) SetUpTest(c *gc.C) {
+	s.ConnSuite.SetUpTest(c)
+	var err error
+	s.machine, err = s.State.AddMachine("quantal", state.JobHostUnits)
+	c.Assert(err, jc.ErrorIsNil)
+}
+
+func (s *ProvisioningErrorSuite) TestProvisioningErrorNotFound(c *gc.C) {
+	_, err := s.machine.ProvisioningError()
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+	c.Assert(err, gc.ErrorMatches, `provisioning error for machine 0 not found`)
+}
+
+func (s *ProvisioningErrorSuite) TestSetProvisioningError(c *gc.C) {
+	err := s.machine.SetProvisioningError(state.MachineProvisioningError{
+		Kind:     "quota",
+		Message:  "cores quota exhausted",
+		Attempts: 3,
+		Updated:  time.Unix(1, 0),
+	})
+	c.Assert(err, jc.ErrorIsNil)
+
+	perr, err := s.machine.ProvisioningError()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(perr, jc.DeepEquals, state.MachineProvisioningError{
+		Kind:     "quota",
+		Message:  "cores quota exhausted",
+		Attempts: 3,
+		Updated:  s.Clock.Now().UTC(),
+	})
+}
+
+func (s *ProvisioningErrorSuite) TestSetProvisioningErrorReplaces(c *gc.C) {
+	err := s.machine.SetProvisioningError(state.MachineProvisioningError{
+		Kind: "quota", Message: "cores quota exhausted", Attempts: 3,
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	s.Clock.Advance(time.Minute)
+	err = s.machine.SetProvisioningError(state.MachineProvisioningError{
+		Kind: "auth", Message: "invalid credentials", Attempts: 1,
+	})
+	c.Assert(err, jc.ErrorIsNil)
+
+	perr, err := s.machine.ProvisioningError()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(perr, jc.DeepEquals, state.MachineProvisioningError{
+		Kind:     "auth",
+		Message:  "invalid credentials",
+		Attempts: 1,
+		Updated:  s.Clock.Now().UTC(),
+	})
+}
+
+func (s *ProvisioningErrorSuite) TestSetProvisioningErrorDead(c *gc.C) {
+	err := s.machine.EnsureDead()
+	c.Assert(err, jc.ErrorIsNil)
+	err = s.machine.SetProvisioningError(state.MachineProvisioningError{Kind: "quota"})
+	c.Assert(err, gc.ErrorMatches, `cannot set provisioning error for machine 0: not found or dead`)
+}
+
+func (s *ProvisioningErrorSuite) TestClearProvisioningError(c *gc.C) {
+	err := s.machine.ClearProvisioningError()
+	c.Assert(err, jc.ErrorIsNil)
+
+	err = s.machine.SetProvisioningError(state.MachineProvisioningError{Kind: "quota"})
+	c.Assert(err, jc.ErrorIsNil)
+	err = s.machine.ClearProvisioningError()
+	c.Assert(err, jc.ErrorIsNil)
+	_, err = s.machine.ProvisioningError()
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+}
+
+func (s *ProvisioningErrorSuite) TestSetProvisionedClearsProvisioningError(c *gc.C) {
+	err := s.machine.SetProvisioningError(state.MachineProvisioningError{Kind: "capacity"})
+	c.Assert(err, jc.ErrorIsNil)
+	err = s.machine.SetProvisioned(instance.Id("inst-0"), "fake_nonce", nil)
+	c.Assert(err, jc.ErrorIsNil)
+	_, err = s.machine.ProvisioningError()
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+}
+
+func (s *ProvisioningErrorSuite) TestRemoveMachineRemovesProvisioningError(c *gc.C) {
+	err := s.machine.SetProvisioningError(state.MachineProvisioningError{Kind: "capacity"})
+	c.Assert(err, jc.ErrorIsNil)
+	err = s.machine.EnsureDead()
+	c.Assert(err, jc.ErrorIsNil)
+	err = s.machine.Remove()
+	c.Assert(err, jc.ErrorIsNil)
+	_, err = s.machine.ProvisioningError()
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+}
@@ -193,29 +193,12 @@ func (r *Relation) removeOps(ignoreService string, departingUnit *Unit) ([]txn.O
 			asserts = append(hasRelation, cannotDieYet...)
 		} else {
 			// This service may require immediate removal.
-			applications, closer := r.st.getCollection(applicationsC)
-			defer closer()
-
-			svc := &Application{st: r.st}
-			hasLastRef := bson.D{{"life", Dying}, {"unitcount", 0}, {"relationcount", 1}}
-			removable := append(bson.D{{"_id", ep.ApplicationName}}, hasLastRef...)
-			if err := applications.Find(removable).One(&svc.doc); err == nil {
-				appRemoveOps, err := svc.removeOps(hasLastRef)
-				if err != nil {
-					return nil, errors.Trace(err)
-				}
-				ops = append(ops, appRemoveOps...)
-				continue
-			} else if err != mgo.ErrNotFound {
-				return nil, err
+			appOps, err := r.releaseApplicationOps(ep.ApplicationName)
+			if err != nil {
+				return nil, errors.Trace(err)
 			}
-			// If not, we must check that this is still the case when the
-			// transaction is applied.
-			asserts = bson.D{{"$or", []bson.D{
-				{{"life", Alive}},
-				{{"unitcount", bson.D{{"$gt", 0}}}},
-				{{"relationcount", bson.D{{"$gt", 1}}}},
-			}}}
+			ops = append(ops, appOps...)
+			continue
 		}
 		ops = append(ops, txn.Op{
 			C:      applicationsC,
@@ -228,6 +211,40 @@ func (r *Relation) removeOps(ignoreService string, departingUnit *Unit) ([]txn.O
 	return append(ops, cleanupOp), nil
 }
 
+// releaseApplicationOps returns the operations necessary to release the
+// named application's reference to the relation as the relation is
+// removed with its last unit. The application may be Dying and otherwise
+// unreferenced, in which case it is removed too.
+func (r *Relation) releaseApplicationOps(applicationName string) ([]txn.Op, error) {
+	applications, closer := r.st.getCollection(applicationsC)
+	defer closer()
+
+	svc := &Application{st: r.st}
+	hasLastRef := bson.D{{"life", Dying}, {"unitcount", 0}, {"relationcount", 1}}
+	removable := append(bson.D{{"_id", applicationName}}, hasLastRef...)
+	if err := applications.Find(removable).One(&svc.doc); err == nil {
+		appRemoveOps, err := svc.removeOps(hasLastRef)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		return appRemoveOps, nil
+	} else if err != mgo.ErrNotFound {
+		return nil, err
+	}
+	// If not, we must check that this is still the case when the
+	// transaction is applied.
+	return []txn.Op{{
+		C:  applicationsC,
+		Id: r.st.docID(applicationName),
+		Assert: bson.D{{"$or", []bson.D{
+			{{"life", Alive}},
+			{{"unitcount", bson.D{{"$gt", 0}}}},
+			{{"relationcount", bson.D{{"$gt", 1}}}},
+		}}},
+		Update: bson.D{{"$inc", bson.D{{"relationcount", -1}}}},
+	}}, nil
+}
+
 // Id returns the integer internal relation key. This is exposed
 // because the unit agent needs to expose a value derived from this
 // (as JUJU_RELATION_ID) to allow relation hooks to differentiate
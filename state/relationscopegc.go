@@ -0,0 +1,196 @@
+// Copyright 2016 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package state
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/juju/errors"
+	jujutxn "github.com/juju/txn"
+	"gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
+	"gopkg.in/mgo.v2/txn"
+)
+
+// StaleRelationScopes returns the sorted keys of the relation scope
+// memberships whose units will never leave the scope: those of units
+// that have been removed or are Dead, or that are assigned to machines
+// that have been removed or are Dead. Such memberships are normally left
+// behind by unit agents that stopped without leaving their scopes, and
+// prevent the removal of Dying relations and applications.
+func (st *State) StaleRelationScopes() ([]string, error) {
+	relationScopes, closer := st.getCollection(relationScopesC)
+	defer closer()
+
+	var docs []relationScopeDoc
+	if err := relationScopes.Find(nil).All(&docs); err != nil {
+		return nil, errors.Annotate(err, "cannot get relation scopes")
+	}
+	var stale []string
+	for _, doc := range docs {
+		_, err := st.staleRelationScopeAssertOp(doc.unitName())
+		if errors.IsNotValid(err) {
+			continue
+		} else if err != nil {
+			return nil, errors.Trace(err)
+		}
+		stale = append(stale, doc.Key)
+	}
+	sort.Strings(stale)
+	return stale, nil
+}
+
+// RemoveStaleRelationScope removes the stale relation scope membership
+// with the given key, as reported by StaleRelationScopes, on behalf of
+// its unit. As when the unit leaves the scope itself, a Dying relation is
+// removed along with its last member, as are any Dying applications that
+// are otherwise unreferenced. It is not an error to remove a membership
+// that no longer exists; if the membership is not stale, an error
+// satisfying errors.IsNotValid is returned.
+func (st *State) RemoveStaleRelationScope(key string) (err error) {
+	defer errors.DeferredAnnotatef(&err, "cannot remove relation scope %q", key)
+	relationId, err := relationIdFromScopeKey(key)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	relationScopes, closer := st.getCollection(relationScopesC)
+	defer closer()
+
+	buildTxn := func(attempt int) ([]txn.Op, error) {
+		var doc relationScopeDoc
+		if err := relationScopes.FindId(key).One(&doc); err == mgo.ErrNotFound {
+			return nil, jujutxn.ErrNoOperations
+		} else if err != nil {
+			return nil, errors.Trace(err)
+		}
+		assertOp, err := st.staleRelationScopeAssertOp(doc.unitName())
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		ops := []txn.Op{assertOp, {
+			C:      relationScopesC,
+			Id:     key,
+			Assert: txn.DocExists,
+			Remove: true,
+		}}
+		rel, err := st.Relation(relationId)
+		if errors.IsNotFound(err) {
+			// The relation is gone, so the membership is simply
+			// an orphan.
+			return ops, nil
+		} else if err != nil {
+			return nil, errors.Trace(err)
+		}
+		relOps, err := rel.staleScopeRemovalOps()
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		return append(ops, relOps...), nil
+	}
+	return st.run(buildTxn)
+}
+
+// staleRelationScopeAssertOp returns an operation asserting that the
+// named unit will never leave its relation scopes. If the unit may yet
+// leave them, an error satisfying errors.IsNotValid is returned.
+func (st *State) staleRelationScopeAssertOp(unitName string) (txn.Op, error) {
+	units, closer := st.getCollection(unitsC)
+	defer closer()
+
+	var unit struct {
+		Life      Life   `bson:"life"`
+		MachineId string `bson:"machineid"`
+	}
+	unitOp := txn.Op{C: unitsC, Id: st.docID(unitName)}
+	err := units.FindId(unitName).Select(bson.D{{"life", 1}, {"machineid", 1}}).One(&unit)
+	if err == mgo.ErrNotFound {
+		unitOp.Assert = txn.DocMissing
+		return unitOp, nil
+	} else if err != nil {
+		return txn.Op{}, errors.Annotatef(err, "cannot get unit %q", unitName)
+	}
+	if unit.Life == Dead {
+		unitOp.Assert = isDeadDoc
+		return unitOp, nil
+	}
+	if unit.MachineId != "" {
+		machines, closer := st.getCollection(machinesC)
+		defer closer()
+
+		var machine struct {
+			Life Life `bson:"life"`
+		}
+		machineOp := txn.Op{C: machinesC, Id: st.docID(unit.MachineId)}
+		err := machines.FindId(unit.MachineId).Select(bson.D{{"life", 1}}).One(&machine)
+		if err == mgo.ErrNotFound {
+			machineOp.Assert = txn.DocMissing
+			return machineOp, nil
+		} else if err != nil {
+			return txn.Op{}, errors.Annotatef(err, "cannot get machine %q", unit.MachineId)
+		}
+		if machine.Life == Dead {
+			machineOp.Assert = isDeadDoc
+			return machineOp, nil
+		}
+	}
+	return txn.Op{}, errors.NotValidf("removing relation scope of live unit %q", unitName)
+}
+
+// staleScopeRemovalOps returns the operations necessary to account
+// for a member of the relation's scope that is being removed on behalf
+// of its unit, which may no longer exist.
+func (r *Relation) staleScopeRemovalOps() ([]txn.Op, error) {
+	if r.doc.Life == Alive {
+		return []txn.Op{{
+			C:      relationsC,
+			Id:     r.doc.DocID,
+			Assert: bson.D{{"life", Alive}},
+			Update: bson.D{{"$inc", bson.D{{"unitcount", -1}}}},
+		}}, nil
+	}
+	if r.doc.UnitCount > 1 {
+		return []txn.Op{{
+			C:      relationsC,
+			Id:     r.doc.DocID,
+			Assert: bson.D{{"unitcount", bson.D{{"$gt", 1}}}},
+			Update: bson.D{{"$inc", bson.D{{"unitcount", -1}}}},
+		}}, nil
+	}
+	// This is the last member of a Dying relation, so the relation
+	// is removed. Unlike when a unit leaves the scope itself, the
+	// unit may have been removed already, so none of the relation's
+	// applications can be assumed to have units remaining.
+	ops := []txn.Op{{
+		C:      relationsC,
+		Id:     r.doc.DocID,
+		Assert: bson.D{{"life", Dying}, {"unitcount", 1}},
+		Remove: true,
+	}}
+	for _, ep := range r.doc.Endpoints {
+		appOps, err := r.releaseApplicationOps(ep.ApplicationName)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		ops = append(ops, appOps...)
+	}
+	cleanupOp := newCleanupOp(cleanupRelationSettings, fmt.Sprintf("r#%d#", r.Id()))
+	return append(ops, cleanupOp), nil
+}
+
+// relationIdFromScopeKey returns the id of the relation whose scope
+// is identified by the given key.
+func relationIdFromScopeKey(key string) (int, error) {
+	parts := strings.Split(key, "#")
+	if len(parts) < 4 || parts[0] != "r" {
+		return 0, errors.NotValidf("relation scope key %q", key)
+	}
+	id, err := strconv.Atoi(parts[1])
+	if err != nil {
+		return 0, errors.NotValidf("relation scope key %q", key)
+	}
+	return id, nil
+}
@@ -0,0 +1,121 @@
+// Copyright 2016 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package state_test
+
+import (
+	"fmt"
+
+	"github.com/juju/errors"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/state"
+)
+
+type relationScopeGCSuite struct {
+	ConnSuite
+}
+
+var _ = gc.Suite(&relationScopeGCSuite{})
+
+func (s *relationScopeGCSuite) TestStaleRelationScopesNone(c *gc.C) {
+	pr := NewPeerRelation(c, s.State)
+	err := pr.ru0.EnterScope(nil)
+	c.Assert(err, jc.ErrorIsNil)
+	err = pr.u1.Destroy()
+	c.Assert(err, jc.ErrorIsNil)
+	err = pr.ru1.EnterScope(nil)
+	c.Assert(err, gc.Equals, state.ErrCannotEnterScope)
+
+	stale, err := s.State.StaleRelationScopes()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(stale, gc.HasLen, 0)
+}
+
+func (s *relationScopeGCSuite) TestRemoveStaleRelationScopeDeadUnit(c *gc.C) {
+	pr := NewPeerRelation(c, s.State)
+	err := pr.ru0.EnterScope(nil)
+	c.Assert(err, jc.ErrorIsNil)
+	err = pr.ru1.EnterScope(nil)
+	c.Assert(err, jc.ErrorIsNil)
+	err = pr.u0.EnsureDead()
+	c.Assert(err, jc.ErrorIsNil)
+
+	stale, err := s.State.StaleRelationScopes()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(stale, gc.HasLen, 1)
+	c.Assert(stale[0], gc.Matches, `r#\d+#peer#riak/0`)
+
+	err = s.State.RemoveStaleRelationScope(stale[0])
+	c.Assert(err, jc.ErrorIsNil)
+	assertNotInScope(c, pr.ru0)
+	assertJoined(c, pr.ru1)
+
+	// Removing it again is a no-op.
+	err = s.State.RemoveStaleRelationScope(stale[0])
+	c.Assert(err, jc.ErrorIsNil)
+
+	stale, err = s.State.StaleRelationScopes()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(stale, gc.HasLen, 0)
+
+	// The relation is unaffected, and counts only the live member.
+	err = pr.rel.Refresh()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(pr.rel.Life(), gc.Equals, state.Alive)
+	err = pr.ru1.LeaveScope()
+	c.Assert(err, jc.ErrorIsNil)
+	err = pr.svc.Destroy()
+	c.Assert(err, jc.ErrorIsNil)
+	err = pr.rel.Refresh()
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+}
+
+func (s *relationScopeGCSuite) TestRemoveStaleRelationScopeLastMember(c *gc.C) {
+	pr := NewPeerRelation(c, s.State)
+	err := pr.ru0.EnterScope(nil)
+	c.Assert(err, jc.ErrorIsNil)
+	err = pr.svc.Destroy()
+	c.Assert(err, jc.ErrorIsNil)
+	err = pr.rel.Refresh()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(pr.rel.Life(), gc.Equals, state.Dying)
+	err = pr.u0.EnsureDead()
+	c.Assert(err, jc.ErrorIsNil)
+
+	stale, err := s.State.StaleRelationScopes()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(stale, gc.HasLen, 1)
+	err = s.State.RemoveStaleRelationScope(stale[0])
+	c.Assert(err, jc.ErrorIsNil)
+
+	// The Dying relation is removed with its last member; the
+	// application remains until its units are removed.
+	err = pr.rel.Refresh()
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+	err = pr.svc.Refresh()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(pr.svc.Life(), gc.Equals, state.Dying)
+}
+
+func (s *relationScopeGCSuite) TestRemoveStaleRelationScopeLiveUnit(c *gc.C) {
+	pr := NewPeerRelation(c, s.State)
+	err := pr.ru0.EnterScope(nil)
+	c.Assert(err, jc.ErrorIsNil)
+	err = pr.u1.EnsureDead()
+	c.Assert(err, jc.ErrorIsNil)
+
+	stale, err := s.State.StaleRelationScopes()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(stale, gc.HasLen, 0)
+
+	key := fmt.Sprintf("r#%d#peer#riak/0", pr.rel.Id())
+	err = s.State.RemoveStaleRelationScope(key)
+	c.Assert(err, gc.ErrorMatches, `cannot remove relation scope ".*": removing relation scope of live unit "riak/0" not valid`)
+	c.Assert(err, jc.Satisfies, errors.IsNotValid)
+	assertJoined(c, pr.ru0)
+
+	err = s.State.RemoveStaleRelationScope("riak/0")
+	c.Assert(err, gc.ErrorMatches, `cannot remove relation scope "riak/0": relation scope key "riak/0" not valid`)
+}
@@ -0,0 +1,238 @@
+// Copyright 2016 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package state
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/juju/errors"
+	jujutxn "github.com/juju/txn"
+	"gopkg.in/mgo.v2/bson"
+	"gopkg.in/mgo.v2/txn"
+)
+
+// scaleDoc keeps track of changes relevant to an application's desired
+// scale. A document is created when the desired scale is set, and is
+// removed when the desired scale is unset or the application is destroyed.
+// The Revno is increased when the desired scale changes, and whenever a
+// unit of the application is added or destroyed, so that the scaler can
+// converge the number of alive units on the desired scale.
+type scaleDoc struct {
+	DocID           string `bson:"_id"`
+	ApplicationName string
+	ModelUUID       string `bson:"model-uuid"`
+	Revno           int
+}
+
+// DesiredScale returns the number of units the application is
+// expected to have, and whether a desired scale has been set.
+func (a *Application) DesiredScale() (int, bool) {
+	if a.doc.DesiredScale == nil {
+		return 0, false
+	}
+	return *a.doc.DesiredScale, true
+}
+
+// SetScale sets the number of units the application is expected to
+// have. Once set, units are added or destroyed by the application
+// scaler until the number of alive units matches the desired scale
+// (or the minimum number of units, if that is greater).
+func (a *Application) SetScale(scale int) (err error) {
+	defer errors.DeferredAnnotatef(&err, "cannot set scale for application %q", a)
+	if scale < 0 {
+		return errors.New("cannot set a negative scale")
+	}
+	return a.updateScale(&scale)
+}
+
+// UnsetScale clears the application's desired scale, so that units
+// are once again added and destroyed only on request.
+func (a *Application) UnsetScale() (err error) {
+	defer errors.DeferredAnnotatef(&err, "cannot unset scale for application %q", a)
+	return a.updateScale(nil)
+}
+
+func (a *Application) updateScale(scale *int) error {
+	app := &Application{st: a.st, doc: a.doc}
+	buildTxn := func(attempt int) ([]txn.Op, error) {
+		if attempt > 0 {
+			if err := app.Refresh(); err != nil {
+				return nil, err
+			}
+		}
+		if app.doc.Life != Alive {
+			return nil, errors.New("application is no longer alive")
+		}
+		current, set := app.DesiredScale()
+		if scale == nil && !set || scale != nil && set && *scale == current {
+			return nil, jujutxn.ErrNoOperations
+		}
+		return setScaleOps(app, scale), nil
+	}
+	if err := a.st.run(buildTxn); err != nil {
+		return err
+	}
+	a.doc.DesiredScale = scale
+	return nil
+}
+
+// setScaleOps returns the operations required to set DesiredScale on
+// the application and to create/update/remove the scale document.
+func setScaleOps(app *Application, scale *int) []txn.Op {
+	st := app.st
+	name := app.Name()
+	op := txn.Op{
+		C:      applicationsC,
+		Id:     app.doc.DocID,
+		Assert: isAliveDoc,
+	}
+	if scale == nil {
+		op.Update = bson.D{{"$unset", bson.D{{"desiredscale", nil}}}}
+		return []txn.Op{op, scaleRemoveOp(st, name)}
+	}
+	op.Update = bson.D{{"$set", bson.D{{"desiredscale", *scale}}}}
+	if app.doc.DesiredScale == nil {
+		return []txn.Op{op, {
+			C:      scalesC,
+			Id:     st.docID(name),
+			Assert: txn.DocMissing,
+			Insert: &scaleDoc{
+				ApplicationName: name,
+				ModelUUID:       st.ModelUUID(),
+			},
+		}}
+	}
+	trigger := scaleTriggerOp(st, name)
+	trigger.Assert = txn.DocExists
+	return []txn.Op{op, trigger}
+}
+
+// scaleTriggerOp returns the operation required to increase the scale
+// revno for the application, ignoring the case of the document not
+// existing. This is included in the operations performed when a unit
+// is added or destroyed; if the application does not have a desired
+// scale, the operation is a noop.
+func scaleTriggerOp(st *State, applicationname string) txn.Op {
+	return txn.Op{
+		C:      scalesC,
+		Id:     st.docID(applicationname),
+		Update: bson.D{{"$inc", bson.D{{"revno", 1}}}},
+	}
+}
+
+// scaleRemoveOp returns the operation required to remove the scale
+// document for the application.
+func scaleRemoveOp(st *State, applicationname string) txn.Op {
+	return txn.Op{
+		C:      scalesC,
+		Id:     st.docID(applicationname),
+		Remove: true,
+	}
+}
+
+// EnsureScale adds or destroys units until the number of alive units
+// matches the application's desired scale. The minimum number of units
+// takes precedence: the application is never scaled below MinUnits.
+// If no desired scale is set, EnsureScale does nothing. Units are not
+// added while the application is paused.
+func (a *Application) EnsureScale() (err error) {
+	defer errors.DeferredAnnotatef(&err, "cannot ensure scale for application %q", a)
+	app := &Application{st: a.st, doc: a.doc}
+	if err := app.Refresh(); err != nil {
+		return err
+	}
+	for {
+		if app.doc.Life != Alive {
+			return errors.New("application is not alive")
+		}
+		scale, ok := app.DesiredScale()
+		if !ok {
+			return nil
+		}
+		if scale < app.doc.MinUnits {
+			scale = app.doc.MinUnits
+		}
+		units, err := aliveUnits(app)
+		if err != nil {
+			return err
+		}
+		if len(units) > scale {
+			// Destroy the most recently added units first.
+			sort.Sort(sort.Reverse(unitsByNumber(units)))
+			for _, unit := range units[scale:] {
+				if err := unit.Destroy(); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+		missing := scale - len(units)
+		if missing == 0 {
+			return nil
+		}
+		// Units may not be added to a paused application. The
+		// missing units are added when the application is resumed.
+		if app.doc.Paused {
+			return nil
+		}
+		name, ops, err := ensureMinUnitsOps(app)
+		if err != nil {
+			return err
+		}
+		switch err := a.st.runTransaction(ops); err {
+		case nil:
+			unit, err := a.st.Unit(name)
+			if err != nil {
+				return err
+			}
+			if err := a.st.AssignUnit(unit, AssignNew); err != nil {
+				return err
+			}
+			if missing == 1 {
+				return nil
+			}
+		case txn.ErrAborted:
+			// Refresh the application and restart the loop.
+		default:
+			return err
+		}
+		if err := app.Refresh(); err != nil {
+			return err
+		}
+	}
+}
+
+// aliveUnits returns the alive units of the application.
+func aliveUnits(app *Application) ([]*Unit, error) {
+	unitsCollection, closer := app.st.getCollection(unitsC)
+	defer closer()
+
+	var docs []unitDoc
+	query := bson.D{{"application", app.doc.Name}, {"life", Alive}}
+	if err := unitsCollection.Find(query).All(&docs); err != nil {
+		return nil, errors.Trace(err)
+	}
+	units := make([]*Unit, len(docs))
+	for i := range docs {
+		units[i] = newUnit(app.st, &docs[i])
+	}
+	return units, nil
+}
+
+// unitsByNumber sorts units of a single application by unit number.
+type unitsByNumber []*Unit
+
+func (u unitsByNumber) Len() int      { return len(u) }
+func (u unitsByNumber) Swap(i, j int) { u[i], u[j] = u[j], u[i] }
+func (u unitsByNumber) Less(i, j int) bool {
+	return unitNumber(u[i].Name()) < unitNumber(u[j].Name())
+}
+
+// unitNumber returns the number part of the given unit name.
+func unitNumber(name string) int {
+	n, _ := strconv.Atoi(name[strings.LastIndex(name, "/")+1:])
+	return n
+}
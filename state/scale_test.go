@@ -0,0 +1,222 @@
+// Copyright 2016 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package state_test
+
+import (
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/state"
+)
+
+type ScaleSuite struct {
+	ConnSuite
+	application *state.Application
+}
+
+var _ = gc.Suite(&ScaleSuite{})
+
+func (s *ScaleSuite) SetUpTest(c *gc.C) {
+	s.ConnSuite.SetUpTest(c)
+	s.application = s.AddTestingService(c, "dummy-application", s.AddTestingCharm(c, "dummy"))
+}
+
+func (s *ScaleSuite) addUnits(c *gc.C, count int) {
+	for i := 0; i < count; i++ {
+		_, err := s.application.AddUnit()
+		c.Assert(err, jc.ErrorIsNil)
+	}
+}
+
+func (s *ScaleSuite) assertDesiredScale(c *gc.C, expected int, expectedSet bool) {
+	for _, app := range []*state.Application{s.application, s.refreshed(c)} {
+		scale, ok := app.DesiredScale()
+		c.Assert(ok, gc.Equals, expectedSet)
+		c.Assert(scale, gc.Equals, expected)
+	}
+}
+
+func (s *ScaleSuite) refreshed(c *gc.C) *state.Application {
+	app, err := s.State.Application(s.application.Name())
+	c.Assert(err, jc.ErrorIsNil)
+	return app
+}
+
+func (s *ScaleSuite) TestDesiredScaleNotSet(c *gc.C) {
+	s.assertDesiredScale(c, 0, false)
+}
+
+func (s *ScaleSuite) TestSetScale(c *gc.C) {
+	err := s.application.SetScale(3)
+	c.Assert(err, jc.ErrorIsNil)
+	s.assertDesiredScale(c, 3, true)
+
+	err = s.application.SetScale(0)
+	c.Assert(err, jc.ErrorIsNil)
+	s.assertDesiredScale(c, 0, true)
+
+	err = s.application.UnsetScale()
+	c.Assert(err, jc.ErrorIsNil)
+	s.assertDesiredScale(c, 0, false)
+
+	// Unsetting again is a no-op.
+	err = s.application.UnsetScale()
+	c.Assert(err, jc.ErrorIsNil)
+	s.assertDesiredScale(c, 0, false)
+}
+
+func (s *ScaleSuite) TestSetScaleNegative(c *gc.C) {
+	err := s.application.SetScale(-1)
+	c.Assert(err, gc.ErrorMatches, `cannot set scale for application "dummy-application": cannot set a negative scale`)
+	s.assertDesiredScale(c, 0, false)
+}
+
+func (s *ScaleSuite) TestSetScaleApplicationNotAlive(c *gc.C) {
+	s.addUnits(c, 1)
+	err := s.application.Destroy()
+	c.Assert(err, jc.ErrorIsNil)
+	err = s.application.SetScale(2)
+	c.Assert(err, gc.ErrorMatches, `cannot set scale for application "dummy-application": application is no longer alive`)
+}
+
+func (s *ScaleSuite) TestEnsureScale(c *gc.C) {
+	for i, t := range []struct {
+		about    string // Test description.
+		initial  int    // Initial number of units.
+		scale    int    // Desired scale, if non-negative.
+		minimum  int    // Minimum number of units for the application.
+		expected int    // Expected number of units after calling EnsureScale.
+	}{{
+		about:    "no scale set",
+		initial:  2,
+		scale:    -1,
+		expected: 2,
+	}, {
+		about:    "scale up",
+		initial:  1,
+		scale:    3,
+		expected: 3,
+	}, {
+		about:    "scale down",
+		initial:  4,
+		scale:    1,
+		expected: 1,
+	}, {
+		about:    "scale to zero",
+		initial:  2,
+		scale:    0,
+		expected: 0,
+	}, {
+		about:    "scale matches",
+		initial:  2,
+		scale:    2,
+		expected: 2,
+	}, {
+		about:    "minimum units takes precedence",
+		initial:  4,
+		scale:    1,
+		minimum:  2,
+		expected: 2,
+	}} {
+		c.Logf("test %d. %s", i, t.about)
+		s.addUnits(c, t.initial)
+		err := s.application.SetMinUnits(t.minimum)
+		c.Assert(err, jc.ErrorIsNil)
+		if t.scale >= 0 {
+			err := s.application.SetScale(t.scale)
+			c.Assert(err, jc.ErrorIsNil)
+		}
+
+		err = s.application.EnsureScale()
+		c.Assert(err, jc.ErrorIsNil)
+		assertAllUnits(c, s.application, t.expected)
+
+		err = s.application.UnsetScale()
+		c.Assert(err, jc.ErrorIsNil)
+		err = s.application.SetMinUnits(0)
+		c.Assert(err, jc.ErrorIsNil)
+		removeAllUnits(c, s.application)
+	}
+}
+
+func (s *ScaleSuite) TestEnsureScaleDestroysNewestUnits(c *gc.C) {
+	s.addUnits(c, 3)
+	err := s.application.SetScale(1)
+	c.Assert(err, jc.ErrorIsNil)
+	err = s.application.EnsureScale()
+	c.Assert(err, jc.ErrorIsNil)
+
+	units, err := s.application.AllUnits()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(units, gc.HasLen, 1)
+	c.Assert(units[0].Name(), gc.Equals, "dummy-application/0")
+}
+
+func (s *ScaleSuite) TestEnsureScaleIgnoresDyingUnits(c *gc.C) {
+	s.addUnits(c, 2)
+	units, err := s.application.AllUnits()
+	c.Assert(err, jc.ErrorIsNil)
+	preventUnitDestroyRemove(c, units[0])
+	err = units[0].Destroy()
+	c.Assert(err, jc.ErrorIsNil)
+
+	err = s.application.SetScale(2)
+	c.Assert(err, jc.ErrorIsNil)
+	err = s.application.EnsureScale()
+	c.Assert(err, jc.ErrorIsNil)
+	// The dying unit is replaced.
+	assertAllUnits(c, s.application, 3)
+}
+
+func (s *ScaleSuite) TestEnsureScaleApplicationNotAlive(c *gc.C) {
+	err := s.application.SetScale(2)
+	c.Assert(err, jc.ErrorIsNil)
+	s.addUnits(c, 1)
+	err = s.application.Destroy()
+	c.Assert(err, jc.ErrorIsNil)
+	err = s.application.EnsureScale()
+	c.Assert(err, gc.ErrorMatches, `cannot ensure scale for application "dummy-application": application is not alive`)
+}
+
+func (s *ScaleSuite) TestEnsureScalePaused(c *gc.C) {
+	s.addUnits(c, 1)
+	err := s.application.SetScale(3)
+	c.Assert(err, jc.ErrorIsNil)
+	err = s.application.Pause()
+	c.Assert(err, jc.ErrorIsNil)
+
+	// No units are added while the application is paused.
+	err = s.application.EnsureScale()
+	c.Assert(err, jc.ErrorIsNil)
+	assertAllUnits(c, s.application, 1)
+
+	err = s.application.Resume()
+	c.Assert(err, jc.ErrorIsNil)
+	err = s.application.EnsureScale()
+	c.Assert(err, jc.ErrorIsNil)
+	assertAllUnits(c, s.application, 3)
+}
+
+func (s *ScaleSuite) TestEnsureScalePausedScalesDown(c *gc.C) {
+	s.addUnits(c, 3)
+	err := s.application.SetScale(1)
+	c.Assert(err, jc.ErrorIsNil)
+	err = s.application.Pause()
+	c.Assert(err, jc.ErrorIsNil)
+	err = s.application.EnsureScale()
+	c.Assert(err, jc.ErrorIsNil)
+	assertAllUnits(c, s.application, 1)
+}
+
+func (s *ScaleSuite) TestEnsureScalePausedBefore(c *gc.C) {
+	err := s.application.SetScale(2)
+	c.Assert(err, jc.ErrorIsNil)
+	defer state.SetBeforeHooks(c, s.State, func() {
+		err := s.application.Pause()
+		c.Assert(err, jc.ErrorIsNil)
+	}).Check()
+	err = s.application.EnsureScale()
+	c.Assert(err, jc.ErrorIsNil)
+	assertAllUnits(c, s.application, 0)
+}
@@ -38,3 +38,31 @@ func (s *State) sequence(name string) (int, error) {
 	}
 	return result.Counter, nil
 }
+
+// advanceSequence advances the sequence with the given name, if
+// necessary, so that its next value is no less than that of the
+// other named sequence.
+func (s *State) advanceSequence(name, other string) error {
+	sequences, closer := s.getCollection(sequenceC)
+	defer closer()
+	var otherDoc sequenceDoc
+	if err := sequences.FindId(other).One(&otherDoc); err == mgo.ErrNotFound {
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("cannot get %q sequence number: %v", other, err)
+	}
+	change := mgo.Change{
+		Update: bson.M{
+			"$set": bson.M{
+				"name":       name,
+				"model-uuid": s.ModelUUID(),
+			},
+			"$max": bson.M{"counter": otherDoc.Counter},
+		},
+		Upsert: true,
+	}
+	if _, err := sequences.FindId(name).Apply(change, &sequenceDoc{}); err != nil {
+		return fmt.Errorf("cannot advance %q sequence number: %v", name, err)
+	}
+	return nil
+}
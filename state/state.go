@@ -112,6 +112,12 @@ type State struct {
 
 	// TODO(anastasiamac 2015-07-16) As state gets broken up, remove this.
 	CloudImageMetadataStorage cloudimagemetadata.Storage
+
+	// base and watchOwner are set in a State returned by
+	// WithWatchOwner: base is the State from which it was
+	// derived, and watchOwner the owner of its watches.
+	base       *State
+	watchOwner string
 }
 
 // StateServingInfo holds information needed by a controller.
@@ -517,6 +523,9 @@ func (st *State) MongoSession() *mgo.Session {
 }
 
 func (st *State) Watch() *Multiwatcher {
+	if st.base != nil {
+		return st.base.Watch()
+	}
 	st.mu.Lock()
 	if st.allManager == nil {
 		st.allManager = newStoreManager(newAllWatcherStateBacking(st))
@@ -526,6 +535,9 @@ func (st *State) Watch() *Multiwatcher {
 }
 
 func (st *State) WatchAllModels() *Multiwatcher {
+	if st.base != nil {
+		return st.base.WatchAllModels()
+	}
 	st.mu.Lock()
 	if st.allModelManager == nil {
 		st.allModelWatcherBacking = NewAllModelWatcherStateBacking(st)
@@ -535,6 +547,60 @@ func (st *State) WatchAllModels() *Multiwatcher {
 	return NewMultiwatcher(st.allModelManager)
 }
 
+// WithWatchOwner returns a State for st's model that shares st's
+// database connection and workers, and whose watchers register their
+// database watches on behalf of the given owner. All of the owner's
+// watches can then be stopped with UnwatchOwner, such as when the API
+// connection that started them is closed.
+//
+// The returned State is valid for as long as st is, and must not
+// be closed.
+func (st *State) WithWatchOwner(owner string) *State {
+	if owner == "" {
+		panic("cannot watch on behalf of an empty owner")
+	}
+	base := st
+	if st.base != nil {
+		base = st.base
+	}
+	return &State{
+		clock:                     base.clock,
+		modelTag:                  base.modelTag,
+		controllerModelTag:        base.controllerModelTag,
+		controllerTag:             base.controllerTag,
+		mongoInfo:                 base.mongoInfo,
+		session:                   base.session,
+		database:                  base.database,
+		policy:                    base.policy,
+		newPolicy:                 base.newPolicy,
+		cloudName:                 base.cloudName,
+		leaseClientId:             base.leaseClientId,
+		workers:                   ownedWorkers{base.workers, owner},
+		CloudImageMetadataStorage: base.CloudImageMetadataStorage,
+		base:                      base,
+		watchOwner:                owner,
+	}
+}
+
+// WatchOwner returns the owner on whose behalf st's watchers register
+// their database watches, or "" if st was not returned by
+// WithWatchOwner.
+func (st *State) WatchOwner() string {
+	return st.watchOwner
+}
+
+// ownedWorkers is a workers.Workers whose TxnLogWatcher registers
+// watches on behalf of an owner.
+type ownedWorkers struct {
+	workers.Workers
+	owner string
+}
+
+// TxnLogWatcher is part of the workers.Workers interface.
+func (w ownedWorkers) TxnLogWatcher() workers.TxnLogWatcher {
+	return w.Workers.TxnLogWatcher().Owned(w.owner)
+}
+
 // versionInconsistentError indicates one or more agents have a
 // different version from the current one (even empty, when not yet
 // set).
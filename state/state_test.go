@@ -1943,6 +1943,45 @@ func (s *StateSuite) TestWatchModelsLifecycle(c *gc.C) {
 	wc.AssertNoChange()
 }
 
+func (s *StateSuite) TestWithWatchOwnerUnwatchOwner(c *gc.C) {
+	owned := s.State.WithWatchOwner("connection-1")
+	c.Assert(owned.WatchOwner(), gc.Equals, "connection-1")
+	c.Assert(owned.ModelUUID(), gc.Equals, s.State.ModelUUID())
+
+	w := owned.WatchModels()
+	defer statetesting.AssertStop(c, w)
+	wc := statetesting.NewStringsWatcherC(c, s.State, w)
+	wc.AssertChange(s.State.ModelUUID())
+	wc.AssertNoChange()
+
+	// Once the owner's watches are stopped, the
+	// watcher sees no further changes.
+	s.State.UnwatchOwner("connection-1")
+	st1 := s.Factory.MakeModel(c, nil)
+	defer st1.Close()
+	wc.AssertNoChange()
+}
+
+func (s *StateSuite) TestWithWatchOwnerOtherOwnersUnaffected(c *gc.C) {
+	w := s.State.WithWatchOwner("connection-1").WatchModels()
+	defer statetesting.AssertStop(c, w)
+	wc := statetesting.NewStringsWatcherC(c, s.State, w)
+	wc.AssertChange(s.State.ModelUUID())
+
+	s.State.UnwatchOwner("connection-2")
+	st1 := s.Factory.MakeModel(c, nil)
+	defer st1.Close()
+	wc.AssertChange(st1.ModelUUID())
+	wc.AssertNoChange()
+}
+
+func (s *StateSuite) TestWithWatchOwnerCannotClose(c *gc.C) {
+	owned := s.State.WithWatchOwner("connection-1")
+	c.Assert(s.State.WatchOwner(), gc.Equals, "")
+	err := owned.Close()
+	c.Assert(err, gc.ErrorMatches, "closing state failed: cannot close a State returned by WithWatchOwner")
+}
+
 func (s *StateSuite) TestWatchServicesBulkEvents(c *gc.C) {
 	// Alive service...
 	dummyCharm := s.AddTestingCharm(c, "dummy")
@@ -3053,6 +3092,76 @@ func (s *StateSuite) TestWatchMinUnits(c *gc.C) {
 	wc.AssertClosed()
 }
 
+func (s *StateSuite) TestWatchScaledApplications(c *gc.C) {
+	// Check initial event.
+	w := s.State.WatchScaledApplications()
+	defer statetesting.AssertStop(c, w)
+	wc := statetesting.NewStringsWatcherC(c, s.State, w)
+	wc.AssertChange()
+	wc.AssertNoChange()
+
+	wordpress := s.AddTestingService(c,
+		"wordpress", s.AddTestingCharm(c, "wordpress"))
+	mysql := s.AddTestingService(c, "mysql", s.AddTestingCharm(c, "mysql"))
+	wordpress0, err := wordpress.AddUnit()
+	c.Assert(err, jc.ErrorIsNil)
+	wc.AssertNoChange()
+
+	// Minimum units changes are reported as for WatchMinUnits.
+	err = mysql.SetMinUnits(1)
+	c.Assert(err, jc.ErrorIsNil)
+	wc.AssertChange(mysql.Name())
+	wc.AssertNoChange()
+
+	// Setting the desired scale causes a change.
+	err = wordpress.SetScale(2)
+	c.Assert(err, jc.ErrorIsNil)
+	wc.AssertChange(wordpress.Name())
+	wc.AssertNoChange()
+
+	// Unlike minimum units, decreasing the scale causes a change.
+	err = wordpress.SetScale(1)
+	c.Assert(err, jc.ErrorIsNil)
+	wc.AssertChange(wordpress.Name())
+	wc.AssertNoChange()
+
+	// Adding a unit to a scaled application causes a change.
+	_, err = wordpress.AddUnit()
+	c.Assert(err, jc.ErrorIsNil)
+	wc.AssertChange(wordpress.Name())
+	wc.AssertNoChange()
+
+	// As does destroying one.
+	preventUnitDestroyRemove(c, wordpress0)
+	err = wordpress0.Destroy()
+	c.Assert(err, jc.ErrorIsNil)
+	wc.AssertChange(wordpress.Name())
+	wc.AssertNoChange()
+
+	// Pausing does not cause a change, but resuming does, so
+	// that units can be added once more.
+	err = wordpress.Pause()
+	c.Assert(err, jc.ErrorIsNil)
+	wc.AssertNoChange()
+	err = wordpress.Resume()
+	c.Assert(err, jc.ErrorIsNil)
+	wc.AssertChange(wordpress.Name())
+	wc.AssertNoChange()
+
+	// Unsetting the scale does not cause a change, and
+	// subsequent unit changes are not reported.
+	err = wordpress.UnsetScale()
+	c.Assert(err, jc.ErrorIsNil)
+	wc.AssertNoChange()
+	_, err = wordpress.AddUnit()
+	c.Assert(err, jc.ErrorIsNil)
+	wc.AssertNoChange()
+
+	// Stop watcher, check closed.
+	statetesting.AssertStop(c, w)
+	wc.AssertClosed()
+}
+
 func (s *StateSuite) TestWatchMinUnitsDiesOnStateClose(c *gc.C) {
 	testWatcherDiesWhenStateCloses(c, s.modelTag, s.State.ControllerTag(), func(c *gc.C, st *state.State) waiter {
 		w := st.WatchMinUnits()
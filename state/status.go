@@ -122,6 +122,122 @@ func setStatus(st *State, params setStatusParams) (err error) {
 	return errors.Trace(err)
 }
 
+// StatusUpdate describes a status to be set on an entity by SetStatuses.
+type StatusUpdate struct {
+	Entity status.StatusSetter
+	Status status.StatusInfo
+}
+
+// statusParamsSource is implemented by entities whose statuses may be
+// set as part of a batch by SetStatuses.
+type statusParamsSource interface {
+	statusParams(status.StatusInfo) (setStatusParams, error)
+}
+
+// SetStatuses sets the status of each of the given entities, returning
+// a slice holding the error, if any, encountered setting each status.
+// The statuses of machines, units and unit agents are validated as by
+// their SetStatus methods, and then set in a single transaction; the
+// statuses of any other entities are set individually.
+func (st *State) SetStatuses(updates []StatusUpdate) []error {
+	errs := make([]error, len(updates))
+	var batch []setStatusParams
+	var indices []int
+	for i, update := range updates {
+		source, ok := update.Entity.(statusParamsSource)
+		if !ok {
+			errs[i] = update.Entity.SetStatus(update.Status)
+			continue
+		}
+		params, err := source.statusParams(update.Status)
+		if err != nil {
+			errs[i] = err
+			continue
+		}
+		batch = append(batch, params)
+		indices = append(indices, i)
+	}
+	for i, err := range setStatuses(st, batch) {
+		errs[indices[i]] = err
+	}
+	return errs
+}
+
+// setStatuses sets the statuses described by the supplied params in a
+// single transaction, returning the error, if any, encountered setting
+// each status. Statuses whose documents do not exist are reported as
+// not found, and do not prevent the others from being set.
+func setStatuses(st *State, batch []setStatusParams) []error {
+	errs := make([]error, len(batch))
+	if len(batch) == 0 {
+		return errs
+	}
+	docs := make([]statusDoc, len(batch))
+	for i, params := range batch {
+		docs[i] = statusDoc{
+			Status:     params.status,
+			StatusInfo: params.message,
+			StatusData: utils.EscapeKeys(params.rawData),
+			Updated:    params.updated.UnixNano(),
+		}
+	}
+
+	buildTxn := func(int) ([]txn.Op, error) {
+		var ops []txn.Op
+		for i, params := range batch {
+			errs[i] = nil
+			txnRevno, err := st.readTxnRevno(statusesC, params.globalKey)
+			if errors.Cause(err) == mgo.ErrNotFound {
+				errs[i] = errors.Annotate(errors.NotFoundf(params.badge), "cannot set status")
+				continue
+			} else if err != nil {
+				return nil, errors.Trace(err)
+			}
+			ops = append(ops, txn.Op{
+				C:      statusesC,
+				Id:     params.globalKey,
+				Assert: bson.D{{"txn-revno", txnRevno}},
+				Update: bson.D{{"$set", &docs[i]}},
+			})
+		}
+		if len(ops) == 0 {
+			return nil, jujutxn.ErrNoOperations
+		}
+		return ops, nil
+	}
+	if err := st.run(buildTxn); err != nil {
+		err = errors.Annotate(err, "cannot set status")
+		for i := range errs {
+			if errs[i] == nil {
+				errs[i] = err
+			}
+		}
+		return errs
+	}
+
+	var historyDocs []interface{}
+	for i, params := range batch {
+		if errs[i] != nil {
+			continue
+		}
+		historyDocs = append(historyDocs, &historicalStatusDoc{
+			Status:     docs[i].Status,
+			StatusInfo: docs[i].StatusInfo,
+			StatusData: docs[i].StatusData,
+			Updated:    docs[i].Updated,
+			GlobalKey:  params.globalKey,
+		})
+	}
+	if len(historyDocs) > 0 {
+		history, closer := st.getCollection(statusesHistoryC)
+		defer closer()
+		if err := history.Writeable().Insert(historyDocs...); err != nil {
+			logger.Errorf("failed to write status history: %v", err)
+		}
+	}
+	return errs
+}
+
 // updateStatusSource returns a transaction source that builds the operations
 // necessary to set the supplied status (and to fail safely if leaked and
 // executed late, so as not to overwrite more recent documents).
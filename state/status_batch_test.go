@@ -0,0 +1,127 @@
+// Copyright 2016 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package state_test
+
+import (
+	"github.com/juju/errors"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/state"
+	"github.com/juju/juju/status"
+	"github.com/juju/juju/testing"
+)
+
+type StatusBatchSuite struct {
+	ConnSuite
+	machine *state.Machine
+	unit    *state.Unit
+}
+
+var _ = gc.Suite(&StatusBatchSuite{})
+
+func (s *StatusBatchSuite) SetUpTest(c *gc.C) {
+	s.ConnSuite.SetUpTest(c)
+	s.unit = s.Factory.MakeUnit(c, nil)
+	machineId, err := s.unit.AssignedMachineId()
+	c.Assert(err, jc.ErrorIsNil)
+	s.machine, err = s.State.Machine(machineId)
+	c.Assert(err, jc.ErrorIsNil)
+}
+
+func (s *StatusBatchSuite) statusInfo(value status.Status, message string) status.StatusInfo {
+	now := testing.ZeroTime()
+	return status.StatusInfo{
+		Status:  value,
+		Message: message,
+		Data:    map[string]interface{}{"$foo.bar": "baz"},
+		Since:   &now,
+	}
+}
+
+func (s *StatusBatchSuite) checkStatus(c *gc.C, getter status.StatusGetter, value status.Status, message string) {
+	statusInfo, err := getter.Status()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(statusInfo.Status, gc.Equals, value)
+	c.Check(statusInfo.Message, gc.Equals, message)
+	c.Check(statusInfo.Data, jc.DeepEquals, map[string]interface{}{"$foo.bar": "baz"})
+}
+
+func (s *StatusBatchSuite) TestSetStatuses(c *gc.C) {
+	application, err := s.unit.Application()
+	c.Assert(err, jc.ErrorIsNil)
+	errs := s.State.SetStatuses([]state.StatusUpdate{
+		{s.machine, s.statusInfo(status.Started, "machine")},
+		{s.unit, s.statusInfo(status.Active, "unit")},
+		{s.unit.Agent(), s.statusInfo(status.Executing, "agent")},
+		{application, s.statusInfo(status.Blocked, "application")},
+	})
+	c.Assert(errs, jc.DeepEquals, []error{nil, nil, nil, nil})
+
+	s.checkStatus(c, s.machine, status.Started, "machine")
+	s.checkStatus(c, s.unit, status.Active, "unit")
+	s.checkStatus(c, s.unit.Agent(), status.Executing, "agent")
+	s.checkStatus(c, application, status.Blocked, "application")
+}
+
+func (s *StatusBatchSuite) TestSetStatusesRecordsHistory(c *gc.C) {
+	errs := s.State.SetStatuses([]state.StatusUpdate{
+		{s.machine, s.statusInfo(status.Started, "machine")},
+		{s.unit, s.statusInfo(status.Active, "unit")},
+	})
+	c.Assert(errs, jc.DeepEquals, []error{nil, nil})
+
+	history, err := s.unit.StatusHistory(status.StatusHistoryFilter{Size: 1})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(history, gc.HasLen, 1)
+	c.Check(history[0].Status, gc.Equals, status.Active)
+	c.Check(history[0].Message, gc.Equals, "unit")
+
+	history, err = s.machine.StatusHistory(status.StatusHistoryFilter{Size: 1})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(history, gc.HasLen, 1)
+	c.Check(history[0].Status, gc.Equals, status.Started)
+	c.Check(history[0].Message, gc.Equals, "machine")
+}
+
+func (s *StatusBatchSuite) TestSetStatusesInvalid(c *gc.C) {
+	errs := s.State.SetStatuses([]state.StatusUpdate{
+		{s.machine, s.statusInfo(status.Error, "")},
+		{s.unit, s.statusInfo(status.Active, "unit")},
+		{s.unit.Agent(), s.statusInfo(status.Status("vliegkat"), "agent")},
+	})
+	c.Assert(errs, gc.HasLen, 3)
+	c.Check(errs[0], gc.ErrorMatches, `cannot set status "error" without info`)
+	c.Check(errs[1], jc.ErrorIsNil)
+	c.Check(errs[2], gc.ErrorMatches, `cannot set invalid status "vliegkat"`)
+
+	s.checkStatus(c, s.unit, status.Active, "unit")
+	agentStatus, err := s.unit.Agent().Status()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(agentStatus.Status, gc.Equals, status.Allocating)
+}
+
+func (s *StatusBatchSuite) TestSetStatusesGone(c *gc.C) {
+	machine := s.Factory.MakeMachine(c, nil)
+	err := machine.EnsureDead()
+	c.Assert(err, jc.ErrorIsNil)
+	err = machine.Remove()
+	c.Assert(err, jc.ErrorIsNil)
+
+	errs := s.State.SetStatuses([]state.StatusUpdate{
+		{machine, s.statusInfo(status.Started, "gone")},
+		{s.unit, s.statusInfo(status.Active, "unit")},
+	})
+	c.Assert(errs, gc.HasLen, 2)
+	c.Check(errs[0], gc.ErrorMatches, `cannot set status: machine not found`)
+	c.Check(errs[0], jc.Satisfies, errors.IsNotFound)
+	c.Check(errs[1], jc.ErrorIsNil)
+
+	s.checkStatus(c, s.unit, status.Active, "unit")
+}
+
+func (s *StatusBatchSuite) TestSetStatusesEmpty(c *gc.C) {
+	errs := s.State.SetStatuses(nil)
+	c.Assert(errs, gc.HasLen, 0)
+}
@@ -0,0 +1,409 @@
+// Copyright 2016 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package storage
+
+import (
+	"io"
+
+	"github.com/juju/errors"
+	"github.com/juju/loggo"
+	"gopkg.in/juju/blobstore.v2"
+	"gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
+)
+
+var logger = loggo.GetLogger("juju.state.storage")
+
+const (
+	// backendC is the name of the collection in the metadata
+	// database that records the backend holding the content of
+	// the controller's blobs.
+	backendC = "blobBackend"
+
+	// backendDocId is the id of the single document in backendC.
+	backendDocId = "backend"
+)
+
+// BackendType identifies a kind of blob backend.
+type BackendType string
+
+const (
+	// BackendGridFS stores blob content in MongoDB's GridFS. This
+	// is the default backend.
+	BackendGridFS BackendType = "gridfs"
+
+	// BackendFilesystem stores blob content in files beneath a
+	// directory on the controller machine. It is intended for
+	// small, non-HA controllers.
+	BackendFilesystem BackendType = "filesystem"
+
+	// BackendS3 stores blob content in an Amazon S3 bucket.
+	BackendS3 BackendType = "s3"
+
+	// BackendAzure stores blob content in an Azure Blob Storage
+	// container.
+	BackendAzure BackendType = "azure"
+)
+
+// Attributes of blob backend configuration.
+const (
+	// BackendTypeAttr is the attribute holding the type of
+	// a blob backend, when the configuration is expressed as
+	// a map of attributes.
+	BackendTypeAttr = "type"
+
+	// DirectoryAttr is the directory in which a filesystem
+	// backend stores blob content.
+	DirectoryAttr = "directory"
+
+	// BucketAttr, RegionAttr, AccessKeyAttr and SecretKeyAttr
+	// identify the bucket in which an S3 backend stores blob
+	// content, and the credentials used to access it.
+	BucketAttr    = "bucket"
+	RegionAttr    = "region"
+	AccessKeyAttr = "access-key"
+	SecretKeyAttr = "secret-key"
+
+	// AccountNameAttr, AccountKeyAttr and ContainerAttr identify
+	// the storage account and container in which an Azure backend
+	// stores blob content, and the key used to access them. The
+	// container must already exist.
+	AccountNameAttr = "account-name"
+	AccountKeyAttr  = "account-key"
+	ContainerAttr   = "container"
+)
+
+// requiredBackendAttrs holds the attributes required by each type of
+// blob backend.
+var requiredBackendAttrs = map[BackendType][]string{
+	BackendGridFS:     nil,
+	BackendFilesystem: {DirectoryAttr},
+	BackendS3:         {BucketAttr, RegionAttr, AccessKeyAttr, SecretKeyAttr},
+	BackendAzure:      {AccountNameAttr, AccountKeyAttr, ContainerAttr},
+}
+
+// BackendConfig describes the backend holding the content of the
+// controller's blobs.
+type BackendConfig struct {
+	// Type is the type of the backend.
+	Type BackendType `bson:"type"`
+
+	// Attrs holds the type-specific attributes of the backend.
+	Attrs map[string]string `bson:"attrs,omitempty"`
+}
+
+// DefaultBackendConfig is the configuration of the backend used when
+// no other has been configured.
+var DefaultBackendConfig = BackendConfig{Type: BackendGridFS}
+
+// ParseBackendConfig returns the BackendConfig described by the given
+// attributes, which must include the backend type.
+func ParseBackendConfig(attrs map[string]string) (BackendConfig, error) {
+	var config BackendConfig
+	for k, v := range attrs {
+		if k == BackendTypeAttr {
+			config.Type = BackendType(v)
+			continue
+		}
+		if config.Attrs == nil {
+			config.Attrs = make(map[string]string)
+		}
+		config.Attrs[k] = v
+	}
+	if err := config.Validate(); err != nil {
+		return BackendConfig{}, errors.Trace(err)
+	}
+	return config, nil
+}
+
+// Validate returns an error if the configuration is not valid.
+func (c BackendConfig) Validate() error {
+	required, ok := requiredBackendAttrs[c.Type]
+	if !ok {
+		return errors.NotValidf("blob backend type %q", c.Type)
+	}
+	for _, attr := range required {
+		if c.Attrs[attr] == "" {
+			return errors.NotValidf("%s blob backend without %q", c.Type, attr)
+		}
+	}
+	return nil
+}
+
+// String returns a description of the backend that identifies where
+// it stores blob content, without any credentials.
+func (c BackendConfig) String() string {
+	switch c.Type {
+	case BackendFilesystem:
+		return string(c.Type) + ":" + c.Attrs[DirectoryAttr]
+	case BackendS3:
+		return string(c.Type) + ":" + c.Attrs[BucketAttr]
+	case BackendAzure:
+		return string(c.Type) + ":" + c.Attrs[AccountNameAttr] + "/" + c.Attrs[ContainerAttr]
+	}
+	return string(c.Type)
+}
+
+func (c BackendConfig) equal(other BackendConfig) bool {
+	if c.Type != other.Type || len(c.Attrs) != len(other.Attrs) {
+		return false
+	}
+	for k, v := range c.Attrs {
+		if other.Attrs[k] != v {
+			return false
+		}
+	}
+	return true
+}
+
+// newResourceStorage returns a blobstore.ResourceStorage that stores
+// blob content in the configured backend.
+func (c BackendConfig) newResourceStorage(session *mgo.Session) (blobstore.ResourceStorage, error) {
+	switch c.Type {
+	case BackendGridFS:
+		return blobstore.NewGridFS(blobstoreDB, blobstoreDB, session), nil
+	case BackendFilesystem:
+		return directoryStorage{c.Attrs[DirectoryAttr]}, nil
+	case BackendS3:
+		return newS3Storage(c.Attrs)
+	case BackendAzure:
+		return newAzureStorage(c.Attrs)
+	}
+	return nil, errors.NotValidf("blob backend type %q", c.Type)
+}
+
+// backendDoc records the backend holding the content of the
+// controller's blobs. While blobs are being migrated between
+// backends, Fallback records the backend they are migrating from.
+//
+// Version is increased whenever the current backend changes. Writes
+// of blob content are fenced: each writer increments Writers, for
+// the version of the backend it writes to, for the duration of the
+// write. When the backend is switched, the count of writes to the
+// old backend still in progress moves to FallbackWriters, and the
+// migration waits for it to reach zero before copying content, so
+// that no content written to the old backend is left behind.
+type backendDoc struct {
+	Id       string         `bson:"_id"`
+	Version  int64          `bson:"version"`
+	Current  BackendConfig  `bson:"current"`
+	Fallback *BackendConfig `bson:"fallback,omitempty"`
+
+	Writers         int   `bson:"writers"`
+	FallbackVersion int64 `bson:"fallback-version,omitempty"`
+	FallbackWriters int   `bson:"fallback-writers"`
+}
+
+func readBackendDoc(db *mgo.Database) (backendDoc, error) {
+	var doc backendDoc
+	err := db.C(backendC).FindId(backendDocId).One(&doc)
+	if err == mgo.ErrNotFound {
+		return backendDoc{Id: backendDocId, Current: DefaultBackendConfig}, nil
+	} else if err != nil {
+		return backendDoc{}, errors.Annotate(err, "reading blob backend")
+	}
+	return doc, nil
+}
+
+// ensureBackendDoc returns the backend document, first recording the
+// default backend if none has been recorded, so that writes to it can
+// be fenced.
+func ensureBackendDoc(db *mgo.Database) (backendDoc, error) {
+	doc, err := readBackendDoc(db)
+	if err != nil || doc.Version != 0 {
+		return doc, errors.Trace(err)
+	}
+	doc.Version = 1
+	err = db.C(backendC).Insert(&doc)
+	if mgo.IsDup(err) {
+		return readBackendDoc(db)
+	} else if err != nil {
+		return backendDoc{}, errors.Annotate(err, "recording blob backend")
+	}
+	return doc, nil
+}
+
+// errBackendChanged is returned when the backend document changes
+// between reading and updating it.
+var errBackendChanged = errors.New("blob backend changed concurrently")
+
+// updateBackendDoc applies the given update to the backend document,
+// provided that it matches the given selector.
+func updateBackendDoc(db *mgo.Database, selector, update bson.D) error {
+	selector = append(bson.D{{"_id", backendDocId}}, selector...)
+	err := db.C(backendC).Update(selector, update)
+	if err == mgo.ErrNotFound {
+		return errBackendChanged
+	}
+	return errors.Annotate(err, "writing blob backend")
+}
+
+// maxWriteFenceAttempts is the number of times a writer attempts to
+// enter the write fence before giving up, if the backend keeps
+// changing underneath it.
+const maxWriteFenceAttempts = 5
+
+// enterWriteFence records a write to the current backend, and returns
+// the backend document read when doing so. The caller must write to
+// the backend the document describes, and call exitWriteFence with
+// the document when the write completes.
+func enterWriteFence(db *mgo.Database) (backendDoc, error) {
+	for attempt := 0; attempt < maxWriteFenceAttempts; attempt++ {
+		doc, err := ensureBackendDoc(db)
+		if err != nil {
+			return backendDoc{}, errors.Trace(err)
+		}
+		err = updateBackendDoc(db,
+			bson.D{{"version", doc.Version}},
+			bson.D{{"$inc", bson.D{{"writers", 1}}}},
+		)
+		if err == errBackendChanged {
+			continue
+		} else if err != nil {
+			return backendDoc{}, errors.Trace(err)
+		}
+		return doc, nil
+	}
+	return backendDoc{}, errBackendChanged
+}
+
+// exitWriteFence records the completion of a write started with
+// enterWriteFence.
+func exitWriteFence(db *mgo.Database, doc backendDoc) error {
+	err := updateBackendDoc(db,
+		bson.D{{"version", doc.Version}},
+		bson.D{{"$inc", bson.D{{"writers", -1}}}},
+	)
+	if err != errBackendChanged {
+		return errors.Trace(err)
+	}
+	// The backend was switched during the write.
+	err = updateBackendDoc(db,
+		bson.D{{"fallback-version", doc.Version}},
+		bson.D{{"$inc", bson.D{{"fallback-writers", -1}}}},
+	)
+	if err == errBackendChanged {
+		// The migration stopped waiting for the write.
+		logger.Warningf("blob write to %s backend completed after migration", doc.Current)
+		return nil
+	}
+	return errors.Trace(err)
+}
+
+// NewResourceStorage returns a blobstore.ResourceStorage that stores
+// blob content in the controller's current backend, using the given
+// session. While blobs are being migrated, content not yet in the
+// current backend is read from the backend they are migrating from.
+func NewResourceStorage(session *mgo.Session) (blobstore.ResourceStorage, error) {
+	doc, err := readBackendDoc(session.DB(metadataDB))
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	current, err := doc.Current.newResourceStorage(session)
+	if err != nil {
+		return nil, errors.Annotatef(err, "opening %s blob backend", doc.Current)
+	}
+	if doc.Fallback != nil {
+		fallback, err := doc.Fallback.newResourceStorage(session)
+		if err != nil {
+			return nil, errors.Annotatef(err, "opening %s blob backend", *doc.Fallback)
+		}
+		current = fallbackStorage{current, fallback}
+	}
+	return fencedStorage{current, session}, nil
+}
+
+// fencedStorage is a blobstore.ResourceStorage that reads and removes
+// content using the backends current when it was created, and writes
+// content to the current backend within the write fence.
+type fencedStorage struct {
+	blobstore.ResourceStorage
+	session *mgo.Session
+}
+
+// Put is part of the blobstore.ResourceStorage interface.
+func (s fencedStorage) Put(path string, r io.Reader, length int64) (_ string, err error) {
+	db := s.session.DB(metadataDB)
+	doc, err := enterWriteFence(db)
+	if err != nil {
+		return "", errors.Annotate(err, "entering blob write fence")
+	}
+	defer func() {
+		if exitErr := exitWriteFence(db, doc); exitErr != nil && err == nil {
+			err = errors.Annotate(exitErr, "exiting blob write fence")
+		}
+	}()
+	current, err := doc.Current.newResourceStorage(s.session)
+	if err != nil {
+		return "", errors.Annotatef(err, "opening %s blob backend", doc.Current)
+	}
+	return current.Put(path, r, length)
+}
+
+// InitBackend records the backend that will hold the content of the
+// controller's blobs. It must be called before any blobs are stored;
+// use MigrateBackend to change the backend afterwards.
+func InitBackend(session *mgo.Session, config BackendConfig) error {
+	if err := config.Validate(); err != nil {
+		return errors.Trace(err)
+	}
+	session = session.Copy()
+	defer session.Close()
+	err := session.DB(metadataDB).C(backendC).Insert(&backendDoc{
+		Id:      backendDocId,
+		Version: 1,
+		Current: config,
+	})
+	if mgo.IsDup(err) {
+		return errors.AlreadyExistsf("blob backend")
+	}
+	return errors.Annotate(err, "recording blob backend")
+}
+
+// CurrentBackend returns the configuration of the backend holding the
+// content of the controller's blobs, and the configuration of the
+// backend they are being migrated from, if any.
+func CurrentBackend(session *mgo.Session) (BackendConfig, *BackendConfig, error) {
+	session = session.Copy()
+	defer session.Close()
+	doc, err := readBackendDoc(session.DB(metadataDB))
+	if err != nil {
+		return BackendConfig{}, nil, errors.Trace(err)
+	}
+	return doc.Current, doc.Fallback, nil
+}
+
+// fallbackStorage is a blobstore.ResourceStorage that stores content in
+// one backend, and reads content it does not have from another.
+type fallbackStorage struct {
+	primary  blobstore.ResourceStorage
+	fallback blobstore.ResourceStorage
+}
+
+// Get is part of the blobstore.ResourceStorage interface.
+func (s fallbackStorage) Get(path string) (io.ReadCloser, error) {
+	r, err := s.primary.Get(path)
+	if err == nil {
+		return r, nil
+	}
+	r, fallbackErr := s.fallback.Get(path)
+	if fallbackErr != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// Put is part of the blobstore.ResourceStorage interface.
+func (s fallbackStorage) Put(path string, r io.Reader, length int64) (string, error) {
+	return s.primary.Put(path, r, length)
+}
+
+// Remove is part of the blobstore.ResourceStorage interface.
+func (s fallbackStorage) Remove(path string) error {
+	err := s.primary.Remove(path)
+	if fallbackErr := s.fallback.Remove(path); fallbackErr == nil {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,334 @@
+// Copyright 2016 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package storage_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	gitjujutesting "github.com/juju/testing"
+	jc "github.com/juju/testing/checkers"
+	"github.com/juju/utils/clock"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/state/storage"
+	"github.com/juju/juju/testing"
+)
+
+type BackendSuite struct {
+	gitjujutesting.MgoSuite
+	testing.BaseSuite
+}
+
+var _ = gc.Suite(&BackendSuite{})
+
+func (s *BackendSuite) SetUpSuite(c *gc.C) {
+	s.BaseSuite.SetUpSuite(c)
+	s.MgoSuite.SetUpSuite(c)
+}
+
+func (s *BackendSuite) TearDownSuite(c *gc.C) {
+	s.MgoSuite.TearDownSuite(c)
+	s.BaseSuite.TearDownSuite(c)
+}
+
+func (s *BackendSuite) SetUpTest(c *gc.C) {
+	s.BaseSuite.SetUpTest(c)
+	s.MgoSuite.SetUpTest(c)
+}
+
+func (s *BackendSuite) TearDownTest(c *gc.C) {
+	s.MgoSuite.TearDownTest(c)
+	s.BaseSuite.TearDownTest(c)
+}
+
+func (s *BackendSuite) TestParseBackendConfig(c *gc.C) {
+	config, err := storage.ParseBackendConfig(map[string]string{
+		"type":      "filesystem",
+		"directory": "/var/lib/juju/blobs",
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(config, jc.DeepEquals, storage.BackendConfig{
+		Type:  storage.BackendFilesystem,
+		Attrs: map[string]string{"directory": "/var/lib/juju/blobs"},
+	})
+	c.Assert(config.String(), gc.Equals, "filesystem:/var/lib/juju/blobs")
+}
+
+func (s *BackendSuite) TestParseBackendConfigInvalid(c *gc.C) {
+	for _, test := range []struct {
+		attrs map[string]string
+		err   string
+	}{{
+		attrs: map[string]string{},
+		err:   `blob backend type "" not valid`,
+	}, {
+		attrs: map[string]string{"type": "floppy"},
+		err:   `blob backend type "floppy" not valid`,
+	}, {
+		attrs: map[string]string{"type": "filesystem"},
+		err:   `filesystem blob backend without "directory" not valid`,
+	}, {
+		attrs: map[string]string{"type": "s3", "bucket": "juju", "region": "us-east-1"},
+		err:   `s3 blob backend without "access-key" not valid`,
+	}} {
+		_, err := storage.ParseBackendConfig(test.attrs)
+		c.Check(err, gc.ErrorMatches, test.err)
+	}
+}
+
+func (s *BackendSuite) TestCurrentBackendDefault(c *gc.C) {
+	current, fallback, err := storage.CurrentBackend(s.Session)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(current, jc.DeepEquals, storage.DefaultBackendConfig)
+	c.Assert(fallback, gc.IsNil)
+}
+
+func (s *BackendSuite) TestInitBackend(c *gc.C) {
+	dir := c.MkDir()
+	config := filesystemBackend(dir)
+	err := storage.InitBackend(s.Session, config)
+	c.Assert(err, jc.ErrorIsNil)
+
+	current, fallback, err := storage.CurrentBackend(s.Session)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(current, jc.DeepEquals, config)
+	c.Assert(fallback, gc.IsNil)
+
+	stor := storage.NewStorage(testUUID, s.Session)
+	err = stor.Put("path", strings.NewReader("abc"), 3)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(countFiles(c, dir), gc.Equals, 1)
+	checkContent(c, stor, "path", "abc")
+
+	err = stor.Remove("path")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(countFiles(c, dir), gc.Equals, 0)
+}
+
+func (s *BackendSuite) TestInitBackendAlreadyExists(c *gc.C) {
+	err := storage.InitBackend(s.Session, storage.DefaultBackendConfig)
+	c.Assert(err, jc.ErrorIsNil)
+	err = storage.InitBackend(s.Session, filesystemBackend(c.MkDir()))
+	c.Assert(err, gc.ErrorMatches, "blob backend already exists")
+}
+
+func (s *BackendSuite) TestMigrateBackend(c *gc.C) {
+	stor := storage.NewStorage(testUUID, s.Session)
+	err := stor.Put("abc", strings.NewReader("abc"), 3)
+	c.Assert(err, jc.ErrorIsNil)
+	err = stor.Put("def", strings.NewReader("defg"), 4)
+	c.Assert(err, jc.ErrorIsNil)
+
+	dir := c.MkDir()
+	config := filesystemBackend(dir)
+	err = storage.StartBackendMigration(s.Session, config)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(countFiles(c, dir), gc.Equals, 0)
+
+	// New content goes to the new backend, and content not
+	// yet moved is read from the old one.
+	current, fallback, err := storage.CurrentBackend(s.Session)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(current, jc.DeepEquals, config)
+	c.Assert(fallback, jc.DeepEquals, &storage.DefaultBackendConfig)
+	err = stor.Put("ghi", strings.NewReader("ghij"), 4)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(countFiles(c, dir), gc.Equals, 1)
+	checkContent(c, stor, "abc", "abc")
+
+	moved, err := storage.ResumeBackendMigration(s.Session, clock.WallClock, nil)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(moved, gc.Equals, 2)
+	c.Assert(countFiles(c, dir), gc.Equals, 3)
+
+	current, fallback, err = storage.CurrentBackend(s.Session)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(current, jc.DeepEquals, config)
+	c.Assert(fallback, gc.IsNil)
+	checkContent(c, stor, "abc", "abc")
+	checkContent(c, stor, "def", "defg")
+	checkContent(c, stor, "ghi", "ghij")
+
+	// Migrating back moves the content out of the directory.
+	err = storage.StartBackendMigration(s.Session, storage.DefaultBackendConfig)
+	c.Assert(err, jc.ErrorIsNil)
+	moved, err = storage.ResumeBackendMigration(s.Session, clock.WallClock, nil)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(moved, gc.Equals, 3)
+	c.Assert(countFiles(c, dir), gc.Equals, 0)
+	checkContent(c, stor, "abc", "abc")
+	checkContent(c, stor, "def", "defg")
+	checkContent(c, stor, "ghi", "ghij")
+}
+
+func (s *BackendSuite) TestStartBackendMigrationCurrent(c *gc.C) {
+	err := storage.StartBackendMigration(s.Session, storage.DefaultBackendConfig)
+	c.Assert(err, jc.ErrorIsNil)
+
+	_, fallback, err := storage.CurrentBackend(s.Session)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(fallback, gc.IsNil)
+}
+
+func (s *BackendSuite) TestStartBackendMigrationInProgress(c *gc.C) {
+	config := filesystemBackend(c.MkDir())
+	err := storage.StartBackendMigration(s.Session, config)
+	c.Assert(err, jc.ErrorIsNil)
+
+	// Starting the same migration again is a no-op.
+	err = storage.StartBackendMigration(s.Session, config)
+	c.Assert(err, jc.ErrorIsNil)
+
+	err = storage.StartBackendMigration(s.Session, filesystemBackend(c.MkDir()))
+	c.Assert(err, gc.ErrorMatches, "migration from gridfs to filesystem:.* blob backend in progress")
+}
+
+func (s *BackendSuite) TestStartBackendMigrationInvalid(c *gc.C) {
+	err := storage.StartBackendMigration(s.Session, storage.BackendConfig{Type: "floppy"})
+	c.Assert(err, gc.ErrorMatches, `blob backend type "floppy" not valid`)
+}
+
+func (s *BackendSuite) TestResumeBackendMigrationNoMigration(c *gc.C) {
+	moved, err := storage.ResumeBackendMigration(s.Session, clock.WallClock, nil)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(moved, gc.Equals, 0)
+}
+
+func (s *BackendSuite) TestResumeBackendMigrationCompleted(c *gc.C) {
+	stor := storage.NewStorage(testUUID, s.Session)
+	err := stor.Put("abc", strings.NewReader("abc"), 3)
+	c.Assert(err, jc.ErrorIsNil)
+	err = storage.StartBackendMigration(s.Session, filesystemBackend(c.MkDir()))
+	c.Assert(err, jc.ErrorIsNil)
+
+	moved, err := storage.ResumeBackendMigration(s.Session, clock.WallClock, nil)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(moved, gc.Equals, 1)
+	moved, err = storage.ResumeBackendMigration(s.Session, clock.WallClock, nil)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(moved, gc.Equals, 0)
+	checkContent(c, stor, "abc", "abc")
+}
+
+func (s *BackendSuite) TestResumeBackendMigrationWaitsForWriters(c *gc.C) {
+	exit, err := storage.EnterWriteFence(s.Session)
+	c.Assert(err, jc.ErrorIsNil)
+	dir := c.MkDir()
+	err = storage.StartBackendMigration(s.Session, filesystemBackend(dir))
+	c.Assert(err, jc.ErrorIsNil)
+
+	clock := gitjujutesting.NewClock(testing.ZeroTime())
+	done := s.resumeBackendMigration(clock, nil)
+
+	// The migration waits while the write to the old backend
+	// is in progress...
+	err = clock.WaitAdvance(storage.WriteFencePollInterval, testing.LongWait, 2)
+	c.Assert(err, jc.ErrorIsNil)
+	select {
+	case err := <-done:
+		c.Fatalf("migration completed during write: %v", err)
+	case <-time.After(testing.ShortWait):
+	}
+
+	// ...and completes once it has finished.
+	err = exit()
+	c.Assert(err, jc.ErrorIsNil)
+	err = clock.WaitAdvance(storage.WriteFencePollInterval, testing.LongWait, 2)
+	c.Assert(err, jc.ErrorIsNil)
+	s.waitMigrated(c, done)
+}
+
+func (s *BackendSuite) TestResumeBackendMigrationWriteFenceTimeout(c *gc.C) {
+	exit, err := storage.EnterWriteFence(s.Session)
+	c.Assert(err, jc.ErrorIsNil)
+	err = storage.StartBackendMigration(s.Session, filesystemBackend(c.MkDir()))
+	c.Assert(err, jc.ErrorIsNil)
+
+	clock := gitjujutesting.NewClock(testing.ZeroTime())
+	done := s.resumeBackendMigration(clock, nil)
+	err = clock.WaitAdvance(storage.WriteFenceTimeout, testing.LongWait, 2)
+	c.Assert(err, jc.ErrorIsNil)
+	s.waitMigrated(c, done)
+
+	// A writer that completes after the migration
+	// gave up waiting is not an error.
+	err = exit()
+	c.Assert(err, jc.ErrorIsNil)
+}
+
+func (s *BackendSuite) TestResumeBackendMigrationAbort(c *gc.C) {
+	_, err := storage.EnterWriteFence(s.Session)
+	c.Assert(err, jc.ErrorIsNil)
+	config := filesystemBackend(c.MkDir())
+	err = storage.StartBackendMigration(s.Session, config)
+	c.Assert(err, jc.ErrorIsNil)
+
+	abort := make(chan struct{})
+	close(abort)
+	clock := gitjujutesting.NewClock(testing.ZeroTime())
+	_, err = storage.ResumeBackendMigration(s.Session, clock, abort)
+	c.Assert(err, gc.Equals, storage.ErrBackendMigrationAborted)
+
+	// The migration is still in progress.
+	_, fallback, err := storage.CurrentBackend(s.Session)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(fallback, jc.DeepEquals, &storage.DefaultBackendConfig)
+}
+
+func (s *BackendSuite) resumeBackendMigration(clock clock.Clock, abort <-chan struct{}) <-chan error {
+	done := make(chan error, 1)
+	go func() {
+		_, err := storage.ResumeBackendMigration(s.Session, clock, abort)
+		done <- err
+	}()
+	return done
+}
+
+func (s *BackendSuite) waitMigrated(c *gc.C, done <-chan error) {
+	select {
+	case err := <-done:
+		c.Assert(err, jc.ErrorIsNil)
+	case <-time.After(testing.LongWait):
+		c.Fatalf("timed out waiting for migration")
+	}
+	_, fallback, err := storage.CurrentBackend(s.Session)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(fallback, gc.IsNil)
+}
+
+func filesystemBackend(dir string) storage.BackendConfig {
+	return storage.BackendConfig{
+		Type:  storage.BackendFilesystem,
+		Attrs: map[string]string{"directory": dir},
+	}
+}
+
+func checkContent(c *gc.C, stor storage.Storage, path, content string) {
+	r, length, err := stor.Get(path)
+	c.Assert(err, jc.ErrorIsNil)
+	defer r.Close()
+	c.Check(length, gc.Equals, int64(len(content)))
+	data, err := ioutil.ReadAll(r)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(string(data), gc.Equals, content)
+}
+
+func countFiles(c *gc.C, dir string) int {
+	var n int
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.Mode().IsRegular() {
+			n++
+		}
+		return nil
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	return n
+}
@@ -0,0 +1,246 @@
+// Copyright 2016 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package storage
+
+import (
+	"time"
+
+	"github.com/juju/errors"
+	"github.com/juju/utils/clock"
+	"gopkg.in/juju/blobstore.v2"
+	"gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
+)
+
+const (
+	// writeFencePollInterval is the interval at which a migration
+	// checks whether writes to the old backend have completed.
+	writeFencePollInterval = 5 * time.Second
+
+	// writeFenceTimeout is the maximum amount of time a migration
+	// waits for writes to the old backend to complete. A writer
+	// that has not finished by then is assumed to have died without
+	// exiting the write fence; content it did not finish writing is
+	// never marked complete in the catalog, so is not lost.
+	writeFenceTimeout = 10 * time.Minute
+)
+
+// ErrBackendMigrationAborted is returned by ResumeBackendMigration
+// if it is aborted before the migration completes.
+var ErrBackendMigrationAborted = errors.New("blob backend migration aborted")
+
+// catalogPathDoc is the subset of the blobstore's persistent
+// representation of a catalog entry that we need to migrate its
+// content between backends.
+type catalogPathDoc struct {
+	Path   string `bson:"path"`
+	Length int64  `bson:"length"`
+}
+
+// StartBackendMigration makes the backend with the given configuration
+// current, so that new blob content is stored there, and records the
+// old backend as the one from which existing content is to be moved.
+// Content not yet moved is read from the old backend until the
+// migration completes. The content is moved by ResumeBackendMigration.
+//
+// Starting a migration to the backend already being migrated to is
+// a no-op; starting one to any other backend fails until the current
+// migration completes.
+func StartBackendMigration(session *mgo.Session, target BackendConfig) error {
+	if err := target.Validate(); err != nil {
+		return errors.Trace(err)
+	}
+	session = session.Copy()
+	defer session.Close()
+	db := session.DB(metadataDB)
+
+	for attempt := 0; attempt < maxWriteFenceAttempts; attempt++ {
+		doc, err := ensureBackendDoc(db)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		if doc.Current.equal(target) {
+			return nil
+		}
+		if doc.Fallback != nil {
+			return errors.Errorf(
+				"migration from %s to %s blob backend in progress",
+				*doc.Fallback, doc.Current,
+			)
+		}
+		// Switch the backend, moving the count of writes in
+		// progress to the old backend, so that the migration
+		// can wait for them.
+		err = updateBackendDoc(db, bson.D{
+			{"version", doc.Version},
+			{"writers", doc.Writers},
+			{"fallback", bson.D{{"$exists", false}}},
+		}, bson.D{
+			{"$set", bson.D{
+				{"current", target},
+				{"fallback", doc.Current},
+				{"fallback-version", doc.Version},
+				{"fallback-writers", doc.Writers},
+				{"writers", 0},
+			}},
+			{"$inc", bson.D{{"version", 1}}},
+		})
+		if err == errBackendChanged {
+			continue
+		} else if err != nil {
+			return errors.Trace(err)
+		}
+		logger.Infof("started migrating blobs from %s to %s backend", doc.Current, target)
+		return nil
+	}
+	return errBackendChanged
+}
+
+// ResumeBackendMigration moves the content of the controller's blobs
+// to the current backend from the backend being migrated from, if any,
+// and returns the number of blobs moved. Once writes to the old backend
+// that were in progress when the migration started have completed, the
+// content of each blob is copied; the old backend is then no longer
+// read, and its content is removed.
+//
+// If the migration is interrupted, calling ResumeBackendMigration again
+// resumes it. If abort is closed before the migration completes,
+// ErrBackendMigrationAborted is returned.
+func ResumeBackendMigration(session *mgo.Session, clock clock.Clock, abort <-chan struct{}) (int, error) {
+	session = session.Copy()
+	defer session.Close()
+	db := session.DB(metadataDB)
+
+	doc, err := readBackendDoc(db)
+	if err != nil {
+		return 0, errors.Trace(err)
+	}
+	if doc.Fallback == nil {
+		return 0, nil
+	}
+	source, target := *doc.Fallback, doc.Current
+	if err := waitForWriteFence(db, clock, abort); err != nil {
+		return 0, errors.Trace(err)
+	}
+	logger.Infof("migrating blobs from %s to %s backend", source, target)
+
+	src, err := source.newResourceStorage(session)
+	if err != nil {
+		return 0, errors.Annotatef(err, "opening %s blob backend", source)
+	}
+	dst, err := target.newResourceStorage(session)
+	if err != nil {
+		return 0, errors.Annotatef(err, "opening %s blob backend", target)
+	}
+	copied, err := copyBlobs(db, src, dst, abort)
+	if err != nil {
+		return len(copied), errors.Trace(err)
+	}
+
+	// The version is left alone, as the current backend does
+	// not change; writers fenced on it are still counted.
+	err = updateBackendDoc(db, bson.D{
+		{"version", doc.Version},
+	}, bson.D{
+		{"$unset", bson.D{{"fallback", nil}, {"fallback-version", nil}}},
+		{"$set", bson.D{{"fallback-writers", 0}}},
+	})
+	if err != nil {
+		return len(copied), errors.Trace(err)
+	}
+	if err := removeMigratedBlobs(db, src, dst); err != nil {
+		// The migration is complete; the old backend
+		// is no longer read, so leftover content is
+		// only wasted space.
+		logger.Warningf("cannot remove blobs from %s backend: %v", source, err)
+	}
+	logger.Infof("migrated %d blobs from %s to %s backend", len(copied), source, target)
+	return len(copied), nil
+}
+
+// waitForWriteFence waits until there are no writes in progress to
+// the backend being migrated from, or until writeFenceTimeout elapses.
+func waitForWriteFence(db *mgo.Database, clock clock.Clock, abort <-chan struct{}) error {
+	timeout := clock.After(writeFenceTimeout)
+	for {
+		doc, err := readBackendDoc(db)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		if doc.FallbackWriters <= 0 {
+			return nil
+		}
+		logger.Debugf("waiting for %d blob writes to %s backend", doc.FallbackWriters, doc.Fallback)
+		select {
+		case <-abort:
+			return ErrBackendMigrationAborted
+		case <-timeout:
+			logger.Warningf(
+				"timed out waiting for %d blob writes to %s backend",
+				doc.FallbackWriters, doc.Fallback,
+			)
+			return nil
+		case <-clock.After(writeFencePollInterval):
+		}
+	}
+}
+
+// copyBlobs copies the content of each blob in the catalog from src to
+// dst, unless dst already holds it, and returns the paths of the blobs
+// copied. Entries whose upload is not marked complete are copied too:
+// the upload may have written to src before the migration started,
+// and be marked complete after it.
+func copyBlobs(db *mgo.Database, src, dst blobstore.ResourceStorage, abort <-chan struct{}) ([]string, error) {
+	var copied []string
+	var doc catalogPathDoc
+	iter := db.C(resourceCatalogC).Find(nil).Iter()
+	for iter.Next(&doc) {
+		select {
+		case <-abort:
+			iter.Close()
+			return copied, ErrBackendMigrationAborted
+		default:
+		}
+		if r, err := dst.Get(doc.Path); err == nil {
+			r.Close()
+			continue
+		}
+		r, err := src.Get(doc.Path)
+		if err != nil {
+			// The blob may have been removed since we
+			// listed it, or its upload never completed.
+			logger.Debugf("cannot read blob %q: %v", doc.Path, err)
+			continue
+		}
+		_, err = dst.Put(doc.Path, r, doc.Length)
+		r.Close()
+		if err != nil {
+			iter.Close()
+			return copied, errors.Annotatef(err, "copying blob %q", doc.Path)
+		}
+		copied = append(copied, doc.Path)
+	}
+	if err := iter.Close(); err != nil {
+		return copied, errors.Annotate(err, "iterating blob catalog")
+	}
+	return copied, nil
+}
+
+// removeMigratedBlobs removes from src the content of each blob in the
+// catalog that dst holds.
+func removeMigratedBlobs(db *mgo.Database, src, dst blobstore.ResourceStorage) error {
+	var doc catalogPathDoc
+	iter := db.C(resourceCatalogC).Find(nil).Iter()
+	for iter.Next(&doc) {
+		r, err := dst.Get(doc.Path)
+		if err != nil {
+			continue
+		}
+		r.Close()
+		if err := src.Remove(doc.Path); err != nil && !errors.IsNotFound(err) {
+			logger.Debugf("cannot remove blob %q: %v", doc.Path, err)
+		}
+	}
+	return errors.Trace(iter.Close())
+}
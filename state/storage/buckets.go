@@ -0,0 +1,251 @@
+// Copyright 2016 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package storage
+
+import (
+	"path"
+	"strings"
+	"time"
+
+	"github.com/juju/errors"
+	"gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
+)
+
+// Bucket identifies the purpose of the blobs in a namespace of a
+// model's storage. The path of each blob in a bucket begins with
+// the bucket's name.
+type Bucket string
+
+const (
+	// BucketTools holds agent binaries and GUI archives.
+	BucketTools Bucket = "tools"
+
+	// BucketCharms holds charm archives.
+	BucketCharms Bucket = "charms"
+
+	// BucketResources holds the content of application resources.
+	BucketResources Bucket = "resources"
+
+	// BucketBackups holds controller backup archives. Backups are
+	// kept apart from the blobs of the controller's models, in the
+	// backups database, and so are never listed by ListBlobs.
+	BucketBackups Bucket = "backups"
+)
+
+// Buckets holds all of the buckets, in order of name.
+var Buckets = []Bucket{
+	BucketBackups,
+	BucketCharms,
+	BucketResources,
+	BucketTools,
+}
+
+// Path returns the storage path of the blob with the given path,
+// relative to the bucket.
+func (b Bucket) Path(p string) string {
+	return path.Join(string(b), p)
+}
+
+// BucketOf returns the bucket holding the blob with the given storage
+// path, and false if the blob is not held in a bucket. Resources were
+// stored beneath the paths of their applications before they were
+// held in a bucket, so such paths are considered to be held in the
+// resources bucket.
+func BucketOf(p string) (Bucket, bool) {
+	parts := strings.Split(p, "/")
+	if len(parts) < 2 {
+		return "", false
+	}
+	for _, bucket := range Buckets {
+		if parts[0] == string(bucket) {
+			return bucket, true
+		}
+	}
+	if len(parts) > 2 && strings.HasPrefix(parts[0], "application-") && parts[1] == "resources" {
+		return BucketResources, true
+	}
+	return "", false
+}
+
+// Blob describes a blob held in a model's storage.
+type Blob struct {
+	// Path is the storage path of the blob.
+	Path string
+
+	// Bucket is the bucket holding the blob, or the empty
+	// string if the blob is not held in a bucket.
+	Bucket Bucket
+
+	// Length is the length of the blob's content, in bytes.
+	Length int64
+}
+
+// ListBlobs returns the blobs stored for the model with the specified
+// UUID, ordered by path. Content shared between blobs is counted in
+// the length of each.
+func ListBlobs(modelUUID string, session *mgo.Session) ([]Blob, error) {
+	session = session.Copy()
+	defer session.Close()
+	db := session.DB(metadataDB)
+	var docs []managedResourceDoc
+	if err := db.C(managedResourcesC).Find(
+		bson.D{{"bucketuuid", modelUUID}},
+	).Sort("path").All(&docs); err != nil {
+		return nil, errors.Annotate(err, "listing managed resources")
+	}
+	blobs := make([]Blob, 0, len(docs))
+	catalog := db.C(resourceCatalogC)
+	for _, doc := range docs {
+		if isPendingResourcePath(doc.Path) {
+			// The blob is being stored or removed.
+			continue
+		}
+		var catalogDoc resourceCatalogDoc
+		if err := catalog.FindId(doc.ResourceId).One(&catalogDoc); err == mgo.ErrNotFound {
+			// The blob was removed since we listed it.
+			continue
+		} else if err != nil {
+			return nil, errors.Annotatef(err, "reading catalog entry for %q", doc.Path)
+		}
+		bucket, _ := BucketOf(doc.Path)
+		blobs = append(blobs, Blob{
+			Path:   doc.Path,
+			Bucket: bucket,
+			Length: catalogDoc.Length,
+		})
+	}
+	return blobs, nil
+}
+
+// BucketUsage describes the blobs held in a bucket.
+type BucketUsage struct {
+	// Blobs is the number of blobs held in the bucket.
+	Blobs int
+
+	// Length is the total length of the content of the blobs
+	// held in the bucket, in bytes.
+	Length int64
+}
+
+// Usage returns the usage of each bucket holding any of the given
+// blobs. Blobs not held in a bucket are ignored.
+func Usage(blobs []Blob) map[Bucket]BucketUsage {
+	usage := make(map[Bucket]BucketUsage)
+	for _, blob := range blobs {
+		if blob.Bucket == "" {
+			continue
+		}
+		u := usage[blob.Bucket]
+		u.Blobs++
+		u.Length += blob.Length
+		usage[blob.Bucket] = u
+	}
+	return usage
+}
+
+// BucketPolicy describes the lifecycle of the blobs in a bucket. Only
+// blobs that are no longer in use are removed under a policy; a zero
+// field places no limit.
+type BucketPolicy struct {
+	// MaxAge is the age beyond which blobs are removed. The most
+	// recent backup is kept regardless of its age.
+	MaxAge time.Duration
+
+	// MaxVersions is the number of most recent versions of each
+	// blob that are kept; older versions are removed. What
+	// constitutes a version depends on the bucket: agent binaries
+	// are versioned by major.minor version, charms by name, and
+	// backups are all versions of the one blob.
+	MaxVersions int
+}
+
+// bucketPolicySupport records which policy limits can be enforced for
+// each bucket. The blobstore does not record when blobs were stored, so
+// only backups, which record when they were taken, can be aged out.
+// Resources have neither ages nor versions to limit.
+var bucketPolicySupport = map[Bucket]struct {
+	maxAge, maxVersions bool
+}{
+	BucketBackups:   {maxAge: true, maxVersions: true},
+	BucketCharms:    {maxVersions: true},
+	BucketResources: {},
+	BucketTools:     {maxVersions: true},
+}
+
+// Validate returns an error if the policy cannot be enforced for the
+// given bucket.
+func (p BucketPolicy) Validate(bucket Bucket) error {
+	support, ok := bucketPolicySupport[bucket]
+	if !ok {
+		return errors.NotValidf("bucket %q", bucket)
+	}
+	if p.MaxAge < 0 {
+		return errors.NotValidf("negative max age")
+	}
+	if p.MaxVersions < 0 {
+		return errors.NotValidf("negative max versions")
+	}
+	if p.MaxAge > 0 && !support.maxAge {
+		return errors.NotSupportedf("max age for %s bucket", bucket)
+	}
+	if p.MaxVersions > 0 && !support.maxVersions {
+		return errors.NotSupportedf("max versions for %s bucket", bucket)
+	}
+	return nil
+}
+
+// bucketPoliciesC is the name of the collection in the metadata
+// database that records the lifecycle policy of each bucket.
+const bucketPoliciesC = "blobBucketPolicies"
+
+type bucketPolicyDoc struct {
+	Bucket      string `bson:"_id"`
+	MaxAge      int64  `bson:"max-age"`
+	MaxVersions int    `bson:"max-versions"`
+}
+
+// SetBucketPolicy sets the lifecycle policy of the given bucket,
+// which applies to the blobs of all of the controller's models.
+// Setting a zero policy removes any limits.
+func SetBucketPolicy(session *mgo.Session, bucket Bucket, policy BucketPolicy) error {
+	if err := policy.Validate(bucket); err != nil {
+		return errors.Trace(err)
+	}
+	session = session.Copy()
+	defer session.Close()
+	coll := session.DB(metadataDB).C(bucketPoliciesC)
+	if policy == (BucketPolicy{}) {
+		err := coll.RemoveId(string(bucket))
+		if err == mgo.ErrNotFound {
+			return nil
+		}
+		return errors.Annotatef(err, "removing %s bucket policy", bucket)
+	}
+	_, err := coll.UpsertId(string(bucket), &bucketPolicyDoc{
+		Bucket:      string(bucket),
+		MaxAge:      int64(policy.MaxAge),
+		MaxVersions: policy.MaxVersions,
+	})
+	return errors.Annotatef(err, "setting %s bucket policy", bucket)
+}
+
+// BucketPolicies returns the lifecycle policies of the buckets that
+// have them.
+func BucketPolicies(session *mgo.Session) (map[Bucket]BucketPolicy, error) {
+	session = session.Copy()
+	defer session.Close()
+	var docs []bucketPolicyDoc
+	if err := session.DB(metadataDB).C(bucketPoliciesC).Find(nil).All(&docs); err != nil {
+		return nil, errors.Annotate(err, "reading bucket policies")
+	}
+	policies := make(map[Bucket]BucketPolicy)
+	for _, doc := range docs {
+		policies[Bucket(doc.Bucket)] = BucketPolicy{
+			MaxAge:      time.Duration(doc.MaxAge),
+			MaxVersions: doc.MaxVersions,
+		}
+	}
+	return policies, nil
+}
@@ -0,0 +1,108 @@
+// Copyright 2016 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package storage
+
+import (
+	"crypto/md5"
+	"encoding/hex"
+	"io"
+
+	azurestorage "github.com/Azure/azure-sdk-for-go/storage"
+	"github.com/juju/errors"
+	"gopkg.in/amz.v3/aws"
+	"gopkg.in/amz.v3/s3"
+	"gopkg.in/juju/blobstore.v2"
+)
+
+// s3Storage is a blobstore.ResourceStorage that stores content in an
+// Amazon S3 bucket.
+type s3Storage struct {
+	bucket *s3.Bucket
+}
+
+func newS3Storage(attrs map[string]string) (blobstore.ResourceStorage, error) {
+	region, ok := aws.Regions[attrs[RegionAttr]]
+	if !ok {
+		return nil, errors.NotValidf("S3 region %q", attrs[RegionAttr])
+	}
+	auth := aws.Auth{
+		AccessKey: attrs[AccessKeyAttr],
+		SecretKey: attrs[SecretKeyAttr],
+	}
+	bucket, err := s3.New(auth, region).Bucket(attrs[BucketAttr])
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	return s3Storage{bucket}, nil
+}
+
+// Get is part of the blobstore.ResourceStorage interface.
+func (s s3Storage) Get(path string) (io.ReadCloser, error) {
+	r, err := s.bucket.GetReader(path)
+	if err != nil {
+		return nil, errors.Annotatef(err, "reading blob %q", path)
+	}
+	return r, nil
+}
+
+// Put is part of the blobstore.ResourceStorage interface.
+func (s s3Storage) Put(path string, r io.Reader, length int64) (string, error) {
+	hash := md5.New()
+	r = io.TeeReader(r, hash)
+	if err := s.bucket.PutReader(path, r, length, "application/octet-stream", s3.Private); err != nil {
+		return "", errors.Annotatef(err, "writing blob %q", path)
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// Remove is part of the blobstore.ResourceStorage interface.
+func (s s3Storage) Remove(path string) error {
+	return errors.Annotatef(s.bucket.Del(path), "removing blob %q", path)
+}
+
+// azureStorage is a blobstore.ResourceStorage that stores content in
+// an Azure Blob Storage container.
+type azureStorage struct {
+	blobs     azurestorage.BlobStorageClient
+	container string
+}
+
+func newAzureStorage(attrs map[string]string) (blobstore.ResourceStorage, error) {
+	client, err := azurestorage.NewBasicClient(attrs[AccountNameAttr], attrs[AccountKeyAttr])
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	return azureStorage{client.GetBlobService(), attrs[ContainerAttr]}, nil
+}
+
+// Get is part of the blobstore.ResourceStorage interface.
+func (s azureStorage) Get(path string) (io.ReadCloser, error) {
+	r, err := s.blobs.GetBlob(s.container, path)
+	if err != nil {
+		return nil, errors.Annotatef(err, "reading blob %q", path)
+	}
+	return r, nil
+}
+
+// Put is part of the blobstore.ResourceStorage interface.
+func (s azureStorage) Put(path string, r io.Reader, length int64) (string, error) {
+	hash := md5.New()
+	r = io.TeeReader(r, hash)
+	if err := s.blobs.CreateBlockBlobFromReader(s.container, path, uint64(length), r, nil); err != nil {
+		return "", errors.Annotatef(err, "writing blob %q", path)
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// Remove is part of the blobstore.ResourceStorage interface.
+func (s azureStorage) Remove(path string) error {
+	deleted, err := s.blobs.DeleteBlobIfExists(s.container, path, nil)
+	if err != nil {
+		return errors.Annotatef(err, "removing blob %q", path)
+	}
+	if !deleted {
+		return errors.NotFoundf("blob %q", path)
+	}
+	return nil
+}
@@ -0,0 +1,96 @@
+// Copyright 2016 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package storage
+
+import (
+	"crypto/md5"
+	"encoding/hex"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/juju/errors"
+)
+
+// directoryStorage is a blobstore.ResourceStorage that stores content
+// in files beneath a directory.
+type directoryStorage struct {
+	dir string
+}
+
+// filePath returns the path of the file holding the content stored
+// at the given path, ensuring that it is beneath the directory.
+func (s directoryStorage) filePath(path string) (string, error) {
+	rel := filepath.Clean(filepath.FromSlash(path))
+	if filepath.IsAbs(rel) || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", errors.NotValidf("blob path %q", path)
+	}
+	return filepath.Join(s.dir, rel), nil
+}
+
+// Get is part of the blobstore.ResourceStorage interface.
+func (s directoryStorage) Get(path string) (io.ReadCloser, error) {
+	filePath, err := s.filePath(path)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	f, err := os.Open(filePath)
+	if os.IsNotExist(err) {
+		return nil, errors.NotFoundf("blob %q", path)
+	} else if err != nil {
+		return nil, errors.Trace(err)
+	}
+	return f, nil
+}
+
+// Put is part of the blobstore.ResourceStorage interface. The content
+// is written to a temporary file which is renamed into place once it
+// is complete, so partially written content is never read.
+func (s directoryStorage) Put(path string, r io.Reader, length int64) (string, error) {
+	filePath, err := s.filePath(path)
+	if err != nil {
+		return "", errors.Trace(err)
+	}
+	if err := os.MkdirAll(filepath.Dir(filePath), 0700); err != nil {
+		return "", errors.Trace(err)
+	}
+	f, err := ioutil.TempFile(filepath.Dir(filePath), ".blob-")
+	if err != nil {
+		return "", errors.Trace(err)
+	}
+	defer os.Remove(f.Name())
+	hash := md5.New()
+	n, err := io.Copy(io.MultiWriter(f, hash), io.LimitReader(r, length))
+	if err == nil && n != length {
+		err = errors.Errorf("expected %d bytes, read %d", length, n)
+	}
+	if err == nil {
+		err = f.Sync()
+	}
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return "", errors.Annotatef(err, "writing blob %q", path)
+	}
+	if err := os.Rename(f.Name(), filePath); err != nil {
+		return "", errors.Trace(err)
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// Remove is part of the blobstore.ResourceStorage interface.
+func (s directoryStorage) Remove(path string) error {
+	filePath, err := s.filePath(path)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	err = os.Remove(filePath)
+	if os.IsNotExist(err) {
+		return errors.NotFoundf("blob %q", path)
+	}
+	return errors.Trace(err)
+}
@@ -0,0 +1,29 @@
+// Copyright 2016 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package storage
+
+import (
+	"gopkg.in/mgo.v2"
+)
+
+var NewPutId = &newPutId
+
+const (
+	WriteFencePollInterval = writeFencePollInterval
+	WriteFenceTimeout      = writeFenceTimeout
+)
+
+// EnterWriteFence records a blob write to the current backend, as
+// if one were in progress, and returns a function that records its
+// completion.
+func EnterWriteFence(session *mgo.Session) (func() error, error) {
+	db := session.DB(metadataDB)
+	doc, err := enterWriteFence(db)
+	if err != nil {
+		return nil, err
+	}
+	return func() error {
+		return exitWriteFence(db, doc)
+	}, nil
+}
@@ -0,0 +1,288 @@
+// Copyright 2016 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package storage
+
+import (
+	"fmt"
+	"io"
+	"path"
+	"strings"
+
+	"github.com/juju/errors"
+	jujutxn "github.com/juju/txn"
+	"github.com/juju/utils"
+	"gopkg.in/juju/blobstore.v2"
+	"gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
+	"gopkg.in/mgo.v2/txn"
+)
+
+// pendingDir is the directory, relative to a bucket, beneath which
+// content is uploaded before it is swapped into place.
+const pendingDir = ".pending"
+
+// newPutId returns a new unique ID for a put or remove of a managed
+// resource. It is a variable so that tests can choose the winner of
+// concurrent puts.
+var newPutId = func() string {
+	return utils.MustNewUUID().String()
+}
+
+// NewManagedStorage returns a blobstore.ManagedStorage that records
+// managed resources in db and stores their content in rs.
+//
+// The blobstore updates a path's managed resource by reading it and
+// then writing it, so concurrent writers of the same path, in this or
+// any other controller process, may each replace the resource the other
+// read, orphaning a catalog entry. The ManagedStorage returned by
+// NewManagedStorage instead uploads each put's content to a pending
+// path of its own, and then swaps the managed resources of the pending
+// path and the target path in a transaction asserting that neither has
+// changed since they were read, retrying if either has. Whatever the
+// pending path is left holding is then released.
+//
+// Of concurrent puts of the same path, the one that started last wins,
+// whatever order they finish in: each put claims the version after the
+// path's version when it starts, and a put whose claim is lower than
+// the path's current version gives way, releasing its own content.
+// Puts claiming the same version are ordered by their IDs.
+func NewManagedStorage(db *mgo.Database, rs blobstore.ResourceStorage) blobstore.ManagedStorage {
+	return versionedManagedStorage{
+		ManagedStorage: blobstore.NewManagedStorage(db, rs),
+		db:             db,
+	}
+}
+
+// versionedManagedStorage is a blobstore.ManagedStorage that versions
+// the managed resources of paths, so that concurrent updates of a path
+// do not orphan content.
+type versionedManagedStorage struct {
+	blobstore.ManagedStorage
+	db *mgo.Database
+}
+
+// versionedResourceDoc is the subset of the blobstore's persistent
+// representation of a managed resource that we need to update it,
+// along with the fields recording the put that last updated it.
+type versionedResourceDoc struct {
+	ResourceId string `bson:"resourceid"`
+	TxnRevno   int64  `bson:"txn-revno"`
+	PutVersion int64  `bson:"put-version"`
+	PutId      string `bson:"put-id"`
+}
+
+// putClaim identifies a put of a managed resource, and orders it
+// among other puts of the same path.
+type putClaim struct {
+	version int64
+	id      string
+}
+
+// supersedes reports whether the put with the claim should replace
+// the content stored at the path with the given managed resource.
+func (c putClaim) supersedes(doc versionedResourceDoc) bool {
+	if c.version != doc.PutVersion {
+		return c.version > doc.PutVersion
+	}
+	return c.id > doc.PutId
+}
+
+// PutForBucket is part of the blobstore.ManagedStorage interface.
+func (s versionedManagedStorage) PutForBucket(bucketUUID, path string, r io.Reader, length int64) error {
+	return s.put(bucketUUID, path, func(pendingPath string) error {
+		return s.ManagedStorage.PutForBucket(bucketUUID, pendingPath, r, length)
+	})
+}
+
+// PutForBucketAndCheckHash is part of the blobstore.ManagedStorage interface.
+func (s versionedManagedStorage) PutForBucketAndCheckHash(bucketUUID, path string, r io.Reader, length int64, checkHash string) error {
+	return s.put(bucketUUID, path, func(pendingPath string) error {
+		return s.ManagedStorage.PutForBucketAndCheckHash(bucketUUID, pendingPath, r, length, checkHash)
+	})
+}
+
+func (s versionedManagedStorage) put(bucketUUID, path string, upload func(pendingPath string) error) error {
+	managedPath := managedResourcePath(bucketUUID, path)
+	claim := putClaim{version: 1, id: newPutId()}
+	current, err := s.readManagedResource(managedPath)
+	if err == nil {
+		claim.version = current.PutVersion + 1
+	} else if !errors.IsNotFound(err) {
+		return errors.Trace(err)
+	}
+
+	pendingPath := pendingResourcePath(claim.id)
+	if err := upload(pendingPath); err != nil {
+		return errors.Trace(err)
+	}
+	managedPendingPath := managedResourcePath(bucketUUID, pendingPath)
+	buildTxn := func(attempt int) ([]txn.Op, error) {
+		pending, err := s.readManagedResource(managedPendingPath)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		current, err := s.readManagedResource(managedPath)
+		if errors.IsNotFound(err) {
+			// Nothing is stored at the path, so the pending
+			// path's managed resource is moved there.
+			doc, err := s.copyManagedResource(managedPendingPath, pendingPath, path)
+			if err != nil {
+				return nil, errors.Trace(err)
+			}
+			doc["put-version"] = claim.version
+			doc["put-id"] = claim.id
+			return []txn.Op{{
+				C:      managedResourcesC,
+				Id:     managedPath,
+				Assert: txn.DocMissing,
+				Insert: doc,
+			}, {
+				C:      managedResourcesC,
+				Id:     managedPendingPath,
+				Assert: bson.D{{"txn-revno", pending.TxnRevno}},
+				Remove: true,
+			}}, nil
+		} else if err != nil {
+			return nil, errors.Trace(err)
+		}
+		if !claim.supersedes(current) {
+			// A put that started later has already stored
+			// its content; ours is released below.
+			return nil, jujutxn.ErrNoOperations
+		}
+		return []txn.Op{{
+			C:      managedResourcesC,
+			Id:     managedPath,
+			Assert: bson.D{{"txn-revno", current.TxnRevno}},
+			Update: bson.D{{"$set", bson.D{
+				{"resourceid", pending.ResourceId},
+				{"put-version", claim.version},
+				{"put-id", claim.id},
+			}}},
+		}, {
+			C:      managedResourcesC,
+			Id:     managedPendingPath,
+			Assert: bson.D{{"txn-revno", pending.TxnRevno}},
+			Update: bson.D{{"$set", bson.D{
+				{"resourceid", current.ResourceId},
+			}}},
+		}}, nil
+	}
+	if err := s.runTransaction(buildTxn); err != nil {
+		return errors.Annotatef(err, "cannot store resource at path %q", path)
+	}
+	return errors.Trace(s.releasePending(bucketUUID, pendingPath))
+}
+
+// RemoveForBucket is part of the blobstore.ManagedStorage interface.
+func (s versionedManagedStorage) RemoveForBucket(bucketUUID, path string) error {
+	managedPath := managedResourcePath(bucketUUID, path)
+	pendingPath := pendingResourcePath(newPutId())
+	managedPendingPath := managedResourcePath(bucketUUID, pendingPath)
+	buildTxn := func(attempt int) ([]txn.Op, error) {
+		current, err := s.readManagedResource(managedPath)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		// The path's managed resource is moved to a pending
+		// path, from which it is released once the path has
+		// been removed.
+		doc, err := s.copyManagedResource(managedPath, path, pendingPath)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		return []txn.Op{{
+			C:      managedResourcesC,
+			Id:     managedPath,
+			Assert: bson.D{{"txn-revno", current.TxnRevno}},
+			Remove: true,
+		}, {
+			C:      managedResourcesC,
+			Id:     managedPendingPath,
+			Assert: txn.DocMissing,
+			Insert: doc,
+		}}, nil
+	}
+	if err := s.runTransaction(buildTxn); err != nil {
+		return errors.Trace(err)
+	}
+	return errors.Trace(s.releasePending(bucketUUID, pendingPath))
+}
+
+func (s versionedManagedStorage) runTransaction(buildTxn jujutxn.TransactionSource) error {
+	runner := jujutxn.NewRunner(jujutxn.RunnerParams{Database: s.db})
+	return runner.Run(buildTxn)
+}
+
+// releasePending removes the managed resource at the given pending
+// path, releasing its content if no other path refers to it.
+func (s versionedManagedStorage) releasePending(bucketUUID, pendingPath string) error {
+	err := s.ManagedStorage.RemoveForBucket(bucketUUID, pendingPath)
+	if err != nil && !errors.IsNotFound(err) {
+		return errors.Annotate(err, "cannot release replaced resource")
+	}
+	return nil
+}
+
+// readManagedResource returns the managed resource at the given
+// managed path, or an error satisfying errors.IsNotFound if there
+// is none.
+func (s versionedManagedStorage) readManagedResource(managedPath string) (versionedResourceDoc, error) {
+	var doc versionedResourceDoc
+	err := s.db.C(managedResourcesC).FindId(managedPath).One(&doc)
+	if err == mgo.ErrNotFound {
+		return doc, errors.NotFoundf("resource at path %q", managedPath)
+	} else if err != nil {
+		return doc, errors.Annotatef(err, "cannot read resource at path %q", managedPath)
+	}
+	return doc, nil
+}
+
+// copyManagedResource returns a copy of the blobstore's document for
+// the managed resource at the given path, relative to its bucket, as
+// it would be stored at the other given path. Only the fields naming
+// the path are changed; transaction fields are omitted.
+func (s versionedManagedStorage) copyManagedResource(fromManagedPath, from, to string) (bson.M, error) {
+	var src bson.M
+	err := s.db.C(managedResourcesC).FindId(fromManagedPath).One(&src)
+	if err == mgo.ErrNotFound {
+		return nil, errors.NotFoundf("resource at path %q", fromManagedPath)
+	} else if err != nil {
+		return nil, errors.Annotatef(err, "cannot read resource at path %q", fromManagedPath)
+	}
+	doc := make(bson.M)
+	for k, v := range src {
+		switch k {
+		case "txn-revno", "txn-queue", "put-version", "put-id":
+			continue
+		}
+		if p, ok := v.(string); ok && strings.HasSuffix(p, from) {
+			v = strings.TrimSuffix(p, from) + to
+		}
+		doc[k] = v
+	}
+	return doc, nil
+}
+
+// managedResourcePath returns the path at which the blobstore records
+// the managed resource with the given path in the given bucket.
+func managedResourcePath(bucketUUID, path string) string {
+	if bucketUUID == "" {
+		return path
+	}
+	return fmt.Sprintf("buckets/%s/%s", bucketUUID, path)
+}
+
+// pendingResourcePath returns the path, relative to a bucket, to which
+// the put with the given ID uploads its content.
+func pendingResourcePath(putId string) string {
+	return path.Join(pendingDir, putId)
+}
+
+// isPendingResourcePath reports whether the given path of a managed
+// resource is one to which content is uploaded before being swapped
+// into place.
+func isPendingResourcePath(p string) bool {
+	return strings.HasPrefix(p, pendingDir+"/") || strings.Contains(p, "/"+pendingDir+"/")
+}
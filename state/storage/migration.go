@@ -0,0 +1,198 @@
+// Copyright 2016 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package storage
+
+import (
+	"io"
+
+	"github.com/juju/errors"
+	"gopkg.in/juju/blobstore.v2"
+	"gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
+)
+
+const (
+	// managedResourcesC and resourceCatalogC are the names of the
+	// collections in which the blobstore records the paths of managed
+	// resources, and the catalog of their (deduplicated) content.
+	managedResourcesC = "managedStoredResources"
+	resourceCatalogC  = "storedResources"
+)
+
+// managedResourceDoc is the subset of the blobstore's persistent
+// representation of a managed resource that we need for migration.
+type managedResourceDoc struct {
+	Path       string `bson:"path"`
+	ResourceId string `bson:"resourceid"`
+}
+
+// resourceCatalogDoc is the subset of the blobstore's persistent
+// representation of a catalog entry that we need for migration and
+// for listing blobs.
+type resourceCatalogDoc struct {
+	SHA384Hash string `bson:"sha384hash"`
+	Length     int64  `bson:"length"`
+}
+
+// ResourceMetadata describes a managed resource stored for a model.
+type ResourceMetadata struct {
+	// Path is the path of the resource, relative to the model.
+	Path string
+
+	// Length is the length of the resource's content, in bytes.
+	Length int64
+
+	// SHA384Hash is the hex-encoded SHA-384 hash of the
+	// resource's content.
+	SHA384Hash string
+}
+
+// ResourceIterator provides sequential access to managed resources.
+type ResourceIterator interface {
+	// Next returns the metadata and content of the next resource.
+	// The caller is responsible for closing the returned reader.
+	// Next returns io.EOF when there are no more resources.
+	Next() (ResourceMetadata, io.ReadCloser, error)
+
+	// Close releases the resources held by the iterator. Any
+	// readers returned by Next must be closed first.
+	Close() error
+}
+
+// ExportResources returns a ResourceIterator over the managed resources
+// stored for the model with the specified UUID, ordered by path.
+// Resources that have not been completely uploaded are skipped.
+//
+// TODO(axw) ExportResources and ImportResources are not yet used by
+// model migration. Charms and agent binaries are migrated by uploading
+// them to the target, which stores them at new paths, and the documents
+// describing application resources are not yet exported; copying the
+// blobs as they are would leave them unreferenced on the target. Once
+// application resources are included in the model description, the
+// migration master should stream the model's resources bucket to the
+// target with these.
+func ExportResources(modelUUID string, session *mgo.Session) (ResourceIterator, error) {
+	s := stateStorage{modelUUID, session}
+	session, ms, err := s.blobstore()
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	db := session.DB(metadataDB)
+	iter := db.C(managedResourcesC).Find(
+		bson.D{{"bucketuuid", modelUUID}},
+	).Sort("path").Iter()
+	return &resourceIterator{
+		modelUUID: modelUUID,
+		session:   session,
+		catalog:   db.C(resourceCatalogC),
+		managed:   ms,
+		iter:      iter,
+	}, nil
+}
+
+type resourceIterator struct {
+	modelUUID string
+	session   *mgo.Session
+	catalog   *mgo.Collection
+	managed   blobstore.ManagedStorage
+	iter      *mgo.Iter
+}
+
+// Next is part of the ResourceIterator interface.
+func (i *resourceIterator) Next() (ResourceMetadata, io.ReadCloser, error) {
+	var doc managedResourceDoc
+	for i.iter.Next(&doc) {
+		if isPendingResourcePath(doc.Path) {
+			// The resource is being stored or removed.
+			continue
+		}
+		var catalogDoc resourceCatalogDoc
+		if err := i.catalog.FindId(doc.ResourceId).One(&catalogDoc); err == mgo.ErrNotFound {
+			// The resource was removed since we started iterating.
+			continue
+		} else if err != nil {
+			return ResourceMetadata{}, nil, errors.Annotatef(err, "reading catalog entry for %q", doc.Path)
+		}
+		r, length, err := i.managed.GetForBucket(i.modelUUID, doc.Path)
+		if errors.IsNotFound(err) || errors.Cause(err) == blobstore.ErrUploadPending {
+			// The resource was removed, or is still being
+			// uploaded; either way there's nothing to export.
+			continue
+		} else if err != nil {
+			return ResourceMetadata{}, nil, errors.Annotatef(err, "reading resource %q", doc.Path)
+		}
+		return ResourceMetadata{
+			Path:       doc.Path,
+			Length:     length,
+			SHA384Hash: catalogDoc.SHA384Hash,
+		}, r, nil
+	}
+	if err := i.iter.Err(); err != nil {
+		return ResourceMetadata{}, nil, errors.Annotate(err, "iterating managed resources")
+	}
+	return ResourceMetadata{}, nil, io.EOF
+}
+
+// Close is part of the ResourceIterator interface.
+func (i *resourceIterator) Close() error {
+	defer i.session.Close()
+	return errors.Trace(i.iter.Close())
+}
+
+// ImportResources stores the resources from the given iterator for the
+// model with the specified UUID. The content of each resource is checked
+// against its hash. Resources already stored at the same path with the
+// same hash are left alone, and content shared between paths is stored
+// only once, as the blobstore catalog deduplicates by hash.
+func ImportResources(modelUUID string, session *mgo.Session, resources ResourceIterator) error {
+	s := stateStorage{modelUUID, session}
+	session, ms, err := s.blobstore()
+	if err != nil {
+		return errors.Trace(err)
+	}
+	defer session.Close()
+	db := session.DB(metadataDB)
+	for {
+		metadata, r, err := resources.Next()
+		if err == io.EOF {
+			return nil
+		} else if err != nil {
+			return errors.Trace(err)
+		}
+		err = importResource(modelUUID, db, ms, metadata, r)
+		r.Close()
+		if err != nil {
+			return errors.Annotatef(err, "importing resource %q", metadata.Path)
+		}
+	}
+}
+
+func importResource(
+	modelUUID string,
+	db *mgo.Database,
+	ms blobstore.ManagedStorage,
+	metadata ResourceMetadata,
+	r io.Reader,
+) error {
+	var doc managedResourceDoc
+	err := db.C(managedResourcesC).Find(bson.D{
+		{"bucketuuid", modelUUID},
+		{"path", metadata.Path},
+	}).One(&doc)
+	if err == nil {
+		var catalogDoc resourceCatalogDoc
+		err := db.C(resourceCatalogC).FindId(doc.ResourceId).One(&catalogDoc)
+		if err != nil && err != mgo.ErrNotFound {
+			return errors.Trace(err)
+		}
+		if err == nil && catalogDoc.SHA384Hash == metadata.SHA384Hash {
+			return nil
+		}
+	} else if err != mgo.ErrNotFound {
+		return errors.Trace(err)
+	}
+	return ms.PutForBucketAndCheckHash(
+		modelUUID, metadata.Path, r, metadata.Length, metadata.SHA384Hash,
+	)
+}
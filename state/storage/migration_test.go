@@ -0,0 +1,137 @@
+// Copyright 2016 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package storage_test
+
+import (
+	"crypto/sha512"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strings"
+
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/state/storage"
+)
+
+const otherUUID = "6f9b4d5c-2a53-4e5f-8a61-3d1e1c3b2f70"
+
+func sha384(data string) string {
+	return fmt.Sprintf("%x", sha512.Sum384([]byte(data)))
+}
+
+func (s *StorageSuite) exportAll(c *gc.C, modelUUID string) map[storage.ResourceMetadata]string {
+	iter, err := storage.ExportResources(modelUUID, s.Session)
+	c.Assert(err, jc.ErrorIsNil)
+	defer iter.Close()
+
+	resources := make(map[storage.ResourceMetadata]string)
+	for {
+		metadata, r, err := iter.Next()
+		if err == io.EOF {
+			break
+		}
+		c.Assert(err, jc.ErrorIsNil)
+		data, err := ioutil.ReadAll(r)
+		r.Close()
+		c.Assert(err, jc.ErrorIsNil)
+		resources[metadata] = string(data)
+	}
+	return resources
+}
+
+func (s *StorageSuite) TestExportResources(c *gc.C) {
+	err := s.storage.Put("a", strings.NewReader("abc"), 3)
+	c.Assert(err, jc.ErrorIsNil)
+	err = s.storage.Put("b", strings.NewReader("defg"), 4)
+	c.Assert(err, jc.ErrorIsNil)
+	err = storage.NewStorage(otherUUID, s.Session).Put("c", strings.NewReader("xyz"), 3)
+	c.Assert(err, jc.ErrorIsNil)
+
+	c.Assert(s.exportAll(c, testUUID), jc.DeepEquals, map[storage.ResourceMetadata]string{
+		{Path: "a", Length: 3, SHA384Hash: sha384("abc")}:  "abc",
+		{Path: "b", Length: 4, SHA384Hash: sha384("defg")}: "defg",
+	})
+}
+
+func (s *StorageSuite) TestExportResourcesEmpty(c *gc.C) {
+	c.Assert(s.exportAll(c, testUUID), gc.HasLen, 0)
+}
+
+func (s *StorageSuite) TestImportResources(c *gc.C) {
+	err := s.storage.Put("a", strings.NewReader("abc"), 3)
+	c.Assert(err, jc.ErrorIsNil)
+	err = s.storage.Put("b", strings.NewReader("abc"), 3)
+	c.Assert(err, jc.ErrorIsNil)
+
+	iter, err := storage.ExportResources(testUUID, s.Session)
+	c.Assert(err, jc.ErrorIsNil)
+	defer iter.Close()
+	err = storage.ImportResources(otherUUID, s.Session, iter)
+	c.Assert(err, jc.ErrorIsNil)
+
+	c.Assert(s.exportAll(c, otherUUID), jc.DeepEquals, map[storage.ResourceMetadata]string{
+		{Path: "a", Length: 3, SHA384Hash: sha384("abc")}: "abc",
+		{Path: "b", Length: 3, SHA384Hash: sha384("abc")}: "abc",
+	})
+
+	// Identical content is stored only once.
+	n, err := s.Session.DB("juju").C("storedResources").Count()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(n, gc.Equals, 1)
+}
+
+func (s *StorageSuite) TestImportResourcesExisting(c *gc.C) {
+	err := s.storage.Put("a", strings.NewReader("abc"), 3)
+	c.Assert(err, jc.ErrorIsNil)
+	err = s.storage.Put("b", strings.NewReader("def"), 3)
+	c.Assert(err, jc.ErrorIsNil)
+	other := storage.NewStorage(otherUUID, s.Session)
+	err = other.Put("a", strings.NewReader("abc"), 3)
+	c.Assert(err, jc.ErrorIsNil)
+	err = other.Put("b", strings.NewReader("old"), 3)
+	c.Assert(err, jc.ErrorIsNil)
+
+	iter, err := storage.ExportResources(testUUID, s.Session)
+	c.Assert(err, jc.ErrorIsNil)
+	defer iter.Close()
+	err = storage.ImportResources(otherUUID, s.Session, iter)
+	c.Assert(err, jc.ErrorIsNil)
+
+	// Identical resources are left alone, and
+	// differing ones are replaced.
+	c.Assert(s.exportAll(c, otherUUID), jc.DeepEquals, map[storage.ResourceMetadata]string{
+		{Path: "a", Length: 3, SHA384Hash: sha384("abc")}: "abc",
+		{Path: "b", Length: 3, SHA384Hash: sha384("def")}: "def",
+	})
+}
+
+func (s *StorageSuite) TestImportResourcesHashMismatch(c *gc.C) {
+	err := storage.ImportResources(otherUUID, s.Session, &fakeResourceIterator{
+		metadata: []storage.ResourceMetadata{{
+			Path: "a", Length: 3, SHA384Hash: sha384("xyz"),
+		}},
+		data: []string{"abc"},
+	})
+	c.Assert(err, gc.ErrorMatches, `importing resource "a": .*`)
+}
+
+type fakeResourceIterator struct {
+	metadata []storage.ResourceMetadata
+	data     []string
+}
+
+func (i *fakeResourceIterator) Next() (storage.ResourceMetadata, io.ReadCloser, error) {
+	if len(i.metadata) == 0 {
+		return storage.ResourceMetadata{}, nil, io.EOF
+	}
+	metadata, data := i.metadata[0], i.data[0]
+	i.metadata, i.data = i.metadata[1:], i.data[1:]
+	return metadata, ioutil.NopCloser(strings.NewReader(data)), nil
+}
+
+func (i *fakeResourceIterator) Close() error {
+	return nil
+}
@@ -6,6 +6,7 @@ package storage
 import (
 	"io"
 
+	"github.com/juju/errors"
 	"gopkg.in/juju/blobstore.v2"
 	"gopkg.in/mgo.v2"
 )
@@ -51,15 +52,25 @@ type stateStorage struct {
 	session   *mgo.Session
 }
 
-func (s stateStorage) blobstore() (*mgo.Session, blobstore.ManagedStorage) {
+// blobstore returns a copy of the storage's session, and a
+// blobstore.ManagedStorage that stores content in the controller's
+// blob backend using that session.
+func (s stateStorage) blobstore() (*mgo.Session, blobstore.ManagedStorage, error) {
 	session := s.session.Copy()
-	rs := blobstore.NewGridFS(blobstoreDB, blobstoreDB, session)
+	rs, err := NewResourceStorage(session)
+	if err != nil {
+		session.Close()
+		return nil, nil, errors.Trace(err)
+	}
 	db := session.DB(metadataDB)
-	return session, blobstore.NewManagedStorage(db, rs)
+	return session, NewManagedStorage(db, rs), nil
 }
 
 func (s stateStorage) Get(path string) (r io.ReadCloser, length int64, err error) {
-	session, ms := s.blobstore()
+	session, ms, err := s.blobstore()
+	if err != nil {
+		return nil, -1, errors.Trace(err)
+	}
 	r, length, err = ms.GetForBucket(s.modelUUID, path)
 	if err != nil {
 		session.Close()
@@ -69,19 +80,28 @@ func (s stateStorage) Get(path string) (r io.ReadCloser, length int64, err error
 }
 
 func (s stateStorage) Put(path string, r io.Reader, length int64) error {
-	session, ms := s.blobstore()
+	session, ms, err := s.blobstore()
+	if err != nil {
+		return errors.Trace(err)
+	}
 	defer session.Close()
 	return ms.PutForBucket(s.modelUUID, path, r, length)
 }
 
 func (s stateStorage) PutAndCheckHash(path string, r io.Reader, length int64, hash string) error {
-	session, ms := s.blobstore()
+	session, ms, err := s.blobstore()
+	if err != nil {
+		return errors.Trace(err)
+	}
 	defer session.Close()
 	return ms.PutForBucketAndCheckHash(s.modelUUID, path, r, length, hash)
 }
 
 func (s stateStorage) Remove(path string) error {
-	session, ms := s.blobstore()
+	session, ms, err := s.blobstore()
+	if err != nil {
+		return errors.Trace(err)
+	}
 	defer session.Close()
 	return ms.RemoveForBucket(s.modelUUID, path)
 }
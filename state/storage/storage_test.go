@@ -4,14 +4,18 @@
 package storage_test
 
 import (
+	"fmt"
+	"io"
 	"io/ioutil"
 	"strings"
+	"sync"
 
 	"github.com/juju/errors"
 	gitjujutesting "github.com/juju/testing"
 	jc "github.com/juju/testing/checkers"
 	gc "gopkg.in/check.v1"
 	"gopkg.in/juju/blobstore.v2"
+	"gopkg.in/mgo.v2/bson"
 
 	"github.com/juju/juju/state/storage"
 	"github.com/juju/juju/testing"
@@ -94,3 +98,164 @@ func (s *StorageSuite) TestStorageRemove(c *gc.C) {
 	err = s.storage.Remove("path")
 	c.Assert(err, jc.Satisfies, errors.IsNotFound)
 }
+
+func (s *StorageSuite) TestStorageConcurrentPut(c *gc.C) {
+	const writers = 10
+	contents := make(map[string]bool)
+	var wg sync.WaitGroup
+	errs := make(chan error, writers)
+	for i := 0; i < writers; i++ {
+		content := fmt.Sprintf("content-%d", i)
+		contents[content] = true
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs <- s.storage.Put("path", strings.NewReader(content), int64(len(content)))
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		c.Check(err, jc.ErrorIsNil)
+	}
+
+	// Exactly one writer wins, and the content of the others is not
+	// left behind in the catalog.
+	r, _, err := s.storage.Get("path")
+	c.Assert(err, jc.ErrorIsNil)
+	defer r.Close()
+	data, err := ioutil.ReadAll(r)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(contents[string(data)], jc.IsTrue)
+
+	s.assertOneResource(c)
+}
+
+func (s *StorageSuite) TestStoragePutWinnerStartedLast(c *gc.C) {
+	var mu sync.Mutex
+	putIds := []string{"z", "a", "b"}
+	s.PatchValue(storage.NewPutId, func() string {
+		mu.Lock()
+		defer mu.Unlock()
+		id := putIds[0]
+		putIds = putIds[1:]
+		return id
+	})
+
+	// The first put starts uploading, and is held until the
+	// other two have finished.
+	blocked := newBlockingReader("first")
+	done := make(chan error, 1)
+	go func() {
+		done <- s.storage.Put("path", blocked, 5)
+	}()
+	<-blocked.started
+
+	// The second put claims the same version as the first. The
+	// third starts after the second has finished, so it claims a
+	// later version than both.
+	err := s.storage.Put("path", strings.NewReader("second"), 6)
+	c.Assert(err, jc.ErrorIsNil)
+	err = s.storage.Put("path", strings.NewReader("third"), 5)
+	c.Assert(err, jc.ErrorIsNil)
+
+	// The first put finishes last, and has the highest ID, but
+	// started before the third, so it gives way and its content
+	// is released.
+	close(blocked.proceed)
+	c.Assert(<-done, jc.ErrorIsNil)
+	s.assertContent(c, "path", "third")
+	s.assertOneResource(c)
+}
+
+func (s *StorageSuite) TestStoragePutWinnerSameVersion(c *gc.C) {
+	var mu sync.Mutex
+	putIds := []string{"b", "a"}
+	s.PatchValue(storage.NewPutId, func() string {
+		mu.Lock()
+		defer mu.Unlock()
+		id := putIds[0]
+		putIds = putIds[1:]
+		return id
+	})
+
+	blocked := newBlockingReader("first")
+	done := make(chan error, 1)
+	go func() {
+		done <- s.storage.Put("path", blocked, 5)
+	}()
+	<-blocked.started
+
+	// Both puts claim the same version, so the one with the higher
+	// ID wins, even though it finishes last.
+	err := s.storage.Put("path", strings.NewReader("second"), 6)
+	c.Assert(err, jc.ErrorIsNil)
+	close(blocked.proceed)
+	c.Assert(<-done, jc.ErrorIsNil)
+	s.assertContent(c, "path", "first")
+	s.assertOneResource(c)
+}
+
+func (s *StorageSuite) TestStorageRemoveReleasesContent(c *gc.C) {
+	err := s.storage.Put("path", strings.NewReader("abc"), 3)
+	c.Assert(err, jc.ErrorIsNil)
+	err = s.storage.Remove("path")
+	c.Assert(err, jc.ErrorIsNil)
+
+	db := s.Session.DB("juju")
+	n, err := db.C("managedStoredResources").Count()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(n, gc.Equals, 0)
+	n, err = db.C("storedResources").Count()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(n, gc.Equals, 0)
+}
+
+func (s *StorageSuite) assertContent(c *gc.C, path, expected string) {
+	r, _, err := s.storage.Get(path)
+	c.Assert(err, jc.ErrorIsNil)
+	defer r.Close()
+	data, err := ioutil.ReadAll(r)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(string(data), gc.Equals, expected)
+}
+
+// assertOneResource asserts that only the resource stored at "path"
+// remains, and that no pending or replaced content is left behind.
+func (s *StorageSuite) assertOneResource(c *gc.C) {
+	db := s.Session.DB("juju")
+	n, err := db.C("managedStoredResources").Find(bson.D{{"path", "buckets/" + testUUID + "/path"}}).Count()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(n, gc.Equals, 1)
+	n, err = db.C("managedStoredResources").Count()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(n, gc.Equals, 1)
+	n, err = db.C("storedResources").Count()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(n, gc.Equals, 1)
+}
+
+// blockingReader is an io.Reader that blocks on its first read
+// until proceed is closed, having first closed started.
+type blockingReader struct {
+	io.Reader
+	once    sync.Once
+	started chan struct{}
+	proceed chan struct{}
+}
+
+func newBlockingReader(content string) *blockingReader {
+	return &blockingReader{
+		Reader:  strings.NewReader(content),
+		started: make(chan struct{}),
+		proceed: make(chan struct{}),
+	}
+}
+
+func (r *blockingReader) Read(p []byte) (int, error) {
+	r.once.Do(func() {
+		close(r.started)
+		<-r.proceed
+	})
+	return r.Reader.Read(p)
+}
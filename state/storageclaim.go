@@ -0,0 +1,403 @@
+// Copyright 2016 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package state
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/juju/errors"
+	jujutxn "github.com/juju/txn"
+	"gopkg.in/juju/names.v2"
+	"gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
+	"gopkg.in/mgo.v2/txn"
+)
+
+const (
+	// maxStorageClaimHistory is the maximum number of events recorded
+	// in the claim history of a storage entity; older events are
+	// discarded.
+	maxStorageClaimHistory = 20
+
+	// storageClaimDuration is the time for which a claim is held
+	// after it is made or renewed, unless it is released first. It
+	// is long enough for any single storage operation; a scope that
+	// claims an entity again renews its claim. Claims expire so that
+	// a storage provisioner that dies while holding a claim does not
+	// block the other scopes forever.
+	storageClaimDuration = time.Hour
+)
+
+// StorageClaimAction describes a change in the ownership of a
+// storage entity's claim.
+type StorageClaimAction string
+
+const (
+	// StorageClaimed records that a scope claimed a storage entity.
+	StorageClaimed StorageClaimAction = "claimed"
+
+	// StorageReleased records that a scope released its claim
+	// on a storage entity, or that the scope's machine was removed.
+	StorageReleased StorageClaimAction = "released"
+
+	// StorageClaimExpired records that a scope's claim on a storage
+	// entity expired, and was taken over by another scope.
+	StorageClaimExpired StorageClaimAction = "expired"
+)
+
+// StorageClaimEvent records a change in the ownership of a
+// storage entity's claim.
+type StorageClaimEvent struct {
+	// Owner is the tag of the scope that claimed or
+	// released the entity.
+	Owner names.Tag
+
+	// Action records whether the entity was claimed or released.
+	Action StorageClaimAction
+
+	// Time is the time at which the event occurred.
+	Time time.Time
+}
+
+// StorageClaim describes the claim on a volume or filesystem.
+//
+// The storage provisioners of the model (environ scope) and of the
+// machines (machine scope) both act on storage entities; for example,
+// the environ-scoped provisioner creates the volume backing a
+// filesystem, and the machine-scoped provisioner then creates the
+// filesystem on it. A provisioner claims an entity before acting on
+// it, and releases the claim when it is done, so that provisioners of
+// different scopes never act on the same entity concurrently. Claims
+// not released expire, and may then be taken over by another scope.
+type StorageClaim struct {
+	// Owner is the tag of the scope holding the claim,
+	// or nil if the entity is not currently claimed.
+	Owner names.Tag
+
+	// Since is the time at which the claim was last
+	// claimed or released.
+	Since time.Time
+
+	// Expires is the time at which the claim expires, unless
+	// it is renewed first, or the zero time if the entity is
+	// not currently claimed.
+	Expires time.Time
+
+	// History records the most recent changes in the
+	// ownership of the claim, oldest first.
+	History []StorageClaimEvent
+}
+
+// storageClaimDoc records the claim on a storage entity.
+type storageClaimDoc struct {
+	DocID     string                 `bson:"_id"`
+	ModelUUID string                 `bson:"model-uuid"`
+	Entity    string                 `bson:"entity"`
+	Owner     string                 `bson:"owner"`
+	Since     int64                  `bson:"since"`
+	Expires   int64                  `bson:"expires"`
+	History   []storageClaimEventDoc `bson:"history"`
+}
+
+// storageClaimEventDoc records a change in the ownership of
+// a storage entity's claim.
+type storageClaimEventDoc struct {
+	Owner  string `bson:"owner"`
+	Action string `bson:"action"`
+	Time   int64  `bson:"time"`
+}
+
+type storageClaimedError struct {
+	entity names.Tag
+	owner  names.Tag
+}
+
+func (e *storageClaimedError) Error() string {
+	return fmt.Sprintf(
+		"%s is claimed by %s",
+		names.ReadableString(e.entity),
+		names.ReadableString(e.owner),
+	)
+}
+
+// IsStorageClaimedError reports whether or not the given error was
+// caused by an attempt to claim or release a storage entity that is
+// claimed by another scope.
+func IsStorageClaimedError(err error) bool {
+	_, ok := errors.Cause(err).(*storageClaimedError)
+	return ok
+}
+
+// StorageEntityClaim returns the claim on the volume or filesystem with
+// the given tag. If the entity has never been claimed, an error
+// satisfying errors.IsNotFound is returned.
+func (st *State) StorageEntityClaim(entity names.Tag) (StorageClaim, error) {
+	key, err := storageClaimKey(entity)
+	if err != nil {
+		return StorageClaim{}, errors.Trace(err)
+	}
+	doc, err := st.storageClaimDoc(key)
+	if err == mgo.ErrNotFound {
+		return StorageClaim{}, errors.NotFoundf("claim on %s", names.ReadableString(entity))
+	} else if err != nil {
+		return StorageClaim{}, errors.Annotatef(err, "cannot get claim on %s", names.ReadableString(entity))
+	}
+	claim := StorageClaim{
+		Since:   time.Unix(0, doc.Since).UTC(),
+		History: make([]StorageClaimEvent, len(doc.History)),
+	}
+	if doc.Owner != "" {
+		if claim.Owner, err = names.ParseTag(doc.Owner); err != nil {
+			return StorageClaim{}, errors.Trace(err)
+		}
+		claim.Expires = time.Unix(0, doc.Expires).UTC()
+	}
+	for i, event := range doc.History {
+		owner, err := names.ParseTag(event.Owner)
+		if err != nil {
+			return StorageClaim{}, errors.Trace(err)
+		}
+		claim.History[i] = StorageClaimEvent{
+			Owner:  owner,
+			Action: StorageClaimAction(event.Action),
+			Time:   time.Unix(0, event.Time).UTC(),
+		}
+	}
+	return claim, nil
+}
+
+// ClaimStorageEntity claims the volume or filesystem with the given tag
+// on behalf of the storage provisioner scope (model or machine) with the
+// given tag. Claims are held by scopes rather than by workers, so
+// claiming an entity already claimed by the same scope succeeds, and
+// renews the claim. If the entity is claimed by another scope, and the
+// claim has not expired, an error satisfying IsStorageClaimedError is
+// returned; an expired claim is taken over.
+func (st *State) ClaimStorageEntity(entity, owner names.Tag) (err error) {
+	defer errors.DeferredAnnotatef(&err, "cannot claim %s", names.ReadableString(entity))
+	key, err := storageClaimKey(entity)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	if err := validateStorageClaimOwner(owner); err != nil {
+		return errors.Trace(err)
+	}
+	buildTxn := func(attempt int) ([]txn.Op, error) {
+		entityOp, err := st.storageClaimEntityOp(entity)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		now := st.clock.Now()
+		expires := now.Add(storageClaimDuration).UnixNano()
+		events := []storageClaimEventDoc{{
+			Owner:  owner.String(),
+			Action: string(StorageClaimed),
+			Time:   now.UnixNano(),
+		}}
+		doc, err := st.storageClaimDoc(key)
+		if err == mgo.ErrNotFound {
+			return []txn.Op{entityOp, {
+				C:      storageClaimsC,
+				Id:     key,
+				Assert: txn.DocMissing,
+				Insert: &storageClaimDoc{
+					DocID:   key,
+					Entity:  entity.String(),
+					Owner:   owner.String(),
+					Since:   now.UnixNano(),
+					Expires: expires,
+					History: events,
+				},
+			}}, nil
+		} else if err != nil {
+			return nil, errors.Trace(err)
+		}
+		assert := bson.D{{"owner", doc.Owner}}
+		switch doc.Owner {
+		case owner.String():
+			return []txn.Op{entityOp, {
+				C:      storageClaimsC,
+				Id:     key,
+				Assert: assert,
+				Update: bson.D{{"$set", bson.D{{"expires", expires}}}},
+			}}, nil
+		case "":
+		default:
+			if now.UnixNano() < doc.Expires {
+				return nil, storageClaimedErr(entity, doc.Owner)
+			}
+			// The claim has expired. Make sure that
+			// it is not renewed while we take it over.
+			assert = append(assert, bson.DocElem{"expires", doc.Expires})
+			events = append([]storageClaimEventDoc{{
+				Owner:  doc.Owner,
+				Action: string(StorageClaimExpired),
+				Time:   now.UnixNano(),
+			}}, events...)
+		}
+		return []txn.Op{entityOp, {
+			C:      storageClaimsC,
+			Id:     key,
+			Assert: assert,
+			Update: updateStorageClaimOwner(owner.String(), now.UnixNano(), expires, events...),
+		}}, nil
+	}
+	return st.run(buildTxn)
+}
+
+// ReleaseStorageEntity releases the claim on the volume or filesystem
+// with the given tag held by the storage provisioner scope with the
+// given tag. Releasing an entity that is not claimed, or that no longer
+// exists, succeeds; if the entity is claimed by another scope, an error
+// satisfying IsStorageClaimedError is returned.
+func (st *State) ReleaseStorageEntity(entity, owner names.Tag) (err error) {
+	defer errors.DeferredAnnotatef(&err, "cannot release %s", names.ReadableString(entity))
+	key, err := storageClaimKey(entity)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	if err := validateStorageClaimOwner(owner); err != nil {
+		return errors.Trace(err)
+	}
+	buildTxn := func(attempt int) ([]txn.Op, error) {
+		doc, err := st.storageClaimDoc(key)
+		if err == mgo.ErrNotFound {
+			return nil, jujutxn.ErrNoOperations
+		} else if err != nil {
+			return nil, errors.Trace(err)
+		}
+		switch doc.Owner {
+		case owner.String():
+		case "":
+			return nil, jujutxn.ErrNoOperations
+		default:
+			return nil, storageClaimedErr(entity, doc.Owner)
+		}
+		return []txn.Op{releaseStorageClaimOp(key, owner, st.clock.Now())}, nil
+	}
+	return st.run(buildTxn)
+}
+
+// removeMachineStorageClaimsOps returns the operations required to
+// release the claims held by the given machine's scope, so that they
+// are not left to expire when the machine is removed.
+func (st *State) removeMachineStorageClaimsOps(machine names.MachineTag) ([]txn.Op, error) {
+	coll, closer := st.getCollection(storageClaimsC)
+	defer closer()
+
+	var docs []storageClaimDoc
+	if err := coll.Find(bson.D{{"owner", machine.String()}}).All(&docs); err != nil {
+		return nil, errors.Annotatef(err, "cannot get claims held by %s", names.ReadableString(machine))
+	}
+	now := st.clock.Now()
+	ops := make([]txn.Op, len(docs))
+	for i, doc := range docs {
+		ops[i] = releaseStorageClaimOp(st.localID(doc.DocID), machine, now)
+	}
+	return ops, nil
+}
+
+// releaseStorageClaimOp returns the operation required to release the
+// claim with the given key held by the given owner.
+func releaseStorageClaimOp(key string, owner names.Tag, now time.Time) txn.Op {
+	event := storageClaimEventDoc{
+		Owner:  owner.String(),
+		Action: string(StorageReleased),
+		Time:   now.UnixNano(),
+	}
+	return txn.Op{
+		C:      storageClaimsC,
+		Id:     key,
+		Assert: bson.D{{"owner", owner.String()}},
+		Update: updateStorageClaimOwner("", event.Time, 0, event),
+	}
+}
+
+func (st *State) storageClaimDoc(key string) (*storageClaimDoc, error) {
+	coll, closer := st.getCollection(storageClaimsC)
+	defer closer()
+
+	var doc storageClaimDoc
+	if err := coll.FindId(key).One(&doc); err != nil {
+		return nil, err
+	}
+	return &doc, nil
+}
+
+// storageClaimEntityOp returns an operation asserting that the storage
+// entity being claimed exists, so that claims are not left behind for
+// removed entities.
+func (st *State) storageClaimEntityOp(entity names.Tag) (txn.Op, error) {
+	switch entity := entity.(type) {
+	case names.VolumeTag:
+		if _, err := st.Volume(entity); err != nil {
+			return txn.Op{}, errors.Trace(err)
+		}
+		return txn.Op{C: volumesC, Id: entity.Id(), Assert: txn.DocExists}, nil
+	case names.FilesystemTag:
+		if _, err := st.Filesystem(entity); err != nil {
+			return txn.Op{}, errors.Trace(err)
+		}
+		return txn.Op{C: filesystemsC, Id: entity.Id(), Assert: txn.DocExists}, nil
+	}
+	return txn.Op{}, errors.NotValidf("claiming %s", names.ReadableString(entity))
+}
+
+// updateStorageClaimOwner returns the update that records the given
+// owner of a claim, and when the claim expires, and appends the events
+// to the claim's history.
+func updateStorageClaimOwner(owner string, since, expires int64, events ...storageClaimEventDoc) bson.D {
+	return bson.D{
+		{"$set", bson.D{
+			{"owner", owner},
+			{"since", since},
+			{"expires", expires},
+		}},
+		{"$push", bson.D{{"history", bson.D{
+			{"$each", events},
+			{"$slice", -maxStorageClaimHistory},
+		}}}},
+	}
+}
+
+// removeStorageClaimOp returns the operation required to remove the
+// claim on the storage entity with the given global key, if it has one.
+func removeStorageClaimOp(globalKey string) txn.Op {
+	return txn.Op{
+		C:      storageClaimsC,
+		Id:     globalKey,
+		Remove: true,
+	}
+}
+
+// storageClaimKey returns the key of the claim on the given
+// storage entity, which is the entity's global key.
+func storageClaimKey(entity names.Tag) (string, error) {
+	switch entity := entity.(type) {
+	case names.VolumeTag:
+		return volumeGlobalKey(entity.Id()), nil
+	case names.FilesystemTag:
+		return filesystemGlobalKey(entity.Id()), nil
+	}
+	return "", errors.NotValidf("storage entity %s", names.ReadableString(entity))
+}
+
+// validateStorageClaimOwner returns an error if the given tag is not
+// that of a storage provisioner scope.
+func validateStorageClaimOwner(owner names.Tag) error {
+	switch owner.(type) {
+	case names.ModelTag, names.MachineTag:
+		return nil
+	}
+	return errors.NotValidf("storage claim owner %s", names.ReadableString(owner))
+}
+
+func storageClaimedErr(entity names.Tag, owner string) error {
+	ownerTag, err := names.ParseTag(owner)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	return &storageClaimedError{entity, ownerTag}
+}
@@ -0,0 +1,194 @@
+// Copyright 2016 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package state
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/juju/errors"
+	"github.com/juju/version"
+	"gopkg.in/mgo.v2/bson"
+
+	"github.com/juju/juju/state/binarystorage"
+	"github.com/juju/juju/tools"
+)
+
+// UnusedAgentBinaries returns the metadata of the agent binaries in
+// the model's own tools storage that may be removed, sorted by version.
+//
+// An agent binary is retained if its version is among the keepLatest
+// most recent versions with the same major and minor version numbers,
+// or if its version is in use. A version is in use if it is the model's
+// agent-version, or if a machine or unit agent is running it; for the
+// controller model, whose tools storage is shared by all models, the
+// versions in use by every model are considered. Versions are compared
+// without regard to series or architecture, so that all binaries for
+// a retained version are kept.
+func (st *State) UnusedAgentBinaries(keepLatest int) ([]binarystorage.Metadata, error) {
+	if keepLatest < 1 {
+		return nil, errors.NotValidf("keeping %d latest agent versions", keepLatest)
+	}
+	storage, err := st.newBinaryStorageCloser(toolsmetadataC, st.ModelUUID())
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	defer storage.Close()
+	all, err := storage.AllMetadata()
+	if err != nil {
+		return nil, errors.Annotate(err, "listing agent binaries")
+	}
+	inUse, err := st.agentVersionsInUse()
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	return unusedAgentBinaries(all, inUse, keepLatest), nil
+}
+
+// RemoveUnusedAgentBinaries removes the agent binaries reported by
+// UnusedAgentBinaries, returning the metadata of those removed.
+func (st *State) RemoveUnusedAgentBinaries(keepLatest int) ([]binarystorage.Metadata, error) {
+	unused, err := st.UnusedAgentBinaries(keepLatest)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	storage, err := st.newBinaryStorageCloser(toolsmetadataC, st.ModelUUID())
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	defer storage.Close()
+	removed := make([]binarystorage.Metadata, 0, len(unused))
+	for _, metadata := range unused {
+		err := storage.Remove(metadata.Version)
+		if errors.IsNotFound(err) {
+			continue
+		} else if err != nil {
+			return removed, errors.Annotatef(err, "removing agent binary %s", metadata.Version)
+		}
+		logger.Infof("removed unused agent binary %s", metadata.Version)
+		removed = append(removed, metadata)
+	}
+	return removed, nil
+}
+
+// agentVersionsInUse returns the set of agent versions in use by the
+// model or, for the controller model, by all models.
+func (st *State) agentVersionsInUse() (map[version.Number]bool, error) {
+	inUse := make(map[version.Number]bool)
+	if err := st.modelAgentVersionsInUse(inUse); err != nil {
+		return nil, errors.Trace(err)
+	}
+	if !st.IsController() {
+		return inUse, nil
+	}
+	models, err := st.AllModels()
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	for _, model := range models {
+		if model.UUID() == st.ModelUUID() {
+			continue
+		}
+		modelSt, err := st.ForModel(model.ModelTag())
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		err = modelSt.modelAgentVersionsInUse(inUse)
+		modelSt.Close()
+		if err != nil {
+			return nil, errors.Annotatef(err, "model %q", model.UUID())
+		}
+	}
+	return inUse, nil
+}
+
+// modelAgentVersionsInUse adds the model's agent-version, and the
+// versions run by its machine and unit agents, to inUse.
+func (st *State) modelAgentVersionsInUse(inUse map[version.Number]bool) error {
+	cfg, err := st.ModelConfig()
+	if err != nil {
+		return errors.Trace(err)
+	}
+	if agentVersion, ok := cfg.AgentVersion(); ok {
+		inUse[agentVersion] = true
+	}
+	for _, collName := range []string{machinesC, unitsC} {
+		var docs []struct {
+			Tools *tools.Tools `bson:"tools"`
+		}
+		coll, closer := st.getCollection(collName)
+		err := coll.Find(bson.D{{"tools", bson.D{{"$exists", true}}}}).Select(bson.D{{"tools", 1}}).All(&docs)
+		closer()
+		if err != nil {
+			return errors.Annotatef(err, "reading %s agent versions", collName)
+		}
+		for _, doc := range docs {
+			if doc.Tools != nil {
+				inUse[doc.Tools.Version.Number] = true
+			}
+		}
+	}
+	return nil
+}
+
+// unusedAgentBinaries applies the agent binary retention policy
+// described by UnusedAgentBinaries, returning the binaries that
+// are not retained, sorted by version.
+func unusedAgentBinaries(all []binarystorage.Metadata, inUse map[version.Number]bool, keepLatest int) []binarystorage.Metadata {
+	// Find the distinct versions for each major.minor.
+	byMinor := make(map[string][]version.Number)
+	seen := make(map[version.Number]bool)
+	parsed := make([]version.Binary, len(all))
+	for i, metadata := range all {
+		v, err := version.ParseBinary(metadata.Version)
+		if err != nil {
+			// Leave anything we don't understand alone.
+			logger.Warningf("ignoring agent binary with invalid version %q: %v", metadata.Version, err)
+			continue
+		}
+		parsed[i] = v
+		if seen[v.Number] {
+			continue
+		}
+		seen[v.Number] = true
+		key := fmt.Sprintf("%d.%d", v.Major, v.Minor)
+		byMinor[key] = append(byMinor[key], v.Number)
+	}
+
+	keep := make(map[version.Number]bool)
+	for number := range inUse {
+		keep[number] = true
+	}
+	for _, numbers := range byMinor {
+		sort.Sort(sort.Reverse(versionNumbers(numbers)))
+		if len(numbers) > keepLatest {
+			numbers = numbers[:keepLatest]
+		}
+		for _, number := range numbers {
+			keep[number] = true
+		}
+	}
+
+	var unused []binarystorage.Metadata
+	for i, metadata := range all {
+		if parsed[i] == (version.Binary{}) || keep[parsed[i].Number] {
+			continue
+		}
+		unused = append(unused, metadata)
+	}
+	sort.Sort(metadataByVersion(unused))
+	return unused
+}
+
+type versionNumbers []version.Number
+
+func (v versionNumbers) Len() int           { return len(v) }
+func (v versionNumbers) Swap(i, j int)      { v[i], v[j] = v[j], v[i] }
+func (v versionNumbers) Less(i, j int) bool { return v[i].Compare(v[j]) < 0 }
+
+type metadataByVersion []binarystorage.Metadata
+
+func (m metadataByVersion) Len() int           { return len(m) }
+func (m metadataByVersion) Swap(i, j int)      { m[i], m[j] = m[j], m[i] }
+func (m metadataByVersion) Less(i, j int) bool { return m[i].Version < m[j].Version }
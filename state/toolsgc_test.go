@@ -0,0 +1,140 @@
+// Copyright 2016 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package state_test
+
+import (
+	"strings"
+
+	"github.com/juju/errors"
+	jc "github.com/juju/testing/checkers"
+	"github.com/juju/version"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/state"
+	"github.com/juju/juju/state/binarystorage"
+)
+
+type toolsGCSuite struct {
+	ConnSuite
+}
+
+var _ = gc.Suite(&toolsGCSuite{})
+
+func (s *toolsGCSuite) addAgentBinaries(c *gc.C, st *state.State, versions ...string) {
+	storage, err := st.ToolsStorage()
+	c.Assert(err, jc.ErrorIsNil)
+	defer storage.Close()
+	for _, v := range versions {
+		err := storage.Add(strings.NewReader(v), binarystorage.Metadata{
+			Version: v,
+			Size:    int64(len(v)),
+			SHA256:  "hash(" + v + ")",
+		})
+		c.Assert(err, jc.ErrorIsNil)
+	}
+}
+
+func (s *toolsGCSuite) addMachineRunning(c *gc.C, st *state.State, v string) {
+	m, err := st.AddMachine("quantal", state.JobHostUnits)
+	c.Assert(err, jc.ErrorIsNil)
+	err = m.SetAgentVersion(version.MustParseBinary(v))
+	c.Assert(err, jc.ErrorIsNil)
+}
+
+func metadataVersions(metadata []binarystorage.Metadata) []string {
+	versions := make([]string, len(metadata))
+	for i, m := range metadata {
+		versions[i] = m.Version
+	}
+	return versions
+}
+
+func (s *toolsGCSuite) TestUnusedAgentBinaries(c *gc.C) {
+	s.addAgentBinaries(c, s.State,
+		"1.24.5-trusty-amd64",
+		"1.24.6-trusty-amd64",
+		"1.25.0-trusty-amd64",
+		"1.25.1-trusty-amd64",
+		"1.25.1-xenial-amd64",
+		"1.25.2-trusty-amd64",
+		"1.25.2-xenial-arm64",
+		"not-a-version",
+	)
+	s.addMachineRunning(c, s.State, "1.25.0-trusty-amd64")
+
+	unused, err := s.State.UnusedAgentBinaries(1)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(metadataVersions(unused), jc.DeepEquals, []string{
+		"1.24.5-trusty-amd64",
+		"1.25.1-trusty-amd64",
+		"1.25.1-xenial-amd64",
+	})
+	c.Assert(unused[0], jc.DeepEquals, binarystorage.Metadata{
+		Version: "1.24.5-trusty-amd64",
+		Size:    19,
+		SHA256:  "hash(1.24.5-trusty-amd64)",
+	})
+
+	unused, err = s.State.UnusedAgentBinaries(2)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(metadataVersions(unused), gc.HasLen, 0)
+}
+
+func (s *toolsGCSuite) TestUnusedAgentBinariesInvalidKeepLatest(c *gc.C) {
+	_, err := s.State.UnusedAgentBinaries(0)
+	c.Assert(err, jc.Satisfies, errors.IsNotValid)
+	c.Assert(err, gc.ErrorMatches, "keeping 0 latest agent versions not valid")
+}
+
+func (s *toolsGCSuite) TestUnusedAgentBinariesHostedModelReferences(c *gc.C) {
+	// The controller's tools storage is shared by all models, so
+	// versions in use by hosted models must be retained.
+	s.addAgentBinaries(c, s.State, "1.25.0-trusty-amd64", "1.25.1-trusty-amd64")
+	st := s.NewStateForModelNamed(c, "hosted")
+	s.addMachineRunning(c, st, "1.25.0-trusty-amd64")
+
+	unused, err := s.State.UnusedAgentBinaries(1)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(unused, gc.HasLen, 0)
+}
+
+func (s *toolsGCSuite) TestUnusedAgentBinariesHostedModel(c *gc.C) {
+	// A hosted model only considers its own tools storage.
+	s.addAgentBinaries(c, s.State, "1.25.0-trusty-amd64")
+	st := s.NewStateForModelNamed(c, "hosted")
+	s.addAgentBinaries(c, st, "1.26.0-trusty-amd64", "1.26.1-trusty-amd64")
+
+	unused, err := st.UnusedAgentBinaries(1)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(metadataVersions(unused), jc.DeepEquals, []string{"1.26.0-trusty-amd64"})
+}
+
+func (s *toolsGCSuite) TestRemoveUnusedAgentBinaries(c *gc.C) {
+	s.addAgentBinaries(c, s.State,
+		"1.25.0-trusty-amd64",
+		"1.25.1-trusty-amd64",
+		"1.25.2-trusty-amd64",
+	)
+	s.addMachineRunning(c, s.State, "1.25.0-trusty-amd64")
+
+	removed, err := s.State.RemoveUnusedAgentBinaries(1)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(metadataVersions(removed), jc.DeepEquals, []string{"1.25.1-trusty-amd64"})
+
+	storage, err := s.State.ToolsStorage()
+	c.Assert(err, jc.ErrorIsNil)
+	defer storage.Close()
+	_, err = storage.Metadata("1.25.1-trusty-amd64")
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+	all, err := storage.AllMetadata()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(metadataVersions(all), jc.SameContents, []string{
+		"1.25.0-trusty-amd64",
+		"1.25.2-trusty-amd64",
+	})
+
+	removed, err = s.State.RemoveUnusedAgentBinaries(1)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(removed, gc.HasLen, 0)
+}
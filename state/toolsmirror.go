@@ -0,0 +1,48 @@
+// Copyright 2016 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package state
+
+import (
+	"bytes"
+	"io"
+	"path"
+
+	"github.com/juju/errors"
+
+	"github.com/juju/juju/state/storage"
+)
+
+// toolsMirrorStoragePath is the path in the model's blob storage
+// beneath which the tools mirror metadata files are stored.
+const toolsMirrorStoragePath = "tools-mirror"
+
+// SetToolsMirrorMetadata stores the simplestreams metadata files of the
+// model's tools mirror, which describe the agent binaries in the model's
+// tools storage. The files are keyed by their paths relative to the root
+// of the mirror, and replace any existing files with the same paths.
+func (st *State) SetToolsMirrorMetadata(files map[string][]byte) error {
+	stor := storage.NewStorage(st.ModelUUID(), st.MongoSession())
+	for filePath, data := range files {
+		storagePath := path.Join(toolsMirrorStoragePath, filePath)
+		if err := stor.Put(storagePath, bytes.NewReader(data), int64(len(data))); err != nil {
+			return errors.Annotatef(err, "cannot store tools mirror metadata %q", filePath)
+		}
+	}
+	return nil
+}
+
+// ToolsMirrorMetadata returns the contents and length of the model's
+// tools mirror metadata file with the given path, relative to the root
+// of the mirror. If there is no such file, an error satisfying
+// errors.IsNotFound is returned.
+func (st *State) ToolsMirrorMetadata(filePath string) (io.ReadCloser, int64, error) {
+	stor := storage.NewStorage(st.ModelUUID(), st.MongoSession())
+	r, length, err := stor.Get(path.Join(toolsMirrorStoragePath, filePath))
+	if errors.IsNotFound(err) {
+		return nil, -1, errors.NotFoundf("tools mirror metadata %q", filePath)
+	} else if err != nil {
+		return nil, -1, errors.Annotatef(err, "cannot get tools mirror metadata %q", filePath)
+	}
+	return r, length, nil
+}
@@ -0,0 +1,63 @@
+// Copyright 2016 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package state_test
+
+import (
+	"io/ioutil"
+
+	"github.com/juju/errors"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+)
+
+type ToolsMirrorSuite struct {
+	ConnSuite
+}
+
+var _ = gc.Suite(&ToolsMirrorSuite{})
+
+func (s *ToolsMirrorSuite) assertMetadata(c *gc.C, path, expect string) {
+	r, length, err := s.State.ToolsMirrorMetadata(path)
+	c.Assert(err, jc.ErrorIsNil)
+	defer r.Close()
+	data, err := ioutil.ReadAll(r)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(string(data), gc.Equals, expect)
+	c.Assert(length, gc.Equals, int64(len(expect)))
+}
+
+func (s *ToolsMirrorSuite) TestSetToolsMirrorMetadata(c *gc.C) {
+	err := s.State.SetToolsMirrorMetadata(map[string][]byte{
+		"streams/v1/index2.json":   []byte("index"),
+		"streams/v1/products.json": []byte("products"),
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	s.assertMetadata(c, "streams/v1/index2.json", "index")
+	s.assertMetadata(c, "streams/v1/products.json", "products")
+
+	err = s.State.SetToolsMirrorMetadata(map[string][]byte{
+		"streams/v1/index2.json": []byte("new index"),
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	s.assertMetadata(c, "streams/v1/index2.json", "new index")
+	s.assertMetadata(c, "streams/v1/products.json", "products")
+}
+
+func (s *ToolsMirrorSuite) TestToolsMirrorMetadataNotFound(c *gc.C) {
+	_, _, err := s.State.ToolsMirrorMetadata("streams/v1/index2.json")
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+	c.Assert(err, gc.ErrorMatches, `tools mirror metadata "streams/v1/index2.json" not found`)
+}
+
+func (s *ToolsMirrorSuite) TestToolsMirrorMetadataPerModel(c *gc.C) {
+	err := s.State.SetToolsMirrorMetadata(map[string][]byte{
+		"streams/v1/index2.json": []byte("index"),
+	})
+	c.Assert(err, jc.ErrorIsNil)
+
+	st := s.Factory.MakeModel(c, nil)
+	defer st.Close()
+	_, _, err = st.ToolsMirrorMetadata("streams/v1/index2.json")
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+}
@@ -0,0 +1,139 @@
+// Copyright 2016 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package state
+
+import (
+	"time"
+
+	"github.com/juju/errors"
+	"gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
+
+	"github.com/juju/juju/mongo"
+)
+
+// ToolsMirrorHealth records the outcome of recent attempts by
+// the controller to fetch agent binaries from a simplestreams
+// mirror.
+type ToolsMirrorHealth struct {
+	// Mirror identifies the mirror, typically by the scheme and
+	// host of the URLs from which agent binaries are fetched.
+	Mirror string
+
+	// LastSuccess is the time of the most recent successful
+	// fetch from the mirror, or the zero time if there has
+	// never been one.
+	LastSuccess time.Time
+
+	// LastFailure is the time of the most recent failed
+	// fetch from the mirror, or the zero time if there has
+	// never been one.
+	LastFailure time.Time
+
+	// LastError is the error message of the most recent
+	// failed fetch from the mirror.
+	LastError string
+
+	// ConsecutiveFailures is the number of fetches from the
+	// mirror that have failed since the last successful one.
+	ConsecutiveFailures int
+}
+
+type toolsMirrorDoc struct {
+	Mirror              string `bson:"_id"`
+	LastSuccess         int64  `bson:"last-success"`
+	LastFailure         int64  `bson:"last-failure"`
+	LastError           string `bson:"last-error"`
+	ConsecutiveFailures int    `bson:"consecutive-failures"`
+}
+
+func (doc *toolsMirrorDoc) health() ToolsMirrorHealth {
+	health := ToolsMirrorHealth{
+		Mirror:              doc.Mirror,
+		LastError:           doc.LastError,
+		ConsecutiveFailures: doc.ConsecutiveFailures,
+	}
+	if doc.LastSuccess != 0 {
+		health.LastSuccess = time.Unix(0, doc.LastSuccess).UTC()
+	}
+	if doc.LastFailure != 0 {
+		health.LastFailure = time.Unix(0, doc.LastFailure).UTC()
+	}
+	return health
+}
+
+// RecordToolsMirrorSuccess records that agent binaries were
+// successfully fetched from the specified mirror, resetting
+// its count of consecutive failures.
+func (st *State) RecordToolsMirrorSuccess(mirror string) error {
+	coll, closer := st.toolsMirrorsCollection()
+	defer closer()
+	_, err := coll.UpsertId(mirror, bson.D{{"$set", bson.D{
+		{"last-success", st.clock.Now().UnixNano()},
+		{"consecutive-failures", 0},
+	}}})
+	if err != nil {
+		return errors.Annotatef(err, "cannot record success for tools mirror %q", mirror)
+	}
+	return nil
+}
+
+// RecordToolsMirrorFailure records that an attempt to fetch agent
+// binaries from the specified mirror failed with the given error.
+func (st *State) RecordToolsMirrorFailure(mirror string, cause error) error {
+	coll, closer := st.toolsMirrorsCollection()
+	defer closer()
+	var message string
+	if cause != nil {
+		message = cause.Error()
+	}
+	_, err := coll.UpsertId(mirror, bson.D{
+		{"$set", bson.D{
+			{"last-failure", st.clock.Now().UnixNano()},
+			{"last-error", message},
+		}},
+		{"$inc", bson.D{{"consecutive-failures", 1}}},
+	})
+	if err != nil {
+		return errors.Annotatef(err, "cannot record failure for tools mirror %q", mirror)
+	}
+	return nil
+}
+
+// ToolsMirrorHealth returns the recorded health of the specified
+// tools mirror. If nothing has been recorded for the mirror, an
+// error satisfying errors.IsNotFound is returned.
+func (st *State) ToolsMirrorHealth(mirror string) (ToolsMirrorHealth, error) {
+	coll, closer := st.toolsMirrorsCollection()
+	defer closer()
+	var doc toolsMirrorDoc
+	err := coll.FindId(mirror).One(&doc)
+	if err == mgo.ErrNotFound {
+		return ToolsMirrorHealth{}, errors.NotFoundf("tools mirror %q", mirror)
+	} else if err != nil {
+		return ToolsMirrorHealth{}, errors.Annotatef(err, "cannot get health of tools mirror %q", mirror)
+	}
+	return doc.health(), nil
+}
+
+// AllToolsMirrorHealth returns the recorded health of all
+// tools mirrors, ordered by mirror.
+func (st *State) AllToolsMirrorHealth() ([]ToolsMirrorHealth, error) {
+	coll, closer := st.toolsMirrorsCollection()
+	defer closer()
+	var docs []toolsMirrorDoc
+	if err := coll.Find(nil).Sort("_id").All(&docs); err != nil {
+		return nil, errors.Annotate(err, "cannot get health of tools mirrors")
+	}
+	result := make([]ToolsMirrorHealth, len(docs))
+	for i, doc := range docs {
+		result[i] = doc.health()
+	}
+	return result, nil
+}
+
+func (st *State) toolsMirrorsCollection() (mongo.WriteCollection, func()) {
+	coll, closer := st.getCollection(toolsMirrorsC)
+	return coll.Writeable(), closer
+}
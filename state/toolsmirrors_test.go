@@ -0,0 +1,79 @@
+// Copyright 2016 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package state_test
+
+import (
+	"time"
+
+	"github.com/juju/errors"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/state"
+	statetesting "github.com/juju/juju/state/testing"
+)
+
+type toolsMirrorsSuite struct {
+	statetesting.StateSuite
+}
+
+var _ = gc.Suite(&toolsMirrorsSuite{})
+
+func (s *toolsMirrorsSuite) TestToolsMirrorHealthNotFound(c *gc.C) {
+	_, err := s.State.ToolsMirrorHealth("https://streams.example.com")
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+	c.Assert(err, gc.ErrorMatches, `tools mirror "https://streams.example.com" not found`)
+}
+
+func (s *toolsMirrorsSuite) TestRecordToolsMirrorFailure(c *gc.C) {
+	const mirror = "https://streams.example.com"
+	err := s.State.RecordToolsMirrorFailure(mirror, errors.New("boom"))
+	c.Assert(err, jc.ErrorIsNil)
+	s.Clock.Advance(time.Minute)
+	err = s.State.RecordToolsMirrorFailure(mirror, errors.New("splat"))
+	c.Assert(err, jc.ErrorIsNil)
+
+	health, err := s.State.ToolsMirrorHealth(mirror)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(health, jc.DeepEquals, state.ToolsMirrorHealth{
+		Mirror:              mirror,
+		LastFailure:         s.Clock.Now().UTC(),
+		LastError:           "splat",
+		ConsecutiveFailures: 2,
+	})
+}
+
+func (s *toolsMirrorsSuite) TestRecordToolsMirrorSuccessResetsFailures(c *gc.C) {
+	const mirror = "https://streams.example.com"
+	err := s.State.RecordToolsMirrorFailure(mirror, errors.New("boom"))
+	c.Assert(err, jc.ErrorIsNil)
+	failed := s.Clock.Now().UTC()
+	s.Clock.Advance(time.Minute)
+	err = s.State.RecordToolsMirrorSuccess(mirror)
+	c.Assert(err, jc.ErrorIsNil)
+
+	health, err := s.State.ToolsMirrorHealth(mirror)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(health, jc.DeepEquals, state.ToolsMirrorHealth{
+		Mirror:      mirror,
+		LastSuccess: s.Clock.Now().UTC(),
+		LastFailure: failed,
+		LastError:   "boom",
+	})
+}
+
+func (s *toolsMirrorsSuite) TestAllToolsMirrorHealth(c *gc.C) {
+	err := s.State.RecordToolsMirrorSuccess("https://b.example.com")
+	c.Assert(err, jc.ErrorIsNil)
+	err = s.State.RecordToolsMirrorFailure("https://a.example.com", errors.New("boom"))
+	c.Assert(err, jc.ErrorIsNil)
+
+	all, err := s.State.AllToolsMirrorHealth()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(all, gc.HasLen, 2)
+	c.Assert(all[0].Mirror, gc.Equals, "https://a.example.com")
+	c.Assert(all[0].ConsecutiveFailures, gc.Equals, 1)
+	c.Assert(all[1].Mirror, gc.Equals, "https://b.example.com")
+	c.Assert(all[1].ConsecutiveFailures, gc.Equals, 0)
+}
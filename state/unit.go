@@ -212,13 +212,48 @@ func (u *Unit) SetWorkloadVersion(version string) error {
 	// want to avoid everything being an attr of the main docs to
 	// stop a swarm of watchers being notified for irrelevant changes.
 	now := u.st.clock.Now()
-	return setStatus(u.st, setStatusParams{
+	if err := setStatus(u.st, setStatusParams{
 		badge:     "workload",
 		globalKey: u.globalWorkloadVersionKey(),
 		status:    status.Active,
 		message:   version,
 		updated:   &now,
-	})
+	}); err != nil {
+		return errors.Trace(err)
+	}
+	return errors.Trace(u.setApplicationWorkloadVersion(version))
+}
+
+// setApplicationWorkloadVersion records the given version, most
+// recently reported by the unit, as the workload version of the
+// unit's application. Versions change rarely, so the application
+// document is only written, and its watchers notified, when the
+// version differs from the one recorded.
+func (u *Unit) setApplicationWorkloadVersion(version string) error {
+	app, err := u.Application()
+	if err != nil {
+		return errors.Trace(err)
+	}
+	// The most recently reported version wins, so there is
+	// no need to assert the application's current version.
+	buildTxn := func(attempt int) ([]txn.Op, error) {
+		if attempt > 0 {
+			if err := app.Refresh(); err != nil {
+				return nil, errors.Trace(err)
+			}
+		}
+		if app.doc.WorkloadVersion == version {
+			return nil, jujutxn.ErrNoOperations
+		}
+		return []txn.Op{{
+			C:      applicationsC,
+			Id:     app.doc.DocID,
+			Assert: txn.DocExists,
+			Update: bson.D{{"$set", bson.D{{"workload-version", version}}}},
+		}}, nil
+	}
+	err = u.st.run(buildTxn)
+	return errors.Annotatef(err, "cannot set workload version of application %q", app.Name())
 }
 
 // WorkloadVersionHistory returns a HistoryGetter which enables the
@@ -389,6 +424,7 @@ func (u *Unit) destroyOps() ([]txn.Op, error) {
 	// the number of tests that have to change and defer that improvement to
 	// its own CL.
 	minUnitsOp := minUnitsTriggerOp(u.st, u.ApplicationName())
+	scaleOp := scaleTriggerOp(u.st, u.ApplicationName())
 	cleanupOp := newCleanupOp(cleanupDyingUnit, u.doc.Name)
 	setDyingOp := txn.Op{
 		C:      unitsC,
@@ -396,7 +432,7 @@ func (u *Unit) destroyOps() ([]txn.Op, error) {
 		Assert: isAliveDoc,
 		Update: bson.D{{"$set", bson.D{{"life", Dying}}}},
 	}
-	setDyingOps := []txn.Op{setDyingOp, cleanupOp, minUnitsOp}
+	setDyingOps := []txn.Op{setDyingOp, cleanupOp, minUnitsOp, scaleOp}
 	if u.doc.Principal != "" {
 		return setDyingOps, nil
 	} else if len(u.doc.Subordinates)+u.doc.StorageAttachmentCount != 0 {
@@ -420,7 +456,7 @@ func (u *Unit) destroyOps() ([]txn.Op, error) {
 		C:      statusesC,
 		Id:     u.st.docID(agentStatusDocId),
 		Assert: bson.D{{"status", status.Allocating}},
-	}, minUnitsOp}
+	}, minUnitsOp, scaleOp}
 	removeAsserts := append(isAliveDoc, bson.DocElem{
 		"$and", []bson.D{
 			unitHasNoSubordinates,
@@ -884,17 +920,27 @@ func (u *Unit) Status() (status.StatusInfo, error) {
 // the effort to separate Unit from UnitAgent. Now the SetStatus for UnitAgent is in
 // the UnitAgent struct.
 func (u *Unit) SetStatus(unitStatus status.StatusInfo) error {
+	params, err := u.statusParams(unitStatus)
+	if err != nil {
+		return err
+	}
+	return setStatus(u.st, params)
+}
+
+// statusParams validates the given workload status, and returns
+// the parameters with which to set it on the unit.
+func (u *Unit) statusParams(unitStatus status.StatusInfo) (setStatusParams, error) {
 	if !status.ValidWorkloadStatus(unitStatus.Status) {
-		return errors.Errorf("cannot set invalid status %q", unitStatus.Status)
+		return setStatusParams{}, errors.Errorf("cannot set invalid status %q", unitStatus.Status)
 	}
-	return setStatus(u.st, setStatusParams{
+	return setStatusParams{
 		badge:     "unit",
 		globalKey: u.globalKey(),
 		status:    unitStatus.Status,
 		message:   unitStatus.Message,
 		rawData:   unitStatus.Data,
 		updated:   unitStatus.Since,
-	})
+	}, nil
 }
 
 // OpenPortsOnSubnet opens the given port range and protocol for the unit on the
@@ -1793,3 +1793,58 @@ func (s *UnitSuite) TestWorkloadVersion(c *gc.C) {
 	c.Assert(err, jc.ErrorIsNil)
 	c.Check(version, gc.Equals, "3.combined")
 }
+
+func (s *UnitSuite) TestApplicationWorkloadVersion(c *gc.C) {
+	ch := state.AddTestingCharm(c, s.State, "dummy")
+	app := state.AddTestingService(c, s.State, "alexandrite", ch)
+	unit0, err := app.AddUnit()
+	c.Assert(err, jc.ErrorIsNil)
+	unit1, err := app.AddUnit()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(app.WorkloadVersion(), gc.Equals, "")
+
+	err = unit0.SetWorkloadVersion("3.combined")
+	c.Assert(err, jc.ErrorIsNil)
+	err = app.Refresh()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(app.WorkloadVersion(), gc.Equals, "3.combined")
+
+	// The application's version is the one most recently
+	// reported by any of its units.
+	err = unit1.SetWorkloadVersion("4.separated")
+	c.Assert(err, jc.ErrorIsNil)
+	err = app.Refresh()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(app.WorkloadVersion(), gc.Equals, "4.separated")
+
+	version, err := unit0.WorkloadVersion()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(version, gc.Equals, "3.combined")
+}
+
+func (s *UnitSuite) TestWatchWorkloadVersion(c *gc.C) {
+	w := s.unit.WatchWorkloadVersion()
+	defer testing.AssertStop(c, w)
+
+	// Initial event.
+	wc := testing.NewNotifyWatcherC(c, s.State, w)
+	wc.AssertOneChange()
+
+	err := s.unit.SetWorkloadVersion("1.0")
+	c.Assert(err, jc.ErrorIsNil)
+	wc.AssertOneChange()
+
+	// Changes to other statuses of the unit are not reported.
+	now := coretesting.NonZeroTime()
+	err = s.unit.SetStatus(status.StatusInfo{
+		Status:  status.Active,
+		Message: "ready",
+		Since:   &now,
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	wc.AssertNoChange()
+
+	err = s.unit.SetWorkloadVersion("1.1")
+	c.Assert(err, jc.ErrorIsNil)
+	wc.AssertOneChange()
+}
@@ -57,25 +57,35 @@ func (u *UnitAgent) Status() (status.StatusInfo, error) {
 // SetStatus sets the status of the unit agent. The optional values
 // allow to pass additional helpful status data.
 func (u *UnitAgent) SetStatus(unitAgentStatus status.StatusInfo) (err error) {
+	params, err := u.statusParams(unitAgentStatus)
+	if err != nil {
+		return err
+	}
+	return setStatus(u.st, params)
+}
+
+// statusParams validates the given agent status, and returns the
+// parameters with which to set it on the unit agent.
+func (u *UnitAgent) statusParams(unitAgentStatus status.StatusInfo) (setStatusParams, error) {
 	switch unitAgentStatus.Status {
 	case status.Idle, status.Executing, status.Rebooting, status.Failed:
 	case status.Error:
 		if unitAgentStatus.Message == "" {
-			return errors.Errorf("cannot set status %q without info", unitAgentStatus.Status)
+			return setStatusParams{}, errors.Errorf("cannot set status %q without info", unitAgentStatus.Status)
 		}
 	case status.Allocating, status.Lost:
-		return errors.Errorf("cannot set status %q", unitAgentStatus.Status)
+		return setStatusParams{}, errors.Errorf("cannot set status %q", unitAgentStatus.Status)
 	default:
-		return errors.Errorf("cannot set invalid status %q", unitAgentStatus.Status)
+		return setStatusParams{}, errors.Errorf("cannot set invalid status %q", unitAgentStatus.Status)
 	}
-	return setStatus(u.st, setStatusParams{
+	return setStatusParams{
 		badge:     "agent",
 		globalKey: u.globalKey(),
 		status:    unitAgentStatus.Status,
 		message:   unitAgentStatus.Message,
 		rawData:   unitAgentStatus.Data,
 		updated:   unitAgentStatus.Since,
-	})
+	}, nil
 }
 
 // StatusHistory returns a slice of at most filter.Size StatusInfo items
@@ -4,6 +4,8 @@
 package state
 
 import (
+	"time"
+
 	"github.com/juju/errors"
 	"github.com/juju/loggo"
 	"gopkg.in/juju/names.v2"
@@ -247,3 +249,60 @@ func addDefaultBindingsToServices(st *State) error {
 func AddDefaultEndpointBindingsToServices(st *State) error {
 	return runForAllEnvStates(st, addDefaultBindingsToServices)
 }
+
+// AddApplicationWorkloadVersions records the workload version of each
+// application that does not yet have one, as the version most recently
+// reported by any of its units.
+func AddApplicationWorkloadVersions(st *State) error {
+	return runForAllEnvStates(st, addApplicationWorkloadVersions)
+}
+
+func addApplicationWorkloadVersions(st *State) error {
+	applications, err := st.AllApplications()
+	if err != nil {
+		return errors.Trace(err)
+	}
+
+	upgradesLogger.Debugf("adding workload versions to applications (where missing)")
+	for _, application := range applications {
+		if application.doc.WorkloadVersion != "" {
+			continue
+		}
+		units, err := application.AllUnits()
+		if err != nil {
+			return errors.Annotatef(err, "cannot get units of application %q", application.Name())
+		}
+		var version string
+		var since time.Time
+		for _, unit := range units {
+			info, err := getStatus(st, unit.globalWorkloadVersionKey(), "workload")
+			if errors.IsNotFound(err) {
+				continue
+			} else if err != nil {
+				return errors.Annotatef(err, "cannot get workload version of unit %q", unit.Name())
+			}
+			if info.Message == "" || info.Since == nil || !info.Since.After(since) {
+				continue
+			}
+			version = info.Message
+			since = *info.Since
+		}
+		if version == "" {
+			continue
+		}
+		err = st.runTransaction([]txn.Op{{
+			C:      applicationsC,
+			Id:     application.doc.DocID,
+			Assert: bson.D{{"workload-version", bson.D{{"$exists", false}}}},
+			Update: bson.D{{"$set", bson.D{{"workload-version", version}}}},
+		}})
+		if err == txn.ErrAborted {
+			// The application has been removed, or one of its
+			// units has since reported a more recent version.
+			continue
+		} else if err != nil {
+			return errors.Annotatef(err, "setting workload version of application %q", application.Name())
+		}
+	}
+	return nil
+}
@@ -4,6 +4,8 @@
 package state
 
 import (
+	"time"
+
 	"github.com/juju/errors"
 	jc "github.com/juju/testing/checkers"
 	gc "gopkg.in/check.v1"
@@ -584,3 +586,47 @@ func (s *upgradesSuite) TestAddDefaultEndpointBindingsToServices(c *gc.C) {
 func (s *upgradesSuite) TestAddDefaultEndpointBindingsToServicesIdempotent(c *gc.C) {
 	s.testAddDefaultEndpointBindingsToServices(c, true)
 }
+
+func (s *upgradesSuite) TestAddApplicationWorkloadVersions(c *gc.C) {
+	ch := AddTestingCharm(c, s.state, "wordpress")
+	app := AddTestingService(c, s.state, "wordpress", ch)
+	unversioned := AddTestingService(c, s.state, "unversioned", ch)
+	u0, err := app.AddUnit()
+	c.Assert(err, jc.ErrorIsNil)
+	u1, err := app.AddUnit()
+	c.Assert(err, jc.ErrorIsNil)
+	_, err = unversioned.AddUnit()
+	c.Assert(err, jc.ErrorIsNil)
+
+	// Record the units' versions as they were before applications
+	// recorded their versions.
+	setVersion := func(u *Unit, version string, updated time.Time) {
+		err := setStatus(s.state, setStatusParams{
+			badge:     "workload",
+			globalKey: u.globalWorkloadVersionKey(),
+			status:    status.Active,
+			message:   version,
+			updated:   &updated,
+		})
+		c.Assert(err, jc.ErrorIsNil)
+	}
+	now := testing.ZeroTime()
+	setVersion(u0, "1.1", now.Add(time.Minute))
+	setVersion(u1, "1.0", now)
+
+	assertVersion := func(app *Application, expect string) {
+		err := app.Refresh()
+		c.Assert(err, jc.ErrorIsNil)
+		c.Assert(app.WorkloadVersion(), gc.Equals, expect)
+	}
+	err = AddApplicationWorkloadVersions(s.state)
+	c.Assert(err, jc.ErrorIsNil)
+	assertVersion(app, "1.1")
+	assertVersion(unversioned, "")
+
+	// Versions already recorded are left alone.
+	setVersion(u1, "1.2", now.Add(2*time.Minute))
+	err = AddApplicationWorkloadVersions(s.state)
+	c.Assert(err, jc.ErrorIsNil)
+	assertVersion(app, "1.1")
+}
@@ -439,7 +439,7 @@ func (st *State) removeMachineVolumesOps(machine names.MachineTag) ([]txn.Op, er
 			Id:     volumeTag.Id(),
 			Assert: txn.DocExists,
 			Remove: true,
-		})
+		}, removeStorageClaimOp(volumeGlobalKey(volumeTag.Id())))
 	}
 	return ops, nil
 }
@@ -705,6 +705,7 @@ func (st *State) RemoveVolume(tag names.VolumeTag) (err error) {
 				Remove: true,
 			},
 			removeStatusOp(st, volumeGlobalKey(tag.Id())),
+			removeStorageClaimOp(volumeGlobalKey(tag.Id())),
 		}, nil
 	}
 	return st.run(buildTxn)
@@ -4,6 +4,8 @@
 package state_test
 
 import (
+	"time"
+
 	"github.com/juju/errors"
 	jc "github.com/juju/testing/checkers"
 	"github.com/juju/utils/set"
@@ -628,6 +630,27 @@ func (s *VolumeStateSuite) TestDetachVolume(c *gc.C) {
 	assertDetach()
 }
 
+func (s *VolumeStateSuite) TestMachineAttachmentLives(c *gc.C) {
+	volume, machine := s.setupVolumeAttachment(c)
+	err := s.State.DetachVolume(machine.MachineTag(), volume.VolumeTag())
+	c.Assert(err, jc.ErrorIsNil)
+
+	attached := state.MachineAttachmentId{machine.MachineTag(), volume.VolumeTag()}
+	missing := state.MachineAttachmentId{machine.MachineTag(), names.NewVolumeTag("42")}
+	lives, err := s.State.MachineAttachmentLives([]state.MachineAttachmentId{attached, missing})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(lives, jc.DeepEquals, map[state.MachineAttachmentId]state.Life{
+		attached: state.Dying,
+	})
+}
+
+func (s *VolumeStateSuite) TestMachineAttachmentLivesInvalidTag(c *gc.C) {
+	_, err := s.State.MachineAttachmentLives([]state.MachineAttachmentId{{
+		names.NewMachineTag("0"), names.NewMachineTag("1"),
+	}})
+	c.Assert(err, gc.ErrorMatches, `attachment tag "machine-1" not valid`)
+}
+
 func (s *VolumeStateSuite) TestRemoveLastVolumeAttachment(c *gc.C) {
 	volume, machine := s.setupVolumeAttachment(c)
 
@@ -864,6 +887,165 @@ func (s *VolumeStateSuite) TestVolumeBindingStorage(c *gc.C) {
 	c.Assert(volume.Life(), gc.Equals, state.Dying)
 }
 
+func (s *VolumeStateSuite) TestClaimStorageEntity(c *gc.C) {
+	volume, machine := s.setupVolumeAttachment(c)
+	modelTag := s.State.ModelTag()
+
+	_, err := s.State.StorageEntityClaim(volume.VolumeTag())
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+
+	err = s.State.ClaimStorageEntity(volume.VolumeTag(), modelTag)
+	c.Assert(err, jc.ErrorIsNil)
+	// Claims are re-entrant for the same scope.
+	err = s.State.ClaimStorageEntity(volume.VolumeTag(), modelTag)
+	c.Assert(err, jc.ErrorIsNil)
+
+	err = s.State.ClaimStorageEntity(volume.VolumeTag(), machine.MachineTag())
+	c.Assert(err, jc.Satisfies, state.IsStorageClaimedError)
+	c.Assert(err, gc.ErrorMatches, "cannot claim volume 0/0: volume 0/0 is claimed by model .*")
+	err = s.State.ReleaseStorageEntity(volume.VolumeTag(), machine.MachineTag())
+	c.Assert(err, jc.Satisfies, state.IsStorageClaimedError)
+
+	err = s.State.ReleaseStorageEntity(volume.VolumeTag(), modelTag)
+	c.Assert(err, jc.ErrorIsNil)
+	err = s.State.ClaimStorageEntity(volume.VolumeTag(), machine.MachineTag())
+	c.Assert(err, jc.ErrorIsNil)
+
+	claim, err := s.State.StorageEntityClaim(volume.VolumeTag())
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(claim.Owner, gc.Equals, machine.MachineTag())
+	c.Assert(claim.History, gc.HasLen, 3)
+	expect := []struct {
+		owner  names.Tag
+		action state.StorageClaimAction
+	}{
+		{modelTag, state.StorageClaimed},
+		{modelTag, state.StorageReleased},
+		{machine.MachineTag(), state.StorageClaimed},
+	}
+	for i, event := range claim.History {
+		c.Check(event.Owner, gc.Equals, expect[i].owner)
+		c.Check(event.Action, gc.Equals, expect[i].action)
+	}
+	c.Assert(claim.Since, gc.Equals, claim.History[2].Time)
+}
+
+func (s *VolumeStateSuite) TestReleaseStorageEntityUnclaimed(c *gc.C) {
+	volume, _ := s.setupVolumeAttachment(c)
+	err := s.State.ReleaseStorageEntity(volume.VolumeTag(), s.State.ModelTag())
+	c.Assert(err, jc.ErrorIsNil)
+	_, err = s.State.StorageEntityClaim(volume.VolumeTag())
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+}
+
+func (s *VolumeStateSuite) TestClaimStorageEntityNotFound(c *gc.C) {
+	err := s.State.ClaimStorageEntity(names.NewVolumeTag("42"), s.State.ModelTag())
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+}
+
+func (s *VolumeStateSuite) TestClaimStorageEntityInvalid(c *gc.C) {
+	volume, _ := s.setupVolumeAttachment(c)
+	err := s.State.ClaimStorageEntity(names.NewStorageTag("data/0"), s.State.ModelTag())
+	c.Assert(err, jc.Satisfies, errors.IsNotValid)
+	err = s.State.ClaimStorageEntity(volume.VolumeTag(), names.NewUnitTag("foo/0"))
+	c.Assert(err, jc.Satisfies, errors.IsNotValid)
+}
+
+func (s *VolumeStateSuite) TestRemoveVolumeRemovesClaim(c *gc.C) {
+	volume, machine := s.setupVolumeAttachment(c)
+	err := s.State.ClaimStorageEntity(volume.VolumeTag(), s.State.ModelTag())
+	c.Assert(err, jc.ErrorIsNil)
+	err = s.State.DestroyVolume(volume.VolumeTag())
+	c.Assert(err, jc.ErrorIsNil)
+	err = s.State.DetachVolume(machine.MachineTag(), volume.VolumeTag())
+	c.Assert(err, jc.ErrorIsNil)
+	err = s.State.RemoveVolumeAttachment(machine.MachineTag(), volume.VolumeTag())
+	c.Assert(err, jc.ErrorIsNil)
+	err = s.State.RemoveVolume(volume.VolumeTag())
+	c.Assert(err, jc.ErrorIsNil)
+	_, err = s.State.StorageEntityClaim(volume.VolumeTag())
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+}
+
+func (s *VolumeStateSuite) TestClaimStorageEntityExpires(c *gc.C) {
+	volume, machine := s.setupVolumeAttachment(c)
+	modelTag := s.State.ModelTag()
+
+	err := s.State.ClaimStorageEntity(volume.VolumeTag(), modelTag)
+	c.Assert(err, jc.ErrorIsNil)
+	claim, err := s.State.StorageEntityClaim(volume.VolumeTag())
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(claim.Expires, gc.Equals, claim.Since.Add(time.Hour))
+
+	s.Clock.Advance(time.Hour - time.Second)
+	err = s.State.ClaimStorageEntity(volume.VolumeTag(), machine.MachineTag())
+	c.Assert(err, jc.Satisfies, state.IsStorageClaimedError)
+
+	s.Clock.Advance(time.Second)
+	err = s.State.ClaimStorageEntity(volume.VolumeTag(), machine.MachineTag())
+	c.Assert(err, jc.ErrorIsNil)
+
+	claim, err = s.State.StorageEntityClaim(volume.VolumeTag())
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(claim.Owner, gc.Equals, machine.MachineTag())
+	c.Assert(claim.History, gc.HasLen, 3)
+	c.Check(claim.History[1].Owner, gc.Equals, modelTag)
+	c.Check(claim.History[1].Action, gc.Equals, state.StorageClaimExpired)
+	c.Check(claim.History[2].Owner, gc.Equals, machine.MachineTag())
+	c.Check(claim.History[2].Action, gc.Equals, state.StorageClaimed)
+}
+
+func (s *VolumeStateSuite) TestClaimStorageEntityRenews(c *gc.C) {
+	volume, machine := s.setupVolumeAttachment(c)
+	modelTag := s.State.ModelTag()
+
+	err := s.State.ClaimStorageEntity(volume.VolumeTag(), modelTag)
+	c.Assert(err, jc.ErrorIsNil)
+	s.Clock.Advance(30 * time.Minute)
+	err = s.State.ClaimStorageEntity(volume.VolumeTag(), modelTag)
+	c.Assert(err, jc.ErrorIsNil)
+
+	// The claim was renewed, so it has not yet expired.
+	s.Clock.Advance(45 * time.Minute)
+	err = s.State.ClaimStorageEntity(volume.VolumeTag(), machine.MachineTag())
+	c.Assert(err, jc.Satisfies, state.IsStorageClaimedError)
+
+	claim, err := s.State.StorageEntityClaim(volume.VolumeTag())
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(claim.History, gc.HasLen, 1)
+	c.Assert(claim.Expires, gc.Equals, claim.Since.Add(90*time.Minute))
+}
+
+func (s *VolumeStateSuite) TestRemoveMachineReleasesClaims(c *gc.C) {
+	_, err := s.State.AddOneMachine(state.MachineTemplate{
+		Series: "quantal",
+		Jobs:   []state.MachineJob{state.JobHostUnits},
+		Volumes: []state.MachineVolumeParams{{
+			Volume: state.VolumeParams{Pool: "environscoped", Size: 1024},
+		}},
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	machine, err := s.State.AddMachine("quantal", state.JobHostUnits)
+	c.Assert(err, jc.ErrorIsNil)
+	volumeTag := names.NewVolumeTag("0")
+	err = s.State.ClaimStorageEntity(volumeTag, machine.MachineTag())
+	c.Assert(err, jc.ErrorIsNil)
+
+	c.Assert(machine.Destroy(), jc.ErrorIsNil)
+	c.Assert(machine.EnsureDead(), jc.ErrorIsNil)
+	c.Assert(machine.Remove(), jc.ErrorIsNil)
+
+	claim, err := s.State.StorageEntityClaim(volumeTag)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(claim.Owner, gc.IsNil)
+	c.Assert(claim.History, gc.HasLen, 2)
+	c.Assert(claim.History[1].Owner, gc.Equals, machine.MachineTag())
+	c.Assert(claim.History[1].Action, gc.Equals, state.StorageReleased)
+
+	err = s.State.ClaimStorageEntity(volumeTag, s.State.ModelTag())
+	c.Assert(err, jc.ErrorIsNil)
+}
+
 func (s *VolumeStateSuite) setupVolumeAttachment(c *gc.C) (state.Volume, *state.Machine) {
 	_, u, storageTag := s.setupSingleStorage(c, "block", "loop-pool")
 	err := s.State.AssignUnit(u, state.AssignCleanEmpty)
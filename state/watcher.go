@@ -582,18 +582,30 @@ func (w *lifecycleWatcher) loop() error {
 // watcher is the set of application names requiring a minimum number of units.
 // Subsequent events are generated when a service increases MinUnits, or when
 // one or more units belonging to a service are destroyed.
+//
+// The same watcher is used to watch the scales collection, in which case
+// events are generated for applications with a desired scale, whenever the
+// desired scale changes or a unit is added or destroyed.
 type minUnitsWatcher struct {
 	commonWatcher
-	known map[string]int
-	out   chan []string
+	collections []string
+	known       map[minUnitsKey]int
+	out         chan []string
+}
+
+// minUnitsKey identifies a document watched by a minUnitsWatcher.
+type minUnitsKey struct {
+	collection      string
+	applicationName string
 }
 
 var _ Watcher = (*minUnitsWatcher)(nil)
 
-func newMinUnitsWatcher(st *State) StringsWatcher {
+func newMinUnitsWatcher(st *State, collections ...string) StringsWatcher {
 	w := &minUnitsWatcher{
 		commonWatcher: newCommonWatcher(st),
-		known:         make(map[string]int),
+		collections:   collections,
+		known:         make(map[minUnitsKey]int),
 		out:           make(chan []string),
 	}
 	go func() {
@@ -606,38 +618,67 @@ func newMinUnitsWatcher(st *State) StringsWatcher {
 
 // WatchMinUnits returns a StringsWatcher for the minUnits collection
 func (st *State) WatchMinUnits() StringsWatcher {
-	return newMinUnitsWatcher(st)
+	return newMinUnitsWatcher(st, minUnitsC)
+}
+
+// WatchScaledApplications returns a StringsWatcher that notifies of
+// applications whose number of alive units may need to change, due to
+// either their minimum number of units or their desired scale.
+func (st *State) WatchScaledApplications() StringsWatcher {
+	return newMinUnitsWatcher(st, minUnitsC, scalesC)
+}
+
+// minUnitsWatcherDoc holds the fields common to the documents
+// watched by a minUnitsWatcher.
+type minUnitsWatcherDoc struct {
+	ApplicationName string `bson:"applicationname"`
+	Revno           int    `bson:"revno"`
 }
 
 func (w *minUnitsWatcher) initial() (set.Strings, error) {
 	applicationnames := make(set.Strings)
-	var doc minUnitsDoc
-	newMinUnits, closer := w.st.getCollection(minUnitsC)
+	for _, collection := range w.collections {
+		if err := w.initialCollection(collection, applicationnames); err != nil {
+			return nil, err
+		}
+	}
+	return applicationnames, nil
+}
+
+func (w *minUnitsWatcher) initialCollection(collection string, applicationnames set.Strings) error {
+	var doc minUnitsWatcherDoc
+	coll, closer := w.st.getCollection(collection)
 	defer closer()
 
-	iter := newMinUnits.Find(nil).Iter()
+	iter := coll.Find(nil).Iter()
 	for iter.Next(&doc) {
-		w.known[doc.ApplicationName] = doc.Revno
+		w.known[minUnitsKey{collection, doc.ApplicationName}] = doc.Revno
 		applicationnames.Add(doc.ApplicationName)
 	}
-	return applicationnames, iter.Close()
+	return iter.Close()
 }
 
 func (w *minUnitsWatcher) merge(applicationnames set.Strings, change watcher.Change) error {
 	applicationname := w.st.localID(change.Id.(string))
+	key := minUnitsKey{change.C, applicationname}
 	if change.Revno == -1 {
-		delete(w.known, applicationname)
+		delete(w.known, key)
+		for _, collection := range w.collections {
+			if _, ok := w.known[minUnitsKey{collection, applicationname}]; ok {
+				return nil
+			}
+		}
 		applicationnames.Remove(applicationname)
 		return nil
 	}
-	doc := minUnitsDoc{}
-	newMinUnits, closer := w.st.getCollection(minUnitsC)
+	doc := minUnitsWatcherDoc{}
+	coll, closer := w.st.getCollection(change.C)
 	defer closer()
-	if err := newMinUnits.FindId(change.Id).One(&doc); err != nil {
+	if err := coll.FindId(change.Id).One(&doc); err != nil {
 		return err
 	}
-	revno, known := w.known[applicationname]
-	w.known[applicationname] = doc.Revno
+	revno, known := w.known[key]
+	w.known[key] = doc.Revno
 	if !known || doc.Revno > revno {
 		applicationnames.Add(applicationname)
 	}
@@ -646,8 +687,10 @@ func (w *minUnitsWatcher) merge(applicationnames set.Strings, change watcher.Cha
 
 func (w *minUnitsWatcher) loop() (err error) {
 	ch := make(chan watcher.Change)
-	w.watcher.WatchCollectionWithFilter(minUnitsC, ch, isLocalID(w.st))
-	defer w.watcher.UnwatchCollection(minUnitsC, ch)
+	for _, collection := range w.collections {
+		w.watcher.WatchCollectionWithFilter(collection, ch, isLocalID(w.st))
+		defer w.watcher.UnwatchCollection(collection, ch)
+	}
 	applicationnames, err := w.initial()
 	if err != nil {
 		return err
@@ -1275,8 +1318,10 @@ func (s *Application) Watch() NotifyWatcher {
 // WatchLeaderSettings returns a watcher for observing changed to a service's
 // leader settings.
 func (s *Application) WatchLeaderSettings() NotifyWatcher {
+	// Leader settings are relayed to the units' hooks, so changes
+	// are not held back for the txn watcher's coalescing window.
 	docId := s.st.docID(leadershipSettingsKey(s.Name()))
-	return newEntityWatcher(s.st, settingsC, docId)
+	return newImmediateEntityWatcher(s.st, settingsC, docId)
 }
 
 // Watch returns a watcher for observing changes to a unit.
@@ -1368,15 +1413,32 @@ func (u *Unit) WatchMeterStatus() NotifyWatcher {
 	})
 }
 
+// WatchWorkloadVersion returns a watcher observing changes to the
+// version of the workload run by the unit.
+func (u *Unit) WatchWorkloadVersion() NotifyWatcher {
+	return newEntityWatcher(u.st, statusesC, u.st.docID(u.globalWorkloadVersionKey()))
+}
+
 func newEntityWatcher(st *State, collName string, key interface{}) NotifyWatcher {
 	return newDocWatcher(st, []docKey{{collName, key}})
 }
 
+// newImmediateEntityWatcher returns a watcher for the given document,
+// as for newEntityWatcher, whose changes are delivered without waiting
+// for the txn watcher's coalescing window.
+func newImmediateEntityWatcher(st *State, collName string, key interface{}) NotifyWatcher {
+	return startDocWatcher(st, []docKey{{collName, key}}, true)
+}
+
 // docWatcher watches for changes in 1 or more mongo documents
 // across collections.
 type docWatcher struct {
 	commonWatcher
 	out chan struct{}
+
+	// immediate records that changes should be delivered without
+	// waiting for the txn watcher's coalescing window.
+	immediate bool
 }
 
 var _ Watcher = (*docWatcher)(nil)
@@ -1392,9 +1454,14 @@ type docKey struct {
 // newDocWatcher returns a new docWatcher.
 // docKeys identifies the documents that should be watched (their id and which collection they are in)
 func newDocWatcher(st *State, docKeys []docKey) NotifyWatcher {
+	return startDocWatcher(st, docKeys, false)
+}
+
+func startDocWatcher(st *State, docKeys []docKey, immediate bool) NotifyWatcher {
 	w := &docWatcher{
 		commonWatcher: newCommonWatcher(st),
 		out:           make(chan struct{}),
+		immediate:     immediate,
 	}
 	go func() {
 		defer w.tomb.Done()
@@ -1435,7 +1502,11 @@ func (w *docWatcher) loop(docKeys []docKey) error {
 		if err != nil {
 			return err
 		}
-		w.watcher.Watch(coll.Name(), k.docId, txnRevno, in)
+		if w.immediate {
+			w.watcher.WatchImmediate(coll.Name(), k.docId, txnRevno, in)
+		} else {
+			w.watcher.Watch(coll.Name(), k.docId, txnRevno, in)
+		}
 		defer w.watcher.Unwatch(coll.Name(), k.docId, in)
 	}
 	out := w.out
@@ -1900,6 +1971,14 @@ type colWCfg struct {
 	col    string
 	filter func(interface{}) bool
 	idconv func(string) string
+
+	// snapshot, if true, causes the watcher to take its initial
+	// event from a snapshot of the collection delivered by the
+	// underlying watcher, rather than by reading the collection
+	// before watching it. This ensures that no change between
+	// reading the collection and starting the watch is missed,
+	// at the cost of reading the collection in the watcher.
+	snapshot bool
 }
 
 // newcollectionWatcher starts and returns a new StringsWatcher configured
@@ -1948,15 +2027,26 @@ func (w *collectionWatcher) loop() error {
 	var (
 		changes []string
 		in      = (<-chan watcher.Change)(w.source)
-		out     = (chan<- []string)(w.sink)
+		out     chan<- []string
 	)
 
-	w.watcher.WatchCollectionWithFilter(w.col, w.source, w.filter)
+	snapshotDone := !w.snapshot
+	if w.snapshot {
+		// The existing documents are delivered as a snapshot before
+		// any incremental changes; the initial event is sent once the
+		// whole snapshot has been received.
+		w.watcher.WatchCollectionWithSnapshot(w.col, w.source, w.filter)
+	} else {
+		w.watcher.WatchCollectionWithFilter(w.col, w.source, w.filter)
+	}
 	defer w.watcher.UnwatchCollection(w.col, w.source)
 
-	changes, err := w.initial()
-	if err != nil {
-		return err
+	if !w.snapshot {
+		var err error
+		if changes, err = w.initial(); err != nil {
+			return err
+		}
+		out = w.sink
 	}
 
 	for {
@@ -1970,10 +2060,16 @@ func (w *collectionWatcher) loop() error {
 			if !ok {
 				return tomb.ErrDying
 			}
+			if _, ok := updates[nil]; ok && w.snapshot {
+				// The end of the snapshot is marked by a nil id.
+				delete(updates, nil)
+				snapshotDone = true
+				out = w.sink
+			}
 			if err := w.mergeIds(&changes, updates); err != nil {
 				return err
 			}
-			if len(changes) > 0 {
+			if snapshotDone && len(changes) > 0 {
 				out = w.sink
 			}
 		case out <- changes:
@@ -1983,6 +2079,28 @@ func (w *collectionWatcher) loop() error {
 	}
 }
 
+// initial pre-loads the id's that have already been added to the
+// collection that would otherwise not normally trigger the watcher
+func (w *collectionWatcher) initial() ([]string, error) {
+	var ids []string
+	var doc struct {
+		DocId string `bson:"_id"`
+	}
+	coll, closer := w.st.getCollection(w.col)
+	defer closer()
+	iter := coll.Find(nil).Iter()
+	for iter.Next(&doc) {
+		if w.filter == nil || w.filter(doc.DocId) {
+			id := w.st.localID(doc.DocId)
+			if w.idconv != nil {
+				id = w.idconv(id)
+			}
+			ids = append(ids, id)
+		}
+	}
+	return ids, iter.Close()
+}
+
 // makeIdFilter constructs a predicate to filter keys that have the
 // prefix matching one of the passed in ActionReceivers, or returns nil
 // if tags is empty
@@ -2011,28 +2129,6 @@ func makeIdFilter(st *State, marker string, receivers ...ActionReceiver) func(in
 	}
 }
 
-// initial pre-loads the id's that have already been added to the
-// collection that would otherwise not normally trigger the watcher
-func (w *collectionWatcher) initial() ([]string, error) {
-	var ids []string
-	var doc struct {
-		DocId string `bson:"_id"`
-	}
-	coll, closer := w.st.getCollection(w.col)
-	defer closer()
-	iter := coll.Find(nil).Iter()
-	for iter.Next(&doc) {
-		if w.filter == nil || w.filter(doc.DocId) {
-			id := w.st.localID(doc.DocId)
-			if w.idconv != nil {
-				id = w.idconv(id)
-			}
-			ids = append(ids, id)
-		}
-	}
-	return ids, iter.Close()
-}
-
 // mergeIds is used for merging actionId's and actionResultId's that
 // come in via the updates map. It cleans up the pending changes to
 // account for id's being removed before the watcher consumes them,
@@ -0,0 +1,78 @@
+// Copyright 2016 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package watcher
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/juju/errors"
+)
+
+// A Decoder decodes Changes to documents in a collection into typed
+// notifications, such as machinewatcher.Change, so that consumers
+// need not interpret collection names and document ids themselves.
+type Decoder interface {
+	Decode(Change) (interface{}, error)
+}
+
+// DecoderFunc is a function that implements Decoder.
+type DecoderFunc func(Change) (interface{}, error)
+
+// Decode is part of the Decoder interface.
+func (f DecoderFunc) Decode(ch Change) (interface{}, error) {
+	return f(ch)
+}
+
+var (
+	decodersMu sync.RWMutex
+	decoders   = make(map[string]Decoder)
+)
+
+// RegisterDecoder registers the Decoder for changes to documents in
+// the named collection. Decoders are typically registered by the init
+// function of the package defining the typed notification. If a
+// Decoder is already registered for the collection, RegisterDecoder
+// panics.
+func RegisterDecoder(collection string, decoder Decoder) {
+	decodersMu.Lock()
+	defer decodersMu.Unlock()
+	if _, ok := decoders[collection]; ok {
+		panic(fmt.Sprintf("decoder for collection %q already registered", collection))
+	}
+	decoders[collection] = decoder
+}
+
+// Decode decodes the given Change using the Decoder registered for
+// the change's collection. If no Decoder is registered, an error
+// satisfying errors.IsNotFound is returned.
+func Decode(ch Change) (interface{}, error) {
+	decodersMu.RLock()
+	decoder, ok := decoders[ch.C]
+	decodersMu.RUnlock()
+	if !ok {
+		return nil, errors.NotFoundf("decoder for collection %q", ch.C)
+	}
+	result, err := decoder.Decode(ch)
+	if err != nil {
+		return nil, errors.Annotatef(err, "decoding change to %s %v", ch.C, ch.Id)
+	}
+	return result, nil
+}
+
+// ParseModelDocID parses the id of a document in a collection that is
+// shared by multiple models, returning the model UUID and the id of the
+// document within the model. Such ids have the form "<model-uuid>:<id>".
+func ParseModelDocID(id interface{}) (modelUUID, localID string, err error) {
+	s, ok := id.(string)
+	if !ok {
+		return "", "", errors.NotValidf("document id %v of type %T", id, id)
+	}
+	parts := strings.SplitN(s, ":", 2)
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return "", "", errors.NotValidf("document id %q", s)
+	}
+	return parts[0], parts[1], nil
+}
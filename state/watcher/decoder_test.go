@@ -0,0 +1,67 @@
+// Copyright 2016 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package watcher_test
+
+import (
+	"github.com/juju/errors"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/state/watcher"
+)
+
+type decoderSuite struct{}
+
+var _ = gc.Suite(&decoderSuite{})
+
+func init() {
+	watcher.RegisterDecoder("decoder-test", watcher.DecoderFunc(
+		func(ch watcher.Change) (interface{}, error) {
+			if ch.Revno == -1 {
+				return nil, errors.New("removed")
+			}
+			return ch.Id, nil
+		},
+	))
+}
+
+func (s *decoderSuite) TestDecode(c *gc.C) {
+	result, err := watcher.Decode(watcher.Change{C: "decoder-test", Id: "foo"})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(result, gc.Equals, "foo")
+}
+
+func (s *decoderSuite) TestDecodeError(c *gc.C) {
+	_, err := watcher.Decode(watcher.Change{C: "decoder-test", Id: "foo", Revno: -1})
+	c.Assert(err, gc.ErrorMatches, "decoding change to decoder-test foo: removed")
+}
+
+func (s *decoderSuite) TestDecodeNoDecoder(c *gc.C) {
+	_, err := watcher.Decode(watcher.Change{C: "no-decoder", Id: "foo"})
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+	c.Assert(err, gc.ErrorMatches, `decoder for collection "no-decoder" not found`)
+}
+
+func (s *decoderSuite) TestRegisterDecoderTwice(c *gc.C) {
+	decoder := watcher.DecoderFunc(func(watcher.Change) (interface{}, error) {
+		return nil, nil
+	})
+	c.Assert(func() {
+		watcher.RegisterDecoder("decoder-test", decoder)
+	}, gc.PanicMatches, `decoder for collection "decoder-test" already registered`)
+}
+
+func (s *decoderSuite) TestParseModelDocID(c *gc.C) {
+	modelUUID, localID, err := watcher.ParseModelDocID("uuid:0/lxd/1")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(modelUUID, gc.Equals, "uuid")
+	c.Assert(localID, gc.Equals, "0/lxd/1")
+}
+
+func (s *decoderSuite) TestParseModelDocIDInvalid(c *gc.C) {
+	for _, id := range []interface{}{"0", ":0", "uuid:", 123} {
+		_, _, err := watcher.ParseModelDocID(id)
+		c.Check(err, jc.Satisfies, errors.IsNotValid)
+	}
+}
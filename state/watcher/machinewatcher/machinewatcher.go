@@ -0,0 +1,57 @@
+// Copyright 2016 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+// Package machinewatcher decodes changes to machine documents,
+// observed by a state/watcher.Watcher, into typed notifications.
+package machinewatcher
+
+import (
+	"github.com/juju/errors"
+	"gopkg.in/juju/names.v2"
+
+	"github.com/juju/juju/state/watcher"
+)
+
+// Collection is the name of the collection holding machine documents.
+const Collection = "machines"
+
+func init() {
+	watcher.RegisterDecoder(Collection, watcher.DecoderFunc(decode))
+}
+
+// Change describes a change to a machine document.
+type Change struct {
+	// ModelUUID is the UUID of the model the machine is in.
+	ModelUUID string
+
+	// Tag is the tag of the machine that changed.
+	Tag names.MachineTag
+
+	// Removed reports whether the machine document was removed.
+	// The machine's life is not recorded in the change log, so
+	// consumers interested in it must read the machine.
+	Removed bool
+}
+
+// Decode decodes a watcher.Change to a machine document.
+func Decode(ch watcher.Change) (Change, error) {
+	if ch.C != Collection {
+		return Change{}, errors.Errorf("expected change to %s, got %s", Collection, ch.C)
+	}
+	modelUUID, id, err := watcher.ParseModelDocID(ch.Id)
+	if err != nil {
+		return Change{}, errors.Trace(err)
+	}
+	if !names.IsValidMachine(id) {
+		return Change{}, errors.NotValidf("machine id %q", id)
+	}
+	return Change{
+		ModelUUID: modelUUID,
+		Tag:       names.NewMachineTag(id),
+		Removed:   ch.Revno == -1,
+	}, nil
+}
+
+func decode(ch watcher.Change) (interface{}, error) {
+	return Decode(ch)
+}
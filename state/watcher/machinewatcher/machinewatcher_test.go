@@ -0,0 +1,67 @@
+// Copyright 2016 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package machinewatcher_test
+
+import (
+	"github.com/juju/errors"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+	"gopkg.in/juju/names.v2"
+
+	"github.com/juju/juju/state/watcher"
+	"github.com/juju/juju/state/watcher/machinewatcher"
+)
+
+type machineWatcherSuite struct{}
+
+var _ = gc.Suite(&machineWatcherSuite{})
+
+func (s *machineWatcherSuite) TestDecode(c *gc.C) {
+	change, err := machinewatcher.Decode(watcher.Change{
+		C:     "machines",
+		Id:    "model-uuid:0/lxd/1",
+		Revno: 3,
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(change, jc.DeepEquals, machinewatcher.Change{
+		ModelUUID: "model-uuid",
+		Tag:       names.NewMachineTag("0/lxd/1"),
+	})
+}
+
+func (s *machineWatcherSuite) TestDecodeRemoved(c *gc.C) {
+	change, err := machinewatcher.Decode(watcher.Change{
+		C:     "machines",
+		Id:    "model-uuid:1",
+		Revno: -1,
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(change.Removed, jc.IsTrue)
+}
+
+func (s *machineWatcherSuite) TestDecodeRegistered(c *gc.C) {
+	change, err := watcher.Decode(watcher.Change{
+		C:  "machines",
+		Id: "model-uuid:1",
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(change, jc.DeepEquals, machinewatcher.Change{
+		ModelUUID: "model-uuid",
+		Tag:       names.NewMachineTag("1"),
+	})
+}
+
+func (s *machineWatcherSuite) TestDecodeWrongCollection(c *gc.C) {
+	_, err := machinewatcher.Decode(watcher.Change{C: "units", Id: "model-uuid:1"})
+	c.Assert(err, gc.ErrorMatches, "expected change to machines, got units")
+}
+
+func (s *machineWatcherSuite) TestDecodeInvalidId(c *gc.C) {
+	_, err := machinewatcher.Decode(watcher.Change{C: "machines", Id: "model-uuid:foo"})
+	c.Assert(err, jc.Satisfies, errors.IsNotValid)
+	c.Assert(err, gc.ErrorMatches, `machine id "foo" not valid`)
+
+	_, err = machinewatcher.Decode(watcher.Change{C: "machines", Id: "1"})
+	c.Assert(err, jc.Satisfies, errors.IsNotValid)
+}
@@ -0,0 +1,65 @@
+// Copyright 2016 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+// Package unitwatcher decodes changes to unit documents, observed
+// by a state/watcher.Watcher, into typed notifications.
+package unitwatcher
+
+import (
+	"github.com/juju/errors"
+	"gopkg.in/juju/names.v2"
+
+	"github.com/juju/juju/state/watcher"
+)
+
+// Collection is the name of the collection holding unit documents.
+const Collection = "units"
+
+func init() {
+	watcher.RegisterDecoder(Collection, watcher.DecoderFunc(decode))
+}
+
+// Change describes a change to a unit document.
+type Change struct {
+	// ModelUUID is the UUID of the model the unit is in.
+	ModelUUID string
+
+	// Tag is the tag of the unit that changed.
+	Tag names.UnitTag
+
+	// Application is the name of the unit's application.
+	Application string
+
+	// Removed reports whether the unit document was removed.
+	// The unit's life is not recorded in the change log, so
+	// consumers interested in it must read the unit.
+	Removed bool
+}
+
+// Decode decodes a watcher.Change to a unit document.
+func Decode(ch watcher.Change) (Change, error) {
+	if ch.C != Collection {
+		return Change{}, errors.Errorf("expected change to %s, got %s", Collection, ch.C)
+	}
+	modelUUID, id, err := watcher.ParseModelDocID(ch.Id)
+	if err != nil {
+		return Change{}, errors.Trace(err)
+	}
+	if !names.IsValidUnit(id) {
+		return Change{}, errors.NotValidf("unit name %q", id)
+	}
+	application, err := names.UnitApplication(id)
+	if err != nil {
+		return Change{}, errors.Trace(err)
+	}
+	return Change{
+		ModelUUID:   modelUUID,
+		Tag:         names.NewUnitTag(id),
+		Application: application,
+		Removed:     ch.Revno == -1,
+	}, nil
+}
+
+func decode(ch watcher.Change) (interface{}, error) {
+	return Decode(ch)
+}
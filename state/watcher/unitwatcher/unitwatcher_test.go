@@ -0,0 +1,66 @@
+// Copyright 2016 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package unitwatcher_test
+
+import (
+	"github.com/juju/errors"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+	"gopkg.in/juju/names.v2"
+
+	"github.com/juju/juju/state/watcher"
+	"github.com/juju/juju/state/watcher/unitwatcher"
+)
+
+type unitWatcherSuite struct{}
+
+var _ = gc.Suite(&unitWatcherSuite{})
+
+func (s *unitWatcherSuite) TestDecode(c *gc.C) {
+	change, err := unitwatcher.Decode(watcher.Change{
+		C:     "units",
+		Id:    "model-uuid:mysql/0",
+		Revno: 3,
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(change, jc.DeepEquals, unitwatcher.Change{
+		ModelUUID:   "model-uuid",
+		Tag:         names.NewUnitTag("mysql/0"),
+		Application: "mysql",
+	})
+}
+
+func (s *unitWatcherSuite) TestDecodeRemoved(c *gc.C) {
+	change, err := unitwatcher.Decode(watcher.Change{
+		C:     "units",
+		Id:    "model-uuid:mysql/0",
+		Revno: -1,
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(change.Removed, jc.IsTrue)
+}
+
+func (s *unitWatcherSuite) TestDecodeRegistered(c *gc.C) {
+	change, err := watcher.Decode(watcher.Change{
+		C:  "units",
+		Id: "model-uuid:mysql/1",
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(change, jc.DeepEquals, unitwatcher.Change{
+		ModelUUID:   "model-uuid",
+		Tag:         names.NewUnitTag("mysql/1"),
+		Application: "mysql",
+	})
+}
+
+func (s *unitWatcherSuite) TestDecodeWrongCollection(c *gc.C) {
+	_, err := unitwatcher.Decode(watcher.Change{C: "machines", Id: "model-uuid:mysql/0"})
+	c.Assert(err, gc.ErrorMatches, "expected change to units, got machines")
+}
+
+func (s *unitWatcherSuite) TestDecodeInvalidId(c *gc.C) {
+	_, err := unitwatcher.Decode(watcher.Change{C: "units", Id: "model-uuid:mysql"})
+	c.Assert(err, jc.Satisfies, errors.IsNotValid)
+	c.Assert(err, gc.ErrorMatches, `unit name "mysql" not valid`)
+}
@@ -8,11 +8,13 @@ package watcher
 
 import (
 	"fmt"
+	"sync"
 	"time"
 
 	"github.com/juju/errors"
 	"github.com/juju/juju/worker"
 	"github.com/juju/loggo"
+	"github.com/juju/utils/clock"
 	"gopkg.in/mgo.v2"
 	"gopkg.in/mgo.v2/bson"
 	"gopkg.in/tomb.v1"
@@ -21,35 +23,37 @@ import (
 var logger = loggo.GetLogger("juju.state.watcher")
 
 // A Watcher can watch any number of collections and documents for changes.
+//
+// Changes are read from the changelog by a single goroutine, and handed
+// to a shard for each collection. Each shard runs its own goroutine, which
+// manages the watches on its collection and delivers the changes to them,
+// so that a busy collection, or a slow consumer of one collection's
+// changes, does not delay the delivery of changes in other collections.
 type Watcher struct {
-	tomb tomb.Tomb
-	log  *mgo.Collection
+	tomb   tomb.Tomb
+	log    *mgo.Collection
+	config Config
 
-	// watches holds the observers managed by Watch/Unwatch.
-	watches map[watchKey][]watchInfo
+	// request is used to deliver sync requests from the public API
+	// into the changelog goroutine loop.
+	request chan interface{}
 
-	// current holds the current txn-revno values for all the observed
-	// documents known to exist. Documents not observed or deleted are
-	// omitted from this map and are considered to have revno -1.
-	current map[watchKey]int64
+	// lastId is the most recent transaction id observed by a sync.
+	lastId interface{}
 
-	// needSync is set when a synchronization should take
-	// place.
-	needSync bool
+	// shardsWG tracks the running shard loops.
+	shardsWG sync.WaitGroup
 
-	// syncEvents and requestEvents contain the events to be
-	// dispatched to the watcher channels. They're queued during
-	// processing and flushed at the end to simplify the algorithm.
-	// The two queues are separated because events from sync are
-	// handled in reverse order due to the way the algorithm works.
-	syncEvents, requestEvents []event
+	// mu guards the fields below.
+	mu sync.Mutex
 
-	// request is used to deliver requests from the public API into
-	// the the goroutine loop.
-	request chan interface{}
+	// shards holds the shard for each collection that has been
+	// watched, or observed in the changelog.
+	shards map[string]*shard
 
-	// lastId is the most recent transaction id observed by a sync.
-	lastId interface{}
+	// stopped is set when the watcher has stopped, after which
+	// no more shard loops are started.
+	stopped bool
 }
 
 // A Change holds information about a document change.
@@ -63,6 +67,13 @@ type Change struct {
 	Revno int64
 }
 
+// IsSnapshotEnd reports whether the change marks the end of the
+// initial snapshot delivered to a collection watch started with
+// WatchCollectionWithSnapshot.
+func (c Change) IsSnapshotEnd() bool {
+	return c.Id == nil
+}
+
 type watchKey struct {
 	c  string
 	id interface{} // nil when watching collection
@@ -95,6 +106,14 @@ type watchInfo struct {
 	ch     chan<- Change
 	revno  int64
 	filter func(interface{}) bool
+
+	// owner identifies the owner on whose behalf the watch was
+	// registered, or is empty if the watch has no owner.
+	owner string
+
+	// immediate records that changes observed by the watch should
+	// be delivered without waiting for the coalescing window.
+	immediate bool
 }
 
 type event struct {
@@ -103,14 +122,59 @@ type event struct {
 	revno int64
 }
 
+// docChange records a document revision observed in the changelog.
+type docChange struct {
+	key   watchKey
+	revno int64
+}
+
+// Config holds the configuration for a Watcher.
+type Config struct {
+	// CoalesceWindow is the time for which changes observed in a
+	// collection are held before they are delivered to the watches
+	// on the collection, so that a burst of changes to the same
+	// document results in a single event. If it is zero, changes
+	// are delivered as soon as they are observed. The window is
+	// fixed for the lifetime of the Watcher.
+	CoalesceWindow time.Duration
+
+	// Clock is used to time the coalescing window.
+	Clock clock.Clock
+}
+
+// Validate returns an error if the config is not valid.
+func (config Config) Validate() error {
+	if config.CoalesceWindow < 0 {
+		return errors.NotValidf("negative CoalesceWindow")
+	}
+	if config.Clock == nil {
+		return errors.NotValidf("nil Clock")
+	}
+	return nil
+}
+
 // New returns a new Watcher observing the changelog collection,
-// which must be a capped collection maintained by mgo/txn.
+// which must be a capped collection maintained by mgo/txn. Changes
+// are delivered as soon as they are observed.
 func New(changelog *mgo.Collection) *Watcher {
+	w, err := NewWithConfig(changelog, Config{Clock: clock.WallClock})
+	if err != nil {
+		panic(err)
+	}
+	return w
+}
+
+// NewWithConfig returns a new Watcher observing the changelog
+// collection, as for New, configured with the given config.
+func NewWithConfig(changelog *mgo.Collection, config Config) (*Watcher, error) {
+	if err := config.Validate(); err != nil {
+		return nil, errors.Trace(err)
+	}
 	w := &Watcher{
 		log:     changelog,
-		watches: make(map[watchKey][]watchInfo),
-		current: make(map[watchKey]int64),
+		config:  config,
 		request: make(chan interface{}),
+		shards:  make(map[string]*shard),
 	}
 	go func() {
 		err := w.loop()
@@ -122,9 +186,10 @@ func New(changelog *mgo.Collection) *Watcher {
 			logger.Infof("watcher loop failed: %v", err)
 		}
 		w.tomb.Kill(cause)
+		w.stopShards()
 		w.tomb.Done()
 	}()
-	return w
+	return w, nil
 }
 
 // Kill is part of the worker.Worker interface.
@@ -158,32 +223,79 @@ func (w *Watcher) Err() error {
 type reqWatch struct {
 	key  watchKey
 	info watchInfo
+
+	// snapshot is true if the existing documents in the
+	// watched collection should be reported before any
+	// incremental changes.
+	snapshot bool
 }
 
 type reqUnwatch struct {
 	key watchKey
 	ch  chan<- Change
+
+	// owner is the owner on whose behalf the watch was registered,
+	// if any. An owned watch may already have been removed by
+	// UnwatchOwner, so it is not an error for it to be missing.
+	owner string
 }
 
-type reqSync struct{}
+type reqUnwatchOwner struct {
+	owner string
+}
+
+// reqSnapshot delivers the events read for a collection snapshot
+// back to the shard's goroutine.
+type reqSnapshot struct {
+	key    watchKey
+	ch     chan<- Change
+	events []event
+}
+
+type reqSync struct {
+	// done is closed when the sync is complete.
+	done chan struct{}
+}
+
+type reqReport struct {
+	// result receives the shard's report.
+	result chan<- map[string]interface{}
+}
 
-func (w *Watcher) sendReq(req interface{}) {
+func (w *Watcher) sendReq(request chan<- interface{}, req interface{}) {
 	select {
-	case w.request <- req:
+	case request <- req:
 	case <-w.tomb.Dying():
 	}
 }
 
+// sendShardReq sends a request to the shard for the given collection.
+func (w *Watcher) sendShardReq(collection string, req interface{}) {
+	w.sendReq(w.shard(collection).request, req)
+}
+
 // Watch starts watching the given collection and document id.
 // An event will be sent onto ch whenever a matching document's txn-revno
 // field is observed to change after a transaction is applied. The revno
 // parameter holds the currently known revision number for the document.
 // Non-existent documents are represented by a -1 revno.
 func (w *Watcher) Watch(collection string, id interface{}, revno int64, ch chan<- Change) {
+	w.watch("", collection, id, revno, ch, false)
+}
+
+// WatchImmediate starts watching the given collection and document id,
+// as for Watch, except that changes to the document are delivered as
+// soon as they are observed, without waiting for the coalescing window.
+// It is intended for watches whose consumers are sensitive to latency.
+func (w *Watcher) WatchImmediate(collection string, id interface{}, revno int64, ch chan<- Change) {
+	w.watch("", collection, id, revno, ch, true)
+}
+
+func (w *Watcher) watch(owner, collection string, id interface{}, revno int64, ch chan<- Change, immediate bool) {
 	if id == nil {
 		panic("watcher: cannot watch a document with nil id")
 	}
-	w.sendReq(reqWatch{watchKey{collection, id}, watchInfo{ch, revno, nil}})
+	w.sendShardReq(collection, reqWatch{watchKey{collection, id}, watchInfo{ch, revno, nil, owner, immediate}, false})
 }
 
 // WatchCollection starts watching the given collection.
@@ -198,70 +310,406 @@ func (w *Watcher) WatchCollection(collection string, ch chan<- Change) {
 // to change after a transaction is applied for any document in the collection, so long as the
 // specified filter function returns true when called with the document id value.
 func (w *Watcher) WatchCollectionWithFilter(collection string, ch chan<- Change, filter func(interface{}) bool) {
-	w.sendReq(reqWatch{watchKey{collection, nil}, watchInfo{ch, 0, filter}})
+	w.watchCollection("", collection, ch, filter, false)
+}
+
+func (w *Watcher) watchCollection(owner, collection string, ch chan<- Change, filter func(interface{}) bool, snapshot bool) {
+	w.sendShardReq(collection, reqWatch{watchKey{collection, nil}, watchInfo{ch, 0, filter, owner, false}, snapshot})
+}
+
+// WatchCollectionWithSnapshot starts watching the given collection, as for
+// WatchCollectionWithFilter, but first sends an event onto ch for each
+// document in the collection that exists when the watch starts, followed
+// by an event whose IsSnapshotEnd method returns true. Incremental changes
+// follow the snapshot; a document may be reported in both the snapshot and
+// the changes that follow it, but no change is missed.
+func (w *Watcher) WatchCollectionWithSnapshot(collection string, ch chan<- Change, filter func(interface{}) bool) {
+	w.watchCollection("", collection, ch, filter, true)
 }
 
 // Unwatch stops watching the given collection and document id via ch.
 func (w *Watcher) Unwatch(collection string, id interface{}, ch chan<- Change) {
+	w.unwatch("", collection, id, ch)
+}
+
+func (w *Watcher) unwatch(owner, collection string, id interface{}, ch chan<- Change) {
 	if id == nil {
 		panic("watcher: cannot unwatch a document with nil id")
 	}
-	w.sendReq(reqUnwatch{watchKey{collection, id}, ch})
+	w.sendShardReq(collection, reqUnwatch{watchKey{collection, id}, ch, owner})
 }
 
 // UnwatchCollection stops watching the given collection via ch.
 func (w *Watcher) UnwatchCollection(collection string, ch chan<- Change) {
-	w.sendReq(reqUnwatch{watchKey{collection, nil}, ch})
+	w.sendShardReq(collection, reqUnwatch{watchKey{collection, nil}, ch, ""})
+}
+
+// Owned returns an OwnedWatcher that registers watches with w on
+// behalf of the given owner, which must not be empty.
+func (w *Watcher) Owned(owner string) *OwnedWatcher {
+	if owner == "" {
+		panic("watcher: cannot watch on behalf of an empty owner")
+	}
+	return &OwnedWatcher{w, owner}
+}
+
+// UnwatchOwner stops all of the watches registered on behalf of the
+// given owner, such as the watches of an API connection that has been
+// closed. No further events will be sent to the owner's channels, and
+// subsequently unwatching any of them has no effect.
+func (w *Watcher) UnwatchOwner(owner string) {
+	if owner == "" {
+		panic("watcher: cannot unwatch an empty owner")
+	}
+	w.mu.Lock()
+	shards := make([]*shard, 0, len(w.shards))
+	for _, s := range w.shards {
+		shards = append(shards, s)
+	}
+	w.mu.Unlock()
+	for _, s := range shards {
+		w.sendReq(s.request, reqUnwatchOwner{owner})
+	}
+}
+
+// OwnedWatcher registers watches with a Watcher on behalf of an owner,
+// so that they may all be stopped with the Watcher's UnwatchOwner
+// method. Its other methods are those of the Watcher.
+type OwnedWatcher struct {
+	*Watcher
+	owner string
+}
+
+// Owner returns the owner on whose behalf watches are registered.
+func (w *OwnedWatcher) Owner() string {
+	return w.owner
+}
+
+// Watch is as for Watcher.Watch.
+func (w *OwnedWatcher) Watch(collection string, id interface{}, revno int64, ch chan<- Change) {
+	w.watch(w.owner, collection, id, revno, ch, false)
+}
+
+// WatchImmediate is as for Watcher.WatchImmediate.
+func (w *OwnedWatcher) WatchImmediate(collection string, id interface{}, revno int64, ch chan<- Change) {
+	w.watch(w.owner, collection, id, revno, ch, true)
+}
+
+// WatchCollection is as for Watcher.WatchCollection.
+func (w *OwnedWatcher) WatchCollection(collection string, ch chan<- Change) {
+	w.watchCollection(w.owner, collection, ch, nil, false)
+}
+
+// WatchCollectionWithFilter is as for Watcher.WatchCollectionWithFilter.
+func (w *OwnedWatcher) WatchCollectionWithFilter(collection string, ch chan<- Change, filter func(interface{}) bool) {
+	w.watchCollection(w.owner, collection, ch, filter, false)
+}
+
+// WatchCollectionWithSnapshot is as for Watcher.WatchCollectionWithSnapshot.
+func (w *OwnedWatcher) WatchCollectionWithSnapshot(collection string, ch chan<- Change, filter func(interface{}) bool) {
+	w.watchCollection(w.owner, collection, ch, filter, true)
+}
+
+// Unwatch is as for Watcher.Unwatch, except that it has no
+// effect if the watch has been stopped by UnwatchOwner.
+func (w *OwnedWatcher) Unwatch(collection string, id interface{}, ch chan<- Change) {
+	w.unwatch(w.owner, collection, id, ch)
+}
+
+// UnwatchCollection is as for Watcher.UnwatchCollection, except that
+// it has no effect if the watch has been stopped by UnwatchOwner.
+func (w *OwnedWatcher) UnwatchCollection(collection string, ch chan<- Change) {
+	w.sendShardReq(collection, reqUnwatch{watchKey{collection, nil}, ch, w.owner})
 }
 
 // StartSync forces the watcher to load new events from the database.
+// It returns once the events have been handed to the collections'
+// shards, so any subsequent Watch will observe them. If the watcher
+// has a coalescing window, the shards deliver the events when the
+// window expires.
 func (w *Watcher) StartSync() {
-	w.sendReq(reqSync{})
+	done := make(chan struct{})
+	w.sendReq(w.request, reqSync{done})
+	select {
+	case <-done:
+	case <-w.tomb.Dying():
+	}
+}
+
+// Report returns a report of the watches on each collection, keyed by
+// collection name, for introspection. For each collection, the report
+// holds the number of document and collection watches, and the number
+// of documents whose revisions are known to the watcher.
+func (w *Watcher) Report() map[string]interface{} {
+	w.mu.Lock()
+	shards := make(map[string]*shard, len(w.shards))
+	for collection, s := range w.shards {
+		shards[collection] = s
+	}
+	w.mu.Unlock()
+
+	report := make(map[string]interface{})
+	for collection, s := range shards {
+		result := make(chan map[string]interface{}, 1)
+		w.sendReq(s.request, reqReport{result})
+		select {
+		case shardReport := <-result:
+			report[collection] = shardReport
+		case <-w.tomb.Dying():
+			return report
+		}
+	}
+	return report
 }
 
 // Period is the delay between each sync.
 // It must not be changed when any watchers are active.
 var Period time.Duration = 5 * time.Second
 
-// loop implements the main watcher loop.
+// loop implements the main watcher loop, which reads changes from
+// the changelog and hands them to the collections' shards.
 func (w *Watcher) loop() error {
 	next := time.After(Period)
-	w.needSync = true
+	needSync := true
 	if err := w.initLastId(); err != nil {
 		return errors.Trace(err)
 	}
 	for {
-		if w.needSync {
+		if needSync {
 			if err := w.sync(); err != nil {
 				return errors.Trace(err)
 			}
-			w.flush()
+			needSync = false
 			next = time.After(Period)
 		}
 		select {
 		case <-w.tomb.Dying():
 			return errors.Trace(tomb.ErrDying)
 		case <-next:
-			next = time.After(Period)
-			w.needSync = true
+			needSync = true
 		case req := <-w.request:
-			w.handle(req)
-			w.flush()
+			logger.Tracef("got request: %#v", req)
+			switch r := req.(type) {
+			case reqSync:
+				if err := w.sync(); err != nil {
+					return errors.Trace(err)
+				}
+				close(r.done)
+				next = time.After(Period)
+			default:
+				panic(fmt.Errorf("unknown request: %T", req))
+			}
+		}
+	}
+}
+
+// shard returns the shard for the given collection, creating and
+// starting it if necessary.
+func (w *Watcher) shard(collection string) *shard {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if s, ok := w.shards[collection]; ok {
+		return s
+	}
+	s := &shard{
+		w:         w,
+		window:    w.config.CoalesceWindow,
+		request:   make(chan interface{}),
+		changed:   make(chan struct{}, 1),
+		watches:   make(map[watchKey][]watchInfo),
+		owned:     make(map[string]map[watchKey]int),
+		immediate: make(map[watchKey]int),
+		current:   make(map[watchKey]int64),
+	}
+	w.shards[collection] = s
+	if !w.stopped {
+		w.shardsWG.Add(1)
+		go func() {
+			defer w.shardsWG.Done()
+			s.loop()
+		}()
+	}
+	return s
+}
+
+// stopShards waits for the shard loops to stop, and prevents any
+// more from being started. The watcher must be dying.
+func (w *Watcher) stopShards() {
+	w.mu.Lock()
+	w.stopped = true
+	w.mu.Unlock()
+	w.shardsWG.Wait()
+}
+
+// shard manages the watches on a single collection, and delivers the
+// changes observed in that collection to them.
+type shard struct {
+	w *Watcher
+
+	// window is the time for which pending changes are held
+	// before they are applied, so that they may be coalesced.
+	window time.Duration
+
+	// request is used to deliver requests from the public API into
+	// the shard's goroutine loop.
+	request chan interface{}
+
+	// changed is signalled when changes are added to pending.
+	changed chan struct{}
+
+	// pendingMu guards pending.
+	pendingMu sync.Mutex
+
+	// pending holds the changes observed by the watcher's sync
+	// that have yet to be applied by the shard, newest first.
+	pending []docChange
+
+	// watches holds the observers managed by Watch/Unwatch.
+	watches map[watchKey][]watchInfo
+
+	// owned holds, for each owner, the number of watches registered
+	// on its behalf under each key, so that UnwatchOwner need not
+	// visit every watch in the shard.
+	owned map[string]map[watchKey]int
+
+	// immediate holds the number of immediate watches under each
+	// key, whose changes are not held for the coalescing window.
+	immediate map[watchKey]int
+
+	// current holds the current txn-revno values for all the observed
+	// documents known to exist. Documents not observed or deleted are
+	// omitted from this map and are considered to have revno -1.
+	current map[watchKey]int64
+
+	// syncEvents and requestEvents contain the events to be
+	// dispatched to the watcher channels. They're queued during
+	// processing and flushed at the end to simplify the algorithm.
+	// The two queues are separated because events from sync are
+	// handled in reverse order due to the way the algorithm works.
+	syncEvents, requestEvents []event
+}
+
+// loop implements the shard's goroutine loop.
+//
+// If the shard has no coalescing window, pending changes are applied
+// as soon as they are observed, and before each request is handled,
+// so that requests made after a sync observe its changes.
+//
+// If the shard has a coalescing window, changes are held until the
+// window after the first of them has passed, unless an immediate
+// watch observes any of them; all of the changes to each document
+// in that time are then delivered as a single event. Requests do not
+// cut the window short: a watch added in the meantime observes the
+// held changes when they are applied.
+func (s *shard) loop() {
+	var coalesced <-chan time.Time
+	for {
+		select {
+		case <-s.w.tomb.Dying():
+			return
+		case <-s.changed:
+			if s.window > 0 && !s.pendingImmediate() {
+				if coalesced == nil {
+					coalesced = s.w.config.Clock.After(s.window)
+				}
+				continue
+			}
+		case <-coalesced:
+		case req := <-s.request:
+			if s.window == 0 {
+				s.applyPending()
+			}
+			s.handle(req)
+			if s.window == 0 || coalesced == nil || !s.pendingImmediate() {
+				s.flush()
+				continue
+			}
+			// The request added an immediate watch
+			// that observes held changes.
+		}
+		coalesced = nil
+		s.applyPending()
+		s.flush()
+	}
+}
+
+// addPending adds the given changes, which must be newer than any
+// already pending and ordered newest first, to the shard's pending
+// changes, and signals the shard's loop to apply them.
+func (s *shard) addPending(changes []docChange) {
+	s.pendingMu.Lock()
+	s.pending = append(changes, s.pending...)
+	s.pendingMu.Unlock()
+	select {
+	case s.changed <- struct{}{}:
+	default:
+	}
+}
+
+// pendingImmediate reports whether any of the shard's pending changes
+// are observed by an immediate watch.
+func (s *shard) pendingImmediate() bool {
+	if len(s.immediate) == 0 {
+		return false
+	}
+	s.pendingMu.Lock()
+	defer s.pendingMu.Unlock()
+	for _, change := range s.pending {
+		if s.immediate[change.key] > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// applyPending updates the shard's knowledge with its pending changes,
+// and queues events to observing channels.
+func (s *shard) applyPending() {
+	s.pendingMu.Lock()
+	pending := s.pending
+	s.pending = nil
+	s.pendingMu.Unlock()
+
+	seen := make(map[watchKey]bool)
+	for _, change := range pending {
+		key, revno := change.key, change.revno
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		if s.current[key] == revno {
+			continue
+		}
+		s.current[key] = revno
+		// Queue notifications for per-collection watches.
+		for _, info := range s.watches[watchKey{key.c, nil}] {
+			if info.filter != nil && !info.filter(key.id) {
+				continue
+			}
+			s.syncEvents = append(s.syncEvents, event{info.ch, key, revno})
+		}
+		// Queue notifications for per-document watches.
+		infos := s.watches[key]
+		for i, info := range infos {
+			if revno > info.revno || revno < 0 && info.revno >= 0 {
+				infos[i].revno = revno
+				s.syncEvents = append(s.syncEvents, event{info.ch, key, revno})
+			}
 		}
 	}
 }
 
 // flush sends all pending events to their respective channels.
-func (w *Watcher) flush() {
+func (s *shard) flush() {
 	// refreshEvents are stored newest first.
-	for i := len(w.syncEvents) - 1; i >= 0; i-- {
-		e := &w.syncEvents[i]
+	for i := len(s.syncEvents) - 1; i >= 0; i-- {
+		e := &s.syncEvents[i]
 		for e.ch != nil {
 			select {
-			case <-w.tomb.Dying():
+			case <-s.w.tomb.Dying():
 				return
-			case req := <-w.request:
-				w.handle(req)
+			case req := <-s.request:
+				s.handle(req)
 				continue
 			case e.ch <- Change{e.key.c, e.key.id, e.revno}:
 			}
@@ -270,73 +718,216 @@ func (w *Watcher) flush() {
 	}
 	// requestEvents are stored oldest first, and
 	// may grow during the loop.
-	for i := 0; i < len(w.requestEvents); i++ {
-		e := &w.requestEvents[i]
+	for i := 0; i < len(s.requestEvents); i++ {
+		e := &s.requestEvents[i]
 		for e.ch != nil {
 			select {
-			case <-w.tomb.Dying():
+			case <-s.w.tomb.Dying():
 				return
-			case req := <-w.request:
-				w.handle(req)
+			case req := <-s.request:
+				s.handle(req)
 				continue
 			case e.ch <- Change{e.key.c, e.key.id, e.revno}:
 			}
 			break
 		}
 	}
-	w.syncEvents = w.syncEvents[:0]
-	w.requestEvents = w.requestEvents[:0]
+	s.syncEvents = s.syncEvents[:0]
+	s.requestEvents = s.requestEvents[:0]
 }
 
 // handle deals with requests delivered by the public API
-// onto the background watcher goroutine.
-func (w *Watcher) handle(req interface{}) {
+// onto the shard's goroutine.
+func (s *shard) handle(req interface{}) {
 	logger.Tracef("got request: %#v", req)
 	switch r := req.(type) {
-	case reqSync:
-		w.needSync = true
 	case reqWatch:
-		for _, info := range w.watches[r.key] {
+		for _, info := range s.watches[r.key] {
 			if info.ch == r.info.ch {
 				panic(fmt.Errorf("tried to re-add channel %v for %s", info.ch, r.key))
 			}
 		}
-		if revno, ok := w.current[r.key]; ok && (revno > r.info.revno || revno == -1 && r.info.revno >= 0) {
+		if revno, ok := s.current[r.key]; ok && (revno > r.info.revno || revno == -1 && r.info.revno >= 0) {
 			r.info.revno = revno
-			w.requestEvents = append(w.requestEvents, event{r.info.ch, r.key, revno})
+			s.requestEvents = append(s.requestEvents, event{r.info.ch, r.key, revno})
 		}
-		w.watches[r.key] = append(w.watches[r.key], r.info)
-	case reqUnwatch:
-		watches := w.watches[r.key]
-		removed := false
-		for i, info := range watches {
-			if info.ch == r.ch {
-				watches[i] = watches[len(watches)-1]
-				w.watches[r.key] = watches[:len(watches)-1]
-				removed = true
-				break
+		s.watches[r.key] = append(s.watches[r.key], r.info)
+		if r.info.immediate {
+			s.immediate[r.key]++
+		}
+		if r.info.owner != "" {
+			keys := s.owned[r.info.owner]
+			if keys == nil {
+				keys = make(map[watchKey]int)
+				s.owned[r.info.owner] = keys
 			}
+			keys[r.key]++
 		}
-		if !removed {
+		if r.snapshot {
+			s.startSnapshot(r.key, r.info)
+		}
+	case reqUnwatch:
+		if !s.removeWatch(r.key, r.ch) {
+			if r.owner != "" {
+				// The watch was stopped by UnwatchOwner.
+				return
+			}
 			panic(fmt.Errorf("tried to remove missing channel %v for %s", r.ch, r.key))
 		}
-		for i := range w.requestEvents {
-			e := &w.requestEvents[i]
-			if r.key.match(e.key) && e.ch == r.ch {
-				e.ch = nil
+		if r.owner != "" {
+			s.disown(r.owner, r.key)
+		}
+	case reqUnwatchOwner:
+		for key := range s.owned[r.owner] {
+			// Collect the owner's channels first, as
+			// removeWatch reorders the watches.
+			var chans []chan<- Change
+			for _, info := range s.watches[key] {
+				if info.owner == r.owner {
+					chans = append(chans, info.ch)
+				}
+			}
+			for _, ch := range chans {
+				s.removeWatch(key, ch)
+			}
+		}
+		delete(s.owned, r.owner)
+	case reqSnapshot:
+		// The watch may have been removed while the
+		// snapshot was being read.
+		for _, info := range s.watches[r.key] {
+			if info.ch == r.ch {
+				s.requestEvents = append(s.requestEvents, r.events...)
+				break
 			}
 		}
-		for i := range w.syncEvents {
-			e := &w.syncEvents[i]
-			if r.key.match(e.key) && e.ch == r.ch {
-				e.ch = nil
+	case reqReport:
+		var documentWatches, collectionWatches int
+		for key, infos := range s.watches {
+			if key.id == nil {
+				collectionWatches += len(infos)
+			} else {
+				documentWatches += len(infos)
 			}
 		}
+		r.result <- map[string]interface{}{
+			"document-watches":   documentWatches,
+			"collection-watches": collectionWatches,
+			"known-documents":    len(s.current),
+			"owners":             len(s.owned),
+		}
 	default:
 		panic(fmt.Errorf("unknown request: %T", req))
 	}
 }
 
+// removeWatch removes the watch sending events for the given key to
+// ch, and discards any of its queued events. It reports whether the
+// watch was found.
+func (s *shard) removeWatch(key watchKey, ch chan<- Change) bool {
+	watches := s.watches[key]
+	removed := false
+	for i, info := range watches {
+		if info.ch == ch {
+			watches[i] = watches[len(watches)-1]
+			s.watches[key] = watches[:len(watches)-1]
+			removed = true
+			if info.immediate {
+				if s.immediate[key]--; s.immediate[key] <= 0 {
+					delete(s.immediate, key)
+				}
+			}
+			break
+		}
+	}
+	if !removed {
+		return false
+	}
+	for i := range s.requestEvents {
+		e := &s.requestEvents[i]
+		if key.match(e.key) && e.ch == ch {
+			e.ch = nil
+		}
+	}
+	for i := range s.syncEvents {
+		e := &s.syncEvents[i]
+		if key.match(e.key) && e.ch == ch {
+			e.ch = nil
+		}
+	}
+	return true
+}
+
+// disown records the removal of one of the given owner's watches
+// for the given key.
+func (s *shard) disown(owner string, key watchKey) {
+	keys := s.owned[owner]
+	if keys == nil {
+		return
+	}
+	if keys[key]--; keys[key] <= 0 {
+		delete(keys, key)
+	}
+	if len(keys) == 0 {
+		delete(s.owned, owner)
+	}
+}
+
+// startSnapshot reads a snapshot of the watched collection in a
+// separate goroutine, so that reading a large collection does not
+// delay the delivery of changes to the shard's other watches. The
+// snapshot's events are handed back to the shard's goroutine with a
+// reqSnapshot request, and queued if the watch still exists.
+func (s *shard) startSnapshot(key watchKey, info watchInfo) {
+	// The shard's own loop is counted in shardsWG, so
+	// the counter cannot be zero while it is running.
+	s.w.shardsWG.Add(1)
+	go func() {
+		defer s.w.shardsWG.Done()
+		events, err := s.readSnapshot(key.c, info)
+		if err != nil {
+			s.w.tomb.Kill(errors.Annotatef(err, "reading snapshot of %s", key))
+			return
+		}
+		s.w.sendReq(s.request, reqSnapshot{key, info.ch, events})
+	}()
+}
+
+// readSnapshot returns events for each of the documents currently in
+// the specified collection that pass the watch's filter, followed by
+// an event marking the end of the snapshot.
+//
+// The snapshot is read from the collection directly, rather than from
+// the changelog, so it reflects all transactions applied so far; some
+// of those may not yet have been observed by sync, and so may later be
+// reported again. Documents removed before the snapshot is read will
+// be reported as removed by sync, if at all, which is harmless.
+func (s *shard) readSnapshot(collection string, info watchInfo) ([]event, error) {
+	var doc struct {
+		Id    interface{} `bson:"_id"`
+		Revno int64       `bson:"txn-revno"`
+	}
+	var events []event
+	coll := s.w.log.Database.C(collection)
+	iter := coll.Find(nil).Select(bson.D{{"_id", 1}, {"txn-revno", 1}}).Iter()
+	for iter.Next(&doc) {
+		if doc.Revno < 0 {
+			continue
+		}
+		if info.filter != nil && !info.filter(doc.Id) {
+			continue
+		}
+		key := watchKey{collection, doc.Id}
+		events = append(events, event{info.ch, key, doc.Revno})
+	}
+	if err := iter.Close(); err != nil {
+		return nil, errors.Trace(err)
+	}
+	// The end of the snapshot is marked with a nil document id.
+	endKey := watchKey{collection, nil}
+	return append(events, event{info.ch, endKey, -1}), nil
+}
+
 // initLastId reads the most recent changelog document and initializes
 // lastId with it. This causes all history that precedes the creation
 // of the watcher to be ignored.
@@ -352,13 +943,13 @@ func (w *Watcher) initLastId() error {
 	return nil
 }
 
-// sync updates the watcher knowledge from the database, and
-// queues events to observing channels.
+// sync reads new changes from the changelog, and hands them to the
+// shards for their collections.
 func (w *Watcher) sync() error {
-	w.needSync = false
 	// Iterate through log events in reverse insertion order (newest first).
 	iter := w.log.Find(nil).Batch(10).Sort("-$natural").Iter()
 	seen := make(map[watchKey]bool)
+	changes := make(map[string][]docChange)
 	first := true
 	lastId := w.lastId
 	var entry bson.D
@@ -408,30 +999,15 @@ func (w *Watcher) sync() error {
 				if revno < 0 {
 					revno = -1
 				}
-				if w.current[key] == revno {
-					continue
-				}
-				w.current[key] = revno
-				// Queue notifications for per-collection watches.
-				for _, info := range w.watches[watchKey{c.Name, nil}] {
-					if info.filter != nil && !info.filter(d[i]) {
-						continue
-					}
-					w.syncEvents = append(w.syncEvents, event{info.ch, key, revno})
-				}
-				// Queue notifications for per-document watches.
-				infos := w.watches[key]
-				for i, info := range infos {
-					if revno > info.revno || revno < 0 && info.revno >= 0 {
-						infos[i].revno = revno
-						w.syncEvents = append(w.syncEvents, event{info.ch, key, revno})
-					}
-				}
+				changes[c.Name] = append(changes[c.Name], docChange{key, revno})
 			}
 		}
 	}
 	if err := iter.Close(); err != nil {
 		return errors.Errorf("watcher iteration error: %v", err)
 	}
+	for collection, collChanges := range changes {
+		w.shard(collection).addPending(collChanges)
+	}
 	return nil
 }
@@ -447,6 +447,139 @@ func (s *FastPeriodSuite) TestStartSync(c *gc.C) {
 	assertChange(c, s.ch, watcher.Change{"test", "a", revno})
 }
 
+func (s *FastPeriodSuite) TestReport(c *gc.C) {
+	chA := make(chan watcher.Change)
+	chB := make(chan watcher.Change)
+	s.w.Watch("testA", 1, -1, chA)
+	s.w.Watch("testA", 2, -1, chA)
+	s.w.WatchCollection("testA", chB)
+	s.w.Watch("testB", 1, -1, chB)
+
+	c.Assert(s.w.Report(), jc.DeepEquals, map[string]interface{}{
+		"testA": map[string]interface{}{
+			"document-watches":   2,
+			"collection-watches": 1,
+			"known-documents":    0,
+			"owners":             0,
+		},
+		"testB": map[string]interface{}{
+			"document-watches":   1,
+			"collection-watches": 0,
+			"known-documents":    0,
+			"owners":             0,
+		},
+	})
+
+	s.w.Unwatch("testA", 1, chA)
+	s.w.Unwatch("testA", 2, chA)
+	s.w.UnwatchCollection("testA", chB)
+	s.w.Unwatch("testB", 1, chB)
+	c.Assert(s.w.Report(), jc.DeepEquals, map[string]interface{}{
+		"testA": map[string]interface{}{
+			"document-watches":   0,
+			"collection-watches": 0,
+			"known-documents":    0,
+			"owners":             0,
+		},
+		"testB": map[string]interface{}{
+			"document-watches":   0,
+			"collection-watches": 0,
+			"known-documents":    0,
+			"owners":             0,
+		},
+	})
+}
+
+func (s *FastPeriodSuite) TestUnwatchOwner(c *gc.C) {
+	owned := s.w.Owned("conn-1")
+	c.Assert(owned.Owner(), gc.Equals, "conn-1")
+	chA := make(chan watcher.Change)
+	chB := make(chan watcher.Change)
+	chC := make(chan watcher.Change)
+	owned.Watch("testA", 1, -1, chA)
+	owned.WatchCollection("testB", chB)
+	s.w.Watch("testA", 1, -1, chC)
+
+	c.Assert(s.w.Report(), jc.DeepEquals, map[string]interface{}{
+		"testA": map[string]interface{}{
+			"document-watches":   2,
+			"collection-watches": 0,
+			"known-documents":    0,
+			"owners":             1,
+		},
+		"testB": map[string]interface{}{
+			"document-watches":   0,
+			"collection-watches": 1,
+			"known-documents":    0,
+			"owners":             1,
+		},
+	})
+
+	s.w.UnwatchOwner("conn-1")
+	c.Assert(s.w.Report(), jc.DeepEquals, map[string]interface{}{
+		"testA": map[string]interface{}{
+			"document-watches":   1,
+			"collection-watches": 0,
+			"known-documents":    0,
+			"owners":             0,
+		},
+		"testB": map[string]interface{}{
+			"document-watches":   0,
+			"collection-watches": 0,
+			"known-documents":    0,
+			"owners":             0,
+		},
+	})
+
+	// Only the unowned watch is notified.
+	revno := s.insert(c, "testA", 1)
+	s.insert(c, "testB", 1)
+	s.w.StartSync()
+	assertChange(c, chC, watcher.Change{"testA", 1, revno})
+	assertNoChange(c, chA)
+	assertNoChange(c, chB)
+
+	// Unwatching the owner's stopped watches has no effect.
+	owned.Unwatch("testA", 1, chA)
+	owned.UnwatchCollection("testB", chB)
+	s.w.Unwatch("testA", 1, chC)
+}
+
+func (s *FastPeriodSuite) TestUnwatchOwnerWithOutstandingRequest(c *gc.C) {
+	owned := s.w.Owned("conn-1")
+	chA := make(chan watcher.Change)
+	owned.WatchCollection("testA", chA)
+	chB := make(chan watcher.Change)
+	s.w.Watch("testA", 3, -1, chB)
+	revnoA := s.insert(c, "testA", 1)
+	s.insert(c, "testA", 2)
+	revnoB := s.insert(c, "testA", 3)
+	s.w.StartSync()
+	// Once the first change has been received on chA, the watcher
+	// is trying to deliver the remaining changes; stopping the
+	// owner's watches must cancel those destined for chA.
+	assertChange(c, chA, watcher.Change{"testA", 1, revnoA})
+	s.w.UnwatchOwner("conn-1")
+	assertChange(c, chB, watcher.Change{"testA", 3, revnoB})
+	assertNoChange(c, chA)
+	s.w.Unwatch("testA", 3, chB)
+}
+
+func (s *FastPeriodSuite) TestOwnedUnwatch(c *gc.C) {
+	owned := s.w.Owned("conn-1")
+	chA := make(chan watcher.Change)
+	owned.Watch("testA", 1, -1, chA)
+	owned.Unwatch("testA", 1, chA)
+	c.Assert(s.w.Report()["testA"], jc.DeepEquals, map[string]interface{}{
+		"document-watches":   0,
+		"collection-watches": 0,
+		"known-documents":    0,
+		"owners":             0,
+	})
+	// The owner has no watches left to stop.
+	s.w.UnwatchOwner("conn-1")
+}
+
 func (s *FastPeriodSuite) TestWatchCollection(c *gc.C) {
 	chA1 := make(chan watcher.Change)
 	chB1 := make(chan watcher.Change)
@@ -587,6 +720,70 @@ func (s *FastPeriodSuite) TestUnwatchCollectionWithOutstandingRequest(c *gc.C) {
 	assertChange(c, chB, watcher.Change{"testB", 1, revnoB})
 }
 
+func (s *FastPeriodSuite) TestBlockedCollectionDoesNotBlockOthers(c *gc.C) {
+	// Nothing ever receives from chA, so the watcher will block
+	// trying to deliver testA's changes. Changes to testB must
+	// be delivered regardless.
+	chA := make(chan watcher.Change)
+	s.w.WatchCollection("testA", chA)
+	chB := make(chan watcher.Change)
+	s.w.WatchCollection("testB", chB)
+
+	s.insert(c, "testA", 1)
+	revnoB := s.insert(c, "testB", 1)
+	s.w.StartSync()
+	assertChange(c, chB, watcher.Change{"testB", 1, revnoB})
+
+	revnoB = s.update(c, "testB", 1)
+	s.w.StartSync()
+	assertChange(c, chB, watcher.Change{"testB", 1, revnoB})
+
+	s.w.UnwatchCollection("testA", chA)
+}
+
+func (s *FastPeriodSuite) TestWatchCollectionWithSnapshot(c *gc.C) {
+	revno1 := s.insert(c, "testA", 1)
+	s.insert(c, "testA", 2)
+	revno3 := s.insert(c, "testA", 3)
+	s.insert(c, "testB", 1)
+	s.w.StartSync()
+
+	filter := func(key interface{}) bool {
+		return key.(int) != 2
+	}
+	chA := make(chan watcher.Change)
+	s.w.WatchCollectionWithSnapshot("testA", chA, filter)
+	assertChange(c, chA, watcher.Change{"testA", 1, revno1})
+	assertChange(c, chA, watcher.Change{"testA", 3, revno3})
+	assertChange(c, chA, watcher.Change{"testA", nil, -1})
+
+	// Changes after the snapshot are delivered incrementally.
+	revno1 = s.update(c, "testA", 1)
+	s.w.StartSync()
+	assertChange(c, chA, watcher.Change{"testA", 1, revno1})
+	assertNoChange(c, chA)
+}
+
+func (s *FastPeriodSuite) TestWatchCollectionWithSnapshotEmpty(c *gc.C) {
+	chA := make(chan watcher.Change)
+	s.w.WatchCollectionWithSnapshot("testA", chA, nil)
+	assertChange(c, chA, watcher.Change{"testA", nil, -1})
+	c.Assert(watcher.Change{"testA", nil, -1}.IsSnapshotEnd(), jc.IsTrue)
+	c.Assert(watcher.Change{"testA", 1, -1}.IsSnapshotEnd(), jc.IsFalse)
+
+	revno := s.insert(c, "testA", 1)
+	s.w.StartSync()
+	assertChange(c, chA, watcher.Change{"testA", 1, revno})
+}
+
+func (s *FastPeriodSuite) TestUnwatchCollectionWithSnapshotPending(c *gc.C) {
+	s.insertAll(c, "testA", 1, 2, 3)
+	chA := make(chan watcher.Change)
+	s.w.WatchCollectionWithSnapshot("testA", chA, nil)
+	s.w.UnwatchCollection("testA", chA)
+	assertNoChange(c, chA)
+}
+
 func (s *FastPeriodSuite) TestNonMutatingTxn(c *gc.C) {
 	chA1 := make(chan watcher.Change)
 	chA := make(chan watcher.Change)
@@ -703,3 +900,96 @@ func (s *SlowPeriodSuite) TestStartSyncStartsImmediately(c *gc.C) {
 	case <-time.After(justLongEnough):
 	}
 }
+
+// coalesceWindow is the coalescing window of the watchers
+// started by the coalescing tests.
+const coalesceWindow = 500 * time.Millisecond
+
+func (s *SlowPeriodSuite) startCoalescingWatcher(c *gc.C) *gitjujutesting.Clock {
+	c.Assert(s.w.Stop(), gc.IsNil)
+	clock := gitjujutesting.NewClock(time.Time{})
+	w, err := watcher.NewWithConfig(s.log, watcher.Config{
+		CoalesceWindow: coalesceWindow,
+		Clock:          clock,
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	s.w = w
+	return clock
+}
+
+func (s *SlowPeriodSuite) TestCoalesceWindow(c *gc.C) {
+	clock := s.startCoalescingWatcher(c)
+	revno1 := s.insert(c, "test", "a")
+	s.w.StartSync()
+	s.w.Watch("test", "a", revno1, s.ch)
+
+	revno2 := s.update(c, "test", "a")
+	s.w.StartSync()
+	revno3 := s.update(c, "test", "a")
+	s.w.StartSync()
+	assertNoChange(c, s.ch)
+
+	err := clock.WaitAdvance(coalesceWindow, worstCase, 1)
+	c.Assert(err, jc.ErrorIsNil)
+	assertChange(c, s.ch, watcher.Change{"test", "a", revno3})
+	assertNoChange(c, s.ch)
+
+	assertOrder(c, -1, revno1, revno2, revno3)
+}
+
+func (s *SlowPeriodSuite) TestCoalesceWindowRequestsDoNotFlush(c *gc.C) {
+	clock := s.startCoalescingWatcher(c)
+	revno1 := s.insert(c, "test", "a")
+	s.w.StartSync()
+	s.w.Watch("test", "a", revno1, s.ch)
+
+	revno2 := s.update(c, "test", "a")
+	s.w.StartSync()
+
+	// Watching and unwatching other documents in the
+	// collection does not deliver the held changes.
+	ch := make(chan watcher.Change)
+	s.w.Watch("test", "b", -1, ch)
+	s.w.Unwatch("test", "b", ch)
+	assertNoChange(c, s.ch)
+
+	err := clock.WaitAdvance(coalesceWindow, worstCase, 1)
+	c.Assert(err, jc.ErrorIsNil)
+	assertChange(c, s.ch, watcher.Change{"test", "a", revno2})
+	assertNoChange(c, s.ch)
+}
+
+func (s *SlowPeriodSuite) TestCoalesceWindowWatchImmediate(c *gc.C) {
+	clock := s.startCoalescingWatcher(c)
+	revno1 := s.insert(c, "test", "a")
+	s.w.StartSync()
+	s.w.WatchImmediate("test", "a", revno1, s.ch)
+
+	// The clock is not advanced, so the change
+	// can only be delivered immediately.
+	revno2 := s.update(c, "test", "a")
+	s.w.StartSync()
+	assertChange(c, s.ch, watcher.Change{"test", "a", revno2})
+
+	// Once the immediate watch is stopped, changes are coalesced again.
+	s.w.Unwatch("test", "a", s.ch)
+	s.w.Watch("test", "a", revno2, s.ch)
+	revno3 := s.update(c, "test", "a")
+	s.w.StartSync()
+	assertNoChange(c, s.ch)
+
+	err := clock.WaitAdvance(coalesceWindow, worstCase, 1)
+	c.Assert(err, jc.ErrorIsNil)
+	assertChange(c, s.ch, watcher.Change{"test", "a", revno3})
+}
+
+func (s *FastPeriodSuite) TestNewWithConfigInvalid(c *gc.C) {
+	_, err := watcher.NewWithConfig(s.log, watcher.Config{
+		CoalesceWindow: -time.Second,
+		Clock:          gitjujutesting.NewClock(time.Time{}),
+	})
+	c.Assert(err, gc.ErrorMatches, "negative CoalesceWindow not valid")
+
+	_, err = watcher.NewWithConfig(s.log, watcher.Config{})
+	c.Assert(err, gc.ErrorMatches, "nil Clock not valid")
+}
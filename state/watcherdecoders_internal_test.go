@@ -0,0 +1,42 @@
+// Copyright 2016 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package state
+
+import (
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+	"gopkg.in/juju/names.v2"
+
+	"github.com/juju/juju/state/watcher"
+	"github.com/juju/juju/state/watcher/machinewatcher"
+	"github.com/juju/juju/state/watcher/unitwatcher"
+)
+
+// TestWatcherDecoders checks that the typed change decoders agree
+// with State about collection names and document ids.
+func (s *internalStateSuite) TestWatcherDecoders(c *gc.C) {
+	c.Assert(machinewatcher.Collection, gc.Equals, machinesC)
+	c.Assert(unitwatcher.Collection, gc.Equals, unitsC)
+
+	machineChange, err := machinewatcher.Decode(watcher.Change{
+		C:  machinesC,
+		Id: s.state.docID("0/lxd/1"),
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(machineChange, jc.DeepEquals, machinewatcher.Change{
+		ModelUUID: s.state.ModelUUID(),
+		Tag:       names.NewMachineTag("0/lxd/1"),
+	})
+
+	unitChange, err := unitwatcher.Decode(watcher.Change{
+		C:  unitsC,
+		Id: s.state.docID("mysql/0"),
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(unitChange, jc.DeepEquals, unitwatcher.Change{
+		ModelUUID:   s.state.ModelUUID(),
+		Tag:         names.NewUnitTag("mysql/0"),
+		Application: "mysql",
+	})
+}
@@ -21,8 +21,18 @@ type workersFactory struct {
 }
 
 func (wf workersFactory) NewTxnLogWorker() (workers.TxnLogWorker, error) {
+	config := watcher.Config{Clock: wf.clock}
+	controllerConfig, err := wf.st.ControllerConfig()
+	if err == nil {
+		config.CoalesceWindow = controllerConfig.WatcherCoalesceWindow()
+	} else if !errors.IsNotFound(err) {
+		return nil, errors.Annotate(err, "cannot get controller config")
+	}
 	coll := wf.st.getTxnLogCollection()
-	worker := watcher.New(coll)
+	worker, err := watcher.NewWithConfig(coll, config)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
 	return worker, nil
 }
 
@@ -62,12 +62,21 @@ type TxnLogWatcher interface {
 
 	// single-document watching
 	Watch(coll string, id interface{}, revno int64, ch chan<- watcher.Change)
+	WatchImmediate(coll string, id interface{}, revno int64, ch chan<- watcher.Change)
 	Unwatch(coll string, id interface{}, ch chan<- watcher.Change)
 
 	// collection-watching
 	WatchCollection(coll string, ch chan<- watcher.Change)
 	WatchCollectionWithFilter(coll string, ch chan<- watcher.Change, filter func(interface{}) bool)
+	WatchCollectionWithSnapshot(coll string, ch chan<- watcher.Change, filter func(interface{}) bool)
 	UnwatchCollection(coll string, ch chan<- watcher.Change)
+
+	// owner-watching
+	Owned(owner string) *watcher.OwnedWatcher
+	UnwatchOwner(owner string)
+
+	// introspection
+	Report() map[string]interface{}
 }
 
 // TxnLogWorker includes the watcher.Watcher's worker.Worker methods,
@@ -174,6 +174,10 @@ const (
 
 	// Available indicates that the model is available for use.
 	Available Status = "available"
+
+	// Suspended indicates that the model's provisioning is suspended,
+	// e.g. because the cloud has rejected the model's credential.
+	Suspended Status = "suspended"
 )
 
 const (
@@ -275,6 +279,7 @@ func ValidModelStatus(status Status) bool {
 	switch status {
 	case
 		Available,
+		Suspended,
 		Destroying:
 		return true
 	default:
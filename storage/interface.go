@@ -160,6 +160,27 @@ type FilesystemSource interface {
 	DetachFilesystems(params []FilesystemAttachmentParams) ([]error, error)
 }
 
+// FilesystemResizer is an optional interface that may be implemented
+// by a FilesystemSource whose filesystems are backed by volumes, to
+// grow filesystems to fill their backing volumes after the volumes
+// have grown.
+type FilesystemResizer interface {
+	// ResizeFilesystems grows the filesystems with the specified
+	// parameters to fill their backing volumes.
+	ResizeFilesystems(params []ResizeFilesystemParams) ([]ResizeFilesystemsResult, error)
+}
+
+// VolumeAttachmentLimiter is an optional interface that may be
+// implemented by a VolumeSource whose instances may have only a
+// limited number of volumes attached, e.g. because of limits imposed
+// by the instances' types. The storage provisioner uses it to reject
+// attachments that would exceed the limits before attempting them.
+type VolumeAttachmentLimiter interface {
+	// VolumeAttachmentLimits returns the attachment limits of the
+	// instances with the specified IDs.
+	VolumeAttachmentLimits(instanceIds []instance.Id) ([]VolumeAttachmentLimitsResult, error)
+}
+
 // VolumeParams is a fully specified set of parameters for volume creation,
 // derived from one or more of user-specified storage constraints, a
 // storage pool definition, and charm storage metadata.
@@ -208,6 +229,13 @@ type VolumeAttachmentParams struct {
 	// VolumeId is the unique provider-supplied ID for the volume that
 	// should be attached/detached.
 	VolumeId string
+
+	// DeleteOnTermination indicates that the volume should be deleted
+	// when the instance it is attached to is terminated. This is the
+	// case for volumes whose lifecycle is bound to the machine.
+	// Storage providers that can do so should arrange for the volume
+	// to be deleted along with the instance.
+	DeleteOnTermination bool
 }
 
 // AttachmentParams describes the parameters for attaching a volume or
@@ -258,6 +286,17 @@ type FilesystemParams struct {
 	ResourceTags map[string]string
 }
 
+// ResizeFilesystemParams is a set of parameters for growing a filesystem.
+type ResizeFilesystemParams struct {
+	// Filesystem is the tag of the filesystem to grow.
+	Filesystem names.FilesystemTag
+
+	// Path is the path at which the filesystem is mounted on the
+	// machine, or empty if the filesystem is not mounted. Some
+	// filesystems can only be grown while mounted.
+	Path string
+}
+
 // FilesystemAttachmentParams is a set of parameters for filesystem attachment
 // or detachment.
 type FilesystemAttachmentParams struct {
@@ -299,6 +338,21 @@ type AttachVolumesResult struct {
 	Error            error
 }
 
+// VolumeAttachmentLimitsResult contains the result of a
+// VolumeAttachmentLimiter.VolumeAttachmentLimits call for one instance.
+// Max and Attached should only be used if Error is nil.
+type VolumeAttachmentLimitsResult struct {
+	// Max is the maximum number of volumes that may be attached
+	// to the instance.
+	Max int
+
+	// Attached holds the provider IDs of the volumes currently
+	// attached to the instance, including any not managed by Juju.
+	Attached []string
+
+	Error error
+}
+
 // CreateFilesystemsResult contains the result of a FilesystemSource.CreateFilesystems call
 // for one filesystem. Filesystem should only be used if Error is nil.
 type CreateFilesystemsResult struct {
@@ -306,6 +360,13 @@ type CreateFilesystemsResult struct {
 	Error      error
 }
 
+// ResizeFilesystemsResult contains the result of a FilesystemResizer.ResizeFilesystems
+// call for one filesystem. Filesystem should only be used if Error is nil.
+type ResizeFilesystemsResult struct {
+	Filesystem *Filesystem
+	Error      error
+}
+
 // DescribeFilesystemsResult contains the result of a FilesystemSource.DescribeFilesystems call
 // for one filesystem. Filesystem should only be used if Error is nil.
 type DescribeFilesystemsResult struct {
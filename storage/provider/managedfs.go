@@ -6,6 +6,7 @@ package provider
 import (
 	"path"
 	"path/filepath"
+	"strings"
 	"unicode"
 
 	"github.com/juju/errors"
@@ -167,6 +168,43 @@ func (s *managedFilesystemSource) DetachFilesystems(args []storage.FilesystemAtt
 	return results, nil
 }
 
+// ResizeFilesystems is defined on storage.FilesystemResizer.
+func (s *managedFilesystemSource) ResizeFilesystems(args []storage.ResizeFilesystemParams) ([]storage.ResizeFilesystemsResult, error) {
+	results := make([]storage.ResizeFilesystemsResult, len(args))
+	for i, arg := range args {
+		filesystem, err := s.resizeFilesystem(arg)
+		if err != nil {
+			results[i].Error = err
+			continue
+		}
+		results[i].Filesystem = filesystem
+	}
+	return results, nil
+}
+
+func (s *managedFilesystemSource) resizeFilesystem(arg storage.ResizeFilesystemParams) (*storage.Filesystem, error) {
+	filesystem, ok := s.filesystems[arg.Filesystem]
+	if !ok {
+		return nil, errors.Errorf("filesystem %v is not yet provisioned", arg.Filesystem.Id())
+	}
+	blockDevice, err := s.backingVolumeBlockDevice(filesystem.Volume)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	devicePath := devicePath(blockDevice)
+	if isDiskDevice(devicePath) {
+		if err := growPartition(s.run, devicePath); err != nil {
+			return nil, errors.Trace(err)
+		}
+		devicePath = partitionDevicePath(devicePath)
+	}
+	if err := growFilesystem(s.run, devicePath, arg.Path); err != nil {
+		return nil, errors.Trace(err)
+	}
+	filesystem.Size = blockDevice.Size
+	return &filesystem, nil
+}
+
 func destroyPartitions(run runCommandFunc, devicePath string) error {
 	logger.Debugf("destroying partitions on %q", devicePath)
 	if _, err := run("sgdisk", "--zap-all", devicePath); err != nil {
@@ -185,6 +223,57 @@ func createPartition(run runCommandFunc, devicePath string) error {
 	return nil
 }
 
+// growPartition grows the single partition (1) on the disk with the
+// specified device path to fill the disk.
+func growPartition(run runCommandFunc, devicePath string) error {
+	logger.Debugf("growing partition on %q", devicePath)
+	output, err := run("growpart", devicePath, "1")
+	if err != nil {
+		// growpart exits with an error if the partition
+		// already fills the disk, which is not a problem.
+		if strings.Contains(output, "NOCHANGE") {
+			return nil
+		}
+		return errors.Annotate(err, "growpart failed")
+	}
+	return nil
+}
+
+// growFilesystem grows the filesystem on the device with the specified
+// path to fill the device. The mount point of the filesystem must be
+// specified if the filesystem is mounted.
+func growFilesystem(run runCommandFunc, devicePath, mountPoint string) error {
+	logger.Debugf("attempting to grow filesystem on %q", devicePath)
+	output, err := run("blkid", "-o", "value", "-s", "TYPE", devicePath)
+	if err != nil {
+		return errors.Annotate(err, "blkid failed")
+	}
+	switch filesystemType := strings.TrimSpace(output); filesystemType {
+	case "ext2", "ext3", "ext4":
+		if mountPoint == "" {
+			// resize2fs refuses to grow an unmounted
+			// filesystem that has not been checked.
+			if _, err := run("e2fsck", "-f", "-p", devicePath); err != nil {
+				return errors.Annotate(err, "e2fsck failed")
+			}
+		}
+		if _, err := run("resize2fs", devicePath); err != nil {
+			return errors.Annotate(err, "resize2fs failed")
+		}
+	case "xfs":
+		if mountPoint == "" {
+			return errors.New("cannot grow unmounted xfs filesystem")
+		}
+		if _, err := run("xfs_growfs", mountPoint); err != nil {
+			return errors.Annotate(err, "xfs_growfs failed")
+		}
+	default:
+		return errors.NotSupportedf("growing %q filesystem", filesystemType)
+	}
+	logger.Infof("grew filesystem on %q", devicePath)
+	return nil
+}
+
 func createFilesystem(run runCommandFunc, devicePath string) error {
 	logger.Debugf("attempting to create filesystem on %q", devicePath)
 	mkfscmd := "mkfs." + defaultFilesystemType
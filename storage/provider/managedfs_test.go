@@ -6,6 +6,7 @@ package provider_test
 import (
 	"path/filepath"
 
+	"github.com/juju/errors"
 	jc "github.com/juju/testing/checkers"
 	gc "gopkg.in/check.v1"
 	"gopkg.in/juju/names.v2"
@@ -185,3 +186,127 @@ func (s *managedfsSuite) TestDetachFilesystemsUnattached(c *gc.C) {
 	source := s.initSource(c)
 	testDetachFilesystems(c, s.commands, source, false)
 }
+
+func (s *managedfsSuite) TestResizeFilesystems(c *gc.C) {
+	source := s.initSource(c)
+	// sda's partition is grown, and then the filesystem on
+	// the partition; the filesystem is mounted, so it is
+	// grown online.
+	s.commands.expect("growpart", "/dev/sda", "1")
+	s.commands.expect("blkid", "-o", "value", "-s", "TYPE", "/dev/sda1").respond("ext4\n", nil)
+	s.commands.expect("resize2fs", "/dev/sda1")
+	// xvdf1 is not partitioned, and the filesystem is not
+	// mounted, so it must be checked before it is grown.
+	s.commands.expect("blkid", "-o", "value", "-s", "TYPE", "/dev/xvdf1").respond("ext4\n", nil)
+	s.commands.expect("e2fsck", "-f", "-p", "/dev/xvdf1")
+	s.commands.expect("resize2fs", "/dev/xvdf1")
+
+	s.blockDevices[names.NewVolumeTag("0")] = storage.BlockDevice{
+		DeviceName: "sda",
+		Size:       4,
+	}
+	s.blockDevices[names.NewVolumeTag("1")] = storage.BlockDevice{
+		DeviceName: "xvdf1",
+		Size:       6,
+	}
+	s.filesystems[names.NewFilesystemTag("0/0")] = storage.Filesystem{
+		Tag:    names.NewFilesystemTag("0/0"),
+		Volume: names.NewVolumeTag("0"),
+		FilesystemInfo: storage.FilesystemInfo{
+			FilesystemId: "filesystem-0-0",
+			Size:         2,
+		},
+	}
+	s.filesystems[names.NewFilesystemTag("0/1")] = storage.Filesystem{
+		Tag:    names.NewFilesystemTag("0/1"),
+		Volume: names.NewVolumeTag("1"),
+		FilesystemInfo: storage.FilesystemInfo{
+			FilesystemId: "filesystem-0-1",
+			Size:         3,
+		},
+	}
+	results, err := source.(storage.FilesystemResizer).ResizeFilesystems([]storage.ResizeFilesystemParams{{
+		Filesystem: names.NewFilesystemTag("0/0"),
+		Path:       "/in/the/place",
+	}, {
+		Filesystem: names.NewFilesystemTag("0/1"),
+	}})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(results, jc.DeepEquals, []storage.ResizeFilesystemsResult{{
+		Filesystem: &storage.Filesystem{
+			names.NewFilesystemTag("0/0"),
+			names.NewVolumeTag("0"),
+			storage.FilesystemInfo{
+				FilesystemId: "filesystem-0-0",
+				Size:         4,
+			},
+		},
+	}, {
+		Filesystem: &storage.Filesystem{
+			names.NewFilesystemTag("0/1"),
+			names.NewVolumeTag("1"),
+			storage.FilesystemInfo{
+				FilesystemId: "filesystem-0-1",
+				Size:         6,
+			},
+		},
+	}})
+}
+
+func (s *managedfsSuite) TestResizeFilesystemsXFS(c *gc.C) {
+	source := s.initSource(c)
+	s.commands.expect("growpart", "/dev/sda", "1").respond(
+		"NOCHANGE: partition 1 could only be grown by 0", errors.New("exit status 1"),
+	)
+	s.commands.expect("blkid", "-o", "value", "-s", "TYPE", "/dev/sda1").respond("xfs\n", nil)
+	s.commands.expect("xfs_growfs", "/in/the/place")
+
+	s.blockDevices[names.NewVolumeTag("0")] = storage.BlockDevice{
+		DeviceName: "sda",
+		Size:       4,
+	}
+	s.filesystems[names.NewFilesystemTag("0/0")] = storage.Filesystem{
+		Tag:    names.NewFilesystemTag("0/0"),
+		Volume: names.NewVolumeTag("0"),
+		FilesystemInfo: storage.FilesystemInfo{
+			FilesystemId: "filesystem-0-0",
+			Size:         2,
+		},
+	}
+	results, err := source.(storage.FilesystemResizer).ResizeFilesystems([]storage.ResizeFilesystemParams{{
+		Filesystem: names.NewFilesystemTag("0/0"),
+		Path:       "/in/the/place",
+	}})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(results, gc.HasLen, 1)
+	c.Assert(results[0].Error, jc.ErrorIsNil)
+	c.Assert(results[0].Filesystem.Size, gc.Equals, uint64(4))
+}
+
+func (s *managedfsSuite) TestResizeFilesystemsXFSUnmounted(c *gc.C) {
+	source := s.initSource(c)
+	s.commands.expect("blkid", "-o", "value", "-s", "TYPE", "/dev/xvdf1").respond("xfs\n", nil)
+
+	s.blockDevices[names.NewVolumeTag("0")] = storage.BlockDevice{
+		DeviceName: "xvdf1",
+		Size:       4,
+	}
+	s.filesystems[names.NewFilesystemTag("0/0")] = storage.Filesystem{
+		Tag:    names.NewFilesystemTag("0/0"),
+		Volume: names.NewVolumeTag("0"),
+	}
+	results, err := source.(storage.FilesystemResizer).ResizeFilesystems([]storage.ResizeFilesystemParams{{
+		Filesystem: names.NewFilesystemTag("0/0"),
+	}})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(results[0].Error, gc.ErrorMatches, "cannot grow unmounted xfs filesystem")
+}
+
+func (s *managedfsSuite) TestResizeFilesystemsNotProvisioned(c *gc.C) {
+	source := s.initSource(c)
+	results, err := source.(storage.FilesystemResizer).ResizeFilesystems([]storage.ResizeFilesystemParams{{
+		Filesystem: names.NewFilesystemTag("0/0"),
+	}})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(results[0].Error, gc.ErrorMatches, "filesystem 0/0 is not yet provisioned")
+}
@@ -0,0 +1,202 @@
+// Copyright 2016 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+// Package delta implements binary deltas between versions of the
+// agent binaries, so that an agent may upgrade by downloading only
+// the differences from the binaries that it already has.
+//
+// A delta is a sequence of instructions that either copy a range of
+// the base content, or add literal content, in the manner of xdelta
+// (RFC 3284), though the encoding is simpler and specific to Juju.
+package delta
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"io"
+	"math"
+
+	"github.com/juju/errors"
+)
+
+const (
+	// magic identifies the encoding of a delta.
+	magic = "jujudelta1\n"
+
+	// blockSize is the size of the base content's blocks that are
+	// indexed for matching. Smaller blocks find more matches, at
+	// the cost of a larger index and shorter copies.
+	blockSize = 32
+
+	// prime is the multiplier of the rolling hash.
+	prime = 16777619
+
+	opCopy = 'c'
+	opAdd  = 'a'
+)
+
+// Diff returns a delta that transforms base into target.
+func Diff(base, target []byte) []byte {
+	var buf bytes.Buffer
+	buf.WriteString(magic)
+	writeUvarint(&buf, uint64(len(target)))
+
+	// Index the hashes of the base's aligned blocks, recording
+	// the first offset of each.
+	index := make(map[uint32]int)
+	for off := 0; off+blockSize <= len(base); off += blockSize {
+		h := hashBlock(base[off : off+blockSize])
+		if _, ok := index[h]; !ok {
+			index[h] = off
+		}
+	}
+
+	// Scan every offset of the target for a block matching one in
+	// the index, rolling the hash forward a byte at a time. Matches
+	// are extended in both directions as far as the content agrees.
+	var pending int
+	var h uint32
+	if len(target) >= blockSize {
+		h = hashBlock(target[:blockSize])
+	}
+	pow := power()
+	for i := 0; i+blockSize <= len(target); {
+		off, ok := index[h]
+		if ok && bytes.Equal(base[off:off+blockSize], target[i:i+blockSize]) {
+			start, baseStart := i, off
+			for start > pending && baseStart > 0 && target[start-1] == base[baseStart-1] {
+				start--
+				baseStart--
+			}
+			end, baseEnd := i+blockSize, off+blockSize
+			for end < len(target) && baseEnd < len(base) && target[end] == base[baseEnd] {
+				end++
+				baseEnd++
+			}
+			writeAdd(&buf, target[pending:start])
+			writeCopy(&buf, baseStart, end-start)
+			pending, i = end, end
+			if i+blockSize <= len(target) {
+				h = hashBlock(target[i : i+blockSize])
+			}
+			continue
+		}
+		if i+blockSize < len(target) {
+			h = h*prime + uint32(target[i+blockSize]) - pow*uint32(target[i])
+		}
+		i++
+	}
+	writeAdd(&buf, target[pending:])
+	return buf.Bytes()
+}
+
+// Apply applies the given delta to base, and returns the result.
+// Apply returns an error satisfying errors.IsNotValid if the delta
+// is malformed, or does not apply to base.
+func Apply(base, delta []byte) ([]byte, error) {
+	if !bytes.HasPrefix(delta, []byte(magic)) {
+		return nil, errors.NotValidf("delta header")
+	}
+	r := bufio.NewReader(bytes.NewReader(delta[len(magic):]))
+	size, err := binary.ReadUvarint(r)
+	if err != nil {
+		return nil, invalidDelta(err)
+	}
+	if size > math.MaxInt64 {
+		return nil, errors.NotValidf("delta target size %d", size)
+	}
+	// The result grows only as content is copied or read from the
+	// delta, so that a bad delta cannot make us allocate arbitrary
+	// amounts of memory.
+	var result bytes.Buffer
+	for {
+		op, err := r.ReadByte()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, invalidDelta(err)
+		}
+		var n uint64
+		switch op {
+		case opCopy:
+			off, err := binary.ReadUvarint(r)
+			if err != nil {
+				return nil, invalidDelta(err)
+			}
+			if n, err = binary.ReadUvarint(r); err != nil {
+				return nil, invalidDelta(err)
+			}
+			if off > uint64(len(base)) || n > uint64(len(base))-off {
+				return nil, errors.NotValidf("copy outside base content")
+			}
+			if n > size-uint64(result.Len()) {
+				return nil, errors.NotValidf("delta exceeding target size")
+			}
+			result.Write(base[off : off+n])
+		case opAdd:
+			if n, err = binary.ReadUvarint(r); err != nil {
+				return nil, invalidDelta(err)
+			}
+			if n > size-uint64(result.Len()) {
+				return nil, errors.NotValidf("delta exceeding target size")
+			}
+			if _, err := io.CopyN(&result, r, int64(n)); err != nil {
+				return nil, invalidDelta(err)
+			}
+		default:
+			return nil, errors.NotValidf("delta instruction %q", op)
+		}
+	}
+	if uint64(result.Len()) != size {
+		return nil, errors.NotValidf("delta of %d bytes producing %d", size, result.Len())
+	}
+	return result.Bytes(), nil
+}
+
+func invalidDelta(err error) error {
+	if err == io.EOF {
+		err = io.ErrUnexpectedEOF
+	}
+	return errors.NewNotValid(err, "delta")
+}
+
+// hashBlock returns the rolling hash of the given block.
+func hashBlock(block []byte) uint32 {
+	var h uint32
+	for _, b := range block {
+		h = h*prime + uint32(b)
+	}
+	return h
+}
+
+// power returns prime raised to blockSize, which is the weight of the
+// byte leaving the rolling hash's window.
+func power() uint32 {
+	pow := uint32(1)
+	for i := 0; i < blockSize; i++ {
+		pow *= prime
+	}
+	return pow
+}
+
+func writeCopy(buf *bytes.Buffer, off, n int) {
+	buf.WriteByte(opCopy)
+	writeUvarint(buf, uint64(off))
+	writeUvarint(buf, uint64(n))
+}
+
+func writeAdd(buf *bytes.Buffer, data []byte) {
+	if len(data) == 0 {
+		return
+	}
+	buf.WriteByte(opAdd)
+	writeUvarint(buf, uint64(len(data)))
+	buf.Write(data)
+}
+
+func writeUvarint(buf *bytes.Buffer, v uint64) {
+	var tmp [binary.MaxVarintLen64]byte
+	n := binary.PutUvarint(tmp[:], v)
+	buf.Write(tmp[:n])
+}
@@ -0,0 +1,101 @@
+// Copyright 2016 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package delta_test
+
+import (
+	"bytes"
+	"math/rand"
+
+	"github.com/juju/errors"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/tools/delta"
+)
+
+type deltaSuite struct{}
+
+var _ = gc.Suite(&deltaSuite{})
+
+func randomBytes(seed int64, n int) []byte {
+	data := make([]byte, n)
+	r := rand.New(rand.NewSource(seed))
+	for i := range data {
+		data[i] = byte(r.Intn(256))
+	}
+	return data
+}
+
+func (s *deltaSuite) checkRoundTrip(c *gc.C, base, target []byte) []byte {
+	d := delta.Diff(base, target)
+	result, err := delta.Apply(base, d)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(bytes.Equal(result, target), jc.IsTrue)
+	return d
+}
+
+func (s *deltaSuite) TestRoundTrip(c *gc.C) {
+	for i, test := range []struct {
+		base, target []byte
+	}{
+		{nil, nil},
+		{nil, []byte("hello")},
+		{[]byte("hello"), nil},
+		{[]byte("hello"), []byte("hello")},
+		{randomBytes(0, 100), randomBytes(1, 100)},
+		{randomBytes(0, 4096), randomBytes(0, 4096)},
+	} {
+		c.Logf("test %d", i)
+		s.checkRoundTrip(c, test.base, test.target)
+	}
+}
+
+func (s *deltaSuite) TestSimilarContent(c *gc.C) {
+	// Insert, change and remove content, as a new version of a
+	// binary would, and shift the remainder.
+	base := randomBytes(0, 64*1024)
+	var target []byte
+	target = append(target, base[:10000]...)
+	target = append(target, randomBytes(1, 100)...)
+	target = append(target, base[10000:30000]...)
+	target = append(target, randomBytes(2, 50)...)
+	target = append(target, base[30050:60000]...)
+	target = append(target, base[:1000]...)
+
+	d := s.checkRoundTrip(c, base, target)
+	c.Assert(len(d) < 512, jc.IsTrue, gc.Commentf("delta of %d bytes", len(d)))
+}
+
+func (s *deltaSuite) TestApplyInvalid(c *gc.C) {
+	base := randomBytes(0, 1024)
+	target := append(randomBytes(1, 100), base...)
+	d := delta.Diff(base, target)
+
+	for i, test := range []struct {
+		base   []byte
+		delta  []byte
+		expect string
+	}{{
+		base:   base,
+		delta:  []byte("rubbish"),
+		expect: "delta header not valid",
+	}, {
+		base:   base,
+		delta:  d[:len(d)-1],
+		expect: "delta: unexpected EOF",
+	}, {
+		base:   base[:512],
+		delta:  d,
+		expect: "copy outside base content not valid",
+	}, {
+		base:   base,
+		delta:  append(d, 'x'),
+		expect: `delta instruction 'x' not valid`,
+	}} {
+		c.Logf("test %d", i)
+		_, err := delta.Apply(test.base, test.delta)
+		c.Check(err, gc.ErrorMatches, test.expect)
+		c.Check(err, jc.Satisfies, errors.IsNotValid)
+	}
+}
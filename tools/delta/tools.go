@@ -0,0 +1,214 @@
+// Copyright 2016 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package delta
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/binary"
+	"io"
+	"io/ioutil"
+	"math"
+	"os"
+	"strings"
+
+	"github.com/juju/errors"
+)
+
+// toolsMagic identifies the encoding of a delta between sets of
+// agent binary files.
+const toolsMagic = "jujutoolsdelta1\n"
+
+// File is a regular file held in an agent binary tarball.
+type File struct {
+	Name string
+	Mode os.FileMode
+	Data []byte
+}
+
+// ReadTarball returns the regular files held in the given gzipped
+// agent binary tarball.
+func ReadTarball(r io.Reader) ([]File, error) {
+	zr, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	defer zr.Close()
+	var files []File
+	tr := tar.NewReader(zr)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, errors.Trace(err)
+		}
+		if err := checkName(hdr.Name); err != nil {
+			return nil, errors.Trace(err)
+		}
+		if hdr.Typeflag != tar.TypeReg {
+			return nil, errors.Errorf("bad file type %c in file %q in tools archive", hdr.Typeflag, hdr.Name)
+		}
+		data, err := ioutil.ReadAll(tr)
+		if err != nil {
+			return nil, errors.Annotatef(err, "reading %q", hdr.Name)
+		}
+		files = append(files, File{
+			Name: hdr.Name,
+			Mode: os.FileMode(hdr.Mode & 0777),
+			Data: data,
+		})
+	}
+	return files, nil
+}
+
+// DiffTools returns a gzipped delta that transforms the base agent
+// binary files into the target files. Each target file is diffed
+// against the base file with the same name, if there is one.
+func DiffTools(base, target []File) ([]byte, error) {
+	baseData := make(map[string][]byte)
+	for _, f := range base {
+		baseData[f.Name] = f.Data
+	}
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	w := bufio.NewWriter(zw)
+	w.WriteString(toolsMagic)
+	writeUvarintTo(w, uint64(len(target)))
+	for _, f := range target {
+		data, hasBase := baseData[f.Name]
+		writeUvarintTo(w, uint64(len(f.Name)))
+		w.WriteString(f.Name)
+		writeUvarintTo(w, uint64(f.Mode))
+		if hasBase {
+			w.WriteByte(1)
+		} else {
+			w.WriteByte(0)
+		}
+		hash := sha256.Sum256(f.Data)
+		w.Write(hash[:])
+		delta := Diff(data, f.Data)
+		writeUvarintTo(w, uint64(len(delta)))
+		w.Write(delta)
+	}
+	if err := w.Flush(); err != nil {
+		return nil, errors.Trace(err)
+	}
+	if err := zw.Close(); err != nil {
+		return nil, errors.Trace(err)
+	}
+	return buf.Bytes(), nil
+}
+
+// ApplyTools reads a delta produced by DiffTools from r, and applies
+// it to the base agent binary files, whose content is returned by
+// readBase. The SHA-256 hash of each resulting file is verified
+// against that recorded in the delta. ApplyTools returns an error
+// satisfying errors.IsNotValid if the delta is malformed, or does not
+// apply to the base files.
+func ApplyTools(r io.Reader, readBase func(name string) ([]byte, error)) ([]File, error) {
+	zr, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, errors.NewNotValid(err, "tools delta")
+	}
+	defer zr.Close()
+	br := bufio.NewReader(zr)
+	header := make([]byte, len(toolsMagic))
+	if _, err := io.ReadFull(br, header); err != nil || string(header) != toolsMagic {
+		return nil, errors.NotValidf("tools delta header")
+	}
+	n, err := binary.ReadUvarint(br)
+	if err != nil {
+		return nil, invalidToolsDelta(err)
+	}
+	var files []File
+	for i := uint64(0); i < n; i++ {
+		name, err := readString(br)
+		if err != nil {
+			return nil, invalidToolsDelta(err)
+		}
+		if err := checkName(name); err != nil {
+			return nil, errors.NewNotValid(err, "tools delta")
+		}
+		mode, err := binary.ReadUvarint(br)
+		if err != nil {
+			return nil, invalidToolsDelta(err)
+		}
+		hasBase, err := br.ReadByte()
+		if err != nil {
+			return nil, invalidToolsDelta(err)
+		}
+		var hash [sha256.Size]byte
+		if _, err := io.ReadFull(br, hash[:]); err != nil {
+			return nil, invalidToolsDelta(err)
+		}
+		delta, err := readString(br)
+		if err != nil {
+			return nil, invalidToolsDelta(err)
+		}
+
+		var base []byte
+		if hasBase != 0 {
+			if base, err = readBase(name); err != nil {
+				return nil, errors.Annotatef(err, "reading base file %q", name)
+			}
+		}
+		data, err := Apply(base, []byte(delta))
+		if err != nil {
+			return nil, errors.Annotatef(err, "applying delta to %q", name)
+		}
+		if sha256.Sum256(data) != hash {
+			return nil, errors.NotValidf("%q sha256", name)
+		}
+		files = append(files, File{
+			Name: name,
+			Mode: os.FileMode(mode) & 0777,
+			Data: data,
+		})
+	}
+	return files, nil
+}
+
+// checkName returns an error if the given name is not a valid name
+// for a file in an agent binary tarball.
+func checkName(name string) error {
+	if name == "" || strings.ContainsAny(name, "/\\") {
+		return errors.Errorf("bad name %q in tools archive", name)
+	}
+	return nil
+}
+
+func invalidToolsDelta(err error) error {
+	if err == io.EOF {
+		err = io.ErrUnexpectedEOF
+	}
+	return errors.NewNotValid(err, "tools delta")
+}
+
+// readString reads a length-prefixed string. The content is copied
+// as it is read, so that a bad length cannot make us allocate
+// arbitrary amounts of memory.
+func readString(r *bufio.Reader) (string, error) {
+	n, err := binary.ReadUvarint(r)
+	if err != nil {
+		return "", err
+	}
+	if n > math.MaxInt64 {
+		return "", errors.New("length out of range")
+	}
+	var buf bytes.Buffer
+	if _, err := io.CopyN(&buf, r, int64(n)); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+func writeUvarintTo(w *bufio.Writer, v uint64) {
+	var tmp [binary.MaxVarintLen64]byte
+	n := binary.PutUvarint(tmp[:], v)
+	w.Write(tmp[:n])
+}
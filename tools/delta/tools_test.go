@@ -0,0 +1,95 @@
+// Copyright 2016 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package delta_test
+
+import (
+	"bytes"
+	"os"
+
+	"github.com/juju/errors"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/testing"
+	"github.com/juju/juju/tools/delta"
+)
+
+type toolsSuite struct{}
+
+var _ = gc.Suite(&toolsSuite{})
+
+func (s *toolsSuite) TestReadTarball(c *gc.C) {
+	tarball, _ := testing.TarGz(
+		testing.NewTarFile("jujud", 0755, "jujud contents"),
+		testing.NewTarFile("FORCE-VERSION", 0644, "2.0.1"),
+	)
+	files, err := delta.ReadTarball(bytes.NewReader(tarball))
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(files, jc.DeepEquals, []delta.File{
+		{Name: "jujud", Mode: 0755, Data: []byte("jujud contents")},
+		{Name: "FORCE-VERSION", Mode: 0644, Data: []byte("2.0.1")},
+	})
+}
+
+func (s *toolsSuite) TestReadTarballBadName(c *gc.C) {
+	tarball, _ := testing.TarGz(testing.NewTarFile("bin/jujud", 0755, "jujud contents"))
+	_, err := delta.ReadTarball(bytes.NewReader(tarball))
+	c.Assert(err, gc.ErrorMatches, `bad name "bin/jujud" in tools archive`)
+}
+
+func (s *toolsSuite) TestDiffApplyTools(c *gc.C) {
+	jujud := randomBytes(0, 16*1024)
+	base := []delta.File{
+		{Name: "jujud", Mode: 0755, Data: jujud},
+		{Name: "removed", Mode: 0644, Data: []byte("old")},
+	}
+	target := []delta.File{
+		{Name: "jujud", Mode: 0755, Data: append(append([]byte{}, jujud...), "more"...)},
+		{Name: "added", Mode: 0644, Data: []byte("new")},
+	}
+	d, err := delta.DiffTools(base, target)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(len(d) < 1024, jc.IsTrue, gc.Commentf("delta of %d bytes", len(d)))
+
+	var read []string
+	files, err := delta.ApplyTools(bytes.NewReader(d), func(name string) ([]byte, error) {
+		read = append(read, name)
+		c.Assert(name, gc.Equals, "jujud")
+		return jujud, nil
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(files, jc.DeepEquals, target)
+	c.Assert(read, jc.DeepEquals, []string{"jujud"})
+}
+
+func (s *toolsSuite) TestApplyToolsBaseMismatch(c *gc.C) {
+	base := []delta.File{{Name: "jujud", Mode: 0755, Data: randomBytes(0, 1024)}}
+	target := []delta.File{{Name: "jujud", Mode: 0755, Data: randomBytes(0, 2048)}}
+	d, err := delta.DiffTools(base, target)
+	c.Assert(err, jc.ErrorIsNil)
+
+	// The base content is the same length, but different,
+	// so the delta applies but produces the wrong result.
+	_, err = delta.ApplyTools(bytes.NewReader(d), func(string) ([]byte, error) {
+		return randomBytes(1, 1024), nil
+	})
+	c.Assert(err, gc.ErrorMatches, `"jujud" sha256 not valid`)
+	c.Assert(err, jc.Satisfies, errors.IsNotValid)
+}
+
+func (s *toolsSuite) TestApplyToolsBaseError(c *gc.C) {
+	base := []delta.File{{Name: "jujud", Mode: 0755, Data: []byte("old")}}
+	target := []delta.File{{Name: "jujud", Mode: 0755, Data: []byte("new")}}
+	d, err := delta.DiffTools(base, target)
+	c.Assert(err, jc.ErrorIsNil)
+	_, err = delta.ApplyTools(bytes.NewReader(d), func(string) ([]byte, error) {
+		return nil, os.ErrNotExist
+	})
+	c.Assert(err, gc.ErrorMatches, `reading base file "jujud": file does not exist`)
+}
+
+func (s *toolsSuite) TestApplyToolsInvalid(c *gc.C) {
+	_, err := delta.ApplyTools(bytes.NewReader([]byte("rubbish")), nil)
+	c.Assert(err, jc.Satisfies, errors.IsNotValid)
+}
@@ -18,7 +18,7 @@ import (
 // (below).
 var stateUpgradeOperations = func() []Operation {
 	steps := []Operation{
-		upgradeToVersion{version.MustParse("2.0.0"), []Step{}},
+		upgradeToVersion{version.MustParse("2.0.0"), stateStepsFor20()},
 	}
 	return steps
 }
@@ -14,6 +14,7 @@ import (
 	gc "gopkg.in/check.v1"
 
 	"github.com/juju/juju/testing"
+	"github.com/juju/juju/upgrades"
 )
 
 var v200 = version.MustParse("2.0.0")
@@ -50,6 +51,11 @@ func (s *steps20Suite) TestCharmGetCacheDir(c *gc.C) {
 	check() // Check OK when directory not present
 }
 
+func (s *steps20Suite) TestStateStepsFor20(c *gc.C) {
+	step := findStateStep(c, v200, "add workload versions to applications")
+	c.Assert(step.Targets(), jc.DeepEquals, []upgrades.Target{upgrades.DatabaseMaster})
+}
+
 func pathExists(p string) bool {
 	_, err := os.Stat(p)
 	if err == nil {
@@ -0,0 +1,32 @@
+// Copyright 2016 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package apirelay
+
+import (
+	"path/filepath"
+
+	"github.com/juju/juju/api"
+)
+
+// SocketPath returns the path of the socket on which the relay of
+// the machine agent with the given data directory listens.
+func SocketPath(dataDir string) string {
+	return filepath.Join(dataDir, "api-relay.socket")
+}
+
+// NewOpenFunc returns an api.OpenFunc that connects through the relay
+// listening on the socket at the given path, falling back to opening
+// a connection with the given function if the relay cannot be used:
+// because there is none, say, or because the API server does not let
+// the relay's machine agent call on the agent's behalf.
+func NewOpenFunc(socketPath string, fallback api.OpenFunc) api.OpenFunc {
+	return func(info *api.Info, opts api.DialOpts) (api.Connection, error) {
+		conn, err := api.OpenRelayed(info, opts, socketPath)
+		if err == nil {
+			return conn, nil
+		}
+		logger.Debugf("cannot connect through API relay, connecting directly: %v", err)
+		return fallback(info, opts)
+	}
+}
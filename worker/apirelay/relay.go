@@ -0,0 +1,200 @@
+// Copyright 2016 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+// Package apirelay provides a worker that lets the unit agents on a
+// machine make their API calls over the machine agent's connection to
+// the API server, rather than each holding a connection of its own.
+//
+// The relay listens on a unix socket that only root may connect to.
+// A unit agent logs in to the relay as it would to the API server, and
+// the relay makes each of the unit agent's subsequent calls over the
+// machine agent's connection, on behalf of the unit; the API server
+// authorizes them as though the unit agent had made them itself. The
+// relay does not check the unit agent's password: whoever can connect
+// to the socket can read the unit agents' credentials anyway.
+package apirelay
+
+import (
+	"net"
+	"os"
+	"sync"
+
+	"github.com/juju/errors"
+	"github.com/juju/loggo"
+	"gopkg.in/juju/names.v2"
+
+	"github.com/juju/juju/api"
+	"github.com/juju/juju/api/base"
+	"github.com/juju/juju/apiserver/observer"
+	"github.com/juju/juju/juju/sockets"
+	"github.com/juju/juju/rpc"
+	"github.com/juju/juju/rpc/jsoncodec"
+	"github.com/juju/juju/worker/catacomb"
+)
+
+var logger = loggo.GetLogger("juju.worker.apirelay")
+
+// errUpstreamBroken is returned by a relay when the API connection
+// over which it makes calls is broken.
+var errUpstreamBroken = errors.New("upstream API connection broken")
+
+// Config holds the dependencies and configuration for a relay.
+type Config struct {
+	// SocketPath is the path of the unix socket on
+	// which the relay listens for connections.
+	SocketPath string
+
+	// Upstream is the API connection over which the
+	// relay makes the calls of its connected agents.
+	Upstream api.Connection
+
+	// NewDelegatedAPICaller returns a base.APICaller that makes
+	// calls over the given connection on behalf of the entity
+	// with the given tag.
+	NewDelegatedAPICaller func(api.Connection, names.Tag) (base.APICaller, error)
+}
+
+// Validate returns an error if the config cannot be expected to
+// drive a functional relay.
+func (config Config) Validate() error {
+	if config.SocketPath == "" {
+		return errors.NotValidf("empty SocketPath")
+	}
+	if config.Upstream == nil {
+		return errors.NotValidf("nil Upstream")
+	}
+	if config.NewDelegatedAPICaller == nil {
+		return errors.NotValidf("nil NewDelegatedAPICaller")
+	}
+	return nil
+}
+
+// New returns a Worker that relays API calls made by the agents
+// connected to the configured socket over the upstream connection,
+// until it is killed or the upstream connection is broken.
+func New(config Config) (*Worker, error) {
+	if err := config.Validate(); err != nil {
+		return nil, errors.Trace(err)
+	}
+	listener, err := sockets.Listen(config.SocketPath)
+	if err != nil {
+		return nil, errors.Annotate(err, "cannot listen for API relay connections")
+	}
+	if err := os.Chmod(config.SocketPath, 0600); err != nil {
+		listener.Close()
+		return nil, errors.Annotate(err, "cannot restrict access to API relay socket")
+	}
+	logger.Debugf("relaying API calls from %q", config.SocketPath)
+
+	w := &Worker{
+		config:   config,
+		listener: listener,
+		conns:    make(map[net.Conn]bool),
+	}
+	err = catacomb.Invoke(catacomb.Plan{
+		Site: &w.catacomb,
+		Work: w.loop,
+	})
+	if err != nil {
+		listener.Close()
+		return nil, errors.Trace(err)
+	}
+	return w, nil
+}
+
+// Worker relays API calls from the agents connected to its socket.
+type Worker struct {
+	catacomb catacomb.Catacomb
+	config   Config
+	listener net.Listener
+
+	// mu guards conns, which holds the connections of the
+	// connected agents, which are closed when the worker
+	// stops.
+	mu    sync.Mutex
+	conns map[net.Conn]bool
+}
+
+// Kill is part of the worker.Worker interface.
+func (w *Worker) Kill() {
+	w.catacomb.Kill(nil)
+}
+
+// Wait is part of the worker.Worker interface.
+func (w *Worker) Wait() error {
+	return w.catacomb.Wait()
+}
+
+func (w *Worker) loop() error {
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		w.accept()
+	}()
+	defer func() {
+		w.listener.Close()
+		<-done
+		w.closeConns()
+	}()
+
+	select {
+	case <-w.catacomb.Dying():
+		return w.catacomb.ErrDying()
+	case <-w.config.Upstream.Broken():
+		return errUpstreamBroken
+	}
+}
+
+// accept serves the connections made to the listener until
+// it is closed, killing the worker if it fails before then.
+func (w *Worker) accept() {
+	for {
+		conn, err := w.listener.Accept()
+		if err != nil {
+			select {
+			case <-w.catacomb.Dying():
+			default:
+				w.catacomb.Kill(errors.Annotate(err, "cannot accept API relay connection"))
+			}
+			return
+		}
+		w.serve(conn)
+	}
+}
+
+// serve relays the API calls made over the given connection
+// until the agent closes it or the worker stops.
+func (w *Worker) serve(conn net.Conn) {
+	rpcConn := rpc.NewConn(jsoncodec.NewNet(conn), observer.None())
+	rpcConn.ServeRoot(newRelayRoot(w.config), nil)
+
+	w.mu.Lock()
+	w.conns[conn] = true
+	w.mu.Unlock()
+
+	rpcConn.Start()
+	go func() {
+		<-rpcConn.Dead()
+		w.mu.Lock()
+		delete(w.conns, conn)
+		w.mu.Unlock()
+		// Close waits for the calls being relayed to
+		// complete, which they do once the upstream
+		// connection is closed, if not before.
+		if err := rpcConn.Close(); err != nil {
+			logger.Debugf("closing API relay connection: %v", err)
+		}
+	}()
+}
+
+// closeConns closes the connections of all connected agents.
+// Calls being relayed for them are abandoned.
+func (w *Worker) closeConns() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	for conn := range w.conns {
+		if err := conn.Close(); err != nil {
+			logger.Debugf("closing API relay connection: %v", err)
+		}
+	}
+}
@@ -0,0 +1,323 @@
+// Copyright 2016 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package apirelay_test
+
+import (
+	"encoding/json"
+	"net"
+	"path/filepath"
+	"time"
+
+	"github.com/juju/errors"
+	jujutesting "github.com/juju/testing"
+	jc "github.com/juju/testing/checkers"
+	"github.com/juju/version"
+	gc "gopkg.in/check.v1"
+	"gopkg.in/juju/names.v2"
+
+	"github.com/juju/juju/api"
+	"github.com/juju/juju/api/base"
+	"github.com/juju/juju/apiserver/observer"
+	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/network"
+	"github.com/juju/juju/rpc"
+	"github.com/juju/juju/rpc/jsoncodec"
+	coretesting "github.com/juju/juju/testing"
+	"github.com/juju/juju/worker/apirelay"
+	"github.com/juju/juju/worker/workertest"
+)
+
+var (
+	modelTag      = names.NewModelTag("deadbeef-0bad-400d-8000-4b1d0d06f00d")
+	controllerTag = names.NewControllerTag("deadbeef-1bad-500d-9000-4b1d0d06f00d")
+	unitTag       = names.NewUnitTag("mysql/0")
+)
+
+type RelaySuite struct {
+	jujutesting.IsolationSuite
+
+	stub     *jujutesting.Stub
+	upstream *stubConnection
+	config   apirelay.Config
+}
+
+var _ = gc.Suite(&RelaySuite{})
+
+func (s *RelaySuite) SetUpTest(c *gc.C) {
+	s.IsolationSuite.SetUpTest(c)
+	s.stub = new(jujutesting.Stub)
+	s.upstream = &stubConnection{
+		broken: make(chan struct{}),
+	}
+	s.config = apirelay.Config{
+		SocketPath: filepath.Join(c.MkDir(), "relay.socket"),
+		Upstream:   s.upstream,
+		NewDelegatedAPICaller: func(conn api.Connection, tag names.Tag) (base.APICaller, error) {
+			s.stub.AddCall("NewDelegatedAPICaller", conn, tag)
+			if err := s.stub.NextErr(); err != nil {
+				return nil, err
+			}
+			return &stubCaller{stub: s.stub, upstream: s.upstream, tag: tag}, nil
+		},
+	}
+}
+
+func (s *RelaySuite) startRelay(c *gc.C) *apirelay.Worker {
+	w, err := apirelay.New(s.config)
+	c.Assert(err, jc.ErrorIsNil)
+	s.AddCleanup(func(c *gc.C) { workertest.DirtyKill(c, w) })
+	return w
+}
+
+func (s *RelaySuite) openRelayed(c *gc.C, tag names.Tag) (api.Connection, error) {
+	return api.OpenRelayed(&api.Info{
+		Addrs:    []string{"0.1.2.3:17070"},
+		Tag:      tag,
+		Password: "hunter2",
+		ModelTag: modelTag,
+	}, api.DialOpts{}, s.config.SocketPath)
+}
+
+func (s *RelaySuite) TestValidate(c *gc.C) {
+	config := s.config
+	config.SocketPath = ""
+	_, err := apirelay.New(config)
+	c.Check(err, gc.ErrorMatches, "empty SocketPath not valid")
+
+	config = s.config
+	config.Upstream = nil
+	_, err = apirelay.New(config)
+	c.Check(err, gc.ErrorMatches, "nil Upstream not valid")
+
+	config = s.config
+	config.NewDelegatedAPICaller = nil
+	_, err = apirelay.New(config)
+	c.Check(err, gc.ErrorMatches, "nil NewDelegatedAPICaller not valid")
+}
+
+func (s *RelaySuite) TestLogin(c *gc.C) {
+	s.startRelay(c)
+	conn, err := s.openRelayed(c, unitTag)
+	c.Assert(err, jc.ErrorIsNil)
+	defer conn.Close()
+
+	s.stub.CheckCall(c, 0, "NewDelegatedAPICaller", s.upstream, unitTag)
+	c.Check(conn.AuthTag(), gc.Equals, unitTag)
+	c.Check(conn.ControllerTag(), gc.Equals, controllerTag)
+	c.Check(conn.BestFacadeVersion("Uniter"), gc.Equals, 4)
+	serverVersion, ok := conn.ServerVersion()
+	c.Check(ok, jc.IsTrue)
+	c.Check(serverVersion, gc.Equals, version.MustParse("2.1.0"))
+	c.Check(conn.APIHostPorts(), jc.DeepEquals, [][]network.HostPort{
+		network.NewHostPorts(17070, "0.1.2.3"),
+		network.NewHostPorts(17070, "10.0.0.1"),
+	})
+}
+
+func (s *RelaySuite) TestRelaysCallsOnBehalfOfUnit(c *gc.C) {
+	s.startRelay(c)
+	conn, err := s.openRelayed(c, unitTag)
+	c.Assert(err, jc.ErrorIsNil)
+	defer conn.Close()
+
+	var result params.LifeResults
+	args := params.Entities{Entities: []params.Entity{{Tag: unitTag.String()}}}
+	err = conn.APICall("Uniter", 4, "", "Life", args, &result)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(result, jc.DeepEquals, params.LifeResults{
+		Results: []params.LifeResult{{Life: params.Alive}},
+	})
+
+	calls := s.relayedCalls()
+	c.Assert(calls, gc.HasLen, 1)
+	c.Check(calls[0].Args[:5], jc.DeepEquals, []interface{}{
+		unitTag, "Uniter", 4, "", "Life",
+	})
+	c.Check(string(calls[0].Args[5].(json.RawMessage)), gc.Equals,
+		`{"entities":[{"tag":"unit-mysql-0"}]}`,
+	)
+}
+
+func (s *RelaySuite) TestRelaysErrorCodes(c *gc.C) {
+	s.startRelay(c)
+	conn, err := s.openRelayed(c, unitTag)
+	c.Assert(err, jc.ErrorIsNil)
+	defer conn.Close()
+
+	s.upstream.callErr = &rpc.RequestError{
+		Message: "permission denied",
+		Code:    params.CodeUnauthorized,
+	}
+	err = conn.APICall("Uniter", 4, "", "Life", nil, nil)
+	c.Check(err, gc.ErrorMatches, "permission denied")
+	c.Check(err, jc.Satisfies, params.IsCodeUnauthorized)
+}
+
+func (s *RelaySuite) TestLoginRejectsNonUnit(c *gc.C) {
+	s.startRelay(c)
+	_, err := s.openRelayed(c, names.NewMachineTag("1"))
+	c.Check(err, gc.ErrorMatches, `cannot relay API calls for "machine-1"`)
+	s.stub.CheckNoCalls(c)
+}
+
+func (s *RelaySuite) TestLoginDelegationNotSupported(c *gc.C) {
+	s.startRelay(c)
+	s.stub.SetErrors(errors.NotSupportedf("delegating API calls as machine-1"))
+	_, err := s.openRelayed(c, unitTag)
+	c.Check(err, gc.ErrorMatches,
+		"cannot relay API calls for unit-mysql-0: delegating API calls as machine-1 not supported",
+	)
+}
+
+func (s *RelaySuite) TestCallsRequireLogin(c *gc.C) {
+	s.startRelay(c)
+	client := s.dialRelay(c)
+	err := client.Call(rpc.Request{
+		Type:    "Uniter",
+		Version: 4,
+		Action:  "Life",
+	}, nil, nil)
+	c.Check(err, gc.ErrorMatches, "not logged in")
+	c.Check(err, jc.Satisfies, params.IsCodeUnauthorized)
+	s.stub.CheckNoCalls(c)
+}
+
+func (s *RelaySuite) TestRejectsDelegatedRequests(c *gc.C) {
+	s.startRelay(c)
+	conn, err := s.openRelayed(c, unitTag)
+	c.Assert(err, jc.ErrorIsNil)
+	defer conn.Close()
+
+	caller, err := api.NewDelegatedAPICaller(conn, names.NewUnitTag("mysql/1"))
+	c.Check(err, gc.ErrorMatches, "delegating API calls as unit-mysql-0 not supported")
+	c.Check(caller, gc.IsNil)
+}
+
+func (s *RelaySuite) TestUpstreamBroken(c *gc.C) {
+	w := s.startRelay(c)
+	close(s.upstream.broken)
+	err := workertest.CheckKilled(c, w)
+	c.Check(err, gc.ErrorMatches, "upstream API connection broken")
+}
+
+func (s *RelaySuite) TestStopClosesConnections(c *gc.C) {
+	w := s.startRelay(c)
+	client := s.dialRelay(c)
+	workertest.CleanKill(c, w)
+	select {
+	case <-client.Dead():
+	case <-time.After(coretesting.LongWait):
+		c.Fatalf("relay connection not closed")
+	}
+}
+
+func (s *RelaySuite) TestOpenFuncFallsBack(c *gc.C) {
+	var fallbackInfo *api.Info
+	fallback := func(info *api.Info, opts api.DialOpts) (api.Connection, error) {
+		fallbackInfo = info
+		return nil, errors.New("no API server either")
+	}
+	open := apirelay.NewOpenFunc(s.config.SocketPath, fallback)
+	info := &api.Info{
+		Addrs:    []string{"0.1.2.3:17070"},
+		Tag:      unitTag,
+		ModelTag: modelTag,
+	}
+	_, err := open(info, api.DialOpts{})
+	c.Check(err, gc.ErrorMatches, "no API server either")
+	c.Check(fallbackInfo, gc.Equals, info)
+}
+
+func (s *RelaySuite) TestOpenFuncUsesRelay(c *gc.C) {
+	s.startRelay(c)
+	fallback := func(*api.Info, api.DialOpts) (api.Connection, error) {
+		c.Fatalf("unexpected fallback")
+		return nil, nil
+	}
+	open := apirelay.NewOpenFunc(s.config.SocketPath, fallback)
+	conn, err := open(&api.Info{
+		Addrs:    []string{"0.1.2.3:17070"},
+		Tag:      unitTag,
+		ModelTag: modelTag,
+	}, api.DialOpts{})
+	c.Assert(err, jc.ErrorIsNil)
+	conn.Close()
+}
+
+// dialRelay returns an RPC connection to the relay, on which
+// no login has been made.
+func (s *RelaySuite) dialRelay(c *gc.C) *rpc.Conn {
+	netConn, err := net.Dial("unix", s.config.SocketPath)
+	c.Assert(err, jc.ErrorIsNil)
+	client := rpc.NewConn(jsoncodec.NewNet(netConn), observer.None())
+	client.Start()
+	s.AddCleanup(func(*gc.C) { client.Close() })
+	return client
+}
+
+// relayedCalls returns the calls relayed upstream, other
+// than the pings made to keep the relayed connection alive.
+func (s *RelaySuite) relayedCalls() []jujutesting.StubCall {
+	var calls []jujutesting.StubCall
+	for _, call := range s.stub.Calls() {
+		if call.FuncName == "APICall" && call.Args[1] != "Pinger" {
+			calls = append(calls, call)
+		}
+	}
+	return calls
+}
+
+// stubConnection is the upstream api.Connection of a relay.
+type stubConnection struct {
+	api.Connection
+	broken  chan struct{}
+	callErr error
+}
+
+func (c *stubConnection) Broken() <-chan struct{} {
+	return c.broken
+}
+
+func (c *stubConnection) ModelTag() (names.ModelTag, bool) {
+	return modelTag, true
+}
+
+func (c *stubConnection) ControllerTag() names.ControllerTag {
+	return controllerTag
+}
+
+func (c *stubConnection) APIHostPorts() [][]network.HostPort {
+	return [][]network.HostPort{network.NewHostPorts(17070, "10.0.0.1")}
+}
+
+func (c *stubConnection) ServerVersion() (version.Number, bool) {
+	return version.MustParse("2.1.0"), true
+}
+
+func (c *stubConnection) AllFacadeVersions() map[string][]int {
+	return map[string][]int{
+		"Pinger": {1},
+		"Uniter": {2, 3, 4},
+	}
+}
+
+// stubCaller is a base.APICaller that makes calls on behalf of
+// a unit over a stubConnection.
+type stubCaller struct {
+	base.APICaller
+	stub     *jujutesting.Stub
+	upstream *stubConnection
+	tag      names.Tag
+}
+
+func (c *stubCaller) APICall(facade string, version int, id, method string, args, response interface{}) error {
+	c.stub.AddCall("APICall", c.tag, facade, version, id, method, args)
+	if facade == "Pinger" {
+		return nil
+	}
+	if c.upstream.callErr != nil {
+		return c.upstream.callErr
+	}
+	return json.Unmarshal([]byte(`{"results":[{"life":"alive"}]}`), response)
+}
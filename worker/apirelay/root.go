@@ -0,0 +1,193 @@
+// Copyright 2016 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package apirelay
+
+import (
+	"encoding/json"
+	"reflect"
+	"sort"
+	"sync"
+
+	"github.com/juju/errors"
+	"gopkg.in/juju/names.v2"
+
+	"github.com/juju/juju/api/base"
+	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/rpc/rpcreflect"
+)
+
+// loginVersion is the version of the Admin facade whose
+// Login method the relay implements.
+const loginVersion = 3
+
+var (
+	errNotLoggedIn = &params.Error{
+		Message: "not logged in",
+		Code:    params.CodeUnauthorized,
+	}
+	errAlreadyLoggedIn = errors.New("already logged in")
+
+	rawMessageType = reflect.TypeOf(json.RawMessage(nil))
+)
+
+// relayRoot is the rpc.Root served to an agent connected to a relay.
+// Until the agent logs in, only the Admin facade's Login method is
+// available; every other call is then made over the relay's upstream
+// connection, on behalf of the agent.
+type relayRoot struct {
+	config Config
+
+	mu     sync.Mutex
+	caller base.APICaller
+}
+
+func newRelayRoot(config Config) *relayRoot {
+	return &relayRoot{config: config}
+}
+
+// FindMethod is part of the rpc.Root interface.
+func (r *relayRoot) FindMethod(facade string, version int, method string) (rpcreflect.MethodCaller, error) {
+	if facade == "Admin" {
+		if version != loginVersion || method != "Login" {
+			return nil, &rpcreflect.CallNotImplementedError{
+				RootMethod: facade,
+				Version:    version,
+				Method:     method,
+			}
+		}
+		return loginMethod{r}, nil
+	}
+	r.mu.Lock()
+	caller := r.caller
+	r.mu.Unlock()
+	if caller == nil {
+		return nil, errNotLoggedIn
+	}
+	return relayedMethod{
+		caller:  caller,
+		facade:  facade,
+		version: version,
+		method:  method,
+	}, nil
+}
+
+// Kill is part of the rpc.Root interface.
+func (r *relayRoot) Kill() {}
+
+// login logs the agent in as the unit whose tag is given in the
+// request, and returns the result of the upstream connection's own
+// login, as far as the agent needs it.
+func (r *relayRoot) login(req params.LoginRequest) (params.LoginResult, error) {
+	var fail params.LoginResult
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.caller != nil {
+		return fail, errAlreadyLoggedIn
+	}
+
+	// Only unit agents' calls are relayed; the API server only
+	// permits a machine agent to call on behalf of its units.
+	tag, err := names.ParseUnitTag(req.AuthTag)
+	if err != nil {
+		return fail, errors.Errorf("cannot relay API calls for %q", req.AuthTag)
+	}
+	upstream := r.config.Upstream
+	modelTag, ok := upstream.ModelTag()
+	if !ok {
+		return fail, errors.New("cannot relay API calls without a model")
+	}
+	caller, err := r.config.NewDelegatedAPICaller(upstream, tag)
+	if err != nil {
+		return fail, errors.Annotatef(err, "cannot relay API calls for %s", tag)
+	}
+
+	allFacades := upstream.AllFacadeVersions()
+	facades := make([]params.FacadeVersions, 0, len(allFacades))
+	for name, versions := range allFacades {
+		facades = append(facades, params.FacadeVersions{
+			Name:     name,
+			Versions: versions,
+		})
+	}
+	sort.Sort(facadesByName(facades))
+	serverVersion, _ := upstream.ServerVersion()
+
+	r.caller = caller
+	return params.LoginResult{
+		Servers:       params.FromNetworkHostsPorts(upstream.APIHostPorts()),
+		ModelTag:      modelTag.String(),
+		ControllerTag: upstream.ControllerTag().String(),
+		Facades:       facades,
+		ServerVersion: serverVersion.String(),
+	}, nil
+}
+
+// loginMethod is an rpcreflect.MethodCaller for a relay's
+// implementation of the Admin facade's Login method.
+type loginMethod struct {
+	root *relayRoot
+}
+
+// ParamsType is part of the rpcreflect.MethodCaller interface.
+func (loginMethod) ParamsType() reflect.Type {
+	return reflect.TypeOf(params.LoginRequest{})
+}
+
+// ResultType is part of the rpcreflect.MethodCaller interface.
+func (loginMethod) ResultType() reflect.Type {
+	return reflect.TypeOf(params.LoginResult{})
+}
+
+// Call is part of the rpcreflect.MethodCaller interface.
+func (m loginMethod) Call(_ string, arg reflect.Value) (reflect.Value, error) {
+	result, err := m.root.login(arg.Interface().(params.LoginRequest))
+	if err != nil {
+		return reflect.Value{}, err
+	}
+	return reflect.ValueOf(result), nil
+}
+
+// relayedMethod is an rpcreflect.MethodCaller that makes calls over
+// a relay's upstream connection. The call's parameters and results
+// are passed through as they are.
+type relayedMethod struct {
+	caller  base.APICaller
+	facade  string
+	version int
+	method  string
+}
+
+// ParamsType is part of the rpcreflect.MethodCaller interface.
+func (relayedMethod) ParamsType() reflect.Type {
+	return rawMessageType
+}
+
+// ResultType is part of the rpcreflect.MethodCaller interface.
+func (relayedMethod) ResultType() reflect.Type {
+	return rawMessageType
+}
+
+// Call is part of the rpcreflect.MethodCaller interface.
+func (m relayedMethod) Call(objId string, arg reflect.Value) (reflect.Value, error) {
+	var args interface{}
+	if raw := arg.Interface().(json.RawMessage); len(raw) > 0 {
+		args = raw
+	}
+	var result json.RawMessage
+	if err := m.caller.APICall(m.facade, m.version, objId, m.method, args, &result); err != nil {
+		// The upstream error is passed on unadorned, so
+		// that the agent sees the error code it carries.
+		return reflect.Value{}, errors.Cause(err)
+	}
+	if len(result) == 0 {
+		return reflect.Value{}, nil
+	}
+	return reflect.ValueOf(result), nil
+}
+
+type facadesByName []params.FacadeVersions
+
+func (f facadesByName) Len() int           { return len(f) }
+func (f facadesByName) Swap(i, j int)      { f[i], f[j] = f[j], f[i] }
+func (f facadesByName) Less(i, j int) bool { return f[i].Name < f[j].Name }
@@ -14,11 +14,11 @@ import (
 type Facade interface {
 
 	// Watch returns a StringsWatcher reporting names of
-	// services which may have insufficient units.
+	// services which may have insufficient or excess units.
 	Watch() (watcher.StringsWatcher, error)
 
-	// Rescale scales up any named service observed to be
-	// running too few units.
+	// Rescale scales any named service observed to be running
+	// too few units, or more units than its desired scale.
 	Rescale(services []string) error
 }
 
@@ -37,7 +37,8 @@ func (config Config) Validate() error {
 }
 
 // New returns a worker that will attempt to rescale any
-// services that might be undersized.
+// services that might be undersized, or that might not
+// match their desired scale.
 func New(config Config) (worker.Worker, error) {
 	if err := config.Validate(); err != nil {
 		return nil, errors.Trace(err)
@@ -0,0 +1,117 @@
+// Copyright 2016 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+// Package blobbackendmigrator provides a worker that moves the content
+// of the controller's blobs to a new backend, once a migration has been
+// started with the Controller facade's MigrateBlobBackend method.
+package blobbackendmigrator
+
+import (
+	"time"
+
+	"github.com/juju/errors"
+	"github.com/juju/loggo"
+	"github.com/juju/utils/clock"
+	"gopkg.in/tomb.v1"
+
+	"github.com/juju/juju/worker"
+)
+
+var logger = loggo.GetLogger("juju.worker.blobbackendmigrator")
+
+// Migrator exposes the controller capabilities required by the worker.
+type Migrator interface {
+
+	// ResumeBlobBackendMigration moves the content of the
+	// controller's blobs to the current backend, if a migration
+	// has been started, returning the number of blobs moved. It
+	// returns early, with an error, if abort is closed.
+	ResumeBlobBackendMigration(abort <-chan struct{}) (int, error)
+}
+
+// Config defines the operation of a blob backend migrator.
+type Config struct {
+
+	// Migrator is the worker's view of the controller.
+	Migrator Migrator
+
+	// Clock is the worker's view of time.
+	Clock clock.Clock
+
+	// Period is the time between checks for a migration to resume.
+	Period time.Duration
+}
+
+// Validate returns an error if the configuration cannot be expected
+// to start a functional worker.
+func (config Config) Validate() error {
+	if config.Migrator == nil {
+		return errors.NotValidf("nil Migrator")
+	}
+	if config.Clock == nil {
+		return errors.NotValidf("nil Clock")
+	}
+	if config.Period <= 0 {
+		return errors.NotValidf("non-positive Period")
+	}
+	return nil
+}
+
+// NewWorker returns a worker that calls ResumeBlobBackendMigration on
+// the configured Migrator, once when started and subsequently every
+// Period. A migration interrupted by the worker stopping, or by the
+// controller restarting, is resumed when the worker next runs.
+func NewWorker(config Config) (worker.Worker, error) {
+	if err := config.Validate(); err != nil {
+		return nil, errors.Trace(err)
+	}
+	w := &migratorWorker{
+		config: config,
+	}
+	go func() {
+		defer w.tomb.Done()
+		w.tomb.Kill(w.loop())
+	}()
+	return w, nil
+}
+
+type migratorWorker struct {
+	tomb   tomb.Tomb
+	config Config
+}
+
+func (w *migratorWorker) loop() error {
+	var delay time.Duration
+	for {
+		select {
+		case <-w.tomb.Dying():
+			return tomb.ErrDying
+		case <-w.config.Clock.After(delay):
+			moved, err := w.config.Migrator.ResumeBlobBackendMigration(w.tomb.Dying())
+			select {
+			case <-w.tomb.Dying():
+				// The migration was aborted, and will
+				// be resumed when the worker restarts.
+				return tomb.ErrDying
+			default:
+			}
+			if err != nil {
+				return errors.Annotate(err, "migrating blob backend")
+			}
+			if moved > 0 {
+				logger.Infof("moved %d blobs to the current backend", moved)
+			}
+		}
+		delay = w.config.Period
+	}
+}
+
+// Kill is part of the worker.Worker interface.
+func (w *migratorWorker) Kill() {
+	w.tomb.Kill(nil)
+}
+
+// Wait is part of the worker.Worker interface.
+func (w *migratorWorker) Wait() error {
+	return w.tomb.Wait()
+}
@@ -0,0 +1,152 @@
+// Copyright 2016 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package blobbackendmigrator_test
+
+import (
+	"time"
+
+	"github.com/juju/errors"
+	"github.com/juju/testing"
+	jc "github.com/juju/testing/checkers"
+	"github.com/juju/utils/clock"
+	gc "gopkg.in/check.v1"
+
+	coretesting "github.com/juju/juju/testing"
+	"github.com/juju/juju/worker"
+	"github.com/juju/juju/worker/blobbackendmigrator"
+)
+
+type WorkerSuite struct {
+	testing.IsolationSuite
+	migrator *mockMigrator
+	clock    *testing.Clock
+}
+
+var _ = gc.Suite(&WorkerSuite{})
+
+func (s *WorkerSuite) SetUpTest(c *gc.C) {
+	s.IsolationSuite.SetUpTest(c)
+	s.migrator = &mockMigrator{calls: make(chan struct{}, 10)}
+	s.clock = testing.NewClock(coretesting.ZeroTime())
+}
+
+func (s *WorkerSuite) startWorker(c *gc.C) worker.Worker {
+	w, err := blobbackendmigrator.NewWorker(blobbackendmigrator.Config{
+		Migrator: s.migrator,
+		Clock:    s.clock,
+		Period:   time.Minute,
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	return w
+}
+
+func (s *WorkerSuite) waitCall(c *gc.C) {
+	select {
+	case <-s.migrator.calls:
+	case <-time.After(coretesting.LongWait):
+		c.Fatalf("timed out waiting for call")
+	}
+}
+
+func (s *WorkerSuite) waitNoCall(c *gc.C) {
+	select {
+	case <-s.migrator.calls:
+		c.Fatalf("unexpected call")
+	case <-time.After(coretesting.ShortWait):
+	}
+}
+
+func (s *WorkerSuite) TestResumesImmediatelyAndPeriodically(c *gc.C) {
+	s.migrator.moved = 3
+	w := s.startWorker(c)
+	defer worker.Stop(w)
+
+	s.waitCall(c)
+	s.clock.Advance(time.Minute - time.Nanosecond)
+	s.waitNoCall(c)
+	if err := s.clock.WaitAdvance(time.Nanosecond, coretesting.LongWait, 1); err != nil {
+		c.Fatal(err)
+	}
+	s.waitCall(c)
+
+	c.Assert(worker.Stop(w), jc.ErrorIsNil)
+	s.migrator.stub.CheckCallNames(c,
+		"ResumeBlobBackendMigration",
+		"ResumeBlobBackendMigration",
+	)
+}
+
+func (s *WorkerSuite) TestMigrateError(c *gc.C) {
+	s.migrator.stub.SetErrors(errors.New("boom"))
+	w := s.startWorker(c)
+	defer worker.Stop(w)
+
+	s.waitCall(c)
+	err := w.Wait()
+	c.Assert(err, gc.ErrorMatches, "migrating blob backend: boom")
+}
+
+func (s *WorkerSuite) TestStopAbortsMigration(c *gc.C) {
+	s.migrator.block = true
+	w := s.startWorker(c)
+	s.waitCall(c)
+
+	// The migration is aborted, rather than failed,
+	// so the worker stops cleanly.
+	c.Assert(worker.Stop(w), jc.ErrorIsNil)
+}
+
+func (s *WorkerSuite) TestValidate(c *gc.C) {
+	valid := blobbackendmigrator.Config{
+		Migrator: struct{ blobbackendmigrator.Migrator }{},
+		Clock:    struct{ clock.Clock }{},
+		Period:   time.Minute,
+	}
+	c.Assert(valid.Validate(), jc.ErrorIsNil)
+
+	for i, test := range []struct {
+		mutate func(*blobbackendmigrator.Config)
+		expect string
+	}{{
+		func(config *blobbackendmigrator.Config) { config.Migrator = nil },
+		"nil Migrator not valid",
+	}, {
+		func(config *blobbackendmigrator.Config) { config.Clock = nil },
+		"nil Clock not valid",
+	}, {
+		func(config *blobbackendmigrator.Config) { config.Period = 0 },
+		"non-positive Period not valid",
+	}} {
+		c.Logf("test %d", i)
+		config := valid
+		test.mutate(&config)
+		err := config.Validate()
+		c.Check(err, jc.Satisfies, errors.IsNotValid)
+		c.Check(err, gc.ErrorMatches, test.expect)
+
+		w, err := blobbackendmigrator.NewWorker(config)
+		c.Check(w, gc.IsNil)
+		c.Check(err, gc.ErrorMatches, test.expect)
+	}
+}
+
+type mockMigrator struct {
+	stub  testing.Stub
+	calls chan struct{}
+	moved int
+	block bool
+}
+
+func (m *mockMigrator) ResumeBlobBackendMigration(abort <-chan struct{}) (int, error) {
+	m.stub.AddCall("ResumeBlobBackendMigration")
+	m.calls <- struct{}{}
+	if m.block {
+		<-abort
+		return 0, errors.New("aborted")
+	}
+	if err := m.stub.NextErr(); err != nil {
+		return 0, err
+	}
+	return m.moved, nil
+}
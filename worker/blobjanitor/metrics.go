@@ -0,0 +1,96 @@
+// Copyright 2016 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package blobjanitor
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/juju/juju/apiserver/params"
+)
+
+const (
+	metricsNamespace = "juju"
+	metricsSubsystem = "blobstore"
+)
+
+// MetricsRegisterer is an interface for registering and unregistering
+// Prometheus metrics collectors. The blob janitor registers its
+// collector when it starts, and unregisters it when it stops.
+type MetricsRegisterer interface {
+	// Register registers the given collector, returning an
+	// error if it, or a collector with the same descriptors,
+	// is already registered.
+	Register(prometheus.Collector) error
+
+	// Unregister unregisters the given collector, returning
+	// true if the collector was registered.
+	Unregister(prometheus.Collector) bool
+}
+
+// metrics holds the Prometheus metrics for a model's blob buckets.
+// It implements prometheus.Collector.
+type metrics struct {
+	blobs  *prometheus.GaugeVec
+	bytes  *prometheus.GaugeVec
+	pruned *prometheus.CounterVec
+}
+
+func newMetrics(model string) *metrics {
+	constLabels := prometheus.Labels{"model": model}
+	return &metrics{
+		blobs: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace:   metricsNamespace,
+			Subsystem:   metricsSubsystem,
+			Name:        "bucket_blobs",
+			Help:        "Number of blobs held in each bucket.",
+			ConstLabels: constLabels,
+		}, []string{"bucket"}),
+		bytes: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace:   metricsNamespace,
+			Subsystem:   metricsSubsystem,
+			Name:        "bucket_bytes",
+			Help:        "Total length of the blobs held in each bucket.",
+			ConstLabels: constLabels,
+		}, []string{"bucket"}),
+		pruned: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace:   metricsNamespace,
+			Subsystem:   metricsSubsystem,
+			Name:        "pruned_blobs_total",
+			Help:        "Number of blobs removed under each bucket's lifecycle policy.",
+			ConstLabels: constLabels,
+		}, []string{"bucket"}),
+	}
+}
+
+// Describe is part of the prometheus.Collector interface.
+func (m *metrics) Describe(ch chan<- *prometheus.Desc) {
+	m.blobs.Describe(ch)
+	m.bytes.Describe(ch)
+	m.pruned.Describe(ch)
+}
+
+// Collect is part of the prometheus.Collector interface.
+func (m *metrics) Collect(ch chan<- prometheus.Metric) {
+	m.blobs.Collect(ch)
+	m.bytes.Collect(ch)
+	m.pruned.Collect(ch)
+}
+
+// updateUsage records the usage of each bucket, discarding that
+// previously recorded so that emptied buckets are no longer reported.
+func (m *metrics) updateUsage(usage []params.BucketUsage) {
+	m.blobs.Reset()
+	m.bytes.Reset()
+	for _, u := range usage {
+		m.blobs.WithLabelValues(u.Bucket).Set(float64(u.Blobs))
+		m.bytes.WithLabelValues(u.Bucket).Set(float64(u.Length))
+	}
+}
+
+// observeRemoved records the removal of blobs.
+func (m *metrics) observeRemoved(removed []params.RemovedBlob) {
+	for _, blob := range removed {
+		m.pruned.WithLabelValues(blob.Bucket).Inc()
+	}
+}
@@ -0,0 +1,141 @@
+// Copyright 2016 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+// Package blobjanitor provides a worker that periodically enforces the
+// lifecycle policies of a model's blob buckets, and reports the usage
+// of each bucket as Prometheus metrics.
+package blobjanitor
+
+import (
+	"time"
+
+	"github.com/juju/errors"
+	"github.com/juju/loggo"
+	"github.com/juju/utils/clock"
+	"gopkg.in/juju/names.v2"
+	"gopkg.in/tomb.v1"
+
+	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/worker"
+)
+
+var logger = loggo.GetLogger("juju.worker.blobjanitor")
+
+// Facade exposes the controller capabilities required by the worker.
+type Facade interface {
+
+	// EnforceBucketPolicies removes the blobs that are not retained
+	// under their bucket's lifecycle policy, returning those removed.
+	EnforceBucketPolicies() ([]params.RemovedBlob, error)
+
+	// BucketUsage returns the number and total length of the blobs
+	// held in each bucket.
+	BucketUsage() ([]params.BucketUsage, error)
+}
+
+// Config defines the operation of a blob janitor.
+type Config struct {
+
+	// Facade is the worker's view of the controller.
+	Facade Facade
+
+	// Clock is the worker's view of time.
+	Clock clock.Clock
+
+	// Period is the time between enforcements of the bucket
+	// policies.
+	Period time.Duration
+
+	// ModelTag identifies the model whose buckets are reported.
+	ModelTag names.ModelTag
+
+	// MetricsRegisterer, if non-nil, is used to register the
+	// worker's bucket usage metrics.
+	MetricsRegisterer MetricsRegisterer
+}
+
+// Validate returns an error if the configuration cannot be expected
+// to start a functional worker.
+func (config Config) Validate() error {
+	if config.Facade == nil {
+		return errors.NotValidf("nil Facade")
+	}
+	if config.Clock == nil {
+		return errors.NotValidf("nil Clock")
+	}
+	if config.Period <= 0 {
+		return errors.NotValidf("non-positive Period")
+	}
+	if config.ModelTag.Id() == "" {
+		return errors.NotValidf("empty ModelTag")
+	}
+	return nil
+}
+
+// NewWorker returns a worker that enforces the bucket policies and
+// records the bucket usage via the configured Facade, once when
+// started and subsequently every Period.
+func NewWorker(config Config) (worker.Worker, error) {
+	if err := config.Validate(); err != nil {
+		return nil, errors.Trace(err)
+	}
+	w := &janitorWorker{
+		config:  config,
+		metrics: newMetrics(config.ModelTag.Id()),
+	}
+	if config.MetricsRegisterer != nil {
+		if err := config.MetricsRegisterer.Register(w.metrics); err != nil {
+			return nil, errors.Annotate(err, "registering metrics")
+		}
+	}
+	go func() {
+		defer w.tomb.Done()
+		if config.MetricsRegisterer != nil {
+			defer config.MetricsRegisterer.Unregister(w.metrics)
+		}
+		w.tomb.Kill(w.loop())
+	}()
+	return w, nil
+}
+
+type janitorWorker struct {
+	tomb    tomb.Tomb
+	config  Config
+	metrics *metrics
+}
+
+func (w *janitorWorker) loop() error {
+	var delay time.Duration
+	for {
+		select {
+		case <-w.tomb.Dying():
+			return tomb.ErrDying
+		case <-w.config.Clock.After(delay):
+			removed, err := w.config.Facade.EnforceBucketPolicies()
+			if err != nil {
+				return errors.Annotate(err, "enforcing bucket policies")
+			}
+			for _, blob := range removed {
+				logger.Infof("removed %s blob %s", blob.Bucket, blob.Name)
+			}
+			w.metrics.observeRemoved(removed)
+
+			usage, err := w.config.Facade.BucketUsage()
+			if err != nil {
+				return errors.Annotate(err, "getting bucket usage")
+			}
+			w.metrics.updateUsage(usage)
+		}
+		delay = w.config.Period
+	}
+}
+
+// Kill is part of the worker.Worker interface.
+func (w *janitorWorker) Kill() {
+	w.tomb.Kill(nil)
+}
+
+// Wait is part of the worker.Worker interface.
+func (w *janitorWorker) Wait() error {
+	return w.tomb.Wait()
+}
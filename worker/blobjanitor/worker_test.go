@@ -0,0 +1,276 @@
+// Copyright 2016 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package blobjanitor_test
+
+import (
+	"strings"
+	"time"
+
+	"github.com/juju/errors"
+	"github.com/juju/testing"
+	jc "github.com/juju/testing/checkers"
+	"github.com/juju/utils/clock"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	gc "gopkg.in/check.v1"
+	"gopkg.in/juju/names.v2"
+
+	"github.com/juju/juju/apiserver/params"
+	coretesting "github.com/juju/juju/testing"
+	"github.com/juju/juju/worker"
+	"github.com/juju/juju/worker/blobjanitor"
+)
+
+type WorkerSuite struct {
+	testing.IsolationSuite
+	facade     *mockFacade
+	clock      *testing.Clock
+	registerer *mockMetricsRegisterer
+}
+
+var _ = gc.Suite(&WorkerSuite{})
+
+func (s *WorkerSuite) SetUpTest(c *gc.C) {
+	s.IsolationSuite.SetUpTest(c)
+	s.facade = &mockFacade{calls: make(chan struct{}, 10)}
+	s.clock = testing.NewClock(coretesting.ZeroTime())
+	s.registerer = &mockMetricsRegisterer{}
+}
+
+func (s *WorkerSuite) startWorker(c *gc.C) worker.Worker {
+	w, err := blobjanitor.NewWorker(blobjanitor.Config{
+		Facade:            s.facade,
+		Clock:             s.clock,
+		Period:            time.Hour,
+		ModelTag:          coretesting.ModelTag,
+		MetricsRegisterer: s.registerer,
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	return w
+}
+
+func (s *WorkerSuite) waitCall(c *gc.C) {
+	select {
+	case <-s.facade.calls:
+	case <-time.After(coretesting.LongWait):
+		c.Fatalf("timed out waiting for call")
+	}
+}
+
+func (s *WorkerSuite) waitNoCall(c *gc.C) {
+	select {
+	case <-s.facade.calls:
+		c.Fatalf("unexpected call")
+	case <-time.After(coretesting.ShortWait):
+	}
+}
+
+func (s *WorkerSuite) TestEnforcesImmediatelyAndPeriodically(c *gc.C) {
+	w := s.startWorker(c)
+	defer worker.Stop(w)
+
+	s.waitCall(c)
+	s.waitCall(c)
+	s.clock.Advance(time.Hour - time.Nanosecond)
+	s.waitNoCall(c)
+	if err := s.clock.WaitAdvance(time.Nanosecond, coretesting.LongWait, 1); err != nil {
+		c.Fatal(err)
+	}
+	s.waitCall(c)
+	s.waitCall(c)
+
+	c.Assert(worker.Stop(w), jc.ErrorIsNil)
+	s.facade.stub.CheckCallNames(c,
+		"EnforceBucketPolicies", "BucketUsage",
+		"EnforceBucketPolicies", "BucketUsage",
+	)
+}
+
+func (s *WorkerSuite) TestMetrics(c *gc.C) {
+	s.facade.removed = []params.RemovedBlob{
+		{Bucket: "charms", Name: "local:xenial/dummy-1"},
+		{Bucket: "charms", Name: "local:xenial/dummy-2"},
+	}
+	s.facade.usage = []params.BucketUsage{
+		{Bucket: "tools", Blobs: 2, Length: 1024},
+		{Bucket: "charms", Blobs: 3, Length: 300},
+	}
+	w := s.startWorker(c)
+	defer worker.Stop(w)
+	s.waitCall(c)
+	s.waitCall(c)
+	// Make sure the usage has been recorded.
+	s.clock.WaitAdvance(0, coretesting.LongWait, 1)
+
+	c.Assert(s.registerer.collectors, gc.HasLen, 1)
+	collector := s.registerer.collectors[0]
+	model := ",model=" + coretesting.ModelTag.Id()
+	c.Assert(collectMetrics(c, collector, "juju_blobstore_bucket_blobs"), jc.DeepEquals, map[string]float64{
+		"bucket=tools" + model:  2,
+		"bucket=charms" + model: 3,
+	})
+	c.Assert(collectMetrics(c, collector, "juju_blobstore_bucket_bytes"), jc.DeepEquals, map[string]float64{
+		"bucket=tools" + model:  1024,
+		"bucket=charms" + model: 300,
+	})
+	c.Assert(collectMetrics(c, collector, "juju_blobstore_pruned_blobs_total"), jc.DeepEquals, map[string]float64{
+		"bucket=charms" + model: 2,
+	})
+
+	c.Assert(worker.Stop(w), jc.ErrorIsNil)
+	c.Assert(s.registerer.collectors, gc.HasLen, 0)
+	s.registerer.CheckCallNames(c, "Register", "Unregister")
+}
+
+func (s *WorkerSuite) TestMetricsRegistrationError(c *gc.C) {
+	s.registerer.SetErrors(errors.New("duplicate metrics collector"))
+	_, err := blobjanitor.NewWorker(blobjanitor.Config{
+		Facade:            s.facade,
+		Clock:             s.clock,
+		Period:            time.Hour,
+		ModelTag:          coretesting.ModelTag,
+		MetricsRegisterer: s.registerer,
+	})
+	c.Assert(err, gc.ErrorMatches, "registering metrics: duplicate metrics collector")
+}
+
+func (s *WorkerSuite) TestEnforceError(c *gc.C) {
+	s.facade.stub.SetErrors(errors.New("boom"))
+	w := s.startWorker(c)
+	defer worker.Stop(w)
+
+	s.waitCall(c)
+	err := w.Wait()
+	c.Assert(err, gc.ErrorMatches, "enforcing bucket policies: boom")
+}
+
+func (s *WorkerSuite) TestUsageError(c *gc.C) {
+	s.facade.stub.SetErrors(nil, errors.New("boom"))
+	w := s.startWorker(c)
+	defer worker.Stop(w)
+
+	s.waitCall(c)
+	s.waitCall(c)
+	err := w.Wait()
+	c.Assert(err, gc.ErrorMatches, "getting bucket usage: boom")
+}
+
+func (s *WorkerSuite) TestValidate(c *gc.C) {
+	valid := blobjanitor.Config{
+		Facade:   struct{ blobjanitor.Facade }{},
+		Clock:    struct{ clock.Clock }{},
+		Period:   time.Hour,
+		ModelTag: coretesting.ModelTag,
+	}
+	c.Assert(valid.Validate(), jc.ErrorIsNil)
+
+	for i, test := range []struct {
+		mutate func(*blobjanitor.Config)
+		expect string
+	}{{
+		func(config *blobjanitor.Config) { config.Facade = nil },
+		"nil Facade not valid",
+	}, {
+		func(config *blobjanitor.Config) { config.Clock = nil },
+		"nil Clock not valid",
+	}, {
+		func(config *blobjanitor.Config) { config.Period = 0 },
+		"non-positive Period not valid",
+	}, {
+		func(config *blobjanitor.Config) { config.ModelTag = names.ModelTag{} },
+		"empty ModelTag not valid",
+	}} {
+		c.Logf("test %d", i)
+		config := valid
+		test.mutate(&config)
+		err := config.Validate()
+		c.Check(err, jc.Satisfies, errors.IsNotValid)
+		c.Check(err, gc.ErrorMatches, test.expect)
+
+		w, err := blobjanitor.NewWorker(config)
+		c.Check(w, gc.IsNil)
+		c.Check(err, gc.ErrorMatches, test.expect)
+	}
+}
+
+// collectMetrics collects the counter and gauge values of the metrics
+// with the given name from the collector, keyed by their sorted label
+// pairs.
+func collectMetrics(c *gc.C, collector prometheus.Collector, name string) map[string]float64 {
+	ch := make(chan prometheus.Metric)
+	go func() {
+		defer close(ch)
+		collector.Collect(ch)
+	}()
+	result := make(map[string]float64)
+	for metric := range ch {
+		if !strings.Contains(metric.Desc().String(), `fqName: "`+name+`"`) {
+			continue
+		}
+		var m dto.Metric
+		c.Assert(metric.Write(&m), jc.ErrorIsNil)
+		labels := make([]string, len(m.Label))
+		for i, label := range m.Label {
+			labels[i] = label.GetName() + "=" + label.GetValue()
+		}
+		key := strings.Join(labels, ",")
+		switch {
+		case m.Counter != nil:
+			result[key] = m.Counter.GetValue()
+		case m.Gauge != nil:
+			result[key] = m.Gauge.GetValue()
+		}
+	}
+	return result
+}
+
+type mockFacade struct {
+	stub    testing.Stub
+	calls   chan struct{}
+	removed []params.RemovedBlob
+	usage   []params.BucketUsage
+}
+
+func (f *mockFacade) EnforceBucketPolicies() ([]params.RemovedBlob, error) {
+	f.stub.AddCall("EnforceBucketPolicies")
+	f.calls <- struct{}{}
+	if err := f.stub.NextErr(); err != nil {
+		return nil, err
+	}
+	return f.removed, nil
+}
+
+func (f *mockFacade) BucketUsage() ([]params.BucketUsage, error) {
+	f.stub.AddCall("BucketUsage")
+	f.calls <- struct{}{}
+	if err := f.stub.NextErr(); err != nil {
+		return nil, err
+	}
+	return f.usage, nil
+}
+
+type mockMetricsRegisterer struct {
+	testing.Stub
+	collectors []prometheus.Collector
+}
+
+func (r *mockMetricsRegisterer) Register(c prometheus.Collector) error {
+	r.MethodCall(r, "Register", c)
+	if err := r.NextErr(); err != nil {
+		return err
+	}
+	r.collectors = append(r.collectors, c)
+	return nil
+}
+
+func (r *mockMetricsRegisterer) Unregister(c prometheus.Collector) bool {
+	r.MethodCall(r, "Unregister", c)
+	for i, existing := range r.collectors {
+		if existing == c {
+			r.collectors = append(r.collectors[:i], r.collectors[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
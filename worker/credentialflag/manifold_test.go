@@ -0,0 +1,85 @@
+// Copyright 2016 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package credentialflag_test
+
+import (
+	"github.com/juju/errors"
+	"github.com/juju/testing"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/api/base"
+	"github.com/juju/juju/worker"
+	"github.com/juju/juju/worker/credentialflag"
+	"github.com/juju/juju/worker/dependency"
+	dt "github.com/juju/juju/worker/dependency/testing"
+)
+
+type ManifoldSuite struct {
+	testing.IsolationSuite
+}
+
+var _ = gc.Suite(&ManifoldSuite{})
+
+func validManifoldConfig() credentialflag.ManifoldConfig {
+	return credentialflag.ManifoldConfig{
+		APICallerName: "api-caller",
+		NewFacade: func(base.APICaller) (credentialflag.Facade, error) {
+			panic("NewFacade")
+		},
+		NewWorker: func(credentialflag.Config) (worker.Worker, error) {
+			panic("NewWorker")
+		},
+	}
+}
+
+func (*ManifoldSuite) TestInputs(c *gc.C) {
+	manifold := credentialflag.Manifold(validManifoldConfig())
+	c.Check(manifold.Inputs, jc.DeepEquals, []string{"api-caller"})
+}
+
+func (*ManifoldSuite) TestFilterErrChanged(c *gc.C) {
+	manifold := credentialflag.Manifold(validManifoldConfig())
+	err := manifold.Filter(credentialflag.ErrChanged)
+	c.Check(err, gc.Equals, dependency.ErrBounce)
+}
+
+func (*ManifoldSuite) TestFilterOther(c *gc.C) {
+	manifold := credentialflag.Manifold(validManifoldConfig())
+	expect := errors.New("whatever")
+	actual := manifold.Filter(expect)
+	c.Check(actual, gc.Equals, expect)
+}
+
+func (*ManifoldSuite) TestStartMissingNewFacade(c *gc.C) {
+	config := validManifoldConfig()
+	config.NewFacade = nil
+	manifold := credentialflag.Manifold(config)
+	worker, err := manifold.Start(dt.StubContext(nil, nil))
+	c.Check(worker, gc.IsNil)
+	c.Check(err, gc.ErrorMatches, "nil NewFacade not valid")
+}
+
+func (*ManifoldSuite) TestStartSuccess(c *gc.C) {
+	expectCaller := &struct{ base.APICaller }{}
+	context := dt.StubContext(nil, map[string]interface{}{
+		"api-caller": expectCaller,
+	})
+	expectFacade := &struct{ credentialflag.Facade }{}
+	expectWorker := &struct{ worker.Worker }{}
+	config := validManifoldConfig()
+	config.NewFacade = func(caller base.APICaller) (credentialflag.Facade, error) {
+		c.Check(caller, gc.Equals, expectCaller)
+		return expectFacade, nil
+	}
+	config.NewWorker = func(workerConfig credentialflag.Config) (worker.Worker, error) {
+		c.Check(workerConfig.Facade, gc.Equals, expectFacade)
+		return expectWorker, nil
+	}
+	manifold := credentialflag.Manifold(config)
+
+	worker, err := manifold.Start(context)
+	c.Check(err, jc.ErrorIsNil)
+	c.Check(worker, gc.Equals, expectWorker)
+}
@@ -0,0 +1,27 @@
+// Copyright 2016 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package credentialflag
+
+import (
+	"github.com/juju/errors"
+
+	"github.com/juju/juju/api/agent"
+	"github.com/juju/juju/api/base"
+	"github.com/juju/juju/worker"
+)
+
+// NewFacade creates a *agent.State and returns it as a Facade.
+func NewFacade(apiCaller base.APICaller) (Facade, error) {
+	facade := agent.NewState(apiCaller)
+	return facade, nil
+}
+
+// NewWorker creates a *Worker and returns it as a worker.Worker.
+func NewWorker(config Config) (worker.Worker, error) {
+	worker, err := New(config)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	return worker, nil
+}
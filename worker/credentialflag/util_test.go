@@ -0,0 +1,93 @@
+// Copyright 2016 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package credentialflag_test
+
+import (
+	"github.com/juju/testing"
+	"gopkg.in/juju/names.v2"
+
+	"github.com/juju/juju/watcher"
+	"github.com/juju/juju/worker"
+	"github.com/juju/juju/worker/workertest"
+)
+
+// credentialTag is the model credential used in the tests.
+var credentialTag = names.NewCloudCredentialTag("dummy/fred/default")
+
+// newMockFacade returns a mock Facade that will add calls to the
+// supplied testing.Stub, and return errors in the sequences it
+// specifies; if any ModelCredentialValid call does not return an
+// error, it will return a validity consumed from the head of the
+// supplied list (or panic if it's empty).
+func newMockFacade(stub *testing.Stub, credential bool, valid ...bool) *mockFacade {
+	return &mockFacade{
+		stub:       stub,
+		credential: credential,
+		valid:      valid,
+	}
+}
+
+// mockFacade implements credentialflag.Facade for use in the tests.
+type mockFacade struct {
+	stub       *testing.Stub
+	credential bool
+	valid      []bool
+}
+
+// ModelCredential is part of the credentialflag.Facade interface.
+func (mock *mockFacade) ModelCredential() (names.CloudCredentialTag, bool, error) {
+	mock.stub.AddCall("ModelCredential")
+	if err := mock.stub.NextErr(); err != nil {
+		return names.CloudCredentialTag{}, false, err
+	}
+	if !mock.credential {
+		return names.CloudCredentialTag{}, false, nil
+	}
+	return credentialTag, true, nil
+}
+
+// ModelCredentialValid is part of the credentialflag.Facade interface.
+func (mock *mockFacade) ModelCredentialValid() (bool, error) {
+	mock.stub.AddCall("ModelCredentialValid")
+	if err := mock.stub.NextErr(); err != nil {
+		return false, err
+	}
+	valid := mock.valid[0]
+	mock.valid = mock.valid[1:]
+	return valid, nil
+}
+
+// WatchCredential is part of the credentialflag.Facade interface.
+func (mock *mockFacade) WatchCredential(tag names.CloudCredentialTag) (watcher.NotifyWatcher, error) {
+	mock.stub.AddCall("WatchCredential", tag)
+	if err := mock.stub.NextErr(); err != nil {
+		return nil, err
+	}
+	return newMockWatcher(), nil
+}
+
+// newMockWatcher returns a watcher.NotifyWatcher that always
+// sends 3 changes and then sits quietly until killed.
+func newMockWatcher() *mockWatcher {
+	const count = 3
+	changes := make(chan struct{}, count)
+	for i := 0; i < count; i++ {
+		changes <- struct{}{}
+	}
+	return &mockWatcher{
+		Worker:  workertest.NewErrorWorker(nil),
+		changes: changes,
+	}
+}
+
+// mockWatcher implements watcher.NotifyWatcher for use in the tests.
+type mockWatcher struct {
+	worker.Worker
+	changes chan struct{}
+}
+
+// Changes is part of the watcher.NotifyWatcher interface.
+func (mock *mockWatcher) Changes() watcher.NotifyChannel {
+	return mock.changes
+}
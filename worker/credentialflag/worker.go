@@ -0,0 +1,128 @@
+// Copyright 2016 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package credentialflag
+
+import (
+	"github.com/juju/errors"
+	"gopkg.in/juju/names.v2"
+
+	"github.com/juju/juju/watcher"
+	"github.com/juju/juju/worker/catacomb"
+)
+
+// ErrChanged indicates that a Worker has stopped because the validity
+// of the model's cloud credential has changed.
+var ErrChanged = errors.New("cloud credential validity changed")
+
+// Facade exposes controller functionality required by a Worker.
+type Facade interface {
+	ModelCredential() (names.CloudCredentialTag, bool, error)
+	ModelCredentialValid() (bool, error)
+	WatchCredential(names.CloudCredentialTag) (watcher.NotifyWatcher, error)
+}
+
+// Config holds the dependencies and configuration for a Worker.
+type Config struct {
+	Facade Facade
+}
+
+// Validate returns an error if the config cannot be expected to
+// drive a functional Worker.
+func (config Config) Validate() error {
+	if config.Facade == nil {
+		return errors.NotValidf("nil Facade")
+	}
+	return nil
+}
+
+// New returns a Worker that tracks the validity of the model's cloud
+// credential, as exposed by the Facade. A model without a credential
+// is considered to have a valid credential.
+func New(config Config) (*Worker, error) {
+	if err := config.Validate(); err != nil {
+		return nil, errors.Trace(err)
+	}
+	tag, ok, err := config.Facade.ModelCredential()
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	valid := true
+	if ok {
+		valid, err = config.Facade.ModelCredentialValid()
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+	}
+
+	w := &Worker{
+		config:     config,
+		credential: tag,
+		watch:      ok,
+		valid:      valid,
+	}
+	err = catacomb.Invoke(catacomb.Plan{
+		Site: &w.catacomb,
+		Work: w.loop,
+	})
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	return w, nil
+}
+
+// Worker implements worker.Worker and util.Flag, and exits
+// with ErrChanged whenever the model's cloud credential is
+// invalidated or, having been invalidated, is updated.
+type Worker struct {
+	catacomb   catacomb.Catacomb
+	config     Config
+	credential names.CloudCredentialTag
+	watch      bool
+	valid      bool
+}
+
+// Kill is part of the worker.Worker interface.
+func (w *Worker) Kill() {
+	w.catacomb.Kill(nil)
+}
+
+// Wait is part of the worker.Worker interface.
+func (w *Worker) Wait() error {
+	return w.catacomb.Wait()
+}
+
+// Check is part of the util.Flag interface.
+func (w *Worker) Check() bool {
+	return w.valid
+}
+
+func (w *Worker) loop() error {
+	if !w.watch {
+		// The model has no credential to be invalidated.
+		<-w.catacomb.Dying()
+		return w.catacomb.ErrDying()
+	}
+	facade := w.config.Facade
+	watcher, err := facade.WatchCredential(w.credential)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	if err := w.catacomb.Add(watcher); err != nil {
+		return errors.Trace(err)
+	}
+	for {
+		select {
+		case <-w.catacomb.Dying():
+			return w.catacomb.ErrDying()
+		case <-watcher.Changes():
+			valid, err := facade.ModelCredentialValid()
+			if err != nil {
+				return errors.Trace(err)
+			}
+			if valid != w.valid {
+				return ErrChanged
+			}
+		}
+	}
+}
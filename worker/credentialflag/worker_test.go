@@ -0,0 +1,108 @@
+// Copyright 2016 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package credentialflag_test
+
+import (
+	"github.com/juju/errors"
+	"github.com/juju/testing"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/worker/credentialflag"
+	"github.com/juju/juju/worker/workertest"
+)
+
+type WorkerSuite struct {
+	testing.IsolationSuite
+}
+
+var _ = gc.Suite(&WorkerSuite{})
+
+func (*WorkerSuite) TestValidateNilFacade(c *gc.C) {
+	worker, err := credentialflag.New(credentialflag.Config{})
+	c.Check(worker, gc.IsNil)
+	c.Check(err, gc.ErrorMatches, "nil Facade not valid")
+	c.Check(err, jc.Satisfies, errors.IsNotValid)
+}
+
+func (*WorkerSuite) TestModelCredentialErrorOnStartup(c *gc.C) {
+	stub := &testing.Stub{}
+	stub.SetErrors(errors.New("gaah"))
+	facade := newMockFacade(stub, true)
+	worker, err := credentialflag.New(credentialflag.Config{Facade: facade})
+	c.Check(worker, gc.IsNil)
+	c.Check(err, gc.ErrorMatches, "gaah")
+	stub.CheckCallNames(c, "ModelCredential")
+}
+
+func (*WorkerSuite) TestNoCredential(c *gc.C) {
+	stub := &testing.Stub{}
+	facade := newMockFacade(stub, false)
+	worker, err := credentialflag.New(credentialflag.Config{Facade: facade})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(worker.Check(), jc.IsTrue)
+
+	workertest.CheckAlive(c, worker)
+	workertest.CleanKill(c, worker)
+	stub.CheckCallNames(c, "ModelCredential")
+}
+
+func (*WorkerSuite) TestWatchError(c *gc.C) {
+	stub := &testing.Stub{}
+	stub.SetErrors(nil, nil, errors.New("boff"))
+	facade := newMockFacade(stub, true, false)
+	worker, err := credentialflag.New(credentialflag.Config{Facade: facade})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(worker.Check(), jc.IsFalse)
+
+	err = workertest.CheckKilled(c, worker)
+	c.Check(err, gc.ErrorMatches, "boff")
+	stub.CheckCallNames(c, "ModelCredential", "ModelCredentialValid", "WatchCredential")
+	stub.CheckCall(c, 2, "WatchCredential", credentialTag)
+}
+
+func (*WorkerSuite) TestNoRelevantChanges(c *gc.C) {
+	stub := &testing.Stub{}
+	facade := newMockFacade(stub, true, true, true, true, true)
+	worker, err := credentialflag.New(credentialflag.Config{Facade: facade})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(worker.Check(), jc.IsTrue)
+
+	workertest.CheckAlive(c, worker)
+	workertest.CleanKill(c, worker)
+	stub.CheckCallNames(c,
+		"ModelCredential", "ModelCredentialValid", "WatchCredential",
+		"ModelCredentialValid", "ModelCredentialValid", "ModelCredentialValid",
+	)
+}
+
+func (*WorkerSuite) TestCredentialInvalidated(c *gc.C) {
+	stub := &testing.Stub{}
+	facade := newMockFacade(stub, true, true, true, false)
+	worker, err := credentialflag.New(credentialflag.Config{Facade: facade})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(worker.Check(), jc.IsTrue)
+
+	err = workertest.CheckKilled(c, worker)
+	c.Check(err, gc.Equals, credentialflag.ErrChanged)
+	stub.CheckCallNames(c,
+		"ModelCredential", "ModelCredentialValid", "WatchCredential",
+		"ModelCredentialValid", "ModelCredentialValid",
+	)
+}
+
+func (*WorkerSuite) TestCredentialUpdated(c *gc.C) {
+	stub := &testing.Stub{}
+	facade := newMockFacade(stub, true, false, true)
+	worker, err := credentialflag.New(credentialflag.Config{Facade: facade})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(worker.Check(), jc.IsFalse)
+
+	err = workertest.CheckKilled(c, worker)
+	c.Check(err, gc.Equals, credentialflag.ErrChanged)
+	stub.CheckCallNames(c,
+		"ModelCredential", "ModelCredentialValid", "WatchCredential",
+		"ModelCredentialValid",
+	)
+}
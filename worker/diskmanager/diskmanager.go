@@ -19,9 +19,15 @@ var logger = loggo.GetLogger("juju.worker.diskmanager")
 const (
 	// listBlockDevicesPeriod is the time period between block device listings.
 	// Unfortunately Linux's inotify does not work with virtual filesystems, so
-	// polling it is.
+	// polling it is, unless block devices can be watched by other means.
 	listBlockDevicesPeriod = time.Second * 30
 
+	// blockDevicesSettlePeriod is the time to wait after a block device
+	// change is observed before listing the block devices. Changes tend
+	// to come in bursts (e.g. a disk and its partitions), and udev must
+	// be given a chance to process them and create the device links.
+	blockDevicesSettlePeriod = time.Second
+
 	// bytesInMiB is the number of bytes in a MiB.
 	bytesInMiB = 1024 * 1024
 )
@@ -40,14 +46,71 @@ type ListBlockDevicesFunc func() ([]storage.BlockDevice, error)
 // devices for the operating system of the local host.
 var DefaultListBlockDevices ListBlockDevicesFunc
 
+// WatchBlockDevicesFunc is the type of a function that is supplied to
+// NewWorker for watching block devices being added to or removed from
+// the local host. The returned channel receives a value when the block
+// devices may have changed, and is closed if the watch fails. The watch
+// is stopped when the supplied stop channel is closed.
+type WatchBlockDevicesFunc func(stop <-chan struct{}) (<-chan struct{}, error)
+
+// DefaultWatchBlockDevices is the default function for watching block
+// devices for the operating system of the local host. It is nil if
+// block devices cannot be watched on the operating system.
+var DefaultWatchBlockDevices WatchBlockDevicesFunc
+
 // NewWorker returns a worker that lists block devices
 // attached to the machine, and records them in state.
-var NewWorker = func(l ListBlockDevicesFunc, b BlockDeviceSetter) worker.Worker {
+//
+// If watch is non-nil, it is used to list the block devices as soon as
+// they change, so that newly attached volumes are discovered without
+// waiting for the next periodic listing.
+var NewWorker = func(l ListBlockDevicesFunc, b BlockDeviceSetter, watch WatchBlockDevicesFunc) worker.Worker {
 	var old []storage.BlockDevice
-	f := func(stop <-chan struct{}) error {
-		return doWork(l, b, &old)
+	if watch == nil {
+		f := func(stop <-chan struct{}) error {
+			return doWork(l, b, &old)
+		}
+		return worker.NewPeriodicWorker(f, listBlockDevicesPeriod, worker.NewTimer)
+	}
+	return worker.NewSimpleWorker(func(stop <-chan struct{}) error {
+		return watchLoop(l, b, watch, &old, stop)
+	})
+}
+
+// watchLoop lists the block devices periodically, and shortly after
+// they are observed to change.
+func watchLoop(
+	listf ListBlockDevicesFunc,
+	b BlockDeviceSetter,
+	watch WatchBlockDevicesFunc,
+	old *[]storage.BlockDevice,
+	stop <-chan struct{},
+) error {
+	changes, err := watch(stop)
+	if err != nil {
+		logger.Warningf("cannot watch block devices, falling back to polling: %v", err)
+		changes = nil
+	}
+	timer := worker.NewTimer(0)
+	for {
+		select {
+		case <-stop:
+			return nil
+		case _, ok := <-changes:
+			if !ok {
+				logger.Warningf("block device watcher stopped, falling back to polling")
+				changes = nil
+				continue
+			}
+			logger.Debugf("block devices changed, listing in %v", blockDevicesSettlePeriod)
+			timer.Reset(blockDevicesSettlePeriod)
+		case <-timer.CountDown():
+			if err := doWork(listf, b, old); err != nil {
+				return err
+			}
+			timer.Reset(listBlockDevicesPeriod)
+		}
 	}
-	return worker.NewPeriodicWorker(f, listBlockDevicesPeriod, worker.NewTimer)
 }
 
 func doWork(listf ListBlockDevicesFunc, b BlockDeviceSetter, old *[]storage.BlockDevice) error {
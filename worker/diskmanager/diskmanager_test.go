@@ -6,12 +6,14 @@ package diskmanager_test
 import (
 	"time"
 
+	"github.com/juju/errors"
 	jc "github.com/juju/testing/checkers"
 	gc "gopkg.in/check.v1"
 
 	"github.com/juju/juju/storage"
 	coretesting "github.com/juju/juju/testing"
 	"github.com/juju/juju/worker/diskmanager"
+	"github.com/juju/juju/worker/workertest"
 )
 
 var _ = gc.Suite(&DiskManagerWorkerSuite{})
@@ -38,7 +40,7 @@ func (s *DiskManagerWorkerSuite) TestWorker(c *gc.C) {
 		return []storage.BlockDevice{{DeviceName: "whatever"}}, nil
 	}
 
-	w := diskmanager.NewWorker(listDevices, setDevices)
+	w := diskmanager.NewWorker(listDevices, setDevices, nil)
 	defer w.Wait()
 	defer w.Kill()
 
@@ -49,6 +51,73 @@ func (s *DiskManagerWorkerSuite) TestWorker(c *gc.C) {
 	}
 }
 
+func (s *DiskManagerWorkerSuite) TestWorkerWatchesBlockDevices(c *gc.C) {
+	devices := make(chan []storage.BlockDevice, 1)
+	var setDevices BlockDeviceSetterFunc = func(blockDevices []storage.BlockDevice) error {
+		devices <- blockDevices
+		return nil
+	}
+
+	deviceName := "sda"
+	var listDevices diskmanager.ListBlockDevicesFunc = func() ([]storage.BlockDevice, error) {
+		return []storage.BlockDevice{{DeviceName: deviceName}}, nil
+	}
+
+	changes := make(chan struct{})
+	watchDevices := func(stop <-chan struct{}) (<-chan struct{}, error) {
+		return changes, nil
+	}
+
+	w := diskmanager.NewWorker(listDevices, setDevices, watchDevices)
+	defer workertest.CleanKill(c, w)
+
+	// The block devices are listed on startup.
+	s.assertDevicesSet(c, devices, "sda")
+
+	// A change to the block devices causes them to be listed again,
+	// well before the next periodic listing.
+	deviceName = "sdb"
+	select {
+	case changes <- struct{}{}:
+	case <-time.After(coretesting.LongWait):
+		c.Fatalf("timed out sending block device change")
+	}
+	s.assertDevicesSet(c, devices, "sdb")
+}
+
+func (s *DiskManagerWorkerSuite) TestWorkerWatchFails(c *gc.C) {
+	done := make(chan struct{})
+	var setDevices BlockDeviceSetterFunc = func(devices []storage.BlockDevice) error {
+		close(done)
+		return nil
+	}
+	var listDevices diskmanager.ListBlockDevicesFunc = func() ([]storage.BlockDevice, error) {
+		return []storage.BlockDevice{{DeviceName: "whatever"}}, nil
+	}
+	watchDevices := func(stop <-chan struct{}) (<-chan struct{}, error) {
+		return nil, errors.New("netlink not supported")
+	}
+
+	// The worker falls back to listing block devices periodically.
+	w := diskmanager.NewWorker(listDevices, setDevices, watchDevices)
+	defer workertest.CleanKill(c, w)
+
+	select {
+	case <-done:
+	case <-time.After(coretesting.LongWait):
+		c.Fatalf("timed out waiting for diskmanager to update")
+	}
+}
+
+func (s *DiskManagerWorkerSuite) assertDevicesSet(c *gc.C, devices <-chan []storage.BlockDevice, deviceName string) {
+	select {
+	case set := <-devices:
+		c.Assert(set, jc.DeepEquals, []storage.BlockDevice{{DeviceName: deviceName}})
+	case <-time.After(coretesting.LongWait):
+		c.Fatalf("timed out waiting for block devices to be set")
+	}
+}
+
 func (s *DiskManagerWorkerSuite) TestBlockDeviceChanges(c *gc.C) {
 	var oldDevices []storage.BlockDevice
 	var devicesSet [][]storage.BlockDevice
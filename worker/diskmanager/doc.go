@@ -4,4 +4,8 @@
 // Package diskmanager defines a worker that periodically lists block devices
 // on the machine it runs on. This worker will be run on all Juju-managed
 // machines (one per machine agent).
+//
+// On Linux, the worker also listens for kernel uevents, and lists the block
+// devices as soon as they change, so that newly attached volumes are recorded
+// without waiting for the next periodic listing.
 package diskmanager
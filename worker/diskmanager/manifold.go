@@ -35,5 +35,5 @@ func newWorker(a agent.Agent, apiCaller base.APICaller) (worker.Worker, error) {
 
 	api := apidiskmanager.NewState(apiCaller, tag)
 
-	return NewWorker(DefaultListBlockDevices, api), nil
+	return NewWorker(DefaultListBlockDevices, api, DefaultWatchBlockDevices), nil
 }
@@ -39,10 +39,15 @@ func (s *manifoldSuite) TestMachineDiskmanager(c *gc.C) {
 			return nil
 		})
 
-	s.PatchValue(&diskmanager.NewWorker, func(l diskmanager.ListBlockDevicesFunc, b diskmanager.BlockDeviceSetter) worker.Worker {
+	s.PatchValue(&diskmanager.NewWorker, func(
+		l diskmanager.ListBlockDevicesFunc,
+		b diskmanager.BlockDeviceSetter,
+		w diskmanager.WatchBlockDevicesFunc,
+	) worker.Worker {
 		called = true
 
 		c.Assert(l, gc.FitsTypeOf, diskmanager.DefaultListBlockDevices)
+		c.Assert(w, gc.FitsTypeOf, diskmanager.DefaultWatchBlockDevices)
 		c.Assert(b, gc.NotNil)
 
 		api, ok := b.(*apidiskmanager.State)
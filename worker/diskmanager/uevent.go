@@ -0,0 +1,136 @@
+// Copyright 2016 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+// +build linux
+
+package diskmanager
+
+import (
+	"bytes"
+	"syscall"
+	"time"
+
+	"github.com/juju/errors"
+)
+
+const (
+	// kernelUeventGroup is the netlink multicast group to which the
+	// kernel sends uevents. udev rebroadcasts the events, once it has
+	// processed them, to a separate group in its own format; we rely
+	// on the settle period to give udev time to process the events.
+	kernelUeventGroup = 1
+
+	// ueventBufferSize is the size of the buffer into which uevents
+	// are read. The kernel limits uevents to 2KiB.
+	ueventBufferSize = 8192
+
+	// ueventReadTimeout is the maximum time to block reading uevents,
+	// after which the watcher checks whether it has been stopped.
+	ueventReadTimeout = time.Second
+)
+
+func init() {
+	DefaultWatchBlockDevices = watchBlockDevices
+}
+
+// watchBlockDevices watches for block devices being added to, removed
+// from or changed on the machine, by listening for kernel uevents on a
+// netlink socket. This is not permitted in some containers, in which
+// case an error is returned.
+func watchBlockDevices(stop <-chan struct{}) (<-chan struct{}, error) {
+	fd, err := syscall.Socket(
+		syscall.AF_NETLINK,
+		syscall.SOCK_DGRAM|syscall.SOCK_CLOEXEC,
+		syscall.NETLINK_KOBJECT_UEVENT,
+	)
+	if err != nil {
+		return nil, errors.Annotate(err, "creating uevent socket")
+	}
+	if err := syscall.Bind(fd, &syscall.SockaddrNetlink{
+		Family: syscall.AF_NETLINK,
+		Groups: kernelUeventGroup,
+	}); err != nil {
+		syscall.Close(fd)
+		return nil, errors.Annotate(err, "binding uevent socket")
+	}
+	// Reads time out periodically, so the watcher can be stopped.
+	timeout := syscall.NsecToTimeval(int64(ueventReadTimeout))
+	if err := syscall.SetsockoptTimeval(
+		fd, syscall.SOL_SOCKET, syscall.SO_RCVTIMEO, &timeout,
+	); err != nil {
+		syscall.Close(fd)
+		return nil, errors.Annotate(err, "setting uevent socket timeout")
+	}
+
+	changes := make(chan struct{}, 1)
+	go func() {
+		defer close(changes)
+		defer syscall.Close(fd)
+		buf := make([]byte, ueventBufferSize)
+		for {
+			select {
+			case <-stop:
+				return
+			default:
+			}
+			n, _, err := syscall.Recvfrom(fd, buf, 0)
+			switch err {
+			case nil:
+				if !isBlockDeviceUevent(buf[:n]) {
+					continue
+				}
+			case syscall.EAGAIN, syscall.EINTR:
+				continue
+			case syscall.ENOBUFS:
+				// The socket's buffer overflowed, and uevents
+				// were dropped; any of them may have been for
+				// block devices.
+				logger.Debugf("uevents dropped")
+			default:
+				logger.Errorf("reading uevents: %v", err)
+				return
+			}
+			// Changes are coalesced, as the block devices are
+			// listed afresh for any number of them.
+			select {
+			case changes <- struct{}{}:
+			default:
+			}
+		}
+	}()
+	return changes, nil
+}
+
+// isBlockDeviceUevent reports whether the given kernel uevent message
+// describes a block device being added, removed or changed. A message
+// consists of a "<action>@<devpath>" header, followed by KEY=value
+// pairs, each terminated by a NUL byte.
+func isBlockDeviceUevent(msg []byte) bool {
+	fields := bytes.Split(msg, []byte{0})
+	if len(fields) == 0 || bytes.IndexByte(fields[0], '@') == -1 {
+		// This is not a kernel uevent; udev's
+		// own messages start with "libudev".
+		return false
+	}
+	var action, subsystem string
+	for _, field := range fields[1:] {
+		sep := bytes.IndexByte(field, '=')
+		if sep == -1 {
+			continue
+		}
+		switch key, value := string(field[:sep]), string(field[sep+1:]); key {
+		case "ACTION":
+			action = value
+		case "SUBSYSTEM":
+			subsystem = value
+		}
+	}
+	if subsystem != "block" {
+		return false
+	}
+	switch action {
+	case "add", "remove", "change":
+		return true
+	}
+	return false
+}
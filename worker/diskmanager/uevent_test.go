@@ -0,0 +1,69 @@
+// Copyright 2016 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+// +build linux
+
+package diskmanager_test
+
+import (
+	"strings"
+
+	"github.com/juju/testing"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/worker/diskmanager"
+)
+
+type UeventSuite struct {
+	testing.IsolationSuite
+}
+
+var _ = gc.Suite(&UeventSuite{})
+
+func uevent(fields ...string) []byte {
+	return []byte(strings.Join(fields, "\x00") + "\x00")
+}
+
+func (s *UeventSuite) TestIsBlockDeviceUevent(c *gc.C) {
+	for i, test := range []struct {
+		msg    []byte
+		expect bool
+	}{{
+		msg: uevent(
+			"add@/devices/virtual/block/sdc",
+			"ACTION=add",
+			"DEVPATH=/devices/virtual/block/sdc",
+			"SUBSYSTEM=block",
+			"DEVNAME=sdc",
+			"DEVTYPE=disk",
+		),
+		expect: true,
+	}, {
+		msg: uevent(
+			"remove@/devices/virtual/block/sdc/sdc1",
+			"ACTION=remove",
+			"SUBSYSTEM=block",
+			"DEVTYPE=partition",
+		),
+		expect: true,
+	}, {
+		msg:    uevent("change@/devices/virtual/block/sdc", "ACTION=change", "SUBSYSTEM=block"),
+		expect: true,
+	}, {
+		msg:    uevent("offline@/devices/virtual/block/sdc", "ACTION=offline", "SUBSYSTEM=block"),
+		expect: false,
+	}, {
+		msg:    uevent("add@/devices/virtual/net/eth1", "ACTION=add", "SUBSYSTEM=net"),
+		expect: false,
+	}, {
+		msg:    uevent("libudev", "ACTION=add", "SUBSYSTEM=block"),
+		expect: false,
+	}, {
+		msg:    nil,
+		expect: false,
+	}} {
+		c.Logf("test %d: %q", i, test.msg)
+		c.Assert(diskmanager.IsBlockDeviceUevent(test.msg), jc.DeepEquals, test.expect)
+	}
+}
@@ -8,7 +8,9 @@ import (
 	"gopkg.in/juju/names.v2"
 
 	"github.com/juju/juju/environs"
+	"github.com/juju/juju/instance"
 	"github.com/juju/juju/watcher"
+	"github.com/juju/juju/worker"
 	"github.com/juju/juju/worker/catacomb"
 )
 
@@ -17,7 +19,9 @@ import (
 type ConfigObserver interface {
 	environs.EnvironConfigGetter
 	WatchForModelConfigChanges() (watcher.NotifyWatcher, error)
+	ModelCredential() (names.CloudCredentialTag, bool, error)
 	WatchCredential(tag names.CloudCredentialTag) (watcher.NotifyWatcher, error)
+	InvalidateModelCredential(reason string) error
 }
 
 // Config describes the dependencies of a Tracker.
@@ -41,7 +45,13 @@ func (config Config) Validate() error {
 }
 
 // Tracker loads an environment, makes it available to clients, and updates
-// the environment in response to config changes until it is killed.
+// the environment in response to config changes until it is killed. If the
+// environment implements environs.CloudSpecSetter, its cloud spec is also
+// updated in response to changes to the model's cloud credential. If the
+// environment implements environs.CredentialInvalidatorSetter, the model's
+// cloud credential is invalidated when the cloud rejects it. If the
+// environment implements environs.InstanceAuthorizedKeysUpdater, changes
+// to the model's authorized keys are pushed to its instances.
 type Tracker struct {
 	config   Config
 	catacomb catacomb.Catacomb
@@ -62,6 +72,9 @@ func NewTracker(config Config) (*Tracker, error) {
 	if err != nil {
 		return nil, errors.Annotate(err, "cannot create environ")
 	}
+	if setter, ok := environ.(environs.CredentialInvalidatorSetter); ok {
+		setter.SetCredentialInvalidator(config.Observer.InvalidateModelCredential)
+	}
 
 	t := &Tracker{
 		config:  config,
@@ -91,6 +104,10 @@ func (t *Tracker) loop() error {
 	if err := t.catacomb.Add(environWatcher); err != nil {
 		return errors.Trace(err)
 	}
+	credentialChanges, err := t.watchCredential()
+	if err != nil {
+		return errors.Trace(err)
+	}
 	for {
 		logger.Debugf("waiting for environ watch notification")
 		select {
@@ -100,16 +117,111 @@ func (t *Tracker) loop() error {
 			if !ok {
 				return errors.New("environ config watch closed")
 			}
+			if err := t.updateConfig(); err != nil {
+				return errors.Trace(err)
+			}
+		case _, ok := <-credentialChanges:
+			if !ok {
+				return errors.New("credential watch closed")
+			}
+			if err := t.updateCloudSpec(); err != nil {
+				return errors.Trace(err)
+			}
+		}
+	}
+}
+
+// watchCredential starts watching the model's cloud credential, if the
+// environ can have its cloud spec updated and the model has a credential.
+// Otherwise, the returned channel is nil.
+func (t *Tracker) watchCredential() (watcher.NotifyChannel, error) {
+	if _, ok := t.environ.(environs.CloudSpecSetter); !ok {
+		return nil, nil
+	}
+	credentialTag, ok, err := t.config.Observer.ModelCredential()
+	if err != nil {
+		return nil, errors.Annotate(err, "cannot get model credential")
+	}
+	if !ok {
+		return nil, nil
+	}
+	credentialWatcher, err := t.config.Observer.WatchCredential(credentialTag)
+	if err != nil {
+		return nil, errors.Annotate(err, "cannot watch credential")
+	}
+	if err := t.catacomb.Add(credentialWatcher); err != nil {
+		return nil, errors.Trace(err)
+	}
+	return credentialWatcher.Changes(), nil
+}
+
+func (t *Tracker) updateConfig() error {
+	logger.Debugf("reloading environ config")
+	modelConfig, err := t.config.Observer.ModelConfig()
+	if err != nil {
+		return errors.Annotate(err, "cannot read environ config")
+	}
+	oldAuthorizedKeys := t.environ.Config().AuthorizedKeys()
+	if err = t.environ.SetConfig(modelConfig); err != nil {
+		return errors.Annotate(err, "cannot update environ config")
+	}
+	if authorizedKeys := modelConfig.AuthorizedKeys(); authorizedKeys != oldAuthorizedKeys {
+		if err := t.updateAuthorizedKeys(authorizedKeys); err != nil {
+			return errors.Trace(err)
+		}
+	}
+	return nil
+}
+
+// updateAuthorizedKeys starts a worker to update the authorized keys
+// of the environ's instances, if the environ implements
+// environs.InstanceAuthorizedKeysUpdater. Instances may take some time
+// to update, so the update does not hold up the tracker; failures are
+// logged rather than stopping the tracker, as the machine agents also
+// update their machines' authorized keys.
+func (t *Tracker) updateAuthorizedKeys(authorizedKeys string) error {
+	updater, ok := t.environ.(environs.InstanceAuthorizedKeysUpdater)
+	if !ok {
+		return nil
+	}
+	w := worker.NewSimpleWorker(func(stop <-chan struct{}) error {
+		instances, err := t.environ.AllInstances()
+		if err != nil && err != environs.ErrPartialInstances {
+			logger.Errorf("cannot update authorized keys: getting instances: %v", err)
+			return nil
+		}
+		ids := make([]instance.Id, 0, len(instances))
+		for _, inst := range instances {
+			if inst != nil {
+				ids = append(ids, inst.Id())
+			}
 		}
-		logger.Debugf("reloading environ config")
-		modelConfig, err := t.config.Observer.ModelConfig()
-		if err != nil {
-			return errors.Annotate(err, "cannot read environ config")
+		select {
+		case <-stop:
+			return nil
+		default:
 		}
-		if err = t.environ.SetConfig(modelConfig); err != nil {
-			return errors.Annotate(err, "cannot update environ config")
+		logger.Debugf("updating authorized keys on %d instances", len(ids))
+		if err := updater.UpdateAuthorizedKeys(ids, authorizedKeys); err != nil {
+			logger.Errorf("cannot update authorized keys: %v", err)
 		}
+		return nil
+	})
+	return t.catacomb.Add(w)
+}
+
+func (t *Tracker) updateCloudSpec() error {
+	logger.Debugf("reloading cloud spec")
+	modelTag := names.NewModelTag(t.environ.Config().UUID())
+	cloudSpec, err := t.config.Observer.CloudSpec(modelTag)
+	if err != nil {
+		return errors.Annotate(err, "cannot read cloud spec")
 	}
+	setter := t.environ.(environs.CloudSpecSetter)
+	if err := setter.SetCloudSpec(cloudSpec); err != nil {
+		return errors.Annotate(err, "cannot update cloud spec")
+	}
+	return nil
 }
 
 // Kill is part of the worker.Worker interface.
@@ -9,8 +9,11 @@ import (
 	"github.com/juju/errors"
 	jc "github.com/juju/testing/checkers"
 	gc "gopkg.in/check.v1"
+	"gopkg.in/juju/names.v2"
 
+	"github.com/juju/juju/cloud"
 	"github.com/juju/juju/environs"
+	"github.com/juju/juju/instance"
 	coretesting "github.com/juju/juju/testing"
 	"github.com/juju/juju/worker/environ"
 	"github.com/juju/juju/worker/workertest"
@@ -241,3 +244,217 @@ func (s *TrackerSuite) TestWatchedModelConfigUpdates(c *gc.C) {
 		context.CheckCallNames(c, "ModelConfig", "CloudSpec", "WatchForModelConfigChanges", "ModelConfig")
 	})
 }
+
+func (s *TrackerSuite) TestModelCredentialFails(c *gc.C) {
+	fix := &fixture{
+		observerErrs: []error{
+			nil, nil, nil, errors.New("no credential for you"),
+		},
+	}
+	fix.Run(c, func(context *runContext) {
+		tracker, err := environ.NewTracker(environ.Config{
+			Observer:       context,
+			NewEnvironFunc: newMockCloudSpecEnviron,
+		})
+		c.Assert(err, jc.ErrorIsNil)
+		defer workertest.DirtyKill(c, tracker)
+
+		err = workertest.CheckKilled(c, tracker)
+		c.Check(err, gc.ErrorMatches, "cannot get model credential: no credential for you")
+		context.CheckCallNames(c, "ModelConfig", "CloudSpec", "WatchForModelConfigChanges", "ModelCredential")
+	})
+}
+
+func (s *TrackerSuite) TestCredentialWatchFails(c *gc.C) {
+	credentialTag := names.NewCloudCredentialTag("dummy/fred/default")
+	fix := &fixture{
+		credential: &credentialTag,
+		observerErrs: []error{
+			nil, nil, nil, nil, errors.New("grrk splat"),
+		},
+	}
+	fix.Run(c, func(context *runContext) {
+		tracker, err := environ.NewTracker(environ.Config{
+			Observer:       context,
+			NewEnvironFunc: newMockCloudSpecEnviron,
+		})
+		c.Assert(err, jc.ErrorIsNil)
+		defer workertest.DirtyKill(c, tracker)
+
+		err = workertest.CheckKilled(c, tracker)
+		c.Check(err, gc.ErrorMatches, "cannot watch credential: grrk splat")
+		context.CheckCallNames(c, "ModelConfig", "CloudSpec", "WatchForModelConfigChanges", "ModelCredential", "WatchCredential")
+	})
+}
+
+func (s *TrackerSuite) TestCredentialWatchCloses(c *gc.C) {
+	credentialTag := names.NewCloudCredentialTag("dummy/fred/default")
+	fix := &fixture{credential: &credentialTag}
+	fix.Run(c, func(context *runContext) {
+		tracker, err := environ.NewTracker(environ.Config{
+			Observer:       context,
+			NewEnvironFunc: newMockCloudSpecEnviron,
+		})
+		c.Assert(err, jc.ErrorIsNil)
+		defer workertest.DirtyKill(c, tracker)
+
+		context.CloseCredentialNotify()
+		err = workertest.CheckKilled(c, tracker)
+		c.Check(err, gc.ErrorMatches, "credential watch closed")
+		context.CheckCallNames(c, "ModelConfig", "CloudSpec", "WatchForModelConfigChanges", "ModelCredential", "WatchCredential")
+	})
+}
+
+func (s *TrackerSuite) TestWatchedCloudSpecIncompatible(c *gc.C) {
+	credentialTag := names.NewCloudCredentialTag("dummy/fred/default")
+	fix := &fixture{credential: &credentialTag}
+	fix.Run(c, func(context *runContext) {
+		tracker, err := environ.NewTracker(environ.Config{
+			Observer: context,
+			NewEnvironFunc: func(args environs.OpenParams) (environs.Environ, error) {
+				env, _ := newMockCloudSpecEnviron(args)
+				env.(*mockCloudSpecEnviron).SetErrors(
+					nil, // Config
+					errors.New("SetCloudSpec is broken"),
+				)
+				return env, nil
+			},
+		})
+		c.Check(err, jc.ErrorIsNil)
+		defer workertest.DirtyKill(c, tracker)
+
+		context.SendCredentialNotify()
+		err = workertest.CheckKilled(c, tracker)
+		c.Check(err, gc.ErrorMatches, "cannot update cloud spec: SetCloudSpec is broken")
+		context.CheckCallNames(c,
+			"ModelConfig", "CloudSpec", "WatchForModelConfigChanges",
+			"ModelCredential", "WatchCredential", "CloudSpec",
+		)
+	})
+}
+
+func (s *TrackerSuite) TestWatchedCredentialUpdatesCloudSpec(c *gc.C) {
+	credentialTag := names.NewCloudCredentialTag("dummy/fred/default")
+	original := cloud.NewCredential(cloud.UserPassAuthType, map[string]string{"password": "original"})
+	fix := &fixture{
+		credential: &credentialTag,
+		cloud: environs.CloudSpec{
+			Name:       "foo",
+			Type:       "bar",
+			Credential: &original,
+		},
+	}
+	fix.Run(c, func(context *runContext) {
+		tracker, err := environ.NewTracker(environ.Config{
+			Observer:       context,
+			NewEnvironFunc: newMockCloudSpecEnviron,
+		})
+		c.Check(err, jc.ErrorIsNil)
+		defer workertest.CleanKill(c, tracker)
+
+		rotated := cloud.NewCredential(cloud.UserPassAuthType, map[string]string{"password": "rotated"})
+		context.SetCloudSpec(environs.CloudSpec{
+			Name:       "foo",
+			Type:       "bar",
+			Credential: &rotated,
+		})
+		gotEnviron := tracker.Environ().(*mockCloudSpecEnviron)
+		c.Assert(gotEnviron.CloudSpec().Credential, jc.DeepEquals, &original)
+
+		timeout := time.After(coretesting.LongWait)
+		attempt := time.After(0)
+		context.SendCredentialNotify()
+		for {
+			select {
+			case <-attempt:
+				credential := gotEnviron.CloudSpec().Credential
+				if credential.Attributes()["password"] == "original" {
+					attempt = time.After(coretesting.ShortWait)
+					continue
+				}
+				c.Check(credential, jc.DeepEquals, &rotated)
+			case <-timeout:
+				c.Fatalf("timed out waiting for cloud spec to be updated")
+			}
+			break
+		}
+		context.CheckCallNames(c,
+			"ModelConfig", "CloudSpec", "WatchForModelConfigChanges",
+			"ModelCredential", "WatchCredential", "CloudSpec",
+		)
+	})
+}
+
+func (s *TrackerSuite) TestCredentialInvalidator(c *gc.C) {
+	fix := &fixture{}
+	fix.Run(c, func(context *runContext) {
+		tracker, err := environ.NewTracker(environ.Config{
+			Observer:       context,
+			NewEnvironFunc: newMockCredentialInvalidatorEnviron,
+		})
+		c.Check(err, jc.ErrorIsNil)
+		defer workertest.DirtyKill(c, tracker)
+
+		gotEnviron := tracker.Environ().(*mockCredentialInvalidatorEnviron)
+		err = gotEnviron.InvalidateCredential("secret expired")
+		c.Assert(err, jc.ErrorIsNil)
+		workertest.CleanKill(c, tracker)
+
+		// The tracker's loop watches the model config concurrently,
+		// so only the invalidation calls are checked.
+		var reasons []interface{}
+		for _, call := range context.stub.Calls() {
+			if call.FuncName == "InvalidateModelCredential" {
+				reasons = append(reasons, call.Args...)
+			}
+		}
+		c.Assert(reasons, jc.DeepEquals, []interface{}{"secret expired"})
+	})
+}
+
+func (s *TrackerSuite) TestWatchedAuthorizedKeysUpdateInstances(c *gc.C) {
+	fix := &fixture{
+		initialConfig: coretesting.Attrs{
+			"authorized-keys": "ssh-rsa old-key",
+		},
+	}
+	fix.Run(c, func(context *runContext) {
+		updates := make(chan []interface{}, 1)
+		tracker, err := environ.NewTracker(environ.Config{
+			Observer: context,
+			NewEnvironFunc: func(args environs.OpenParams) (environs.Environ, error) {
+				return &mockAuthorizedKeysEnviron{
+					mockEnviron: &mockEnviron{cfg: args.Config},
+					updates:     updates,
+				}, nil
+			},
+		})
+		c.Check(err, jc.ErrorIsNil)
+		defer workertest.CleanKill(c, tracker)
+
+		// Changes to other attributes do not update the instances.
+		context.SetConfig(c, coretesting.Attrs{
+			"authorized-keys": "ssh-rsa old-key",
+			"name":            "updated-name",
+		})
+		context.SendModelConfigNotify()
+		select {
+		case update := <-updates:
+			c.Fatalf("unexpected update: %v", update)
+		case <-time.After(coretesting.ShortWait):
+		}
+
+		context.SetConfig(c, coretesting.Attrs{
+			"authorized-keys": "ssh-rsa new-key",
+		})
+		context.SendModelConfigNotify()
+		select {
+		case update := <-updates:
+			c.Assert(update, jc.DeepEquals, []interface{}{
+				[]instance.Id{"inst-0", "inst-1"}, "ssh-rsa new-key",
+			})
+		case <-time.After(coretesting.LongWait):
+			c.Fatalf("timed out waiting for authorized keys update")
+		}
+	})
+}
@@ -6,12 +6,14 @@ package environ_test
 import (
 	"sync"
 
+	"github.com/juju/errors"
 	"github.com/juju/testing"
 	gc "gopkg.in/check.v1"
 	names "gopkg.in/juju/names.v2"
 
 	"github.com/juju/juju/environs"
 	"github.com/juju/juju/environs/config"
+	"github.com/juju/juju/instance"
 	coretesting "github.com/juju/juju/testing"
 	"github.com/juju/juju/watcher"
 	"github.com/juju/juju/worker"
@@ -22,16 +24,21 @@ type fixture struct {
 	watcherErr    error
 	observerErrs  []error
 	cloud         environs.CloudSpec
+	credential    *names.CloudCredentialTag
 	initialConfig map[string]interface{}
 }
 
 func (fix *fixture) Run(c *gc.C, test func(*runContext)) {
 	watcher := newNotifyWatcher(fix.watcherErr)
 	defer workertest.DirtyKill(c, watcher)
+	credWatcher := newNotifyWatcher(fix.watcherErr)
+	defer workertest.DirtyKill(c, credWatcher)
 	context := &runContext{
-		cloud:   fix.cloud,
-		config:  newModelConfig(c, fix.initialConfig),
-		watcher: watcher,
+		cloud:       fix.cloud,
+		credential:  fix.credential,
+		config:      newModelConfig(c, fix.initialConfig),
+		watcher:     watcher,
+		credWatcher: credWatcher,
 	}
 	context.stub.SetErrors(fix.observerErrs...)
 	test(context)
@@ -41,6 +48,7 @@ type runContext struct {
 	mu          sync.Mutex
 	stub        testing.Stub
 	cloud       environs.CloudSpec
+	credential  *names.CloudCredentialTag
 	config      map[string]interface{}
 	watcher     *notifyWatcher
 	credWatcher *notifyWatcher
@@ -53,6 +61,13 @@ func (context *runContext) SetConfig(c *gc.C, extraAttrs coretesting.Attrs) {
 	context.config = newModelConfig(c, extraAttrs)
 }
 
+// SetCloudSpec updates the cloud spec returned by CloudSpec.
+func (context *runContext) SetCloudSpec(spec environs.CloudSpec) {
+	context.mu.Lock()
+	defer context.mu.Unlock()
+	context.cloud = spec
+}
+
 // CloudSpec is part of the environ.ConfigObserver interface.
 func (context *runContext) CloudSpec(tag names.ModelTag) (environs.CloudSpec, error) {
 	context.mu.Lock()
@@ -120,15 +135,37 @@ func (context *runContext) CloseCredentialNotify() {
 	close(context.credWatcher.changes)
 }
 
+// ModelCredential is part of the environ.ConfigObserver interface.
+func (context *runContext) ModelCredential() (names.CloudCredentialTag, bool, error) {
+	context.mu.Lock()
+	defer context.mu.Unlock()
+	context.stub.AddCall("ModelCredential")
+	if err := context.stub.NextErr(); err != nil {
+		return names.CloudCredentialTag{}, false, err
+	}
+	if context.credential == nil {
+		return names.CloudCredentialTag{}, false, nil
+	}
+	return *context.credential, true, nil
+}
+
 // WatchCredential is part of the environ.ConfigObserver interface.
 func (context *runContext) WatchCredential(cred names.CloudCredentialTag) (watcher.NotifyWatcher, error) {
 	context.mu.Lock()
 	defer context.mu.Unlock()
-	context.stub.AddCall("WatchCredential")
+	context.stub.AddCall("WatchCredential", cred)
 	if err := context.stub.NextErr(); err != nil {
 		return nil, err
 	}
-	return context.watcher, nil
+	return context.credWatcher, nil
+}
+
+// InvalidateModelCredential is part of the environ.ConfigObserver interface.
+func (context *runContext) InvalidateModelCredential(reason string) error {
+	context.mu.Lock()
+	defer context.mu.Unlock()
+	context.stub.AddCall("InvalidateModelCredential", reason)
+	return context.stub.NextErr()
 }
 
 func (context *runContext) CheckCallNames(c *gc.C, names ...string) {
@@ -191,3 +228,91 @@ func (e *mockEnviron) SetConfig(cfg *config.Config) error {
 func newMockEnviron(args environs.OpenParams) (environs.Environ, error) {
 	return &mockEnviron{cfg: args.Config}, nil
 }
+
+// mockCloudSpecEnviron is a mockEnviron that implements
+// environs.CloudSpecSetter.
+type mockCloudSpecEnviron struct {
+	*mockEnviron
+	spec environs.CloudSpec
+}
+
+func (e *mockCloudSpecEnviron) SetCloudSpec(spec environs.CloudSpec) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.MethodCall(e, "SetCloudSpec", spec)
+	if err := e.NextErr(); err != nil {
+		return err
+	}
+	e.spec = spec
+	return nil
+}
+
+func (e *mockCloudSpecEnviron) CloudSpec() environs.CloudSpec {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.spec
+}
+
+// mockCredentialInvalidatorEnviron is a mockEnviron that implements
+// environs.CredentialInvalidatorSetter.
+type mockCredentialInvalidatorEnviron struct {
+	*mockEnviron
+	invalidate environs.InvalidateCredentialFunc
+}
+
+func (e *mockCredentialInvalidatorEnviron) SetCredentialInvalidator(f environs.InvalidateCredentialFunc) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.MethodCall(e, "SetCredentialInvalidator", f)
+	e.PopNoErr()
+	e.invalidate = f
+}
+
+func (e *mockCredentialInvalidatorEnviron) InvalidateCredential(reason string) error {
+	e.mu.Lock()
+	invalidate := e.invalidate
+	e.mu.Unlock()
+	return invalidate(reason)
+}
+
+func newMockCredentialInvalidatorEnviron(args environs.OpenParams) (environs.Environ, error) {
+	return &mockCredentialInvalidatorEnviron{
+		mockEnviron: &mockEnviron{cfg: args.Config},
+	}, nil
+}
+
+// mockAuthorizedKeysEnviron is a mockEnviron that implements
+// environs.InstanceAuthorizedKeysUpdater, reporting the updates
+// on a channel.
+type mockAuthorizedKeysEnviron struct {
+	*mockEnviron
+	updates chan []interface{}
+}
+
+func (e *mockAuthorizedKeysEnviron) AllInstances() ([]instance.Instance, error) {
+	return []instance.Instance{
+		&mockInstance{id: "inst-0"},
+		&mockInstance{id: "inst-1"},
+	}, nil
+}
+
+func (e *mockAuthorizedKeysEnviron) UpdateAuthorizedKeys(ids []instance.Id, authorizedKeys string) error {
+	e.updates <- []interface{}{ids, authorizedKeys}
+	return errors.New("update is broken")
+}
+
+type mockInstance struct {
+	instance.Instance
+	id instance.Id
+}
+
+func (inst *mockInstance) Id() instance.Id {
+	return inst.id
+}
+
+func newMockCloudSpecEnviron(args environs.OpenParams) (environs.Environ, error) {
+	return &mockCloudSpecEnviron{
+		mockEnviron: &mockEnviron{cfg: args.Config},
+		spec:        args.Cloud,
+	}, nil
+}
@@ -33,11 +33,11 @@ type Firewaller struct {
 	modelWatcher    watcher.NotifyWatcher
 	machinesWatcher watcher.StringsWatcher
 	portsWatcher    watcher.StringsWatcher
+	exposureWatcher watcher.StringsWatcher
 	machineds       map[names.MachineTag]*machineData
 	unitsChange     chan *unitsChange
 	unitds          map[names.UnitTag]*unitData
 	applicationids  map[names.ApplicationTag]*serviceData
-	exposedChange   chan *exposedChange
 	globalMode      bool
 	globalPortRef   map[network.PortRange]int
 	machinePorts    map[names.MachineTag]machineRanges
@@ -52,7 +52,6 @@ func NewFirewaller(st *firewaller.State) (worker.Worker, error) {
 		unitsChange:    make(chan *unitsChange),
 		unitds:         make(map[names.UnitTag]*unitData),
 		applicationids: make(map[names.ApplicationTag]*serviceData),
-		exposedChange:  make(chan *exposedChange),
 		machinePorts:   make(map[names.MachineTag]machineRanges),
 	}
 	err := catacomb.Invoke(catacomb.Plan{
@@ -114,6 +113,14 @@ func (fw *Firewaller) setUp() error {
 		return errors.Trace(err)
 	}
 
+	fw.exposureWatcher, err = fw.st.WatchExposureIntents()
+	if err != nil {
+		return errors.Annotatef(err, "failed to start exposure intents watcher")
+	}
+	if err := fw.catacomb.Add(fw.exposureWatcher); err != nil {
+		return errors.Trace(err)
+	}
+
 	logger.Debugf("started watching opened port ranges for the environment")
 	return nil
 }
@@ -180,14 +187,15 @@ func (fw *Firewaller) loop() error {
 			if err := fw.unitsChanged(change); err != nil {
 				return errors.Trace(err)
 			}
-		case change := <-fw.exposedChange:
-			change.serviced.exposed = change.exposed
-			unitds := []*unitData{}
-			for _, unitd := range change.serviced.unitds {
-				unitds = append(unitds, unitd)
+		case change, ok := <-fw.exposureWatcher.Changes():
+			if !ok {
+				return errors.New("exposure intents watcher closed")
 			}
-			if err := fw.flushUnits(unitds); err != nil {
-				return errors.Annotate(err, "cannot change firewall ports")
+			for _, applicationName := range change {
+				applicationTag := names.NewApplicationTag(applicationName)
+				if err := fw.exposureIntentChanged(applicationTag); err != nil {
+					return errors.Trace(err)
+				}
 			}
 		}
 	}
@@ -307,31 +315,42 @@ func (fw *Firewaller) startUnit(unit *firewaller.Unit, machineTag names.MachineT
 }
 
 // startService creates a new data value for tracking details of the
-// service and starts watching the service for exposure changes.
+// service. Subsequent exposure changes are reported by the exposure
+// intents watcher.
 func (fw *Firewaller) startService(service *firewaller.Application) error {
-	exposed, err := service.IsExposed()
-	if err != nil {
-		return err
-	}
 	serviced := &serviceData{
 		fw:          fw,
 		application: service,
-		exposed:     exposed,
 		unitds:      make(map[names.UnitTag]*unitData),
 	}
-	err = catacomb.Invoke(catacomb.Plan{
-		Site: &serviced.catacomb,
-		Work: func() error {
-			return serviced.watchLoop(exposed)
-		},
-	})
-	if err != nil {
-		return errors.Trace(err)
+	if err := serviced.refreshExposure(); err != nil {
+		return err
+	}
+	fw.applicationids[service.Tag()] = serviced
+	return nil
+}
+
+// exposureIntentChanged updates the firewall ports of the units of the
+// specified service when its exposure intent changes. Services without
+// tracked units are ignored; their exposure intent is read when the
+// first of their units is started.
+func (fw *Firewaller) exposureIntentChanged(tag names.ApplicationTag) error {
+	serviced, ok := fw.applicationids[tag]
+	if !ok {
+		return nil
 	}
-	if err := fw.catacomb.Add(serviced); err != nil {
+	if err := serviced.refreshExposure(); params.IsCodeNotFound(err) {
+		return nil
+	} else if err != nil {
 		return errors.Trace(err)
 	}
-	fw.applicationids[service.Tag()] = serviced
+	unitds := []*unitData{}
+	for _, unitd := range serviced.unitds {
+		unitds = append(unitds, unitd)
+	}
+	if err := fw.flushUnits(unitds); err != nil {
+		return errors.Annotate(err, "cannot change firewall ports")
+	}
 	return nil
 }
 
@@ -351,7 +370,7 @@ func (fw *Firewaller) reconcileGlobal() error {
 				delete(machined.unitds, unitTag)
 				continue
 			}
-			if unitd.serviced.exposed {
+			if unitd.serviced.exposesPortRange(unitTag, portRange) {
 				collector[portRange] = true
 			}
 		}
@@ -361,15 +380,7 @@ func (fw *Firewaller) reconcileGlobal() error {
 		wantedPorts = append(wantedPorts, port)
 	}
 	// Check which ports to open or to close.
-	toOpen := diffRanges(wantedPorts, initialPortRanges)
-	toClose := diffRanges(initialPortRanges, wantedPorts)
-	if len(toOpen) > 0 {
-		logger.Infof("opening global ports %v", toOpen)
-		if err := fw.environ.OpenPorts(toOpen); err != nil {
-			return err
-		}
-		network.SortPortRanges(toOpen)
-	}
+	toOpen, toClose := reconcileRanges(wantedPorts, initialPortRanges)
 	if len(toClose) > 0 {
 		logger.Infof("closing global ports %v", toClose)
 		if err := fw.environ.ClosePorts(toClose); err != nil {
@@ -377,6 +388,13 @@ func (fw *Firewaller) reconcileGlobal() error {
 		}
 		network.SortPortRanges(toClose)
 	}
+	if len(toOpen) > 0 {
+		logger.Infof("opening global ports %v", toOpen)
+		if err := fw.environ.OpenPorts(toOpen); err != nil {
+			return err
+		}
+		network.SortPortRanges(toOpen)
+	}
 	return nil
 }
 
@@ -417,17 +435,7 @@ func (fw *Firewaller) reconcileInstances() error {
 		}
 
 		// Check which ports to open or to close.
-		toOpen := diffRanges(machined.openedPorts, initialPortRanges)
-		toClose := diffRanges(initialPortRanges, machined.openedPorts)
-		if len(toOpen) > 0 {
-			logger.Infof("opening instance port ranges %v for %q",
-				toOpen, machined.tag)
-			if err := instances[0].OpenPorts(machineId, toOpen); err != nil {
-				// TODO(mue) Add local retry logic.
-				return err
-			}
-			network.SortPortRanges(toOpen)
-		}
+		toOpen, toClose := reconcileRanges(machined.openedPorts, initialPortRanges)
 		if len(toClose) > 0 {
 			logger.Infof("closing instance port ranges %v for %q",
 				toClose, machined.tag)
@@ -437,6 +445,15 @@ func (fw *Firewaller) reconcileInstances() error {
 			}
 			network.SortPortRanges(toClose)
 		}
+		if len(toOpen) > 0 {
+			logger.Infof("opening instance port ranges %v for %q",
+				toOpen, machined.tag)
+			if err := instances[0].OpenPorts(machineId, toOpen); err != nil {
+				// TODO(mue) Add local retry logic.
+				return err
+			}
+			network.SortPortRanges(toOpen)
+		}
 	}
 	return nil
 }
@@ -564,7 +581,7 @@ func (fw *Firewaller) flushMachine(machined *machineData) error {
 			delete(machined.unitds, unitTag)
 			continue
 		}
-		if unitd.serviced.exposed {
+		if unitd.serviced.exposesPortRange(unitTag, portRange) {
 			want = append(want, portRange)
 		}
 	}
@@ -708,16 +725,11 @@ func (fw *Firewaller) forgetUnit(unitd *unitData) {
 	serviced := unitd.serviced
 	machined := unitd.machined
 
-	// If it's the last unit in the service, we'll need to stop the serviced.
-	stoppedService := false
+	// If it's the last unit in the service, we'll need to forget the serviced.
+	forgetService := false
 	if len(serviced.unitds) == 1 {
 		if _, found := serviced.unitds[unitd.tag]; found {
-			// Unusually, it's fine to ignore this error, because we know the
-			// serviced is being tracked in fw.catacomb. But we do still want
-			// to wait until the watch loop has stopped before we nuke the last
-			// data and return.
-			worker.Stop(serviced)
-			stoppedService = true
+			forgetService = true
 		}
 	}
 
@@ -726,7 +738,7 @@ func (fw *Firewaller) forgetUnit(unitd *unitData) {
 	delete(machined.unitds, unitd.tag)
 	delete(serviced.unitds, unitd.tag)
 	logger.Debugf("stopped watching %q", unitd.tag)
-	if stoppedService {
+	if forgetService {
 		applicationTag := serviced.application.Tag()
 		delete(fw.applicationids, applicationTag)
 		logger.Debugf("stopped watching %q", applicationTag)
@@ -805,70 +817,57 @@ type unitData struct {
 	machined *machineData
 }
 
-// exposedChange contains the changed exposed flag for one specific service.
-type exposedChange struct {
-	serviced *serviceData
-	exposed  bool
-}
-
-// serviceData holds service details and watches exposure changes.
+// serviceData holds service details.
 type serviceData struct {
-	catacomb    catacomb.Catacomb
 	fw          *Firewaller
 	application *firewaller.Application
 	exposed     bool
 	unitds      map[names.UnitTag]*unitData
-}
 
-// watchLoop watches the service's exposed flag for changes.
-func (sd *serviceData) watchLoop(exposed bool) error {
-	serviceWatcher, err := sd.application.Watch()
-	if err != nil {
-		return errors.Trace(err)
-	}
-	if err := sd.catacomb.Add(serviceWatcher); err != nil {
-		return errors.Trace(err)
-	}
-	for {
-		select {
-		case <-sd.catacomb.Dying():
-			return sd.catacomb.ErrDying()
-		case _, ok := <-serviceWatcher.Changes():
-			if !ok {
-				return errors.New("service watcher closed")
-			}
-			if err := sd.application.Refresh(); err != nil {
-				if !params.IsCodeNotFound(err) {
-					return errors.Trace(err)
-				}
-				return nil
-			}
-			change, err := sd.application.IsExposed()
-			if err != nil {
-				return errors.Trace(err)
-			}
-			if change == exposed {
-				continue
-			}
+	// intentPorts holds the port ranges recorded in the service's
+	// exposure intent, by unit. It is nil if the service has no
+	// exposure intent, in which case all of the port ranges opened
+	// by its units are exposed while it is exposed.
+	intentPorts map[names.UnitTag][]network.PortRange
+}
 
-			exposed = change
-			select {
-			case sd.fw.exposedChange <- &exposedChange{sd, change}:
-			case <-sd.catacomb.Dying():
-				return sd.catacomb.ErrDying()
-			}
+// refreshExposure reads the service's exposure intent, falling back
+// to its exposed flag if it has not been exposed since exposure
+// intents were introduced.
+func (sd *serviceData) refreshExposure() error {
+	intent, err := sd.application.ExposureIntent()
+	if params.IsCodeNotFound(err) {
+		exposed, err := sd.application.IsExposed()
+		if err != nil {
+			return err
 		}
+		sd.exposed = exposed
+		sd.intentPorts = nil
+		return nil
+	} else if err != nil {
+		return err
 	}
+	sd.exposed = intent.Exposed
+	sd.intentPorts = intent.PortRanges
+	return nil
 }
 
-// Kill is part of the worker.Worker interface.
-func (sd *serviceData) Kill() {
-	sd.catacomb.Kill(nil)
-}
-
-// Wait is part of the worker.Worker interface.
-func (sd *serviceData) Wait() error {
-	return sd.catacomb.Wait()
+// exposesPortRange reports whether the port range opened by the unit
+// should be opened in the environment: the service must be exposed,
+// and the port range must be in its exposure intent if it has one.
+func (sd *serviceData) exposesPortRange(unitTag names.UnitTag, portRange network.PortRange) bool {
+	if !sd.exposed {
+		return false
+	}
+	if sd.intentPorts == nil {
+		return true
+	}
+	for _, intentRange := range sd.intentPorts[unitTag] {
+		if intentRange == portRange {
+			return true
+		}
+	}
+	return false
 }
 
 // diffRanges returns all the port rangess that exist in A but not B.
@@ -885,6 +884,89 @@ next:
 	return
 }
 
+// reconcileRanges returns the port ranges that must be closed and then
+// opened so that the port ranges reported open by a provider, have,
+// match those wanted.
+//
+// Providers may report their open ports consolidated into fewer, larger
+// ranges than were requested, so ranges are compared by coverage rather
+// than for equality: a wanted range that is covered by those open need
+// not be opened, and an open range that is covered by those wanted need
+// not be closed. Closing an open range that is only partly wanted also
+// closes the wanted parts of it, so any wanted range overlapping a range
+// to close is opened again afterwards, unless it is itself reported open.
+func reconcileRanges(want, have []network.PortRange) (toOpen, toClose []network.PortRange) {
+	toClose = uncoveredRanges(have, want)
+	for _, portRange := range want {
+		switch {
+		case !rangeCovered(portRange, have):
+		case rangeOverlapsAny(portRange, toClose) && !containsRange(have, portRange):
+		default:
+			continue
+		}
+		toOpen = append(toOpen, portRange)
+	}
+	return toOpen, toClose
+}
+
+// uncoveredRanges returns the port ranges in A that are not covered by
+// the union of the port ranges in B.
+func uncoveredRanges(A, B []network.PortRange) (uncovered []network.PortRange) {
+	for _, a := range A {
+		if !rangeCovered(a, B) {
+			uncovered = append(uncovered, a)
+		}
+	}
+	return uncovered
+}
+
+// rangeCovered reports whether every port in r is covered by one of the
+// port ranges with the same protocol in ranges.
+func rangeCovered(r network.PortRange, ranges []network.PortRange) bool {
+	var candidates []network.PortRange
+	for _, candidate := range ranges {
+		if candidate.Protocol == r.Protocol {
+			candidates = append(candidates, candidate)
+		}
+	}
+	network.SortPortRanges(candidates)
+	next := r.FromPort
+	for _, candidate := range candidates {
+		if candidate.ToPort < next {
+			continue
+		}
+		if candidate.FromPort > next {
+			return false
+		}
+		next = candidate.ToPort + 1
+		if next > r.ToPort {
+			return true
+		}
+	}
+	return false
+}
+
+// rangeOverlapsAny reports whether r shares any port with one of the
+// port ranges in ranges.
+func rangeOverlapsAny(r network.PortRange, ranges []network.PortRange) bool {
+	for _, other := range ranges {
+		if other.Protocol == r.Protocol && other.FromPort <= r.ToPort && r.FromPort <= other.ToPort {
+			return true
+		}
+	}
+	return false
+}
+
+// containsRange reports whether ranges contains r.
+func containsRange(ranges []network.PortRange, r network.PortRange) bool {
+	for _, other := range ranges {
+		if other == r {
+			return true
+		}
+	}
+	return false
+}
+
 // parsePortsKey parses a ports document global key coming from the ports
 // watcher (e.g. "42:0.1.2.0/24") and returns the machine and subnet tags from
 // its components (in the last example "machine-42" and "subnet-0.1.2.0/24").
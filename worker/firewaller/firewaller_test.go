@@ -320,6 +320,26 @@ func (s *InstanceModeSuite) TestStartWithState(c *gc.C) {
 	c.Assert(err, jc.ErrorIsNil)
 }
 
+func (s *InstanceModeSuite) TestStartWithPartlyWantedConsolidatedPorts(c *gc.C) {
+	app := s.AddTestingService(c, "wordpress", s.charm)
+	err := app.SetExposed()
+	c.Assert(err, jc.ErrorIsNil)
+	u, m := s.addUnit(c, app)
+	inst := s.startInstance(c, m)
+
+	err = u.OpenPort("tcp", 80)
+	c.Assert(err, jc.ErrorIsNil)
+	err = inst.OpenPorts(m.Id(), []network.PortRange{{80, 81, "tcp"}})
+	c.Assert(err, jc.ErrorIsNil)
+
+	// Closing the merged range must not leave port 80 closed.
+	fw, err := firewaller.NewFirewaller(s.firewaller)
+	c.Assert(err, jc.ErrorIsNil)
+	defer statetesting.AssertKillAndWait(c, fw)
+
+	s.assertPorts(c, inst, m.Id(), []network.PortRange{{80, 80, "tcp"}})
+}
+
 func (s *InstanceModeSuite) TestStartWithPartialState(c *gc.C) {
 	m, err := s.State.AddMachine("quantal", state.JobHostUnits)
 	c.Assert(err, jc.ErrorIsNil)
@@ -0,0 +1,124 @@
+// Copyright 2017 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package firewaller_test
+
+import (
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/network"
+	coretesting "github.com/juju/juju/testing"
+	"github.com/juju/juju/worker/firewaller"
+)
+
+type ReconcileSuite struct {
+	coretesting.BaseSuite
+}
+
+var _ = gc.Suite(&ReconcileSuite{})
+
+// consolidatingFirewall models a provider that merges contiguous
+// port ranges, and reports the merged ranges as open.
+type consolidatingFirewall struct {
+	open []network.PortRange
+}
+
+func (f *consolidatingFirewall) ports() []network.PortRange {
+	var ports []network.PortRange
+	for _, r := range f.open {
+		if n := len(ports); n > 0 && ports[n-1].Protocol == r.Protocol && r.FromPort <= ports[n-1].ToPort+1 {
+			if r.ToPort > ports[n-1].ToPort {
+				ports[n-1].ToPort = r.ToPort
+			}
+			continue
+		}
+		ports = append(ports, r)
+	}
+	return ports
+}
+
+func (f *consolidatingFirewall) openPorts(ports []network.PortRange) {
+	f.open = append(f.open, ports...)
+	network.SortPortRanges(f.open)
+	f.open = f.ports()
+}
+
+func (f *consolidatingFirewall) closePorts(ports []network.PortRange) {
+	var remaining []network.PortRange
+	for _, r := range f.ports() {
+		for port := r.FromPort; port <= r.ToPort; port++ {
+			closed := false
+			for _, c := range ports {
+				if c.Protocol == r.Protocol && c.FromPort <= port && port <= c.ToPort {
+					closed = true
+					break
+				}
+			}
+			if !closed {
+				remaining = append(remaining, network.PortRange{port, port, r.Protocol})
+			}
+		}
+	}
+	f.open = nil
+	f.openPorts(remaining)
+}
+
+// reconcile reconciles the firewall with the wanted ports in the
+// same order as the firewaller, and returns the number of ranges
+// opened and closed.
+func (f *consolidatingFirewall) reconcile(want []network.PortRange) (opened, closed int) {
+	toOpen, toClose := firewaller.ReconcileRanges(want, f.ports())
+	f.closePorts(toClose)
+	f.openPorts(toOpen)
+	return len(toOpen), len(toClose)
+}
+
+func (s *ReconcileSuite) TestConsolidatedRangesRoundTrip(c *gc.C) {
+	want := []network.PortRange{
+		{80, 80, "tcp"},
+		{81, 81, "tcp"},
+		{82, 90, "tcp"},
+		{53, 53, "udp"},
+		{8080, 8080, "tcp"},
+	}
+	var fw consolidatingFirewall
+	opened, closed := fw.reconcile(want)
+	c.Assert(opened, gc.Equals, len(want))
+	c.Assert(closed, gc.Equals, 0)
+	c.Assert(fw.ports(), jc.DeepEquals, []network.PortRange{
+		{80, 90, "tcp"},
+		{8080, 8080, "tcp"},
+		{53, 53, "udp"},
+	})
+
+	// The merged ranges cover exactly what is wanted, so
+	// reconciling again must neither open nor close anything.
+	opened, closed = fw.reconcile(want)
+	c.Assert(opened, gc.Equals, 0)
+	c.Assert(closed, gc.Equals, 0)
+}
+
+func (s *ReconcileSuite) TestConsolidatedRangePartlyWanted(c *gc.C) {
+	var fw consolidatingFirewall
+	fw.openPorts([]network.PortRange{{80, 80, "tcp"}, {81, 81, "tcp"}})
+	want := []network.PortRange{{80, 80, "tcp"}}
+
+	opened, closed := fw.reconcile(want)
+	c.Assert(opened, gc.Equals, 1)
+	c.Assert(closed, gc.Equals, 1)
+	c.Assert(fw.ports(), jc.DeepEquals, want)
+
+	opened, closed = fw.reconcile(want)
+	c.Assert(opened, gc.Equals, 0)
+	c.Assert(closed, gc.Equals, 0)
+}
+
+func (s *ReconcileSuite) TestExactRanges(c *gc.C) {
+	toOpen, toClose := firewaller.ReconcileRanges(
+		[]network.PortRange{{80, 80, "tcp"}, {443, 443, "tcp"}},
+		[]network.PortRange{{80, 80, "tcp"}, {80, 80, "udp"}},
+	)
+	c.Assert(toOpen, jc.DeepEquals, []network.PortRange{{443, 443, "tcp"}})
+	c.Assert(toClose, jc.DeepEquals, []network.PortRange{{80, 80, "udp"}})
+}
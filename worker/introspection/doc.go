@@ -9,4 +9,6 @@
 //   - prints out all the goroutines in the agent
 // * `/debug/pprof/heap?debug=1`
 //   - prints out the heap profile
+// * `/provider/trace/<machine-tag>?model=<model-uuid>`
+//   - prints out the requests made to the cloud on behalf of the machine
 package introspection
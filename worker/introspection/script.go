@@ -77,10 +77,17 @@ juju-engine-report () {
   jujuMachineOrUnit depengine/ $@
 }
 
+juju-provider-trace () {
+  # Optional args are the tag of the machine whose requests to show,
+  # and the UUID of the model containing it.
+  jujuAgentCall $(jujuMachineAgentName) "provider/trace/$1${2:+?model=$2}"
+}
+
 export -f jujuAgentCall
 export -f jujuMachineAgentName
 export -f jujuMachineOrUnit
 export -f juju-goroutines
 export -f juju-heap-profile
 export -f juju-engine-report
+export -f juju-provider-trace
 `
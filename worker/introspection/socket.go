@@ -4,13 +4,18 @@
 package introspection
 
 import (
+	"bytes"
 	"fmt"
+	"io"
 	"net"
 	"net/http"
 	"runtime"
+	"sort"
+	"strings"
 
 	"github.com/juju/errors"
 	"github.com/juju/loggo"
+	"gopkg.in/juju/names.v2"
 	"gopkg.in/tomb.v1"
 	"gopkg.in/yaml.v2"
 
@@ -27,10 +32,31 @@ type DepEngineReporter interface {
 	Report() map[string]interface{}
 }
 
+// RequestTracer provides insight into the requests made to a cloud by an
+// environ provider on behalf of machines.
+type RequestTracer interface {
+	// WriteRequestTrace writes a trace of the requests made on behalf of
+	// the machine with the given tag in the model with the given UUID
+	// to w. If the model UUID is empty, machines with the tag in all
+	// models are included; if the tag is empty, all machines are. If
+	// no requests have been traced, an error satisfying
+	// errors.IsNotFound is returned.
+	WriteRequestTrace(w io.Writer, modelUUID, machineTag string) error
+}
+
 // Config describes the arguments required to create the introspection worker.
 type Config struct {
 	SocketName string
 	Reporter   DepEngineReporter
+
+	// PrometheusHandler, if non-nil, is used to serve the
+	// agent's Prometheus metrics at /metrics.
+	PrometheusHandler http.Handler
+
+	// RequestTracers, keyed by provider type, are used to serve
+	// traces of the requests made by the agent's environ providers
+	// at /provider/trace/[<machine-tag>][?model=<model-uuid>].
+	RequestTracers map[string]RequestTracer
 }
 
 // Validate checks the config values to assert they are valid to create the worker.
@@ -43,10 +69,12 @@ func (c *Config) Validate() error {
 
 // socketListener is a worker and constructed with NewWorker.
 type socketListener struct {
-	tomb     tomb.Tomb
-	listener *net.UnixListener
-	reporter DepEngineReporter
-	done     chan struct{}
+	tomb       tomb.Tomb
+	listener   *net.UnixListener
+	reporter   DepEngineReporter
+	prometheus http.Handler
+	tracers    map[string]RequestTracer
+	done       chan struct{}
 }
 
 // NewWorker starts an http server listening on an abstract domain socket
@@ -72,9 +100,11 @@ func NewWorker(config Config) (worker.Worker, error) {
 	logger.Debugf("introspection worker listening on %q", path)
 
 	w := &socketListener{
-		listener: l,
-		reporter: config.Reporter,
-		done:     make(chan struct{}),
+		listener:   l,
+		reporter:   config.Reporter,
+		prometheus: config.PrometheusHandler,
+		tracers:    config.RequestTracers,
+		done:       make(chan struct{}),
 	}
 	go w.serve()
 	go w.run()
@@ -88,6 +118,8 @@ func (w *socketListener) serve() {
 	mux.Handle("/debug/pprof/profile", http.HandlerFunc(pprof.Profile))
 	mux.Handle("/debug/pprof/symbol", http.HandlerFunc(pprof.Symbol))
 	mux.Handle("/depengine/", http.HandlerFunc(w.depengineReport))
+	mux.Handle("/metrics", http.HandlerFunc(w.metrics))
+	mux.Handle("/provider/trace/", http.HandlerFunc(w.providerTrace))
 
 	srv := http.Server{
 		Handler: mux,
@@ -137,3 +169,65 @@ func (s *socketListener) depengineReport(w http.ResponseWriter, r *http.Request)
 	fmt.Fprint(w, "Dependency Engine Report\n\n")
 	w.Write(bytes)
 }
+
+func (s *socketListener) metrics(w http.ResponseWriter, r *http.Request) {
+	if s.prometheus == nil {
+		w.WriteHeader(http.StatusNotFound)
+		fmt.Fprintln(w, "missing prometheus handler")
+		return
+	}
+	s.prometheus.ServeHTTP(w, r)
+}
+
+func (s *socketListener) providerTrace(w http.ResponseWriter, r *http.Request) {
+	if len(s.tracers) == 0 {
+		w.WriteHeader(http.StatusNotFound)
+		fmt.Fprintln(w, "missing request tracers")
+		return
+	}
+	machineTag := strings.TrimPrefix(r.URL.Path, "/provider/trace/")
+	if machineTag != "" {
+		if _, err := names.ParseMachineTag(machineTag); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Fprintf(w, "error: %v\n", err)
+			return
+		}
+	}
+	modelUUID := r.URL.Query().Get("model")
+	if modelUUID != "" && !names.IsValidModel(modelUUID) {
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Fprintf(w, "error: %q is not a valid model UUID\n", modelUUID)
+		return
+	}
+
+	providerTypes := make([]string, 0, len(s.tracers))
+	for providerType := range s.tracers {
+		providerTypes = append(providerTypes, providerType)
+	}
+	sort.Strings(providerTypes)
+
+	var buf bytes.Buffer
+	for _, providerType := range providerTypes {
+		var trace bytes.Buffer
+		err := s.tracers[providerType].WriteRequestTrace(&trace, modelUUID, machineTag)
+		if errors.IsNotFound(err) {
+			continue
+		} else if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			fmt.Fprintf(w, "error: %v\n", err)
+			return
+		}
+		fmt.Fprintf(&buf, "Provider %q\n\n", providerType)
+		trace.WriteTo(&buf)
+		fmt.Fprintln(&buf)
+	}
+	if buf.Len() == 0 {
+		w.WriteHeader(http.StatusNotFound)
+		fmt.Fprintln(w, "no requests traced")
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	fmt.Fprint(w, "Provider Request Trace\n\n")
+	buf.WriteTo(w)
+}
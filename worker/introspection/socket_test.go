@@ -7,12 +7,15 @@ import (
 	"bufio"
 	"bytes"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"net"
+	"net/http"
 	"os"
 	"regexp"
 	"runtime"
 
+	"github.com/juju/errors"
 	"github.com/juju/testing"
 	jc "github.com/juju/testing/checkers"
 	gc "gopkg.in/check.v1"
@@ -49,9 +52,11 @@ func (s *suite) TestStartStop(c *gc.C) {
 type introspectionSuite struct {
 	testing.IsolationSuite
 
-	name     string
-	worker   worker.Worker
-	reporter introspection.DepEngineReporter
+	name       string
+	worker     worker.Worker
+	reporter   introspection.DepEngineReporter
+	prometheus http.Handler
+	tracers    map[string]introspection.RequestTracer
 }
 
 var _ = gc.Suite(&introspectionSuite{})
@@ -62,6 +67,8 @@ func (s *introspectionSuite) SetUpTest(c *gc.C) {
 	}
 	s.IsolationSuite.SetUpTest(c)
 	s.reporter = nil
+	s.prometheus = nil
+	s.tracers = nil
 	s.worker = nil
 	s.startWorker(c)
 }
@@ -69,8 +76,10 @@ func (s *introspectionSuite) SetUpTest(c *gc.C) {
 func (s *introspectionSuite) startWorker(c *gc.C) {
 	s.name = fmt.Sprintf("introspection-test-%d", os.Getpid())
 	w, err := introspection.NewWorker(introspection.Config{
-		SocketName: s.name,
-		Reporter:   s.reporter,
+		SocketName:        s.name,
+		Reporter:          s.reporter,
+		PrometheusHandler: s.prometheus,
+		RequestTracers:    s.tracers,
 	})
 	c.Assert(err, jc.ErrorIsNil)
 	s.worker = w
@@ -127,6 +136,90 @@ func (s *introspectionSuite) TestEngineReporter(c *gc.C) {
 	matches(c, buf, "working: true")
 }
 
+func (s *introspectionSuite) TestMissingPrometheusHandler(c *gc.C) {
+	buf := s.call(c, "/metrics")
+	matches(c, buf, "404 Not Found")
+	matches(c, buf, "missing prometheus handler")
+}
+
+func (s *introspectionSuite) TestPrometheusHandler(c *gc.C) {
+	workertest.CheckKill(c, s.worker)
+	s.prometheus = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintln(w, "juju_metric 42")
+	})
+	s.startWorker(c)
+	buf := s.call(c, "/metrics")
+
+	matches(c, buf, "200 OK")
+	matches(c, buf, "juju_metric 42")
+}
+
+func (s *introspectionSuite) TestMissingRequestTracers(c *gc.C) {
+	buf := s.call(c, "/provider/trace/")
+	matches(c, buf, "404 Not Found")
+	matches(c, buf, "missing request tracers")
+}
+
+func (s *introspectionSuite) startWorkerWithTracer(c *gc.C) *requestTracer {
+	workertest.CheckKill(c, s.worker)
+	tracer := &requestTracer{traces: map[string]string{
+		":machine-0": "machine-0 request",
+		":":          "all requests",
+		"deadbeef-0bad-400d-8000-4b1d0d06f00d:machine-0": "model machine-0 request",
+	}}
+	s.tracers = map[string]introspection.RequestTracer{"azure": tracer}
+	s.startWorker(c)
+	return tracer
+}
+
+func (s *introspectionSuite) TestRequestTrace(c *gc.C) {
+	s.startWorkerWithTracer(c)
+	buf := s.call(c, "/provider/trace/machine-0")
+	matches(c, buf, "200 OK")
+	matches(c, buf, `Provider "azure"`)
+	matches(c, buf, "machine-0 request")
+
+	buf = s.call(c, "/provider/trace/")
+	matches(c, buf, "200 OK")
+	matches(c, buf, "all requests")
+}
+
+func (s *introspectionSuite) TestRequestTraceModel(c *gc.C) {
+	s.startWorkerWithTracer(c)
+	buf := s.call(c, "/provider/trace/machine-0?model=deadbeef-0bad-400d-8000-4b1d0d06f00d")
+	matches(c, buf, "200 OK")
+	matches(c, buf, "model machine-0 request")
+}
+
+func (s *introspectionSuite) TestRequestTraceInvalidModel(c *gc.C) {
+	s.startWorkerWithTracer(c)
+	buf := s.call(c, "/provider/trace/machine-0?model=foo")
+	matches(c, buf, "400 Bad Request")
+	matches(c, buf, `"foo" is not a valid model UUID`)
+}
+
+func (s *introspectionSuite) TestRequestTraceNotFound(c *gc.C) {
+	s.startWorkerWithTracer(c)
+	buf := s.call(c, "/provider/trace/machine-1")
+	matches(c, buf, "404 Not Found")
+	matches(c, buf, "no requests traced")
+}
+
+func (s *introspectionSuite) TestRequestTraceInvalidMachineTag(c *gc.C) {
+	s.startWorkerWithTracer(c)
+	buf := s.call(c, "/provider/trace/unit-mysql-0")
+	matches(c, buf, "400 Bad Request")
+	matches(c, buf, `"unit-mysql-0" is not a valid machine tag`)
+}
+
+func (s *introspectionSuite) TestRequestTraceError(c *gc.C) {
+	tracer := s.startWorkerWithTracer(c)
+	tracer.err = errors.New("boom")
+	buf := s.call(c, "/provider/trace/machine-0")
+	matches(c, buf, "500 Internal Server Error")
+	matches(c, buf, "error: boom")
+}
+
 // matches fails if regex is not found in the contents of b.
 // b is expected to be the response from the pprof http server, and will
 // contain some HTTP preamble that should be ignored.
@@ -150,3 +243,20 @@ type reporter struct {
 func (r *reporter) Report() map[string]interface{} {
 	return r.values
 }
+
+type requestTracer struct {
+	traces map[string]string
+	err    error
+}
+
+func (t *requestTracer) WriteRequestTrace(w io.Writer, modelUUID, machineTag string) error {
+	if t.err != nil {
+		return t.err
+	}
+	trace, ok := t.traces[modelUUID+":"+machineTag]
+	if !ok {
+		return errors.NotFoundf("requests for %s", machineTag)
+	}
+	_, err := fmt.Fprintln(w, trace)
+	return err
+}
@@ -49,6 +49,7 @@ type ProvisionerTask interface {
 type MachineGetter interface {
 	Machine(names.MachineTag) (*apiprovisioner.Machine, error)
 	MachinesWithTransientErrors() ([]*apiprovisioner.Machine, []params.StatusResult, error)
+	MachinesToReplace() ([]*apiprovisioner.Machine, error)
 }
 
 // ToolsFinder is an interface used for finding tools to run on
@@ -179,6 +180,9 @@ func (task *provisionerTask) loop() error {
 			if err := task.processMachinesWithTransientErrors(); err != nil {
 				return errors.Annotate(err, "failed to process machines with transient errors")
 			}
+			if err := task.processMachinesToReplace(); err != nil {
+				return errors.Annotate(err, "failed to process machines to replace")
+			}
 		}
 	}
 }
@@ -480,6 +484,130 @@ func (task *provisionerTask) stopInstances(instances []instance.Instance) error
 	return nil
 }
 
+// processMachinesToReplace starts replacement instances for the machines
+// whose instances have been requested to be replaced, if the broker is
+// able to replace instances.
+func (task *provisionerTask) processMachinesToReplace() error {
+	replacer, ok := task.broker.(environs.InstanceReplacer)
+	if !ok {
+		return nil
+	}
+	machines, err := task.machineGetter.MachinesToReplace()
+	if err != nil {
+		logger.Errorf("cannot get machines to replace: %v", err)
+		return nil
+	}
+	logger.Tracef("processMachinesToReplace(%v)", machines)
+	for _, m := range machines {
+		if err := task.replaceMachine(replacer, m); err != nil {
+			return errors.Annotatef(err, "cannot replace machine %v", m)
+		}
+	}
+	return nil
+}
+
+// replaceMachine starts an instance to replace the machine's existing
+// instance, with a freshly rendered instance config, and with the
+// machine's provisioned volumes attached.
+func (task *provisionerTask) replaceMachine(replacer environs.InstanceReplacer, m *apiprovisioner.Machine) error {
+	instanceId, err := m.InstanceId()
+	if err != nil {
+		return task.setErrorStatus("cannot get instance ID of machine %q: %v", m, err)
+	}
+
+	// Check that the instance is gone before setting up the
+	// replacement's authentication, which changes the machine's
+	// password, locking out the agent of an instance that still
+	// exists.
+	if err := replacer.CheckReplaceable(instanceId); errors.IsAlreadyExists(err) {
+		// There is nothing to do. The request remains until
+		// the instance is gone, or the machine is removed.
+		logger.Warningf("not replacing machine %v: %v", m, err)
+		return nil
+	} else if err != nil {
+		return task.setErrorStatus("cannot check instance of machine %q: %v", m, err)
+	}
+
+	pInfo, err := m.ProvisioningInfo()
+	if err != nil {
+		return task.setErrorStatus("fetching provisioning info for machine %q: %v", m, err)
+	}
+
+	instanceCfg, err := task.constructInstanceConfig(m, task.auth, pInfo)
+	if err != nil {
+		return task.setErrorStatus("creating instance config for machine %q: %v", m, err)
+	}
+
+	var arch string
+	if pInfo.Constraints.Arch != nil {
+		arch = *pInfo.Constraints.Arch
+	}
+
+	possibleTools, err := task.toolsFinder.FindTools(
+		jujuversion.Current,
+		pInfo.Series,
+		arch,
+	)
+	if err != nil {
+		return task.setErrorStatus("cannot find tools for machine %q: %v", m, err)
+	}
+
+	startInstanceParams, err := constructStartInstanceParams(
+		task.controllerUUID,
+		m,
+		instanceCfg,
+		pInfo,
+		possibleTools,
+	)
+	if err != nil {
+		return task.setErrorStatus("cannot construct params for machine %q: %v", m, err)
+	}
+	volumeAttachments, err := volumeAttachmentsFromAPIserver(m, pInfo.VolumeAttachments)
+	if err != nil {
+		return task.setErrorStatus("cannot construct params for machine %q: %v", m, err)
+	}
+
+	result, err := replacer.ReplaceInstance(environs.ReplaceInstanceParams{
+		StartInstanceParams: startInstanceParams,
+		InstanceId:          instanceId,
+		VolumeAttachments:   volumeAttachments,
+	})
+	if errors.IsAlreadyExists(err) {
+		// The instance being replaced reappeared since it was
+		// checked above.
+		logger.Warningf("not replacing machine %v: %v", m, err)
+		return nil
+	} else if err != nil {
+		return task.setErrorStatus("cannot replace instance for machine %q: %v", m, err)
+	}
+
+	volumeNameToAttachmentInfo := volumeAttachmentsToAPIserver(result.VolumeAttachments)
+	if err := m.ReplaceInstance(
+		result.Instance.Id(),
+		startInstanceParams.InstanceConfig.MachineNonce,
+		result.Hardware,
+		volumeNameToAttachmentInfo,
+	); err != nil {
+		// The replacement instance is deleted, leaving the
+		// machine's volumes intact, so that it is not left
+		// running unrecorded; the replacement is retried.
+		if err := replacer.DeleteReplacement(result.Instance.Id()); err != nil {
+			logger.Errorf("cannot delete replacement instance %s for machine %v: %v", result.Instance.Id(), m, err)
+		}
+		return task.setErrorStatus("cannot register replacement instance for machine %q: %v", m, err)
+	}
+
+	logger.Infof(
+		"replaced instance %s of machine %s with instance %s, with hardware %q, volume attachments %v",
+		instanceId,
+		m,
+		result.Instance.Id(),
+		result.Hardware,
+		volumeNameToAttachmentInfo,
+	)
+	return nil
+}
+
 func (task *provisionerTask) constructInstanceConfig(
 	machine *apiprovisioner.Machine,
 	auth authentication.AuthenticationProvider,
@@ -573,7 +701,8 @@ func constructStartInstanceParams(
 					Machine:  machineTag,
 					ReadOnly: v.Attachment.ReadOnly,
 				},
-				Volume: volumeTag,
+				Volume:              volumeTag,
+				DeleteOnTermination: v.Attachment.DeleteOnTermination,
 			},
 		}
 	}
@@ -606,6 +735,9 @@ func constructStartInstanceParams(
 			VirtType:    metadata.VirtType,
 			Version:     metadata.Version,
 		}
+		if metadata.RootStorageSize != nil {
+			possibleImageMetadata[i].MinRootDisk = *metadata.RootStorageSize * 1024
+		}
 	}
 
 	return environs.StartInstanceParams{
@@ -692,6 +824,52 @@ func (task *provisionerTask) setErrorStatus(message string, machine *apiprovisio
 	return nil
 }
 
+// setProvisioningError records on the machine the classified reason
+// that an instance could not be started for it, so that it can be
+// reported to the user. Failure to record the error is logged, but
+// is otherwise ignored; the machine's error status is set regardless.
+func (task *provisionerTask) setProvisioningError(machine *apiprovisioner.Machine, err error, attempts int) {
+	kind := environs.ProvisioningErrorKindOf(err)
+	if err1 := machine.SetProvisioningError(string(kind), err.Error(), attempts); err1 != nil {
+		logger.Errorf("cannot record provisioning error for machine %q: %v", machine, err1)
+	}
+}
+
+// addQuotaContext annotates the given error with the details of any
+// quotas with too little remaining capacity to start an instance with
+// the given constraints, if the broker is able to report them.
+// Providers often report exhausted quotas with opaque errors, so this
+// tells the user why an instance could not be started.
+func (task *provisionerTask) addQuotaContext(err error, cons constraints.Value) error {
+	quotaInfo, ok := task.broker.(environs.QuotaInfo)
+	if !ok {
+		return err
+	}
+	quotas, quotaErr := quotaInfo.Quotas()
+	if quotaErr != nil {
+		logger.Warningf("cannot get quotas: %v", quotaErr)
+		return err
+	}
+	if insufficient := environs.InsufficientQuotas(quotas, requiredQuotas(cons)); insufficient != "" {
+		err = errors.Annotatef(err, "quota exhausted (%s)", insufficient)
+		return environs.NewProvisioningError(err, environs.ProvisioningErrorQuota)
+	}
+	return err
+}
+
+// requiredQuotas returns the amount of each quota-limited resource
+// needed to start a single instance with the given constraints.
+func requiredQuotas(cons constraints.Value) map[string]int64 {
+	cores := int64(1)
+	if cons.CpuCores != nil && *cons.CpuCores > 0 {
+		cores = int64(*cons.CpuCores)
+	}
+	return map[string]int64{
+		environs.QuotaCores:           cores,
+		environs.QuotaVirtualMachines: 1,
+	}
+}
+
 func (task *provisionerTask) startMachine(
 	machine *apiprovisioner.Machine,
 	provisioningInfo *params.ProvisioningInfo,
@@ -707,6 +885,8 @@ func (task *provisionerTask) startMachine(
 			// Set the state to error, so the machine will be skipped
 			// next time until the error is resolved, but don't return
 			// an error; just keep going with the other machines.
+			err = task.addQuotaContext(err, startInstanceParams.Constraints)
+			task.setProvisioningError(machine, err, task.retryStartInstanceStrategy.retryCount+1)
 			return task.setErrorStatus("cannot start instance for machine %q: %v", machine, err)
 		}
 
@@ -796,6 +976,37 @@ func volumesToAPIserver(volumes []storage.Volume) []params.Volume {
 	return result
 }
 
+func volumeAttachmentsFromAPIserver(
+	machine *apiprovisioner.Machine,
+	attachments []params.VolumeAttachmentParams,
+) ([]storage.VolumeAttachmentParams, error) {
+	result := make([]storage.VolumeAttachmentParams, len(attachments))
+	for i, a := range attachments {
+		volumeTag, err := names.ParseVolumeTag(a.VolumeTag)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		machineTag, err := names.ParseMachineTag(a.MachineTag)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		if machineTag != machine.Tag() {
+			return nil, errors.Errorf("volume attachment params has invalid machine tag")
+		}
+		result[i] = storage.VolumeAttachmentParams{
+			AttachmentParams: storage.AttachmentParams{
+				Provider: storage.ProviderType(a.Provider),
+				Machine:  machineTag,
+				ReadOnly: a.ReadOnly,
+			},
+			Volume:              volumeTag,
+			VolumeId:            a.VolumeId,
+			DeleteOnTermination: a.DeleteOnTermination,
+		}
+	}
+	return result, nil
+}
+
 func volumeAttachmentsToAPIserver(attachments []storage.VolumeAttachment) map[string]params.VolumeAttachmentInfo {
 	result := make(map[string]params.VolumeAttachmentInfo)
 	for _, a := range attachments {
@@ -1078,6 +1078,10 @@ func (*mockMachineGetter) MachinesWithTransientErrors() ([]*apiprovisioner.Machi
 	return nil, nil, fmt.Errorf("error")
 }
 
+func (*mockMachineGetter) MachinesToReplace() ([]*apiprovisioner.Machine, error) {
+	return nil, fmt.Errorf("error")
+}
+
 func (s *ProvisionerSuite) TestMachineErrorsRetainInstances(c *gc.C) {
 	task := s.newProvisionerTask(c, config.HarvestAll, s.Environ, s.provisioner, mockToolsFinder{})
 	defer stop(c, task)
@@ -1310,6 +1314,80 @@ func (s *ProvisionerSuite) TestProvisionerObservesMachineJobs(c *gc.C) {
 	}
 }
 
+func (s *ProvisionerSuite) TestProvisionerReportsExhaustedQuotas(c *gc.C) {
+	broker := &quotaBroker{Environ: s.Environ, quotas: []environs.Quota{
+		{Resource: environs.QuotaCores, Used: 20, Limit: 20},
+		{Resource: environs.QuotaPublicIPAddresses, Used: 1, Limit: 20},
+	}}
+	task := s.newProvisionerTask(c, config.HarvestAll, broker, s.provisioner, mockToolsFinder{})
+	defer stop(c, task)
+
+	m, err := s.addMachine()
+	c.Assert(err, jc.ErrorIsNil)
+	s.checkNoOperations(c)
+
+	t0 := time.Now()
+	for time.Since(t0) < coretesting.LongWait {
+		statusInfo, err := m.Status()
+		c.Assert(err, jc.ErrorIsNil)
+		if statusInfo.Status == status.Pending {
+			time.Sleep(coretesting.ShortWait)
+			continue
+		}
+		c.Assert(statusInfo.Status, gc.Equals, status.Error)
+		c.Assert(statusInfo.Message, gc.Equals, "quota exhausted (cores quota: 20 of 20 used): 409 conflict")
+		perr, err := m.ProvisioningError()
+		c.Assert(err, jc.ErrorIsNil)
+		c.Assert(perr.Kind, gc.Equals, "quota")
+		c.Assert(perr.Message, gc.Equals, statusInfo.Message)
+		c.Assert(perr.Attempts, gc.Equals, 1)
+		return
+	}
+	c.Fatal("Test took too long to complete")
+}
+
+func (s *ProvisionerSuite) TestProvisionerReportsInsufficientQuotas(c *gc.C) {
+	broker := &quotaBroker{Environ: s.Environ, quotas: []environs.Quota{
+		{Resource: environs.QuotaCores, Used: 18, Limit: 20},
+		{Resource: environs.QuotaVirtualMachines, Used: 1, Limit: 20},
+	}}
+	task := s.newProvisionerTask(c, config.HarvestAll, broker, s.provisioner, mockToolsFinder{})
+	defer stop(c, task)
+
+	m, err := s.addMachineWithConstraints(constraints.MustParse("cores=4"))
+	c.Assert(err, jc.ErrorIsNil)
+	s.checkNoOperations(c)
+
+	t0 := time.Now()
+	for time.Since(t0) < coretesting.LongWait {
+		statusInfo, err := m.Status()
+		c.Assert(err, jc.ErrorIsNil)
+		if statusInfo.Status == status.Pending {
+			time.Sleep(coretesting.ShortWait)
+			continue
+		}
+		c.Assert(statusInfo.Status, gc.Equals, status.Error)
+		c.Assert(statusInfo.Message, gc.Equals, "quota exhausted (cores quota: 18 of 20 used, 4 required): 409 conflict")
+		return
+	}
+	c.Fatal("Test took too long to complete")
+}
+
+// quotaBroker fails to start any instance, and reports
+// the configured quotas.
+type quotaBroker struct {
+	environs.Environ
+	quotas []environs.Quota
+}
+
+func (b *quotaBroker) StartInstance(args environs.StartInstanceParams) (*environs.StartInstanceResult, error) {
+	return nil, errors.New("409 conflict")
+}
+
+func (b *quotaBroker) Quotas() ([]environs.Quota, error) {
+	return b.quotas, nil
+}
+
 type mockBroker struct {
 	environs.Environ
 	retryCount map[string]int
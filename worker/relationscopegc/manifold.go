@@ -0,0 +1,68 @@
+// Copyright 2016 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package relationscopegc
+
+import (
+	"time"
+
+	"github.com/juju/errors"
+	"github.com/juju/utils/clock"
+
+	"github.com/juju/juju/api/base"
+	"github.com/juju/juju/api/relationscopegc"
+	"github.com/juju/juju/worker"
+	"github.com/juju/juju/worker/dependency"
+)
+
+// ManifoldConfig describes how to create a worker that removes stale
+// relation scope memberships from a model.
+type ManifoldConfig struct {
+	APICallerName string
+	ClockName     string
+
+	Period      time.Duration
+	GracePeriod time.Duration
+	NewFacade   func(base.APICaller) (Facade, error)
+	NewWorker   func(Config) (worker.Worker, error)
+}
+
+// Manifold returns a dependency.Manifold that runs a relation scope
+// garbage collector according to the supplied configuration.
+func Manifold(config ManifoldConfig) dependency.Manifold {
+	return dependency.Manifold{
+		Inputs: []string{
+			config.APICallerName,
+			config.ClockName,
+		},
+		Start: func(context dependency.Context) (worker.Worker, error) {
+			var clock clock.Clock
+			if err := context.Get(config.ClockName, &clock); err != nil {
+				return nil, errors.Trace(err)
+			}
+			var apiCaller base.APICaller
+			if err := context.Get(config.APICallerName, &apiCaller); err != nil {
+				return nil, errors.Trace(err)
+			}
+			facade, err := config.NewFacade(apiCaller)
+			if err != nil {
+				return nil, errors.Annotate(err, "cannot create facade")
+			}
+			w, err := config.NewWorker(Config{
+				Facade:      facade,
+				Clock:       clock,
+				Period:      config.Period,
+				GracePeriod: config.GracePeriod,
+			})
+			if err != nil {
+				return nil, errors.Annotate(err, "cannot create worker")
+			}
+			return w, nil
+		},
+	}
+}
+
+// NewAPIFacade returns a Facade backed by the supplied APICaller.
+func NewAPIFacade(apiCaller base.APICaller) (Facade, error) {
+	return relationscopegc.NewClient(apiCaller), nil
+}
@@ -0,0 +1,162 @@
+// Copyright 2016 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package relationscopegc
+
+import (
+	"time"
+
+	"github.com/juju/errors"
+	"github.com/juju/loggo"
+	"github.com/juju/utils/clock"
+	"gopkg.in/tomb.v1"
+
+	"github.com/juju/juju/worker"
+)
+
+var logger = loggo.GetLogger("juju.worker.relationscopegc")
+
+// Facade exposes the controller capabilities required by the worker.
+type Facade interface {
+
+	// StaleRelationScopes returns the keys of the relation scope
+	// memberships whose units, or the units' machines, are gone.
+	StaleRelationScopes() ([]string, error)
+
+	// RemoveStaleRelationScopes removes the relation scope
+	// memberships with the given keys, returning an error for each.
+	RemoveStaleRelationScopes(keys []string) ([]error, error)
+}
+
+// Config defines the operation of a relation scope garbage collector.
+type Config struct {
+
+	// Facade is the worker's view of the controller.
+	Facade Facade
+
+	// Clock is the worker's view of time.
+	Clock clock.Clock
+
+	// Period is the time between checks for stale relation scopes.
+	Period time.Duration
+
+	// GracePeriod is the time for which a relation scope membership
+	// must have been continuously reported as stale before it is
+	// removed, so that units and machines in the process of being
+	// removed may leave their scopes themselves.
+	GracePeriod time.Duration
+}
+
+// Validate returns an error if the configuration cannot be expected
+// to start a functional worker.
+func (config Config) Validate() error {
+	if config.Facade == nil {
+		return errors.NotValidf("nil Facade")
+	}
+	if config.Clock == nil {
+		return errors.NotValidf("nil Clock")
+	}
+	if config.Period <= 0 {
+		return errors.NotValidf("non-positive Period")
+	}
+	if config.GracePeriod < 0 {
+		return errors.NotValidf("negative GracePeriod")
+	}
+	return nil
+}
+
+// NewWorker returns a worker that checks for stale relation scope
+// memberships once when started and subsequently every Period, and
+// removes those that have been stale for at least GracePeriod.
+func NewWorker(config Config) (worker.Worker, error) {
+	if err := config.Validate(); err != nil {
+		return nil, errors.Trace(err)
+	}
+	w := &gcWorker{
+		config:    config,
+		firstSeen: make(map[string]time.Time),
+	}
+	go func() {
+		defer w.tomb.Done()
+		w.tomb.Kill(w.loop())
+	}()
+	return w, nil
+}
+
+type gcWorker struct {
+	tomb   tomb.Tomb
+	config Config
+
+	// firstSeen records the time at which each stale relation scope
+	// membership was first reported by the facade.
+	firstSeen map[string]time.Time
+}
+
+func (w *gcWorker) loop() error {
+	var delay time.Duration
+	for {
+		select {
+		case <-w.tomb.Dying():
+			return tomb.ErrDying
+		case <-w.config.Clock.After(delay):
+			if err := w.collect(); err != nil {
+				return errors.Trace(err)
+			}
+		}
+		delay = w.config.Period
+	}
+}
+
+// collect removes the relation scope memberships that have been stale
+// for at least the grace period.
+func (w *gcWorker) collect() error {
+	keys, err := w.config.Facade.StaleRelationScopes()
+	if err != nil {
+		return errors.Annotate(err, "getting stale relation scopes")
+	}
+	now := w.config.Clock.Now()
+	stale := make(map[string]time.Time)
+	var expired []string
+	for _, key := range keys {
+		firstSeen, ok := w.firstSeen[key]
+		if !ok {
+			logger.Debugf("relation scope %q is stale", key)
+			firstSeen = now
+		}
+		stale[key] = firstSeen
+		if now.Sub(firstSeen) >= w.config.GracePeriod {
+			expired = append(expired, key)
+		}
+	}
+	// Forget memberships that are no longer stale, so that they
+	// get a full grace period if they become stale again.
+	w.firstSeen = stale
+	if len(expired) == 0 {
+		return nil
+	}
+
+	errs, err := w.config.Facade.RemoveStaleRelationScopes(expired)
+	if err != nil {
+		return errors.Annotate(err, "removing stale relation scopes")
+	}
+	for i, err := range errs {
+		key := expired[i]
+		if err != nil {
+			logger.Warningf("cannot remove stale relation scope %q: %v", key, err)
+			continue
+		}
+		logger.Infof("removed stale relation scope %q", key)
+		delete(w.firstSeen, key)
+	}
+	return nil
+}
+
+// Kill is part of the worker.Worker interface.
+func (w *gcWorker) Kill() {
+	w.tomb.Kill(nil)
+}
+
+// Wait is part of the worker.Worker interface.
+func (w *gcWorker) Wait() error {
+	return w.tomb.Wait()
+}
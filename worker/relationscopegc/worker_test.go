@@ -0,0 +1,183 @@
+// Copyright 2016 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package relationscopegc_test
+
+import (
+	"time"
+
+	"github.com/juju/errors"
+	"github.com/juju/testing"
+	jc "github.com/juju/testing/checkers"
+	"github.com/juju/utils/clock"
+	gc "gopkg.in/check.v1"
+
+	coretesting "github.com/juju/juju/testing"
+	"github.com/juju/juju/worker"
+	"github.com/juju/juju/worker/relationscopegc"
+)
+
+type WorkerSuite struct {
+	testing.IsolationSuite
+	facade *mockFacade
+	clock  *testing.Clock
+}
+
+var _ = gc.Suite(&WorkerSuite{})
+
+func (s *WorkerSuite) SetUpTest(c *gc.C) {
+	s.IsolationSuite.SetUpTest(c)
+	s.facade = &mockFacade{calls: make(chan struct{}, 10)}
+	s.clock = testing.NewClock(coretesting.ZeroTime())
+}
+
+func (s *WorkerSuite) startWorker(c *gc.C) worker.Worker {
+	w, err := relationscopegc.NewWorker(relationscopegc.Config{
+		Facade:      s.facade,
+		Clock:       s.clock,
+		Period:      time.Hour,
+		GracePeriod: 90 * time.Minute,
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	return w
+}
+
+func (s *WorkerSuite) waitCall(c *gc.C) {
+	select {
+	case <-s.facade.calls:
+	case <-time.After(coretesting.LongWait):
+		c.Fatalf("timed out waiting for call")
+	}
+}
+
+func (s *WorkerSuite) advance(c *gc.C, d time.Duration) {
+	if err := s.clock.WaitAdvance(d, coretesting.LongWait, 1); err != nil {
+		c.Fatal(err)
+	}
+}
+
+func (s *WorkerSuite) TestRemovesAfterGracePeriod(c *gc.C) {
+	s.facade.stale = [][]string{
+		{"r#0#peer#riak/0"},
+		{"r#0#peer#riak/0", "r#1#peer#riak/1"},
+		{"r#0#peer#riak/0", "r#1#peer#riak/1"},
+		{"r#1#peer#riak/1"},
+	}
+	w := s.startWorker(c)
+	defer worker.Stop(w)
+
+	// riak/0 is first reported at 0h, and removed at 2h;
+	// riak/1 is first reported at 1h, and removed at 3h.
+	s.waitCall(c)
+	s.advance(c, time.Hour)
+	s.waitCall(c)
+	s.advance(c, time.Hour)
+	s.waitCall(c)
+	s.advance(c, time.Hour)
+	s.waitCall(c)
+
+	c.Assert(worker.Stop(w), jc.ErrorIsNil)
+	s.facade.stub.CheckCalls(c, []testing.StubCall{
+		{"StaleRelationScopes", nil},
+		{"StaleRelationScopes", nil},
+		{"StaleRelationScopes", nil},
+		{"RemoveStaleRelationScopes", []interface{}{[]string{"r#0#peer#riak/0"}}},
+		{"StaleRelationScopes", nil},
+		{"RemoveStaleRelationScopes", []interface{}{[]string{"r#1#peer#riak/1"}}},
+	})
+}
+
+func (s *WorkerSuite) TestGracePeriodRestartsWhenNoLongerStale(c *gc.C) {
+	s.facade.stale = [][]string{
+		{"r#0#peer#riak/0"},
+		nil,
+		{"r#0#peer#riak/0"},
+	}
+	w := s.startWorker(c)
+	defer worker.Stop(w)
+
+	s.waitCall(c)
+	s.advance(c, time.Hour)
+	s.waitCall(c)
+	s.advance(c, time.Hour)
+	s.waitCall(c)
+
+	c.Assert(worker.Stop(w), jc.ErrorIsNil)
+	s.facade.stub.CheckCallNames(c,
+		"StaleRelationScopes",
+		"StaleRelationScopes",
+		"StaleRelationScopes",
+	)
+}
+
+func (s *WorkerSuite) TestStaleRelationScopesError(c *gc.C) {
+	s.facade.stub.SetErrors(errors.New("boom"))
+	w := s.startWorker(c)
+	defer worker.Stop(w)
+
+	s.waitCall(c)
+	err := w.Wait()
+	c.Assert(err, gc.ErrorMatches, "getting stale relation scopes: boom")
+}
+
+func (s *WorkerSuite) TestValidate(c *gc.C) {
+	valid := relationscopegc.Config{
+		Facade:      struct{ relationscopegc.Facade }{},
+		Clock:       struct{ clock.Clock }{},
+		Period:      time.Hour,
+		GracePeriod: 0,
+	}
+	c.Assert(valid.Validate(), jc.ErrorIsNil)
+
+	for i, test := range []struct {
+		mutate func(*relationscopegc.Config)
+		expect string
+	}{{
+		func(config *relationscopegc.Config) { config.Facade = nil },
+		"nil Facade not valid",
+	}, {
+		func(config *relationscopegc.Config) { config.Clock = nil },
+		"nil Clock not valid",
+	}, {
+		func(config *relationscopegc.Config) { config.Period = 0 },
+		"non-positive Period not valid",
+	}, {
+		func(config *relationscopegc.Config) { config.GracePeriod = -1 },
+		"negative GracePeriod not valid",
+	}} {
+		c.Logf("test %d", i)
+		config := valid
+		test.mutate(&config)
+		err := config.Validate()
+		c.Check(err, jc.Satisfies, errors.IsNotValid)
+		c.Check(err, gc.ErrorMatches, test.expect)
+
+		w, err := relationscopegc.NewWorker(config)
+		c.Check(w, gc.IsNil)
+		c.Check(err, gc.ErrorMatches, test.expect)
+	}
+}
+
+type mockFacade struct {
+	stub  testing.Stub
+	calls chan struct{}
+	stale [][]string
+}
+
+func (f *mockFacade) StaleRelationScopes() ([]string, error) {
+	f.stub.AddCall("StaleRelationScopes")
+	defer func() { f.calls <- struct{}{} }()
+	if err := f.stub.NextErr(); err != nil {
+		return nil, err
+	}
+	var stale []string
+	if len(f.stale) > 0 {
+		stale, f.stale = f.stale[0], f.stale[1:]
+	}
+	return stale, nil
+}
+
+func (f *mockFacade) RemoveStaleRelationScopes(keys []string) ([]error, error) {
+	f.stub.AddCall("RemoveStaleRelationScopes", keys)
+	return make([]error, len(keys)), f.stub.NextErr()
+}
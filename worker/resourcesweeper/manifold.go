@@ -0,0 +1,66 @@
+// Copyright 2016 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package resourcesweeper
+
+import (
+	"time"
+
+	"github.com/juju/errors"
+	"github.com/juju/utils/clock"
+
+	"github.com/juju/juju/api/base"
+	"github.com/juju/juju/api/resourcesweeper"
+	"github.com/juju/juju/worker"
+	"github.com/juju/juju/worker/dependency"
+)
+
+// ManifoldConfig describes how to create a worker that deletes the
+// cloud resources left behind by a model's removed machines.
+type ManifoldConfig struct {
+	APICallerName string
+	ClockName     string
+
+	Period    time.Duration
+	NewFacade func(base.APICaller) (Facade, error)
+	NewWorker func(Config) (worker.Worker, error)
+}
+
+// Manifold returns a dependency.Manifold that runs a machine resource
+// sweeper according to the supplied configuration.
+func Manifold(config ManifoldConfig) dependency.Manifold {
+	return dependency.Manifold{
+		Inputs: []string{
+			config.APICallerName,
+			config.ClockName,
+		},
+		Start: func(context dependency.Context) (worker.Worker, error) {
+			var clock clock.Clock
+			if err := context.Get(config.ClockName, &clock); err != nil {
+				return nil, errors.Trace(err)
+			}
+			var apiCaller base.APICaller
+			if err := context.Get(config.APICallerName, &apiCaller); err != nil {
+				return nil, errors.Trace(err)
+			}
+			facade, err := config.NewFacade(apiCaller)
+			if err != nil {
+				return nil, errors.Annotate(err, "cannot create facade")
+			}
+			w, err := config.NewWorker(Config{
+				Facade: facade,
+				Clock:  clock,
+				Period: config.Period,
+			})
+			if err != nil {
+				return nil, errors.Annotate(err, "cannot create worker")
+			}
+			return w, nil
+		},
+	}
+}
+
+// NewAPIFacade returns a Facade backed by the supplied APICaller.
+func NewAPIFacade(apiCaller base.APICaller) (Facade, error) {
+	return resourcesweeper.NewClient(apiCaller), nil
+}
@@ -0,0 +1,111 @@
+// Copyright 2016 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package resourcesweeper
+
+import (
+	"time"
+
+	"github.com/juju/errors"
+	"github.com/juju/loggo"
+	"github.com/juju/utils/clock"
+	"gopkg.in/tomb.v1"
+
+	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/worker"
+)
+
+var logger = loggo.GetLogger("juju.worker.resourcesweeper")
+
+// Facade exposes the controller capabilities required by the worker.
+type Facade interface {
+
+	// SweepMachineResources deletes the unused cloud resources that
+	// the provider created for machines that are no longer in the
+	// model, returning those deleted.
+	SweepMachineResources() ([]params.ProviderResource, error)
+}
+
+// Config defines the operation of a machine resource sweeper.
+type Config struct {
+
+	// Facade is the worker's view of the controller.
+	Facade Facade
+
+	// Clock is the worker's view of time.
+	Clock clock.Clock
+
+	// Period is the time between sweeps.
+	Period time.Duration
+}
+
+// Validate returns an error if the configuration cannot be expected
+// to start a functional worker.
+func (config Config) Validate() error {
+	if config.Facade == nil {
+		return errors.NotValidf("nil Facade")
+	}
+	if config.Clock == nil {
+		return errors.NotValidf("nil Clock")
+	}
+	if config.Period <= 0 {
+		return errors.NotValidf("non-positive Period")
+	}
+	return nil
+}
+
+// NewWorker returns a worker that calls SweepMachineResources on the
+// configured Facade, once when started and subsequently every Period.
+// If the model's provider does not support sweeping, the worker does
+// nothing until it is killed.
+func NewWorker(config Config) (worker.Worker, error) {
+	if err := config.Validate(); err != nil {
+		return nil, errors.Trace(err)
+	}
+	w := &sweepWorker{
+		config: config,
+	}
+	go func() {
+		defer w.tomb.Done()
+		w.tomb.Kill(w.loop())
+	}()
+	return w, nil
+}
+
+type sweepWorker struct {
+	tomb   tomb.Tomb
+	config Config
+}
+
+func (w *sweepWorker) loop() error {
+	var delay time.Duration
+	for {
+		select {
+		case <-w.tomb.Dying():
+			return tomb.ErrDying
+		case <-w.config.Clock.After(delay):
+			deleted, err := w.config.Facade.SweepMachineResources()
+			if params.IsCodeNotSupported(err) {
+				logger.Debugf("provider does not support sweeping machine resources")
+				<-w.tomb.Dying()
+				return tomb.ErrDying
+			} else if err != nil {
+				return errors.Annotate(err, "sweeping machine resources")
+			}
+			for _, resource := range deleted {
+				logger.Infof("deleted orphaned %s %s", resource.Type, resource.Id)
+			}
+		}
+		delay = w.config.Period
+	}
+}
+
+// Kill is part of the worker.Worker interface.
+func (w *sweepWorker) Kill() {
+	w.tomb.Kill(nil)
+}
+
+// Wait is part of the worker.Worker interface.
+func (w *sweepWorker) Wait() error {
+	return w.tomb.Wait()
+}
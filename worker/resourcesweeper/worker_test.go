@@ -0,0 +1,150 @@
+// Copyright 2016 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package resourcesweeper_test
+
+import (
+	"time"
+
+	"github.com/juju/errors"
+	"github.com/juju/testing"
+	jc "github.com/juju/testing/checkers"
+	"github.com/juju/utils/clock"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/apiserver/params"
+	coretesting "github.com/juju/juju/testing"
+	"github.com/juju/juju/worker"
+	"github.com/juju/juju/worker/resourcesweeper"
+)
+
+type WorkerSuite struct {
+	testing.IsolationSuite
+	facade *mockFacade
+	clock  *testing.Clock
+}
+
+var _ = gc.Suite(&WorkerSuite{})
+
+func (s *WorkerSuite) SetUpTest(c *gc.C) {
+	s.IsolationSuite.SetUpTest(c)
+	s.facade = &mockFacade{calls: make(chan struct{}, 10)}
+	s.clock = testing.NewClock(coretesting.ZeroTime())
+}
+
+func (s *WorkerSuite) startWorker(c *gc.C) worker.Worker {
+	w, err := resourcesweeper.NewWorker(resourcesweeper.Config{
+		Facade: s.facade,
+		Clock:  s.clock,
+		Period: time.Hour,
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	return w
+}
+
+func (s *WorkerSuite) waitCall(c *gc.C) {
+	select {
+	case <-s.facade.calls:
+	case <-time.After(coretesting.LongWait):
+		c.Fatalf("timed out waiting for call")
+	}
+}
+
+func (s *WorkerSuite) waitNoCall(c *gc.C) {
+	select {
+	case <-s.facade.calls:
+		c.Fatalf("unexpected call")
+	case <-time.After(coretesting.ShortWait):
+	}
+}
+
+func (s *WorkerSuite) TestSweepsImmediatelyAndPeriodically(c *gc.C) {
+	s.facade.result = []params.ProviderResource{{Type: "nic", Id: "machine-1-primary"}}
+	w := s.startWorker(c)
+	defer worker.Stop(w)
+
+	s.waitCall(c)
+	s.clock.Advance(time.Hour - time.Nanosecond)
+	s.waitNoCall(c)
+	if err := s.clock.WaitAdvance(time.Nanosecond, coretesting.LongWait, 1); err != nil {
+		c.Fatal(err)
+	}
+	s.waitCall(c)
+
+	c.Assert(worker.Stop(w), jc.ErrorIsNil)
+	s.facade.stub.CheckCallNames(c, "SweepMachineResources", "SweepMachineResources")
+}
+
+func (s *WorkerSuite) TestSweepError(c *gc.C) {
+	s.facade.stub.SetErrors(errors.New("boom"))
+	w := s.startWorker(c)
+	defer worker.Stop(w)
+
+	s.waitCall(c)
+	err := w.Wait()
+	c.Assert(err, gc.ErrorMatches, "sweeping machine resources: boom")
+}
+
+func (s *WorkerSuite) TestSweepNotSupported(c *gc.C) {
+	s.facade.stub.SetErrors(&params.Error{
+		Code:    params.CodeNotSupported,
+		Message: "sweeping machine resources not supported",
+	})
+	w := s.startWorker(c)
+
+	s.waitCall(c)
+	s.clock.Advance(time.Hour)
+	s.waitNoCall(c)
+
+	c.Assert(worker.Stop(w), jc.ErrorIsNil)
+	s.facade.stub.CheckCallNames(c, "SweepMachineResources")
+}
+
+func (s *WorkerSuite) TestValidate(c *gc.C) {
+	valid := resourcesweeper.Config{
+		Facade: struct{ resourcesweeper.Facade }{},
+		Clock:  struct{ clock.Clock }{},
+		Period: time.Hour,
+	}
+	c.Assert(valid.Validate(), jc.ErrorIsNil)
+
+	for i, test := range []struct {
+		mutate func(*resourcesweeper.Config)
+		expect string
+	}{{
+		func(config *resourcesweeper.Config) { config.Facade = nil },
+		"nil Facade not valid",
+	}, {
+		func(config *resourcesweeper.Config) { config.Clock = nil },
+		"nil Clock not valid",
+	}, {
+		func(config *resourcesweeper.Config) { config.Period = 0 },
+		"non-positive Period not valid",
+	}} {
+		c.Logf("test %d", i)
+		config := valid
+		test.mutate(&config)
+		err := config.Validate()
+		c.Check(err, jc.Satisfies, errors.IsNotValid)
+		c.Check(err, gc.ErrorMatches, test.expect)
+
+		w, err := resourcesweeper.NewWorker(config)
+		c.Check(w, gc.IsNil)
+		c.Check(err, gc.ErrorMatches, test.expect)
+	}
+}
+
+type mockFacade struct {
+	stub   testing.Stub
+	calls  chan struct{}
+	result []params.ProviderResource
+}
+
+func (f *mockFacade) SweepMachineResources() ([]params.ProviderResource, error) {
+	f.stub.AddCall("SweepMachineResources")
+	f.calls <- struct{}{}
+	if err := f.stub.NextErr(); err != nil {
+		return nil, err
+	}
+	return f.result, nil
+}
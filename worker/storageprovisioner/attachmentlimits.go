@@ -0,0 +1,112 @@
+// Copyright 2016 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package storageprovisioner
+
+import (
+	"sort"
+
+	"github.com/juju/errors"
+	"github.com/juju/utils/set"
+	"gopkg.in/juju/names.v2"
+
+	"github.com/juju/juju/environs"
+	"github.com/juju/juju/instance"
+	"github.com/juju/juju/storage"
+)
+
+// checkVolumeAttachmentLimits checks the volume attachments with the
+// given parameters, all of which are for the given volume source,
+// against the attachment limits of their instances, if the source
+// implements storage.VolumeAttachmentLimiter. The result contains an
+// error for each attachment that would exceed its instance's limit,
+// and nil for each attachment that may be attempted.
+//
+// Where there is room on an instance for only some of its pending
+// attachments, they are admitted in order of volume tag, so that the
+// same attachments are admitted each time the operations are retried.
+// Attachments that already exist do not count against the limits, as
+// attaching them again has no effect.
+//
+// If the limits cannot be determined, all of the attachments are
+// attempted and left to the source to reject.
+func checkVolumeAttachmentLimits(
+	source storage.VolumeSource,
+	attachParams []storage.VolumeAttachmentParams,
+) []error {
+	errs := make([]error, len(attachParams))
+	limiter, ok := source.(storage.VolumeAttachmentLimiter)
+	if !ok {
+		return errs
+	}
+
+	var instanceIds []instance.Id
+	instanceIndex := make(map[instance.Id]int)
+	for _, p := range attachParams {
+		if _, ok := instanceIndex[p.InstanceId]; ok {
+			continue
+		}
+		instanceIndex[p.InstanceId] = len(instanceIds)
+		instanceIds = append(instanceIds, p.InstanceId)
+	}
+	limits, err := limiter.VolumeAttachmentLimits(instanceIds)
+	if err != nil {
+		logger.Warningf("cannot get volume attachment limits: %v", err)
+		return errs
+	}
+	attached := make([]set.Strings, len(limits))
+	for i, limit := range limits {
+		if limit.Error != nil {
+			logger.Debugf(
+				"cannot get volume attachment limits for %s: %v",
+				instanceIds[i], limit.Error,
+			)
+			continue
+		}
+		attached[i] = set.NewStrings(limit.Attached...)
+	}
+
+	order := make([]int, len(attachParams))
+	for i := range order {
+		order[i] = i
+	}
+	sort.Sort(byVolumeTag{attachParams, order})
+	for _, i := range order {
+		p := attachParams[i]
+		index := instanceIndex[p.InstanceId]
+		if attached[index] == nil || attached[index].Contains(p.VolumeId) {
+			continue
+		}
+		max := limits[index].Max
+		if attached[index].Size() >= max {
+			errs[i] = environs.NewProvisioningError(errors.Errorf(
+				"cannot attach %s to %s: instance %s has %d of a maximum %d volumes attached",
+				names.ReadableString(p.Volume),
+				names.ReadableString(p.Machine),
+				p.InstanceId, attached[index].Size(), max,
+			), environs.ProvisioningErrorQuota)
+			continue
+		}
+		attached[index].Add(p.VolumeId)
+	}
+	return errs
+}
+
+// byVolumeTag sorts indices into a slice of volume
+// attachment parameters by the parameters' volume tags.
+type byVolumeTag struct {
+	params []storage.VolumeAttachmentParams
+	order  []int
+}
+
+func (b byVolumeTag) Len() int {
+	return len(b.order)
+}
+
+func (b byVolumeTag) Less(i, j int) bool {
+	return b.params[b.order[i]].Volume.String() < b.params[b.order[j]].Volume.String()
+}
+
+func (b byVolumeTag) Swap(i, j int) {
+	b.order[i], b.order[j] = b.order[j], b.order[i]
+}
@@ -13,12 +13,11 @@ import (
 
 // machineBlockDevicesChanged is called when the block devices of the scoped
 // machine have been seen to have changed. This triggers a refresh of all
-// block devices for attached volumes backing pending filesystems.
+// block devices for attached volumes backing pending filesystems, and for
+// volumes backing provisioned filesystems so that the filesystems can be
+// grown when the volumes grow.
 func machineBlockDevicesChanged(ctx *context) error {
-	if len(ctx.incompleteFilesystemParams) == 0 {
-		return nil
-	}
-	volumeTags := make([]names.VolumeTag, 0, len(ctx.incompleteFilesystemParams))
+	volumeTags := make(set.Tags)
 	// We only need to query volumes for incomplete filesystems,
 	// and not incomplete filesystem attachments, because a
 	// filesystem attachment cannot exist without a filesystem.
@@ -35,12 +34,25 @@ func machineBlockDevicesChanged(ctx *context) error {
 			// Backing-volume's block device is already attached.
 			continue
 		}
-		volumeTags = append(volumeTags, params.Volume)
+		volumeTags.Add(params.Volume)
+	}
+	// Provisioned filesystems' backing volumes may have grown,
+	// in which case we must grow the filesystems.
+	for _, filesystem := range ctx.filesystems {
+		if filesystem.Volume == (names.VolumeTag{}) {
+			// Filesystem is not volume-backed.
+			continue
+		}
+		volumeTags.Add(filesystem.Volume)
 	}
 	if len(volumeTags) == 0 {
 		return nil
 	}
-	return refreshVolumeBlockDevices(ctx, volumeTags)
+	sortedVolumeTags := make([]names.VolumeTag, len(volumeTags))
+	for i, tag := range volumeTags.SortedValues() {
+		sortedVolumeTags[i] = tag.(names.VolumeTag)
+	}
+	return refreshVolumeBlockDevices(ctx, sortedVolumeTags)
 }
 
 // processPendingVolumeBlockDevices is called before waiting for any events,
@@ -80,9 +92,15 @@ func refreshVolumeBlockDevices(ctx *context, volumeTags []names.VolumeTag) error
 	if err != nil {
 		return errors.Annotate(err, "refreshing volume block devices")
 	}
+	grownFilesystems := make(set.Tags)
 	for i, result := range results {
 		if result.Error == nil {
 			ctx.volumeBlockDevices[volumeTags[i]] = result.Result
+			for _, filesystem := range ctx.filesystems {
+				if filesystem.Volume == volumeTags[i] && filesystem.Size < result.Result.Size {
+					grownFilesystems.Add(filesystem.Tag)
+				}
+			}
 			for _, params := range ctx.incompleteFilesystemParams {
 				if params.Volume == volumeTags[i] {
 					updatePendingFilesystem(ctx, params)
@@ -110,5 +128,12 @@ func refreshVolumeBlockDevices(ctx *context, volumeTags []names.VolumeTag) error
 			)
 		}
 	}
+	if len(grownFilesystems) > 0 {
+		filesystemTags := make([]names.FilesystemTag, len(grownFilesystems))
+		for i, tag := range grownFilesystems.SortedValues() {
+			filesystemTags[i] = tag.(names.FilesystemTag)
+		}
+		return resizeFilesystems(ctx, filesystemTags)
+	}
 	return nil
 }
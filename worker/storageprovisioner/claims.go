@@ -0,0 +1,146 @@
+// Copyright 2016 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package storageprovisioner
+
+import (
+	"github.com/juju/errors"
+	"gopkg.in/juju/names.v2"
+)
+
+// claimStorageEntities claims, on behalf of the worker's scope, the
+// volumes and filesystems that the given operations create or destroy,
+// so that the storage provisioners of other scopes do not act on them
+// at the same time. Creating or destroying a volume-backed filesystem
+// also claims the backing volume, which the environ-scoped storage
+// provisioner may otherwise be creating or destroying.
+//
+// Operations any of whose entities could not be claimed are removed
+// from the maps and rescheduled. The returned function releases the
+// claims, and must be called once the remaining operations have been
+// executed.
+func claimStorageEntities(
+	ctx *context,
+	createVolumeOps map[names.VolumeTag]*createVolumeOp,
+	destroyVolumeOps map[names.VolumeTag]*destroyVolumeOp,
+	createFilesystemOps map[names.FilesystemTag]*createFilesystemOp,
+	destroyFilesystemOps map[names.FilesystemTag]*destroyFilesystemOp,
+) (release func(), _ error) {
+	entities := make(map[scheduleOp][]names.Tag)
+	for tag, op := range createVolumeOps {
+		entities[op] = []names.Tag{tag}
+	}
+	for tag, op := range destroyVolumeOps {
+		entities[op] = []names.Tag{tag}
+	}
+	for tag, op := range createFilesystemOps {
+		entities[op] = filesystemClaimEntities(tag, op.args.Volume)
+	}
+	for tag, op := range destroyFilesystemOps {
+		entities[op] = filesystemClaimEntities(tag, ctx.filesystems[tag].Volume)
+	}
+	release = func() {}
+	if len(entities) == 0 {
+		return release, nil
+	}
+
+	var tags []names.Tag
+	seen := make(map[names.Tag]bool)
+	for _, opEntities := range entities {
+		for _, tag := range opEntities {
+			if !seen[tag] {
+				seen[tag] = true
+				tags = append(tags, tag)
+			}
+		}
+	}
+	results, err := ctx.config.Claims.ClaimStorageEntities(tags)
+	if err != nil {
+		return nil, errors.Annotate(err, "claiming storage entities")
+	}
+	var claimed []names.Tag
+	claimErrors := make(map[names.Tag]error)
+	for i, result := range results {
+		if result.Error != nil {
+			claimErrors[tags[i]] = result.Error
+			continue
+		}
+		claimed = append(claimed, tags[i])
+	}
+	if len(claimed) > 0 {
+		release = func() {
+			releaseStorageEntities(ctx, claimed)
+		}
+	}
+	if len(claimErrors) == 0 {
+		return release, nil
+	}
+
+	// Reschedule the operations whose entities could not be
+	// claimed; they are most likely being acted upon by the
+	// storage provisioner of another scope.
+	contended := make(map[scheduleOp]bool)
+	var reschedule []scheduleOp
+	for op, opEntities := range entities {
+		for _, tag := range opEntities {
+			err, ok := claimErrors[tag]
+			if !ok {
+				continue
+			}
+			logger.Debugf("failed to claim %s: %v", names.ReadableString(tag), err)
+			contended[op] = true
+			op.failed(err)
+			reschedule = append(reschedule, op)
+			break
+		}
+	}
+	scheduleOperations(ctx, reschedule...)
+	for tag, op := range createVolumeOps {
+		if contended[op] {
+			delete(createVolumeOps, tag)
+		}
+	}
+	for tag, op := range destroyVolumeOps {
+		if contended[op] {
+			delete(destroyVolumeOps, tag)
+		}
+	}
+	for tag, op := range createFilesystemOps {
+		if contended[op] {
+			delete(createFilesystemOps, tag)
+		}
+	}
+	for tag, op := range destroyFilesystemOps {
+		if contended[op] {
+			delete(destroyFilesystemOps, tag)
+		}
+	}
+	return release, nil
+}
+
+// releaseStorageEntities releases the claims held by the worker's scope
+// on the specified storage entities. Failures are logged rather than
+// returned: claims are held by scopes rather than workers, so the claim
+// will be released after the next operation on the entity by this scope.
+func releaseStorageEntities(ctx *context, tags []names.Tag) {
+	results, err := ctx.config.Claims.ReleaseStorageEntities(tags)
+	if err != nil {
+		logger.Errorf("releasing storage entities: %v", err)
+		return
+	}
+	for i, result := range results {
+		if result.Error != nil {
+			logger.Errorf("releasing %s: %v", names.ReadableString(tags[i]), result.Error)
+		}
+	}
+}
+
+// filesystemClaimEntities returns the tags of the entities to claim
+// before creating or destroying the specified filesystem, which may be
+// backed by the specified volume.
+func filesystemClaimEntities(tag names.FilesystemTag, volume names.VolumeTag) []names.Tag {
+	if volume == (names.VolumeTag{}) {
+		return []names.Tag{tag}
+	}
+	return []names.Tag{tag, volume}
+}
@@ -202,3 +202,14 @@ func copyMachineStorageIds(src []watcher.MachineStorageId) []params.MachineStora
 	}
 	return dst
 }
+
+// bulkErrors returns n copies of the error with which a bulk provider
+// call failed as a whole, so that the failure can be handled as the
+// failure of each item in the call.
+func bulkErrors(err error, n int) []error {
+	errs := make([]error, n)
+	for i := range errs {
+		errs[i] = err
+	}
+	return errs
+}
@@ -20,7 +20,18 @@ type Config struct {
 	Registry    storage.ProviderRegistry
 	Machines    MachineAccessor
 	Status      StatusSetter
+	Claims      StorageClaimer
 	Clock       clock.Clock
+
+	// RetryJitter is the proportion by which the delays before
+	// retrying failed operations are randomly varied, so that
+	// retries of operations that failed together are spread
+	// out. It must be at least 0, and less than 1.
+	RetryJitter float64
+
+	// MetricsRegisterer, if non-nil, is used to register the
+	// worker's Prometheus metrics collector.
+	MetricsRegisterer MetricsRegisterer
 }
 
 // Validate returns an error if the config cannot be relied upon to start a worker.
@@ -57,8 +68,14 @@ func (config Config) Validate() error {
 	if config.Status == nil {
 		return errors.NotValidf("nil Status")
 	}
+	if config.Claims == nil {
+		return errors.NotValidf("nil Claims")
+	}
 	if config.Clock == nil {
 		return errors.NotValidf("nil Clock")
 	}
+	if config.RetryJitter < 0 || config.RetryJitter >= 1 {
+		return errors.NotValidf("RetryJitter %v", config.RetryJitter)
+	}
 	return nil
 }
@@ -82,11 +82,26 @@ func (s *ConfigSuite) TestNilStatus(c *gc.C) {
 	s.checkNotValid(c, "nil Status not valid")
 }
 
+func (s *ConfigSuite) TestNilClaims(c *gc.C) {
+	s.config.Claims = nil
+	s.checkNotValid(c, "nil Claims not valid")
+}
+
 func (s *ConfigSuite) TestNilClock(c *gc.C) {
 	s.config.Clock = nil
 	s.checkNotValid(c, "nil Clock not valid")
 }
 
+func (s *ConfigSuite) TestNegativeRetryJitter(c *gc.C) {
+	s.config.RetryJitter = -0.1
+	s.checkNotValid(c, "RetryJitter -0.1 not valid")
+}
+
+func (s *ConfigSuite) TestExcessiveRetryJitter(c *gc.C) {
+	s.config.RetryJitter = 1
+	s.checkNotValid(c, "RetryJitter 1 not valid")
+}
+
 func (s *ConfigSuite) checkNotValid(c *gc.C, match string) {
 	err := s.config.Validate()
 	c.Check(err, jc.Satisfies, errors.IsNotValid)
@@ -128,6 +143,9 @@ func almostValidConfig() storageprovisioner.Config {
 		Status: struct {
 			storageprovisioner.StatusSetter
 		}{},
+		Claims: struct {
+			storageprovisioner.StorageClaimer
+		}{},
 		Clock: struct {
 			clock.Clock
 		}{},
@@ -56,9 +56,25 @@ func createFilesystems(ctx *context, ops map[names.FilesystemTag]*createFilesyst
 		if len(filesystemParams) == 0 {
 			continue
 		}
+		start := ctx.config.Clock.Now()
 		results, err := filesystemSource.CreateFilesystems(filesystemParams)
+		resultErrs := make([]error, len(results))
+		for i, result := range results {
+			resultErrs[i] = result.Error
+		}
+		ctx.metrics.observeOperation(
+			operationCreateFilesystem, filesystemParams[0].Provider, ctx.config.Clock.Now().Sub(start),
+			len(filesystemParams), err, resultErrs,
+		)
 		if err != nil {
-			return errors.Annotatef(err, "creating filesystems from source %q", sourceName)
+			// Treat the failure of the call as a whole as the
+			// failure of each filesystem, so that each is retried
+			// according to the class of error.
+			err = errors.Annotatef(err, "creating filesystems from source %q", sourceName)
+			results = make([]storage.CreateFilesystemsResult, len(filesystemParams))
+			for i := range results {
+				results[i].Error = err
+			}
 		}
 		for i, result := range results {
 			statuses = append(statuses, params.EntityStatusArgs{
@@ -67,15 +83,16 @@ func createFilesystems(ctx *context, ops map[names.FilesystemTag]*createFilesyst
 			})
 			entityStatus := &statuses[len(statuses)-1]
 			if result.Error != nil {
-				// Reschedule the filesystem creation.
-				reschedule = append(reschedule, ops[filesystemParams[i].Tag])
-
-				// Note: we keep the status as "pending" to indicate
-				// that we will retry. When we distinguish between
-				// transient and permanent errors, we will set the
-				// status to "error" for permanent errors.
+				// Reschedule the filesystem creation, keeping the
+				// status as "pending" to indicate that we will
+				// retry, or "error" if the error is permanent.
 				entityStatus.Status = status.Pending.String()
 				entityStatus.Info = result.Error.Error()
+				op := ops[filesystemParams[i].Tag]
+				reschedule = append(reschedule, op)
+				if !op.failed(result.Error) {
+					entityStatus.Status = status.Error.String()
+				}
 				logger.Debugf(
 					"failed to create %s: %v",
 					names.ReadableString(filesystemParams[i].Tag),
@@ -114,6 +131,62 @@ func createFilesystems(ctx *context, ops map[names.FilesystemTag]*createFilesyst
 	return nil
 }
 
+// resizeFilesystems grows the specified volume-backed filesystems to fill
+// their backing volumes, and records the filesystems' new sizes.
+func resizeFilesystems(ctx *context, tags []names.FilesystemTag) error {
+	resizer, ok := ctx.managedFilesystemSource.(storage.FilesystemResizer)
+	if !ok {
+		logger.Debugf("managed filesystem source cannot resize filesystems")
+		return nil
+	}
+	args := make([]storage.ResizeFilesystemParams, len(tags))
+	for i, tag := range tags {
+		args[i].Filesystem = tag
+		id := params.MachineStorageId{
+			MachineTag:    ctx.config.Scope.String(),
+			AttachmentTag: tag.String(),
+		}
+		if attachment, ok := ctx.filesystemAttachments[id]; ok {
+			args[i].Path = attachment.Path
+		}
+	}
+	logger.Debugf("resizing filesystems: %v", args)
+	results, err := resizer.ResizeFilesystems(args)
+	if err != nil {
+		return errors.Annotate(err, "resizing filesystems")
+	}
+	var filesystems []storage.Filesystem
+	for i, result := range results {
+		if result.Error != nil {
+			// The filesystem will be resized when the
+			// machine's block devices next change.
+			logger.Errorf("failed to resize %s: %v", names.ReadableString(tags[i]), result.Error)
+			continue
+		}
+		filesystems = append(filesystems, *result.Filesystem)
+	}
+	if len(filesystems) == 0 {
+		return nil
+	}
+	errorResults, err := ctx.config.Filesystems.SetFilesystemInfo(filesystemsFromStorage(filesystems))
+	if err != nil {
+		return errors.Annotate(err, "publishing filesystems to state")
+	}
+	for i, result := range errorResults {
+		if result.Error != nil {
+			logger.Errorf(
+				"publishing filesystem %s to state: %v",
+				filesystems[i].Tag.Id(),
+				result.Error,
+			)
+		}
+	}
+	for _, f := range filesystems {
+		ctx.filesystems[f.Tag] = f
+	}
+	return nil
+}
+
 // attachFilesystems creates filesystem attachments with the specified parameters.
 func attachFilesystems(ctx *context, ops map[params.MachineStorageId]*attachFilesystemOp) error {
 	filesystemAttachmentParams := make([]storage.FilesystemAttachmentParams, 0, len(ops))
@@ -140,9 +213,22 @@ func attachFilesystems(ctx *context, ops map[params.MachineStorageId]*attachFile
 	for sourceName, filesystemAttachmentParams := range paramsBySource {
 		logger.Debugf("attaching filesystems: %+v", filesystemAttachmentParams)
 		filesystemSource := filesystemSources[sourceName]
+		start := ctx.config.Clock.Now()
 		results, err := filesystemSource.AttachFilesystems(filesystemAttachmentParams)
+		resultErrs := make([]error, len(results))
+		for i, result := range results {
+			resultErrs[i] = result.Error
+		}
+		ctx.metrics.observeOperation(
+			operationAttachFilesystem, filesystemAttachmentParams[0].Provider, ctx.config.Clock.Now().Sub(start),
+			len(filesystemAttachmentParams), err, resultErrs,
+		)
 		if err != nil {
-			return errors.Annotatef(err, "attaching filesystems from source %q", sourceName)
+			err = errors.Annotatef(err, "attaching filesystems from source %q", sourceName)
+			results = make([]storage.AttachFilesystemsResult, len(filesystemAttachmentParams))
+			for i := range results {
+				results[i].Error = err
+			}
 		}
 		for i, result := range results {
 			p := filesystemAttachmentParams[i]
@@ -157,14 +243,16 @@ func attachFilesystems(ctx *context, ops map[params.MachineStorageId]*attachFile
 					MachineTag:    p.Machine.String(),
 					AttachmentTag: p.Filesystem.String(),
 				}
-				reschedule = append(reschedule, ops[id])
-
-				// Note: we keep the status as "attaching" to
-				// indicate that we will retry. When we distinguish
-				// between transient and permanent errors, we will
-				// set the status to "error" for permanent errors.
+				// Keep the status as "attaching" to indicate
+				// that we will retry, or set it to "error" if
+				// the error is permanent.
 				entityStatus.Status = status.Attaching.String()
 				entityStatus.Info = result.Error.Error()
+				op := ops[id]
+				reschedule = append(reschedule, op)
+				if !op.failed(result.Error) {
+					entityStatus.Status = status.Error.String()
+				}
 				logger.Debugf(
 					"failed to attach %s to %s: %v",
 					names.ReadableString(p.Filesystem),
@@ -236,9 +324,14 @@ func destroyFilesystems(ctx *context, ops map[names.FilesystemTag]*destroyFilesy
 			}
 			filesystemIds[i] = filesystem.FilesystemId
 		}
+		start := ctx.config.Clock.Now()
 		errs, err := filesystemSource.DestroyFilesystems(filesystemIds)
+		ctx.metrics.observeOperation(
+			operationDestroyFilesystem, filesystemParams[0].Provider, ctx.config.Clock.Now().Sub(start),
+			len(filesystemIds), err, errs,
+		)
 		if err != nil {
-			return errors.Trace(err)
+			errs = bulkErrors(errors.Annotatef(err, "destroying filesystems from source %q", sourceName), len(filesystemIds))
 		}
 		for i, err := range errs {
 			tag := filesystemParams[i].Tag
@@ -246,13 +339,19 @@ func destroyFilesystems(ctx *context, ops map[names.FilesystemTag]*destroyFilesy
 				remove = append(remove, tag)
 				continue
 			}
-			// Failed to destroy filesystem; reschedule and update status.
-			reschedule = append(reschedule, ops[tag])
-			statuses = append(statuses, params.EntityStatusArgs{
+			// Failed to destroy filesystem; reschedule, and
+			// update status.
+			entityStatus := params.EntityStatusArgs{
 				Tag:    tag.String(),
 				Status: status.Destroying.String(),
 				Info:   err.Error(),
-			})
+			}
+			op := ops[tag]
+			reschedule = append(reschedule, op)
+			if !op.failed(err) {
+				entityStatus.Status = status.Error.String()
+			}
+			statuses = append(statuses, entityStatus)
 		}
 	}
 	scheduleOperations(ctx, reschedule...)
@@ -285,9 +384,14 @@ func detachFilesystems(ctx *context, ops map[params.MachineStorageId]*detachFile
 	for sourceName, filesystemAttachmentParams := range paramsBySource {
 		logger.Debugf("detaching filesystems: %+v", filesystemAttachmentParams)
 		filesystemSource := filesystemSources[sourceName]
+		start := ctx.config.Clock.Now()
 		errs, err := filesystemSource.DetachFilesystems(filesystemAttachmentParams)
+		ctx.metrics.observeOperation(
+			operationDetachFilesystem, filesystemAttachmentParams[0].Provider, ctx.config.Clock.Now().Sub(start),
+			len(filesystemAttachmentParams), err, errs,
+		)
 		if err != nil {
-			return errors.Annotatef(err, "detaching filesystems from source %q", sourceName)
+			errs = bulkErrors(errors.Annotatef(err, "detaching filesystems from source %q", sourceName), len(filesystemAttachmentParams))
 		}
 		for i, err := range errs {
 			p := filesystemAttachmentParams[i]
@@ -305,9 +409,13 @@ func detachFilesystems(ctx *context, ops map[params.MachineStorageId]*detachFile
 			}
 			entityStatus := &statuses[len(statuses)-1]
 			if err != nil {
-				reschedule = append(reschedule, ops[id])
 				entityStatus.Status = status.Detaching.String()
 				entityStatus.Info = err.Error()
+				op := ops[id]
+				reschedule = append(reschedule, op)
+				if !op.failed(err) {
+					entityStatus.Status = status.Error.String()
+				}
 				logger.Debugf(
 					"failed to detach %s from %s: %v",
 					names.ReadableString(p.Filesystem),
@@ -75,6 +75,11 @@ func (s *Schedule) Remove(key interface{}) {
 	}
 }
 
+// Len returns the number of items in the schedule.
+func (s *Schedule) Len() int {
+	return len(s.items)
+}
+
 type scheduleItems []*scheduleItem
 
 type scheduleItem struct {
@@ -99,6 +99,22 @@ func (*scheduleSuite) TestRemoveKeyNotFound(c *gc.C) {
 	s.Remove("0") // does not explode
 }
 
+func (*scheduleSuite) TestLen(c *gc.C) {
+	clock := jujutesting.NewClock(time.Time{})
+	now := clock.Now()
+	s := schedule.NewSchedule(clock)
+	c.Assert(s.Len(), gc.Equals, 0)
+
+	s.Add("k0", "v0", now)
+	s.Add("k1", "v1", now.Add(time.Second))
+	c.Assert(s.Len(), gc.Equals, 2)
+
+	s.Remove("k1")
+	c.Assert(s.Len(), gc.Equals, 1)
+	s.Ready(now)
+	c.Assert(s.Len(), gc.Equals, 0)
+}
+
 func assertNextOp(c *gc.C, s *schedule.Schedule, clock *jujutesting.Clock, d time.Duration) {
 	next := s.Next()
 	c.Assert(next, gc.NotNil)
@@ -24,6 +24,10 @@ type MachineManifoldConfig struct {
 	AgentName     string
 	APICallerName string
 	Clock         clock.Clock
+
+	// MetricsRegisterer, if non-nil, is used to register
+	// the storage provisioner's metrics collector.
+	MetricsRegisterer MetricsRegisterer
 }
 
 func (config MachineManifoldConfig) newWorker(a agent.Agent, apiCaller base.APICaller) (worker.Worker, error) {
@@ -52,7 +56,11 @@ func (config MachineManifoldConfig) newWorker(a agent.Agent, apiCaller base.APIC
 		Registry:    provider.CommonStorageProviders(),
 		Machines:    api,
 		Status:      api,
+		Claims:      api,
 		Clock:       config.Clock,
+		RetryJitter: defaultRetryJitter,
+
+		MetricsRegisterer: config.MetricsRegisterer,
 	})
 	if err != nil {
 		return nil, errors.Trace(err)
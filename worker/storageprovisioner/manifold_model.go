@@ -23,6 +23,10 @@ type ModelManifoldConfig struct {
 
 	Scope      names.Tag
 	StorageDir string
+
+	// MetricsRegisterer, if non-nil, is used to register
+	// the storage provisioner's metrics collector.
+	MetricsRegisterer MetricsRegisterer
 }
 
 // ModelManifold returns a dependency.Manifold that runs a storage provisioner.
@@ -57,7 +61,11 @@ func ModelManifold(config ModelManifoldConfig) dependency.Manifold {
 				Registry:    environ,
 				Machines:    api,
 				Status:      api,
+				Claims:      api,
 				Clock:       clock,
+				RetryJitter: defaultRetryJitter,
+
+				MetricsRegisterer: config.MetricsRegisterer,
 			})
 			if err != nil {
 				return nil, errors.Trace(err)
@@ -0,0 +1,156 @@
+// Copyright 2016 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package storageprovisioner
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/juju/juju/storage"
+)
+
+const (
+	metricsNamespace = "juju"
+	metricsSubsystem = "storageprovisioner"
+
+	metricsResultSuccess = "success"
+	metricsResultError   = "error"
+)
+
+// Operation names used to label the storage provisioner's metrics.
+const (
+	operationCreateVolume      = "create-volume"
+	operationDestroyVolume     = "destroy-volume"
+	operationAttachVolume      = "attach-volume"
+	operationDetachVolume      = "detach-volume"
+	operationCreateFilesystem  = "create-filesystem"
+	operationDestroyFilesystem = "destroy-filesystem"
+	operationAttachFilesystem  = "attach-filesystem"
+	operationDetachFilesystem  = "detach-filesystem"
+)
+
+// Entity kinds used to label the storage provisioner's pending metric.
+const (
+	pendingKindVolume               = "volume"
+	pendingKindVolumeAttachment     = "volume-attachment"
+	pendingKindFilesystem           = "filesystem"
+	pendingKindFilesystemAttachment = "filesystem-attachment"
+)
+
+// MetricsRegisterer is an interface for registering and unregistering
+// Prometheus metrics collectors. The storage provisioner registers its
+// collector when it starts, and unregisters it when it stops.
+type MetricsRegisterer interface {
+	// Register registers the given collector, returning an
+	// error if it, or a collector with the same descriptors,
+	// is already registered.
+	Register(prometheus.Collector) error
+
+	// Unregister unregisters the given collector, returning
+	// true if the collector was registered.
+	Unregister(prometheus.Collector) bool
+}
+
+// metrics holds the Prometheus metrics for a storage provisioner.
+// It implements prometheus.Collector.
+type metrics struct {
+	scheduled  prometheus.Gauge
+	pending    *prometheus.GaugeVec
+	operations *prometheus.CounterVec
+	latency    *prometheus.HistogramVec
+}
+
+func newMetrics(scope string) *metrics {
+	constLabels := prometheus.Labels{"scope": scope}
+	return &metrics{
+		scheduled: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace:   metricsNamespace,
+			Subsystem:   metricsSubsystem,
+			Name:        "scheduled_operations",
+			Help:        "Number of storage operations waiting in the schedule.",
+			ConstLabels: constLabels,
+		}),
+		pending: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace:   metricsNamespace,
+			Subsystem:   metricsSubsystem,
+			Name:        "pending",
+			Help:        "Number of storage entities waiting on prerequisites before they can be provisioned.",
+			ConstLabels: constLabels,
+		}, []string{"kind"}),
+		operations: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace:   metricsNamespace,
+			Subsystem:   metricsSubsystem,
+			Name:        "operations_total",
+			Help:        "Number of storage operations performed, by operation, provider and result.",
+			ConstLabels: constLabels,
+		}, []string{"operation", "provider", "result"}),
+		latency: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace:   metricsNamespace,
+			Subsystem:   metricsSubsystem,
+			Name:        "operation_duration_seconds",
+			Help:        "Latency of bulk storage provider calls, by operation and provider.",
+			ConstLabels: constLabels,
+			Buckets:     []float64{0.1, 0.5, 1, 5, 10, 30, 60, 120, 300, 600},
+		}, []string{"operation", "provider"}),
+	}
+}
+
+// Describe is part of the prometheus.Collector interface.
+func (m *metrics) Describe(ch chan<- *prometheus.Desc) {
+	m.scheduled.Describe(ch)
+	m.pending.Describe(ch)
+	m.operations.Describe(ch)
+	m.latency.Describe(ch)
+}
+
+// Collect is part of the prometheus.Collector interface.
+func (m *metrics) Collect(ch chan<- prometheus.Metric) {
+	m.scheduled.Collect(ch)
+	m.pending.Collect(ch)
+	m.operations.Collect(ch)
+	m.latency.Collect(ch)
+}
+
+// updateQueueDepth records the number of scheduled operations,
+// and the number of entities waiting on prerequisites.
+func (m *metrics) updateQueueDepth(ctx *context) {
+	m.scheduled.Set(float64(ctx.schedule.Len()))
+	m.pending.WithLabelValues(pendingKindVolume).Set(float64(len(ctx.incompleteVolumeParams)))
+	m.pending.WithLabelValues(pendingKindVolumeAttachment).Set(float64(len(ctx.incompleteVolumeAttachmentParams)))
+	m.pending.WithLabelValues(pendingKindFilesystem).Set(float64(len(ctx.incompleteFilesystemParams)))
+	m.pending.WithLabelValues(pendingKindFilesystemAttachment).Set(float64(len(ctx.incompleteFilesystemAttachmentParams)))
+}
+
+// observeOperation records the latency of a bulk provider call for the
+// specified operation and provider type, and the result of each item in
+// the call. If the call as a whole failed, all n items are considered to
+// have failed.
+//
+// The provider label is the storage provider type, rather than the name
+// of the source the call was made through, so that the label's values
+// stay bounded when a provider has multiple sources.
+func (m *metrics) observeOperation(
+	operation string, providerType storage.ProviderType,
+	d time.Duration, n int, callErr error, errs []error,
+) {
+	provider := string(providerType)
+	m.latency.WithLabelValues(operation, provider).Observe(d.Seconds())
+	var failed int
+	if callErr != nil {
+		failed = n
+	} else {
+		for _, err := range errs {
+			if err != nil {
+				failed++
+			}
+		}
+	}
+	if succeeded := n - failed; succeeded > 0 {
+		m.operations.WithLabelValues(operation, provider, metricsResultSuccess).Add(float64(succeeded))
+	}
+	if failed > 0 {
+		m.operations.WithLabelValues(operation, provider, metricsResultError).Add(float64(failed))
+	}
+}
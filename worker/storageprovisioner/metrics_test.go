@@ -0,0 +1,121 @@
+// Copyright 2016 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package storageprovisioner_test
+
+import (
+	"strings"
+
+	"github.com/juju/errors"
+	jc "github.com/juju/testing/checkers"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/instance"
+	"github.com/juju/juju/storage"
+	coretesting "github.com/juju/juju/testing"
+	"github.com/juju/juju/watcher"
+	"github.com/juju/juju/worker/storageprovisioner"
+)
+
+func (s *storageProvisionerSuite) TestMetricsRegistration(c *gc.C) {
+	registerer := &mockMetricsRegisterer{}
+	worker := newStorageProvisioner(c, &workerArgs{
+		registry:   s.registry,
+		registerer: registerer,
+	})
+	c.Assert(registerer.collectors, gc.HasLen, 1)
+	registerer.CheckCallNames(c, "Register")
+
+	worker.Kill()
+	c.Assert(worker.Wait(), jc.ErrorIsNil)
+	c.Assert(registerer.collectors, gc.HasLen, 0)
+	registerer.CheckCallNames(c, "Register", "Unregister")
+}
+
+func (s *storageProvisionerSuite) TestMetricsRegistrationError(c *gc.C) {
+	registerer := &mockMetricsRegisterer{}
+	registerer.SetErrors(errors.New("duplicate metrics collector"))
+	config := almostValidConfig()
+	config.Scope = coretesting.ModelTag
+	config.Clock = &mockClock{}
+	config.MetricsRegisterer = registerer
+	_, err := storageprovisioner.NewStorageProvisioner(config)
+	c.Assert(err, gc.ErrorMatches, "registering metrics: duplicate metrics collector")
+}
+
+func (s *storageProvisionerSuite) TestCreateVolumesMetrics(c *gc.C) {
+	volumeAccessor := newMockVolumeAccessor()
+	volumeAccessor.provisionedMachines["machine-1"] = instance.Id("already-provisioned-1")
+	volumeInfoSet := make(chan interface{})
+	volumeAccessor.setVolumeInfo = func(volumes []params.Volume) ([]params.ErrorResult, error) {
+		defer close(volumeInfoSet)
+		return nil, nil
+	}
+	s.provider.createVolumesFunc = func(args []storage.VolumeParams) ([]storage.CreateVolumesResult, error) {
+		results := make([]storage.CreateVolumesResult, len(args))
+		for i, arg := range args {
+			results[i].Volume = &storage.Volume{
+				Tag:        arg.Tag,
+				VolumeInfo: storage.VolumeInfo{VolumeId: "vol-" + arg.Tag.Id()},
+			}
+		}
+		return results, nil
+	}
+
+	registerer := &mockMetricsRegisterer{}
+	worker := newStorageProvisioner(c, &workerArgs{
+		volumes:    volumeAccessor,
+		registry:   s.registry,
+		registerer: registerer,
+	})
+	defer func() { c.Assert(worker.Wait(), gc.IsNil) }()
+	defer worker.Kill()
+
+	volumeAccessor.attachmentsWatcher.changes <- []watcher.MachineStorageId{{
+		MachineTag: "machine-1", AttachmentTag: "volume-1",
+	}, {
+		MachineTag: "machine-1", AttachmentTag: "volume-2",
+	}}
+	volumeAccessor.volumesWatcher.changes <- []string{"1", "2"}
+	waitChannel(c, volumeInfoSet, "waiting for volume info to be set")
+
+	c.Assert(registerer.collectors, gc.HasLen, 1)
+	metrics := collectMetrics(c, registerer.collectors[0], "juju_storageprovisioner_operations_total")
+	c.Assert(metrics, jc.DeepEquals, map[string]float64{
+		"operation=create-volume,provider=dummy,result=success,scope=" + coretesting.ModelTag.String(): 2,
+	})
+}
+
+// collectMetrics collects the counter and gauge values of the metrics
+// with the given name from the collector, keyed by their sorted label
+// pairs.
+func collectMetrics(c *gc.C, collector prometheus.Collector, name string) map[string]float64 {
+	ch := make(chan prometheus.Metric)
+	go func() {
+		defer close(ch)
+		collector.Collect(ch)
+	}()
+	result := make(map[string]float64)
+	for metric := range ch {
+		if !strings.Contains(metric.Desc().String(), `fqName: "`+name+`"`) {
+			continue
+		}
+		var m dto.Metric
+		c.Assert(metric.Write(&m), jc.ErrorIsNil)
+		labels := make([]string, len(m.Label))
+		for i, label := range m.Label {
+			labels[i] = label.GetName() + "=" + label.GetValue()
+		}
+		key := strings.Join(labels, ",")
+		switch {
+		case m.Counter != nil:
+			result[key] = m.Counter.GetValue()
+		case m.Gauge != nil:
+			result[key] = m.Gauge.GetValue()
+		}
+	}
+	return result
+}
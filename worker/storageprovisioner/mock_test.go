@@ -10,6 +10,7 @@ import (
 	"github.com/juju/errors"
 	gitjujutesting "github.com/juju/testing"
 	"github.com/juju/utils/clock"
+	"github.com/prometheus/client_golang/prometheus"
 	gc "gopkg.in/check.v1"
 	"gopkg.in/juju/names.v2"
 
@@ -441,6 +442,17 @@ type dummyVolumeSource struct {
 	createVolumesArgs [][]storage.VolumeParams
 }
 
+// limitedVolumeSource is a dummyVolumeSource that implements
+// storage.VolumeAttachmentLimiter.
+type limitedVolumeSource struct {
+	*dummyVolumeSource
+	volumeAttachmentLimitsFunc func([]instance.Id) ([]storage.VolumeAttachmentLimitsResult, error)
+}
+
+func (s *limitedVolumeSource) VolumeAttachmentLimits(instanceIds []instance.Id) ([]storage.VolumeAttachmentLimitsResult, error) {
+	return s.volumeAttachmentLimitsFunc(instanceIds)
+}
+
 type dummyFilesystemSource struct {
 	storage.FilesystemSource
 	provider              *dummyProvider
@@ -614,6 +626,7 @@ func (s *dummyFilesystemSource) DetachFilesystems(params []storage.FilesystemAtt
 type mockManagedFilesystemSource struct {
 	blockDevices map[names.VolumeTag]storage.BlockDevice
 	filesystems  map[names.FilesystemTag]storage.Filesystem
+	resizeArgs   []storage.ResizeFilesystemParams
 }
 
 func (s *mockManagedFilesystemSource) ValidateFilesystemParams(params storage.FilesystemParams) error {
@@ -678,6 +691,26 @@ func (s *mockManagedFilesystemSource) DetachFilesystems(params []storage.Filesys
 	return nil, errors.NotImplementedf("DetachFilesystems")
 }
 
+func (s *mockManagedFilesystemSource) ResizeFilesystems(args []storage.ResizeFilesystemParams) ([]storage.ResizeFilesystemsResult, error) {
+	s.resizeArgs = append(s.resizeArgs, args...)
+	results := make([]storage.ResizeFilesystemsResult, len(args))
+	for i, arg := range args {
+		filesystem, ok := s.filesystems[arg.Filesystem]
+		if !ok {
+			results[i].Error = errors.Errorf("filesystem %v has not been created", arg.Filesystem.Id())
+			continue
+		}
+		blockDevice, ok := s.blockDevices[filesystem.Volume]
+		if !ok {
+			results[i].Error = errors.Errorf("filesystem %v's backing-volume is not attached", filesystem.Tag.Id())
+			continue
+		}
+		filesystem.Size = blockDevice.Size
+		results[i].Filesystem = &filesystem
+	}
+	return results, nil
+}
+
 type mockMachineAccessor struct {
 	instanceIds map[names.MachineTag]instance.Id
 	watcher     *mockNotifyWatcher
@@ -773,3 +806,47 @@ func (m *mockStatusSetter) SetStatus(args []params.EntityStatusArgs) error {
 	m.args = append(m.args, args...)
 	return nil
 }
+
+type mockStorageClaimer struct {
+	claim   func([]names.Tag) ([]params.ErrorResult, error)
+	release func([]names.Tag) ([]params.ErrorResult, error)
+}
+
+func (m *mockStorageClaimer) ClaimStorageEntities(tags []names.Tag) ([]params.ErrorResult, error) {
+	if m.claim != nil {
+		return m.claim(tags)
+	}
+	return make([]params.ErrorResult, len(tags)), nil
+}
+
+func (m *mockStorageClaimer) ReleaseStorageEntities(tags []names.Tag) ([]params.ErrorResult, error) {
+	if m.release != nil {
+		return m.release(tags)
+	}
+	return make([]params.ErrorResult, len(tags)), nil
+}
+
+type mockMetricsRegisterer struct {
+	gitjujutesting.Stub
+	collectors []prometheus.Collector
+}
+
+func (r *mockMetricsRegisterer) Register(c prometheus.Collector) error {
+	r.MethodCall(r, "Register", c)
+	if err := r.NextErr(); err != nil {
+		return err
+	}
+	r.collectors = append(r.collectors, c)
+	return nil
+}
+
+func (r *mockMetricsRegisterer) Unregister(c prometheus.Collector) bool {
+	r.MethodCall(r, "Unregister", c)
+	for i, existing := range r.collectors {
+		if existing == c {
+			r.collectors = append(r.collectors[:i], r.collectors[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
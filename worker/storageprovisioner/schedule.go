@@ -3,7 +3,11 @@
 
 package storageprovisioner
 
-import "time"
+import (
+	"time"
+
+	"github.com/juju/juju/environs"
+)
 
 // minRetryDelay is the minimum delay to apply
 // to operation retries; this does not apply to
@@ -15,12 +19,83 @@ const minRetryDelay = 30 * time.Second
 // up to this ceiling.
 const maxRetryDelay = 30 * time.Minute
 
+// defaultRetryJitter is the retry jitter used by
+// the storage provisioners started by manifolds.
+const defaultRetryJitter = 0.2
+
+// retryStrategy describes how an operation is retried after
+// failing with a particular class of error.
+type retryStrategy struct {
+	// minDelay and maxDelay bound the backoff applied
+	// to retries of the operation.
+	minDelay time.Duration
+	maxDelay time.Duration
+
+	// permanent records that the error will not go away by
+	// retrying alone. The operation is still retried, in case
+	// somebody fixes the cause, but its status is "error".
+	permanent bool
+}
+
+var (
+	// transientRetryStrategy is the strategy for errors
+	// that are not classified by the provider, which
+	// are assumed to be transient.
+	transientRetryStrategy = retryStrategy{
+		minDelay: minRetryDelay,
+		maxDelay: maxRetryDelay,
+	}
+
+	// longRetryStrategy is the strategy for errors that
+	// are not expected to go away until somebody acts,
+	// e.g. by increasing a quota.
+	longRetryStrategy = retryStrategy{
+		minDelay: 5 * time.Minute,
+		maxDelay: 2 * time.Hour,
+	}
+
+	// permanentRetryStrategy is the strategy for errors
+	// that retrying the same operation cannot fix. The
+	// operation is retried with the long backoff, so that
+	// it completes once somebody fixes the cause.
+	permanentRetryStrategy = retryStrategy{
+		minDelay:  longRetryStrategy.minDelay,
+		maxDelay:  longRetryStrategy.maxDelay,
+		permanent: true,
+	}
+)
+
+// retryStrategies maps the kinds of provisioning error reported by
+// storage providers to the strategy for retrying operations that
+// fail with them. Kinds not listed use transientRetryStrategy.
+var retryStrategies = map[environs.ProvisioningErrorKind]retryStrategy{
+	environs.ProvisioningErrorQuota:    longRetryStrategy,
+	environs.ProvisioningErrorAuth:     longRetryStrategy,
+	environs.ProvisioningErrorCapacity: transientRetryStrategy,
+	environs.ProvisioningErrorConfig:   permanentRetryStrategy,
+	environs.ProvisioningErrorPolicy:   permanentRetryStrategy,
+}
+
+// retryStrategyFor returns the strategy for retrying
+// an operation that failed with the given error.
+func retryStrategyFor(err error) retryStrategy {
+	if strategy, ok := retryStrategies[environs.ProvisioningErrorKindOf(err)]; ok {
+		return strategy
+	}
+	return transientRetryStrategy
+}
+
 // scheduleOperations schedules the given operations
 // by calculating the current time once, and then
 // adding each operation's delay to that time. By
 // calculating the current time once, we guarantee
 // that operations with the same delay will be
 // batched together.
+//
+// If the worker is configured with a retry jitter,
+// each operation's delay is scaled by its own random
+// factor, so that the retries of operations that
+// failed together are spread out.
 func scheduleOperations(ctx *context, ops ...scheduleOp) {
 	if len(ops) == 0 {
 		return
@@ -29,6 +104,10 @@ func scheduleOperations(ctx *context, ops ...scheduleOp) {
 	for _, op := range ops {
 		k := op.key()
 		d := op.delay()
+		if jitter := ctx.config.RetryJitter; jitter > 0 {
+			factor := 1 + jitter*(2*ctx.rand.Float64()-1)
+			d = time.Duration(float64(d) * factor)
+		}
 		ctx.schedule.Add(k, op, now.Add(d))
 	}
 }
@@ -43,24 +122,51 @@ type scheduleOp interface {
 	// delay is the amount of time to delay
 	// before next executing the operation.
 	delay() time.Duration
+
+	// failed records that the operation failed
+	// with the given error, and reports whether
+	// retrying the operation is expected to fix
+	// the error. Failed operations are always
+	// rescheduled; if the error is permanent, it
+	// is on a long backoff.
+	failed(err error) bool
 }
 
 // exponentialBackoff is a type that can be embedded to implement the
-// delay() method of scheduleOp, providing truncated binary exponential
-// backoff for operations that may be rescheduled.
+// delay() and failed() methods of scheduleOp, providing truncated
+// binary exponential backoff for operations that may be rescheduled.
+// The bounds of the backoff depend on the class of the most recent
+// error that the operation failed with.
 type exponentialBackoff struct {
-	d time.Duration
+	d        time.Duration
+	strategy *retryStrategy
 }
 
 func (s *exponentialBackoff) delay() time.Duration {
+	strategy := transientRetryStrategy
+	if s.strategy != nil {
+		strategy = *s.strategy
+	}
 	current := s.d
-	if s.d < minRetryDelay {
-		s.d = minRetryDelay
+	if s.d < strategy.minDelay {
+		s.d = strategy.minDelay
 	} else {
 		s.d *= 2
-		if s.d > maxRetryDelay {
-			s.d = maxRetryDelay
+		if s.d > strategy.maxDelay {
+			s.d = strategy.maxDelay
 		}
 	}
 	return current
 }
+
+func (s *exponentialBackoff) failed(err error) bool {
+	strategy := retryStrategyFor(err)
+	if s.strategy != nil && *s.strategy != strategy || s.d < strategy.minDelay {
+		// The class of error has changed, or the
+		// backoff is below its bounds, so restart
+		// the backoff at the lower bound.
+		s.d = strategy.minDelay
+	}
+	s.strategy = &strategy
+	return !strategy.permanent
+}
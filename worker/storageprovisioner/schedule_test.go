@@ -0,0 +1,203 @@
+// Copyright 2016 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package storageprovisioner_test
+
+import (
+	"time"
+
+	"github.com/juju/errors"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/environs"
+	"github.com/juju/juju/instance"
+	"github.com/juju/juju/storage"
+	"github.com/juju/juju/watcher"
+)
+
+// createVolumeRetryTimes starts a storage provisioner that creates a
+// single volume, with CreateVolumes failing as specified by fail until
+// it returns nil, and returns the times at which CreateVolumes was
+// called.
+func (s *storageProvisionerSuite) createVolumeRetryTimes(
+	c *gc.C, args *workerArgs, fail func(attempt int) (resultErr, callErr error),
+) []time.Time {
+	volumeInfoSet := make(chan interface{})
+	volumeAccessor := newMockVolumeAccessor()
+	volumeAccessor.provisionedMachines["machine-1"] = instance.Id("already-provisioned-1")
+	volumeAccessor.setVolumeInfo = func(volumes []params.Volume) ([]params.ErrorResult, error) {
+		defer close(volumeInfoSet)
+		return make([]params.ErrorResult, len(volumes)), nil
+	}
+
+	// mockClock's After will progress the current time by the specified
+	// duration and signal the channel immediately.
+	clock := &mockClock{}
+	var createVolumeTimes []time.Time
+	s.provider.createVolumesFunc = func(volumeParams []storage.VolumeParams) ([]storage.CreateVolumesResult, error) {
+		createVolumeTimes = append(createVolumeTimes, clock.Now())
+		resultErr, callErr := fail(len(createVolumeTimes))
+		if callErr != nil {
+			return nil, callErr
+		}
+		if resultErr != nil {
+			return []storage.CreateVolumesResult{{Error: resultErr}}, nil
+		}
+		return []storage.CreateVolumesResult{{
+			Volume: &storage.Volume{Tag: volumeParams[0].Tag},
+		}}, nil
+	}
+
+	args.volumes = volumeAccessor
+	args.clock = clock
+	args.registry = s.registry
+	worker := newStorageProvisioner(c, args)
+	defer func() { c.Assert(worker.Wait(), gc.IsNil) }()
+	defer worker.Kill()
+
+	volumeAccessor.attachmentsWatcher.changes <- []watcher.MachineStorageId{{
+		MachineTag: "machine-1", AttachmentTag: "volume-1",
+	}}
+	volumeAccessor.volumesWatcher.changes <- []string{"1"}
+	waitChannel(c, volumeInfoSet, "waiting for volume info to be set")
+	return createVolumeTimes
+}
+
+func retryDelays(times []time.Time) []time.Duration {
+	delays := make([]time.Duration, len(times)-1)
+	for i := range times[1:] {
+		delays[i] = times[i+1].Sub(times[i])
+	}
+	return delays
+}
+
+func (s *storageProvisionerSuite) TestCreateVolumeRetryQuota(c *gc.C) {
+	quotaErr := environs.NewProvisioningError(
+		errors.New("disk quota exceeded"),
+		environs.ProvisioningErrorQuota,
+	)
+	args := &workerArgs{}
+	times := s.createVolumeRetryTimes(c, args, func(attempt int) (error, error) {
+		if attempt < 10 {
+			return quotaErr, nil
+		}
+		return nil, nil
+	})
+	c.Assert(times, gc.HasLen, 10)
+	c.Assert(retryDelays(times), jc.DeepEquals, []time.Duration{
+		5 * time.Minute,
+		10 * time.Minute,
+		20 * time.Minute,
+		40 * time.Minute,
+		80 * time.Minute,
+		2 * time.Hour, // ceiling reached
+		2 * time.Hour,
+		2 * time.Hour,
+		2 * time.Hour,
+	})
+}
+
+func (s *storageProvisionerSuite) TestCreateVolumeRetryErrorClassChanges(c *gc.C) {
+	quotaErr := environs.NewProvisioningError(
+		errors.New("disk quota exceeded"),
+		environs.ProvisioningErrorQuota,
+	)
+	args := &workerArgs{}
+	times := s.createVolumeRetryTimes(c, args, func(attempt int) (error, error) {
+		switch {
+		case attempt < 3:
+			return errors.New("badness"), nil
+		case attempt < 5:
+			return quotaErr, nil
+		case attempt < 7:
+			return errors.New("badness"), nil
+		}
+		return nil, nil
+	})
+	c.Assert(times, gc.HasLen, 7)
+	c.Assert(retryDelays(times), jc.DeepEquals, []time.Duration{
+		30 * time.Second,
+		1 * time.Minute,
+		5 * time.Minute, // quota exceeded
+		10 * time.Minute,
+		30 * time.Second, // transient again
+		1 * time.Minute,
+	})
+}
+
+func (s *storageProvisionerSuite) TestCreateVolumeRetryCallError(c *gc.C) {
+	// The failure of CreateVolumes as a whole should not kill
+	// the worker, but cause the volumes to be retried.
+	args := &workerArgs{}
+	times := s.createVolumeRetryTimes(c, args, func(attempt int) (error, error) {
+		if attempt < 3 {
+			return nil, errors.New("rate limited")
+		}
+		return nil, nil
+	})
+	c.Assert(times, gc.HasLen, 3)
+	c.Assert(retryDelays(times), jc.DeepEquals, []time.Duration{
+		30 * time.Second,
+		1 * time.Minute,
+	})
+	c.Assert(args.statusSetter.args, jc.DeepEquals, []params.EntityStatusArgs{
+		{Tag: "volume-1", Status: "pending", Info: `creating volumes from source "dummy": rate limited`},
+		{Tag: "volume-1", Status: "pending", Info: `creating volumes from source "dummy": rate limited`},
+		{Tag: "volume-1", Status: "attaching", Info: ""},
+	})
+}
+
+func (s *storageProvisionerSuite) TestCreateVolumeRetryJitter(c *gc.C) {
+	args := &workerArgs{retryJitter: 0.5}
+	times := s.createVolumeRetryTimes(c, args, func(attempt int) (error, error) {
+		if attempt < 7 {
+			return errors.New("badness"), nil
+		}
+		return nil, nil
+	})
+	c.Assert(times, gc.HasLen, 7)
+	c.Assert(times[0], gc.Equals, time.Time{})
+	delays := retryDelays(times)
+	for i, expect := range []time.Duration{
+		30 * time.Second,
+		1 * time.Minute,
+		2 * time.Minute,
+		4 * time.Minute,
+		8 * time.Minute,
+		16 * time.Minute,
+	} {
+		c.Check(delays[i] >= expect/2, jc.IsTrue, gc.Commentf("delay %d: %v", i, delays[i]))
+		c.Check(delays[i] <= expect*3/2, jc.IsTrue, gc.Commentf("delay %d: %v", i, delays[i]))
+	}
+}
+
+func (s *storageProvisionerSuite) TestCreateVolumeRetryConfigError(c *gc.C) {
+	// Config errors are not fixed by retrying alone, so the volume's
+	// status is set to "error"; the volume is retried on the long
+	// backoff, so that it is created once the config is fixed.
+	configErr := environs.NewProvisioningError(
+		errors.New("unknown disk type"),
+		environs.ProvisioningErrorConfig,
+	)
+	args := &workerArgs{}
+	times := s.createVolumeRetryTimes(c, args, func(attempt int) (error, error) {
+		if attempt < 4 {
+			return configErr, nil
+		}
+		return nil, nil
+	})
+	c.Assert(times, gc.HasLen, 4)
+	c.Assert(retryDelays(times), jc.DeepEquals, []time.Duration{
+		5 * time.Minute,
+		10 * time.Minute,
+		20 * time.Minute,
+	})
+	c.Assert(args.statusSetter.args, jc.DeepEquals, []params.EntityStatusArgs{
+		{Tag: "volume-1", Status: "error", Info: "unknown disk type"},
+		{Tag: "volume-1", Status: "error", Info: "unknown disk type"},
+		{Tag: "volume-1", Status: "error", Info: "unknown disk type"},
+		{Tag: "volume-1", Status: "attaching", Info: ""},
+	})
+}
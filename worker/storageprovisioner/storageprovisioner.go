@@ -27,6 +27,8 @@
 package storageprovisioner
 
 import (
+	"math/rand"
+
 	"github.com/juju/errors"
 	"github.com/juju/loggo"
 	"github.com/juju/utils/set"
@@ -150,6 +152,20 @@ type LifecycleManager interface {
 	RemoveAttachments([]params.MachineStorageId) ([]params.ErrorResult, error)
 }
 
+// StorageClaimer defines an interface used to coordinate the storage
+// provisioners of different scopes, by claiming volumes and filesystems
+// before acting on them.
+type StorageClaimer interface {
+	// ClaimStorageEntities claims the specified volumes and
+	// filesystems on behalf of the worker's scope. Claiming an
+	// entity claimed by another scope fails.
+	ClaimStorageEntities([]names.Tag) ([]params.ErrorResult, error)
+
+	// ReleaseStorageEntities releases the claims held by the
+	// worker's scope on the specified volumes and filesystems.
+	ReleaseStorageEntities([]names.Tag) ([]params.ErrorResult, error)
+}
+
 // StatusSetter defines an interface used to set the status of entities.
 type StatusSetter interface {
 	SetStatus([]params.EntityStatusArgs) error
@@ -168,13 +184,22 @@ var NewStorageProvisioner = func(config Config) (worker.Worker, error) {
 		return nil, errors.Trace(err)
 	}
 	w := &storageProvisioner{
-		config: config,
+		config:  config,
+		metrics: newMetrics(config.Scope.String()),
+	}
+	if config.MetricsRegisterer != nil {
+		if err := config.MetricsRegisterer.Register(w.metrics); err != nil {
+			return nil, errors.Annotate(err, "registering metrics")
+		}
 	}
 	err := catacomb.Invoke(catacomb.Plan{
 		Site: &w.catacomb,
 		Work: w.loop,
 	})
 	if err != nil {
+		if config.MetricsRegisterer != nil {
+			config.MetricsRegisterer.Unregister(w.metrics)
+		}
 		return nil, errors.Trace(err)
 	}
 	return w, nil
@@ -183,6 +208,7 @@ var NewStorageProvisioner = func(config Config) (worker.Worker, error) {
 type storageProvisioner struct {
 	catacomb catacomb.Catacomb
 	config   Config
+	metrics  *metrics
 }
 
 // Kill implements Worker.Kill().
@@ -196,6 +222,9 @@ func (w *storageProvisioner) Wait() error {
 }
 
 func (w *storageProvisioner) loop() error {
+	if w.config.MetricsRegisterer != nil {
+		defer w.config.MetricsRegisterer.Unregister(w.metrics)
+	}
 	var (
 		volumesChanges               watcher.StringsChannel
 		filesystemsChanges           watcher.StringsChannel
@@ -258,6 +287,7 @@ func (w *storageProvisioner) loop() error {
 		kill:                                 w.catacomb.Kill,
 		addWorker:                            w.catacomb.Add,
 		config:                               w.config,
+		metrics:                              w.metrics,
 		volumes:                              make(map[names.VolumeTag]storage.Volume),
 		volumeAttachments:                    make(map[params.MachineStorageId]storage.VolumeAttachment),
 		volumeBlockDevices:                   make(map[names.VolumeTag]storage.BlockDevice),
@@ -271,6 +301,7 @@ func (w *storageProvisioner) loop() error {
 		incompleteFilesystemParams:           make(map[names.FilesystemTag]storage.FilesystemParams),
 		incompleteFilesystemAttachmentParams: make(map[params.MachineStorageId]storage.FilesystemAttachmentParams),
 		pendingVolumeBlockDevices:            make(set.Tags),
+		rand:                                 rand.New(rand.NewSource(w.config.Clock.Now().UnixNano())),
 	}
 	ctx.managedFilesystemSource = newManagedFilesystemSource(
 		ctx.volumeBlockDevices, ctx.filesystems,
@@ -281,6 +312,7 @@ func (w *storageProvisioner) loop() error {
 		if err := processPendingVolumeBlockDevices(&ctx); err != nil {
 			return errors.Annotate(err, "processing pending block devices")
 		}
+		ctx.metrics.updateQueueDepth(&ctx)
 
 		select {
 		case <-w.catacomb.Dying():
@@ -366,6 +398,14 @@ func processSchedule(ctx *context) error {
 			detachFilesystemOps[key.(params.MachineStorageId)] = op
 		}
 	}
+	release, err := claimStorageEntities(
+		ctx, createVolumeOps, destroyVolumeOps,
+		createFilesystemOps, destroyFilesystemOps,
+	)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	defer release()
 	if len(destroyVolumeOps) > 0 {
 		if err := destroyVolumes(ctx, destroyVolumeOps); err != nil {
 			return errors.Annotate(err, "destroying volumes")
@@ -413,6 +453,7 @@ type context struct {
 	kill      func(error)
 	addWorker func(worker.Worker) error
 	config    Config
+	metrics   *metrics
 
 	// volumes contains information about provisioned volumes.
 	volumes map[names.VolumeTag]storage.Volume
@@ -441,6 +482,10 @@ type context struct {
 	// schedule is the schedule of storage operations.
 	schedule *schedule.Schedule
 
+	// rand is the source of the jitter applied to
+	// the delays before retrying failed operations.
+	rand *rand.Rand
+
 	// incompleteVolumeParams contains incomplete parameters for volumes.
 	//
 	// Volume parameters are incomplete when they lack information about
@@ -64,6 +64,7 @@ func (s *storageProvisionerSuite) TestStartStop(c *gc.C) {
 		Registry:    s.registry,
 		Machines:    newMockMachineAccessor(c),
 		Status:      &mockStatusSetter{},
+		Claims:      &mockStorageClaimer{},
 		Clock:       &mockClock{},
 	})
 	c.Assert(err, jc.ErrorIsNil)
@@ -197,6 +198,65 @@ func (s *storageProvisionerSuite) TestCreateVolumeCreatesAttachment(c *gc.C) {
 	assertNoEvent(c, attachVolumesCalled, "AttachVolumes called")
 }
 
+func (s *storageProvisionerSuite) TestCreateVolumeClaimContended(c *gc.C) {
+	volumeInfoSet := make(chan interface{})
+	volumeAccessor := newMockVolumeAccessor()
+	volumeAccessor.provisionedMachines["machine-1"] = instance.Id("already-provisioned-1")
+	volumeAccessor.setVolumeInfo = func(volumes []params.Volume) ([]params.ErrorResult, error) {
+		defer close(volumeInfoSet)
+		return make([]params.ErrorResult, len(volumes)), nil
+	}
+
+	clock := &mockClock{}
+	var createVolumeTimes []time.Time
+	s.provider.createVolumesFunc = func(args []storage.VolumeParams) ([]storage.CreateVolumesResult, error) {
+		createVolumeTimes = append(createVolumeTimes, clock.Now())
+		return []storage.CreateVolumesResult{{
+			Volume: &storage.Volume{Tag: args[0].Tag},
+		}}, nil
+	}
+
+	// The first claim fails, as if the volume were being acted
+	// upon by the storage provisioner of another scope.
+	var claimed [][]names.Tag
+	released := make(chan interface{}, 1)
+	claims := &mockStorageClaimer{
+		claim: func(tags []names.Tag) ([]params.ErrorResult, error) {
+			claimed = append(claimed, tags)
+			results := make([]params.ErrorResult, len(tags))
+			if len(claimed) == 1 {
+				results[0].Error = &params.Error{Message: "volume 1 is claimed by machine 0"}
+			}
+			return results, nil
+		},
+		release: func(tags []names.Tag) ([]params.ErrorResult, error) {
+			released <- tags
+			return make([]params.ErrorResult, len(tags)), nil
+		},
+	}
+
+	args := &workerArgs{volumes: volumeAccessor, clock: clock, registry: s.registry, claims: claims}
+	worker := newStorageProvisioner(c, args)
+	defer func() { c.Assert(worker.Wait(), gc.IsNil) }()
+	defer worker.Kill()
+
+	volumeAccessor.attachmentsWatcher.changes <- []watcher.MachineStorageId{{
+		MachineTag: "machine-1", AttachmentTag: "volume-1",
+	}}
+	volumeAccessor.volumesWatcher.changes <- []string{"1"}
+	waitChannel(c, volumeInfoSet, "waiting for volume info to be set")
+	tags := waitChannel(c, released, "waiting for claims to be released")
+
+	// The volume should have been created only once claimed,
+	// after backing off, and the claim then released.
+	volume1 := names.NewVolumeTag("1")
+	c.Assert(tags, jc.DeepEquals, []names.Tag{volume1})
+	c.Assert(claimed, jc.DeepEquals, [][]names.Tag{{volume1}, {volume1}})
+	c.Assert(createVolumeTimes, jc.DeepEquals, []time.Time{
+		time.Time{}.Add(30 * time.Second),
+	})
+}
+
 func (s *storageProvisionerSuite) TestCreateVolumeRetry(c *gc.C) {
 	volumeInfoSet := make(chan interface{})
 	volumeAccessor := newMockVolumeAccessor()
@@ -417,6 +477,83 @@ func (s *storageProvisionerSuite) TestAttachVolumeRetry(c *gc.C) {
 	})
 }
 
+func (s *storageProvisionerSuite) TestAttachVolumeLimits(c *gc.C) {
+	volumeAccessor := newMockVolumeAccessor()
+	volumeAccessor.provisionedMachines["machine-1"] = instance.Id("already-provisioned-1")
+	volumeAttachmentInfoSet := make(chan interface{})
+	var attachmentInfo []params.VolumeAttachment
+	volumeAccessor.setVolumeAttachmentInfo = func(volumeAttachments []params.VolumeAttachment) ([]params.ErrorResult, error) {
+		attachmentInfo = append(attachmentInfo, volumeAttachments...)
+		if len(attachmentInfo) == 2 {
+			close(volumeAttachmentInfoSet)
+		}
+		return make([]params.ErrorResult, len(volumeAttachments)), nil
+	}
+
+	// The instance can initially have only one volume attached. Once
+	// an attachment has been rejected, the limit is raised so that the
+	// rejected attachment succeeds when it is retried.
+	maxAttachments := 1
+	var attached []string
+	var limitsArgs [][]instance.Id
+	s.provider.volumeSourceFunc = func(*storage.Config) (storage.VolumeSource, error) {
+		return &limitedVolumeSource{
+			dummyVolumeSource: &dummyVolumeSource{provider: s.provider},
+			volumeAttachmentLimitsFunc: func(ids []instance.Id) ([]storage.VolumeAttachmentLimitsResult, error) {
+				limitsArgs = append(limitsArgs, ids)
+				results := []storage.VolumeAttachmentLimitsResult{{
+					Max:      maxAttachments,
+					Attached: append([]string{}, attached...),
+				}}
+				if len(attached) >= maxAttachments {
+					// The pending attachment is rejected.
+					maxAttachments++
+				}
+				return results, nil
+			},
+		}, nil
+	}
+	s.provider.attachVolumesFunc = func(args []storage.VolumeAttachmentParams) ([]storage.AttachVolumesResult, error) {
+		results := make([]storage.AttachVolumesResult, len(args))
+		for i, a := range args {
+			attached = append(attached, a.VolumeId)
+			results[i].VolumeAttachment = &storage.VolumeAttachment{
+				a.Volume, a.Machine, storage.VolumeAttachmentInfo{},
+			}
+		}
+		return results, nil
+	}
+
+	args := &workerArgs{volumes: volumeAccessor, clock: &mockClock{}, registry: s.registry}
+	worker := newStorageProvisioner(c, args)
+	defer func() { c.Assert(worker.Wait(), gc.IsNil) }()
+	defer worker.Kill()
+
+	volumeAccessor.attachmentsWatcher.changes <- []watcher.MachineStorageId{{
+		MachineTag: "machine-1", AttachmentTag: "volume-1",
+	}, {
+		MachineTag: "machine-1", AttachmentTag: "volume-2",
+	}}
+	volumeAccessor.volumesWatcher.changes <- []string{"1", "2"}
+	waitChannel(c, volumeAttachmentInfoSet, "waiting for volume attachments to be set")
+	c.Assert(attached, jc.SameContents, []string{"id-1", "id-2"})
+	for _, ids := range limitsArgs {
+		c.Assert(ids, jc.DeepEquals, []instance.Id{"already-provisioned-1"})
+	}
+
+	var rejected []string
+	for _, status := range args.statusSetter.args {
+		if status.Info != "" {
+			c.Assert(status.Status, gc.Equals, "attaching")
+			rejected = append(rejected, status.Info)
+		}
+	}
+	c.Assert(rejected, gc.HasLen, 1)
+	c.Assert(rejected[0], gc.Matches,
+		"cannot attach volume [12] to machine 1: instance already-provisioned-1 has 1 of a maximum 1 volumes attached",
+	)
+}
+
 func (s *storageProvisionerSuite) TestAttachFilesystemRetry(c *gc.C) {
 	filesystemInfoSet := make(chan interface{})
 	filesystemAccessor := newMockFilesystemAccessor()
@@ -1055,6 +1192,78 @@ func (s *storageProvisionerSuite) TestAttachVolumeBackedFilesystem(c *gc.C) {
 	}})
 }
 
+func (s *storageProvisionerSuite) TestResizeVolumeBackedFilesystem(c *gc.C) {
+	attachmentInfoSet := make(chan interface{})
+	filesystemInfoSet := make(chan interface{})
+	filesystemAccessor := newMockFilesystemAccessor()
+	filesystemAccessor.setFilesystemAttachmentInfo = func(attachments []params.FilesystemAttachment) ([]params.ErrorResult, error) {
+		attachmentInfoSet <- attachments
+		return make([]params.ErrorResult, len(attachments)), nil
+	}
+	filesystemAccessor.setFilesystemInfo = func(filesystems []params.Filesystem) ([]params.ErrorResult, error) {
+		filesystemInfoSet <- filesystems
+		return nil, nil
+	}
+
+	args := &workerArgs{
+		scope:       names.NewMachineTag("0"),
+		filesystems: filesystemAccessor,
+		registry:    s.registry,
+	}
+	worker := newStorageProvisioner(c, args)
+	defer func() { c.Assert(worker.Wait(), gc.IsNil) }()
+	defer worker.Kill()
+
+	filesystemAccessor.provisionedFilesystems["filesystem-0-0"] = params.Filesystem{
+		FilesystemTag: "filesystem-0-0",
+		VolumeTag:     "volume-0-0",
+		Info: params.FilesystemInfo{
+			FilesystemId: "whatever",
+			Size:         123,
+		},
+	}
+	filesystemAccessor.provisionedMachines["machine-0"] = instance.Id("already-provisioned-0")
+
+	blockDeviceId := params.MachineStorageId{
+		MachineTag:    "machine-0",
+		AttachmentTag: "volume-0-0",
+	}
+	args.volumes.blockDevices[blockDeviceId] = storage.BlockDevice{
+		DeviceName: "xvdf1",
+		Size:       123,
+	}
+	filesystemAccessor.attachmentsWatcher.changes <- []watcher.MachineStorageId{{
+		MachineTag:    "machine-0",
+		AttachmentTag: "filesystem-0-0",
+	}}
+	filesystemAccessor.filesystemsWatcher.changes <- []string{"0/0"}
+	waitChannel(c, attachmentInfoSet, "waiting for filesystem attachment info to be set")
+
+	// Growing the backing volume's block device, and triggering
+	// the notification, causes the filesystem to be grown.
+	args.volumes.blockDevices[blockDeviceId] = storage.BlockDevice{
+		DeviceName: "xvdf1",
+		Size:       246,
+	}
+	args.volumes.blockDevicesWatcher.changes <- struct{}{}
+	filesystemInfo := waitChannel(
+		c, filesystemInfoSet,
+		"waiting for filesystem info to be set",
+	).([]params.Filesystem)
+	c.Assert(filesystemInfo, jc.DeepEquals, []params.Filesystem{{
+		FilesystemTag: "filesystem-0-0",
+		VolumeTag:     "volume-0-0",
+		Info: params.FilesystemInfo{
+			FilesystemId: "whatever",
+			Size:         246,
+		},
+	}})
+	c.Assert(s.managedFilesystemSource.resizeArgs, jc.DeepEquals, []storage.ResizeFilesystemParams{{
+		Filesystem: names.NewFilesystemTag("0/0"),
+		Path:       "/mnt/xvdf1",
+	}})
+}
+
 func (s *storageProvisionerSuite) TestResourceTags(c *gc.C) {
 	volumeInfoSet := make(chan interface{})
 	volumeAccessor := newMockVolumeAccessor()
@@ -1690,6 +1899,12 @@ func newStorageProvisioner(c *gc.C, args *workerArgs) worker.Worker {
 	if args.statusSetter == nil {
 		args.statusSetter = &mockStatusSetter{}
 	}
+	if args.registerer == nil {
+		args.registerer = &mockMetricsRegisterer{}
+	}
+	if args.claims == nil {
+		args.claims = &mockStorageClaimer{}
+	}
 	worker, err := storageprovisioner.NewStorageProvisioner(storageprovisioner.Config{
 		Scope:       args.scope,
 		StorageDir:  storageDir,
@@ -1699,7 +1914,11 @@ func newStorageProvisioner(c *gc.C, args *workerArgs) worker.Worker {
 		Registry:    args.registry,
 		Machines:    args.machines,
 		Status:      args.statusSetter,
+		Claims:      args.claims,
 		Clock:       args.clock,
+		RetryJitter: args.retryJitter,
+
+		MetricsRegisterer: args.registerer,
 	})
 	c.Assert(err, jc.ErrorIsNil)
 	return worker
@@ -1714,6 +1933,9 @@ type workerArgs struct {
 	machines     *mockMachineAccessor
 	clock        clock.Clock
 	statusSetter *mockStatusSetter
+	registerer   *mockMetricsRegisterer
+	claims       *mockStorageClaimer
+	retryJitter  float64
 }
 
 func waitChannel(c *gc.C, ch <-chan interface{}, activity string) interface{} {
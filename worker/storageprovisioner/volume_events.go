@@ -465,7 +465,8 @@ func volumeParamsFromParams(in params.VolumeParams) (storage.VolumeParams, error
 				InstanceId: instance.Id(in.Attachment.InstanceId),
 				ReadOnly:   in.Attachment.ReadOnly,
 			},
-			Volume: volumeTag,
+			Volume:              volumeTag,
+			DeleteOnTermination: in.Attachment.DeleteOnTermination,
 		}
 	}
 	return storage.VolumeParams{
@@ -494,7 +495,8 @@ func volumeAttachmentParamsFromParams(in params.VolumeAttachmentParams) (storage
 			InstanceId: instance.Id(in.InstanceId),
 			ReadOnly:   in.ReadOnly,
 		},
-		Volume:   volumeTag,
-		VolumeId: in.VolumeId,
+		Volume:              volumeTag,
+		VolumeId:            in.VolumeId,
+		DeleteOnTermination: in.DeleteOnTermination,
 	}, nil
 }
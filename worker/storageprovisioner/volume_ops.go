@@ -50,9 +50,25 @@ func createVolumes(ctx *context, ops map[names.VolumeTag]*createVolumeOp) error
 		if len(volumeParams) == 0 {
 			continue
 		}
+		start := ctx.config.Clock.Now()
 		results, err := volumeSource.CreateVolumes(volumeParams)
+		resultErrs := make([]error, len(results))
+		for i, result := range results {
+			resultErrs[i] = result.Error
+		}
+		ctx.metrics.observeOperation(
+			operationCreateVolume, volumeParams[0].Provider, ctx.config.Clock.Now().Sub(start),
+			len(volumeParams), err, resultErrs,
+		)
 		if err != nil {
-			return errors.Annotatef(err, "creating volumes from source %q", sourceName)
+			// Treat the failure of the call as a whole as the
+			// failure of each volume, so that each is retried
+			// according to the class of error.
+			err = errors.Annotatef(err, "creating volumes from source %q", sourceName)
+			results = make([]storage.CreateVolumesResult, len(volumeParams))
+			for i := range results {
+				results[i].Error = err
+			}
 		}
 		for i, result := range results {
 			statuses = append(statuses, params.EntityStatusArgs{
@@ -61,15 +77,16 @@ func createVolumes(ctx *context, ops map[names.VolumeTag]*createVolumeOp) error
 			})
 			entityStatus := &statuses[len(statuses)-1]
 			if result.Error != nil {
-				// Reschedule the volume creation.
-				reschedule = append(reschedule, ops[volumeParams[i].Tag])
-
-				// Note: we keep the status as "pending" to indicate
-				// that we will retry. When we distinguish between
-				// transient and permanent errors, we will set the
-				// status to "error" for permanent errors.
+				// Reschedule the volume creation, keeping the
+				// status as "pending" to indicate that we will
+				// retry, or "error" if the error is permanent.
 				entityStatus.Status = status.Pending.String()
 				entityStatus.Info = result.Error.Error()
+				op := ops[volumeParams[i].Tag]
+				reschedule = append(reschedule, op)
+				if !op.failed(result.Error) {
+					entityStatus.Status = status.Error.String()
+				}
 				logger.Debugf(
 					"failed to create %s: %v",
 					names.ReadableString(volumeParams[i].Tag),
@@ -137,11 +154,25 @@ func attachVolumes(ctx *context, ops map[params.MachineStorageId]*attachVolumeOp
 	var volumeAttachments []storage.VolumeAttachment
 	var statuses []params.EntityStatusArgs
 	for sourceName, volumeAttachmentParams := range paramsBySource {
-		logger.Debugf("attaching volumes: %+v", volumeAttachmentParams)
 		volumeSource := volumeSources[sourceName]
-		results, err := volumeSource.AttachVolumes(volumeAttachmentParams)
-		if err != nil {
-			return errors.Annotatef(err, "attaching volumes from source %q", sourceName)
+		limitErrs := checkVolumeAttachmentLimits(volumeSource, volumeAttachmentParams)
+		results := make([]storage.AttachVolumesResult, len(volumeAttachmentParams))
+		attachParams := make([]storage.VolumeAttachmentParams, 0, len(volumeAttachmentParams))
+		for i, p := range volumeAttachmentParams {
+			if limitErrs[i] != nil {
+				results[i].Error = limitErrs[i]
+				continue
+			}
+			attachParams = append(attachParams, p)
+		}
+		if len(attachParams) > 0 {
+			attachResults := attachVolumesFromSource(ctx, sourceName, volumeSource, attachParams)
+			for i := range results {
+				if limitErrs[i] == nil {
+					results[i] = attachResults[0]
+					attachResults = attachResults[1:]
+				}
+			}
 		}
 		for i, result := range results {
 			p := volumeAttachmentParams[i]
@@ -156,14 +187,16 @@ func attachVolumes(ctx *context, ops map[params.MachineStorageId]*attachVolumeOp
 					MachineTag:    p.Machine.String(),
 					AttachmentTag: p.Volume.String(),
 				}
-				reschedule = append(reschedule, ops[id])
-
-				// Note: we keep the status as "attaching" to
-				// indicate that we will retry. When we distinguish
-				// between transient and permanent errors, we will
-				// set the status to "error" for permanent errors.
+				// Keep the status as "attaching" to indicate
+				// that we will retry, or set it to "error" if
+				// the error is permanent.
 				entityStatus.Status = status.Attaching.String()
 				entityStatus.Info = result.Error.Error()
+				op := ops[id]
+				reschedule = append(reschedule, op)
+				if !op.failed(result.Error) {
+					entityStatus.Status = status.Error.String()
+				}
 				logger.Debugf(
 					"failed to attach %s to %s: %v",
 					names.ReadableString(p.Volume),
@@ -183,6 +216,35 @@ func attachVolumes(ctx *context, ops map[params.MachineStorageId]*attachVolumeOp
 	return nil
 }
 
+// attachVolumesFromSource attaches volumes from the given source with
+// the specified parameters, and returns a result for each.
+func attachVolumesFromSource(
+	ctx *context,
+	sourceName string,
+	volumeSource storage.VolumeSource,
+	volumeAttachmentParams []storage.VolumeAttachmentParams,
+) []storage.AttachVolumesResult {
+	logger.Debugf("attaching volumes: %+v", volumeAttachmentParams)
+	start := ctx.config.Clock.Now()
+	results, err := volumeSource.AttachVolumes(volumeAttachmentParams)
+	resultErrs := make([]error, len(results))
+	for i, result := range results {
+		resultErrs[i] = result.Error
+	}
+	ctx.metrics.observeOperation(
+		operationAttachVolume, volumeAttachmentParams[0].Provider, ctx.config.Clock.Now().Sub(start),
+		len(volumeAttachmentParams), err, resultErrs,
+	)
+	if err != nil {
+		err = errors.Annotatef(err, "attaching volumes from source %q", sourceName)
+		results = make([]storage.AttachVolumesResult, len(volumeAttachmentParams))
+		for i := range results {
+			results[i].Error = err
+		}
+	}
+	return results
+}
+
 // destroyVolumes destroys volumes with the specified parameters.
 func destroyVolumes(ctx *context, ops map[names.VolumeTag]*destroyVolumeOp) error {
 	tags := make([]names.VolumeTag, 0, len(ops))
@@ -232,9 +294,14 @@ func destroyVolumes(ctx *context, ops map[names.VolumeTag]*destroyVolumeOp) erro
 			}
 			volumeIds[i] = volume.VolumeId
 		}
+		start := ctx.config.Clock.Now()
 		errs, err := volumeSource.DestroyVolumes(volumeIds)
+		ctx.metrics.observeOperation(
+			operationDestroyVolume, volumeParams[0].Provider, ctx.config.Clock.Now().Sub(start),
+			len(volumeIds), err, errs,
+		)
 		if err != nil {
-			return errors.Trace(err)
+			errs = bulkErrors(errors.Annotatef(err, "destroying volumes from source %q", sourceName), len(volumeIds))
 		}
 		for i, err := range errs {
 			tag := volumeParams[i].Tag
@@ -242,13 +309,19 @@ func destroyVolumes(ctx *context, ops map[names.VolumeTag]*destroyVolumeOp) erro
 				remove = append(remove, tag)
 				continue
 			}
-			// Failed to destroy volume; reschedule and update status.
-			reschedule = append(reschedule, ops[tag])
-			statuses = append(statuses, params.EntityStatusArgs{
+			// Failed to destroy volume; reschedule, and
+			// update status.
+			entityStatus := params.EntityStatusArgs{
 				Tag:    tag.String(),
 				Status: status.Destroying.String(),
 				Info:   err.Error(),
-			})
+			}
+			op := ops[tag]
+			reschedule = append(reschedule, op)
+			if !op.failed(err) {
+				entityStatus.Status = status.Error.String()
+			}
+			statuses = append(statuses, entityStatus)
 		}
 	}
 	scheduleOperations(ctx, reschedule...)
@@ -277,9 +350,14 @@ func detachVolumes(ctx *context, ops map[params.MachineStorageId]*detachVolumeOp
 	for sourceName, volumeAttachmentParams := range paramsBySource {
 		logger.Debugf("detaching volumes: %+v", volumeAttachmentParams)
 		volumeSource := volumeSources[sourceName]
+		start := ctx.config.Clock.Now()
 		errs, err := volumeSource.DetachVolumes(volumeAttachmentParams)
+		ctx.metrics.observeOperation(
+			operationDetachVolume, volumeAttachmentParams[0].Provider, ctx.config.Clock.Now().Sub(start),
+			len(volumeAttachmentParams), err, errs,
+		)
 		if err != nil {
-			return errors.Annotatef(err, "detaching volumes from source %q", sourceName)
+			errs = bulkErrors(errors.Annotatef(err, "detaching volumes from source %q", sourceName), len(volumeAttachmentParams))
 		}
 		for i, err := range errs {
 			p := volumeAttachmentParams[i]
@@ -297,9 +375,13 @@ func detachVolumes(ctx *context, ops map[params.MachineStorageId]*detachVolumeOp
 			}
 			entityStatus := &statuses[len(statuses)-1]
 			if err != nil {
-				reschedule = append(reschedule, ops[id])
 				entityStatus.Status = status.Detaching.String()
 				entityStatus.Info = err.Error()
+				op := ops[id]
+				reschedule = append(reschedule, op)
+				if !op.failed(err) {
+					entityStatus.Status = status.Error.String()
+				}
 				logger.Debugf(
 					"failed to detach %s from %s: %v",
 					names.ReadableString(p.Volume),
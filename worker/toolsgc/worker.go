@@ -0,0 +1,114 @@
+// Copyright 2016 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package toolsgc
+
+import (
+	"time"
+
+	"github.com/juju/errors"
+	"github.com/juju/loggo"
+	"github.com/juju/utils/clock"
+	"gopkg.in/tomb.v1"
+
+	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/worker"
+)
+
+var logger = loggo.GetLogger("juju.worker.toolsgc")
+
+// Facade exposes the controller capabilities required by the worker.
+type Facade interface {
+
+	// RemoveUnusedAgentBinaries removes the agent binaries that are
+	// neither in use, nor among the keepLatest most recent versions
+	// for their major.minor version, returning those removed.
+	RemoveUnusedAgentBinaries(keepLatest int) ([]params.AgentBinary, error)
+}
+
+// Config defines the operation of an agent binary garbage collector.
+type Config struct {
+
+	// Facade is the worker's view of the controller.
+	Facade Facade
+
+	// Clock is the worker's view of time.
+	Clock clock.Clock
+
+	// Period is the time between garbage collections.
+	Period time.Duration
+
+	// KeepLatest is the number of most recent versions to retain
+	// for each major.minor version, regardless of whether they are
+	// in use.
+	KeepLatest int
+}
+
+// Validate returns an error if the configuration cannot be expected
+// to start a functional worker.
+func (config Config) Validate() error {
+	if config.Facade == nil {
+		return errors.NotValidf("nil Facade")
+	}
+	if config.Clock == nil {
+		return errors.NotValidf("nil Clock")
+	}
+	if config.Period <= 0 {
+		return errors.NotValidf("non-positive Period")
+	}
+	if config.KeepLatest <= 0 {
+		return errors.NotValidf("non-positive KeepLatest")
+	}
+	return nil
+}
+
+// NewWorker returns a worker that calls RemoveUnusedAgentBinaries on
+// the configured Facade, once when started and subsequently every
+// Period.
+func NewWorker(config Config) (worker.Worker, error) {
+	if err := config.Validate(); err != nil {
+		return nil, errors.Trace(err)
+	}
+	w := &gcWorker{
+		config: config,
+	}
+	go func() {
+		defer w.tomb.Done()
+		w.tomb.Kill(w.loop())
+	}()
+	return w, nil
+}
+
+type gcWorker struct {
+	tomb   tomb.Tomb
+	config Config
+}
+
+func (w *gcWorker) loop() error {
+	var delay time.Duration
+	for {
+		select {
+		case <-w.tomb.Dying():
+			return tomb.ErrDying
+		case <-w.config.Clock.After(delay):
+			removed, err := w.config.Facade.RemoveUnusedAgentBinaries(w.config.KeepLatest)
+			if err != nil {
+				return errors.Annotate(err, "removing unused agent binaries")
+			}
+			for _, binary := range removed {
+				logger.Infof("removed unused agent binary %s", binary.Version)
+			}
+		}
+		delay = w.config.Period
+	}
+}
+
+// Kill is part of the worker.Worker interface.
+func (w *gcWorker) Kill() {
+	w.tomb.Kill(nil)
+}
+
+// Wait is part of the worker.Worker interface.
+func (w *gcWorker) Wait() error {
+	return w.tomb.Wait()
+}
@@ -0,0 +1,99 @@
+// Copyright 2016 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package toolsmirror
+
+import (
+	"time"
+
+	"github.com/juju/errors"
+	"github.com/juju/utils/clock"
+	"gopkg.in/tomb.v1"
+
+	"github.com/juju/juju/worker"
+)
+
+// Facade exposes the controller capabilities required by the worker.
+type Facade interface {
+
+	// UpdateToolsMirror regenerates the simplestreams metadata of
+	// the model's tools mirror from the agent binaries in the
+	// model's tools storage.
+	UpdateToolsMirror() error
+}
+
+// Config defines the operation of a tools mirror updater.
+type Config struct {
+
+	// Facade is the worker's view of the controller.
+	Facade Facade
+
+	// Clock is the worker's view of time.
+	Clock clock.Clock
+
+	// Period is the time between updates of the tools mirror.
+	Period time.Duration
+}
+
+// Validate returns an error if the configuration cannot be expected
+// to start a functional worker.
+func (config Config) Validate() error {
+	if config.Facade == nil {
+		return errors.NotValidf("nil Facade")
+	}
+	if config.Clock == nil {
+		return errors.NotValidf("nil Clock")
+	}
+	if config.Period <= 0 {
+		return errors.NotValidf("non-positive Period")
+	}
+	return nil
+}
+
+// NewWorker returns a worker that calls UpdateToolsMirror on the
+// configured Facade, once when started and subsequently every Period,
+// so that the tools mirror served by the controller reflects the agent
+// binaries cached in the model's tools storage.
+func NewWorker(config Config) (worker.Worker, error) {
+	if err := config.Validate(); err != nil {
+		return nil, errors.Trace(err)
+	}
+	w := &mirrorWorker{
+		config: config,
+	}
+	go func() {
+		defer w.tomb.Done()
+		w.tomb.Kill(w.loop())
+	}()
+	return w, nil
+}
+
+type mirrorWorker struct {
+	tomb   tomb.Tomb
+	config Config
+}
+
+func (w *mirrorWorker) loop() error {
+	var delay time.Duration
+	for {
+		select {
+		case <-w.tomb.Dying():
+			return tomb.ErrDying
+		case <-w.config.Clock.After(delay):
+			if err := w.config.Facade.UpdateToolsMirror(); err != nil {
+				return errors.Annotate(err, "updating tools mirror")
+			}
+		}
+		delay = w.config.Period
+	}
+}
+
+// Kill is part of the worker.Worker interface.
+func (w *mirrorWorker) Kill() {
+	w.tomb.Kill(nil)
+}
+
+// Wait is part of the worker.Worker interface.
+func (w *mirrorWorker) Wait() error {
+	return w.tomb.Wait()
+}
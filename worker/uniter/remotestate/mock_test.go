@@ -239,6 +239,7 @@ type mockService struct {
 	curl                  *charm.URL
 	charmModifiedVersion  int
 	forceUpgrade          bool
+	paused                bool
 	serviceWatcher        *mockNotifyWatcher
 	leaderSettingsWatcher *mockNotifyWatcher
 	relationsWatcher      *mockStringsWatcher
@@ -256,6 +257,10 @@ func (s *mockService) Life() params.Life {
 	return s.life
 }
 
+func (s *mockService) Paused() (bool, error) {
+	return s.paused, nil
+}
+
 func (s *mockService) Refresh() error {
 	return nil
 }
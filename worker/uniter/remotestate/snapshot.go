@@ -36,6 +36,10 @@ type Snapshot struct {
 	// should upgrade even in an error state.
 	ForceCharmUpgrade bool
 
+	// Paused reports whether the service has been
+	// paused, in which case hooks should be deferred.
+	Paused bool
+
 	// ResolvedMode reports the method of resolving
 	// hook execution errors.
 	ResolvedMode params.ResolvedMode
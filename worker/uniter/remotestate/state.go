@@ -42,6 +42,8 @@ type Application interface {
 	CharmURL() (*charm.URL, bool, error)
 	// Life returns whether the service is alive.
 	Life() params.Life
+	// Paused returns whether the service has been paused.
+	Paused() (bool, error)
 	// Refresh syncs this value with the api server.
 	Refresh() error
 	// Tag returns the tag for this service.
@@ -495,10 +495,15 @@ func (w *RemoteStateWatcher) serviceChanged() error {
 	if err != nil {
 		return errors.Trace(err)
 	}
+	paused, err := w.service.Paused()
+	if err != nil {
+		return errors.Trace(err)
+	}
 	w.mu.Lock()
 	w.current.CharmURL = url
 	w.current.ForceCharmUpgrade = force
 	w.current.CharmModifiedVersion = ver
+	w.current.Paused = paused
 	w.mu.Unlock()
 	return nil
 }
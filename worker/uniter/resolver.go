@@ -73,6 +73,15 @@ func (s *uniterResolver) NextOp(
 		s.retryHookTimerStarted = false
 	}
 
+	if remoteState.Paused && remoteState.Life == params.Alive && localState.Kind == operation.Continue {
+		// The application has been paused, so we defer running any
+		// further hooks until it is resumed. Operations already in
+		// progress are allowed to complete, and a dying unit is not
+		// prevented from cleaning up after itself.
+		logger.Infof("application is paused; deferring hooks")
+		return nil, resolver.ErrNoOperation
+	}
+
 	op, err := s.config.Leadership.NextOp(localState, remoteState, opFactory)
 	if errors.Cause(err) != resolver.ErrNoOperation {
 		return op, err
@@ -215,6 +215,14 @@ func (u *Upgrader) loop() error {
 		// repeatedly (causing the agent to be stopped), as long
 		// as we have got as far as this, we will still be able to
 		// upgrade the agent.
+		if len(wantToolsList) > 0 {
+			wantTools := wantToolsList[0]
+			err = u.ensureToolsDelta(wantTools)
+			if err == nil {
+				return u.newUpgradeReadyError(wantTools.Version)
+			}
+			logger.Infof("cannot fetch tools delta, fetching full tools: %v", err)
+		}
 		for _, wantTools := range wantToolsList {
 			err = u.ensureTools(wantTools)
 			if err == nil {
@@ -268,3 +276,33 @@ func (u *Upgrader) ensureTools(agentTools *coretools.Tools) error {
 	logger.Infof("unpacked tools %s to %s", agentTools.Version, u.dataDir)
 	return nil
 }
+
+// ensureToolsDelta fetches the given tools over the API connection as
+// a delta from the tools that the agent is currently running, falling
+// back to the full tarball if the controller cannot produce a delta.
+// A delta is verified against the hashes that it carries, rather than
+// the tools' hash, so it is only trusted over the API connection,
+// whose peer is validated.
+func (u *Upgrader) ensureToolsDelta(agentTools *coretools.Tools) error {
+	currentVersion := toBinaryVersion(jujuversion.Current)
+	currentTools, err := agenttools.ReadTools(u.dataDir, currentVersion)
+	if err != nil {
+		return errors.Annotate(err, "cannot read current tools")
+	}
+	logger.Infof("fetching tools %s as a delta from %s", agentTools.Version, currentVersion)
+	r, isDelta, err := u.st.OpenToolsDelta(agentTools.Version, currentTools)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	defer r.Close()
+	if isDelta {
+		err = agenttools.UnpackToolsDelta(u.dataDir, currentVersion, agentTools, r)
+	} else {
+		err = agenttools.UnpackTools(u.dataDir, agentTools, r)
+	}
+	if err != nil {
+		return errors.Annotate(err, "cannot unpack tools")
+	}
+	logger.Infof("unpacked tools %s to %s", agentTools.Version, u.dataDir)
+	return nil
+}